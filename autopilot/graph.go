@@ -3,11 +3,13 @@ package autopilot
 import (
 	"context"
 	"encoding/hex"
+	"math"
 	"net"
 	"sort"
 
 	graphdb "github.com/flokiorg/flnd/graph/db"
 	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/go-flokicoin/chainutil"
@@ -47,6 +49,41 @@ func ChannelGraphFromDatabase(db GraphSource) ChannelGraph {
 	}
 }
 
+// leaseSellerSource is implemented by graph sources that index the lease
+// rates advertised by nodes.
+type leaseSellerSource interface {
+	CheapestLeaseSellers(amt chainutil.Amount,
+		feeRate chainfee.SatPerKWeight,
+		numSellers int) []graphdb.LeaseSeller
+}
+
+// A compile time assertion to ensure databaseChannelGraph meets the
+// autopilot.LeaseSellerGraph interface.
+var _ LeaseSellerGraph = (*databaseChannelGraph)(nil)
+
+// LeaseFees returns the fee each node selling inbound liquidity charges for
+// leasing amt, not counting the fee for the funding weight the node adds.
+//
+// NOTE: Part of the autopilot.LeaseSellerGraph interface.
+func (d *databaseChannelGraph) LeaseFees(
+	amt chainutil.Amount) map[NodeID]chainutil.Amount {
+
+	fees := make(map[NodeID]chainutil.Amount)
+
+	source, ok := d.db.(leaseSellerSource)
+	if !ok {
+		return fees
+	}
+
+	for _, seller := range source.CheapestLeaseSellers(
+		amt, 0, math.MaxInt,
+	) {
+		fees[NodeID(seller.Node)] = seller.Fee
+	}
+
+	return fees
+}
+
 // type dbNode is a wrapper struct around a database transaction an
 // channeldb.Node. The wrapper method implement the autopilot.Node
 // interface.
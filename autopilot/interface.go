@@ -92,6 +92,17 @@ type ChannelGraph interface {
 		reset func()) error
 }
 
+// LeaseSellerGraph is a ChannelGraph that also knows about the nodes selling
+// inbound liquidity at the lease rates they advertised.
+type LeaseSellerGraph interface {
+	ChannelGraph
+
+	// LeaseFees returns the fee each node selling inbound liquidity
+	// charges for leasing amt, not counting the fee for the funding
+	// weight the node adds.
+	LeaseFees(amt chainutil.Amount) map[NodeID]chainutil.Amount
+}
+
 // NodeScore is a tuple mapping a NodeID to a score indicating the preference
 // of opening a channel with it.
 type NodeScore struct {
@@ -192,6 +203,7 @@ var (
 		NewPrefAttachment(),
 		NewExternalScoreAttachment(),
 		NewTopCentrality(),
+		NewLeaseCostAttachment(),
 	}
 
 	// AvailableHeuristics is a map that holds the name of available
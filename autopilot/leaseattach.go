@@ -0,0 +1,96 @@
+package autopilot
+
+import (
+	"context"
+
+	"github.com/flokiorg/go-flokicoin/chainutil"
+)
+
+// LeaseCostAttachment is an implementation of the AttachmentHeuristic
+// interface that prefers nodes selling inbound liquidity at the lowest fee,
+// based on the lease rates they advertised in their node announcements.
+type LeaseCostAttachment struct{}
+
+// NewLeaseCostAttachment creates a new instance of a LeaseCostAttachment
+// heuristic.
+func NewLeaseCostAttachment() *LeaseCostAttachment {
+	return &LeaseCostAttachment{}
+}
+
+// A compile time assertion to ensure LeaseCostAttachment meets the
+// AttachmentHeuristic interface.
+var _ AttachmentHeuristic = (*LeaseCostAttachment)(nil)
+
+// Name returns the name of this heuristic.
+//
+// NOTE: This is a part of the AttachmentHeuristic interface.
+func (l *LeaseCostAttachment) Name() string {
+	return "leasecost"
+}
+
+// NodeScores is a method that given the current channel graph and current set
+// of local channels, scores the given nodes according to the preference of
+// opening a channel of the given size with them. The returned channel
+// candidates maps the NodeID to a NodeScore for the node.
+//
+// The score of a node selling inbound liquidity is the lowest fee any of the
+// given nodes charges for leasing chanSize, divided by the fee of the node.
+// The cheapest sellers thereby get a score of 1.0, while nodes that don't sell
+// any liquidity, or that we already have a channel with, aren't scored.
+//
+// NOTE: This is a part of the AttachmentHeuristic interface.
+func (l *LeaseCostAttachment) NodeScores(_ context.Context, g ChannelGraph,
+	chans []LocalChannel, chanSize chainutil.Amount,
+	nodes map[NodeID]struct{}) (map[NodeID]*NodeScore, error) {
+
+	candidates := make(map[NodeID]*NodeScore)
+
+	sellerGraph, ok := g.(LeaseSellerGraph)
+	if !ok {
+		log.Debugf("Graph doesn't know about lease sellers, unable " +
+			"to score nodes by lease cost")
+
+		return candidates, nil
+	}
+
+	existingPeers := make(map[NodeID]struct{})
+	for _, c := range chans {
+		existingPeers[c.Node] = struct{}{}
+	}
+
+	// Collect the fees of the sellers among the nodes to score, keeping
+	// track of the cheapest one.
+	fees := make(map[NodeID]chainutil.Amount)
+	minFee := chainutil.Amount(-1)
+	for nID, fee := range sellerGraph.LeaseFees(chanSize) {
+		if _, ok := nodes[nID]; !ok {
+			continue
+		}
+
+		if _, ok := existingPeers[nID]; ok {
+			continue
+		}
+
+		fees[nID] = fee
+		if minFee < 0 || fee < minFee {
+			minFee = fee
+		}
+	}
+
+	for nID, fee := range fees {
+		score := 1.0
+		if fee > 0 {
+			score = float64(minFee) / float64(fee)
+		}
+
+		log.Tracef("Lease cost score %v given to node %x with fee %v",
+			score, nID[:], fee)
+
+		candidates[nID] = &NodeScore{
+			NodeID: nID,
+			Score:  score,
+		}
+	}
+
+	return candidates, nil
+}
@@ -0,0 +1,74 @@
+package autopilot_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flokiorg/flnd/autopilot"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/stretchr/testify/require"
+)
+
+// leaseSellerGraph is a graph without any nodes or channels that only knows
+// the fees of lease sellers.
+type leaseSellerGraph struct {
+	fees map[autopilot.NodeID]chainutil.Amount
+}
+
+func (g *leaseSellerGraph) ForEachNode(context.Context,
+	func(context.Context, autopilot.Node) error, func()) error {
+
+	return nil
+}
+
+func (g *leaseSellerGraph) ForEachNodesChannels(context.Context,
+	func(context.Context, autopilot.NodeID, []*autopilot.ChannelEdge) error,
+	func()) error {
+
+	return nil
+}
+
+func (g *leaseSellerGraph) LeaseFees(
+	chainutil.Amount) map[autopilot.NodeID]chainutil.Amount {
+
+	return g.fees
+}
+
+// TestLeaseCostAttachment asserts that the cheapest sellers of inbound
+// liquidity get the highest scores.
+func TestLeaseCostAttachment(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	cheap := autopilot.NodeID{1}
+	expensive := autopilot.NodeID{2}
+	peer := autopilot.NodeID{3}
+	unknown := autopilot.NodeID{4}
+	notCandidate := autopilot.NodeID{5}
+
+	graph := &leaseSellerGraph{
+		fees: map[autopilot.NodeID]chainutil.Amount{
+			cheap:        1_000,
+			expensive:    4_000,
+			peer:         500,
+			notCandidate: 100,
+		},
+	}
+	nodes := map[autopilot.NodeID]struct{}{
+		cheap:     {},
+		expensive: {},
+		peer:      {},
+		unknown:   {},
+	}
+	chans := []autopilot.LocalChannel{{Node: peer}}
+
+	h := autopilot.NewLeaseCostAttachment()
+	scores, err := h.NodeScores(ctx, graph, chans, 1_000_000, nodes)
+	require.NoError(t, err)
+
+	// Only the sellers among the candidates we don't have a channel with
+	// yet are scored.
+	require.Len(t, scores, 2)
+	require.Equal(t, 1.0, scores[cheap].Score)
+	require.Equal(t, 0.25, scores[expensive].Score)
+}
@@ -0,0 +1,198 @@
+package bolt12
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/flokiorg/go-flokicoin/chainutil/bech32"
+)
+
+const (
+	// OfferHRP is the human-readable prefix of an encoded offer.
+	OfferHRP = "lno"
+
+	// InvoiceRequestHRP is the human-readable prefix of an encoded
+	// invoice request.
+	InvoiceRequestHRP = "lnr"
+
+	// InvoiceHRP is the human-readable prefix of an encoded invoice.
+	InvoiceHRP = "lni"
+
+	// bech32Charset is the bech32 data-part alphabet.
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+var (
+	// ErrInvalidBech32 is returned when a BOLT 12 string is not a
+	// well-formed checksumless bech32 string.
+	ErrInvalidBech32 = errors.New("invalid bolt12 bech32 string")
+
+	// ErrWrongHRP is returned when a BOLT 12 string carries a different
+	// human-readable prefix than the one the caller expects.
+	ErrWrongHRP = errors.New("unexpected bolt12 human-readable prefix")
+)
+
+// encodeBech32 encodes data as a BOLT 12 string: the human-readable prefix, the
+// separator '1', and the data in the bech32 alphabet without a checksum.
+func encodeBech32(hrp string, data []byte) (string, error) {
+	conv, err := bech32.ConvertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(hrp) + 1 + len(conv))
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, b := range conv {
+		sb.WriteByte(bech32Charset[b])
+	}
+
+	return sb.String(), nil
+}
+
+// decodeBech32 decodes a BOLT 12 string with the expected human-readable
+// prefix. Per the reader rules, a '+' followed by optional whitespace joins
+// split strings, and the string must be all lower or all upper case.
+func decodeBech32(s, expectedHRP string) ([]byte, error) {
+	joined, err := joinBech32(s)
+	if err != nil {
+		return nil, err
+	}
+
+	lower := strings.ToLower(joined)
+	if joined != lower && joined != strings.ToUpper(joined) {
+		return nil, fmt.Errorf("%w: mixed case", ErrInvalidBech32)
+	}
+
+	sep := strings.LastIndexByte(lower, '1')
+	if sep < 1 {
+		return nil, fmt.Errorf("%w: missing separator",
+			ErrInvalidBech32)
+	}
+
+	if hrp := lower[:sep]; hrp != expectedHRP {
+		return nil, fmt.Errorf("%w: got %q, want %q", ErrWrongHRP,
+			hrp, expectedHRP)
+	}
+
+	dataPart := lower[sep+1:]
+	conv := make([]byte, len(dataPart))
+	for i := 0; i < len(dataPart); i++ {
+		idx := strings.IndexByte(bech32Charset, dataPart[i])
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: invalid character %q",
+				ErrInvalidBech32, dataPart[i])
+		}
+		conv[i] = byte(idx)
+	}
+
+	// Unlike BIP-173, BOLT 12 requires the padding to be discarded, so a
+	// string with more than 4 bits of zero padding is malformed rather
+	// than silently truncated.
+	data, err := bech32.ConvertBits(conv, 5, 8, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBech32, err)
+	}
+
+	return data, nil
+}
+
+// joinBech32 removes the '+' continuation markers (and any whitespace that
+// follows them) that the spec allows in split BOLT 12 strings.
+func joinBech32(s string) (string, error) {
+	if !strings.Contains(s, "+") {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	parts := strings.Split(s, "+")
+	for i, p := range parts {
+		if i > 0 {
+			p = strings.TrimLeftFunc(p, unicode.IsSpace)
+		}
+		if p == "" {
+			return "", fmt.Errorf("%w: empty segment around '+'",
+				ErrInvalidBech32)
+		}
+		sb.WriteString(p)
+	}
+
+	return sb.String(), nil
+}
+
+// EncodeString encodes the offer as an lno string.
+func (o *Offer) EncodeString() (string, error) {
+	data, err := o.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	return encodeBech32(OfferHRP, data)
+}
+
+// DecodeOfferString decodes an lno string into an Offer. Like the byte-level
+// decoder it is permissive, so callers must run ValidateOfferRead.
+func DecodeOfferString(s string) (*Offer, error) {
+	data, err := decodeBech32(s, OfferHRP)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeOffer(data)
+}
+
+// EncodeString encodes the invoice request as an lnr string. Unlike Encode,
+// which also runs before signing to produce the Merkle root, the string form
+// is wire-facing, so the signature is mandatory.
+func (ir *InvoiceRequest) EncodeString() (string, error) {
+	if !ir.Signature.IsSome() {
+		return "", ErrMissingSignature
+	}
+
+	data, err := ir.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	return encodeBech32(InvoiceRequestHRP, data)
+}
+
+// DecodeInvoiceRequestString decodes an lnr string into an InvoiceRequest.
+// Callers must run ValidateInvoiceRequestRead.
+func DecodeInvoiceRequestString(s string) (*InvoiceRequest, error) {
+	data, err := decodeBech32(s, InvoiceRequestHRP)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeInvoiceRequest(data)
+}
+
+// EncodeString encodes the invoice as an lni string. The signature is
+// mandatory in the string form.
+func (inv *Invoice) EncodeString() (string, error) {
+	if !inv.Signature.IsSome() {
+		return "", ErrMissingSignature
+	}
+
+	data, err := inv.Encode()
+	if err != nil {
+		return "", err
+	}
+
+	return encodeBech32(InvoiceHRP, data)
+}
+
+// DecodeInvoiceString decodes an lni string into an Invoice. Callers must run
+// ValidateInvoiceRead.
+func DecodeInvoiceString(s string) (*Invoice, error) {
+	data, err := decodeBech32(s, InvoiceHRP)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeInvoice(data)
+}
@@ -0,0 +1,459 @@
+package bolt12
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/crypto"
+)
+
+// ErrMissingNodeID is returned when invoice_node_id is absent.
+var ErrMissingNodeID = errors.New("missing invoice_node_id")
+
+// Invoice represents a BOLT 12 invoice message. It mirrors every non-signature
+// field of the invoice request it answers, adds the payee's payment details and
+// is signed by invoice_node_id with a BIP-340 signature over the Merkle root of
+// its TLV stream.
+//
+// An invoice responding to an invoice request should be constructed with
+// NewInvoiceFromRequest so the mirrored fields match exactly.
+type Invoice struct {
+	// InvreqMetadata is the payer metadata mirrored from the request.
+	InvreqMetadata tlv.OptionalRecordT[tlv.TlvType0, tlv.Blob]
+
+	// OfferChains are the chains mirrored from the offer.
+	OfferChains tlv.OptionalRecordT[tlv.TlvType2, ChainsRecord]
+
+	// OfferMetadata is the metadata mirrored from the offer.
+	OfferMetadata tlv.OptionalRecordT[tlv.TlvType4, tlv.Blob]
+
+	// OfferCurrency is the currency mirrored from the offer.
+	OfferCurrency tlv.OptionalRecordT[tlv.TlvType6, tlv.Blob]
+
+	// OfferAmount is the amount mirrored from the offer.
+	OfferAmount tlv.OptionalRecordT[tlv.TlvType8, TUint64]
+
+	// OfferDescription is the description mirrored from the offer.
+	OfferDescription tlv.OptionalRecordT[tlv.TlvType10, tlv.Blob]
+
+	// OfferFeatures are the features mirrored from the offer.
+	OfferFeatures tlv.OptionalRecordT[
+		tlv.TlvType12, lnwire.RawFeatureVector,
+	]
+
+	// OfferAbsoluteExpiry is the absolute expiry mirrored from the offer.
+	OfferAbsoluteExpiry tlv.OptionalRecordT[tlv.TlvType14, TUint64]
+
+	// OfferPaths are the blinded paths mirrored from the offer.
+	OfferPaths tlv.OptionalRecordT[tlv.TlvType16, lnwire.BlindedPaths]
+
+	// OfferIssuer is the issuer name mirrored from the offer.
+	OfferIssuer tlv.OptionalRecordT[tlv.TlvType18, tlv.Blob]
+
+	// OfferQuantityMax is the maximum quantity mirrored from the offer.
+	OfferQuantityMax tlv.OptionalRecordT[tlv.TlvType20, TUint64]
+
+	// OfferIssuerID is the issuer public key mirrored from the offer.
+	OfferIssuerID tlv.OptionalRecordT[tlv.TlvType22, *crypto.PublicKey]
+
+	// InvreqChain is the chain mirrored from the request.
+	InvreqChain tlv.OptionalRecordT[tlv.TlvType80, [32]byte]
+
+	// InvreqAmount is the amount mirrored from the request.
+	InvreqAmount tlv.OptionalRecordT[tlv.TlvType82, TUint64]
+
+	// InvreqFeatures are the features mirrored from the request.
+	InvreqFeatures tlv.OptionalRecordT[
+		tlv.TlvType84, lnwire.RawFeatureVector,
+	]
+
+	// InvreqQuantity is the quantity mirrored from the request.
+	InvreqQuantity tlv.OptionalRecordT[tlv.TlvType86, TUint64]
+
+	// InvreqPayerID is the payer public key mirrored from the request.
+	InvreqPayerID tlv.OptionalRecordT[tlv.TlvType88, *crypto.PublicKey]
+
+	// InvreqPayerNote is the payer note mirrored from the request.
+	InvreqPayerNote tlv.OptionalRecordT[tlv.TlvType89, tlv.Blob]
+
+	// InvreqPaths are the reply paths mirrored from the request.
+	InvreqPaths tlv.OptionalRecordT[tlv.TlvType90, lnwire.BlindedPaths]
+
+	// InvreqBip353Name is the BIP 353 name mirrored from the request.
+	InvreqBip353Name tlv.OptionalRecordT[tlv.TlvType91, tlv.Blob]
+
+	// InvoicePaths are the blinded payment paths to the payee.
+	InvoicePaths tlv.OptionalRecordT[
+		tlv.TlvType160, lnwire.BlindedPaths,
+	]
+
+	// InvoiceBlindedPay holds one blinded_payinfo per InvoicePaths entry,
+	// in the same order.
+	InvoiceBlindedPay tlv.OptionalRecordT[tlv.TlvType162, BlindedPayInfos]
+
+	// InvoiceCreatedAt is the creation time in seconds since the epoch.
+	InvoiceCreatedAt tlv.OptionalRecordT[tlv.TlvType164, TUint64]
+
+	// InvoiceRelativeExpiry is the number of seconds after
+	// InvoiceCreatedAt that the invoice expires. Absent means
+	// DefaultInvoiceExpiry.
+	InvoiceRelativeExpiry tlv.OptionalRecordT[tlv.TlvType166, TUint32]
+
+	// InvoicePaymentHash is the SHA256 hash of the payment preimage.
+	InvoicePaymentHash tlv.OptionalRecordT[tlv.TlvType168, [32]byte]
+
+	// InvoiceAmount is the amount to pay in msat.
+	InvoiceAmount tlv.OptionalRecordT[tlv.TlvType170, TUint64]
+
+	// InvoiceFallbacks are on-chain addresses the payer may fall back to.
+	InvoiceFallbacks tlv.OptionalRecordT[
+		tlv.TlvType172, FallbackAddresses,
+	]
+
+	// InvoiceFeatures is the feature bit vector of the invoice.
+	InvoiceFeatures tlv.OptionalRecordT[
+		tlv.TlvType174, lnwire.RawFeatureVector,
+	]
+
+	// InvoiceNodeID is the key that signs the invoice.
+	InvoiceNodeID tlv.OptionalRecordT[tlv.TlvType176, *crypto.PublicKey]
+
+	// Signature is a BIP-340 Schnorr signature by InvoiceNodeID covering
+	// all signed-range fields.
+	Signature tlv.OptionalRecordT[tlv.TlvType240, [64]byte]
+
+	// decodedTLVs is the canonical TypeMap produced by the typed-stream
+	// pass that decoded this invoice. See Offer.decodedTLVs for the design
+	// rationale.
+	decodedTLVs tlv.TypeMap
+}
+
+var _ lnwire.PureTLVMessage = (*Invoice)(nil)
+
+// AllRecords returns the canonical sorted record list for this invoice, merging
+// the typed records with any extra signed-range fields that the decoder
+// preserved.
+//
+// NOTE: this is part of the tlv.PureTLVMessage interface.
+func (inv *Invoice) AllRecords() []tlv.Record {
+	return allRecordsFromTypeMap(
+		inv.allRecordProducers(), inv.decodedTLVs,
+	)
+}
+
+// allRecordProducers returns the set of records that are present.
+func (inv *Invoice) allRecordProducers() []tlv.RecordProducer {
+	var p []tlv.RecordProducer
+
+	lnwire.AddOpt(&p, inv.InvreqMetadata)
+	lnwire.AddOpt(&p, inv.OfferChains)
+	lnwire.AddOpt(&p, inv.OfferMetadata)
+	lnwire.AddOpt(&p, inv.OfferCurrency)
+	lnwire.AddOpt(&p, inv.OfferAmount)
+	lnwire.AddOpt(&p, inv.OfferDescription)
+	lnwire.AddOpt(&p, inv.OfferFeatures)
+	lnwire.AddOpt(&p, inv.OfferAbsoluteExpiry)
+	lnwire.AddOpt(&p, inv.OfferPaths)
+	lnwire.AddOpt(&p, inv.OfferIssuer)
+	lnwire.AddOpt(&p, inv.OfferQuantityMax)
+	lnwire.AddOpt(&p, inv.OfferIssuerID)
+	lnwire.AddOpt(&p, inv.InvreqChain)
+	lnwire.AddOpt(&p, inv.InvreqAmount)
+	lnwire.AddOpt(&p, inv.InvreqFeatures)
+	lnwire.AddOpt(&p, inv.InvreqQuantity)
+	lnwire.AddOpt(&p, inv.InvreqPayerID)
+	lnwire.AddOpt(&p, inv.InvreqPayerNote)
+	lnwire.AddOpt(&p, inv.InvreqPaths)
+	lnwire.AddOpt(&p, inv.InvreqBip353Name)
+	lnwire.AddOpt(&p, inv.InvoicePaths)
+	lnwire.AddOpt(&p, inv.InvoiceBlindedPay)
+	lnwire.AddOpt(&p, inv.InvoiceCreatedAt)
+	lnwire.AddOpt(&p, inv.InvoiceRelativeExpiry)
+	lnwire.AddOpt(&p, inv.InvoicePaymentHash)
+	lnwire.AddOpt(&p, inv.InvoiceAmount)
+	lnwire.AddOpt(&p, inv.InvoiceFallbacks)
+	lnwire.AddOpt(&p, inv.InvoiceFeatures)
+	lnwire.AddOpt(&p, inv.InvoiceNodeID)
+	lnwire.AddOpt(&p, inv.Signature)
+
+	return p
+}
+
+// Encode validates the invoice per writer requirements and serialises it via
+// the PureTLVMessage shape.
+func (inv *Invoice) Encode() ([]byte, error) {
+	if err := ValidateInvoiceWrite(inv); err != nil {
+		return nil, fmt.Errorf("validate invoice: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := lnwire.EncodePureTLVMessage(inv, &buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeInvoice deserializes an invoice from a TLV byte stream. Decoding is
+// permissive: callers that need spec compliance must run ValidateInvoiceRead.
+func DecodeInvoice(data []byte) (*Invoice, error) {
+	var inv Invoice
+
+	invreqMetadata := tlv.ZeroRecordT[tlv.TlvType0, tlv.Blob]()
+	chains := tlv.ZeroRecordT[tlv.TlvType2, ChainsRecord]()
+	metadata := tlv.ZeroRecordT[tlv.TlvType4, tlv.Blob]()
+	currency := tlv.ZeroRecordT[tlv.TlvType6, tlv.Blob]()
+	amount := tlv.ZeroRecordT[tlv.TlvType8, TUint64]()
+	desc := tlv.ZeroRecordT[tlv.TlvType10, tlv.Blob]()
+	features := tlv.ZeroRecordT[tlv.TlvType12, lnwire.RawFeatureVector]()
+	expiry := tlv.ZeroRecordT[tlv.TlvType14, TUint64]()
+	paths := tlv.ZeroRecordT[tlv.TlvType16, lnwire.BlindedPaths]()
+	issuer := tlv.ZeroRecordT[tlv.TlvType18, tlv.Blob]()
+	qtyMax := tlv.ZeroRecordT[tlv.TlvType20, TUint64]()
+	issuerID := tlv.ZeroRecordT[tlv.TlvType22, *crypto.PublicKey]()
+	invreqChain := tlv.ZeroRecordT[tlv.TlvType80, [32]byte]()
+	invreqAmount := tlv.ZeroRecordT[tlv.TlvType82, TUint64]()
+	invreqFeatures := tlv.ZeroRecordT[
+		tlv.TlvType84, lnwire.RawFeatureVector,
+	]()
+	invreqQty := tlv.ZeroRecordT[tlv.TlvType86, TUint64]()
+	payerID := tlv.ZeroRecordT[tlv.TlvType88, *crypto.PublicKey]()
+	payerNote := tlv.ZeroRecordT[tlv.TlvType89, tlv.Blob]()
+	invreqPaths := tlv.ZeroRecordT[tlv.TlvType90, lnwire.BlindedPaths]()
+	bip353 := tlv.ZeroRecordT[tlv.TlvType91, tlv.Blob]()
+	invPaths := tlv.ZeroRecordT[tlv.TlvType160, lnwire.BlindedPaths]()
+	payInfo := tlv.ZeroRecordT[tlv.TlvType162, BlindedPayInfos]()
+	createdAt := tlv.ZeroRecordT[tlv.TlvType164, TUint64]()
+	relExpiry := tlv.ZeroRecordT[tlv.TlvType166, TUint32]()
+	payHash := tlv.ZeroRecordT[tlv.TlvType168, [32]byte]()
+	invAmount := tlv.ZeroRecordT[tlv.TlvType170, TUint64]()
+	fallbacks := tlv.ZeroRecordT[tlv.TlvType172, FallbackAddresses]()
+	invFeatures := tlv.ZeroRecordT[
+		tlv.TlvType174, lnwire.RawFeatureVector,
+	]()
+	nodeID := tlv.ZeroRecordT[tlv.TlvType176, *crypto.PublicKey]()
+	sig := tlv.ZeroRecordT[tlv.TlvType240, [64]byte]()
+
+	tm, err := decodeStream(
+		data, invreqMetadata.Record(), chains.Record(),
+		metadata.Record(), currency.Record(), amount.Record(),
+		desc.Record(), features.Record(), expiry.Record(),
+		paths.Record(), issuer.Record(), qtyMax.Record(),
+		issuerID.Record(), invreqChain.Record(), invreqAmount.Record(),
+		invreqFeatures.Record(), invreqQty.Record(), payerID.Record(),
+		payerNote.Record(), invreqPaths.Record(), bip353.Record(),
+		invPaths.Record(), payInfo.Record(), createdAt.Record(),
+		relExpiry.Record(), payHash.Record(), invAmount.Record(),
+		fallbacks.Record(), invFeatures.Record(), nodeID.Record(),
+		sig.Record(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("decode invoice: %w", err)
+	}
+
+	lnwire.SetOptFromMap(tm, &inv.InvreqMetadata, invreqMetadata)
+	lnwire.SetOptFromMap(tm, &inv.OfferChains, chains)
+	lnwire.SetOptFromMap(tm, &inv.OfferMetadata, metadata)
+	lnwire.SetOptFromMap(tm, &inv.OfferCurrency, currency)
+	lnwire.SetOptFromMap(tm, &inv.OfferAmount, amount)
+	lnwire.SetOptFromMap(tm, &inv.OfferDescription, desc)
+	lnwire.SetOptFromMap(tm, &inv.OfferFeatures, features)
+	lnwire.SetOptFromMap(tm, &inv.OfferAbsoluteExpiry, expiry)
+	lnwire.SetOptFromMap(tm, &inv.OfferPaths, paths)
+	lnwire.SetOptFromMap(tm, &inv.OfferIssuer, issuer)
+	lnwire.SetOptFromMap(tm, &inv.OfferQuantityMax, qtyMax)
+	lnwire.SetOptFromMap(tm, &inv.OfferIssuerID, issuerID)
+	lnwire.SetOptFromMap(tm, &inv.InvreqChain, invreqChain)
+	lnwire.SetOptFromMap(tm, &inv.InvreqAmount, invreqAmount)
+	lnwire.SetOptFromMap(tm, &inv.InvreqFeatures, invreqFeatures)
+	lnwire.SetOptFromMap(tm, &inv.InvreqQuantity, invreqQty)
+	lnwire.SetOptFromMap(tm, &inv.InvreqPayerID, payerID)
+	lnwire.SetOptFromMap(tm, &inv.InvreqPayerNote, payerNote)
+	lnwire.SetOptFromMap(tm, &inv.InvreqPaths, invreqPaths)
+	lnwire.SetOptFromMap(tm, &inv.InvreqBip353Name, bip353)
+	lnwire.SetOptFromMap(tm, &inv.InvoicePaths, invPaths)
+	lnwire.SetOptFromMap(tm, &inv.InvoiceBlindedPay, payInfo)
+	lnwire.SetOptFromMap(tm, &inv.InvoiceCreatedAt, createdAt)
+	lnwire.SetOptFromMap(tm, &inv.InvoiceRelativeExpiry, relExpiry)
+	lnwire.SetOptFromMap(tm, &inv.InvoicePaymentHash, payHash)
+	lnwire.SetOptFromMap(tm, &inv.InvoiceAmount, invAmount)
+	lnwire.SetOptFromMap(tm, &inv.InvoiceFallbacks, fallbacks)
+	lnwire.SetOptFromMap(tm, &inv.InvoiceFeatures, invFeatures)
+	lnwire.SetOptFromMap(tm, &inv.InvoiceNodeID, nodeID)
+	lnwire.SetOptFromMap(tm, &inv.Signature, sig)
+
+	inv.decodedTLVs = tm
+
+	return &inv, nil
+}
+
+// NewInvoiceFromRequest constructs an unsigned Invoice answering the given
+// invoice request. Every non-signature field of the request, including the
+// unknown signed-range TLVs it carried, is mirrored so the payer can match the
+// invoice against its request. The caller must sign the result with the key
+// behind nodeID.
+//
+// amount is the invoice_amount in msat. Per the writer rules it must equal
+// invreq_amount when the request carries one, which ValidateInvoiceWrite
+// enforces.
+func NewInvoiceFromRequest(ir *InvoiceRequest, nodeID *crypto.PublicKey,
+	paymentHash [32]byte, amount uint64, createdAt time.Time,
+	paths lnwire.BlindedPaths, payInfos []BlindedPayInfo) (*Invoice,
+	error) {
+
+	if nodeID == nil {
+		return nil, ErrMissingNodeID
+	}
+
+	inv := &Invoice{
+		InvreqMetadata:      ir.InvreqMetadata,
+		OfferChains:         ir.OfferChains,
+		OfferMetadata:       ir.OfferMetadata,
+		OfferCurrency:       ir.OfferCurrency,
+		OfferAmount:         ir.OfferAmount,
+		OfferDescription:    ir.OfferDescription,
+		OfferFeatures:       ir.OfferFeatures,
+		OfferAbsoluteExpiry: ir.OfferAbsoluteExpiry,
+		OfferPaths:          ir.OfferPaths,
+		OfferIssuer:         ir.OfferIssuer,
+		OfferQuantityMax:    ir.OfferQuantityMax,
+		OfferIssuerID:       ir.OfferIssuerID,
+		InvreqChain:         ir.InvreqChain,
+		InvreqAmount:        ir.InvreqAmount,
+		InvreqFeatures:      ir.InvreqFeatures,
+		InvreqQuantity:      ir.InvreqQuantity,
+		InvreqPayerID:       ir.InvreqPayerID,
+		InvreqPayerNote:     ir.InvreqPayerNote,
+		InvreqPaths:         ir.InvreqPaths,
+		InvreqBip353Name:    ir.InvreqBip353Name,
+
+		InvoicePaths: tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType160](paths),
+		),
+		InvoiceBlindedPay: tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType162](BlindedPayInfos{
+				PayInfos: payInfos,
+			}),
+		),
+		InvoiceCreatedAt: tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType164](
+				TUint64(createdAt.Unix()),
+			),
+		),
+		InvoicePaymentHash: tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType168](paymentHash),
+		),
+		InvoiceAmount: tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType170](TUint64(amount)),
+		),
+		InvoiceNodeID: tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType176](nodeID),
+		),
+	}
+
+	// Mirror the request's unknown signed-range TLVs. Signature-range
+	// entries belong to the request's own signature and must not leak
+	// into the invoice.
+	if len(ir.decodedTLVs) > 0 {
+		inv.decodedTLVs = make(tlv.TypeMap, len(ir.decodedTLVs))
+		for t, v := range ir.decodedTLVs {
+			if bolt12InUnsignedRange(t) {
+				continue
+			}
+			inv.decodedTLVs[t] = v
+		}
+	}
+
+	return inv, nil
+}
+
+// SignatureDigest returns the BIP-340 message that invoice_node_id signs: the
+// tagged hash of the invoice's Merkle root.
+func (inv *Invoice) SignatureDigest() ([32]byte, error) {
+	return SignatureDigest(invoiceMessageName, inv.AllRecords())
+}
+
+// MerkleRoot returns the Merkle root of the invoice's signed-range records.
+func (inv *Invoice) MerkleRoot() ([32]byte, error) {
+	return MerkleRoot(inv.AllRecords())
+}
+
+// SignatureTag returns the BIP-340 tag of invoice signatures. Signers that take
+// a message and a tag, like the wallet keyring, produce a valid signature when
+// given the Merkle root and this tag.
+func (inv *Invoice) SignatureTag() []byte {
+	return signatureTag(invoiceMessageName)
+}
+
+// Sign signs the invoice with the private key behind invoice_node_id and sets
+// the signature field.
+func (inv *Invoice) Sign(priv *crypto.PrivateKey) error {
+	nodeID, err := invoiceNodeID(inv)
+	if err != nil {
+		return err
+	}
+
+	if err := checkSigner(priv, nodeID); err != nil {
+		return err
+	}
+
+	digest, err := inv.SignatureDigest()
+	if err != nil {
+		return err
+	}
+
+	sig, err := signDigest(priv, digest)
+	if err != nil {
+		return err
+	}
+
+	inv.SetSignature(sig)
+
+	return nil
+}
+
+// SetSignature sets the signature field. It is used by signers that produce
+// the BIP-340 signature over SignatureDigest out of process (e.g. a remote
+// signer holding the node key).
+func (inv *Invoice) SetSignature(sig [64]byte) {
+	inv.Signature = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType240](sig),
+	)
+}
+
+// VerifySignature checks the invoice signature against invoice_node_id.
+func (inv *Invoice) VerifySignature() error {
+	nodeID, err := invoiceNodeID(inv)
+	if err != nil {
+		return err
+	}
+
+	sig, err := inv.Signature.ValOpt().UnwrapOrErr(ErrMissingSignature)
+	if err != nil {
+		return err
+	}
+
+	digest, err := inv.SignatureDigest()
+	if err != nil {
+		return err
+	}
+
+	return verifyDigest(sig, digest, nodeID)
+}
+
+// invoiceNodeID returns the non-nil invoice_node_id or an error.
+func invoiceNodeID(inv *Invoice) (*crypto.PublicKey, error) {
+	nodeID, err := inv.InvoiceNodeID.ValOpt().UnwrapOrErr(ErrMissingNodeID)
+	if err != nil {
+		return nil, err
+	}
+	if nodeID == nil {
+		return nil, fmt.Errorf("%w: invoice_node_id", ErrNilPublicKey)
+	}
+
+	return nodeID, nil
+}
@@ -300,3 +300,76 @@ func NewInvoiceRequestFromOffer(offer *Offer, payerID *crypto.PublicKey,
 
 	return ir, nil
 }
+
+// OfferID returns the offer_id of the offer this request was made for. It is
+// the Merkle root of the mirrored offer-range records, which equals Offer.ID of
+// the original offer as long as the payer copied every offer field unchanged.
+func (ir *InvoiceRequest) OfferID() ([32]byte, error) {
+	var offerRecords []tlv.Record
+	for _, r := range ir.AllRecords() {
+		if offerAllowedRange(r.Type()) {
+			offerRecords = append(offerRecords, r)
+		}
+	}
+
+	return MerkleRoot(offerRecords)
+}
+
+// SignatureDigest returns the BIP-340 message that invreq_payer_id signs: the
+// tagged hash of the request's Merkle root.
+func (ir *InvoiceRequest) SignatureDigest() ([32]byte, error) {
+	return SignatureDigest(invoiceRequestMessageName, ir.AllRecords())
+}
+
+// Sign signs the invoice request with the transient payer key and sets the
+// signature field. The key must be the one behind invreq_payer_id.
+func (ir *InvoiceRequest) Sign(priv *crypto.PrivateKey) error {
+	payerID, err := ir.InvreqPayerID.ValOpt().UnwrapOrErr(
+		ErrMissingPayerID,
+	)
+	if err != nil {
+		return err
+	}
+	if err := checkSigner(priv, payerID); err != nil {
+		return err
+	}
+
+	digest, err := ir.SignatureDigest()
+	if err != nil {
+		return err
+	}
+
+	sig, err := signDigest(priv, digest)
+	if err != nil {
+		return err
+	}
+
+	ir.Signature = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType240](sig),
+	)
+
+	return nil
+}
+
+// VerifySignature checks the invoice request signature against
+// invreq_payer_id.
+func (ir *InvoiceRequest) VerifySignature() error {
+	payerID, err := ir.InvreqPayerID.ValOpt().UnwrapOrErr(
+		ErrMissingPayerID,
+	)
+	if err != nil {
+		return err
+	}
+
+	sig, err := ir.Signature.ValOpt().UnwrapOrErr(ErrMissingSignature)
+	if err != nil {
+		return err
+	}
+
+	digest, err := ir.SignatureDigest()
+	if err != nil {
+		return err
+	}
+
+	return verifyDigest(sig, digest, payerID)
+}
@@ -151,7 +151,7 @@ func TestNewInvoiceRequestFromOfferMirrorsUnknownFields(t *testing.T) {
 	// sorted.
 	spliced := append(append([]byte{}, encoded...), extra.Bytes()...)
 
-	decodedOffer, err := decodeOffer(spliced)
+	decodedOffer, err := DecodeOffer(spliced)
 	require.NoError(t, err)
 
 	ir, err := NewInvoiceRequestFromOffer(
@@ -169,3 +169,38 @@ func TestNewInvoiceRequestFromOfferMirrorsUnknownFields(t *testing.T) {
 	}
 	require.True(t, found, "unknown offer TLV not mirrored into request")
 }
+
+// TestInvoiceRequestOfferID verifies that the offer_id recomputed from an
+// invoice request matches the offer it was built from, and that tampering with
+// a mirrored offer field breaks the match.
+func TestInvoiceRequestOfferID(t *testing.T) {
+	t.Parallel()
+
+	offer := validBobOffer(t)
+	addAmountAndDescription(offer)
+
+	offerID, err := offer.ID()
+	require.NoError(t, err)
+
+	_, payerID := aliceKey()
+	ir, err := NewInvoiceRequestFromOffer(
+		offer, payerID, []byte("metadata"),
+		flokicoinMainnetGenesisHash,
+	)
+	require.NoError(t, err)
+	ir.InvreqAmount = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType82, TUint64](2000),
+	)
+
+	// Request-only fields must not affect the offer_id.
+	reqOfferID, err := ir.OfferID()
+	require.NoError(t, err)
+	require.Equal(t, offerID, reqOfferID)
+
+	ir.OfferAmount = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType8, TUint64](1),
+	)
+	tamperedID, err := ir.OfferID()
+	require.NoError(t, err)
+	require.NotEqual(t, offerID, tamperedID)
+}
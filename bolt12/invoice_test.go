@@ -0,0 +1,267 @@
+package bolt12
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// testInvoiceCreatedAt is the creation time used by invoice fixtures. It is
+// fixed so expiry checks are deterministic.
+var testInvoiceCreatedAt = time.Unix(1_700_000_000, 0)
+
+// testBlindedPaths returns a single one-hop blinded path to the given node.
+func testBlindedPaths(t *testing.T,
+	node *crypto.PublicKey) lnwire.BlindedPaths {
+
+	t.Helper()
+
+	_, blinding := aliceKey()
+	intro, err := lnwire.NewPubkeyIntro(node)
+	require.NoError(t, err)
+
+	return lnwire.BlindedPaths{
+		Paths: []lnwire.BlindedPath{{
+			IntroductionNode: intro,
+			BlindingPoint:    blinding,
+			Hops: []lnwire.BlindedHop{{
+				BlindedNodeID: node,
+				EncryptedData: []byte{0x01, 0x02},
+			}},
+		}},
+	}
+}
+
+// testPayInfo is a representative blinded_payinfo with a feature bit set so
+// the flen-prefixed encoding is exercised.
+func testPayInfo() BlindedPayInfo {
+	return BlindedPayInfo{
+		FeeBaseMsat:               1000,
+		FeeProportionalMillionths: 100,
+		CltvExpiryDelta:           144,
+		HtlcMinimumMsat:           1,
+		HtlcMaximumMsat:           1_000_000_000,
+		Features:                  *lnwire.NewRawFeatureVector(9),
+	}
+}
+
+// validSignedRequest returns a signed invoice request answering Bob's offer.
+func validSignedRequest(t *testing.T) *InvoiceRequest {
+	t.Helper()
+
+	_, bobPub := bobKey()
+	alicePriv, alicePub := aliceKey()
+
+	offer := &Offer{
+		OfferAmount: tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType8](TUint64(1500)),
+		),
+		OfferDescription: tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType10](
+				tlv.Blob("coffee"),
+			),
+		),
+		OfferIssuerID: tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType22](bobPub),
+		),
+	}
+
+	ir, err := NewInvoiceRequestFromOffer(
+		offer, alicePub, []byte("payer-metadata"),
+		flokicoinMainnetGenesisHash,
+	)
+	require.NoError(t, err)
+	require.NoError(t, ir.Sign(alicePriv))
+
+	return ir
+}
+
+// validSignedInvoice returns Bob's signed invoice answering
+// validSignedRequest.
+func validSignedInvoice(t *testing.T) *Invoice {
+	t.Helper()
+
+	bobPriv, bobPub := bobKey()
+
+	inv, err := NewInvoiceFromRequest(
+		validSignedRequest(t), bobPub, [32]byte{0xaa}, 1500,
+		testInvoiceCreatedAt, testBlindedPaths(t, bobPub),
+		[]BlindedPayInfo{testPayInfo()},
+	)
+	require.NoError(t, err)
+	require.NoError(t, inv.Sign(bobPriv))
+
+	return inv
+}
+
+// TestInvoiceRoundTrip pins encode→decode→re-encode for a signed Invoice and
+// checks the signature survives the codec boundary.
+func TestInvoiceRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	inv := validSignedInvoice(t)
+
+	encoded, err := inv.Encode()
+	require.NoError(t, err)
+
+	decoded, err := DecodeInvoice(encoded)
+	require.NoError(t, err)
+
+	require.Equal(
+		t, [32]byte{0xaa}, decoded.InvoicePaymentHash.UnwrapOrFailV(t),
+	)
+	require.Equal(
+		t, TUint64(1500), decoded.InvoiceAmount.UnwrapOrFailV(t),
+	)
+	require.Equal(
+		t, []BlindedPayInfo{testPayInfo()},
+		decoded.InvoiceBlindedPay.UnwrapOrFailV(t).PayInfos,
+	)
+	require.NoError(t, decoded.VerifySignature())
+
+	reencoded, err := decoded.Encode()
+	require.NoError(t, err)
+	require.Equal(t, encoded, reencoded)
+}
+
+// TestInvoiceStringRoundTrip pins the lni string codec, including the
+// mandatory signature and the '+' continuation rule.
+func TestInvoiceStringRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	inv := validSignedInvoice(t)
+
+	s, err := inv.EncodeString()
+	require.NoError(t, err)
+	require.True(t, len(s) > 4 && s[:4] == InvoiceHRP+"1")
+
+	decoded, err := DecodeInvoiceString(s)
+	require.NoError(t, err)
+	require.NoError(t, ValidateInvoiceRead(
+		decoded, testInvoiceCreatedAt, flokicoinMainnetGenesisHash,
+	))
+
+	// A split string joined with '+' and whitespace decodes the same.
+	split := s[:20] + "+\n  " + s[20:]
+	joined, err := DecodeInvoiceString(split)
+	require.NoError(t, err)
+	require.Equal(t, mustEncodeInvoice(t, decoded), mustEncodeInvoice(t, joined))
+
+	// An lnr string is not an invoice.
+	_, err = DecodeInvoiceString("lnr1" + s[4:])
+	require.ErrorIs(t, err, ErrWrongHRP)
+
+	// The string form refuses an unsigned invoice.
+	inv.Signature = tlv.OptionalRecordT[tlv.TlvType240, [64]byte]{}
+	_, err = inv.EncodeString()
+	require.ErrorIs(t, err, ErrMissingSignature)
+}
+
+// TestNewInvoiceFromRequestMirrorsFields checks that every request field,
+// including unknown odd signed-range TLVs, is mirrored, and that the request's
+// own signature is not.
+func TestNewInvoiceFromRequestMirrorsFields(t *testing.T) {
+	t.Parallel()
+
+	ir := validSignedRequest(t)
+	ir.decodedTLVs = tlv.TypeMap{
+		1000000001:       []byte{0x07},
+		signatureTLVType: nil,
+	}
+
+	_, bobPub := bobKey()
+	inv, err := NewInvoiceFromRequest(
+		ir, bobPub, [32]byte{0xaa}, 1500, testInvoiceCreatedAt,
+		testBlindedPaths(t, bobPub), []BlindedPayInfo{testPayInfo()},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, ir.InvreqMetadata, inv.InvreqMetadata)
+	require.Equal(t, ir.InvreqPayerID, inv.InvreqPayerID)
+	require.Equal(t, ir.OfferAmount, inv.OfferAmount)
+	require.False(t, inv.Signature.IsSome())
+	require.Contains(t, inv.decodedTLVs, tlv.Type(1000000001))
+	require.NotContains(t, inv.decodedTLVs, signatureTLVType)
+
+	_, err = NewInvoiceFromRequest(
+		ir, nil, [32]byte{}, 1500, testInvoiceCreatedAt,
+		testBlindedPaths(t, bobPub), []BlindedPayInfo{testPayInfo()},
+	)
+	require.ErrorIs(t, err, ErrMissingNodeID)
+}
+
+// TestInvoiceSignature pins signing and verification: only the
+// invoice_node_id key may sign, and any mutation of a signed field invalidates
+// the signature.
+func TestInvoiceSignature(t *testing.T) {
+	t.Parallel()
+
+	inv := validSignedInvoice(t)
+	require.NoError(t, inv.VerifySignature())
+
+	// Re-signing with a key other than invoice_node_id is refused.
+	alicePriv, _ := aliceKey()
+	require.ErrorIs(t, inv.Sign(alicePriv), ErrSignerMismatch)
+
+	// Mutating a signed field breaks the signature.
+	inv.InvoiceAmount = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType170](TUint64(1)),
+	)
+	require.ErrorIs(t, inv.VerifySignature(), ErrInvalidSignature)
+
+	// Unsigned invoices fail verification.
+	inv.Signature = tlv.OptionalRecordT[tlv.TlvType240, [64]byte]{}
+	require.ErrorIs(t, inv.VerifySignature(), ErrMissingSignature)
+}
+
+// TestInvoiceRequestSignature pins invoice_request signing against the payer
+// key and verification after a codec round trip.
+func TestInvoiceRequestSignature(t *testing.T) {
+	t.Parallel()
+
+	ir := validSignedRequest(t)
+
+	encoded, err := ir.Encode()
+	require.NoError(t, err)
+
+	decoded, err := DecodeInvoiceRequest(encoded)
+	require.NoError(t, err)
+	require.NoError(t, decoded.VerifySignature())
+
+	bobPriv, _ := bobKey()
+	require.ErrorIs(t, decoded.Sign(bobPriv), ErrSignerMismatch)
+
+	s, err := decoded.EncodeString()
+	require.NoError(t, err)
+
+	fromString, err := DecodeInvoiceRequestString(s)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(
+		encoded, mustEncodeRequest(t, fromString),
+	))
+}
+
+// mustEncodeRequest encodes an invoice request or fails the test.
+func mustEncodeRequest(t *testing.T, ir *InvoiceRequest) []byte {
+	t.Helper()
+
+	b, err := ir.Encode()
+	require.NoError(t, err)
+
+	return b
+}
+
+// mustEncodeInvoice encodes an invoice or fails the test.
+func mustEncodeInvoice(t *testing.T, inv *Invoice) []byte {
+	t.Helper()
+
+	b, err := inv.Encode()
+	require.NoError(t, err)
+
+	return b
+}
@@ -0,0 +1,208 @@
+package bolt12
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr"
+)
+
+var (
+	// ErrInvalidSignature is returned when a BOLT 12 signature does not
+	// verify against the Merkle root of the message and the expected
+	// signing key.
+	ErrInvalidSignature = errors.New("invalid signature")
+
+	// ErrEmptyMerkleTree is returned when a Merkle root is requested for a
+	// message that has no signed-range TLV records.
+	ErrEmptyMerkleTree = errors.New("no signed TLV records")
+
+	// ErrSignerMismatch is returned when a message is signed with a key
+	// other than the one the message names as its signer.
+	ErrSignerMismatch = errors.New("signing key does not match " +
+		"message signer")
+)
+
+const (
+	// signatureFieldName is the field name mixed into the signature tag.
+	// BOLT 12 defines a single signature field for every signed message.
+	signatureFieldName = "signature"
+
+	// invoiceRequestMessageName is the message name mixed into the
+	// signature tag of an invoice_request.
+	invoiceRequestMessageName = "invoice_request"
+
+	// invoiceMessageName is the message name mixed into the signature tag
+	// of an invoice.
+	invoiceMessageName = "invoice"
+)
+
+var (
+	// leafTag is the tag for the H("LnLeaf", tlv) leaves.
+	leafTag = []byte("LnLeaf")
+
+	// nonceTagPrefix prefixes the first TLV record to form the tag of the
+	// H("LnNonce"||first-tlv, tlv-type) nonce leaves.
+	nonceTagPrefix = []byte("LnNonce")
+
+	// branchTag is the tag for the H("LnBranch", lesser||greater) inner
+	// nodes.
+	branchTag = []byte("LnBranch")
+)
+
+// MerkleRoot computes the BOLT 12 Merkle root over the signed-range records of
+// a message. Records in the signature range (240-1000) are skipped, every
+// other record contributes an LnLeaf leaf paired with an LnNonce leaf keyed on
+// the first record of the stream. Records must be supplied in ascending type
+// order, which is what AllRecords returns.
+func MerkleRoot(records []tlv.Record) ([32]byte, error) {
+	var (
+		firstTLV []byte
+		leaves   []chainhash.Hash
+		typeBuf  [8]byte
+	)
+	for _, r := range records {
+		if bolt12InUnsignedRange(r.Type()) {
+			continue
+		}
+
+		var rec bytes.Buffer
+		if err := lnwire.EncodeRecordsTo(
+			&rec, []tlv.Record{r},
+		); err != nil {
+			return [32]byte{}, fmt.Errorf("encode record %d: %w",
+				r.Type(), err)
+		}
+
+		var typ bytes.Buffer
+		err := tlv.WriteVarInt(&typ, uint64(r.Type()), &typeBuf)
+		if err != nil {
+			return [32]byte{}, err
+		}
+
+		// The nonce tag commits to the full first record so that
+		// sibling leaves cannot be brute-forced from the tree alone.
+		if firstTLV == nil {
+			firstTLV = append(
+				append([]byte{}, nonceTagPrefix...),
+				rec.Bytes()...,
+			)
+		}
+
+		leaves = append(
+			leaves,
+			*chainhash.TaggedHash(leafTag, rec.Bytes()),
+			*chainhash.TaggedHash(firstTLV, typ.Bytes()),
+		)
+	}
+
+	if len(leaves) == 0 {
+		return [32]byte{}, ErrEmptyMerkleTree
+	}
+
+	// Fold the leaves in place. At each level the node at i is combined
+	// with its sibling at i+offset; when the leaf count is not a power of
+	// two the unpaired right-most node is carried up unchanged, leaving
+	// the deepest subtrees on the lowest-order leaves.
+	for step := 2; step/2 < len(leaves); step *= 2 {
+		offset := step / 2
+		for i := 0; i+offset < len(leaves); i += step {
+			leaves[i] = branchHash(leaves[i], leaves[i+offset])
+		}
+	}
+
+	return leaves[0], nil
+}
+
+// branchHash returns H("LnBranch", lesser||greater) for two sibling nodes. The
+// ordering makes proofs compact as left/right is implied by the values.
+func branchHash(a, b chainhash.Hash) chainhash.Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+
+	return *chainhash.TaggedHash(branchTag, a[:], b[:])
+}
+
+// signatureTag returns the BOLT 12 signature tag for the named message, i.e.
+// "lightning" || messagename || fieldname.
+func signatureTag(messageName string) []byte {
+	return []byte("lightning" + messageName + signatureFieldName)
+}
+
+// SignatureDigest returns the 32-byte message that is signed with BIP-340 for
+// the named BOLT 12 message: H(tag, merkle-root) where tag is the signature tag
+// of the message.
+func SignatureDigest(messageName string, records []tlv.Record) ([32]byte,
+	error) {
+
+	root, err := MerkleRoot(records)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	return *chainhash.TaggedHash(signatureTag(messageName), root[:]), nil
+}
+
+// signDigest produces a BIP-340 signature over a signature digest.
+func signDigest(priv *crypto.PrivateKey, digest [32]byte) ([64]byte, error) {
+	var out [64]byte
+
+	sig, err := schnorr.Sign(priv, digest[:])
+	if err != nil {
+		return out, fmt.Errorf("schnorr sign: %w", err)
+	}
+	copy(out[:], sig.Serialize())
+
+	return out, nil
+}
+
+// checkSigner ensures priv is the secret key behind the x-only key of pub, the
+// only key a verifier will accept the signature under.
+func checkSigner(priv *crypto.PrivateKey, pub *crypto.PublicKey) error {
+	if pub == nil {
+		return ErrNilPublicKey
+	}
+
+	if !bytes.Equal(
+		schnorr.SerializePubKey(priv.PubKey()),
+		schnorr.SerializePubKey(pub),
+	) {
+
+		return ErrSignerMismatch
+	}
+
+	return nil
+}
+
+// verifyDigest checks a BIP-340 signature over a signature digest against the
+// given public key. The key is interpreted by its x-only coordinate as
+// required by BIP-340.
+func verifyDigest(sig [64]byte, digest [32]byte,
+	pub *crypto.PublicKey) error {
+
+	if pub == nil {
+		return ErrNilPublicKey
+	}
+
+	parsedSig, err := schnorr.ParseSignature(sig[:])
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	xOnly, err := schnorr.ParsePubKey(schnorr.SerializePubKey(pub))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+
+	if !parsedSig.Verify(digest[:], xOnly) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
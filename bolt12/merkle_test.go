@@ -0,0 +1,81 @@
+package bolt12
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/stretchr/testify/require"
+)
+
+// rawRecord builds a record that emits the given type and raw value bytes, so
+// the spec vectors can be expressed without a typed message.
+func rawRecord(t *testing.T, typ tlv.Type, valueHex string) tlv.Record {
+	t.Helper()
+
+	v, err := hex.DecodeString(valueHex)
+	require.NoError(t, err)
+
+	return tlv.MakePrimitiveRecord(typ, &v)
+}
+
+// TestMerkleRootSpecVectors pins MerkleRoot against the BOLT 12 signature test
+// vectors, covering a single leaf pair and uneven trees.
+func TestMerkleRootSpecVectors(t *testing.T) {
+	t.Parallel()
+
+	tlv1 := func(t *testing.T) tlv.Record {
+		return rawRecord(t, 1, "03e8")
+	}
+	tlv2 := func(t *testing.T) tlv.Record {
+		return rawRecord(t, 2, "0000010000020003")
+	}
+	tlv3 := func(t *testing.T) tlv.Record {
+		return rawRecord(
+			t, 3, "0266e4598d1d3c415f572a8488830b60f7e744ed9235eb"+
+				"0b1ba93283b315c035180000000000000001000000"+
+				"0000000002",
+		)
+	}
+
+	tests := []struct {
+		name    string
+		records func(t *testing.T) []tlv.Record
+		want    string
+	}{
+		{
+			name: "n1",
+			records: func(t *testing.T) []tlv.Record {
+				return []tlv.Record{tlv1(t)}
+			},
+			want: "b013756c8fee86503a0b4abdab4cddeb1af5d344ca6fc2fa" +
+				"8b6c08938caa6f93",
+		},
+		{
+			name: "n1 n2",
+			records: func(t *testing.T) []tlv.Record {
+				return []tlv.Record{tlv1(t), tlv2(t)}
+			},
+			want: "c3774abbf4815aa54ccaa026bff6581f01f3be5fe814c620" +
+				"a252534f434bc0d1",
+		},
+		{
+			name: "n1 n2 n3",
+			records: func(t *testing.T) []tlv.Record {
+				return []tlv.Record{tlv1(t), tlv2(t), tlv3(t)}
+			},
+			want: "ab2e79b1283b0b31e0b035258de23782df6b89a38cfa7237" +
+				"bde69aed1a658c5d",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			root, err := MerkleRoot(tc.records(t))
+			require.NoError(t, err)
+			require.Equal(t, tc.want, hex.EncodeToString(root[:]))
+		})
+	}
+}
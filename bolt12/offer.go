@@ -95,6 +95,13 @@ func (o *Offer) allRecordProducers() []tlv.RecordProducer {
 	return p
 }
 
+// ID returns the offer_id: the Merkle root of the offer's TLV records. An
+// invoice request mirrors every offer field, so the same root can be recomputed
+// from the request to find the offer it was made for.
+func (o *Offer) ID() ([32]byte, error) {
+	return MerkleRoot(o.AllRecords())
+}
+
 // Encode serialises the offer into a canonical TLV byte stream.
 func (o *Offer) Encode() ([]byte, error) {
 	if err := ValidateOfferWrite(o); err != nil {
@@ -109,12 +116,12 @@ func (o *Offer) Encode() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// decodeOffer parses a TLV byte stream into an Offer. Decoding is permissive —
+// DecodeOffer parses a TLV byte stream into an Offer. Decoding is permissive —
 // the spec writer requirements are not enforced here, so callers that need a
 // valid offer must run ValidateOfferRead. Unknown TLVs are preserved on the
 // returned offer so a later Encode can re-emit signed-range extras and keep
 // offer_id stable.
-func decodeOffer(data []byte) (*Offer, error) {
+func DecodeOffer(data []byte) (*Offer, error) {
 	var o Offer
 
 	// Prepare zero-valued records for all optional fields so the TLV
@@ -1,16 +1,32 @@
 package bolt12
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 
+	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/tlv"
 )
 
-// ErrTooManyChains is returned when offer_chains declares more entries than
-// maxOfferChains.
-var ErrTooManyChains = errors.New("offer_chains exceeds maxOfferChains")
+var (
+	// ErrTooManyChains is returned when offer_chains declares more
+	// entries than maxOfferChains.
+	ErrTooManyChains = errors.New("offer_chains exceeds maxOfferChains")
+
+	// ErrTooManyPayInfos is returned when invoice_blindedpay declares more
+	// entries than maxInvoiceSubtypes.
+	ErrTooManyPayInfos = errors.New(
+		"invoice_blindedpay exceeds maxInvoiceSubtypes",
+	)
+
+	// ErrTooManyFallbacks is returned when invoice_fallbacks declares more
+	// entries than maxInvoiceSubtypes.
+	ErrTooManyFallbacks = errors.New(
+		"invoice_fallbacks exceeds maxInvoiceSubtypes",
+	)
+)
 
 const (
 	// chainHashLen is the length of a chain hash (32 bytes).
@@ -20,6 +36,21 @@ const (
 	// check to prevent excessive memory allocation and is not a protocol
 	// limit but a local implementation choice.
 	maxOfferChains = 32
+
+	// blindedPayInfoFixedLen is the length of a blinded_payinfo entry
+	// without its feature bytes: fee_base_msat (4),
+	// fee_proportional_millionths (4), cltv_expiry_delta (2),
+	// htlc_minimum_msat (8), htlc_maximum_msat (8) and flen (2).
+	blindedPayInfoFixedLen = 28
+
+	// fallbackAddressFixedLen is the length of a fallback_address entry
+	// without its address bytes: version (1) and len (2).
+	fallbackAddressFixedLen = 3
+
+	// maxInvoiceSubtypes caps decoded invoice_blindedpay and
+	// invoice_fallbacks entries. Like maxOfferChains this is a local
+	// allocation bound rather than a protocol limit.
+	maxInvoiceSubtypes = 64
 )
 
 // ChainsRecord holds one or more chain hashes for the offer_chains field.
@@ -85,3 +116,239 @@ func decodeChainsRecord(r io.Reader, val any, _ *[8]byte, l uint64) error {
 
 	return nil
 }
+
+// BlindedPayInfo is the BOLT 12 blinded_payinfo subtype describing the
+// aggregate forwarding policy of one blinded payment path.
+type BlindedPayInfo struct {
+	// FeeBaseMsat is the aggregate base fee of the path.
+	FeeBaseMsat uint32
+
+	// FeeProportionalMillionths is the aggregate proportional fee of the
+	// path.
+	FeeProportionalMillionths uint32
+
+	// CltvExpiryDelta is the aggregate CLTV delta of the path.
+	CltvExpiryDelta uint16
+
+	// HtlcMinimumMsat is the smallest HTLC the path will carry.
+	HtlcMinimumMsat uint64
+
+	// HtlcMaximumMsat is the largest HTLC the path will carry.
+	HtlcMaximumMsat uint64
+
+	// Features is the feature bit vector of the path.
+	Features lnwire.RawFeatureVector
+}
+
+// BlindedPayInfos holds one blinded_payinfo per invoice_paths entry.
+type BlindedPayInfos struct {
+	PayInfos []BlindedPayInfo
+}
+
+var _ tlv.RecordProducer = (*BlindedPayInfos)(nil)
+
+// Record returns a TLV record for BlindedPayInfos.
+func (b *BlindedPayInfos) Record() tlv.Record {
+	return tlv.MakeDynamicRecord(
+		0, b,
+		func() uint64 {
+			var size uint64
+			for i := range b.PayInfos {
+				size += blindedPayInfoFixedLen + uint64(
+					b.PayInfos[i].Features.SerializeSize(),
+				)
+			}
+
+			return size
+		},
+		encodeBlindedPayInfos,
+		decodeBlindedPayInfos,
+	)
+}
+
+// encodeBlindedPayInfos writes the pay infos in sequence, without a count
+// prefix.
+func encodeBlindedPayInfos(w io.Writer, val any, buf *[8]byte) error {
+	b, ok := val.(*BlindedPayInfos)
+	if !ok {
+		return fmt.Errorf("expected *BlindedPayInfos, got %T", val)
+	}
+
+	for i := range b.PayInfos {
+		p := &b.PayInfos[i]
+
+		var fixed [blindedPayInfoFixedLen]byte
+		binary.BigEndian.PutUint32(fixed[0:4], p.FeeBaseMsat)
+		binary.BigEndian.PutUint32(
+			fixed[4:8], p.FeeProportionalMillionths,
+		)
+		binary.BigEndian.PutUint16(fixed[8:10], p.CltvExpiryDelta)
+		binary.BigEndian.PutUint64(fixed[10:18], p.HtlcMinimumMsat)
+		binary.BigEndian.PutUint64(fixed[18:26], p.HtlcMaximumMsat)
+		binary.BigEndian.PutUint16(
+			fixed[26:28], uint16(p.Features.SerializeSize()),
+		)
+		if _, err := w.Write(fixed[:]); err != nil {
+			return err
+		}
+
+		if err := p.Features.EncodeBase256(w); err != nil {
+			return fmt.Errorf("pay info %d features: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// decodeBlindedPayInfos reads concatenated pay infos, capping the count at
+// maxInvoiceSubtypes to bound allocation.
+func decodeBlindedPayInfos(r io.Reader, val any, _ *[8]byte, l uint64) error {
+	b, ok := val.(*BlindedPayInfos)
+	if !ok {
+		return fmt.Errorf("expected *BlindedPayInfos, got %T", val)
+	}
+
+	lr := &io.LimitedReader{R: r, N: int64(l)}
+	for lr.N > 0 {
+		if len(b.PayInfos) >= maxInvoiceSubtypes {
+			return fmt.Errorf("%w: > %d", ErrTooManyPayInfos,
+				maxInvoiceSubtypes)
+		}
+
+		var fixed [blindedPayInfoFixedLen]byte
+		if _, err := io.ReadFull(lr, fixed[:]); err != nil {
+			return fmt.Errorf("read pay info: %w", err)
+		}
+
+		p := BlindedPayInfo{
+			FeeBaseMsat: binary.BigEndian.Uint32(fixed[0:4]),
+			FeeProportionalMillionths: binary.BigEndian.Uint32(
+				fixed[4:8],
+			),
+			CltvExpiryDelta: binary.BigEndian.Uint16(fixed[8:10]),
+			HtlcMinimumMsat: binary.BigEndian.Uint64(fixed[10:18]),
+			HtlcMaximumMsat: binary.BigEndian.Uint64(fixed[18:26]),
+		}
+
+		flen := binary.BigEndian.Uint16(fixed[26:28])
+		if int64(flen) > lr.N {
+			return fmt.Errorf("flen %d exceeds remaining %d", flen,
+				lr.N)
+		}
+
+		fv := lnwire.NewRawFeatureVector()
+		if err := fv.DecodeBase256(lr, int(flen)); err != nil {
+			return fmt.Errorf("read pay info features: %w", err)
+		}
+		p.Features = *fv
+
+		b.PayInfos = append(b.PayInfos, p)
+	}
+
+	return nil
+}
+
+// FallbackAddress is the BOLT 12 fallback_address subtype: an on-chain segwit
+// address the payer may use if it cannot pay over lightning.
+type FallbackAddress struct {
+	// Version is the segwit witness version.
+	Version uint8
+
+	// Address is the witness program.
+	Address []byte
+}
+
+// FallbackAddresses holds the invoice_fallbacks entries.
+type FallbackAddresses struct {
+	Addresses []FallbackAddress
+}
+
+var _ tlv.RecordProducer = (*FallbackAddresses)(nil)
+
+// Record returns a TLV record for FallbackAddresses.
+func (f *FallbackAddresses) Record() tlv.Record {
+	return tlv.MakeDynamicRecord(
+		0, f,
+		func() uint64 {
+			var size uint64
+			for i := range f.Addresses {
+				size += fallbackAddressFixedLen +
+					uint64(len(f.Addresses[i].Address))
+			}
+
+			return size
+		},
+		encodeFallbackAddresses,
+		decodeFallbackAddresses,
+	)
+}
+
+// encodeFallbackAddresses writes the fallback addresses in sequence, without a
+// count prefix.
+func encodeFallbackAddresses(w io.Writer, val any, _ *[8]byte) error {
+	f, ok := val.(*FallbackAddresses)
+	if !ok {
+		return fmt.Errorf("expected *FallbackAddresses, got %T", val)
+	}
+
+	for _, addr := range f.Addresses {
+		if len(addr.Address) > 0xffff {
+			return fmt.Errorf("fallback address length %d too "+
+				"long", len(addr.Address))
+		}
+
+		var fixed [fallbackAddressFixedLen]byte
+		fixed[0] = addr.Version
+		binary.BigEndian.PutUint16(fixed[1:3], uint16(len(addr.Address)))
+		if _, err := w.Write(fixed[:]); err != nil {
+			return err
+		}
+		if _, err := w.Write(addr.Address); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeFallbackAddresses reads concatenated fallback addresses, capping the
+// count at maxInvoiceSubtypes to bound allocation.
+func decodeFallbackAddresses(r io.Reader, val any, _ *[8]byte,
+	l uint64) error {
+
+	f, ok := val.(*FallbackAddresses)
+	if !ok {
+		return fmt.Errorf("expected *FallbackAddresses, got %T", val)
+	}
+
+	lr := &io.LimitedReader{R: r, N: int64(l)}
+	for lr.N > 0 {
+		if len(f.Addresses) >= maxInvoiceSubtypes {
+			return fmt.Errorf("%w: > %d", ErrTooManyFallbacks,
+				maxInvoiceSubtypes)
+		}
+
+		var fixed [fallbackAddressFixedLen]byte
+		if _, err := io.ReadFull(lr, fixed[:]); err != nil {
+			return fmt.Errorf("read fallback address: %w", err)
+		}
+
+		addrLen := binary.BigEndian.Uint16(fixed[1:3])
+		if int64(addrLen) > lr.N {
+			return fmt.Errorf("address len %d exceeds remaining %d",
+				addrLen, lr.N)
+		}
+
+		addr := FallbackAddress{
+			Version: fixed[0],
+			Address: make([]byte, addrLen),
+		}
+		if _, err := io.ReadFull(lr, addr.Address); err != nil {
+			return fmt.Errorf("read fallback address: %w", err)
+		}
+
+		f.Addresses = append(f.Addresses, addr)
+	}
+
+	return nil
+}
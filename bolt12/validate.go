@@ -1,6 +1,7 @@
 package bolt12
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/bits"
@@ -455,11 +456,8 @@ func getInvreqChain(ir *InvoiceRequest) [32]byte {
 // Stateful or contextual checks (offer matching, path verification, unit-price
 // calculations) must be handled externally by the caller.
 //
-// Signature verification is NOT performed yet: the reader MUST also reject a
-// request whose Schnorr signature does not verify against invreq_payer_id, but
-// that check is deferred until the merkle/signing primitives land with the
-// Invoice message (see the TODO at the end of this function). Until then, a
-// caller wiring this into a handler MUST verify the signature itself.
+// The Schnorr signature is verified last against invreq_payer_id, so the
+// cheaper structural rejections are reported first.
 func ValidateInvoiceRequestRead(ir *InvoiceRequest,
 	activeChain [32]byte) error {
 
@@ -612,9 +610,8 @@ func ValidateInvoiceRequestRead(ir *InvoiceRequest,
 
 	// - MUST reject the invoice request if signature is not correct as
 	//   detailed in Signature Calculation using the invreq_payer_id.
-	// TODO(bolt12): implement signature verification.
-	if !ir.Signature.IsSome() {
-		return ErrMissingSignature
+	if err := ir.VerifySignature(); err != nil {
+		return err
 	}
 
 	return nil
@@ -1114,3 +1111,477 @@ func checkPubKeyNotNil[T tlv.TlvType](
 		return nil
 	})
 }
+
+var (
+	// ErrMissingCreatedAt is returned when invoice_created_at is absent.
+	ErrMissingCreatedAt = errors.New("missing invoice_created_at")
+
+	// ErrMissingPaymentHash is returned when invoice_payment_hash is
+	// absent.
+	ErrMissingPaymentHash = errors.New("missing invoice_payment_hash")
+
+	// ErrMissingInvoiceAmount is returned when invoice_amount is absent.
+	ErrMissingInvoiceAmount = errors.New("missing invoice_amount")
+
+	// ErrInvoiceAmountMismatch is returned when invreq_amount is present
+	// but invoice_amount differs from it.
+	ErrInvoiceAmountMismatch = errors.New(
+		"invoice_amount does not equal invreq_amount",
+	)
+
+	// ErrMissingInvoicePaths is returned when invoice_paths is absent.
+	ErrMissingInvoicePaths = errors.New("missing invoice_paths")
+
+	// ErrBlindedPayMismatch is returned when invoice_blindedpay does not
+	// hold exactly one entry per invoice_paths entry.
+	ErrBlindedPayMismatch = errors.New(
+		"invoice_blindedpay count does not match invoice_paths",
+	)
+
+	// ErrNodeIDMismatch is returned when invoice_node_id is not the key
+	// the mirrored offer designates as the invoice signer.
+	ErrNodeIDMismatch = errors.New(
+		"invoice_node_id does not match the offer",
+	)
+
+	// ErrInvalidFallback is returned when an invoice_fallbacks entry has a
+	// witness version or program length the writer MUST NOT emit.
+	ErrInvalidFallback = errors.New("invalid invoice_fallbacks entry")
+
+	// ErrInvoiceExpired is returned when the current time is after
+	// invoice_created_at plus the relative expiry.
+	ErrInvoiceExpired = errors.New("invoice has expired")
+
+	// ErrInvoiceRequestMismatch is returned when the fields an invoice
+	// mirrors from the invoice request differ from the request we sent.
+	ErrInvoiceRequestMismatch = errors.New(
+		"invoice does not match the invoice request",
+	)
+)
+
+const (
+	// Invoice TLV types.
+	invoicePathsType       tlv.Type = 160
+	invoiceBlindedPayType  tlv.Type = 162
+	invoiceCreatedAtType   tlv.Type = 164
+	invoiceRelExpiryType   tlv.Type = 166
+	invoicePaymentHashType tlv.Type = 168
+	invoiceAmountType      tlv.Type = 170
+	invoiceFallbacksType   tlv.Type = 172
+	invoiceFeaturesType    tlv.Type = 174
+	invoiceNodeIDType      tlv.Type = 176
+
+	// DefaultInvoiceExpiry is the relative expiry implied when
+	// invoice_relative_expiry is absent.
+	DefaultInvoiceExpiry = 7200 * time.Second
+
+	// maxFallbackVersion is the highest segwit version a fallback address
+	// may carry.
+	maxFallbackVersion = 16
+
+	// minFallbackAddrLen and maxFallbackAddrLen bound the witness program
+	// length of a fallback address.
+	minFallbackAddrLen = 2
+	maxFallbackAddrLen = 40
+)
+
+// isKnownInvoiceTLVType determines if a TLV type is defined in the invoice
+// specification, including the fields mirrored from the invoice request.
+func isKnownInvoiceTLVType(typ tlv.Type) bool {
+	switch typ {
+	case invoicePathsType,
+		invoiceBlindedPayType,
+		invoiceCreatedAtType,
+		invoiceRelExpiryType,
+		invoicePaymentHashType,
+		invoiceAmountType,
+		invoiceFallbacksType,
+		invoiceFeaturesType,
+		invoiceNodeIDType:
+
+		return true
+
+	default:
+		return isKnownInvreqTLVType(typ)
+	}
+}
+
+// invoiceAllowedRange determines if the TLV type falls within the allowed
+// ranges for invoice messages: 0-239 and 1000000000-3999999999.
+func invoiceAllowedRange(typ tlv.Type) bool {
+	return typ <= 239 ||
+		(typ >= 1000000000 && typ <= 3999999999)
+}
+
+// ValidateInvoiceWrite ensures an invoice adheres to the BOLT 12 writer
+// requirements.
+//
+// Note: like ValidateInvoiceRequestWrite, this assumes the mirrored request
+// fields were copied exactly, which NewInvoiceFromRequest guarantees.
+func ValidateInvoiceWrite(inv *Invoice) error {
+	// A present-but-nil pubkey passes IsSome but would panic the codec on
+	// encode, so reject every pubkey field.
+	if err := checkInvoicePubKeys(inv); err != nil {
+		return err
+	}
+
+	// - MUST NOT set any non-signature TLV fields outside the inclusive
+	//   ranges: 0 to 159, 160 to 239 and 1000000000 to 3999999999.
+	for _, t := range sortedTypes(inv.decodedTLVs) {
+		if bolt12InUnsignedRange(t) {
+			continue
+		}
+		if !invoiceAllowedRange(t) {
+			return fmt.Errorf("%w: type %d",
+				ErrOutOfRangeType, t)
+		}
+	}
+
+	// - MUST set invoice_created_at to the number of seconds since
+	//   Midnight 1 January 1970, UTC when the invoice was created.
+	if !inv.InvoiceCreatedAt.IsSome() {
+		return ErrMissingCreatedAt
+	}
+
+	// - MUST set invoice_payment_hash to the SHA256 hash of the
+	//   payment_preimage that will be given in return for payment.
+	if !inv.InvoicePaymentHash.IsSome() {
+		return ErrMissingPaymentHash
+	}
+
+	// - if invreq_amount is present: MUST set invoice_amount to
+	//   invreq_amount.
+	// - otherwise: MUST set invoice_amount to the expected amount.
+	// NOT CHECKED HERE: the expected amount for an offer_currency offer
+	// needs an exchange rate.
+	if err := checkInvoiceAmount(inv); err != nil {
+		return err
+	}
+
+	// - MUST include invoice_paths containing one or more paths to the
+	//   node, and invoice_blindedpay with exactly one blinded_payinfo for
+	//   each path.
+	if err := checkInvoicePaths(inv); err != nil {
+		return err
+	}
+
+	// - MUST set invoice_node_id: to offer_issuer_id if present, otherwise
+	//   to the final blinded_node_id of one of the offer_paths.
+	if err := checkInvoiceNodeID(inv); err != nil {
+		return err
+	}
+
+	// - if it supports bolt12 invoice features: MUST set
+	//   invoice_features.features to the bitmap of features.
+	if err := checkFeatures(inv.InvoiceFeatures); err != nil {
+		return err
+	}
+
+	// - for the bitcoin chain, if it sets invoice_fallbacks: MUST set
+	//   version to 16 or less and address between 2 and 40 bytes.
+	if err := checkInvoiceFallbacks(inv); err != nil {
+		return err
+	}
+
+	// - MUST specify signature.sig using the invoice_node_id.
+	// NOT CHECKED HERE: signing happens after this validator runs; the
+	// string encoder rejects an unsigned invoice and the reader verifies
+	// signature correctness.
+
+	return nil
+}
+
+// ValidateInvoiceRead validates an invoice against the BOLT 12 reader
+// requirements. The now parameter is used for the expiry check and activeChain
+// is the genesis hash of the chain we are willing to pay on.
+//
+// Stateful checks are left to the caller: that the mirrored fields equal the
+// invoice request we sent, that invoice_node_id matches the offer path the
+// request travelled when offer_issuer_id is absent, and the expected amount
+// for an offer_currency offer.
+func ValidateInvoiceRead(inv *Invoice, now time.Time,
+	activeChain [32]byte) error {
+
+	if err := checkInvoicePubKeys(inv); err != nil {
+		return err
+	}
+
+	// - MUST reject the invoice if any non-signature TLV fields are outside
+	//   the inclusive ranges: 0 to 159, 160 to 239 and 1000000000 to
+	//   3999999999. Unknown even types are rejected everywhere.
+	for _, t := range sortedTypes(inv.decodedTLVs) {
+		if !bolt12InUnsignedRange(t) && !invoiceAllowedRange(t) {
+			return fmt.Errorf("%w: type %d",
+				ErrOutOfRangeType, t)
+		}
+		if !isKnownInvoiceTLVType(t) && t%2 == 0 {
+			return fmt.Errorf("%w: type %d",
+				ErrUnknownEvenType, t)
+		}
+	}
+
+	// - MUST reject the invoice if invoice_amount, invoice_created_at,
+	//   invoice_payment_hash or invoice_node_id are not present.
+	if !inv.InvoiceAmount.IsSome() {
+		return ErrMissingInvoiceAmount
+	}
+	if !inv.InvoiceCreatedAt.IsSome() {
+		return ErrMissingCreatedAt
+	}
+	if !inv.InvoicePaymentHash.IsSome() {
+		return ErrMissingPaymentHash
+	}
+	if !inv.InvoiceNodeID.IsSome() {
+		return ErrMissingNodeID
+	}
+
+	// - MUST reject the invoice if invoice_paths is not present or is
+	//   empty, if num_hops is 0 in any path, or if invoice_blindedpay
+	//   does not contain exactly one blinded_payinfo per path.
+	if err := checkInvoicePaths(inv); err != nil {
+		return err
+	}
+
+	// - if invoice_features contains unknown even bits that are non-zero:
+	//   MUST reject the invoice.
+	if err := checkFeatures(inv.InvoiceFeatures); err != nil {
+		return err
+	}
+
+	// - if offer_issuer_id is present: MUST reject the invoice if
+	//   invoice_node_id is not equal to offer_issuer_id.
+	if err := checkInvoiceNodeID(inv); err != nil {
+		return err
+	}
+
+	// - if invreq_amount is present: MUST reject the invoice if
+	//   invoice_amount is not equal to invreq_amount.
+	if err := checkInvoiceAmount(inv); err != nil {
+		return err
+	}
+
+	// - if invreq_chain is not present: MUST reject the invoice if
+	//   bitcoin is not a supported chain; otherwise MUST reject it if
+	//   invreq_chain is not a supported chain.
+	chain := flokicoinMainnetGenesisHash
+	inv.InvreqChain.WhenSome(
+		func(r tlv.RecordT[tlv.TlvType80, [32]byte]) {
+			chain = r.Val
+		},
+	)
+	if chain != activeChain {
+		return ErrUnsupportedChain
+	}
+
+	// - if the current time is after invoice_created_at plus
+	//   invoice_relative_expiry (or 7200 seconds if absent): MUST reject
+	//   the invoice.
+	if now.After(InvoiceExpiry(inv)) {
+		return ErrInvoiceExpired
+	}
+
+	// - MUST reject the invoice if signature is not a valid signature
+	//   using invoice_node_id as described in Signature Calculation.
+	if err := inv.VerifySignature(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CheckInvoiceMatchesRequest covers the first of the stateful checks left out
+// of ValidateInvoiceRead: the invoice must mirror every non-signature field of
+// the invoice request it answers, including unknown ones, and add no other
+// field in the invoice request range.
+func CheckInvoiceMatchesRequest(inv *Invoice, ir *InvoiceRequest) error {
+	invRecords, err := encodeInvreqRange(inv.AllRecords())
+	if err != nil {
+		return err
+	}
+
+	reqRecords, err := encodeInvreqRange(ir.AllRecords())
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(invRecords, reqRecords) {
+		return ErrInvoiceRequestMismatch
+	}
+
+	return nil
+}
+
+// encodeInvreqRange encodes the records that fall in the invoice request range
+// as a single TLV stream.
+func encodeInvreqRange(records []tlv.Record) ([]byte, error) {
+	var invreqRecords []tlv.Record
+	for _, r := range records {
+		if invreqAllowedRange(r.Type()) {
+			invreqRecords = append(invreqRecords, r)
+		}
+	}
+
+	stream, err := tlv.NewStream(invreqRecords...)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err := stream.Encode(&b); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// InvoiceExpiry returns the absolute time after which the invoice must not be
+// paid: invoice_created_at plus invoice_relative_expiry, which defaults to
+// DefaultInvoiceExpiry when absent.
+func InvoiceExpiry(inv *Invoice) time.Time {
+	var createdAt int64
+	inv.InvoiceCreatedAt.WhenSome(
+		func(r tlv.RecordT[tlv.TlvType164, TUint64]) {
+			createdAt = int64(r.Val)
+		},
+	)
+
+	expiry := DefaultInvoiceExpiry
+	inv.InvoiceRelativeExpiry.WhenSome(
+		func(r tlv.RecordT[tlv.TlvType166, TUint32]) {
+			expiry = time.Duration(r.Val) * time.Second
+		},
+	)
+
+	return time.Unix(createdAt, 0).Add(expiry)
+}
+
+// checkInvoicePubKeys rejects present-but-nil public key fields.
+func checkInvoicePubKeys(inv *Invoice) error {
+	if err := checkPubKeyNotNil(
+		inv.InvreqPayerID, "invreq_payer_id",
+	); err != nil {
+		return err
+	}
+	if err := checkPubKeyNotNil(
+		inv.OfferIssuerID, "offer_issuer_id",
+	); err != nil {
+		return err
+	}
+
+	return checkPubKeyNotNil(inv.InvoiceNodeID, "invoice_node_id")
+}
+
+// checkInvoiceAmount requires invoice_amount and, when the mirrored request
+// carries invreq_amount, that the two are equal.
+func checkInvoiceAmount(inv *Invoice) error {
+	amt, err := inv.InvoiceAmount.ValOpt().UnwrapOrErr(
+		ErrMissingInvoiceAmount,
+	)
+	if err != nil {
+		return err
+	}
+
+	return fn.MapOptionZ(
+		inv.InvreqAmount.ValOpt(), func(reqAmt TUint64) error {
+			if reqAmt != amt {
+				return fmt.Errorf("%w: invoice_amount %d, "+
+					"invreq_amount %d",
+					ErrInvoiceAmountMismatch, amt, reqAmt)
+			}
+
+			return nil
+		},
+	)
+}
+
+// checkInvoicePaths requires a non-empty invoice_paths whose paths all have
+// hops, paired with exactly one blinded_payinfo per path.
+func checkInvoicePaths(inv *Invoice) error {
+	if !inv.InvoicePaths.IsSome() {
+		return ErrMissingInvoicePaths
+	}
+	if err := checkBlindedPaths(inv.InvoicePaths); err != nil {
+		return err
+	}
+
+	numPaths := fn.MapOptionZ(
+		inv.InvoicePaths.ValOpt(), func(p lnwire.BlindedPaths) int {
+			return len(p.Paths)
+		},
+	)
+	numPayInfos := fn.MapOptionZ(
+		inv.InvoiceBlindedPay.ValOpt(), func(p BlindedPayInfos) int {
+			return len(p.PayInfos)
+		},
+	)
+	if numPaths != numPayInfos {
+		return fmt.Errorf("%w: %d paths, %d pay infos",
+			ErrBlindedPayMismatch, numPaths, numPayInfos)
+	}
+
+	return nil
+}
+
+// checkInvoiceNodeID requires invoice_node_id and enforces the offer binding:
+// equal to offer_issuer_id when present, otherwise equal to the final blinded
+// node of one of the offer_paths.
+func checkInvoiceNodeID(inv *Invoice) error {
+	nodeID, err := invoiceNodeID(inv)
+	if err != nil {
+		return err
+	}
+
+	if inv.OfferIssuerID.IsSome() {
+		issuerID := inv.OfferIssuerID.ValOpt().UnsafeFromSome()
+		if !issuerID.IsEqual(nodeID) {
+			return ErrNodeIDMismatch
+		}
+
+		return nil
+	}
+
+	if !inv.OfferPaths.IsSome() {
+		return nil
+	}
+
+	paths := inv.OfferPaths.ValOpt().UnsafeFromSome()
+	for _, p := range paths.Paths {
+		if len(p.Hops) == 0 {
+			continue
+		}
+
+		final := p.Hops[len(p.Hops)-1].BlindedNodeID
+		if final != nil && final.IsEqual(nodeID) {
+			return nil
+		}
+	}
+
+	return ErrNodeIDMismatch
+}
+
+// checkInvoiceFallbacks enforces the writer bounds on each fallback address.
+func checkInvoiceFallbacks(inv *Invoice) error {
+	return fn.MapOptionZ(
+		inv.InvoiceFallbacks.ValOpt(),
+		func(f FallbackAddresses) error {
+			for i, addr := range f.Addresses {
+				if addr.Version > maxFallbackVersion {
+					return fmt.Errorf("%w: entry %d "+
+						"version %d", ErrInvalidFallback,
+						i, addr.Version)
+				}
+
+				l := len(addr.Address)
+				if l < minFallbackAddrLen ||
+					l > maxFallbackAddrLen {
+
+					return fmt.Errorf("%w: entry %d "+
+						"length %d", ErrInvalidFallback,
+						i, l)
+				}
+			}
+
+			return nil
+		},
+	)
+}
@@ -694,9 +694,7 @@ func validInvoiceRequest(t *testing.T) *InvoiceRequest {
 		tlv.NewRecordT[tlv.TlvType82, TUint64](1000),
 	)
 
-	ir.Signature = tlv.SomeRecordT(
-		tlv.NewPrimitiveRecord[tlv.TlvType240]([64]byte{0x01}),
-	)
+	require.NoError(t, ir.Sign(privKey))
 
 	return ir
 }
@@ -1249,6 +1247,28 @@ func TestValidateInvoiceRequestReadSentinels(t *testing.T) {
 			},
 			wantErr: ErrMissingSignature,
 		},
+		{
+			name: "signature does not verify",
+			mutate: func(ir *InvoiceRequest) {
+				ir.Signature = tlv.SomeRecordT(
+					tlv.NewPrimitiveRecord[tlv.TlvType240](
+						[64]byte{0x01},
+					),
+				)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "signed field mutated after signing",
+			mutate: func(ir *InvoiceRequest) {
+				ir.InvreqAmount = tlv.SomeRecordT(
+					tlv.NewRecordT[tlv.TlvType82](
+						TUint64(2000),
+					),
+				)
+			},
+			wantErr: ErrInvalidSignature,
+		},
 		{
 			name: "missing amount",
 			mutate: func(ir *InvoiceRequest) {
@@ -1680,3 +1700,324 @@ func TestCheckBip353Name(t *testing.T) {
 		})
 	}
 }
+
+// TestValidateInvoiceWrite pins the BOLT 12 invoice writer-side MUSTs the codec
+// can enforce.
+func TestValidateInvoiceWrite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		mutate  func(*Invoice)
+		wantErr error
+	}{
+		{
+			name:    "happy path",
+			mutate:  func(*Invoice) {},
+			wantErr: nil,
+		},
+		{
+			name: "missing created_at",
+			mutate: func(inv *Invoice) {
+				inv.InvoiceCreatedAt = tlv.OptionalRecordT[
+					tlv.TlvType164, TUint64,
+				]{}
+			},
+			wantErr: ErrMissingCreatedAt,
+		},
+		{
+			name: "missing payment hash",
+			mutate: func(inv *Invoice) {
+				inv.InvoicePaymentHash = tlv.OptionalRecordT[
+					tlv.TlvType168, [32]byte,
+				]{}
+			},
+			wantErr: ErrMissingPaymentHash,
+		},
+		{
+			name: "amount differs from invreq_amount",
+			mutate: func(inv *Invoice) {
+				inv.InvreqAmount = tlv.SomeRecordT(
+					tlv.NewRecordT[tlv.TlvType82](
+						TUint64(2000),
+					),
+				)
+			},
+			wantErr: ErrInvoiceAmountMismatch,
+		},
+		{
+			name: "missing invoice paths",
+			mutate: func(inv *Invoice) {
+				inv.InvoicePaths = tlv.OptionalRecordT[
+					tlv.TlvType160, lnwire.BlindedPaths,
+				]{}
+			},
+			wantErr: ErrMissingInvoicePaths,
+		},
+		{
+			name: "blindedpay count mismatch",
+			mutate: func(inv *Invoice) {
+				inv.InvoiceBlindedPay = tlv.SomeRecordT(
+					tlv.NewRecordT[tlv.TlvType162](
+						BlindedPayInfos{},
+					),
+				)
+			},
+			wantErr: ErrBlindedPayMismatch,
+		},
+		{
+			name: "node id differs from offer_issuer_id",
+			mutate: func(inv *Invoice) {
+				_, alicePub := aliceKey()
+				inv.InvoiceNodeID = tlv.SomeRecordT(
+					tlv.NewPrimitiveRecord[tlv.TlvType176](
+						alicePub,
+					),
+				)
+			},
+			wantErr: ErrNodeIDMismatch,
+		},
+		{
+			name: "fallback version too high",
+			mutate: func(inv *Invoice) {
+				inv.InvoiceFallbacks = tlv.SomeRecordT(
+					tlv.NewRecordT[tlv.TlvType172](
+						FallbackAddresses{
+							Addresses: []FallbackAddress{{
+								Version: 17,
+								Address: make(
+									[]byte, 20,
+								),
+							}},
+						},
+					),
+				)
+			},
+			wantErr: ErrInvalidFallback,
+		},
+		{
+			name: "out-of-range TLV in decoded extras",
+			mutate: func(inv *Invoice) {
+				inv.decodedTLVs = tlv.TypeMap{4000000000: nil}
+			},
+			wantErr: ErrOutOfRangeType,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			inv := validSignedInvoice(t)
+			tc.mutate(inv)
+
+			err := ValidateInvoiceWrite(inv)
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+// TestValidateInvoiceRead pins the BOLT 12 invoice reader-side rejections,
+// including expiry and signature verification.
+func TestValidateInvoiceRead(t *testing.T) {
+	t.Parallel()
+
+	var altChain [32]byte
+	for i := range altChain {
+		altChain[i] = 0xaa
+	}
+
+	tests := []struct {
+		name        string
+		mutate      func(*Invoice)
+		now         time.Time
+		activeChain [32]byte
+		wantErr     error
+	}{
+		{
+			name:    "happy path",
+			mutate:  func(*Invoice) {},
+			wantErr: nil,
+		},
+		{
+			name: "missing amount",
+			mutate: func(inv *Invoice) {
+				inv.InvoiceAmount = tlv.OptionalRecordT[
+					tlv.TlvType170, TUint64,
+				]{}
+			},
+			wantErr: ErrMissingInvoiceAmount,
+		},
+		{
+			name: "missing node id",
+			mutate: func(inv *Invoice) {
+				inv.InvoiceNodeID = tlv.OptionalRecordT[
+					tlv.TlvType176, *crypto.PublicKey,
+				]{}
+			},
+			wantErr: ErrMissingNodeID,
+		},
+		{
+			name: "present-but-nil node id",
+			mutate: func(inv *Invoice) {
+				inv.InvoiceNodeID = tlv.SomeRecordT(
+					tlv.NewPrimitiveRecord[tlv.TlvType176](
+						(*crypto.PublicKey)(nil),
+					),
+				)
+			},
+			wantErr: ErrNilPublicKey,
+		},
+		{
+			name: "unknown even TLV type in invoice range",
+			mutate: func(inv *Invoice) {
+				inv.decodedTLVs = tlv.TypeMap{200: nil}
+			},
+			wantErr: ErrUnknownEvenType,
+		},
+		{
+			name: "unknown even invoice feature",
+			mutate: func(inv *Invoice) {
+				inv.InvoiceFeatures = tlv.SomeRecordT(
+					tlv.NewRecordT[tlv.TlvType174](
+						*lnwire.NewRawFeatureVector(0),
+					),
+				)
+			},
+			wantErr: ErrUnknownEvenFeature,
+		},
+		{
+			name:        "unsupported chain",
+			mutate:      func(*Invoice) {},
+			activeChain: altChain,
+			wantErr:     ErrUnsupportedChain,
+		},
+		{
+			name:   "expired with default expiry",
+			mutate: func(*Invoice) {},
+			now: testInvoiceCreatedAt.Add(
+				DefaultInvoiceExpiry + time.Second,
+			),
+			wantErr: ErrInvoiceExpired,
+		},
+		{
+			name: "tampered after signing",
+			mutate: func(inv *Invoice) {
+				inv.InvoicePaymentHash = tlv.SomeRecordT(
+					tlv.NewPrimitiveRecord[tlv.TlvType168](
+						[32]byte{0xbb},
+					),
+				)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "missing signature",
+			mutate: func(inv *Invoice) {
+				inv.Signature = tlv.OptionalRecordT[
+					tlv.TlvType240, [64]byte,
+				]{}
+			},
+			wantErr: ErrMissingSignature,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			inv := validSignedInvoice(t)
+			tc.mutate(inv)
+
+			now := tc.now
+			if now.IsZero() {
+				now = testInvoiceCreatedAt
+			}
+			chain := tc.activeChain
+			if chain == ([32]byte{}) {
+				chain = flokicoinMainnetGenesisHash
+			}
+
+			err := ValidateInvoiceRead(inv, now, chain)
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
+
+// TestCheckInvoiceMatchesRequest checks that an invoice is only accepted for
+// the exact invoice request it mirrors.
+func TestCheckInvoiceMatchesRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		mutate  func(*Invoice)
+		wantErr error
+	}{
+		{
+			name:    "mirrors request",
+			mutate:  func(*Invoice) {},
+			wantErr: nil,
+		},
+		{
+			name: "changed payer note",
+			mutate: func(inv *Invoice) {
+				inv.InvreqPayerNote = tlv.SomeRecordT(
+					tlv.NewPrimitiveRecord[tlv.TlvType89](
+						tlv.Blob("tip"),
+					),
+				)
+			},
+			wantErr: ErrInvoiceRequestMismatch,
+		},
+		{
+			name: "dropped payer metadata",
+			mutate: func(inv *Invoice) {
+				inv.InvreqMetadata = tlv.OptionalRecordT[
+					tlv.TlvType0, tlv.Blob,
+				]{}
+			},
+			wantErr: ErrInvoiceRequestMismatch,
+		},
+		{
+			name: "changed offer amount",
+			mutate: func(inv *Invoice) {
+				inv.OfferAmount = tlv.SomeRecordT(
+					tlv.NewRecordT[tlv.TlvType8](
+						TUint64(1),
+					),
+				)
+			},
+			wantErr: ErrInvoiceRequestMismatch,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			inv := validSignedInvoice(t)
+			tc.mutate(inv)
+
+			err := CheckInvoiceMatchesRequest(
+				inv, validSignedRequest(t),
+			)
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+
+				return
+			}
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package electrumnotify
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/chainntnfs"
+	"github.com/flokiorg/flnd/electrumio"
+)
+
+// createNewNotifier creates a new instance of the ChainNotifier interface
+// implemented by ElectrumNotifier.
+func createNewNotifier(args ...interface{}) (chainntnfs.ChainNotifier, error) {
+	if len(args) != 3 {
+		return nil, fmt.Errorf("incorrect number of arguments to "+
+			".New(...), expected 3, instead passed %v", len(args))
+	}
+
+	backend, ok := args[0].(*electrumio.Backend)
+	if !ok {
+		return nil, errors.New("first argument to electrumnotify.New " +
+			"is incorrect, expected a *electrumio.Backend")
+	}
+
+	spendHintCache, ok := args[1].(chainntnfs.SpendHintCache)
+	if !ok {
+		return nil, errors.New("second argument to electrumnotify.New " +
+			"is incorrect, expected a chainntnfs.SpendHintCache")
+	}
+
+	confirmHintCache, ok := args[2].(chainntnfs.ConfirmHintCache)
+	if !ok {
+		return nil, errors.New("third argument to electrumnotify.New " +
+			"is incorrect, expected a chainntnfs.ConfirmHintCache")
+	}
+
+	return New(backend, spendHintCache, confirmHintCache), nil
+}
+
+// init registers a driver for the ElectrumNotifier concrete implementation of
+// the chainntnfs.ChainNotifier interface.
+func init() {
+	// Register the driver.
+	notifier := &chainntnfs.NotifierDriver{
+		NotifierType: notifierType,
+		New:          createNewNotifier,
+	}
+
+	if err := chainntnfs.RegisterNotifier(notifier); err != nil {
+		panic(fmt.Sprintf("failed to register notifier driver '%s': %v",
+			notifierType, err))
+	}
+}
@@ -0,0 +1,60 @@
+package esploranotify
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flokiorg/flnd/chainntnfs"
+	"github.com/flokiorg/flnd/esploraio"
+)
+
+// createNewNotifier creates a new instance of the ChainNotifier interface
+// implemented by EsploraNotifier.
+func createNewNotifier(args ...interface{}) (chainntnfs.ChainNotifier, error) {
+	if len(args) != 4 {
+		return nil, fmt.Errorf("incorrect number of arguments to "+
+			".New(...), expected 4, instead passed %v", len(args))
+	}
+
+	backend, ok := args[0].(*esploraio.Backend)
+	if !ok {
+		return nil, errors.New("first argument to esploranotify.New " +
+			"is incorrect, expected a *esploraio.Backend")
+	}
+
+	pollInterval, ok := args[1].(time.Duration)
+	if !ok {
+		return nil, errors.New("second argument to esploranotify.New " +
+			"is incorrect, expected a time.Duration")
+	}
+
+	spendHintCache, ok := args[2].(chainntnfs.SpendHintCache)
+	if !ok {
+		return nil, errors.New("third argument to esploranotify.New " +
+			"is incorrect, expected a chainntnfs.SpendHintCache")
+	}
+
+	confirmHintCache, ok := args[3].(chainntnfs.ConfirmHintCache)
+	if !ok {
+		return nil, errors.New("fourth argument to esploranotify.New " +
+			"is incorrect, expected a chainntnfs.ConfirmHintCache")
+	}
+
+	return New(backend, pollInterval, spendHintCache, confirmHintCache), nil
+}
+
+// init registers a driver for the EsploraNotifier concrete implementation of
+// the chainntnfs.ChainNotifier interface.
+func init() {
+	// Register the driver.
+	notifier := &chainntnfs.NotifierDriver{
+		NotifierType: notifierType,
+		New:          createNewNotifier,
+	}
+
+	if err := chainntnfs.RegisterNotifier(notifier); err != nil {
+		panic(fmt.Sprintf("failed to register notifier driver '%s': %v",
+			notifierType, err))
+	}
+}
@@ -0,0 +1,797 @@
+package esploranotify
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flokiorg/flnd/chainntnfs"
+	"github.com/flokiorg/flnd/esploraio"
+	"github.com/flokiorg/flnd/queue"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+const (
+	// notifierType uniquely identifies this concrete implementation of the
+	// ChainNotifier interface.
+	notifierType = "esplora"
+)
+
+// EsploraNotifier implements the ChainNotifier interface using an Esplora
+// HTTP API. The API is polled for new blocks, whose headers must be part of the
+// validated most-work header chain of the backend and extend our chain, and
+// whose contents are verified against their header before they're filtered
+// for our requests.
+// Historical lookups are served by the API's address and output spend
+// indexes. Multiple concurrent clients are supported. All notifications are
+// achieved via non-blocking sends on client channels.
+type EsploraNotifier struct {
+	epochClientCounter uint64 // To be used atomically.
+
+	start   sync.Once
+	active  int32 // To be used atomically.
+	stopped int32 // To be used atomically.
+
+	backend *esploraio.Backend
+
+	// pollInterval is how often we poll the API for its chain tip.
+	pollInterval time.Duration
+
+	notificationCancels  chan interface{}
+	notificationRegistry chan interface{}
+
+	txNotifier *chainntnfs.TxNotifier
+
+	blockEpochClients map[uint64]*blockEpochRegistration
+
+	bestBlock chainntnfs.BlockEpoch
+
+	// connectedBlocks holds the blocks we've connected that are still
+	// within the reorg safety limit, by height. These make up the header
+	// chain we verify new blocks against, and let us find out which of
+	// our blocks were disconnected by a reorg.
+	connectedBlocks map[int32]chainntnfs.BlockEpoch
+
+	// spendHintCache is a cache used to query and update the latest height
+	// hints for an outpoint. Each height hint represents the earliest
+	// height at which the outpoint could have been spent within the chain.
+	spendHintCache chainntnfs.SpendHintCache
+
+	// confirmHintCache is a cache used to query the latest height hints for
+	// a transaction. Each height hint represents the earliest height at
+	// which the transaction could have confirmed within the chain.
+	confirmHintCache chainntnfs.ConfirmHintCache
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// Ensure EsploraNotifier implements the ChainNotifier interface at compile
+// time.
+var _ chainntnfs.ChainNotifier = (*EsploraNotifier)(nil)
+
+// New returns a new EsploraNotifier instance, which polls the Esplora API
+// behind the passed backend for new blocks at the given interval.
+func New(backend *esploraio.Backend, pollInterval time.Duration,
+	spendHintCache chainntnfs.SpendHintCache,
+	confirmHintCache chainntnfs.ConfirmHintCache) *EsploraNotifier {
+
+	return &EsploraNotifier{
+		backend:      backend,
+		pollInterval: pollInterval,
+
+		notificationCancels:  make(chan interface{}),
+		notificationRegistry: make(chan interface{}),
+
+		blockEpochClients: make(map[uint64]*blockEpochRegistration),
+		connectedBlocks:   make(map[int32]chainntnfs.BlockEpoch),
+
+		spendHintCache:   spendHintCache,
+		confirmHintCache: confirmHintCache,
+
+		quit: make(chan struct{}),
+	}
+}
+
+// Start fetches the current chain tip and launches all related helper
+// goroutines.
+func (e *EsploraNotifier) Start() error {
+	var startErr error
+	e.start.Do(func() {
+		startErr = e.startNotifier()
+	})
+
+	return startErr
+}
+
+// Started returns true if this instance has been started, and false otherwise.
+func (e *EsploraNotifier) Started() bool {
+	return atomic.LoadInt32(&e.active) != 0
+}
+
+// Stop shuts down the EsploraNotifier.
+func (e *EsploraNotifier) Stop() error {
+	// Already shutting down?
+	if atomic.AddInt32(&e.stopped, 1) != 1 {
+		return nil
+	}
+
+	chainntnfs.Log.Info("esplora notifier shutting down...")
+	defer chainntnfs.Log.Debug("esplora notifier shutdown complete")
+
+	close(e.quit)
+	e.wg.Wait()
+
+	// Notify all pending clients of our shutdown by closing the related
+	// notification channels.
+	for _, epochClient := range e.blockEpochClients {
+		close(epochClient.cancelChan)
+		epochClient.wg.Wait()
+
+		close(epochClient.epochChan)
+	}
+
+	// The tx notifier is only created once we've started.
+	if e.txNotifier != nil {
+		e.txNotifier.TearDown()
+	}
+
+	return nil
+}
+
+// startNotifier is the main starting point for the EsploraNotifier. It fetches
+// the current chain tip and starts the main dispatcher goroutine.
+func (e *EsploraNotifier) startNotifier() error {
+	chainntnfs.Log.Infof("esplora notifier starting...")
+
+	bestHash, bestHeight, err := e.backend.BestBlock()
+	if err != nil {
+		return err
+	}
+	bestHeader, err := e.backend.BlockHeader(bestHash)
+	if err != nil {
+		return err
+	}
+
+	e.txNotifier = chainntnfs.NewTxNotifier(
+		uint32(bestHeight), chainntnfs.ReorgSafetyLimit,
+		e.confirmHintCache, e.spendHintCache,
+	)
+
+	e.bestBlock = chainntnfs.BlockEpoch{
+		Height:      bestHeight,
+		Hash:        bestHash,
+		BlockHeader: bestHeader,
+	}
+	e.connectedBlocks[bestHeight] = e.bestBlock
+
+	e.wg.Add(1)
+	go e.notificationDispatcher()
+
+	// Set the active flag now that we've completed the full startup.
+	atomic.StoreInt32(&e.active, 1)
+
+	chainntnfs.Log.Debugf("esplora notifier started")
+
+	return nil
+}
+
+// notificationDispatcher is the primary goroutine which handles client
+// notification registrations, as well as notification dispatches.
+//
+// NOTE: This MUST be run as a goroutine.
+func (e *EsploraNotifier) notificationDispatcher() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case cancelMsg := <-e.notificationCancels:
+			switch msg := cancelMsg.(type) {
+			case *epochCancel:
+				chainntnfs.Log.Infof("Cancelling epoch "+
+					"notification, epoch_id=%v", msg.epochID)
+
+				// First, we'll lookup the original
+				// registration in order to stop the active
+				// queue goroutine.
+				reg := e.blockEpochClients[msg.epochID]
+				reg.epochQueue.Stop()
+
+				// Next, close the cancel channel for this
+				// specific client, and wait for the client to
+				// exit.
+				close(reg.cancelChan)
+				reg.wg.Wait()
+
+				// Once the client has exited, we can then
+				// safely close the channel used to send epoch
+				// notifications, in order to notify any
+				// listeners that the intent has been
+				// canceled.
+				close(reg.epochChan)
+				delete(e.blockEpochClients, msg.epochID)
+			}
+
+		case registerMsg := <-e.notificationRegistry:
+			switch msg := registerMsg.(type) {
+			case *blockEpochRegistration:
+				chainntnfs.Log.Infof("New block epoch subscription")
+
+				e.blockEpochClients[msg.epochID] = msg
+
+				// If the client did not provide their best
+				// known block, then we'll immediately dispatch
+				// a notification for the current tip.
+				if msg.bestBlock == nil {
+					e.notifyBlockEpochClient(
+						msg, e.bestBlock.Height,
+						e.bestBlock.Hash,
+						e.bestBlock.BlockHeader,
+					)
+
+					msg.errorChan <- nil
+					continue
+				}
+
+				// Otherwise, we'll attempt to deliver the
+				// backlog of notifications from their best
+				// known block.
+				missedBlocks, err := e.missedBlocks(
+					msg.bestBlock.Height,
+				)
+				if err != nil {
+					msg.errorChan <- err
+					continue
+				}
+
+				for _, block := range missedBlocks {
+					e.notifyBlockEpochClient(
+						msg, block.Height, block.Hash,
+						block.BlockHeader,
+					)
+				}
+
+				msg.errorChan <- nil
+			}
+
+		case <-ticker.C:
+			e.syncBestBlock()
+
+		case <-e.quit:
+			return
+		}
+	}
+}
+
+// syncBestBlock syncs the notifier with the tip of the backend's header chain,
+// after syncing it with the Esplora API.
+func (e *EsploraNotifier) syncBestBlock() {
+	_, bestHeight, err := e.backend.BestBlock()
+	if err != nil {
+		chainntnfs.Log.Errorf("Unable to get best block: %v", err)
+		return
+	}
+
+	if err := e.syncChain(bestHeight); err != nil {
+		chainntnfs.Log.Errorf("Unable to sync to block at height %d: %v",
+			bestHeight, err)
+	}
+}
+
+// syncChain brings the notifier in line with the main chain of the backend's
+// header chain, whose tip is at the given height. Any of our blocks that are
+// no longer part of the main chain are disconnected first, after which the
+// main chain's blocks above our new best block are connected one at a time.
+func (e *EsploraNotifier) syncChain(tipHeight int32) error {
+	for e.bestBlock.Height > 0 {
+		if e.bestBlock.Height <= tipHeight {
+			hash, err := e.backend.BlockHash(e.bestBlock.Height)
+			if err != nil {
+				return err
+			}
+
+			if *hash == *e.bestBlock.Hash {
+				break
+			}
+		}
+
+		if err := e.disconnectTip(); err != nil {
+			return err
+		}
+	}
+
+	for height := e.bestBlock.Height + 1; height <= tipHeight; height++ {
+		// The header is taken from the backend's header chain, whose
+		// main chain is linked and validated as a whole.
+		hash, header, err := e.backend.BlockHeaderByHeight(height)
+		if err != nil {
+			return err
+		}
+
+		// If the block doesn't extend our tip, the header chain
+		// switched to another branch while we were syncing. We'll
+		// catch up with it on our next sync.
+		if header.PrevBlock != *e.bestBlock.Hash {
+			return fmt.Errorf("block %v at height %d doesn't "+
+				"extend our tip %v", hash, height,
+				e.bestBlock.Hash)
+		}
+
+		err = e.handleBlockConnected(chainntnfs.BlockEpoch{
+			Height:      height,
+			Hash:        hash,
+			BlockHeader: header,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// disconnectTip disconnects our best block, which is no longer part of the
+// main chain.
+func (e *EsploraNotifier) disconnectTip() error {
+	height := e.bestBlock.Height
+
+	chainntnfs.Log.Infof("Block disconnected from main chain: height=%v, "+
+		"sha=%v", height, e.bestBlock.Hash)
+
+	err := e.txNotifier.DisconnectTip(uint32(height))
+	if err != nil {
+		return fmt.Errorf("unable to disconnect tip for height=%d: %w",
+			height, err)
+	}
+	delete(e.connectedBlocks, height)
+
+	// If we didn't connect the previous block ourselves, as happens when
+	// the block we started out with is reorged out, we'll look it up by
+	// the hash our block commits to, as the main chain's block at that
+	// height may be part of the new branch already.
+	prevBlock, ok := e.connectedBlocks[height-1]
+	if !ok {
+		prevHash := e.bestBlock.BlockHeader.PrevBlock
+		header, err := e.backend.BlockHeader(&prevHash)
+		if err != nil {
+			return err
+		}
+
+		prevBlock = chainntnfs.BlockEpoch{
+			Height:      height - 1,
+			Hash:        &prevHash,
+			BlockHeader: header,
+		}
+	}
+	e.bestBlock = prevBlock
+
+	return nil
+}
+
+// handleBlockConnected applies a chain update for a new block. Any watched
+// transactions included this block will processed to either send
+// notifications now or after numConfirmations confs.
+func (e *EsploraNotifier) handleBlockConnected(
+	epoch chainntnfs.BlockEpoch) error {
+
+	// The block is verified against its header before it's returned.
+	rawBlock, err := e.backend.GetBlock(epoch.Hash)
+	if err != nil {
+		return fmt.Errorf("unable to get block: %w", err)
+	}
+	block := chainutil.NewBlock(rawBlock)
+
+	// We'll then extend the txNotifier's height with the information of
+	// this new block, which will handle all of the notification logic for
+	// us.
+	err = e.txNotifier.ConnectTip(block, uint32(epoch.Height))
+	if err != nil {
+		return fmt.Errorf("unable to connect tip: %w", err)
+	}
+
+	chainntnfs.Log.Infof("New block: height=%v, sha=%v", epoch.Height,
+		epoch.Hash)
+
+	// Now that we've guaranteed the new block extends the txNotifier's
+	// current tip, we'll proceed to dispatch notifications to all of our
+	// registered clients whom have had notifications fulfilled. Before
+	// doing so, we'll make sure update our in memory state in order to
+	// satisfy any client requests based upon the new block.
+	e.bestBlock = epoch
+	e.connectedBlocks[epoch.Height] = epoch
+	delete(e.connectedBlocks, epoch.Height-chainntnfs.ReorgSafetyLimit)
+
+	err = e.txNotifier.NotifyHeight(uint32(epoch.Height))
+	if err != nil {
+		return fmt.Errorf("unable to notify height: %w", err)
+	}
+
+	e.notifyBlockEpochs(epoch.Height, epoch.Hash, epoch.BlockHeader)
+
+	return nil
+}
+
+// missedBlocks returns the blocks of the main chain above the given height, up
+// to our best block.
+func (e *EsploraNotifier) missedBlocks(
+	height int32) ([]chainntnfs.BlockEpoch, error) {
+
+	var missedBlocks []chainntnfs.BlockEpoch
+	for h := height + 1; h <= e.bestBlock.Height; h++ {
+		if block, ok := e.connectedBlocks[h]; ok {
+			missedBlocks = append(missedBlocks, block)
+			continue
+		}
+
+		hash, header, err := e.backend.BlockHeaderByHeight(h)
+		if err != nil {
+			return nil, err
+		}
+
+		missedBlocks = append(missedBlocks, chainntnfs.BlockEpoch{
+			Height:      h,
+			Hash:        hash,
+			BlockHeader: header,
+		})
+	}
+
+	return missedBlocks, nil
+}
+
+// notifyBlockEpochs notifies all registered block epoch clients of the newly
+// connected block to the main chain.
+func (e *EsploraNotifier) notifyBlockEpochs(newHeight int32,
+	newSha *chainhash.Hash, blockHeader *wire.BlockHeader) {
+
+	for _, client := range e.blockEpochClients {
+		e.notifyBlockEpochClient(client, newHeight, newSha, blockHeader)
+	}
+}
+
+// notifyBlockEpochClient sends a registered block epoch client a notification
+// about a specific block.
+func (e *EsploraNotifier) notifyBlockEpochClient(
+	epochClient *blockEpochRegistration, height int32,
+	sha *chainhash.Hash, blockHeader *wire.BlockHeader) {
+
+	epoch := &chainntnfs.BlockEpoch{
+		Height:      height,
+		Hash:        sha,
+		BlockHeader: blockHeader,
+	}
+
+	select {
+	case epochClient.epochQueue.ChanIn() <- epoch:
+	case <-epochClient.cancelChan:
+	case <-e.quit:
+	}
+}
+
+// historicalConfDetails looks up whether a confirmation request (txid/output
+// script) has already been included in a block in the active chain and, if so,
+// returns details about said block.
+func (e *EsploraNotifier) historicalConfDetails(
+	confRequest chainntnfs.ConfRequest, startHeight,
+	endHeight uint32) (*chainntnfs.TxConfirmation, error) {
+
+	txs, err := e.backend.ScriptTxs(
+		confRequest.PkScript.Script(), startHeight, endHeight,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tx := range txs {
+		if !confRequest.MatchesTx(tx.Tx) {
+			continue
+		}
+
+		blockHash := tx.BlockHash
+
+		return &chainntnfs.TxConfirmation{
+			Tx:          tx.Tx,
+			BlockHash:   &blockHash,
+			BlockHeight: tx.BlockHeight,
+			TxIndex:     tx.TxIndex,
+			Block:       tx.Block,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// historicalSpendDetails looks up whether a spend request (outpoint/output
+// script) has already been spent by a transaction in the active chain and, if
+// so, returns details about the spend.
+func (e *EsploraNotifier) historicalSpendDetails(
+	spendRequest chainntnfs.SpendRequest, pkScript []byte, startHeight,
+	endHeight uint32) (*chainntnfs.SpendDetail, error) {
+
+	// Spends of outpoints can be looked up directly, while spends of
+	// output scripts require going through the script's history.
+	var txs []*esploraio.BlockTx
+	if spendRequest.OutPoint != chainntnfs.ZeroOutPoint {
+		tx, _, err := e.backend.SpendingTx(&spendRequest.OutPoint)
+		if err != nil {
+			return nil, err
+		}
+		if tx != nil {
+			txs = append(txs, tx)
+		}
+	} else {
+		var err error
+		txs, err = e.backend.ScriptTxs(pkScript, startHeight, endHeight)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, tx := range txs {
+		if tx.BlockHeight < startHeight || tx.BlockHeight > endHeight {
+			continue
+		}
+
+		matches, inputIndex, err := spendRequest.MatchesTx(tx.Tx)
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			continue
+		}
+
+		spenderHash := tx.Tx.TxHash()
+
+		return &chainntnfs.SpendDetail{
+			SpentOutPoint:     &tx.Tx.TxIn[inputIndex].PreviousOutPoint,
+			SpenderTxHash:     &spenderHash,
+			SpendingTx:        tx.Tx,
+			SpenderInputIndex: inputIndex,
+			SpendingHeight:    int32(tx.BlockHeight),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// RegisterSpendNtfn registers an intent to be notified once the target
+// outpoint/output script has been spent by a transaction on-chain. When
+// intending to be notified of the spend of an output script, a nil outpoint
+// must be used. The heightHint should represent the earliest height in the
+// chain of the transaction that spent the outpoint/output script.
+//
+// Once a spend of has been detected, the details of the spending event will be
+// sent across the 'Spend' channel.
+func (e *EsploraNotifier) RegisterSpendNtfn(outpoint *wire.OutPoint,
+	pkScript []byte, heightHint uint32) (*chainntnfs.SpendEvent, error) {
+
+	// Register the spend notification with the TxNotifier. A non-nil
+	// value for `dispatch` will be returned if we are required to perform
+	// a manual scan for the spend. Otherwise the notifier will begin
+	// watching at tip for the outpoint/output script to be spent.
+	ntfn, err := e.txNotifier.RegisterSpend(outpoint, pkScript, heightHint)
+	if err != nil {
+		return nil, err
+	}
+
+	if ntfn.HistoricalDispatch == nil {
+		return ntfn.Event, nil
+	}
+
+	// Otherwise, we'll look up whether the outpoint/output script was
+	// already spent. We'll do this in a goroutine to avoid
+	// blocking the caller.
+	//
+	// TODO: add retry logic if the lookup fails?
+	e.wg.Add(1)
+	go func(dispatch *chainntnfs.HistoricalSpendDispatch) {
+		defer e.wg.Done()
+
+		spendDetails, err := e.historicalSpendDetails(
+			dispatch.SpendRequest, pkScript,
+			dispatch.StartHeight, dispatch.EndHeight,
+		)
+		if err != nil {
+			chainntnfs.Log.Errorf("Unable to look up spend "+
+				"details of %v: %v", dispatch.SpendRequest, err)
+			return
+		}
+
+		// No matter whether we found a spend or not, we'll let the
+		// txNotifier know the historical lookup is complete, so it can
+		// begin updating its spend hint at tip.
+		err = e.txNotifier.UpdateSpendDetails(
+			dispatch.SpendRequest, spendDetails,
+		)
+		if err != nil {
+			chainntnfs.Log.Errorf("Unable to update spend "+
+				"details of %v: %v", dispatch.SpendRequest, err)
+		}
+	}(ntfn.HistoricalDispatch)
+
+	return ntfn.Event, nil
+}
+
+// RegisterConfirmationsNtfn registers an intent to be notified once the target
+// txid/output script has reached numConfs confirmations on-chain. When
+// intending to be notified of the confirmation of an output script, a nil txid
+// must be used. The heightHint should represent the earliest height at which
+// the txid/output script could have been included in the chain.
+//
+// Progress on the number of confirmations left can be read from the 'Updates'
+// channel. Once it has reached all of its confirmations, a notification will be
+// sent across the 'Confirmed' channel.
+func (e *EsploraNotifier) RegisterConfirmationsNtfn(txid *chainhash.Hash,
+	pkScript []byte, numConfs, heightHint uint32,
+	opts ...chainntnfs.NotifierOption) (*chainntnfs.ConfirmationEvent, error) {
+
+	// Register the conf notification with the TxNotifier. A non-nil value
+	// for `dispatch` will be returned if we are required to perform a
+	// manual scan for the confirmation. Otherwise the notifier will begin
+	// watching at tip for the transaction to confirm.
+	ntfn, err := e.txNotifier.RegisterConf(
+		txid, pkScript, numConfs, heightHint, opts...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if ntfn.HistoricalDispatch == nil {
+		return ntfn.Event, nil
+	}
+
+	// Look up whether the transaction/output script has already confirmed
+	// in the active chain. We'll do this in a goroutine to avoid blocking
+	// the caller.
+	//
+	// TODO: add retry logic if the lookup fails?
+	e.wg.Add(1)
+	go func(dispatch *chainntnfs.HistoricalConfDispatch) {
+		defer e.wg.Done()
+
+		confDetails, err := e.historicalConfDetails(
+			dispatch.ConfRequest, dispatch.StartHeight,
+			dispatch.EndHeight,
+		)
+		if err != nil {
+			chainntnfs.Log.Errorf("Unable to look up confirmation "+
+				"details of %v: %v", dispatch.ConfRequest, err)
+			return
+		}
+
+		// If the historical lookup finished without error, we will
+		// invoke UpdateConfDetails even if none were found. This
+		// allows the notifier to begin safely updating the height hint
+		// cache at tip, since any pending rescans have now completed.
+		err = e.txNotifier.UpdateConfDetails(
+			dispatch.ConfRequest, confDetails,
+		)
+		if err != nil {
+			chainntnfs.Log.Errorf("Unable to update confirmation "+
+				"details of %v: %v", dispatch.ConfRequest, err)
+		}
+	}(ntfn.HistoricalDispatch)
+
+	return ntfn.Event, nil
+}
+
+// blockEpochRegistration represents a client's intent to receive a
+// notification with each newly connected block.
+type blockEpochRegistration struct {
+	epochID uint64
+
+	epochChan chan *chainntnfs.BlockEpoch
+
+	epochQueue *queue.ConcurrentQueue
+
+	bestBlock *chainntnfs.BlockEpoch
+
+	errorChan chan error
+
+	cancelChan chan struct{}
+
+	wg sync.WaitGroup
+}
+
+// epochCancel is a message sent to the EsploraNotifier when a client wishes
+// to cancel an outstanding epoch notification that has yet to be dispatched.
+type epochCancel struct {
+	epochID uint64
+}
+
+// RegisterBlockEpochNtfn returns a BlockEpochEvent which subscribes the
+// caller to receive notifications, of each new block connected to the main
+// chain. Clients have the option of passing in their best known block, which
+// the notifier uses to check if they are behind on blocks and catch them up. If
+// they do not provide one, then a notification will be dispatched immediately
+// for the current tip of the chain upon a successful registration.
+func (e *EsploraNotifier) RegisterBlockEpochNtfn(
+	bestBlock *chainntnfs.BlockEpoch) (*chainntnfs.BlockEpochEvent, error) {
+
+	reg := &blockEpochRegistration{
+		epochQueue: queue.NewConcurrentQueue(20),
+		epochChan:  make(chan *chainntnfs.BlockEpoch, 20),
+		cancelChan: make(chan struct{}),
+		epochID:    atomic.AddUint64(&e.epochClientCounter, 1),
+		bestBlock:  bestBlock,
+		errorChan:  make(chan error, 1),
+	}
+
+	reg.epochQueue.Start()
+
+	// Before we send the request to the main goroutine, we'll launch a new
+	// goroutine to proxy items added to our queue to the client itself.
+	// This ensures that all notifications are received *in order*.
+	reg.wg.Add(1)
+	go func() {
+		defer reg.wg.Done()
+
+		for {
+			select {
+			case ntfn := <-reg.epochQueue.ChanOut():
+				blockNtfn := ntfn.(*chainntnfs.BlockEpoch)
+				select {
+				case reg.epochChan <- blockNtfn:
+
+				case <-reg.cancelChan:
+					return
+
+				case <-e.quit:
+					return
+				}
+
+			case <-reg.cancelChan:
+				return
+
+			case <-e.quit:
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-e.quit:
+		// As we're exiting before the registration could be sent,
+		// we'll stop the queue now ourselves.
+		reg.epochQueue.Stop()
+
+		return nil, errors.New("chainntnfs: system interrupt while " +
+			"attempting to register for block epoch notification.")
+
+	case e.notificationRegistry <- reg:
+		return &chainntnfs.BlockEpochEvent{
+			Epochs: reg.epochChan,
+			Cancel: func() {
+				cancel := &epochCancel{
+					epochID: reg.epochID,
+				}
+
+				// Submit epoch cancellation to notification
+				// dispatcher.
+				select {
+				case e.notificationCancels <- cancel:
+					// Cancellation is being handled, drain
+					// the epoch channel until it is closed
+					// before yielding to caller.
+					for {
+						select {
+						case _, ok := <-reg.epochChan:
+							if !ok {
+								return
+							}
+						case <-e.quit:
+							return
+						}
+					}
+				case <-e.quit:
+				}
+			},
+		}, nil
+	}
+}
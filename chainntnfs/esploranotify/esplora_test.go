@@ -0,0 +1,422 @@
+package esploranotify
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/blockcache"
+	"github.com/flokiorg/flnd/chainntnfs"
+	"github.com/flokiorg/flnd/channeldb"
+	"github.com/flokiorg/flnd/esploraio"
+	"github.com/flokiorg/flnd/esploraio/esploratest"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/txscript"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// testTimeout is the time we wait for a notification to be dispatched.
+	testTimeout = 10 * time.Second
+
+	// testPollInterval is how often the notifier polls the fake API for
+	// new blocks.
+	testPollInterval = 50 * time.Millisecond
+)
+
+var (
+	// testWitnessScript is the witness script of the outputs created by
+	// the tests, which anyone can spend.
+	testWitnessScript = []byte{txscript.OP_TRUE}
+
+	// testScript is the P2WSH output script of testWitnessScript.
+	testScript = func() []byte {
+		scriptHash := sha256.Sum256(testWitnessScript)
+		script, err := txscript.NewScriptBuilder().
+			AddOp(txscript.OP_0).
+			AddData(scriptHash[:]).
+			Script()
+		if err != nil {
+			panic(err)
+		}
+
+		return script
+	}()
+)
+
+func initHintCache(t *testing.T) *channeldb.HeightHintCache {
+	t.Helper()
+
+	db := channeldb.OpenForTesting(t, t.TempDir())
+
+	testCfg := channeldb.CacheConfig{
+		QueryDisable: false,
+	}
+	hintCache, err := channeldb.NewHeightHintCache(testCfg, db.Backend)
+	require.NoError(t, err, "unable to create hint cache")
+
+	return hintCache
+}
+
+// setUpNotifier is a helper function to start a new notifier backed by the
+// given fake Esplora API.
+func setUpNotifier(t *testing.T,
+	server *esploratest.Server) *EsploraNotifier {
+
+	t.Helper()
+
+	client := esploraio.NewClient(server.URL(), testTimeout)
+	hintCache := initHintCache(t)
+
+	db := channeldb.OpenForTesting(t, t.TempDir())
+	backend, err := esploraio.NewBackend(
+		client, esploratest.ChainParams, blockcache.NewBlockCache(10000),
+		db.Backend,
+	)
+	require.NoError(t, err)
+
+	notifier := New(backend, testPollInterval, hintCache, hintCache)
+	require.NoError(t, notifier.Start())
+	t.Cleanup(func() {
+		require.NoError(t, notifier.Stop())
+	})
+
+	return notifier
+}
+
+// newTx returns a new transaction spending the given outpoint to testScript.
+// The input spends the outpoint as if it was locked to testScript.
+func newTx(prevOut wire.OutPoint) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: prevOut,
+		Witness:          wire.TxWitness{{0x01}, testWitnessScript},
+	})
+	tx.AddTxOut(&wire.TxOut{Value: 1e6, PkScript: testScript})
+
+	return tx
+}
+
+// checkEpoch asserts that the next block epoch notification is for the block
+// with the given height and hash.
+func checkEpoch(t *testing.T, epochEvent *chainntnfs.BlockEpochEvent,
+	height int32, hash chainhash.Hash) {
+
+	t.Helper()
+
+	select {
+	case epoch := <-epochEvent.Epochs:
+		require.Equal(t, height, epoch.Height)
+		require.Equal(t, hash, *epoch.Hash)
+
+	case <-time.After(testTimeout):
+		t.Fatal("block epoch notification not received")
+	}
+}
+
+// checkNoEpoch asserts that no block epoch notification is dispatched for a
+// while.
+func checkNoEpoch(t *testing.T, epochEvent *chainntnfs.BlockEpochEvent) {
+	t.Helper()
+
+	select {
+	case epoch := <-epochEvent.Epochs:
+		t.Fatalf("unexpected block epoch notification for height %d",
+			epoch.Height)
+
+	case <-time.After(20 * testPollInterval):
+	}
+}
+
+// TestConfirmationNotification ensures that a confirmation notification is
+// dispatched once a transaction reaches the requested number of
+// confirmations, both when it confirmed before and after the registration.
+func TestConfirmationNotification(t *testing.T) {
+	t.Parallel()
+
+	server := esploratest.NewServer(t)
+	server.MineBlock()
+
+	notifier := setUpNotifier(t, server)
+
+	// The first transaction confirms before the registration, so its
+	// confirmation is found through the script's history.
+	tx1 := newTx(wire.OutPoint{Index: 1})
+	block1 := server.MineBlock(tx1)
+
+	tx1Hash := tx1.TxHash()
+	confEvent1, err := notifier.RegisterConfirmationsNtfn(
+		&tx1Hash, testScript, 1, 1,
+	)
+	require.NoError(t, err)
+
+	// The second transaction confirms after the registration, and
+	// requires two confirmations.
+	tx2 := newTx(wire.OutPoint{Index: 2})
+	tx2Hash := tx2.TxHash()
+	confEvent2, err := notifier.RegisterConfirmationsNtfn(
+		&tx2Hash, testScript, 2, 1,
+	)
+	require.NoError(t, err)
+
+	select {
+	case conf := <-confEvent1.Confirmed:
+		require.Equal(t, block1, *conf.BlockHash)
+		require.EqualValues(t, 2, conf.BlockHeight)
+		require.EqualValues(t, 1, conf.TxIndex)
+		require.Equal(t, tx1Hash, conf.Tx.TxHash())
+
+	case <-time.After(testTimeout):
+		t.Fatal("confirmation notification not received")
+	}
+
+	block2 := server.MineBlock(tx2)
+
+	select {
+	case <-confEvent2.Confirmed:
+		t.Fatal("confirmation notification received too early")
+
+	case <-time.After(20 * testPollInterval):
+	}
+
+	server.MineBlock()
+
+	select {
+	case conf := <-confEvent2.Confirmed:
+		require.Equal(t, block2, *conf.BlockHash)
+		require.EqualValues(t, 3, conf.BlockHeight)
+		require.Equal(t, tx2Hash, conf.Tx.TxHash())
+
+	case <-time.After(testTimeout):
+		t.Fatal("confirmation notification not received")
+	}
+}
+
+// TestSpendNotification ensures that a spend notification is dispatched once
+// a watched outpoint is spent, both when it was spent before and after the
+// registration.
+func TestSpendNotification(t *testing.T) {
+	t.Parallel()
+
+	server := esploratest.NewServer(t)
+
+	fundingTx := wire.NewMsgTx(2)
+	fundingTx.AddTxIn(&wire.TxIn{})
+	fundingTx.AddTxOut(&wire.TxOut{Value: 1e6, PkScript: testScript})
+	fundingTx.AddTxOut(&wire.TxOut{Value: 1e6, PkScript: testScript})
+	server.MineBlock(fundingTx)
+
+	notifier := setUpNotifier(t, server)
+
+	op1 := wire.OutPoint{Hash: fundingTx.TxHash(), Index: 0}
+	op2 := wire.OutPoint{Hash: fundingTx.TxHash(), Index: 1}
+
+	// The first outpoint is spent before the registration.
+	spendTx1 := newTx(op1)
+	server.MineBlock(spendTx1)
+
+	spendEvent1, err := notifier.RegisterSpendNtfn(&op1, testScript, 1)
+	require.NoError(t, err)
+
+	spendEvent2, err := notifier.RegisterSpendNtfn(&op2, testScript, 1)
+	require.NoError(t, err)
+
+	checkSpend := func(event *chainntnfs.SpendEvent, op wire.OutPoint,
+		spendTx *wire.MsgTx, height int32) {
+
+		t.Helper()
+
+		select {
+		case spend := <-event.Spend:
+			require.Equal(t, op, *spend.SpentOutPoint)
+			require.Equal(t, spendTx.TxHash(), *spend.SpenderTxHash)
+			require.EqualValues(t, 0, spend.SpenderInputIndex)
+			require.Equal(t, height, spend.SpendingHeight)
+
+		case <-time.After(testTimeout):
+			t.Fatal("spend notification not received")
+		}
+	}
+
+	checkSpend(spendEvent1, op1, spendTx1, 2)
+
+	// The second outpoint is spent after the registration.
+	spendTx2 := newTx(op2)
+	server.MineBlock(spendTx2)
+
+	checkSpend(spendEvent2, op2, spendTx2, 3)
+}
+
+// TestBlockEpochReorg ensures that block epoch notifications are dispatched
+// for each connected block, including the blocks of a new branch after a
+// reorg, and that confirmations are reverted when their block is reorged out.
+func TestBlockEpochReorg(t *testing.T) {
+	t.Parallel()
+
+	server := esploratest.NewServer(t)
+	notifier := setUpNotifier(t, server)
+
+	epochEvent, err := notifier.RegisterBlockEpochNtfn(nil)
+	require.NoError(t, err)
+
+	// We'll first receive a notification for the current tip.
+	checkEpoch(t, epochEvent, 0, server.BlockHash(0))
+
+	tx := newTx(wire.OutPoint{Index: 1})
+	txHash := tx.TxHash()
+	confEvent, err := notifier.RegisterConfirmationsNtfn(
+		&txHash, testScript, 2, 1,
+	)
+	require.NoError(t, err)
+
+	checkEpoch(t, epochEvent, 1, server.MineBlock(tx))
+
+	// Reorg out the block that confirmed the transaction before it has
+	// reached its confirmations. A branch only replaces our chain once
+	// it carries more work, so nothing happens until it's extended.
+	server.Reorg(1)
+	server.MineBlock()
+	checkNoEpoch(t, epochEvent)
+
+	server.MineBlock()
+	checkEpoch(t, epochEvent, 1, server.BlockHash(1))
+	checkEpoch(t, epochEvent, 2, server.BlockHash(2))
+
+	select {
+	case <-confEvent.NegativeConf:
+	case <-time.After(testTimeout):
+		t.Fatal("negative confirmation notification not received")
+	}
+
+	checkEpoch(t, epochEvent, 3, server.MineBlock(tx))
+	checkEpoch(t, epochEvent, 4, server.MineBlock())
+
+	select {
+	case conf := <-confEvent.Confirmed:
+		require.EqualValues(t, 3, conf.BlockHeight)
+		require.Equal(t, server.BlockHash(3), *conf.BlockHash)
+
+	case <-time.After(testTimeout):
+		t.Fatal("confirmation notification not received")
+	}
+}
+
+// TestSpendReorg ensures that a spend notification is reverted once the block
+// that included the spend is reorged out, and dispatched again once the spend
+// confirms in the new branch.
+func TestSpendReorg(t *testing.T) {
+	t.Parallel()
+
+	server := esploratest.NewServer(t)
+
+	fundingTx := wire.NewMsgTx(2)
+	fundingTx.AddTxIn(&wire.TxIn{})
+	fundingTx.AddTxOut(&wire.TxOut{Value: 1e6, PkScript: testScript})
+	server.MineBlock(fundingTx)
+
+	notifier := setUpNotifier(t, server)
+
+	op := wire.OutPoint{Hash: fundingTx.TxHash(), Index: 0}
+	spendEvent, err := notifier.RegisterSpendNtfn(&op, testScript, 1)
+	require.NoError(t, err)
+
+	spendTx := newTx(op)
+	server.MineBlock(spendTx)
+
+	select {
+	case spend := <-spendEvent.Spend:
+		require.EqualValues(t, 2, spend.SpendingHeight)
+
+	case <-time.After(testTimeout):
+		t.Fatal("spend notification not received")
+	}
+
+	// Replace the block including the spend, along with the block before
+	// it, with a longer branch that doesn't include the spend.
+	server.Reorg(2)
+	server.MineBlock(fundingTx)
+	server.MineBlock()
+	server.MineBlock()
+
+	select {
+	case <-spendEvent.Reorg:
+	case <-time.After(testTimeout):
+		t.Fatal("spend reorg notification not received")
+	}
+
+	// Once the spend confirms in the new branch, it should be dispatched
+	// again.
+	server.MineBlock(spendTx)
+
+	select {
+	case spend := <-spendEvent.Spend:
+		require.EqualValues(t, 4, spend.SpendingHeight)
+
+	case <-time.After(testTimeout):
+		t.Fatal("spend notification not received")
+	}
+}
+
+// TestInvalidBlockRejected ensures that blocks whose header doesn't carry
+// valid proof-of-work aren't connected, and that the notifier recovers once
+// the API moves to a valid chain.
+func TestInvalidBlockRejected(t *testing.T) {
+	t.Parallel()
+
+	server := esploratest.NewServer(t)
+	notifier := setUpNotifier(t, server)
+
+	epochEvent, err := notifier.RegisterBlockEpochNtfn(nil)
+	require.NoError(t, err)
+	checkEpoch(t, epochEvent, 0, server.BlockHash(0))
+
+	checkEpoch(t, epochEvent, 1, server.MineBlock())
+
+	// A block with invalid proof-of-work, as well as any block building
+	// on top of it, must not be connected.
+	server.MineInvalidBlock()
+	server.MineBlock()
+	checkNoEpoch(t, epochEvent)
+
+	// Once the invalid block is replaced by a valid branch, its blocks
+	// are connected.
+	server.Reorg(2)
+	checkEpoch(t, epochEvent, 2, server.MineBlock())
+	checkEpoch(t, epochEvent, 3, server.MineBlock())
+}
+
+// TestMissedBlocksAfterDeepReorg ensures that a client providing its best
+// known block receives the blocks it missed, and that the notifier follows a
+// reorg deeper than a single block.
+func TestMissedBlocksAfterDeepReorg(t *testing.T) {
+	t.Parallel()
+
+	server := esploratest.NewServer(t)
+	for i := 0; i < 5; i++ {
+		server.MineBlock()
+	}
+
+	notifier := setUpNotifier(t, server)
+
+	bestHash := server.BlockHash(2)
+	epochEvent, err := notifier.RegisterBlockEpochNtfn(
+		&chainntnfs.BlockEpoch{Height: 2, Hash: &bestHash},
+	)
+	require.NoError(t, err)
+
+	for height := int32(3); height <= 5; height++ {
+		checkEpoch(t, epochEvent, height, server.BlockHash(height))
+	}
+
+	// Replace the last three blocks with a longer branch. We should be
+	// notified of all blocks of the new branch.
+	server.Reorg(3)
+	for i := 0; i < 4; i++ {
+		server.MineBlock()
+	}
+
+	for height := int32(3); height <= 6; height++ {
+		checkEpoch(t, epochEvent, height, server.BlockHash(height))
+	}
+}
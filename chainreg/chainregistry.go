@@ -16,8 +16,13 @@ import (
 	"github.com/flokiorg/flnd/chainntnfs"
 	"github.com/flokiorg/flnd/chainntnfs/bitcoindnotify"
 	"github.com/flokiorg/flnd/chainntnfs/btcdnotify"
+	"github.com/flokiorg/flnd/chainntnfs/electrumnotify"
+	"github.com/flokiorg/flnd/chainntnfs/esploranotify"
 	"github.com/flokiorg/flnd/chainntnfs/neutrinonotify"
 	"github.com/flokiorg/flnd/channeldb"
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/electrumio"
+	"github.com/flokiorg/flnd/esploraio"
 	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/graph/db/models"
 	"github.com/flokiorg/flnd/input"
@@ -34,6 +39,7 @@ import (
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/rpcclient"
 	"github.com/flokiorg/walletd/chain"
+	"github.com/flokiorg/walletd/chain/electrum"
 )
 
 // Config houses necessary fields that a chainControl instance needs to
@@ -56,13 +62,19 @@ type Config struct {
 	// BtcdMode defines settings for connecting to a btcd node.
 	BtcdMode *lncfg.Btcd
 
+	// ElectrumMode defines settings for connecting to an electrum server.
+	ElectrumMode *lncfg.Electrum
+
+	// EsploraMode defines settings for connecting to an Esplora API.
+	EsploraMode *lncfg.Esplora
+
 	// HeightHintDB is a pointer to the database that stores the height
 	// hints.
 	HeightHintDB kvdb.Backend
 
 	// ChanStateDB is a pointer to the database that stores the channel
 	// state.
-	ChanStateDB *channeldb.ChannelStateDB
+	ChanStateDB chanstate.Store
 
 	// AuxLeafStore is an optional store that can be used to store auxiliary
 	// leaves for certain custom channel types.
@@ -188,6 +200,11 @@ type PartialChainControl struct {
 
 	// MinHtlcIn is the minimum HTLC we will accept.
 	MinHtlcIn lnwire.MilliLoki
+
+	// BackendChainIO is an optional BlockChainIO implementation that's
+	// backed directly by the chain backend. If set, it's used instead of
+	// the wallet to query the blockchain.
+	BackendChainIO fn.Option[lnwallet.BlockChainIO]
 }
 
 // ChainControl couples the three primary interfaces lnd utilizes for a
@@ -270,6 +287,13 @@ func NewPartialChainControl(cfg *Config) (*PartialChainControl, func(), error) {
 		cfg.Fee.URL = cfg.FeeURL
 	}
 
+	// The electrum client shared by the electrum backed interfaces, which
+	// is shut down along with the chain control.
+	var (
+		electrumClient *electrum.Client
+		electrumQuit   chan struct{}
+	)
+
 	// If spv mode is active, then we'll be using a distinct set of
 	// chainControl interfaces that interface directly with the p2p network
 	// of the selected chain.
@@ -673,6 +697,111 @@ func NewPartialChainControl(cfg *Config) (*PartialChainControl, func(), error) {
 			}
 		}
 
+	case "electrum":
+		electrumMode := cfg.ElectrumMode
+
+		// The wallet's chain source shuts down its electrum client
+		// once it's stopped, so it gets a connection of its own. The
+		// remaining interfaces share a second connection that lives
+		// as long as the chain control.
+		sourceClient, err := newElectrumClient(electrumMode)
+		if err != nil {
+			return nil, nil, err
+		}
+		electrumClient, err = newElectrumClient(electrumMode)
+		if err != nil {
+			sourceClient.Shutdown()
+			return nil, nil, err
+		}
+
+		electrumQuit = make(chan struct{})
+		go drainClientErrors(electrumClient, electrumQuit)
+
+		backend := electrumio.NewBackend(
+			electrumClient, cfg.ActiveNetParams.Params,
+			electrumMode.Timeout,
+		)
+
+		cc.ChainNotifier = electrumnotify.New(
+			backend, hintCache, hintCache,
+		)
+		cc.ChainView = chainview.NewElectrumFilteredChainView(backend)
+		cc.ChainSource = newElectrumChainSource(cfg, sourceClient)
+		cc.BackendChainIO = fn.Some[lnwallet.BlockChainIO](
+			electrumio.NewChainIO(backend),
+		)
+
+		// Ping the electrum server as a health check.
+		cc.HealthCheck = backend.Ping
+
+		// If feeurl is not provided, use the electrum server's fee
+		// estimator.
+		if cfg.Fee.URL == "" {
+			log.Info("Initializing electrum backed fee estimator")
+
+			fallBackFeeRate := chainfee.SatPerKVByte(25 * 1000)
+			cc.FeeEstimator = chainfee.NewElectrumEstimator(
+				electrumClient, electrumMode.Timeout,
+				fallBackFeeRate.FeePerKWeight(),
+			)
+		}
+
+	case "esplora":
+		esploraMode := cfg.EsploraMode
+
+		// All esplora backed interfaces share the same backend, so
+		// they follow the same header chain, and the blocks and
+		// headers they fetch are only verified once.
+		client := esploraio.NewClient(
+			esploraMode.URL, esploraMode.Timeout,
+		)
+		backend, err := esploraio.NewBackend(
+			client, cfg.ActiveNetParams.Params, cfg.BlockCache,
+			cfg.HeightHintDB,
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to create esplora "+
+				"backend: %v", err)
+		}
+
+		cc.ChainNotifier = esploranotify.New(
+			backend, esploraMode.PollInterval, hintCache, hintCache,
+		)
+		cc.ChainView = chainview.NewEsploraFilteredChainView(
+			backend, esploraMode.PollInterval,
+		)
+		cc.ChainSource = newEsploraChainSource(
+			backend, cfg.ActiveNetParams.Params,
+			esploraMode.PollInterval,
+		)
+		cc.BackendChainIO = fn.Some[lnwallet.BlockChainIO](
+			esploraio.NewChainIO(backend),
+		)
+
+		// Query the tip of the Esplora API as a health check.
+		cc.HealthCheck = func() error {
+			_, _, err := backend.BestBlock()
+			return err
+		}
+
+		// If feeurl is not provided, use the Esplora API's fee
+		// estimates.
+		if cfg.Fee.URL == "" {
+			log.Info("Initializing esplora backed fee estimator")
+
+			cc.FeeEstimator, err = chainfee.NewWebAPIEstimator(
+				chainfee.EsploraFeeSource{
+					URL: client.URL("/fee-estimates"),
+				},
+				false,
+				cfg.Fee.MinUpdateTimeout,
+				cfg.Fee.MaxUpdateTimeout,
+			)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
 	case "nochainbackend":
 		backend := &NoChainBackend{}
 		source := &NoChainSource{
@@ -738,6 +867,11 @@ func NewPartialChainControl(cfg *Config) (*PartialChainControl, func(), error) {
 					err)
 			}
 		}
+
+		if electrumClient != nil {
+			electrumClient.Shutdown()
+			close(electrumQuit)
+		}
 	}
 
 	// Start fee estimator.
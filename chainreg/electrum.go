@@ -0,0 +1,139 @@
+package chainreg
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/flokiorg/flnd/lncfg"
+	"github.com/flokiorg/go-flokicoin/chainjson"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/flokiorg/walletd/chain"
+	"github.com/flokiorg/walletd/chain/electrum"
+)
+
+// electrumChainSource wraps the electrum chain.Interface implementation to
+// adapt it to the way we use chain sources.
+type electrumChainSource struct {
+	*chain.ElectrumClient
+
+	client *electrum.Client
+
+	stopOnce sync.Once
+	quit     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// A compile time check to ensure electrumChainSource implements the
+// chain.Interface.
+var _ chain.Interface = (*electrumChainSource)(nil)
+
+// newElectrumChainSource creates a new electrum backed chain source using the
+// passed electrum client, which must already be started. The chain source
+// shuts the client down once it's stopped.
+func newElectrumChainSource(cfg *Config,
+	client *electrum.Client) *electrumChainSource {
+
+	source := chain.NewElectrumClient(cfg.ActiveNetParams.Params, client)
+
+	return &electrumChainSource{
+		ElectrumClient: source.(*chain.ElectrumClient),
+		client:         client,
+		quit:           make(chan struct{}),
+	}
+}
+
+// Start connects the chain source to the electrum server.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *electrumChainSource) Start(ctx context.Context) error {
+	// The electrum client reports the result of its periodic pings, as
+	// well as any connection errors, over unbuffered channels. Nothing
+	// else consumes these, so we drain them to make sure the client
+	// never blocks.
+	e.wg.Add(1)
+	go e.drainHealth()
+
+	return e.ElectrumClient.Start(ctx)
+}
+
+// drainHealth consumes the health reports of the electrum client until the
+// chain source has shut down.
+//
+// NOTE: This MUST be run as a goroutine.
+func (e *electrumChainSource) drainHealth() {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case err := <-e.ElectrumClient.Health():
+			if err != nil && err != electrum.NerrHealthPong {
+				log.Warnf("Electrum server health check "+
+					"failed: %v", err)
+			}
+
+		case err := <-e.client.Error:
+			log.Errorf("Electrum server connection error: %v", err)
+
+		case <-e.quit:
+			return
+		}
+	}
+}
+
+// WaitForShutdown blocks until the chain source has shut down.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *electrumChainSource) WaitForShutdown() {
+	// The client's goroutines may still be sending on the health channel,
+	// so we only stop draining it once they've all exited.
+	e.ElectrumClient.WaitForShutdown()
+
+	e.stopOnce.Do(func() {
+		close(e.quit)
+	})
+	e.wg.Wait()
+}
+
+// TestMempoolAccept always returns chain.ErrUnimplemented, as electrum servers
+// can't test whether transactions would be accepted to their mempool. This
+// allows callers to fall back to publishing the transactions directly.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *electrumChainSource) TestMempoolAccept(_ []*wire.MsgTx,
+	_ float64) ([]*chainjson.TestMempoolAcceptResult, error) {
+
+	return nil, chain.ErrUnimplemented
+}
+
+// newElectrumClient connects a new electrum client to the configured electrum
+// server.
+func newElectrumClient(cfg *lncfg.Electrum) (*electrum.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+
+	client := electrum.NewClient(cfg.Server, nil)
+	if err := client.Start(ctx); err != nil {
+		return nil, fmt.Errorf("unable to connect to electrum server "+
+			"%v: %w", cfg.Server, err)
+	}
+
+	return client, nil
+}
+
+// drainClientErrors consumes the connection errors of an electrum client
+// until the passed quit channel is closed, so the client never blocks on
+// reporting them.
+//
+// NOTE: This MUST be run as a goroutine.
+func drainClientErrors(client *electrum.Client, quit <-chan struct{}) {
+	for {
+		select {
+		case err := <-client.Error:
+			log.Errorf("Electrum server connection error: %v", err)
+
+		case <-quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,726 @@
+package chainreg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flokiorg/flnd/esploraio"
+	"github.com/flokiorg/go-flokicoin/chaincfg"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/chainjson"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/txscript"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/flokiorg/walletd/chain"
+	"github.com/flokiorg/walletd/waddrmgr"
+	"github.com/flokiorg/walletd/wtxmgr"
+)
+
+const (
+	// esploraBackendName is the name the esplora chain source reports as
+	// its backend.
+	esploraBackendName = "esplora"
+
+	// esploraCurrentDelta is how recent our best block must be for the
+	// chain source to consider itself synced to the tip of the chain.
+	esploraCurrentDelta = 2 * time.Hour
+
+	// esploraNotificationBuffer is the size of the buffer of the chain
+	// source's notification queue.
+	esploraNotificationBuffer = 20
+)
+
+// esploraChainSource is an implementation of the chain.Interface backed by an
+// Esplora API. New blocks are found by polling the API for its chain tip, and
+// are filtered in full once verified against the backend's validated most-work
+// header chain. Rescans are served from the API's address history index
+// instead of scanning blocks, which means unconfirmed transactions are only
+// picked up once they confirm.
+type esploraChainSource struct {
+	started atomic.Bool
+	stopped atomic.Bool
+
+	backend      *esploraio.Backend
+	chainParams  *chaincfg.Params
+	pollInterval time.Duration
+
+	// notifyBlocks is set once the wallet asked to be notified of new
+	// blocks.
+	notifyBlocks atomic.Bool
+
+	notificationQueue *chain.ConcurrentQueue
+
+	// bestBlock is the latest block the chain source has processed.
+	bestBlockMtx sync.RWMutex
+	bestBlock    waddrmgr.BlockStamp
+
+	// watchedAddrs and watchedOutPoints are the set of items we match
+	// transactions against to determine whether they're relevant to the
+	// wallet.
+	watchMtx         sync.RWMutex
+	watchedAddrs     map[string]chainutil.Address
+	watchedOutPoints map[wire.OutPoint]chainutil.Address
+
+	// rescanReqs is a channel over which the start of a requested rescan
+	// is sent to the chainUpdater goroutine.
+	rescanReqs chan chainhash.Hash
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// A compile time check to ensure esploraChainSource implements the
+// chain.Interface.
+var _ chain.Interface = (*esploraChainSource)(nil)
+
+// newEsploraChainSource creates a new esplora backed chain source, which polls
+// the passed backend for new blocks at the given interval.
+func newEsploraChainSource(backend *esploraio.Backend,
+	chainParams *chaincfg.Params,
+	pollInterval time.Duration) *esploraChainSource {
+
+	return &esploraChainSource{
+		backend:      backend,
+		chainParams:  chainParams,
+		pollInterval: pollInterval,
+		notificationQueue: chain.NewConcurrentQueue(
+			esploraNotificationBuffer,
+		),
+		watchedAddrs:     make(map[string]chainutil.Address),
+		watchedOutPoints: make(map[wire.OutPoint]chainutil.Address),
+		rescanReqs:       make(chan chainhash.Hash),
+		quit:             make(chan struct{}),
+	}
+}
+
+// Start retrieves the best block of the Esplora API, and starts polling it for
+// new blocks.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) Start(_ context.Context) error {
+	if !e.started.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	bestHash, bestHeight, err := e.backend.BestBlock()
+	if err != nil {
+		return fmt.Errorf("unable to retrieve best block: %w", err)
+	}
+	bestHeader, err := e.backend.BlockHeader(bestHash)
+	if err != nil {
+		return err
+	}
+
+	e.bestBlockMtx.Lock()
+	e.bestBlock = waddrmgr.BlockStamp{
+		Hash:      *bestHash,
+		Height:    bestHeight,
+		Timestamp: bestHeader.Timestamp,
+	}
+	e.bestBlockMtx.Unlock()
+
+	// Start the notification queue and immediately dispatch a
+	// ClientConnected notification to the caller, as the wallet requires
+	// it before syncing.
+	e.notificationQueue.Start()
+	e.notificationQueue.ChanIn() <- chain.ClientConnected{}
+
+	e.wg.Add(1)
+	go e.chainUpdater()
+
+	return nil
+}
+
+// Stop stops the chain source.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) Stop() {
+	if !e.stopped.CompareAndSwap(false, true) {
+		return
+	}
+
+	close(e.quit)
+	e.notificationQueue.Stop()
+}
+
+// WaitForShutdown blocks until the chain source has shut down.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) WaitForShutdown() {
+	e.wg.Wait()
+}
+
+// GetBestBlock returns the hash and height of the tip of the main chain.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) GetBestBlock() (*chainhash.Hash, int32, error) {
+	return e.backend.BestBlock()
+}
+
+// GetBlock returns the verified block with the given hash.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) GetBlock(
+	hash *chainhash.Hash) (*wire.MsgBlock, error) {
+
+	return e.backend.GetBlock(hash)
+}
+
+// GetBlockHash returns the hash of the main chain block at the given height.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) GetBlockHash(
+	height int64) (*chainhash.Hash, error) {
+
+	return e.backend.BlockHash(int32(height))
+}
+
+// GetBlockHeader returns the verified header of the block with the given hash.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) GetBlockHeader(
+	hash *chainhash.Hash) (*wire.BlockHeader, error) {
+
+	return e.backend.BlockHeader(hash)
+}
+
+// IsCurrent returns whether the latest block we've processed is recent.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) IsCurrent() bool {
+	e.bestBlockMtx.RLock()
+	defer e.bestBlockMtx.RUnlock()
+
+	return time.Since(e.bestBlock.Timestamp) < esploraCurrentDelta
+}
+
+// BlockStamp returns the latest block the chain source has processed.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) BlockStamp() (*waddrmgr.BlockStamp, error) {
+	e.bestBlockMtx.RLock()
+	bestBlock := e.bestBlock
+	e.bestBlockMtx.RUnlock()
+
+	return &bestBlock, nil
+}
+
+// SendRawTransaction publishes the given transaction through the Esplora API.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) SendRawTransaction(tx *wire.MsgTx,
+	_ bool) (*chainhash.Hash, error) {
+
+	txid, err := e.backend.Client().Broadcast(tx)
+	if err != nil {
+		return nil, e.MapRPCErr(err)
+	}
+
+	return txid, nil
+}
+
+// FilterBlocks scans the requested blocks for the addresses and outpoints of
+// interest, returning the first block that holds any of them.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) FilterBlocks(
+	req *chain.FilterBlocksRequest) (*chain.FilterBlocksResponse, error) {
+
+	blockFilterer := chain.NewBlockFilterer(e.chainParams, req)
+
+	// Iterate over the requested blocks, each of which is verified
+	// against its header before being scanned, breaking out early if any
+	// addresses are found.
+	for i, block := range req.Blocks {
+		rawBlock, err := e.backend.GetBlock(&block.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		if !blockFilterer.FilterBlock(rawBlock) {
+			continue
+		}
+
+		// If any external or internal addresses were detected in this
+		// block, we return them to the caller so that the rescan
+		// windows can widened with subsequent addresses. The
+		// `BatchIndex` is returned so that the caller can compute the
+		// *next* block from which to begin again.
+		resp := &chain.FilterBlocksResponse{
+			BatchIndex:         uint32(i),
+			BlockMeta:          block,
+			FoundExternalAddrs: blockFilterer.FoundExternal,
+			FoundInternalAddrs: blockFilterer.FoundInternal,
+			FoundOutPoints:     blockFilterer.FoundOutPoints,
+			RelevantTxns:       blockFilterer.RelevantTxns,
+		}
+
+		return resp, nil
+	}
+
+	// No addresses were found for this range.
+	return nil, nil
+}
+
+// Rescan adds the passed addresses and outpoints to the watch list, and looks
+// up their confirmed transactions from the block with the given hash until
+// our best block. Once done, a RescanFinished notification is dispatched.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) Rescan(blockHash *chainhash.Hash,
+	addrs []chainutil.Address,
+	outPoints map[wire.OutPoint]chainutil.Address) error {
+
+	// A block hash is required to use as the starting point of the rescan.
+	if blockHash == nil {
+		return errors.New("rescan requires a starting block hash")
+	}
+
+	e.watchMtx.Lock()
+	for _, addr := range addrs {
+		e.watchedAddrs[addr.String()] = addr
+	}
+	for op, addr := range outPoints {
+		e.watchedOutPoints[op] = addr
+	}
+	e.watchMtx.Unlock()
+
+	select {
+	case e.rescanReqs <- *blockHash:
+		return nil
+
+	case <-e.quit:
+		return errors.New("chain source shutting down")
+	}
+}
+
+// NotifyReceived adds the passed addresses to the watch list.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) NotifyReceived(addrs []chainutil.Address) error {
+	e.watchMtx.Lock()
+	defer e.watchMtx.Unlock()
+
+	for _, addr := range addrs {
+		e.watchedAddrs[addr.String()] = addr
+	}
+
+	return nil
+}
+
+// NotifyBlocks starts dispatching notifications for blocks connected to and
+// disconnected from the main chain.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) NotifyBlocks() error {
+	e.notifyBlocks.Store(true)
+
+	return nil
+}
+
+// Notifications returns the channel the chain source's notifications are sent
+// over.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) Notifications() <-chan interface{} {
+	return e.notificationQueue.ChanOut()
+}
+
+// BackEnd returns the name of the chain source's backend.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) BackEnd() string {
+	return esploraBackendName
+}
+
+// TestMempoolAccept always returns chain.ErrUnimplemented, as the Esplora API
+// can't test whether transactions would be accepted to its mempool. This
+// allows callers to fall back to publishing the transactions directly.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) TestMempoolAccept(_ []*wire.MsgTx,
+	_ float64) ([]*chainjson.TestMempoolAcceptResult, error) {
+
+	return nil, chain.ErrUnimplemented
+}
+
+// MapRPCErr maps an error returned when broadcasting a transaction to one of
+// the errors defined by the chain package. The Esplora API relays the errors
+// of its backing node, so these are matched against its error strings.
+//
+// NOTE: This is part of the chain.Interface interface.
+func (e *esploraChainSource) MapRPCErr(err error) error {
+	errStr := strings.ToLower(err.Error())
+
+	for _, errMap := range []map[string]error{
+		chain.LokidErrMap, chain.LokidErrMapPre2402,
+	} {
+		for nodeErr, matchedErr := range errMap {
+			if strings.Contains(errStr, strings.ToLower(nodeErr)) {
+				return matchedErr
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: %v", chain.ErrUndefined, err)
+}
+
+// chainUpdater is the chain source's main goroutine, which serves rescan
+// requests and syncs the chain source with the Esplora API's main chain.
+//
+// NOTE: This MUST be run as a goroutine.
+func (e *esploraChainSource) chainUpdater() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case start := <-e.rescanReqs:
+			if err := e.rescan(start); err != nil {
+				log.Errorf("Unable to complete chain rescan: %v",
+					err)
+			}
+
+		case <-ticker.C:
+			if err := e.syncChain(); err != nil {
+				log.Errorf("Unable to sync with the chain: %v",
+					err)
+			}
+
+		case <-e.quit:
+			return
+		}
+	}
+}
+
+// rescan dispatches the confirmed transactions of the watched addresses and
+// outpoints after the block with the given hash, up to our best block,
+// followed by a RescanFinished notification.
+func (e *esploraChainSource) rescan(start chainhash.Hash) error {
+	startHeight, err := e.backend.BlockHeight(&start)
+	if err != nil {
+		return err
+	}
+
+	e.bestBlockMtx.RLock()
+	bestBlock := e.bestBlock
+	e.bestBlockMtx.RUnlock()
+
+	log.Debugf("Rescanning from block height %v to %v", startHeight+1,
+		bestBlock.Height)
+
+	e.watchMtx.RLock()
+	addrs := make([]chainutil.Address, 0, len(e.watchedAddrs))
+	for _, addr := range e.watchedAddrs {
+		addrs = append(addrs, addr)
+	}
+	outPoints := make([]wire.OutPoint, 0, len(e.watchedOutPoints))
+	for op := range e.watchedOutPoints {
+		outPoints = append(outPoints, op)
+	}
+	e.watchMtx.RUnlock()
+
+	// We'll look up the history of every watched address, which holds
+	// both the transactions paying to it and the ones spending from it.
+	// The spends of watched outpoints not paying to a watched address are
+	// looked up separately.
+	txs := make(map[chainhash.Hash]*esploraio.BlockTx)
+	addTx := func(tx *esploraio.BlockTx) {
+		if tx.BlockHeight > uint32(bestBlock.Height) {
+			return
+		}
+		txs[tx.Tx.TxHash()] = tx
+	}
+	for _, addr := range addrs {
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return err
+		}
+
+		history, err := e.backend.ScriptTxs(
+			pkScript, uint32(startHeight+1),
+			uint32(bestBlock.Height),
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, tx := range history {
+			addTx(tx)
+		}
+	}
+	for _, op := range outPoints {
+		spend, _, err := e.backend.SpendingTx(&op)
+		if err != nil {
+			return err
+		}
+		if spend == nil || spend.BlockHeight <= uint32(startHeight) {
+			continue
+		}
+
+		addTx(spend)
+	}
+
+	// The transactions are dispatched in the order they were confirmed,
+	// as the wallet expects to learn about outputs before their spends.
+	sortedTxs := make([]*esploraio.BlockTx, 0, len(txs))
+	for _, tx := range txs {
+		sortedTxs = append(sortedTxs, tx)
+	}
+	sort.Slice(sortedTxs, func(i, j int) bool {
+		if sortedTxs[i].BlockHeight != sortedTxs[j].BlockHeight {
+			return sortedTxs[i].BlockHeight <
+				sortedTxs[j].BlockHeight
+		}
+
+		return sortedTxs[i].TxIndex < sortedTxs[j].TxIndex
+	})
+
+	for _, tx := range sortedTxs {
+		// Any outputs paying to watched addresses are now watched
+		// for spends as well.
+		e.watchOutputs(tx.Tx)
+
+		blockMeta := &wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{
+				Hash:   tx.BlockHash,
+				Height: int32(tx.BlockHeight),
+			},
+			Time: tx.Block.Header.Timestamp,
+		}
+		rec, err := wtxmgr.NewTxRecordFromMsgTx(tx.Tx, blockMeta.Time)
+		if err != nil {
+			return err
+		}
+
+		e.notify(chain.RelevantTx{
+			TxRecord: rec,
+			Block:    blockMeta,
+		})
+	}
+
+	e.notify(&chain.RescanFinished{
+		Hash:   &bestBlock.Hash,
+		Height: bestBlock.Height,
+		Time:   bestBlock.Timestamp,
+	})
+
+	return nil
+}
+
+// syncChain brings the chain source in line with the main chain of the
+// backend's header chain, after syncing it with the Esplora API. Any of our
+// blocks that are no longer part of the main chain are disconnected first,
+// after which the main chain's blocks above our new best block are connected
+// one at a time.
+func (e *esploraChainSource) syncChain() error {
+	_, tipHeight, err := e.backend.BestBlock()
+	if err != nil {
+		return err
+	}
+
+	e.bestBlockMtx.RLock()
+	bestBlock := e.bestBlock
+	e.bestBlockMtx.RUnlock()
+
+	for bestBlock.Height > 0 {
+		if bestBlock.Height <= tipHeight {
+			hash, err := e.backend.BlockHash(bestBlock.Height)
+			if err != nil {
+				return err
+			}
+
+			if *hash == bestBlock.Hash {
+				break
+			}
+		}
+
+		bestBlock, err = e.disconnectTip(bestBlock)
+		if err != nil {
+			return err
+		}
+	}
+
+	for height := bestBlock.Height + 1; height <= tipHeight; height++ {
+		hash, header, err := e.backend.BlockHeaderByHeight(height)
+		if err != nil {
+			return err
+		}
+
+		// If the block doesn't extend our tip, the backend's header
+		// chain switched to another branch while we were syncing.
+		// We'll catch up with it on our next sync.
+		if header.PrevBlock != bestBlock.Hash {
+			return fmt.Errorf("block %v at height %d doesn't "+
+				"extend our tip %v", hash, height,
+				bestBlock.Hash)
+		}
+
+		bestBlock, err = e.connectBlock(height, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// disconnectTip disconnects our best block, which is no longer part of the
+// main chain, and returns the block it builds upon as our new best block.
+func (e *esploraChainSource) disconnectTip(
+	bestBlock waddrmgr.BlockStamp) (waddrmgr.BlockStamp, error) {
+
+	// We look the previous block up by the hash our block commits to, as
+	// the main chain's block at that height may be part of the new branch
+	// already.
+	header, err := e.backend.BlockHeader(&bestBlock.Hash)
+	if err != nil {
+		return bestBlock, err
+	}
+	prevHeader, err := e.backend.BlockHeader(&header.PrevBlock)
+	if err != nil {
+		return bestBlock, err
+	}
+
+	if e.notifyBlocks.Load() {
+		e.notify(chain.BlockDisconnected{
+			Block: wtxmgr.Block{
+				Hash:   bestBlock.Hash,
+				Height: bestBlock.Height,
+			},
+			Time: bestBlock.Timestamp,
+		})
+	}
+
+	newBest := waddrmgr.BlockStamp{
+		Hash:      header.PrevBlock,
+		Height:    bestBlock.Height - 1,
+		Timestamp: prevHeader.Timestamp,
+	}
+	e.setBestBlock(newBest)
+
+	return newBest, nil
+}
+
+// connectBlock fetches the verified block with the given hash, and dispatches
+// it along with the transactions within it that are relevant to the wallet.
+func (e *esploraChainSource) connectBlock(height int32,
+	hash *chainhash.Hash) (waddrmgr.BlockStamp, error) {
+
+	block, err := e.backend.GetBlock(hash)
+	if err != nil {
+		return waddrmgr.BlockStamp{}, err
+	}
+
+	var relevantTxs []*wtxmgr.TxRecord
+	for _, tx := range block.Transactions {
+		if !e.watchOutputs(tx) && !e.spendsWatched(tx) {
+			continue
+		}
+
+		rec, err := wtxmgr.NewTxRecordFromMsgTx(
+			tx, block.Header.Timestamp,
+		)
+		if err != nil {
+			return waddrmgr.BlockStamp{}, err
+		}
+		relevantTxs = append(relevantTxs, rec)
+	}
+
+	newBest := waddrmgr.BlockStamp{
+		Hash:      *hash,
+		Height:    height,
+		Timestamp: block.Header.Timestamp,
+	}
+
+	if e.notifyBlocks.Load() {
+		blockMeta := wtxmgr.BlockMeta{
+			Block: wtxmgr.Block{
+				Hash:   *hash,
+				Height: height,
+			},
+			Time: block.Header.Timestamp,
+		}
+
+		e.notify(chain.FilteredBlockConnected{
+			Block:       &blockMeta,
+			RelevantTxs: relevantTxs,
+		})
+		e.notify(chain.BlockConnected(blockMeta))
+	}
+
+	e.setBestBlock(newBest)
+
+	return newBest, nil
+}
+
+// setBestBlock records the latest block the chain source has processed.
+func (e *esploraChainSource) setBestBlock(bestBlock waddrmgr.BlockStamp) {
+	e.bestBlockMtx.Lock()
+	e.bestBlock = bestBlock
+	e.bestBlockMtx.Unlock()
+}
+
+// watchOutputs adds the outputs of the transaction that pay to a watched
+// address to the watched outpoints, returning whether any were found.
+func (e *esploraChainSource) watchOutputs(tx *wire.MsgTx) bool {
+	e.watchMtx.Lock()
+	defer e.watchMtx.Unlock()
+
+	var found bool
+	for i, txOut := range tx.TxOut {
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			txOut.PkScript, e.chainParams,
+		)
+		if err != nil {
+			// Non-standard outputs can be safely skipped.
+			continue
+		}
+
+		for _, addr := range addrs {
+			if _, ok := e.watchedAddrs[addr.String()]; !ok {
+				continue
+			}
+
+			op := wire.OutPoint{
+				Hash:  tx.TxHash(),
+				Index: uint32(i),
+			}
+			e.watchedOutPoints[op] = addr
+			found = true
+		}
+	}
+
+	return found
+}
+
+// spendsWatched returns whether the transaction spends any of the watched
+// outpoints.
+func (e *esploraChainSource) spendsWatched(tx *wire.MsgTx) bool {
+	e.watchMtx.RLock()
+	defer e.watchMtx.RUnlock()
+
+	for _, txIn := range tx.TxIn {
+		if _, ok := e.watchedOutPoints[txIn.PreviousOutPoint]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// notify queues the given notification to the caller.
+func (e *esploraChainSource) notify(ntfn interface{}) {
+	select {
+	case e.notificationQueue.ChanIn() <- ntfn:
+	case <-e.quit:
+	}
+}
@@ -67,6 +67,11 @@ type ChannelAcceptResponse struct {
 	// ZeroConf indicates that the fundee wishes to send min_depth = 0 and
 	// request a zero-conf channel with the counter-party.
 	ZeroConf bool
+
+	// FundingContribution is the amount the fundee contributes to the
+	// funding output of a dual-funded channel. It's ignored if the
+	// initiator didn't request a dual-funded channel.
+	FundingContribution chainutil.Amount
 }
 
 // NewChannelAcceptResponse is a constructor for a channel accept response,
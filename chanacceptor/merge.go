@@ -18,6 +18,7 @@ const (
 	fieldMinIn           = "min htlc in"
 	fieldInFlightTotal   = "in flight total"
 	fieldUpfrontShutdown = "upfront shutdown"
+	fieldContribution    = "funding contribution"
 )
 
 var (
@@ -143,6 +144,15 @@ func mergeResponse(current,
 	}
 	current.Reserve = chainutil.Amount(reserve)
 
+	contribution, err := mergeInt64(
+		fieldContribution, int64(current.FundingContribution),
+		int64(newValue.FundingContribution),
+	)
+	if err != nil {
+		return current, err
+	}
+	current.FundingContribution = chainutil.Amount(contribution)
+
 	current.MinHtlcIn, err = mergeMillisatoshi(
 		fieldMinIn, current.MinHtlcIn, newValue.MinHtlcIn,
 	)
@@ -68,6 +68,16 @@ func TestMergeResponse(t *testing.T) {
 			},
 			err: fieldMismatchError(fieldReserve, 1, 2),
 		},
+		{
+			name: "different funding contribution",
+			current: ChannelAcceptResponse{
+				FundingContribution: 1,
+			},
+			new: ChannelAcceptResponse{
+				FundingContribution: 2,
+			},
+			err: fieldMismatchError(fieldContribution, 1, 2),
+		},
 		{
 			name: "different in flight",
 			current: ChannelAcceptResponse{
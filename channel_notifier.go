@@ -109,6 +109,11 @@ func (c *channelNotifier) SubscribeChans(ctx context.Context,
 				case channelnotifier.OpenChannelEvent:
 					sendChanOpenUpdate(event.Channel)
 
+				// The type or parameters of an open channel
+				// changed, so we'll refresh its backup.
+				case channelnotifier.ChannelParamsUpdateEvent:
+					sendChanOpenUpdate(event.Channel)
+
 				// An existing channel has been closed, we'll
 				// send only the chanPoint of the closed
 				// channel to the sub-swapper.
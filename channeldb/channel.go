@@ -215,6 +215,18 @@ var (
 	// ErrOnionBlobLength is returned is an onion blob with incorrect
 	// length is read from disk.
 	ErrOnionBlobLength = cstate.ErrOnionBlobLength
+
+	// ErrDynCommitmentInProgress is returned when a dynamic commitment is
+	// applied before the previous one completed.
+	ErrDynCommitmentInProgress = cstate.ErrDynCommitmentInProgress
+
+	// ErrSpliceInProgress is returned when a splice is started while the
+	// channel still has a pending splice.
+	ErrSpliceInProgress = cstate.ErrSpliceInProgress
+
+	// ErrNoPendingSplice is returned when an operation requires a pending
+	// splice, but the channel doesn't have one.
+	ErrNoPendingSplice = cstate.ErrNoPendingSplice
 )
 
 const (
@@ -287,6 +299,18 @@ type openChannelTlvData struct {
 	// Note: if not set, it means either the channel has not been
 	// closed yet, or it was closed before this field was introduced.
 	closeConfirmationHeight tlv.OptionalRecordT[tlv.TlvType9, uint32]
+
+	// dynCommitments is the optional serialized history of the dynamic
+	// commitments executed on the channel.
+	dynCommitments tlv.OptionalRecordT[tlv.TlvType10, tlv.Blob]
+
+	// spliceOutpoint is the optional funding outpoint created by the last
+	// splice that was locked.
+	spliceOutpoint tlv.OptionalRecordT[tlv.TlvType11, tlv.Blob]
+
+	// pendingSplice is the optional serialized splice that has been
+	// signed, but not yet locked by both parties.
+	pendingSplice tlv.OptionalRecordT[tlv.TlvType12, tlv.Blob]
 }
 
 // encode serializes the openChannelTlvData to the given io.Writer.
@@ -314,6 +338,21 @@ func (c *openChannelTlvData) encode(w io.Writer) error {
 			tlvRecords = append(tlvRecords, h.Record())
 		},
 	)
+	c.dynCommitments.WhenSome(
+		func(dyn tlv.RecordT[tlv.TlvType10, tlv.Blob]) {
+			tlvRecords = append(tlvRecords, dyn.Record())
+		},
+	)
+	c.spliceOutpoint.WhenSome(
+		func(op tlv.RecordT[tlv.TlvType11, tlv.Blob]) {
+			tlvRecords = append(tlvRecords, op.Record())
+		},
+	)
+	c.pendingSplice.WhenSome(
+		func(splice tlv.RecordT[tlv.TlvType12, tlv.Blob]) {
+			tlvRecords = append(tlvRecords, splice.Record())
+		},
+	)
 
 	tlv.SortRecords(tlvRecords)
 
@@ -332,6 +371,9 @@ func (c *openChannelTlvData) decode(r io.Reader) error {
 	tapscriptRoot := c.tapscriptRoot.Zero()
 	blob := c.customBlob.Zero()
 	closeConfHeight := c.closeConfirmationHeight.Zero()
+	dynCommitments := c.dynCommitments.Zero()
+	spliceOutpoint := c.spliceOutpoint.Zero()
+	pendingSplice := c.pendingSplice.Zero()
 
 	// Create the tlv stream.
 	tlvStream, err := tlv.NewStream(
@@ -344,6 +386,9 @@ func (c *openChannelTlvData) decode(r io.Reader) error {
 		blob.Record(),
 		c.confirmationHeight.Record(),
 		closeConfHeight.Record(),
+		dynCommitments.Record(),
+		spliceOutpoint.Record(),
+		pendingSplice.Record(),
 	)
 	if err != nil {
 		return err
@@ -366,6 +411,15 @@ func (c *openChannelTlvData) decode(r io.Reader) error {
 	if _, ok := tlvs[closeConfHeight.TlvType()]; ok {
 		c.closeConfirmationHeight = tlv.SomeRecordT(closeConfHeight)
 	}
+	if _, ok := tlvs[dynCommitments.TlvType()]; ok {
+		c.dynCommitments = tlv.SomeRecordT(dynCommitments)
+	}
+	if _, ok := tlvs[spliceOutpoint.TlvType()]; ok {
+		c.spliceOutpoint = tlv.SomeRecordT(spliceOutpoint)
+	}
+	if _, ok := tlvs[pendingSplice.TlvType()]; ok {
+		c.pendingSplice = tlv.SomeRecordT(pendingSplice)
+	}
 
 	return nil
 }
@@ -488,6 +542,19 @@ type CommitmentParams = cstate.CommitmentParams
 // ChannelConfig houses the channel configuration for one side of a channel.
 type ChannelConfig = cstate.ChannelConfig
 
+// ChannelParams is the set of channel parameters that can be renegotiated by
+// the dynamic commitments protocol.
+type ChannelParams = cstate.ChannelParams
+
+// DynCommitment records a dynamic commitment executed on a channel.
+type DynCommitment = cstate.DynCommitment
+
+// Splice records a signed splice of a channel that hasn't yet been locked.
+type Splice = cstate.Splice
+
+// SpliceUpgrade is the channel type upgrade carried out by a splice.
+type SpliceUpgrade = cstate.SpliceUpgrade
+
 // commitTlvData stores all the optional data that may be stored as a TLV stream
 // at the _end_ of the normal serialized commit on disk.
 type commitTlvData struct {
@@ -608,7 +675,9 @@ const (
 
 // amendOpenChannelTlvData updates the channel with the given auxiliary TLV
 // data.
-func amendOpenChannelTlvData(channel *OpenChannel, auxData openChannelTlvData) {
+func amendOpenChannelTlvData(channel *OpenChannel,
+	auxData openChannelTlvData) error {
+
 	channel.RevocationKeyLocator = auxData.revokeKeyLoc.Val.KeyLocator
 	channel.InitialLocalBalance = lnwire.MilliLoki(
 		auxData.initialLocalBalance.Val,
@@ -631,11 +700,40 @@ func amendOpenChannelTlvData(channel *OpenChannel, auxData openChannelTlvData) {
 	auxData.closeConfirmationHeight.WhenSomeV(func(h uint32) {
 		channel.CloseConfirmationHeight = fn.Some(h)
 	})
+
+	var err error
+	auxData.dynCommitments.WhenSomeV(func(blob tlv.Blob) {
+		channel.DynCommitments, err = deserializeDynCommitments(
+			bytes.NewReader(blob),
+		)
+	})
+	if err != nil {
+		return err
+	}
+
+	auxData.spliceOutpoint.WhenSomeV(func(blob tlv.Blob) {
+		var op wire.OutPoint
+		err = ReadElement(bytes.NewReader(blob), &op)
+		channel.SpliceOutpoint = fn.Some(op)
+	})
+	if err != nil {
+		return err
+	}
+
+	auxData.pendingSplice.WhenSomeV(func(blob tlv.Blob) {
+		var splice Splice
+		splice, err = deserializeSplice(bytes.NewReader(blob))
+		channel.PendingSplice = fn.Some(splice)
+	})
+
+	return err
 }
 
 // extractOpenChannelTlvData creates a new openChannelTlvData from the given
 // channel.
-func extractOpenChannelTlvData(channel *OpenChannel) openChannelTlvData {
+func extractOpenChannelTlvData(channel *OpenChannel) (openChannelTlvData,
+	error) {
+
 	auxData := openChannelTlvData{
 		revokeKeyLoc: tlv.NewRecordT[tlv.TlvType1](
 			keyLocRecord{channel.RevocationKeyLocator},
@@ -675,7 +773,161 @@ func extractOpenChannelTlvData(channel *OpenChannel) openChannelTlvData {
 		)
 	})
 
-	return auxData
+	if len(channel.DynCommitments) != 0 {
+		var b bytes.Buffer
+		err := serializeDynCommitments(&b, channel.DynCommitments)
+		if err != nil {
+			return openChannelTlvData{}, err
+		}
+
+		blob := tlv.Blob(b.Bytes())
+		auxData.dynCommitments = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType10](blob),
+		)
+	}
+
+	var err error
+	channel.SpliceOutpoint.WhenSome(func(op wire.OutPoint) {
+		var b bytes.Buffer
+		err = WriteElement(&b, op)
+
+		blob := tlv.Blob(b.Bytes())
+		auxData.spliceOutpoint = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType11](blob),
+		)
+	})
+	if err != nil {
+		return openChannelTlvData{}, err
+	}
+
+	channel.PendingSplice.WhenSome(func(splice Splice) {
+		var b bytes.Buffer
+		err = serializeSplice(&b, &splice)
+
+		blob := tlv.Blob(b.Bytes())
+		auxData.pendingSplice = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType12](blob),
+		)
+	})
+	if err != nil {
+		return openChannelTlvData{}, err
+	}
+
+	return auxData, nil
+}
+
+// serializeDynCommitments writes the dynamic commitment history of a channel
+// to the given writer.
+func serializeDynCommitments(w io.Writer, dyns []DynCommitment) error {
+	if err := WriteElement(w, uint16(len(dyns))); err != nil {
+		return err
+	}
+
+	for _, dyn := range dyns {
+		err := WriteElements(w,
+			dyn.LocalCommitHeight, dyn.RemoteCommitHeight,
+			dyn.PrevChanType, dyn.PrevLocalParams.DustLimit,
+			dyn.PrevLocalParams.CsvDelay,
+			dyn.PrevRemoteParams.DustLimit,
+			dyn.PrevRemoteParams.CsvDelay, dyn.Msg,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deserializeDynCommitments reads a dynamic commitment history written by
+// serializeDynCommitments.
+func deserializeDynCommitments(r io.Reader) ([]DynCommitment, error) {
+	var numDyns uint16
+	if err := ReadElement(r, &numDyns); err != nil {
+		return nil, err
+	}
+
+	dyns := make([]DynCommitment, numDyns)
+	for i := range dyns {
+		dyn := &dyns[i]
+		err := ReadElements(r,
+			&dyn.LocalCommitHeight, &dyn.RemoteCommitHeight,
+			&dyn.PrevChanType, &dyn.PrevLocalParams.DustLimit,
+			&dyn.PrevLocalParams.CsvDelay,
+			&dyn.PrevRemoteParams.DustLimit,
+			&dyn.PrevRemoteParams.CsvDelay, &dyn.Msg,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return dyns, nil
+}
+
+// serializeSplice writes the pending splice of a channel to the given writer.
+func serializeSplice(w io.Writer, splice *Splice) error {
+	err := WriteElements(w,
+		splice.FundingTx, splice.FundingOutpoint, splice.Capacity,
+		splice.LocalBalance, splice.RemoteBalance, splice.LocalCommitTx,
+		splice.LocalCommitSig, splice.RemoteCommitTx,
+		splice.IsInitiator, splice.HeightHint, splice.TxSignatures,
+		splice.RemoteSigned, splice.LocalLocked, splice.RemoteLocked,
+	)
+	if err != nil {
+		return err
+	}
+
+	// The channel type upgrade is optional and appended last, so splices
+	// written before it was introduced can still be read.
+	if splice.Upgrade.IsNone() {
+		return nil
+	}
+
+	upgrade := splice.Upgrade.UnwrapOr(SpliceUpgrade{})
+
+	return WriteElements(w, true, upgrade.ChanType, upgrade.CommitFee)
+}
+
+// deserializeSplice reads a pending splice written by serializeSplice.
+func deserializeSplice(r io.Reader) (Splice, error) {
+	var splice Splice
+	err := ReadElements(r,
+		&splice.FundingTx, &splice.FundingOutpoint, &splice.Capacity,
+		&splice.LocalBalance, &splice.RemoteBalance,
+		&splice.LocalCommitTx, &splice.LocalCommitSig,
+		&splice.RemoteCommitTx, &splice.IsInitiator,
+		&splice.HeightHint, &splice.TxSignatures,
+		&splice.RemoteSigned, &splice.LocalLocked,
+		&splice.RemoteLocked,
+	)
+	if err != nil {
+		return splice, err
+	}
+
+	var (
+		hasUpgrade bool
+		upgrade    SpliceUpgrade
+	)
+	err = ReadElement(r, &hasUpgrade)
+	switch {
+	case errors.Is(err, io.EOF):
+		return splice, nil
+
+	case err != nil:
+		return splice, err
+	}
+
+	if hasUpgrade {
+		err = ReadElements(r, &upgrade.ChanType, &upgrade.CommitFee)
+		if err != nil {
+			return splice, err
+		}
+
+		splice.Upgrade = fn.Some(upgrade)
+	}
+
+	return splice, nil
 }
 
 // RefreshChannel updates the in-memory channel state using the latest state
@@ -1080,6 +1332,82 @@ func (c *ChannelStateDB) MarkChannelScidAliasNegotiated(
 	}, func() {})
 }
 
+// ApplyChannelDynCommitment switches the channel over to the passed parameters
+// and appends the dynamic commitment to its history in the database.
+func (c *ChannelStateDB) ApplyChannelDynCommitment(channel *OpenChannel,
+	params ChannelParams, dyn DynCommitment) error {
+
+	return kvdb.Update(c.backend, func(tx kvdb.RwTx) error {
+		chanBucket, err := fetchChanBucketRw(
+			tx, channel.IdentityPub, &channel.FundingOutpoint,
+			channel.ChainHash,
+		)
+		if err != nil {
+			return err
+		}
+
+		diskChannel, err := fetchOpenChannel(
+			chanBucket, &channel.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		diskChannel.ApplyDynCommitmentForStore(params, dyn)
+
+		return putOpenChannel(chanBucket, diskChannel)
+	}, func() {})
+}
+
+// PutChannelSplice replaces the pending splice of the channel with the passed
+// one, removing it if none is passed.
+func (c *ChannelStateDB) PutChannelSplice(channel *OpenChannel,
+	splice fn.Option[Splice]) error {
+
+	return c.updateDiskChannel(channel, func(dbChannel *OpenChannel) error {
+		dbChannel.SetPendingSpliceForStore(splice)
+
+		return nil
+	})
+}
+
+// LockChannelSplice switches the channel over to the funding output of its
+// pending splice.
+func (c *ChannelStateDB) LockChannelSplice(channel *OpenChannel) error {
+	return c.updateDiskChannel(channel, func(dbChannel *OpenChannel) error {
+		return dbChannel.LockSpliceForStore()
+	})
+}
+
+// updateDiskChannel fetches the disk copy of the passed channel, applies the
+// given modification and writes the result back.
+func (c *ChannelStateDB) updateDiskChannel(channel *OpenChannel,
+	modify func(diskChannel *OpenChannel) error) error {
+
+	return kvdb.Update(c.backend, func(tx kvdb.RwTx) error {
+		chanBucket, err := fetchChanBucketRw(
+			tx, channel.IdentityPub, &channel.FundingOutpoint,
+			channel.ChainHash,
+		)
+		if err != nil {
+			return err
+		}
+
+		diskChannel, err := fetchOpenChannel(
+			chanBucket, &channel.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := modify(diskChannel); err != nil {
+			return err
+		}
+
+		return putOpenChannel(chanBucket, diskChannel)
+	}, func() {})
+}
+
 // MarkChannelDataLoss marks the channel as local-data-loss and stores the
 // commit point needed if the remote force closes.
 func (c *ChannelStateDB) MarkChannelDataLoss(channel *OpenChannel,
@@ -1706,25 +2034,8 @@ func (c *ChannelStateDB) UpdateChannelCommitment(channel *OpenChannel,
 func processFinalHtlc(finalHtlcsBucket walletdb.ReadWriteBucket, upd LogUpdate,
 	finalHtlcs map[uint64]bool) error {
 
-	var (
-		settled bool
-		id      uint64
-	)
-
-	switch msg := upd.UpdateMsg.(type) {
-	case *lnwire.UpdateFulfillHTLC:
-		settled = true
-		id = msg.ID
-
-	case *lnwire.UpdateFailHTLC:
-		settled = false
-		id = msg.ID
-
-	case *lnwire.UpdateFailMalformedHTLC:
-		settled = false
-		id = msg.ID
-
-	default:
+	id, settled, ok := finalHtlcOutcome(upd)
+	if !ok {
 		return nil
 	}
 
@@ -1747,6 +2058,25 @@ func processFinalHtlc(finalHtlcsBucket walletdb.ReadWriteBucket, upd LogUpdate,
 	return nil
 }
 
+// finalHtlcOutcome returns the htlc index and the outcome of the htlc that the
+// passed log update resolves. The returned boolean is false if the update is
+// not a resolution.
+func finalHtlcOutcome(upd LogUpdate) (uint64, bool, bool) {
+	switch msg := upd.UpdateMsg.(type) {
+	case *lnwire.UpdateFulfillHTLC:
+		return msg.ID, true, true
+
+	case *lnwire.UpdateFailHTLC:
+		return msg.ID, false, true
+
+	case *lnwire.UpdateFailMalformedHTLC:
+		return msg.ID, false, true
+
+	default:
+		return 0, false, false
+	}
+}
+
 // serializeHtlcExtraData encodes a TLV stream of extra data to be stored with a
 // HTLC. It uses the update_add_htlc TLV types, because this is where extra
 // data is passed with a HTLC. At present blinding points are the only extra
@@ -2515,6 +2845,36 @@ func (c *ChannelStateDB) LoadFwdPkgs(channel *OpenChannel) ([]*FwdPkg,
 	return fwdPkgs, nil
 }
 
+// LoadChannelFwdPkgs loads all forwarding packages owned by the channel with
+// the given short channel ID.
+func (c *ChannelStateDB) LoadChannelFwdPkgs(source lnwire.ShortChannelID) (
+	[]*FwdPkg, error) {
+
+	var fwdPkgs []*FwdPkg
+	if err := kvdb.View(c.backend, func(tx kvdb.RTx) error {
+		var err error
+		fwdPkgs, err = loadChannelFwdPkgs(tx, source)
+		return err
+	}, func() {
+		fwdPkgs = nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return fwdPkgs, nil
+}
+
+// AckSettleFailRefs updates the SettleFailFilter of the forwarding packages
+// referenced by the given SettleFailRefs, which may belong to any channel.
+// The acks are batched with other concurrent callers.
+func (c *ChannelStateDB) AckSettleFailRefs(
+	settleFailRefs ...SettleFailRef) error {
+
+	return kvdb.Batch(c.backend, func(tx kvdb.RwTx) error {
+		return ackSettleFails(tx, settleFailRefs)
+	})
+}
+
 // AckAddHtlcs updates the AckAddFilter containing any of the provided AddRefs
 // indicating that a response to this Add has been committed to the remote party.
 // Doing so will prevent these Add HTLCs from being reforwarded internally.
@@ -3181,9 +3541,11 @@ func fundingTxPresent(channel *OpenChannel) bool {
 		!channel.HasChanStatusForStore(ChanStatusRestored)
 }
 
-func putChanInfo(chanBucket kvdb.RwBucket, channel *OpenChannel) error {
-	var w bytes.Buffer
-	if err := WriteElements(&w,
+// serializeChanInfo writes the static channel info of the passed channel to
+// the given writer. The upfront shutdown scripts and the last-was-revoke flag
+// are not part of this encoding and need to be stored separately.
+func serializeChanInfo(w io.Writer, channel *OpenChannel) error {
+	if err := WriteElements(w,
 		channel.ChanType, channel.ChainHash, channel.FundingOutpoint,
 		channel.ShortChannelID, channel.IsPending, channel.IsInitiator,
 		channel.ChannelStatusForStore(), channel.FundingBroadcastHeight,
@@ -3197,23 +3559,35 @@ func putChanInfo(chanBucket kvdb.RwBucket, channel *OpenChannel) error {
 	// For single funder channels that we initiated, and we have the
 	// funding transaction, then write the funding txn.
 	if fundingTxPresent(channel) {
-		if err := WriteElement(&w, channel.FundingTxn); err != nil {
+		if err := WriteElement(w, channel.FundingTxn); err != nil {
 			return err
 		}
 	}
 
-	if err := writeChanConfig(&w, &channel.LocalChanCfg); err != nil {
+	if err := writeChanConfig(w, &channel.LocalChanCfg); err != nil {
 		return err
 	}
-	if err := writeChanConfig(&w, &channel.RemoteChanCfg); err != nil {
+	if err := writeChanConfig(w, &channel.RemoteChanCfg); err != nil {
 		return err
 	}
 
-	auxData := extractOpenChannelTlvData(channel)
-	if err := auxData.encode(&w); err != nil {
+	auxData, err := extractOpenChannelTlvData(channel)
+	if err != nil {
+		return fmt.Errorf("unable to extract aux data: %w", err)
+	}
+	if err := auxData.encode(w); err != nil {
 		return fmt.Errorf("unable to encode aux data: %w", err)
 	}
 
+	return nil
+}
+
+func putChanInfo(chanBucket kvdb.RwBucket, channel *OpenChannel) error {
+	var w bytes.Buffer
+	if err := serializeChanInfo(&w, channel); err != nil {
+		return err
+	}
+
 	if err := chanBucket.Put(chanInfoKey, w.Bytes()); err != nil {
 		return err
 	}
@@ -3294,17 +3668,49 @@ func putChanCommitment(chanBucket kvdb.RwBucket, c *ChannelCommitment,
 	}
 
 	var b bytes.Buffer
-	if err := serializeChanCommit(&b, c); err != nil {
+	if err := serializeChanCommitWithAux(&b, c); err != nil {
+		return err
+	}
+
+	return chanBucket.Put(commitKey, b.Bytes())
+}
+
+// serializeChanCommitWithAux writes the passed commitment followed by its
+// auxiliary TLV data to the given writer.
+func serializeChanCommitWithAux(w io.Writer, c *ChannelCommitment) error {
+	if err := serializeChanCommit(w, c); err != nil {
 		return err
 	}
 
 	// Before we write to disk, we'll also write our aux data as well.
 	auxData := extractCommitTlvData(c)
-	if err := auxData.encode(&b); err != nil {
+	if err := auxData.encode(w); err != nil {
 		return fmt.Errorf("unable to write aux data: %w", err)
 	}
 
-	return chanBucket.Put(commitKey, b.Bytes())
+	return nil
+}
+
+// deserializeChanCommitWithAux reads a commitment written by
+// serializeChanCommitWithAux from the given reader.
+func deserializeChanCommitWithAux(r io.Reader) (ChannelCommitment, error) {
+	chanCommit, err := deserializeChanCommit(r)
+	if err != nil {
+		return ChannelCommitment{}, fmt.Errorf("unable to decode "+
+			"chan commit: %w", err)
+	}
+
+	// We'll also check to see if we have any aux data stored as the end of
+	// the stream.
+	var auxData commitTlvData
+	if err := auxData.decode(r); err != nil {
+		return ChannelCommitment{}, fmt.Errorf("unable to decode "+
+			"chan aux data: %w", err)
+	}
+
+	amendCommitTlvData(&chanCommit, auxData)
+
+	return chanCommit, nil
 }
 
 func putChanCommitments(chanBucket kvdb.RwBucket, channel *OpenChannel) error {
@@ -3326,10 +3732,11 @@ func putChanCommitments(chanBucket kvdb.RwBucket, channel *OpenChannel) error {
 	)
 }
 
-func putChanRevocationState(chanBucket kvdb.RwBucket, channel *OpenChannel) error {
-	var b bytes.Buffer
+// serializeChanRevocationState writes the revocation state of the passed
+// channel to the given writer.
+func serializeChanRevocationState(w io.Writer, channel *OpenChannel) error {
 	err := WriteElements(
-		&b, channel.RemoteCurrentRevocation, channel.RevocationProducer,
+		w, channel.RemoteCurrentRevocation, channel.RevocationProducer,
 		channel.RevocationStore,
 	)
 	if err != nil {
@@ -3339,10 +3746,16 @@ func putChanRevocationState(chanBucket kvdb.RwBucket, channel *OpenChannel) erro
 	// If the next revocation is present, which is only the case after the
 	// ChannelReady message has been sent, then we'll write it to disk.
 	if channel.RemoteNextRevocation != nil {
-		err = WriteElements(&b, channel.RemoteNextRevocation)
-		if err != nil {
-			return err
-		}
+		return WriteElements(w, channel.RemoteNextRevocation)
+	}
+
+	return nil
+}
+
+func putChanRevocationState(chanBucket kvdb.RwBucket, channel *OpenChannel) error {
+	var b bytes.Buffer
+	if err := serializeChanRevocationState(&b, channel); err != nil {
+		return err
 	}
 
 	return chanBucket.Put(revocationStateKey, b.Bytes())
@@ -3358,13 +3771,9 @@ func readChanConfig(b io.Reader, c *ChannelConfig) error {
 	)
 }
 
-func fetchChanInfo(chanBucket kvdb.RBucket, channel *OpenChannel) error {
-	infoBytes := chanBucket.Get(chanInfoKey)
-	if infoBytes == nil {
-		return ErrNoChanInfoFound
-	}
-	r := bytes.NewReader(infoBytes)
-
+// deserializeChanInfo reads the static channel info written by
+// serializeChanInfo from the given reader into the passed channel.
+func deserializeChanInfo(r io.Reader, channel *OpenChannel) error {
 	var chanStatus ChannelStatus
 	if err := ReadElements(r,
 		&channel.ChanType, &channel.ChainHash, &channel.FundingOutpoint,
@@ -3393,6 +3802,31 @@ func fetchChanInfo(chanBucket kvdb.RBucket, channel *OpenChannel) error {
 		return err
 	}
 
+	var auxData openChannelTlvData
+	if err := auxData.decode(r); err != nil {
+		return fmt.Errorf("unable to decode aux data: %w", err)
+	}
+
+	// Assign all the relevant fields from the aux data into the actual
+	// open channel.
+	if err := amendOpenChannelTlvData(channel, auxData); err != nil {
+		return fmt.Errorf("unable to amend aux data: %w", err)
+	}
+
+	return nil
+}
+
+func fetchChanInfo(chanBucket kvdb.RBucket, channel *OpenChannel) error {
+	infoBytes := chanBucket.Get(chanInfoKey)
+	if infoBytes == nil {
+		return ErrNoChanInfoFound
+	}
+
+	err := deserializeChanInfo(bytes.NewReader(infoBytes), channel)
+	if err != nil {
+		return err
+	}
+
 	// Retrieve the boolean stored under lastWasRevokeKey.
 	lastWasRevokeBytes := chanBucket.Get(lastWasRevokeKey)
 	if lastWasRevokeBytes == nil {
@@ -3408,15 +3842,6 @@ func fetchChanInfo(chanBucket kvdb.RBucket, channel *OpenChannel) error {
 		}
 	}
 
-	var auxData openChannelTlvData
-	if err := auxData.decode(r); err != nil {
-		return fmt.Errorf("unable to decode aux data: %w", err)
-	}
-
-	// Assign all the relevant fields from the aux data into the actual
-	// open channel.
-	amendOpenChannelTlvData(channel, auxData)
-
 	// Finally, read the optional shutdown scripts.
 	if err := getOptionalUpfrontShutdownScript(
 		chanBucket, localUpfrontShutdownKey, &channel.LocalShutdownScript,
@@ -3464,24 +3889,7 @@ func fetchChanCommitment(chanBucket kvdb.RBucket,
 		return ChannelCommitment{}, ErrNoCommitmentsFound
 	}
 
-	r := bytes.NewReader(commitBytes)
-	chanCommit, err := deserializeChanCommit(r)
-	if err != nil {
-		return ChannelCommitment{}, fmt.Errorf("unable to decode "+
-			"chan commit: %w", err)
-	}
-
-	// We'll also check to see if we have any aux data stored as the end of
-	// the stream.
-	var auxData commitTlvData
-	if err := auxData.decode(r); err != nil {
-		return ChannelCommitment{}, fmt.Errorf("unable to decode "+
-			"chan aux data: %w", err)
-	}
-
-	amendCommitTlvData(&chanCommit, auxData)
-
-	return chanCommit, nil
+	return deserializeChanCommitWithAux(bytes.NewReader(commitBytes))
 }
 
 func fetchChanCommitments(chanBucket kvdb.RBucket, channel *OpenChannel) error {
@@ -3510,7 +3918,16 @@ func fetchChanRevocationState(chanBucket kvdb.RBucket, channel *OpenChannel) err
 	if revBytes == nil {
 		return ErrNoRevocationsFound
 	}
-	r := bytes.NewReader(revBytes)
+
+	return deserializeChanRevocationState(
+		bytes.NewReader(revBytes), channel,
+	)
+}
+
+// deserializeChanRevocationState reads the revocation state written by
+// serializeChanRevocationState from the given reader into the passed channel.
+func deserializeChanRevocationState(r *bytes.Reader,
+	channel *OpenChannel) error {
 
 	err := ReadElements(
 		r, &channel.RemoteCurrentRevocation, &channel.RevocationProducer,
@@ -254,6 +254,7 @@ func createTestChannel(t *testing.T, cdb *ChannelStateDB,
 		// Set the other public keys so that serialization doesn't
 		// panic.
 		err = params.channel.CloseChannel(&ChannelCloseSummary{
+			ChanPoint:               params.channel.FundingOutpoint,
 			RemotePub:               params.channel.IdentityPub,
 			RemoteCurrentRevocation: params.channel.IdentityPub,
 			RemoteNextRevocation:    params.channel.IdentityPub,
@@ -1372,6 +1373,230 @@ func testShutdownInfo(t *testing.T, locallyInitiated bool) {
 	})
 }
 
+// TestApplyDynCommitment asserts that the parameters and the history of
+// dynamic commitments survive a round trip through the database, and that a
+// new dynamic commitment can only be applied once the previous one completed.
+func TestApplyDynCommitment(t *testing.T) {
+	t.Parallel()
+
+	fullDB, err := MakeTestDB(t)
+	require.NoError(t, err, "unable to make test database")
+
+	cdb := fullDB.ChannelStateDB()
+	channel := createTestChannel(t, cdb, openChannelOption())
+	prevParams := channel.ChannelParams()
+
+	params := prevParams
+	params.ChanType |= AnchorOutputsBit | ZeroHtlcTxFeeBit
+	params.LocalParams.DustLimit += 100
+	params.RemoteParams.CsvDelay += 10
+	params.LocalBounds.MaxAcceptedHtlcs--
+	params.RemoteBounds.ChanReserve += 1000
+
+	msg := []byte{1, 2, 3}
+	require.NoError(t, channel.ApplyDynCommitment(params, msg))
+
+	// The new parameters take effect at the next commitment of each
+	// party.
+	localHeight := channel.LocalCommitment.CommitHeight
+	remoteHeight := channel.RemoteCommitment.CommitHeight
+	require.True(t, channel.DynCommitmentPending())
+	require.Equal(t, prevParams.ChanType, channel.CommitTypeAt(
+		lntypes.Local, localHeight,
+	))
+	require.Equal(t, params.ChanType, channel.CommitTypeAt(
+		lntypes.Remote, remoteHeight+1,
+	))
+
+	// Applying another dynamic commitment before the commitments caught
+	// up with the first one isn't allowed.
+	err = channel.ApplyDynCommitment(prevParams, msg)
+	require.ErrorIs(t, err, ErrDynCommitmentInProgress)
+
+	openChannels, err := cdb.FetchOpenChannels(channel.IdentityPub)
+	require.NoError(t, err)
+	require.Len(t, openChannels, 1)
+
+	dbChannel := openChannels[0]
+	require.Equal(t, params, dbChannel.ChannelParams())
+	require.Equal(t, []DynCommitment{{
+		LocalCommitHeight:  localHeight + 1,
+		RemoteCommitHeight: remoteHeight + 1,
+		PrevChanType:       prevParams.ChanType,
+		PrevLocalParams:    prevParams.LocalParams,
+		PrevRemoteParams:   prevParams.RemoteParams,
+		Msg:                msg,
+	}}, dbChannel.DynCommitments)
+
+	// Commitments below the switch heights still use the previous
+	// parameters.
+	oldView := dbChannel.ParamsAtHeight(lntypes.Local, localHeight)
+	require.Equal(t, prevParams.ChanType, oldView.ChanType)
+	require.Equal(
+		t, prevParams.LocalParams,
+		oldView.LocalChanCfg.CommitmentParams,
+	)
+	newView := dbChannel.ParamsAtHeight(lntypes.Remote, remoteHeight+1)
+	require.Equal(t, params.ChanType, newView.ChanType)
+
+	// The chan sync message advertises the number of executed dynamic
+	// commitments.
+	syncMsg, err := dbChannel.ChanSyncMsg()
+	require.NoError(t, err)
+	require.Equal(t, fn.Some(lnwire.DynHeight(1)), syncMsg.DynHeight)
+}
+
+// testSplice returns a pending splice of the passed channel that adds funds to
+// it.
+func testSplice(channel *OpenChannel) Splice {
+	spliceTx := channels.TestFundingTx.Copy()
+	spliceTx.LockTime = 1
+
+	localTx := channels.TestFundingTx.Copy()
+	localTx.LockTime = 2
+
+	remoteTx := channels.TestFundingTx.Copy()
+	remoteTx.LockTime = 3
+
+	return Splice{
+		FundingTx: spliceTx,
+		FundingOutpoint: wire.OutPoint{
+			Hash: spliceTx.TxHash(),
+		},
+		Capacity: channel.Capacity + 10_000,
+		LocalBalance: channel.LocalCommitment.LocalBalance +
+			lnwire.NewMSatFromLokis(10_000),
+		RemoteBalance:  channel.LocalCommitment.RemoteBalance,
+		LocalCommitTx:  localTx,
+		LocalCommitSig: []byte{1, 2, 3},
+		RemoteCommitTx: remoteTx,
+		IsInitiator:    true,
+		HeightHint:     100,
+		TxSignatures:   []byte{4, 5, 6},
+	}
+}
+
+// TestChannelSplice asserts that a pending splice survives a round trip
+// through the database, and that locking it switches the channel over to the
+// new funding output.
+func TestChannelSplice(t *testing.T) {
+	t.Parallel()
+
+	fullDB, err := MakeTestDB(t)
+	require.NoError(t, err, "unable to make test database")
+
+	cdb := fullDB.ChannelStateDB()
+	channel := createTestChannel(t, cdb, openChannelOption())
+
+	// Locking a splice requires a pending one.
+	require.ErrorIs(t, channel.LockSplice(), ErrNoPendingSplice)
+
+	splice := testSplice(channel)
+	require.NoError(t, channel.PutPendingSplice(splice))
+
+	// Only a single splice may be pending at a time.
+	otherSplice := splice
+	otherSplice.FundingOutpoint.Index = 1
+	err = channel.PutPendingSplice(otherSplice)
+	require.ErrorIs(t, err, ErrSpliceInProgress)
+
+	// Until we received the remote party's signatures, we'll ask them to
+	// retransmit them on reestablish.
+	syncMsg, err := channel.ChanSyncMsg()
+	require.NoError(t, err)
+	syncMsg.NextFundingTxid.WhenSomeV(func(txid [32]byte) {
+		require.Equal(t, splice.FundingOutpoint.Hash[:], txid[:])
+	})
+	require.True(t, syncMsg.NextFundingTxid.IsSome())
+
+	dbChannel, err := cdb.FetchChannel(channel.FundingOutpoint)
+	require.NoError(t, err)
+	require.Equal(t, fn.Some(splice), dbChannel.PendingSplice)
+	require.Equal(t, channel.FundingOutpoint, dbChannel.FundingTxOutpoint())
+
+	// Locking the splice switches over the funding output, but keeps the
+	// splice around until the remote party locked it too.
+	require.NoError(t, channel.LockSplice())
+
+	dbChannel, err = cdb.FetchChannel(channel.FundingOutpoint)
+	require.NoError(t, err)
+	require.Equal(t, splice.FundingOutpoint, dbChannel.FundingTxOutpoint())
+	require.Equal(t, splice.Capacity, dbChannel.Capacity)
+	require.Equal(
+		t, splice.LocalCommitTx.TxHash(),
+		dbChannel.LocalCommitment.CommitTx.TxHash(),
+	)
+	require.Equal(
+		t, splice.RemoteCommitTx.TxHash(),
+		dbChannel.RemoteCommitment.CommitTx.TxHash(),
+	)
+	require.Equal(
+		t, splice.LocalBalance, dbChannel.RemoteCommitment.LocalBalance,
+	)
+	require.True(t, dbChannel.PendingSplice.IsSome())
+
+	// A locked splice can't be aborted anymore.
+	require.ErrorIs(t, channel.AbortPendingSplice(), ErrSpliceInProgress)
+
+	require.NoError(t, channel.MarkSpliceRemoteLocked())
+
+	dbChannel, err = cdb.FetchChannel(channel.FundingOutpoint)
+	require.NoError(t, err)
+	require.True(t, dbChannel.PendingSplice.IsNone())
+	require.Equal(t, splice.FundingOutpoint, dbChannel.FundingTxOutpoint())
+}
+
+// TestChannelSpliceUpgrade asserts that a splice carrying a channel type
+// upgrade survives a round trip through the database, and that locking it
+// switches the channel over to the new type.
+func TestChannelSpliceUpgrade(t *testing.T) {
+	t.Parallel()
+
+	fullDB, err := MakeTestDB(t)
+	require.NoError(t, err, "unable to make test database")
+
+	cdb := fullDB.ChannelStateDB()
+	channel := createTestChannel(t, cdb, openChannelOption())
+	prevType := channel.ChanType
+	prevParams := channel.LocalChanCfg.CommitmentParams
+
+	upgrade := SpliceUpgrade{
+		ChanType:  prevType | SimpleTaprootFeatureBit,
+		CommitFee: channel.LocalCommitment.CommitFee + 100,
+	}
+	splice := testSplice(channel)
+	splice.Upgrade = fn.Some(upgrade)
+	require.NoError(t, channel.PutPendingSplice(splice))
+
+	dbChannel, err := cdb.FetchChannel(channel.FundingOutpoint)
+	require.NoError(t, err)
+	require.Equal(t, fn.Some(splice), dbChannel.PendingSplice)
+
+	require.NoError(t, channel.LockSplice())
+
+	dbChannel, err = cdb.FetchChannel(channel.FundingOutpoint)
+	require.NoError(t, err)
+	require.Equal(t, upgrade.ChanType, dbChannel.ChanType)
+	require.Equal(t, upgrade.CommitFee, dbChannel.LocalCommitment.CommitFee)
+	require.Equal(
+		t, upgrade.CommitFee, dbChannel.RemoteCommitment.CommitFee,
+	)
+
+	// The current commitments are the first ones to use the new type.
+	dyn, ok := dbChannel.LastDynCommitment()
+	require.True(t, ok)
+	require.Equal(t, prevType, dyn.PrevChanType)
+	require.Equal(t, prevParams, dyn.PrevLocalParams)
+	require.Equal(
+		t, dbChannel.LocalCommitment.CommitHeight, dyn.LocalCommitHeight,
+	)
+	require.Equal(
+		t, dbChannel.RemoteCommitment.CommitHeight,
+		dyn.RemoteCommitHeight,
+	)
+	require.False(t, dbChannel.DynCommitmentPending())
+}
+
 // TestRefresh asserts that Refresh updates the in-memory state of another
 // OpenChannel to reflect a preceding call to MarkOpen on a different
 // OpenChannel.
@@ -5,3 +5,4 @@ import "github.com/flokiorg/flnd/chanstate"
 // Compile-time assertions that ChannelStateDB satisfies the channel-state
 // store contracts while the KV implementation still lives in channeldb.
 var _ chanstate.Store = (*ChannelStateDB)(nil)
+var _ chanstate.Store = (*SQLStore)(nil)
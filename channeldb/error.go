@@ -50,6 +50,11 @@ var (
 	// to the log not having any recorded events.
 	ErrNoForwardingEvents = fmt.Errorf("no recorded forwarding events")
 
+	// ErrInvalidForwardingBucketSize is returned when forwarding events
+	// are aggregated by time without a positive bucket size.
+	ErrInvalidForwardingBucketSize = fmt.Errorf("bucket size of " +
+		"forwarding aggregates must be positive")
+
 	// ErrChanAlreadyExists is return when the caller attempts to create a
 	// channel with a channel point that is already present in the
 	// database.
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"sort"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/kvdb"
 	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/walletd/walletdb"
 )
 
@@ -35,6 +37,11 @@ const (
 	// total fee extract from a forwarding event.
 	forwardingEventSize = 48
 
+	// forwardingEventPeersSize is the size of the optional peers of a
+	// forwarding event that are appended to the event: the 33 byte
+	// incoming peer followed by the 33 byte outgoing peer.
+	forwardingEventPeersSize = 66
+
 	// MaxResponseEvents is the max number of forwarding events that will
 	// be returned by a single query response. This size was selected to
 	// safely remain under gRPC's 4MiB message size response limit. As each
@@ -47,6 +54,33 @@ const (
 	defaultDeleteBatchSize = 10_000
 )
 
+// ForwardingLogStore is the interface of a store of the forwarding log. It is
+// implemented by the KV ForwardingLog and by the native SQLForwardingLog.
+type ForwardingLogStore interface {
+	// AddForwardingEvents adds a series of forwarding events to the log.
+	AddForwardingEvents(events []ForwardingEvent) error
+
+	// Query returns the forwarding events of the time slice described by
+	// the given query.
+	Query(q ForwardingEventQuery) (ForwardingLogTimeSlice, error)
+
+	// DeleteForwardingEvents deletes all forwarding events with a
+	// timestamp at or before the specified endTime in batches of the
+	// given size.
+	DeleteForwardingEvents(ctx context.Context, endTime time.Time,
+		batchSize int) (DeleteStats, error)
+
+	// AggregateForwardingEvents returns the fee and volume totals of the
+	// forwarding events in the time range of the given query, grouped as
+	// requested by the query.
+	AggregateForwardingEvents(ctx context.Context,
+		q ForwardingAggregateQuery) ([]ForwardingAggregate, error)
+}
+
+// A compile-time assertion to ensure ForwardingLog implements the
+// ForwardingLogStore interface.
+var _ ForwardingLogStore = (*ForwardingLog)(nil)
+
 // ForwardingLog returns an instance of the ForwardingLog object backed by the
 // target database instance.
 func (d *DB) ForwardingLog() *ForwardingLog {
@@ -98,6 +132,15 @@ type ForwardingEvent struct {
 	// v0.20 and is made optional to make it backward compatible with
 	// existing forwarding events created before it's introduction.
 	OutgoingHtlcID fn.Option[uint64]
+
+	// IncomingPeer is the public key of the peer of the incoming channel.
+	// It is none for events that were created before the peers were
+	// recorded, unless the peer could be resolved from the channel state
+	// later on.
+	IncomingPeer fn.Option[route.Vertex]
+
+	// OutgoingPeer is the public key of the peer of the outgoing channel.
+	OutgoingPeer fn.Option[route.Vertex]
 }
 
 // encodeForwardingEvent writes out the target forwarding event to the passed
@@ -121,10 +164,30 @@ func encodeForwardingEvent(w io.Writer, f *ForwardingEvent) error {
 		return err
 	}
 
-	return WriteElements(
+	err = WriteElements(
 		w, f.IncomingChanID, f.OutgoingChanID, f.AmtIn, f.AmtOut,
 		incomingID, outgoingID,
 	)
+	if err != nil {
+		return err
+	}
+
+	// The peers are optional and appended as a pair, so we only write
+	// them if at least one of them is known. An unknown peer is written as
+	// an all-zero key, which can't be a valid public key.
+	if f.IncomingPeer.IsNone() && f.OutgoingPeer.IsNone() {
+		return nil
+	}
+
+	incomingPeer := f.IncomingPeer.UnwrapOr(route.Vertex{})
+	if _, err := w.Write(incomingPeer[:]); err != nil {
+		return err
+	}
+
+	outgoingPeer := f.OutgoingPeer.UnwrapOr(route.Vertex{})
+	_, err = w.Write(outgoingPeer[:])
+
+	return err
 }
 
 // decodeForwardingEvent attempts to decode the raw bytes of a serialized
@@ -150,14 +213,37 @@ func decodeForwardingEvent(r io.Reader, f *ForwardingEvent) error {
 		f.IncomingHtlcID = fn.Some(incomingHtlcID)
 		f.OutgoingHtlcID = fn.Some(outgoingHtlcID)
 
+	case errors.Is(err, io.EOF):
 		return nil
 
+	default:
+		return err
+	}
+
+	// Finally, decode the peers of the event. Like the htlc IDs, these
+	// are missing for older records.
+	var incomingPeer, outgoingPeer route.Vertex
+	_, err = io.ReadFull(r, incomingPeer[:])
+	switch {
 	case errors.Is(err, io.EOF):
 		return nil
 
-	default:
+	case err != nil:
 		return err
 	}
+
+	if _, err := io.ReadFull(r, outgoingPeer[:]); err != nil {
+		return err
+	}
+
+	if incomingPeer != (route.Vertex{}) {
+		f.IncomingPeer = fn.Some(incomingPeer)
+	}
+	if outgoingPeer != (route.Vertex{}) {
+		f.OutgoingPeer = fn.Some(outgoingPeer)
+	}
+
+	return nil
 }
 
 // AddForwardingEvents adds a series of forwarding events to the database.
@@ -235,8 +321,9 @@ func storeEvent(bucket walletdb.ReadWriteBucket, event ForwardingEvent,
 
 	// With the key encoded, we'll then encode the event
 	// into our buffer, then write it out to disk.
-	var eventBytes [forwardingEventSize]byte
-	eventBuf := bytes.NewBuffer(eventBytes[0:0:forwardingEventSize])
+	const maxEventSize = forwardingEventSize + forwardingEventPeersSize
+	var eventBytes [maxEventSize]byte
+	eventBuf := bytes.NewBuffer(eventBytes[0:0:maxEventSize])
 	err := encodeForwardingEvent(eventBuf, &event)
 	if err != nil {
 		return err
@@ -576,6 +663,278 @@ func (f *ForwardingLog) DeleteForwardingEvents(ctx context.Context,
 	return stats, nil
 }
 
+// ForwardingGroup determines how forwarding events are grouped when they are
+// aggregated.
+type ForwardingGroup uint8
+
+const (
+	// ForwardingGroupChannel groups forwarding events by the channels they
+	// were received and forwarded on.
+	ForwardingGroupChannel ForwardingGroup = iota
+
+	// ForwardingGroupPeer groups forwarding events by the peers they were
+	// received from and forwarded to. Events that were recorded without
+	// their peers are left out.
+	ForwardingGroupPeer
+
+	// ForwardingGroupTime groups forwarding events into time buckets of a
+	// fixed size.
+	ForwardingGroupTime
+)
+
+// ForwardingAggregateQuery represents a query for the aggregated fees and
+// volume of the forwarding events within a time range.
+type ForwardingAggregateQuery struct {
+	// StartTime is the start time of the time range.
+	StartTime time.Time
+
+	// EndTime is the end time of the time range.
+	EndTime time.Time
+
+	// GroupBy determines how the forwarding events are grouped.
+	GroupBy ForwardingGroup
+
+	// BucketSize is the size of the time buckets if the events are
+	// grouped by time. Buckets are aligned to the unix epoch, so a size of
+	// one day results in buckets that start at midnight UTC.
+	BucketSize time.Duration
+}
+
+// ForwardingStats holds the totals of a set of forwarding events.
+type ForwardingStats struct {
+	// NumEvents is the number of forwarding events.
+	NumEvents uint64
+
+	// AmtIn is the total amount of the incoming HTLCs of the events.
+	AmtIn lnwire.MilliLoki
+
+	// AmtOut is the total amount of the outgoing HTLCs of the events.
+	AmtOut lnwire.MilliLoki
+}
+
+// Fee returns the total fees earned by the forwarding events.
+func (f ForwardingStats) Fee() lnwire.MilliLoki {
+	return f.AmtIn - f.AmtOut
+}
+
+// add adds the given totals to the stats.
+func (f *ForwardingStats) add(other ForwardingStats) {
+	f.NumEvents += other.NumEvents
+	f.AmtIn += other.AmtIn
+	f.AmtOut += other.AmtOut
+}
+
+// ForwardingAggregate holds the aggregated forwarding events of a single
+// channel, peer or time bucket. Only the key that matches the group of the
+// query is set.
+type ForwardingAggregate struct {
+	// ChanID is the channel of the aggregate if the events are grouped by
+	// channel.
+	ChanID lnwire.ShortChannelID
+
+	// Peer is the peer of the aggregate if the events are grouped by peer.
+	Peer route.Vertex
+
+	// BucketStart is the start of the time bucket of the aggregate if the
+	// events are grouped by time.
+	BucketStart time.Time
+
+	// Incoming holds the totals of the events that were received on the
+	// channel or from the peer of the aggregate.
+	Incoming ForwardingStats
+
+	// Outgoing holds the totals of the events that were forwarded on the
+	// channel or to the peer of the aggregate. This is where the fees
+	// earned by a channel or peer are usually accounted for. For time
+	// buckets, every event is both received and forwarded by our node, so
+	// Incoming and Outgoing hold the same totals.
+	Outgoing ForwardingStats
+}
+
+// forwardingAggregator accumulates the forwarding aggregates of a query. It
+// is shared by the KV and the SQL forwarding log so that both of them return
+// the aggregates in the same shape and order.
+type forwardingAggregator struct {
+	q ForwardingAggregateQuery
+
+	// aggregates holds the aggregates by their key, which is either a
+	// channel ID, a peer or a bucket index.
+	aggregates map[any]*ForwardingAggregate
+}
+
+// newForwardingAggregator validates the given query and returns a new
+// aggregator for it.
+func newForwardingAggregator(
+	q ForwardingAggregateQuery) (*forwardingAggregator, error) {
+
+	switch q.GroupBy {
+	case ForwardingGroupChannel, ForwardingGroupPeer:
+
+	case ForwardingGroupTime:
+		if q.BucketSize <= 0 {
+			return nil, ErrInvalidForwardingBucketSize
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown forwarding group: %v",
+			q.GroupBy)
+	}
+
+	return &forwardingAggregator{
+		q:          q,
+		aggregates: make(map[any]*ForwardingAggregate),
+	}, nil
+}
+
+// channel returns the aggregate of the given channel.
+func (a *forwardingAggregator) channel(
+	chanID lnwire.ShortChannelID) *ForwardingAggregate {
+
+	agg, ok := a.aggregates[chanID]
+	if !ok {
+		agg = &ForwardingAggregate{ChanID: chanID}
+		a.aggregates[chanID] = agg
+	}
+
+	return agg
+}
+
+// peer returns the aggregate of the given peer.
+func (a *forwardingAggregator) peer(peer route.Vertex) *ForwardingAggregate {
+	agg, ok := a.aggregates[peer]
+	if !ok {
+		agg = &ForwardingAggregate{Peer: peer}
+		a.aggregates[peer] = agg
+	}
+
+	return agg
+}
+
+// bucket returns the aggregate of the time bucket with the given index.
+func (a *forwardingAggregator) bucket(index int64) *ForwardingAggregate {
+	agg, ok := a.aggregates[index]
+	if !ok {
+		agg = &ForwardingAggregate{
+			BucketStart: time.Unix(0, index*int64(a.q.BucketSize)),
+		}
+		a.aggregates[index] = agg
+	}
+
+	return agg
+}
+
+// addEvent adds a single forwarding event to the aggregates.
+func (a *forwardingAggregator) addEvent(event *ForwardingEvent) {
+	stats := ForwardingStats{
+		NumEvents: 1,
+		AmtIn:     event.AmtIn,
+		AmtOut:    event.AmtOut,
+	}
+
+	switch a.q.GroupBy {
+	case ForwardingGroupChannel:
+		a.channel(event.IncomingChanID).Incoming.add(stats)
+		a.channel(event.OutgoingChanID).Outgoing.add(stats)
+
+	case ForwardingGroupPeer:
+		event.IncomingPeer.WhenSome(func(peer route.Vertex) {
+			a.peer(peer).Incoming.add(stats)
+		})
+		event.OutgoingPeer.WhenSome(func(peer route.Vertex) {
+			a.peer(peer).Outgoing.add(stats)
+		})
+
+	case ForwardingGroupTime:
+		index := event.Timestamp.UnixNano() / int64(a.q.BucketSize)
+		agg := a.bucket(index)
+		agg.Incoming.add(stats)
+		agg.Outgoing.add(stats)
+	}
+}
+
+// result returns the aggregates ordered by their key.
+func (a *forwardingAggregator) result() []ForwardingAggregate {
+	aggregates := make([]ForwardingAggregate, 0, len(a.aggregates))
+	for _, agg := range a.aggregates {
+		aggregates = append(aggregates, *agg)
+	}
+
+	// Only the key of the queried group is set, so we can simply compare
+	// all of them.
+	sort.Slice(aggregates, func(i, j int) bool {
+		x, y := aggregates[i], aggregates[j]
+
+		if x.ChanID != y.ChanID {
+			return x.ChanID.ToUint64() < y.ChanID.ToUint64()
+		}
+		if x.Peer != y.Peer {
+			return bytes.Compare(x.Peer[:], y.Peer[:]) < 0
+		}
+
+		return x.BucketStart.Before(y.BucketStart)
+	})
+
+	return aggregates
+}
+
+// AggregateForwardingEvents returns the fee and volume totals of the
+// forwarding events in the time range of the given query, grouped by channel,
+// peer or time bucket as requested by the query. As the KV store doesn't
+// index the events by anything but their timestamp, this scans all events in
+// the time range.
+func (f *ForwardingLog) AggregateForwardingEvents(_ context.Context,
+	q ForwardingAggregateQuery) ([]ForwardingAggregate, error) {
+
+	aggregator, err := newForwardingAggregator(q)
+	if err != nil {
+		return nil, err
+	}
+
+	err = kvdb.View(f.db, func(tx kvdb.RTx) error {
+		logBucket := tx.ReadBucket(forwardingLogBucket)
+		if logBucket == nil {
+			return nil
+		}
+
+		var start, end [8]byte
+		byteOrder.PutUint64(start[:], uint64(q.StartTime.UnixNano()))
+		byteOrder.PutUint64(end[:], uint64(q.EndTime.UnixNano()))
+
+		logCursor := logBucket.ReadCursor()
+		k, v := logCursor.Seek(start[:])
+		for ; k != nil; k, v = logCursor.Next() {
+			if bytes.Compare(k, end[:]) > 0 {
+				break
+			}
+
+			readBuf := bytes.NewReader(v)
+			if readBuf.Len() == 0 {
+				continue
+			}
+
+			var event ForwardingEvent
+			err := decodeForwardingEvent(readBuf, &event)
+			if err != nil {
+				return err
+			}
+			event.Timestamp = time.Unix(
+				0, int64(byteOrder.Uint64(k)),
+			)
+
+			aggregator.addEvent(&event)
+		}
+
+		return nil
+	}, func() {
+		aggregator.aggregates = make(map[any]*ForwardingAggregate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregator.result(), nil
+}
+
 // makeUniqueTimestamps takes a slice of forwarding events, sorts it by the
 // event timestamps and then makes sure there are no duplicates in the
 // timestamps. If duplicates are found, some of the timestamps are increased on
@@ -82,12 +82,35 @@ var (
 func putRevocationLog(bucket kvdb.RwBucket, commit *ChannelCommitment,
 	ourOutputIndex, theirOutputIndex uint32, noAmtData bool) error {
 
+	rl, err := newRevocationLog(
+		commit, ourOutputIndex, theirOutputIndex, noAmtData,
+	)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	err = serializeRevocationLog(&b, rl)
+	if err != nil {
+		return err
+	}
+
+	logEntrykey := makeLogKey(commit.CommitHeight)
+	return bucket.Put(logEntrykey[:], b.Bytes())
+}
+
+// newRevocationLog constructs the revocation log entry for the passed
+// commitment. Dust HTLCs are not included in the entry, and the balances are
+// omitted if noAmtData is set.
+func newRevocationLog(commit *ChannelCommitment, ourOutputIndex,
+	theirOutputIndex uint32, noAmtData bool) (*RevocationLog, error) {
+
 	// Sanity check that the output indexes can be safely converted.
 	if ourOutputIndex > math.MaxUint16 {
-		return ErrOutputIndexTooBig
+		return nil, ErrOutputIndexTooBig
 	}
 	if theirOutputIndex > math.MaxUint16 {
-		return ErrOutputIndexTooBig
+		return nil, ErrOutputIndexTooBig
 	}
 
 	rl := &RevocationLog{
@@ -128,24 +151,17 @@ func putRevocationLog(bucket kvdb.RwBucket, commit *ChannelCommitment,
 		// Sanity check that the output indexes can be safely
 		// converted.
 		if htlc.OutputIndex > math.MaxUint16 {
-			return ErrOutputIndexTooBig
+			return nil, ErrOutputIndexTooBig
 		}
 
 		entry, err := NewHTLCEntryFromHTLC(htlc)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		rl.HTLCEntries = append(rl.HTLCEntries, entry)
 	}
 
-	var b bytes.Buffer
-	err := serializeRevocationLog(&b, rl)
-	if err != nil {
-		return err
-	}
-
-	logEntrykey := makeLogKey(commit.CommitHeight)
-	return bucket.Put(logEntrykey[:], b.Bytes())
+	return rl, nil
 }
 
 // fetchRevocationLog queries the revocation log bucket to find an log entry.
@@ -0,0 +1,1128 @@
+package channeldb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/fn"
+	graphdb "github.com/flokiorg/flnd/graph/db"
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+// commitmentType is the kind of a commitment as stored in the
+// commitment_type column of the channel_commitments table.
+type commitmentType int16
+
+const (
+	// commitmentLocal is the current commitment of the local party.
+	commitmentLocal commitmentType = 0
+
+	// commitmentRemote is the current commitment of the remote party.
+	commitmentRemote commitmentType = 1
+
+	// commitmentRemotePending is the commitment we've extended to the
+	// remote party, but which they haven't revoked their current one for
+	// yet.
+	commitmentRemotePending commitmentType = 2
+)
+
+// logUpdateType is the list a log update belongs to as stored in the
+// update_type column of the channel_log_updates table.
+type logUpdateType int16
+
+const (
+	// logUpdateCommitDiff marks the local updates that are included in
+	// the pending commitment of the remote party.
+	logUpdateCommitDiff logUpdateType = 0
+
+	// logUpdateUnsignedAcked marks the remote updates that we've acked,
+	// but not yet signed a commitment for.
+	logUpdateUnsignedAcked logUpdateType = 1
+
+	// logUpdateRemoteUnsignedLocal marks the local updates that the remote
+	// party hasn't signed a commitment for yet.
+	logUpdateRemoteUnsignedLocal logUpdateType = 2
+)
+
+// fwdPkgUpdateType is the kind of an update of a forwarding package as stored
+// in the update_type column of the channel_forwarding_package_updates table.
+type fwdPkgUpdateType int16
+
+const (
+	// fwdPkgUpdateAdd marks the adds of a forwarding package.
+	fwdPkgUpdateAdd fwdPkgUpdateType = 0
+
+	// fwdPkgUpdateSettleFail marks the settles and fails of a forwarding
+	// package.
+	fwdPkgUpdateSettleFail fwdPkgUpdateType = 1
+)
+
+// putChanCommitmentSQL replaces the commitment of the given type of the
+// channel with the passed one, including all its HTLCs.
+func putChanCommitmentSQL(ctx context.Context, db SQLQueries, channelID int64,
+	commitType commitmentType, c *ChannelCommitment) error {
+
+	err := db.DeleteChannelCommitment(
+		ctx, sqlc.DeleteChannelCommitmentParams{
+			ChannelID:      channelID,
+			CommitmentType: int16(commitType),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to delete commitment: %w", err)
+	}
+
+	var commitTx bytes.Buffer
+	if err := WriteElement(&commitTx, c.CommitTx); err != nil {
+		return err
+	}
+
+	commitmentID, err := db.InsertChannelCommitment(
+		ctx, sqlc.InsertChannelCommitmentParams{
+			ChannelID:         channelID,
+			CommitmentType:    int16(commitType),
+			CommitHeight:      int64(c.CommitHeight),
+			LocalLogIndex:     int64(c.LocalLogIndex),
+			LocalHtlcIndex:    int64(c.LocalHtlcIndex),
+			RemoteLogIndex:    int64(c.RemoteLogIndex),
+			RemoteHtlcIndex:   int64(c.RemoteHtlcIndex),
+			LocalBalanceMsat:  int64(c.LocalBalance),
+			RemoteBalanceMsat: int64(c.RemoteBalance),
+			CommitFee:         int64(c.CommitFee),
+			FeePerKw:          int64(c.FeePerKw),
+			CommitTx:          commitTx.Bytes(),
+			CommitSig:         notNullBytes(c.CommitSig),
+			CustomBlob:        c.CustomBlob.UnwrapOr(nil),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to insert commitment: %w", err)
+	}
+
+	for _, htlc := range c.Htlcs {
+		err := insertCommitmentHtlc(ctx, db, commitmentID, &htlc)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertCommitmentHtlc inserts the given HTLC of a commitment together with
+// its custom records.
+func insertCommitmentHtlc(ctx context.Context, db SQLQueries,
+	commitmentID int64, htlc *HTLC) error {
+
+	if err := htlc.CustomRecords.Validate(); err != nil {
+		return err
+	}
+
+	var blindingPoint []byte
+	htlc.BlindingPoint.WhenSomeV(func(b *crypto.PublicKey) {
+		blindingPoint = b.SerializeCompressed()
+	})
+
+	htlcID, err := db.InsertCommitmentHtlc(
+		ctx, sqlc.InsertCommitmentHtlcParams{
+			CommitmentID:  commitmentID,
+			HtlcIndex:     int64(htlc.HtlcIndex),
+			LogIndex:      int64(htlc.LogIndex),
+			Incoming:      htlc.Incoming,
+			PaymentHash:   htlc.RHash[:],
+			AmountMsat:    int64(htlc.Amt),
+			RefundTimeout: int64(htlc.RefundTimeout),
+			OutputIndex:   htlc.OutputIndex,
+			Signature:     nilIfEmpty(htlc.Signature),
+			OnionBlob:     htlc.OnionBlob[:],
+			BlindingPoint: blindingPoint,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to insert htlc: %w", err)
+	}
+
+	for key, value := range htlc.CustomRecords {
+		err := db.InsertHtlcCustomRecord(
+			ctx, sqlc.InsertHtlcCustomRecordParams{
+				HtlcID: htlcID,
+				Key:    int64(key),
+				Value:  value,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to insert htlc custom "+
+				"record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// fetchChanCommitmentSQL fetches the commitment of the given type of the
+// channel. ErrNoCommitmentsFound is returned if no such commitment exists.
+func fetchChanCommitmentSQL(ctx context.Context, db SQLQueries,
+	channelID int64, commitType commitmentType) (ChannelCommitment, error) {
+
+	row, err := db.FetchChannelCommitment(
+		ctx, sqlc.FetchChannelCommitmentParams{
+			ChannelID:      channelID,
+			CommitmentType: int16(commitType),
+		},
+	)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ChannelCommitment{}, ErrNoCommitmentsFound
+
+	case err != nil:
+		return ChannelCommitment{}, fmt.Errorf("unable to fetch "+
+			"commitment: %w", err)
+	}
+
+	var commitTx *wire.MsgTx
+	err = ReadElement(bytes.NewReader(row.CommitTx), &commitTx)
+	if err != nil {
+		return ChannelCommitment{}, err
+	}
+
+	htlcs, err := fetchCommitmentHtlcs(ctx, db, row.ID)
+	if err != nil {
+		return ChannelCommitment{}, err
+	}
+
+	c := ChannelCommitment{
+		CommitHeight:    uint64(row.CommitHeight),
+		LocalLogIndex:   uint64(row.LocalLogIndex),
+		LocalHtlcIndex:  uint64(row.LocalHtlcIndex),
+		RemoteLogIndex:  uint64(row.RemoteLogIndex),
+		RemoteHtlcIndex: uint64(row.RemoteHtlcIndex),
+		LocalBalance:    lnwire.MilliLoki(row.LocalBalanceMsat),
+		RemoteBalance:   lnwire.MilliLoki(row.RemoteBalanceMsat),
+		CommitFee:       chainutil.Amount(row.CommitFee),
+		FeePerKw:        chainutil.Amount(row.FeePerKw),
+		CommitTx:        commitTx,
+		CommitSig:       notNullBytes(row.CommitSig),
+		Htlcs:           htlcs,
+	}
+	if row.CustomBlob != nil {
+		c.CustomBlob = fn.Some(tlv.Blob(row.CustomBlob))
+	}
+
+	return c, nil
+}
+
+// fetchCommitmentHtlcs fetches the HTLCs of the given commitment. Like the KV
+// store, nil is returned if the commitment has no HTLCs.
+func fetchCommitmentHtlcs(ctx context.Context, db SQLQueries,
+	commitmentID int64) ([]HTLC, error) {
+
+	rows, err := db.FetchCommitmentHtlcs(ctx, commitmentID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch htlcs: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	records, err := db.FetchCommitmentHtlcCustomRecords(ctx, commitmentID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch htlc custom records: "+
+			"%w", err)
+	}
+
+	customRecords := make(map[int64]lnwire.CustomRecords)
+	for _, r := range records {
+		if customRecords[r.HtlcID] == nil {
+			customRecords[r.HtlcID] = make(lnwire.CustomRecords)
+		}
+		customRecords[r.HtlcID][uint64(r.Key)] = r.Value
+	}
+
+	htlcs := make([]HTLC, len(rows))
+	for i, row := range rows {
+		if len(row.OnionBlob) != lnwire.OnionPacketSize {
+			return nil, ErrOnionBlobLength
+		}
+
+		htlc := &htlcs[i]
+		htlc.Signature = notNullBytes(row.Signature)
+		copy(htlc.RHash[:], row.PaymentHash)
+		htlc.Amt = lnwire.MilliLoki(row.AmountMsat)
+		htlc.RefundTimeout = uint32(row.RefundTimeout)
+		htlc.OutputIndex = row.OutputIndex
+		htlc.Incoming = row.Incoming
+		copy(htlc.OnionBlob[:], row.OnionBlob)
+		htlc.HtlcIndex = uint64(row.HtlcIndex)
+		htlc.LogIndex = uint64(row.LogIndex)
+
+		if row.BlindingPoint != nil {
+			blindingPoint, err := crypto.ParsePubKey(
+				row.BlindingPoint,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			htlc.BlindingPoint = tlv.SomeRecordT(
+				tlv.NewPrimitiveRecord[lnwire.BlindingPointTlvType](
+					blindingPoint,
+				),
+			)
+		}
+		htlc.CustomRecords = customRecords[row.ID]
+
+		// The extra data of the HTLC is derived from its blinding
+		// point and custom records. We rebuild it and parse it back
+		// the same way the KV store does, so that both stores return
+		// identical HTLCs.
+		if err := serializeHtlcExtraData(htlc); err != nil {
+			return nil, err
+		}
+		if len(htlc.ExtraData) == 0 {
+			htlc.ExtraData = nil
+		}
+
+		htlc.BlindingPoint = lnwire.BlindingPointRecord{}
+		htlc.CustomRecords = nil
+		if err := deserializeHtlcExtraData(htlc); err != nil {
+			return nil, err
+		}
+	}
+
+	return htlcs, nil
+}
+
+// putChanCommitmentsSQL writes the current commitments of both parties of the
+// passed channel. Restored channels don't have any commitments to write.
+func putChanCommitmentsSQL(ctx context.Context, db SQLQueries,
+	channelID int64, channel *OpenChannel) error {
+
+	if channel.HasChanStatusForStore(ChanStatusRestored) {
+		return nil
+	}
+
+	err := putChanCommitmentSQL(
+		ctx, db, channelID, commitmentLocal, &channel.LocalCommitment,
+	)
+	if err != nil {
+		return err
+	}
+
+	return putChanCommitmentSQL(
+		ctx, db, channelID, commitmentRemote, &channel.RemoteCommitment,
+	)
+}
+
+// fetchChanCommitmentsSQL populates the passed channel with the current
+// commitments of both parties.
+func fetchChanCommitmentsSQL(ctx context.Context, db SQLQueries,
+	channelID int64, channel *OpenChannel) error {
+
+	// If this is a restored channel, then we don't have any commitments to
+	// read.
+	if channel.HasChanStatusForStore(ChanStatusRestored) {
+		return nil
+	}
+
+	var err error
+	channel.LocalCommitment, err = fetchChanCommitmentSQL(
+		ctx, db, channelID, commitmentLocal,
+	)
+	if err != nil {
+		return err
+	}
+
+	channel.RemoteCommitment, err = fetchChanCommitmentSQL(
+		ctx, db, channelID, commitmentRemote,
+	)
+
+	return err
+}
+
+// putLogUpdatesSQL replaces the log updates of the given list of the channel
+// with the passed ones.
+func putLogUpdatesSQL(ctx context.Context, db SQLQueries, channelID int64,
+	updateType logUpdateType, updates []LogUpdate) error {
+
+	err := db.DeleteChannelLogUpdates(
+		ctx, sqlc.DeleteChannelLogUpdatesParams{
+			ChannelID:  channelID,
+			UpdateType: int16(updateType),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to delete log updates: %w", err)
+	}
+
+	for _, update := range updates {
+		msgType, msg, err := encodeUpdateMsg(update.UpdateMsg)
+		if err != nil {
+			return err
+		}
+
+		err = db.InsertChannelLogUpdate(
+			ctx, sqlc.InsertChannelLogUpdateParams{
+				ChannelID:  channelID,
+				UpdateType: int16(updateType),
+				LogIndex:   int64(update.LogIndex),
+				MsgType:    msgType,
+				Msg:        msg,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to insert log update: %w",
+				err)
+		}
+	}
+
+	return nil
+}
+
+// fetchLogUpdatesSQL fetches the log updates of the given list of the
+// channel. nil is returned if the list is empty.
+func fetchLogUpdatesSQL(ctx context.Context, db SQLQueries, channelID int64,
+	updateType logUpdateType) ([]LogUpdate, error) {
+
+	rows, err := db.FetchChannelLogUpdates(
+		ctx, sqlc.FetchChannelLogUpdatesParams{
+			ChannelID:  channelID,
+			UpdateType: int16(updateType),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch log updates: %w", err)
+	}
+
+	var updates []LogUpdate
+	for _, row := range rows {
+		msg, err := decodeUpdateMsg(row.MsgType, row.Msg)
+		if err != nil {
+			return nil, err
+		}
+
+		updates = append(updates, LogUpdate{
+			LogIndex:  uint64(row.LogIndex),
+			UpdateMsg: msg,
+		})
+	}
+
+	return updates, nil
+}
+
+// putCommitDiffSQL replaces the pending commitment of the remote party of the
+// channel with the one of the passed commit diff.
+func putCommitDiffSQL(ctx context.Context, db SQLQueries, channelID int64,
+	diff *CommitDiff) error {
+
+	err := putChanCommitmentSQL(
+		ctx, db, channelID, commitmentRemotePending, &diff.Commitment,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := db.DeleteCommitDiff(ctx, channelID); err != nil {
+		return fmt.Errorf("unable to delete commit diff: %w", err)
+	}
+
+	var commitSig bytes.Buffer
+	if err := diff.CommitSig.Encode(&commitSig, 0); err != nil {
+		return err
+	}
+
+	commitDiffID, err := db.InsertCommitDiff(
+		ctx, sqlc.InsertCommitDiffParams{
+			ChannelID: channelID,
+			CommitSig: commitSig.Bytes(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to insert commit diff: %w", err)
+	}
+
+	insertKeys := func(keys []models.CircuitKey, opened bool) error {
+		for _, key := range keys {
+			err := db.InsertCommitDiffCircuitKey(
+				ctx, sqlc.InsertCommitDiffCircuitKeyParams{
+					CommitDiffID: commitDiffID,
+					Opened:       opened,
+					Scid:         int64(key.ChanID.ToUint64()),
+					HtlcID:       int64(key.HtlcID),
+				},
+			)
+			if err != nil {
+				return fmt.Errorf("unable to insert circuit "+
+					"key: %w", err)
+			}
+		}
+
+		return nil
+	}
+	if err := insertKeys(diff.OpenedCircuitKeys, true); err != nil {
+		return err
+	}
+	if err := insertKeys(diff.ClosedCircuitKeys, false); err != nil {
+		return err
+	}
+
+	return putLogUpdatesSQL(
+		ctx, db, channelID, logUpdateCommitDiff, diff.LogUpdates,
+	)
+}
+
+// fetchCommitDiffSQL fetches the commit diff of the pending commitment of the
+// remote party. ErrNoPendingCommit is returned if there is none.
+func fetchCommitDiffSQL(ctx context.Context, db SQLQueries,
+	channelID int64) (*CommitDiff, error) {
+
+	row, err := db.FetchCommitDiff(ctx, channelID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrNoPendingCommit
+
+	case err != nil:
+		return nil, fmt.Errorf("unable to fetch commit diff: %w", err)
+	}
+
+	commitment, err := fetchChanCommitmentSQL(
+		ctx, db, channelID, commitmentRemotePending,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	commitSig := &lnwire.CommitSig{}
+	err = commitSig.Decode(bytes.NewReader(row.CommitSig), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	logUpdates, err := fetchLogUpdatesSQL(
+		ctx, db, channelID, logUpdateCommitDiff,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := db.FetchCommitDiffCircuitKeys(ctx, row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch circuit keys: %w", err)
+	}
+
+	// The lists of a commit diff are never nil when read from the KV
+	// store, so we keep it that way here.
+	diff := &CommitDiff{
+		Commitment:        commitment,
+		LogUpdates:        make([]LogUpdate, 0, len(logUpdates)),
+		CommitSig:         commitSig,
+		OpenedCircuitKeys: make([]models.CircuitKey, 0),
+		ClosedCircuitKeys: make([]models.CircuitKey, 0),
+	}
+	diff.LogUpdates = append(diff.LogUpdates, logUpdates...)
+
+	for _, key := range keys {
+		circuitKey := models.CircuitKey{
+			ChanID: lnwire.NewShortChanIDFromInt(uint64(key.Scid)),
+			HtlcID: uint64(key.HtlcID),
+		}
+
+		if key.Opened {
+			diff.OpenedCircuitKeys = append(
+				diff.OpenedCircuitKeys, circuitKey,
+			)
+		} else {
+			diff.ClosedCircuitKeys = append(
+				diff.ClosedCircuitKeys, circuitKey,
+			)
+		}
+	}
+
+	return diff, nil
+}
+
+// deleteCommitDiffSQL removes the pending commitment of the remote party of
+// the channel together with its commit diff.
+func deleteCommitDiffSQL(ctx context.Context, db SQLQueries,
+	channelID int64) error {
+
+	if err := db.DeleteCommitDiff(ctx, channelID); err != nil {
+		return fmt.Errorf("unable to delete commit diff: %w", err)
+	}
+
+	err := db.DeleteChannelCommitment(
+		ctx, sqlc.DeleteChannelCommitmentParams{
+			ChannelID:      channelID,
+			CommitmentType: int16(commitmentRemotePending),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to delete commitment: %w", err)
+	}
+
+	return putLogUpdatesSQL(ctx, db, channelID, logUpdateCommitDiff, nil)
+}
+
+// putRevocationLogSQL stores the passed revocation log entry of the channel
+// at the given commitment height, replacing any existing one.
+func putRevocationLogSQL(ctx context.Context, db SQLQueries, channelID int64,
+	commitHeight uint64, rl *RevocationLog) error {
+
+	err := db.DeleteRevocationLog(ctx, sqlc.DeleteRevocationLogParams{
+		ChannelID:    channelID,
+		CommitHeight: int64(commitHeight),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to delete revocation log: %w", err)
+	}
+
+	params := sqlc.InsertRevocationLogParams{
+		ChannelID:        channelID,
+		CommitHeight:     int64(commitHeight),
+		OurOutputIndex:   int32(rl.OurOutputIndex.Val),
+		TheirOutputIndex: int32(rl.TheirOutputIndex.Val),
+		CommitTxHash:     rl.CommitTxHash.Val[:],
+	}
+	rl.OurBalance.WhenSomeV(func(balance BigSizeMilliSatoshi) {
+		params.OurBalanceMsat = sqldb.SQLInt64(balance.Int())
+	})
+	rl.TheirBalance.WhenSomeV(func(balance BigSizeMilliSatoshi) {
+		params.TheirBalanceMsat = sqldb.SQLInt64(balance.Int())
+	})
+	rl.CustomBlob.WhenSomeV(func(blob tlv.Blob) {
+		params.CustomBlob = blob
+	})
+
+	logID, err := db.InsertRevocationLog(ctx, params)
+	if err != nil {
+		return fmt.Errorf("unable to insert revocation log: %w", err)
+	}
+
+	for _, htlc := range rl.HTLCEntries {
+		params := sqlc.InsertRevocationLogHtlcParams{
+			RevocationLogID: logID,
+			PaymentHash:     htlc.RHash.Val[:],
+			RefundTimeout:   int64(htlc.RefundTimeout.Val),
+			OutputIndex:     int32(htlc.OutputIndex.Val),
+			Incoming:        htlc.Incoming.Val,
+			Amount:          int64(htlc.Amt.Val.Int()),
+		}
+		htlc.CustomBlob.WhenSomeV(func(blob tlv.Blob) {
+			params.CustomBlob = blob
+		})
+		htlc.HtlcIndex.WhenSomeV(func(index tlv.BigSizeT[uint64]) {
+			params.HtlcIndex = sqldb.SQLInt64(index.Int())
+		})
+
+		if err := db.InsertRevocationLogHtlc(ctx, params); err != nil {
+			return fmt.Errorf("unable to insert revocation log "+
+				"htlc: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// fetchRevocationLogSQL assembles the revocation log entry of the given row
+// together with its HTLCs.
+func fetchRevocationLogSQL(ctx context.Context, db SQLQueries,
+	row sqlc.ChannelRevocationLog) (RevocationLog, error) {
+
+	var commitTxHash [32]byte
+	copy(commitTxHash[:], row.CommitTxHash)
+
+	rl := RevocationLog{
+		OurOutputIndex: tlv.NewPrimitiveRecord[tlv.TlvType0](
+			uint16(row.OurOutputIndex),
+		),
+		TheirOutputIndex: tlv.NewPrimitiveRecord[tlv.TlvType1](
+			uint16(row.TheirOutputIndex),
+		),
+		CommitTxHash: tlv.NewPrimitiveRecord[tlv.TlvType2](
+			commitTxHash,
+		),
+	}
+	if row.OurBalanceMsat.Valid {
+		rl.OurBalance = tlv.SomeRecordT(tlv.NewRecordT[tlv.TlvType3](
+			tlv.NewBigSizeT(
+				lnwire.MilliLoki(row.OurBalanceMsat.Int64),
+			),
+		))
+	}
+	if row.TheirBalanceMsat.Valid {
+		rl.TheirBalance = tlv.SomeRecordT(tlv.NewRecordT[tlv.TlvType4](
+			tlv.NewBigSizeT(
+				lnwire.MilliLoki(row.TheirBalanceMsat.Int64),
+			),
+		))
+	}
+	if row.CustomBlob != nil {
+		rl.CustomBlob = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType5, tlv.Blob](
+				row.CustomBlob,
+			),
+		)
+	}
+
+	htlcRows, err := db.FetchRevocationLogHtlcs(ctx, row.ID)
+	if err != nil {
+		return RevocationLog{}, fmt.Errorf("unable to fetch revocation "+
+			"log htlcs: %w", err)
+	}
+
+	for _, htlcRow := range htlcRows {
+		var rHash [32]byte
+		copy(rHash[:], htlcRow.PaymentHash)
+
+		htlc := &HTLCEntry{
+			RHash: tlv.NewRecordT[tlv.TlvType0](
+				NewSparsePayHash(rHash),
+			),
+			RefundTimeout: tlv.NewPrimitiveRecord[tlv.TlvType1](
+				uint32(htlcRow.RefundTimeout),
+			),
+			OutputIndex: tlv.NewPrimitiveRecord[tlv.TlvType2](
+				uint16(htlcRow.OutputIndex),
+			),
+			Incoming: tlv.NewPrimitiveRecord[tlv.TlvType3](
+				htlcRow.Incoming,
+			),
+			Amt: tlv.NewRecordT[tlv.TlvType4](
+				tlv.NewBigSizeT(chainutil.Amount(htlcRow.Amount)),
+			),
+		}
+		if htlcRow.CustomBlob != nil {
+			htlc.CustomBlob = tlv.SomeRecordT(
+				tlv.NewPrimitiveRecord[tlv.TlvType5, tlv.Blob](
+					htlcRow.CustomBlob,
+				),
+			)
+		}
+		if htlcRow.HtlcIndex.Valid {
+			htlc.HtlcIndex = tlv.SomeRecordT(
+				tlv.NewRecordT[tlv.TlvType6](tlv.NewBigSizeT(
+					uint64(htlcRow.HtlcIndex.Int64),
+				)),
+			)
+		}
+
+		rl.HTLCEntries = append(rl.HTLCEntries, htlc)
+	}
+
+	return rl, nil
+}
+
+// putCloseSummarySQL stores the passed close summary of the channel with the
+// given funding outpoint.
+func putCloseSummarySQL(ctx context.Context, db SQLQueries,
+	chanPoint *wire.OutPoint, summary *ChannelCloseSummary) error {
+
+	opBytes, err := serializeOutpoint(chanPoint)
+	if err != nil {
+		return err
+	}
+
+	cid := lnwire.NewChanIDFromOutPoint(*chanPoint)
+	params := sqlc.UpsertChannelCloseSummaryParams{
+		Outpoint:          opBytes,
+		ChanID:            cid[:],
+		Scid:              int64(summary.ShortChanID.ToUint64()),
+		ChainHash:         summary.ChainHash[:],
+		ClosingTxid:       summary.ClosingTXID[:],
+		CloseHeight:       int64(summary.CloseHeight),
+		RemotePub:         summary.RemotePub.SerializeCompressed(),
+		Capacity:          int64(summary.Capacity),
+		SettledBalance:    int64(summary.SettledBalance),
+		TimeLockedBalance: int64(summary.TimeLockedBalance),
+		CloseType:         int16(summary.CloseType),
+		IsPending:         summary.IsPending,
+	}
+
+	// Summaries created before the revocation points were stored don't
+	// have any of the optional fields.
+	if summary.RemoteCurrentRevocation != nil {
+		params.RemoteCurrentRevocation =
+			summary.RemoteCurrentRevocation.SerializeCompressed()
+
+		var chanConfig bytes.Buffer
+		err := writeChanConfig(&chanConfig, &summary.LocalChanConfig)
+		if err != nil {
+			return err
+		}
+		params.LocalChanConfig = chanConfig.Bytes()
+
+		if summary.RemoteNextRevocation != nil {
+			params.RemoteNextRevocation =
+				summary.RemoteNextRevocation.SerializeCompressed()
+		}
+
+		if summary.LastChanSyncMsg != nil {
+			var msg bytes.Buffer
+			err := summary.LastChanSyncMsg.Encode(&msg, 0)
+			if err != nil {
+				return err
+			}
+			params.LastChanSyncMsg = msg.Bytes()
+		}
+	}
+
+	if err := db.UpsertChannelCloseSummary(ctx, params); err != nil {
+		return fmt.Errorf("unable to store close summary: %w", err)
+	}
+
+	return nil
+}
+
+// unmarshalCloseSummary assembles the close summary stored in the passed row.
+func unmarshalCloseSummary(row sqlc.ChannelCloseSummary) (
+	*ChannelCloseSummary, error) {
+
+	summary := &ChannelCloseSummary{
+		ShortChanID: lnwire.NewShortChanIDFromInt(uint64(row.Scid)),
+		CloseHeight: uint32(row.CloseHeight),
+		Capacity:    chainutil.Amount(row.Capacity),
+		SettledBalance: chainutil.Amount(
+			row.SettledBalance,
+		),
+		TimeLockedBalance: chainutil.Amount(row.TimeLockedBalance),
+		CloseType:         ClosureType(row.CloseType),
+		IsPending:         row.IsPending,
+	}
+	copy(summary.ChainHash[:], row.ChainHash)
+	copy(summary.ClosingTXID[:], row.ClosingTxid)
+
+	err := graphdb.ReadOutpoint(
+		bytes.NewReader(row.Outpoint), &summary.ChanPoint,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.RemotePub, err = crypto.ParsePubKey(row.RemotePub)
+	if err != nil {
+		return nil, err
+	}
+
+	if row.RemoteCurrentRevocation == nil {
+		return summary, nil
+	}
+
+	summary.RemoteCurrentRevocation, err = crypto.ParsePubKey(
+		row.RemoteCurrentRevocation,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = readChanConfig(
+		bytes.NewReader(row.LocalChanConfig), &summary.LocalChanConfig,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if row.RemoteNextRevocation != nil {
+		summary.RemoteNextRevocation, err = crypto.ParsePubKey(
+			row.RemoteNextRevocation,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if row.LastChanSyncMsg != nil {
+		chanSync := &lnwire.ChannelReestablish{}
+		err := chanSync.Decode(bytes.NewReader(row.LastChanSyncMsg), 0)
+		if err != nil {
+			return nil, err
+		}
+		summary.LastChanSyncMsg = chanSync
+	}
+
+	return summary, nil
+}
+
+// addFwdPkgSQL writes a newly locked in forwarding package.
+func addFwdPkgSQL(ctx context.Context, db SQLQueries, fwdPkg *FwdPkg) error {
+	source := int64(fwdPkg.Source.ToUint64())
+	row, err := db.FetchFwdPkg(ctx, sqlc.FetchFwdPkgParams{
+		SourceScid: source,
+		Height:     int64(fwdPkg.Height),
+	})
+	fwdPkgID := row.ID
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		fwdPkgID, err = db.InsertFwdPkg(ctx, sqlc.InsertFwdPkgParams{
+			SourceScid: source,
+			Height:     int64(fwdPkg.Height),
+		})
+		if err != nil {
+			return fmt.Errorf("unable to insert forwarding "+
+				"package: %w", err)
+		}
+
+	case err != nil:
+		return fmt.Errorf("unable to fetch forwarding package: %w", err)
+	}
+
+	err = putFwdPkgUpdates(
+		ctx, db, fwdPkgID, fwdPkgUpdateAdd, fwdPkg.Adds,
+		fwdPkg.AckFilter,
+	)
+	if err != nil {
+		return err
+	}
+
+	return putFwdPkgUpdates(
+		ctx, db, fwdPkgID, fwdPkgUpdateSettleFail, fwdPkg.SettleFails,
+		fwdPkg.SettleFailFilter,
+	)
+}
+
+// putFwdPkgUpdates writes the updates of the given kind of a forwarding
+// package. An update is marked as acked if the passed filter contains it.
+func putFwdPkgUpdates(ctx context.Context, db SQLQueries, fwdPkgID int64,
+	updateType fwdPkgUpdateType, updates []LogUpdate,
+	ackFilter *PkgFilter) error {
+
+	for i, update := range updates {
+		msgType, msg, err := encodeUpdateMsg(update.UpdateMsg)
+		if err != nil {
+			return err
+		}
+
+		err = db.UpsertFwdPkgUpdate(ctx, sqlc.UpsertFwdPkgUpdateParams{
+			FwdPkgID:    fwdPkgID,
+			UpdateType:  int16(updateType),
+			UpdateIndex: int32(i),
+			LogIndex:    int64(update.LogIndex),
+			MsgType:     msgType,
+			Msg:         msg,
+			Acked:       ackFilter.Contains(uint16(i)),
+		})
+		if err != nil {
+			return fmt.Errorf("unable to store forwarding package "+
+				"update: %w", err)
+		}
+	}
+
+	// Drop any updates left over from a previous version of the package.
+	err := db.DeleteFwdPkgUpdatesFrom(
+		ctx, sqlc.DeleteFwdPkgUpdatesFromParams{
+			FwdPkgID:    fwdPkgID,
+			UpdateType:  int16(updateType),
+			UpdateIndex: int32(len(updates)),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to delete forwarding package "+
+			"updates: %w", err)
+	}
+
+	return nil
+}
+
+// fetchFwdPkgSQL assembles the forwarding package of the given row together
+// with its updates and determines its FwdState.
+func fetchFwdPkgSQL(ctx context.Context, db SQLQueries,
+	source lnwire.ShortChannelID,
+	row sqlc.ChannelForwardingPackage) (*FwdPkg, error) {
+
+	rows, err := db.FetchFwdPkgUpdates(ctx, row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch forwarding package "+
+			"updates: %w", err)
+	}
+
+	// Like the KV store, the updates of a kind are nil if there are none.
+	var (
+		adds, settleFails            []LogUpdate
+		acked, settleFailAcked, fwds []uint16
+	)
+	for _, updateRow := range rows {
+		msg, err := decodeUpdateMsg(updateRow.MsgType, updateRow.Msg)
+		if err != nil {
+			return nil, err
+		}
+
+		update := LogUpdate{
+			LogIndex:  uint64(updateRow.LogIndex),
+			UpdateMsg: msg,
+		}
+		index := uint16(updateRow.UpdateIndex)
+
+		switch fwdPkgUpdateType(updateRow.UpdateType) {
+		case fwdPkgUpdateAdd:
+			adds = append(adds, update)
+			if updateRow.Acked {
+				acked = append(acked, index)
+			}
+			if updateRow.Forwarded {
+				fwds = append(fwds, index)
+			}
+
+		case fwdPkgUpdateSettleFail:
+			settleFails = append(settleFails, update)
+			if updateRow.Acked {
+				settleFailAcked = append(settleFailAcked, index)
+			}
+
+		default:
+			return nil, fmt.Errorf("unknown forwarding package "+
+				"update type: %v", updateRow.UpdateType)
+		}
+	}
+
+	newFilter := func(count int, indexes []uint16) *PkgFilter {
+		filter := NewPkgFilter(uint16(count))
+		for _, index := range indexes {
+			filter.Set(index)
+		}
+
+		return filter
+	}
+
+	fwdPkg := &FwdPkg{
+		Source:      source,
+		State:       FwdStateLockedIn,
+		Height:      uint64(row.Height),
+		Adds:        adds,
+		AckFilter:   newFilter(len(adds), acked),
+		SettleFails: settleFails,
+		SettleFailFilter: newFilter(
+			len(settleFails), settleFailAcked,
+		),
+		FwdFilter: newFilter(len(adds), fwds),
+	}
+
+	// Packages that haven't been processed yet have an empty forward
+	// filter.
+	if !row.Processed {
+		return fwdPkg, nil
+	}
+
+	// Mark the package as processed. If every add, settle, and fail has
+	// been fully acknowledged, the package can be garbage collected.
+	fwdPkg.State = FwdStateProcessed
+	if fwdPkg.AckFilter.IsFull() && fwdPkg.SettleFailFilter.IsFull() {
+		fwdPkg.State = FwdStateCompleted
+	}
+
+	return fwdPkg, nil
+}
+
+// ackFwdPkgUpdates marks the updates of the given kind with the passed indexes
+// of the forwarding package at the given height as acked. If the package
+// doesn't exist, this could be because it was already removed, so there is
+// nothing to update.
+func ackFwdPkgUpdates(ctx context.Context, db SQLQueries,
+	source lnwire.ShortChannelID, height uint64,
+	updateType fwdPkgUpdateType, indexes []uint16) error {
+
+	row, err := db.FetchFwdPkg(ctx, sqlc.FetchFwdPkgParams{
+		SourceScid: int64(source.ToUint64()),
+		Height:     int64(height),
+	})
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+
+	case err != nil:
+		return fmt.Errorf("unable to fetch forwarding package: %w", err)
+	}
+
+	for _, index := range indexes {
+		err := db.AckFwdPkgUpdate(ctx, sqlc.AckFwdPkgUpdateParams{
+			FwdPkgID:    row.ID,
+			UpdateType:  int16(updateType),
+			UpdateIndex: int32(index),
+		})
+		if err != nil {
+			return fmt.Errorf("unable to ack forwarding package "+
+				"update: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// setFwdFilterSQL marks the adds of the passed forwarding package row that
+// the given filter contains as forwarded and the package as processed. If
+// the package has already been processed, the call is a no-op.
+func setFwdFilterSQL(ctx context.Context, db SQLQueries,
+	row sqlc.ChannelForwardingPackage, fwdFilter *PkgFilter) error {
+
+	if row.Processed {
+		return nil
+	}
+
+	for i := uint16(0); i < fwdFilter.Count(); i++ {
+		if !fwdFilter.Contains(i) {
+			continue
+		}
+
+		err := db.SetFwdPkgUpdateForwarded(
+			ctx, sqlc.SetFwdPkgUpdateForwardedParams{
+				FwdPkgID:    row.ID,
+				UpdateType:  int16(fwdPkgUpdateAdd),
+				UpdateIndex: int32(i),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to mark add as forwarded: "+
+				"%w", err)
+		}
+	}
+
+	if err := db.MarkFwdPkgProcessed(ctx, row.ID); err != nil {
+		return fmt.Errorf("unable to mark forwarding package as "+
+			"processed: %w", err)
+	}
+
+	return nil
+}
+
+// encodeUpdateMsg returns the type and the wire encoded body of the passed
+// update message.
+func encodeUpdateMsg(msg lnwire.Message) (int32, []byte, error) {
+	var b bytes.Buffer
+	if err := msg.Encode(&b, 0); err != nil {
+		return 0, nil, err
+	}
+
+	return int32(msg.MsgType()), b.Bytes(), nil
+}
+
+// decodeUpdateMsg decodes the update message of the given type from its wire
+// encoded body.
+func decodeUpdateMsg(msgType int32, b []byte) (lnwire.Message, error) {
+	msg, err := lnwire.MakeEmptyMessage(lnwire.MessageType(msgType))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := msg.Decode(bytes.NewReader(b), 0); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// notNullBytes returns an empty byte slice in place of nil. Byte slices read
+// from the KV store are never nil, so this keeps both stores consistent.
+func notNullBytes(b []byte) []byte {
+	if b == nil {
+		return []byte{}
+	}
+
+	return b
+}
@@ -0,0 +1,594 @@
+package channeldb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+)
+
+// SQLForwardingLogQueries is an interface that defines the set of operations
+// that can be executed against the forwarding log SQL database.
+type SQLForwardingLogQueries interface { //nolint:interfacebloat
+	InsertForwardingEvent(ctx context.Context,
+		arg sqlc.InsertForwardingEventParams) (int64, error)
+
+	FetchForwardingEvent(ctx context.Context, id int64) (
+		sqlc.ForwardingEvent, error)
+
+	FetchForwardingEvents(ctx context.Context,
+		arg sqlc.FetchForwardingEventsParams) ([]sqlc.ForwardingEvent,
+		error)
+
+	FetchForwardingEventsByIncomingChans(ctx context.Context,
+		arg sqlc.FetchForwardingEventsByIncomingChansParams) (
+		[]sqlc.ForwardingEvent, error)
+
+	FetchForwardingEventsByOutgoingChans(ctx context.Context,
+		arg sqlc.FetchForwardingEventsByOutgoingChansParams) (
+		[]sqlc.ForwardingEvent, error)
+
+	FetchForwardingEventsByChans(ctx context.Context,
+		arg sqlc.FetchForwardingEventsByChansParams) (
+		[]sqlc.ForwardingEvent, error)
+
+	DeleteForwardingEventsUpTo(ctx context.Context,
+		arg sqlc.DeleteForwardingEventsUpToParams) error
+
+	AggregateForwardingEventsByIncomingChan(ctx context.Context,
+		arg sqlc.AggregateForwardingEventsByIncomingChanParams) (
+		[]sqlc.AggregateForwardingEventsByIncomingChanRow, error)
+
+	AggregateForwardingEventsByOutgoingChan(ctx context.Context,
+		arg sqlc.AggregateForwardingEventsByOutgoingChanParams) (
+		[]sqlc.AggregateForwardingEventsByOutgoingChanRow, error)
+
+	AggregateForwardingEventsByIncomingPeer(ctx context.Context,
+		arg sqlc.AggregateForwardingEventsByIncomingPeerParams) (
+		[]sqlc.AggregateForwardingEventsByIncomingPeerRow, error)
+
+	AggregateForwardingEventsByOutgoingPeer(ctx context.Context,
+		arg sqlc.AggregateForwardingEventsByOutgoingPeerParams) (
+		[]sqlc.AggregateForwardingEventsByOutgoingPeerRow, error)
+
+	AggregateForwardingEventsByTime(ctx context.Context,
+		arg sqlc.AggregateForwardingEventsByTimeParams) (
+		[]sqlc.AggregateForwardingEventsByTimeRow, error)
+}
+
+// BatchedSQLForwardingLogQueries is a version of the SQLForwardingLogQueries
+// that's capable of batched database operations.
+type BatchedSQLForwardingLogQueries interface {
+	SQLForwardingLogQueries
+
+	sqldb.BatchedTx[SQLForwardingLogQueries]
+}
+
+// SQLForwardingLog implements the forwarding log on top of a native SQL
+// database. Unlike the KV forwarding log, the events are indexed by their
+// channels and peers, which allows filtering and aggregating them without
+// scanning the whole log.
+type SQLForwardingLog struct {
+	db BatchedSQLForwardingLogQueries
+}
+
+// A compile-time assertion to ensure SQLForwardingLog implements the
+// ForwardingLogStore interface.
+var _ ForwardingLogStore = (*SQLForwardingLog)(nil)
+
+// NewSQLForwardingLog creates a new SQLForwardingLog given an open
+// BatchedSQLForwardingLogQueries storage backend.
+func NewSQLForwardingLog(db BatchedSQLForwardingLogQueries) *SQLForwardingLog {
+	return &SQLForwardingLog{
+		db: db,
+	}
+}
+
+// AddForwardingEvents adds a series of forwarding events to the database.
+// Like the KV forwarding log, the events are sorted and given unique
+// timestamps before they are inserted, so both logs return the events in the
+// same order.
+//
+// NOTE: This is part of the ForwardingLogStore interface.
+func (s *SQLForwardingLog) AddForwardingEvents(events []ForwardingEvent) error {
+	ctx := context.TODO()
+
+	makeUniqueTimestamps(events)
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLForwardingLogQueries) error {
+			for i := range events {
+				_, err := insertForwardingEvent(
+					ctx, db, &events[i],
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}, sqldb.NoOpReset,
+	)
+}
+
+// Query allows a caller to query the forwarding event time series for a
+// particular time slice. If the query filters the events by their channels,
+// the filter is applied by the database using the channel indexes.
+//
+// NOTE: This is part of the ForwardingLogStore interface.
+func (s *SQLForwardingLog) Query(q ForwardingEventQuery) (
+	ForwardingLogTimeSlice, error) {
+
+	ctx := context.TODO()
+
+	var (
+		rows      []sqlc.ForwardingEvent
+		startTime = q.StartTime.UnixNano()
+		endTime   = q.EndTime.UnixNano()
+		incoming  = toSQLChanIDs(q.IncomingChanIDs.ToSlice())
+		outgoing  = toSQLChanIDs(q.OutgoingChanIDs.ToSlice())
+	)
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLForwardingLogQueries) error {
+			var err error
+
+			// Without any filters, the database can apply the
+			// offset and the limit of the query directly.
+			if len(incoming) == 0 && len(outgoing) == 0 {
+				params := sqlc.FetchForwardingEventsParams{
+					StartTime: startTime,
+					EndTime:   endTime,
+					NumLimit:  clampInt32(q.NumMaxEvents),
+					NumOffset: clampInt32(q.IndexOffset),
+				}
+				rows, err = db.FetchForwardingEvents(
+					ctx, params,
+				)
+
+				return err
+			}
+
+			rows, err = fetchFilteredForwardingEvents(
+				ctx, db, startTime, endTime, incoming, outgoing,
+			)
+			if err != nil {
+				return err
+			}
+
+			// The filtered queries return all matching events, so
+			// we apply the offset and the limit here.
+			offset := min(uint64(q.IndexOffset), uint64(len(rows)))
+			rows = rows[offset:]
+
+			limit := min(uint64(q.NumMaxEvents), uint64(len(rows)))
+			rows = rows[:limit]
+
+			return nil
+		}, func() {
+			rows = nil
+		},
+	)
+	if err != nil {
+		return ForwardingLogTimeSlice{}, err
+	}
+
+	resp := ForwardingLogTimeSlice{
+		ForwardingEventQuery: q,
+	}
+	for _, row := range rows {
+		event, err := unmarshalForwardingEvent(row)
+		if err != nil {
+			return ForwardingLogTimeSlice{}, err
+		}
+
+		resp.ForwardingEvents = append(resp.ForwardingEvents, event)
+	}
+	resp.LastIndexOffset = q.IndexOffset + uint32(len(rows))
+
+	return resp, nil
+}
+
+// fetchFilteredForwardingEvents returns all forwarding events in the given
+// time range that were received on one of the incoming channels and forwarded
+// on one of the outgoing channels. An empty list of channels matches all
+// channels.
+func fetchFilteredForwardingEvents(ctx context.Context,
+	db SQLForwardingLogQueries, startTime, endTime int64, incoming,
+	outgoing []int64) ([]sqlc.ForwardingEvent, error) {
+
+	switch {
+	case len(outgoing) == 0:
+		arg := sqlc.FetchForwardingEventsByIncomingChansParams{
+			StartTime:       startTime,
+			EndTime:         endTime,
+			IncomingChanIds: incoming,
+		}
+
+		return db.FetchForwardingEventsByIncomingChans(ctx, arg)
+
+	case len(incoming) == 0:
+		arg := sqlc.FetchForwardingEventsByOutgoingChansParams{
+			StartTime:       startTime,
+			EndTime:         endTime,
+			OutgoingChanIds: outgoing,
+		}
+
+		return db.FetchForwardingEventsByOutgoingChans(ctx, arg)
+
+	default:
+		arg := sqlc.FetchForwardingEventsByChansParams{
+			StartTime:       startTime,
+			EndTime:         endTime,
+			IncomingChanIds: incoming,
+			OutgoingChanIds: outgoing,
+		}
+
+		return db.FetchForwardingEventsByChans(ctx, arg)
+	}
+}
+
+// DeleteForwardingEvents deletes all forwarding events with a timestamp at or
+// before the specified endTime from the database. As with the KV forwarding
+// log, the events are deleted in batches of the given size, each in its own
+// transaction, and statistics about the deleted events are returned.
+//
+// NOTE: This is part of the ForwardingLogStore interface.
+func (s *SQLForwardingLog) DeleteForwardingEvents(ctx context.Context,
+	endTime time.Time, batchSize int) (DeleteStats, error) {
+
+	// Set default batch size if not specified, and enforce maximum.
+	if batchSize <= 0 {
+		batchSize = defaultDeleteBatchSize
+	}
+	if batchSize > MaxResponseEvents {
+		batchSize = MaxResponseEvents
+	}
+
+	var stats DeleteStats
+	for {
+		// Check for cancellation between batches so callers can abort
+		// cleanly.
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		var (
+			batchDeleted int
+			batchFees    int64
+		)
+		err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+			func(db SQLForwardingLogQueries) error {
+				rows, err := db.FetchForwardingEvents(
+					ctx, sqlc.FetchForwardingEventsParams{
+						StartTime: math.MinInt64,
+						EndTime:   endTime.UnixNano(),
+						NumLimit:  int32(batchSize),
+					},
+				)
+				if err != nil || len(rows) == 0 {
+					return err
+				}
+
+				for _, r := range rows {
+					batchFees += r.AmtInMsat - r.AmtOutMsat
+				}
+				batchDeleted = len(rows)
+
+				// As the events are ordered by their timestamp
+				// and ID, the batch consists of exactly the
+				// events up to the last one we fetched.
+				last := rows[len(rows)-1]
+
+				params := sqlc.DeleteForwardingEventsUpToParams{
+					Timestamp: last.Timestamp,
+					ID:        last.ID,
+				}
+
+				return db.DeleteForwardingEventsUpTo(
+					ctx, params,
+				)
+			}, func() {
+				batchDeleted = 0
+				batchFees = 0
+			},
+		)
+		if err != nil {
+			return stats, err
+		}
+
+		// Update our running statistics.
+		stats.NumEventsDeleted += uint64(batchDeleted)
+		stats.TotalFeeMsat += batchFees
+
+		// If we deleted fewer events than the batch size, we're done.
+		if batchDeleted < batchSize {
+			break
+		}
+	}
+
+	return stats, nil
+}
+
+// AggregateForwardingEvents returns the fee and volume totals of the
+// forwarding events in the time range of the given query, grouped by channel,
+// peer or time bucket as requested by the query. The aggregation is done by
+// the database.
+//
+// NOTE: This is part of the ForwardingLogStore interface.
+func (s *SQLForwardingLog) AggregateForwardingEvents(ctx context.Context,
+	q ForwardingAggregateQuery) ([]ForwardingAggregate, error) {
+
+	aggregator, err := newForwardingAggregator(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		startTime = q.StartTime.UnixNano()
+		endTime   = q.EndTime.UnixNano()
+	)
+	err = s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLForwardingLogQueries) error {
+			switch q.GroupBy {
+			case ForwardingGroupChannel:
+				return aggregateByChannel(
+					ctx, db, aggregator, startTime, endTime,
+				)
+
+			case ForwardingGroupPeer:
+				return aggregateByPeer(
+					ctx, db, aggregator, startTime, endTime,
+				)
+
+			default:
+				return aggregateByTime(
+					ctx, db, aggregator, startTime, endTime,
+				)
+			}
+		}, func() {
+			aggregator.aggregates = make(
+				map[any]*ForwardingAggregate,
+			)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregator.result(), nil
+}
+
+// aggregateByChannel adds the totals of the forwarding events in the given
+// time range grouped by their incoming and outgoing channels to the
+// aggregator.
+func aggregateByChannel(ctx context.Context, db SQLForwardingLogQueries,
+	aggregator *forwardingAggregator, startTime, endTime int64) error {
+
+	incoming, err := db.AggregateForwardingEventsByIncomingChan(
+		ctx, sqlc.AggregateForwardingEventsByIncomingChanParams{
+			StartTime: startTime,
+			EndTime:   endTime,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to aggregate by incoming channel: %w",
+			err)
+	}
+
+	for _, row := range incoming {
+		chanID := lnwire.NewShortChanIDFromInt(uint64(row.ChanID))
+		aggregator.channel(chanID).Incoming.add(newForwardingStats(
+			row.NumEvents, row.AmtInMsat, row.AmtOutMsat,
+		))
+	}
+
+	outgoing, err := db.AggregateForwardingEventsByOutgoingChan(
+		ctx, sqlc.AggregateForwardingEventsByOutgoingChanParams{
+			StartTime: startTime,
+			EndTime:   endTime,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to aggregate by outgoing channel: %w",
+			err)
+	}
+
+	for _, row := range outgoing {
+		chanID := lnwire.NewShortChanIDFromInt(uint64(row.ChanID))
+		aggregator.channel(chanID).Outgoing.add(newForwardingStats(
+			row.NumEvents, row.AmtInMsat, row.AmtOutMsat,
+		))
+	}
+
+	return nil
+}
+
+// aggregateByPeer adds the totals of the forwarding events in the given time
+// range grouped by their incoming and outgoing peers to the aggregator.
+func aggregateByPeer(ctx context.Context, db SQLForwardingLogQueries,
+	aggregator *forwardingAggregator, startTime, endTime int64) error {
+
+	incoming, err := db.AggregateForwardingEventsByIncomingPeer(
+		ctx, sqlc.AggregateForwardingEventsByIncomingPeerParams{
+			StartTime: startTime,
+			EndTime:   endTime,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to aggregate by incoming peer: %w",
+			err)
+	}
+
+	for _, row := range incoming {
+		peer, err := route.NewVertexFromBytes(row.Peer)
+		if err != nil {
+			return err
+		}
+
+		aggregator.peer(peer).Incoming.add(newForwardingStats(
+			row.NumEvents, row.AmtInMsat, row.AmtOutMsat,
+		))
+	}
+
+	outgoing, err := db.AggregateForwardingEventsByOutgoingPeer(
+		ctx, sqlc.AggregateForwardingEventsByOutgoingPeerParams{
+			StartTime: startTime,
+			EndTime:   endTime,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to aggregate by outgoing peer: %w",
+			err)
+	}
+
+	for _, row := range outgoing {
+		peer, err := route.NewVertexFromBytes(row.Peer)
+		if err != nil {
+			return err
+		}
+
+		aggregator.peer(peer).Outgoing.add(newForwardingStats(
+			row.NumEvents, row.AmtInMsat, row.AmtOutMsat,
+		))
+	}
+
+	return nil
+}
+
+// aggregateByTime adds the totals of the forwarding events in the given time
+// range grouped into the time buckets of the query to the aggregator.
+func aggregateByTime(ctx context.Context, db SQLForwardingLogQueries,
+	aggregator *forwardingAggregator, startTime, endTime int64) error {
+
+	rows, err := db.AggregateForwardingEventsByTime(
+		ctx, sqlc.AggregateForwardingEventsByTimeParams{
+			BucketSize: int64(aggregator.q.BucketSize),
+			StartTime:  startTime,
+			EndTime:    endTime,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to aggregate by time: %w", err)
+	}
+
+	for _, row := range rows {
+		stats := newForwardingStats(
+			row.NumEvents, row.AmtInMsat, row.AmtOutMsat,
+		)
+
+		agg := aggregator.bucket(row.Bucket)
+		agg.Incoming.add(stats)
+		agg.Outgoing.add(stats)
+	}
+
+	return nil
+}
+
+// newForwardingStats creates forwarding stats from the totals returned by the
+// database.
+func newForwardingStats(numEvents, amtIn, amtOut int64) ForwardingStats {
+	return ForwardingStats{
+		NumEvents: uint64(numEvents),
+		AmtIn:     lnwire.MilliLoki(amtIn),
+		AmtOut:    lnwire.MilliLoki(amtOut),
+	}
+}
+
+// insertForwardingEvent inserts the given forwarding event into the database
+// and returns its ID.
+func insertForwardingEvent(ctx context.Context, db SQLForwardingLogQueries,
+	event *ForwardingEvent) (int64, error) {
+
+	arg := sqlc.InsertForwardingEventParams{
+		Timestamp:      event.Timestamp.UnixNano(),
+		IncomingChanID: int64(event.IncomingChanID.ToUint64()),
+		OutgoingChanID: int64(event.OutgoingChanID.ToUint64()),
+		AmtInMsat:      int64(event.AmtIn),
+		AmtOutMsat:     int64(event.AmtOut),
+	}
+	event.IncomingPeer.WhenSome(func(peer route.Vertex) {
+		arg.IncomingPeer = peer[:]
+	})
+	event.OutgoingPeer.WhenSome(func(peer route.Vertex) {
+		arg.OutgoingPeer = peer[:]
+	})
+	event.IncomingHtlcID.WhenSome(func(id uint64) {
+		arg.IncomingHtlcID = sqldb.SQLInt64(id)
+	})
+	event.OutgoingHtlcID.WhenSome(func(id uint64) {
+		arg.OutgoingHtlcID = sqldb.SQLInt64(id)
+	})
+
+	id, err := db.InsertForwardingEvent(ctx, arg)
+	if err != nil {
+		return 0, fmt.Errorf("unable to insert forwarding event: %w",
+			err)
+	}
+
+	return id, nil
+}
+
+// unmarshalForwardingEvent converts a forwarding event row of the database
+// into a ForwardingEvent.
+func unmarshalForwardingEvent(row sqlc.ForwardingEvent) (ForwardingEvent,
+	error) {
+
+	event := ForwardingEvent{
+		Timestamp: time.Unix(0, row.Timestamp),
+		IncomingChanID: lnwire.NewShortChanIDFromInt(
+			uint64(row.IncomingChanID),
+		),
+		OutgoingChanID: lnwire.NewShortChanIDFromInt(
+			uint64(row.OutgoingChanID),
+		),
+		AmtIn:  lnwire.MilliLoki(row.AmtInMsat),
+		AmtOut: lnwire.MilliLoki(row.AmtOutMsat),
+	}
+
+	if row.IncomingHtlcID.Valid {
+		event.IncomingHtlcID = fn.Some(uint64(row.IncomingHtlcID.Int64))
+	}
+	if row.OutgoingHtlcID.Valid {
+		event.OutgoingHtlcID = fn.Some(uint64(row.OutgoingHtlcID.Int64))
+	}
+
+	if row.IncomingPeer != nil {
+		peer, err := route.NewVertexFromBytes(row.IncomingPeer)
+		if err != nil {
+			return ForwardingEvent{}, err
+		}
+		event.IncomingPeer = fn.Some(peer)
+	}
+	if row.OutgoingPeer != nil {
+		peer, err := route.NewVertexFromBytes(row.OutgoingPeer)
+		if err != nil {
+			return ForwardingEvent{}, err
+		}
+		event.OutgoingPeer = fn.Some(peer)
+	}
+
+	return event, nil
+}
+
+// toSQLChanIDs converts the given short channel IDs into their database
+// representation.
+func toSQLChanIDs(chanIDs []uint64) []int64 {
+	sqlChanIDs := make([]int64, 0, len(chanIDs))
+	for _, chanID := range chanIDs {
+		sqlChanIDs = append(sqlChanIDs, int64(chanID))
+	}
+
+	return sqlChanIDs
+}
+
+// clampInt32 converts the given value to an int32, capping it at the maximum
+// value of an int32.
+func clampInt32(v uint32) int32 {
+	return int32(min(v, math.MaxInt32))
+}
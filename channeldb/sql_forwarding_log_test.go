@@ -0,0 +1,449 @@
+package channeldb
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testFwdPeer1 = route.Vertex{2, 1}
+	testFwdPeer2 = route.Vertex{2, 2}
+
+	testFwdChan1 = lnwire.NewShortChanIDFromInt(1)
+	testFwdChan2 = lnwire.NewShortChanIDFromInt(2)
+	testFwdChan3 = lnwire.NewShortChanIDFromInt(3)
+)
+
+// newSQLTestForwardingLog creates a new SQL forwarding log backed by a fresh
+// sqlite database.
+func newSQLTestForwardingLog(t *testing.T) *SQLForwardingLog {
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) SQLForwardingLogQueries {
+			return db.WithTx(tx)
+		},
+	)
+
+	return NewSQLForwardingLog(executor)
+}
+
+// newTestForwardingLogStores returns a KV and a SQL forwarding log so that
+// tests can assert that both of them behave the same.
+func newTestForwardingLogStores(t *testing.T) map[string]ForwardingLogStore {
+	db, err := MakeTestDB(t)
+	require.NoError(t, err)
+
+	return map[string]ForwardingLogStore{
+		"kv":  db.ForwardingLog(),
+		"sql": newSQLTestForwardingLog(t),
+	}
+}
+
+// newTestFwdEvent creates a forwarding event between the given channels. The
+// peers are only set if both of them are given.
+func newTestFwdEvent(timestamp time.Time, chanIn, chanOut lnwire.ShortChannelID,
+	amtIn, amtOut lnwire.MilliLoki, peers ...route.Vertex) ForwardingEvent {
+
+	event := ForwardingEvent{
+		Timestamp:      timestamp,
+		IncomingChanID: chanIn,
+		OutgoingChanID: chanOut,
+		AmtIn:          amtIn,
+		AmtOut:         amtOut,
+		IncomingHtlcID: fn.Some(uint64(timestamp.Unix())),
+		OutgoingHtlcID: fn.Some(uint64(timestamp.Unix()) + 1),
+	}
+	if len(peers) == 2 {
+		event.IncomingPeer = fn.Some(peers[0])
+		event.OutgoingPeer = fn.Some(peers[1])
+	}
+
+	return event
+}
+
+// testFwdEvents returns a set of forwarding events spread over two hours.
+func testFwdEvents() []ForwardingEvent {
+	start := time.Unix(3600*100, 0)
+
+	return []ForwardingEvent{
+		newTestFwdEvent(
+			start, testFwdChan1, testFwdChan2, 1100, 1000,
+			testFwdPeer1, testFwdPeer2,
+		),
+		newTestFwdEvent(
+			start.Add(time.Minute), testFwdChan1, testFwdChan3,
+			2200, 2000, testFwdPeer1, testFwdPeer2,
+		),
+		newTestFwdEvent(
+			start.Add(time.Hour), testFwdChan2, testFwdChan1,
+			3300, 3000, testFwdPeer2, testFwdPeer1,
+		),
+
+		// The last event is recorded without its peers.
+		newTestFwdEvent(
+			start.Add(time.Hour+time.Minute), testFwdChan3,
+			testFwdChan2, 4400, 4000,
+		),
+	}
+}
+
+// TestForwardingLogStoresQuery asserts that the KV and the SQL forwarding log
+// return the same events for queries with and without channel filters.
+func TestForwardingLogStoresQuery(t *testing.T) {
+	t.Parallel()
+
+	events := testFwdEvents()
+	start := events[0].Timestamp
+	end := events[len(events)-1].Timestamp
+
+	queries := []ForwardingEventQuery{
+		{
+			StartTime:    start,
+			EndTime:      end,
+			NumMaxEvents: 10,
+		},
+		{
+			StartTime:    start,
+			EndTime:      end,
+			IndexOffset:  1,
+			NumMaxEvents: 2,
+		},
+		{
+			StartTime:    start.Add(time.Second),
+			EndTime:      end,
+			NumMaxEvents: 10,
+		},
+		{
+			StartTime:       start,
+			EndTime:         end,
+			NumMaxEvents:    10,
+			IncomingChanIDs: fn.NewSet(testFwdChan1.ToUint64()),
+		},
+		{
+			StartTime:       start,
+			EndTime:         end,
+			IndexOffset:     1,
+			NumMaxEvents:    10,
+			OutgoingChanIDs: fn.NewSet(testFwdChan2.ToUint64()),
+		},
+		{
+			StartTime:    start,
+			EndTime:      end,
+			NumMaxEvents: 1,
+			IncomingChanIDs: fn.NewSet(
+				testFwdChan1.ToUint64(),
+				testFwdChan3.ToUint64(),
+			),
+			OutgoingChanIDs: fn.NewSet(testFwdChan2.ToUint64()),
+		},
+	}
+
+	stores := newTestForwardingLogStores(t)
+	for _, store := range stores {
+		require.NoError(t, store.AddForwardingEvents(testFwdEvents()))
+	}
+
+	for _, q := range queries {
+		kvSlice, err := stores["kv"].Query(q)
+		require.NoError(t, err)
+
+		sqlSlice, err := stores["sql"].Query(q)
+		require.NoError(t, err)
+
+		require.Equal(t, kvSlice, sqlSlice)
+	}
+
+	// The peers are stored by both forwarding logs.
+	timeSlice, err := stores["sql"].Query(queries[0])
+	require.NoError(t, err)
+	require.Equal(t, events, timeSlice.ForwardingEvents)
+}
+
+// TestForwardingLogStoresAggregate asserts that the forwarding events are
+// aggregated by channel, peer and time, and that the KV and the SQL
+// forwarding log return the same aggregates.
+func TestForwardingLogStoresAggregate(t *testing.T) {
+	t.Parallel()
+
+	events := testFwdEvents()
+	start := events[0].Timestamp
+	end := events[len(events)-1].Timestamp
+
+	stats := func(numEvents uint64, amtIn,
+		amtOut lnwire.MilliLoki) ForwardingStats {
+
+		return ForwardingStats{
+			NumEvents: numEvents,
+			AmtIn:     amtIn,
+			AmtOut:    amtOut,
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		query    ForwardingAggregateQuery
+		expected []ForwardingAggregate
+	}{
+		{
+			name: "by channel",
+			query: ForwardingAggregateQuery{
+				StartTime: start,
+				EndTime:   end,
+				GroupBy:   ForwardingGroupChannel,
+			},
+			expected: []ForwardingAggregate{
+				{
+					ChanID:   testFwdChan1,
+					Incoming: stats(2, 3300, 3000),
+					Outgoing: stats(1, 3300, 3000),
+				},
+				{
+					ChanID:   testFwdChan2,
+					Incoming: stats(1, 3300, 3000),
+					Outgoing: stats(2, 5500, 5000),
+				},
+				{
+					ChanID:   testFwdChan3,
+					Incoming: stats(1, 4400, 4000),
+					Outgoing: stats(1, 2200, 2000),
+				},
+			},
+		},
+		{
+			name: "by peer",
+			query: ForwardingAggregateQuery{
+				StartTime: start,
+				EndTime:   end,
+				GroupBy:   ForwardingGroupPeer,
+			},
+			expected: []ForwardingAggregate{
+				{
+					Peer:     testFwdPeer1,
+					Incoming: stats(2, 3300, 3000),
+					Outgoing: stats(1, 3300, 3000),
+				},
+				{
+					Peer:     testFwdPeer2,
+					Incoming: stats(1, 3300, 3000),
+					Outgoing: stats(2, 3300, 3000),
+				},
+			},
+		},
+		{
+			name: "by hour",
+			query: ForwardingAggregateQuery{
+				StartTime:  start,
+				EndTime:    end,
+				GroupBy:    ForwardingGroupTime,
+				BucketSize: time.Hour,
+			},
+			expected: []ForwardingAggregate{
+				{
+					BucketStart: start,
+					Incoming:    stats(2, 3300, 3000),
+					Outgoing:    stats(2, 3300, 3000),
+				},
+				{
+					BucketStart: start.Add(time.Hour),
+					Incoming:    stats(2, 7700, 7000),
+					Outgoing:    stats(2, 7700, 7000),
+				},
+			},
+		},
+		{
+			name: "partial time range",
+			query: ForwardingAggregateQuery{
+				StartTime:  start.Add(time.Minute),
+				EndTime:    start.Add(time.Hour),
+				GroupBy:    ForwardingGroupTime,
+				BucketSize: 24 * time.Hour,
+			},
+			expected: []ForwardingAggregate{
+				{
+					BucketStart: time.Unix(4*86400, 0),
+					Incoming:    stats(2, 5500, 5000),
+					Outgoing:    stats(2, 5500, 5000),
+				},
+			},
+		},
+	}
+
+	stores := newTestForwardingLogStores(t)
+	for _, store := range stores {
+		require.NoError(t, store.AddForwardingEvents(testFwdEvents()))
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, s := range stores {
+				aggregates, err := s.AggregateForwardingEvents(
+					t.Context(), tc.query,
+				)
+				require.NoError(t, err, name)
+				require.Equal(t, tc.expected, aggregates, name)
+			}
+		})
+	}
+
+	// Grouping by time requires a bucket size.
+	for _, store := range stores {
+		_, err := store.AggregateForwardingEvents(
+			t.Context(), ForwardingAggregateQuery{
+				StartTime: start,
+				EndTime:   end,
+				GroupBy:   ForwardingGroupTime,
+			},
+		)
+		require.ErrorIs(t, err, ErrInvalidForwardingBucketSize)
+	}
+}
+
+// TestSQLForwardingLogDeletion asserts that the SQL forwarding log deletes
+// events in batches and returns the same statistics as the KV forwarding log.
+func TestSQLForwardingLogDeletion(t *testing.T) {
+	t.Parallel()
+
+	events := testFwdEvents()
+	stores := newTestForwardingLogStores(t)
+	for _, store := range stores {
+		require.NoError(t, store.AddForwardingEvents(testFwdEvents()))
+	}
+
+	// Delete all but the last event with a batch size that requires
+	// multiple batches.
+	endTime := events[2].Timestamp
+	for name, store := range stores {
+		stats, err := store.DeleteForwardingEvents(
+			t.Context(), endTime, 2,
+		)
+		require.NoError(t, err, name)
+		require.Equal(t, DeleteStats{
+			NumEventsDeleted: 3,
+			TotalFeeMsat:     600,
+		}, stats, name)
+
+		timeSlice, err := store.Query(ForwardingEventQuery{
+			StartTime:    events[0].Timestamp,
+			EndTime:      events[3].Timestamp,
+			NumMaxEvents: 10,
+		})
+		require.NoError(t, err, name)
+		require.Equal(t, events[3:], timeSlice.ForwardingEvents, name)
+	}
+}
+
+// TestMigrateForwardingLogToSQL checks that the forwarding log, including
+// events in the old format without htlc IDs, is migrated from the KV store to
+// the SQL store, and that the missing peers of events are resolved from the
+// channel state.
+func TestMigrateForwardingLogToSQL(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	fullDB, err := MakeTestDB(t)
+	require.NoError(t, err)
+
+	kvLog := fullDB.ForwardingLog()
+	require.NoError(t, kvLog.AddForwardingEvents(testFwdEvents()))
+
+	oldEvent := ForwardingEvent{
+		Timestamp:      time.Unix(1000, 0),
+		IncomingChanID: testFwdChan1,
+		OutgoingChanID: testFwdChan2,
+		AmtIn:          500,
+		AmtOut:         400,
+	}
+	err = writeOldFormatEvents(fullDB, []ForwardingEvent{oldEvent})
+	require.NoError(t, err)
+
+	// An event whose incoming link was already gone when it was recorded
+	// only knows its outgoing peer.
+	oneSided := newTestFwdEvent(
+		time.Unix(2000, 0), testFwdChan3, testFwdChan1, 600, 500,
+	)
+	oneSided.OutgoingPeer = fn.Some(testFwdPeer1)
+	require.NoError(t, kvLog.AddForwardingEvents(
+		[]ForwardingEvent{oneSided},
+	))
+
+	// The first channel is still open, while the third one is closed
+	// already. The second channel is unknown to the channel state.
+	cdb := fullDB.ChannelStateDB()
+	openChan := createTestChannel(
+		t, cdb, channelIDOption(testFwdChan1), openChannelOption(),
+	)
+	closedChan := createTestChannel(
+		t, cdb, channelIDOption(testFwdChan3), openChannelOption(),
+	)
+	err = closedChan.CloseChannel(&ChannelCloseSummary{
+		ChanPoint:               closedChan.FundingOutpoint,
+		ShortChanID:             testFwdChan3,
+		RemotePub:               closedChan.IdentityPub,
+		RemoteCurrentRevocation: closedChan.IdentityPub,
+		RemoteNextRevocation:    closedChan.IdentityPub,
+	})
+	require.NoError(t, err)
+
+	openPeer := route.NewVertex(openChan.IdentityPub)
+	closedPeer := route.NewVertex(closedChan.IdentityPub)
+
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+	genericExecutor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) *sqlc.Queries {
+			return db.WithTx(tx)
+		},
+	)
+	err = genericExecutor.ExecTx(
+		ctx, sqldb.WriteTxOpt(), func(tx *sqlc.Queries) error {
+			return MigrateForwardingLogToSQL(
+				ctx, fullDB.Backend, tx,
+			)
+		}, sqldb.NoOpReset,
+	)
+	require.NoError(t, err)
+
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) SQLForwardingLogQueries {
+			return db.WithTx(tx)
+		},
+	)
+	sqlLog := NewSQLForwardingLog(executor)
+
+	q := ForwardingEventQuery{
+		StartTime:    time.Unix(0, 0),
+		EndTime:      time.Unix(3600*200, 0),
+		NumMaxEvents: 10,
+	}
+	kvSlice, err := kvLog.Query(q)
+	require.NoError(t, err)
+	require.Len(t, kvSlice.ForwardingEvents, 6)
+
+	// The KV store returns the one-sided event as it was recorded.
+	require.True(t, kvSlice.ForwardingEvents[1].IncomingPeer.IsNone())
+	require.Equal(
+		t, fn.Some(testFwdPeer1),
+		kvSlice.ForwardingEvents[1].OutgoingPeer,
+	)
+
+	// The migrated events are the same, except that the peers of the open
+	// and the closed channel were filled in where they were missing.
+	expected := kvSlice.ForwardingEvents
+	expected[0].IncomingPeer = fn.Some(openPeer)
+	expected[1].IncomingPeer = fn.Some(closedPeer)
+	expected[5].IncomingPeer = fn.Some(closedPeer)
+
+	sqlSlice, err := sqlLog.Query(q)
+	require.NoError(t, err)
+	require.Equal(t, expected, sqlSlice.ForwardingEvents)
+	require.True(t, sqlSlice.ForwardingEvents[0].OutgoingPeer.IsNone())
+	require.True(t, sqlSlice.ForwardingEvents[5].OutgoingPeer.IsNone())
+}
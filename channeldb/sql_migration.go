@@ -0,0 +1,978 @@
+package channeldb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	graphdb "github.com/flokiorg/flnd/graph/db"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// channelStateTombstoneKey is the marker that is added to the KV
+	// database once the channel state has been migrated to the native SQL
+	// store. A channel state that has been migrated must never be used
+	// from the KV database again, as it would be outdated and broadcasting
+	// an outdated state leads to the loss of all channel funds.
+	channelStateTombstoneKey = []byte("channel-state-tombstone")
+
+	// ErrDeprecatedRevocationLog is returned by the migration if a channel
+	// still has revocation log entries in the deprecated format.
+	ErrDeprecatedRevocationLog = errors.New("channel has revocation log " +
+		"entries in the deprecated format, please run with " +
+		"--db.prune-revocation before migrating to native SQL")
+)
+
+// MigrateChannelStateToSQL runs the migration of the channel state from the
+// KV database to the SQL database. This covers open and historical channels
+// together with their revocation logs, the close summaries, the forwarding
+// packages, the final htlc resolutions and the state of channels that are in
+// the process of being opened. Every migrated record is read back from the
+// SQL database and compared against the original one.
+//
+// NOTE: Link nodes are not part of the native SQL schema and are therefore
+// left in the KV database.
+func MigrateChannelStateToSQL(ctx context.Context, kvBackend kvdb.Backend,
+	tx *sqlc.Queries) error {
+
+	log.Infof("Starting migration of channel state from KV to SQL")
+
+	s := rate.Sometimes{
+		Interval: 30 * time.Second,
+	}
+
+	var (
+		t0       = time.Now()
+		chunk    int
+		channels int
+	)
+	logProgress := func() {
+		channels++
+		chunk++
+
+		s.Do(func() {
+			elapsed := time.Since(t0).Seconds()
+			ratePerSec := float64(chunk) / elapsed
+			log.Debugf("Migrated %d channels (%.2f channels/sec)",
+				channels, ratePerSec)
+
+			t0 = time.Now()
+			chunk = 0
+		})
+	}
+
+	err := kvdb.View(kvBackend, func(kvTx kvdb.RTx) error {
+		err := migrateOpenChannels(ctx, kvTx, tx, logProgress)
+		if err != nil {
+			return fmt.Errorf("unable to migrate open channels: %w",
+				err)
+		}
+
+		err = migrateHistoricalChannels(ctx, kvTx, tx, logProgress)
+		if err != nil {
+			return fmt.Errorf("unable to migrate historical "+
+				"channels: %w", err)
+		}
+
+		if err := migrateCloseSummaries(ctx, kvTx, tx); err != nil {
+			return fmt.Errorf("unable to migrate close summaries: "+
+				"%w", err)
+		}
+
+		if err := migrateFwdPkgs(ctx, kvTx, tx); err != nil {
+			return fmt.Errorf("unable to migrate forwarding "+
+				"packages: %w", err)
+		}
+
+		if err := migrateFinalHtlcs(ctx, kvTx, tx); err != nil {
+			return fmt.Errorf("unable to migrate final htlcs: %w",
+				err)
+		}
+
+		if err := migrateOpeningStates(ctx, kvTx, tx); err != nil {
+			return fmt.Errorf("unable to migrate channel opening "+
+				"states: %w", err)
+		}
+
+		err = migrateForwardingPolicies(ctx, kvTx, tx)
+		if err != nil {
+			return fmt.Errorf("unable to migrate forwarding "+
+				"policies: %w", err)
+		}
+
+		return nil
+	}, func() {
+		t0 = time.Now()
+		chunk, channels = 0, 0
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Migration of %d channels from KV to SQL completed",
+		channels)
+
+	return nil
+}
+
+// migrateOpenChannels migrates all open channels, including the pending and
+// waiting close ones, together with their revocation logs.
+func migrateOpenChannels(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries, logProgress func()) error {
+
+	openChanBucket := kvTx.ReadBucket(openChannelBucket)
+	if openChanBucket == nil {
+		return nil
+	}
+
+	opBucket := kvTx.ReadBucket(outpointBucket)
+
+	return openChanBucket.ForEach(func(nodePub, v []byte) error {
+		// Ensure that this is a key the same size as a pubkey, and
+		// also that it leads directly to a bucket.
+		if len(nodePub) != 33 || v != nil {
+			return nil
+		}
+
+		nodeChanBucket := openChanBucket.NestedReadBucket(nodePub)
+		if nodeChanBucket == nil {
+			return fmt.Errorf("no bucket for node %x", nodePub)
+		}
+
+		return nodeChanBucket.ForEach(func(chainHash, v []byte) error {
+			// If there's a value, it's not a bucket so ignore it.
+			if v != nil {
+				return nil
+			}
+
+			chainBucket := nodeChanBucket.NestedReadBucket(
+				chainHash,
+			)
+			if chainBucket == nil {
+				return fmt.Errorf("no chain bucket for node %x",
+					nodePub)
+			}
+
+			migrate := func(chanKey, v []byte) error {
+				// Channels that were closed while closed
+				// channels were tombstoned keep their bucket,
+				// they are migrated as historical channels.
+				closed, err := isOutpointClosed(
+					opBucket, chanKey,
+				)
+				if err != nil {
+					return err
+				}
+				if v != nil || closed {
+					return nil
+				}
+
+				chanBucket := chainBucket.NestedReadBucket(
+					chanKey,
+				)
+
+				err = migrateChannel(
+					ctx, tx, chanKey, chanBucket, false,
+				)
+				if err != nil {
+					return fmt.Errorf("unable to migrate "+
+						"channel(%x): %w", chanKey, err)
+				}
+
+				logProgress()
+
+				return nil
+			}
+
+			return chainBucket.ForEach(migrate)
+		})
+	})
+}
+
+// migrateHistoricalChannels migrates the last known state of all closed
+// channels.
+func migrateHistoricalChannels(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries, logProgress func()) error {
+
+	historicalBucket := kvTx.ReadBucket(historicalChannelBucket)
+	if historicalBucket == nil {
+		return nil
+	}
+
+	return historicalBucket.ForEach(func(chanKey, v []byte) error {
+		if v != nil {
+			return nil
+		}
+
+		chanBucket := historicalBucket.NestedReadBucket(chanKey)
+		err := migrateChannel(ctx, tx, chanKey, chanBucket, true)
+		if err != nil {
+			return fmt.Errorf("unable to migrate historical "+
+				"channel(%x): %w", chanKey, err)
+		}
+
+		logProgress()
+
+		return nil
+	})
+}
+
+// migrateChannel migrates the channel stored in the given KV bucket and
+// verifies that the migrated channel matches the original one.
+func migrateChannel(ctx context.Context, tx *sqlc.Queries, chanKey []byte,
+	chanBucket kvdb.RBucket, closed bool) error {
+
+	var chanPoint wire.OutPoint
+	err := graphdb.ReadOutpoint(bytes.NewReader(chanKey), &chanPoint)
+	if err != nil {
+		return err
+	}
+
+	channel, err := fetchOpenChannel(chanBucket, &chanPoint)
+	if err != nil {
+		return err
+	}
+
+	var row sqlc.Channel
+	if err := marshalChannel(&row, channel); err != nil {
+		return err
+	}
+
+	// The remaining channel data is copied as is.
+	nodePub := channel.IdentityPub.SerializeCompressed()
+	cid := lnwire.NewChanIDFromOutPoint(chanPoint)
+	channelID, err := tx.InsertChannelState(
+		ctx, sqlc.InsertChannelStateParams{
+			Outpoint:             chanKey,
+			ChanID:               cid[:],
+			NodePubKey:           nodePub,
+			ChainHash:            channel.ChainHash[:],
+			Closed:               closed,
+			ChanInfo:             row.ChanInfo,
+			RevocationState:      row.RevocationState,
+			LocalShutdownScript:  row.LocalShutdownScript,
+			RemoteShutdownScript: row.RemoteShutdownScript,
+			ThawHeight:           row.ThawHeight,
+			LastWasRevoke:        channel.LastWasRevoke,
+			DataLossCommitPoint: copyBlob(
+				chanBucket.Get(dataLossCommitPointKey),
+			),
+			ForceCloseTx: copyBlob(chanBucket.Get(forceCloseTxKey)),
+			CoopCloseTx:  copyBlob(chanBucket.Get(coopCloseTxKey)),
+			ShutdownInfo: copyBlob(chanBucket.Get(shutdownInfoKey)),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to insert channel: %w", err)
+	}
+
+	err = putChanCommitmentsSQL(ctx, tx, channelID, channel)
+	if err != nil {
+		return err
+	}
+
+	err = migrateChannelUpdates(ctx, tx, channelID, chanBucket)
+	if err != nil {
+		return err
+	}
+
+	err = migrateRevocationLogs(ctx, tx, channelID, chanBucket)
+	if err != nil {
+		return err
+	}
+
+	// Read the channel back and make sure it matches the original one.
+	migratedRow, err := tx.FetchChannelStateByOutpoint(ctx, chanKey)
+	if err != nil {
+		return fmt.Errorf("unable to fetch migrated channel: %w", err)
+	}
+
+	migrated, err := (&SQLStore{}).unmarshalChannel(ctx, tx, migratedRow)
+	if err != nil {
+		return err
+	}
+
+	channel.Db = nil
+	migrated.Db = nil
+
+	return sqldb.CompareRecords(channel, migrated, "channel")
+}
+
+// migrateChannelUpdates migrates the pending commitment of the remote party
+// and the log updates that need to be restored after a restart of the channel
+// stored in the given KV bucket.
+func migrateChannelUpdates(ctx context.Context, tx *sqlc.Queries,
+	channelID int64, chanBucket kvdb.RBucket) error {
+
+	if diffBytes := chanBucket.Get(commitDiffKey); diffBytes != nil {
+		diff, err := deserializeCommitDiff(bytes.NewReader(diffBytes))
+		if err != nil {
+			return err
+		}
+
+		if err := putCommitDiffSQL(ctx, tx, channelID, diff); err != nil {
+			return err
+		}
+
+		migrated, err := fetchCommitDiffSQL(ctx, tx, channelID)
+		if err != nil {
+			return err
+		}
+
+		err = sqldb.CompareRecords(diff, migrated, "commit diff")
+		if err != nil {
+			return err
+		}
+	}
+
+	updateKeys := []struct {
+		key        []byte
+		updateType logUpdateType
+	}{
+		{unsignedAckedUpdatesKey, logUpdateUnsignedAcked},
+		{remoteUnsignedLocalUpdatesKey, logUpdateRemoteUnsignedLocal},
+	}
+	for _, updateKey := range updateKeys {
+		updateBytes := chanBucket.Get(updateKey.key)
+		if updateBytes == nil {
+			continue
+		}
+
+		updates, err := deserializeLogUpdates(
+			bytes.NewReader(updateBytes),
+		)
+		if err != nil {
+			return err
+		}
+
+		err = putLogUpdatesSQL(
+			ctx, tx, channelID, updateKey.updateType, updates,
+		)
+		if err != nil {
+			return err
+		}
+
+		migrated, err := fetchLogUpdatesSQL(
+			ctx, tx, channelID, updateKey.updateType,
+		)
+		if err != nil {
+			return err
+		}
+
+		// The SQL store doesn't distinguish between an empty and a
+		// missing list of updates.
+		if len(updates) == 0 {
+			updates = nil
+		}
+
+		err = sqldb.CompareRecords(
+			updates, migrated, fmt.Sprintf("log updates %x",
+				updateKey.key),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateRevocationLogs migrates the revocation log of the channel stored in
+// the given KV bucket.
+func migrateRevocationLogs(ctx context.Context, tx *sqlc.Queries,
+	channelID int64, chanBucket kvdb.RBucket) error {
+
+	// The native SQL schema only holds revocation logs in the current
+	// format, so the deprecated entries need to be pruned first.
+	oldLogs := chanBucket.NestedReadBucket(revocationLogBucketDeprecated)
+	if oldLogs != nil {
+		k, _ := oldLogs.ReadCursor().First()
+		if k != nil {
+			return ErrDeprecatedRevocationLog
+		}
+	}
+
+	logBucket := chanBucket.NestedReadBucket(revocationLogBucket)
+	if logBucket == nil {
+		return nil
+	}
+
+	return logBucket.ForEach(func(k, v []byte) error {
+		if len(k) != 8 {
+			return fmt.Errorf("invalid revocation log key %x", k)
+		}
+		height := byteOrder.Uint64(k)
+
+		log, err := deserializeRevocationLog(bytes.NewReader(v))
+		if err != nil {
+			return err
+		}
+
+		err = putRevocationLogSQL(ctx, tx, channelID, height, &log)
+		if err != nil {
+			return err
+		}
+
+		row, err := tx.FetchRevocationLog(
+			ctx, sqlc.FetchRevocationLogParams{
+				ChannelID:    channelID,
+				CommitHeight: int64(height),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to fetch migrated "+
+				"revocation log: %w", err)
+		}
+
+		migrated, err := fetchRevocationLogSQL(ctx, tx, row)
+		if err != nil {
+			return err
+		}
+
+		return sqldb.CompareRecords(
+			log, migrated, fmt.Sprintf("revocation log %d", height),
+		)
+	})
+}
+
+// migrateCloseSummaries migrates the close summaries of all closed channels.
+func migrateCloseSummaries(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries) error {
+
+	closeBucket := kvTx.ReadBucket(closedChannelBucket)
+	if closeBucket == nil {
+		return nil
+	}
+
+	return closeBucket.ForEach(func(chanKey, summaryBytes []byte) error {
+		summary, err := deserializeCloseChannelSummary(
+			bytes.NewReader(summaryBytes),
+		)
+		if err != nil {
+			return err
+		}
+
+		var chanPoint wire.OutPoint
+		err = graphdb.ReadOutpoint(bytes.NewReader(chanKey), &chanPoint)
+		if err != nil {
+			return err
+		}
+
+		err = putCloseSummarySQL(ctx, tx, &chanPoint, summary)
+		if err != nil {
+			return err
+		}
+
+		row, err := tx.FetchChannelCloseSummary(ctx, chanKey)
+		if err != nil {
+			return fmt.Errorf("unable to fetch migrated close "+
+				"summary: %w", err)
+		}
+
+		migrated, err := unmarshalCloseSummary(row)
+		if err != nil {
+			return err
+		}
+
+		return sqldb.CompareRecords(
+			summary, migrated, fmt.Sprintf("close summary %v",
+				summary.ChanPoint),
+		)
+	})
+}
+
+// migrateFwdPkgs migrates the forwarding packages of all channels.
+func migrateFwdPkgs(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries) error {
+
+	fwdPkgBkt := kvTx.ReadBucket(fwdPackagesKey)
+	if fwdPkgBkt == nil {
+		return nil
+	}
+
+	return fwdPkgBkt.ForEach(func(sourceKey, _ []byte) error {
+		if len(sourceKey) != 8 {
+			return nil
+		}
+		source := lnwire.NewShortChanIDFromInt(
+			byteOrder.Uint64(sourceKey),
+		)
+
+		fwdPkgs, err := loadChannelFwdPkgs(kvTx, source)
+		if err != nil {
+			return err
+		}
+
+		for _, fwdPkg := range fwdPkgs {
+			if err := addFwdPkgSQL(ctx, tx, fwdPkg); err != nil {
+				return err
+			}
+
+			row, err := tx.FetchFwdPkg(ctx, sqlc.FetchFwdPkgParams{
+				SourceScid: int64(source.ToUint64()),
+				Height:     int64(fwdPkg.Height),
+			})
+			if err != nil {
+				return fmt.Errorf("unable to fetch migrated "+
+					"forwarding package: %w", err)
+			}
+
+			// Packages that haven't been processed yet don't have
+			// a persisted forwarding filter.
+			if fwdPkg.State != FwdStateLockedIn {
+				err := setFwdFilterSQL(
+					ctx, tx, row, fwdPkg.FwdFilter,
+				)
+				if err != nil {
+					return err
+				}
+				row.Processed = true
+			}
+
+			migrated, err := fetchFwdPkgSQL(ctx, tx, source, row)
+			if err != nil {
+				return err
+			}
+
+			err = sqldb.CompareRecords(
+				fwdPkg, migrated, fmt.Sprintf("forwarding "+
+					"package %v/%d", source, fwdPkg.Height),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// migrateFinalHtlcs migrates the stored final htlc resolutions.
+func migrateFinalHtlcs(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries) error {
+
+	finalHtlcs := kvTx.ReadBucket(finalHtlcsBucket)
+	if finalHtlcs == nil {
+		return nil
+	}
+
+	return finalHtlcs.ForEach(func(scidKey, _ []byte) error {
+		chanBucket := finalHtlcs.NestedReadBucket(scidKey)
+		if len(scidKey) != 8 || chanBucket == nil {
+			return nil
+		}
+		scid := int64(byteOrder.Uint64(scidKey))
+
+		return chanBucket.ForEach(func(k, v []byte) error {
+			if len(k) != 8 || len(v) != 1 {
+				return fmt.Errorf("invalid final htlc entry "+
+					"%x: %x", k, v)
+			}
+
+			finalHtlcByte := FinalHtlcByte(v[0])
+			arg := sqlc.UpsertFinalHtlcParams{
+				Scid:      scid,
+				HtlcIndex: int64(byteOrder.Uint64(k)),
+				Settled: finalHtlcByte&
+					FinalHtlcSettledBit != 0,
+				Offchain: finalHtlcByte&
+					FinalHtlcOffchainBit != 0,
+			}
+			if err := tx.UpsertFinalHtlc(ctx, arg); err != nil {
+				return err
+			}
+
+			row, err := tx.FetchFinalHtlc(
+				ctx, sqlc.FetchFinalHtlcParams{
+					Scid:      arg.Scid,
+					HtlcIndex: arg.HtlcIndex,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			return sqldb.CompareRecords(
+				[2]bool{arg.Settled, arg.Offchain},
+				[2]bool{row.Settled, row.Offchain},
+				fmt.Sprintf("final htlc %d/%d", arg.Scid,
+					arg.HtlcIndex),
+			)
+		})
+	})
+}
+
+// migrateOpeningStates migrates the state of channels that are in the process
+// of being opened.
+func migrateOpeningStates(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries) error {
+
+	bucket := kvTx.ReadBucket(channelOpeningStateBucket)
+	if bucket == nil {
+		return nil
+	}
+
+	return bucket.ForEach(func(k, v []byte) error {
+		if v == nil {
+			return nil
+		}
+
+		err := tx.UpsertChannelOpeningState(
+			ctx, sqlc.UpsertChannelOpeningStateParams{
+				Outpoint: copyBlob(k),
+				State:    copyBlob(v),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		migrated, err := tx.FetchChannelOpeningState(ctx, k)
+		if err != nil {
+			return err
+		}
+
+		return sqldb.CompareRecords(
+			v, migrated, fmt.Sprintf("opening state %x", k),
+		)
+	})
+}
+
+// migrateForwardingPolicies migrates the initial forwarding policies of
+// channels that are in the process of being opened.
+func migrateForwardingPolicies(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries) error {
+
+	bucket := kvTx.ReadBucket(initialChannelForwardingPolicyBucket)
+	if bucket == nil {
+		return nil
+	}
+
+	return bucket.ForEach(func(k, v []byte) error {
+		if len(k) != 32 || len(v) != 36 {
+			return fmt.Errorf("invalid forwarding policy entry "+
+				"%x: %x", k, v)
+		}
+
+		arg := sqlc.UpsertChannelForwardingPolicyParams{
+			ChanID:        copyBlob(k),
+			MinHtlcMsat:   int64(byteOrder.Uint64(v[:8])),
+			MaxHtlcMsat:   int64(byteOrder.Uint64(v[8:16])),
+			BaseFeeMsat:   int64(byteOrder.Uint64(v[16:24])),
+			FeeRate:       int64(byteOrder.Uint64(v[24:32])),
+			TimeLockDelta: int64(byteOrder.Uint32(v[32:36])),
+		}
+		err := tx.UpsertChannelForwardingPolicy(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		row, err := tx.FetchChannelForwardingPolicy(ctx, k)
+		if err != nil {
+			return err
+		}
+
+		return sqldb.CompareRecords(
+			arg, sqlc.UpsertChannelForwardingPolicyParams{
+				ChanID:        row.ChanID,
+				MinHtlcMsat:   row.MinHtlcMsat,
+				MaxHtlcMsat:   row.MaxHtlcMsat,
+				BaseFeeMsat:   row.BaseFeeMsat,
+				FeeRate:       row.FeeRate,
+				TimeLockDelta: row.TimeLockDelta,
+			}, fmt.Sprintf("forwarding policy %x", k),
+		)
+	})
+}
+
+// MigrateForwardingLogToSQL runs the migration of the forwarding log from the
+// KV database to the SQL database. The events are migrated in the order of
+// their timestamps and every migrated event is read back from the SQL
+// database and compared against the original one. The peers of events that
+// were recorded without them are resolved from the channel state, which also
+// holds the peers of closed channels.
+func MigrateForwardingLogToSQL(ctx context.Context, kvBackend kvdb.Backend,
+	tx *sqlc.Queries) error {
+
+	log.Infof("Starting migration of the forwarding log from KV to SQL")
+
+	s := rate.Sometimes{
+		Interval: 30 * time.Second,
+	}
+
+	var (
+		t0    = time.Now()
+		chunk int
+		total int
+	)
+	err := kvdb.View(kvBackend, func(kvTx kvdb.RTx) error {
+		logBucket := kvTx.ReadBucket(forwardingLogBucket)
+		if logBucket == nil {
+			return nil
+		}
+
+		peers, err := fetchChannelPeers(kvTx)
+		if err != nil {
+			return err
+		}
+		return logBucket.ForEach(func(k, v []byte) error {
+			if len(v) == 0 {
+				return nil
+			}
+
+			var event ForwardingEvent
+			err := decodeForwardingEvent(bytes.NewReader(v), &event)
+			if err != nil {
+				return err
+			}
+			event.Timestamp = time.Unix(
+				0, int64(byteOrder.Uint64(k)),
+			)
+			event.IncomingPeer = event.IncomingPeer.Alt(
+				lookupPeer(peers, event.IncomingChanID),
+			)
+			event.OutgoingPeer = event.OutgoingPeer.Alt(
+				lookupPeer(peers, event.OutgoingChanID),
+			)
+
+			id, err := insertForwardingEvent(ctx, tx, &event)
+			if err != nil {
+				return err
+			}
+
+			row, err := tx.FetchForwardingEvent(ctx, id)
+			if err != nil {
+				return fmt.Errorf("unable to fetch migrated "+
+					"forwarding event: %w", err)
+			}
+
+			migrated, err := unmarshalForwardingEvent(row)
+			if err != nil {
+				return err
+			}
+
+			err = sqldb.CompareRecords(
+				event, migrated, fmt.Sprintf("forwarding "+
+					"event at %v", event.Timestamp),
+			)
+			if err != nil {
+				return err
+			}
+
+			total++
+			chunk++
+
+			s.Do(func() {
+				elapsed := time.Since(t0).Seconds()
+				ratePerSec := float64(chunk) / elapsed
+				log.Debugf("Migrated %d forwarding events "+
+					"(%.2f events/sec)", total, ratePerSec)
+
+				t0 = time.Now()
+				chunk = 0
+			})
+
+			return nil
+		})
+	}, func() {
+		chunk, total = 0, 0
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Migration of %d forwarding events from KV to SQL "+
+		"completed", total)
+
+	return nil
+}
+
+// fetchChannelPeers returns the public keys of the peers of all channels in
+// the KV database, including the closed ones, keyed by their short channel
+// IDs. Zero-conf channels are found by their alias as well as by their
+// confirmed short channel ID.
+func fetchChannelPeers(kvTx kvdb.RTx) (map[lnwire.ShortChannelID]route.Vertex,
+	error) {
+
+	peers := make(map[lnwire.ShortChannelID]route.Vertex)
+
+	err := fetchOpenChannelPeers(kvTx, peers)
+	if err != nil {
+		return nil, err
+	}
+
+	closeBucket := kvTx.ReadBucket(closedChannelBucket)
+	if closeBucket == nil {
+		return peers, nil
+	}
+
+	err = closeBucket.ForEach(func(_, summaryBytes []byte) error {
+		summary, err := deserializeCloseChannelSummary(
+			bytes.NewReader(summaryBytes),
+		)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := peers[summary.ShortChanID]; !ok {
+			peers[summary.ShortChanID] = route.NewVertex(
+				summary.RemotePub,
+			)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return peers, nil
+}
+
+// lookupPeer returns the peer of the channel with the given short channel ID,
+// if it is known.
+func lookupPeer(peers map[lnwire.ShortChannelID]route.Vertex,
+	scid lnwire.ShortChannelID) fn.Option[route.Vertex] {
+
+	peer, ok := peers[scid]
+	if !ok {
+		return fn.None[route.Vertex]()
+	}
+
+	return fn.Some(peer)
+}
+
+// fetchOpenChannelPeers adds the peers of all open channels, including the
+// pending and waiting close ones, to the given map.
+func fetchOpenChannelPeers(kvTx kvdb.RTx,
+	peers map[lnwire.ShortChannelID]route.Vertex) error {
+
+	openChanBucket := kvTx.ReadBucket(openChannelBucket)
+	if openChanBucket == nil {
+		return nil
+	}
+
+	return openChanBucket.ForEach(func(nodePub, v []byte) error {
+		// Ensure that this is a key the same size as a pubkey, and
+		// also that it leads directly to a bucket.
+		if len(nodePub) != 33 || v != nil {
+			return nil
+		}
+
+		peer, err := route.NewVertexFromBytes(nodePub)
+		if err != nil {
+			return err
+		}
+
+		nodeChanBucket := openChanBucket.NestedReadBucket(nodePub)
+		if nodeChanBucket == nil {
+			return fmt.Errorf("no bucket for node %x", nodePub)
+		}
+
+		return nodeChanBucket.ForEach(func(chainHash, v []byte) error {
+			// If there's a value, it's not a bucket so ignore it.
+			if v != nil {
+				return nil
+			}
+
+			chainBucket := nodeChanBucket.NestedReadBucket(
+				chainHash,
+			)
+			if chainBucket == nil {
+				return fmt.Errorf("no chain bucket for node %x",
+					nodePub)
+			}
+
+			addPeer := func(chanKey, v []byte) error {
+				if v != nil {
+					return nil
+				}
+
+				var chanPoint wire.OutPoint
+				err := graphdb.ReadOutpoint(
+					bytes.NewReader(chanKey), &chanPoint,
+				)
+				if err != nil {
+					return err
+				}
+
+				channel, err := fetchOpenChannel(
+					chainBucket.NestedReadBucket(chanKey),
+					&chanPoint,
+				)
+				if err != nil {
+					return fmt.Errorf("unable to fetch "+
+						"channel(%x): %w", chanKey, err)
+				}
+
+				peers[channel.ShortChannelID] = peer
+				if channel.IsZeroConf() &&
+					channel.ZeroConfConfirmed() {
+
+					scid := channel.ZeroConfRealScid()
+					peers[scid] = peer
+				}
+
+				return nil
+			}
+
+			return chainBucket.ForEach(addPeer)
+		})
+	})
+}
+
+// copyBlob returns a copy of the passed KV value, as values are only valid
+// for the lifetime of the transaction.
+func copyBlob(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+
+	return append([]byte(nil), b...)
+}
+
+// SetChannelStateTombstone marks the channel state in the KV database as
+// migrated to the native SQL store. This prevents the outdated KV channel
+// state from being used again.
+func (d *DB) SetChannelStateTombstone() error {
+	return kvdb.Update(d, func(tx kvdb.RwTx) error {
+		return AddMarker(tx, channelStateTombstoneKey, []byte("1"))
+	}, func() {})
+}
+
+// GetChannelStateTombstone returns true if the channel state in the KV
+// database has been migrated to the native SQL store.
+func (d *DB) GetChannelStateTombstone() (bool, error) {
+	var tombstoneExists bool
+	err := kvdb.View(d, func(tx kvdb.RTx) error {
+		_, err := CheckMarkerPresent(tx, channelStateTombstoneKey)
+		switch {
+		case errors.Is(err, ErrMarkerNotPresent):
+			return nil
+
+		case err != nil:
+			return err
+		}
+
+		tombstoneExists = true
+
+		return nil
+	}, func() {
+		tombstoneExists = false
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return tombstoneExists, nil
+}
@@ -0,0 +1,171 @@
+package channeldb
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrateChannelStateToSQL checks that open and closed channels together
+// with their revocation logs, forwarding packages and the remaining channel
+// state are migrated from the KV store to the SQL store.
+func TestMigrateChannelStateToSQL(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	fullDB, err := MakeTestDB(t, OptionStoreFinalHtlcResolutions(true))
+	require.NoError(t, err)
+	cdb := fullDB.ChannelStateDB()
+
+	// Create an open channel that has advanced its commitment chain once,
+	// so that it has both a revocation log entry and a forwarding package.
+	openChan := createTestChannel(t, cdb, openChannelOption())
+	commitDiff := &CommitDiff{
+		Commitment: openChan.RemoteCommitment,
+		CommitSig: &lnwire.CommitSig{
+			ChanID:    lnwire.ChannelID(key),
+			CommitSig: wireSig,
+		},
+		LogUpdates:        []LogUpdate{},
+		OpenedCircuitKeys: []models.CircuitKey{},
+		ClosedCircuitKeys: []models.CircuitKey{},
+	}
+	commitDiff.Commitment.CommitHeight = 1
+	require.NoError(t, openChan.AppendRemoteCommitChain(commitDiff))
+
+	fwdPkg := NewFwdPkg(openChan.ShortChanID(), 0, nil, nil)
+	err = openChan.AdvanceCommitChainTail(
+		fwdPkg, nil, dummyLocalOutputIndex, dummyRemoteOutIndex,
+	)
+	require.NoError(t, err)
+	require.NoError(t, openChan.SetFwdFilter(0, NewPkgFilter(0)))
+
+	// Add a pending and a closed channel.
+	createTestChannel(t, cdb)
+	closedChan := createTestChannel(
+		t, cdb, openChannelOption(), closedChannelOption(),
+	)
+
+	// Finally, add the remaining channel state.
+	chanID := lnwire.NewShortChanIDFromInt(1)
+	require.NoError(t, cdb.PutOnchainFinalHtlcOutcome(chanID, 2, true))
+
+	outpoint := []byte{1, 2, 3}
+	require.NoError(t, cdb.SaveChannelOpeningState(outpoint, []byte{4}))
+
+	policy := &models.ForwardingPolicy{
+		MinHTLCOut:    1,
+		MaxHTLC:       2,
+		BaseFee:       3,
+		FeeRate:       4,
+		TimeLockDelta: 5,
+	}
+	require.NoError(t, cdb.SaveInitialForwardingPolicy(
+		lnwire.ChannelID{1}, policy,
+	))
+
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+	genericExecutor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) *sqlc.Queries {
+			return db.WithTx(tx)
+		},
+	)
+	err = genericExecutor.ExecTx(
+		ctx, sqldb.WriteTxOpt(), func(tx *sqlc.Queries) error {
+			return MigrateChannelStateToSQL(ctx, fullDB.Backend, tx)
+		}, sqldb.NoOpReset,
+	)
+	require.NoError(t, err)
+
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) SQLQueries {
+			return db.WithTx(tx)
+		},
+	)
+	sqlStore := NewSQLStore(
+		executor, cdb.LinkNodeDB(),
+		OptionStoreFinalHtlcResolutions(true),
+	)
+
+	kvChannels, err := cdb.FetchAllChannels()
+	require.NoError(t, err)
+	sqlChannels, err := sqlStore.FetchAllChannels()
+	require.NoError(t, err)
+	require.Len(t, sqlChannels, len(kvChannels))
+
+	for _, kvChannel := range kvChannels {
+		sqlChannel, err := sqlStore.FetchChannel(
+			kvChannel.FundingOutpoint,
+		)
+		require.NoError(t, err)
+		assertSQLChannelEqual(t, kvChannel, sqlChannel)
+	}
+
+	kvLog, _, err := cdb.FindPreviousState(openChan, 0)
+	require.NoError(t, err)
+	sqlLog, _, err := sqlStore.FindPreviousState(openChan, 0)
+	require.NoError(t, err)
+	require.Equal(t, kvLog, sqlLog)
+
+	kvFwdPkgs, err := cdb.LoadFwdPkgs(openChan)
+	require.NoError(t, err)
+	sqlFwdPkgs, err := sqlStore.LoadFwdPkgs(openChan)
+	require.NoError(t, err)
+	require.Equal(t, kvFwdPkgs, sqlFwdPkgs)
+	require.Equal(t, FwdStateCompleted, sqlFwdPkgs[0].State)
+
+	kvSummaries, err := cdb.FetchClosedChannels(false)
+	require.NoError(t, err)
+	sqlSummaries, err := sqlStore.FetchClosedChannels(false)
+	require.NoError(t, err)
+	require.Equal(t, kvSummaries, sqlSummaries)
+
+	kvHistChannel, err := cdb.FetchHistoricalChannel(
+		&closedChan.FundingOutpoint,
+	)
+	require.NoError(t, err)
+	sqlHistChannel, err := sqlStore.FetchHistoricalChannel(
+		&closedChan.FundingOutpoint,
+	)
+	require.NoError(t, err)
+	assertSQLChannelEqual(t, kvHistChannel, sqlHistChannel)
+
+	info, err := sqlStore.LookupFinalHtlc(chanID, 2)
+	require.NoError(t, err)
+	require.Equal(t, &FinalHtlcInfo{Settled: true}, info)
+
+	state, err := sqlStore.GetChannelOpeningState(outpoint)
+	require.NoError(t, err)
+	require.Equal(t, []byte{4}, state)
+
+	sqlPolicy, err := sqlStore.GetInitialForwardingPolicy(
+		lnwire.ChannelID{1},
+	)
+	require.NoError(t, err)
+	require.Equal(t, policy, sqlPolicy)
+}
+
+// TestChannelStateTombstone asserts that the channel state tombstone can be
+// set and queried.
+func TestChannelStateTombstone(t *testing.T) {
+	t.Parallel()
+
+	fullDB, err := MakeTestDB(t)
+	require.NoError(t, err)
+
+	tombstoned, err := fullDB.GetChannelStateTombstone()
+	require.NoError(t, err)
+	require.False(t, tombstoned)
+
+	require.NoError(t, fullDB.SetChannelStateTombstone())
+
+	tombstoned, err = fullDB.GetChannelStateTombstone()
+	require.NoError(t, err)
+	require.True(t, tombstoned)
+}
@@ -0,0 +1,2716 @@
+package channeldb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/flokiorg/flnd/fn"
+	graphdb "github.com/flokiorg/flnd/graph/db"
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/shachain"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+// SQLQueries is an interface that defines the set of operations that can be
+// executed against the channel state SQL database.
+type SQLQueries interface { //nolint:interfacebloat
+	InsertChannelState(ctx context.Context,
+		arg sqlc.InsertChannelStateParams) (int64, error)
+
+	UpdateChannelState(ctx context.Context,
+		arg sqlc.UpdateChannelStateParams) error
+
+	FetchChannelStateByOutpoint(ctx context.Context,
+		outpoint []byte) (sqlc.Channel, error)
+
+	FetchChannelStateByChanID(ctx context.Context,
+		chanID []byte) (sqlc.Channel, error)
+
+	ListChannelStates(ctx context.Context, closed bool) ([]sqlc.Channel,
+		error)
+
+	ListChannelStatesByNode(ctx context.Context,
+		nodePubKey []byte) ([]sqlc.Channel, error)
+
+	// Commitment specific methods.
+	InsertChannelCommitment(ctx context.Context,
+		arg sqlc.InsertChannelCommitmentParams) (int64, error)
+
+	FetchChannelCommitment(ctx context.Context,
+		arg sqlc.FetchChannelCommitmentParams) (sqlc.ChannelCommitment,
+		error)
+
+	UpdateChannelCommitmentType(ctx context.Context,
+		arg sqlc.UpdateChannelCommitmentTypeParams) error
+
+	DeleteChannelCommitment(ctx context.Context,
+		arg sqlc.DeleteChannelCommitmentParams) error
+
+	InsertCommitmentHtlc(ctx context.Context,
+		arg sqlc.InsertCommitmentHtlcParams) (int64, error)
+
+	FetchCommitmentHtlcs(ctx context.Context, commitmentID int64) (
+		[]sqlc.ChannelCommitmentHtlc, error)
+
+	InsertHtlcCustomRecord(ctx context.Context,
+		arg sqlc.InsertHtlcCustomRecordParams) error
+
+	FetchCommitmentHtlcCustomRecords(ctx context.Context,
+		commitmentID int64) ([]sqlc.ChannelHtlcCustomRecord, error)
+
+	// Commit diff and log update specific methods.
+	InsertCommitDiff(ctx context.Context,
+		arg sqlc.InsertCommitDiffParams) (int64, error)
+
+	FetchCommitDiff(ctx context.Context, channelID int64) (
+		sqlc.ChannelCommitDiff, error)
+
+	DeleteCommitDiff(ctx context.Context, channelID int64) error
+
+	InsertCommitDiffCircuitKey(ctx context.Context,
+		arg sqlc.InsertCommitDiffCircuitKeyParams) error
+
+	FetchCommitDiffCircuitKeys(ctx context.Context, commitDiffID int64) (
+		[]sqlc.ChannelCommitDiffCircuitKey, error)
+
+	InsertChannelLogUpdate(ctx context.Context,
+		arg sqlc.InsertChannelLogUpdateParams) error
+
+	FetchChannelLogUpdates(ctx context.Context,
+		arg sqlc.FetchChannelLogUpdatesParams) ([]sqlc.ChannelLogUpdate,
+		error)
+
+	DeleteChannelLogUpdates(ctx context.Context,
+		arg sqlc.DeleteChannelLogUpdatesParams) error
+
+	// Revocation log specific methods.
+	InsertRevocationLog(ctx context.Context,
+		arg sqlc.InsertRevocationLogParams) (int64, error)
+
+	FetchRevocationLog(ctx context.Context,
+		arg sqlc.FetchRevocationLogParams) (sqlc.ChannelRevocationLog,
+		error)
+
+	CountRevocationLogs(ctx context.Context, channelID int64) (int64,
+		error)
+
+	DeleteRevocationLog(ctx context.Context,
+		arg sqlc.DeleteRevocationLogParams) error
+
+	DeleteRevocationLogs(ctx context.Context, channelID int64) error
+
+	InsertRevocationLogHtlc(ctx context.Context,
+		arg sqlc.InsertRevocationLogHtlcParams) error
+
+	FetchRevocationLogHtlcs(ctx context.Context, revocationLogID int64) (
+		[]sqlc.ChannelRevocationLogHtlc, error)
+
+	// Close summary specific methods.
+	UpsertChannelCloseSummary(ctx context.Context,
+		arg sqlc.UpsertChannelCloseSummaryParams) error
+
+	UpdateChannelCloseSummaryPending(ctx context.Context,
+		arg sqlc.UpdateChannelCloseSummaryPendingParams) error
+
+	FetchChannelCloseSummary(ctx context.Context,
+		outpoint []byte) (sqlc.ChannelCloseSummary, error)
+
+	FetchChannelCloseSummaryByChanID(ctx context.Context,
+		chanID []byte) (sqlc.ChannelCloseSummary, error)
+
+	ListChannelCloseSummaries(ctx context.Context) (
+		[]sqlc.ChannelCloseSummary, error)
+
+	// Forwarding package specific methods.
+	InsertFwdPkg(ctx context.Context, arg sqlc.InsertFwdPkgParams) (int64,
+		error)
+
+	FetchFwdPkg(ctx context.Context, arg sqlc.FetchFwdPkgParams) (
+		sqlc.ChannelForwardingPackage, error)
+
+	ListFwdPkgs(ctx context.Context, sourceScid int64) (
+		[]sqlc.ChannelForwardingPackage, error)
+
+	MarkFwdPkgProcessed(ctx context.Context, id int64) error
+
+	DeleteFwdPkg(ctx context.Context, arg sqlc.DeleteFwdPkgParams) error
+
+	DeleteFwdPkgs(ctx context.Context, sourceScid int64) error
+
+	UpsertFwdPkgUpdate(ctx context.Context,
+		arg sqlc.UpsertFwdPkgUpdateParams) error
+
+	FetchFwdPkgUpdates(ctx context.Context, fwdPkgID int64) (
+		[]sqlc.ChannelForwardingPackageUpdate, error)
+
+	AckFwdPkgUpdate(ctx context.Context,
+		arg sqlc.AckFwdPkgUpdateParams) error
+
+	SetFwdPkgUpdateForwarded(ctx context.Context,
+		arg sqlc.SetFwdPkgUpdateForwardedParams) error
+
+	DeleteFwdPkgUpdatesFrom(ctx context.Context,
+		arg sqlc.DeleteFwdPkgUpdatesFromParams) error
+
+	// Final htlc specific methods.
+	UpsertFinalHtlc(ctx context.Context,
+		arg sqlc.UpsertFinalHtlcParams) error
+
+	FetchFinalHtlc(ctx context.Context, arg sqlc.FetchFinalHtlcParams) (
+		sqlc.ChannelFinalHtlc, error)
+
+	// Channel setup specific methods.
+	UpsertChannelOpeningState(ctx context.Context,
+		arg sqlc.UpsertChannelOpeningStateParams) error
+
+	FetchChannelOpeningState(ctx context.Context, outpoint []byte) ([]byte,
+		error)
+
+	DeleteChannelOpeningState(ctx context.Context, outpoint []byte) error
+
+	UpsertChannelForwardingPolicy(ctx context.Context,
+		arg sqlc.UpsertChannelForwardingPolicyParams) error
+
+	FetchChannelForwardingPolicy(ctx context.Context, chanID []byte) (
+		sqlc.ChannelForwardingPolicy, error)
+
+	DeleteChannelForwardingPolicy(ctx context.Context, chanID []byte) error
+}
+
+// BatchedSQLQueries is a version of the SQLQueries that's capable of batched
+// database operations.
+type BatchedSQLQueries interface {
+	SQLQueries
+
+	sqldb.BatchedTx[SQLQueries]
+}
+
+// SQLStore implements the channel state store on top of a native SQL
+// database.
+//
+// NOTE: Link nodes are not part of the native SQL schema yet, so they are
+// still kept in the KV link node database. Creating or pruning a link node is
+// therefore not atomic with the channel state update that triggers it.
+type SQLStore struct {
+	db BatchedSQLQueries
+
+	// linkNodeDB is the KV database holding the link nodes of our peers.
+	linkNodeDB *LinkNodeDB
+
+	// noRevLogAmtData if true, means we won't store the amount data in
+	// the revocation log.
+	noRevLogAmtData bool
+
+	// storeFinalHtlcResolutions determines whether to persistently store
+	// the final resolution of incoming htlcs.
+	storeFinalHtlcResolutions bool
+}
+
+// NewSQLStore creates a new SQLStore for channel state given an open
+// BatchedSQLQueries storage backend and the link node database.
+func NewSQLStore(db BatchedSQLQueries, linkNodeDB *LinkNodeDB,
+	options ...OptionModifier) *SQLStore {
+
+	opts := DefaultOptions()
+	for _, applyOption := range options {
+		applyOption(&opts)
+	}
+
+	return &SQLStore{
+		db:                        db,
+		linkNodeDB:                linkNodeDB,
+		noRevLogAmtData:           opts.NoRevLogAmtData,
+		storeFinalHtlcResolutions: opts.storeFinalHtlcResolutions,
+	}
+}
+
+// LinkNodeDB returns the link node database used by the store.
+func (s *SQLStore) LinkNodeDB() *LinkNodeDB {
+	return s.linkNodeDB
+}
+
+// FetchOpenChannels returns all stored currently active/open channels
+// associated with the target nodeID. In the case that no active channels are
+// known to have been created with this node, then a zero-length slice is
+// returned.
+func (s *SQLStore) FetchOpenChannels(nodeID *crypto.PublicKey) (
+	[]*OpenChannel, error) {
+
+	ctx := context.TODO()
+
+	var channels []*OpenChannel
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		var err error
+		channels, err = s.fetchNodeChannels(ctx, db, nodeID)
+
+		return err
+	}, func() {
+		channels = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// FetchChannel attempts to locate a channel specified by the passed channel
+// point. If the channel cannot be found, then an error will be returned.
+func (s *SQLStore) FetchChannel(chanPoint wire.OutPoint) (*OpenChannel,
+	error) {
+
+	ctx := context.TODO()
+
+	var channel *OpenChannel
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := fetchOpenChannelRow(ctx, db, &chanPoint)
+		if err != nil {
+			return err
+		}
+
+		channel, err = s.unmarshalChannel(ctx, db, row)
+
+		return err
+	}, func() {
+		channel = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// FetchChannelByID attempts to locate a channel specified by the passed
+// channel ID. If the channel cannot be found, then an error will be returned.
+func (s *SQLStore) FetchChannelByID(id lnwire.ChannelID) (*OpenChannel,
+	error) {
+
+	ctx := context.TODO()
+
+	var channel *OpenChannel
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := db.FetchChannelStateByChanID(ctx, id[:])
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrChannelNotFound
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch channel: %w", err)
+
+		case row.Closed:
+			return ErrChannelNotFound
+		}
+
+		channel, err = s.unmarshalChannel(ctx, db, row)
+
+		return err
+	}, func() {
+		channel = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// FetchAllChannels attempts to retrieve all open channels currently stored
+// within the database, including pending open, fully open and channels
+// waiting for a closing transaction to confirm.
+func (s *SQLStore) FetchAllChannels() ([]*OpenChannel, error) {
+	return s.fetchChannels()
+}
+
+// FetchAllOpenChannels will return all channels that have the funding
+// transaction confirmed, and is not waiting for a closing transaction to be
+// confirmed.
+func (s *SQLStore) FetchAllOpenChannels() ([]*OpenChannel, error) {
+	return s.fetchChannels(
+		pendingChannelFilter(false), waitingCloseFilter(false),
+	)
+}
+
+// FetchPendingChannels will return channels that have completed the process
+// of generating and broadcasting funding transactions, but whose funding
+// transactions have yet to be confirmed on the blockchain.
+func (s *SQLStore) FetchPendingChannels() ([]*OpenChannel, error) {
+	return s.fetchChannels(
+		pendingChannelFilter(true), waitingCloseFilter(false),
+	)
+}
+
+// FetchWaitingCloseChannels will return all channels that have been opened,
+// but are now waiting for a closing transaction to be confirmed.
+//
+// NOTE: This includes channels that are also pending to be opened.
+func (s *SQLStore) FetchWaitingCloseChannels() ([]*OpenChannel, error) {
+	return s.fetchChannels(waitingCloseFilter(true))
+}
+
+// fetchChannels retrieves all open channels that pass all of the given
+// filters.
+func (s *SQLStore) fetchChannels(filters ...fetchChannelsFilter) (
+	[]*OpenChannel, error) {
+
+	ctx := context.TODO()
+
+	var channels []*OpenChannel
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		rows, err := db.ListChannelStates(ctx, false)
+		if err != nil {
+			return fmt.Errorf("unable to list channels: %w", err)
+		}
+
+	nextChannel:
+		for _, row := range rows {
+			channel, err := s.unmarshalChannel(ctx, db, row)
+			if err != nil {
+				return err
+			}
+
+			for _, f := range filters {
+				if !f(channel) {
+					continue nextChannel
+				}
+			}
+
+			channels = append(channels, channel)
+		}
+
+		return nil
+	}, func() {
+		channels = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// FetchPermAndTempPeers returns a map where the key is the remote node's
+// public key and the value is a struct that has a tally of the pending-open
+// channels and whether the peer has an open or closed channel with us.
+func (s *SQLStore) FetchPermAndTempPeers(chainHash []byte) (
+	map[string]ChanCount, error) {
+
+	ctx := context.TODO()
+
+	peerChanInfo := make(map[string]ChanCount)
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		openRows, err := db.ListChannelStates(ctx, false)
+		if err != nil {
+			return fmt.Errorf("unable to list channels: %w", err)
+		}
+
+		for _, row := range openRows {
+			if !bytes.Equal(row.ChainHash, chainHash) {
+				continue
+			}
+
+			channel, err := s.unmarshalChannel(ctx, db, row)
+			if err != nil {
+				return err
+			}
+
+			peerKey := string(row.NodePubKey)
+			count := peerChanInfo[peerKey]
+			if channel.IsPending {
+				count.PendingOpenCount++
+			} else {
+				count.HasOpenOrClosedChan = true
+			}
+			peerChanInfo[peerKey] = count
+		}
+
+		closedRows, err := db.ListChannelStates(ctx, true)
+		if err != nil {
+			return fmt.Errorf("unable to list closed channels: %w",
+				err)
+		}
+
+		for _, row := range closedRows {
+			channel, err := s.unmarshalChannel(ctx, db, row)
+			if err != nil {
+				return err
+			}
+
+			// Only include this peer in the protected class if the
+			// closing transaction confirmed. CloseChannel can be
+			// called by the funding manager while IsPending is
+			// still true, which is why these are skipped.
+			if channel.IsPending {
+				continue
+			}
+
+			peerKey := string(row.NodePubKey)
+			count := peerChanInfo[peerKey]
+			count.HasOpenOrClosedChan = true
+			peerChanInfo[peerKey] = count
+		}
+
+		return nil
+	}, func() {
+		clear(peerChanInfo)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return peerChanInfo, nil
+}
+
+// RestoreChannelShells is a method that allows the caller to reconstruct the
+// state of an OpenChannel from the ChannelShell. We'll attempt to write the
+// new channel to disk, and create a LinkNode instance with the passed node
+// addresses.
+func (s *SQLStore) RestoreChannelShells(channelShells ...*ChannelShell) error {
+	ctx := context.TODO()
+
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		for _, channelShell := range channelShells {
+			channel := channelShell.Chan
+
+			// When we make a channel, we mark that the channel has
+			// been restored, this will signal to other sub-systems
+			// to not attempt to use the channel as if it was a
+			// regular one.
+			channel.SetChannelStatusForStore(
+				channel.ChannelStatusForStore() |
+					ChanStatusRestored,
+			)
+
+			channel.Db = s
+			_, err := insertChannel(ctx, db, channel)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, sqldb.NoOpReset)
+	if err != nil {
+		return err
+	}
+
+	for _, channelShell := range channelShells {
+		err := s.createLinkNode(
+			channelShell.Chan.IdentityPub, channelShell.NodeAddrs,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FetchHistoricalChannel fetches the last known state of a closed channel.
+func (s *SQLStore) FetchHistoricalChannel(outPoint *wire.OutPoint) (
+	*OpenChannel, error) {
+
+	ctx := context.TODO()
+
+	opBytes, err := serializeOutpoint(outPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var channel *OpenChannel
+	err = s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := db.FetchChannelStateByOutpoint(ctx, opBytes)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrChannelNotFound
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch channel: %w", err)
+
+		case !row.Closed:
+			return ErrChannelNotFound
+		}
+
+		channel, err = s.unmarshalChannel(ctx, db, row)
+
+		return err
+	}, func() {
+		channel = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return channel, nil
+}
+
+// SyncPendingChannel writes a pending channel to the store and records the
+// funding broadcast height.
+func (s *SQLStore) SyncPendingChannel(channel *OpenChannel, addr net.Addr,
+	pendingHeight uint32) error {
+
+	ctx := context.TODO()
+
+	channel.FundingBroadcastHeight = pendingHeight
+
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		_, err := insertChannel(ctx, db, channel)
+
+		return err
+	}, sqldb.NoOpReset)
+	if err != nil {
+		return err
+	}
+
+	return s.createLinkNode(channel.IdentityPub, []net.Addr{addr})
+}
+
+// RefreshChannel updates the in-memory channel state using the latest state
+// observed on disk.
+func (s *SQLStore) RefreshChannel(channel *OpenChannel) error {
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := fetchOpenChannelRow(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		return populateChannel(ctx, db, row, channel)
+	}, sqldb.NoOpReset)
+}
+
+// MarkChannelConfirmationHeight updates the channel's confirmation height once
+// the channel opening transaction receives one confirmation.
+func (s *SQLStore) MarkChannelConfirmationHeight(channel *OpenChannel,
+	height uint32) error {
+
+	return s.updateDiskChannel(channel, func(diskChannel *OpenChannel) {
+		diskChannel.ConfirmationHeight = height
+	})
+}
+
+// MarkChannelCloseConfirmationHeight updates the channel's close confirmation
+// height when the closing transaction is first detected in a block.
+func (s *SQLStore) MarkChannelCloseConfirmationHeight(channel *OpenChannel,
+	height fn.Option[uint32]) error {
+
+	return s.updateDiskChannel(channel, func(diskChannel *OpenChannel) {
+		diskChannel.CloseConfirmationHeight = height
+	})
+}
+
+// MarkChannelOpen marks a channel as fully open given a locator that uniquely
+// describes its location within the chain.
+func (s *SQLStore) MarkChannelOpen(channel *OpenChannel,
+	openLoc lnwire.ShortChannelID) error {
+
+	return s.updateDiskChannel(channel, func(diskChannel *OpenChannel) {
+		diskChannel.IsPending = false
+		diskChannel.ShortChannelID = openLoc
+	})
+}
+
+// MarkChannelRealScid marks the zero-conf channel's confirmed ShortChannelID.
+func (s *SQLStore) MarkChannelRealScid(channel *OpenChannel,
+	realScid lnwire.ShortChannelID) error {
+
+	return s.updateDiskChannel(channel, func(diskChannel *OpenChannel) {
+		diskChannel.SetConfirmedScidForStore(realScid)
+	})
+}
+
+// MarkChannelScidAliasNegotiated adds ScidAliasFeatureBit to ChanType in the
+// database.
+func (s *SQLStore) MarkChannelScidAliasNegotiated(channel *OpenChannel) error {
+	return s.updateDiskChannel(channel, func(diskChannel *OpenChannel) {
+		diskChannel.ChanType |= ScidAliasFeatureBit
+	})
+}
+
+// ApplyChannelDynCommitment switches the channel over to the passed parameters
+// and appends the dynamic commitment to its history in the database.
+func (s *SQLStore) ApplyChannelDynCommitment(channel *OpenChannel,
+	params ChannelParams, dyn DynCommitment) error {
+
+	return s.updateDiskChannel(channel, func(diskChannel *OpenChannel) {
+		diskChannel.ApplyDynCommitmentForStore(params, dyn)
+	})
+}
+
+// PutChannelSplice replaces the pending splice of the channel with the passed
+// one, removing it if none is passed.
+func (s *SQLStore) PutChannelSplice(channel *OpenChannel,
+	splice fn.Option[Splice]) error {
+
+	return s.updateDiskChannel(channel, func(diskChannel *OpenChannel) {
+		diskChannel.SetPendingSpliceForStore(splice)
+	})
+}
+
+// LockChannelSplice switches the channel over to the funding output of its
+// pending splice.
+func (s *SQLStore) LockChannelSplice(channel *OpenChannel) error {
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, diskChannel, err := s.fetchOpenChannel(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := diskChannel.LockSpliceForStore(); err != nil {
+			return err
+		}
+
+		if err := marshalChannel(&row, diskChannel); err != nil {
+			return err
+		}
+
+		// Locking the splice moves both commitments over to the new
+		// funding output.
+		err = putChanCommitmentsSQL(ctx, db, row.ID, diskChannel)
+		if err != nil {
+			return err
+		}
+
+		return updateChannelRow(ctx, db, &row)
+	}, sqldb.NoOpReset)
+}
+
+// ApplyChannelStatus adds the target status to the channel's persisted status
+// bit field.
+func (s *SQLStore) ApplyChannelStatus(channel *OpenChannel,
+	status ChannelStatus) error {
+
+	return s.putChanStatus(channel, status)
+}
+
+// ClearChannelStatus clears the target status from the channel's persisted
+// status bit field.
+func (s *SQLStore) ClearChannelStatus(channel *OpenChannel,
+	status ChannelStatus) error {
+
+	ctx := context.TODO()
+
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, diskChannel, err := s.fetchOpenChannel(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Unset this bit in the bitvector on disk.
+		status = diskChannel.ChannelStatusForStore() & ^status
+		diskChannel.SetChannelStatusForStore(status)
+
+		if err := marshalChannel(&row, diskChannel); err != nil {
+			return err
+		}
+
+		return updateChannelRow(ctx, db, &row)
+	}, sqldb.NoOpReset)
+	if err != nil {
+		return err
+	}
+
+	// Update the in-memory representation to keep it in sync with the DB.
+	channel.SetChannelStatusForStore(status)
+
+	return nil
+}
+
+// MarkChannelDataLoss marks the channel as local-data-loss and stores the
+// commit point needed if the remote force closes.
+func (s *SQLStore) MarkChannelDataLoss(channel *OpenChannel,
+	commitPoint *crypto.PublicKey) error {
+
+	var b bytes.Buffer
+	if err := WriteElement(&b, commitPoint); err != nil {
+		return err
+	}
+
+	putCommitPoint := func(row *sqlc.Channel) {
+		row.DataLossCommitPoint = b.Bytes()
+	}
+
+	return s.putChanStatus(channel, ChanStatusLocalDataLoss, putCommitPoint)
+}
+
+// FetchChannelDataLossCommitPoint retrieves the commit point stored when the
+// channel was marked as local-data-loss.
+func (s *SQLStore) FetchChannelDataLossCommitPoint(channel *OpenChannel) (
+	*crypto.PublicKey, error) {
+
+	blob, err := s.fetchChannelBlob(
+		channel, ErrNoCommitPoint, func(row sqlc.Channel) []byte {
+			return row.DataLossCommitPoint
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var commitPoint *crypto.PublicKey
+	err = ReadElements(bytes.NewReader(blob), &commitPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return commitPoint, nil
+}
+
+// MarkChannelBorked marks the channel as irreconcilable.
+func (s *SQLStore) MarkChannelBorked(channel *OpenChannel) error {
+	return s.ApplyChannelStatus(channel, ChanStatusBorked)
+}
+
+// StoreChannelShutdownInfo persists the ShutdownInfo for the target channel.
+func (s *SQLStore) StoreChannelShutdownInfo(channel *OpenChannel,
+	info *ShutdownInfo) error {
+
+	var b bytes.Buffer
+	if err := encodeShutdownInfo(info, &b); err != nil {
+		return err
+	}
+
+	return s.updateChannelRow(channel, func(row *sqlc.Channel) error {
+		row.ShutdownInfo = b.Bytes()
+
+		return nil
+	})
+}
+
+// FetchChannelShutdownInfo fetches the persisted ShutdownInfo for the target
+// channel.
+func (s *SQLStore) FetchChannelShutdownInfo(channel *OpenChannel) (
+	fn.Option[ShutdownInfo], error) {
+
+	blob, err := s.fetchChannelBlob(
+		channel, ErrNoShutdownInfo, func(row sqlc.Channel) []byte {
+			return row.ShutdownInfo
+		},
+	)
+	if err != nil {
+		return fn.None[ShutdownInfo](), err
+	}
+
+	shutdownInfo, err := decodeShutdownInfo(blob)
+	if err != nil {
+		return fn.None[ShutdownInfo](), err
+	}
+
+	return fn.Some(*shutdownInfo), nil
+}
+
+// MarkChannelCommitmentBroadcasted marks the channel as having a commitment
+// transaction broadcast.
+func (s *SQLStore) MarkChannelCommitmentBroadcasted(channel *OpenChannel,
+	closeTx *wire.MsgTx, closer lntypes.ChannelParty) error {
+
+	return s.markBroadcasted(
+		channel, ChanStatusCommitBroadcasted, closeTx, closer,
+		func(row *sqlc.Channel, tx []byte) {
+			row.ForceCloseTx = tx
+		},
+	)
+}
+
+// MarkChannelCoopBroadcasted marks the channel as having a cooperative close
+// transaction broadcast.
+func (s *SQLStore) MarkChannelCoopBroadcasted(channel *OpenChannel,
+	closeTx *wire.MsgTx, closer lntypes.ChannelParty) error {
+
+	return s.markBroadcasted(
+		channel, ChanStatusCoopBroadcasted, closeTx, closer,
+		func(row *sqlc.Channel, tx []byte) {
+			row.CoopCloseTx = tx
+		},
+	)
+}
+
+// markBroadcasted modifies the channel status and stores the close
+// transaction using the passed setter. It adds a status which indicates the
+// party that initiated the channel close.
+func (s *SQLStore) markBroadcasted(channel *OpenChannel,
+	status ChannelStatus, closeTx *wire.MsgTx,
+	closer lntypes.ChannelParty,
+	setTx func(row *sqlc.Channel, tx []byte)) error {
+
+	if closeTx == nil {
+		return fmt.Errorf("closeTx must be non-nil")
+	}
+
+	channel.Lock()
+	defer channel.Unlock()
+
+	var b bytes.Buffer
+	if err := WriteElement(&b, closeTx); err != nil {
+		return err
+	}
+
+	putClosingTx := func(row *sqlc.Channel) {
+		setTx(row, b.Bytes())
+	}
+
+	// Add the initiator status to the status provided. These statuses are
+	// set in addition to the broadcast status.
+	if closer.IsLocal() {
+		status |= ChanStatusLocalCloseInitiator
+	} else {
+		status |= ChanStatusRemoteCloseInitiator
+	}
+
+	return s.putChanStatus(channel, status, putClosingTx)
+}
+
+// FetchChannelBroadcastedCommitment fetches the stored unilateral closing
+// transaction.
+func (s *SQLStore) FetchChannelBroadcastedCommitment(channel *OpenChannel) (
+	*wire.MsgTx, error) {
+
+	return s.getClosingTx(channel, func(row sqlc.Channel) []byte {
+		return row.ForceCloseTx
+	})
+}
+
+// FetchChannelBroadcastedCooperative fetches the stored cooperative closing
+// transaction.
+func (s *SQLStore) FetchChannelBroadcastedCooperative(channel *OpenChannel) (
+	*wire.MsgTx, error) {
+
+	return s.getClosingTx(channel, func(row sqlc.Channel) []byte {
+		return row.CoopCloseTx
+	})
+}
+
+// getClosingTx returns the closing transaction selected by the passed getter.
+func (s *SQLStore) getClosingTx(channel *OpenChannel,
+	getTx func(row sqlc.Channel) []byte) (*wire.MsgTx, error) {
+
+	blob, err := s.fetchChannelBlob(channel, ErrNoCloseTx, getTx)
+	if err != nil {
+		return nil, err
+	}
+
+	var closeTx *wire.MsgTx
+	if err := ReadElement(bytes.NewReader(blob), &closeTx); err != nil {
+		return nil, err
+	}
+
+	return closeTx, nil
+}
+
+// UpdateChannelCommitment updates the local commitment state. It locks in the
+// pending local updates that were received by us from the remote party. The
+// commitment state completely describes the balance state at this point in
+// the commitment chain. In addition to that, it persists all the remote log
+// updates that we have acked, but not signed a remote commitment for yet.
+// The returned map contains the final outcome of the htlcs that were locked
+// in by this update.
+func (s *SQLStore) UpdateChannelCommitment(channel *OpenChannel,
+	newCommitment *ChannelCommitment,
+	unsignedAckedUpdates []LogUpdate) (map[uint64]bool, error) {
+
+	ctx := context.TODO()
+
+	finalHtlcs := make(map[uint64]bool)
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, err := s.fetchUnborkedChannelRow(ctx, db, channel)
+		if err != nil {
+			return err
+		}
+
+		var info bytes.Buffer
+		if err := serializeChanInfo(&info, channel); err != nil {
+			return fmt.Errorf("unable to store chan info: %w", err)
+		}
+		row.ChanInfo = info.Bytes()
+		row.LocalShutdownScript = channel.LocalShutdownScript
+		row.RemoteShutdownScript = channel.RemoteShutdownScript
+
+		// With the channel row fetched, we'll now write the latest
+		// commitment state for the local party.
+		err = putChanCommitmentSQL(
+			ctx, db, row.ID, commitmentLocal, newCommitment,
+		)
+		if err != nil {
+			return fmt.Errorf("unable to store chan commitment: %w",
+				err)
+		}
+
+		// Persist unsigned but acked remote updates that need to be
+		// restored after a restart.
+		err = putLogUpdatesSQL(
+			ctx, db, row.ID, logUpdateUnsignedAcked,
+			unsignedAckedUpdates,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Since we have just sent the counterparty a revocation, we
+		// mark the last update as a revocation.
+		row.LastWasRevoke = true
+
+		// Persist the remote unsigned local updates that are not
+		// included in our new commitment.
+		updates, err := fetchLogUpdatesSQL(
+			ctx, db, row.ID, logUpdateRemoteUnsignedLocal,
+		)
+		if err != nil {
+			return err
+		}
+
+		var unsignedUpdates []LogUpdate
+		for _, upd := range updates {
+			// Gather updates that are not on our local commitment.
+			if upd.LogIndex >= newCommitment.LocalLogIndex {
+				unsignedUpdates = append(unsignedUpdates, upd)
+
+				continue
+			}
+
+			// The update was locked in. If the update was a
+			// resolution, then store it in the database.
+			err := s.processFinalHtlc(
+				ctx, db, channel.ShortChannelID, upd, finalHtlcs,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = putLogUpdatesSQL(
+			ctx, db, row.ID, logUpdateRemoteUnsignedLocal,
+			unsignedUpdates,
+		)
+		if err != nil {
+			return err
+		}
+
+		return updateChannelRow(ctx, db, &row)
+	}, func() {
+		finalHtlcs = make(map[uint64]bool)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return finalHtlcs, nil
+}
+
+// processFinalHtlc stores a final htlc outcome in the database if signaled via
+// the supplied log update and the user opted in to storing final
+// resolutions. An in-memory htlcs map is updated too.
+func (s *SQLStore) processFinalHtlc(ctx context.Context, db SQLQueries,
+	chanID lnwire.ShortChannelID, upd LogUpdate,
+	finalHtlcs map[uint64]bool) error {
+
+	id, settled, ok := finalHtlcOutcome(upd)
+	if !ok {
+		return nil
+	}
+
+	if s.storeFinalHtlcResolutions {
+		err := db.UpsertFinalHtlc(ctx, sqlc.UpsertFinalHtlcParams{
+			Scid:      int64(chanID.ToUint64()),
+			HtlcIndex: int64(id),
+			Settled:   settled,
+			Offchain:  true,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to store final htlc: %w", err)
+		}
+	}
+
+	finalHtlcs[id] = settled
+
+	return nil
+}
+
+// AppendRemoteCommitChain appends a new CommitDiff to the end of the
+// commitment chain for the remote party. This method is to be used once we
+// have prepared a new commitment state for the remote party, but before we
+// transmit it to the remote party.
+func (s *SQLStore) AppendRemoteCommitChain(channel *OpenChannel,
+	diff *CommitDiff) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, err := s.fetchUnborkedChannelRow(ctx, db, channel)
+		if err != nil {
+			return err
+		}
+
+		// Any outgoing settles and fails necessarily have a
+		// corresponding adds in this channel's forwarding packages.
+		// Mark all of these as being fully processed in our forwarding
+		// package, which prevents us from reprocessing them after
+		// startup.
+		err = ackAddHtlcsSQL(
+			ctx, db, channel.ShortChannelID, diff.AddAcks,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Additionally, we ack from any fails or settles that are
+		// persisted in another channel's forwarding package. This
+		// prevents the same fails and settles from being retransmitted
+		// after restarts.
+		err = ackSettleFailsSQL(ctx, db, diff.SettleFailAcks)
+		if err != nil {
+			return err
+		}
+
+		// We are sending a commitment signature so the last update is
+		// no longer a revocation.
+		row.LastWasRevoke = false
+
+		if err := putCommitDiffSQL(ctx, db, row.ID, diff); err != nil {
+			return err
+		}
+
+		return updateChannelRow(ctx, db, &row)
+	}, sqldb.NoOpReset)
+}
+
+// RemoteCommitChainTip returns the "tip" of the current remote commitment
+// chain. This value will be non-nil iff, we've created a new commitment for
+// the remote party that they haven't yet ACK'd. In this case, their
+// commitment chain will have a length of two: their current unrevoked
+// commitment, and this new pending commitment. Once they revoked their prior
+// state, we'll swap these pointers, causing the tip and the tail to point to
+// the same entry.
+func (s *SQLStore) RemoteCommitChainTip(channel *OpenChannel) (*CommitDiff,
+	error) {
+
+	ctx := context.TODO()
+
+	var diff *CommitDiff
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := fetchOpenChannelRow(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		switch {
+		case errors.Is(err, ErrChannelNotFound):
+			return ErrNoPendingCommit
+
+		case err != nil:
+			return err
+		}
+
+		diff, err = fetchCommitDiffSQL(ctx, db, row.ID)
+
+		return err
+	}, func() {
+		diff = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// UnsignedAckedUpdates retrieves the persisted unsigned acked remote log
+// updates that still need to be signed for. nil is returned if there are
+// none.
+func (s *SQLStore) UnsignedAckedUpdates(channel *OpenChannel) ([]LogUpdate,
+	error) {
+
+	return s.fetchLogUpdates(channel, logUpdateUnsignedAcked)
+}
+
+// RemoteUnsignedLocalUpdates retrieves the persisted, unsigned local log
+// updates that the remote still needs to sign for. nil is returned if there
+// are none.
+func (s *SQLStore) RemoteUnsignedLocalUpdates(channel *OpenChannel) (
+	[]LogUpdate, error) {
+
+	return s.fetchLogUpdates(channel, logUpdateRemoteUnsignedLocal)
+}
+
+// fetchLogUpdates returns the log updates of the given list of the channel.
+// If either the channel or the updates can't be found, nil is returned.
+func (s *SQLStore) fetchLogUpdates(channel *OpenChannel,
+	updateType logUpdateType) ([]LogUpdate, error) {
+
+	ctx := context.TODO()
+
+	var updates []LogUpdate
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := fetchOpenChannelRow(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		switch {
+		case errors.Is(err, ErrChannelNotFound):
+			return nil
+
+		case err != nil:
+			return err
+		}
+
+		updates, err = fetchLogUpdatesSQL(ctx, db, row.ID, updateType)
+
+		return err
+	}, func() {
+		updates = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updates, nil
+}
+
+// InsertNextRevocation inserts the _next_ commitment point (revocation) into
+// the database, and also modifies the internal RemoteNextRevocation attribute
+// to point to the passed key.
+func (s *SQLStore) InsertNextRevocation(channel *OpenChannel,
+	revKey *crypto.PublicKey) error {
+
+	channel.RemoteNextRevocation = revKey
+
+	return s.updateChannelRow(channel, func(row *sqlc.Channel) error {
+		var b bytes.Buffer
+		err := serializeChanRevocationState(&b, channel)
+		if err != nil {
+			return err
+		}
+		row.RevocationState = b.Bytes()
+
+		return nil
+	})
+}
+
+// AdvanceCommitChainTail records the new state transition within the
+// revocation log and promotes the pending remote commitment to the current
+// remote commitment. The forwarding package is written in the same
+// transaction.
+func (s *SQLStore) AdvanceCommitChainTail(channel *OpenChannel,
+	fwdPkg *FwdPkg, updates []LogUpdate, ourOutputIndex,
+	theirOutputIndex uint32) error {
+
+	ctx := context.TODO()
+
+	var newRemoteCommit *ChannelCommitment
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, err := s.fetchUnborkedChannelRow(ctx, db, channel)
+		if err != nil {
+			return err
+		}
+
+		// Persist the latest preimage state to disk as the remote peer
+		// has just added to our local preimage store, and given us a
+		// new pending revocation key.
+		var revState bytes.Buffer
+		err = serializeChanRevocationState(&revState, channel)
+		if err != nil {
+			return err
+		}
+		row.RevocationState = revState.Bytes()
+
+		// Before we append this revoked state to the revocation log,
+		// we'll swap out what's currently the tail of the commit tip,
+		// with the current locked-in commitment for the remote party.
+		newCommit, err := fetchCommitDiffSQL(ctx, db, row.ID)
+		if err != nil {
+			return err
+		}
+
+		err = db.DeleteChannelCommitment(
+			ctx, sqlc.DeleteChannelCommitmentParams{
+				ChannelID:      row.ID,
+				CommitmentType: int16(commitmentRemote),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to delete commitment: %w",
+				err)
+		}
+
+		err = db.UpdateChannelCommitmentType(
+			ctx, sqlc.UpdateChannelCommitmentTypeParams{
+				ChannelID:        row.ID,
+				CommitmentType:   int16(commitmentRemotePending),
+				CommitmentType_2: int16(commitmentRemote),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to promote commitment: %w",
+				err)
+		}
+
+		if err := deleteCommitDiffSQL(ctx, db, row.ID); err != nil {
+			return err
+		}
+
+		// With the commitment pointer swapped, we can now add the
+		// revoked (prior) state to the revocation log.
+		rl, err := newRevocationLog(
+			&channel.RemoteCommitment, ourOutputIndex,
+			theirOutputIndex, s.noRevLogAmtData,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = putRevocationLogSQL(
+			ctx, db, row.ID, channel.RemoteCommitment.CommitHeight,
+			rl,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Lastly, we write the forwarding package so that we can
+		// properly recover from failures and reforward HTLCs that
+		// have not received a corresponding settle/fail.
+		if err := addFwdPkgSQL(ctx, db, fwdPkg); err != nil {
+			return err
+		}
+
+		// Persist the unsigned acked updates that are not included in
+		// their new commitment.
+		unsignedUpdates, err := fetchLogUpdatesSQL(
+			ctx, db, row.ID, logUpdateUnsignedAcked,
+		)
+		if err != nil {
+			return err
+		}
+
+		var validUpdates []LogUpdate
+		for _, upd := range unsignedUpdates {
+			lIdx := upd.LogIndex
+
+			// Filter for updates that are not on the remote
+			// commitment.
+			if lIdx >= newCommit.Commitment.RemoteLogIndex {
+				validUpdates = append(validUpdates, upd)
+			}
+		}
+
+		err = putLogUpdatesSQL(
+			ctx, db, row.ID, logUpdateUnsignedAcked, validUpdates,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Persist the local updates the peer hasn't yet signed so they
+		// can be restored after restart.
+		err = putLogUpdatesSQL(
+			ctx, db, row.ID, logUpdateRemoteUnsignedLocal, updates,
+		)
+		if err != nil {
+			return err
+		}
+
+		newRemoteCommit = &newCommit.Commitment
+
+		return updateChannelRow(ctx, db, &row)
+	}, func() {
+		newRemoteCommit = nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// With the db transaction complete, we'll swap over the in-memory
+	// pointer of the new remote commitment, which was previously the tip
+	// of the commit chain.
+	channel.RemoteCommitment = *newRemoteCommit
+
+	return nil
+}
+
+// CommitmentHeight returns the current commitment height. The commitment
+// height represents the number of updates to the commitment state to date.
+func (s *SQLStore) CommitmentHeight(channel *OpenChannel) (uint64, error) {
+	ctx := context.TODO()
+
+	var height uint64
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := fetchOpenChannelRow(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		switch {
+		case errors.Is(err, ErrChannelNotFound):
+			return ErrNoCommitmentsFound
+
+		case err != nil:
+			return err
+		}
+
+		commit, err := fetchChanCommitmentSQL(
+			ctx, db, row.ID, commitmentLocal,
+		)
+		if err != nil {
+			return err
+		}
+		height = commit.CommitHeight
+
+		return nil
+	}, func() {
+		height = 0
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return height, nil
+}
+
+// LatestCommitments returns the two latest commitments for both the local and
+// remote party. These commitments are read from disk to ensure that only the
+// latest fully committed state is returned. The first commitment returned is
+// the local commitment, and the second returned is the remote commitment.
+func (s *SQLStore) LatestCommitments(channel *OpenChannel) (
+	*ChannelCommitment, *ChannelCommitment, error) {
+
+	ctx := context.TODO()
+
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := fetchOpenChannelRow(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		return fetchChanCommitmentsSQL(ctx, db, row.ID, channel)
+	}, sqldb.NoOpReset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &channel.LocalCommitment, &channel.RemoteCommitment, nil
+}
+
+// RemoteRevocationStore returns the most up to date commitment version of the
+// revocation storage tree for the remote party. This method can be used when
+// acting on a possible contract breach to ensure, that the caller has the
+// most up to date information required to deliver justice.
+func (s *SQLStore) RemoteRevocationStore(channel *OpenChannel) (
+	shachain.Store, error) {
+
+	ctx := context.TODO()
+
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := fetchOpenChannelRow(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		return deserializeChanRevocationState(
+			bytes.NewReader(row.RevocationState), channel,
+		)
+	}, sqldb.NoOpReset)
+	if err != nil {
+		return nil, err
+	}
+
+	return channel.RevocationStore, nil
+}
+
+// FindPreviousState scans through the revocation log in an attempt to recover
+// the previous channel state indicated by the update number. The native SQL
+// store only holds revocation logs in the current format, so the returned
+// commitment is always nil.
+func (s *SQLStore) FindPreviousState(channel *OpenChannel,
+	updateNum uint64) (*RevocationLog, *ChannelCommitment, error) {
+
+	ctx := context.TODO()
+
+	var rl *RevocationLog
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := fetchOpenChannelRow(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		logRow, err := db.FetchRevocationLog(
+			ctx, sqlc.FetchRevocationLogParams{
+				ChannelID:    row.ID,
+				CommitHeight: int64(updateNum),
+			},
+		)
+		switch {
+		// If the entry can't be found, we'll distinguish between a
+		// channel without any past states and a missing entry.
+		case errors.Is(err, sql.ErrNoRows):
+			numLogs, err := db.CountRevocationLogs(ctx, row.ID)
+			if err != nil {
+				return err
+			}
+			if numLogs == 0 {
+				return ErrNoPastDeltas
+			}
+
+			return ErrLogEntryNotFound
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch revocation log: %w",
+				err)
+		}
+
+		log, err := fetchRevocationLogSQL(ctx, db, logRow)
+		if err != nil {
+			return err
+		}
+		rl = &log
+
+		return nil
+	}, func() {
+		rl = nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rl, nil, nil
+}
+
+// LoadFwdPkgs scans the forwarding log for any packages that haven't been
+// processed, and returns their deserialized log updates.
+func (s *SQLStore) LoadFwdPkgs(channel *OpenChannel) ([]*FwdPkg, error) {
+	return s.LoadChannelFwdPkgs(channel.ShortChannelID)
+}
+
+// LoadChannelFwdPkgs loads all forwarding packages owned by the channel with
+// the given short channel ID.
+func (s *SQLStore) LoadChannelFwdPkgs(source lnwire.ShortChannelID) (
+	[]*FwdPkg, error) {
+
+	ctx := context.TODO()
+
+	var fwdPkgs []*FwdPkg
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		rows, err := db.ListFwdPkgs(ctx, int64(source.ToUint64()))
+		if err != nil {
+			return fmt.Errorf("unable to list forwarding "+
+				"packages: %w", err)
+		}
+
+		for _, row := range rows {
+			fwdPkg, err := fetchFwdPkgSQL(ctx, db, source, row)
+			if err != nil {
+				return err
+			}
+
+			fwdPkgs = append(fwdPkgs, fwdPkg)
+		}
+
+		return nil
+	}, func() {
+		fwdPkgs = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fwdPkgs, nil
+}
+
+// AckAddHtlcs updates the AckAddFilter containing any of the provided AddRefs
+// indicating that a response to this Add has been committed to the remote
+// party. Doing so will prevent these Add HTLCs from being reforwarded
+// internally.
+func (s *SQLStore) AckAddHtlcs(channel *OpenChannel, addRefs ...AddRef) error {
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		return ackAddHtlcsSQL(ctx, db, channel.ShortChannelID, addRefs)
+	}, sqldb.NoOpReset)
+}
+
+// AckSettleFails updates the SettleFailFilter containing any of the provided
+// SettleFailRefs, indicating that the response has been delivered to the
+// incoming link, corresponding to a particular AddRef. Doing so will prevent
+// the responses from being retransmitted internally.
+func (s *SQLStore) AckSettleFails(_ *OpenChannel,
+	settleFailRefs ...SettleFailRef) error {
+
+	return s.AckSettleFailRefs(settleFailRefs...)
+}
+
+// AckSettleFailRefs updates the SettleFailFilter of the forwarding packages
+// referenced by the given SettleFailRefs, which may belong to any channel.
+func (s *SQLStore) AckSettleFailRefs(settleFailRefs ...SettleFailRef) error {
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		return ackSettleFailsSQL(ctx, db, settleFailRefs)
+	}, sqldb.NoOpReset)
+}
+
+// SetFwdFilter atomically sets the forwarding filter for the forwarding
+// package identified by `height`. If a filter has already been written, the
+// call is a no-op.
+func (s *SQLStore) SetFwdFilter(channel *OpenChannel, height uint64,
+	fwdFilter *PkgFilter) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		source := int64(channel.ShortChannelID.ToUint64())
+		row, err := db.FetchFwdPkg(ctx, sqlc.FetchFwdPkgParams{
+			SourceScid: source,
+			Height:     int64(height),
+		})
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrCorruptedFwdPkg
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch forwarding "+
+				"package: %w", err)
+		}
+
+		return setFwdFilterSQL(ctx, db, row, fwdFilter)
+	}, sqldb.NoOpReset)
+}
+
+// RemoveFwdPkgs atomically removes forwarding packages specified by the remote
+// commitment heights.
+//
+// NOTE: This method should only be called on packages marked
+// FwdStateCompleted.
+func (s *SQLStore) RemoveFwdPkgs(channel *OpenChannel,
+	heights ...uint64) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		source := int64(channel.ShortChannelID.ToUint64())
+		for _, height := range heights {
+			err := db.DeleteFwdPkg(ctx, sqlc.DeleteFwdPkgParams{
+				SourceScid: source,
+				Height:     int64(height),
+			})
+			if err != nil {
+				return fmt.Errorf("unable to remove "+
+					"forwarding package: %w", err)
+			}
+		}
+
+		return nil
+	}, sqldb.NoOpReset)
+}
+
+// FetchClosedChannels attempts to fetch all closed channels from the
+// database. The pendingOnly bool toggles if channels that aren't yet fully
+// closed should be returned in the response or not.
+func (s *SQLStore) FetchClosedChannels(pendingOnly bool) (
+	[]*ChannelCloseSummary, error) {
+
+	ctx := context.TODO()
+
+	var chanSummaries []*ChannelCloseSummary
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		rows, err := db.ListChannelCloseSummaries(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to list close summaries: %w",
+				err)
+		}
+
+		for _, row := range rows {
+			// If the query specified to only include pending
+			// channels, then we'll skip any channels which aren't
+			// currently pending.
+			if !row.IsPending && pendingOnly {
+				continue
+			}
+
+			chanSummary, err := unmarshalCloseSummary(row)
+			if err != nil {
+				return err
+			}
+
+			chanSummaries = append(chanSummaries, chanSummary)
+		}
+
+		return nil
+	}, func() {
+		chanSummaries = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chanSummaries, nil
+}
+
+// FetchClosedChannel queries for a channel close summary using the channel
+// point of the channel in question.
+func (s *SQLStore) FetchClosedChannel(chanID *wire.OutPoint) (
+	*ChannelCloseSummary, error) {
+
+	ctx := context.TODO()
+
+	opBytes, err := serializeOutpoint(chanID)
+	if err != nil {
+		return nil, err
+	}
+
+	var chanSummary *ChannelCloseSummary
+	err = s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := db.FetchChannelCloseSummary(ctx, opBytes)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrClosedChannelNotFound
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch close summary: %w",
+				err)
+		}
+
+		chanSummary, err = unmarshalCloseSummary(row)
+
+		return err
+	}, func() {
+		chanSummary = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chanSummary, nil
+}
+
+// FetchClosedChannelForID queries for a channel close summary using the
+// channel ID of the channel in question.
+func (s *SQLStore) FetchClosedChannelForID(cid lnwire.ChannelID) (
+	*ChannelCloseSummary, error) {
+
+	ctx := context.TODO()
+
+	var chanSummary *ChannelCloseSummary
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := db.FetchChannelCloseSummaryByChanID(ctx, cid[:])
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrClosedChannelNotFound
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch close summary: %w",
+				err)
+		}
+
+		chanSummary, err = unmarshalCloseSummary(row)
+
+		return err
+	}, func() {
+		chanSummary = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chanSummary, nil
+}
+
+// MarkChanFullyClosed marks a channel as fully closed within the database. A
+// channel should be marked as fully closed if the channel was initially
+// cooperatively closed and it's reached a single confirmation, or after all
+// the pending funds in a channel that has been forcibly closed have been
+// swept.
+func (s *SQLStore) MarkChanFullyClosed(chanPoint *wire.OutPoint) error {
+	ctx := context.TODO()
+
+	opBytes, err := serializeOutpoint(chanPoint)
+	if err != nil {
+		return err
+	}
+
+	var (
+		remotePub    *crypto.PublicKey
+		openChannels int
+	)
+	err = s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, err := db.FetchChannelCloseSummary(ctx, opBytes)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("no closed channel for "+
+				"chan_point=%v found", chanPoint)
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch close summary: %w",
+				err)
+		}
+
+		chanSummary, err := unmarshalCloseSummary(row)
+		if err != nil {
+			return err
+		}
+
+		err = db.UpdateChannelCloseSummaryPending(
+			ctx, sqlc.UpdateChannelCloseSummaryPendingParams{
+				Outpoint:  row.Outpoint,
+				IsPending: false,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to update close summary: %w",
+				err)
+		}
+
+		// Now that the channel is closed, we'll check if we have any
+		// other open channels with this peer.
+		remotePub = chanSummary.RemotePub
+		rows, err := db.ListChannelStatesByNode(
+			ctx, remotePub.SerializeCompressed(),
+		)
+		if err != nil {
+			return fmt.Errorf("unable to fetch open channels for "+
+				"peer %x: %w", remotePub.SerializeCompressed(),
+				err)
+		}
+		openChannels = len(rows)
+
+		return nil
+	}, func() {
+		remotePub = nil
+		openChannels = 0
+	})
+	if err != nil {
+		return err
+	}
+
+	if openChannels > 0 {
+		return nil
+	}
+
+	// If there are no open channels with this peer, we'll garbage collect
+	// the link node to ensure we don't establish persistent connections
+	// to peers without open channels.
+	return s.pruneLinkNode(remotePub)
+}
+
+// CloseChannel closes a previously active channel. The channel is kept in
+// the store as a historical channel with the passed statuses applied, its
+// revocation log and forwarding packages are removed, and the close summary
+// is recorded.
+func (s *SQLStore) CloseChannel(channel *OpenChannel,
+	summary *ChannelCloseSummary, statuses ...ChannelStatus) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, chanState, err := s.fetchOpenChannel(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		switch {
+		// A channel that can't be found was either never opened or
+		// already closed.
+		case errors.Is(err, ErrChannelNotFound):
+			opBytes, err := serializeOutpoint(
+				&channel.FundingOutpoint,
+			)
+			if err != nil {
+				return err
+			}
+
+			_, err = db.FetchChannelStateByOutpoint(ctx, opBytes)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNoActiveChannels
+			}
+
+			return ErrChannelNotFound
+
+		case err != nil:
+			return err
+		}
+
+		err = db.DeleteFwdPkgs(
+			ctx, int64(chanState.ShortChannelID.ToUint64()),
+		)
+		if err != nil {
+			return fmt.Errorf("unable to delete forwarding "+
+				"packages: %w", err)
+		}
+
+		if err := db.DeleteRevocationLogs(ctx, row.ID); err != nil {
+			return fmt.Errorf("unable to delete revocation "+
+				"log: %w", err)
+		}
+
+		for _, status := range statuses {
+			chanState.SetChannelStatusForStore(
+				chanState.ChannelStatusForStore() | status,
+			)
+		}
+
+		if err := marshalChannel(&row, chanState); err != nil {
+			return err
+		}
+
+		// The historical channel only retains the channel state and
+		// the current commitments, so we drop all the data that is
+		// only relevant while the channel is open.
+		row.Closed = true
+		if err := updateChannelRow(ctx, db, &row); err != nil {
+			return err
+		}
+
+		if err := deleteCommitDiffSQL(ctx, db, row.ID); err != nil {
+			return err
+		}
+
+		for _, updateType := range []logUpdateType{
+			logUpdateUnsignedAcked, logUpdateRemoteUnsignedLocal,
+		} {
+			err := putLogUpdatesSQL(ctx, db, row.ID, updateType, nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		summary.RemoteCurrentRevocation =
+			chanState.RemoteCurrentRevocation
+		summary.RemoteNextRevocation = chanState.RemoteNextRevocation
+		summary.LocalChanConfig = chanState.LocalChanCfg
+
+		return putCloseSummarySQL(
+			ctx, db, &chanState.FundingOutpoint, summary,
+		)
+	}, sqldb.NoOpReset)
+}
+
+// AbandonChannel attempts to remove the target channel from the open channel
+// database. If the channel was already removed (has a closed channel entry),
+// then we'll return a nil error. Otherwise, we'll insert a new close summary
+// into the database.
+func (s *SQLStore) AbandonChannel(chanPoint *wire.OutPoint,
+	bestHeight uint32) error {
+
+	dbChan, err := s.FetchChannel(*chanPoint)
+	switch {
+	// If the channel wasn't found, then it's possible that it was already
+	// abandoned from the database.
+	case errors.Is(err, ErrChannelNotFound):
+		_, closedErr := s.FetchClosedChannel(chanPoint)
+		if closedErr != nil {
+			return closedErr
+		}
+
+		// If the channel was already closed, then we don't return an
+		// error as we'd like this step to be repeatable.
+		return nil
+
+	case err != nil:
+		return err
+	}
+
+	summary := &ChannelCloseSummary{
+		CloseType:   Abandoned,
+		ChanPoint:   *chanPoint,
+		ChainHash:   dbChan.ChainHash,
+		CloseHeight: bestHeight,
+		RemotePub:   dbChan.IdentityPub,
+		Capacity:    dbChan.Capacity,
+		SettledBalance: dbChan.LocalCommitment.LocalBalance.
+			ToLokis(),
+		ShortChanID:             dbChan.ShortChanID(),
+		RemoteCurrentRevocation: dbChan.RemoteCurrentRevocation,
+		RemoteNextRevocation:    dbChan.RemoteNextRevocation,
+		LocalChanConfig:         dbChan.LocalChanCfg,
+	}
+
+	// Finally, we'll close the channel in the DB, and return back to the
+	// caller. We set ourselves as the close initiator because we abandoned
+	// the channel.
+	return dbChan.CloseChannel(summary, ChanStatusLocalCloseInitiator)
+}
+
+// LookupFinalHtlc retrieves a final htlc resolution from the database. If the
+// htlc has no final resolution yet, ErrHtlcUnknown is returned.
+func (s *SQLStore) LookupFinalHtlc(chanID lnwire.ShortChannelID,
+	htlcIndex uint64) (*FinalHtlcInfo, error) {
+
+	ctx := context.TODO()
+
+	var info *FinalHtlcInfo
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := db.FetchFinalHtlc(ctx, sqlc.FetchFinalHtlcParams{
+			Scid:      int64(chanID.ToUint64()),
+			HtlcIndex: int64(htlcIndex),
+		})
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrHtlcUnknown
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch final htlc: %w", err)
+		}
+
+		info = &FinalHtlcInfo{
+			Settled:  row.Settled,
+			Offchain: row.Offchain,
+		}
+
+		return nil
+	}, func() {
+		info = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// PutOnchainFinalHtlcOutcome stores the final on-chain outcome of an htlc in
+// the database.
+func (s *SQLStore) PutOnchainFinalHtlcOutcome(chanID lnwire.ShortChannelID,
+	htlcID uint64, settled bool) error {
+
+	// Skip if the user did not opt in to storing final resolutions.
+	if !s.storeFinalHtlcResolutions {
+		return nil
+	}
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		return db.UpsertFinalHtlc(ctx, sqlc.UpsertFinalHtlcParams{
+			Scid:      int64(chanID.ToUint64()),
+			HtlcIndex: int64(htlcID),
+			Settled:   settled,
+			Offchain:  false,
+		})
+	}, sqldb.NoOpReset)
+}
+
+// SaveChannelOpeningState saves the serialized channel state for the provided
+// chanPoint.
+func (s *SQLStore) SaveChannelOpeningState(outPoint,
+	serializedState []byte) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		return db.UpsertChannelOpeningState(
+			ctx, sqlc.UpsertChannelOpeningStateParams{
+				Outpoint: outPoint,
+				State:    serializedState,
+			},
+		)
+	}, sqldb.NoOpReset)
+}
+
+// GetChannelOpeningState fetches the serialized channel state for the
+// provided outPoint from the database, or returns ErrChannelNotFound if the
+// channel is not found.
+func (s *SQLStore) GetChannelOpeningState(outPoint []byte) ([]byte, error) {
+	ctx := context.TODO()
+
+	var serializedState []byte
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		state, err := db.FetchChannelOpeningState(ctx, outPoint)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrChannelNotFound
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch opening state: %w",
+				err)
+		}
+
+		serializedState = state
+
+		return nil
+	}, func() {
+		serializedState = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return serializedState, nil
+}
+
+// DeleteChannelOpeningState removes any state for outPoint from the database.
+func (s *SQLStore) DeleteChannelOpeningState(outPoint []byte) error {
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		return db.DeleteChannelOpeningState(ctx, outPoint)
+	}, sqldb.NoOpReset)
+}
+
+// SaveInitialForwardingPolicy saves the forwarding policy for the provided
+// permanent channel id.
+func (s *SQLStore) SaveInitialForwardingPolicy(chanID lnwire.ChannelID,
+	forwardingPolicy *models.ForwardingPolicy) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		return db.UpsertChannelForwardingPolicy(
+			ctx, sqlc.UpsertChannelForwardingPolicyParams{
+				ChanID: chanID[:],
+				MinHtlcMsat: int64(
+					forwardingPolicy.MinHTLCOut,
+				),
+				MaxHtlcMsat: int64(forwardingPolicy.MaxHTLC),
+				BaseFeeMsat: int64(forwardingPolicy.BaseFee),
+				FeeRate:     int64(forwardingPolicy.FeeRate),
+				TimeLockDelta: int64(
+					forwardingPolicy.TimeLockDelta,
+				),
+			},
+		)
+	}, sqldb.NoOpReset)
+}
+
+// GetInitialForwardingPolicy fetches the forwarding policy for the provided
+// channel id from the database, or returns ErrChannelNotFound if a
+// forwarding policy for this channel id is not found.
+func (s *SQLStore) GetInitialForwardingPolicy(chanID lnwire.ChannelID) (
+	*models.ForwardingPolicy, error) {
+
+	ctx := context.TODO()
+
+	var forwardingPolicy *models.ForwardingPolicy
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := db.FetchChannelForwardingPolicy(ctx, chanID[:])
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrChannelNotFound
+
+		case err != nil:
+			return fmt.Errorf("unable to fetch forwarding "+
+				"policy: %w", err)
+		}
+
+		forwardingPolicy = &models.ForwardingPolicy{
+			MinHTLCOut:    lnwire.MilliLoki(row.MinHtlcMsat),
+			MaxHTLC:       lnwire.MilliLoki(row.MaxHtlcMsat),
+			BaseFee:       lnwire.MilliLoki(row.BaseFeeMsat),
+			FeeRate:       lnwire.MilliLoki(row.FeeRate),
+			TimeLockDelta: uint32(row.TimeLockDelta),
+		}
+
+		return nil
+	}, func() {
+		forwardingPolicy = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return forwardingPolicy, nil
+}
+
+// DeleteInitialForwardingPolicy removes the forwarding policy for a given
+// channel from the database.
+func (s *SQLStore) DeleteInitialForwardingPolicy(
+	chanID lnwire.ChannelID) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		return db.DeleteChannelForwardingPolicy(ctx, chanID[:])
+	}, sqldb.NoOpReset)
+}
+
+// PruneLinkNodes attempts to prune all link nodes found within the database
+// with whom we no longer have any open channels with.
+func (s *SQLStore) PruneLinkNodes() error {
+	allLinkNodes, err := s.linkNodeDB.FetchAllLinkNodes()
+	if err != nil {
+		return err
+	}
+
+	for _, linkNode := range allLinkNodes {
+		err := s.pruneLinkNode(linkNode.IdentityPub)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RepairLinkNodes scans all channels in the database and ensures that a link
+// node exists for each remote peer.
+func (s *SQLStore) RepairLinkNodes(network wire.FlokicoinNet) error {
+	channels, err := s.FetchAllChannels()
+	if err != nil {
+		return fmt.Errorf("unable to fetch channels: %w", err)
+	}
+
+	seen := make(map[[33]byte]struct{})
+	var peersWithChannels []*crypto.PublicKey
+	for _, channel := range channels {
+		var pub [33]byte
+		copy(pub[:], channel.IdentityPub.SerializeCompressed())
+		if _, ok := seen[pub]; ok {
+			continue
+		}
+		seen[pub] = struct{}{}
+
+		peersWithChannels = append(
+			peersWithChannels, channel.IdentityPub,
+		)
+	}
+
+	missingPeers, err := s.linkNodeDB.FindMissingLinkNodes(
+		nil, peersWithChannels,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Early exit if no repairs needed.
+	if len(missingPeers) == 0 {
+		return nil
+	}
+
+	linkNodesToCreate := make([]*LinkNode, 0, len(missingPeers))
+	for _, remotePub := range missingPeers {
+		linkNode := NewLinkNode(s.linkNodeDB, network, remotePub)
+		linkNodesToCreate = append(linkNodesToCreate, linkNode)
+
+		log.Infof("Repairing missing link node for peer %x",
+			remotePub.SerializeCompressed())
+	}
+
+	err = s.linkNodeDB.CreateLinkNodes(nil, linkNodesToCreate)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Repaired %d missing link nodes on startup",
+		len(missingPeers))
+
+	return nil
+}
+
+// createLinkNode creates a link node for the passed peer if it doesn't exist
+// yet.
+func (s *SQLStore) createLinkNode(pub *crypto.PublicKey,
+	addrs []net.Addr) error {
+
+	_, err := s.linkNodeDB.FetchLinkNode(pub)
+	switch {
+	// If a LinkNode for this identity public key already exists, then we
+	// can exit early.
+	case err == nil:
+		return nil
+
+	case !errors.Is(err, ErrNodeNotFound):
+		return err
+	}
+
+	linkNode := NewLinkNode(s.linkNodeDB, wire.MainNet, pub, addrs...)
+
+	return s.linkNodeDB.CreateLinkNodes(nil, []*LinkNode{linkNode})
+}
+
+// pruneLinkNode removes the link node of the passed peer if we no longer have
+// any open channels with it.
+func (s *SQLStore) pruneLinkNode(remotePub *crypto.PublicKey) error {
+	openChannels, err := s.FetchOpenChannels(remotePub)
+	if err != nil {
+		return err
+	}
+
+	// If channels exist, don't prune.
+	if len(openChannels) > 0 {
+		return nil
+	}
+
+	log.Infof("Pruning link node %x with zero open channels from "+
+		"database", remotePub.SerializeCompressed())
+
+	err = s.linkNodeDB.DeleteLinkNode(remotePub)
+	if err != nil && !errors.Is(err, ErrNodeNotFound) {
+		return fmt.Errorf("unable to prune link node: %w", err)
+	}
+
+	return nil
+}
+
+// fetchNodeChannels returns all open channels with the passed peer.
+func (s *SQLStore) fetchNodeChannels(ctx context.Context, db SQLQueries,
+	nodeID *crypto.PublicKey) ([]*OpenChannel, error) {
+
+	rows, err := db.ListChannelStatesByNode(
+		ctx, nodeID.SerializeCompressed(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list channels: %w", err)
+	}
+
+	var channels []*OpenChannel
+	for _, row := range rows {
+		channel, err := s.unmarshalChannel(ctx, db, row)
+		if err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, channel)
+	}
+
+	return channels, nil
+}
+
+// fetchOpenChannel fetches the row of an open channel together with its
+// deserialized state.
+func (s *SQLStore) fetchOpenChannel(ctx context.Context, db SQLQueries,
+	chanPoint *wire.OutPoint) (sqlc.Channel, *OpenChannel, error) {
+
+	row, err := fetchOpenChannelRow(ctx, db, chanPoint)
+	if err != nil {
+		return sqlc.Channel{}, nil, err
+	}
+
+	channel, err := s.unmarshalChannel(ctx, db, row)
+	if err != nil {
+		return sqlc.Channel{}, nil, err
+	}
+
+	return row, channel, nil
+}
+
+// fetchUnborkedChannelRow fetches the row of the passed open channel and
+// makes sure that the channel hasn't been marked as borked. If it has, then
+// for safety reasons, we shouldn't attempt any further updates.
+func (s *SQLStore) fetchUnborkedChannelRow(ctx context.Context,
+	db SQLQueries, channel *OpenChannel) (sqlc.Channel, error) {
+
+	row, diskChannel, err := s.fetchOpenChannel(
+		ctx, db, &channel.FundingOutpoint,
+	)
+	if err != nil {
+		return sqlc.Channel{}, err
+	}
+
+	if diskChannel.ChannelStatusForStore() != ChanStatusDefault {
+		return sqlc.Channel{}, ErrChanBorked
+	}
+
+	return row, nil
+}
+
+// updateDiskChannel fetches the persisted state of the passed channel,
+// applies the given modification and writes the result back.
+func (s *SQLStore) updateDiskChannel(channel *OpenChannel,
+	modify func(diskChannel *OpenChannel)) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, diskChannel, err := s.fetchOpenChannel(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		modify(diskChannel)
+
+		if err := marshalChannel(&row, diskChannel); err != nil {
+			return err
+		}
+
+		return updateChannelRow(ctx, db, &row)
+	}, sqldb.NoOpReset)
+}
+
+// updateChannelRow fetches the row of the passed open channel, applies the
+// given modification and writes the result back.
+func (s *SQLStore) updateChannelRow(channel *OpenChannel,
+	modify func(row *sqlc.Channel) error) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, err := fetchOpenChannelRow(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := modify(&row); err != nil {
+			return err
+		}
+
+		return updateChannelRow(ctx, db, &row)
+	}, sqldb.NoOpReset)
+}
+
+// putChanStatus appends the given status to the channel. fs is an optional
+// list of closures that are given the channel row in order to atomically add
+// extra information together with the new status.
+func (s *SQLStore) putChanStatus(channel *OpenChannel, status ChannelStatus,
+	fs ...func(row *sqlc.Channel)) error {
+
+	ctx := context.TODO()
+
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, diskChannel, err := s.fetchOpenChannel(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Add this status to the existing bitvector found in the DB.
+		status = diskChannel.ChannelStatusForStore() | status
+		diskChannel.SetChannelStatusForStore(status)
+
+		if err := marshalChannel(&row, diskChannel); err != nil {
+			return err
+		}
+
+		for _, f := range fs {
+			f(&row)
+		}
+
+		return updateChannelRow(ctx, db, &row)
+	}, sqldb.NoOpReset)
+	if err != nil {
+		return err
+	}
+
+	// Update the in-memory representation to keep it in sync with the DB.
+	channel.SetChannelStatusForStore(status)
+
+	return nil
+}
+
+// fetchChannelBlob returns the blob that the passed getter selects from the
+// row of the given open channel. If either the channel or the blob can't be
+// found, errNotFound is returned.
+func (s *SQLStore) fetchChannelBlob(channel *OpenChannel, errNotFound error,
+	getBlob func(row sqlc.Channel) []byte) ([]byte, error) {
+
+	ctx := context.TODO()
+
+	var blob []byte
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := fetchOpenChannelRow(
+			ctx, db, &channel.FundingOutpoint,
+		)
+		switch {
+		case errors.Is(err, ErrChannelNotFound):
+			return errNotFound
+
+		case err != nil:
+			return err
+		}
+
+		blob = getBlob(row)
+		if blob == nil {
+			return errNotFound
+		}
+
+		return nil
+	}, func() {
+		blob = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blob, nil
+}
+
+// unmarshalChannel deserializes the channel stored in the passed row.
+func (s *SQLStore) unmarshalChannel(ctx context.Context, db SQLQueries,
+	row sqlc.Channel) (*OpenChannel, error) {
+
+	channel := &OpenChannel{}
+	if err := populateChannel(ctx, db, row, channel); err != nil {
+		return nil, err
+	}
+
+	// Frozen and leased channels also store their thaw height.
+	if channel.ChanType.IsFrozen() ||
+		channel.ChanType.HasLeaseExpiration() {
+
+		channel.ThawHeight = uint32(row.ThawHeight)
+	}
+
+	channel.Db = s
+
+	return channel, nil
+}
+
+// populateChannel populates the passed channel with the static info and the
+// revocation state stored in the given row, and with its commitments.
+func populateChannel(ctx context.Context, db SQLQueries, row sqlc.Channel,
+	channel *OpenChannel) error {
+
+	err := deserializeChanInfo(bytes.NewReader(row.ChanInfo), channel)
+	if err != nil {
+		return fmt.Errorf("unable to fetch chan info: %w", err)
+	}
+
+	channel.LastWasRevoke = row.LastWasRevoke
+	if len(row.LocalShutdownScript) != 0 {
+		channel.LocalShutdownScript = row.LocalShutdownScript
+	}
+	if len(row.RemoteShutdownScript) != 0 {
+		channel.RemoteShutdownScript = row.RemoteShutdownScript
+	}
+
+	err = fetchChanCommitmentsSQL(ctx, db, row.ID, channel)
+	if err != nil {
+		return fmt.Errorf("unable to fetch chan commitments: %w", err)
+	}
+
+	err = deserializeChanRevocationState(
+		bytes.NewReader(row.RevocationState), channel,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to fetch chan revocations: %w", err)
+	}
+
+	return nil
+}
+
+// marshalChannel serializes the state of the passed channel into the given
+// row. This mirrors putOpenChannel of the KV store: the thaw height of
+// regular channels is left as is. The commitments are stored in their own
+// table and are written separately.
+func marshalChannel(row *sqlc.Channel, channel *OpenChannel) error {
+	var info bytes.Buffer
+	if err := serializeChanInfo(&info, channel); err != nil {
+		return fmt.Errorf("unable to store chan info: %w", err)
+	}
+	row.ChanInfo = info.Bytes()
+	row.LocalShutdownScript = nilIfEmpty(channel.LocalShutdownScript)
+	row.RemoteShutdownScript = nilIfEmpty(channel.RemoteShutdownScript)
+
+	if channel.ChanType.IsFrozen() ||
+		channel.ChanType.HasLeaseExpiration() {
+
+		row.ThawHeight = int64(channel.ThawHeight)
+	}
+
+	var revState bytes.Buffer
+	if err := serializeChanRevocationState(&revState, channel); err != nil {
+		return fmt.Errorf("unable to store chan revocations: %w", err)
+	}
+	row.RevocationState = revState.Bytes()
+
+	return nil
+}
+
+// insertChannel inserts a new open channel together with its commitments and
+// returns its ID. ErrChanAlreadyExists is returned if a channel with the same
+// funding outpoint already exists.
+func insertChannel(ctx context.Context, db SQLQueries,
+	channel *OpenChannel) (int64, error) {
+
+	opBytes, err := serializeOutpoint(&channel.FundingOutpoint)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = db.FetchChannelStateByOutpoint(ctx, opBytes)
+	switch {
+	case err == nil:
+		return 0, ErrChanAlreadyExists
+
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, fmt.Errorf("unable to fetch channel: %w", err)
+	}
+
+	cid := lnwire.NewChanIDFromOutPoint(channel.FundingOutpoint)
+	_, err = db.FetchChannelStateByChanID(ctx, cid[:])
+	switch {
+	case err == nil:
+		return 0, ErrChanAlreadyExists
+
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, fmt.Errorf("unable to fetch channel: %w", err)
+	}
+
+	var row sqlc.Channel
+	if err := marshalChannel(&row, channel); err != nil {
+		return 0, err
+	}
+
+	channelID, err := db.InsertChannelState(
+		ctx, sqlc.InsertChannelStateParams{
+			Outpoint:             opBytes,
+			ChanID:               cid[:],
+			NodePubKey:           channel.IdentityPub.SerializeCompressed(),
+			ChainHash:            channel.ChainHash[:],
+			Closed:               false,
+			ChanInfo:             row.ChanInfo,
+			RevocationState:      row.RevocationState,
+			LocalShutdownScript:  row.LocalShutdownScript,
+			RemoteShutdownScript: row.RemoteShutdownScript,
+			ThawHeight:           row.ThawHeight,
+		},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("unable to insert channel: %w", err)
+	}
+
+	err = putChanCommitmentsSQL(ctx, db, channelID, channel)
+	if err != nil {
+		return 0, err
+	}
+
+	return channelID, nil
+}
+
+// updateChannelRow writes all mutable columns of the passed row.
+func updateChannelRow(ctx context.Context, db SQLQueries,
+	row *sqlc.Channel) error {
+
+	err := db.UpdateChannelState(ctx, sqlc.UpdateChannelStateParams{
+		ID:                   row.ID,
+		Closed:               row.Closed,
+		ChanInfo:             row.ChanInfo,
+		RevocationState:      row.RevocationState,
+		LocalShutdownScript:  row.LocalShutdownScript,
+		RemoteShutdownScript: row.RemoteShutdownScript,
+		ThawHeight:           row.ThawHeight,
+		LastWasRevoke:        row.LastWasRevoke,
+		DataLossCommitPoint:  row.DataLossCommitPoint,
+		ForceCloseTx:         row.ForceCloseTx,
+		CoopCloseTx:          row.CoopCloseTx,
+		ShutdownInfo:         row.ShutdownInfo,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to update channel: %w", err)
+	}
+
+	return nil
+}
+
+// fetchOpenChannelRow fetches the row of the open channel with the passed
+// funding outpoint. ErrChannelNotFound is returned if the channel doesn't
+// exist or has already been closed.
+func fetchOpenChannelRow(ctx context.Context, db SQLQueries,
+	chanPoint *wire.OutPoint) (sqlc.Channel, error) {
+
+	opBytes, err := serializeOutpoint(chanPoint)
+	if err != nil {
+		return sqlc.Channel{}, err
+	}
+
+	row, err := db.FetchChannelStateByOutpoint(ctx, opBytes)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return sqlc.Channel{}, ErrChannelNotFound
+
+	case err != nil:
+		return sqlc.Channel{}, fmt.Errorf("unable to fetch channel: %w",
+			err)
+
+	case row.Closed:
+		return sqlc.Channel{}, ErrChannelNotFound
+	}
+
+	return row, nil
+}
+
+// ackAddHtlcsSQL acks the adds of the forwarding packages of the passed
+// source channel that are referenced by the given AddRefs. Forwarding packages
+// that were already removed are skipped.
+func ackAddHtlcsSQL(ctx context.Context, db SQLQueries,
+	source lnwire.ShortChannelID, addRefs []AddRef) error {
+
+	// Organize the forward references such that we just get a single
+	// slice of indexes for each unique height.
+	heightDiffs := make(map[uint64][]uint16)
+	for _, addRef := range addRefs {
+		heightDiffs[addRef.Height] = append(
+			heightDiffs[addRef.Height], addRef.Index,
+		)
+	}
+
+	for height, indexes := range heightDiffs {
+		err := ackFwdPkgUpdates(
+			ctx, db, source, height, fwdPkgUpdateAdd, indexes,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ackSettleFailsSQL acks the settles and fails of the forwarding packages that
+// are referenced by the given SettleFailRefs. Forwarding packages that were
+// already removed are skipped.
+func ackSettleFailsSQL(ctx context.Context, db SQLQueries,
+	settleFailRefs []SettleFailRef) error {
+
+	// Organize the forward references such that we just get a single
+	// slice of indexes for each unique destination-height pair.
+	type destHeight struct {
+		dest   lnwire.ShortChannelID
+		height uint64
+	}
+	destHeightDiffs := make(map[destHeight][]uint16)
+	for _, ref := range settleFailRefs {
+		key := destHeight{dest: ref.Source, height: ref.Height}
+		destHeightDiffs[key] = append(destHeightDiffs[key], ref.Index)
+	}
+
+	for key, indexes := range destHeightDiffs {
+		err := ackFwdPkgUpdates(
+			ctx, db, key.dest, key.height, fwdPkgUpdateSettleFail,
+			indexes,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// serializeOutpoint serializes the passed outpoint in the same format that is
+// used for the keys of the KV store.
+func serializeOutpoint(op *wire.OutPoint) ([]byte, error) {
+	var b bytes.Buffer
+	if err := graphdb.WriteOutpoint(&b, op); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// nilIfEmpty returns nil for empty byte slices so that optional blobs are
+// stored as NULL.
+func nilIfEmpty(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+
+	return b
+}
@@ -0,0 +1,477 @@
+package channeldb
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// newSQLTestStore creates a SQLStore backed by a SQLite database for testing.
+// The link nodes are stored in a KV test database.
+func newSQLTestStore(t *testing.T, options ...OptionModifier) *SQLStore {
+	fullDB, err := MakeTestDB(t)
+	require.NoError(t, err)
+
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) SQLQueries {
+			return db.WithTx(tx)
+		},
+	)
+
+	return NewSQLStore(
+		executor, fullDB.ChannelStateDB().LinkNodeDB(), options...,
+	)
+}
+
+// assertSQLChannelEqual asserts that the passed channels are equal, ignoring
+// the store they are backed by.
+func assertSQLChannelEqual(t *testing.T, expected, actual *OpenChannel) {
+	t.Helper()
+
+	expectedDb, actualDb := expected.Db, actual.Db
+	expected.Db, actual.Db = nil, nil
+	defer func() {
+		expected.Db, actual.Db = expectedDb, actualDb
+	}()
+
+	require.Equal(t, expected, actual)
+}
+
+// createSQLTestChannel writes a fully open test channel to the passed store.
+func createSQLTestChannel(t *testing.T, store *SQLStore) *OpenChannel {
+	channel := createTestChannelState(t, nil)
+	channel.Db = store
+
+	err := channel.SyncPending(defaultAddr, uint32(defaultPendingHeight))
+	require.NoError(t, err)
+
+	err = channel.MarkAsOpen(channel.ShortChannelID)
+	require.NoError(t, err)
+
+	return channel
+}
+
+// TestSQLStoreOpenChannel asserts that channels can be written to and read
+// from the SQL store, and that their status can be updated.
+func TestSQLStoreOpenChannel(t *testing.T) {
+	t.Parallel()
+
+	store := newSQLTestStore(t)
+
+	channel := createTestChannelState(t, nil)
+	channel.Db = store
+	channel.LocalShutdownScript = lnwire.DeliveryAddress{1, 2, 3}
+
+	err := channel.SyncPending(defaultAddr, uint32(defaultPendingHeight))
+	require.NoError(t, err)
+
+	// Syncing the same channel again isn't allowed.
+	err = store.SyncPendingChannel(
+		channel, defaultAddr, uint32(defaultPendingHeight),
+	)
+	require.ErrorIs(t, err, ErrChanAlreadyExists)
+
+	// A link node for the peer is created together with the channel.
+	_, err = store.LinkNodeDB().FetchLinkNode(channel.IdentityPub)
+	require.NoError(t, err)
+
+	pending, err := store.FetchPendingChannels()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assertSQLChannelEqual(t, channel, pending[0])
+
+	// Once the channel is marked as open, it's no longer pending.
+	err = channel.MarkAsOpen(channel.ShortChannelID)
+	require.NoError(t, err)
+
+	pending, err = store.FetchPendingChannels()
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	open, err := store.FetchAllOpenChannels()
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	assertSQLChannelEqual(t, channel, open[0])
+
+	dbChannel, err := store.FetchChannel(channel.FundingOutpoint)
+	require.NoError(t, err)
+	assertSQLChannelEqual(t, channel, dbChannel)
+
+	dbChannel, err = store.FetchChannelByID(
+		lnwire.NewChanIDFromOutPoint(channel.FundingOutpoint),
+	)
+	require.NoError(t, err)
+	assertSQLChannelEqual(t, channel, dbChannel)
+
+	peerChannels, err := store.FetchOpenChannels(channel.IdentityPub)
+	require.NoError(t, err)
+	require.Len(t, peerChannels, 1)
+
+	peers, err := store.FetchPermAndTempPeers(channel.ChainHash[:])
+	require.NoError(t, err)
+	require.True(
+		t, peers[string(channel.IdentityPub.SerializeCompressed())].
+			HasOpenOrClosedChan,
+	)
+
+	// Marking the commitment as broadcast moves the channel to the
+	// waiting close set and stores the closing transaction.
+	closeTx := channel.FundingTxn.Copy()
+	err = channel.MarkCommitmentBroadcasted(closeTx, lntypes.Local)
+	require.NoError(t, err)
+
+	waitingClose, err := store.FetchWaitingCloseChannels()
+	require.NoError(t, err)
+	require.Len(t, waitingClose, 1)
+	require.True(t, waitingClose[0].HasChanStatus(
+		ChanStatusLocalCloseInitiator,
+	))
+
+	dbCloseTx, err := channel.BroadcastedCommitment()
+	require.NoError(t, err)
+	require.Equal(t, closeTx, dbCloseTx)
+
+	_, err = channel.BroadcastedCooperative()
+	require.ErrorIs(t, err, ErrNoCloseTx)
+
+	// A borked channel can't be updated anymore.
+	_, err = channel.UpdateCommitment(&channel.LocalCommitment, nil)
+	require.ErrorIs(t, err, ErrChanBorked)
+
+	err = channel.ClearChanStatus(ChanStatusCommitBroadcasted)
+	require.NoError(t, err)
+	require.False(t, channel.HasChanStatus(ChanStatusCommitBroadcasted))
+
+	require.NoError(t, store.RefreshChannel(dbChannel))
+	require.Equal(t, channel.ChanStatus(), dbChannel.ChanStatus())
+}
+
+// TestSQLStoreStateTransition asserts that the commitment chain can be
+// advanced, that revoked states are kept in the revocation log and that the
+// forwarding packages are written, up until the channel is closed.
+func TestSQLStoreStateTransition(t *testing.T) {
+	t.Parallel()
+
+	store := newSQLTestStore(t)
+	channel := createSQLTestChannel(t, store)
+
+	// There are no past states yet.
+	_, _, err := channel.FindPreviousState(0)
+	require.ErrorIs(t, err, ErrNoPastDeltas)
+
+	commitment := channel.LocalCommitment
+	commitment.CommitHeight = 1
+	commitment.LocalLogIndex = 2
+	commitment.LocalBalance = lnwire.MilliLoki(1e8)
+
+	unsignedAckedUpdates := []LogUpdate{{
+		LogIndex: 2,
+		UpdateMsg: &lnwire.UpdateAddHTLC{
+			ChanID: lnwire.ChannelID{1, 2, 3},
+		},
+	}}
+	_, err = channel.UpdateCommitment(&commitment, unsignedAckedUpdates)
+	require.NoError(t, err)
+
+	dbUpdates, err := channel.UnsignedAckedUpdates()
+	require.NoError(t, err)
+	require.Equal(t, unsignedAckedUpdates, dbUpdates)
+
+	height, err := channel.CommitmentHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, height)
+
+	_, err = channel.RemoteCommitChainTip()
+	require.ErrorIs(t, err, ErrNoPendingCommit)
+
+	// Extend a new state to the remote party.
+	remoteCommit := commitment
+	remoteCommit.RemoteLogIndex = 3
+	commitDiff := &CommitDiff{
+		Commitment: remoteCommit,
+		CommitSig: &lnwire.CommitSig{
+			ChanID:    lnwire.ChannelID(key),
+			CommitSig: wireSig,
+			HtlcSigs:  []lnwire.Sig{wireSig},
+		},
+		LogUpdates: []LogUpdate{{
+			LogIndex: 1,
+			UpdateMsg: &lnwire.UpdateAddHTLC{
+				ID:     1,
+				Amount: lnwire.NewMSatFromLokis(100),
+				Expiry: 25,
+			},
+		}},
+		OpenedCircuitKeys: []models.CircuitKey{},
+		ClosedCircuitKeys: []models.CircuitKey{},
+	}
+	require.NoError(t, channel.AppendRemoteCommitChain(commitDiff))
+
+	dbCommitDiff, err := channel.RemoteCommitChainTip()
+	require.NoError(t, err)
+	require.Equal(t, commitDiff, dbCommitDiff)
+
+	oldRemoteCommit := channel.RemoteCommitment
+	newPriv, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+	channel.RemoteCurrentRevocation = channel.RemoteNextRevocation
+	channel.RemoteNextRevocation = newPriv.PubKey()
+
+	fwdPkg := NewFwdPkg(
+		channel.ShortChanID(), oldRemoteCommit.CommitHeight,
+		dbCommitDiff.LogUpdates, nil,
+	)
+	err = channel.AdvanceCommitChainTail(
+		fwdPkg, nil, dummyLocalOutputIndex, dummyRemoteOutIndex,
+	)
+	require.NoError(t, err)
+
+	_, err = channel.RemoteCommitChainTip()
+	require.ErrorIs(t, err, ErrNoPendingCommit)
+
+	// The acked update is covered by the new remote commitment, so it's no
+	// longer kept around.
+	dbUpdates, err = channel.UnsignedAckedUpdates()
+	require.NoError(t, err)
+	require.Empty(t, dbUpdates)
+
+	prevState, _, err := channel.FindPreviousState(
+		oldRemoteCommit.CommitHeight,
+	)
+	require.NoError(t, err)
+	assertRevocationLogEntryEqual(t, &oldRemoteCommit, prevState)
+
+	_, _, err = channel.FindPreviousState(100)
+	require.ErrorIs(t, err, ErrLogEntryNotFound)
+
+	dbChannel, err := store.FetchChannel(channel.FundingOutpoint)
+	require.NoError(t, err)
+	require.Equal(t, remoteCommit, dbChannel.RemoteCommitment)
+	require.True(t, channel.RemoteNextRevocation.IsEqual(
+		dbChannel.RemoteNextRevocation,
+	))
+
+	// The forwarding package is locked in until the forwarding filter is
+	// written, and completed once everything is acked.
+	fwdPkgs, err := channel.LoadFwdPkgs()
+	require.NoError(t, err)
+	require.Len(t, fwdPkgs, 1)
+	require.Equal(t, FwdStateLockedIn, fwdPkgs[0].State)
+
+	fwdFilter := NewPkgFilter(1)
+	fwdFilter.Set(0)
+	err = channel.SetFwdFilter(fwdPkg.Height, fwdFilter)
+	require.NoError(t, err)
+
+	err = channel.AckAddHtlcs(AddRef{Height: fwdPkg.Height, Index: 0})
+	require.NoError(t, err)
+
+	fwdPkgs, err = store.LoadChannelFwdPkgs(channel.ShortChanID())
+	require.NoError(t, err)
+	require.Len(t, fwdPkgs, 1)
+	require.Equal(t, FwdStateCompleted, fwdPkgs[0].State)
+	require.Equal(t, fwdFilter, fwdPkgs[0].FwdFilter)
+
+	// Finally, close the channel.
+	closeSummary := &ChannelCloseSummary{
+		ChanPoint:      channel.FundingOutpoint,
+		ChainHash:      channel.ChainHash,
+		RemotePub:      channel.IdentityPub,
+		SettledBalance: chainutil.Amount(500),
+		IsPending:      true,
+		CloseType:      RemoteForceClose,
+	}
+	err = channel.CloseChannel(closeSummary, ChanStatusRemoteCloseInitiator)
+	require.NoError(t, err)
+
+	err = channel.CloseChannel(closeSummary)
+	require.ErrorIs(t, err, ErrChannelNotFound)
+
+	_, err = store.FetchChannel(channel.FundingOutpoint)
+	require.ErrorIs(t, err, ErrChannelNotFound)
+
+	_, _, err = channel.FindPreviousState(oldRemoteCommit.CommitHeight)
+	require.Error(t, err)
+
+	fwdPkgs, err = store.LoadChannelFwdPkgs(channel.ShortChanID())
+	require.NoError(t, err)
+	require.Empty(t, fwdPkgs)
+
+	histChannel, err := store.FetchHistoricalChannel(
+		&channel.FundingOutpoint,
+	)
+	require.NoError(t, err)
+	require.True(t, histChannel.HasChanStatus(
+		ChanStatusRemoteCloseInitiator,
+	))
+
+	pendingClosed, err := store.FetchClosedChannels(true)
+	require.NoError(t, err)
+	require.Len(t, pendingClosed, 1)
+
+	summary, err := store.FetchClosedChannelForID(
+		lnwire.NewChanIDFromOutPoint(channel.FundingOutpoint),
+	)
+	require.NoError(t, err)
+	require.Equal(t, pendingClosed[0], summary)
+
+	// Marking the channel as fully closed prunes the link node, as there
+	// are no other channels with the peer.
+	err = store.MarkChanFullyClosed(&channel.FundingOutpoint)
+	require.NoError(t, err)
+
+	pendingClosed, err = store.FetchClosedChannels(true)
+	require.NoError(t, err)
+	require.Empty(t, pendingClosed)
+
+	_, err = store.LinkNodeDB().FetchLinkNode(channel.IdentityPub)
+	require.ErrorIs(t, err, ErrNodeNotFound)
+}
+
+// TestSQLStoreFinalHtlcs asserts that the final htlc outcomes are only stored
+// if the store was configured to do so.
+func TestSQLStoreFinalHtlcs(t *testing.T) {
+	t.Parallel()
+
+	chanID := lnwire.NewShortChanIDFromInt(1)
+
+	store := newSQLTestStore(t)
+	require.NoError(t, store.PutOnchainFinalHtlcOutcome(chanID, 2, true))
+	_, err := store.LookupFinalHtlc(chanID, 2)
+	require.ErrorIs(t, err, ErrHtlcUnknown)
+
+	store = newSQLTestStore(t, OptionStoreFinalHtlcResolutions(true))
+	require.NoError(t, store.PutOnchainFinalHtlcOutcome(chanID, 2, true))
+
+	info, err := store.LookupFinalHtlc(chanID, 2)
+	require.NoError(t, err)
+	require.Equal(t, &FinalHtlcInfo{Settled: true}, info)
+}
+
+// TestSQLStoreChannelSetup asserts that the state of channels that are in the
+// process of being opened can be stored and removed again.
+func TestSQLStoreChannelSetup(t *testing.T) {
+	t.Parallel()
+
+	store := newSQLTestStore(t)
+
+	outpoint := bytes.Repeat([]byte{1}, 36)
+	_, err := store.GetChannelOpeningState(outpoint)
+	require.ErrorIs(t, err, ErrChannelNotFound)
+
+	require.NoError(t, store.SaveChannelOpeningState(outpoint, []byte{2}))
+	state, err := store.GetChannelOpeningState(outpoint)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, state)
+
+	require.NoError(t, store.DeleteChannelOpeningState(outpoint))
+	_, err = store.GetChannelOpeningState(outpoint)
+	require.ErrorIs(t, err, ErrChannelNotFound)
+
+	chanID := lnwire.ChannelID{3}
+	policy := &models.ForwardingPolicy{
+		MinHTLCOut:    1,
+		MaxHTLC:       2,
+		BaseFee:       3,
+		FeeRate:       4,
+		TimeLockDelta: 5,
+	}
+	require.NoError(t, store.SaveInitialForwardingPolicy(chanID, policy))
+
+	dbPolicy, err := store.GetInitialForwardingPolicy(chanID)
+	require.NoError(t, err)
+	require.Equal(t, policy, dbPolicy)
+
+	require.NoError(t, store.DeleteInitialForwardingPolicy(chanID))
+	_, err = store.GetInitialForwardingPolicy(chanID)
+	require.ErrorIs(t, err, ErrChannelNotFound)
+}
+
+// TestSQLStoreShutdownInfo asserts that the shutdown info and the data loss
+// commit point of a channel can be stored.
+func TestSQLStoreShutdownInfo(t *testing.T) {
+	t.Parallel()
+
+	store := newSQLTestStore(t)
+	channel := createSQLTestChannel(t, store)
+
+	info, err := channel.ShutdownInfo()
+	require.ErrorIs(t, err, ErrNoShutdownInfo)
+	require.True(t, info.IsNone())
+
+	shutdownInfo := NewShutdownInfo(
+		lnwire.DeliveryAddress{1, 2, 3}, true,
+	)
+	require.NoError(t, channel.MarkShutdownSent(shutdownInfo))
+
+	info, err = channel.ShutdownInfo()
+	require.NoError(t, err)
+	require.Equal(t, fn.Some(*shutdownInfo), info)
+
+	_, err = channel.DataLossCommitPoint()
+	require.ErrorIs(t, err, ErrNoCommitPoint)
+
+	require.NoError(t, channel.MarkDataLoss(pubKey))
+	require.True(t, channel.HasChanStatus(ChanStatusLocalDataLoss))
+
+	commitPoint, err := channel.DataLossCommitPoint()
+	require.NoError(t, err)
+	require.True(t, pubKey.IsEqual(commitPoint))
+}
+
+// TestSQLStoreDynCommitment asserts that dynamic commitments applied to a
+// channel are persisted along with the new channel parameters.
+func TestSQLStoreDynCommitment(t *testing.T) {
+	t.Parallel()
+
+	store := newSQLTestStore(t)
+	channel := createSQLTestChannel(t, store)
+
+	params := channel.ChannelParams()
+	params.ChanType |= AnchorOutputsBit | ZeroHtlcTxFeeBit
+	params.LocalParams.CsvDelay++
+	require.NoError(t, channel.ApplyDynCommitment(params, []byte{1, 2}))
+
+	dbChannel, err := store.FetchChannel(channel.FundingOutpoint)
+	require.NoError(t, err)
+	assertSQLChannelEqual(t, channel, dbChannel)
+	require.Equal(t, params, dbChannel.ChannelParams())
+	require.Len(t, dbChannel.DynCommitments, 1)
+}
+
+// TestSQLStoreSplice asserts that pending splices and the funding output of a
+// locked splice are persisted.
+func TestSQLStoreSplice(t *testing.T) {
+	t.Parallel()
+
+	store := newSQLTestStore(t)
+	channel := createSQLTestChannel(t, store)
+
+	splice := testSplice(channel)
+	require.NoError(t, channel.PutPendingSplice(splice))
+
+	dbChannel, err := store.FetchChannel(channel.FundingOutpoint)
+	require.NoError(t, err)
+	assertSQLChannelEqual(t, channel, dbChannel)
+
+	require.NoError(t, channel.LockSplice())
+
+	dbChannel, err = store.FetchChannel(channel.FundingOutpoint)
+	require.NoError(t, err)
+	assertSQLChannelEqual(t, channel, dbChannel)
+	require.Equal(t, splice.FundingOutpoint, dbChannel.FundingTxOutpoint())
+
+	// A locked splice can't be aborted anymore.
+	err = channel.AbortPendingSplice()
+	require.ErrorIs(t, err, ErrSpliceInProgress)
+}
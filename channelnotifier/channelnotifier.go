@@ -78,6 +78,13 @@ type ChannelUpdateEvent struct {
 	Channel *chanstate.OpenChannel
 }
 
+// ChannelParamsUpdateEvent represents a new event where the channel type or
+// parameters of an open channel were changed by a dynamic commitment.
+type ChannelParamsUpdateEvent struct {
+	// Channel is the channel that has been updated.
+	Channel *chanstate.OpenChannel
+}
+
 // FullyResolvedChannelEvent represents a new event where a channel becomes
 // fully resolved.
 type FullyResolvedChannelEvent struct {
@@ -267,6 +274,17 @@ func (c *ChannelNotifier) NotifyInactiveChannelEvent(chanPoint wire.OutPoint) {
 	}
 }
 
+// NotifyChannelParamsUpdateEvent notifies subscribers that the channel type or
+// parameters of an open channel were changed.
+func (c *ChannelNotifier) NotifyChannelParamsUpdateEvent(
+	channel *chanstate.OpenChannel) {
+
+	event := ChannelParamsUpdateEvent{Channel: channel}
+	if err := c.ntfnServer.SendUpdate(event); err != nil {
+		log.Warnf("Unable to send channel params update: %v", err)
+	}
+}
+
 // NotifyChannelUpdateEvent notifies subscribers that a channel's state has been
 // updated.
 func (c *ChannelNotifier) NotifyChannelUpdateEvent(
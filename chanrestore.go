@@ -1,6 +1,7 @@
 package flnd
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"net"
@@ -323,6 +324,48 @@ func (c *chanDBRestorer) RestoreChansFromSingles(backups ...chanbackup.Single) e
 // chanbackup.ChannelRestorer.
 var _ chanbackup.ChannelRestorer = (*chanDBRestorer)(nil)
 
+// restorePeerStorageBackups restores the channels of the static channel
+// backups a peer returned to us through peer storage. Channels that are
+// already open were either part of the backups supplied when restoring from
+// seed or returned by another peer, and are skipped by chanbackup.Recover.
+// Channels we already closed are skipped as well, as the backup a peer holds
+// may be older than our latest one.
+func (s *server) restorePeerStorageBackups(backups []chanbackup.Single) error {
+	unknownBackups := make([]chanbackup.Single, 0, len(backups))
+	for _, backup := range backups {
+		_, err := s.chanStateDB.FetchClosedChannel(
+			&backup.FundingOutpoint,
+		)
+		switch {
+		case err == nil:
+			ltndLog.Debugf("Skipping restore of closed "+
+				"ChannelPoint(%v) from peer storage",
+				backup.FundingOutpoint)
+
+			continue
+
+		case !errors.Is(err, channeldb.ErrClosedChannelNotFound):
+			return err
+		}
+
+		unknownBackups = append(unknownBackups, backup)
+	}
+
+	chanRestorer := &chanDBRestorer{
+		db:         s.chanStateDB,
+		secretKeys: s.cc.KeyRing,
+		chainArb:   s.chainArb,
+	}
+	numRestored, err := chanbackup.Recover(unknownBackups, chanRestorer, s)
+	if err != nil {
+		return err
+	}
+
+	ltndLog.Infof("Restored %d channels from peer storage", numRestored)
+
+	return nil
+}
+
 // ConnectPeer attempts to connect to the target node at the set of available
 // addresses. Once this method returns with a non-nil error, the connector
 // should attempt to persistently connect to the target peer in the background
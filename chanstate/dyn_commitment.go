@@ -0,0 +1,229 @@
+package chanstate
+
+import (
+	"errors"
+
+	"github.com/flokiorg/flnd/lntypes"
+)
+
+// ErrDynCommitmentInProgress is returned when a new dynamic commitment is
+// applied to a channel before both commitments have switched over to the
+// parameters of the previous one.
+var ErrDynCommitmentInProgress = errors.New("dynamic commitment in progress")
+
+// ChannelParams is the set of channel parameters that can be renegotiated over
+// the lifetime of a channel by the dynamic commitments protocol.
+type ChannelParams struct {
+	// ChanType is the channel type that both commitments will use.
+	ChanType ChannelType
+
+	// LocalBounds are the state space bounds that apply to the local
+	// party.
+	LocalBounds ChannelStateBounds
+
+	// RemoteBounds are the state space bounds that apply to the remote
+	// party.
+	RemoteBounds ChannelStateBounds
+
+	// LocalParams are the parameters that render the local party's
+	// outputs on both commitment transactions.
+	LocalParams CommitmentParams
+
+	// RemoteParams are the parameters that render the remote party's
+	// outputs on both commitment transactions.
+	RemoteParams CommitmentParams
+}
+
+// DynCommitment records a dynamic commitment that was executed on a channel.
+// Since the commitment type and the commitment parameters change at a known
+// commitment height for each party, the previous values are kept around so
+// that revoked and unrevoked commitments broadcast before the switch can still
+// be resolved on chain.
+type DynCommitment struct {
+	// LocalCommitHeight is the first local commitment height that uses
+	// the new channel parameters.
+	LocalCommitHeight uint64
+
+	// RemoteCommitHeight is the first remote commitment height that uses
+	// the new channel parameters.
+	RemoteCommitHeight uint64
+
+	// PrevChanType is the channel type used by the commitments below the
+	// switch heights.
+	PrevChanType ChannelType
+
+	// PrevLocalParams are the local commitment parameters used by the
+	// commitments below the switch heights.
+	PrevLocalParams CommitmentParams
+
+	// PrevRemoteParams are the remote commitment parameters used by the
+	// commitments below the switch heights.
+	PrevRemoteParams CommitmentParams
+
+	// Msg is the serialized DynCommit message that executed the update.
+	// It is retransmitted on reestablish if the remote party did not
+	// process it before disconnecting.
+	Msg []byte
+}
+
+// switchHeight returns the first commitment height of the given party that
+// uses the parameters introduced by this dynamic commitment.
+func (d *DynCommitment) switchHeight(party lntypes.ChannelParty) uint64 {
+	if party.IsLocal() {
+		return d.LocalCommitHeight
+	}
+
+	return d.RemoteCommitHeight
+}
+
+// ChannelParams returns the renegotiable parameters currently in effect for
+// the channel.
+func (c *OpenChannel) ChannelParams() ChannelParams {
+	c.RLock()
+	defer c.RUnlock()
+
+	return ChannelParams{
+		ChanType:     c.ChanType,
+		LocalBounds:  c.LocalChanCfg.ChannelStateBounds,
+		RemoteBounds: c.RemoteChanCfg.ChannelStateBounds,
+		LocalParams:  c.LocalChanCfg.CommitmentParams,
+		RemoteParams: c.RemoteChanCfg.CommitmentParams,
+	}
+}
+
+// LastDynCommitment returns the most recent dynamic commitment executed on the
+// channel, if any.
+func (c *OpenChannel) LastDynCommitment() (*DynCommitment, bool) {
+	c.RLock()
+	defer c.RUnlock()
+
+	if len(c.DynCommitments) == 0 {
+		return nil, false
+	}
+
+	last := c.DynCommitments[len(c.DynCommitments)-1]
+
+	return &last, true
+}
+
+// ApplyDynCommitment switches the channel over to the passed parameters and
+// persists the change along with the serialized DynCommit message that
+// executed it. The next local and remote commitments will be the first ones to
+// use the new parameters.
+func (c *OpenChannel) ApplyDynCommitment(params ChannelParams,
+	msg []byte) error {
+
+	c.Lock()
+	defer c.Unlock()
+
+	dyn := DynCommitment{
+		LocalCommitHeight:  c.LocalCommitment.CommitHeight + 1,
+		RemoteCommitHeight: c.RemoteCommitment.CommitHeight + 1,
+		PrevChanType:       c.ChanType,
+		PrevLocalParams:    c.LocalChanCfg.CommitmentParams,
+		PrevRemoteParams:   c.RemoteChanCfg.CommitmentParams,
+		Msg:                msg,
+	}
+
+	// A new dynamic commitment may only be applied once both commitments
+	// have caught up with the previous one, otherwise the history of the
+	// parameters would become ambiguous.
+	if n := len(c.DynCommitments); n > 0 {
+		last := c.DynCommitments[n-1]
+		if dyn.LocalCommitHeight <= last.LocalCommitHeight ||
+			dyn.RemoteCommitHeight <= last.RemoteCommitHeight {
+
+			return ErrDynCommitmentInProgress
+		}
+	}
+
+	if err := c.Db.ApplyChannelDynCommitment(c, params, dyn); err != nil {
+		return err
+	}
+
+	c.ApplyDynCommitmentForStore(params, dyn)
+
+	return nil
+}
+
+// ApplyDynCommitmentForStore applies the passed parameters to the in-memory
+// channel and records the dynamic commitment in its history. Store
+// implementations use this to mutate their disk copy of the channel.
+func (c *OpenChannel) ApplyDynCommitmentForStore(params ChannelParams,
+	dyn DynCommitment) {
+
+	c.ChanType = params.ChanType
+	c.LocalChanCfg.ChannelStateBounds = params.LocalBounds
+	c.RemoteChanCfg.ChannelStateBounds = params.RemoteBounds
+	c.LocalChanCfg.CommitmentParams = params.LocalParams
+	c.RemoteChanCfg.CommitmentParams = params.RemoteParams
+	c.DynCommitments = append(c.DynCommitments, dyn)
+}
+
+// DynCommitmentPending returns true if a dynamic commitment was applied to the
+// channel but at least one of the current commitments still uses the previous
+// parameters.
+func (c *OpenChannel) DynCommitmentPending() bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	if len(c.DynCommitments) == 0 {
+		return false
+	}
+
+	last := c.DynCommitments[len(c.DynCommitments)-1]
+
+	return c.LocalCommitment.CommitHeight < last.LocalCommitHeight ||
+		c.RemoteCommitment.CommitHeight < last.RemoteCommitHeight
+}
+
+// CommitTypeAt returns the channel type used by the commitment of the given
+// party at the given height.
+func (c *OpenChannel) CommitTypeAt(party lntypes.ChannelParty,
+	height uint64) ChannelType {
+
+	c.RLock()
+	defer c.RUnlock()
+
+	chanType := c.ChanType
+	for i := len(c.DynCommitments) - 1; i >= 0; i-- {
+		dyn := c.DynCommitments[i]
+		if height >= dyn.switchHeight(party) {
+			break
+		}
+
+		chanType = dyn.PrevChanType
+	}
+
+	return chanType
+}
+
+// ParamsAtHeight returns a copy of the channel whose channel type and
+// commitment parameters are the ones that were used to construct the
+// commitment of the given party at the given height. If the channel never
+// executed a dynamic commitment, the channel itself is returned.
+func (c *OpenChannel) ParamsAtHeight(party lntypes.ChannelParty,
+	height uint64) *OpenChannel {
+
+	c.RLock()
+	numDyn := len(c.DynCommitments)
+	c.RUnlock()
+
+	if numDyn == 0 {
+		return c
+	}
+
+	view := c.Copy()
+	for i := len(view.DynCommitments) - 1; i >= 0; i-- {
+		dyn := view.DynCommitments[i]
+		if height >= dyn.switchHeight(party) {
+			break
+		}
+
+		view.ChanType = dyn.PrevChanType
+		view.LocalChanCfg.CommitmentParams = dyn.PrevLocalParams
+		view.RemoteChanCfg.CommitmentParams = dyn.PrevRemoteParams
+	}
+
+	return view
+}
@@ -50,6 +50,10 @@ type Store interface { //nolint:interfacebloat
 	// channel records.
 	OpenChannelFwdPkgStore
 
+	// GlobalFwdPkgStore owns access to the forwarding packages of any
+	// channel by short channel ID.
+	GlobalFwdPkgStore
+
 	// ClosedChannelStore owns closed-channel summaries and lifecycle
 	// mutations.
 	ClosedChannelStore
@@ -166,6 +170,19 @@ type OpenChannelLifecycleStore interface {
 	// MarkChannelScidAliasNegotiated marks that the scid-alias feature
 	// bit was negotiated during the lifetime of the channel.
 	MarkChannelScidAliasNegotiated(channel *OpenChannel) error
+
+	// ApplyChannelDynCommitment switches the channel over to the passed
+	// parameters and appends the dynamic commitment to its history.
+	ApplyChannelDynCommitment(channel *OpenChannel, params ChannelParams,
+		dyn DynCommitment) error
+
+	// PutChannelSplice replaces the pending splice of the channel with the
+	// passed one, removing it if none is passed.
+	PutChannelSplice(channel *OpenChannel, splice fn.Option[Splice]) error
+
+	// LockChannelSplice switches the channel over to the funding output
+	// of its pending splice.
+	LockChannelSplice(channel *OpenChannel) error
 }
 
 // OpenChannelStatusStore owns persisted status flags for open channel records.
@@ -320,6 +337,20 @@ type OpenChannelFwdPkgStore interface {
 	RemoveFwdPkgs(channel *OpenChannel, heights ...uint64) error
 }
 
+// GlobalFwdPkgStore gives access to the forwarding packages of arbitrary
+// channels. This is used by the switch to reforward and ack the settles and
+// fails of channels it does not own a link for.
+type GlobalFwdPkgStore interface {
+	// LoadChannelFwdPkgs loads all forwarding packages owned by the
+	// channel with the given short channel ID.
+	LoadChannelFwdPkgs(source lnwire.ShortChannelID) ([]*FwdPkg, error)
+
+	// AckSettleFailRefs marks the referenced settles or fails as delivered
+	// to the incoming link. References to forwarding packages that no
+	// longer exist are ignored.
+	AckSettleFailRefs(settleFailRefs ...SettleFailRef) error
+}
+
 // ClosedChannelStore owns closed-channel summaries and lifecycle mutations.
 type ClosedChannelStore interface {
 	// FetchClosedChannels attempts to fetch all closed channels from the
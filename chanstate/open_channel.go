@@ -222,6 +222,20 @@ type OpenChannel struct {
 	// immutable.
 	CustomBlob fn.Option[tlv.Blob]
 
+	// DynCommitments is the ordered history of the dynamic commitments
+	// executed on this channel. The last entry records the parameters
+	// that were replaced by the current ones.
+	DynCommitments []DynCommitment
+
+	// SpliceOutpoint is the funding output created by the last splice
+	// that was locked. FundingOutpoint keeps identifying the channel after
+	// a splice, so this is only set if the channel was ever spliced.
+	SpliceOutpoint fn.Option[wire.OutPoint]
+
+	// PendingSplice is the splice whose transaction has been signed, but
+	// that hasn't yet been locked by both parties.
+	PendingSplice fn.Option[Splice]
+
 	// Db persists channel state through the Store contract. This field
 	// intentionally keeps the existing name while callers still construct
 	// channels through the channeldb compatibility alias. The store
@@ -687,6 +701,29 @@ func (c *OpenChannel) ChanSyncMsg() (*lnwire.ChannelReestablish, error) {
 		}
 	}
 
+	// If the channel executed any dynamic commitments, then we'll let the
+	// remote party know how many so they can retransmit the last DynCommit
+	// if we never processed it.
+	var dynHeight fn.Option[lnwire.DynHeight]
+	if len(c.DynCommitments) > 0 {
+		dynHeight = fn.Some(lnwire.DynHeight(len(c.DynCommitments)))
+	}
+
+	// If we signed a splice, but never received the remote party's
+	// TxSignatures for it, then we'll ask them to retransmit them.
+	var nextFundingTxid tlv.OptionalRecordT[tlv.TlvType0, [32]byte]
+	c.PendingSplice.WhenSome(func(splice Splice) {
+		if splice.RemoteSigned {
+			return
+		}
+
+		nextFundingTxid = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType0, [32]byte](
+				splice.FundingOutpoint.Hash,
+			),
+		)
+	})
+
 	return &lnwire.ChannelReestablish{
 		ChanID: lnwire.NewChanIDFromOutPoint(
 			c.FundingOutpoint,
@@ -699,6 +736,9 @@ func (c *OpenChannel) ChanSyncMsg() (*lnwire.ChannelReestablish, error) {
 		),
 		LocalNonce:  nextTaprootNonce,
 		LocalNonces: nextLocalNonces,
+		DynHeight:   dynHeight,
+
+		NextFundingTxid: nextFundingTxid,
 	}, nil
 }
 
@@ -1191,6 +1231,18 @@ func (c *OpenChannel) Copy() *OpenChannel {
 		clone.CustomBlob = fn.Some(blobCopy)
 	})
 
+	clone.SpliceOutpoint = c.SpliceOutpoint
+	c.PendingSplice.WhenSome(func(splice Splice) {
+		clone.PendingSplice = fn.Some(splice.Copy())
+	})
+
+	if len(c.DynCommitments) > 0 {
+		clone.DynCommitments = make(
+			[]DynCommitment, len(c.DynCommitments),
+		)
+		copy(clone.DynCommitments, c.DynCommitments)
+	}
+
 	return clone
 }
 
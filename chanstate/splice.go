@@ -0,0 +1,297 @@
+package chanstate
+
+import (
+	"errors"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+var (
+	// ErrSpliceInProgress is returned when a new splice is started on a
+	// channel that still has a pending splice.
+	ErrSpliceInProgress = errors.New("splice in progress")
+
+	// ErrNoPendingSplice is returned when an operation requires a pending
+	// splice, but the channel doesn't have one.
+	ErrNoPendingSplice = errors.New("no pending splice")
+)
+
+// Splice records a splice of the channel whose transaction has been signed,
+// but that hasn't yet been locked by both parties. Until the splice
+// transaction confirms, the commitments spending the current funding output
+// remain valid, so the commitments spending the new funding output are kept
+// here until the splice is locked.
+type Splice struct {
+	// FundingTx is the splice transaction. Its witnesses are only
+	// complete once both parties exchanged their TxSignatures.
+	FundingTx *wire.MsgTx
+
+	// FundingOutpoint is the funding output created by the splice
+	// transaction.
+	FundingOutpoint wire.OutPoint
+
+	// Capacity is the capacity of the channel after the splice.
+	Capacity chainutil.Amount
+
+	// LocalBalance is the local balance of the commitments spending the
+	// new funding output.
+	LocalBalance lnwire.MilliLoki
+
+	// RemoteBalance is the remote balance of the commitments spending the
+	// new funding output.
+	RemoteBalance lnwire.MilliLoki
+
+	// LocalCommitTx is the local commitment transaction spending the new
+	// funding output.
+	LocalCommitTx *wire.MsgTx
+
+	// LocalCommitSig is the remote party's signature for LocalCommitTx.
+	LocalCommitSig []byte
+
+	// RemoteCommitTx is the remote commitment transaction spending the
+	// new funding output.
+	RemoteCommitTx *wire.MsgTx
+
+	// IsInitiator is true if we initiated the splice.
+	IsInitiator bool
+
+	// HeightHint is the best height known when the splice was signed.
+	// The splice transaction can't confirm below this height.
+	HeightHint uint32
+
+	// TxSignatures is the serialized TxSignatures message we sent for the
+	// splice transaction. It's retransmitted on reestablish if the remote
+	// party didn't process it before disconnecting.
+	TxSignatures []byte
+
+	// RemoteSigned is true once we received the remote party's
+	// TxSignatures.
+	RemoteSigned bool
+
+	// LocalLocked is true once the splice transaction confirmed and the
+	// channel switched over to the new funding output.
+	LocalLocked bool
+
+	// RemoteLocked is true once the remote party sent SpliceLocked.
+	RemoteLocked bool
+
+	// Upgrade is set if the splice moves the channel to a new channel
+	// type. The commitments of the splice already use the new type, which
+	// takes effect once the splice is locked.
+	Upgrade fn.Option[SpliceUpgrade]
+}
+
+// SpliceUpgrade describes the channel type upgrade carried out by a splice.
+type SpliceUpgrade struct {
+	// ChanType is the channel type of the commitments spending the new
+	// funding output.
+	ChanType ChannelType
+
+	// CommitFee is the fee paid by the commitments spending the new
+	// funding output.
+	CommitFee chainutil.Amount
+}
+
+// IsUpgrade returns true if the splice moves the channel to a new channel
+// type.
+func (s *Splice) IsUpgrade() bool {
+	return s.Upgrade.IsSome()
+}
+
+// Copy returns a deep copy of the splice.
+func (s *Splice) Copy() Splice {
+	clone := *s
+	if s.FundingTx != nil {
+		clone.FundingTx = s.FundingTx.Copy()
+	}
+	if s.LocalCommitTx != nil {
+		clone.LocalCommitTx = s.LocalCommitTx.Copy()
+	}
+	if s.RemoteCommitTx != nil {
+		clone.RemoteCommitTx = s.RemoteCommitTx.Copy()
+	}
+
+	clone.LocalCommitSig = append([]byte(nil), s.LocalCommitSig...)
+	clone.TxSignatures = append([]byte(nil), s.TxSignatures...)
+
+	return clone
+}
+
+// FundingTxOutpoint returns the outpoint of the output that currently funds
+// the channel. This is the funding output of the last locked splice, if the
+// channel was ever spliced, and FundingOutpoint otherwise. FundingOutpoint
+// keeps identifying the channel in either case.
+func (c *OpenChannel) FundingTxOutpoint() wire.OutPoint {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.SpliceOutpoint.UnwrapOr(c.FundingOutpoint)
+}
+
+// PendingSpliceInfo returns a copy of the pending splice of the channel, if
+// any.
+func (c *OpenChannel) PendingSpliceInfo() fn.Option[Splice] {
+	c.RLock()
+	defer c.RUnlock()
+
+	return fn.MapOption(func(s Splice) Splice {
+		return s.Copy()
+	})(c.PendingSplice)
+}
+
+// PutPendingSplice persists the passed splice as the pending splice of the
+// channel, replacing the current one if it's for the same transaction.
+func (c *OpenChannel) PutPendingSplice(splice Splice) error {
+	c.Lock()
+	defer c.Unlock()
+
+	var inProgress bool
+	c.PendingSplice.WhenSome(func(s Splice) {
+		inProgress = s.FundingOutpoint != splice.FundingOutpoint
+	})
+	if inProgress {
+		return ErrSpliceInProgress
+	}
+
+	err := c.Db.PutChannelSplice(c, fn.Some(splice))
+	if err != nil {
+		return err
+	}
+
+	c.SetPendingSpliceForStore(fn.Some(splice))
+
+	return nil
+}
+
+// AbortPendingSplice removes the pending splice of the channel. This may only
+// be done before the splice was locked.
+func (c *OpenChannel) AbortPendingSplice() error {
+	c.Lock()
+	defer c.Unlock()
+
+	splice, err := c.PendingSplice.UnwrapOrErr(ErrNoPendingSplice)
+	if err != nil {
+		return err
+	}
+	if splice.LocalLocked {
+		return ErrSpliceInProgress
+	}
+
+	err = c.Db.PutChannelSplice(c, fn.None[Splice]())
+	if err != nil {
+		return err
+	}
+
+	c.SetPendingSpliceForStore(fn.None[Splice]())
+
+	return nil
+}
+
+// LockSplice switches the channel over to the funding output of its pending
+// splice once the splice transaction confirmed. The commitments spending the
+// new funding output replace the current ones. The pending splice is kept
+// around until the remote party locked it as well.
+func (c *OpenChannel) LockSplice() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if err := c.Db.LockChannelSplice(c); err != nil {
+		return err
+	}
+
+	return c.LockSpliceForStore()
+}
+
+// MarkSpliceRemoteLocked records that the remote party locked the pending
+// splice. If we already locked it as well, the pending splice is removed.
+func (c *OpenChannel) MarkSpliceRemoteLocked() error {
+	c.Lock()
+	defer c.Unlock()
+
+	splice, err := c.PendingSplice.UnwrapOrErr(ErrNoPendingSplice)
+	if err != nil {
+		return err
+	}
+
+	splice.RemoteLocked = true
+
+	pending := fn.Some(splice)
+	if splice.LocalLocked {
+		pending = fn.None[Splice]()
+	}
+
+	if err := c.Db.PutChannelSplice(c, pending); err != nil {
+		return err
+	}
+
+	c.SetPendingSpliceForStore(pending)
+
+	return nil
+}
+
+// SetPendingSpliceForStore sets the pending splice of the in-memory channel.
+// Store implementations use this to mutate their disk copy of the channel.
+func (c *OpenChannel) SetPendingSpliceForStore(splice fn.Option[Splice]) {
+	c.PendingSplice = splice
+}
+
+// LockSpliceForStore applies the pending splice to the in-memory channel.
+// Store implementations use this to mutate their disk copy of the channel.
+func (c *OpenChannel) LockSpliceForStore() error {
+	splice, err := c.PendingSplice.UnwrapOrErr(ErrNoPendingSplice)
+	if err != nil {
+		return err
+	}
+
+	if !splice.LocalLocked {
+		c.SpliceOutpoint = fn.Some(splice.FundingOutpoint)
+		c.Capacity = splice.Capacity
+
+		c.LocalCommitment.CommitTx = splice.LocalCommitTx
+		c.LocalCommitment.CommitSig = splice.LocalCommitSig
+		c.LocalCommitment.LocalBalance = splice.LocalBalance
+		c.LocalCommitment.RemoteBalance = splice.RemoteBalance
+
+		c.RemoteCommitment.CommitTx = splice.RemoteCommitTx
+		c.RemoteCommitment.LocalBalance = splice.LocalBalance
+		c.RemoteCommitment.RemoteBalance = splice.RemoteBalance
+
+		splice.Upgrade.WhenSome(func(upgrade SpliceUpgrade) {
+			c.applySpliceUpgrade(upgrade)
+		})
+
+		splice.LocalLocked = true
+	}
+
+	// Once both parties locked the splice there's nothing left to track.
+	if splice.RemoteLocked {
+		c.PendingSplice = fn.None[Splice]()
+	} else {
+		c.PendingSplice = fn.Some(splice)
+	}
+
+	return nil
+}
+
+// applySpliceUpgrade switches the channel over to the channel type of a splice
+// upgrade. The current commitments, which were just replaced by the ones of
+// the splice, are the first ones to use the new type. Since they spend the
+// new funding output, none of the earlier commitments can be confirmed once
+// the splice is locked, but the previous type is still recorded to keep the
+// history of the channel parameters complete.
+func (c *OpenChannel) applySpliceUpgrade(upgrade SpliceUpgrade) {
+	c.DynCommitments = append(c.DynCommitments, DynCommitment{
+		LocalCommitHeight:  c.LocalCommitment.CommitHeight,
+		RemoteCommitHeight: c.RemoteCommitment.CommitHeight,
+		PrevChanType:       c.ChanType,
+		PrevLocalParams:    c.LocalChanCfg.CommitmentParams,
+		PrevRemoteParams:   c.RemoteChanCfg.CommitmentParams,
+	})
+
+	c.ChanType = upgrade.ChanType
+	c.LocalCommitment.CommitFee = upgrade.CommitFee
+	c.RemoteCommitment.CommitFee = upgrade.CommitFee
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
 
 	"github.com/flokiorg/flnd/shachain"
@@ -24,11 +25,48 @@ var (
 	// the taproot nonces. This is done via HMAC of the existing revocation
 	// root.
 	taprootRevRootKey = []byte("taproot-rev-root")
+
+	// taprootAnnRootKey is the key used to derive the root of the
+	// shachain that the nonces of the channel_announcement_2 signing
+	// session are generated from. This is done via HMAC of the existing
+	// revocation root.
+	taprootAnnRootKey = []byte("taproot-ann-root")
+)
+
+// AnnouncementNonceIndex is the shachain index of the nonce that one of the
+// local keys uses in the MuSig2 session that signs a channel_announcement_2.
+type AnnouncementNonceIndex uint64
+
+const (
+	// AnnouncementNodeNonceIndex is the index of the nonce used by the
+	// node's identity key.
+	AnnouncementNodeNonceIndex AnnouncementNonceIndex = iota
+
+	// AnnouncementFundingNonceIndex is the index of the nonce used by the
+	// node's funding key.
+	AnnouncementFundingNonceIndex
 )
 
 // DeriveMusig2Shachain derives a shachain producer for the taproot channel
 // from normal shachain revocation root.
 func DeriveMusig2Shachain(revRoot shachain.Producer) (shachain.Producer, error) { //nolint:ll
+	return deriveHmacShachain(revRoot, taprootRevRootKey)
+}
+
+// DeriveAnnouncementShachain derives a shachain producer, distinct from the
+// one of DeriveMusig2Shachain, from the normal shachain revocation root. It is
+// used to generate the nonces of the channel_announcement_2 signing session.
+func DeriveAnnouncementShachain(
+	revRoot shachain.Producer) (shachain.Producer, error) {
+
+	return deriveHmacShachain(revRoot, taprootAnnRootKey)
+}
+
+// deriveHmacShachain derives a new shachain producer from the normal shachain
+// revocation root, bound to the given HMAC key.
+func deriveHmacShachain(revRoot shachain.Producer,
+	hmacKey []byte) (shachain.Producer, error) {
+
 	// In order to obtain the revocation root hash to create the taproot
 	// revocation, we'll encode the producer into a buffer, then use that
 	// to derive the shachain root needed.
@@ -39,11 +77,10 @@ func DeriveMusig2Shachain(revRoot shachain.Producer) (shachain.Producer, error)
 
 	revRootHash := chainhash.HashH(rootHashBuf.Bytes())
 
-	// For taproot channel types, we'll also generate a distinct shachain
-	// root using the same seed information. We'll use this to generate
-	// verification nonces for the channel. We'll bind with this a simple
-	// hmac.
-	taprootRevHmac := hmac.New(sha256.New, taprootRevRootKey)
+	// We'll generate a distinct shachain root using the same seed
+	// information, bound to its purpose with a simple hmac over the given
+	// key.
+	taprootRevHmac := hmac.New(sha256.New, hmacKey)
 	if _, err := taprootRevHmac.Write(revRootHash[:]); err != nil {
 		return nil, err
 	}
@@ -77,3 +114,28 @@ func NewMusigVerificationNonce(pubKey *crypto.PublicKey, targetHeight uint64,
 
 	return musig2.GenNonces(pubKeyOpt, shaChainRand)
 }
+
+// NewAnnouncementNonce generates the nonce that the local key at the given
+// index uses in the MuSig2 session that signs the channel_announcement_2 of a
+// channel. Like the verification nonces, it is derived from a shachain so that
+// no secret nonce state has to be written to disk. The nonce is additionally
+// bound to the short channel ID being announced: should a re-org change it,
+// a fresh nonce results instead of the same one signing a second message.
+func NewAnnouncementNonce(pubKey *crypto.PublicKey,
+	index AnnouncementNonceIndex, scid uint64,
+	shaGen shachain.Producer) (*musig2.Nonces, error) {
+
+	preimage, err := shaGen.AtIndex(uint64(index))
+	if err != nil {
+		return nil, err
+	}
+
+	var scidBytes [8]byte
+	binary.BigEndian.PutUint64(scidBytes[:], scid)
+
+	return musig2.GenNonces(
+		musig2.WithPublicKey(pubKey),
+		musig2.WithCustomRand(bytes.NewBuffer(preimage[:])),
+		musig2.WithNonceAuxInput(scidBytes[:]),
+	)
+}
@@ -65,10 +65,11 @@ var setCfgCommand = cli.Command{
 		cli.StringFlag{
 			Name: "estimator",
 			Usage: "the probability estimator to use, choose " +
-				"between 'apriori' or 'bimodal' (bimodal is " +
-				"experimental)",
+				"between 'apriori', 'bimodal' (bimodal is " +
+				"experimental) or 'external'",
 		},
-		// Apriori config.
+		// Apriori config, which also configures the fallback of the
+		// external estimator.
 		cli.DurationFlag{
 			Name: "apriorihalflife",
 			Usage: "the amount of time taken to restore a node " +
@@ -111,6 +112,18 @@ var setCfgCommand = cli.Command{
 				"estimator takes into account other channels " +
 				"of a router",
 		},
+		// External config.
+		cli.DurationFlag{
+			Name: "externaltimeout",
+			Usage: "the time pathfinding waits for the " +
+				"registered estimator before falling back " +
+				"to the apriori estimator",
+		},
+		cli.DurationFlag{
+			Name: "externalcachettl",
+			Usage: "the time a probability estimated by the " +
+				"registered estimator is reused for",
+		},
 	},
 	Action: actionDecorator(setCfg),
 }
@@ -181,27 +194,7 @@ func setCfg(ctx *cli.Context) error {
 			mcCfg.Config.Model = routerrpc.
 				MissionControlConfig_APRIORI
 
-			aCfg := mcCfg.Config.GetApriori()
-			if ctx.IsSet("apriorihalflife") {
-				aCfg.HalfLifeSeconds = uint64(ctx.Duration(
-					"apriorihalflife",
-				).Seconds())
-			}
-
-			if ctx.IsSet("apriorihopprob") {
-				aCfg.HopProbability = ctx.Float64(
-					"apriorihopprob",
-				)
-			}
-
-			if ctx.IsSet("aprioriweight") {
-				aCfg.Weight = ctx.Float64("aprioriweight")
-			}
-
-			if ctx.IsSet("aprioricapacityfraction") {
-				aCfg.CapacityFraction =
-					ctx.Float64("aprioricapacityfraction")
-			}
+			setAprioriParams(ctx, mcCfg.Config.GetApriori())
 
 		case routing.BimodalEstimatorName:
 			haveValue = true
@@ -250,6 +243,66 @@ func setCfg(ctx *cli.Context) error {
 				)
 			}
 
+		case routing.ExternalEstimatorName:
+			haveValue = true
+
+			// If we switch from another estimator, initialize with
+			// default values.
+			if mcCfg.Config.Model !=
+				routerrpc.MissionControlConfig_EXTERNAL {
+
+				dCfg := routing.DefaultExternalConfig()
+				fCfg := dCfg.FallbackConfig
+				eParams := &routerrpc.ExternalParameters{
+					TimeoutMs: uint64(
+						dCfg.ExternalTimeout.
+							Milliseconds(),
+					),
+					CacheTtlSeconds: uint64(
+						dCfg.ExternalCacheTTL.Seconds(),
+					),
+					Fallback: &routerrpc.AprioriParameters{
+						HalfLifeSeconds: uint64(
+							fCfg.PenaltyHalfLife.
+								Seconds(),
+						),
+						HopProbability: fCfg.
+							AprioriHopProbability,
+						Weight: fCfg.AprioriWeight,
+						CapacityFraction: fCfg.
+							CapacityFraction,
+					},
+				}
+
+				// We make sure the correct config is set.
+				mcCfg.Config.EstimatorConfig = &routerrpc.
+					MissionControlConfig_External{
+					External: eParams,
+				}
+			}
+
+			// We update all values for the external estimator.
+			mcCfg.Config.Model = routerrpc.
+				MissionControlConfig_EXTERNAL
+
+			eCfg := mcCfg.Config.GetExternal()
+			if ctx.IsSet("externaltimeout") {
+				eCfg.TimeoutMs = uint64(ctx.Duration(
+					"externaltimeout",
+				).Milliseconds())
+			}
+
+			if ctx.IsSet("externalcachettl") {
+				eCfg.CacheTtlSeconds = uint64(ctx.Duration(
+					"externalcachettl",
+				).Seconds())
+			}
+
+			if eCfg.Fallback == nil {
+				eCfg.Fallback = &routerrpc.AprioriParameters{}
+			}
+			setAprioriParams(ctx, eCfg.Fallback)
+
 		default:
 			return fmt.Errorf("unknown estimator %v",
 				ctx.String("estimator"))
@@ -269,6 +322,28 @@ func setCfg(ctx *cli.Context) error {
 	return err
 }
 
+// setAprioriParams updates the apriori parameters with the values of the
+// apriori flags that are set.
+func setAprioriParams(ctx *cli.Context, aCfg *routerrpc.AprioriParameters) {
+	if ctx.IsSet("apriorihalflife") {
+		aCfg.HalfLifeSeconds = uint64(ctx.Duration(
+			"apriorihalflife",
+		).Seconds())
+	}
+
+	if ctx.IsSet("apriorihopprob") {
+		aCfg.HopProbability = ctx.Float64("apriorihopprob")
+	}
+
+	if ctx.IsSet("aprioriweight") {
+		aCfg.Weight = ctx.Float64("aprioriweight")
+	}
+
+	if ctx.IsSet("aprioricapacityfraction") {
+		aCfg.CapacityFraction = ctx.Float64("aprioricapacityfraction")
+	}
+}
+
 var queryMissionControlCommand = cli.Command{
 	Name:     "querymc",
 	Category: "Mission Control",
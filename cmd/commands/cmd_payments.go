@@ -2056,6 +2056,103 @@ func deleteFwdHistory(ctx *cli.Context) error {
 	return nil
 }
 
+var aggregateFwdHistoryCommand = cli.Command{
+	Name:     "aggregatefwdhistory",
+	Category: "Payments",
+	Usage: "Aggregate the forwarding history per channel, peer or " +
+		"time bucket.",
+	Description: `
+	Aggregates the HTLC switch's forwarding log over a particular time
+	range (--start_time and --end_time) and returns the number of forwarded
+	HTLCs, the forwarded volume and the fees earned for each group. The
+	start and end times are expressed in seconds since the Unix epoch or as
+	negative time ranges, e.g. "-3d", just like for fwdinghistory.
+	If --start_time isn't provided, then 24 hours ago is used. If
+	--end_time isn't provided, then the current time is used.
+
+	The events can be grouped by channel, peer, hour or day. When grouping
+	by channel or peer, the incoming statistics hold the HTLCs that were
+	received on the channel or from the peer, and the outgoing statistics
+	hold the HTLCs that were forwarded to it. Events that were recorded
+	before the peers were tracked are skipped when grouping by peer.
+	`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "start_time",
+			Usage: "the starting time for the query " +
+				`as unix timestamp or relative e.g. "-1w"`,
+		},
+		cli.StringFlag{
+			Name: "end_time",
+			Usage: "the end time for the query " +
+				`as unix timestamp or relative e.g. "-1w"`,
+		},
+		cli.StringFlag{
+			Name: "group_by",
+			Usage: "how to group the events; one of channel, " +
+				"peer, hour or day",
+			Value: "channel",
+		},
+	},
+	Action: actionDecorator(aggregateFwdHistory),
+}
+
+func aggregateFwdHistory(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	now := time.Now()
+	req := &routerrpc.AggregateForwardingHistoryRequest{
+		StartTime: uint64(now.Add(-time.Hour * 24).Unix()),
+		EndTime:   uint64(now.Unix()),
+	}
+
+	var err error
+	if ctx.IsSet("start_time") {
+		req.StartTime, err = parseTime(ctx.String("start_time"), now)
+		if err != nil {
+			return fmt.Errorf("unable to decode start_time: %w",
+				err)
+		}
+	}
+	if ctx.IsSet("end_time") {
+		req.EndTime, err = parseTime(ctx.String("end_time"), now)
+		if err != nil {
+			return fmt.Errorf("unable to decode end_time: %w", err)
+		}
+	}
+
+	switch ctx.String("group_by") {
+	case "channel":
+		req.GroupBy = routerrpc.ForwardingGroupBy_GROUP_BY_CHANNEL
+
+	case "peer":
+		req.GroupBy = routerrpc.ForwardingGroupBy_GROUP_BY_PEER
+
+	case "hour":
+		req.GroupBy = routerrpc.ForwardingGroupBy_GROUP_BY_HOUR
+
+	case "day":
+		req.GroupBy = routerrpc.ForwardingGroupBy_GROUP_BY_DAY
+
+	default:
+		return fmt.Errorf("unknown group_by %q, must be one of "+
+			"channel, peer, hour or day", ctx.String("group_by"))
+	}
+
+	resp, err := client.AggregateForwardingHistory(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
 var estimateRouteFeeCommand = cli.Command{
 	Name:     "estimateroutefee",
 	Category: "Payments",
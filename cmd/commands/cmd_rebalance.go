@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flokiorg/flnd/lnrpc/routerrpc"
+	"github.com/urfave/cli"
+)
+
+var rebalanceCommand = cli.Command{
+	Name:     "rebalance",
+	Category: "Channels",
+	Usage:    "Move funds into a channel along a circular route.",
+	Description: `
+	Moves the given amount into the incoming channel by paying ourselves
+	along a circular route that leaves through one of our other channels
+	and returns through the incoming channel. If no outgoing channels are
+	given, any of our active channels may be used.
+
+	The fee of the rebalance is limited by --max_fee, or by the configured
+	maximum fee and fee rate if it isn't set. The fees spent on rebalances
+	within a day never exceed the configured daily budget.`,
+	Flags: []cli.Flag{
+		cli.Uint64Flag{
+			Name: "incoming_chan_id",
+			Usage: "the short channel id of the channel to move " +
+				"the funds into",
+		},
+		cli.StringSliceFlag{
+			Name: "outgoing_chan_id",
+			Usage: "short channel id of a channel the funds may " +
+				"leave through; can be specified multiple " +
+				"times",
+			Value: &cli.StringSlice{},
+		},
+		cli.Int64Flag{
+			Name:  "amt",
+			Usage: "the amount in loki to move",
+		},
+		cli.Int64Flag{
+			Name: "max_fee",
+			Usage: "the maximum fee in loki to pay for the " +
+				"rebalance",
+		},
+	},
+	Action: actionDecorator(rebalanceChannel),
+}
+
+func rebalanceChannel(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	if !ctx.IsSet("incoming_chan_id") {
+		return errors.New("incoming_chan_id is required")
+	}
+	if !ctx.IsSet("amt") {
+		return errors.New("amt is required")
+	}
+
+	outgoing, err := parseChanIDs(ctx.StringSlice("outgoing_chan_id"))
+	if err != nil {
+		return fmt.Errorf("unable to decode outgoing_chan_id: %w", err)
+	}
+
+	resp, err := client.Rebalance(ctxc, &routerrpc.RebalanceRequest{
+		OutgoingChanIds: outgoing,
+		IncomingChanId:  ctx.Uint64("incoming_chan_id"),
+		AmtSat:          ctx.Int64("amt"),
+		MaxFeeSat:       ctx.Int64("max_fee"),
+	})
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var setRebalanceTargetsCommand = cli.Command{
+	Name:     "setrebalancetargets",
+	Category: "Channels",
+	Usage:    "Set the local balance a channel should be rebalanced to.",
+	Description: `
+	Sets the target of a channel, either as the fraction of its capacity
+	that should be on our side (--local_ratio) or as an absolute local
+	balance (--local_balance). If neither is given, the target of the
+	channel is removed. With --replace, the targets of all other channels
+	are removed; calling it with --replace only removes all targets.
+
+	If automatic rebalancing is active, the channels that deviate from
+	their targets are rebalanced periodically. The targets of all channels
+	are returned.`,
+	Flags: []cli.Flag{
+		cli.Uint64Flag{
+			Name:  "chan_id",
+			Usage: "the short channel id of the channel",
+		},
+		cli.Float64Flag{
+			Name: "local_ratio",
+			Usage: "the fraction of the capacity of the channel " +
+				"that should be on our side, between 0 and 1",
+		},
+		cli.Int64Flag{
+			Name: "local_balance",
+			Usage: "the local balance in loki the channel " +
+				"should have",
+		},
+		cli.BoolFlag{
+			Name:  "replace",
+			Usage: "remove the targets of all other channels",
+		},
+	},
+	Action: actionDecorator(setRebalanceTargets),
+}
+
+func setRebalanceTargets(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	req := &routerrpc.SetRebalanceTargetsRequest{
+		Replace: ctx.Bool("replace"),
+	}
+
+	switch {
+	case ctx.IsSet("chan_id"):
+		target := &routerrpc.RebalanceTarget{
+			ChanId: ctx.Uint64("chan_id"),
+		}
+
+		switch {
+		case ctx.IsSet("local_ratio") && ctx.IsSet("local_balance"):
+			return errors.New("only one of local_ratio and " +
+				"local_balance may be set")
+
+		case ctx.IsSet("local_ratio"):
+			target.Target = &routerrpc.RebalanceTarget_LocalRatio{
+				LocalRatio: ctx.Float64("local_ratio"),
+			}
+
+		case ctx.IsSet("local_balance"):
+			balance := &routerrpc.RebalanceTarget_LocalBalanceSat{
+				LocalBalanceSat: ctx.Int64("local_balance"),
+			}
+			target.Target = balance
+		}
+
+		req.Targets = []*routerrpc.RebalanceTarget{target}
+
+	case !req.Replace:
+		return errors.New("chan_id is required unless all targets " +
+			"are replaced")
+	}
+
+	resp, err := client.SetRebalanceTargets(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var listRebalancesCommand = cli.Command{
+	Name:     "listrebalances",
+	Category: "Channels",
+	Usage:    "List the rebalances that were made.",
+	Description: `
+	Lists the rebalances that were made since --start_time, together with
+	the fees spent on rebalancing within the last day and the daily budget.
+	The start time is expressed in seconds since the Unix epoch or as a
+	negative time range, e.g. "-3d". If it isn't provided, then 24 hours
+	ago is used.`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "start_time",
+			Usage: "the starting time for the query " +
+				`as unix timestamp or relative e.g. "-1w"`,
+		},
+	},
+	Action: actionDecorator(listRebalances),
+}
+
+func listRebalances(ctx *cli.Context) error {
+	ctxc := getContext()
+	conn := getClientConn(ctx, false)
+	defer conn.Close()
+
+	client := routerrpc.NewRouterClient(conn)
+
+	now := time.Now()
+	req := &routerrpc.ListRebalancesRequest{
+		StartTime: uint64(now.Add(-time.Hour * 24).Unix()),
+	}
+
+	if ctx.IsSet("start_time") {
+		var err error
+		req.StartTime, err = parseTime(ctx.String("start_time"), now)
+		if err != nil {
+			return fmt.Errorf("unable to decode start_time: %w",
+				err)
+		}
+	}
+
+	resp, err := client.ListRebalances(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/flokiorg/flnd/lnrpc"
+	"github.com/urfave/cli"
+)
+
+// spliceFeeFlags are the flags that set the fee rate of a splice transaction.
+var spliceFeeFlags = []cli.Flag{
+	cli.Int64Flag{
+		Name: "conf_target",
+		Usage: "(optional) the number of blocks that the splice " +
+			"transaction *should* confirm in, will be used for " +
+			"fee estimation",
+	},
+	cli.Uint64Flag{
+		Name: "sat_per_vbyte",
+		Usage: "(optional) a manual fee expressed in sat/vbyte that " +
+			"should be used for the splice transaction",
+	},
+}
+
+var spliceInCommand = cli.Command{
+	Name:      "splicein",
+	Category:  "Channels",
+	Usage:     "Add funds from the wallet to an open channel.",
+	ArgsUsage: "chan_point amt",
+	Description: `
+	Adds funds from the internal wallet to an open channel by splicing them
+	into its funding output. The command blocks until the splice
+	transaction was signed by both parties and published. The channel
+	can't forward payments until the splice transaction confirmed.
+
+	Channel points are encoded as: funding_txid:output_index
+	`,
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name: "chan_point",
+			Usage: "the channel to splice funds into, in the " +
+				"form of txid:output_index",
+		},
+		cli.Int64Flag{
+			Name:  "amt",
+			Usage: "the amount in satoshis to add to the channel",
+		},
+		cli.Uint64Flag{
+			Name: "min_confs",
+			Usage: "(optional) the minimum number of " +
+				"confirmations each one of your outputs used " +
+				"for the splice transaction must satisfy",
+			Value: defaultUtxoMinConf,
+		},
+	}, spliceFeeFlags...),
+	Action: actionDecorator(spliceIn),
+}
+
+func spliceIn(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	chanPoint, amt, _, err := parseSpliceArgs(ctx)
+	if err != nil {
+		return err
+	}
+
+	req := &lnrpc.SpliceInRequest{
+		ChanPoint:   chanPoint,
+		Amount:      amt,
+		TargetConf:  int32(ctx.Int64("conf_target")),
+		SatPerVbyte: ctx.Uint64("sat_per_vbyte"),
+		MinConfs:    int32(ctx.Uint64("min_confs")),
+	}
+
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.SpliceIn(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var spliceOutCommand = cli.Command{
+	Name:      "spliceout",
+	Category:  "Channels",
+	Usage:     "Remove funds from an open channel.",
+	ArgsUsage: "chan_point amt [addr]",
+	Description: `
+	Removes funds from an open channel by splicing them out of its funding
+	output. The funds are sent to the given address, or to a new address of
+	the internal wallet if none is given. The fee of the splice transaction
+	is paid from the local channel balance.
+
+	The command blocks until the splice transaction was signed by both
+	parties and published. The channel can't forward payments until the
+	splice transaction confirmed.
+
+	Channel points are encoded as: funding_txid:output_index
+	`,
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name: "chan_point",
+			Usage: "the channel to splice funds out of, in the " +
+				"form of txid:output_index",
+		},
+		cli.Int64Flag{
+			Name: "amt",
+			Usage: "the amount in satoshis to remove from the " +
+				"channel",
+		},
+		cli.StringFlag{
+			Name: "addr",
+			Usage: "(optional) the address to send the removed " +
+				"funds to",
+		},
+	}, spliceFeeFlags...),
+	Action: actionDecorator(spliceOut),
+}
+
+func spliceOut(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	chanPoint, amt, args, err := parseSpliceArgs(ctx)
+	if err != nil {
+		return err
+	}
+
+	addr := ctx.String("addr")
+	if !ctx.IsSet("addr") && args.Present() {
+		addr = args.First()
+	}
+
+	req := &lnrpc.SpliceOutRequest{
+		ChanPoint:   chanPoint,
+		Amount:      amt,
+		Addr:        addr,
+		TargetConf:  int32(ctx.Int64("conf_target")),
+		SatPerVbyte: ctx.Uint64("sat_per_vbyte"),
+	}
+
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.SpliceOut(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+// parseSpliceArgs parses the channel point and amount of a splice from the
+// flags or positional arguments of the command. The remaining positional
+// arguments are returned as well.
+func parseSpliceArgs(ctx *cli.Context) (*lnrpc.ChannelPoint, int64, cli.Args,
+	error) {
+
+	if _, err := checkNotBothSet(
+		ctx, "sat_per_vbyte", "conf_target",
+	); err != nil {
+		return nil, 0, nil, err
+	}
+
+	args := ctx.Args()
+
+	var chanPointStr string
+	switch {
+	case ctx.IsSet("chan_point"):
+		chanPointStr = ctx.String("chan_point")
+	case args.Present():
+		chanPointStr = args.First()
+		args = args.Tail()
+	default:
+		return nil, 0, nil, fmt.Errorf("chan_point argument missing")
+	}
+
+	chanPoint, err := parseChanPoint(chanPointStr)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("unable to parse chan_point: "+
+			"%w", err)
+	}
+
+	var amt int64
+	switch {
+	case ctx.IsSet("amt"):
+		amt = ctx.Int64("amt")
+	case args.Present():
+		amt, err = strconv.ParseInt(args.First(), 10, 64)
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("unable to decode "+
+				"amount: %w", err)
+		}
+		args = args.Tail()
+	default:
+		return nil, 0, nil, fmt.Errorf("amt argument missing")
+	}
+
+	return chanPoint, amt, args, nil
+}
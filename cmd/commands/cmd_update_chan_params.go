@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/flokiorg/flnd/lnrpc"
+	"github.com/urfave/cli"
+)
+
+var updateChannelParamsCommand = cli.Command{
+	Name:      "updatechanparams",
+	Category:  "Channels",
+	Usage:     "Renegotiate the parameters of an open channel.",
+	ArgsUsage: "chan_point",
+	Description: `
+	Renegotiates the parameters and the commitment type of an open channel
+	with the channel peer using the dynamic commitments protocol. Only the
+	parameters that are set are changed. The command blocks until both
+	commitments use the new parameters or the peer rejects the update.
+
+	Legacy and tweakless channels can be upgraded to the anchors commitment
+	type this way. Private channels can also be upgraded to a taproot
+	commitment type, which moves the funds to a new taproot funding output
+	with a kickoff transaction. We pay its fee at the default fee rate.
+
+	Channel points are encoded as: funding_txid:output_index
+	`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "chan_point",
+			Usage: "the channel to update, in the form of " +
+				"txid:output_index",
+		},
+		cli.Uint64Flag{
+			Name: "dust_limit_sat",
+			Usage: "(optional) the dust limit that applies to " +
+				"our outputs",
+		},
+		cli.Uint64Flag{
+			Name: "max_value_in_flight_msat",
+			Usage: "(optional) the maximum value in msat the " +
+				"peer may have in flight towards us",
+		},
+		cli.Uint64Flag{
+			Name: "min_htlc_msat",
+			Usage: "(optional) the smallest HTLC in msat the " +
+				"peer may offer us",
+		},
+		cli.Uint64Flag{
+			Name: "chan_reserve_sat",
+			Usage: "(optional) the channel reserve the peer " +
+				"has to keep on their side of the channel",
+		},
+		cli.Uint64Flag{
+			Name: "csv_delay",
+			Usage: "(optional) the number of blocks the peer's " +
+				"outputs are delayed by when they force " +
+				"close the channel",
+		},
+		cli.Uint64Flag{
+			Name: "max_accepted_htlcs",
+			Usage: "(optional) the maximum number of HTLCs the " +
+				"peer may offer us at once",
+		},
+		cli.StringFlag{
+			Name: "channel_type",
+			Usage: fmt.Sprintf("(optional) the commitment type "+
+				"to upgrade the channel to (%q, %q, %q, %q)",
+				channelTypeTweakless, channelTypeAnchors,
+				channelTypeSimpleTaproot,
+				channelTypeSimpleTaprootStaging),
+		},
+	},
+	Action: actionDecorator(updateChannelParams),
+}
+
+func updateChannelParams(ctx *cli.Context) error {
+	ctxc := getContext()
+
+	var chanPointStr string
+	switch {
+	case ctx.IsSet("chan_point"):
+		chanPointStr = ctx.String("chan_point")
+	case ctx.Args().Present():
+		chanPointStr = ctx.Args().First()
+	default:
+		return fmt.Errorf("chan_point argument missing")
+	}
+
+	chanPoint, err := parseChanPoint(chanPointStr)
+	if err != nil {
+		return fmt.Errorf("unable to parse chan_point: %w", err)
+	}
+
+	req := &lnrpc.UpdateChannelParamsRequest{
+		ChanPoint:            chanPoint,
+		DustLimitSat:         ctx.Uint64("dust_limit_sat"),
+		MaxValueInFlightMsat: ctx.Uint64("max_value_in_flight_msat"),
+		MinHtlcMsat:          ctx.Uint64("min_htlc_msat"),
+		ChanReserveSat:       ctx.Uint64("chan_reserve_sat"),
+		CsvDelay:             uint32(ctx.Uint64("csv_delay")),
+		MaxAcceptedHtlcs:     uint32(ctx.Uint64("max_accepted_htlcs")),
+	}
+
+	channelType := ctx.String("channel_type")
+	switch channelType {
+	case "":
+		break
+	case channelTypeTweakless:
+		req.CommitmentType = lnrpc.CommitmentType_STATIC_REMOTE_KEY
+	case channelTypeAnchors:
+		req.CommitmentType = lnrpc.CommitmentType_ANCHORS
+	case channelTypeSimpleTaproot:
+		req.CommitmentType = lnrpc.CommitmentType_SIMPLE_TAPROOT_FINAL
+	case channelTypeSimpleTaprootStaging:
+		req.CommitmentType = lnrpc.CommitmentType_SIMPLE_TAPROOT
+	default:
+		return fmt.Errorf("unsupported channel type %v", channelType)
+	}
+
+	client, cleanUp := getClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.UpdateChannelParams(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
@@ -496,8 +496,12 @@ func Main() {
 		verifyMessageCommand,
 		feeReportCommand,
 		updateChannelPolicyCommand,
+		updateChannelParamsCommand,
+		spliceInCommand,
+		spliceOutCommand,
 		forwardingHistoryCommand,
 		deleteFwdHistoryCommand,
+		aggregateFwdHistoryCommand,
 		exportChanBackupCommand,
 		verifyChanBackupCommand,
 		restoreChanBackupCommand,
@@ -530,6 +534,7 @@ func Main() {
 	app.Commands = append(app.Commands, wtclientCommands()...)
 	app.Commands = append(app.Commands, devCommands()...)
 	app.Commands = append(app.Commands, peersCommands()...)
+	app.Commands = append(app.Commands, offersCommands()...)
 	app.Commands = append(app.Commands, chainCommands()...)
 
 	if err := app.Run(os.Args); err != nil {
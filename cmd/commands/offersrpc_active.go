@@ -0,0 +1,258 @@
+//go:build offersrpc
+// +build offersrpc
+
+package commands
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flokiorg/flnd/bolt12"
+	"github.com/flokiorg/flnd/lnrpc/offersrpc"
+	"github.com/urfave/cli"
+)
+
+// offersCommands will return the set of commands to enable for offersrpc
+// builds.
+func offersCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:     "offer",
+			Category: "Offers",
+			Usage:    "Create, manage and decode BOLT 12 offers.",
+			Subcommands: []cli.Command{
+				createOfferCommand,
+				listOffersCommand,
+				disableOfferCommand,
+				decodeOfferCommand,
+			},
+		},
+	}
+}
+
+func getOffersClient(ctx *cli.Context) (offersrpc.OffersClient, func()) {
+	conn := getClientConn(ctx, false)
+	cleanUp := func() {
+		conn.Close()
+	}
+	return offersrpc.NewOffersClient(conn), cleanUp
+}
+
+var createOfferCommand = cli.Command{
+	Name:     "create",
+	Category: "Offers",
+	Usage:    "Create a new offer.",
+	Description: `
+	Create a new BOLT 12 offer issued under the node's key. Invoice requests
+	for the offer are answered until it is disabled or expires.
+
+	If no amount is set, the payer chooses the amount to pay. An offer with
+	an amount must have a description.`,
+	ArgsUsage: "[--description=] [--amt_msat=] [--issuer=] " +
+		"[--quantity_max=] [--expiry=]",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "description",
+			Usage: "the description of the offer",
+		},
+		cli.Uint64Flag{
+			Name: "amt_msat",
+			Usage: "the amount per item in milli-lokis, if not " +
+				"set the payer chooses the amount",
+		},
+		cli.StringFlag{
+			Name:  "issuer",
+			Usage: "an optional string identifying the issuer",
+		},
+		cli.Uint64Flag{
+			Name: "quantity_max",
+			Usage: "the maximum quantity that can be requested " +
+				"in a single invoice, if not set the offer " +
+				"is for a single item",
+		},
+		cli.DurationFlag{
+			Name: "expiry",
+			Usage: "the duration after which the offer should no " +
+				"longer be used (e.g. 24h), if not set the " +
+				"offer does not expire",
+		},
+	},
+	Action: actionDecorator(createOffer),
+}
+
+func createOffer(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getOffersClient(ctx)
+	defer cleanUp()
+
+	req := &offersrpc.CreateOfferRequest{
+		Description: ctx.String("description"),
+		AmountMsat:  ctx.Uint64("amt_msat"),
+		Issuer:      ctx.String("issuer"),
+		QuantityMax: ctx.Uint64("quantity_max"),
+	}
+
+	if ctx.IsSet("expiry") {
+		expiry := ctx.Duration("expiry")
+		if expiry <= 0 {
+			return fmt.Errorf("expiry must be positive")
+		}
+
+		req.AbsoluteExpiry = uint64(time.Now().Add(expiry).Unix())
+	}
+
+	resp, err := client.CreateOffer(ctxc, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var listOffersCommand = cli.Command{
+	Name:     "list",
+	Category: "Offers",
+	Usage:    "List the offers created by this node.",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "active_only",
+			Usage: "only list offers that have not been disabled",
+		},
+	},
+	Action: actionDecorator(listOffers),
+}
+
+func listOffers(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getOffersClient(ctx)
+	defer cleanUp()
+
+	resp, err := client.ListOffers(ctxc, &offersrpc.ListOffersRequest{
+		ActiveOnly: ctx.Bool("active_only"),
+	})
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var disableOfferCommand = cli.Command{
+	Name:     "disable",
+	Category: "Offers",
+	Usage:    "Stop answering invoice requests for an offer.",
+	Description: `
+	Disable one of the node's offers. Invoice requests for the offer are no
+	longer answered, so it can no longer be paid.`,
+	ArgsUsage: "offer_id",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "offer_id",
+			Usage: "the hex-encoded ID (32 byte) of the offer",
+		},
+	},
+	Action: actionDecorator(disableOffer),
+}
+
+func disableOffer(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getOffersClient(ctx)
+	defer cleanUp()
+
+	var offerID string
+	switch {
+	case ctx.IsSet("offer_id"):
+		offerID = ctx.String("offer_id")
+	case ctx.Args().Present():
+		offerID = ctx.Args().First()
+	default:
+		return cli.ShowCommandHelp(ctx, "disable")
+	}
+
+	id, err := hex.DecodeString(offerID)
+	if err != nil {
+		return fmt.Errorf("unable to parse offer ID: %w", err)
+	}
+
+	resp, err := client.DisableOffer(ctxc, &offersrpc.DisableOfferRequest{
+		OfferId: id,
+	})
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
+
+var decodeOfferCommand = cli.Command{
+	Name:     "decode",
+	Category: "Offers",
+	Usage:    "Decode a BOLT 12 offer or invoice request.",
+	Description: `
+	Decode a bech32 encoded BOLT 12 offer (lno...) or invoice request
+	(lnr...) and print its fields. The signature of an invoice request is
+	checked as well.`,
+	ArgsUsage: "bolt12_string",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "bolt12_string",
+			Usage: "the offer or invoice request to decode",
+		},
+	},
+	Action: actionDecorator(decodeOffer),
+}
+
+func decodeOffer(ctx *cli.Context) error {
+	ctxc := getContext()
+	client, cleanUp := getOffersClient(ctx)
+	defer cleanUp()
+
+	var encoded string
+	switch {
+	case ctx.IsSet("bolt12_string"):
+		encoded = ctx.String("bolt12_string")
+	case ctx.Args().Present():
+		encoded = ctx.Args().First()
+	default:
+		return cli.ShowCommandHelp(ctx, "decode")
+	}
+
+	// Invoice requests share the encoding of offers and are told apart
+	// by their human-readable prefix.
+	if strings.HasPrefix(
+		strings.ToLower(encoded), bolt12.InvoiceRequestHRP+"1",
+	) {
+
+		resp, err := client.DecodeInvoiceRequest(
+			ctxc, &offersrpc.DecodeInvoiceRequestRequest{
+				InvoiceRequest: encoded,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		printRespJSON(resp)
+
+		return nil
+	}
+
+	resp, err := client.DecodeOffer(ctxc, &offersrpc.DecodeOfferRequest{
+		Offer: encoded,
+	})
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+
+	return nil
+}
@@ -14,5 +14,8 @@ func routerCommands() []cli.Command {
 		getCfgCommand,
 		setCfgCommand,
 		updateChanStatusCommand,
+		rebalanceCommand,
+		setRebalanceTargetsCommand,
+		listRebalancesCommand,
 	}
 }
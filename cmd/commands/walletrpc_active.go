@@ -318,10 +318,40 @@ var bumpFeeCommand = cli.Command{
 	required. When the deadline is reached, ALL the budget will be spent as
 	fee.`,
 		},
+		feeFunctionFlag,
 	},
 	Action: actionDecorator(bumpFee),
 }
 
+// feeFunctionFlag is the flag used to select the sweeper's fee function when
+// bumping the fee of an input.
+var feeFunctionFlag = cli.StringFlag{
+	Name: "fee_function",
+	Usage: `
+	The fee function the sweeper will use to raise the fee rate towards the
+	deadline, one of linear, exponential, cubic, step or mempool. If not
+	set, for new inputs, the linear fee function will be used; for existing
+	inputs, their current fee functions will be retained.`,
+}
+
+// parseFeeFunction parses the fee function flag into its RPC type.
+func parseFeeFunction(ctx *cli.Context) (walletrpc.FeeFunctionType, error) {
+	if !ctx.IsSet(feeFunctionFlag.Name) {
+		return walletrpc.FeeFunctionType_FEE_FUNCTION_TYPE_UNSPECIFIED,
+			nil
+	}
+
+	name := ctx.String(feeFunctionFlag.Name)
+	enumName := "FEE_FUNCTION_TYPE_" + strings.ToUpper(name)
+
+	feeFunction, ok := walletrpc.FeeFunctionType_value[enumName]
+	if !ok || feeFunction == 0 {
+		return 0, fmt.Errorf("invalid fee function: %s", name)
+	}
+
+	return walletrpc.FeeFunctionType(feeFunction), nil
+}
+
 func bumpFee(ctx *cli.Context) error {
 	ctxc := getContext()
 
@@ -354,6 +384,11 @@ func bumpFee(ctx *cli.Context) error {
 		immediate = true
 	}
 
+	feeFunction, err := parseFeeFunction(ctx)
+	if err != nil {
+		return err
+	}
+
 	resp, err := client.BumpFee(ctxc, &walletrpc.BumpFeeRequest{
 		Outpoint:      protoOutPoint,
 		TargetConf:    uint32(ctx.Uint64("conf_target")),
@@ -361,6 +396,7 @@ func bumpFee(ctx *cli.Context) error {
 		Budget:        ctx.Uint64("budget"),
 		SatPerVbyte:   ctx.Uint64("sat_per_vbyte"),
 		DeadlineDelta: uint32(ctx.Uint64("deadline_delta")),
+		FeeFunction:   feeFunction,
 	})
 	if err != nil {
 		return err
@@ -496,6 +532,7 @@ var bumpForceCloseFeeCommand = cli.Command{
 	the cpfp transaction of the force closed channel otherwise the fee 
 	bumping will fail.`,
 		},
+		feeFunctionFlag,
 	},
 	Action: actionDecorator(bumpForceCloseFee),
 }
@@ -532,6 +569,11 @@ func bumpForceCloseFee(ctx *cli.Context) error {
 	}
 	immediate := ctx.Bool("immediate") || ctx.Bool("force")
 
+	feeFunction, err := parseFeeFunction(ctx)
+	if err != nil {
+		return err
+	}
+
 	resp, err := walletClient.BumpForceCloseFee(
 		ctxc, &walletrpc.BumpForceCloseFeeRequest{
 			ChanPoint:       rpcChannelPoint,
@@ -540,6 +582,7 @@ func bumpForceCloseFee(ctx *cli.Context) error {
 			StartingFeerate: ctx.Uint64("sat_per_vbyte"),
 			TargetConf:      uint32(ctx.Uint64("conf_target")),
 			DeadlineDelta:   uint32(ctx.Uint64("deadline_delta")),
+			FeeFunction:     feeFunction,
 		})
 	if err != nil {
 		return err
@@ -17,6 +17,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The migrate-db command migrates the node's databases to native SQL
+	// instead of starting the daemon. It accepts the same options as the
+	// daemon, so we remove it from the arguments before parsing them.
+	migrateDB := len(os.Args) > 1 && os.Args[1] == "migrate-db"
+	if migrateDB {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	// Load the configuration, and parse any command line options. This
 	// function will also set up logging properly.
 	loadedConfig, err := lnd.LoadConfig(shutdownInterceptor)
@@ -31,6 +39,16 @@ func main() {
 		// Help was requested, exit normally.
 		os.Exit(0)
 	}
+
+	if migrateDB {
+		if err := lnd.MigrateDB(loadedConfig); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	implCfg := loadedConfig.ImplementationConfig(shutdownInterceptor)
 
 	flnStarted := make(chan struct{})
@@ -36,6 +36,7 @@ import (
 	"github.com/flokiorg/flnd/lnrpc/devrpc"
 	"github.com/flokiorg/flnd/lnrpc/invoicesrpc"
 	"github.com/flokiorg/flnd/lnrpc/neutrinorpc"
+	"github.com/flokiorg/flnd/lnrpc/offersrpc"
 	"github.com/flokiorg/flnd/lnrpc/peersrpc"
 	"github.com/flokiorg/flnd/lnrpc/routerrpc"
 	"github.com/flokiorg/flnd/lnrpc/signrpc"
@@ -44,8 +45,11 @@ import (
 	"github.com/flokiorg/flnd/lnrpc/wtclientrpc"
 	"github.com/flokiorg/flnd/lnutils"
 	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwallet/txbatcher"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/routing"
+	"github.com/flokiorg/flnd/routing/probe"
+	"github.com/flokiorg/flnd/routing/rebalance"
 	"github.com/flokiorg/flnd/signal"
 	"github.com/flokiorg/flnd/tor"
 	neutrino "github.com/flokiorg/flokicoin-neutrino"
@@ -256,6 +260,8 @@ const (
 	bitcoindBackendName = "bitcoind"
 	btcdBackendName     = "btcd"
 	neutrinoBackendName = "neutrino"
+	electrumBackendName = "electrum"
+	esploraBackendName  = "esplora"
 
 	defaultPrunedNodeMaxPeers = 4
 	defaultNeutrinoMaxPeers   = 8
@@ -438,6 +444,8 @@ type Config struct {
 	BtcdMode       *lncfg.Btcd       `group:"btcd" namespace:"btcd"`
 	FlokicoindMode *lncfg.Flokicoind `group:"bitcoind" namespace:"bitcoind"`
 	NeutrinoMode   *lncfg.Neutrino   `group:"neutrino" namespace:"neutrino"`
+	ElectrumMode   *lncfg.Electrum   `group:"electrum" namespace:"electrum"`
+	EsploraMode    *lncfg.Esplora    `group:"esplora" namespace:"esplora"`
 
 	BlockCacheSize uint64 `long:"blockcachesize" description:"The maximum capacity of the block cache"`
 
@@ -552,6 +560,8 @@ type Config struct {
 
 	DB *lncfg.DB `group:"db" namespace:"db"`
 
+	MigrateDB *lncfg.MigrateDB `group:"migratedb" namespace:"migratedb"`
+
 	Cluster *lncfg.Cluster `group:"cluster" namespace:"cluster"`
 
 	RPCMiddleware *lncfg.RPCMiddleware `group:"rpcmiddleware" namespace:"rpcmiddleware"`
@@ -562,6 +572,12 @@ type Config struct {
 
 	Htlcswitch *lncfg.Htlcswitch `group:"htlcswitch" namespace:"htlcswitch"`
 
+	LiquidityAds *lncfg.LiquidityAds `group:"liquidityads" namespace:"liquidityads"`
+
+	TxBatcher *lncfg.TxBatcher `group:"txbatcher" namespace:"txbatcher"`
+
+	ResourceManager *lncfg.ResourceManager `group:"resourcemanager" namespace:"resourcemanager"`
+
 	GRPC *GRPCConfig `group:"grpc" namespace:"grpc"`
 
 	// SubLogMgr is the root logger that all the daemon's subloggers are
@@ -680,6 +696,13 @@ func DefaultConfig() Config {
 			UserAgentVersion: neutrino.UserAgentVersion,
 			MaxPeers:         defaultNeutrinoMaxPeers,
 		},
+		ElectrumMode: &lncfg.Electrum{
+			Timeout: lncfg.DefaultElectrumTimeout,
+		},
+		EsploraMode: &lncfg.Esplora{
+			Timeout:      lncfg.DefaultEsploraTimeout,
+			PollInterval: lncfg.DefaultEsploraPollInterval,
+		},
 		BlockCacheSize:     defaultBlockCacheSize,
 		MaxPendingChannels: lncfg.DefaultMaxPendingChannels,
 		NoSeedBackup:       defaultNoSeedBackup,
@@ -696,6 +719,7 @@ func DefaultConfig() Config {
 			SignRPC:   &signrpc.Config{},
 			RouterRPC: routerrpc.DefaultConfig(),
 			PeersRPC:  &peersrpc.Config{},
+			OffersRPC: &offersrpc.Config{},
 			// #FLZ_CHANGE init
 			WalletKitRPC:        &walletrpc.Config{},
 			AutopilotRPC:        &autopilotrpc.Config{},
@@ -811,12 +835,36 @@ func DefaultConfig() Config {
 				PolicyIncreaseMultiplier: lncfg.DefaultBlindedPathPolicyIncreaseMultiplier,
 				PolicyDecreaseMultiplier: lncfg.DefaultBlindedPathPolicyDecreaseMultiplier,
 			},
+			Trampoline: lncfg.Trampoline{
+				BaseFee:       lncfg.DefaultTrampolineBaseFee,
+				FeeRate:       lncfg.DefaultTrampolineFeeRate,
+				TimeLockDelta: lncfg.DefaultTrampolineTimeLockDelta,
+			},
+			Rebalance: lncfg.Rebalance{
+				Interval:    rebalance.DefaultInterval,
+				MaxFee:      uint64(rebalance.DefaultMaxFee),
+				MaxFeeRate:  rebalance.DefaultMaxFeeRate,
+				DailyBudget: uint64(rebalance.DefaultDailyBudget),
+				MinAmount:   uint64(rebalance.DefaultMinAmount),
+				MaxAmount:   uint64(rebalance.DefaultMaxAmount),
+				Tolerance:   rebalance.DefaultTolerance,
+				MaxAttempts: rebalance.DefaultMaxAttempts,
+			},
+			Probe: lncfg.Probe{
+				Interval:          probe.DefaultInterval,
+				CentralNodes:      probe.DefaultCentralNodes,
+				CentralityRefresh: probe.DefaultCentralityRefresh,
+				MinAmount:         uint64(probe.DefaultMinAmount),
+				MaxAmount:         uint64(probe.DefaultMaxAmount),
+				InFlightBudget:    uint64(probe.DefaultInFlightBudget),
+			},
 		},
 		MaxOutgoingCltvExpiry:     htlcswitch.DefaultMaxOutgoingCltvExpiry,
 		MaxChannelFeeAllocation:   htlcswitch.DefaultMaxLinkFeeAllocation,
 		MaxCommitFeeRateAnchors:   lnwallet.DefaultAnchorsCommitMaxFeeRateSatPerVByte,
 		LogRotator:                build.NewRotatingLogWriter(),
 		DB:                        lncfg.DefaultDB(),
+		MigrateDB:                 lncfg.DefaultMigrateDB(),
 		Cluster:                   lncfg.DefaultCluster(),
 		RPCMiddleware:             lncfg.DefaultRPCMiddleware(),
 		ActiveNetParams:           chainreg.FlokicoinTestNetParams,
@@ -833,6 +881,18 @@ func DefaultConfig() Config {
 			MailboxDeliveryTimeout: htlcswitch.DefaultMailboxDeliveryTimeout,
 			QuiescenceTimeout:      lncfg.DefaultQuiescenceTimeout,
 		},
+		LiquidityAds: &lncfg.LiquidityAds{},
+		TxBatcher: &lncfg.TxBatcher{
+			Interval:    txbatcher.DefaultBatchInterval,
+			UrgentDelta: txbatcher.DefaultUrgentDelta,
+		},
+		ResourceManager: &lncfg.ResourceManager{
+			RevenueWindow:           htlcswitch.DefaultRevenueWindow,
+			ReputationMultiplier:    htlcswitch.DefaultReputationMultiplier,
+			ResolutionPeriod:        htlcswitch.DefaultResolutionPeriod,
+			GeneralBucketPercent:    htlcswitch.DefaultGeneralBucketPercent,
+			CongestionBucketPercent: htlcswitch.DefaultCongestionBucketPercent,
+		},
 		GRPC: &GRPCConfig{
 			ServerPingTime:    defaultGrpcServerPingTime,
 			ServerPingTimeout: defaultGrpcServerPingTimeout,
@@ -1178,6 +1238,19 @@ func ValidateConfig(cfg Config, interceptor signal.Interceptor, fileParser,
 			"if the watchtower client is active")
 	}
 
+	// Inbound liquidity can only be sold in dual-funded channels that
+	// enforce the lease in their scripts.
+	if cfg.LiquidityAds.Active && !cfg.ProtocolOptions.DualFunding {
+		return nil, mkErr("liquidity ads require dual funding to be " +
+			"enabled")
+	}
+	if cfg.LiquidityAds.Active &&
+		cfg.ProtocolOptions.NoScriptEnforcementLease() {
+
+		return nil, mkErr("liquidity ads require script enforced " +
+			"lease commitments")
+	}
+
 	// Ensure a valid max channel fee allocation was set.
 	if cfg.MaxChannelFeeAllocation <= 0 || cfg.MaxChannelFeeAllocation > 1 {
 		return nil, mkErr("invalid max channel fee allocation: %v, "+
@@ -1398,13 +1471,35 @@ func ValidateConfig(cfg Config, interceptor signal.Interceptor, fileParser,
 	case neutrinoBackendName:
 		// No need to get RPC parameters.
 
+	case electrumBackendName:
+		if cfg.ElectrumMode.Server == "" {
+			return nil, mkErr("electrum.server must be set when " +
+				"using the electrum backend")
+		}
+		if cfg.ElectrumMode.Timeout <= 0 {
+			return nil, mkErr("electrum.timeout must be positive")
+		}
+
+	case esploraBackendName:
+		if cfg.EsploraMode.URL == "" {
+			return nil, mkErr("esplora.url must be set when using " +
+				"the esplora backend")
+		}
+		if cfg.EsploraMode.Timeout <= 0 {
+			return nil, mkErr("esplora.timeout must be positive")
+		}
+		if cfg.EsploraMode.PollInterval <= 0 {
+			return nil, mkErr("esplora.pollinterval must be " +
+				"positive")
+		}
+
 	case "nochainbackend":
 		// Nothing to configure, we're running without any chain
 		// backend whatsoever (pure signing mode).
 
 	default:
-		str := "only btcd, bitcoind, and neutrino mode " +
-			"supported for bitcoin at this time"
+		str := "only btcd, bitcoind, neutrino, electrum, and " +
+			"esplora mode supported for bitcoin at this time"
 
 		return nil, mkErr(str)
 	}
@@ -1859,6 +1954,9 @@ func ValidateConfig(cfg Config, interceptor signal.Interceptor, fileParser,
 		cfg.RemoteSigner,
 		cfg.Sweeper,
 		cfg.Htlcswitch,
+		cfg.LiquidityAds,
+		cfg.TxBatcher,
+		cfg.ResourceManager,
 		cfg.Invoices,
 		cfg.Routing,
 		cfg.Pprof,
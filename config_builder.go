@@ -21,6 +21,7 @@ import (
 	"github.com/flokiorg/flnd/chainntnfs"
 	"github.com/flokiorg/flnd/chainreg"
 	"github.com/flokiorg/flnd/channeldb"
+	"github.com/flokiorg/flnd/chanstate"
 	"github.com/flokiorg/flnd/clock"
 	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/funding"
@@ -77,6 +78,30 @@ const (
 	// graphMigration is the version number for the graph migration
 	// that migrates the KV graph to the native SQL schema.
 	graphMigration = 10
+
+	// paymentsMigration is the version number for the payments migration
+	// that migrates the KV payments to the native SQL schema.
+	paymentsMigration = 12
+
+	// chanStateMigration is the version number for the channel state
+	// migration that migrates the KV channel state to the native SQL
+	// schema.
+	chanStateMigration = 14
+
+	// fwdLogMigration is the version number for the forwarding log
+	// migration that migrates the KV forwarding log to the native SQL
+	// schema.
+	fwdLogMigration = 16
+
+	// wtClientMigration is the version number for the watchtower client
+	// migration that migrates the KV watchtower client DB to the native
+	// SQL schema.
+	wtClientMigration = 18
+
+	// towerDBMigration is the version number for the watchtower server
+	// migration that migrates the KV watchtower server DB to the native
+	// SQL schema.
+	towerDBMigration = 19
 )
 
 // GrpcRegistrar is an interface that must be satisfied by an external subserver
@@ -623,8 +648,10 @@ func (d *DefaultWalletImpl) BuildWalletConfig(ctx context.Context,
 		NeutrinoMode:                d.cfg.NeutrinoMode,
 		FlokicoindMode:              d.cfg.FlokicoindMode,
 		BtcdMode:                    d.cfg.BtcdMode,
+		ElectrumMode:                d.cfg.ElectrumMode,
+		EsploraMode:                 d.cfg.EsploraMode,
 		HeightHintDB:                dbs.HeightHintDB,
-		ChanStateDB:                 dbs.ChanStateDB.ChannelStateDB(),
+		ChanStateDB:                 dbs.ChanStateStore,
 		NeutrinoCS:                  neutrinoCS,
 		AuxLeafStore:                aux.AuxLeafStore,
 		AuxSigner:                   aux.AuxSigner,
@@ -772,6 +799,12 @@ func (d *DefaultWalletImpl) BuildChainControl(
 		walletController.InternalWallet(), walletConfig.CoinType,
 	)
 
+	// Unless the chain backend provides its own way to query the
+	// blockchain, we'll use the wallet's chain source for it.
+	chainIO := partialChainControl.BackendChainIO.UnwrapOr(
+		walletController,
+	)
+
 	// Create, and start the lnwallet, which handles the core payment
 	// channel logic, and exposes control via proxy state machines.
 	lnWalletConfig := lnwallet.Config{
@@ -781,7 +814,7 @@ func (d *DefaultWalletImpl) BuildChainControl(
 		Signer:                walletController,
 		FeeEstimator:          partialChainControl.FeeEstimator,
 		SecretKeyRing:         keyRing,
-		ChainIO:               walletController,
+		ChainIO:               chainIO,
 		NetParams:             *walletConfig.NetParams,
 		CoinSelectionStrategy: walletConfig.CoinSelectionStrategy,
 		AuxLeafStore:          partialChainControl.Cfg.AuxLeafStore,
@@ -892,6 +925,12 @@ func (d *RPCSignerWalletImpl) BuildChainControl(
 		return nil, nil, err
 	}
 
+	// Unless the chain backend provides its own way to query the
+	// blockchain, we'll use the wallet's chain source for it.
+	chainIO := partialChainControl.BackendChainIO.UnwrapOr(
+		walletController,
+	)
+
 	// Create, and start the lnwallet, which handles the core payment
 	// channel logic, and exposes control via proxy state machines.
 	lnWalletConfig := lnwallet.Config{
@@ -901,7 +940,7 @@ func (d *RPCSignerWalletImpl) BuildChainControl(
 		Signer:                rpcKeyRing,
 		FeeEstimator:          partialChainControl.FeeEstimator,
 		SecretKeyRing:         rpcKeyRing,
-		ChainIO:               walletController,
+		ChainIO:               chainIO,
 		NetParams:             *walletConfig.NetParams,
 		CoinSelectionStrategy: walletConfig.CoinSelectionStrategy,
 	}
@@ -931,6 +970,11 @@ type DatabaseInstances struct {
 	// state.
 	ChanStateDB *channeldb.DB
 
+	// ChanStateStore is the store that holds the state of our channels.
+	// Depending on the configuration, this is either backed by the
+	// ChanStateDB or by the native SQL store.
+	ChanStateStore chanstate.Store
+
 	// HeightHintDB is the database that stores height hints for spends.
 	HeightHintDB kvdb.Backend
 
@@ -941,6 +985,11 @@ type DatabaseInstances struct {
 	// information.
 	PaymentsDB paymentsdb.DB
 
+	// ForwardingLog is the log of all the HTLCs we've forwarded.
+	// Depending on the configuration, this is either backed by the
+	// ChanStateDB or by the native SQL store.
+	ForwardingLog channeldb.ForwardingLogStore
+
 	// MacaroonDB is the database that stores macaroon root keys.
 	MacaroonDB kvdb.Backend
 
@@ -1163,6 +1212,80 @@ func (d *DefaultDatabaseBuilder) BuildDatabase(
 				return nil
 			}
 
+			paymentsMig := func(tx *sqlc.Queries) error {
+				err := paymentsdb.MigratePaymentsToSQL(
+					ctx, dbs.ChanStateDB.Backend, tx,
+				)
+				if err != nil {
+					return fmt.Errorf("failed to migrate "+
+						"payments to SQL: %w", err)
+				}
+
+				return nil
+			}
+
+			chanStateMig := func(tx *sqlc.Queries) error {
+				err := channeldb.MigrateChannelStateToSQL(
+					ctx, dbs.ChanStateDB.Backend, tx,
+				)
+				if err != nil {
+					return fmt.Errorf("failed to migrate "+
+						"channel state to SQL: %w", err)
+				}
+
+				// Set the channel state tombstone to make sure
+				// the outdated KV channel state is never used
+				// again.
+				d.logger.Debugf("Setting channel state " +
+					"tombstone")
+
+				//nolint:ll
+				return dbs.ChanStateDB.SetChannelStateTombstone()
+			}
+
+			fwdLogMig := func(tx *sqlc.Queries) error {
+				err := channeldb.MigrateForwardingLogToSQL(
+					ctx, dbs.ChanStateDB.Backend, tx,
+				)
+				if err != nil {
+					return fmt.Errorf("failed to migrate "+
+						"forwarding log to SQL: %w",
+						err)
+				}
+
+				return nil
+			}
+
+			// Native SQL requires a SQL backend, for which the
+			// watchtower KV backends are opened whether or not
+			// the client and the tower are active. Their data is
+			// therefore migrated even if they are only activated
+			// later on.
+			wtClientMig := func(tx *sqlc.Queries) error {
+				err := wtdb.MigrateClientDBToSQL(
+					ctx, databaseBackends.TowerClientDB, tx,
+				)
+				if err != nil {
+					return fmt.Errorf("failed to migrate "+
+						"watchtower client DB to SQL: "+
+						"%w", err)
+				}
+
+				return nil
+			}
+
+			towerDBMig := func(tx *sqlc.Queries) error {
+				err := wtdb.MigrateTowerDBToSQL(
+					ctx, databaseBackends.TowerServerDB, tx,
+				)
+				if err != nil {
+					return fmt.Errorf("failed to migrate "+
+						"watchtower DB to SQL: %w", err)
+				}
+
+				return nil
+			}
+
 			// Make sure we attach the custom migration function to
 			// the correct migration version.
 			for i := 0; i < len(migrations); i++ {
@@ -1177,6 +1300,31 @@ func (d *DefaultDatabaseBuilder) BuildDatabase(
 
 					continue
 
+				case paymentsMigration:
+					migrations[i].MigrationFn = paymentsMig
+
+					continue
+
+				case chanStateMigration:
+					migrations[i].MigrationFn = chanStateMig
+
+					continue
+
+				case fwdLogMigration:
+					migrations[i].MigrationFn = fwdLogMig
+
+					continue
+
+				case wtClientMigration:
+					migrations[i].MigrationFn = wtClientMig
+
+					continue
+
+				case towerDBMigration:
+					migrations[i].MigrationFn = towerDBMig
+
+					continue
+
 				default:
 				}
 
@@ -1258,6 +1406,24 @@ func (d *DefaultDatabaseBuilder) BuildDatabase(
 			return nil, nil, err
 		}
 
+		// The same goes for the channel state, which must never be
+		// used from the KV store once it has been migrated.
+		ripChanState, err := dbs.ChanStateDB.GetChannelStateTombstone()
+		if err != nil {
+			err = fmt.Errorf("unable to check channel state "+
+				"tombstone: %w", err)
+			d.logger.Error(err)
+
+			return nil, nil, err
+		}
+		if ripChanState {
+			err = fmt.Errorf("channel state tombstoned, please " +
+				"switch back to native SQL")
+			d.logger.Error(err)
+
+			return nil, nil, err
+		}
+
 		dbs.InvoiceDB = dbs.ChanStateDB
 
 		graphStore, err = graphdb.NewKVStore(
@@ -1278,31 +1444,95 @@ func (d *DefaultDatabaseBuilder) BuildDatabase(
 		return nil, nil, err
 	}
 
-	// Mount the payments DB which is only KV for now.
-	//
-	// TODO(ziggie): Add support for SQL payments DB.
-	// Mount the payments DB for the KV store.
+	// Mount the payments DB. Like the invoices and the graph, payments are
+	// stored in the native SQL store if enabled.
 	paymentsDBOptions := []paymentsdb.OptionModifier{
 		paymentsdb.WithKeepFailedPaymentAttempts(
 			cfg.KeepFailedPaymentAttempts,
 		),
 	}
-	kvPaymentsDB, err := paymentsdb.NewKVStore(
-		dbs.ChanStateDB,
-		paymentsDBOptions...,
-	)
-	if err != nil {
-		cleanUp()
+	if d.cfg.DB.UseNativeSQL {
+		baseDB := dbs.NativeSQLStore.GetBaseDB()
+		paymentsExecutor := sqldb.NewTransactionExecutor(
+			baseDB, func(tx *sql.Tx) paymentsdb.SQLQueries {
+				return baseDB.WithTx(tx)
+			},
+		)
 
-		err = fmt.Errorf("unable to open payments DB: %w", err)
-		d.logger.Error(err)
+		dbs.PaymentsDB = paymentsdb.NewSQLStore(
+			paymentsExecutor, paymentsDBOptions...,
+		)
+	} else {
+		kvPaymentsDB, err := paymentsdb.NewKVStore(
+			dbs.ChanStateDB,
+			paymentsDBOptions...,
+		)
+		if err != nil {
+			cleanUp()
 
-		return nil, nil, err
+			err = fmt.Errorf("unable to open payments DB: %w", err)
+			d.logger.Error(err)
+
+			return nil, nil, err
+		}
+		dbs.PaymentsDB = kvPaymentsDB
 	}
-	dbs.PaymentsDB = kvPaymentsDB
 
-	// Wrap the watchtower client DB and make sure we clean up.
-	if cfg.WtClient.Active {
+	// Mount the channel state store. The link nodes of our peers remain in
+	// the KV channel state DB for now, even if the channel state itself is
+	// stored in the native SQL store.
+	kvChanStateDB := dbs.ChanStateDB.ChannelStateDB()
+	if d.cfg.DB.UseNativeSQL {
+		baseDB := dbs.NativeSQLStore.GetBaseDB()
+		chanStateExecutor := sqldb.NewTransactionExecutor(
+			baseDB, func(tx *sql.Tx) channeldb.SQLQueries {
+				return baseDB.WithTx(tx)
+			},
+		)
+
+		dbs.ChanStateStore = channeldb.NewSQLStore(
+			chanStateExecutor, kvChanStateDB.LinkNodeDB(),
+			channeldb.OptionNoRevLogAmtData(cfg.DB.NoRevLogAmtData),
+			channeldb.OptionStoreFinalHtlcResolutions(
+				cfg.StoreFinalHtlcResolutions,
+			),
+		)
+	} else {
+		dbs.ChanStateStore = kvChanStateDB
+	}
+
+	// Mount the forwarding log, which is moved to the native SQL store
+	// together with the channel state.
+	if d.cfg.DB.UseNativeSQL {
+		baseDB := dbs.NativeSQLStore.GetBaseDB()
+		fwdLogExecutor := sqldb.NewTransactionExecutor(
+			baseDB,
+			func(tx *sql.Tx) channeldb.SQLForwardingLogQueries {
+				return baseDB.WithTx(tx)
+			},
+		)
+
+		dbs.ForwardingLog = channeldb.NewSQLForwardingLog(
+			fwdLogExecutor,
+		)
+	} else {
+		dbs.ForwardingLog = dbs.ChanStateDB.ForwardingLog()
+	}
+
+	// Wrap the watchtower client DB and make sure we clean up. If the
+	// native SQL store is used, the watchtower DBs live there instead.
+	switch {
+	case cfg.WtClient.Active && d.cfg.DB.UseNativeSQL:
+		baseDB := dbs.NativeSQLStore.GetBaseDB()
+		wtClientExecutor := sqldb.NewTransactionExecutor(
+			baseDB, func(tx *sql.Tx) wtdb.SQLClientDBQueries {
+				return baseDB.WithTx(tx)
+			},
+		)
+
+		dbs.TowerClientDB = wtdb.NewSQLClientDB(wtClientExecutor)
+
+	case cfg.WtClient.Active:
 		dbs.TowerClientDB, err = wtdb.OpenClientDB(
 			databaseBackends.TowerClientDB,
 		)
@@ -1317,7 +1547,18 @@ func (d *DefaultDatabaseBuilder) BuildDatabase(
 	}
 
 	// Wrap the watchtower server DB and make sure we clean up.
-	if cfg.Watchtower.Active {
+	switch {
+	case cfg.Watchtower.Active && d.cfg.DB.UseNativeSQL:
+		baseDB := dbs.NativeSQLStore.GetBaseDB()
+		towerExecutor := sqldb.NewTransactionExecutor(
+			baseDB, func(tx *sql.Tx) wtdb.SQLTowerDBQueries {
+				return baseDB.WithTx(tx)
+			},
+		)
+
+		dbs.TowerServerDB = wtdb.NewSQLTowerDB(towerExecutor)
+
+	case cfg.Watchtower.Active:
 		dbs.TowerServerDB, err = wtdb.OpenTowerDB(
 			databaseBackends.TowerServerDB,
 		)
@@ -292,6 +292,9 @@ type ChainArbitrator struct {
 	// active channels that it must still watch over.
 	chanSource *channeldb.DB
 
+	// chanStateDB is the store that holds the state of our channels.
+	chanStateDB chanstate.Store
+
 	// beat is the current best known blockbeat.
 	beat chainio.Blockbeat
 
@@ -306,15 +309,16 @@ type ChainArbitrator struct {
 }
 
 // NewChainArbitrator returns a new instance of the ChainArbitrator using the
-// passed config struct, and backing persistent database.
-func NewChainArbitrator(cfg ChainArbitratorConfig,
-	db *channeldb.DB) *ChainArbitrator {
+// passed config struct, and backing persistent databases.
+func NewChainArbitrator(cfg ChainArbitratorConfig, db *channeldb.DB,
+	chanStateDB chanstate.Store) *ChainArbitrator {
 
 	c := &ChainArbitrator{
 		cfg:            cfg,
 		activeChannels: make(map[wire.OutPoint]*ChannelArbitrator),
 		activeWatchers: make(map[wire.OutPoint]*chainWatcher),
 		chanSource:     db,
+		chanStateDB:    chanStateDB,
 		quit:           make(chan struct{}),
 		resolvedChan:   make(chan wire.OutPoint),
 	}
@@ -351,7 +355,7 @@ func (a *arbChannel) NewAnchorResolutions() (*lnwallet.AnchorResolutions,
 	// same instance that is used by the link.
 	chanPoint := a.channel.FundingOutpoint
 
-	channel, err := a.c.chanSource.ChannelStateDB().FetchChannel(chanPoint)
+	channel, err := a.c.chanStateDB.FetchChannel(chanPoint)
 	if err != nil {
 		return nil, err
 	}
@@ -404,7 +408,7 @@ func (a *arbChannel) ForceCloseChan() (*wire.MsgTx, error) {
 	// Now that we know the link can't mutate the channel
 	// state, we'll read the channel from disk the target
 	// channel according to its channel point.
-	channel, err := a.c.chanSource.ChannelStateDB().FetchChannel(chanPoint)
+	channel, err := a.c.chanStateDB.FetchChannel(chanPoint)
 	if err != nil {
 		return nil, err
 	}
@@ -520,8 +524,7 @@ func newActiveChannelArbitrator(channel *chanstate.OpenChannel,
 			)
 		},
 		FetchHistoricalChannel: func() (*chanstate.OpenChannel, error) {
-			chanStateDB := c.chanSource.ChannelStateDB()
-			return chanStateDB.FetchHistoricalChannel(&chanPoint)
+			return c.chanStateDB.FetchHistoricalChannel(&chanPoint)
 		},
 		FindOutgoingHTLCDeadline: func(
 			htlc channeldb.HTLC) fn.Option[int32] {
@@ -587,7 +590,7 @@ func (c *ChainArbitrator) ResolveContract(chanPoint wire.OutPoint) error {
 
 	// First, we'll we'll mark the channel as fully closed from the PoV of
 	// the channel source.
-	err := c.chanSource.ChannelStateDB().MarkChanFullyClosed(&chanPoint)
+	err := c.chanStateDB.MarkChanFullyClosed(&chanPoint)
 	if err != nil {
 		log.Errorf("ChainArbitrator: unable to mark ChannelPoint(%v) "+
 			"fully closed: %v", chanPoint, err)
@@ -1259,6 +1262,27 @@ func (c *ChainArbitrator) SubscribeChannelEvents(
 	return watcher.SubscribeChannelEvents(), nil
 }
 
+// NotifyFundingTxReplaced notifies the chain watcher of the pending channel
+// with the passed channel point that its funding transaction was replaced by
+// one paying a higher fee, so it watches the funding output of the
+// replacement from now on.
+func (c *ChainArbitrator) NotifyFundingTxReplaced(
+	chanPoint wire.OutPoint) error {
+
+	c.Lock()
+	watcher, ok := c.activeWatchers[chanPoint]
+	c.Unlock()
+
+	if !ok {
+		return fmt.Errorf("unable to find watcher for: %v",
+			chanPoint)
+	}
+
+	watcher.fundingTxReplaced()
+
+	return nil
+}
+
 // FindOutgoingHTLCDeadline returns the deadline in absolute block height for
 // the specified outgoing HTLC. For an outgoing HTLC, its deadline is defined
 // by the timeout height of its corresponding incoming HTLC - this is the
@@ -1351,7 +1375,7 @@ func (c *ChainArbitrator) Name() string {
 // loadOpenChannels loads all channels that are currently open in the database
 // and registers them with the chainWatcher for future notification.
 func (c *ChainArbitrator) loadOpenChannels() error {
-	openChannels, err := c.chanSource.ChannelStateDB().FetchAllChannels()
+	openChannels, err := c.chanStateDB.FetchAllChannels()
 	if err != nil {
 		return err
 	}
@@ -1420,7 +1444,7 @@ func (c *ChainArbitrator) loadOpenChannels() error {
 // closure in the database and registers them with the ChannelArbitrator to
 // continue the resolution process.
 func (c *ChainArbitrator) loadPendingCloseChannels() error {
-	chanStateDB := c.chanSource.ChannelStateDB()
+	chanStateDB := c.chanStateDB
 
 	closingChannels, err := chanStateDB.FetchClosedChannels(true)
 	if err != nil {
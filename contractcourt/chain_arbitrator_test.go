@@ -88,7 +88,7 @@ func TestChainArbitratorRepublishCloses(t *testing.T) {
 		Budget: *DefaultBudgetConfig(),
 	}
 	chainArb := NewChainArbitrator(
-		chainArbCfg, db,
+		chainArbCfg, db, db.ChannelStateDB(),
 	)
 
 	beat := newBeatFromHeight(0)
@@ -173,7 +173,7 @@ func TestResolveContract(t *testing.T) {
 		},
 	}
 	chainArb := NewChainArbitrator(
-		chainArbCfg, db,
+		chainArbCfg, db, db.ChannelStateDB(),
 	)
 	beat := newBeatFromHeight(0)
 	if err := chainArb.Start(beat); err != nil {
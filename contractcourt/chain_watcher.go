@@ -325,10 +325,25 @@ type chainWatcher struct {
 	// client subscriptions for events related to this channel.
 	clientSubscriptions map[uint64]*ChainEventSubscription
 
+	// fundingOutpoint is the funding output that is watched for spends.
+	// This is the funding output of the last splice of the channel once
+	// its splice transaction confirmed, and the original funding output
+	// otherwise.
+	fundingOutpoint wire.OutPoint
+
+	// fundingPkScript is the pkScript of fundingOutpoint. A splice that
+	// upgrades the channel to taproot moves it to a MuSig2 funding output.
+	fundingPkScript []byte
+
 	// fundingSpendNtfn is the spending notification subscription for the
 	// funding outpoint.
 	fundingSpendNtfn *chainntnfs.SpendEvent
 
+	// fundingReplaced is signalled once the funding transaction of a
+	// pending channel was replaced by one paying a higher fee, which
+	// confirmed instead.
+	fundingReplaced chan struct{}
+
 	// fundingConfirmedNtfn is the confirmation notification subscription
 	// for the funding outpoint. This is only created if the channel is
 	// both taproot and pending confirmation.
@@ -395,8 +410,9 @@ func newChainWatcher(cfg chainWatcherConfig) (*chainWatcher, error) {
 
 	// We'll register for a notification to be dispatched if the funding
 	// output is spent.
+	fundingOutpoint := chanState.FundingTxOutpoint()
 	spendNtfn, err := cfg.notifier.RegisterSpendNtfn(
-		&chanState.FundingOutpoint, fundingPkScript, heightHint,
+		&fundingOutpoint, fundingPkScript, heightHint,
 	)
 	if err != nil {
 		return nil, err
@@ -407,7 +423,10 @@ func newChainWatcher(cfg chainWatcherConfig) (*chainWatcher, error) {
 		stateHintObfuscator: stateHint,
 		quit:                make(chan struct{}),
 		clientSubscriptions: make(map[uint64]*ChainEventSubscription),
+		fundingOutpoint:     fundingOutpoint,
+		fundingPkScript:     fundingPkScript,
 		fundingSpendNtfn:    spendNtfn,
+		fundingReplaced:     make(chan struct{}, 1),
 	}
 
 	// If this is a pending taproot channel, we need to register for a
@@ -470,6 +489,15 @@ func (c *chainWatcher) Stop() error {
 	return nil
 }
 
+// fundingTxReplaced signals the close observer that the funding transaction
+// of the channel was replaced.
+func (c *chainWatcher) fundingTxReplaced() {
+	select {
+	case c.fundingReplaced <- struct{}{}:
+	default:
+	}
+}
+
 // SubscribeChannelEvents returns an active subscription to the set of channel
 // events for the channel watched by this chain watcher. Once clients no longer
 // require the subscription, they should call the Cancel() method to allow the
@@ -520,9 +548,15 @@ func (c *chainWatcher) handleUnknownLocalState(
 	// either case, the only thing we can do is to attempt to sweep what is
 	// there.
 
+	// The broadcast state may predate a dynamic commitment, so we'll use
+	// the channel parameters that were in effect at that height.
+	chanState := c.cfg.chanState.ParamsAtHeight(
+		lntypes.Local, broadcastStateNum,
+	)
+
 	// First, we'll re-derive our commitment point for this state since
 	// this is what we use to randomize each of the keys for this state.
-	commitSecret, err := c.cfg.chanState.RevocationProducer.AtIndex(
+	commitSecret, err := chanState.RevocationProducer.AtIndex(
 		broadcastStateNum,
 	)
 	if err != nil {
@@ -534,8 +568,8 @@ func (c *chainWatcher) handleUnknownLocalState(
 	// and remote keys for this state. We use our point as only we can
 	// revoke our own commitment.
 	commitKeyRing := lnwallet.DeriveCommitmentKeys(
-		commitPoint, lntypes.Local, c.cfg.chanState.ChanType,
-		&c.cfg.chanState.LocalChanCfg, &c.cfg.chanState.RemoteChanCfg,
+		commitPoint, lntypes.Local, chanState.ChanType,
+		&chanState.LocalChanCfg, &chanState.RemoteChanCfg,
 	)
 
 	auxResult, err := fn.MapOptionZ(
@@ -544,7 +578,7 @@ func (c *chainWatcher) handleUnknownLocalState(
 		func(s lnwallet.AuxLeafStore) fn.Result[lnwallet.CommitDiffAuxResult] {
 			return s.FetchLeavesFromCommit(
 				lnwallet.NewAuxChanState(c.cfg.chanState),
-				c.cfg.chanState.LocalCommitment, *commitKeyRing,
+				chanState.LocalCommitment, *commitKeyRing,
 				lntypes.Local,
 			)
 		},
@@ -556,8 +590,8 @@ func (c *chainWatcher) handleUnknownLocalState(
 	// With the keys derived, we'll construct the remote script that'll be
 	// present if they have a non-dust balance on the commitment.
 	var leaseExpiry uint32
-	if c.cfg.chanState.ChanType.HasLeaseExpiration() {
-		leaseExpiry = c.cfg.chanState.ThawHeight
+	if chanState.ChanType.HasLeaseExpiration() {
+		leaseExpiry = chanState.ThawHeight
 	}
 
 	remoteAuxLeaf := fn.FlatMapOption(
@@ -566,7 +600,7 @@ func (c *chainWatcher) handleUnknownLocalState(
 		},
 	)(auxResult.AuxLeaves)
 	remoteScript, _, err := lnwallet.CommitScriptToRemote(
-		c.cfg.chanState.ChanType, c.cfg.chanState.IsInitiator,
+		chanState.ChanType, chanState.IsInitiator,
 		commitKeyRing.ToRemoteKey, leaseExpiry,
 		remoteAuxLeaf,
 	)
@@ -583,9 +617,9 @@ func (c *chainWatcher) handleUnknownLocalState(
 		},
 	)(auxResult.AuxLeaves)
 	localScript, err := lnwallet.CommitScriptToSelf(
-		c.cfg.chanState.ChanType, c.cfg.chanState.IsInitiator,
+		chanState.ChanType, chanState.IsInitiator,
 		commitKeyRing.ToLocalKey, commitKeyRing.RevocationKey,
-		uint32(c.cfg.chanState.LocalChanCfg.CsvDelay), leaseExpiry,
+		uint32(chanState.LocalChanCfg.CsvDelay), leaseExpiry,
 		localAuxLeaf,
 	)
 	if err != nil {
@@ -844,6 +878,101 @@ func (c *chainWatcher) processDetectedSpend(
 	}
 }
 
+// spliceFundingScripts returns the pkScripts of the funding outputs splices
+// of the channel can create. Splices keep the funding keys of the channel, so
+// this is the funding script of the channel itself, or the MuSig2 funding
+// script of the same keys if the splice upgrades the channel to taproot. Only
+// channels with a segwit v0 funding output can be spliced, so nil is returned
+// for taproot channels.
+func (c *chainWatcher) spliceFundingScripts() [][]byte {
+	chanState := c.cfg.chanState
+	if chanState.ChanType.IsTaproot() {
+		return nil
+	}
+
+	fundingPkScript, err := deriveFundingPkScript(chanState)
+	if err != nil {
+		log.Errorf("ChannelPoint(%v): unable to derive funding "+
+			"script: %v", chanState.FundingOutpoint, err)
+
+		return nil
+	}
+
+	taprootPkScript, _, err := input.GenTaprootFundingScript(
+		chanState.LocalChanCfg.MultiSigKey.PubKey,
+		chanState.RemoteChanCfg.MultiSigKey.PubKey, 0,
+		chanState.TapscriptRoot,
+	)
+	if err != nil {
+		log.Errorf("ChannelPoint(%v): unable to derive taproot "+
+			"funding script: %v", chanState.FundingOutpoint, err)
+
+		return [][]byte{fundingPkScript}
+	}
+
+	return [][]byte{fundingPkScript, taprootPkScript}
+}
+
+// spliceFundingOutput returns the pkScript and index of the funding output
+// created by the passed splice transaction, if it creates one.
+func (c *chainWatcher) spliceFundingOutput(tx *wire.MsgTx) ([]byte, uint32,
+	bool) {
+
+	for _, pkScript := range c.spliceFundingScripts() {
+		found, index := input.FindScriptOutputIndex(tx, pkScript)
+		if found {
+			return pkScript, index, true
+		}
+	}
+
+	return nil, 0, false
+}
+
+// isSpliceSpend returns true if the passed spend of the funding output is a
+// splice transaction. Neither commitment nor closing transactions pay back to
+// the funding keys, so any spend that creates a new funding output is a
+// splice.
+func (c *chainWatcher) isSpliceSpend(spend *chainntnfs.SpendDetail) bool {
+	_, _, found := c.spliceFundingOutput(spend.SpendingTx)
+
+	return found
+}
+
+// trackSplice registers for the confirmation of the passed splice
+// transaction, replacing the splice that is currently tracked, if any.
+func (c *chainWatcher) trackSplice(spend *chainntnfs.SpendDetail,
+	currentSplice *chainntnfs.SpendDetail,
+	currentConfNtfn *chainntnfs.ConfirmationEvent) (
+	*chainntnfs.SpendDetail, *chainntnfs.ConfirmationEvent) {
+
+	if currentSplice != nil {
+		if *currentSplice.SpenderTxHash == *spend.SpenderTxHash {
+			return currentSplice, currentConfNtfn
+		}
+
+		currentConfNtfn.Cancel()
+	}
+
+	pkScript, _, _ := c.spliceFundingOutput(spend.SpendingTx)
+	numConfs := c.requiredConfsForSpend()
+	confNtfn, err := c.cfg.notifier.RegisterConfirmationsNtfn(
+		spend.SpenderTxHash, pkScript, numConfs,
+		uint32(spend.SpendingHeight),
+	)
+	if err != nil {
+		log.Errorf("Unable to register for splice confirmations: %v",
+			err)
+
+		return nil, nil
+	}
+
+	log.Infof("ChannelPoint(%v): detected splice tx %v, waiting for %d "+
+		"confirmations", c.cfg.chanState.FundingOutpoint,
+		spend.SpenderTxHash, numConfs)
+
+	return spend, confNtfn
+}
+
 // closeObserver is a dedicated goroutine that will watch for any closes of the
 // channel that it's watching on chain. It implements a state machine to handle
 // spend detection and confirmation with reorg protection. The states are:
@@ -864,17 +993,10 @@ func (c *chainWatcher) closeObserver() {
 	defer c.wg.Done()
 
 	registerForSpend := func() (*chainntnfs.SpendEvent, error) {
-		fundingPkScript, err := deriveFundingPkScript(c.cfg.chanState)
-		if err != nil {
-			return nil, err
-		}
-
 		heightHint := c.cfg.chanState.DeriveHeightHint()
 
 		return c.cfg.notifier.RegisterSpendNtfn(
-			&c.cfg.chanState.FundingOutpoint,
-			fundingPkScript,
-			heightHint,
+			&c.fundingOutpoint, c.fundingPkScript, heightHint,
 		)
 	}
 
@@ -893,6 +1015,36 @@ func (c *chainWatcher) closeObserver() {
 		confNtfn     *chainntnfs.ConfirmationEvent
 	)
 
+	// A splice of the channel spends the funding output as well, but
+	// doesn't close the channel. We track the splice transaction
+	// separately until it confirmed, and then move on to watching the new
+	// funding output.
+	var (
+		pendingSplice  *chainntnfs.SpendDetail
+		spliceConfNtfn *chainntnfs.ConfirmationEvent
+	)
+	defer func() {
+		if spliceConfNtfn != nil {
+			spliceConfNtfn.Cancel()
+		}
+	}()
+
+	// reRegisterForSpend replaces the spend notification for the funding
+	// output with a new one for the currently watched funding output.
+	reRegisterForSpend := func() error {
+		spendNtfn.Cancel()
+
+		newSpendNtfn, err := registerForSpend()
+		if err != nil {
+			return err
+		}
+
+		spendNtfn = newSpendNtfn
+		c.fundingSpendNtfn = spendNtfn
+
+		return nil
+	}
+
 	log.Infof("Close observer for ChannelPoint(%v) active",
 		c.cfg.chanState.FundingOutpoint)
 
@@ -902,13 +1054,19 @@ func (c *chainWatcher) closeObserver() {
 		// select ignores those cases, effectively implementing our
 		// state machine.
 		var (
-			confChan         <-chan *chainntnfs.TxConfirmation
-			negativeConfChan <-chan int32
+			confChan               <-chan *chainntnfs.TxConfirmation
+			negativeConfChan       <-chan int32
+			spliceConfChan         <-chan *chainntnfs.TxConfirmation
+			spliceNegativeConfChan <-chan int32
 		)
 		if confNtfn != nil {
 			confChan = confNtfn.Confirmed
 			negativeConfChan = confNtfn.NegativeConf
 		}
+		if spliceConfNtfn != nil {
+			spliceConfChan = spliceConfNtfn.Confirmed
+			spliceNegativeConfChan = spliceConfNtfn.NegativeConf
+		}
 
 		select {
 		// A new block beat has just arrived, we'll handle the block
@@ -923,6 +1081,14 @@ func (c *chainWatcher) closeObserver() {
 				continue
 			}
 
+			if c.isSpliceSpend(spend) {
+				pendingSplice, spliceConfNtfn = c.trackSplice(
+					spend, pendingSplice, spliceConfNtfn,
+				)
+
+				continue
+			}
+
 			result := c.processDetectedSpend(
 				spend, "blockbeat", pendingSpend, confNtfn,
 			)
@@ -938,6 +1104,14 @@ func (c *chainWatcher) closeObserver() {
 				return
 			}
 
+			if c.isSpliceSpend(spend) {
+				pendingSplice, spliceConfNtfn = c.trackSplice(
+					spend, pendingSplice, spliceConfNtfn,
+				)
+
+				continue
+			}
+
 			result := c.processDetectedSpend(
 				spend, "spend notification", pendingSpend,
 				confNtfn,
@@ -1020,6 +1194,94 @@ func (c *chainWatcher) closeObserver() {
 			log.Infof("ChannelPoint(%v): re-registered for spend "+
 				"detection", c.cfg.chanState.FundingOutpoint)
 
+		// The splice transaction has reached the required
+		// confirmations, so the channel is now funded by the new
+		// funding output.
+		case conf, ok := <-spliceConfChan:
+			if !ok {
+				log.Errorf("Splice confirmation channel " +
+					"closed unexpectedly")
+				return
+			}
+
+			pkScript, index, _ := c.spliceFundingOutput(
+				pendingSplice.SpendingTx,
+			)
+			c.fundingOutpoint = wire.OutPoint{
+				Hash:  *pendingSplice.SpenderTxHash,
+				Index: index,
+			}
+			c.fundingPkScript = pkScript
+
+			log.Infof("ChannelPoint(%v): splice tx confirmed at "+
+				"height %d, watching new funding output %v",
+				c.cfg.chanState.FundingOutpoint,
+				conf.BlockHeight, c.fundingOutpoint)
+
+			spliceConfNtfn.Cancel()
+			spliceConfNtfn = nil
+			pendingSplice = nil
+
+			// Pick up the commitments spending the new funding
+			// output, and the channel type of a taproot upgrade, so
+			// a later close is resolved against them.
+			if err := c.cfg.chanState.Refresh(); err != nil {
+				log.Warnf("ChannelPoint(%v): unable to "+
+					"refresh channel state: %v",
+					c.cfg.chanState.FundingOutpoint, err)
+			}
+
+			if err := reRegisterForSpend(); err != nil {
+				log.Errorf("Unable to register for spend of "+
+					"splice output: %v", err)
+				return
+			}
+
+		// A reorg removed the splice transaction, so the current
+		// funding output is unspent again.
+		case reorgDepth, ok := <-spliceNegativeConfChan:
+			if !ok {
+				log.Errorf("Splice negative conf channel " +
+					"closed unexpectedly")
+				return
+			}
+
+			log.Infof("ChannelPoint(%v): splice tx %v reorged out "+
+				"at depth %d", c.cfg.chanState.FundingOutpoint,
+				pendingSplice.SpenderTxHash, reorgDepth)
+
+			spliceConfNtfn.Cancel()
+			spliceConfNtfn = nil
+			pendingSplice = nil
+
+			if err := reRegisterForSpend(); err != nil {
+				log.Errorf("Unable to re-register for "+
+					"spend: %v", err)
+				return
+			}
+
+		// The funding transaction was replaced, so the channel is
+		// now funded by the funding output of the replacement.
+		case <-c.fundingReplaced:
+			if err := c.cfg.chanState.Refresh(); err != nil {
+				log.Warnf("ChannelPoint(%v): unable to "+
+					"refresh channel state: %v",
+					c.cfg.chanState.FundingOutpoint, err)
+			}
+
+			c.fundingOutpoint = c.cfg.chanState.FundingTxOutpoint()
+
+			log.Infof("ChannelPoint(%v): funding tx replaced, "+
+				"watching funding output %v",
+				c.cfg.chanState.FundingOutpoint,
+				c.fundingOutpoint)
+
+			if err := reRegisterForSpend(); err != nil {
+				log.Errorf("Unable to register for spend of "+
+					"replaced funding output: %v", err)
+				return
+			}
+
 		// The chainWatcher has been signalled to exit, so we'll do so
 		// now.
 		case <-c.quit:
@@ -0,0 +1,92 @@
+package contractcourt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// createSpliceTx creates a splice transaction that spends the funding output
+// of the harness channel into a new, larger funding output.
+func (h *chainWatcherTestHarness) createSpliceTx() *wire.MsgTx {
+	fundingOutput := h.aliceChannel.FundingTxOutput()
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: h.aliceChannel.State().FundingOutpoint,
+	})
+	tx.AddTxOut(&wire.TxOut{
+		Value:    fundingOutput.Value + 1_000_000,
+		PkScript: fundingOutput.PkScript,
+	})
+
+	return tx
+}
+
+// TestChainWatcherSplice asserts that a confirmed splice of the channel isn't
+// mistaken for a close, and that the chain watcher moves on to watching the
+// new funding output.
+func TestChainWatcherSplice(t *testing.T) {
+	t.Parallel()
+
+	h := newChainWatcherTestHarness(t, withRequiredConfs(3))
+
+	spliceTx := h.createSpliceTx()
+	h.sendSpend(spliceTx)
+	h.waitForConfRegistration()
+
+	h.mineBlocks(3)
+	h.confirmTx(spliceTx, h.currentHeight)
+
+	// Once the splice confirmed, the chain watcher registers for spends
+	// of the new funding output.
+	select {
+	case <-h.notifier.spendRegistered:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no spend registration for splice output")
+	}
+
+	h.assertNoCoopClose(100 * time.Millisecond)
+	require.Empty(t, h.chanEvents.RemoteUnilateralClosure)
+	require.Empty(t, h.chanEvents.LocalUnilateralClosure)
+
+	// A later close of the channel is still detected.
+	closeTx := h.createRemoteForceCloseTx()
+	h.sendSpend(closeTx)
+	h.waitForConfRegistration()
+	h.confirmTx(closeTx, h.currentHeight)
+
+	closeInfo := h.waitForRemoteUnilateralClose(5 * time.Second)
+	h.assertRemoteUnilateralCloseTx(closeInfo, closeTx)
+}
+
+// TestChainWatcherSpliceReorg asserts that the chain watcher goes back to
+// watching the current funding output if a splice transaction is reorged out.
+func TestChainWatcherSpliceReorg(t *testing.T) {
+	t.Parallel()
+
+	h := newChainWatcherTestHarness(t, withRequiredConfs(3))
+
+	spliceTx := h.createSpliceTx()
+	h.sendSpend(spliceTx)
+	h.waitForConfRegistration()
+
+	h.triggerReorg(spliceTx, 1)
+
+	select {
+	case <-h.notifier.spendRegistered:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no spend re-registration after splice reorg")
+	}
+
+	// The current commitment can still close the channel.
+	closeTx := h.createRemoteForceCloseTx()
+	h.sendSpend(closeTx)
+	h.waitForConfRegistration()
+	h.confirmTx(closeTx, h.currentHeight)
+
+	closeInfo := h.waitForRemoteUnilateralClose(5 * time.Second)
+	h.assertRemoteUnilateralCloseTx(closeInfo, closeTx)
+}
@@ -2,11 +2,13 @@ package discovery
 
 import (
 	"context"
+	"errors"
 	"iter"
 	"time"
 
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	graphdb "github.com/flokiorg/flnd/graph/db"
+	"github.com/flokiorg/flnd/graph/db/models"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/netann"
 	"github.com/flokiorg/flnd/routing/route"
@@ -63,7 +65,7 @@ type ChannelGraphTimeSeries interface {
 	// specified short channel ID. If no channel updates are known for the
 	// channel, then an empty slice will be returned.
 	FetchChanUpdates(chain chainhash.Hash,
-		shortChanID lnwire.ShortChannelID) ([]*lnwire.ChannelUpdate1,
+		shortChanID lnwire.ShortChannelID) ([]lnwire.ChannelUpdate,
 		error)
 }
 
@@ -131,8 +133,7 @@ func (c *ChanSeries) UpdatesInHorizon(chain chainhash.Hash,
 				continue
 			}
 
-			//nolint:ll
-			chanAnn, edge1, edge2, err := netann.CreateChanAnnouncement(
+			chanAnn, edge1, edge2, err := createChanAnnouncement(
 				channel.Info.AuthProof, channel.Info,
 				channel.Policy1, channel.Policy2,
 			)
@@ -271,6 +272,69 @@ func (c *ChanSeries) FetchChanAnns(chain chainhash.Hash,
 	// anns we'll need to send.
 	nodePubsSent := make(map[route.Vertex]struct{})
 
+	// nodeAnns returns the announcements of the given node that we haven't
+	// yet sent. For the nodes of a gossip v2 channel, we'll also include
+	// the node's v2 announcement if we have one.
+	nodeAnns := func(node *models.Node,
+		version lnwire.GossipVersion) ([]lnwire.Message, error) {
+
+		nodePub := node.PubKeyBytes
+		if _, ok := nodePubsSent[nodePub]; ok {
+			return nil, nil
+		}
+
+		var anns []lnwire.Message
+		if node.HaveAnnouncement() {
+			nodeAnn, err := node.NodeAnnouncement(true)
+			if err != nil {
+				return nil, err
+			}
+
+			err = netann.ValidateNodeAnnFields(nodeAnn)
+			if err != nil {
+				log.Debugf("Skipping forwarding invalid node "+
+					"announcement %x: %v", nodeAnn.NodeID,
+					err)
+			} else {
+				anns = append(anns, nodeAnn)
+			}
+		}
+
+		if version == lnwire.GossipVersion2 {
+			nodeV2, err := c.graph.FetchNodeV2(
+				context.TODO(), nodePub,
+			)
+			switch {
+			// The node hasn't sent us a v2 announcement.
+			case errors.Is(err, graphdb.ErrGraphNodeNotFound):
+
+			case err != nil:
+				return nil, err
+
+			default:
+				nodeAnn, err := nodeV2.NodeAnnouncement2(true)
+				if err != nil {
+					return nil, err
+				}
+
+				err = netann.ValidateNodeAnn2Fields(nodeAnn)
+				if err != nil {
+					log.Debugf("Skipping forwarding "+
+						"invalid node announcement "+
+						"%v: %v", nodePub, err)
+				} else {
+					anns = append(anns, nodeAnn)
+				}
+			}
+		}
+
+		if len(anns) > 0 {
+			nodePubsSent[nodePub] = struct{}{}
+		}
+
+		return anns, nil
+	}
+
 	chanAnns := make([]lnwire.Message, 0, len(channels)*3)
 	for _, channel := range channels {
 		// If the channel doesn't have an authentication proof, then we
@@ -280,7 +344,7 @@ func (c *ChanSeries) FetchChanAnns(chain chainhash.Hash,
 			continue
 		}
 
-		chanAnn, edge1, edge2, err := netann.CreateChanAnnouncement(
+		chanAnn, edge1, edge2, err := createChanAnnouncement(
 			channel.Info.AuthProof, channel.Info, channel.Policy1,
 			channel.Policy2,
 		)
@@ -288,58 +352,30 @@ func (c *ChanSeries) FetchChanAnns(chain chainhash.Hash,
 			return nil, err
 		}
 
+		version := channel.Info.GossipVersion()
+
 		chanAnns = append(chanAnns, chanAnn)
 		if edge1 != nil {
 			chanAnns = append(chanAnns, edge1)
 
 			// If this edge has a validated node announcement, that
 			// we haven't yet sent, then we'll send that as well.
-			nodePub := channel.Node2.PubKeyBytes
-			hasNodeAnn := channel.Node2.HaveAnnouncement()
-			if _, ok := nodePubsSent[nodePub]; !ok && hasNodeAnn {
-				nodeAnn, err := channel.Node2.NodeAnnouncement(
-					true,
-				)
-				if err != nil {
-					return nil, err
-				}
-
-				err = netann.ValidateNodeAnnFields(nodeAnn)
-				if err != nil {
-					log.Debugf("Skipping forwarding "+
-						"invalid node announcement "+
-						"%x: %v", nodeAnn.NodeID, err)
-				} else {
-					chanAnns = append(chanAnns, nodeAnn)
-					nodePubsSent[nodePub] = struct{}{}
-				}
+			anns, err := nodeAnns(channel.Node2, version)
+			if err != nil {
+				return nil, err
 			}
+			chanAnns = append(chanAnns, anns...)
 		}
 		if edge2 != nil {
 			chanAnns = append(chanAnns, edge2)
 
 			// If this edge has a validated node announcement, that
 			// we haven't yet sent, then we'll send that as well.
-			nodePub := channel.Node1.PubKeyBytes
-			hasNodeAnn := channel.Node1.HaveAnnouncement()
-			if _, ok := nodePubsSent[nodePub]; !ok && hasNodeAnn {
-				nodeAnn, err := channel.Node1.NodeAnnouncement(
-					true,
-				)
-				if err != nil {
-					return nil, err
-				}
-
-				err = netann.ValidateNodeAnnFields(nodeAnn)
-				if err != nil {
-					log.Debugf("Skipping forwarding "+
-						"invalid node announcement "+
-						"%x: %v", nodeAnn.NodeID, err)
-				} else {
-					chanAnns = append(chanAnns, nodeAnn)
-					nodePubsSent[nodePub] = struct{}{}
-				}
+			anns, err := nodeAnns(channel.Node1, version)
+			if err != nil {
+				return nil, err
 			}
+			chanAnns = append(chanAnns, anns...)
 		}
 	}
 
@@ -352,7 +388,7 @@ func (c *ChanSeries) FetchChanAnns(chain chainhash.Hash,
 //
 // NOTE: This is part of the ChannelGraphTimeSeries interface.
 func (c *ChanSeries) FetchChanUpdates(chain chainhash.Hash,
-	shortChanID lnwire.ShortChannelID) ([]*lnwire.ChannelUpdate1, error) {
+	shortChanID lnwire.ShortChannelID) ([]lnwire.ChannelUpdate, error) {
 
 	chanInfo, e1, e2, err := c.graph.FetchChannelEdgesByID(
 		shortChanID.ToUint64(),
@@ -361,17 +397,13 @@ func (c *ChanSeries) FetchChanUpdates(chain chainhash.Hash,
 		return nil, err
 	}
 
-	chanUpdates := make([]*lnwire.ChannelUpdate1, 0, 2)
-	if e1 != nil {
-		chanUpdate, err := netann.ChannelUpdateFromEdge(chanInfo, e1)
-		if err != nil {
-			return nil, err
+	chanUpdates := make([]lnwire.ChannelUpdate, 0, 2)
+	for _, edge := range []*models.ChannelEdgePolicy{e1, e2} {
+		if edge == nil {
+			continue
 		}
 
-		chanUpdates = append(chanUpdates, chanUpdate)
-	}
-	if e2 != nil {
-		chanUpdate, err := netann.ChannelUpdateFromEdge(chanInfo, e2)
+		chanUpdate, err := channelUpdateFromEdge(chanInfo, edge)
 		if err != nil {
 			return nil, err
 		}
@@ -382,6 +414,18 @@ func (c *ChanSeries) FetchChanUpdates(chain chainhash.Hash,
 	return chanUpdates, nil
 }
 
+// channelUpdateFromEdge reconstructs the signed channel update of the given
+// policy, using the message version matching the edge.
+func channelUpdateFromEdge(info *models.ChannelEdgeInfo,
+	policy *models.ChannelEdgePolicy) (lnwire.ChannelUpdate, error) {
+
+	if info.GossipVersion() == lnwire.GossipVersion2 {
+		return netann.ChannelUpdate2FromEdge(info, policy)
+	}
+
+	return netann.ChannelUpdateFromEdge(info, policy)
+}
+
 // A compile-time assertion to ensure that ChanSeries meets the
 // ChannelGraphTimeSeries interface.
 var _ ChannelGraphTimeSeries = (*ChanSeries)(nil)
@@ -32,6 +32,7 @@ import (
 	"github.com/flokiorg/flnd/netann"
 	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/flnd/ticker"
+	"github.com/flokiorg/flnd/tlv"
 	"github.com/flokiorg/flokicoin-neutrino/cache"
 	"github.com/flokiorg/flokicoin-neutrino/cache/lru"
 	"github.com/flokiorg/go-flokicoin/chaincfg"
@@ -290,11 +291,12 @@ type Config struct {
 	// AnnSigner is an instance of the MessageSigner interface which will
 	// be used to manually sign any outgoing channel updates. The signer
 	// implementation should be backed by the public key of the backing
-	// Lightning node.
+	// Lightning node. It must also be able to produce the Schnorr
+	// signatures of gossip v2 channel updates.
 	//
 	// TODO(roasbeef): extract ann crafting + sign from fundingMgr into
 	// here?
-	AnnSigner lnwallet.MessageSigner
+	AnnSigner lnwallet.SchnorrMessageSigner
 
 	// ScidCloser is an instance of ClosedChannelTracker that helps the
 	// gossiper cut down on spam channel announcements for already closed
@@ -950,13 +952,13 @@ func (d *AuthenticatedGossiper) ProcessRemoteAnnouncement(ctx context.Context,
 	// To avoid inserting edges in the graph for our own channels that we
 	// have already closed, we ignore such channel announcements coming
 	// from the remote.
-	case *lnwire.ChannelAnnouncement1:
+	case lnwire.ChannelAnnouncement:
 		ownKey := d.selfKey.SerializeCompressed()
-		ownErr := fmt.Errorf("ignoring remote ChannelAnnouncement1 " +
-			"for own channel")
+		ownErr := fmt.Errorf("ignoring remote %T for own channel", m)
 
-		if bytes.Equal(m.NodeID1[:], ownKey) ||
-			bytes.Equal(m.NodeID2[:], ownKey) {
+		node1, node2 := m.Node1KeyBytes(), m.Node2KeyBytes()
+		if bytes.Equal(node1[:], ownKey) ||
+			bytes.Equal(node2[:], ownKey) {
 
 			log.Warn(ownErr)
 			errChan <- ownErr
@@ -1078,6 +1080,11 @@ type deDupedAnnouncements struct {
 	// nodeAnnouncements are identified by the Vertex field.
 	nodeAnnouncements map[route.Vertex]msgWithSenders
 
+	// nodeAnnouncements2 are the gossip v2 node announcements, identified
+	// by the NodeID field. They're kept apart from the v1 announcements
+	// so that a node's v1 and v2 announcement don't replace each other.
+	nodeAnnouncements2 map[route.Vertex]msgWithSenders
+
 	sync.Mutex
 }
 
@@ -1099,6 +1106,7 @@ func (d *deDupedAnnouncements) reset() {
 	d.channelAnnouncements = make(map[lnwire.ShortChannelID]msgWithSenders)
 	d.channelUpdates = make(map[channelUpdateID]msgWithSenders)
 	d.nodeAnnouncements = make(map[route.Vertex]msgWithSenders)
+	d.nodeAnnouncements2 = make(map[route.Vertex]msgWithSenders)
 }
 
 // addMsg adds a new message to the current batch. If the message is already
@@ -1116,8 +1124,8 @@ func (d *deDupedAnnouncements) addMsg(message networkMsg) {
 	switch msg := message.msg.(type) {
 
 	// Channel announcements are identified by the short channel id field.
-	case *lnwire.ChannelAnnouncement1:
-		deDupKey := msg.ShortChannelID
+	case lnwire.ChannelAnnouncement:
+		deDupKey := msg.SCID()
 		sender := route.NewVertex(message.source)
 
 		mws, ok := d.channelAnnouncements[deDupKey]
@@ -1198,6 +1206,54 @@ func (d *deDupedAnnouncements) addMsg(message networkMsg) {
 		mws.senders[sender] = struct{}{}
 		d.channelUpdates[deDupKey] = mws
 
+	// Gossip v2 channel updates carry no timestamp, so their age is
+	// determined by the block height they were signed at instead.
+	case *lnwire.ChannelUpdate2:
+		sender := route.NewVertex(message.source)
+
+		var flags lnwire.ChanUpdateChanFlags
+		if !msg.IsNode1() {
+			flags |= lnwire.ChanUpdateDirection
+		}
+		deDupKey := channelUpdateID{msg.ShortChannelID.Val, flags}
+
+		// Unless this is the first time we see an update for this
+		// direction, we'll only replace the existing one if the new
+		// update is strictly newer.
+		cmp := lnwire.GreaterThan
+		mws, ok := d.channelUpdates[deDupKey]
+		if ok {
+			var err error
+			cmp, err = msg.CmpAge(mws.msg.(lnwire.ChannelUpdate))
+			if err != nil {
+				log.Errorf("Unable to compare channel "+
+					"updates: %v", err)
+
+				return
+			}
+		}
+
+		switch cmp {
+		case lnwire.LessThan:
+			log.Debugf("Ignored outdated network message: "+
+				"peer=%v, msg=%s", message.peer, msg.MsgType())
+
+		case lnwire.GreaterThan:
+			mws = msgWithSenders{
+				msg:     msg,
+				isLocal: !message.isRemote,
+				senders: make(map[route.Vertex]struct{}),
+			}
+			mws.senders[sender] = struct{}{}
+
+			d.channelUpdates[deDupKey] = mws
+
+		case lnwire.EqualTo:
+			mws.msg = msg
+			mws.senders[sender] = struct{}{}
+			d.channelUpdates[deDupKey] = mws
+		}
+
 	// Node announcements are identified by the Vertex field.  Use the
 	// NodeID to create the corresponding Vertex.
 	case *lnwire.NodeAnnouncement1:
@@ -1237,6 +1293,44 @@ func (d *deDupedAnnouncements) addMsg(message networkMsg) {
 		mws.msg = msg
 		mws.senders[sender] = struct{}{}
 		d.nodeAnnouncements[deDupKey] = mws
+
+	// Gossip v2 node announcements are ordered by the block height they
+	// were signed at rather than by a timestamp.
+	case *lnwire.NodeAnnouncement2:
+		sender := route.NewVertex(message.source)
+		deDupKey := route.Vertex(msg.NodeID.Val)
+
+		oldHeight := uint32(0)
+		mws, ok := d.nodeAnnouncements2[deDupKey]
+		if ok {
+			ann, _ := mws.msg.(*lnwire.NodeAnnouncement2)
+			oldHeight = ann.BlockHeight.Val
+		}
+
+		// Discard the message if it's old.
+		if ok && oldHeight > msg.BlockHeight.Val {
+			return
+		}
+
+		// Replace if it's newer or the first one we see.
+		if !ok || oldHeight < msg.BlockHeight.Val {
+			mws = msgWithSenders{
+				msg:     msg,
+				isLocal: !message.isRemote,
+				senders: make(map[route.Vertex]struct{}),
+			}
+
+			mws.senders[sender] = struct{}{}
+
+			d.nodeAnnouncements2[deDupKey] = mws
+
+			return
+		}
+
+		// Add to senders map if it's the same as we had.
+		mws.msg = msg
+		mws.senders[sender] = struct{}{}
+		d.nodeAnnouncements2[deDupKey] = mws
 	}
 }
 
@@ -1294,7 +1388,7 @@ func (d *deDupedAnnouncements) Emit() msgsToBroadcast {
 
 	// Get the total number of announcements.
 	numAnnouncements := len(d.channelAnnouncements) + len(d.channelUpdates) +
-		len(d.nodeAnnouncements)
+		len(d.nodeAnnouncements) + len(d.nodeAnnouncements2)
 
 	// Create an empty array of lnwire.Messages with a length equal to
 	// the total number of announcements.
@@ -1317,6 +1411,9 @@ func (d *deDupedAnnouncements) Emit() msgsToBroadcast {
 	for _, message := range d.nodeAnnouncements {
 		msgs.addMsg(message)
 	}
+	for _, message := range d.nodeAnnouncements2 {
+		msgs.addMsg(message)
+	}
 
 	d.reset()
 
@@ -1808,6 +1905,12 @@ func (d *AuthenticatedGossiper) isRecentlyRejectedMsg(msg lnwire.Message,
 	case *lnwire.ChannelAnnouncement1:
 		scid = m.ShortChannelID.ToUint64()
 
+	case *lnwire.ChannelUpdate2:
+		scid = m.ShortChannelID.Val.ToUint64()
+
+	case *lnwire.ChannelAnnouncement2:
+		scid = m.ShortChannelID.Val.ToUint64()
+
 	default:
 		return false
 	}
@@ -1898,6 +2001,24 @@ func (d *AuthenticatedGossiper) retransmitStaleAnns(ctx context.Context,
 
 	var signedUpdates []lnwire.Message
 	for _, chanToUpdate := range edgesToUpdate {
+		// Gossip v2 channels are re-signed using a v2 update.
+		if chanToUpdate.info.GossipVersion() == lnwire.GossipVersion2 {
+			chanAnn, chanUpdate, err := d.updateChannel2(
+				ctx, chanToUpdate.info, chanToUpdate.edge,
+			)
+			if err != nil {
+				return fmt.Errorf("unable to update channel: "+
+					"%w", err)
+			}
+
+			if chanAnn != nil {
+				signedUpdates = append(signedUpdates, chanAnn)
+			}
+			signedUpdates = append(signedUpdates, chanUpdate)
+
+			continue
+		}
+
 		// Re-sign and update the channel on disk and retrieve our
 		// ChannelUpdate to broadcast.
 		chanAnn, chanUpdate, err := d.updateChannel(
@@ -1973,6 +2094,44 @@ func (d *AuthenticatedGossiper) processChanPolicyUpdate(ctx context.Context,
 
 	var chanUpdates []networkMsg
 	for _, edgeInfo := range edgesToUpdate {
+		// Gossip v2 channels never use an alias in their updates, so
+		// we only need to re-sign them.
+		if edgeInfo.Info.GossipVersion() == lnwire.GossipVersion2 {
+			_, chanUpdate, err := d.updateChannel2(
+				ctx, edgeInfo.Info, edgeInfo.Edge,
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			// As with v1 channels, the update of a channel that
+			// isn't announced yet is only sent to the remote party.
+			if edgeInfo.Info.AuthProof == nil {
+				remotePubKey := remotePubFromChanInfo(
+					edgeInfo.Info, edgeInfo.Edge.ChannelFlags,
+				)
+				err := d.reliableSender.sendMessage(
+					ctx, chanUpdate, remotePubKey,
+				)
+				if err != nil {
+					log.Errorf("Unable to reliably send %v "+
+						"for channel=%v to peer=%x: %v",
+						chanUpdate.MsgType(),
+						chanUpdate.ShortChannelID.Val,
+						remotePubKey, err)
+				}
+				continue
+			}
+
+			chanUpdates = append(chanUpdates, networkMsg{
+				source:   d.selfKey,
+				isRemote: false,
+				msg:      chanUpdate,
+			})
+
+			continue
+		}
+
 		// Now that we've collected all the channels we need to update,
 		// we'll re-sign and update the backing ChannelGraphSource, and
 		// retrieve our ChannelUpdate to broadcast.
@@ -2071,14 +2230,14 @@ func remotePubFromChanInfo(chanInfo *models.ChannelEdgeInfo,
 // to receive the remote peer's proof, while the remote peer is able to fully
 // assemble the proof and craft the ChannelAnnouncement.
 func (d *AuthenticatedGossiper) processRejectedEdge(_ context.Context,
-	chanAnnMsg *lnwire.ChannelAnnouncement1,
+	chanAnnMsg lnwire.ChannelAnnouncement,
 	proof *models.ChannelAuthProof) ([]networkMsg, error) {
 
+	scid := chanAnnMsg.SCID()
+
 	// First, we'll fetch the state of the channel as we know if from the
 	// database.
-	chanInfo, e1, e2, err := d.cfg.Graph.GetChannelByID(
-		chanAnnMsg.ShortChannelID,
-	)
+	chanInfo, e1, e2, err := d.cfg.Graph.GetChannelByID(scid)
 	if err != nil {
 		return nil, err
 	}
@@ -2097,9 +2256,17 @@ func (d *AuthenticatedGossiper) processRejectedEdge(_ context.Context,
 		return nil, nil
 	}
 
+	// The proof must be of the same gossip version as the edge it is
+	// meant to authenticate.
+	if proof.GossipVersion() != chanInfo.GossipVersion() {
+		return nil, fmt.Errorf("%v proof for %v edge with "+
+			"shortChanID=%v", proof.GossipVersion(),
+			chanInfo.GossipVersion(), scid)
+	}
+
 	// We'll then create then validate the new fully assembled
 	// announcement.
-	chanAnn, e1Ann, e2Ann, err := netann.CreateChanAnnouncement(
+	chanAnn, e1Ann, e2Ann, err := createChanAnnouncement(
 		proof, chanInfo, e1, e2,
 	)
 	if err != nil {
@@ -2108,18 +2275,17 @@ func (d *AuthenticatedGossiper) processRejectedEdge(_ context.Context,
 	err = netann.ValidateChannelAnn(chanAnn, d.fetchPKScript)
 	if err != nil {
 		err := fmt.Errorf("assembled channel announcement proof "+
-			"for shortChanID=%v isn't valid: %v",
-			chanAnnMsg.ShortChannelID, err)
+			"for shortChanID=%v isn't valid: %v", scid, err)
 		log.Error(err)
 		return nil, err
 	}
 
 	// If everything checks out, then we'll add the fully assembled proof
 	// to the database.
-	err = d.cfg.Graph.AddProof(chanAnnMsg.ShortChannelID, proof)
+	err = d.cfg.Graph.AddProof(scid, proof)
 	if err != nil {
 		err := fmt.Errorf("unable add proof to shortChanID=%v: %w",
-			chanAnnMsg.ShortChannelID, err)
+			scid, err)
 		log.Error(err)
 		return nil, err
 	}
@@ -2143,12 +2309,167 @@ func (d *AuthenticatedGossiper) processRejectedEdge(_ context.Context,
 			source: d.selfKey,
 			msg:    e2Ann,
 		})
-
 	}
 
 	return announcements, nil
 }
 
+// createChanAnnouncement re-creates the authenticated channel announcement of
+// the given edge, using the message version matching the edge, along with the
+// channel updates of each of the given policies that are known. The returned
+// updates are nil if the corresponding policy is nil.
+func createChanAnnouncement(proof *models.ChannelAuthProof,
+	chanInfo *models.ChannelEdgeInfo, e1,
+	e2 *models.ChannelEdgePolicy) (lnwire.ChannelAnnouncement,
+	lnwire.ChannelUpdate, lnwire.ChannelUpdate, error) {
+
+	var (
+		chanAnn      lnwire.ChannelAnnouncement
+		e1Upd, e2Upd lnwire.ChannelUpdate
+	)
+	switch chanInfo.GossipVersion() {
+	case lnwire.GossipVersion1:
+		ann, e1Ann, e2Ann, err := netann.CreateChanAnnouncement(
+			proof, chanInfo, e1, e2,
+		)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		// Only assign the updates that exist so that we don't return
+		// typed nil interfaces.
+		chanAnn = ann
+		if e1Ann != nil {
+			e1Upd = e1Ann
+		}
+		if e2Ann != nil {
+			e2Upd = e2Ann
+		}
+
+	case lnwire.GossipVersion2:
+		ann, e1Ann, e2Ann, err := netann.CreateChanAnnouncement2(
+			proof, chanInfo, e1, e2,
+		)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		chanAnn = ann
+		if e1Ann != nil {
+			e1Upd = e1Ann
+		}
+		if e2Ann != nil {
+			e2Upd = e2Ann
+		}
+
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown gossip version: %v",
+			chanInfo.GossipVersion())
+	}
+
+	return chanAnn, e1Upd, e2Upd, nil
+}
+
+// isSignedChanAnn2 returns true if the given announcement is a
+// channel_announcement_2 that carries a signature.
+func isSignedChanAnn2(ann lnwire.ChannelAnnouncement) bool {
+	ann2, ok := ann.(*lnwire.ChannelAnnouncement2)
+
+	return ok && ann2.Signature.Val != lnwire.Sig{}
+}
+
+// chanAnnAuthProof extracts the authentication proof carried by the given
+// channel announcement.
+func chanAnnAuthProof(ann lnwire.ChannelAnnouncement) *models.ChannelAuthProof {
+	switch a := ann.(type) {
+	case *lnwire.ChannelAnnouncement1:
+		return &models.ChannelAuthProof{
+			NodeSig1Bytes:      a.NodeSig1.ToSignatureBytes(),
+			NodeSig2Bytes:      a.NodeSig2.ToSignatureBytes(),
+			FlokicoinSig1Bytes: a.FlokicoinSig1.ToSignatureBytes(),
+			FlokicoinSig2Bytes: a.FlokicoinSig2.ToSignatureBytes(),
+		}
+
+	case *lnwire.ChannelAnnouncement2:
+		return models.NewV2ChannelAuthProof(
+			a.Signature.Val.ToSignatureBytes(),
+		)
+
+	default:
+		return nil
+	}
+}
+
+// edgeInfoFromChanAnn builds the channel edge described by the given channel
+// announcement, authenticated by the given (optional) proof.
+func edgeInfoFromChanAnn(ann lnwire.ChannelAnnouncement,
+	proof *models.ChannelAuthProof) (*models.ChannelEdgeInfo, error) {
+
+	switch a := ann.(type) {
+	case *lnwire.ChannelAnnouncement1:
+		return &models.ChannelEdgeInfo{
+			ChannelID:          a.ShortChannelID.ToUint64(),
+			ChainHash:          a.ChainHash,
+			NodeKey1Bytes:      a.NodeID1,
+			NodeKey2Bytes:      a.NodeID2,
+			FlokicoinKey1Bytes: a.FlokicoinKey1,
+			FlokicoinKey2Bytes: a.FlokicoinKey2,
+			AuthProof:          proof,
+			Features: lnwire.NewFeatureVector(
+				a.Features, lnwire.Features,
+			),
+			ExtraOpaqueData: a.ExtraOpaqueData,
+		}, nil
+
+	case *lnwire.ChannelAnnouncement2:
+		// Any unknown signed fields are kept around in their TLV
+		// encoding so the announcement can be re-created later on.
+		extraData, err := a.ExtraSignedFields.ToOpaqueData()
+		if err != nil {
+			return nil, err
+		}
+
+		edge := &models.ChannelEdgeInfo{
+			Version:       lnwire.GossipVersion2,
+			ChannelID:     a.ShortChannelID.Val.ToUint64(),
+			ChainHash:     a.ChainHash.Val,
+			NodeKey1Bytes: a.NodeID1.Val,
+			NodeKey2Bytes: a.NodeID2.Val,
+			ChannelPoint:  wire.OutPoint(a.Outpoint.Val),
+			Capacity:      chainutil.Amount(a.Capacity.Val),
+			AuthProof:     proof,
+			Features: lnwire.NewFeatureVector(
+				&a.Features.Val, lnwire.Features,
+			),
+			ExtraOpaqueData: extraData,
+		}
+
+		a.FlokicoinKey1.WhenSome(
+			func(k tlv.RecordT[tlv.TlvType12, [33]byte]) {
+				edge.FlokicoinKey1Bytes = k.Val
+			},
+		)
+		a.FlokicoinKey2.WhenSome(
+			func(k tlv.RecordT[tlv.TlvType14, [33]byte]) {
+				edge.FlokicoinKey2Bytes = k.Val
+			},
+		)
+		a.MerkleRootHash.WhenSome(
+			func(r tlv.RecordT[tlv.TlvType16, [32]byte]) {
+				edge.MerkleRootHash = fn.Some(
+					chainhash.Hash(r.Val),
+				)
+			},
+		)
+
+		return edge, nil
+
+	default:
+		return nil, fmt.Errorf("unknown channel announcement type: %T",
+			ann)
+	}
+}
+
 // fetchPKScript fetches the output script for the given SCID.
 func (d *AuthenticatedGossiper) fetchPKScript(chanID lnwire.ShortChannelID) (
 	txscript.ScriptClass, chainutil.Address, error) {
@@ -2190,6 +2511,24 @@ func (d *AuthenticatedGossiper) addNode(ctx context.Context,
 	)
 }
 
+// addNode2 processes the given gossip v2 node announcement, and adds it to our
+// channel graph.
+func (d *AuthenticatedGossiper) addNode2(ctx context.Context,
+	msg *lnwire.NodeAnnouncement2, op ...batch.SchedulerOption) error {
+
+	if err := netann.ValidateNodeAnn2(msg); err != nil {
+		return fmt.Errorf("unable to validate node announcement: %w",
+			err)
+	}
+
+	node, err := models.NodeFromWireAnnouncement2(msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return d.cfg.Graph.AddNode(ctx, node, op...)
+}
+
 // isPremature decides whether a given network message has a block height+delta
 // value specified in the future. If so, the message will be added to the
 // future message map and be processed when the block height as reached.
@@ -2272,6 +2611,10 @@ func (d *AuthenticatedGossiper) processNetworkAnnouncement(ctx context.Context,
 	case *lnwire.NodeAnnouncement1:
 		return d.handleNodeAnnouncement(ctx, nMsg, msg, schedulerOp)
 
+	// A new gossip v2 node announcement has arrived.
+	case *lnwire.NodeAnnouncement2:
+		return d.handleNodeAnnouncement2(ctx, nMsg, msg, schedulerOp)
+
 	// A new channel announcement has arrived, this indicates the
 	// *creation* of a new channel within the network. This only advertises
 	// the existence of a channel and not yet the routing policies in
@@ -2279,12 +2622,21 @@ func (d *AuthenticatedGossiper) processNetworkAnnouncement(ctx context.Context,
 	case *lnwire.ChannelAnnouncement1:
 		return d.handleChanAnnouncement(ctx, nMsg, msg, schedulerOp...)
 
+	// A new gossip v2 channel announcement has arrived, which announces a
+	// taproot channel.
+	case *lnwire.ChannelAnnouncement2:
+		return d.handleChanAnnouncement(ctx, nMsg, msg, schedulerOp...)
+
 	// A new authenticated channel edge update has arrived. This indicates
 	// that the directional information for an already known channel has
 	// been updated.
 	case *lnwire.ChannelUpdate1:
 		return d.handleChanUpdate(ctx, nMsg, msg, schedulerOp)
 
+	// A new gossip v2 channel update has arrived for a taproot channel.
+	case *lnwire.ChannelUpdate2:
+		return d.handleChanUpdate2(ctx, nMsg, msg, schedulerOp)
+
 	// A new signature announcement has been received. This indicates
 	// willingness of nodes involved in the funding of a channel to
 	// announce this new channel to the rest of the world.
@@ -2305,11 +2657,11 @@ func (d *AuthenticatedGossiper) processNetworkAnnouncement(ctx context.Context,
 // should be inspected.
 func (d *AuthenticatedGossiper) processZombieUpdate(_ context.Context,
 	chanInfo *models.ChannelEdgeInfo, scid lnwire.ShortChannelID,
-	msg *lnwire.ChannelUpdate1) error {
+	msg lnwire.ChannelUpdate) error {
 
 	// The least-significant bit in the flag on the channel update tells us
 	// which edge is being updated.
-	isNode1 := msg.ChannelFlags&lnwire.ChanUpdateDirection == 0
+	isNode1 := msg.IsNode1()
 
 	// Since we've deemed the update as not stale above, before marking it
 	// live, we'll make sure it has been signed by the correct party. If we
@@ -2325,7 +2677,7 @@ func (d *AuthenticatedGossiper) processZombieUpdate(_ context.Context,
 	}
 	if pubKey == nil {
 		return fmt.Errorf("incorrect pubkey to resurrect zombie "+
-			"with chan_id=%v", msg.ShortChannelID)
+			"with chan_id=%v", msg.SCID())
 	}
 
 	err := netann.VerifyChannelUpdateSignature(msg, pubKey)
@@ -2348,13 +2700,13 @@ func (d *AuthenticatedGossiper) processZombieUpdate(_ context.Context,
 	case err != nil:
 		return fmt.Errorf("unable to remove edge with "+
 			"chan_id=%v from zombie index: %v",
-			msg.ShortChannelID, err)
+			msg.SCID(), err)
 
 	default:
 	}
 
 	log.Debugf("Removed edge with chan_id=%v from zombie "+
-		"index", msg.ShortChannelID)
+		"index", msg.SCID())
 
 	return nil
 }
@@ -2441,6 +2793,39 @@ func (d *AuthenticatedGossiper) isMsgStale(_ context.Context,
 		timestamp := time.Unix(int64(msg.Timestamp), 0)
 		return p.LastUpdate.After(timestamp)
 
+	case *lnwire.ChannelUpdate2:
+		_, p1, p2, err := d.cfg.Graph.GetChannelByID(
+			msg.ShortChannelID.Val,
+		)
+
+		// If the channel cannot be found, it is most likely a leftover
+		// message for a channel that was closed, so we can consider it
+		// stale.
+		if errors.Is(err, graphdb.ErrEdgeNotFound) {
+			return true
+		}
+		if err != nil {
+			log.Debugf("Unable to retrieve channel=%v from graph: "+
+				"%v", msg.ShortChannelID.Val, err)
+			return false
+		}
+
+		// Otherwise, we'll retrieve the correct policy that we
+		// currently have stored within our graph to check if this
+		// message is stale by comparing its block height.
+		p := p1
+		if !msg.IsNode1() {
+			p = p2
+		}
+
+		// If the policy is still unknown, then we can consider this
+		// policy fresh.
+		if p == nil {
+			return false
+		}
+
+		return p.BlockHeight > msg.BlockHeight.Val
+
 	default:
 		// We'll make sure to not mark any unsupported messages as stale
 		// to ensure they are not removed.
@@ -2538,6 +2923,82 @@ func (d *AuthenticatedGossiper) SyncManager() *SyncManager {
 	return d.syncMgr
 }
 
+// updateChannel2 is the gossip v2 counterpart of updateChannel. It creates a
+// new fully signed update for the v2 channel at the current block height, and
+// updates the underlying graph with the new state.
+func (d *AuthenticatedGossiper) updateChannel2(ctx context.Context,
+	info *models.ChannelEdgeInfo,
+	edge *models.ChannelEdgePolicy) (*lnwire.ChannelAnnouncement2,
+	*lnwire.ChannelUpdate2, error) {
+
+	// A v2 update is ordered by the block height it was signed at, so the
+	// new update must have a strictly greater height than the one it
+	// replaces for it to propagate.
+	d.Lock()
+	blockHeight := d.bestHeight
+	d.Unlock()
+	if blockHeight <= edge.BlockHeight {
+		blockHeight = edge.BlockHeight + 1
+	}
+	edge.BlockHeight = blockHeight
+
+	// The disabled bit of the channel flags may have been toggled by the
+	// caller, so we'll make sure the disable flags reflect it.
+	switch {
+	case !edge.IsDisabled():
+		edge.DisableFlags = 0
+
+	case edge.DisableFlags == 0:
+		edge.DisableFlags = lnwire.ChanUpdateDisableIncoming |
+			lnwire.ChanUpdateDisableOutgoing
+	}
+
+	chanUpdate, err := netann.UnsignedChannelUpdate2FromEdge(info, edge)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = netann.SignChannelUpdate2(
+		d.cfg.AnnSigner, d.selfKeyLoc, chanUpdate,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	edge.LastUpdate = time.Now()
+	edge.SigBytes = chanUpdate.Signature.Val.ToSignatureBytes()
+
+	// To ensure that our signature is valid, we'll verify it ourself
+	// before committing it to the slice returned.
+	err = netann.ValidateChannelUpdateAnn(
+		d.selfKey, info.Capacity, chanUpdate,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generated invalid channel "+
+			"update sig: %v", err)
+	}
+
+	// Finally, we'll write the new edge policy to disk.
+	if err := d.cfg.Graph.UpdateEdge(ctx, edge); err != nil {
+		return nil, nil, err
+	}
+
+	// We'll also create the original channel announcement so the two can
+	// be broadcast along side each other, but only if we have a full
+	// channel announcement for this channel.
+	var chanAnn *lnwire.ChannelAnnouncement2
+	if info.AuthProof != nil {
+		chanAnn, _, _, err = netann.CreateChanAnnouncement2(
+			info.AuthProof, info, nil, nil,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return chanAnn, chanUpdate, nil
+}
+
 // IsKeepAliveUpdate determines whether this channel update is considered a
 // keep-alive update based on the previous channel update processed for the same
 // direction.
@@ -2681,34 +3142,118 @@ func (d *AuthenticatedGossiper) handleNodeAnnouncement(ctx context.Context,
 	return announcements, true
 }
 
-// handleChanAnnouncement processes a new channel announcement.
-//
-//nolint:funlen
-func (d *AuthenticatedGossiper) handleChanAnnouncement(ctx context.Context,
-	nMsg *networkMsg, ann *lnwire.ChannelAnnouncement1,
-	ops ...batch.SchedulerOption) ([]networkMsg, bool) {
-
-	scid := ann.ShortChannelID
-	chainHash := d.cfg.ChainParams.GenesisHash
+// handleNodeAnnouncement2 processes a new gossip v2 node announcement. As
+// these announcements carry no timestamp, their age is determined by the
+// block height they were signed at.
+func (d *AuthenticatedGossiper) handleNodeAnnouncement2(ctx context.Context,
+	nMsg *networkMsg, nodeAnn *lnwire.NodeAnnouncement2,
+	ops []batch.SchedulerOption) ([]networkMsg, bool) {
 
-	log.Debugf("Processing ChannelAnnouncement1: peer=%v, short_chan_id=%v",
-		nMsg.peer, scid.ToUint64())
+	nodeID := route.Vertex(nodeAnn.NodeID.Val)
+	blockHeight := nodeAnn.BlockHeight.Val
 
-	// We'll ignore any channel announcements that target any chain other
-	// than the set of chains we know of.
-	if !bytes.Equal(ann.ChainHash[:], chainHash[:]) {
-		err := fmt.Errorf("ignoring ChannelAnnouncement1 from chain=%v"+
-			", gossiper on chain=%v", ann.ChainHash, chainHash)
-		log.Errorf("%v", err)
+	log.Debugf("Processing NodeAnnouncement2: peer=%v, block_height=%v, "+
+		"node=%v, source=%x", nMsg.peer, blockHeight, nodeID,
+		nMsg.source.SerializeCompressed())
 
-		key := newRejectCacheKey(
-			ann.GossipVersion(),
-			scid.ToUint64(),
-			sourceToPub(nMsg.source),
-		)
-		_, _ = d.recentRejects.Put(key, &cachedReject{})
+	// An announcement signed at a height beyond our knowledge of the
+	// chain tip can't be ordered against later ones yet, so we ignore it
+	// rather than let it shadow every announcement until then.
+	if bestHeight := d.latestHeight(); blockHeight > bestHeight {
+		log.Debugf("Ignoring NodeAnnouncement2 for node %v with block "+
+			"height %v beyond best height %v", nodeID, blockHeight,
+			bestHeight)
 
-		nMsg.err <- err
+		nMsg.err <- nil
+		return nil, false
+	}
+
+	// We'll quickly ask the router if it already has a newer update for
+	// this node so we can skip validating signatures if not required.
+	if d.cfg.Graph.IsStaleNodeV2(ctx, nodeID, blockHeight) {
+		log.Debugf("Skipped processing stale v2 node: %v", nodeID)
+		nMsg.err <- nil
+		return nil, true
+	}
+
+	if err := d.addNode2(ctx, nodeAnn, ops...); err != nil {
+		log.Debugf("Adding v2 node: %v got error: %v", nodeID, err)
+
+		if !graph.IsError(
+			err,
+			graph.ErrOutdated,
+			graph.ErrIgnored,
+		) {
+
+			log.Error(err)
+		}
+
+		nMsg.err <- err
+		return nil, false
+	}
+
+	// In order to ensure we don't leak unadvertised nodes, we'll make a
+	// quick check to ensure this node intends to publicly advertise itself
+	// to the network.
+	isPublic, err := d.cfg.Graph.IsPublicNode(nodeID)
+	if err != nil {
+		log.Errorf("Unable to determine if node %v is advertised: %v",
+			nodeID, err)
+		nMsg.err <- err
+		return nil, false
+	}
+
+	var announcements []networkMsg
+	if isPublic {
+		announcements = append(announcements, networkMsg{
+			peer:     nMsg.peer,
+			isRemote: nMsg.isRemote,
+			source:   nMsg.source,
+			msg:      nodeAnn,
+		})
+	} else {
+		log.Tracef("Skipping broadcasting v2 node announcement for "+
+			"%v due to being unadvertised", nodeID)
+	}
+
+	nMsg.err <- nil
+
+	log.Debugf("Processed NodeAnnouncement2: peer=%v, block_height=%v, "+
+		"node=%v, source=%x", nMsg.peer, blockHeight, nodeID,
+		nMsg.source.SerializeCompressed())
+
+	return announcements, true
+}
+
+// handleChanAnnouncement processes a new channel announcement.
+//
+//nolint:funlen
+func (d *AuthenticatedGossiper) handleChanAnnouncement(ctx context.Context,
+	nMsg *networkMsg, ann lnwire.ChannelAnnouncement,
+	ops ...batch.SchedulerOption) ([]networkMsg, bool) {
+
+	scid := ann.SCID()
+	chainHash := d.cfg.ChainParams.GenesisHash
+
+	log.Debugf("Processing %v: peer=%v, short_chan_id=%v", ann.MsgType(),
+		nMsg.peer, scid.ToUint64())
+
+	// We'll ignore any channel announcements that target any chain other
+	// than the set of chains we know of.
+	annChainHash := ann.GetChainHash()
+	if !bytes.Equal(annChainHash[:], chainHash[:]) {
+		err := fmt.Errorf("ignoring %v from chain=%v, gossiper on "+
+			"chain=%v", ann.MsgType(), annChainHash, chainHash)
+		log.Errorf("%v", err)
+
+		key := newRejectCacheKey(
+			ann.GossipVersion(),
+			scid.ToUint64(),
+			sourceToPub(nMsg.source),
+		)
+		_, _ = d.recentRejects.Put(key, &cachedReject{})
+
+		nMsg.err <- err
 		return nil, false
 	}
 
@@ -2744,8 +3289,11 @@ func (d *AuthenticatedGossiper) handleChanAnnouncement(ctx context.Context,
 	d.Unlock()
 
 	// At this point, we'll now ask the router if this is a zombie/known
-	// edge. If so we can skip all the processing below.
-	if d.cfg.Graph.IsKnownEdge(scid) {
+	// edge. If so we can skip all the processing below. Our own signed v2
+	// announcements are the exception, as they're expected to carry the
+	// proof for an edge that we've already added to the graph.
+	isLocalProof := !nMsg.isRemote && isSignedChanAnn2(ann)
+	if !isLocalProof && d.cfg.Graph.IsKnownEdge(scid) {
 		nMsg.err <- nil
 		return nil, true
 	}
@@ -2786,9 +3334,12 @@ func (d *AuthenticatedGossiper) handleChanAnnouncement(ctx context.Context,
 	}
 
 	// If this is a remote channel announcement, then we'll validate all
-	// the signatures within the proof as it should be well formed.
+	// the signatures within the proof as it should be well formed. Our
+	// own v2 announcements are also handed to us fully signed once the
+	// MuSig2 signing session with the remote peer has completed, so we
+	// treat those the same way.
 	var proof *models.ChannelAuthProof
-	if nMsg.isRemote {
+	if nMsg.isRemote || isSignedChanAnn2(ann) {
 		err := netann.ValidateChannelAnn(ann, d.fetchPKScript)
 		if err != nil {
 			err := fmt.Errorf("unable to validate announcement: "+
@@ -2809,28 +3360,18 @@ func (d *AuthenticatedGossiper) handleChanAnnouncement(ctx context.Context,
 		// If the proof checks out, then we'll save the proof itself to
 		// the database so we can fetch it later when gossiping with
 		// other nodes.
-		proof = &models.ChannelAuthProof{
-			NodeSig1Bytes:      ann.NodeSig1.ToSignatureBytes(),
-			NodeSig2Bytes:      ann.NodeSig2.ToSignatureBytes(),
-			FlokicoinSig1Bytes: ann.FlokicoinSig1.ToSignatureBytes(),
-			FlokicoinSig2Bytes: ann.FlokicoinSig2.ToSignatureBytes(),
-		}
+		proof = chanAnnAuthProof(ann)
 	}
 
 	// With the proof validated (if necessary), we can now store it within
 	// the database for our path finding and syncing needs.
-	edge := &models.ChannelEdgeInfo{
-		ChannelID:          scid.ToUint64(),
-		ChainHash:          ann.ChainHash,
-		NodeKey1Bytes:      ann.NodeID1,
-		NodeKey2Bytes:      ann.NodeID2,
-		FlokicoinKey1Bytes: ann.FlokicoinKey1,
-		FlokicoinKey2Bytes: ann.FlokicoinKey2,
-		AuthProof:          proof,
-		Features: lnwire.NewFeatureVector(
-			ann.Features, lnwire.Features,
-		),
-		ExtraOpaqueData: ann.ExtraOpaqueData,
+	edge, err := edgeInfoFromChanAnn(ann, proof)
+	if err != nil {
+		log.Errorf("Unable to parse %v for short_chan_id=%v: %v",
+			ann.MsgType(), scid.ToUint64(), err)
+
+		nMsg.err <- err
+		return nil, false
 	}
 
 	// If there were any optional message fields provided, we'll include
@@ -3076,9 +3617,9 @@ func (d *AuthenticatedGossiper) handleChanAnnouncement(ctx context.Context,
 			// Reprocess the message, making sure we return an
 			// error to the original caller in case the gossiper
 			// shuts down.
-			case *lnwire.ChannelUpdate1:
-				log.Debugf("Reprocessing ChannelUpdate for "+
-					"shortChanID=%v", scid.ToUint64())
+			case *lnwire.ChannelUpdate1, *lnwire.ChannelUpdate2:
+				log.Debugf("Reprocessing %v for shortChanID=%v",
+					msg.MsgType(), scid.ToUint64())
 
 				select {
 				case d.networkMsgs <- updMsg:
@@ -3111,7 +3652,7 @@ func (d *AuthenticatedGossiper) handleChanAnnouncement(ctx context.Context,
 
 	nMsg.err <- nil
 
-	log.Debugf("Processed ChannelAnnouncement1: peer=%v, short_chan_id=%v",
+	log.Debugf("Processed %v: peer=%v, short_chan_id=%v", ann.MsgType(),
 		nMsg.peer, scid.ToUint64())
 
 	return announcements, true
@@ -3298,32 +3839,7 @@ func (d *AuthenticatedGossiper) handleChanUpdate(ctx context.Context,
 		// since we don't have an edge in the graph and if the peer is
 		// not buggy, we should be able to use it once the gossiper
 		// receives the local announcement.
-		pMsg := &processedNetworkMsg{msg: nMsg}
-
-		earlyMsgs, err := d.prematureChannelUpdates.Get(shortChanID)
-		switch {
-		// Nothing in the cache yet, we can just directly insert this
-		// element.
-		case err == cache.ErrElementNotFound:
-			_, _ = d.prematureChannelUpdates.Put(
-				shortChanID, &cachedNetworkMsg{
-					msgs: []*processedNetworkMsg{pMsg},
-				})
-
-		// There's already something in the cache, so we'll combine the
-		// set of messages into a single value.
-		default:
-			msgs := earlyMsgs.msgs
-			msgs = append(msgs, pMsg)
-			_, _ = d.prematureChannelUpdates.Put(
-				shortChanID, &cachedNetworkMsg{
-					msgs: msgs,
-				})
-		}
-
-		log.Debugf("Got ChannelUpdate for edge not found in graph"+
-			"(shortChanID=%v), saving for reprocessing later",
-			shortChanID)
+		d.stashPrematureChanUpdate(nMsg, shortChanID)
 
 		// NOTE: We don't return anything on the error channel for this
 		// message, as we expect that will be done when this
@@ -3412,20 +3928,7 @@ func (d *AuthenticatedGossiper) handleChanUpdate(ctx context.Context,
 			// rate-limit only a single alias of the channel,
 			// instead of the whole channel.
 			baseScid := chanInfo.ChannelID
-			d.Lock()
-			rls, ok := d.chanUpdateRateLimiter[baseScid]
-			if !ok {
-				r := rate.Every(d.cfg.ChannelUpdateInterval)
-				b := d.cfg.MaxChannelUpdateBurst
-				rls = [2]*rate.Limiter{
-					rate.NewLimiter(r, b),
-					rate.NewLimiter(r, b),
-				}
-				d.chanUpdateRateLimiter[baseScid] = rls
-			}
-			d.Unlock()
-
-			if !rls[direction].Allow() {
+			if !d.allowChanUpdate(baseScid, direction) {
 				log.Debugf("Rate limiting update for channel "+
 					"%v from direction %x", shortChanID,
 					pubKey.SerializeCompressed())
@@ -3562,6 +4065,350 @@ func (d *AuthenticatedGossiper) handleChanUpdate(ctx context.Context,
 	return announcements, true
 }
 
+// handleChanUpdate2 processes a new gossip v2 channel update. As v2 updates
+// carry the block height at which they were signed rather than a timestamp,
+// their freshness is determined by comparing block heights instead.
+//
+//nolint:funlen
+func (d *AuthenticatedGossiper) handleChanUpdate2(ctx context.Context,
+	nMsg *networkMsg, upd *lnwire.ChannelUpdate2,
+	ops []batch.SchedulerOption) ([]networkMsg, bool) {
+
+	scid := upd.ShortChannelID.Val
+	shortChanID := scid.ToUint64()
+	blockHeight := upd.BlockHeight.Val
+
+	log.Debugf("Processing ChannelUpdate2: peer=%v, short_chan_id=%v, "+
+		"block_height=%v", nMsg.peer, shortChanID, blockHeight)
+
+	cacheReject := func() {
+		key := newRejectCacheKey(
+			upd.GossipVersion(), shortChanID,
+			sourceToPub(nMsg.source),
+		)
+		_, _ = d.recentRejects.Put(key, &cachedReject{})
+	}
+
+	// We'll ignore any channel updates that target any chain other than
+	// the set of chains we know of.
+	chainHash := d.cfg.ChainParams.GenesisHash
+	if upd.ChainHash.Val != *chainHash {
+		err := fmt.Errorf("ignoring ChannelUpdate2 from chain=%v, "+
+			"gossiper on chain=%v", upd.ChainHash.Val, chainHash)
+		log.Errorf("%v", err)
+
+		cacheReject()
+
+		nMsg.err <- err
+		return nil, false
+	}
+
+	// An update can't have been signed before the funding transaction of
+	// the channel was confirmed.
+	if blockHeight < scid.BlockHeight {
+		err := fmt.Errorf("rejecting ChannelUpdate2 for "+
+			"short_chan_id(%v) with block height %v below the "+
+			"funding height %v", shortChanID, blockHeight,
+			scid.BlockHeight)
+
+		// Only increase ban score for remote peers.
+		if nMsg.isRemote {
+			log.Warnf("Increasing ban score for peer=%v: %v",
+				nMsg.peer, err)
+
+			dcErr := d.handleBadPeer(nMsg.peer)
+			if dcErr != nil {
+				err = dcErr
+			}
+		}
+
+		nMsg.err <- err
+		return nil, false
+	}
+
+	// If the block height the update was signed at is beyond our
+	// knowledge of the chain tip, then we'll put the update in limbo to be
+	// processed once we advance forward in the chain. As the update's
+	// height is never below the funding height, this also covers the case
+	// of a premature channel.
+	d.Lock()
+	delta := blockHeight - scid.BlockHeight
+	if nMsg.isRemote && d.isPremature(scid, delta, nMsg) {
+		log.Warnf("Update announcement for short_chan_id(%v), is "+
+			"premature: advertises height %v, only height %v is "+
+			"known", shortChanID, blockHeight, d.bestHeight)
+		d.Unlock()
+		nMsg.err <- nil
+		return nil, false
+	}
+	d.Unlock()
+
+	// We make sure to obtain the mutex for this channel ID before we access
+	// the database. This ensures the state we read from the database has
+	// not changed between this point and when we call UpdateEdge() later.
+	d.channelMtx.Lock(shortChanID)
+	defer d.channelMtx.Unlock(shortChanID)
+
+	chanInfo, e1, e2, err := d.cfg.Graph.GetChannelByID(scid)
+	switch {
+	// No error, break.
+	case err == nil:
+		break
+
+	case errors.Is(err, graphdb.ErrZombieEdge):
+		err = d.processZombieUpdate(ctx, chanInfo, scid, upd)
+		if err != nil {
+			log.Debug(err)
+			nMsg.err <- err
+			return nil, false
+		}
+
+		// We'll fallthrough to ensure we stash the update until we
+		// receive its corresponding ChannelAnnouncement2.
+		fallthrough
+	case errors.Is(err, graphdb.ErrGraphNotFound),
+		errors.Is(err, graphdb.ErrGraphNoEdgesFound),
+		errors.Is(err, graphdb.ErrEdgeNotFound):
+
+		// As with v1 updates, the channel may not have been announced
+		// to us yet, so we'll hold on to the update and reprocess it
+		// once the announcement has been processed.
+		d.stashPrematureChanUpdate(nMsg, shortChanID)
+
+		// NOTE: We don't return anything on the error channel for this
+		// message, as we expect that will be done when this update is
+		// later reprocessed.
+		return nil, false
+
+	default:
+		err := fmt.Errorf("unable to validate channel update "+
+			"short_chan_id=%v: %v", shortChanID, err)
+		log.Error(err)
+		nMsg.err <- err
+
+		cacheReject()
+
+		return nil, false
+	}
+
+	// A v2 update can only be applied to a channel that was announced
+	// using gossip v2.
+	if chanInfo.GossipVersion() != lnwire.GossipVersion2 {
+		err := fmt.Errorf("ignoring ChannelUpdate2 for %v channel "+
+			"short_chan_id=%v", chanInfo.GossipVersion(),
+			shortChanID)
+		log.Debug(err)
+
+		cacheReject()
+
+		nMsg.err <- err
+		return nil, false
+	}
+
+	// The presence of the second_peer record tells us "which" side of the
+	// channels directed edge is being updated.
+	var (
+		pubKey       *crypto.PublicKey
+		edgeToUpdate *models.ChannelEdgePolicy
+		direction    lnwire.ChanUpdateChanFlags
+	)
+	if upd.IsNode1() {
+		pubKey, _ = chanInfo.NodeKey1()
+		edgeToUpdate = e1
+	} else {
+		pubKey, _ = chanInfo.NodeKey2()
+		edgeToUpdate = e2
+		direction = lnwire.ChanUpdateDirection
+	}
+
+	// Updates that aren't strictly newer than the policy we already have
+	// are stale, so we can exit early.
+	if edgeToUpdate != nil && blockHeight <= edgeToUpdate.BlockHeight {
+		log.Debugf("Ignored stale edge policy for short_chan_id(%v): "+
+			"peer=%v, block_height=%v, known_height=%v, "+
+			"is_remote=%v", shortChanID, nMsg.peer, blockHeight,
+			edgeToUpdate.BlockHeight, nMsg.isRemote)
+
+		nMsg.err <- nil
+		return nil, true
+	}
+
+	log.Debugf("Validating ChannelUpdate2: channel=%v, for node=%x, has "+
+		"edge policy=%v", chanInfo.ChannelID,
+		pubKey.SerializeCompressed(), edgeToUpdate != nil)
+
+	// Validate the channel update with the expected public key and channel
+	// capacity. In the case of an invalid channel update, we'll return an
+	// error to the caller and exit early.
+	err = netann.ValidateChannelUpdateAnn(pubKey, chanInfo.Capacity, upd)
+	if err != nil {
+		rErr := fmt.Errorf("unable to validate channel update "+
+			"announcement for short_chan_id=%v: %v", shortChanID,
+			err)
+
+		log.Error(rErr)
+		nMsg.err <- rErr
+		return nil, false
+	}
+
+	// If we have a previous version of the edge being updated, we'll want
+	// to rate limit its updates to prevent spam throughout the network.
+	if nMsg.isRemote && edgeToUpdate != nil &&
+		!d.allowChanUpdate(chanInfo.ChannelID, direction) {
+
+		log.Debugf("Rate limiting update for channel %v from "+
+			"direction %x", shortChanID,
+			pubKey.SerializeCompressed())
+		nMsg.err <- nil
+		return nil, false
+	}
+
+	// Any unknown signed fields are kept around in their TLV encoding so
+	// the update can be re-created later on.
+	extraData, err := upd.ExtraSignedFields.ToOpaqueData()
+	if err != nil {
+		nMsg.err <- err
+		return nil, false
+	}
+
+	// The disabled bit of the channel flags mirrors whether any of the
+	// disable flags are set, so the rest of the daemon can treat v1 and
+	// v2 policies alike. As v2 updates carry no timestamp, the time at
+	// which we accepted the update is used as the last update time.
+	chanFlags := direction
+	if upd.IsDisabled() {
+		chanFlags |= lnwire.ChanUpdateDisabled
+	}
+	feeRate := lnwire.MilliLoki(upd.FeeProportionalMillionths.Val)
+	update := &models.ChannelEdgePolicy{
+		Version:                   lnwire.GossipVersion2,
+		SigBytes:                  upd.Signature.Val.ToSignatureBytes(),
+		ChannelID:                 chanInfo.ChannelID,
+		LastUpdate:                time.Now(),
+		BlockHeight:               blockHeight,
+		DisableFlags:              upd.DisabledFlags.Val,
+		MessageFlags:              lnwire.ChanUpdateRequiredMaxHtlc,
+		ChannelFlags:              chanFlags,
+		TimeLockDelta:             upd.CLTVExpiryDelta.Val,
+		MinHTLC:                   upd.HTLCMinimumMsat.Val,
+		MaxHTLC:                   upd.HTLCMaximumMsat.Val,
+		FeeBaseMSat:               lnwire.MilliLoki(upd.FeeBaseMsat.Val),
+		FeeProportionalMillionths: feeRate,
+		InboundFee:                upd.InboundFee.ValOpt(),
+		ExtraOpaqueData:           extraData,
+	}
+
+	if err := d.cfg.Graph.UpdateEdge(ctx, update, ops...); err != nil {
+		if graph.IsError(err, graph.ErrOutdated, graph.ErrIgnored) {
+			log.Debugf("Update edge for short_chan_id(%v) got: %v",
+				shortChanID, err)
+		} else {
+			cacheReject()
+
+			log.Errorf("Update edge for short_chan_id(%v) got: %v",
+				shortChanID, err)
+		}
+
+		nMsg.err <- err
+		return nil, false
+	}
+
+	// If this is a local update without an AuthProof, the channel isn't
+	// announced (yet), so we'll only send the update to our channel
+	// counterparty.
+	if !nMsg.isRemote && chanInfo.AuthProof == nil {
+		remotePubKey := remotePubFromChanInfo(chanInfo, direction)
+
+		log.Debugf("The message %v has no AuthProof, sending the "+
+			"update to remote peer %x", upd.MsgType(), remotePubKey)
+
+		err := d.reliableSender.sendMessage(ctx, upd, remotePubKey)
+		if err != nil {
+			err := fmt.Errorf("unable to reliably send %v for "+
+				"channel=%v to peer=%x: %v", upd.MsgType(),
+				scid, remotePubKey, err)
+			nMsg.err <- err
+			return nil, false
+		}
+	}
+
+	// Channel update announcement was successfully processed and now it
+	// can be broadcast to the rest of the network if the channel has been
+	// announced.
+	var announcements []networkMsg
+	if chanInfo.AuthProof != nil {
+		announcements = append(announcements, networkMsg{
+			peer:     nMsg.peer,
+			source:   nMsg.source,
+			isRemote: nMsg.isRemote,
+			msg:      upd,
+		})
+	}
+
+	nMsg.err <- nil
+
+	log.Debugf("Processed ChannelUpdate2: peer=%v, short_chan_id=%v, "+
+		"block_height=%v", nMsg.peer, shortChanID, blockHeight)
+
+	return announcements, true
+}
+
+// stashPrematureChanUpdate stores a channel update for which we don't yet
+// know the channel, so it can be reprocessed once the channel announcement
+// has been processed.
+func (d *AuthenticatedGossiper) stashPrematureChanUpdate(nMsg *networkMsg,
+	shortChanID uint64) {
+
+	pMsg := &processedNetworkMsg{msg: nMsg}
+
+	earlyMsgs, err := d.prematureChannelUpdates.Get(shortChanID)
+	switch {
+	// Nothing in the cache yet, we can just directly insert this element.
+	case err == cache.ErrElementNotFound:
+		_, _ = d.prematureChannelUpdates.Put(
+			shortChanID, &cachedNetworkMsg{
+				msgs: []*processedNetworkMsg{pMsg},
+			})
+
+	// There's already something in the cache, so we'll combine the set of
+	// messages into a single value.
+	default:
+		msgs := earlyMsgs.msgs
+		msgs = append(msgs, pMsg)
+		_, _ = d.prematureChannelUpdates.Put(
+			shortChanID, &cachedNetworkMsg{
+				msgs: msgs,
+			})
+	}
+
+	log.Debugf("Got %v for edge not found in graph(shortChanID=%v), "+
+		"saving for reprocessing later", nMsg.msg.MsgType(),
+		shortChanID)
+}
+
+// allowChanUpdate returns true if a non keep-alive update for the given
+// direction of the channel is allowed by the channel update rate limiter. If
+// we haven't seen an update for this channel before, a rate limiter is
+// initialized for each direction.
+func (d *AuthenticatedGossiper) allowChanUpdate(baseScid uint64,
+	direction lnwire.ChanUpdateChanFlags) bool {
+
+	d.Lock()
+	rls, ok := d.chanUpdateRateLimiter[baseScid]
+	if !ok {
+		r := rate.Every(d.cfg.ChannelUpdateInterval)
+		b := d.cfg.MaxChannelUpdateBurst
+		rls = [2]*rate.Limiter{
+			rate.NewLimiter(r, b),
+			rate.NewLimiter(r, b),
+		}
+		d.chanUpdateRateLimiter[baseScid] = rls
+	}
+	d.Unlock()
+
+	return rls[direction].Allow()
+}
+
 // handleAnnSig processes a new announcement signatures message.
 //
 //nolint:funlen
@@ -3921,11 +4768,11 @@ func (d *AuthenticatedGossiper) ShouldDisconnect(pubkey *crypto.PublicKey) (
 // the channel announcement proof. The transaction's outpoint and value are
 // returned if we can glean them from the work done in this method.
 func (d *AuthenticatedGossiper) validateFundingTransaction(_ context.Context,
-	ann *lnwire.ChannelAnnouncement1,
+	ann lnwire.ChannelAnnouncement,
 	tapscriptRoot fn.Option[chainhash.Hash]) (wire.OutPoint, chainutil.Amount,
 	[]byte, error) {
 
-	scid := ann.ShortChannelID
+	scid := ann.SCID()
 
 	// Before we can add the channel to the channel graph, we need to obtain
 	// the full funding outpoint that's encoded within the channel ID.
@@ -3968,12 +4815,27 @@ func (d *AuthenticatedGossiper) validateFundingTransaction(_ context.Context,
 
 	// Recreate witness output to be sure that declared in channel edge
 	// bitcoin keys and channel value corresponds to the reality.
-	fundingPkScript, err := makeFundingScript(
-		ann.FlokicoinKey1[:], ann.FlokicoinKey2[:], ann.Features,
-		tapscriptRoot,
-	)
-	if err != nil {
-		return wire.OutPoint{}, 0, nil, err
+	var fundingPkScript []byte
+	switch a := ann.(type) {
+	case *lnwire.ChannelAnnouncement1:
+		fundingPkScript, err = makeFundingScript(
+			a.FlokicoinKey1[:], a.FlokicoinKey2[:], a.Features,
+			tapscriptRoot,
+		)
+		if err != nil {
+			return wire.OutPoint{}, 0, nil, err
+		}
+
+	case *lnwire.ChannelAnnouncement2:
+		fundingPkScript, err = fundingScript2(a, fundingTx)
+		if err != nil {
+			return wire.OutPoint{}, 0, nil, fmt.Errorf("%w: %w",
+				ErrInvalidFundingOutput, err)
+		}
+
+	default:
+		return wire.OutPoint{}, 0, nil, fmt.Errorf("unknown channel "+
+			"announcement type: %T", ann)
 	}
 
 	// Next we'll validate that this channel is actually well formed. If
@@ -4020,8 +4882,54 @@ func (d *AuthenticatedGossiper) validateFundingTransaction(_ context.Context,
 			ErrChannelSpent, scid.ToUint64(), fundingPoint, err)
 	}
 
-	return *fundingPoint, chainutil.Amount(chanUtxo.Value), fundingPkScript,
-		nil
+	// A v2 announcement carries the capacity of the channel, which may be
+	// lower than the value of the funding output but never higher.
+	capacity := chainutil.Amount(chanUtxo.Value)
+	if a, ok := ann.(*lnwire.ChannelAnnouncement2); ok {
+		annCapacity := chainutil.Amount(a.Capacity.Val)
+		if annCapacity > capacity {
+			return wire.OutPoint{}, 0, nil, fmt.Errorf("%w: "+
+				"announced capacity %v exceeds funding output "+
+				"value %v", ErrInvalidFundingOutput,
+				annCapacity, capacity)
+		}
+
+		capacity = annCapacity
+	}
+
+	return *fundingPoint, capacity, fundingPkScript, nil
+}
+
+// fundingScript2 returns the funding output script of the channel announced
+// by the given channel_announcement_2. As the funding keys are optional in
+// such an announcement, the script is taken from the funding transaction
+// itself. For signed announcements, the script has already been checked
+// against the announced keys by netann.ValidateChannelAnn.
+func fundingScript2(ann *lnwire.ChannelAnnouncement2,
+	fundingTx *wire.MsgTx) ([]byte, error) {
+
+	locator := &chanvalidate.ShortChanIDChanLocator{
+		ID: ann.ShortChannelID.Val,
+	}
+	txOut, outPoint, err := locator.Locate(fundingTx)
+	if err != nil {
+		return nil, err
+	}
+
+	annOutPoint := wire.OutPoint(ann.Outpoint.Val)
+	if *outPoint != annOutPoint {
+		return nil, fmt.Errorf("announced outpoint %v doesn't match "+
+			"funding outpoint %v", annOutPoint, outPoint)
+	}
+
+	switch class := txscript.GetScriptClass(txOut.PkScript); class {
+	case txscript.WitnessV1TaprootTy, txscript.WitnessV0ScriptHashTy:
+		return txOut.PkScript, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported funding output script "+
+			"class: %v", class)
+	}
 }
 
 // handleBadPeer takes a misbehaving peer and increases its ban score. Once
@@ -20,6 +20,7 @@ import (
 	"github.com/flokiorg/flnd/chainntnfs"
 	"github.com/flokiorg/flnd/channeldb"
 	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/graph"
 	graphdb "github.com/flokiorg/flnd/graph/db"
 	"github.com/flokiorg/flnd/graph/db/models"
@@ -34,12 +35,14 @@ import (
 	"github.com/flokiorg/flnd/netann"
 	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/flnd/ticker"
+	"github.com/flokiorg/flnd/tlv"
 	"github.com/flokiorg/flokicoin-neutrino/cache"
 	"github.com/flokiorg/go-flokicoin/chaincfg"
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/crypto"
 	"github.com/flokiorg/go-flokicoin/crypto/ecdsa"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr/musig2"
 	"github.com/flokiorg/go-flokicoin/wire"
 	tmock "github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -301,6 +304,9 @@ func (r *mockGraphSource) FetchNode(_ context.Context,
 	nodePub route.Vertex) (*models.Node, error) {
 
 	for _, node := range r.nodes {
+		if node.GossipVersion() != lnwire.GossipVersion1 {
+			continue
+		}
 		if bytes.Equal(nodePub[:], node.PubKeyBytes[:]) {
 			return &node, nil
 		}
@@ -318,6 +324,9 @@ func (r *mockGraphSource) IsStaleNode(_ context.Context,
 	defer r.mu.Unlock()
 
 	for _, node := range r.nodes {
+		if node.GossipVersion() != lnwire.GossipVersion1 {
+			continue
+		}
 		if node.PubKeyBytes == nodePub {
 			return node.LastUpdate.After(timestamp) ||
 				node.LastUpdate.Equal(timestamp)
@@ -338,6 +347,41 @@ func (r *mockGraphSource) IsStaleNode(_ context.Context,
 	return true
 }
 
+// IsStaleNodeV2 returns true if the graph source has a v2 node announcement
+// for the target node signed at the same or a later block height.
+func (r *mockGraphSource) IsStaleNodeV2(_ context.Context,
+	nodePub route.Vertex, blockHeight uint32) bool {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// As for v1 announcements, the node must already have a channel in
+	// the graph to not be considered stale.
+	var hasChannel bool
+	for _, info := range r.infos {
+		if info.NodeKey1Bytes == nodePub ||
+			info.NodeKey2Bytes == nodePub {
+
+			hasChannel = true
+			break
+		}
+	}
+	if !hasChannel {
+		return true
+	}
+
+	for _, node := range r.nodes {
+		if node.GossipVersion() != lnwire.GossipVersion2 {
+			continue
+		}
+		if node.PubKeyBytes == nodePub && node.BlockHeight >= blockHeight {
+			return true
+		}
+	}
+
+	return false
+}
+
 // IsPublicNode determines whether the given vertex is seen as a public node in
 // the graph from the graph's source node's point of view.
 func (r *mockGraphSource) IsPublicNode(node route.Vertex) (bool, error) {
@@ -1141,6 +1185,340 @@ func TestProcessAnnouncement(t *testing.T) {
 	}
 }
 
+// createRemoteChannelAnnouncement2 crafts a gossip v2 channel announcement
+// for a taproot channel between the two remote nodes, signed with the
+// aggregate of the node and funding keys of both parties. The chain backend
+// is primed to serve the taproot funding transaction of the channel.
+func (ctx *testCtx) createRemoteChannelAnnouncement2(
+	blockHeight uint32) (*lnwire.ChannelAnnouncement2, error) {
+
+	const capacity = 1000
+
+	pkScript, txOut, err := input.GenTaprootFundingScript(
+		bitcoinKeyPub1, bitcoinKeyPub2, capacity,
+		fn.None[chainhash.Hash](),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fundingTx := wire.NewMsgTx(2)
+	fundingTx.AddTxOut(txOut)
+	block := &wire.MsgBlock{
+		Transactions: []*wire.MsgTx{fundingTx},
+	}
+
+	ctx.chain.On("GetBlockHash", int64(blockHeight)).
+		Return(&chainhash.Hash{}, nil)
+	ctx.chain.On("GetBlock", tmock.Anything).Return(block, nil)
+	ctx.chain.On(
+		"GetUtxo", tmock.Anything, tmock.Anything, tmock.Anything,
+		tmock.Anything,
+	).Return(&wire.TxOut{Value: capacity, PkScript: pkScript}, nil)
+
+	scid := lnwire.ShortChannelID{BlockHeight: blockHeight}
+	ann := &lnwire.ChannelAnnouncement2{}
+	ann.ChainHash.Val = *chaincfg.MainNetParams.GenesisHash
+	ann.Features.Val = *lnwire.NewRawFeatureVector()
+	ann.ShortChannelID.Val = scid
+	ann.Capacity.Val = capacity
+	ann.Outpoint.Val = lnwire.OutPoint{Hash: fundingTx.TxHash()}
+
+	// The node IDs of a v2 announcement are sorted.
+	nodeKey1, nodeKey2 := remoteKeyPub1, remoteKeyPriv2.PubKey()
+	if bytes.Compare(nodeKey1.SerializeCompressed(),
+		nodeKey2.SerializeCompressed()) > 0 {
+
+		nodeKey1, nodeKey2 = nodeKey2, nodeKey1
+	}
+	copy(ann.NodeID1.Val[:], nodeKey1.SerializeCompressed())
+	copy(ann.NodeID2.Val[:], nodeKey2.SerializeCompressed())
+
+	var key1, key2 [33]byte
+	copy(key1[:], bitcoinKeyPub1.SerializeCompressed())
+	copy(key2[:], bitcoinKeyPub2.SerializeCompressed())
+	ann.FlokicoinKey1 = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType12](key1),
+	)
+	ann.FlokicoinKey2 = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType14](key2),
+	)
+
+	digest, err := netann.ChanAnn2DigestToSign(ann)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign the announcement with a 4-of-4 MuSig2 session over the sorted
+	// node and funding keys of both parties.
+	privKeys := []*crypto.PrivateKey{
+		remoteKeyPriv1, remoteKeyPriv2, bitcoinKeyPriv1,
+		bitcoinKeyPriv2,
+	}
+	pubKeys := make([]*crypto.PublicKey, len(privKeys))
+	nonces := make([]*musig2.Nonces, len(privKeys))
+	pubNonces := make([][musig2.PubNonceSize]byte, len(privKeys))
+	for i, privKey := range privKeys {
+		pubKeys[i] = privKey.PubKey()
+
+		nonces[i], err = musig2.GenNonces(
+			musig2.WithPublicKey(pubKeys[i]),
+		)
+		if err != nil {
+			return nil, err
+		}
+		pubNonces[i] = nonces[i].PubNonce
+	}
+
+	combinedNonce, err := musig2.AggregateNonces(pubNonces)
+	if err != nil {
+		return nil, err
+	}
+
+	partialSigs := make([]*musig2.PartialSignature, len(privKeys))
+	for i, privKey := range privKeys {
+		partialSigs[i], err = musig2.Sign(
+			nonces[i].SecNonce, privKey, combinedNonce, pubKeys,
+			*digest, musig2.WithSortedKeys(),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sig := musig2.CombineSigs(partialSigs[0].R, partialSigs)
+	ann.Signature.Val, err = lnwire.NewSigFromSignature(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	return ann, nil
+}
+
+// TestProcessAnnouncement2 checks that a gossip v2 channel announcement and
+// the channel updates for it are validated, added to the graph and
+// broadcast to the rest of the network.
+func TestProcessAnnouncement2(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	tCtx, err := createTestCtx(t, 0, false)
+	require.NoError(t, err, "can't create context")
+
+	nodePeer := &mockPeer{remoteKeyPriv1.PubKey(), nil, nil, atomic.Bool{}}
+
+	ca, err := tCtx.createRemoteChannelAnnouncement2(0)
+	require.NoError(t, err, "can't create channel announcement")
+
+	select {
+	case err = <-tCtx.gossiper.ProcessRemoteAnnouncement(ctx, ca, nodePeer):
+	case <-time.After(2 * time.Second):
+		t.Fatal("remote announcement not processed")
+	}
+	require.NoError(t, err, "can't process remote announcement")
+
+	select {
+	case msg := <-tCtx.broadcastedMessage:
+		require.IsType(t, &lnwire.ChannelAnnouncement2{}, msg.msg)
+	case <-time.After(2 * trickleDelay):
+		t.Fatal("announcement wasn't proceeded")
+	}
+
+	// The edge must have been stored as a v2 edge along with its proof.
+	tCtx.router.mu.Lock()
+	info, ok := tCtx.router.infos[ca.ShortChannelID.Val.ToUint64()]
+	tCtx.router.mu.Unlock()
+	require.True(t, ok, "edge wasn't added to router")
+	require.Equal(t, lnwire.GossipVersion2, info.GossipVersion())
+	require.NotNil(t, info.AuthProof)
+
+	// We'll then craft the channel policy of the remote party and send it
+	// to the gossiper as well.
+	upd := &lnwire.ChannelUpdate2{}
+	upd.ChainHash.Val = *chaincfg.MainNetParams.GenesisHash
+	upd.ShortChannelID.Val = ca.ShortChannelID.Val
+	upd.CLTVExpiryDelta.Val = 40
+	upd.HTLCMinimumMsat.Val = 100
+	upd.HTLCMaximumMsat.Val = 200
+	if !bytes.Equal(ca.NodeID1.Val[:],
+		remoteKeyPub1.SerializeCompressed()) {
+
+		upd.SecondPeer = tlv.SomeRecordT(
+			tlv.ZeroRecordT[tlv.TlvType8, lnwire.TrueBoolean](),
+		)
+	}
+
+	signer := &mock.SingleSigner{Privkey: remoteKeyPriv1}
+	err = netann.SignChannelUpdate2(signer, testKeyLoc, upd)
+	require.NoError(t, err, "can't sign update")
+
+	select {
+	case err = <-tCtx.gossiper.ProcessRemoteAnnouncement(
+		ctx, upd, nodePeer,
+	):
+	case <-time.After(2 * time.Second):
+		t.Fatal("remote announcement not processed")
+	}
+	require.NoError(t, err, "can't process remote announcement")
+
+	select {
+	case msg := <-tCtx.broadcastedMessage:
+		require.IsType(t, &lnwire.ChannelUpdate2{}, msg.msg)
+	case <-time.After(2 * trickleDelay):
+		t.Fatal("update wasn't proceeded")
+	}
+
+	tCtx.router.mu.Lock()
+	require.Len(t, tCtx.router.edges, 1, "edge update wasn't added")
+	tCtx.router.mu.Unlock()
+
+	// An update signed by the wrong node must be rejected.
+	upd.BlockHeight.Val++
+	err = netann.SignChannelUpdate2(
+		&mock.SingleSigner{Privkey: remoteKeyPriv2}, testKeyLoc, upd,
+	)
+	require.NoError(t, err, "can't sign update")
+
+	select {
+	case err = <-tCtx.gossiper.ProcessRemoteAnnouncement(
+		ctx, upd, nodePeer,
+	):
+	case <-time.After(2 * time.Second):
+		t.Fatal("remote announcement not processed")
+	}
+	require.Error(t, err)
+}
+
+// createNodeAnnouncement2 crafts a gossip v2 node announcement for the given
+// node, signed at the given block height.
+func createNodeAnnouncement2(priv *crypto.PrivateKey,
+	blockHeight uint32) (*lnwire.NodeAnnouncement2, error) {
+
+	ann := &lnwire.NodeAnnouncement2{}
+	ann.Features.Val = *testFeatures
+	ann.BlockHeight.Val = blockHeight
+	copy(ann.NodeID.Val[:], priv.PubKey().SerializeCompressed())
+	ann.Alias = tlv.SomeRecordT(tlv.NewRecordT[tlv.TlvType3](
+		lnwire.NodeAlias2("kek"),
+	))
+
+	signer := &mock.SingleSigner{Privkey: priv}
+	err := netann.SignNodeAnnouncement2(signer, testKeyLoc, ann)
+	if err != nil {
+		return nil, err
+	}
+
+	return ann, nil
+}
+
+// TestProcessNodeAnnouncement2 checks that gossip v2 node announcements of
+// nodes with a public channel are validated, added to the graph and broadcast,
+// and that they're ordered by the block height they were signed at.
+func TestProcessNodeAnnouncement2(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	const bestHeight = 10
+
+	tCtx, err := createTestCtx(t, bestHeight, false)
+	require.NoError(t, err, "can't create context")
+
+	nodePeer := &mockPeer{remoteKeyPriv1.PubKey(), nil, nil, atomic.Bool{}}
+
+	process := func(msg lnwire.Message) error {
+		select {
+		case err := <-tCtx.gossiper.ProcessRemoteAnnouncement(
+			ctx, msg, nodePeer,
+		):
+			return err
+
+		case <-time.After(2 * time.Second):
+			t.Fatal("remote announcement not processed")
+		}
+
+		return nil
+	}
+	assertNotBroadcast := func() {
+		t.Helper()
+
+		select {
+		case msg := <-tCtx.broadcastedMessage:
+			t.Fatalf("%T was broadcast", msg.msg)
+		case <-time.After(2 * trickleDelay):
+		}
+	}
+	v2Nodes := func() []models.Node {
+		tCtx.router.mu.Lock()
+		defer tCtx.router.mu.Unlock()
+
+		var nodes []models.Node
+		for _, node := range tCtx.router.nodes {
+			if node.GossipVersion() == lnwire.GossipVersion2 {
+				nodes = append(nodes, node)
+			}
+		}
+
+		return nodes
+	}
+
+	// Without a channel of the node in the graph, the announcement is
+	// ignored.
+	nodeAnn, err := createNodeAnnouncement2(remoteKeyPriv1, 5)
+	require.NoError(t, err)
+	require.NoError(t, process(nodeAnn))
+	assertNotBroadcast()
+	require.Empty(t, v2Nodes())
+
+	ca, err := tCtx.createRemoteChannelAnnouncement2(0)
+	require.NoError(t, err, "can't create channel announcement")
+	require.NoError(t, process(ca))
+
+	select {
+	case msg := <-tCtx.broadcastedMessage:
+		require.IsType(t, &lnwire.ChannelAnnouncement2{}, msg.msg)
+	case <-time.After(2 * trickleDelay):
+		t.Fatal("announcement wasn't proceeded")
+	}
+
+	// Now that the node has a public channel, its announcement is added
+	// to the graph and broadcast.
+	require.NoError(t, process(nodeAnn))
+
+	select {
+	case msg := <-tCtx.broadcastedMessage:
+		require.Equal(t, nodeAnn, msg.msg)
+	case <-time.After(2 * trickleDelay):
+		t.Fatal("node announcement wasn't proceeded")
+	}
+
+	nodes := v2Nodes()
+	require.Len(t, nodes, 1)
+	require.EqualValues(t, 5, nodes[0].BlockHeight)
+	require.Equal(t, fn.Some("kek"), nodes[0].Alias)
+
+	// The same announcement again is stale.
+	require.NoError(t, process(nodeAnn))
+	assertNotBroadcast()
+
+	// An announcement signed beyond our best height is ignored.
+	futureAnn, err := createNodeAnnouncement2(
+		remoteKeyPriv1, bestHeight+1,
+	)
+	require.NoError(t, err)
+	require.NoError(t, process(futureAnn))
+	assertNotBroadcast()
+	require.Len(t, v2Nodes(), 1)
+
+	// An announcement that doesn't match its signature is rejected.
+	badAnn, err := createNodeAnnouncement2(remoteKeyPriv1, 6)
+	require.NoError(t, err)
+	badAnn.Alias = tlv.SomeRecordT(tlv.NewRecordT[tlv.TlvType3](
+		lnwire.NodeAlias2("tampered"),
+	))
+	require.Error(t, process(badAnn))
+	assertNotBroadcast()
+}
+
 // TestPrematureAnnouncement checks that premature announcements are not
 // propagated to the router subsystem.
 func TestPrematureAnnouncement(t *testing.T) {
@@ -87,6 +87,8 @@ func msgShortChanID(msg lnwire.Message) (lnwire.ShortChannelID, error) {
 		shortChanID = msg.ShortChannelID
 	case *lnwire.ChannelUpdate1:
 		shortChanID = msg.ShortChannelID
+	case *lnwire.ChannelUpdate2:
+		shortChanID = msg.ShortChannelID.Val
 	default:
 		return shortChanID, ErrUnsupportedMessage
 	}
@@ -160,7 +162,7 @@ func (s *MessageStore) DeleteMessage(msg lnwire.Message,
 		// In the event that we're attempting to delete a ChannelUpdate
 		// from the store, we'll make sure that we're actually deleting
 		// the correct one as it can be overwritten.
-		if msg, ok := msg.(*lnwire.ChannelUpdate1); ok {
+		if msg, ok := msg.(lnwire.ChannelUpdate); ok {
 			// Deleting a value from a bucket that doesn't exist
 			// acts as a NOP, so we'll return if a message doesn't
 			// exist under this key.
@@ -174,15 +176,19 @@ func (s *MessageStore) DeleteMessage(msg lnwire.Message,
 				return err
 			}
 
-			// If the timestamps don't match, then the update stored
+			// If the ages don't match, then the update stored
 			// should be the latest one, so we'll avoid deleting it.
-			m, ok := dbMsg.(*lnwire.ChannelUpdate1)
+			m, ok := dbMsg.(lnwire.ChannelUpdate)
 			if !ok {
 				return fmt.Errorf("expected "+
-					"*lnwire.ChannelUpdate1, got: %T",
+					"lnwire.ChannelUpdate, got: %T",
 					dbMsg)
 			}
-			if msg.Timestamp != m.Timestamp {
+			cmp, err := msg.CmpAge(m)
+			if err != nil {
+				return err
+			}
+			if cmp != lnwire.EqualTo {
 				return nil
 			}
 		}
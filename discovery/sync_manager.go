@@ -660,6 +660,12 @@ func (m *SyncManager) createGossipSyncer(peer lnpeer.Peer) *GossipSyncer {
 	nodeID := route.Vertex(peer.PubKey())
 	log.Infof("Creating new GossipSyncer for peer=%x", nodeID[:])
 
+	// We'll only send the taproot (v2) gossip messages to peers that
+	// have signalled that they understand them.
+	remoteFeatures := peer.RemoteFeatures()
+	gossipV2 := remoteFeatures != nil &&
+		remoteFeatures.HasFeature(lnwire.GossipV2Optional)
+
 	encoding := lnwire.EncodingSortedPlain
 	s := newGossipSyncer(gossipSyncerCfg{
 		chainHash:     m.cfg.ChainHash,
@@ -680,6 +686,7 @@ func (m *SyncManager) createGossipSyncer(peer lnpeer.Peer) *GossipSyncer {
 		noTimestampQueryOption:   m.cfg.NoTimestampQueries,
 		isStillZombieChannel:     m.cfg.IsStillZombieChannel,
 		msgBytesPerSecond:        m.cfg.PeerMsgBytesPerSecond,
+		gossipV2:                 gossipV2,
 	}, m.gossipFilterSema)
 
 	// Gossip syncers are initialized by default in a PassiveSync type
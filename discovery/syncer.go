@@ -297,6 +297,11 @@ type gossipSyncerCfg struct {
 	// bytes/second that this gossip syncer can consume. Once we exceed this
 	// rate, message sending will block until we're below the rate.
 	msgBytesPerSecond uint64
+
+	// gossipV2 indicates that the remote peer has signalled support for
+	// the taproot (v2) gossip messages. If false, any v2 announcements or
+	// updates will be withheld from the peer.
+	gossipV2 bool
 }
 
 // GossipSyncer is a struct that handles synchronizing the channel graph state
@@ -1604,16 +1609,17 @@ func (g *GossipSyncer) FilterGossipMsgs(ctx context.Context,
 	// to quickly check if we should forward a chan ann, based on the known
 	// channel updates for a channel.
 	chanUpdateIndex := make(
-		map[lnwire.ShortChannelID][]*lnwire.ChannelUpdate1,
+		map[lnwire.ShortChannelID][]lnwire.ChannelUpdate,
 	)
 	for _, msg := range msgs {
-		chanUpdate, ok := msg.msg.(*lnwire.ChannelUpdate1)
+		chanUpdate, ok := msg.msg.(lnwire.ChannelUpdate)
 		if !ok {
 			continue
 		}
 
-		chanUpdateIndex[chanUpdate.ShortChannelID] = append(
-			chanUpdateIndex[chanUpdate.ShortChannelID], chanUpdate,
+		scid := chanUpdate.SCID()
+		chanUpdateIndex[scid] = append(
+			chanUpdateIndex[scid], chanUpdate,
 		)
 	}
 
@@ -1632,6 +1638,22 @@ func (g *GossipSyncer) FilterGossipMsgs(ctx context.Context,
 			(t.After(startTime) && t.Before(endTime))
 	}
 
+	// Channel updates of v2 channels don't carry a timestamp, but a block
+	// height instead. As the timestamp filter can't be applied to them,
+	// we'll treat them as fresh and always forward them.
+	updatePassesFilter := func(upd lnwire.ChannelUpdate) bool {
+		switch upd := upd.(type) {
+		case *lnwire.ChannelUpdate1:
+			return passesFilter(upd.Timestamp)
+
+		case *lnwire.ChannelUpdate2:
+			return true
+
+		default:
+			return false
+		}
+	}
+
 	msgsToSend := make([]lnwire.Message, 0, len(msgs))
 	for _, msg := range msgs {
 		// If the target peer is the peer that sent us this message,
@@ -1646,26 +1668,26 @@ func (g *GossipSyncer) FilterGossipMsgs(ctx context.Context,
 		// For each channel announcement message, we'll only send this
 		// message if the channel updates for the channel are between
 		// our time range.
-		case *lnwire.ChannelAnnouncement1:
+		case lnwire.ChannelAnnouncement:
 			// First, we'll check if the channel updates are in
 			// this message batch.
-			chanUpdates, ok := chanUpdateIndex[msg.ShortChannelID]
+			scid := msg.SCID()
+			chanUpdates, ok := chanUpdateIndex[scid]
 			if !ok {
 				// If not, we'll attempt to query the database
 				// to see if we know of the updates.
 				chanUpdates, err = g.cfg.channelSeries.FetchChanUpdates(
-					g.cfg.chainHash, msg.ShortChannelID,
+					g.cfg.chainHash, scid,
 				)
 				if err != nil {
 					log.Warnf("no channel updates found for "+
-						"short_chan_id=%v",
-						msg.ShortChannelID)
+						"short_chan_id=%v", scid)
 					continue
 				}
 			}
 
 			for _, chanUpdate := range chanUpdates {
-				if passesFilter(chanUpdate.Timestamp) {
+				if updatePassesFilter(chanUpdate) {
 					msgsToSend = append(msgsToSend, msg)
 					break
 				}
@@ -1677,8 +1699,8 @@ func (g *GossipSyncer) FilterGossipMsgs(ctx context.Context,
 
 		// For each channel update, we'll only send if it the timestamp
 		// is between our time range.
-		case *lnwire.ChannelUpdate1:
-			if passesFilter(msg.Timestamp) {
+		case lnwire.ChannelUpdate:
+			if updatePassesFilter(msg) {
 				msgsToSend = append(msgsToSend, msg)
 			}
 
@@ -1688,6 +1710,11 @@ func (g *GossipSyncer) FilterGossipMsgs(ctx context.Context,
 			if passesFilter(msg.Timestamp) {
 				msgsToSend = append(msgsToSend, msg)
 			}
+
+		// Like v2 channel updates, v2 node announcements carry a block
+		// height rather than a timestamp, so they're always sent.
+		case *lnwire.NodeAnnouncement2:
+			msgsToSend = append(msgsToSend, msg)
 		}
 	}
 
@@ -1917,6 +1944,16 @@ func (g *GossipSyncer) sendMsgRateLimited(ctx context.Context, sync bool,
 	msgs ...lnwire.Message) error {
 
 	for _, msg := range msgs {
+		// Peers that don't understand the v2 gossip messages would
+		// treat them as unknown, so we'll withhold them.
+		if !g.cfg.gossipV2 && isGossipV2Msg(msg) {
+			log.Tracef("GossipSyncer(%x): not sending %v to peer "+
+				"without gossip v2 support", g.cfg.peerPub[:],
+				msg.MsgType())
+
+			continue
+		}
+
 		err := maybeRateLimitMsg(
 			ctx, g.rateLimiter, g.cfg.peerPub, msg, g.cg.Done(),
 		)
@@ -1932,3 +1969,17 @@ func (g *GossipSyncer) sendMsgRateLimited(ctx context.Context, sync bool,
 
 	return nil
 }
+
+// isGossipV2Msg returns true if the given message is one of the taproot (v2)
+// gossip messages.
+func isGossipV2Msg(msg lnwire.Message) bool {
+	switch msg.(type) {
+	case *lnwire.ChannelAnnouncement2, *lnwire.ChannelUpdate2,
+		*lnwire.NodeAnnouncement2:
+
+		return true
+
+	default:
+		return false
+	}
+}
@@ -55,7 +55,7 @@ type mockChannelGraphTimeSeries struct {
 	annResp chan []lnwire.Message
 
 	updateReq  chan lnwire.ShortChannelID
-	updateResp chan []*lnwire.ChannelUpdate1
+	updateResp chan []lnwire.ChannelUpdate
 }
 
 func newMockChannelGraphTimeSeries(
@@ -77,7 +77,7 @@ func newMockChannelGraphTimeSeries(
 		annResp: make(chan []lnwire.Message, 1),
 
 		updateReq:  make(chan lnwire.ShortChannelID, 1),
-		updateResp: make(chan []*lnwire.ChannelUpdate1, 1),
+		updateResp: make(chan []lnwire.ChannelUpdate, 1),
 	}
 }
 
@@ -164,7 +164,7 @@ func (m *mockChannelGraphTimeSeries) FetchChanAnns(chain chainhash.Hash,
 	return <-m.annResp, nil
 }
 func (m *mockChannelGraphTimeSeries) FetchChanUpdates(chain chainhash.Hash,
-	shortChanID lnwire.ShortChannelID) ([]*lnwire.ChannelUpdate1, error) {
+	shortChanID lnwire.ShortChannelID) ([]lnwire.ChannelUpdate, error) {
 
 	m.updateReq <- shortChanID
 
@@ -496,8 +496,8 @@ func TestGossipSyncerFilterGossipMsgsAllInMemory(t *testing.T) {
 			}
 
 			// If so, then we'll send back the missing update.
-			chanSeries.updateResp <- []*lnwire.ChannelUpdate1{
-				{
+			chanSeries.updateResp <- []lnwire.ChannelUpdate{
+				&lnwire.ChannelUpdate1{
 					ShortChannelID: lnwire.NewShortChanIDFromInt(25),
 					Timestamp:      unixStamp(5),
 				},
@@ -538,6 +538,83 @@ func TestGossipSyncerFilterGossipMsgsAllInMemory(t *testing.T) {
 	}
 }
 
+// TestGossipSyncerFilterGossipMsgsV2 tests that v2 channel announcements and
+// updates are forwarded regardless of their lack of a timestamp, but only to
+// peers that support the v2 gossip messages.
+func TestGossipSyncerFilterGossipMsgsV2(t *testing.T) {
+	t.Parallel()
+
+	scid := lnwire.NewShortChanIDFromInt(10)
+	newMsgs := func() []msgWithSenders {
+		chanAnn := &lnwire.ChannelAnnouncement2{}
+		chanAnn.ShortChannelID.Val = scid
+
+		chanUpd := &lnwire.ChannelUpdate2{}
+		chanUpd.ShortChannelID.Val = scid
+		chanUpd.BlockHeight.Val = 100
+
+		return []msgWithSenders{{msg: chanAnn}, {msg: chanUpd}}
+	}
+
+	tests := []struct {
+		name     string
+		gossipV2 bool
+		numSent  int
+	}{
+		{
+			name:     "peer supports gossip v2",
+			gossipV2: true,
+			numSent:  2,
+		},
+		{
+			name:     "peer doesn't support gossip v2",
+			gossipV2: false,
+			numSent:  0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+
+			msgChan, syncer, _ := newTestSyncer(
+				lnwire.NewShortChanIDFromInt(10),
+				defaultEncoding, defaultChunkSize,
+			)
+			syncer.cfg.gossipV2 = test.gossipV2
+			syncer.remoteUpdateHorizon = &lnwire.GossipTimestampRange{
+				FirstTimestamp: unixStamp(25000),
+				TimestampRange: uint32(1000),
+			}
+
+			syncer.FilterGossipMsgs(ctx, newMsgs()...)
+
+			var msgReceived []lnwire.Message
+			for len(msgReceived) < test.numSent {
+				select {
+				case msgs := <-msgChan:
+					msgReceived = append(
+						msgReceived, msgs...,
+					)
+
+				case <-time.After(time.Second):
+					t.Fatalf("timeout receiving msgs, "+
+						"got %v", len(msgReceived))
+				}
+			}
+
+			select {
+			case msgs := <-msgChan:
+				t.Fatalf("received unexpected msgs: %v",
+					spew.Sdump(msgs))
+
+			case <-time.After(time.Millisecond * 10):
+			}
+		})
+	}
+}
+
 // TestGossipSyncerApplyNoHistoricalGossipFilter tests that once a gossip filter
 // is applied for the remote peer, then we don't send the peer all known
 // messages which are within their desired time horizon.
@@ -173,19 +173,22 @@ func (v *ValidationBarrier) InitJobDependencies(job interface{}) (JobID,
 	// Once a slot is open, we'll examine the message of the job, to see if
 	// there need to be any dependent barriers set up.
 	switch msg := job.(type) {
-	case *lnwire.ChannelAnnouncement1:
+	case lnwire.ChannelAnnouncement:
 		id := JobID(v.idCtr.Add(1))
 
-		updateOrCreateJobInfo(msg.ShortChannelID.String(), id)
-		updateOrCreateJobInfo(route.Vertex(msg.NodeID1).String(), id)
-		updateOrCreateJobInfo(route.Vertex(msg.NodeID2).String(), id)
+		node1 := route.Vertex(msg.Node1KeyBytes())
+		node2 := route.Vertex(msg.Node2KeyBytes())
+
+		updateOrCreateJobInfo(msg.SCID().String(), id)
+		updateOrCreateJobInfo(node1.String(), id)
+		updateOrCreateJobInfo(node2.String(), id)
 
 		return id, nil
 
 	// Populate the dependency mappings for the below child jobs.
-	case *lnwire.ChannelUpdate1:
+	case lnwire.ChannelUpdate:
 		childJobID := JobID(v.idCtr.Add(1))
-		populateDependencies(msg.ShortChannelID.String(), childJobID)
+		populateDependencies(msg.SCID().String(), childJobID)
 
 		return childJobID, nil
 	case *lnwire.NodeAnnouncement1:
@@ -194,6 +197,13 @@ func (v *ValidationBarrier) InitJobDependencies(job interface{}) (JobID,
 			route.Vertex(msg.NodeID).String(), childJobID,
 		)
 
+		return childJobID, nil
+	case *lnwire.NodeAnnouncement2:
+		childJobID := JobID(v.idCtr.Add(1))
+		populateDependencies(
+			route.Vertex(msg.NodeID.Val).String(), childJobID,
+		)
+
 		return childJobID, nil
 	case *lnwire.AnnounceSignatures1:
 		// TODO(roasbeef): need to wait on chan ann?
@@ -244,8 +254,8 @@ func (v *ValidationBarrier) WaitForParents(childJobID JobID,
 	switch msg := job.(type) {
 	// Any ChannelUpdate or NodeAnnouncement1 jobs will need to wait on the
 	// completion of any active ChannelAnnouncement jobs related to them.
-	case *lnwire.ChannelUpdate1:
-		annID = msg.ShortChannelID.String()
+	case lnwire.ChannelUpdate:
+		annID = msg.SCID().String()
 
 		parentJobIDs, ok = v.jobDependencies[childJobID]
 		if !ok {
@@ -256,7 +266,7 @@ func (v *ValidationBarrier) WaitForParents(childJobID JobID,
 		}
 
 		jobDesc = fmt.Sprintf("job=lnwire.ChannelUpdate, scid=%v",
-			msg.ShortChannelID.ToUint64())
+			msg.SCID().ToUint64())
 
 	case *lnwire.NodeAnnouncement1:
 		annID = route.Vertex(msg.NodeID).String()
@@ -272,6 +282,20 @@ func (v *ValidationBarrier) WaitForParents(childJobID JobID,
 		jobDesc = fmt.Sprintf("job=lnwire.NodeAnnouncement1, pub=%s",
 			route.Vertex(msg.NodeID))
 
+	case *lnwire.NodeAnnouncement2:
+		annID = route.Vertex(msg.NodeID.Val).String()
+
+		parentJobIDs, ok = v.jobDependencies[childJobID]
+		if !ok {
+			// If ok is false, it means that this child job never
+			// had any parent jobs to wait on.
+			v.Unlock()
+			return nil
+		}
+
+		jobDesc = fmt.Sprintf("job=lnwire.NodeAnnouncement2, pub=%s",
+			route.Vertex(msg.NodeID.Val))
+
 	// Other types of jobs can be executed immediately, so we'll just
 	// return directly.
 	case *lnwire.AnnounceSignatures1:
@@ -279,7 +303,7 @@ func (v *ValidationBarrier) WaitForParents(childJobID JobID,
 		v.Unlock()
 		return nil
 
-	case *lnwire.ChannelAnnouncement1:
+	case lnwire.ChannelAnnouncement:
 		v.Unlock()
 		return nil
 	}
@@ -425,21 +449,23 @@ func (v *ValidationBarrier) SignalDependents(job interface{}, id JobID) error {
 	}
 
 	switch msg := job.(type) {
-	case *lnwire.ChannelAnnouncement1:
+	case lnwire.ChannelAnnouncement:
 		// Signal to the child jobs that parent validation has
 		// finished. We have to call removeJob for each annID
 		// that this ChannelAnnouncement can be associated with.
-		err := removeJob(msg.ShortChannelID.String(), id, false)
+		err := removeJob(msg.SCID().String(), id, false)
 		if err != nil {
 			return err
 		}
 
-		err = removeJob(route.Vertex(msg.NodeID1).String(), id, false)
+		node1 := route.Vertex(msg.Node1KeyBytes())
+		err = removeJob(node1.String(), id, false)
 		if err != nil {
 			return err
 		}
 
-		err = removeJob(route.Vertex(msg.NodeID2).String(), id, false)
+		node2 := route.Vertex(msg.Node2KeyBytes())
+		err = removeJob(node2.String(), id, false)
 		if err != nil {
 			return err
 		}
@@ -450,9 +476,15 @@ func (v *ValidationBarrier) SignalDependents(job interface{}, id JobID) error {
 		// Remove child job info.
 		return removeJob(route.Vertex(msg.NodeID).String(), id, true)
 
-	case *lnwire.ChannelUpdate1:
+	case *lnwire.NodeAnnouncement2:
+		// Remove child job info.
+		return removeJob(
+			route.Vertex(msg.NodeID.Val).String(), id, true,
+		)
+
+	case lnwire.ChannelUpdate:
 		// Remove child job info.
-		return removeJob(msg.ShortChannelID.String(), id, true)
+		return removeJob(msg.SCID().String(), id, true)
 
 	case *lnwire.AnnounceSignatures1:
 		// No dependency mappings are stored for AnnounceSignatures1,
@@ -0,0 +1,420 @@
+package electrumio
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flokiorg/flokicoin-neutrino/cache/lru"
+	"github.com/flokiorg/go-flokicoin/blockchain"
+	"github.com/flokiorg/go-flokicoin/chaincfg"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/flokiorg/walletd/chain/electrum"
+)
+
+const (
+	// blockHeightCacheSize is the number of block hash to height mappings
+	// we keep around. Electrum servers can only be queried for blocks by
+	// height, so we remember the heights of the blocks we've seen in order
+	// to serve lookups by hash.
+	blockHeightCacheSize = 10000
+)
+
+var (
+	// ErrUnknownBlock is returned when a block is looked up by a hash that
+	// we haven't seen yet. Electrum servers can only be queried for blocks
+	// by height, so we can only serve blocks we've already looked up by
+	// height.
+	ErrUnknownBlock = errors.New("unknown block hash")
+)
+
+// cachedHeight is a block height stored in the block height cache.
+type cachedHeight uint32
+
+// Size returns the "size" of an entry. We return 1 as we just want to limit
+// the total number of entries rather than do accurate size accounting.
+func (c cachedHeight) Size() (uint64, error) {
+	return 1, nil
+}
+
+// BlockTx is a transaction that's been confirmed in the main chain.
+type BlockTx struct {
+	// Tx is the confirmed transaction.
+	Tx *wire.MsgTx
+
+	// BlockHeight is the height of the block the transaction was
+	// confirmed in.
+	BlockHeight uint32
+
+	// TxIndex is the index of the transaction within its block.
+	TxIndex uint32
+}
+
+// Backend wraps a connection to an electrum server with the queries the
+// electrum chain backend is built upon.
+type Backend struct {
+	client      *electrum.Client
+	chainParams *chaincfg.Params
+	timeout     time.Duration
+
+	// heights maps the hashes of the blocks we've looked up to their
+	// height.
+	heights *lru.Cache[chainhash.Hash, cachedHeight]
+}
+
+// NewBackend creates a new Backend for the given chain from a started
+// electrum client. Every request made to the server is given the passed
+// timeout to complete.
+func NewBackend(client *electrum.Client, chainParams *chaincfg.Params,
+	timeout time.Duration) *Backend {
+
+	return &Backend{
+		client:      client,
+		chainParams: chainParams,
+		timeout:     timeout,
+		heights: lru.NewCache[chainhash.Hash, cachedHeight](
+			blockHeightCacheSize,
+		),
+	}
+}
+
+// Client returns the electrum client the backend is using.
+func (b *Backend) Client() *electrum.Client {
+	return b.client
+}
+
+// reqContext returns a context that times out after the backend's request
+// timeout.
+func (b *Backend) reqContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), b.timeout)
+}
+
+// ScriptHash returns the electrum script hash of an output script, which is
+// the hex encoding of the reversed sha256 of the script.
+func ScriptHash(pkScript []byte) string {
+	hash := sha256.Sum256(pkScript)
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+
+	return hex.EncodeToString(hash[:])
+}
+
+// Ping checks that the electrum server is still responsive.
+func (b *Backend) Ping() error {
+	ctx, cancel := b.reqContext()
+	defer cancel()
+
+	return b.client.Ping(ctx)
+}
+
+// BestBlock returns the hash and height of the tip of the server's main
+// chain.
+func (b *Backend) BestBlock() (*chainhash.Hash, int32, error) {
+	ctx, cancel := b.reqContext()
+	defer cancel()
+
+	// We only use the height of the result, as the hash is computed over
+	// the raw header the server returns, which may carry an AuxPoW
+	// payload.
+	_, height, err := b.client.GetBestBlock(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hash, _, err := b.BlockHeader(uint32(height))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return hash, height, nil
+}
+
+// BlockHeader returns the hash and header of the main chain block at the
+// given height. The header must carry valid proof-of-work for the target it
+// claims.
+func (b *Backend) BlockHeader(height uint32) (*chainhash.Hash,
+	*wire.BlockHeader, error) {
+
+	ctx, cancel := b.reqContext()
+	defer cancel()
+
+	hash, header, err := b.client.GetBlockHash(ctx, height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get header of block "+
+			"at height %d: %w", height, err)
+	}
+
+	// The proof-of-work check only looks at the block's header.
+	block := chainutil.NewBlock(&wire.MsgBlock{Header: *header})
+	err = blockchain.CheckProofOfWork(block, b.chainParams.PowLimit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid proof-of-work for block "+
+			"%v at height %d: %w", hash, height, err)
+	}
+
+	_, _ = b.heights.Put(*hash, cachedHeight(height))
+
+	return hash, header, nil
+}
+
+// BlockHeight returns the height of a block we've previously looked up by
+// height. ErrUnknownBlock is returned for any other block.
+func (b *Backend) BlockHeight(hash *chainhash.Hash) (uint32, error) {
+	height, err := b.heights.Get(*hash)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrUnknownBlock, hash)
+	}
+
+	return uint32(height), nil
+}
+
+// Transaction returns the transaction with the given hash.
+func (b *Backend) Transaction(txid *chainhash.Hash) (*wire.MsgTx, error) {
+	ctx, cancel := b.reqContext()
+	defer cancel()
+
+	rawTx, err := b.client.GetRawTransaction(ctx, txid.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to get transaction %v: %w", txid,
+			err)
+	}
+
+	txBytes, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &wire.MsgTx{}
+	if err := tx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return nil, err
+	}
+
+	if tx.TxHash() != *txid {
+		return nil, fmt.Errorf("transaction %v hashes to %v", txid,
+			tx.TxHash())
+	}
+
+	return tx, nil
+}
+
+// BlockTransaction returns the transaction at the given index of the main
+// chain block at the given height.
+func (b *Backend) BlockTransaction(height, txIndex uint32) (*wire.MsgTx,
+	error) {
+
+	ctx, cancel := b.reqContext()
+	defer cancel()
+
+	// We ask for the merkle branch as well, so we can check that the
+	// server didn't make up the transaction.
+	res, err := b.client.GetMerkleProofFromPosition(ctx, height, txIndex)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get transaction %d of block "+
+			"at height %d: %w", txIndex, height, err)
+	}
+
+	txid, err := chainhash.NewHashFromStr(res.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.verifyMerkleBranch(txid, height, txIndex, res.Merkle)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Transaction(txid)
+}
+
+// verifyMerkleBranch checks that the given merkle branch proves that the
+// transaction is at the given index of the main chain block at the given
+// height, by connecting it to the merkle root of the block's header.
+func (b *Backend) verifyMerkleBranch(txid *chainhash.Hash, height,
+	txIndex uint32, branch []string) error {
+
+	_, header, err := b.BlockHeader(height)
+	if err != nil {
+		return err
+	}
+
+	// The bits of the index tell at each level of the tree whether the
+	// node we've computed so far is the left or the right child.
+	root := *txid
+	for i, hexHash := range branch {
+		sibling, err := chainhash.NewHashFromStr(hexHash)
+		if err != nil {
+			return err
+		}
+
+		if (txIndex>>uint(i))&1 == 0 {
+			root = blockchain.HashMerkleBranches(&root, sibling)
+		} else {
+			root = blockchain.HashMerkleBranches(sibling, &root)
+		}
+	}
+
+	// The branch must also be as deep as the tree, so that the index
+	// doesn't point past it.
+	if txIndex>>uint(len(branch)) != 0 || root != header.MerkleRoot {
+		return fmt.Errorf("invalid merkle branch for transaction %v "+
+			"at index %d of block at height %d", txid, txIndex,
+			height)
+	}
+
+	return nil
+}
+
+// txIndex returns the index of a transaction within the block at the given
+// height.
+func (b *Backend) txIndex(txid *chainhash.Hash, height uint32) (uint32,
+	error) {
+
+	ctx, cancel := b.reqContext()
+	defer cancel()
+
+	proof, err := b.client.GetMerkleProof(ctx, txid.String(), height)
+	if err != nil {
+		return 0, fmt.Errorf("unable to get merkle proof of %v: %w",
+			txid, err)
+	}
+
+	err = b.verifyMerkleBranch(txid, height, proof.Position, proof.Merkle)
+	if err != nil {
+		return 0, err
+	}
+
+	return proof.Position, nil
+}
+
+// ScriptHistory returns the txids of the transactions that pay to or spend
+// from the given output script, along with the height they were confirmed
+// at. Unconfirmed transactions have a height of zero or below.
+func (b *Backend) ScriptHistory(pkScript []byte) (
+	map[chainhash.Hash]int32, error) {
+
+	ctx, cancel := b.reqContext()
+	defer cancel()
+
+	history, err := b.client.GetHistory(ctx, ScriptHash(pkScript))
+	if err != nil {
+		return nil, fmt.Errorf("unable to get script history: %w", err)
+	}
+
+	txids := make(map[chainhash.Hash]int32, len(history))
+	for _, entry := range history {
+		txid, err := chainhash.NewHashFromStr(entry.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		txids[*txid] = entry.Height
+	}
+
+	return txids, nil
+}
+
+// ScriptTxs returns the transactions that pay to or spend from the given
+// output script and were confirmed in the main chain between the start and
+// end heights, inclusive. The transactions are sorted by the order they were
+// confirmed in.
+func (b *Backend) ScriptTxs(pkScript []byte, startHeight,
+	endHeight uint32) ([]*BlockTx, error) {
+
+	history, err := b.ScriptHistory(pkScript)
+	if err != nil {
+		return nil, err
+	}
+
+	var txs []*BlockTx
+	for txid, height := range history {
+		if height <= 0 || uint32(height) < startHeight ||
+			uint32(height) > endHeight {
+
+			continue
+		}
+
+		tx, err := b.Transaction(&txid)
+		if err != nil {
+			return nil, err
+		}
+
+		txIndex, err := b.txIndex(&txid, uint32(height))
+		if err != nil {
+			return nil, err
+		}
+
+		txs = append(txs, &BlockTx{
+			Tx:          tx,
+			BlockHeight: uint32(height),
+			TxIndex:     txIndex,
+		})
+	}
+
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].BlockHeight != txs[j].BlockHeight {
+			return txs[i].BlockHeight < txs[j].BlockHeight
+		}
+
+		return txs[i].TxIndex < txs[j].TxIndex
+	})
+
+	return txs, nil
+}
+
+// ScriptsTxs returns the transactions that pay to or spend from any of the
+// given output scripts and were confirmed in the main chain between the start
+// and end heights, inclusive. Transactions touching several of the scripts
+// are only returned once.
+func (b *Backend) ScriptsTxs(pkScripts [][]byte, startHeight,
+	endHeight uint32) ([]*BlockTx, error) {
+
+	seen := make(map[chainhash.Hash]struct{})
+
+	var txs []*BlockTx
+	for _, pkScript := range pkScripts {
+		scriptTxs, err := b.ScriptTxs(pkScript, startHeight, endHeight)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range scriptTxs {
+			txid := tx.Tx.TxHash()
+			if _, ok := seen[txid]; ok {
+				continue
+			}
+			seen[txid] = struct{}{}
+
+			txs = append(txs, tx)
+		}
+	}
+
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].BlockHeight != txs[j].BlockHeight {
+			return txs[i].BlockHeight < txs[j].BlockHeight
+		}
+
+		return txs[i].TxIndex < txs[j].TxIndex
+	})
+
+	return txs, nil
+}
+
+// SubscribeScript subscribes to status updates of the given output script
+// through the passed subscription. The server will send an update each time
+// a transaction paying to or spending from the script is seen.
+func (b *Backend) SubscribeScript(sub *electrum.ScripthashSubscription,
+	pkScript []byte) error {
+
+	ctx, cancel := b.reqContext()
+	defer cancel()
+
+	return sub.Add(ctx, ScriptHash(pkScript))
+}
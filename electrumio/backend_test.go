@@ -0,0 +1,70 @@
+package electrumio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/electrumio/electrumtest"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/flokiorg/walletd/chain/electrum"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the time we wait for the stub server to answer a request.
+const testTimeout = 10 * time.Second
+
+// newTestBackend returns a backend connected to the given stub server.
+func newTestBackend(t *testing.T, server *electrumtest.Server) *Backend {
+	t.Helper()
+
+	client := electrum.NewClient(server.Addr(), nil)
+	require.NoError(t, client.Start(t.Context()))
+
+	t.Cleanup(func() {
+		server.Disconnect()
+
+		select {
+		case <-client.Error:
+		case <-time.After(testTimeout):
+			t.Fatal("electrum client not disconnected")
+		}
+	})
+
+	return NewBackend(client, electrumtest.ChainParams, testTimeout)
+}
+
+// newTestTx returns a transaction spending the given dummy outpoint index.
+func newTestTx(index uint32) *wire.MsgTx {
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: index},
+	})
+	tx.AddTxOut(&wire.TxOut{Value: 1000, PkScript: []byte{0x51}})
+
+	return tx
+}
+
+// TestBlockTransaction asserts that the transactions of a block are only
+// returned if the server proves their inclusion in the block.
+func TestBlockTransaction(t *testing.T) {
+	t.Parallel()
+
+	server := electrumtest.NewServer(t)
+	backend := newTestBackend(t, server)
+
+	txs := []*wire.MsgTx{newTestTx(0), newTestTx(1), newTestTx(2)}
+	server.MineBlock(txs...)
+	_, height := server.BestBlock()
+
+	// The transactions follow the coinbase in the block.
+	for i, tx := range txs {
+		blockTx, err := backend.BlockTransaction(height, uint32(i+1))
+		require.NoError(t, err)
+		require.Equal(t, tx.TxHash(), blockTx.TxHash())
+	}
+
+	// A transaction the block doesn't commit to is rejected.
+	server.ForgeTransaction(height, 2, newTestTx(3))
+	_, err := backend.BlockTransaction(height, 2)
+	require.ErrorContains(t, err, "merkle")
+}
@@ -0,0 +1,139 @@
+package electrumio
+
+import (
+	"errors"
+
+	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwallet/btcwallet"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+var (
+	// ErrFullBlocksUnsupported is returned when a full block is requested
+	// from an electrum server, which can only serve block headers and
+	// individual transactions.
+	ErrFullBlocksUnsupported = errors.New("electrum servers can't serve " +
+		"full blocks")
+)
+
+// ChainIO is an implementation of the lnwallet.BlockChainIO interface that's
+// backed by an electrum server.
+type ChainIO struct {
+	backend *Backend
+}
+
+// A compile time check to ensure ChainIO implements the lnwallet.BlockChainIO
+// and lnwallet.BlockTxFetcher interfaces.
+var _ lnwallet.BlockChainIO = (*ChainIO)(nil)
+var _ lnwallet.BlockTxFetcher = (*ChainIO)(nil)
+
+// NewChainIO creates a new ChainIO backed by the given electrum backend.
+func NewChainIO(backend *Backend) *ChainIO {
+	return &ChainIO{
+		backend: backend,
+	}
+}
+
+// GetBestBlock returns the current height and hash of the best known block
+// within the main chain.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (c *ChainIO) GetBestBlock() (*chainhash.Hash, int32, error) {
+	return c.backend.BestBlock()
+}
+
+// GetUtxo returns the original output referenced by the passed outpoint that
+// creates the target pkScript.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (c *ChainIO) GetUtxo(op *wire.OutPoint, pkScript []byte,
+	_ uint32, _ <-chan struct{}) (*wire.TxOut, error) {
+
+	ctx, cancel := c.backend.reqContext()
+	defer cancel()
+
+	utxos, err := c.backend.client.ListUnspent(ctx, ScriptHash(pkScript))
+	if err != nil {
+		return nil, err
+	}
+
+	txid := op.Hash.String()
+	for _, utxo := range utxos {
+		if utxo.Hash != txid || utxo.Position != op.Index {
+			continue
+		}
+
+		return &wire.TxOut{
+			Value:    int64(utxo.Value),
+			PkScript: pkScript,
+		}, nil
+	}
+
+	// The output isn't unspent, so we'll check whether the transaction
+	// that created it is part of the script's history to tell a spent
+	// output from one that never existed.
+	history, err := c.backend.ScriptHistory(pkScript)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := history[op.Hash]; ok {
+		return nil, btcwallet.ErrOutputSpent
+	}
+
+	return nil, btcwallet.ErrOutputNotFound
+}
+
+// GetBlockHash returns the hash of the block in the best blockchain at the
+// given height.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (c *ChainIO) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
+	hash, _, err := c.backend.BlockHeader(uint32(blockHeight))
+
+	return hash, err
+}
+
+// GetBlock always returns ErrFullBlocksUnsupported, as electrum servers can't
+// serve full blocks.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (c *ChainIO) GetBlock(*chainhash.Hash) (*wire.MsgBlock, error) {
+	return nil, ErrFullBlocksUnsupported
+}
+
+// GetBlockHeader returns a block header for the block with the given hash.
+// Only the headers of blocks that were previously looked up by height can be
+// returned.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (c *ChainIO) GetBlockHeader(
+	blockHash *chainhash.Hash) (*wire.BlockHeader, error) {
+
+	height, err := c.backend.BlockHeight(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, header, err := c.backend.BlockHeader(height)
+	if err != nil {
+		return nil, err
+	}
+
+	// The block may have been reorged out since we last looked it up.
+	if *hash != *blockHash {
+		return nil, ErrUnknownBlock
+	}
+
+	return header, nil
+}
+
+// GetBlockTransaction returns the transaction at the given index of the main
+// chain block at the given height.
+//
+// This method is a part of the lnwallet.BlockTxFetcher interface.
+func (c *ChainIO) GetBlockTransaction(blockHeight,
+	txIndex uint32) (*wire.MsgTx, error) {
+
+	return c.backend.BlockTransaction(blockHeight, txIndex)
+}
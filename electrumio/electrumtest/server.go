@@ -0,0 +1,765 @@
+// Package electrumtest provides a stub electrum server backed by an in-memory
+// chain, to be used in tests of the electrum chain backend.
+package electrumtest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/go-flokicoin/blockchain"
+	"github.com/flokiorg/go-flokicoin/chaincfg"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// responseDelay is the time the server waits before answering a request. The
+// electrum client only starts listening for the response to a request once
+// it's been sent, so answering right away could race with it.
+const responseDelay = 5 * time.Millisecond
+
+// ChainParams are the parameters of the chain served by the stub server. Its
+// blocks are mined against the proof-of-work limit of these parameters.
+var ChainParams = &chaincfg.RegressionNetParams
+
+// txEntry is a transaction known to the server.
+type txEntry struct {
+	tx *wire.MsgTx
+
+	// height is the height of the block the transaction was confirmed in,
+	// or zero if it's unconfirmed.
+	height uint32
+
+	// pos is the index of the transaction within its block.
+	pos uint32
+}
+
+// request is a JSON-RPC request sent by an electrum client.
+type request struct {
+	ID     uint64            `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// rpcError is the error of a JSON-RPC response.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// historyEntry is an entry of a script's history.
+type historyEntry struct {
+	Hash   string `json:"tx_hash"`
+	Height int32  `json:"height"`
+}
+
+// conn is a connection of an electrum client to the server.
+type conn struct {
+	net.Conn
+
+	writeMtx sync.Mutex
+
+	// subscribedHeaders is true if the client subscribed to headers.
+	subscribedHeaders bool
+
+	// scripts is the set of script hashes the client subscribed to.
+	scripts map[string]struct{}
+}
+
+// send writes a JSON message to the client.
+func (c *conn) send(msg interface{}) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.writeMtx.Lock()
+	defer c.writeMtx.Unlock()
+
+	_, err = c.Write(append(b, '\n'))
+
+	return err
+}
+
+// Server is a stub electrum server that serves an in-memory chain, which
+// tests can extend and reorg at will.
+type Server struct {
+	t        *testing.T
+	listener net.Listener
+
+	mtx     sync.Mutex
+	headers []wire.BlockHeader
+
+	// blockTxs holds the txids of the transactions of each block of the
+	// chain, starting with its coinbase.
+	blockTxs [][]chainhash.Hash
+
+	// mined is the number of blocks mined so far, including those
+	// disconnected in reorgs.
+	mined uint32
+
+	txs      map[chainhash.Hash]*txEntry
+	fees     map[uint32]float32
+	relayFee float32
+	conns    map[*conn]struct{}
+
+	wg sync.WaitGroup
+}
+
+// NewServer starts a new stub electrum server whose chain only holds a
+// genesis block. The server is stopped once the test completes.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	genesis := wire.BlockHeader{
+		Version:   1,
+		Timestamp: time.Unix(1600000000, 0),
+		Bits:      ChainParams.PowLimitBits,
+	}
+	solveHeader(&genesis)
+
+	s := &Server{
+		t:        t,
+		listener: listener,
+		headers:  []wire.BlockHeader{genesis},
+		blockTxs: [][]chainhash.Hash{nil},
+		txs:      make(map[chainhash.Hash]*txEntry),
+		fees:     make(map[uint32]float32),
+		relayFee: 0.00001,
+		conns:    make(map[*conn]struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.acceptConns()
+
+	t.Cleanup(s.stop)
+
+	return s
+}
+
+// Addr returns the host:port the server is listening on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Disconnect closes all client connections, while still accepting new ones.
+func (s *Server) Disconnect() {
+	s.mtx.Lock()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mtx.Unlock()
+}
+
+// stop closes the listener and all client connections.
+func (s *Server) stop() {
+	s.listener.Close()
+	s.Disconnect()
+
+	s.wg.Wait()
+}
+
+// acceptConns accepts new client connections until the listener is closed.
+func (s *Server) acceptConns() {
+	defer s.wg.Done()
+
+	for {
+		netConn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		c := &conn{
+			Conn:    netConn,
+			scripts: make(map[string]struct{}),
+		}
+
+		s.mtx.Lock()
+		s.conns[c] = struct{}{}
+		s.mtx.Unlock()
+
+		s.wg.Add(1)
+		go s.handleConn(c)
+	}
+}
+
+// handleConn serves the requests of a client until its connection is closed.
+func (s *Server) handleConn(c *conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mtx.Lock()
+		delete(s.conns, c)
+		s.mtx.Unlock()
+
+		c.Close()
+	}()
+
+	reader := bufio.NewReader(c)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			return
+		}
+
+		result, err := s.handleRequest(c, &req)
+
+		resp := map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+		}
+		if err != nil {
+			resp["error"] = &rpcError{Code: 1, Message: err.Error()}
+		} else {
+			resp["result"] = result
+		}
+
+		time.Sleep(responseDelay)
+		if err := c.send(resp); err != nil {
+			return
+		}
+	}
+}
+
+// param decodes the request parameter at the given index.
+func param[T any](req *request, i int) (T, error) {
+	var v T
+	if i >= len(req.Params) {
+		return v, fmt.Errorf("missing parameter %d", i)
+	}
+
+	return v, json.Unmarshal(req.Params[i], &v)
+}
+
+// handleRequest returns the result of a request.
+func (s *Server) handleRequest(c *conn, req *request) (interface{},
+	error) {
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	switch req.Method {
+	case "server.ping":
+		return nil, nil
+
+	case "blockchain.headers.subscribe":
+		c.subscribedHeaders = true
+
+		return s.tipNotification(), nil
+
+	case "blockchain.block.header":
+		height, err := param[uint32](req, 0)
+		if err != nil {
+			return nil, err
+		}
+		if height >= uint32(len(s.headers)) {
+			return nil, fmt.Errorf("height %d out of range", height)
+		}
+
+		return serializeHeader(&s.headers[height]), nil
+
+	case "blockchain.scripthash.subscribe":
+		scriptHash, err := param[string](req, 0)
+		if err != nil {
+			return nil, err
+		}
+		c.scripts[scriptHash] = struct{}{}
+
+		return s.scriptStatus(scriptHash), nil
+
+	case "blockchain.scripthash.get_history":
+		scriptHash, err := param[string](req, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.history(scriptHash), nil
+
+	case "blockchain.scripthash.listunspent":
+		scriptHash, err := param[string](req, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.unspent(scriptHash), nil
+
+	case "blockchain.transaction.get":
+		txid, err := param[string](req, 0)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := s.lookupTx(txid)
+		if err != nil {
+			return nil, err
+		}
+
+		var buf bytes.Buffer
+		if err := entry.tx.Serialize(&buf); err != nil {
+			return nil, err
+		}
+
+		return hex.EncodeToString(buf.Bytes()), nil
+
+	case "blockchain.transaction.get_merkle":
+		txid, err := param[string](req, 0)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := s.lookupTx(txid)
+		if err != nil {
+			return nil, err
+		}
+		if entry.height == 0 {
+			return nil, errors.New("transaction not confirmed")
+		}
+
+		return map[string]interface{}{
+			"merkle":       s.merkleBranch(entry.height, entry.pos),
+			"block_height": entry.height,
+			"pos":          entry.pos,
+		}, nil
+
+	case "blockchain.transaction.id_from_pos":
+		height, err := param[uint32](req, 0)
+		if err != nil {
+			return nil, err
+		}
+		pos, err := param[uint32](req, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		if height >= uint32(len(s.blockTxs)) ||
+			pos >= uint32(len(s.blockTxs[height])) {
+
+			return nil, fmt.Errorf("no transaction at position "+
+				"%d of block %d", pos, height)
+		}
+
+		return map[string]interface{}{
+			"tx_hash": s.blockTxs[height][pos].String(),
+			"merkle":  s.merkleBranch(height, pos),
+		}, nil
+
+	case "blockchain.estimatefee":
+		target, err := param[uint32](req, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		fee, ok := s.fees[target]
+		if !ok {
+			return -1, nil
+		}
+
+		return fee, nil
+
+	case "blockchain.relayfee":
+		return s.relayFee, nil
+
+	default:
+		return nil, fmt.Errorf("unknown method %v", req.Method)
+	}
+}
+
+// lookupTx returns the transaction with the given hex encoded txid.
+func (s *Server) lookupTx(txid string) (*txEntry, error) {
+	hash, err := chainhash.NewHashFromStr(txid)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := s.txs[*hash]
+	if !ok {
+		return nil, fmt.Errorf("unknown transaction %v", txid)
+	}
+
+	return entry, nil
+}
+
+// ScriptHash returns the electrum script hash of an output script.
+func ScriptHash(pkScript []byte) string {
+	hash := sha256.Sum256(pkScript)
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+
+	return hex.EncodeToString(hash[:])
+}
+
+// touchesScript returns whether the transaction pays to or spends from the
+// script with the given script hash.
+func (s *Server) touchesScript(tx *wire.MsgTx, scriptHash string) bool {
+	for _, txOut := range tx.TxOut {
+		if ScriptHash(txOut.PkScript) == scriptHash {
+			return true
+		}
+	}
+
+	for _, txIn := range tx.TxIn {
+		prev, ok := s.txs[txIn.PreviousOutPoint.Hash]
+		if !ok {
+			continue
+		}
+
+		index := txIn.PreviousOutPoint.Index
+		if int(index) >= len(prev.tx.TxOut) {
+			continue
+		}
+
+		if ScriptHash(prev.tx.TxOut[index].PkScript) == scriptHash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// history returns the history of the script with the given script hash.
+func (s *Server) history(scriptHash string) []historyEntry {
+	history := make([]historyEntry, 0)
+	for txid, entry := range s.txs {
+		if !s.touchesScript(entry.tx, scriptHash) {
+			continue
+		}
+
+		history = append(history, historyEntry{
+			Hash:   txid.String(),
+			Height: int32(entry.height),
+		})
+	}
+
+	sort.Slice(history, func(i, j int) bool {
+		if history[i].Height != history[j].Height {
+			return history[i].Height < history[j].Height
+		}
+
+		return history[i].Hash < history[j].Hash
+	})
+
+	return history
+}
+
+// unspent returns the unspent outputs paying to the script with the given
+// script hash.
+func (s *Server) unspent(scriptHash string) []map[string]interface{} {
+	spent := make(map[wire.OutPoint]struct{})
+	for _, entry := range s.txs {
+		for _, txIn := range entry.tx.TxIn {
+			spent[txIn.PreviousOutPoint] = struct{}{}
+		}
+	}
+
+	unspent := make([]map[string]interface{}, 0)
+	for txid, entry := range s.txs {
+		for i, txOut := range entry.tx.TxOut {
+			if ScriptHash(txOut.PkScript) != scriptHash {
+				continue
+			}
+
+			op := wire.OutPoint{Hash: txid, Index: uint32(i)}
+			if _, ok := spent[op]; ok {
+				continue
+			}
+
+			unspent = append(unspent, map[string]interface{}{
+				"height":  entry.height,
+				"tx_pos":  i,
+				"tx_hash": txid.String(),
+				"value":   txOut.Value,
+			})
+		}
+	}
+
+	return unspent
+}
+
+// scriptStatus returns the electrum status of the script with the given script
+// hash, which is nil if the script has no history.
+func (s *Server) scriptStatus(scriptHash string) interface{} {
+	history := s.history(scriptHash)
+	if len(history) == 0 {
+		return nil
+	}
+
+	var status string
+	for _, entry := range history {
+		status += fmt.Sprintf("%s:%d:", entry.Hash, entry.Height)
+	}
+	hash := sha256.Sum256([]byte(status))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// tipNotification returns the headers notification of the chain tip.
+func (s *Server) tipNotification() map[string]interface{} {
+	height := len(s.headers) - 1
+
+	return map[string]interface{}{
+		"height": height,
+		"hex":    serializeHeader(&s.headers[height]),
+	}
+}
+
+// serializeHeader returns the hex encoding of a block header.
+func serializeHeader(header *wire.BlockHeader) string {
+	var buf bytes.Buffer
+	if err := header.Serialize(&buf); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(buf.Bytes())
+}
+
+// BestBlock returns the hash and height of the server's chain tip.
+func (s *Server) BestBlock() (chainhash.Hash, uint32) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	height := len(s.headers) - 1
+
+	return s.headers[height].BlockHash(), uint32(height)
+}
+
+// BlockHash returns the hash of the block at the given height.
+func (s *Server) BlockHash(height uint32) chainhash.Hash {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.headers[height].BlockHash()
+}
+
+// SetFee sets the fee rate, in FLC/kB, the server estimates for the given
+// confirmation target.
+func (s *Server) SetFee(target uint32, fee float32) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.fees[target] = fee
+}
+
+// AddMempoolTx adds an unconfirmed transaction to the server.
+func (s *Server) AddMempoolTx(tx *wire.MsgTx) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.txs[tx.TxHash()] = &txEntry{tx: tx}
+	s.notifyScripts(tx)
+}
+
+// MineBlock extends the server's chain with a new block confirming the given
+// transactions, which may already be in the mempool. The hash of the new block
+// is returned.
+func (s *Server) MineBlock(txs ...*wire.MsgTx) chainhash.Hash {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	prevHeader := s.headers[len(s.headers)-1]
+	height := uint32(len(s.headers))
+
+	// The coinbase commits to the number of blocks mined so far, so that
+	// blocks mined on different branches have distinct hashes.
+	s.mined++
+	coinbase := wire.NewMsgTx(2)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		SignatureScript:  []byte(fmt.Sprintf("%d", s.mined)),
+	})
+	coinbase.AddTxOut(&wire.TxOut{Value: 50e8, PkScript: []byte{0x51}})
+
+	txids := []chainhash.Hash{coinbase.TxHash()}
+	for _, tx := range txs {
+		txids = append(txids, tx.TxHash())
+	}
+	levels := merkleLevels(txids)
+
+	header := wire.BlockHeader{
+		Version:    1,
+		PrevBlock:  prevHeader.BlockHash(),
+		MerkleRoot: levels[len(levels)-1][0],
+		Timestamp:  prevHeader.Timestamp.Add(time.Minute),
+		Bits:       prevHeader.Bits,
+	}
+	solveHeader(&header)
+
+	s.headers = append(s.headers, header)
+	s.blockTxs = append(s.blockTxs, txids)
+
+	// The coinbase transaction takes the first position of the block.
+	for i, tx := range txs {
+		s.txs[tx.TxHash()] = &txEntry{
+			tx:     tx,
+			height: height,
+			pos:    uint32(i + 1),
+		}
+	}
+
+	s.notifyHeaders()
+	for _, tx := range txs {
+		s.notifyScripts(tx)
+	}
+
+	return header.BlockHash()
+}
+
+// Reorg disconnects the given number of blocks from the tip of the server's
+// chain. The transactions they confirmed are dropped. Tests can then mine a
+// new branch with MineBlock.
+func (s *Server) Reorg(depth uint32) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	newHeight := uint32(len(s.headers)) - 1 - depth
+	s.headers = s.headers[:newHeight+1]
+	s.blockTxs = s.blockTxs[:newHeight+1]
+
+	for txid, entry := range s.txs {
+		if entry.height > newHeight {
+			delete(s.txs, txid)
+		}
+	}
+}
+
+// ForgeTransaction makes the server claim that the given transaction was
+// confirmed at the given position of the block at the given height, in place
+// of the transaction that's actually there. The block's header is left as is,
+// so the server can't prove the claim.
+func (s *Server) ForgeTransaction(height, pos uint32, tx *wire.MsgTx) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	txid := tx.TxHash()
+	delete(s.txs, s.blockTxs[height][pos])
+	s.blockTxs[height][pos] = txid
+	s.txs[txid] = &txEntry{
+		tx:     tx,
+		height: height,
+		pos:    pos,
+	}
+}
+
+// solveHeader grinds the nonce of the header until it carries valid
+// proof-of-work for its difficulty bits.
+func solveHeader(header *wire.BlockHeader) {
+	target := blockchain.CompactToBig(header.Bits)
+	for {
+		powHash := header.BlockPoWHash()
+		if blockchain.HashToBig(&powHash).Cmp(target) <= 0 {
+			return
+		}
+
+		header.Nonce++
+	}
+}
+
+// merkleLevels returns the levels of the merkle tree over the given txids,
+// starting with the txids themselves and ending with the root.
+func merkleLevels(txids []chainhash.Hash) [][]chainhash.Hash {
+	levels := [][]chainhash.Hash{txids}
+	for level := txids; len(level) > 1; {
+		var next []chainhash.Hash
+		for i := 0; i < len(level); i += 2 {
+			// A node without a sibling is paired with itself.
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			next = append(
+				next, blockchain.HashMerkleBranches(
+					&level[i], &right,
+				),
+			)
+		}
+
+		levels = append(levels, next)
+		level = next
+	}
+
+	return levels
+}
+
+// merkleBranch returns the hex encoded merkle branch of the transaction at the
+// given position of the block at the given height, as served by electrum.
+//
+// NOTE: The server's mutex must be held.
+func (s *Server) merkleBranch(height, pos uint32) []string {
+	levels := merkleLevels(s.blockTxs[height])
+
+	branch := []string{}
+	for _, level := range levels[:len(levels)-1] {
+		sibling := pos ^ 1
+		if sibling >= uint32(len(level)) {
+			sibling = pos
+		}
+		branch = append(branch, level[sibling].String())
+
+		pos >>= 1
+	}
+
+	return branch
+}
+
+// notifyHeaders notifies all clients subscribed to headers of the chain tip.
+//
+// NOTE: The server's mutex must be held.
+func (s *Server) notifyHeaders() {
+	ntfn := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "blockchain.headers.subscribe",
+		"params":  []interface{}{s.tipNotification()},
+	}
+
+	for c := range s.conns {
+		if !c.subscribedHeaders {
+			continue
+		}
+
+		if err := c.send(ntfn); err != nil {
+			s.t.Logf("Unable to send headers notification: %v", err)
+		}
+	}
+}
+
+// notifyScripts notifies all clients subscribed to a script the transaction
+// pays to or spends from of the script's new status.
+//
+// NOTE: The server's mutex must be held.
+func (s *Server) notifyScripts(tx *wire.MsgTx) {
+	for c := range s.conns {
+		for scriptHash := range c.scripts {
+			if !s.touchesScript(tx, scriptHash) {
+				continue
+			}
+
+			ntfn := map[string]interface{}{
+				"jsonrpc": "2.0",
+				"method":  "blockchain.scripthash.subscribe",
+				"params": []interface{}{
+					scriptHash, s.scriptStatus(scriptHash),
+				},
+			}
+
+			if err := c.send(ntfn); err != nil {
+				s.t.Logf("Unable to send script "+
+					"notification: %v", err)
+			}
+		}
+	}
+}
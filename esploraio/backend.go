@@ -0,0 +1,682 @@
+package esploraio
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/flokiorg/flnd/blockcache"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flokicoin-neutrino/cache/lru"
+	"github.com/flokiorg/go-flokicoin/blockchain"
+	"github.com/flokiorg/go-flokicoin/chaincfg"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// headerCacheSize is the number of verified block headers we keep
+	// around.
+	headerCacheSize = 10000
+
+	// headerBatchSize is the number of headers fetched at once while
+	// catching up with the chain of the Esplora API.
+	headerBatchSize = 100
+
+	// headerFetchWorkers is the number of headers of a batch that are
+	// fetched concurrently.
+	headerFetchWorkers = 8
+)
+
+var (
+	// ErrBlockNotInMainChain is returned when a block that's no longer
+	// part of the main chain is looked up by hash.
+	ErrBlockNotInMainChain = errors.New("block not in main chain")
+
+	// ErrUnknownBlock is returned when a block that isn't part of our
+	// header chain is looked up.
+	ErrUnknownBlock = errors.New("block not in header chain")
+)
+
+// cachedHeader is a verified block header stored in the header cache.
+type cachedHeader struct {
+	*wire.BlockHeader
+}
+
+// Size returns the "size" of an entry. We return 1 as we just want to limit
+// the total number of entries rather than do accurate size accounting.
+func (c *cachedHeader) Size() (uint64, error) {
+	return 1, nil
+}
+
+// BlockTx is a transaction that's been confirmed in the main chain.
+type BlockTx struct {
+	// Tx is the confirmed transaction.
+	Tx *wire.MsgTx
+
+	// Block is the block the transaction was confirmed in.
+	Block *wire.MsgBlock
+
+	// BlockHash is the hash of the block the transaction was confirmed in.
+	BlockHash chainhash.Hash
+
+	// BlockHeight is the height of the block the transaction was
+	// confirmed in.
+	BlockHeight uint32
+
+	// TxIndex is the index of the transaction within its block.
+	TxIndex uint32
+}
+
+// Backend wraps an Esplora API client with the queries the Esplora chain
+// backend is built upon. The API isn't trusted with the validity of the chain:
+// the block headers it serves make up a local header chain in which every
+// header is validated in context and the chain with the most work is followed,
+// and every block is checked against its header before it's used. All lookups
+// of blocks by height or hash are answered by the local header chain.
+type Backend struct {
+	client      *Client
+	chainParams *chaincfg.Params
+
+	// blockCache caches the blocks we've fetched and verified, and is
+	// shared with the other users of full blocks.
+	blockCache *blockcache.BlockCache
+
+	// headerChain is our local view of the chain, built from the headers
+	// served by the API.
+	headerChain *headerChain
+
+	// headerStore persists the main chain of the header chain, so that we
+	// resume from its tip on restart.
+	headerStore *headerStore
+
+	// syncMtx ensures the header chain is only synced with the API by a
+	// single caller at a time.
+	syncMtx sync.Mutex
+
+	// headers caches the block headers we've fetched and verified, by
+	// block hash.
+	headers *lru.Cache[chainhash.Hash, *cachedHeader]
+}
+
+// NewBackend creates a new Backend for the given chain, using the passed
+// Esplora API client. The headers of the main chain are persisted in the given
+// database, and the header chain resumes from the stored headers. Without any,
+// it starts from the last checkpoint the API's chain has reached, or the
+// genesis header.
+func NewBackend(client *Client, chainParams *chaincfg.Params,
+	blockCache *blockcache.BlockCache, db kvdb.Backend) (*Backend, error) {
+
+	headerStore, err := newHeaderStore(db)
+	if err != nil {
+		return nil, err
+	}
+
+	headerChain := newHeaderChain(chainParams)
+
+	startHeight, headers, err := headerStore.fetchHeaders()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch stored headers: %w",
+			err)
+	}
+	if len(headers) != 0 {
+		if err := headerChain.reset(startHeight, headers); err != nil {
+			return nil, fmt.Errorf("invalid stored headers: %w",
+				err)
+		}
+
+		headerChain.markPersisted(headerChain.tip().height)
+	}
+
+	return &Backend{
+		client:      client,
+		chainParams: chainParams,
+		blockCache:  blockCache,
+		headerChain: headerChain,
+		headerStore: headerStore,
+		headers: lru.NewCache[chainhash.Hash, *cachedHeader](
+			headerCacheSize,
+		),
+	}, nil
+}
+
+// Client returns the Esplora API client the backend is using.
+func (b *Backend) Client() *Client {
+	return b.client
+}
+
+// VerifyHeader checks that the given header hashes to the expected block hash,
+// and that it carries valid proof-of-work for the target it claims. Whether the
+// target is the one required by the chain is only checked once the header is
+// connected to the header chain.
+func VerifyHeader(hash *chainhash.Hash, header *wire.BlockHeader,
+	chainParams *chaincfg.Params) error {
+
+	if header.BlockHash() != *hash {
+		return fmt.Errorf("header of block %v hashes to %v", hash,
+			header.BlockHash())
+	}
+
+	// The proof-of-work check only looks at the block's header.
+	block := chainutil.NewBlock(&wire.MsgBlock{Header: *header})
+	err := blockchain.CheckProofOfWork(block, chainParams.PowLimit)
+	if err != nil {
+		return fmt.Errorf("invalid proof-of-work for block %v: %w",
+			hash, err)
+	}
+
+	return nil
+}
+
+// verifyBlock checks that the given block matches its already verified
+// header, which commits to all of its transactions.
+func verifyBlock(header *wire.BlockHeader, block *wire.MsgBlock) error {
+	hash := header.BlockHash()
+	if block.BlockHash() != hash {
+		return fmt.Errorf("block %v has a different header", hash)
+	}
+
+	utilBlock := chainutil.NewBlock(block)
+	merkleRoot := blockchain.CalcMerkleRoot(utilBlock.Transactions(), false)
+	if merkleRoot != header.MerkleRoot {
+		return fmt.Errorf("transactions of block %v don't match its "+
+			"merkle root", hash)
+	}
+
+	if err := blockchain.ValidateWitnessCommitment(utilBlock); err != nil {
+		return fmt.Errorf("witnesses of block %v don't match its "+
+			"commitment: %w", hash, err)
+	}
+
+	return nil
+}
+
+// fetchHeader fetches the header of the block with the given hash from the
+// Esplora API and checks it against the hash, adding it to the header cache.
+func (b *Backend) fetchHeader(hash *chainhash.Hash) (*wire.BlockHeader,
+	error) {
+
+	if header, err := b.headers.Get(*hash); err == nil {
+		return header.BlockHeader, nil
+	}
+
+	header, err := b.client.BlockHeader(hash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get header of block %v: %w",
+			hash, err)
+	}
+
+	if err := VerifyHeader(hash, header, b.chainParams); err != nil {
+		return nil, err
+	}
+
+	_, _ = b.headers.Put(*hash, &cachedHeader{header})
+
+	return header, nil
+}
+
+// fetchHeaders fetches the headers of the API's main chain blocks between the
+// given heights, inclusive.
+func (b *Backend) fetchHeaders(startHeight,
+	endHeight int32) ([]*wire.BlockHeader, error) {
+
+	headers := make([]*wire.BlockHeader, endHeight-startHeight+1)
+
+	var g errgroup.Group
+	g.SetLimit(headerFetchWorkers)
+	for height := startHeight; height <= endHeight; height++ {
+		g.Go(func() error {
+			hash, err := b.client.BlockHash(height)
+			if err != nil {
+				return fmt.Errorf("unable to get hash of "+
+					"block at height %d: %w", height, err)
+			}
+
+			header, err := b.fetchHeader(hash)
+			if err != nil {
+				return err
+			}
+			headers[height-startHeight] = header
+
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return headers, nil
+}
+
+// syncHeaders brings the header chain in line with the chain of the Esplora
+// API, by connecting the headers up to the API's tip. The API's tip only
+// becomes our tip if its chain carries more work than ours. The headers that
+// were connected are persisted, even if the sync fails halfway.
+func (b *Backend) syncHeaders() error {
+	b.syncMtx.Lock()
+	defer b.syncMtx.Unlock()
+
+	err := b.connectHeaders()
+	if persistErr := b.persistHeaders(); err == nil {
+		err = persistErr
+	}
+
+	return err
+}
+
+// connectHeaders connects the headers of the API's chain up to its tip. The
+// caller must hold the sync mutex.
+func (b *Backend) connectHeaders() error {
+	tipHash, err := b.client.TipHash()
+	if err != nil {
+		return fmt.Errorf("unable to get best block: %w", err)
+	}
+	if b.headerChain.lookup(tipHash) != nil {
+		return nil
+	}
+
+	// The height the API reports for its tip only tells us which headers
+	// to fetch, as headers are placed by the header they build upon.
+	status, err := b.client.BlockStatus(tipHash)
+	if err != nil {
+		return fmt.Errorf("unable to get status of block %v: %w",
+			tipHash, err)
+	}
+
+	if err := b.startFromCheckpoint(status.Height); err != nil {
+		return err
+	}
+
+	startHeight := b.headerChain.tip().height + 1
+	for height := startHeight; height <= status.Height; {
+		endHeight := min(height+headerBatchSize-1, status.Height)
+		headers, err := b.fetchHeaders(height, endHeight)
+		if err != nil {
+			return err
+		}
+
+		for _, header := range headers {
+			if err := b.connectHeader(header, height); err != nil {
+				return err
+			}
+			height++
+		}
+
+		if err := b.persistHeaders(); err != nil {
+			return err
+		}
+	}
+
+	// If the API's tip has no more height than our own tip, it's on a
+	// branch we'll have to connect by hash.
+	if b.headerChain.lookup(tipHash) != nil {
+		return nil
+	}
+
+	header, err := b.fetchHeader(tipHash)
+	if err != nil {
+		return err
+	}
+
+	return b.connectHeader(header, status.Height)
+}
+
+// startFromCheckpoint makes a header chain that only holds the genesis header
+// start from the last checkpoint the API's chain, whose tip is at the given
+// height, has reached instead, so that we don't have to fetch and validate all
+// the headers before it. The checkpoint is preceded by the headers its
+// successors' timestamps are checked against. The headers are fetched by the
+// hash each of them commits to, starting with the hash of the checkpoint, so
+// the API can't make them up.
+func (b *Backend) startFromCheckpoint(apiHeight int32) error {
+	if b.headerChain.tip().height != 0 {
+		return nil
+	}
+
+	var checkpoint *chaincfg.Checkpoint
+	checkpoints := b.chainParams.Checkpoints
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		if checkpoints[i].Height <= apiHeight {
+			checkpoint = &checkpoints[i]
+			break
+		}
+	}
+	if checkpoint == nil || checkpoint.Height <= medianTimeHeaders {
+		return nil
+	}
+
+	header, err := b.fetchHeader(checkpoint.Hash)
+	if err != nil {
+		return err
+	}
+
+	headers := []*wire.BlockHeader{header}
+	for len(headers) <= medianTimeHeaders {
+		prevHeader, err := b.fetchHeader(&headers[0].PrevBlock)
+		if err != nil {
+			return err
+		}
+		headers = append([]*wire.BlockHeader{prevHeader}, headers...)
+	}
+
+	return b.headerChain.reset(
+		checkpoint.Height-medianTimeHeaders, headers,
+	)
+}
+
+// persistHeaders stores the headers of our main chain that weren't persisted
+// yet, replacing the stored headers of any branch we've reorged away from.
+// The caller must hold the sync mutex.
+func (b *Backend) persistHeaders() error {
+	startHeight, nodes := b.headerChain.dirtyHeaders()
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	headers := make([]*wire.BlockHeader, 0, len(nodes))
+	for _, node := range nodes {
+		header, err := b.header(node)
+		if err != nil {
+			return err
+		}
+		headers = append(headers, header)
+	}
+
+	if err := b.headerStore.putHeaders(startHeight, headers); err != nil {
+		return fmt.Errorf("unable to store headers: %w", err)
+	}
+
+	b.headerChain.markPersisted(nodes[len(nodes)-1].height)
+
+	return nil
+}
+
+// connectHeader connects the given header, which the API places at the given
+// height, to the header chain. If it builds upon a header we don't know of, as
+// happens once the API's chain was reorged, the headers of its branch are
+// fetched by the hash each of them commits to and connected first.
+func (b *Backend) connectHeader(header *wire.BlockHeader, height int32) error {
+	branch := []*wire.BlockHeader{header}
+	for b.headerChain.lookup(&branch[0].PrevBlock) == nil {
+		height--
+		if height <= 0 {
+			return fmt.Errorf("header %v doesn't connect to our "+
+				"header chain", header.BlockHash())
+		}
+
+		prevHeader, err := b.fetchHeader(&branch[0].PrevBlock)
+		if err != nil {
+			return err
+		}
+		branch = append([]*wire.BlockHeader{prevHeader}, branch...)
+	}
+
+	for _, header := range branch {
+		if _, err := b.headerChain.connect(header); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// header returns the full header of the given header chain node.
+func (b *Backend) header(node *headerNode) (*wire.BlockHeader, error) {
+	return b.fetchHeader(&node.hash)
+}
+
+// BlockHeader returns the header of the block with the given hash, which must
+// be part of our header chain. ErrUnknownBlock is returned otherwise.
+func (b *Backend) BlockHeader(hash *chainhash.Hash) (*wire.BlockHeader,
+	error) {
+
+	node := b.headerChain.lookup(hash)
+	if node == nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownBlock, hash)
+	}
+
+	return b.header(node)
+}
+
+// BlockHash returns the hash of the block at the given height of our main
+// chain.
+func (b *Backend) BlockHash(height int32) (*chainhash.Hash, error) {
+	node := b.headerChain.headerAt(height)
+	if node == nil {
+		return nil, fmt.Errorf("no block at height %d in main chain",
+			height)
+	}
+
+	hash := node.hash
+
+	return &hash, nil
+}
+
+// BlockHeaderByHeight returns the hash and header of the block at the given
+// height of our main chain.
+func (b *Backend) BlockHeaderByHeight(height int32) (*chainhash.Hash,
+	*wire.BlockHeader, error) {
+
+	node := b.headerChain.headerAt(height)
+	if node == nil {
+		return nil, nil, fmt.Errorf("no block at height %d in main "+
+			"chain", height)
+	}
+
+	header, err := b.header(node)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hash := node.hash
+
+	return &hash, header, nil
+}
+
+// BlockHeight returns the height of the block with the given hash within our
+// main chain. ErrBlockNotInMainChain is returned if the block was reorged
+// out, and ErrUnknownBlock if we don't know of it.
+func (b *Backend) BlockHeight(hash *chainhash.Hash) (int32, error) {
+	node := b.headerChain.lookup(hash)
+	if node == nil {
+		return 0, fmt.Errorf("%w: %v", ErrUnknownBlock, hash)
+	}
+
+	if !b.headerChain.inMainChain(node) {
+		return 0, fmt.Errorf("%w: %v", ErrBlockNotInMainChain, hash)
+	}
+
+	return node.height, nil
+}
+
+// BestBlock syncs the header chain with the Esplora API, and returns the hash
+// and height of the tip of our main chain.
+func (b *Backend) BestBlock() (*chainhash.Hash, int32, error) {
+	if err := b.syncHeaders(); err != nil {
+		return nil, 0, err
+	}
+
+	tip := b.headerChain.tip()
+	hash := tip.hash
+
+	return &hash, tip.height, nil
+}
+
+// GetBlock returns the verified block with the given hash, first looking it up
+// in the block cache.
+func (b *Backend) GetBlock(hash *chainhash.Hash) (*wire.MsgBlock, error) {
+	return b.blockCache.GetBlock(hash, b.fetchBlock)
+}
+
+// fetchBlock fetches the block with the given hash from the Esplora API and
+// verifies it against its header.
+func (b *Backend) fetchBlock(hash *chainhash.Hash) (*wire.MsgBlock, error) {
+	header, err := b.BlockHeader(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := b.client.Block(hash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get block %v: %w", hash, err)
+	}
+
+	if err := verifyBlock(header, block); err != nil {
+		return nil, err
+	}
+
+	return block, nil
+}
+
+// Transaction returns the transaction with the given hash.
+func (b *Backend) Transaction(txid *chainhash.Hash) (*wire.MsgTx, error) {
+	tx, err := b.client.Transaction(txid)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get transaction %v: %w", txid,
+			err)
+	}
+
+	if tx.TxHash() != *txid {
+		return nil, fmt.Errorf("transaction %v hashes to %v", txid,
+			tx.TxHash())
+	}
+
+	return tx, nil
+}
+
+// mainChainHeight returns the height of the block with the given hash within
+// our main chain, and false if the block isn't part of it.
+func (b *Backend) mainChainHeight(hash *chainhash.Hash) (uint32, bool) {
+	height, err := b.BlockHeight(hash)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint32(height), true
+}
+
+// blockTx looks up a transaction within the verified block with the given
+// hash, which proves its confirmation.
+func (b *Backend) blockTx(txid, blockHash *chainhash.Hash,
+	height uint32) (*BlockTx, error) {
+
+	block, err := b.GetBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, tx := range block.Transactions {
+		if tx.TxHash() != *txid {
+			continue
+		}
+
+		return &BlockTx{
+			Tx:          tx,
+			Block:       block,
+			BlockHash:   *blockHash,
+			BlockHeight: height,
+			TxIndex:     uint32(i),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("transaction %v not found in block %v", txid,
+		blockHash)
+}
+
+// ScriptTxs returns the transactions that pay to or spend from the given
+// output script and were confirmed in our main chain between the start and end
+// heights, inclusive. The transactions are sorted by the order they were
+// confirmed in.
+func (b *Backend) ScriptTxs(pkScript []byte, startHeight,
+	endHeight uint32) ([]*BlockTx, error) {
+
+	history, err := b.client.ScriptTxs(pkScript)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get script history: %w", err)
+	}
+
+	var txs []*BlockTx
+	for _, entry := range history {
+		if !entry.Status.Confirmed {
+			continue
+		}
+
+		txid, err := chainhash.NewHashFromStr(entry.TxID)
+		if err != nil {
+			return nil, err
+		}
+		blockHash, err := chainhash.NewHashFromStr(
+			entry.Status.BlockHash,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// The height of the block is taken from our own main chain,
+		// which the block may not be part of.
+		height, ok := b.mainChainHeight(blockHash)
+		if !ok || height < startHeight || height > endHeight {
+			continue
+		}
+
+		tx, err := b.blockTx(txid, blockHash, height)
+		if err != nil {
+			return nil, err
+		}
+
+		txs = append(txs, tx)
+	}
+
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].BlockHeight != txs[j].BlockHeight {
+			return txs[i].BlockHeight < txs[j].BlockHeight
+		}
+
+		return txs[i].TxIndex < txs[j].TxIndex
+	})
+
+	return txs, nil
+}
+
+// SpendingTx returns the transaction that spent the given outpoint in our main
+// chain, along with the index of the spending input. Nil is returned if the
+// outpoint is unspent, or only spent by a transaction that isn't confirmed in
+// our main chain.
+func (b *Backend) SpendingTx(op *wire.OutPoint) (*BlockTx, uint32, error) {
+	outSpend, err := b.client.OutSpend(op)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to get spend of %v: %w", op,
+			err)
+	}
+
+	if !outSpend.Spent || !outSpend.Status.Confirmed {
+		return nil, 0, nil
+	}
+
+	txid, err := chainhash.NewHashFromStr(outSpend.TxID)
+	if err != nil {
+		return nil, 0, err
+	}
+	blockHash, err := chainhash.NewHashFromStr(outSpend.Status.BlockHash)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// A spend confirmed in a block that isn't part of our main chain is
+	// treated the same as an unconfirmed spend.
+	height, ok := b.mainChainHeight(blockHash)
+	if !ok {
+		return nil, 0, nil
+	}
+
+	tx, err := b.blockTx(txid, blockHash, height)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tx, outSpend.Vin, nil
+}
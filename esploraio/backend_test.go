@@ -0,0 +1,237 @@
+package esploraio_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/blockcache"
+	"github.com/flokiorg/flnd/esploraio"
+	"github.com/flokiorg/flnd/esploraio/esploratest"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/go-flokicoin/chaincfg"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDB returns a new database to persist the header chain in.
+func newTestDB(t *testing.T) kvdb.Backend {
+	t.Helper()
+
+	db, cleanup, err := kvdb.GetTestBackend(t.TempDir(), "headers")
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	return db
+}
+
+// newTestBackend returns a new backend for the given fake Esplora API, which
+// persists its header chain in a new database.
+func newTestBackend(t *testing.T,
+	server *esploratest.Server) *esploraio.Backend {
+
+	t.Helper()
+
+	return newTestBackendWithDB(
+		t, server, esploratest.ChainParams, newTestDB(t),
+	)
+}
+
+// newTestBackendWithDB returns a new backend for the given fake Esplora API
+// and chain, which persists its header chain in the given database.
+func newTestBackendWithDB(t *testing.T, server *esploratest.Server,
+	chainParams *chaincfg.Params, db kvdb.Backend) *esploraio.Backend {
+
+	t.Helper()
+
+	client := esploraio.NewClient(server.URL(), 10*time.Second)
+
+	blockCache := blockcache.NewBlockCache(10000)
+
+	backend, err := esploraio.NewBackend(
+		client, chainParams, blockCache, db,
+	)
+	require.NoError(t, err)
+
+	return backend
+}
+
+// checkBestBlock asserts that the best block of the backend is the block with
+// the given hash and height.
+func checkBestBlock(t *testing.T, backend *esploraio.Backend,
+	hash chainhash.Hash, height int32) {
+
+	t.Helper()
+
+	bestHash, bestHeight, err := backend.BestBlock()
+	require.NoError(t, err)
+	require.Equal(t, hash, *bestHash)
+	require.Equal(t, height, bestHeight)
+}
+
+// TestHeaderChainMostWork ensures that the backend follows the chain with the
+// most work rather than the API's tip, and that blocks are looked up by height
+// and hash within that chain.
+func TestHeaderChainMostWork(t *testing.T) {
+	t.Parallel()
+
+	// Mine enough blocks for the initial sync to span multiple batches of
+	// headers.
+	server := esploratest.NewServer(t)
+	for i := 0; i < 150; i++ {
+		server.MineBlock()
+	}
+
+	backend := newTestBackend(t, server)
+	checkBestBlock(t, backend, server.BlockHash(150), 150)
+
+	// Replace the tip with a branch carrying the same amount of work. We
+	// should stick with our tip, even though the API moved to the branch.
+	oldTip := server.BlockHash(150)
+	server.Reorg(1)
+	branchBlock := server.MineBlock()
+	checkBestBlock(t, backend, oldTip, 150)
+
+	hash, err := backend.BlockHash(150)
+	require.NoError(t, err)
+	require.Equal(t, oldTip, *hash)
+
+	_, err = backend.BlockHeight(&branchBlock)
+	require.ErrorIs(t, err, esploraio.ErrBlockNotInMainChain)
+
+	// Once the branch carries more work, it becomes our main chain.
+	newTip := server.MineBlock()
+	checkBestBlock(t, backend, newTip, 151)
+
+	height, err := backend.BlockHeight(&branchBlock)
+	require.NoError(t, err)
+	require.EqualValues(t, 150, height)
+
+	_, err = backend.BlockHeight(&oldTip)
+	require.ErrorIs(t, err, esploraio.ErrBlockNotInMainChain)
+
+	hash, header, err := backend.BlockHeaderByHeight(151)
+	require.NoError(t, err)
+	require.Equal(t, newTip, *hash)
+	require.Equal(t, branchBlock, header.PrevBlock)
+}
+
+// TestHeaderChainInvalidHeaders ensures that headers which aren't valid in the
+// context of the chain are never connected, along with any header building
+// upon them, and that the backend recovers once the API moves to a valid
+// chain.
+func TestHeaderChainInvalidHeaders(t *testing.T) {
+	t.Parallel()
+
+	server := esploratest.NewServer(t)
+	server.MineBlock()
+
+	backend := newTestBackend(t, server)
+	checkBestBlock(t, backend, server.BlockHash(1), 1)
+
+	// A block with a different difficulty than the one required by the
+	// chain is rejected, even though it carries valid proof-of-work for
+	// the difficulty it claims.
+	params := esploratest.ChainParams
+	badBits := params.PowLimitBits - 0x00400000
+	badBlock := server.MineBlockWithBits(badBits)
+	server.MineBlock()
+
+	_, _, err := backend.BestBlock()
+	require.Error(t, err)
+
+	_, err = backend.BlockHeader(&badBlock)
+	require.ErrorIs(t, err, esploraio.ErrUnknownBlock)
+
+	_, err = backend.BlockHash(2)
+	require.Error(t, err)
+
+	// The same goes for a block with invalid proof-of-work.
+	server.Reorg(2)
+	server.MineInvalidBlock()
+
+	_, _, err = backend.BestBlock()
+	require.Error(t, err)
+
+	// Once the API moves to a valid chain, we follow it.
+	server.Reorg(1)
+	server.MineBlock()
+	tip := server.MineBlock()
+	checkBestBlock(t, backend, tip, 3)
+}
+
+// TestHeaderChainPersistence ensures that the header chain resumes from the
+// persisted headers on restart, including after a reorg replaced some of
+// them.
+func TestHeaderChainPersistence(t *testing.T) {
+	t.Parallel()
+
+	server := esploratest.NewServer(t)
+	for i := 0; i < 150; i++ {
+		server.MineBlock()
+	}
+
+	db := newTestDB(t)
+	backend := newTestBackendWithDB(t, server, esploratest.ChainParams, db)
+	checkBestBlock(t, backend, server.BlockHash(150), 150)
+
+	// A restarted backend knows of the headers before syncing with the
+	// API.
+	backend = newTestBackendWithDB(t, server, esploratest.ChainParams, db)
+	hash, err := backend.BlockHash(150)
+	require.NoError(t, err)
+	require.Equal(t, server.BlockHash(150), *hash)
+
+	// Replace the last blocks with a shorter branch carrying more work,
+	// which replaces the persisted headers of the reorged blocks.
+	server.Reorg(2)
+	branchTip := server.MineBlock()
+	server.MineBlock()
+	server.MineBlock()
+	tip := server.MineBlock()
+	checkBestBlock(t, backend, tip, 152)
+
+	backend = newTestBackendWithDB(t, server, esploratest.ChainParams, db)
+	hash, err = backend.BlockHash(149)
+	require.NoError(t, err)
+	require.Equal(t, branchTip, *hash)
+
+	hash, err = backend.BlockHash(152)
+	require.NoError(t, err)
+	require.Equal(t, tip, *hash)
+	checkBestBlock(t, backend, tip, 152)
+}
+
+// TestHeaderChainCheckpoint ensures that a header chain without any persisted
+// headers starts from the last checkpoint the API's chain has reached.
+func TestHeaderChainCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	server := esploratest.NewServer(t)
+	for i := 0; i < 150; i++ {
+		server.MineBlock()
+	}
+
+	checkpointHash := server.BlockHash(100)
+	futureHash := chainhash.Hash{1}
+	chainParams := *esploratest.ChainParams
+	chainParams.Checkpoints = []chaincfg.Checkpoint{
+		{Height: 100, Hash: &checkpointHash},
+		{Height: 200, Hash: &futureHash},
+	}
+
+	backend := newTestBackendWithDB(t, server, &chainParams, newTestDB(t))
+	checkBestBlock(t, backend, server.BlockHash(150), 150)
+
+	// The headers before the checkpoint aren't part of the chain, except
+	// for the ones needed to check the timestamps of its successors.
+	_, err := backend.BlockHash(50)
+	require.Error(t, err)
+
+	hash, err := backend.BlockHash(100)
+	require.NoError(t, err)
+	require.Equal(t, checkpointHash, *hash)
+
+	hash, err = backend.BlockHash(89)
+	require.NoError(t, err)
+	require.Equal(t, server.BlockHash(89), *hash)
+}
@@ -0,0 +1,90 @@
+package esploraio
+
+import (
+	"errors"
+
+	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwallet/btcwallet"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+// ChainIO is an implementation of the lnwallet.BlockChainIO interface that's
+// backed by an Esplora API.
+type ChainIO struct {
+	backend *Backend
+}
+
+// A compile time check to ensure ChainIO implements the lnwallet.BlockChainIO
+// interface.
+var _ lnwallet.BlockChainIO = (*ChainIO)(nil)
+
+// NewChainIO creates a new ChainIO backed by the given Esplora backend.
+func NewChainIO(backend *Backend) *ChainIO {
+	return &ChainIO{
+		backend: backend,
+	}
+}
+
+// GetBestBlock returns the current height and hash of the best known block
+// within the main chain.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (c *ChainIO) GetBestBlock() (*chainhash.Hash, int32, error) {
+	return c.backend.BestBlock()
+}
+
+// GetUtxo returns the original output referenced by the passed outpoint that
+// creates the target pkScript.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (c *ChainIO) GetUtxo(op *wire.OutPoint, pkScript []byte,
+	_ uint32, _ <-chan struct{}) (*wire.TxOut, error) {
+
+	tx, err := c.backend.Transaction(&op.Hash)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return nil, btcwallet.ErrOutputNotFound
+
+	case err != nil:
+		return nil, err
+	}
+
+	if op.Index >= uint32(len(tx.TxOut)) {
+		return nil, btcwallet.ErrOutputNotFound
+	}
+
+	outSpend, err := c.backend.client.OutSpend(op)
+	if err != nil {
+		return nil, err
+	}
+	if outSpend.Spent {
+		return nil, btcwallet.ErrOutputSpent
+	}
+
+	return tx.TxOut[op.Index], nil
+}
+
+// GetBlockHash returns the hash of the block in the best blockchain at the
+// given height.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (c *ChainIO) GetBlockHash(blockHeight int64) (*chainhash.Hash, error) {
+	return c.backend.BlockHash(int32(blockHeight))
+}
+
+// GetBlock returns the block in the main chain identified by the given hash.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (c *ChainIO) GetBlock(blockHash *chainhash.Hash) (*wire.MsgBlock, error) {
+	return c.backend.GetBlock(blockHash)
+}
+
+// GetBlockHeader returns the block header for the given block hash.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (c *ChainIO) GetBlockHeader(
+	blockHash *chainhash.Hash) (*wire.BlockHeader, error) {
+
+	return c.backend.BlockHeader(blockHash)
+}
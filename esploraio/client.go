@@ -0,0 +1,324 @@
+package esploraio
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+const (
+	// maxResponseSize is the maximum size of a response we'll read from
+	// the Esplora API, which is large enough to hold any raw block.
+	maxResponseSize = 32 * 1024 * 1024
+)
+
+var (
+	// ErrNotFound is returned when the Esplora API doesn't know about the
+	// requested block, transaction or output.
+	ErrNotFound = errors.New("not found")
+)
+
+// TxStatus describes whether and where a transaction was confirmed.
+type TxStatus struct {
+	// Confirmed is true if the transaction is included in a block of the
+	// main chain.
+	Confirmed bool `json:"confirmed"`
+
+	// BlockHeight is the height of the block the transaction is included
+	// in, if confirmed.
+	BlockHeight uint32 `json:"block_height"`
+
+	// BlockHash is the hash of the block the transaction is included in,
+	// if confirmed.
+	BlockHash string `json:"block_hash"`
+}
+
+// ScriptTx is an entry in the transaction history of an output script.
+type ScriptTx struct {
+	// TxID is the hash of the transaction.
+	TxID string `json:"txid"`
+
+	// Status describes whether and where the transaction was confirmed.
+	Status TxStatus `json:"status"`
+}
+
+// OutSpend describes whether and by which transaction an output was spent.
+type OutSpend struct {
+	// Spent is true if the output was spent, either in the mempool or in
+	// the main chain.
+	Spent bool `json:"spent"`
+
+	// TxID is the hash of the spending transaction, if spent.
+	TxID string `json:"txid"`
+
+	// Vin is the index of the spending input, if spent.
+	Vin uint32 `json:"vin"`
+
+	// Status describes whether and where the spending transaction was
+	// confirmed.
+	Status TxStatus `json:"status"`
+}
+
+// BlockStatus describes whether a block is part of the main chain.
+type BlockStatus struct {
+	// InBestChain is true if the block is part of the main chain.
+	InBestChain bool `json:"in_best_chain"`
+
+	// Height is the height of the block.
+	Height int32 `json:"height"`
+}
+
+// Client is a minimal client of the Esplora HTTP API. All requests made by the
+// client are given a fixed amount of time to complete.
+type Client struct {
+	baseURL string
+	timeout time.Duration
+	http    *http.Client
+}
+
+// NewClient creates a new client of the Esplora API found at the given base
+// URL.
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		timeout: timeout,
+		http:    &http.Client{},
+	}
+}
+
+// URL returns the full URL of the given API path.
+func (c *Client) URL(path string) string {
+	return c.baseURL + path
+}
+
+// ScriptHash returns the Esplora script hash of an output script, which is
+// the hex encoding of the reversed sha256 of the script.
+func ScriptHash(pkScript []byte) string {
+	hash := sha256.Sum256(pkScript)
+	for i, j := 0, len(hash)-1; i < j; i, j = i+1, j-1 {
+		hash[i], hash[j] = hash[j], hash[i]
+	}
+
+	return hex.EncodeToString(hash[:])
+}
+
+// do performs an HTTP request against the given API path and returns the body
+// of the response.
+func (c *Client) do(method, path string, body io.Reader) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.URL(path), body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", path, ErrNotFound)
+
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s: unexpected status %d: %s", path,
+			resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+
+	return respBody, nil
+}
+
+// get performs a GET request against the given API path.
+func (c *Client) get(path string) ([]byte, error) {
+	return c.do(http.MethodGet, path, nil)
+}
+
+// getJSON performs a GET request against the given API path and decodes the
+// JSON response into v.
+func (c *Client) getJSON(path string, v interface{}) error {
+	body, err := c.get(path)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%s: unable to decode response: %w", path,
+			err)
+	}
+
+	return nil
+}
+
+// getHash performs a GET request against the given API path, whose response
+// is expected to be a hex encoded hash.
+func (c *Client) getHash(path string) (*chainhash.Hash, error) {
+	body, err := c.get(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainhash.NewHashFromStr(string(bytes.TrimSpace(body)))
+}
+
+// TipHash returns the hash of the tip of the main chain.
+func (c *Client) TipHash() (*chainhash.Hash, error) {
+	return c.getHash("/blocks/tip/hash")
+}
+
+// BlockHash returns the hash of the main chain block at the given height.
+func (c *Client) BlockHash(height int32) (*chainhash.Hash, error) {
+	return c.getHash(fmt.Sprintf("/block-height/%d", height))
+}
+
+// BlockStatus returns whether the block with the given hash is part of the
+// main chain, along with its height.
+func (c *Client) BlockStatus(hash *chainhash.Hash) (*BlockStatus, error) {
+	var status BlockStatus
+	err := c.getJSON(fmt.Sprintf("/block/%v/status", hash), &status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// BlockHeader returns the header of the block with the given hash.
+func (c *Client) BlockHeader(hash *chainhash.Hash) (*wire.BlockHeader,
+	error) {
+
+	body, err := c.get(fmt.Sprintf("/block/%v/header", hash))
+	if err != nil {
+		return nil, err
+	}
+
+	rawHeader, err := hex.DecodeString(string(bytes.TrimSpace(body)))
+	if err != nil {
+		return nil, err
+	}
+
+	var header wire.BlockHeader
+	if err := header.Deserialize(bytes.NewReader(rawHeader)); err != nil {
+		return nil, err
+	}
+
+	return &header, nil
+}
+
+// Block returns the block with the given hash.
+func (c *Client) Block(hash *chainhash.Hash) (*wire.MsgBlock, error) {
+	body, err := c.get(fmt.Sprintf("/block/%v/raw", hash))
+	if err != nil {
+		return nil, err
+	}
+
+	var block wire.MsgBlock
+	if err := block.Deserialize(bytes.NewReader(body)); err != nil {
+		return nil, err
+	}
+
+	return &block, nil
+}
+
+// Transaction returns the transaction with the given hash.
+func (c *Client) Transaction(txid *chainhash.Hash) (*wire.MsgTx, error) {
+	body, err := c.get(fmt.Sprintf("/tx/%v/hex", txid))
+	if err != nil {
+		return nil, err
+	}
+
+	rawTx, err := hex.DecodeString(string(bytes.TrimSpace(body)))
+	if err != nil {
+		return nil, err
+	}
+
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		return nil, err
+	}
+
+	return &tx, nil
+}
+
+// TxStatus returns whether and where the transaction with the given hash was
+// confirmed.
+func (c *Client) TxStatus(txid *chainhash.Hash) (*TxStatus, error) {
+	var status TxStatus
+	err := c.getJSON(fmt.Sprintf("/tx/%v/status", txid), &status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// OutSpend returns whether and by which transaction the given output was
+// spent.
+func (c *Client) OutSpend(op *wire.OutPoint) (*OutSpend, error) {
+	var outSpend OutSpend
+	err := c.getJSON(
+		fmt.Sprintf("/tx/%v/outspend/%d", op.Hash, op.Index), &outSpend,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &outSpend, nil
+}
+
+// ScriptTxs returns the confirmed transaction history of the given output
+// script, newest first. The API returns the history in pages, which are all
+// fetched.
+func (c *Client) ScriptTxs(pkScript []byte) ([]ScriptTx, error) {
+	path := fmt.Sprintf("/scripthash/%s/txs/chain", ScriptHash(pkScript))
+
+	var history []ScriptTx
+	for lastSeen := ""; ; {
+		var page []ScriptTx
+		if err := c.getJSON(path+lastSeen, &page); err != nil {
+			return nil, err
+		}
+		if len(page) == 0 {
+			return history, nil
+		}
+
+		history = append(history, page...)
+		lastSeen = "/" + page[len(page)-1].TxID
+	}
+}
+
+// Broadcast publishes the given transaction to the network.
+func (c *Client) Broadcast(tx *wire.MsgTx) (*chainhash.Hash, error) {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return nil, err
+	}
+
+	body, err := c.do(
+		http.MethodPost, "/tx",
+		strings.NewReader(hex.EncodeToString(buf.Bytes())),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainhash.NewHashFromStr(string(bytes.TrimSpace(body)))
+}
@@ -0,0 +1,634 @@
+// Package esploratest provides a fake Esplora API backed by an in-memory
+// chain, to be used in tests of the Esplora chain backend.
+package esploratest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/esploraio"
+	"github.com/flokiorg/go-flokicoin/blockchain"
+	"github.com/flokiorg/go-flokicoin/chaincfg"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/mining"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// historyPageSize is the number of transactions returned per page of
+	// a script's history. It's kept small to exercise pagination.
+	historyPageSize = 5
+)
+
+// ChainParams are the parameters of the chain served by the fake API. Its
+// blocks are mined against the proof-of-work limit of these parameters.
+var ChainParams = &chaincfg.RegressionNetParams
+
+// txStatus is the JSON representation of a transaction's status.
+type txStatus struct {
+	Confirmed   bool   `json:"confirmed"`
+	BlockHeight uint32 `json:"block_height,omitempty"`
+	BlockHash   string `json:"block_hash,omitempty"`
+}
+
+// Server is a fake Esplora API that serves an in-memory chain, which tests
+// can extend and reorg at will.
+type Server struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mtx sync.Mutex
+
+	// chain holds the blocks of the main chain, by height.
+	chain []*wire.MsgBlock
+
+	// blocks holds all blocks ever mined, including the ones that were
+	// reorged out, by hash.
+	blocks map[chainhash.Hash]*wire.MsgBlock
+
+	// mempool holds the transactions that were broadcast or added, but
+	// haven't been mined yet.
+	mempool map[chainhash.Hash]*wire.MsgTx
+
+	fees map[uint32]float64
+}
+
+// NewServer starts a new fake Esplora API whose chain only holds the genesis
+// block of ChainParams. The server is stopped once the test completes.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+
+	s := &Server{
+		t:       t,
+		blocks:  make(map[chainhash.Hash]*wire.MsgBlock),
+		mempool: make(map[chainhash.Hash]*wire.MsgTx),
+		fees:    make(map[uint32]float64),
+	}
+	s.connectBlock(ChainParams.GenesisBlock)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /blocks/tip/hash", s.handleTipHash)
+	mux.HandleFunc("GET /block-height/{height}", s.handleBlockHeight)
+	mux.HandleFunc("GET /block/{hash}/status", s.handleBlockStatus)
+	mux.HandleFunc("GET /block/{hash}/header", s.handleBlockHeader)
+	mux.HandleFunc("GET /block/{hash}/raw", s.handleBlockRaw)
+	mux.HandleFunc("GET /tx/{txid}/hex", s.handleTxHex)
+	mux.HandleFunc("GET /tx/{txid}/status", s.handleTxStatus)
+	mux.HandleFunc("GET /tx/{txid}/outspend/{vout}", s.handleOutSpend)
+	mux.HandleFunc(
+		"GET /scripthash/{hash}/txs/chain", s.handleScriptTxs,
+	)
+	mux.HandleFunc(
+		"GET /scripthash/{hash}/txs/chain/{lastSeen}",
+		s.handleScriptTxs,
+	)
+	mux.HandleFunc("GET /fee-estimates", s.handleFeeEstimates)
+	mux.HandleFunc("POST /tx", s.handleBroadcast)
+
+	s.server = httptest.NewServer(mux)
+	t.Cleanup(s.server.Close)
+
+	return s
+}
+
+// URL returns the base URL of the fake API.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// BestBlock returns the hash and height of the tip of the main chain.
+func (s *Server) BestBlock() (chainhash.Hash, int32) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	height := len(s.chain) - 1
+
+	return s.chain[height].BlockHash(), int32(height)
+}
+
+// BlockHash returns the hash of the main chain block at the given height.
+func (s *Server) BlockHash(height int32) chainhash.Hash {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.chain[height].BlockHash()
+}
+
+// SetFee sets the fee rate, in sat/vbyte, estimated for the given
+// confirmation target.
+func (s *Server) SetFee(target uint32, feeRate float64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.fees[target] = feeRate
+}
+
+// AddMempoolTx adds a transaction to the mempool, to be included in the next
+// block.
+func (s *Server) AddMempoolTx(tx *wire.MsgTx) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.mempool[tx.TxHash()] = tx
+}
+
+// MineBlock mines a new block on top of the main chain including the given
+// transactions, along with all transactions of the mempool. The hash of the
+// new block is returned.
+func (s *Server) MineBlock(txs ...*wire.MsgTx) chainhash.Hash {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	block := s.newBlock(txs, ChainParams.PowLimitBits, false)
+	s.connectBlock(block)
+
+	return block.BlockHash()
+}
+
+// MineInvalidBlock mines a new block on top of the main chain whose header
+// doesn't carry valid proof-of-work. The hash of the new block is returned.
+func (s *Server) MineInvalidBlock() chainhash.Hash {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	block := s.newBlock(nil, ChainParams.PowLimitBits, true)
+	s.connectBlock(block)
+
+	return block.BlockHash()
+}
+
+// MineBlockWithBits mines a new block on top of the main chain whose header
+// carries valid proof-of-work for the given difficulty bits, rather than the
+// difficulty required by ChainParams. The hash of the new block is returned.
+func (s *Server) MineBlockWithBits(bits uint32) chainhash.Hash {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	block := s.newBlock(nil, bits, false)
+	s.connectBlock(block)
+
+	return block.BlockHash()
+}
+
+// Reorg removes the given number of blocks from the tip of the main chain. The
+// transactions of these blocks are dropped rather than returned to the
+// mempool, so tests control whether they confirm again.
+func (s *Server) Reorg(depth int32) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	require.Less(s.t, int(depth), len(s.chain))
+
+	s.chain = s.chain[:len(s.chain)-int(depth)]
+}
+
+// connectBlock adds the given block to the tip of the main chain, removing its
+// transactions from the mempool.
+func (s *Server) connectBlock(block *wire.MsgBlock) {
+	for _, tx := range block.Transactions {
+		delete(s.mempool, tx.TxHash())
+	}
+
+	s.chain = append(s.chain, block)
+	s.blocks[block.BlockHash()] = block
+}
+
+// newBlock creates a new block on top of the main chain including the given
+// transactions and the mempool. Its header carries valid proof-of-work for the
+// given difficulty bits, unless invalidPoW is set.
+func (s *Server) newBlock(txs []*wire.MsgTx, bits uint32,
+	invalidPoW bool) *wire.MsgBlock {
+
+	height := uint32(len(s.chain))
+
+	// The coinbase commits to the number of blocks mined so far, so that
+	// all blocks are unique, even across reorgs.
+	var extraNonce [4]byte
+	binary.LittleEndian.PutUint32(extraNonce[:], uint32(len(s.blocks)))
+	coinbase := wire.NewMsgTx(2)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		SignatureScript:  append([]byte{0x04}, extraNonce[:]...),
+	})
+	coinbase.AddTxOut(&wire.TxOut{Value: 50e8, PkScript: []byte{0x51}})
+
+	msgTxs := []*wire.MsgTx{coinbase}
+	included := make(map[chainhash.Hash]struct{})
+	for _, tx := range txs {
+		msgTxs = append(msgTxs, tx)
+		included[tx.TxHash()] = struct{}{}
+	}
+	for txid, tx := range s.mempool {
+		if _, ok := included[txid]; !ok {
+			msgTxs = append(msgTxs, tx)
+		}
+	}
+
+	utilTxs := make([]*chainutil.Tx, 0, len(msgTxs))
+	for _, tx := range msgTxs {
+		utilTxs = append(utilTxs, chainutil.NewTx(tx))
+	}
+	mining.AddWitnessCommitment(utilTxs[0], utilTxs)
+
+	prevHeader := s.chain[height-1].Header
+
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    1,
+			PrevBlock:  prevHeader.BlockHash(),
+			MerkleRoot: blockchain.CalcMerkleRoot(utilTxs, false),
+			Timestamp:  prevHeader.Timestamp.Add(time.Minute),
+			Bits:       bits,
+		},
+		Transactions: msgTxs,
+	}
+
+	// Grind the nonce until the header's validity matches what we're
+	// asked for.
+	target := blockchain.CompactToBig(block.Header.Bits)
+	for {
+		powHash := block.Header.BlockPoWHash()
+		valid := blockchain.HashToBig(&powHash).Cmp(target) <= 0
+		if valid != invalidPoW {
+			return block
+		}
+
+		block.Header.Nonce++
+	}
+}
+
+// mainChainHeight returns the height of the given block if it's part of the
+// main chain.
+func (s *Server) mainChainHeight(hash chainhash.Hash) (uint32, bool) {
+	block, ok := s.blocks[hash]
+	if !ok {
+		return 0, false
+	}
+
+	for height := len(s.chain) - 1; height >= 0; height-- {
+		if s.chain[height] == block {
+			return uint32(height), true
+		}
+	}
+
+	return 0, false
+}
+
+// lookupTx returns the transaction with the given hash, along with its status.
+func (s *Server) lookupTx(txid chainhash.Hash) (*wire.MsgTx, txStatus, bool) {
+	for height, block := range s.chain {
+		for _, tx := range block.Transactions {
+			if tx.TxHash() != txid {
+				continue
+			}
+
+			return tx, txStatus{
+				Confirmed:   true,
+				BlockHeight: uint32(height),
+				BlockHash:   block.BlockHash().String(),
+			}, true
+		}
+	}
+
+	if tx, ok := s.mempool[txid]; ok {
+		return tx, txStatus{}, true
+	}
+
+	return nil, txStatus{}, false
+}
+
+// touchesScript returns true if the given transaction pays to or spends from
+// the output script with the given script hash.
+func (s *Server) touchesScript(tx *wire.MsgTx, scriptHash string) bool {
+	for _, txOut := range tx.TxOut {
+		if esploraio.ScriptHash(txOut.PkScript) == scriptHash {
+			return true
+		}
+	}
+
+	for _, txIn := range tx.TxIn {
+		prevTx, _, ok := s.lookupTx(txIn.PreviousOutPoint.Hash)
+		if !ok || txIn.PreviousOutPoint.Index >= uint32(len(prevTx.TxOut)) {
+			continue
+		}
+
+		pkScript := prevTx.TxOut[txIn.PreviousOutPoint.Index].PkScript
+		if esploraio.ScriptHash(pkScript) == scriptHash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeText writes a plain text response.
+func writeText(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = io.WriteString(w, text)
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// pathHash parses the hash found at the given wildcard of the request's path.
+func pathHash(w http.ResponseWriter, r *http.Request,
+	name string) (chainhash.Hash, bool) {
+
+	hash, err := chainhash.NewHashFromStr(r.PathValue(name))
+	if err != nil {
+		http.Error(w, "invalid hash", http.StatusBadRequest)
+		return chainhash.Hash{}, false
+	}
+
+	return *hash, true
+}
+
+// lookupBlock returns the block found at the request path's hash.
+func (s *Server) lookupBlock(w http.ResponseWriter,
+	r *http.Request) (*wire.MsgBlock, bool) {
+
+	hash, ok := pathHash(w, r, "hash")
+	if !ok {
+		return nil, false
+	}
+
+	block, ok := s.blocks[hash]
+	if !ok {
+		http.Error(w, "Block not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	return block, true
+}
+
+// handleTipHash serves the hash of the tip of the main chain.
+func (s *Server) handleTipHash(w http.ResponseWriter, _ *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	writeText(w, s.chain[len(s.chain)-1].BlockHash().String())
+}
+
+// handleBlockHeight serves the hash of the main chain block at a height.
+func (s *Server) handleBlockHeight(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	height, err := strconv.Atoi(r.PathValue("height"))
+	if err != nil || height < 0 || height >= len(s.chain) {
+		http.Error(w, "Block not found", http.StatusNotFound)
+		return
+	}
+
+	writeText(w, s.chain[height].BlockHash().String())
+}
+
+// handleBlockStatus serves whether a block is part of the main chain.
+func (s *Server) handleBlockStatus(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	block, ok := s.lookupBlock(w, r)
+	if !ok {
+		return
+	}
+
+	height, inBestChain := s.mainChainHeight(block.BlockHash())
+	writeJSON(w, map[string]interface{}{
+		"in_best_chain": inBestChain,
+		"height":        height,
+	})
+}
+
+// handleBlockHeader serves the hex encoded header of a block.
+func (s *Server) handleBlockHeader(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	block, ok := s.lookupBlock(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	require.NoError(s.t, block.Header.Serialize(&buf))
+	writeText(w, hex.EncodeToString(buf.Bytes()))
+}
+
+// handleBlockRaw serves the serialized block.
+func (s *Server) handleBlockRaw(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	block, ok := s.lookupBlock(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	require.NoError(s.t, block.Serialize(&buf))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handleTxHex serves the hex encoded transaction.
+func (s *Server) handleTxHex(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	txid, ok := pathHash(w, r, "txid")
+	if !ok {
+		return
+	}
+
+	tx, _, ok := s.lookupTx(txid)
+	if !ok {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+
+	var buf bytes.Buffer
+	require.NoError(s.t, tx.Serialize(&buf))
+	writeText(w, hex.EncodeToString(buf.Bytes()))
+}
+
+// handleTxStatus serves whether and where a transaction was confirmed.
+func (s *Server) handleTxStatus(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	txid, ok := pathHash(w, r, "txid")
+	if !ok {
+		return
+	}
+
+	_, status, ok := s.lookupTx(txid)
+	if !ok {
+		http.Error(w, "Transaction not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, status)
+}
+
+// handleOutSpend serves whether and by which transaction an output was spent.
+func (s *Server) handleOutSpend(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	txid, ok := pathHash(w, r, "txid")
+	if !ok {
+		return
+	}
+	vout, err := strconv.ParseUint(r.PathValue("vout"), 10, 32)
+	if err != nil {
+		http.Error(w, "invalid vout", http.StatusBadRequest)
+		return
+	}
+	op := wire.OutPoint{Hash: txid, Index: uint32(vout)}
+
+	// Look for a spend in the main chain first, and then the mempool.
+	spent := func(txs []*wire.MsgTx, status txStatus) bool {
+		for _, tx := range txs {
+			for i, txIn := range tx.TxIn {
+				if txIn.PreviousOutPoint != op {
+					continue
+				}
+
+				writeJSON(w, map[string]interface{}{
+					"spent":  true,
+					"txid":   tx.TxHash().String(),
+					"vin":    i,
+					"status": status,
+				})
+
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for height, block := range s.chain {
+		status := txStatus{
+			Confirmed:   true,
+			BlockHeight: uint32(height),
+			BlockHash:   block.BlockHash().String(),
+		}
+		if spent(block.Transactions, status) {
+			return
+		}
+	}
+
+	mempool := make([]*wire.MsgTx, 0, len(s.mempool))
+	for _, tx := range s.mempool {
+		mempool = append(mempool, tx)
+	}
+	if spent(mempool, txStatus{}) {
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{"spent": false})
+}
+
+// handleScriptTxs serves a page of the confirmed history of a script.
+func (s *Server) handleScriptTxs(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	scriptHash := r.PathValue("hash")
+	lastSeen := r.PathValue("lastSeen")
+
+	// The history is returned newest first.
+	var history []map[string]interface{}
+	for height := len(s.chain) - 1; height >= 0; height-- {
+		block := s.chain[height]
+		for i := len(block.Transactions) - 1; i >= 0; i-- {
+			tx := block.Transactions[i]
+			if !s.touchesScript(tx, scriptHash) {
+				continue
+			}
+
+			history = append(history, map[string]interface{}{
+				"txid": tx.TxHash().String(),
+				"status": txStatus{
+					Confirmed:   true,
+					BlockHeight: uint32(height),
+					BlockHash:   block.BlockHash().String(),
+				},
+			})
+		}
+	}
+
+	// Skip all transactions up to and including the last one seen.
+	if lastSeen != "" {
+		for i, entry := range history {
+			if entry["txid"] == lastSeen {
+				history = history[i+1:]
+				break
+			}
+		}
+	}
+
+	if len(history) > historyPageSize {
+		history = history[:historyPageSize]
+	}
+	if history == nil {
+		history = []map[string]interface{}{}
+	}
+
+	writeJSON(w, history)
+}
+
+// handleFeeEstimates serves the fee rates estimated per confirmation target.
+func (s *Server) handleFeeEstimates(w http.ResponseWriter, _ *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	fees := make(map[string]float64, len(s.fees))
+	for target, feeRate := range s.fees {
+		fees[strconv.FormatUint(uint64(target), 10)] = feeRate
+	}
+
+	writeJSON(w, fees)
+}
+
+// handleBroadcast adds the posted transaction to the mempool.
+func (s *Server) handleBroadcast(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rawTx, err := hex.DecodeString(string(bytes.TrimSpace(body)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		http.Error(w, fmt.Sprintf("invalid transaction: %v", err),
+			http.StatusBadRequest)
+		return
+	}
+
+	s.AddMempoolTx(&tx)
+	writeText(w, tx.TxHash().String())
+}
@@ -0,0 +1,467 @@
+package esploraio
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/flokiorg/go-flokicoin/blockchain"
+	"github.com/flokiorg/go-flokicoin/chaincfg"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+var (
+	// errUnknownParent is returned when a header that builds upon a header
+	// we don't know of is connected to the header chain.
+	errUnknownParent = errors.New("header builds upon unknown header")
+)
+
+const (
+	// medianTimeHeaders is the number of headers the median time past of
+	// a header is computed over. A header chain that doesn't start at the
+	// genesis header starts this many headers before its checkpoint, so
+	// that the timestamps of the headers building upon it can be checked.
+	medianTimeHeaders = 11
+)
+
+// headerNode is a header that was connected to the header chain. Only the
+// fields needed to validate the headers building upon it are kept, the full
+// header is stored in the backend's header cache.
+type headerNode struct {
+	hash      chainhash.Hash
+	parent    *headerNode
+	height    int32
+	bits      uint32
+	timestamp int64
+
+	// workSum is the total amount of work of the chain up to and
+	// including this header.
+	workSum *big.Int
+}
+
+// A compile time check to ensure headerNode implements the
+// blockchain.HeaderCtx interface.
+var _ blockchain.HeaderCtx = (*headerNode)(nil)
+
+// Height returns the height of the header.
+//
+// NOTE: Part of the blockchain.HeaderCtx interface.
+func (n *headerNode) Height() int32 {
+	return n.height
+}
+
+// Bits returns the difficulty bits of the header.
+//
+// NOTE: Part of the blockchain.HeaderCtx interface.
+func (n *headerNode) Bits() uint32 {
+	return n.bits
+}
+
+// Timestamp returns the timestamp of the header.
+//
+// NOTE: Part of the blockchain.HeaderCtx interface.
+func (n *headerNode) Timestamp() int64 {
+	return n.timestamp
+}
+
+// Parent returns the header this header builds upon.
+//
+// NOTE: Part of the blockchain.HeaderCtx interface.
+func (n *headerNode) Parent() blockchain.HeaderCtx {
+	return n.RelativeAncestorCtx(1)
+}
+
+// RelativeAncestorCtx returns the ancestor that is distance headers before
+// this header in the chain.
+//
+// NOTE: Part of the blockchain.HeaderCtx interface.
+func (n *headerNode) RelativeAncestorCtx(
+	distance int32) blockchain.HeaderCtx {
+
+	// The blockchain package compares the returned context against nil,
+	// so we can't return a nil *headerNode.
+	ancestor := n.ancestor(n.height - distance)
+	if ancestor == nil {
+		return nil
+	}
+
+	return ancestor
+}
+
+// ancestor returns the ancestor of the header at the given height, or nil if
+// there's no such ancestor.
+func (n *headerNode) ancestor(height int32) *headerNode {
+	if height < 0 || height > n.height {
+		return nil
+	}
+
+	node := n
+	for node != nil && node.height > height {
+		node = node.parent
+	}
+
+	return node
+}
+
+// headerChain is our local view of the chain, built from the headers served
+// by the Esplora API. Every header is validated in the context of the header
+// it builds upon, the same way a full node would, and the main chain is the
+// chain of headers carrying the most work. This way, the API can't make us
+// follow a chain that wasn't actually mined, nor hide the chain with the most
+// work behind a less worked one.
+type headerChain struct {
+	chainParams *chaincfg.Params
+	timeSource  blockchain.MedianTimeSource
+
+	minRetargetTimespan int64
+	maxRetargetTimespan int64
+	blocksPerRetarget   int32
+
+	mtx sync.RWMutex
+
+	// index holds all headers we've connected, including the ones that
+	// aren't part of the main chain, by hash.
+	index map[chainhash.Hash]*headerNode
+
+	// mainChain holds the headers of the main chain, by height. The first
+	// header is the genesis header, or the header we started from if the
+	// chain was started from a checkpoint.
+	mainChain []*headerNode
+
+	// dirtyHeight is the height of the first header of the main chain
+	// that wasn't persisted yet.
+	dirtyHeight int32
+}
+
+// A compile time check to ensure headerChain implements the
+// blockchain.ChainCtx interface.
+var _ blockchain.ChainCtx = (*headerChain)(nil)
+
+// newHeaderChain creates a new header chain for the given chain, which only
+// holds its genesis header.
+func newHeaderChain(chainParams *chaincfg.Params) *headerChain {
+	targetTimespan := int64(chainParams.TargetTimespan / time.Second)
+	targetTimePerBlock := int64(
+		chainParams.TargetTimePerBlock / time.Second,
+	)
+	adjustmentFactor := chainParams.RetargetAdjustmentFactor
+
+	c := &headerChain{
+		chainParams:         chainParams,
+		timeSource:          blockchain.NewMedianTime(),
+		minRetargetTimespan: targetTimespan / adjustmentFactor,
+		maxRetargetTimespan: targetTimespan * adjustmentFactor,
+		blocksPerRetarget: int32(
+			targetTimespan / targetTimePerBlock,
+		),
+	}
+
+	// The genesis header is valid by definition.
+	genesis := &chainParams.GenesisBlock.Header
+	err := c.reset(0, []*wire.BlockHeader{genesis})
+	if err != nil {
+		panic(fmt.Sprintf("invalid genesis header: %v", err))
+	}
+
+	return c
+}
+
+// reset replaces the chain with the chain made up of the given headers, the
+// first of which is at the given height. The headers must have been validated
+// already, they're only checked to build upon each other, and to start with
+// the genesis header or include a checkpoint. As the work of the headers
+// before the first one is unknown, the work of the chain is only counted from
+// the first header on, which is enough to compare the chains building upon
+// it.
+func (c *headerChain) reset(startHeight int32,
+	headers []*wire.BlockHeader) error {
+
+	if len(headers) == 0 {
+		return errors.New("no headers to start header chain from")
+	}
+
+	var (
+		parent    *headerNode
+		index     = make(map[chainhash.Hash]*headerNode, len(headers))
+		mainChain = make([]*headerNode, 0, len(headers))
+	)
+	for i, header := range headers {
+		node := &headerNode{
+			hash:      header.BlockHash(),
+			parent:    parent,
+			height:    startHeight + int32(i),
+			bits:      header.Bits,
+			timestamp: header.Timestamp.Unix(),
+			workSum:   blockchain.CalcWork(header.Bits),
+		}
+		if parent != nil {
+			if header.PrevBlock != parent.hash {
+				return fmt.Errorf("header %v at height %d "+
+					"doesn't build upon %v", node.hash,
+					node.height, parent.hash)
+			}
+
+			node.workSum.Add(node.workSum, parent.workSum)
+		}
+
+		index[node.hash] = node
+		mainChain = append(mainChain, node)
+		parent = node
+	}
+
+	if !c.anchored(mainChain) {
+		return fmt.Errorf("header chain starting at height %d doesn't "+
+			"start with the genesis header nor include a "+
+			"checkpoint", startHeight)
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.index = index
+	c.mainChain = mainChain
+	c.dirtyHeight = startHeight
+
+	return nil
+}
+
+// anchored returns whether the given chain starts with the genesis header, or
+// includes a checkpoint at its height.
+func (c *headerChain) anchored(chain []*headerNode) bool {
+	if chain[0].height == 0 {
+		return chain[0].hash == *c.chainParams.GenesisHash
+	}
+
+	startHeight := chain[0].height
+	for _, checkpoint := range c.chainParams.Checkpoints {
+		i := checkpoint.Height - startHeight
+		if i < 0 || int(i) >= len(chain) {
+			continue
+		}
+
+		if chain[i].hash == *checkpoint.Hash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mainChainAt returns the header of the main chain at the given height, or nil
+// if the main chain doesn't include the height. The chain's mutex must be held
+// by the caller.
+func (c *headerChain) mainChainAt(height int32) *headerNode {
+	i := height - c.mainChain[0].height
+	if i < 0 || int(i) >= len(c.mainChain) {
+		return nil
+	}
+
+	return c.mainChain[i]
+}
+
+// ChainParams returns the parameters of the chain.
+//
+// NOTE: Part of the blockchain.ChainCtx interface.
+func (c *headerChain) ChainParams() *chaincfg.Params {
+	return c.chainParams
+}
+
+// BlocksPerRetarget returns the number of blocks before retargeting occurs.
+//
+// NOTE: Part of the blockchain.ChainCtx interface.
+func (c *headerChain) BlocksPerRetarget() int32 {
+	return c.blocksPerRetarget
+}
+
+// MinRetargetTimespan returns the minimum amount of time used in the
+// difficulty calculation.
+//
+// NOTE: Part of the blockchain.ChainCtx interface.
+func (c *headerChain) MinRetargetTimespan() int64 {
+	return c.minRetargetTimespan
+}
+
+// MaxRetargetTimespan returns the maximum amount of time used in the
+// difficulty calculation.
+//
+// NOTE: Part of the blockchain.ChainCtx interface.
+func (c *headerChain) MaxRetargetTimespan() int64 {
+	return c.maxRetargetTimespan
+}
+
+// VerifyCheckpoint returns whether the given hash matches the checkpoint at
+// the given height, if there's one.
+//
+// NOTE: Part of the blockchain.ChainCtx interface.
+func (c *headerChain) VerifyCheckpoint(height int32,
+	hash *chainhash.Hash) bool {
+
+	for _, checkpoint := range c.chainParams.Checkpoints {
+		if checkpoint.Height == height {
+			return *checkpoint.Hash == *hash
+		}
+	}
+
+	return true
+}
+
+// FindPreviousCheckpoint returns the header of the latest checkpoint our main
+// chain has reached, or nil if it hasn't reached any.
+//
+// NOTE: Part of the blockchain.ChainCtx interface. The chain's mutex must be
+// held by the caller.
+func (c *headerChain) FindPreviousCheckpoint() (blockchain.HeaderCtx, error) {
+	checkpoints := c.chainParams.Checkpoints
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		if node := c.mainChainAt(checkpoints[i].Height); node != nil {
+			return node, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// tip returns the header at the tip of the main chain.
+func (c *headerChain) tip() *headerNode {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	return c.mainChain[len(c.mainChain)-1]
+}
+
+// lookup returns the header with the given hash, or nil if we don't know of
+// it.
+func (c *headerChain) lookup(hash *chainhash.Hash) *headerNode {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	return c.index[*hash]
+}
+
+// headerAt returns the header of the main chain at the given height, or nil
+// if the main chain doesn't reach the height.
+func (c *headerChain) headerAt(height int32) *headerNode {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	return c.mainChainAt(height)
+}
+
+// inMainChain returns whether the given header is part of the main chain.
+func (c *headerChain) inMainChain(node *headerNode) bool {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	return c.isMainChain(node)
+}
+
+// isMainChain returns whether the given header is part of the main chain. The
+// chain's mutex must be held by the caller.
+func (c *headerChain) isMainChain(node *headerNode) bool {
+	return c.mainChainAt(node.height) == node
+}
+
+// connect validates the given header in the context of the header it builds
+// upon, and adds it to the chain. If the chain building upon the header
+// carries more work than the main chain, it becomes the new main chain.
+// errUnknownParent is returned if we don't know of the header it builds upon.
+func (c *headerChain) connect(header *wire.BlockHeader) (*headerNode,
+	error) {
+
+	hash := header.BlockHash()
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if node, ok := c.index[hash]; ok {
+		return node, nil
+	}
+
+	parent, ok := c.index[header.PrevBlock]
+	if !ok {
+		return nil, fmt.Errorf("%w: header %v builds upon %v",
+			errUnknownParent, hash, header.PrevBlock)
+	}
+
+	err := blockchain.CheckBlockHeaderSanity(
+		header, c.chainParams.PowLimit, c.timeSource, blockchain.BFNone,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid header %v: %w", hash, err)
+	}
+
+	err = blockchain.CheckBlockHeaderContext(
+		header, parent, blockchain.BFNone, c, false,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid header %v: %w", hash, err)
+	}
+
+	node := &headerNode{
+		hash:      hash,
+		parent:    parent,
+		height:    parent.height + 1,
+		bits:      header.Bits,
+		timestamp: header.Timestamp.Unix(),
+		workSum: new(big.Int).Add(
+			parent.workSum, blockchain.CalcWork(header.Bits),
+		),
+	}
+	c.index[hash] = node
+
+	// A branch only replaces the main chain once it carries strictly more
+	// work, so we stick with the first chain we've seen among equals.
+	tip := c.mainChain[len(c.mainChain)-1]
+	if node.workSum.Cmp(tip.workSum) > 0 {
+		c.setTip(node)
+	}
+
+	return node, nil
+}
+
+// setTip makes the chain ending in the given header the main chain. The
+// chain's mutex must be held by the caller.
+func (c *headerChain) setTip(node *headerNode) {
+	var branch []*headerNode
+	for !c.isMainChain(node) {
+		branch = append(branch, node)
+		node = node.parent
+	}
+
+	c.mainChain = c.mainChain[:node.height-c.mainChain[0].height+1]
+	for i := len(branch) - 1; i >= 0; i-- {
+		c.mainChain = append(c.mainChain, branch[i])
+	}
+
+	c.dirtyHeight = min(c.dirtyHeight, node.height+1)
+}
+
+// dirtyHeaders returns the headers of the main chain that weren't persisted
+// yet, starting at the returned height.
+func (c *headerChain) dirtyHeaders() (int32, []*headerNode) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+
+	startHeight := max(c.dirtyHeight, c.mainChain[0].height)
+	tip := c.mainChain[len(c.mainChain)-1]
+	if startHeight > tip.height {
+		return startHeight, nil
+	}
+
+	i := startHeight - c.mainChain[0].height
+
+	return startHeight, append([]*headerNode(nil), c.mainChain[i:]...)
+}
+
+// markPersisted records that the headers of the main chain up to the given
+// height were persisted. The main chain must not have changed since its dirty
+// headers were returned.
+func (c *headerChain) markPersisted(height int32) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.dirtyHeight = height + 1
+}
@@ -0,0 +1,139 @@
+package esploraio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+var (
+	// headerBucket is the name of the bucket which houses the headers of
+	// our main chain, by big endian height, so that iterating over the
+	// bucket yields them in chain order.
+	headerBucket = []byte("esplora-headers")
+
+	// errCorruptedHeaderStore is returned when the header bucket doesn't
+	// exist.
+	errCorruptedHeaderStore = errors.New("esplora header store corrupted")
+)
+
+// headerStore persists the headers of the main chain of our header chain, so
+// that they don't have to be fetched from the Esplora API and validated again
+// on every start.
+type headerStore struct {
+	db kvdb.Backend
+}
+
+// newHeaderStore returns a new header store backed by the given database.
+func newHeaderStore(db kvdb.Backend) (*headerStore, error) {
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(headerBucket)
+		return err
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+
+	return &headerStore{db: db}, nil
+}
+
+// heightKey returns the key of the header at the given height.
+func heightKey(height int32) []byte {
+	var key [4]byte
+	binary.BigEndian.PutUint32(key[:], uint32(height))
+
+	return key[:]
+}
+
+// fetchHeaders returns the stored headers, along with the height of the first
+// of them. The headers are returned in chain order.
+func (s *headerStore) fetchHeaders() (int32, []*wire.BlockHeader, error) {
+	var (
+		startHeight int32
+		headers     []*wire.BlockHeader
+	)
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(headerBucket)
+		if bucket == nil {
+			return errCorruptedHeaderStore
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			height := int32(binary.BigEndian.Uint32(k))
+			if len(headers) == 0 {
+				startHeight = height
+			} else if height != startHeight+int32(len(headers)) {
+				return fmt.Errorf("%w: missing header at "+
+					"height %d", errCorruptedHeaderStore,
+					startHeight+int32(len(headers)))
+			}
+
+			header := &wire.BlockHeader{}
+			err := header.Deserialize(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+			headers = append(headers, header)
+
+			return nil
+		})
+	}, func() {
+		startHeight = 0
+		headers = nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return startHeight, headers, nil
+}
+
+// putHeaders stores the given headers, the first of which is at the given
+// height, replacing the stored headers from that height on.
+func (s *headerStore) putHeaders(startHeight int32,
+	headers []*wire.BlockHeader) error {
+
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(headerBucket)
+		if bucket == nil {
+			return errCorruptedHeaderStore
+		}
+
+		// Remove the headers of a branch we've reorged away from,
+		// which may reach beyond the new headers.
+		var staleKeys [][]byte
+		cursor := bucket.ReadWriteCursor()
+		start := heightKey(startHeight)
+		for k, _ := cursor.Seek(start); k != nil; k, _ = cursor.Next() {
+			staleKeys = append(staleKeys, append([]byte(nil), k...))
+		}
+		for _, k := range staleKeys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		for i, header := range headers {
+			var b bytes.Buffer
+			if err := header.Serialize(&b); err != nil {
+				return err
+			}
+
+			key := heightKey(startHeight + int32(i))
+			if err := bucket.Put(key, b.Bytes()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {})
+}
+
+// reset removes all stored headers.
+func (s *headerStore) reset() error {
+	return s.putHeaders(0, nil)
+}
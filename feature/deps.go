@@ -94,6 +94,15 @@ var deps = depDesc{
 	lnwire.Bolt11BlindedPathsOptional: {
 		lnwire.RouteBlindingOptional: {},
 	},
+	lnwire.SpliceOptionalStaging: {
+		lnwire.QuiescenceOptional: {},
+	},
+	lnwire.DynamicCommitmentsOptional: {
+		lnwire.QuiescenceOptional: {},
+	},
+	lnwire.GossipV2Optional: {
+		lnwire.GossipQueriesOptional: {},
+	},
 }
 
 // ValidateDeps asserts that a feature vector sets all features and their
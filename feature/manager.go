@@ -81,6 +81,29 @@ type Config struct {
 	// messaging.
 	NoOnionMessages bool
 
+	// NoDualFunding unsets any bits that signal support for dual-funded
+	// channel establishment.
+	NoDualFunding bool
+
+	// NoSplicing unsets any bits that signal support for splicing.
+	NoSplicing bool
+
+	// NoDynamicCommitments unsets any bits that signal support for the
+	// dynamic commitments protocol.
+	NoDynamicCommitments bool
+
+	// NoGossipV2 unsets any bits that signal support for the taproot
+	// gossip protocol.
+	NoGossipV2 bool
+
+	// NoPeerStorage unsets any bits that signal support for storing
+	// backup blobs on behalf of peers.
+	NoPeerStorage bool
+
+	// NoTrampolineRouting unsets any bits that signal support for relaying
+	// payments as a trampoline node.
+	NoTrampolineRouting bool
+
 	// CustomFeatures is a set of custom features to advertise in each
 	// set.
 	CustomFeatures map[Set][]lnwire.FeatureBit
@@ -215,6 +238,30 @@ func newManager(cfg Config, desc setDesc) (*Manager, error) {
 		if cfg.NoQuiescence {
 			raw.Unset(lnwire.QuiescenceOptional)
 		}
+		if cfg.NoDualFunding {
+			raw.Unset(lnwire.DualFundOptionalStaging)
+			raw.Unset(lnwire.DualFundRequiredStaging)
+		}
+		if cfg.NoSplicing || cfg.NoQuiescence {
+			raw.Unset(lnwire.SpliceOptionalStaging)
+			raw.Unset(lnwire.SpliceRequiredStaging)
+		}
+		if cfg.NoDynamicCommitments || cfg.NoQuiescence {
+			raw.Unset(lnwire.DynamicCommitmentsOptional)
+			raw.Unset(lnwire.DynamicCommitmentsRequired)
+		}
+		if cfg.NoGossipV2 {
+			raw.Unset(lnwire.GossipV2Optional)
+			raw.Unset(lnwire.GossipV2Required)
+		}
+		if cfg.NoPeerStorage {
+			raw.Unset(lnwire.ProvideStorageOptional)
+			raw.Unset(lnwire.ProvideStorageRequired)
+		}
+		if cfg.NoTrampolineRouting {
+			raw.Unset(lnwire.TrampolineRoutingOptional)
+			raw.Unset(lnwire.TrampolineRoutingRequired)
+		}
 		if cfg.NoTaprootOverlay {
 			raw.Unset(lnwire.SimpleTaprootOverlayChansOptional)
 			raw.Unset(lnwire.SimpleTaprootOverlayChansRequired)
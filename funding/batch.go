@@ -8,14 +8,17 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/labels"
 	"github.com/flokiorg/flnd/lnrpc"
 	"github.com/flokiorg/flnd/lnrpc/walletrpc"
 	"github.com/flokiorg/flnd/lnwallet/chanfunding"
+	"github.com/flokiorg/flnd/lnwallet/txbatcher"
 	"github.com/flokiorg/go-flokicoin/chaincfg"
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/chainutil/psbt"
+	"github.com/flokiorg/go-flokicoin/txscript"
 	"github.com/flokiorg/go-flokicoin/wire"
 	"golang.org/x/sync/errgroup"
 )
@@ -169,6 +172,11 @@ type BatchConfig struct {
 	// NetParams contains the current bitcoin network parameters.
 	NetParams *chaincfg.Params
 
+	// ClaimOutputs is an optional function that claims queued wallet
+	// payments from the tx batcher, which are then paid by the batch
+	// funding transaction as well.
+	ClaimOutputs func() fn.Option[*txbatcher.Claim]
+
 	// Quit is the channel that is selected on to recognize if the main
 	// server is shutting down.
 	Quit chan struct{}
@@ -182,6 +190,10 @@ type Batcher struct {
 	channels    []*batchChannel
 	lockedUTXOs []*walletrpc.UtxoLease
 
+	// claim holds the wallet payments added to the funding transaction,
+	// if any.
+	claim fn.Option[*txbatcher.Claim]
+
 	didPublish bool
 }
 
@@ -325,6 +337,10 @@ func (b *Batcher) BatchFund(ctx context.Context,
 		)
 	}
 
+	// Queued wallet payments can ride along in the funding transaction, so
+	// they don't need a transaction of their own.
+	b.addBatchedOutputs(txTemplate)
+
 	// Great, we've now started the channel negotiation successfully with
 	// all peers. This means we know the channel outputs for all channels
 	// and can craft our PSBT now. We take the fee rate and min conf
@@ -435,6 +451,11 @@ func (b *Batcher) BatchFund(ctx context.Context,
 	}
 	b.didPublish = true
 
+	b.claim.WhenSome(func(claim *txbatcher.Claim) {
+		claim.Published(finalTx)
+		claim.Done()
+	})
+
 	rpcPoints := make([]*lnrpc.PendingUpdate, len(b.channels))
 	for idx, channel := range b.channels {
 		rpcPoints[idx] = &lnrpc.PendingUpdate{
@@ -446,6 +467,53 @@ func (b *Batcher) BatchFund(ctx context.Context,
 	return rpcPoints, nil
 }
 
+// addBatchedOutputs claims the queued wallet payments from the tx batcher and
+// adds them to the funding transaction template.
+func (b *Batcher) addBatchedOutputs(txTemplate *walletrpc.TxTemplate) {
+	if b.cfg.ClaimOutputs == nil {
+		return
+	}
+
+	b.cfg.ClaimOutputs().WhenSome(func(claim *txbatcher.Claim) {
+		outputs := make(map[string]uint64, len(claim.Outputs()))
+		for _, out := range claim.Outputs() {
+			_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+				out.PkScript, b.cfg.NetParams,
+			)
+			if err != nil || len(addrs) != 1 {
+				log.Debugf("Not adding batched outputs to "+
+					"funding tx, unable to decode script "+
+					"%x: %v", out.PkScript, err)
+
+				claim.Release()
+				return
+			}
+
+			// The template can only pay to each address once.
+			addr := addrs[0].String()
+			_, inTemplate := txTemplate.Outputs[addr]
+			_, inClaim := outputs[addr]
+			if inTemplate || inClaim {
+				log.Debugf("Not adding batched outputs to "+
+					"funding tx, duplicate address %v", addr)
+
+				claim.Release()
+				return
+			}
+
+			outputs[addr] = uint64(out.Value)
+		}
+
+		for addr, amt := range outputs {
+			txTemplate.Outputs[addr] = amt
+		}
+		b.claim = fn.Some(claim)
+
+		log.Infof("Adding %d batched outputs to batch funding tx",
+			len(outputs))
+	})
+}
+
 // waitForUpdate waits for an incoming channel update (or error) for a single
 // channel.
 //
@@ -490,6 +558,11 @@ func (b *Batcher) cleanup(ctx context.Context) {
 		return
 	}
 
+	// The wallet payments we claimed go back to the tx batcher's queue.
+	b.claim.WhenSome(func(claim *txbatcher.Claim) {
+		claim.Release()
+	})
+
 	// Make sure the error message doesn't sound too scary. These might be
 	// logged quite frequently depending on where exactly things were
 	// aborted. We could just not log any cleanup errors though it might be
@@ -0,0 +1,1079 @@
+package funding
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/chainntnfs"
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/interactivetx"
+	"github.com/flokiorg/flnd/labels"
+	"github.com/flokiorg/flnd/lnpeer"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+var (
+	// ErrFundingTxNotReplaceable is returned when the funding transaction
+	// of a channel can't be replaced by one paying a higher fee rate.
+	ErrFundingTxNotReplaceable = errors.New("funding transaction can't " +
+		"be replaced")
+)
+
+// dualFundStage is the stage of the interactive construction and signing of
+// the funding transaction of a dual-funded channel.
+type dualFundStage uint8
+
+const (
+	// dualFundConstructing means the funding transaction, or a
+	// replacement of it, is being constructed interactively.
+	dualFundConstructing dualFundStage = iota
+
+	// dualFundAwaitRbfAck means we asked the remote party to replace the
+	// funding transaction and wait for it to acknowledge.
+	dualFundAwaitRbfAck
+
+	// dualFundCommitting means a replacement of the funding transaction
+	// was constructed, and we wait for the remote party's signature for
+	// our commitment spending it.
+	dualFundCommitting
+
+	// dualFundSigning means the commitments spending the funding output
+	// were signed, and both parties exchange the signatures for the
+	// inputs they contributed.
+	dualFundSigning
+
+	// dualFundPublished means the funding transaction was fully signed
+	// and published.
+	dualFundPublished
+)
+
+// dualFundRound holds the parameters of a single interactive construction of
+// the funding transaction of a dual-funded channel. A replacement of the
+// funding transaction is constructed in a new round.
+type dualFundRound struct {
+	// initiator is true if we initiated the channel, in which case we
+	// also initiate the construction and add the funding output.
+	initiator bool
+
+	// feeRate is the fee rate the funding transaction pays.
+	feeRate chainfee.SatPerKWeight
+
+	// locktime is the locktime of the funding transaction.
+	locktime uint32
+
+	// localAmt and remoteAmt are the amounts each party contributes to
+	// the funding output.
+	localAmt  chainutil.Amount
+	remoteAmt chainutil.Amount
+
+	// fundingScript is the script of the funding output.
+	fundingScript []byte
+
+	// inputs are the wallet outputs we contribute, and change are our
+	// change outputs.
+	inputs []*lnwallet.Utxo
+	change []*wire.TxOut
+
+	// dustLimit is the minimum value of the outputs added by the remote
+	// party.
+	dustLimit chainutil.Amount
+
+	// session is the construction session of the round, and result the
+	// constructed transaction once the session completed.
+	session *interactivetx.Session
+	result  *interactivetx.Result
+}
+
+// capacity returns the value of the funding output.
+func (r *dualFundRound) capacity() chainutil.Amount {
+	return r.localAmt + r.remoteAmt
+}
+
+// start creates the construction session of the round, whose messages are
+// sent with the passed channel ID.
+func (r *dualFundRound) start(chanID lnwire.ChannelID) error {
+	inputs := make([]interactivetx.Input, 0, len(r.inputs))
+	for _, utxo := range r.inputs {
+		inputs = append(inputs, interactivetx.Input{
+			OutPoint: utxo.OutPoint,
+			PrevTx:   utxo.PrevTx,
+		})
+	}
+
+	var outputs []*wire.TxOut
+	if r.initiator {
+		outputs = append(outputs, &wire.TxOut{
+			Value:    int64(r.capacity()),
+			PkScript: r.fundingScript,
+		})
+	}
+	outputs = append(outputs, r.change...)
+
+	session, err := interactivetx.NewSession(interactivetx.Config{
+		ChanID:             chanID,
+		Initiator:          r.initiator,
+		Locktime:           r.locktime,
+		SharedOutputScript: r.fundingScript,
+		DustLimit:          r.dustLimit,
+		Inputs:             inputs,
+		Outputs:            outputs,
+	})
+	if err != nil {
+		return err
+	}
+
+	r.session = session
+
+	return nil
+}
+
+// receive processes an interactive transaction message of the remote party
+// and sends our reply, if any. True is returned once the construction
+// completed and the constructed transaction was validated.
+func (r *dualFundRound) receive(peer lnpeer.Peer,
+	msg lnwire.Message) (bool, error) {
+
+	if r.session == nil {
+		return false, fmt.Errorf("unexpected %v before the funding "+
+			"transaction construction started", msg.MsgType())
+	}
+
+	reply, err := r.session.ReceiveMsg(msg)
+	if err != nil {
+		return false, err
+	}
+
+	err = fn.MapOptionZ(reply, func(m lnwire.Message) error {
+		return peer.SendMessage(false, m)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if !r.session.Complete() {
+		return false, nil
+	}
+
+	return true, r.finish()
+}
+
+// finish validates the constructed funding transaction. The funding output
+// must hold both contributions, and the remote party must pay the fee for
+// the inputs and outputs it added.
+func (r *dualFundRound) finish() error {
+	result, err := r.session.Result()
+	if err != nil {
+		return err
+	}
+
+	index, err := result.OutputIndex(r.fundingScript)
+	if err != nil {
+		return err
+	}
+	value := chainutil.Amount(result.Tx.TxOut[index].Value)
+	if value != r.capacity() {
+		return fmt.Errorf("funding output has value %v, expected %v",
+			value, r.capacity())
+	}
+
+	in, out := result.Contributed(lntypes.Remote, r.fundingScript)
+	fee := r.feeRate.FeeForWeight(
+		result.Weight(lntypes.Remote, r.fundingScript),
+	)
+	if in < out+r.remoteAmt+fee {
+		return fmt.Errorf("remote inputs of %v don't cover outputs "+
+			"of %v, contribution of %v and fee of %v", in, out,
+			r.remoteAmt, fee)
+	}
+
+	r.result = result
+
+	return nil
+}
+
+// checkFundingPoint returns an error if the passed funding outpoint doesn't
+// point to the funding output of the constructed transaction.
+func (r *dualFundRound) checkFundingPoint(op wire.OutPoint) error {
+	if r.result == nil {
+		return fmt.Errorf("funding transaction not yet constructed")
+	}
+
+	index, err := r.result.OutputIndex(r.fundingScript)
+	if err != nil {
+		return err
+	}
+
+	expected := wire.OutPoint{Hash: r.result.Tx.TxHash(), Index: index}
+	if op != expected {
+		return fmt.Errorf("funding outpoint %v doesn't match "+
+			"constructed funding output %v", op, expected)
+	}
+
+	return nil
+}
+
+// replacement returns a new round that replaces the funding transaction of
+// this round. It spends the same inputs and keeps the contributions of both
+// parties, while our change pays for the higher fee of our inputs and
+// outputs.
+func (r *dualFundRound) replacement(
+	feeRate chainfee.SatPerKWeight) (*dualFundRound, error) {
+
+	in, _ := r.result.Contributed(lntypes.Local, r.fundingScript)
+	fee := feeRate.FeeForWeight(
+		r.result.Weight(lntypes.Local, r.fundingScript),
+	)
+
+	changeAmt := in - r.localAmt - fee
+	if changeAmt < 0 {
+		return nil, fmt.Errorf("%w: inputs of %v don't cover "+
+			"contribution of %v and fee of %v",
+			ErrFundingTxNotReplaceable, in, r.localAmt, fee)
+	}
+
+	// We never add a change output that didn't exist before, as that
+	// would increase the fee we pay. A change output that became dust is
+	// left to the miners instead.
+	var change []*wire.TxOut
+	if len(r.change) > 0 && changeAmt >= r.dustLimit {
+		change = []*wire.TxOut{{
+			Value:    int64(changeAmt),
+			PkScript: r.change[0].PkScript,
+		}}
+	}
+
+	return &dualFundRound{
+		initiator:     r.initiator,
+		feeRate:       feeRate,
+		locktime:      r.locktime,
+		localAmt:      r.localAmt,
+		remoteAmt:     r.remoteAmt,
+		fundingScript: r.fundingScript,
+		inputs:        r.inputs,
+		change:        change,
+		dustLimit:     r.dustLimit,
+	}, nil
+}
+
+// dualFundedTx tracks the funding transaction of a dual-funded channel from
+// the moment its initial commitments are signed until it confirmed. The
+// state is only kept in memory, so after a restart the funding transaction
+// of a channel can no longer be replaced.
+//
+// NOTE: The fields are only accessed by the reservationCoordinator.
+type dualFundedTx struct {
+	peer   lnpeer.Peer
+	chanID lnwire.ChannelID
+
+	// reservation signs the inputs we contributed. A replacement spends
+	// the same inputs, so it's used for all rounds.
+	reservation *lnwallet.ChannelReservation
+
+	// channel is the pending channel. It's nil until the initial
+	// commitments were signed by both parties.
+	channel *chanstate.OpenChannel
+
+	// round is the round whose funding transaction is signed or was
+	// published, and rbf the round replacing it, if any.
+	round *dualFundRound
+	rbf   *dualFundRound
+
+	stage dualFundStage
+
+	// sentSigs is true once we sent our TxSignatures for the funding
+	// transaction of the current round.
+	sentSigs bool
+
+	// lnChannel and commits are used to sign the commitments spending
+	// the funding output of a replacement.
+	lnChannel *lnwallet.LightningChannel
+	commits   *lnwallet.SpliceCommitments
+
+	// replaced is signalled once a replacement of the funding transaction
+	// was persisted as the pending splice of the channel.
+	replaced chan struct{}
+}
+
+// canReplace returns an error if the funding transaction can't be replaced by
+// one paying the passed fee rate. The funding transaction of a channel can
+// only be replaced once, and the fee rate must increase by at least 1/24th of
+// the previous one.
+func (t *dualFundedTx) canReplace(feeRate chainfee.SatPerKWeight) error {
+	switch {
+	case t.stage != dualFundPublished:
+		return fmt.Errorf("%w: funding transaction not published or "+
+			"replacement in progress", ErrFundingTxNotReplaceable)
+
+	case t.channel.IsZeroConf():
+		return fmt.Errorf("%w: zero-conf channel",
+			ErrFundingTxNotReplaceable)
+
+	case t.channel.PendingSpliceInfo().IsSome():
+		return fmt.Errorf("%w: funding transaction already replaced",
+			ErrFundingTxNotReplaceable)
+
+	case uint64(feeRate)*24 < uint64(t.round.feeRate)*25:
+		return fmt.Errorf("%w: fee rate %v too low to replace fee "+
+			"rate %v", ErrFundingTxNotReplaceable, feeRate,
+			t.round.feeRate)
+	}
+
+	return nil
+}
+
+// rbfRequest is a request to replace the funding transaction of a pending
+// dual-funded channel by one paying a higher fee rate.
+type rbfRequest struct {
+	chanPoint wire.OutPoint
+	feeRate   chainfee.SatPerKWeight
+	err       chan error
+}
+
+// useDualFunding returns true if the channel requested by the passed message
+// is opened as a dual-funded channel, which lets the remote party contribute
+// to the funding output as well.
+func useDualFunding(msg *InitFundingMsg, commitType lnwallet.CommitmentType,
+	zeroConf bool) bool {
+
+	switch {
+	case !hasFeatures(
+		msg.Peer.LocalFeatures(), msg.Peer.RemoteFeatures(),
+		lnwire.DualFundOptionalStaging,
+	):
+		return false
+
+	// Our wallet must be able to add inputs to the funding transaction,
+	// so externally funded channels, such as PSBT ones, are opened the
+	// regular way. There's no pushing of funds in a dual-funded channel.
+	case msg.ChanFunder != nil || msg.PushAmt != 0:
+		return false
+
+	// The funding transaction of a zero-conf channel must not be replaced,
+	// and the commitments of taproot channels can't be signed for a
+	// replacement.
+	case zeroConf || commitType.IsTaproot():
+		return false
+
+	// The thaw height of a script enforced lease channel that isn't funded
+	// through a shim is the expiry of the lease it's bought with.
+	case commitType == lnwallet.CommitmentTypeScriptEnforcedLease &&
+		msg.LeaseRequest.IsNone():
+
+		return false
+	}
+
+	return true
+}
+
+// newDualFundRound creates the initial round of a dual-funded channel, in
+// which we contribute localAmt to the funding output.
+func newDualFundRound(initiator bool, feeRate chainfee.SatPerKWeight,
+	locktime uint32, localAmt chainutil.Amount) *dualFundRound {
+
+	return &dualFundRound{
+		initiator: initiator,
+		feeRate:   feeRate,
+		locktime:  locktime,
+		localAmt:  localAmt,
+	}
+}
+
+// fund completes the initial round once the contribution of the remote party
+// is known and processed by the passed reservation, which provides our inputs
+// and the funding script.
+func (r *dualFundRound) fund(reservation *lnwallet.ChannelReservation,
+	remoteAmt chainutil.Amount) error {
+
+	inputs, change, err := reservation.InteractiveFunding()
+	if err != nil {
+		return err
+	}
+	fundingScript, err := reservation.FundingScript()
+	if err != nil {
+		return err
+	}
+
+	r.remoteAmt = remoteAmt
+	r.fundingScript = fundingScript
+	r.inputs = inputs
+	r.change = change
+	r.dustLimit = reservation.OurContribution().DustLimit
+
+	return nil
+}
+
+// startDualFunding completes the initial round of a dual-funded channel we
+// initiated once the remote party accepted the channel, and starts the
+// interactive construction of the funding transaction by sending our first
+// message.
+func (f *Manager) startDualFunding(resCtx *reservationWithCtx,
+	cid *chanIdentifier, remoteAmt chainutil.Amount) error {
+
+	round := resCtx.dualFund
+	err := round.fund(resCtx.reservation, remoteAmt)
+	if err != nil {
+		return err
+	}
+
+	if err := round.start(cid.tempChanID); err != nil {
+		return err
+	}
+
+	msg, err := round.session.Start()
+	if err != nil {
+		return err
+	}
+
+	return resCtx.peer.SendMessage(true, msg)
+}
+
+// handleReservationTxMsg processes an interactive transaction message for
+// the funding transaction of a dual-funded channel that's still being
+// negotiated. Once the construction completed, the initiator continues the
+// funding flow by signing the remote commitment, while the responder waits
+// for the FundingCreated message.
+func (f *Manager) handleReservationTxMsg(peer lnpeer.Peer,
+	pendingChanID PendingChanID, resCtx *reservationWithCtx,
+	msg lnwire.Message) {
+
+	defer resCtx.updateTimestamp()
+
+	cid := newChanIdentifier(pendingChanID)
+
+	round := resCtx.dualFund
+	if round == nil {
+		err := fmt.Errorf("unexpected %v for channel that isn't "+
+			"dual-funded", msg.MsgType())
+		f.failFundingFlow(peer, cid, err)
+
+		return
+	}
+
+	done, err := round.receive(peer, msg)
+	if err != nil {
+		log.Errorf("Unable to construct funding tx for "+
+			"pending_id(%x): %v", pendingChanID[:], err)
+		f.failFundingFlow(peer, cid, err)
+
+		return
+	}
+	if !done || !round.initiator {
+		return
+	}
+
+	log.Infof("Constructed funding tx %v for pending_id(%x)",
+		round.result.Tx.TxHash(), pendingChanID[:])
+
+	err = resCtx.reservation.ProcessInteractiveTx(round.result.Tx)
+	if err != nil {
+		log.Errorf("Unable to process funding tx for "+
+			"pending_id(%x): %v", pendingChanID[:], err)
+		f.failFundingFlow(peer, cid, err)
+
+		return
+	}
+
+	f.continueFundingAccept(resCtx, cid)
+}
+
+// handleInteractiveTxMsg processes an interactive tx message, such as a
+// TxAddInput or TxComplete. These either belong to the initial construction of
+// the funding transaction of a reservation, or to a replacement of the funding
+// transaction of a pending channel.
+func (f *Manager) handleInteractiveTxMsg(peer lnpeer.Peer,
+	msg lnwire.Message) {
+
+	updater, ok := msg.(lnwire.LinkUpdater)
+	if !ok {
+		return
+	}
+	chanID := updater.TargetChanID()
+
+	// During the initial construction, the messages reference the
+	// reservation by its pending channel ID.
+	resCtx, err := f.getReservationCtx(peer.IdentityKey(), chanID)
+	if err == nil {
+		f.handleReservationTxMsg(peer, chanID, resCtx, msg)
+		return
+	}
+
+	tx, ok := f.dualFundedTxs.Load(chanID)
+	if !ok || tx.stage != dualFundConstructing {
+		log.Warnf("Received %v for unknown funding tx construction "+
+			"of ChannelID(%v)", msg.MsgType(), chanID)
+		return
+	}
+
+	done, err := tx.rbf.receive(peer, msg)
+	if err != nil {
+		f.abortReplacement(tx, err)
+		return
+	}
+	if !done {
+		return
+	}
+
+	if err := f.commitReplacement(tx); err != nil {
+		f.abortReplacement(tx, err)
+	}
+}
+
+// trackDualFundedTx starts tracking the funding transaction of the
+// dual-funded channel of the passed reservation, which is referenced by the
+// passed channel ID from now on.
+func (f *Manager) trackDualFundedTx(resCtx *reservationWithCtx,
+	chanID lnwire.ChannelID) {
+
+	f.dualFundedTxs.Store(chanID, &dualFundedTx{
+		peer:        resCtx.peer,
+		chanID:      chanID,
+		reservation: resCtx.reservation,
+		round:       resCtx.dualFund,
+		stage:       dualFundSigning,
+		replaced:    make(chan struct{}, 1),
+	})
+}
+
+// dualFundingSigned continues the flow of a dual-funded channel once its
+// initial commitments were signed by both parties. If we are to send our
+// TxSignatures first, we do so now.
+func (f *Manager) dualFundingSigned(chanID lnwire.ChannelID,
+	channel *chanstate.OpenChannel) {
+
+	tx, ok := f.dualFundedTxs.Load(chanID)
+	if !ok {
+		return
+	}
+
+	tx.channel = channel
+
+	result := tx.round.result
+	if !result.SendSignaturesFirst(f.cfg.IDKey, tx.peer.IdentityKey()) {
+		return
+	}
+
+	if err := f.sendTxSignatures(tx); err != nil {
+		log.Errorf("Unable to send TxSignatures for ChannelID(%v): %v",
+			chanID, err)
+	}
+}
+
+// sendTxSignatures signs the inputs we contributed to the funding transaction
+// of the current round and sends the witnesses to the remote party.
+func (f *Manager) sendTxSignatures(tx *dualFundedTx) error {
+	result := tx.round.result
+
+	witnesses, err := tx.reservation.SignInteractiveInputs(
+		result.Tx, result.PrevOuts,
+	)
+	if err != nil {
+		return err
+	}
+	if err := result.SetWitnesses(lntypes.Local, witnesses); err != nil {
+		return err
+	}
+
+	tx.sentSigs = true
+
+	return tx.peer.SendMessage(true, &lnwire.TxSignatures{
+		ChanID:    tx.chanID,
+		TxID:      result.Tx.TxHash(),
+		Witnesses: witnesses,
+	})
+}
+
+// handleTxSignatures processes the remote party's signatures for its inputs
+// of the funding transaction of a dual-funded channel. Once both parties
+// signed, the funding transaction is published.
+func (f *Manager) handleTxSignatures(peer lnpeer.Peer,
+	msg *lnwire.TxSignatures) {
+
+	tx, ok := f.dualFundedTxs.Load(msg.ChanID)
+	if !ok {
+		log.Warnf("Received TxSignatures for unknown ChannelID(%v)",
+			msg.ChanID)
+		return
+	}
+
+	if err := f.processTxSignatures(tx, msg); err != nil {
+		log.Errorf("Unable to process TxSignatures for "+
+			"ChannelID(%v): %v", msg.ChanID, err)
+
+		f.sendTxAbort(tx, err)
+		tx.stage = dualFundPublished
+	}
+}
+
+// processTxSignatures completes and publishes the funding transaction of the
+// current round with the remote party's signatures.
+func (f *Manager) processTxSignatures(tx *dualFundedTx,
+	msg *lnwire.TxSignatures) error {
+
+	if tx.stage != dualFundSigning || tx.channel == nil {
+		return fmt.Errorf("TxSignatures received out of order")
+	}
+
+	result := tx.round.result
+	txid := result.Tx.TxHash()
+	if msg.TxID != txid {
+		return fmt.Errorf("TxSignatures for unknown transaction %v",
+			chainhash.Hash(msg.TxID))
+	}
+
+	err := result.SetWitnesses(lntypes.Remote, msg.Witnesses)
+	if err != nil {
+		return err
+	}
+
+	if !tx.sentSigs {
+		if err := f.sendTxSignatures(tx); err != nil {
+			return err
+		}
+	}
+
+	if err := interactivetx.VerifyTx(result.Tx, result.PrevOuts); err != nil {
+		return err
+	}
+
+	// A replacement was persisted as the pending splice of the channel,
+	// which now gets the fully signed transaction.
+	var spliceErr error
+	tx.channel.PendingSpliceInfo().WhenSome(func(s chanstate.Splice) {
+		if s.FundingOutpoint.Hash != txid {
+			return
+		}
+
+		s.FundingTx = result.Tx
+		s.RemoteSigned = true
+		spliceErr = tx.channel.PutPendingSplice(s)
+	})
+	if spliceErr != nil {
+		return spliceErr
+	}
+
+	tx.stage = dualFundPublished
+
+	log.Infof("Publishing funding tx %v for ChannelID(%v)", txid,
+		tx.chanID)
+
+	// Set a nil short channel ID at this stage because we do not know it
+	// until our funding tx confirms.
+	label := labels.MakeLabel(labels.LabelTypeChannelOpen, nil)
+
+	err = f.cfg.PublishTransaction(result.Tx, label)
+	if err != nil {
+		log.Errorf("Unable to publish funding tx %v for "+
+			"ChannelID(%v): %v", txid, tx.chanID, err)
+	}
+
+	return nil
+}
+
+// BumpFundingFee replaces the unconfirmed funding transaction of the pending
+// dual-funded channel with the passed funding outpoint by one paying the
+// passed fee rate. Only the initiator of a channel can replace its funding
+// transaction, and only once. The call returns once the replacement was
+// requested from the remote party, the rest of the flow completes in the
+// background.
+func (f *Manager) BumpFundingFee(chanPoint wire.OutPoint,
+	feeRate chainfee.SatPerKWeight) error {
+
+	req := &rbfRequest{
+		chanPoint: chanPoint,
+		feeRate:   feeRate,
+		err:       make(chan error, 1),
+	}
+
+	select {
+	case f.rbfRequests <- req:
+	case <-f.quit:
+		return ErrFundingManagerShuttingDown
+	}
+
+	select {
+	case err := <-req.err:
+		return err
+	case <-f.quit:
+		return ErrFundingManagerShuttingDown
+	}
+}
+
+// handleRbfRequest starts the replacement of a funding transaction requested
+// through BumpFundingFee.
+func (f *Manager) handleRbfRequest(req *rbfRequest) error {
+	chanID := lnwire.NewChanIDFromOutPoint(req.chanPoint)
+
+	tx, ok := f.dualFundedTxs.Load(chanID)
+	if !ok {
+		return fmt.Errorf("%w: %v isn't the funding outpoint of a "+
+			"pending dual-funded channel",
+			ErrFundingTxNotReplaceable, req.chanPoint)
+	}
+	if !tx.round.initiator {
+		return fmt.Errorf("%w: only the initiator can replace the "+
+			"funding transaction", ErrFundingTxNotReplaceable)
+	}
+	if err := tx.canReplace(req.feeRate); err != nil {
+		return err
+	}
+
+	round, err := tx.round.replacement(req.feeRate)
+	if err != nil {
+		return err
+	}
+
+	err = tx.peer.SendMessage(true, &lnwire.TxInitRbf{
+		ChanID:              chanID,
+		Locktime:            round.locktime,
+		FeeRate:             uint32(req.feeRate),
+		FundingContribution: int64(round.localAmt),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Requested replacement of funding tx %v at %v",
+		req.chanPoint.Hash, req.feeRate)
+
+	tx.rbf = round
+	tx.stage = dualFundAwaitRbfAck
+
+	return nil
+}
+
+// handleTxInitRbf processes the initiator's request to replace the funding
+// transaction of a pending dual-funded channel.
+func (f *Manager) handleTxInitRbf(peer lnpeer.Peer, msg *lnwire.TxInitRbf) {
+	tx, ok := f.dualFundedTxs.Load(msg.ChanID)
+	if !ok {
+		log.Warnf("Received TxInitRbf for unknown ChannelID(%v)",
+			msg.ChanID)
+		return
+	}
+
+	if err := f.acceptReplacement(tx, msg); err != nil {
+		f.abortReplacement(tx, err)
+	}
+}
+
+// acceptReplacement validates the initiator's request to replace the funding
+// transaction, acknowledges it and waits for the construction to start.
+func (f *Manager) acceptReplacement(tx *dualFundedTx,
+	msg *lnwire.TxInitRbf) error {
+
+	if tx.round.initiator {
+		return fmt.Errorf("TxInitRbf received from responder")
+	}
+
+	feeRate := chainfee.SatPerKWeight(msg.FeeRate)
+	if err := tx.canReplace(feeRate); err != nil {
+		return err
+	}
+
+	contribution := chainutil.Amount(msg.FundingContribution)
+	if contribution != tx.round.remoteAmt {
+		return fmt.Errorf("%w: initiator changed its contribution "+
+			"from %v to %v", ErrFundingTxNotReplaceable,
+			tx.round.remoteAmt, contribution)
+	}
+
+	round, err := tx.round.replacement(feeRate)
+	if err != nil {
+		return err
+	}
+	round.locktime = msg.Locktime
+
+	if err := round.start(tx.chanID); err != nil {
+		return err
+	}
+
+	tx.rbf = round
+	tx.stage = dualFundConstructing
+
+	return tx.peer.SendMessage(true, &lnwire.TxAckRbf{
+		ChanID:              tx.chanID,
+		FundingContribution: int64(round.localAmt),
+	})
+}
+
+// handleTxAckRbf processes the responder's acknowledgement of our request to
+// replace the funding transaction, and starts the construction.
+func (f *Manager) handleTxAckRbf(peer lnpeer.Peer, msg *lnwire.TxAckRbf) {
+	tx, ok := f.dualFundedTxs.Load(msg.ChanID)
+	if !ok || tx.stage != dualFundAwaitRbfAck {
+		log.Warnf("Received unexpected TxAckRbf for ChannelID(%v)",
+			msg.ChanID)
+		return
+	}
+
+	err := func() error {
+		contribution := chainutil.Amount(msg.FundingContribution)
+		if contribution != tx.rbf.remoteAmt {
+			return fmt.Errorf("%w: responder changed its "+
+				"contribution from %v to %v",
+				ErrFundingTxNotReplaceable, tx.rbf.remoteAmt,
+				contribution)
+		}
+
+		if err := tx.rbf.start(tx.chanID); err != nil {
+			return err
+		}
+
+		first, err := tx.rbf.session.Start()
+		if err != nil {
+			return err
+		}
+
+		tx.stage = dualFundConstructing
+
+		return tx.peer.SendMessage(false, first)
+	}()
+	if err != nil {
+		f.abortReplacement(tx, err)
+	}
+}
+
+// commitReplacement signs the remote commitment spending the funding output
+// of the constructed replacement of the funding transaction.
+func (f *Manager) commitReplacement(tx *dualFundedTx) error {
+	lnChannel, err := lnwallet.NewLightningChannel(
+		f.cfg.Wallet.Cfg.Signer, tx.channel, nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	// The contributions don't change, so neither do the balances.
+	commits, err := lnChannel.SignSpliceCommitments(tx.rbf.result.Tx, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	tx.lnChannel = lnChannel
+	tx.commits = commits
+	tx.stage = dualFundCommitting
+
+	return tx.peer.SendMessage(false, &lnwire.CommitSig{
+		ChanID:    tx.chanID,
+		CommitSig: commits.RemoteCommitSig,
+	})
+}
+
+// handleCommitSig processes the remote party's signature for our commitment
+// spending the funding output of a replacement of the funding transaction.
+// The replacement is persisted as the pending splice of the channel, so it's
+// tracked until either funding transaction confirmed.
+func (f *Manager) handleCommitSig(peer lnpeer.Peer, msg *lnwire.CommitSig) {
+	tx, ok := f.dualFundedTxs.Load(msg.ChanID)
+	if !ok || tx.stage != dualFundCommitting {
+		log.Warnf("Received unexpected CommitSig for ChannelID(%v)",
+			msg.ChanID)
+		return
+	}
+
+	err := tx.lnChannel.VerifySpliceCommitSig(tx.commits, msg.CommitSig)
+	if err != nil {
+		f.abortReplacement(tx, err)
+		return
+	}
+
+	_, bestHeight, err := f.cfg.Wallet.Cfg.ChainIO.GetBestBlock()
+	if err != nil {
+		f.abortReplacement(tx, err)
+		return
+	}
+
+	pending := tx.commits.PendingSplice(
+		tx.rbf.result.Tx, msg.CommitSig.ToSignatureBytes(),
+		tx.rbf.initiator,
+		uint32(bestHeight),
+	)
+	if err := tx.channel.PutPendingSplice(pending); err != nil {
+		f.abortReplacement(tx, err)
+		return
+	}
+
+	tx.round = tx.rbf
+	tx.rbf = nil
+	tx.lnChannel = nil
+	tx.commits = nil
+	tx.sentSigs = false
+	tx.stage = dualFundSigning
+
+	// From here on, the replacement may confirm instead of the original
+	// funding transaction.
+	select {
+	case tx.replaced <- struct{}{}:
+	default:
+	}
+
+	result := tx.round.result
+	if !result.SendSignaturesFirst(f.cfg.IDKey, tx.peer.IdentityKey()) {
+		return
+	}
+
+	if err := f.sendTxSignatures(tx); err != nil {
+		log.Errorf("Unable to send TxSignatures for ChannelID(%v): %v",
+			tx.chanID, err)
+	}
+}
+
+// abortReplacement abandons the replacement of the funding transaction being
+// negotiated and informs the remote party.
+func (f *Manager) abortReplacement(tx *dualFundedTx, err error) {
+	log.Errorf("Aborting replacement of funding tx for ChannelID(%v): %v",
+		tx.chanID, err)
+
+	f.resetReplacement(tx)
+	f.sendTxAbort(tx, err)
+}
+
+// resetReplacement drops the replacement of the funding transaction being
+// negotiated, if any.
+func (f *Manager) resetReplacement(tx *dualFundedTx) {
+	switch tx.stage {
+	case dualFundAwaitRbfAck, dualFundConstructing, dualFundCommitting:
+		tx.rbf = nil
+		tx.lnChannel = nil
+		tx.commits = nil
+		tx.stage = dualFundPublished
+	}
+}
+
+// sendTxAbort sends a TxAbort with the passed error to the remote party.
+func (f *Manager) sendTxAbort(tx *dualFundedTx, err error) {
+	sendErr := tx.peer.SendMessage(false, &lnwire.TxAbort{
+		ChanID: tx.chanID,
+		Data:   lnwire.ErrorData(err.Error()),
+	})
+	if sendErr != nil {
+		log.Errorf("Unable to send TxAbort for ChannelID(%v): %v",
+			tx.chanID, sendErr)
+	}
+}
+
+// handleTxAbort processes the remote party's abort of the construction of a
+// funding transaction. Aborting the initial construction fails the funding
+// flow, while aborting a replacement keeps the published funding transaction.
+func (f *Manager) handleTxAbort(peer lnpeer.Peer, msg *lnwire.TxAbort) {
+	_, err := f.getReservationCtx(peer.IdentityKey(), msg.ChanID)
+	if err == nil {
+		f.handleErrorMsg(peer, &lnwire.Error{
+			ChanID: msg.ChanID,
+			Data:   msg.Data,
+		})
+
+		return
+	}
+
+	tx, ok := f.dualFundedTxs.Load(msg.ChanID)
+	if !ok {
+		return
+	}
+
+	// If we aborted the replacement ourselves, this acknowledges it.
+	switch tx.stage {
+	case dualFundAwaitRbfAck, dualFundConstructing, dualFundCommitting:
+	default:
+		return
+	}
+
+	log.Infof("Replacement of funding tx for ChannelID(%v) aborted by "+
+		"peer: %s", tx.chanID, msg.Data)
+
+	f.resetReplacement(tx)
+	f.sendTxAbort(tx, errors.New("acknowledged"))
+}
+
+// watchFundingReplacement waits for the funding transaction of the passed
+// dual-funded channel to be replaced, and then for the replacement to reach
+// numConfs confirmations. The returned channel receives the confirmation of
+// the replacement. If the funding transaction can't be replaced, nothing is
+// ever sent.
+func (f *Manager) watchFundingReplacement(c *chanstate.OpenChannel,
+	numConfs uint32, fundingScript []byte,
+	cancelChan <-chan struct{}) <-chan *chainntnfs.TxConfirmation {
+
+	confChan := make(chan *chainntnfs.TxConfirmation, 1)
+	chanID := lnwire.NewChanIDFromOutPoint(c.FundingOutpoint)
+
+	tx, tracked := f.dualFundedTxs.Load(chanID)
+	if !tracked && c.PendingSpliceInfo().IsNone() {
+		return confChan
+	}
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		if c.PendingSpliceInfo().IsNone() {
+			select {
+			case <-tx.replaced:
+			case <-cancelChan:
+				return
+			case <-f.quit:
+				return
+			}
+		}
+
+		splice, err := c.PendingSpliceInfo().UnwrapOrErr(
+			chanstate.ErrNoPendingSplice,
+		)
+		if err != nil {
+			log.Errorf("Unable to watch replacement of funding tx "+
+				"for ChannelPoint(%v): %v", c.FundingOutpoint,
+				err)
+			return
+		}
+
+		txid := splice.FundingOutpoint.Hash
+		confNtfn, err := f.cfg.Notifier.RegisterConfirmationsNtfn(
+			&txid, fundingScript, numConfs, splice.HeightHint,
+		)
+		if err != nil {
+			log.Errorf("Unable to register for confirmation of "+
+				"funding tx replacement %v: %v", txid, err)
+			return
+		}
+		defer confNtfn.Cancel()
+
+		log.Infof("Waiting for funding tx replacement (%v) to reach "+
+			"%v confirmations", txid, numConfs)
+
+		select {
+		case conf, ok := <-confNtfn.Confirmed:
+			if !ok {
+				return
+			}
+			confChan <- conf
+
+		case <-cancelChan:
+		case <-f.quit:
+		}
+	}()
+
+	return confChan
+}
+
+// lockFundingReplacement switches the passed channel over to the replacement
+// of its funding transaction, which confirmed.
+func (f *Manager) lockFundingReplacement(c *chanstate.OpenChannel) error {
+	if err := c.LockSplice(); err != nil {
+		return err
+	}
+
+	// Both parties see the replacement confirm, so there's no need to
+	// wait for the remote party to lock it.
+	if err := c.MarkSpliceRemoteLocked(); err != nil {
+		return err
+	}
+
+	return f.cfg.NotifyFundingTxReplaced(c.FundingOutpoint)
+}
@@ -0,0 +1,232 @@
+package funding
+
+import (
+	"testing"
+
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// dualFundScript is the script of the funding output of the test
+	// rounds.
+	dualFundScript = append([]byte{0x00, 0x20}, make([]byte, 32)...)
+
+	// dualFundChangeScript is the script of the change outputs of the
+	// test rounds.
+	dualFundChangeScript = append([]byte{0x00, 0x14}, make([]byte, 20)...)
+)
+
+// testUtxo returns a wallet output of the passed value spent by a test round.
+func testUtxo(value int64) *lnwallet.Utxo {
+	prevTx := wire.NewMsgTx(2)
+	prevTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: uint32(value)},
+	})
+	prevTx.AddTxOut(&wire.TxOut{
+		Value:    value,
+		PkScript: dualFundChangeScript,
+	})
+
+	return &lnwallet.Utxo{
+		Value:    chainutil.Amount(value),
+		PkScript: dualFundChangeScript,
+		OutPoint: wire.OutPoint{Hash: prevTx.TxHash()},
+		PrevTx:   prevTx,
+	}
+}
+
+// testRound returns a funded round contributing localAmt from a single input
+// of the passed value, with a change output of the passed value.
+func testRound(initiator bool, localAmt, remoteAmt chainutil.Amount,
+	inputValue, changeValue int64) *dualFundRound {
+
+	round := newDualFundRound(initiator, 2_500, 100, localAmt)
+	round.remoteAmt = remoteAmt
+	round.fundingScript = dualFundScript
+	round.inputs = []*lnwallet.Utxo{testUtxo(inputValue)}
+	round.change = []*wire.TxOut{{
+		Value:    changeValue,
+		PkScript: dualFundChangeScript,
+	}}
+	round.dustLimit = 354
+
+	return round
+}
+
+// constructRounds runs the construction of the funding transaction between
+// the passed rounds and returns the errors of validating the result on both
+// sides.
+func constructRounds(t *testing.T, initiator,
+	responder *dualFundRound) (error, error) {
+
+	t.Helper()
+
+	chanID := lnwire.ChannelID{1}
+	require.NoError(t, initiator.start(chanID))
+	require.NoError(t, responder.start(chanID))
+
+	msg, err := initiator.session.Start()
+	require.NoError(t, err)
+
+	receiver, sender := responder, initiator
+	for {
+		reply, err := receiver.session.ReceiveMsg(msg)
+		require.NoError(t, err)
+
+		if reply.IsNone() {
+			break
+		}
+
+		msg = reply.UnsafeFromSome()
+		receiver, sender = sender, receiver
+	}
+
+	require.True(t, initiator.session.Complete())
+	require.True(t, responder.session.Complete())
+
+	return initiator.finish(), responder.finish()
+}
+
+// TestDualFundRoundConstruction asserts that both parties of a dual-funded
+// channel construct the same funding transaction holding both contributions.
+func TestDualFundRoundConstruction(t *testing.T) {
+	t.Parallel()
+
+	initiator := testRound(true, 500_000, 200_000, 1_000_000, 490_000)
+	responder := testRound(false, 200_000, 500_000, 300_000, 90_000)
+
+	initErr, respErr := constructRounds(t, initiator, responder)
+	require.NoError(t, initErr)
+	require.NoError(t, respErr)
+
+	tx := initiator.result.Tx
+	require.Equal(t, tx.TxHash(), responder.result.Tx.TxHash())
+	require.EqualValues(t, 100, tx.LockTime)
+	require.Len(t, tx.TxIn, 2)
+	require.Len(t, tx.TxOut, 3)
+
+	index, err := initiator.result.OutputIndex(dualFundScript)
+	require.NoError(t, err)
+	require.EqualValues(t, 700_000, tx.TxOut[index].Value)
+
+	fundingPoint := wire.OutPoint{Hash: tx.TxHash(), Index: index}
+	require.NoError(t, responder.checkFundingPoint(fundingPoint))
+
+	fundingPoint.Index++
+	require.Error(t, responder.checkFundingPoint(fundingPoint))
+}
+
+// TestDualFundRoundRemoteFee asserts that the construction fails if the
+// remote party doesn't pay the fee for the inputs and outputs it added.
+func TestDualFundRoundRemoteFee(t *testing.T) {
+	t.Parallel()
+
+	initiator := testRound(true, 500_000, 200_000, 1_000_000, 490_000)
+	responder := testRound(false, 200_000, 500_000, 300_000, 99_900)
+
+	initErr, respErr := constructRounds(t, initiator, responder)
+	require.ErrorContains(t, initErr, "don't cover")
+	require.NoError(t, respErr)
+}
+
+// TestDualFundRoundReplacement asserts that the change of a replacement pays
+// for the higher fee of our inputs and outputs.
+func TestDualFundRoundReplacement(t *testing.T) {
+	t.Parallel()
+
+	initiator := testRound(true, 500_000, 200_000, 1_000_000, 490_000)
+	responder := testRound(false, 200_000, 500_000, 300_000, 90_000)
+
+	initErr, respErr := constructRounds(t, initiator, responder)
+	require.NoError(t, initErr)
+	require.NoError(t, respErr)
+
+	weight := initiator.result.Weight(lntypes.Local, dualFundScript)
+
+	// Our change pays for the higher fee, while the contributions stay
+	// the same.
+	feeRate := chainfee.SatPerKWeight(5_000)
+	rbf, err := initiator.replacement(feeRate)
+	require.NoError(t, err)
+	require.Equal(t, feeRate, rbf.feeRate)
+	require.Equal(t, initiator.capacity(), rbf.capacity())
+	require.Equal(t, initiator.inputs, rbf.inputs)
+	require.Len(t, rbf.change, 1)
+	require.EqualValues(
+		t, 500_000-feeRate.FeeForWeight(weight), rbf.change[0].Value,
+	)
+
+	// A change output that would become dust is dropped.
+	feeRate = chainfee.SatPerKWeight(
+		(500_000 - 100) * 1000 / int64(weight),
+	)
+	rbf, err = initiator.replacement(feeRate)
+	require.NoError(t, err)
+	require.Empty(t, rbf.change)
+
+	// The fee can't exceed the inputs left after our contribution.
+	_, err = initiator.replacement(feeRate * 2)
+	require.ErrorIs(t, err, ErrFundingTxNotReplaceable)
+}
+
+// TestDualFundedTxCanReplace asserts the conditions under which the funding
+// transaction of a dual-funded channel can be replaced.
+func TestDualFundedTxCanReplace(t *testing.T) {
+	t.Parallel()
+
+	newTx := func(stage dualFundStage) *dualFundedTx {
+		return &dualFundedTx{
+			channel: &chanstate.OpenChannel{},
+			round:   &dualFundRound{feeRate: 2_400},
+			stage:   stage,
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		tx      *dualFundedTx
+		feeRate chainfee.SatPerKWeight
+		valid   bool
+	}{
+		{
+			name:    "fee rate increased by 1/24th",
+			tx:      newTx(dualFundPublished),
+			feeRate: 2_500,
+			valid:   true,
+		},
+		{
+			name:    "fee rate increase too small",
+			tx:      newTx(dualFundPublished),
+			feeRate: 2_499,
+		},
+		{
+			name:    "not yet published",
+			tx:      newTx(dualFundSigning),
+			feeRate: 5_000,
+		},
+		{
+			name:    "replacement in progress",
+			tx:      newTx(dualFundConstructing),
+			feeRate: 5_000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.tx.canReplace(tc.feeRate)
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrFundingTxNotReplaceable)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package funding
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/liquidityads"
+	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+)
+
+// leaseFee returns the fee we pay or earn for the passed lease request, given
+// the fee rate of the funding transaction.
+func leaseFee(req lnwire.LeaseRequest,
+	feeRate chainfee.SatPerKWeight) chainutil.Amount {
+
+	fee := req.Rates.LeaseFee(req.Amount, uint32(feeRate))
+
+	return chainutil.Amount(fee)
+}
+
+// leaseChannelType is the channel type of a leased channel if none was
+// requested explicitly. The lease is enforced by the scripts of the seller's
+// outputs, which are locked until the lease expires.
+func leaseChannelType() *lnwire.ChannelType {
+	chanType := lnwire.ChannelType(*lnwire.NewRawFeatureVector(
+		lnwire.ScriptEnforcedLeaseRequired,
+		lnwire.AnchorsZeroFeeHtlcTxRequired,
+		lnwire.StaticRemoteKeyRequired,
+	))
+
+	return &chanType
+}
+
+// buyLease prepares the purchase of inbound liquidity requested in the passed
+// funding request. It returns the lease we enter once the channel is signed,
+// or nil if no liquidity is bought.
+func buyLease(msg *InitFundingMsg, commitType lnwallet.CommitmentType,
+	dualFund bool, fundingLocktime uint32) (*liquidityads.Lease, error) {
+
+	if msg.LeaseRequest.IsNone() {
+		return nil, nil
+	}
+	req := msg.LeaseRequest.UnsafeFromSome()
+
+	// The leased funds are contributed by the remote party, which is only
+	// possible in a dual-funded channel. Without script enforcement, the
+	// seller could take the funds back at any time.
+	switch {
+	case !dualFund:
+		return nil, errors.New("buying inbound liquidity requires a " +
+			"dual-funded channel")
+
+	case commitType != lnwallet.CommitmentTypeScriptEnforcedLease:
+		return nil, fmt.Errorf("buying inbound liquidity requires a "+
+			"script enforced lease channel, got %v", commitType)
+	}
+
+	fee := leaseFee(req, msg.FundingFeePerKw)
+	if fee > msg.MaxLeaseFee {
+		return nil, fmt.Errorf("lease fee %v exceeds maximum %v", fee,
+			msg.MaxLeaseFee)
+	}
+
+	var peer [33]byte
+	copy(peer[:], msg.Peer.IdentityKey().SerializeCompressed())
+
+	return &liquidityads.Lease{
+		Peer:   peer,
+		Role:   liquidityads.RoleBuyer,
+		Amount: chainutil.Amount(req.Amount),
+		Fee:    fee,
+		Rates:  req.Rates,
+		Expiry: fundingLocktime + liquidityads.LeaseDuration,
+	}, nil
+}
+
+// sellLease validates a request of the initiator to buy inbound liquidity from
+// us. It returns the lease we enter once the channel is signed, or nil if the
+// initiator didn't request any liquidity.
+func (f *Manager) sellLease(msg *lnwire.OpenChannel,
+	commitType lnwallet.CommitmentType,
+	peer [33]byte) (*liquidityads.Lease, error) {
+
+	if msg.RequestFunds.IsNone() {
+		return nil, nil
+	}
+	req := msg.RequestFunds.ValOpt().UnsafeFromSome()
+
+	feeRate, err := msg.DualFundFeeRate.ValOpt().UnwrapOrErr(
+		errors.New("inbound liquidity requested for channel that " +
+			"isn't dual-funded"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := f.cfg.LeaseRates.UnwrapOrErr(
+		errors.New("not selling inbound liquidity"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The initiator must agree to our current rates, as they determine
+	// the fee it pays us.
+	switch {
+	case req.Rates != rates:
+		return nil, fmt.Errorf("lease rates %+v don't match ours %+v",
+			req.Rates, rates)
+
+	case chainutil.Amount(req.Amount) > f.cfg.MaxLeaseAmount:
+		return nil, fmt.Errorf("lease amount %v exceeds maximum %v",
+			chainutil.Amount(req.Amount), f.cfg.MaxLeaseAmount)
+	}
+
+	fee := leaseFee(req, chainfee.SatPerKWeight(feeRate))
+	if msg.PushAmount < lnwire.NewMSatFromLokis(fee) {
+		return nil, fmt.Errorf("pushed amount %v doesn't pay lease "+
+			"fee %v", msg.PushAmount, fee)
+	}
+
+	// Both parties derive the expiry from the locktime of the funding
+	// transaction, which is the height the initiator started the open at.
+	// The lease must be enforced by the scripts of our outputs up to that
+	// height.
+	locktime := msg.DualFundLocktime.ValOpt().UnwrapOr(0)
+	expiry := locktime + liquidityads.LeaseDuration
+
+	switch {
+	case commitType != lnwallet.CommitmentTypeScriptEnforcedLease:
+		return nil, fmt.Errorf("inbound liquidity requested for %v "+
+			"channel that isn't script enforced", commitType)
+
+	case msg.LeaseExpiry == nil:
+		return nil, errors.New("missing lease expiry")
+
+	case uint32(*msg.LeaseExpiry) != expiry:
+		return nil, fmt.Errorf("lease expiry %v doesn't match %v",
+			uint32(*msg.LeaseExpiry), expiry)
+	}
+
+	return &liquidityads.Lease{
+		Peer:   peer,
+		Role:   liquidityads.RoleSeller,
+		Amount: chainutil.Amount(req.Amount),
+		Fee:    fee,
+		Rates:  rates,
+		Expiry: expiry,
+	}, nil
+}
+
+// checkLeaseAccepted makes sure the remote party sells us the inbound
+// liquidity we requested at the rates we agreed to pay.
+func checkLeaseAccepted(lease *liquidityads.Lease, msg *lnwire.AcceptChannel,
+	contribution chainutil.Amount) error {
+
+	rates, err := msg.WillFund.ValOpt().UnwrapOrErr(
+		errors.New("requested inbound liquidity not sold"),
+	)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case rates != lease.Rates:
+		return fmt.Errorf("lease sold at rates %+v instead of %+v",
+			rates, lease.Rates)
+
+	case contribution < lease.Amount:
+		return fmt.Errorf("contribution %v below leased amount %v",
+			contribution, lease.Amount)
+	}
+
+	return nil
+}
+
+// recordLease persists the lease of a dual-funded channel once it's signed.
+func (f *Manager) recordLease(resCtx *reservationWithCtx,
+	channel *chanstate.OpenChannel) {
+
+	if resCtx.lease == nil {
+		return
+	}
+
+	lease := *resCtx.lease
+	lease.ChanPoint = channel.FundingOutpoint
+
+	log.Infof("Recording lease of %v as %v for ChannelPoint(%v), fee=%v, "+
+		"expiry=%v", lease.Amount, lease.Role, lease.ChanPoint,
+		lease.Fee, lease.Expiry)
+
+	if err := f.cfg.RecordLease(&lease); err != nil {
+		log.Errorf("Unable to record lease for ChannelPoint(%v): %v",
+			lease.ChanPoint, err)
+	}
+}
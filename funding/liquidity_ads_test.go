@@ -0,0 +1,130 @@
+package funding
+
+import (
+	"testing"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/liquidityads"
+	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLeaseChannelType asserts that the default channel type of a leased
+// channel negotiates a script enforced lease commitment.
+func TestLeaseChannelType(t *testing.T) {
+	t.Parallel()
+
+	features := lnwire.NewRawFeatureVector(
+		lnwire.ScriptEnforcedLeaseOptional,
+		lnwire.AnchorsZeroFeeHtlcTxOptional,
+		lnwire.StaticRemoteKeyOptional,
+		lnwire.ExplicitChannelTypeOptional,
+	)
+	local := lnwire.NewFeatureVector(features, lnwire.Features)
+	remote := lnwire.NewFeatureVector(features, lnwire.Features)
+
+	_, commitType, err := negotiateCommitmentType(
+		leaseChannelType(), local, remote,
+	)
+	require.NoError(t, err)
+	require.Equal(
+		t, lnwallet.CommitmentTypeScriptEnforcedLease, commitType,
+	)
+}
+
+// TestBuyLeaseScriptEnforced asserts that inbound liquidity is only bought
+// in dual-funded channels that enforce the lease in their scripts.
+func TestBuyLeaseScriptEnforced(t *testing.T) {
+	t.Parallel()
+
+	msg := &InitFundingMsg{
+		LeaseRequest: fn.Some(lnwire.LeaseRequest{Amount: 100_000}),
+	}
+
+	_, err := buyLease(
+		msg, lnwallet.CommitmentTypeScriptEnforcedLease, false, 100,
+	)
+	require.ErrorContains(t, err, "dual-funded")
+
+	_, err = buyLease(
+		msg, lnwallet.CommitmentTypeAnchorsZeroFeeHtlcTx, true, 100,
+	)
+	require.ErrorContains(t, err, "script enforced lease")
+}
+
+// TestSellLeaseScriptEnforced asserts that we only sell inbound liquidity in
+// channels that enforce the lease until its expiry.
+func TestSellLeaseScriptEnforced(t *testing.T) {
+	t.Parallel()
+
+	const locktime = 100
+
+	rates := lnwire.LeaseRates{LeaseFeeBase: 1_000}
+	f := &Manager{
+		cfg: &Config{
+			LeaseRates:     fn.Some(rates),
+			MaxLeaseAmount: chainutil.Amount(1_000_000),
+		},
+	}
+
+	newMsg := func(expiry uint32) *lnwire.OpenChannel {
+		msg := &lnwire.OpenChannel{
+			PushAmount: lnwire.NewMSatFromLokis(10_000),
+			DualFundFeeRate: tlv.SomeRecordT(
+				tlv.NewPrimitiveRecord[tlv.TlvType65541](
+					uint32(253),
+				),
+			),
+			DualFundLocktime: tlv.SomeRecordT(
+				tlv.NewPrimitiveRecord[tlv.TlvType65543](
+					uint32(locktime),
+				),
+			),
+			RequestFunds: tlv.SomeRecordT(
+				tlv.NewRecordT[tlv.TlvType10](lnwire.LeaseRequest{
+					Amount: 100_000,
+					Rates:  rates,
+				}),
+			),
+		}
+		if expiry != 0 {
+			leaseExpiry := lnwire.LeaseExpiry(expiry)
+			msg.LeaseExpiry = &leaseExpiry
+		}
+
+		return msg
+	}
+
+	var peer [33]byte
+	expiry := uint32(locktime + liquidityads.LeaseDuration)
+
+	// A lease in a channel that doesn't enforce it is rejected.
+	_, err := f.sellLease(
+		newMsg(expiry), lnwallet.CommitmentTypeAnchorsZeroFeeHtlcTx,
+		peer,
+	)
+	require.ErrorContains(t, err, "isn't script enforced")
+
+	// So is a lease whose expiry doesn't match the one of the channel.
+	_, err = f.sellLease(
+		newMsg(0), lnwallet.CommitmentTypeScriptEnforcedLease, peer,
+	)
+	require.ErrorContains(t, err, "missing lease expiry")
+
+	_, err = f.sellLease(
+		newMsg(expiry+1), lnwallet.CommitmentTypeScriptEnforcedLease,
+		peer,
+	)
+	require.ErrorContains(t, err, "doesn't match")
+
+	lease, err := f.sellLease(
+		newMsg(expiry), lnwallet.CommitmentTypeScriptEnforcedLease,
+		peer,
+	)
+	require.NoError(t, err)
+	require.Equal(t, expiry, lease.Expiry)
+	require.Equal(t, liquidityads.RoleSeller, lease.Role)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/flokiorg/flnd/input"
 	"github.com/flokiorg/flnd/keychain"
 	"github.com/flokiorg/flnd/labels"
+	"github.com/flokiorg/flnd/liquidityads"
 	"github.com/flokiorg/flnd/lncfg"
 	"github.com/flokiorg/flnd/lnpeer"
 	"github.com/flokiorg/flnd/lnrpc"
@@ -29,11 +30,14 @@ import (
 	"github.com/flokiorg/flnd/lnwallet/chainfee"
 	"github.com/flokiorg/flnd/lnwallet/chanfunding"
 	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/netann"
+	"github.com/flokiorg/flnd/tlv"
 	"github.com/flokiorg/go-flokicoin/blockchain"
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/crypto"
 	"github.com/flokiorg/go-flokicoin/crypto/ecdsa"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr"
 	"github.com/flokiorg/go-flokicoin/crypto/schnorr/musig2"
 	"github.com/flokiorg/go-flokicoin/txscript"
 	"github.com/flokiorg/go-flokicoin/wire"
@@ -59,6 +63,15 @@ var (
 	// errNoPartialSig is returned when a partial sig is not found in the
 	// expected TLV.
 	errNoPartialSig = fmt.Errorf("partial sig not found")
+
+	// errNoAnnouncementNonces is returned when the remote party's channel
+	// announcement nonces of a public taproot channel are not known.
+	errNoAnnouncementNonces = fmt.Errorf("announcement nonces not found")
+
+	// annNoncesKeySuffix is appended to the serialized funding outpoint of
+	// a public taproot channel to form the key under which the remote
+	// party's channel announcement nonces are stored.
+	annNoncesKeySuffix = []byte("ann-nonces")
 )
 
 // WriteOutpoint writes an outpoint to an io.Writer. This is not the same as
@@ -159,6 +172,14 @@ type reservationWithCtx struct {
 	// the channel.
 	channelType *lnwire.ChannelType
 
+	// dualFund is the interactive construction of the funding transaction
+	// of a dual-funded channel. It's nil for other channels.
+	dualFund *dualFundRound
+
+	// lease is the inbound liquidity bought or sold in the channel. It's
+	// nil if the channel isn't leased.
+	lease *liquidityads.Lease
+
 	updateMtx   sync.RWMutex
 	lastUpdated time.Time
 
@@ -292,6 +313,15 @@ type InitFundingMsg struct {
 	// channel that will be useful to our future selves.
 	Memo []byte
 
+	// LeaseRequest is set if we buy inbound liquidity from the peer at the
+	// lease rates it advertised. This requires a dual-funded channel, and
+	// the lease fee is pushed to the peer.
+	LeaseRequest fn.Option[lnwire.LeaseRequest]
+
+	// MaxLeaseFee is the maximum fee we're willing to pay for the inbound
+	// liquidity requested in LeaseRequest.
+	MaxLeaseFee chainutil.Amount
+
 	// Updates is a channel which updates to the opening status of the
 	// channel are sent on.
 	Updates chan *lnrpc.OpenStatusUpdate
@@ -394,6 +424,14 @@ type Config struct {
 	SignMessage func(keyLoc keychain.KeyLocator,
 		msg []byte, doubleHash bool) (*ecdsa.Signature, error)
 
+	// SignMessageSchnorr signs an arbitrary message with a given public
+	// key using a BIP-340 Schnorr signature. If a tag is provided, the
+	// tagged hash of the message is signed. This is used to sign the
+	// channel updates of public taproot channels.
+	SignMessageSchnorr func(keyLoc keychain.KeyLocator, msg []byte,
+		doubleHash bool, taprootTweak []byte,
+		tag []byte) (*schnorr.Signature, error)
+
 	// CurrentNodeAnnouncement should return the latest, fully signed node
 	// announcement from the backing Lightning Network node with a fresh
 	// timestamp.
@@ -576,6 +614,24 @@ type Config struct {
 	// ShutdownScript is an optional upfront-shutdown script to which our
 	// funds should be paid on a cooperative close.
 	ShutdownScript fn.Option[lnwire.DeliveryAddress]
+
+	// NotifyFundingTxReplaced informs the ChainArbitrator that the funding
+	// transaction of the pending channel with the given funding outpoint
+	// was replaced, so it watches the new funding output from now on.
+	NotifyFundingTxReplaced func(wire.OutPoint) error
+
+	// LeaseRates are the rates we sell inbound liquidity at to peers
+	// opening dual-funded channels. We don't sell any liquidity if it's
+	// None.
+	LeaseRates fn.Option[lnwire.LeaseRates]
+
+	// MaxLeaseAmount is the maximum amount we contribute to a single
+	// lease.
+	MaxLeaseAmount chainutil.Amount
+
+	// RecordLease persists a lease we entered once the channel it's part
+	// of was signed.
+	RecordLease func(*liquidityads.Lease) error
 }
 
 // Manager acts as an orchestrator/bridge between the wallet's
@@ -640,10 +696,33 @@ type Manager struct {
 	// requests from a local subsystem within the daemon.
 	fundingRequests chan *InitFundingMsg
 
+	// rbfRequests is a channel used to receive requests to replace the
+	// funding transaction of a pending dual-funded channel.
+	rbfRequests chan *rbfRequest
+
+	// dualFundedTxs tracks the funding transactions of dual-funded
+	// channels, keyed by their permanent channel ID, until they confirmed.
+	dualFundedTxs *lnutils.SyncMap[lnwire.ChannelID, *dualFundedTx]
+
 	localDiscoverySignals *lnutils.SyncMap[lnwire.ChannelID, chan struct{}]
 
 	handleChannelReadyBarriers *lnutils.SyncMap[lnwire.ChannelID, struct{}]
 
+	// annSigs2 holds a buffered channel for each public taproot channel
+	// that delivers the remote party's AnnounceSignatures2 message to the
+	// goroutine announcing the channel.
+	annSigs2 *lnutils.SyncMap[
+		lnwire.ChannelID, chan *lnwire.AnnounceSignatures2,
+	]
+
+	// annSigs2Replies tracks, for each already announced public taproot
+	// channel, the connection with the remote peer over which we last
+	// replied with our AnnounceSignatures2, identified by the quit signal
+	// of the peer. We reply at most once per connection so that two nodes
+	// that have both completed the announcement don't keep replying to
+	// each other.
+	annSigs2Replies *lnutils.SyncMap[lnwire.ChannelID, <-chan struct{}]
+
 	quit chan struct{}
 	wg   sync.WaitGroup
 }
@@ -702,12 +781,22 @@ func NewFundingManager(cfg Config) (*Manager, error) {
 		fundingRequests: make(
 			chan *InitFundingMsg, msgBufferSize,
 		),
+		rbfRequests: make(chan *rbfRequest),
+		dualFundedTxs: &lnutils.SyncMap[
+			lnwire.ChannelID, *dualFundedTx,
+		]{},
 		localDiscoverySignals: &lnutils.SyncMap[
 			lnwire.ChannelID, chan struct{},
 		]{},
 		handleChannelReadyBarriers: &lnutils.SyncMap[
 			lnwire.ChannelID, struct{},
 		]{},
+		annSigs2: &lnutils.SyncMap[
+			lnwire.ChannelID, chan *lnwire.AnnounceSignatures2,
+		]{},
+		annSigs2Replies: &lnutils.SyncMap[
+			lnwire.ChannelID, <-chan struct{},
+		]{},
 		pendingMusigNonces: make(
 			map[lnwire.ChannelID]*musig2.Nonces,
 		),
@@ -960,6 +1049,8 @@ func (f *Manager) failFundingFlow(peer lnpeer.Peer, cid *chanIdentifier,
 				"%v", cid,
 				peer.IdentityKey().SerializeCompressed(), err)
 		}
+
+		f.dualFundedTxs.Delete(cid.chanID)
 	}
 
 	ctx, err := f.cancelReservationCtx(
@@ -1058,15 +1149,42 @@ func (f *Manager) reservationCoordinator() {
 			case *lnwire.ChannelReady:
 				f.handleChannelReady(fmsg.peer, msg)
 
+			case *lnwire.AnnounceSignatures2:
+				f.handleAnnounceSignatures2(fmsg.peer, msg)
+
 			case *lnwire.Warning:
 				f.handleWarningMsg(fmsg.peer, msg)
 
 			case *lnwire.Error:
 				f.handleErrorMsg(fmsg.peer, msg)
+
+			case *lnwire.TxAddInput, *lnwire.TxAddOutput,
+				*lnwire.TxRemoveInput, *lnwire.TxRemoveOutput,
+				*lnwire.TxComplete:
+
+				f.handleInteractiveTxMsg(fmsg.peer, msg)
+
+			case *lnwire.TxSignatures:
+				f.handleTxSignatures(fmsg.peer, msg)
+
+			case *lnwire.TxInitRbf:
+				f.handleTxInitRbf(fmsg.peer, msg)
+
+			case *lnwire.TxAckRbf:
+				f.handleTxAckRbf(fmsg.peer, msg)
+
+			case *lnwire.TxAbort:
+				f.handleTxAbort(fmsg.peer, msg)
+
+			case *lnwire.CommitSig:
+				f.handleCommitSig(fmsg.peer, msg)
 			}
 		case req := <-f.fundingRequests:
 			f.handleInitFundingMsg(req)
 
+		case req := <-f.rbfRequests:
+			req.err <- f.handleRbfRequest(req)
+
 		case <-zombieSweepTicker.C:
 			f.pruneZombieReservations()
 
@@ -1460,7 +1578,10 @@ func (f *Manager) fundeeProcessOpenChannel(peer lnpeer.Peer,
 
 	// If request specifies non-zero push amount and 'rejectpush' is set,
 	// signal an error.
-	if f.cfg.RejectPush && msg.PushAmount > 0 {
+	// The lease fee for inbound liquidity is paid through the push amount,
+	// so we only reject it for channels that aren't leased.
+	leased := msg.RequestFunds.IsSome()
+	if f.cfg.RejectPush && msg.PushAmount > 0 && !leased {
 		f.failFundingFlow(peer, cid, lnwallet.ErrNonZeroPushAmount())
 		return
 	}
@@ -1672,10 +1793,25 @@ func (f *Manager) fundeeProcessOpenChannel(peer lnpeer.Peer,
 
 		return
 
-	// The current variant of taproot channels can only be used with
-	// unadvertised channels for now.
-	case commitType.IsTaproot() && public:
-		err = fmt.Errorf("taproot channel type for public channel")
+	// Taproot channels can only be advertised using the v2 gossip
+	// messages, which requires both sides to understand them.
+	case commitType.IsTaproot() && public && !hasFeatures(
+		peer.LocalFeatures(), peer.RemoteFeatures(),
+		lnwire.GossipV2Optional,
+	):
+		err = fmt.Errorf("taproot channel type for public channel " +
+			"without gossip v2 support")
+		log.Errorf("Cancelling funding flow for public taproot "+
+			"channel %v: %v", cid, err)
+		f.failFundingFlow(peer, cid, err)
+
+		return
+
+	// The announcement of a public taproot channel is signed with nonces
+	// bound to its confirmed short channel ID, so it can't be zero-conf.
+	case commitType.IsTaproot() && public && zeroConf:
+		err = fmt.Errorf("zero-conf taproot channel type for public " +
+			"channel")
 		log.Errorf("Cancelling funding flow for public taproot "+
 			"channel %v: %v", cid, err)
 		f.failFundingFlow(peer, cid, err)
@@ -1683,6 +1819,76 @@ func (f *Manager) fundeeProcessOpenChannel(peer lnpeer.Peer,
 		return
 	}
 
+	// If the initiator requested a dual-funded channel, we contribute the
+	// amount our channel acceptor asked for to the funding output.
+	var (
+		dualFund        bool
+		dualFundFeeRate chainfee.SatPerKWeight
+		contribution    chainutil.Amount
+	)
+	msg.DualFundFeeRate.WhenSomeV(func(feeRate uint32) {
+		dualFund = true
+		dualFundFeeRate = chainfee.SatPerKWeight(feeRate)
+		contribution = acceptorResp.FundingContribution
+	})
+
+	// If the initiator buys inbound liquidity from us, we contribute at
+	// least the leased amount.
+	var peerKey [33]byte
+	copy(peerKey[:], peer.IdentityKey().SerializeCompressed())
+	lease, err := f.sellLease(msg, commitType, peerKey)
+	if err != nil {
+		log.Errorf("Cancelling leased channel %v: %v", cid, err)
+		f.failFundingFlow(peer, cid, err)
+
+		return
+	}
+	if lease != nil && contribution < lease.Amount {
+		contribution = lease.Amount
+	}
+
+	if dualFund {
+		switch {
+		case !hasFeatures(
+			peer.LocalFeatures(), peer.RemoteFeatures(),
+			lnwire.DualFundOptionalStaging,
+		):
+			err = errors.New("dual-funded channel without dual " +
+				"funding support")
+
+		case msg.PushAmount != 0 && lease == nil:
+			err = errors.New("push amount in dual-funded channel")
+
+		case commitType.IsTaproot():
+			err = fmt.Errorf("dual funding not supported for "+
+				"commitment type %v", commitType)
+
+		// A dual-funded channel isn't funded through a shim, so the
+		// thaw height of a script enforced lease can only come from
+		// the lease it's sold with.
+		case commitType == lnwallet.CommitmentTypeScriptEnforcedLease &&
+			lease == nil:
+
+			err = errors.New("script enforced lease channel without " +
+				"lease")
+
+		case zeroConf:
+			err = errors.New("zero-conf dual-funded channel")
+
+		case amt+contribution > f.cfg.MaxChanSize:
+			err = lnwallet.ErrChanTooLarge(
+				amt+contribution, f.cfg.MaxChanSize,
+			)
+		}
+		if err != nil {
+			log.Errorf("Cancelling dual-funded channel %v: %v",
+				cid, err)
+			f.failFundingFlow(peer, cid, err)
+
+			return
+		}
+	}
+
 	// At this point, if we have an AuxFundingController active, we'll
 	// check to see if we have a special tapscript root to use in our
 	// MuSig funding output.
@@ -1705,10 +1911,10 @@ func (f *Manager) fundeeProcessOpenChannel(peer lnpeer.Peer,
 		PendingChanID:    msg.PendingChannelID,
 		NodeID:           peer.IdentityKey(),
 		NodeAddr:         peer.Address(),
-		LocalFundingAmt:  0,
+		LocalFundingAmt:  contribution,
 		RemoteFundingAmt: amt,
 		CommitFeePerKw:   chainfee.SatPerKWeight(msg.FeePerKiloWeight),
-		FundingFeePerKw:  0,
+		FundingFeePerKw:  dualFundFeeRate,
 		PushMSat:         msg.PushAmount,
 		Flags:            msg.ChannelFlags,
 		MinConfs:         1,
@@ -1717,6 +1923,10 @@ func (f *Manager) fundeeProcessOpenChannel(peer lnpeer.Peer,
 		OptionScidAlias:  scid,
 		ScidAliasFeature: scidFeatureVal,
 		TapscriptRoot:    tapscriptRoot,
+		DualFunded:       dualFund,
+	}
+	if lease != nil {
+		req.LeaseExpiry = lease.Expiry
 	}
 
 	reservation, err := f.cfg.Wallet.InitChannelReservation(req)
@@ -2015,6 +2225,40 @@ func (f *Manager) fundeeProcessOpenChannel(peer lnpeer.Peer,
 		)
 	}
 
+	// For a dual-funded channel, we announce our contribution and get
+	// ready to construct the funding transaction once the initiator
+	// starts adding its inputs.
+	if dualFund {
+		locktime := msg.DualFundLocktime.ValOpt().UnwrapOr(0)
+		round := newDualFundRound(
+			false, dualFundFeeRate, locktime, contribution,
+		)
+		err := round.fund(reservation, amt)
+		if err == nil {
+			err = round.start(msg.PendingChannelID)
+		}
+		if err != nil {
+			log.Errorf("Unable to prepare funding tx "+
+				"construction: %v", err)
+			f.failFundingFlow(peer, cid, err)
+
+			return
+		}
+		resCtx.dualFund = round
+		resCtx.lease = lease
+
+		fundingAccept.FundingContribution = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType65541](
+				uint64(contribution),
+			),
+		)
+		if lease != nil {
+			fundingAccept.WillFund = tlv.SomeRecordT(
+				tlv.NewRecordT[tlv.TlvType8](lease.Rates),
+			)
+		}
+	}
+
 	if err := peer.SendMessage(true, &fundingAccept); err != nil {
 		log.Errorf("unable to send funding response to peer: %v", err)
 		f.failFundingFlow(peer, cid, err)
@@ -2051,6 +2295,12 @@ func (f *Manager) funderProcessAcceptChannel(peer lnpeer.Peer,
 		return
 	}
 
+	// The funding transaction of a dual-funded channel is already being
+	// constructed, so this is a duplicate of the accepted response.
+	if resCtx.dualFund != nil && resCtx.dualFund.session != nil {
+		return
+	}
+
 	log.Infof("Recv'd fundingResponse for pending_id(%x)",
 		pendingChanID[:])
 
@@ -2214,6 +2464,39 @@ func (f *Manager) funderProcessAcceptChannel(peer lnpeer.Peer,
 		UpfrontShutdown:      msg.UpfrontShutdownScript,
 	}
 
+	// For a dual-funded channel, the remote party tells us how much it
+	// contributes to the funding output, which may be nothing at all.
+	if resCtx.dualFund != nil {
+		contribution, err := msg.FundingContribution.ValOpt().
+			UnwrapOrErr(errors.New("funding contribution missing " +
+				"for dual-funded channel"))
+		if err != nil {
+			log.Error(err)
+			f.failFundingFlow(peer, cid, err)
+			return
+		}
+
+		remoteContribution.FundingAmount = chainutil.Amount(
+			contribution,
+		)
+
+		// If we buy inbound liquidity, the remote party must sell it
+		// to us as agreed.
+		if resCtx.lease != nil {
+			err := checkLeaseAccepted(
+				resCtx.lease, msg,
+				remoteContribution.FundingAmount,
+			)
+			if err != nil {
+				log.Errorf("Cancelling leased channel %v: %v",
+					cid, err)
+				f.failFundingFlow(peer, cid, err)
+
+				return
+			}
+		}
+	}
+
 	if resCtx.reservation.IsTaproot() {
 		localNonce, err := msg.LocalNonce.UnwrapOrErrV(errNoLocalNonce)
 		if err != nil {
@@ -2266,6 +2549,16 @@ func (f *Manager) funderProcessAcceptChannel(peer lnpeer.Peer,
 			},
 		}
 		psbtIntent = psbtErr.Intent
+	} else if _, ok := err.(*lnwallet.InteractiveFundingRequired); ok {
+		// The wallet halted the funding process of a dual-funded
+		// channel, as its funding transaction is constructed together
+		// with the remote party, which is started below.
+		if resCtx.dualFund == nil {
+			log.Errorf("Unexpected interactive funding for "+
+				"contribution from %x", peerKeyBytes)
+			f.failFundingFlow(peer, cid, err)
+			return
+		}
 	} else if err != nil {
 		log.Errorf("Unable to process contribution from %x: %v",
 			peerKeyBytes, err)
@@ -2300,6 +2593,22 @@ func (f *Manager) funderProcessAcceptChannel(peer lnpeer.Peer,
 		return
 	}
 
+	// For a dual-funded channel, we start the interactive construction of
+	// the funding transaction and continue the flow once it completed.
+	if resCtx.dualFund != nil {
+		err := f.startDualFunding(
+			resCtx, cid, remoteContribution.FundingAmount,
+		)
+		if err != nil {
+			log.Errorf("Unable to start funding transaction "+
+				"construction for pending_id(%x): %v",
+				pendingChanID[:], err)
+			f.failFundingFlow(peer, cid, err)
+		}
+
+		return
+	}
+
 	// In a normal, non-PSBT funding flow, we can jump directly to the next
 	// step where we expect our contribution to be finalized.
 	f.continueFundingAccept(resCtx, cid)
@@ -2445,6 +2754,12 @@ func (f *Manager) continueFundingAccept(resCtx *reservationWithCtx,
 	// funding flow fails.
 	cid.setChanID(channelID)
 
+	// The funding transaction of a dual-funded channel is signed by both
+	// parties once the commitments were exchanged.
+	if resCtx.dualFund != nil {
+		f.trackDualFundedTx(resCtx, channelID)
+	}
+
 	// Send the FundingCreated msg.
 	fundingCreated := &lnwire.FundingCreated{
 		PendingChannelID: cid.tempChanID,
@@ -2520,6 +2835,19 @@ func (f *Manager) fundeeProcessFundingCreated(peer lnpeer.Peer,
 	// Create the channel identifier without setting the active channel ID.
 	cid := newChanIdentifier(pendingChanID)
 
+	// For a dual-funded channel, the initiator must reference the funding
+	// output of the transaction we constructed together.
+	if resCtx.dualFund != nil {
+		err := resCtx.dualFund.checkFundingPoint(fundingOut)
+		if err != nil {
+			log.Errorf("Invalid funding outpoint for "+
+				"pending_id(%x): %v", pendingChanID[:], err)
+			f.failFundingFlow(peer, cid, err)
+
+			return
+		}
+	}
+
 	// For taproot channels, the commit signature is actually the partial
 	// signature. Otherwise, we can convert the ECDSA commit signature into
 	// our internal input.Signature type.
@@ -2666,6 +2994,10 @@ func (f *Manager) fundeeProcessFundingCreated(peer lnpeer.Peer,
 	// funding flow fails.
 	cid.setChanID(channelID)
 
+	if resCtx.dualFund != nil {
+		f.trackDualFundedTx(resCtx, channelID)
+	}
+
 	fundingSigned.ChanID = cid.chanID
 
 	log.Infof("sending FundingSigned for pending_id(%x) over "+
@@ -2723,6 +3055,12 @@ func (f *Manager) fundeeProcessFundingCreated(peer lnpeer.Peer,
 	// completely forget about this channel if we haven't seen the funding
 	// transaction in 288 blocks (~ 48 hrs), by canceling the reservation
 	// and canceling the wait for the funding confirmation.
+	//
+	// For a dual-funded channel, both parties now sign their inputs of
+	// the funding transaction, which is then published.
+	f.recordLease(resCtx, completeChan)
+	f.dualFundingSigned(cid.chanID, completeChan)
+
 	f.wg.Add(1)
 	go f.advanceFundingState(completeChan, pendingChanID, nil)
 }
@@ -2933,6 +3271,11 @@ func (f *Manager) funderProcessFundingSigned(peer lnpeer.Peer,
 		return
 	}
 
+	// The funding transaction of a dual-funded channel is only broadcast
+	// once both parties signed their inputs.
+	f.recordLease(resCtx, completeChan)
+	f.dualFundingSigned(cid.chanID, completeChan)
+
 	// At this point we have broadcast the funding transaction and done all
 	// necessary processing.
 	f.wg.Add(1)
@@ -2984,6 +3327,8 @@ func (f *Manager) fundingTimeout(c *chanstate.OpenChannel,
 			c.FundingOutpoint, err)
 	}
 
+	f.dualFundedTxs.Delete(lnwire.NewChanIDFromOutPoint(c.FundingOutpoint))
+
 	// Notify other subsystems about the funding timeout.
 	f.cfg.NotifyFundingTimeout(c.FundingOutpoint, c.IdentityPub)
 
@@ -3150,6 +3495,14 @@ func (f *Manager) waitForFundingConfirmation(
 	log.Infof("Waiting for funding tx (%v) to reach %v confirmations",
 		txid, numConfs)
 
+	// The funding transaction of a dual-funded channel may be replaced by
+	// one paying a higher fee, so we also wait for the replacement to
+	// confirm.
+	chanID := lnwire.NewChanIDFromOutPoint(completeChan.FundingOutpoint)
+	replacementConf := f.watchFundingReplacement(
+		completeChan, numConfs, fundingScript, cancelChan,
+	)
+
 	// Wait until the specified number of confirmations has been reached,
 	// we get a cancel signal, or the wallet signals a shutdown.
 	for {
@@ -3245,6 +3598,20 @@ func (f *Manager) waitForFundingConfirmation(
 				}
 			}
 
+			// A replacement of the funding transaction that was
+			// signed but didn't confirm is dropped.
+			if completeChan.PendingSpliceInfo().IsSome() {
+				err := completeChan.AbortPendingSplice()
+				if err != nil {
+					log.Errorf("Unable to drop funding tx "+
+						"replacement of "+
+						"ChannelPoint(%v): %v",
+						completeChan.FundingOutpoint,
+						err)
+				}
+			}
+			f.dualFundedTxs.Delete(chanID)
+
 			err := f.handleConfirmation(
 				confDetails, completeChan, confChan,
 			)
@@ -3256,6 +3623,45 @@ func (f *Manager) waitForFundingConfirmation(
 
 			return
 
+		case confDetails := <-replacementConf:
+			log.Infof("Funding tx replacement for ChannelPoint(%v) "+
+				"confirmed in block %d",
+				completeChan.FundingOutpoint,
+				confDetails.BlockHeight)
+
+			f.dualFundedTxs.Delete(chanID)
+
+			err := f.lockFundingReplacement(completeChan)
+			if err != nil {
+				log.Errorf("Unable to switch ChannelPoint(%v) "+
+					"to funding tx replacement: %v",
+					completeChan.FundingOutpoint, err)
+
+				return
+			}
+
+			err = completeChan.MarkConfirmationHeight(
+				confDetails.BlockHeight,
+			)
+			if err != nil {
+				log.Errorf("failed to update confirmed state "+
+					"for ChannelPoint(%v): %v",
+					completeChan.FundingOutpoint, err)
+
+				return
+			}
+
+			err = f.handleConfirmation(
+				confDetails, completeChan, confChan,
+			)
+			if err != nil {
+				log.Errorf("Error handling confirmation for "+
+					"ChannelPoint(%v): %v",
+					completeChan.FundingOutpoint, err)
+			}
+
+			return
+
 		case <-cancelChan:
 			log.Warnf("canceled waiting for funding confirmation, "+
 				"stopping funding flow for ChannelPoint(%v)",
@@ -3286,11 +3692,12 @@ func (f *Manager) handleConfirmation(confDetails *chainntnfs.TxConfirmation,
 
 	// With the block height and the transaction index known, we can
 	// construct the compact chanID which is used on the network to unique
-	// identify channels.
+	// identify channels. The funding output of a replaced funding
+	// transaction may be at another position.
 	shortChanID := lnwire.ShortChannelID{
 		BlockHeight: confDetails.BlockHeight,
 		TxIndex:     confDetails.TxIndex,
-		TxPosition:  uint16(fundingPoint.Index),
+		TxPosition:  uint16(completeChan.FundingTxOutpoint().Index),
 	}
 
 	select {
@@ -3539,6 +3946,14 @@ func (f *Manager) sendChannelReady(completeChan *chanstate.OpenChannel,
 		)
 	}
 
+	// If this is a public taproot channel, we'll also send along the
+	// nonces needed to sign the channel announcement with the remote
+	// party.
+	err = f.addAnnouncementNonces(completeChan, channelReadyMsg)
+	if err != nil {
+		return err
+	}
+
 	// If the channel negotiated the option-scid-alias feature bit, we'll
 	// send a TLV segment that includes an alias the peer can use in their
 	// invoice hop hints. We'll send the first alias we find for the
@@ -3723,23 +4138,41 @@ func (f *Manager) addToGraph(completeChan *chanstate.OpenChannel,
 
 	fwdMinHTLC, fwdMaxHTLC := f.extractAnnounceParams(completeChan)
 
-	ann, err := f.newChanAnnouncement(
-		f.cfg.IDKey, completeChan.IdentityPub,
-		&completeChan.LocalChanCfg.MultiSigKey,
-		completeChan.RemoteChanCfg.MultiSigKey.PubKey, *shortChanID,
-		chanID, fwdMinHTLC, fwdMaxHTLC, ourPolicy,
-		completeChan.ChanType,
-	)
-	if err != nil {
-		return fmt.Errorf("error generating channel "+
-			"announcement: %v", err)
+	// Public taproot channels are announced using the v2 gossip messages,
+	// all other channels use the v1 messages.
+	var chanAnn, chanUpdateAnn lnwire.Message
+	if isPublicTaproot(completeChan) {
+		ann, err := f.newChanAnnouncement2(
+			completeChan, *shortChanID, fwdMinHTLC, fwdMaxHTLC,
+			ourPolicy,
+		)
+		if err != nil {
+			return fmt.Errorf("error generating channel "+
+				"announcement: %v", err)
+		}
+
+		chanAnn, chanUpdateAnn = ann.chanAnn, ann.chanUpdateAnn
+	} else {
+		ann, err := f.newChanAnnouncement(
+			f.cfg.IDKey, completeChan.IdentityPub,
+			&completeChan.LocalChanCfg.MultiSigKey,
+			completeChan.RemoteChanCfg.MultiSigKey.PubKey,
+			*shortChanID, chanID, fwdMinHTLC, fwdMaxHTLC,
+			ourPolicy, completeChan.ChanType,
+		)
+		if err != nil {
+			return fmt.Errorf("error generating channel "+
+				"announcement: %v", err)
+		}
+
+		chanAnn, chanUpdateAnn = ann.chanAnn, ann.chanUpdateAnn
 	}
 
 	// Send ChannelAnnouncement and ChannelUpdate to the gossiper to add
 	// to the Router's topology.
 	errChan := f.cfg.SendAnnouncement(
-		ann.chanAnn, discovery.ChannelCapacity(completeChan.Capacity),
-		discovery.ChannelPoint(completeChan.FundingOutpoint),
+		chanAnn, discovery.ChannelCapacity(completeChan.Capacity),
+		discovery.ChannelPoint(completeChan.FundingTxOutpoint()),
 		discovery.TapscriptRoot(completeChan.TapscriptRoot),
 	)
 	select {
@@ -3760,7 +4193,7 @@ func (f *Manager) addToGraph(completeChan *chanstate.OpenChannel,
 	}
 
 	errChan = f.cfg.SendAnnouncement(
-		ann.chanUpdateAnn, discovery.RemoteAlias(peerAlias),
+		chanUpdateAnn, discovery.RemoteAlias(peerAlias),
 	)
 	select {
 	case err := <-errChan:
@@ -3831,7 +4264,7 @@ func (f *Manager) annAfterSixConfs(completeChan *chanstate.OpenChannel,
 		if numConfs < 6 {
 			numConfs = 6
 		}
-		txid := completeChan.FundingOutpoint.Hash
+		txid := completeChan.FundingTxOutpoint().Hash
 		log.Debugf("Will announce channel %v after ChannelPoint"+
 			"(%v) has gotten %d confirmations",
 			shortChanID.ToUint64(), completeChan.FundingOutpoint,
@@ -3916,13 +4349,19 @@ func (f *Manager) annAfterSixConfs(completeChan *chanstate.OpenChannel,
 		}
 
 		// Create and broadcast the proofs required to make this channel
-		// public and usable for other nodes for routing.
-		err = f.announceChannel(
-			f.cfg.IDKey, completeChan.IdentityPub,
-			&completeChan.LocalChanCfg.MultiSigKey,
-			completeChan.RemoteChanCfg.MultiSigKey.PubKey,
-			*shortChanID, chanID, completeChan.ChanType,
-		)
+		// public and usable for other nodes for routing. For public
+		// taproot channels, this requires a MuSig2 signing session
+		// with the remote party.
+		if isPublicTaproot(completeChan) {
+			err = f.announceChannel2(completeChan, *shortChanID)
+		} else {
+			err = f.announceChannel(
+				f.cfg.IDKey, completeChan.IdentityPub,
+				&completeChan.LocalChanCfg.MultiSigKey,
+				completeChan.RemoteChanCfg.MultiSigKey.PubKey,
+				*shortChanID, chanID, completeChan.ChanType,
+			)
+		}
 		if err != nil {
 			return fmt.Errorf("channel announcement failed: %w",
 				err)
@@ -4289,6 +4728,13 @@ func (f *Manager) processChannelReady(peer lnpeer.Peer,
 				)
 			}
 
+			err = f.addAnnouncementNonces(channel, channelReadyMsg)
+			if err != nil {
+				log.Errorf("unable to add announcement "+
+					"nonces: %v", err)
+				return
+			}
+
 			err = peer.SendMessage(true, channelReadyMsg)
 			if err != nil {
 				log.Errorf("unable to send channel_ready: %v",
@@ -4298,6 +4744,18 @@ func (f *Manager) processChannelReady(peer lnpeer.Peer,
 		}
 	}
 
+	// If this is a public taproot channel, we'll store the nonces the
+	// remote party will use to sign the channel announcement with us. We
+	// do this before the duplicate check below in case storing them failed
+	// while processing a previous channel_ready.
+	if isPublicTaproot(channel) {
+		err := f.storeAnnouncementNonces(channel, msg)
+		if err != nil {
+			log.Errorf("Unable to store announcement nonces for "+
+				"ChannelID(%v): %v", chanID, err)
+		}
+	}
+
 	// If the RemoteNextRevocation is non-nil, it means that we have
 	// already processed channelReady for this channel, so ignore. This
 	// check is after the alias logic so we store the peer's most recent
@@ -4813,17 +5271,21 @@ func (f *Manager) announceChannel(localIDKey, remoteIDKey *crypto.PublicKey,
 		return ErrFundingManagerShuttingDown
 	}
 
-	// Now that the channel is announced to the network, we will also
-	// obtain and send a node announcement. This is done since a node
-	// announcement is only accepted after a channel is known for that
-	// particular node, and this might be our first channel.
+	return f.sendNodeAnnouncement()
+}
+
+// sendNodeAnnouncement obtains and sends our current node announcement to the
+// gossiper. This is done once a channel is announced to the network, since a
+// node announcement is only accepted after a channel is known for that
+// particular node, and this might be our first channel.
+func (f *Manager) sendNodeAnnouncement() error {
 	nodeAnn, err := f.cfg.CurrentNodeAnnouncement()
 	if err != nil {
 		log.Errorf("can't generate node announcement: %v", err)
 		return err
 	}
 
-	errChan = f.cfg.SendAnnouncement(&nodeAnn)
+	errChan := f.cfg.SendAnnouncement(&nodeAnn)
 	select {
 	case err := <-errChan:
 		if err != nil {
@@ -4846,63 +5308,690 @@ func (f *Manager) announceChannel(localIDKey, remoteIDKey *crypto.PublicKey,
 	return nil
 }
 
-// InitFundingWorkflow sends a message to the funding manager instructing it
-// to initiate a single funder workflow with the source peer.
-func (f *Manager) InitFundingWorkflow(msg *InitFundingMsg) {
-	f.fundingRequests <- msg
+// isPublicTaproot returns true if the given channel is a taproot channel that
+// is to be announced to the network. Such channels are announced using the v2
+// gossip messages.
+func isPublicTaproot(c *chanstate.OpenChannel) bool {
+	return c.ChanType.IsTaproot() &&
+		c.ChannelFlags&lnwire.FFAnnounceChannel != 0
 }
 
-// getUpfrontShutdownScript takes a user provided script and a getScript
-// function which can be used to generate an upfront shutdown script. If our
-// peer does not support the feature, this function will error if a non-zero
-// script was provided by the user, and return an empty script otherwise. If
-// our peer does support the feature, we will return the user provided script
-// if non-zero, or a freshly generated script if our node is configured to set
-// upfront shutdown scripts automatically.
-func getUpfrontShutdownScript(enableUpfrontShutdown bool, peer lnpeer.Peer,
-	script lnwire.DeliveryAddress,
-	getScript func(bool) (lnwire.DeliveryAddress, error)) (lnwire.DeliveryAddress,
-	error) {
+// localAnnouncementNonces derives the nonces that our node key and our funding
+// key use to sign the channel announcement of the given public taproot
+// channel. The nonces are derived deterministically from the channel's
+// revocation root and are bound to its short channel ID, so they can be
+// re-derived after a restart to produce the same partial signature.
+func (f *Manager) localAnnouncementNonces(c *chanstate.OpenChannel) (
+	*musig2.Nonces, *musig2.Nonces, error) {
 
-	// Check whether the remote peer supports upfront shutdown scripts.
-	remoteUpfrontShutdown := peer.RemoteFeatures().HasFeature(
-		lnwire.UpfrontShutdownScriptOptional,
+	shaGen, err := chanstate.DeriveAnnouncementShachain(
+		c.RevocationProducer,
 	)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// If the peer does not support upfront shutdown scripts, and one has been
-	// provided, return an error because the feature is not supported.
-	if !remoteUpfrontShutdown && len(script) != 0 {
-		return nil, errUpfrontShutdownScriptNotSupported
+	scid := c.ShortChannelID.ToUint64()
+	nodeNonce, err := chanstate.NewAnnouncementNonce(
+		f.cfg.IDKey, chanstate.AnnouncementNodeNonceIndex, scid,
+		shaGen,
+	)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// If the peer does not support upfront shutdown, return an empty address.
-	if !remoteUpfrontShutdown {
-		return nil, nil
+	fundingNonce, err := chanstate.NewAnnouncementNonce(
+		c.LocalChanCfg.MultiSigKey.PubKey,
+		chanstate.AnnouncementFundingNonceIndex, scid, shaGen,
+	)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// If the user has provided an script and the peer supports the feature,
-	// return it. Note that user set scripts override the enable upfront
-	// shutdown flag.
-	if len(script) > 0 {
-		return script, nil
+	return nodeNonce, fundingNonce, nil
+}
+
+// addAnnouncementNonces adds our channel announcement nonces to the given
+// channel_ready message if the channel is a public taproot channel.
+func (f *Manager) addAnnouncementNonces(c *chanstate.OpenChannel,
+	msg *lnwire.ChannelReady) error {
+
+	if !isPublicTaproot(c) {
+		return nil
 	}
 
-	// If we do not have setting of upfront shutdown script enabled, return
-	// an empty script.
-	if !enableUpfrontShutdown {
-		return nil, nil
+	nodeNonce, fundingNonce, err := f.localAnnouncementNonces(c)
+	if err != nil {
+		return fmt.Errorf("unable to derive announcement nonces: %w",
+			err)
 	}
 
-	// We can safely send a taproot address iff, both sides have negotiated
-	// the shutdown-any-segwit feature.
-	taprootOK := peer.RemoteFeatures().HasFeature(lnwire.ShutdownAnySegwitOptional) &&
-		peer.LocalFeatures().HasFeature(lnwire.ShutdownAnySegwitOptional)
+	msg.AnnouncementNodeNonce = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType0, lnwire.Musig2Nonce](
+			nodeNonce.PubNonce,
+		),
+	)
+	msg.AnnouncementFlokicoinNonce = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType2, lnwire.Musig2Nonce](
+			fundingNonce.PubNonce,
+		),
+	)
 
-	return getScript(taprootOK)
+	return nil
 }
 
-// handleInitFundingMsg creates a channel reservation within the daemon's
-// wallet, then sends a funding request to the remote peer kicking off the
+// annNoncesKey returns the key under which the remote party's channel
+// announcement nonces of the given channel are stored.
+func annNoncesKey(chanPoint *wire.OutPoint) ([]byte, error) {
+	var b bytes.Buffer
+	if err := WriteOutpoint(&b, chanPoint); err != nil {
+		return nil, err
+	}
+
+	if _, err := b.Write(annNoncesKeySuffix); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// storeAnnouncementNonces stores the channel announcement nonces the remote
+// party sent in its channel_ready message, so that we're able to sign the
+// announcement of the channel once it has six confirmations, even across
+// restarts.
+func (f *Manager) storeAnnouncementNonces(c *chanstate.OpenChannel,
+	msg *lnwire.ChannelReady) error {
+
+	nodeNonce, err := msg.AnnouncementNodeNonce.UnwrapOrErr(
+		errNoAnnouncementNonces,
+	)
+	if err != nil {
+		return err
+	}
+	fundingNonce, err := msg.AnnouncementFlokicoinNonce.UnwrapOrErr(
+		errNoAnnouncementNonces,
+	)
+	if err != nil {
+		return err
+	}
+
+	key, err := annNoncesKey(&c.FundingOutpoint)
+	if err != nil {
+		return err
+	}
+
+	value := make([]byte, 0, 2*musig2.PubNonceSize)
+	value = append(value, nodeNonce.Val[:]...)
+	value = append(value, fundingNonce.Val[:]...)
+
+	return f.cfg.ChannelDB.SaveChannelOpeningState(key, value)
+}
+
+// fetchAnnouncementNonces returns the channel announcement nonces of the remote
+// party's node key and funding key for the given channel.
+func (f *Manager) fetchAnnouncementNonces(c *chanstate.OpenChannel) (
+	[musig2.PubNonceSize]byte, [musig2.PubNonceSize]byte, error) {
+
+	var nodeNonce, fundingNonce [musig2.PubNonceSize]byte
+
+	key, err := annNoncesKey(&c.FundingOutpoint)
+	if err != nil {
+		return nodeNonce, fundingNonce, err
+	}
+
+	value, err := f.cfg.ChannelDB.GetChannelOpeningState(key)
+	switch {
+	case errors.Is(err, channeldb.ErrChannelNotFound):
+		return nodeNonce, fundingNonce, errNoAnnouncementNonces
+
+	case err != nil:
+		return nodeNonce, fundingNonce, err
+
+	case len(value) != 2*musig2.PubNonceSize:
+		return nodeNonce, fundingNonce, fmt.Errorf("invalid "+
+			"announcement nonces length: %v", len(value))
+	}
+
+	copy(nodeNonce[:], value[:musig2.PubNonceSize])
+	copy(fundingNonce[:], value[musig2.PubNonceSize:])
+
+	return nodeNonce, fundingNonce, nil
+}
+
+// chanAnnouncement2 encapsulates the v2 announcements that we send to the
+// gossiper after a new public taproot channel has been created locally.
+type chanAnnouncement2 struct {
+	chanAnn       *lnwire.ChannelAnnouncement2
+	chanUpdateAnn *lnwire.ChannelUpdate2
+}
+
+// unsignedChanAnnouncement2 creates the unsigned channel_announcement_2 of the
+// given public taproot channel. Both parties must arrive at the exact same
+// message, as it's signed by a 4-of-4 MuSig2 session over both node keys and
+// both funding keys.
+func (f *Manager) unsignedChanAnnouncement2(c *chanstate.OpenChannel,
+	shortChanID lnwire.ShortChannelID) *lnwire.ChannelAnnouncement2 {
+
+	chanAnn := &lnwire.ChannelAnnouncement2{
+		ChainHash: tlv.NewPrimitiveRecord[tlv.TlvType0](
+			*f.cfg.Wallet.Cfg.NetParams.GenesisHash,
+		),
+		Features: tlv.NewRecordT[tlv.TlvType2](
+			*lnwire.NewRawFeatureVector(),
+		),
+		ShortChannelID: tlv.NewRecordT[tlv.TlvType4](shortChanID),
+		Capacity: tlv.NewPrimitiveRecord[tlv.TlvType6](
+			uint64(c.Capacity),
+		),
+		Outpoint: tlv.NewRecordT[tlv.TlvType18](
+			lnwire.OutPoint(c.FundingOutpoint),
+		),
+		ExtraSignedFields: make(lnwire.ExtraSignedFields),
+	}
+
+	var (
+		localNodeKey     [33]byte
+		remoteNodeKey    [33]byte
+		localFundingKey  [33]byte
+		remoteFundingKey [33]byte
+		flcKey1, flcKey2 [33]byte
+	)
+	copy(localNodeKey[:], f.cfg.IDKey.SerializeCompressed())
+	copy(remoteNodeKey[:], c.IdentityPub.SerializeCompressed())
+	copy(
+		localFundingKey[:],
+		c.LocalChanCfg.MultiSigKey.PubKey.SerializeCompressed(),
+	)
+	copy(
+		remoteFundingKey[:],
+		c.RemoteChanCfg.MultiSigKey.PubKey.SerializeCompressed(),
+	)
+
+	// The lexicographical ordering of the two identity public keys of the
+	// nodes indicates which of the nodes is "first".
+	if bytes.Compare(localNodeKey[:], remoteNodeKey[:]) == -1 {
+		chanAnn.NodeID1.Val = localNodeKey
+		chanAnn.NodeID2.Val = remoteNodeKey
+		flcKey1, flcKey2 = localFundingKey, remoteFundingKey
+	} else {
+		chanAnn.NodeID1.Val = remoteNodeKey
+		chanAnn.NodeID2.Val = localNodeKey
+		flcKey1, flcKey2 = remoteFundingKey, localFundingKey
+	}
+
+	// We always include the funding keys, so that the signature is a
+	// 4-of-4 MuSig2 over the node keys and the funding keys, which proves
+	// that the signers control the funding output.
+	chanAnn.FlokicoinKey1 = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType12](flcKey1),
+	)
+	chanAnn.FlokicoinKey2 = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType14](flcKey2),
+	)
+
+	c.TapscriptRoot.WhenSome(func(root chainhash.Hash) {
+		chanAnn.MerkleRootHash = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType16, [32]byte](root),
+		)
+	})
+
+	return chanAnn
+}
+
+// newChanAnnouncement2 creates the unsigned v2 channel announcement of a public
+// taproot channel along with our signed v2 channel update. The announcement
+// itself is only signed once the channel has six confirmations, see
+// announceChannel2. ourPolicy may be set in order to re-use an existing,
+// non-default policy.
+func (f *Manager) newChanAnnouncement2(c *chanstate.OpenChannel,
+	shortChanID lnwire.ShortChannelID, fwdMinHTLC,
+	fwdMaxHTLC lnwire.MilliLoki,
+	ourPolicy *models.ChannelEdgePolicy) (*chanAnnouncement2, error) {
+
+	chanID := lnwire.NewChanIDFromOutPoint(c.FundingOutpoint)
+	chanAnn := f.unsignedChanAnnouncement2(c, shortChanID)
+
+	// The first update of the channel commits to the block height the
+	// channel was confirmed in, later updates will use a greater height.
+	chanUpdateAnn := &lnwire.ChannelUpdate2{
+		ChainHash:      chanAnn.ChainHash,
+		ShortChannelID: tlv.NewRecordT[tlv.TlvType2](shortChanID),
+		BlockHeight: tlv.NewPrimitiveRecord[tlv.TlvType4](
+			shortChanID.BlockHeight,
+		),
+		CLTVExpiryDelta: tlv.NewPrimitiveRecord[tlv.TlvType10](
+			uint16(f.cfg.DefaultRoutingPolicy.TimeLockDelta),
+		),
+		HTLCMinimumMsat:   tlv.NewRecordT[tlv.TlvType12](fwdMinHTLC),
+		HTLCMaximumMsat:   tlv.NewRecordT[tlv.TlvType14](fwdMaxHTLC),
+		ExtraSignedFields: make(lnwire.ExtraSignedFields),
+	}
+
+	// If we're the second node, we'll signal the direction of the update
+	// by setting the second_peer record.
+	localNodeKey := f.cfg.IDKey.SerializeCompressed()
+	if !bytes.Equal(chanAnn.NodeID1.Val[:], localNodeKey) {
+		chanUpdateAnn.SecondPeer = tlv.SomeRecordT(
+			tlv.ZeroRecordT[tlv.TlvType8, lnwire.TrueBoolean](),
+		)
+	}
+
+	// The caller of newChanAnnouncement2 is expected to provide the
+	// initial forwarding policy to be announced. If no persisted initial
+	// policy values are found, then we will use the default policy values
+	// in the channel update.
+	storedFwdingPolicy, err := f.getInitialForwardingPolicy(chanID)
+	if err != nil && !errors.Is(err, channeldb.ErrChannelNotFound) {
+		return nil, fmt.Errorf("unable to generate channel "+
+			"update announcement: %w", err)
+	}
+
+	switch {
+	case ourPolicy != nil:
+		chanUpdateAnn.CLTVExpiryDelta.Val = ourPolicy.TimeLockDelta
+		chanUpdateAnn.HTLCMinimumMsat.Val = ourPolicy.MinHTLC
+		chanUpdateAnn.HTLCMaximumMsat.Val = ourPolicy.MaxHTLC
+		chanUpdateAnn.FeeBaseMsat.Val = uint32(ourPolicy.FeeBaseMSat)
+		chanUpdateAnn.FeeProportionalMillionths.Val = uint32(
+			ourPolicy.FeeProportionalMillionths,
+		)
+
+		// The block height of the new update must not be lower than
+		// the one of the policy we're re-using.
+		if ourPolicy.BlockHeight > chanUpdateAnn.BlockHeight.Val {
+			chanUpdateAnn.BlockHeight.Val = ourPolicy.BlockHeight
+		}
+
+	case storedFwdingPolicy != nil:
+		chanUpdateAnn.FeeBaseMsat.Val = uint32(
+			storedFwdingPolicy.BaseFee,
+		)
+		chanUpdateAnn.FeeProportionalMillionths.Val = uint32(
+			storedFwdingPolicy.FeeRate,
+		)
+
+	default:
+		log.Infof("No channel forwarding policy specified for channel "+
+			"announcement of ChannelID(%v). "+
+			"Assuming default fee parameters.", chanID)
+		chanUpdateAnn.FeeBaseMsat.Val = uint32(
+			f.cfg.DefaultRoutingPolicy.BaseFee,
+		)
+		chanUpdateAnn.FeeProportionalMillionths.Val = uint32(
+			f.cfg.DefaultRoutingPolicy.FeeRate,
+		)
+	}
+
+	// With the channel update constructed, we'll sign it with our node
+	// key using a Schnorr signature over its tagged digest.
+	data, err := lnwire.SerialiseFieldsToSign(chanUpdateAnn)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := f.cfg.SignMessageSchnorr(
+		f.cfg.IDKeyLoc, data, false, nil, netann.ChanUpdate2DigestTag(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate channel "+
+			"update announcement signature: %w", err)
+	}
+	chanUpdateAnn.Signature.Val, err = lnwire.NewSigFromSignature(sig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate channel "+
+			"update announcement signature: %w", err)
+	}
+
+	return &chanAnnouncement2{
+		chanAnn:       chanAnn,
+		chanUpdateAnn: chanUpdateAnn,
+	}, nil
+}
+
+// signChanAnnouncement2 creates our part of the 4-of-4 MuSig2 signature of the
+// given channel announcement. We run a signing session for both our node key
+// and our funding key, and return the sum of the two resulting partial
+// signatures.
+func (f *Manager) signChanAnnouncement2(c *chanstate.OpenChannel,
+	chanAnn *lnwire.ChannelAnnouncement2) (*musig2.PartialSignature,
+	error) {
+
+	remoteNodeNonce, remoteFundingNonce, err := f.fetchAnnouncementNonces(
+		c,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	localNodeNonce, localFundingNonce, err := f.localAnnouncementNonces(c)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := netann.ChanAnn2DigestToSign(chanAnn)
+	if err != nil {
+		return nil, err
+	}
+
+	signers := []*crypto.PublicKey{
+		f.cfg.IDKey, c.IdentityPub,
+		c.LocalChanCfg.MultiSigKey.PubKey,
+		c.RemoteChanCfg.MultiSigKey.PubKey,
+	}
+
+	sessions := []struct {
+		keyLoc      keychain.KeyLocator
+		localNonce  *musig2.Nonces
+		otherNonces [][musig2.PubNonceSize]byte
+	}{
+		{
+			keyLoc:     f.cfg.IDKeyLoc,
+			localNonce: localNodeNonce,
+			otherNonces: [][musig2.PubNonceSize]byte{
+				localFundingNonce.PubNonce, remoteNodeNonce,
+				remoteFundingNonce,
+			},
+		},
+		{
+			keyLoc:     c.LocalChanCfg.MultiSigKey.KeyLocator,
+			localNonce: localFundingNonce,
+			otherNonces: [][musig2.PubNonceSize]byte{
+				localNodeNonce.PubNonce, remoteNodeNonce,
+				remoteFundingNonce,
+			},
+		},
+	}
+
+	signer := f.cfg.Wallet.Cfg.Signer
+
+	var (
+		partialSig crypto.ModNScalar
+		finalNonce *crypto.PublicKey
+	)
+	for _, session := range sessions {
+		sessionInfo, err := signer.MuSig2CreateSession(
+			input.MuSig2Version100RC2, session.keyLoc, signers,
+			&input.MuSig2Tweaks{}, session.otherNonces,
+			session.localNonce,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create announcement "+
+				"signing session: %w", err)
+		}
+
+		sig, err := signer.MuSig2Sign(
+			sessionInfo.SessionID, *digest, true,
+		)
+		if err != nil {
+			_ = signer.MuSig2Cleanup(sessionInfo.SessionID)
+
+			return nil, fmt.Errorf("unable to sign channel "+
+				"announcement: %w", err)
+		}
+
+		partialSig.Add(sig.S)
+		finalNonce = sig.R
+	}
+
+	return &musig2.PartialSignature{
+		S: &partialSig,
+		R: finalNonce,
+	}, nil
+}
+
+// announceChannel2 announces a newly created public taproot channel to the
+// rest of the network. The channel_announcement_2 is signed using a 4-of-4
+// MuSig2 session with the remote party: we exchange our partial signatures in
+// AnnounceSignatures2 messages, combine them and hand the fully signed
+// announcement to the gossiper. Afterwards, our node announcement is sent out.
+//
+// This method is synchronous and will return once the channel is announced,
+// which requires the remote party to send us its partial signature.
+func (f *Manager) announceChannel2(c *chanstate.OpenChannel,
+	shortChanID lnwire.ShortChannelID) error {
+
+	chanID := lnwire.NewChanIDFromOutPoint(c.FundingOutpoint)
+	chanAnn := f.unsignedChanAnnouncement2(c, shortChanID)
+
+	localSig, err := f.signChanAnnouncement2(c, chanAnn)
+	if err != nil {
+		return fmt.Errorf("unable to sign channel announcement: %w",
+			err)
+	}
+
+	annSigs := lnwire.NewAnnSigs2(
+		chanID, shortChanID, lnwire.NewPartialSig(*localSig.S),
+	)
+
+	remoteSigs, _ := f.annSigs2.LoadOrStore(
+		chanID, make(chan *lnwire.AnnounceSignatures2, 1),
+	)
+	defer f.annSigs2.Delete(chanID)
+
+	// We'll send our partial signature to the remote party and wait for
+	// theirs. If the connection drops in the meantime, we'll re-send our
+	// partial signature once the peer is back online.
+	var remoteSig *lnwire.AnnounceSignatures2
+	for remoteSig == nil {
+		peer, err := f.waitForPeerOnline(c.IdentityPub)
+		if err != nil {
+			return err
+		}
+
+		log.Debugf("Sending AnnounceSignatures2 for ChannelID(%v) "+
+			"to peer %x", chanID, peer.PubKey())
+
+		if err := peer.SendMessage(true, annSigs); err != nil {
+			log.Warnf("Unable to send AnnounceSignatures2 for "+
+				"ChannelID(%v): %v", chanID, err)
+		}
+
+		select {
+		case remoteSig = <-remoteSigs:
+
+		case <-peer.QuitSignal():
+			log.Debugf("Peer %x disconnected while waiting for "+
+				"AnnounceSignatures2 for ChannelID(%v)",
+				peer.PubKey(), chanID)
+
+		case <-f.quit:
+			return ErrFundingManagerShuttingDown
+		}
+	}
+
+	// With both partial signatures at hand, we can now create the final
+	// signature of the announcement.
+	finalSig := musig2.CombineSigs(
+		localSig.R, []*musig2.PartialSignature{
+			localSig, {
+				S: &remoteSig.PartialSignature.Val.Sig,
+			},
+		},
+	)
+	chanAnn.Signature.Val, err = lnwire.NewSigFromSignature(finalSig)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Announcing taproot ChannelPoint(%v), short_chan_id=%v",
+		c.FundingOutpoint, shortChanID)
+
+	// The gossiper will validate the signature against the funding output
+	// before adding the proof to the graph and broadcasting the
+	// announcement.
+	errChan := f.cfg.SendAnnouncement(
+		chanAnn, discovery.ChannelCapacity(c.Capacity),
+		discovery.ChannelPoint(c.FundingOutpoint),
+		discovery.TapscriptRoot(c.TapscriptRoot),
+	)
+	select {
+	case err := <-errChan:
+		if err != nil {
+			if graph.IsError(err, graph.ErrOutdated,
+				graph.ErrIgnored) {
+
+				log.Debugf("Graph rejected "+
+					"ChannelAnnouncement2: %v", err)
+			} else {
+				log.Errorf("Unable to send channel "+
+					"announcement: %v", err)
+				return err
+			}
+		}
+
+	case <-f.quit:
+		return ErrFundingManagerShuttingDown
+	}
+
+	return f.sendNodeAnnouncement()
+}
+
+// handleAnnounceSignatures2 processes an AnnounceSignatures2 message sent by
+// the remote party of a public taproot channel. If we're still in the process
+// of announcing the channel, the message is handed to announceChannel2.
+// Otherwise, the remote party is still waiting for our partial signature, so
+// we'll re-send it.
+func (f *Manager) handleAnnounceSignatures2(peer lnpeer.Peer,
+	msg *lnwire.AnnounceSignatures2) {
+
+	chanID := msg.ChannelID.Val
+
+	channel, err := f.cfg.FindChannel(peer.IdentityKey(), chanID)
+	if err != nil {
+		log.Errorf("Unable to locate ChannelID(%v) for "+
+			"AnnounceSignatures2: %v", chanID, err)
+		return
+	}
+
+	if !isPublicTaproot(channel) ||
+		channel.ShortChannelID != msg.ShortChannelID.Val {
+
+		log.Warnf("Ignoring AnnounceSignatures2 for ChannelID(%v) "+
+			"with short_chan_id=%v", chanID,
+			msg.ShortChannelID.Val)
+		return
+	}
+
+	// If the channel is still in the opening process, we'll hand the
+	// message over to the goroutine that will announce the channel. The
+	// message is buffered in case that goroutine hasn't started yet.
+	_, _, err = f.getChannelOpeningState(&channel.FundingOutpoint)
+	if err == nil {
+		remoteSigs, _ := f.annSigs2.LoadOrStore(
+			chanID, make(chan *lnwire.AnnounceSignatures2, 1),
+		)
+
+		select {
+		case remoteSigs <- msg:
+		default:
+			log.Debugf("Ignoring duplicate AnnounceSignatures2 "+
+				"for ChannelID(%v)", chanID)
+		}
+
+		return
+	}
+	if !errors.Is(err, channeldb.ErrChannelNotFound) {
+		log.Errorf("Unable to fetch opening state of "+
+			"ChannelID(%v): %v", chanID, err)
+		return
+	}
+
+	// Otherwise, we've already announced the channel, and the remote
+	// party hasn't received our partial signature yet. We only reply once
+	// per connection to avoid replying back and forth with a peer that
+	// has completed the announcement as well.
+	quit := peer.QuitSignal()
+	lastQuit, loaded := f.annSigs2Replies.Load(chanID)
+	if loaded && lastQuit == quit {
+		return
+	}
+	f.annSigs2Replies.Store(chanID, quit)
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		chanAnn := f.unsignedChanAnnouncement2(
+			channel, channel.ShortChannelID,
+		)
+		localSig, err := f.signChanAnnouncement2(channel, chanAnn)
+		if err != nil {
+			log.Errorf("Unable to sign announcement of "+
+				"ChannelID(%v): %v", chanID, err)
+			return
+		}
+
+		annSigs := lnwire.NewAnnSigs2(
+			chanID, channel.ShortChannelID,
+			lnwire.NewPartialSig(*localSig.S),
+		)
+
+		log.Debugf("Re-sending AnnounceSignatures2 for "+
+			"ChannelID(%v) to peer %x", chanID, peer.PubKey())
+
+		if err := peer.SendMessage(false, annSigs); err != nil {
+			log.Errorf("Unable to send AnnounceSignatures2 for "+
+				"ChannelID(%v): %v", chanID, err)
+		}
+	}()
+}
+
+// InitFundingWorkflow sends a message to the funding manager instructing it
+// to initiate a single funder workflow with the source peer.
+func (f *Manager) InitFundingWorkflow(msg *InitFundingMsg) {
+	f.fundingRequests <- msg
+}
+
+// getUpfrontShutdownScript takes a user provided script and a getScript
+// function which can be used to generate an upfront shutdown script. If our
+// peer does not support the feature, this function will error if a non-zero
+// script was provided by the user, and return an empty script otherwise. If
+// our peer does support the feature, we will return the user provided script
+// if non-zero, or a freshly generated script if our node is configured to set
+// upfront shutdown scripts automatically.
+func getUpfrontShutdownScript(enableUpfrontShutdown bool, peer lnpeer.Peer,
+	script lnwire.DeliveryAddress,
+	getScript func(bool) (lnwire.DeliveryAddress, error)) (lnwire.DeliveryAddress,
+	error) {
+
+	// Check whether the remote peer supports upfront shutdown scripts.
+	remoteUpfrontShutdown := peer.RemoteFeatures().HasFeature(
+		lnwire.UpfrontShutdownScriptOptional,
+	)
+
+	// If the peer does not support upfront shutdown scripts, and one has been
+	// provided, return an error because the feature is not supported.
+	if !remoteUpfrontShutdown && len(script) != 0 {
+		return nil, errUpfrontShutdownScriptNotSupported
+	}
+
+	// If the peer does not support upfront shutdown, return an empty address.
+	if !remoteUpfrontShutdown {
+		return nil, nil
+	}
+
+	// If the user has provided an script and the peer supports the feature,
+	// return it. Note that user set scripts override the enable upfront
+	// shutdown flag.
+	if len(script) > 0 {
+		return script, nil
+	}
+
+	// If we do not have setting of upfront shutdown script enabled, return
+	// an empty script.
+	if !enableUpfrontShutdown {
+		return nil, nil
+	}
+
+	// We can safely send a taproot address iff, both sides have negotiated
+	// the shutdown-any-segwit feature.
+	taprootOK := peer.RemoteFeatures().HasFeature(lnwire.ShutdownAnySegwitOptional) &&
+		peer.LocalFeatures().HasFeature(lnwire.ShutdownAnySegwitOptional)
+
+	return getScript(taprootOK)
+}
+
+// handleInitFundingMsg creates a channel reservation within the daemon's
+// wallet, then sends a funding request to the remote peer kicking off the
 // funding workflow.
 func (f *Manager) handleInitFundingMsg(msg *InitFundingMsg) {
 	var (
@@ -4986,9 +6075,15 @@ func (f *Manager) handleInitFundingMsg(msg *InitFundingMsg) {
 	//
 	// Before we init the channel, we'll also check to see what commitment
 	// format we can use with this peer. This is dependent on *both* us and
-	// the remote peer are signaling the proper feature bit.
+	// the remote party are signaling the proper feature bit. A leased
+	// channel must enforce the lease in its scripts, so unless a channel
+	// type was requested explicitly, we request that of a lease.
+	reqChanType := msg.ChannelType
+	if msg.LeaseRequest.IsSome() && reqChanType == nil {
+		reqChanType = leaseChannelType()
+	}
 	chanType, commitType, err := negotiateCommitmentType(
-		msg.ChannelType, msg.Peer.LocalFeatures(),
+		reqChanType, msg.Peer.LocalFeatures(),
 		msg.Peer.RemoteFeatures(),
 	)
 	if err != nil {
@@ -5021,14 +6116,33 @@ func (f *Manager) handleInitFundingMsg(msg *InitFundingMsg) {
 		}
 	}
 
-	// The current variant of taproot channels can only be used with
-	// unadvertised channels for now.
+	// Taproot channels can only be advertised using the v2 gossip
+	// messages, which requires both sides to understand them. As the
+	// announcement is signed with nonces bound to the confirmed short
+	// channel ID, such a channel can't be zero-conf either.
 	if commitType.IsTaproot() && !msg.Private {
-		err = fmt.Errorf("taproot channel type for public channel")
-		log.Error(err)
-		msg.Err <- err
+		gossipV2 := hasFeatures(
+			msg.Peer.LocalFeatures(), msg.Peer.RemoteFeatures(),
+			lnwire.GossipV2Optional,
+		)
 
-		return
+		if !gossipV2 {
+			err = fmt.Errorf("taproot channel type for public " +
+				"channel without gossip v2 support")
+			log.Error(err)
+			msg.Err <- err
+
+			return
+		}
+
+		if zeroConf {
+			err = fmt.Errorf("zero-conf taproot channel type for " +
+				"public channel")
+			log.Error(err)
+			msg.Err <- err
+
+			return
+		}
 	}
 
 	// First, we'll query the fee estimator for a fee that should get the
@@ -5075,6 +6189,35 @@ func (f *Manager) handleInitFundingMsg(msg *InitFundingMsg) {
 		return
 	}
 
+	// If both parties support it, the funding transaction is constructed
+	// interactively, allowing the remote party to contribute to the
+	// channel as well.
+	dualFund := useDualFunding(msg, commitType, zeroConf)
+
+	// The funding transaction of a dual-funded channel is locked to the
+	// current height to discourage fee sniping.
+	var fundingLocktime uint32
+	if dualFund {
+		_, bestHeight, err := f.cfg.Wallet.Cfg.ChainIO.GetBestBlock()
+		if err != nil {
+			msg.Err <- err
+			return
+		}
+		fundingLocktime = uint32(bestHeight)
+	}
+
+	// If we buy inbound liquidity from the peer, we push the lease fee to
+	// it.
+	pushAmt := msg.PushAmt
+	lease, err := buyLease(msg, commitType, dualFund, fundingLocktime)
+	if err != nil {
+		msg.Err <- err
+		return
+	}
+	if lease != nil {
+		pushAmt = lnwire.NewMSatFromLokis(lease.Fee)
+	}
+
 	req := &lnwallet.InitFundingReserveMsg{
 		ChainHash:         &msg.ChainHash,
 		PendingChanID:     chanID,
@@ -5089,7 +6232,7 @@ func (f *Manager) handleInitFundingMsg(msg *InitFundingMsg) {
 		Outpoints:         outpoints,
 		CommitFeePerKw:    commitFeePerKw,
 		FundingFeePerKw:   msg.FundingFeePerKw,
-		PushMSat:          msg.PushAmt,
+		PushMSat:          pushAmt,
 		Flags:             channelFlags,
 		MinConfs:          msg.MinConfs,
 		CommitType:        commitType,
@@ -5115,6 +6258,10 @@ func (f *Manager) handleInitFundingMsg(msg *InitFundingMsg) {
 		ScidAliasFeature: scidFeatureVal,
 		Memo:             msg.Memo,
 		TapscriptRoot:    tapscriptRoot,
+		DualFunded:       dualFund,
+	}
+	if lease != nil {
+		req.LeaseExpiry = lease.Expiry
 	}
 
 	reservation, err := f.cfg.Wallet.InitChannelReservation(req)
@@ -5223,6 +6370,12 @@ func (f *Manager) handleInitFundingMsg(msg *InitFundingMsg) {
 		peer:              msg.Peer,
 		updates:           msg.Updates,
 		err:               msg.Err,
+		lease:             lease,
+	}
+	if dualFund {
+		resCtx.dualFund = newDualFundRound(
+			true, msg.FundingFeePerKw, fundingLocktime, capacity,
+		)
 	}
 	f.activeReservations[peerIDKey][chanID] = resCtx
 	f.resMtx.Unlock()
@@ -5272,7 +6425,7 @@ func (f *Manager) handleInitFundingMsg(msg *InitFundingMsg) {
 		ChainHash:             *f.cfg.Wallet.Cfg.NetParams.GenesisHash,
 		PendingChannelID:      chanID,
 		FundingAmount:         capacity,
-		PushAmount:            msg.PushAmt,
+		PushAmount:            pushAmt,
 		DustLimit:             ourDustLimit,
 		MaxValueInFlight:      maxValue,
 		ChannelReserve:        chanReserve,
@@ -5298,6 +6451,25 @@ func (f *Manager) handleInitFundingMsg(msg *InitFundingMsg) {
 		)
 	}
 
+	if dualFund {
+		fundingOpen.DualFundFeeRate = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType65541](
+				uint32(msg.FundingFeePerKw),
+			),
+		)
+		fundingOpen.DualFundLocktime = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType65543](
+				fundingLocktime,
+			),
+		)
+	}
+
+	msg.LeaseRequest.WhenSome(func(req lnwire.LeaseRequest) {
+		fundingOpen.RequestFunds = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType10](req),
+		)
+	})
+
 	if err := msg.Peer.SendMessage(true, &fundingOpen); err != nil {
 		e := fmt.Errorf("unable to send funding request message: %w",
 			err)
@@ -5328,6 +6500,17 @@ func (f *Manager) handleErrorMsg(peer lnpeer.Peer, msg *lnwire.Error) {
 	chanID := msg.ChanID
 	peerKey := peer.IdentityKey()
 
+	// An error for a pending dual-funded channel ends an ongoing
+	// replacement of its funding transaction, while the channel itself
+	// keeps waiting for the current funding transaction to confirm.
+	if tx, ok := f.dualFundedTxs.Load(chanID); ok {
+		log.Warnf("Received error for dual-funded ChannelID(%v): %v",
+			chanID, msg.Error())
+		f.resetReplacement(tx)
+
+		return
+	}
+
 	// First, we'll attempt to retrieve and cancel the funding workflow
 	// that this error was tied to. If we're unable to do so, then we'll
 	// exit early as this was an unwarranted error.
@@ -5504,6 +6687,14 @@ func (f *Manager) IsPendingChannel(pendingChanID PendingChanID,
 	_, ok := f.activeReservations[peerIDKey][pendingChanID]
 	f.resMtx.RUnlock()
 
+	if ok {
+		return true
+	}
+
+	// The funding transaction of a pending dual-funded channel is still
+	// signed or replaced through the funding manager.
+	_, ok = f.dualFundedTxs.Load(pendingChanID)
+
 	return ok
 }
 
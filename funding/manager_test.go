@@ -38,11 +38,15 @@ import (
 	"github.com/flokiorg/flnd/lnwallet/chainfee"
 	"github.com/flokiorg/flnd/lnwallet/chanfunding"
 	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/netann"
+	"github.com/flokiorg/flnd/shachain"
 	"github.com/flokiorg/go-flokicoin/chaincfg"
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/crypto"
 	"github.com/flokiorg/go-flokicoin/crypto/ecdsa"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr/musig2"
+	"github.com/flokiorg/go-flokicoin/txscript"
 	"github.com/flokiorg/go-flokicoin/wire"
 	"github.com/flokiorg/walletd/wallet"
 	"github.com/stretchr/testify/require"
@@ -570,6 +574,9 @@ func createTestFundingManager(t *testing.T, privKey *crypto.PrivateKey,
 		OpenChannelPredicate:          chainedAcceptor,
 		NotifyPendingOpenChannelEvent: evt.NotifyPendingOpenChannelEvent,
 		NotifyFundingTimeout:          evt.NotifyFundingTimeout,
+		NotifyFundingTxReplaced: func(wire.OutPoint) error {
+			return nil
+		},
 		DeleteAliasEdge: func(scid lnwire.ShortChannelID) (
 			*models.ChannelEdgePolicy, error) {
 
@@ -5377,3 +5384,169 @@ func TestChannelReadyUnknownChannelID(t *testing.T) {
 		t, alice, bob, 500000, 0, 1, updateChan, true, nil,
 	)
 }
+
+// TestSignChanAnnouncement2 tests that both parties of a public taproot channel
+// arrive at the same channel_ready announcement nonces and channel
+// announcement, and that their partial signatures combine into a signature
+// that is valid for the channel's funding output.
+func TestSignChanAnnouncement2(t *testing.T) {
+	t.Parallel()
+
+	var (
+		scid      = lnwire.NewShortChanIDFromInt(0x12345600000101)
+		chanPoint = wire.OutPoint{Index: 1}
+		capacity  = chainutil.Amount(1_000_000)
+		chanType  = channeldb.SimpleTaprootFeatureBit |
+			channeldb.SingleFunderTweaklessBit
+		nodeLoc = keychain.KeyLocator{
+			Family: keychain.KeyFamilyNodeKey,
+		}
+		fundingLoc = keychain.KeyLocator{
+			Family: keychain.KeyFamilyMultiSig,
+		}
+	)
+
+	type party struct {
+		nodeKey    *crypto.PrivateKey
+		fundingKey *crypto.PrivateKey
+		mgr        *Manager
+		channel    *chanstate.OpenChannel
+	}
+
+	newParty := func() *party {
+		nodeKey, err := crypto.NewPrivateKey()
+		require.NoError(t, err)
+		fundingKey, err := crypto.NewPrivateKey()
+		require.NoError(t, err)
+
+		// The signer only knows about the node and funding key of
+		// this party.
+		musigSigner := input.NewMusigSessionManager(
+			func(desc *keychain.KeyDescriptor) (*crypto.PrivateKey,
+				error) {
+
+				switch desc.KeyLocator {
+				case nodeLoc:
+					return nodeKey, nil
+				case fundingLoc:
+					return fundingKey, nil
+				default:
+					return nil, fmt.Errorf("unknown key")
+				}
+			},
+		)
+
+		wallet := &lnwallet.LightningWallet{
+			Cfg: lnwallet.Config{
+				Signer: &mock.SingleSigner{
+					Privkey:             nodeKey,
+					MusigSessionManager: musigSigner,
+				},
+				NetParams: *fundingNetParams.Params,
+			},
+		}
+
+		var revRoot chainhash.Hash
+		_, err = rand.Read(revRoot[:])
+		require.NoError(t, err)
+
+		cdb := channeldb.OpenForTesting(t, t.TempDir())
+		revProducer := shachain.NewRevocationProducer(revRoot)
+
+		return &party{
+			nodeKey:    nodeKey,
+			fundingKey: fundingKey,
+			mgr: &Manager{
+				cfg: &Config{
+					IDKey:     nodeKey.PubKey(),
+					IDKeyLoc:  nodeLoc,
+					Wallet:    wallet,
+					ChannelDB: cdb.ChannelStateDB(),
+				},
+			},
+			channel: &chanstate.OpenChannel{
+				ChanType:        chanType,
+				ChannelFlags:    lnwire.FFAnnounceChannel,
+				FundingOutpoint: chanPoint,
+				ShortChannelID:  scid,
+				Capacity:        capacity,
+				LocalChanCfg: channeldb.ChannelConfig{
+					MultiSigKey: keychain.KeyDescriptor{
+						KeyLocator: fundingLoc,
+						PubKey:     fundingKey.PubKey(),
+					},
+				},
+				RevocationProducer: revProducer,
+			},
+		}
+	}
+
+	alice, bob := newParty(), newParty()
+	alice.channel.IdentityPub = bob.nodeKey.PubKey()
+	alice.channel.RemoteChanCfg.MultiSigKey.PubKey = bob.fundingKey.PubKey()
+	bob.channel.IdentityPub = alice.nodeKey.PubKey()
+	bob.channel.RemoteChanCfg.MultiSigKey.PubKey = alice.fundingKey.PubKey()
+
+	// Exchange the announcement nonces as part of channel_ready.
+	exchangeNonces := func(from, to *party) {
+		var msg lnwire.ChannelReady
+		err := from.mgr.addAnnouncementNonces(from.channel, &msg)
+		require.NoError(t, err)
+		require.True(t, msg.AnnouncementNodeNonce.IsSome())
+		require.True(t, msg.AnnouncementFlokicoinNonce.IsSome())
+
+		err = to.mgr.storeAnnouncementNonces(to.channel, &msg)
+		require.NoError(t, err)
+	}
+	exchangeNonces(alice, bob)
+	exchangeNonces(bob, alice)
+
+	// Both parties must sign the exact same announcement.
+	aliceAnn := alice.mgr.unsignedChanAnnouncement2(alice.channel, scid)
+	bobAnn := bob.mgr.unsignedChanAnnouncement2(bob.channel, scid)
+	require.Equal(t, aliceAnn, bobAnn)
+
+	aliceSig, err := alice.mgr.signChanAnnouncement2(
+		alice.channel, aliceAnn,
+	)
+	require.NoError(t, err)
+	bobSig, err := bob.mgr.signChanAnnouncement2(bob.channel, bobAnn)
+	require.NoError(t, err)
+
+	// Re-deriving the partial signature, as done when the remote party
+	// asks for it again, must result in the same signature.
+	aliceSig2, err := alice.mgr.signChanAnnouncement2(
+		alice.channel, aliceAnn,
+	)
+	require.NoError(t, err)
+	require.True(t, aliceSig.S.Equals(aliceSig2.S))
+
+	finalSig := musig2.CombineSigs(
+		aliceSig.R, []*musig2.PartialSignature{
+			aliceSig, {S: bobSig.S},
+		},
+	)
+	aliceAnn.Signature.Val, err = lnwire.NewSigFromSignature(finalSig)
+	require.NoError(t, err)
+
+	// The combined signature must be valid for the taproot funding output
+	// of the channel.
+	pkScript, _, err := input.GenTaprootFundingScript(
+		alice.fundingKey.PubKey(), bob.fundingKey.PubKey(),
+		int64(capacity), fn.None[chainhash.Hash](),
+	)
+	require.NoError(t, err)
+	addr, err := chainutil.NewAddressTaproot(
+		pkScript[2:], fundingNetParams.Params,
+	)
+	require.NoError(t, err)
+
+	err = netann.ValidateChannelAnn(
+		aliceAnn, func(lnwire.ShortChannelID) (txscript.ScriptClass,
+			chainutil.Address, error) {
+
+			return txscript.WitnessV1TaprootTy, addr, nil
+		},
+	)
+	require.NoError(t, err)
+}
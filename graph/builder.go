@@ -898,6 +898,44 @@ func (b *Builder) assertNodeAnnFreshness(ctx context.Context, node route.Vertex,
 	return nil
 }
 
+// assertNodeAnn2Freshness is the gossip v2 counterpart of
+// assertNodeAnnFreshness. As v2 node announcements carry no timestamp, a new
+// announcement is only fresh if it was signed at a higher block height than
+// the v2 announcement we already have.
+func (b *Builder) assertNodeAnn2Freshness(ctx context.Context,
+	node route.Vertex, blockHeight uint32) error {
+
+	// As with v1 announcements, we'll ignore the announcements of nodes
+	// that we don't know of through any channel.
+	_, exists, err := b.cfg.Graph.HasNode(ctx, node)
+	if err != nil {
+		return fmt.Errorf("unable to query for the "+
+			"existence of node: %w", err)
+	}
+	if !exists {
+		return NewErrf(ErrIgnored, "Ignoring node announcement"+
+			" for node not found in channel graph (%x)",
+			node[:])
+	}
+
+	dbNode, err := b.cfg.Graph.FetchNodeV2(ctx, node)
+	switch {
+	case errors.Is(err, graphdb.ErrGraphNodeNotFound):
+		return nil
+
+	case err != nil:
+		return fmt.Errorf("unable to fetch v2 node %x: %w", node[:],
+			err)
+	}
+
+	if blockHeight <= dbNode.BlockHeight {
+		return NewErrf(ErrOutdated, "Ignoring outdated "+
+			"announcement for %x", node[:])
+	}
+
+	return nil
+}
+
 // MarkZombieEdge adds a channel that failed complete validation into the zombie
 // index so we can avoid having to re-validate it in the future.
 func (b *Builder) MarkZombieEdge(chanID uint64) error {
@@ -1000,7 +1038,16 @@ func (b *Builder) addNode(ctx context.Context, node *models.Node,
 	// Before we add the node to the database, we'll check to see if the
 	// announcement is "fresh" or not. If it isn't, then we'll return an
 	// error.
-	err := b.assertNodeAnnFreshness(ctx, node.PubKeyBytes, node.LastUpdate)
+	var err error
+	if node.GossipVersion() == lnwire.GossipVersion2 {
+		err = b.assertNodeAnn2Freshness(
+			ctx, node.PubKeyBytes, node.BlockHeight,
+		)
+	} else {
+		err = b.assertNodeAnnFreshness(
+			ctx, node.PubKeyBytes, node.LastUpdate,
+		)
+	}
 	if err != nil {
 		return err
 	}
@@ -1321,6 +1368,22 @@ func (b *Builder) IsStaleNode(ctx context.Context, node route.Vertex,
 	return false
 }
 
+// IsStaleNodeV2 returns true if the graph source has a v2 node announcement
+// for the target node that was signed at the same or a higher block height.
+//
+// NOTE: This method is part of the ChannelGraphSource interface.
+func (b *Builder) IsStaleNodeV2(ctx context.Context, node route.Vertex,
+	blockHeight uint32) bool {
+
+	err := b.assertNodeAnn2Freshness(ctx, node, blockHeight)
+	if err != nil {
+		log.Debugf("Checking stale v2 node %s got %v", node, err)
+		return true
+	}
+
+	return false
+}
+
 // IsPublicNode determines whether the given vertex is seen as a public node in
 // the graph from the graph's source node's point of view.
 //
@@ -11,9 +11,11 @@ import (
 
 	"github.com/flokiorg/flnd/batch"
 	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/wire"
 	"github.com/stretchr/testify/require"
 )
@@ -29,6 +31,10 @@ type ChannelGraph struct {
 
 	graphCache *GraphCache
 
+	// leaseIndex indexes the lease rates advertised by nodes selling
+	// inbound liquidity.
+	leaseIndex *leaseIndex
+
 	V1Store
 	*topologyManager
 
@@ -48,6 +54,7 @@ func NewChannelGraph(v1Store V1Store,
 	g := &ChannelGraph{
 		V1Store:         v1Store,
 		topologyManager: newTopologyManager(),
+		leaseIndex:      newLeaseIndex(),
 		quit:            make(chan struct{}),
 	}
 
@@ -77,6 +84,10 @@ func (c *ChannelGraph) Start() error {
 		}
 	}
 
+	if err := c.populateLeaseIndex(context.TODO()); err != nil {
+		return fmt.Errorf("could not populate the lease index: %w", err)
+	}
+
 	c.wg.Add(1)
 	go c.handleTopologySubscriptions()
 
@@ -190,6 +201,26 @@ func (c *ChannelGraph) populateCache(ctx context.Context) error {
 	return nil
 }
 
+// populateLeaseIndex indexes the lease rates of all nodes in the graph.
+func (c *ChannelGraph) populateLeaseIndex(ctx context.Context) error {
+	return c.V1Store.ForEachNode(ctx, func(node *models.Node) error {
+		c.leaseIndex.addNode(node)
+
+		return nil
+	}, func() {
+		c.leaseIndex = newLeaseIndex()
+	})
+}
+
+// CheapestLeaseSellers returns up to numSellers nodes that sell inbound
+// liquidity, ordered by the fee they charge for leasing amt given the fee
+// rate of the funding transaction, cheapest first.
+func (c *ChannelGraph) CheapestLeaseSellers(amt chainutil.Amount,
+	feeRate chainfee.SatPerKWeight, numSellers int) []LeaseSeller {
+
+	return c.leaseIndex.cheapestSellers(amt, feeRate, numSellers)
+}
+
 // ForEachNodeDirectedChannel iterates through all channels of a given node,
 // executing the passed callback on the directed edge representing the channel
 // and its incoming policy. If the callback returns an error, then the iteration
@@ -276,10 +307,15 @@ func (c *ChannelGraph) AddNode(ctx context.Context,
 		return err
 	}
 
-	if c.graphCache != nil {
-		c.graphCache.AddNodeFeatures(
-			node.PubKeyBytes, node.Features,
-		)
+	// The cached features and lease rates of a node are those of its v1
+	// announcement, which a v2 announcement doesn't replace.
+	if node.GossipVersion() == lnwire.GossipVersion1 {
+		if c.graphCache != nil {
+			c.graphCache.AddNodeFeatures(
+				node.PubKeyBytes, node.Features,
+			)
+		}
+		c.leaseIndex.addNode(node)
 	}
 
 	select {
@@ -304,6 +340,7 @@ func (c *ChannelGraph) DeleteNode(ctx context.Context,
 	if c.graphCache != nil {
 		c.graphCache.RemoveNode(nodePub)
 	}
+	c.leaseIndex.removeNode(nodePub)
 
 	return nil
 }
@@ -465,6 +502,10 @@ func (c *ChannelGraph) PruneGraph(spentOutputs []*wire.OutPoint,
 			c.graphCache.Stats())
 	}
 
+	for _, node := range nodes {
+		c.leaseIndex.removeNode(node)
+	}
+
 	if len(edges) != 0 {
 		// Notify all currently registered clients of the newly closed
 		// channels.
@@ -498,6 +539,10 @@ func (c *ChannelGraph) PruneGraphNodes() error {
 		}
 	}
 
+	for _, node := range nodes {
+		c.leaseIndex.removeNode(node)
+	}
+
 	return nil
 }
 
@@ -261,6 +261,69 @@ func TestNodeInsertionAndDeletion(t *testing.T) {
 	require.Equal(t, expAddrs, dbNode.Addresses)
 }
 
+// TestNodeV2InsertionAndDeletion tests that the gossip v2 announcement of a
+// node is stored next to its v1 announcement, and that both are removed when
+// the node is deleted.
+func TestNodeV2InsertionAndDeletion(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	graph := MakeTestGraph(t)
+
+	node := createTestVertex(t)
+	pub := node.PubKeyBytes
+
+	nodeV2 := func(blockHeight uint32) *models.Node {
+		node, err := models.NewV2Node(pub, &models.NodeV2Fields{
+			BlockHeight: blockHeight,
+			Addresses:   testAddrs,
+			Signature:   bytes.Repeat([]byte{1}, 64),
+			Features:    testFeatures.RawFeatureVector,
+			Color:       fn.Some(color.RGBA{1, 2, 3, 0}),
+			Alias:       fn.Some("kek"),
+			LastUpdate:  time.Unix(1232342, 0),
+		})
+		require.NoError(t, err)
+
+		return node
+	}
+
+	// We don't have a v2 announcement for the node yet.
+	_, err := graph.FetchNodeV2(ctx, pub)
+	require.ErrorIs(t, err, ErrGraphNodeNotFound)
+
+	require.NoError(t, graph.AddNode(ctx, node))
+
+	// Adding the v2 announcement of the node must leave its v1
+	// announcement untouched.
+	node2 := nodeV2(100)
+	require.NoError(t, graph.AddNode(ctx, node2))
+
+	dbNode, err := graph.FetchNode(ctx, pub)
+	require.NoError(t, err)
+	compareNodes(t, node, dbNode)
+
+	dbNode2, err := graph.FetchNodeV2(ctx, pub)
+	require.NoError(t, err)
+	compareNodes(t, node2, dbNode2)
+
+	// A later v2 announcement replaces the previous one.
+	node2 = nodeV2(200)
+	require.NoError(t, graph.AddNode(ctx, node2))
+
+	dbNode2, err = graph.FetchNodeV2(ctx, pub)
+	require.NoError(t, err)
+	compareNodes(t, node2, dbNode2)
+
+	// Deleting the node removes both of its announcements.
+	require.NoError(t, graph.DeleteNode(ctx, pub))
+
+	_, err = graph.FetchNode(ctx, pub)
+	require.ErrorIs(t, err, ErrGraphNodeNotFound)
+	_, err = graph.FetchNodeV2(ctx, pub)
+	require.ErrorIs(t, err, ErrGraphNodeNotFound)
+}
+
 // TestPartialNode checks that we can add and retrieve a Node where
 // only the pubkey is known to the database.
 func TestPartialNode(t *testing.T) {
@@ -1306,6 +1369,148 @@ func TestAddEdgeProof(t *testing.T) {
 	require.Equal(t, edge2, dbEdge2)
 }
 
+// TestV2ChannelEdge tests that channels and policies announced using the v2
+// gossip protocol can be stored and retrieved alongside v1 channels.
+func TestV2ChannelEdge(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	graph := MakeTestGraph(t)
+
+	sourceNode := createTestVertex(t)
+	require.NoError(t, graph.SetSourceNode(ctx, sourceNode))
+
+	node1 := createTestVertex(t)
+	node2 := createTestVertex(t)
+	require.NoError(t, graph.AddNode(ctx, node1))
+	require.NoError(t, graph.AddNode(ctx, node2))
+
+	// Create a v2 edge that doesn't carry the funding keys but does
+	// commit to a tapscript root.
+	edge, pol1, pol2 := createChannelEdge(node1, node2)
+	edge.Version = lnwire.GossipVersion2
+	edge.FlokicoinKey1Bytes = [33]byte{}
+	edge.FlokicoinKey2Bytes = [33]byte{}
+	edge.MerkleRootHash = fn.Some(chainhash.Hash{1, 2, 3})
+	edge.ExtraOpaqueData = []byte{1, 1, 1}
+
+	fundingScript := make([]byte, 34)
+	_, err := prand.Read(fundingScript)
+	require.NoError(t, err)
+	edge.FundingScript = fn.Some(fundingScript)
+
+	// The edge is first added without a proof, as is the case for our
+	// own channels before the announcement signatures are exchanged.
+	edge.AuthProof = nil
+	require.NoError(t, graph.AddChannelEdge(ctx, edge))
+
+	dbEdge, _, _, err := graph.FetchChannelEdgesByID(edge.ChannelID)
+	require.NoError(t, err)
+	require.Equal(t, edge, dbEdge)
+
+	schnorrSig := make([]byte, 64)
+	_, err = prand.Read(schnorrSig)
+	require.NoError(t, err)
+	edge.AuthProof = models.NewV2ChannelAuthProof(schnorrSig)
+
+	require.NoError(t, graph.AddEdgeProof(
+		lnwire.NewShortChanIDFromInt(edge.ChannelID), edge.AuthProof,
+	))
+
+	// A SCID can only be known under a single gossip version.
+	v1Edge, _, _ := createChannelEdge(node1, node2)
+	v1Edge.ChannelID = edge.ChannelID
+	require.ErrorIs(
+		t, graph.AddChannelEdge(ctx, v1Edge), ErrEdgeAlreadyExist,
+	)
+
+	dbEdge, _, _, err = graph.FetchChannelEdgesByID(edge.ChannelID)
+	require.NoError(t, err)
+	require.Equal(t, edge, dbEdge)
+
+	// Add a policy for each direction.
+	pol1.Version = lnwire.GossipVersion2
+	pol1.BlockHeight = 1000
+	pol1.SigBytes = schnorrSig
+	pol1.ExtraOpaqueData = []byte{1, 1, 1}
+	pol1.InboundFee = fn.Some(lnwire.Fee{BaseFee: -10, FeeRate: -20})
+
+	pol2.Version = lnwire.GossipVersion2
+	pol2.BlockHeight = 1001
+	pol2.DisableFlags = lnwire.ChanUpdateDisableIncoming
+	pol2.ChannelFlags |= lnwire.ChanUpdateDisabled
+	pol2.SigBytes = schnorrSig
+	pol2.ExtraOpaqueData = []byte{1, 1, 1}
+
+	require.NoError(t, graph.UpdateEdgePolicy(ctx, pol1))
+	require.NoError(t, graph.UpdateEdgePolicy(ctx, pol2))
+
+	assertV2Policy := func(exp, got *models.ChannelEdgePolicy) {
+		t.Helper()
+
+		require.NotNil(t, got)
+		require.NoError(t, compareEdgePolicies(exp, got))
+		require.Equal(t, exp.GossipVersion(), got.GossipVersion())
+		require.Equal(t, exp.BlockHeight, got.BlockHeight)
+		require.Equal(t, exp.DisableFlags, got.DisableFlags)
+		require.Equal(t, exp.SigBytes, got.SigBytes)
+		require.Equal(t, exp.InboundFee, got.InboundFee)
+	}
+
+	dbEdge, dbPol1, dbPol2, err := graph.FetchChannelEdgesByOutpoint(
+		&edge.ChannelPoint,
+	)
+	require.NoError(t, err)
+	require.Equal(t, edge, dbEdge)
+	assertV2Policy(pol1, dbPol1)
+	assertV2Policy(pol2, dbPol2)
+
+	// The channel should be included in the graph-wide queries.
+	chanID, err := graph.ChannelID(&edge.ChannelPoint)
+	require.NoError(t, err)
+	require.Equal(t, edge.ChannelID, chanID)
+
+	highest, err := graph.HighestChanID(ctx)
+	require.NoError(t, err)
+	require.Equal(t, edge.ChannelID, highest)
+
+	chanView, err := graph.ChannelView()
+	require.NoError(t, err)
+	require.Equal(t, []EdgePoint{{
+		FundingPkScript: fundingScript,
+		OutPoint:        edge.ChannelPoint,
+	}}, chanView)
+
+	scid := lnwire.NewShortChanIDFromInt(edge.ChannelID)
+	ranges, err := graph.FilterChannelRange(
+		scid.BlockHeight, scid.BlockHeight, false,
+	)
+	require.NoError(t, err)
+	require.Len(t, ranges, 1)
+	require.Len(t, ranges[0].Channels, 1)
+	require.Equal(t, scid, ranges[0].Channels[0].ShortChannelID)
+
+	var numChans int
+	err = graph.ForEachChannel(ctx, func(info *models.ChannelEdgeInfo,
+		p1, p2 *models.ChannelEdgePolicy) error {
+
+		numChans++
+		require.Equal(t, edge, info)
+		assertV2Policy(pol1, p1)
+		assertV2Policy(pol2, p2)
+
+		return nil
+	}, func() {
+		numChans = 0
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, numChans)
+
+	isPublic, err := graph.IsPublicNode(node1.PubKeyBytes)
+	require.NoError(t, err)
+	require.True(t, isPublic)
+}
+
 // TestForEachSourceNodeChannel tests that the ForEachSourceNodeChannel
 // correctly iterates through the channels of the set source node.
 func TestForEachSourceNodeChannel(t *testing.T) {
@@ -116,6 +116,12 @@ type V1Store interface { //nolint:interfacebloat
 	FetchNode(ctx context.Context, nodePub route.Vertex) (*models.Node,
 		error)
 
+	// FetchNodeV2 attempts to look up the v2 announcement of a target node
+	// by its identity public key. If we don't have a v2 announcement for
+	// the node, then ErrGraphNodeNotFound is returned.
+	FetchNodeV2(ctx context.Context, nodePub route.Vertex) (*models.Node,
+		error)
+
 	// HasNode determines if the graph has a vertex identified by
 	// the target node identity public key. If the node exists in the
 	// database, a timestamp of when the data for the node was lasted
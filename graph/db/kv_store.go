@@ -26,6 +26,7 @@ import (
 	"github.com/flokiorg/flnd/kvdb"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/tlv"
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/txscript"
 	"github.com/flokiorg/go-flokicoin/wire"
@@ -65,6 +66,15 @@ var (
 	// future UI layer to add an additional degree of confirmation.
 	aliasIndexBucket = []byte("alias")
 
+	// nodeV2Bucket is a sub-bucket that's nested within the main
+	// nodeBucket. It houses the latest v2 node announcement of the nodes
+	// that announced themselves using gossip v2. These are kept apart from
+	// the v1 announcements, so a node that announced itself using both
+	// protocols can be synced to peers speaking either of them.
+	//
+	// maps: pubKey -> nodeInfo
+	nodeV2Bucket = []byte("graph-node-v2")
+
 	// edgeBucket is a bucket which houses all of the edge or channel
 	// information within the channel graph. This bucket essentially acts
 	// as an adjacency list, which in conjunction with a range scan, can be
@@ -341,6 +351,10 @@ func initKVStore(db kvdb.Backend) error {
 		if err != nil {
 			return err
 		}
+		_, err = nodes.CreateBucketIfNotExists(nodeV2Bucket)
+		if err != nil {
+			return err
+		}
 
 		edges := tx.ReadWriteBucket(edgeBucket)
 		_, err = edges.CreateBucketIfNotExists(edgeIndexBucket)
@@ -1001,6 +1015,17 @@ func addLightningNode(tx kvdb.RwTx, node *models.Node) error {
 		return err
 	}
 
+	// The v2 announcement of a node is stored next to its v1 record,
+	// which is left untouched.
+	if node.GossipVersion() == lnwire.GossipVersion2 {
+		nodesV2, err := nodes.CreateBucketIfNotExists(nodeV2Bucket)
+		if err != nil {
+			return err
+		}
+
+		return putLightningNodeV2(nodesV2, node)
+	}
+
 	aliases, err := nodes.CreateBucketIfNotExists(aliasIndexBucket)
 	if err != nil {
 		return err
@@ -1097,6 +1122,13 @@ func (c *KVStore) deleteLightningNode(nodes kvdb.RwBucket,
 		return err
 	}
 
+	// Any v2 announcement of the node goes along with it.
+	if nodesV2 := nodes.NestedReadWriteBucket(nodeV2Bucket); nodesV2 != nil {
+		if err := nodesV2.Delete(compressedPubKey); err != nil {
+			return err
+		}
+	}
+
 	// Finally, we'll delete the index entry for the node within the
 	// nodeUpdateIndexBucket as this node is no longer active, so we don't
 	// need to track its last update.
@@ -3373,6 +3405,33 @@ func (c *KVStore) FetchNode(_ context.Context,
 	return c.fetchLightningNode(nil, nodePub)
 }
 
+// FetchNodeV2 attempts to look up the v2 announcement of a target node by its
+// identity public key. If we don't have a v2 announcement for the node, then
+// ErrGraphNodeNotFound is returned.
+func (c *KVStore) FetchNodeV2(_ context.Context,
+	nodePub route.Vertex) (*models.Node, error) {
+
+	var node *models.Node
+	err := kvdb.View(c.db, func(tx kvdb.RTx) error {
+		nodes := tx.ReadBucket(nodeBucket)
+		if nodes == nil {
+			return ErrGraphNotFound
+		}
+
+		var err error
+		node, err = fetchLightningNodeV2(nodes, nodePub[:])
+
+		return err
+	}, func() {
+		node = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
 // fetchLightningNode attempts to look up a target node by its identity public
 // key. If the node isn't found in the database, then ErrGraphNodeNotFound is
 // returned. An optional transaction may be provided. If none is provided, then
@@ -3947,6 +4006,21 @@ func (c *KVStore) IsPublicNode(pubKey [33]byte) (bool, error) {
 	return nodeIsPublic, nil
 }
 
+// edgeFundingPkScript returns the funding pk script of the given channel. For
+// v1 channels, this is derived from the funding keys while for v2 channels the
+// persisted funding script is used.
+func edgeFundingPkScript(edgeInfo *models.ChannelEdgeInfo) ([]byte, error) {
+	if edgeInfo.GossipVersion() == lnwire.GossipVersion1 {
+		return genMultiSigP2WSH(
+			edgeInfo.FlokicoinKey1Bytes[:],
+			edgeInfo.FlokicoinKey2Bytes[:],
+		)
+	}
+
+	return edgeInfo.FundingScript.UnwrapOrErr(fmt.Errorf("funding "+
+		"script of v2 channel %d not known", edgeInfo.ChannelID))
+}
+
 // genMultiSigP2WSH generates the p2wsh'd multisig script for 2 of 2 pubkeys.
 func genMultiSigP2WSH(aPub, bPub []byte) ([]byte, error) {
 	witnessScript, err := input.GenMultiSigScript(aPub, bPub)
@@ -4030,10 +4104,7 @@ func (c *KVStore) ChannelView() ([]EdgePoint, error) {
 					return err
 				}
 
-				pkScript, err := genMultiSigP2WSH(
-					edgeInfo.FlokicoinKey1Bytes[:],
-					edgeInfo.FlokicoinKey2Bytes[:],
-				)
+				pkScript, err := edgeFundingPkScript(edgeInfo)
 				if err != nil {
 					return err
 				}
@@ -4333,10 +4404,62 @@ func (c *nodeTraverserSession) FetchNodeFeatures(_ context.Context,
 func putLightningNode(nodeBucket, aliasBucket, updateIndex kvdb.RwBucket,
 	node *models.Node) error {
 
-	var (
-		scratch [16]byte
-		b       bytes.Buffer
-	)
+	var b bytes.Buffer
+	if err := serializeLightningNode(&b, node); err != nil {
+		return err
+	}
+
+	nodePub := node.PubKeyBytes[:]
+
+	// If we didn't get a node announcement for this node, there's no
+	// alias or update time to index.
+	if !node.HaveAnnouncement() {
+		return nodeBucket.Put(nodePub, b.Bytes())
+	}
+
+	// If the node has the update time set, index it, else index 0.
+	updateUnix := uint64(0)
+	if node.LastUpdate.Unix() > 0 {
+		updateUnix = uint64(node.LastUpdate.Unix())
+	}
+
+	err := aliasBucket.Put(nodePub, []byte(node.Alias.UnwrapOr("")))
+	if err != nil {
+		return err
+	}
+
+	// With the alias bucket updated, we'll now update the index that
+	// tracks the time series of node updates.
+	var indexKey [8 + 33]byte
+	byteOrder.PutUint64(indexKey[:8], updateUnix)
+	copy(indexKey[8:], nodePub)
+
+	// If there was already an old index entry for this node, then we'll
+	// delete the old one before we write the new entry.
+	if nodeBytes := nodeBucket.Get(nodePub); nodeBytes != nil {
+		// Extract out the old update time to we can reconstruct the
+		// prior index key to delete it from the index.
+		oldUpdateTime := nodeBytes[:8]
+
+		var oldIndexKey [8 + 33]byte
+		copy(oldIndexKey[:8], oldUpdateTime)
+		copy(oldIndexKey[8:], nodePub)
+
+		if err := updateIndex.Delete(oldIndexKey[:]); err != nil {
+			return err
+		}
+	}
+
+	if err := updateIndex.Put(indexKey[:], nil); err != nil {
+		return err
+	}
+
+	return nodeBucket.Put(nodePub, b.Bytes())
+}
+
+// serializeLightningNode writes the legacy encoding of the given node.
+func serializeLightningNode(w io.Writer, node *models.Node) error {
+	var scratch [16]byte
 
 	pub, err := node.PubKey()
 	if err != nil {
@@ -4351,11 +4474,11 @@ func putLightningNode(nodeBucket, aliasBucket, updateIndex kvdb.RwBucket,
 	}
 
 	byteOrder.PutUint64(scratch[:8], updateUnix)
-	if _, err := b.Write(scratch[:8]); err != nil {
+	if _, err := w.Write(scratch[:8]); err != nil {
 		return err
 	}
 
-	if _, err := b.Write(nodePub); err != nil {
+	if _, err := w.Write(nodePub); err != nil {
 		return err
 	}
 
@@ -4364,48 +4487,46 @@ func putLightningNode(nodeBucket, aliasBucket, updateIndex kvdb.RwBucket,
 	if !node.HaveAnnouncement() {
 		// Write HaveNodeAnnouncement=0.
 		byteOrder.PutUint16(scratch[:2], 0)
-		if _, err := b.Write(scratch[:2]); err != nil {
-			return err
-		}
+		_, err := w.Write(scratch[:2])
 
-		return nodeBucket.Put(nodePub, b.Bytes())
+		return err
 	}
 
 	// Write HaveNodeAnnouncement=1.
 	byteOrder.PutUint16(scratch[:2], 1)
-	if _, err := b.Write(scratch[:2]); err != nil {
+	if _, err := w.Write(scratch[:2]); err != nil {
 		return err
 	}
 
 	nodeColor := node.Color.UnwrapOr(color.RGBA{})
 
-	if err := binary.Write(&b, byteOrder, nodeColor.R); err != nil {
+	if err := binary.Write(w, byteOrder, nodeColor.R); err != nil {
 		return err
 	}
-	if err := binary.Write(&b, byteOrder, nodeColor.G); err != nil {
+	if err := binary.Write(w, byteOrder, nodeColor.G); err != nil {
 		return err
 	}
-	if err := binary.Write(&b, byteOrder, nodeColor.B); err != nil {
+	if err := binary.Write(w, byteOrder, nodeColor.B); err != nil {
 		return err
 	}
 
-	err = wire.WriteVarString(&b, 0, node.Alias.UnwrapOr(""))
+	err = wire.WriteVarString(w, 0, node.Alias.UnwrapOr(""))
 	if err != nil {
 		return err
 	}
 
-	if err := node.Features.Encode(&b); err != nil {
+	if err := node.Features.Encode(w); err != nil {
 		return err
 	}
 
 	numAddresses := uint16(len(node.Addresses))
 	byteOrder.PutUint16(scratch[:2], numAddresses)
-	if _, err := b.Write(scratch[:2]); err != nil {
+	if _, err := w.Write(scratch[:2]); err != nil {
 		return err
 	}
 
 	for _, address := range node.Addresses {
-		if err := SerializeAddr(&b, address); err != nil {
+		if err := SerializeAddr(w, address); err != nil {
 			return err
 		}
 	}
@@ -4416,7 +4537,7 @@ func putLightningNode(nodeBucket, aliasBucket, updateIndex kvdb.RwBucket,
 			sigLen)
 	}
 
-	err = wire.WriteVarBytes(&b, 0, node.AuthSigBytes)
+	err = wire.WriteVarBytes(w, 0, node.AuthSigBytes)
 	if err != nil {
 		return err
 	}
@@ -4424,43 +4545,79 @@ func putLightningNode(nodeBucket, aliasBucket, updateIndex kvdb.RwBucket,
 	if len(node.ExtraOpaqueData) > MaxAllowedExtraOpaqueBytes {
 		return ErrTooManyExtraOpaqueBytes(len(node.ExtraOpaqueData))
 	}
-	err = wire.WriteVarBytes(&b, 0, node.ExtraOpaqueData)
-	if err != nil {
+
+	return wire.WriteVarBytes(w, 0, node.ExtraOpaqueData)
+}
+
+// putLightningNodeV2 stores the v2 announcement of a node. The legacy node
+// encoding is followed by a TLV stream that holds the fields that only exist
+// for v2 nodes.
+func putLightningNodeV2(nodesV2 kvdb.RwBucket, node *models.Node) error {
+	if !node.HaveAnnouncement() {
+		return fmt.Errorf("v2 node %x has no announcement",
+			node.PubKeyBytes)
+	}
+
+	var b bytes.Buffer
+	if err := serializeLightningNode(&b, node); err != nil {
 		return err
 	}
 
-	err = aliasBucket.Put(nodePub, []byte(node.Alias.UnwrapOr("")))
+	version := uint8(node.GossipVersion())
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(nodeV2VersionType, &version),
+		tlv.MakePrimitiveRecord(
+			nodeV2BlockHeightType, &node.BlockHeight,
+		),
+	)
 	if err != nil {
 		return err
 	}
+	if err := stream.Encode(&b); err != nil {
+		return err
+	}
 
-	// With the alias bucket updated, we'll now update the index that
-	// tracks the time series of node updates.
-	var indexKey [8 + 33]byte
-	byteOrder.PutUint64(indexKey[:8], updateUnix)
-	copy(indexKey[8:], nodePub)
+	return nodesV2.Put(node.PubKeyBytes[:], b.Bytes())
+}
 
-	// If there was already an old index entry for this node, then we'll
-	// delete the old one before we write the new entry.
-	if nodeBytes := nodeBucket.Get(nodePub); nodeBytes != nil {
-		// Extract out the old update time to we can reconstruct the
-		// prior index key to delete it from the index.
-		oldUpdateTime := nodeBytes[:8]
+// fetchLightningNodeV2 fetches the v2 announcement of the given node. If we
+// don't have one, ErrGraphNodeNotFound is returned.
+func fetchLightningNodeV2(nodeBucket kvdb.RBucket,
+	nodePub []byte) (*models.Node, error) {
 
-		var oldIndexKey [8 + 33]byte
-		copy(oldIndexKey[:8], oldUpdateTime)
-		copy(oldIndexKey[8:], nodePub)
+	nodesV2 := nodeBucket.NestedReadBucket(nodeV2Bucket)
+	if nodesV2 == nil {
+		return nil, ErrGraphNodeNotFound
+	}
 
-		if err := updateIndex.Delete(oldIndexKey[:]); err != nil {
-			return err
-		}
+	nodeBytes := nodesV2.Get(nodePub)
+	if nodeBytes == nil {
+		return nil, ErrGraphNodeNotFound
 	}
 
-	if err := updateIndex.Put(indexKey[:], nil); err != nil {
-		return err
+	r := bytes.NewReader(nodeBytes)
+	node, err := deserializeLightningNode(r)
+	if err != nil {
+		return nil, err
 	}
 
-	return nodeBucket.Put(nodePub, b.Bytes())
+	var version uint8
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(nodeV2VersionType, &version),
+		tlv.MakePrimitiveRecord(
+			nodeV2BlockHeightType, &node.BlockHeight,
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Decode(r); err != nil {
+		return nil, err
+	}
+
+	node.Version = lnwire.GossipVersion(version)
+
+	return node, nil
 }
 
 func fetchLightningNode(nodeBucket kvdb.RBucket,
@@ -4627,6 +4784,53 @@ func deserializeLightningNode(r io.Reader) (*models.Node, error) {
 	return node, nil
 }
 
+const (
+	// nodeV2VersionType is the TLV type of the gossip version in the
+	// trailing TLV stream of a serialized v2 node.
+	nodeV2VersionType tlv.Type = 0
+
+	// nodeV2BlockHeightType is the TLV type of the block height of a v2
+	// node announcement.
+	nodeV2BlockHeightType tlv.Type = 2
+
+	// edgeInfoVersionType is the TLV type of the gossip version in the
+	// trailing TLV stream of a serialized v2 edge info.
+	edgeInfoVersionType tlv.Type = 0
+
+	// edgeInfoMerkleRootType is the TLV type of the tapscript root of the
+	// funding output of a v2 channel.
+	edgeInfoMerkleRootType tlv.Type = 2
+
+	// edgeInfoSignatureType is the TLV type of the Schnorr signature of a
+	// v2 channel announcement.
+	edgeInfoSignatureType tlv.Type = 4
+
+	// edgeInfoFundingScriptType is the TLV type of the funding script of a
+	// v2 channel. Unlike v1 channels, it can't always be derived from the
+	// announced keys.
+	edgeInfoFundingScriptType tlv.Type = 6
+
+	// edgePolicyVersionType is the TLV type of the gossip version in the
+	// trailing TLV stream of a serialized v2 edge policy.
+	edgePolicyVersionType tlv.Type = 0
+
+	// edgePolicyBlockHeightType is the TLV type of the block height of a
+	// v2 edge policy.
+	edgePolicyBlockHeightType tlv.Type = 2
+
+	// edgePolicyDisableFlagsType is the TLV type of the disable flags of a
+	// v2 edge policy.
+	edgePolicyDisableFlagsType tlv.Type = 4
+
+	// edgePolicyInboundBaseType is the TLV type of the inbound base fee of
+	// a v2 edge policy.
+	edgePolicyInboundBaseType tlv.Type = 6
+
+	// edgePolicyInboundRateType is the TLV type of the inbound fee rate of
+	// a v2 edge policy.
+	edgePolicyInboundRateType tlv.Type = 8
+)
+
 func putChanEdgeInfo(edgeIndex kvdb.RwBucket,
 	edgeInfo *models.ChannelEdgeInfo, chanID [8]byte) error {
 
@@ -4698,9 +4902,107 @@ func putChanEdgeInfo(edgeIndex kvdb.RwBucket,
 		return err
 	}
 
+	// The fields that only exist for v2 channels are appended as a TLV
+	// stream so that the encoding of v1 channels is left untouched.
+	if edgeInfo.GossipVersion() != lnwire.GossipVersion1 {
+		err := serializeChanEdgeInfoV2Fields(&b, edgeInfo)
+		if err != nil {
+			return err
+		}
+	}
+
 	return edgeIndex.Put(chanID[:], b.Bytes())
 }
 
+// serializeChanEdgeInfoV2Fields writes the trailing TLV stream that holds the
+// fields of a v2 channel that don't have a place in the legacy encoding.
+func serializeChanEdgeInfoV2Fields(w io.Writer,
+	edgeInfo *models.ChannelEdgeInfo) error {
+
+	version := uint8(edgeInfo.GossipVersion())
+	records := []tlv.Record{
+		tlv.MakePrimitiveRecord(edgeInfoVersionType, &version),
+	}
+
+	var merkleRoot [32]byte
+	edgeInfo.MerkleRootHash.WhenSome(func(root chainhash.Hash) {
+		merkleRoot = root
+		records = append(records, tlv.MakePrimitiveRecord(
+			edgeInfoMerkleRootType, &merkleRoot,
+		))
+	})
+
+	var sig []byte
+	if edgeInfo.AuthProof != nil && len(edgeInfo.AuthProof.Signature) > 0 {
+		sig = edgeInfo.AuthProof.Signature
+		records = append(records, tlv.MakePrimitiveRecord(
+			edgeInfoSignatureType, &sig,
+		))
+	}
+
+	var fundingScript []byte
+	edgeInfo.FundingScript.WhenSome(func(script []byte) {
+		fundingScript = script
+		records = append(records, tlv.MakePrimitiveRecord(
+			edgeInfoFundingScriptType, &fundingScript,
+		))
+	})
+
+	stream, err := tlv.NewStream(records...)
+	if err != nil {
+		return err
+	}
+
+	return stream.Encode(w)
+}
+
+// deserializeChanEdgeInfoV2Fields reads the optional trailing TLV stream
+// written by serializeChanEdgeInfoV2Fields. If the stream is absent, the edge
+// is left as a v1 edge with a zero version.
+func deserializeChanEdgeInfoV2Fields(r io.Reader,
+	edgeInfo *models.ChannelEdgeInfo) error {
+
+	var (
+		version       uint8
+		merkleRoot    [32]byte
+		sig           []byte
+		fundingScript []byte
+	)
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(edgeInfoVersionType, &version),
+		tlv.MakePrimitiveRecord(edgeInfoMerkleRootType, &merkleRoot),
+		tlv.MakePrimitiveRecord(edgeInfoSignatureType, &sig),
+		tlv.MakePrimitiveRecord(
+			edgeInfoFundingScriptType, &fundingScript,
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	typeMap, err := stream.DecodeWithParsedTypes(r)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := typeMap[edgeInfoVersionType]; !ok {
+		return nil
+	}
+
+	edgeInfo.Version = lnwire.GossipVersion(version)
+	if _, ok := typeMap[edgeInfoMerkleRootType]; ok {
+		edgeInfo.MerkleRootHash = fn.Some(chainhash.Hash(merkleRoot))
+	}
+	if _, ok := typeMap[edgeInfoSignatureType]; ok {
+		edgeInfo.AuthProof = models.NewV2ChannelAuthProof(sig)
+	}
+	if _, ok := typeMap[edgeInfoFundingScriptType]; ok {
+		edgeInfo.FundingScript = fn.Some(fundingScript)
+	}
+
+	return nil
+}
+
 func fetchChanEdgeInfo(edgeIndex kvdb.RBucket,
 	chanID []byte) (*models.ChannelEdgeInfo, error) {
 
@@ -4865,6 +5167,10 @@ func deserializeChanEdgeInfo(r io.Reader) (*models.ChannelEdgeInfo, error) {
 		return nil, err
 	}
 
+	if err := deserializeChanEdgeInfoV2Fields(r, &edgeInfo); err != nil {
+		return nil, err
+	}
+
 	return &edgeInfo, nil
 }
 
@@ -5138,6 +5444,96 @@ func serializeChanEdgePolicy(w io.Writer, edge *models.ChannelEdgePolicy,
 		return err
 	}
 
+	// As with the edge info, the fields that only exist for v2 policies
+	// are appended as a TLV stream.
+	if edge.GossipVersion() == lnwire.GossipVersion1 {
+		return nil
+	}
+
+	version := uint8(edge.GossipVersion())
+	disableFlags := uint8(edge.DisableFlags)
+	records := []tlv.Record{
+		tlv.MakePrimitiveRecord(edgePolicyVersionType, &version),
+		tlv.MakePrimitiveRecord(
+			edgePolicyBlockHeightType, &edge.BlockHeight,
+		),
+		tlv.MakePrimitiveRecord(
+			edgePolicyDisableFlagsType, &disableFlags,
+		),
+	}
+
+	// Unlike v1 policies, the inbound fee of a v2 policy is a known field
+	// of the update rather than part of the opaque data, so it is stored
+	// explicitly.
+	var inboundBase, inboundRate uint32
+	edge.InboundFee.WhenSome(func(fee lnwire.Fee) {
+		inboundBase = uint32(fee.BaseFee)
+		inboundRate = uint32(fee.FeeRate)
+		records = append(records,
+			tlv.MakePrimitiveRecord(
+				edgePolicyInboundBaseType, &inboundBase,
+			),
+			tlv.MakePrimitiveRecord(
+				edgePolicyInboundRateType, &inboundRate,
+			),
+		)
+	})
+
+	stream, err := tlv.NewStream(records...)
+	if err != nil {
+		return err
+	}
+
+	return stream.Encode(w)
+}
+
+// deserializeChanEdgePolicyV2Fields reads the optional trailing TLV stream of
+// a v2 policy. If the stream is absent, the policy is left as a v1 policy with
+// a zero version.
+func deserializeChanEdgePolicyV2Fields(r io.Reader,
+	edge *models.ChannelEdgePolicy) error {
+
+	var (
+		version, disableFlags    uint8
+		inboundBase, inboundRate uint32
+	)
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(edgePolicyVersionType, &version),
+		tlv.MakePrimitiveRecord(
+			edgePolicyBlockHeightType, &edge.BlockHeight,
+		),
+		tlv.MakePrimitiveRecord(
+			edgePolicyDisableFlagsType, &disableFlags,
+		),
+		tlv.MakePrimitiveRecord(
+			edgePolicyInboundBaseType, &inboundBase,
+		),
+		tlv.MakePrimitiveRecord(
+			edgePolicyInboundRateType, &inboundRate,
+		),
+	)
+	if err != nil {
+		return err
+	}
+
+	typeMap, err := stream.DecodeWithParsedTypes(r)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := typeMap[edgePolicyVersionType]; !ok {
+		return nil
+	}
+
+	edge.Version = lnwire.GossipVersion(version)
+	edge.DisableFlags = lnwire.ChanUpdateDisableFlags(disableFlags)
+	if _, ok := typeMap[edgePolicyInboundBaseType]; ok {
+		edge.InboundFee = fn.Some(lnwire.Fee{
+			BaseFee: int32(inboundBase),
+			FeeRate: int32(inboundRate),
+		})
+	}
+
 	return nil
 }
 
@@ -5221,6 +5617,10 @@ func deserializeChanEdgePolicyRaw(r io.Reader) (*models.ChannelEdgePolicy,
 		return nil, err
 	}
 
+	if err := deserializeChanEdgePolicyV2Fields(r, edge); err != nil {
+		return nil, err
+	}
+
 	// See if optional fields are present.
 	if edge.MessageFlags.HasMaxHtlc() {
 		// The max_htlc field should be at the beginning of the opaque
@@ -0,0 +1,97 @@
+package graphdb
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+)
+
+// LeaseSeller is a node that sells inbound liquidity at the lease rates it
+// advertised in its node announcement.
+type LeaseSeller struct {
+	// Node is the identity of the seller.
+	Node route.Vertex
+
+	// Rates are the lease rates the seller advertised.
+	Rates lnwire.LeaseRates
+
+	// Fee is the fee the seller charges for the lease that was queried.
+	Fee chainutil.Amount
+}
+
+// leaseIndex indexes the lease rates nodes advertise, so the sellers of
+// inbound liquidity can be listed without reading every node from the
+// database.
+type leaseIndex struct {
+	rates map[route.Vertex]lnwire.LeaseRates
+	mtx   sync.RWMutex
+}
+
+// newLeaseIndex creates a new empty lease index.
+func newLeaseIndex() *leaseIndex {
+	return &leaseIndex{
+		rates: make(map[route.Vertex]lnwire.LeaseRates),
+	}
+}
+
+// addNode indexes the lease rates of the passed node, replacing any rates it
+// advertised before. Nodes that no longer advertise rates are removed.
+func (l *leaseIndex) addNode(node *models.Node) {
+	rates, err := node.LeaseRates()
+	if err != nil {
+		log.Debugf("Unable to parse lease rates of node %x: %v",
+			node.PubKeyBytes, err)
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	rates.WhenSome(func(rates lnwire.LeaseRates) {
+		l.rates[node.PubKeyBytes] = rates
+	})
+	if rates.IsNone() {
+		delete(l.rates, node.PubKeyBytes)
+	}
+}
+
+// removeNode removes the lease rates of the passed node from the index.
+func (l *leaseIndex) removeNode(node route.Vertex) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	delete(l.rates, node)
+}
+
+// cheapestSellers returns up to numSellers nodes that charge the lowest fee
+// for leasing amt at the given fee rate of the funding transaction, cheapest
+// first.
+func (l *leaseIndex) cheapestSellers(amt chainutil.Amount,
+	feeRate chainfee.SatPerKWeight, numSellers int) []LeaseSeller {
+
+	l.mtx.RLock()
+	sellers := make([]LeaseSeller, 0, len(l.rates))
+	for node, rates := range l.rates {
+		fee := rates.LeaseFee(uint64(amt), uint32(feeRate))
+		sellers = append(sellers, LeaseSeller{
+			Node:  node,
+			Rates: rates,
+			Fee:   chainutil.Amount(fee),
+		})
+	}
+	l.mtx.RUnlock()
+
+	sort.Slice(sellers, func(i, j int) bool {
+		return sellers[i].Fee < sellers[j].Fee
+	})
+
+	if len(sellers) > numSellers {
+		sellers = sellers[:numSellers]
+	}
+
+	return sellers
+}
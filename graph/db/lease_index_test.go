@@ -0,0 +1,97 @@
+package graphdb
+
+import (
+	"testing"
+
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheapestLeaseSellers asserts that the lease rates advertised by nodes
+// are indexed as node announcements arrive and are removed with the nodes.
+func TestCheapestLeaseSellers(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	graph := MakeTestGraph(t)
+
+	// withRates returns a new announcement of the node advertising the
+	// passed lease rates.
+	withRates := func(node *models.Node,
+		rates lnwire.LeaseRates) *models.Node {
+
+		var extraData lnwire.ExtraOpaqueData
+		require.NoError(t, lnwire.SetLeaseRates(&extraData, rates))
+
+		node.LastUpdate = nextUpdateTime()
+		node.ExtraOpaqueData = extraData
+
+		return node
+	}
+
+	// The proportional fee of the first seller is lower, the base fee of
+	// the second one.
+	cheapProportional := withRates(createTestVertex(t), lnwire.LeaseRates{
+		LeaseFeeBasis: 10,
+		LeaseFeeBase:  2_000,
+	})
+	cheapBase := withRates(createTestVertex(t), lnwire.LeaseRates{
+		LeaseFeeBasis: 50,
+		LeaseFeeBase:  100,
+	})
+	noSeller := createTestVertex(t)
+	for _, node := range []*models.Node{
+		cheapProportional, cheapBase, noSeller,
+	} {
+		require.NoError(t, graph.AddNode(ctx, node))
+	}
+
+	sellerNodes := func(sellers []LeaseSeller) []route.Vertex {
+		nodes := make([]route.Vertex, 0, len(sellers))
+		for _, seller := range sellers {
+			nodes = append(nodes, seller.Node)
+		}
+
+		return nodes
+	}
+
+	// For small leases the base fee dominates, for large ones the
+	// proportional fee.
+	sellers := graph.CheapestLeaseSellers(100_000, 0, 10)
+	require.Equal(t, []route.Vertex{
+		cheapBase.PubKeyBytes, cheapProportional.PubKeyBytes,
+	}, sellerNodes(sellers))
+	require.EqualValues(t, 600, sellers[0].Fee)
+
+	sellers = graph.CheapestLeaseSellers(10_000_000, 0, 1)
+	require.Equal(t, []route.Vertex{
+		cheapProportional.PubKeyBytes,
+	}, sellerNodes(sellers))
+
+	// The index is populated from the database when the graph starts.
+	restarted, err := NewChannelGraph(graph.V1Store)
+	require.NoError(t, err)
+	require.NoError(t, restarted.Start())
+	t.Cleanup(func() {
+		require.NoError(t, restarted.Stop())
+	})
+	require.Equal(
+		t, sellers, restarted.CheapestLeaseSellers(10_000_000, 0, 1),
+	)
+
+	// A node that stops advertising rates is no longer a seller, and
+	// neither is one that was deleted.
+	cheapProportional.LastUpdate = nextUpdateTime()
+	cheapProportional.ExtraOpaqueData = nil
+	require.NoError(t, graph.AddNode(ctx, cheapProportional))
+
+	sellers = graph.CheapestLeaseSellers(10_000_000, 0, 10)
+	require.Equal(t, []route.Vertex{
+		cheapBase.PubKeyBytes,
+	}, sellerNodes(sellers))
+
+	require.NoError(t, graph.DeleteNode(ctx, cheapBase.PubKeyBytes))
+	require.Empty(t, graph.CheapestLeaseSellers(10_000_000, 0, 10))
+}
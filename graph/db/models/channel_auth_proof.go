@@ -1,5 +1,7 @@
 package models
 
+import "github.com/flokiorg/flnd/lnwire"
+
 // ChannelAuthProof is the authentication proof (the signature portion) for a
 // channel. Using the four signatures contained in the struct, and some
 // auxiliary knowledge (the funding script, node identities, and outpoint) nodes
@@ -7,7 +9,15 @@ package models
 // channel. Each of these signatures signs the following digest: chanID ||
 // nodeID1 || nodeID2 || bitcoinKey1|| bitcoinKey2 || 2-byte-feature-len ||
 // features.
+//
+// For v2 channels, the proof is instead a single Schnorr signature, produced
+// with MuSig2 by all the parties of the channel, over the tagged hash of the
+// signed range of the channel_announcement_2 message.
 type ChannelAuthProof struct {
+	// Version is the gossip version of the announcement that this proof
+	// belongs to. The zero value is interpreted as GossipVersion1.
+	Version lnwire.GossipVersion
+
 	// NodeSig1Bytes are the raw bytes of the first node signature encoded
 	// in DER format.
 	NodeSig1Bytes []byte
@@ -23,11 +33,38 @@ type ChannelAuthProof struct {
 	// FlokicoinSig2Bytes are the raw bytes of the second bitcoin signature
 	// encoded in DER format.
 	FlokicoinSig2Bytes []byte
+
+	// Signature is the raw 64-byte Schnorr signature of a v2 channel
+	// announcement. It is only set for v2 proofs.
+	Signature []byte
+}
+
+// NewV2ChannelAuthProof creates a new v2 ChannelAuthProof from the given
+// Schnorr signature.
+func NewV2ChannelAuthProof(sig []byte) *ChannelAuthProof {
+	return &ChannelAuthProof{
+		Version:   lnwire.GossipVersion2,
+		Signature: sig,
+	}
+}
+
+// GossipVersion returns the gossip version of the announcement that this
+// proof belongs to.
+func (c *ChannelAuthProof) GossipVersion() lnwire.GossipVersion {
+	if c.Version == 0 {
+		return lnwire.GossipVersion1
+	}
+
+	return c.Version
 }
 
 // IsEmpty check is the authentication proof is empty Proof is empty if at
 // least one of the signatures are equal to nil.
 func (c *ChannelAuthProof) IsEmpty() bool {
+	if c.GossipVersion() == lnwire.GossipVersion2 {
+		return len(c.Signature) == 0
+	}
+
 	return len(c.NodeSig1Bytes) == 0 ||
 		len(c.NodeSig2Bytes) == 0 ||
 		len(c.FlokicoinSig1Bytes) == 0 ||
@@ -19,6 +19,12 @@ import (
 // policy of a channel are stored within a ChannelEdgePolicy for each direction
 // of the channel.
 type ChannelEdgeInfo struct {
+	// Version is the gossip version that this channel was announced on.
+	// The zero value is interpreted as GossipVersion1 so that edges built
+	// before the version was tracked keep their original meaning. Use
+	// GossipVersion to read it.
+	Version lnwire.GossipVersion
+
 	// ChannelID is the unique channel ID for the channel. The first 3
 	// bytes are the block height, the next 3 the index within the block,
 	// and the last 2 bytes are the output index for the channel.
@@ -35,11 +41,22 @@ type ChannelEdgeInfo struct {
 	NodeKey2Bytes [33]byte
 
 	// FlokicoinKey1Bytes is the raw public key of the first node.
+	//
+	// NOTE: for v2 channels, this is optional and will be all zeros if the
+	// announcement did not carry the funding keys.
 	FlokicoinKey1Bytes [33]byte
 
 	// FlokicoinKey2Bytes is the raw public key of the first node.
+	//
+	// NOTE: for v2 channels, this is optional and will be all zeros if the
+	// announcement did not carry the funding keys.
 	FlokicoinKey2Bytes [33]byte
 
+	// MerkleRootHash is the optional tapscript root that the funding
+	// output of a v2 channel commits to. It is always None for v1
+	// channels.
+	MerkleRootHash fn.Option[chainhash.Hash]
+
 	// Features is the list of protocol features supported by this channel
 	// edge.
 	Features *lnwire.FeatureVector
@@ -54,13 +71,15 @@ type ChannelEdgeInfo struct {
 	ChannelPoint wire.OutPoint
 
 	// Capacity is the total capacity of the channel, this is determined by
-	// the value output in the outpoint that created this channel.
+	// the value output in the outpoint that created this channel. For v2
+	// channels, this is the capacity carried in the announcement.
 	Capacity chainutil.Amount
 
 	// FundingScript holds the script of the channel's funding transaction.
 	//
-	// NOTE: this is not currently persisted and so will not be present if
-	// the edge object is loaded from the database.
+	// NOTE: this is only persisted for v2 channels, for which it can't
+	// always be derived from the announced keys. For v1 channels it will
+	// not be present if the edge object is loaded from the database.
 	FundingScript fn.Option[[]byte]
 
 	// ExtraOpaqueData is the set of data that was appended to this
@@ -72,6 +91,25 @@ type ChannelEdgeInfo struct {
 	ExtraOpaqueData []byte
 }
 
+// GossipVersion returns the gossip version that the channel was announced
+// on.
+func (c *ChannelEdgeInfo) GossipVersion() lnwire.GossipVersion {
+	if c.Version == 0 {
+		return lnwire.GossipVersion1
+	}
+
+	return c.Version
+}
+
+// HasFlokicoinKeys returns true if the edge carries the funding keys of both
+// nodes. This is always the case for v1 channels, but is optional for v2
+// channels.
+func (c *ChannelEdgeInfo) HasFlokicoinKeys() bool {
+	var zero [33]byte
+
+	return c.FlokicoinKey1Bytes != zero && c.FlokicoinKey2Bytes != zero
+}
+
 // NodeKey1 is the identity public key of the "first" node that was involved in
 // the creation of this channel. A node is considered "first" if the
 // lexicographical ordering the its serialized public key is "smaller" than
@@ -15,6 +15,10 @@ import (
 // information concerning fees, and minimum time-lock information which is
 // utilized during path finding.
 type ChannelEdgePolicy struct {
+	// Version is the gossip version of the update that this policy was
+	// built from. The zero value is interpreted as GossipVersion1.
+	Version lnwire.GossipVersion
+
 	// SigBytes is the raw bytes of the signature of the channel edge
 	// policy. We'll only parse these if the caller needs to access the
 	// signature for validation purposes. Do not set SigBytes directly, but
@@ -30,9 +34,19 @@ type ChannelEdgePolicy struct {
 	ChannelID uint64
 
 	// LastUpdate is the last time an authenticated edge for this channel
-	// was received.
+	// was received. For v1 policies this is the timestamp carried in the
+	// update, v2 updates carry no timestamp so it is instead the time at
+	// which the update was accepted.
 	LastUpdate time.Time
 
+	// BlockHeight is the block height carried in a v2 update. It is used
+	// in place of the timestamp to order the updates of v2 channels.
+	BlockHeight uint32
+
+	// DisableFlags is the set of disable flags carried in a v2 update.
+	// The disabled bit of ChannelFlags mirrors whether any of them is set.
+	DisableFlags lnwire.ChanUpdateDisableFlags
+
 	// MessageFlags is a bitfield which indicates the presence of optional
 	// fields (like max_htlc) in the policy.
 	MessageFlags lnwire.ChanUpdateMsgFlags
@@ -87,6 +101,9 @@ type ChannelEdgePolicy struct {
 // Signature is a channel announcement signature, which is needed for proper
 // edge policy announcement.
 //
+// NOTE: this is only valid for v1 policies, v2 policies carry a Schnorr
+// signature in SigBytes.
+//
 // NOTE: By having this method to access an attribute, we ensure we only need
 // to fully deserialize the signature if absolutely necessary.
 func (c *ChannelEdgePolicy) Signature() (*ecdsa.Signature, error) {
@@ -111,6 +128,16 @@ func (c *ChannelEdgePolicy) SetSigBytes(sig []byte) {
 	c.sig = nil
 }
 
+// GossipVersion returns the gossip version of the update that this policy
+// was built from.
+func (c *ChannelEdgePolicy) GossipVersion() lnwire.GossipVersion {
+	if c.Version == 0 {
+		return lnwire.GossipVersion1
+	}
+
+	return c.Version
+}
+
 // IsDisabled determines whether the edge has the disabled bit set.
 func (c *ChannelEdgePolicy) IsDisabled() bool {
 	return c.ChannelFlags.IsDisabled()
@@ -9,6 +9,8 @@ import (
 	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/flnd/tor"
 	"github.com/flokiorg/go-flokicoin/crypto"
 )
 
@@ -24,9 +26,15 @@ type Node struct {
 	PubKeyBytes [33]byte
 
 	// LastUpdate is the last time the vertex information for this node has
-	// been updated.
+	// been updated. As v2 node announcements carry no timestamp, this is
+	// the time at which we accepted the announcement for v2 nodes.
 	LastUpdate time.Time
 
+	// BlockHeight is the block height that a v2 node announcement was
+	// signed at. It orders the announcements of a v2 node and is unused
+	// for v1 nodes.
+	BlockHeight uint32
+
 	// Address is the TCP address this node is reachable over.
 	Addresses []net.Addr
 
@@ -49,7 +57,8 @@ type Node struct {
 	// parse. By holding onto this data, we ensure that we're able to
 	// properly validate the set of signatures that cover these new fields,
 	// and ensure we're able to make upgrades to the network in a forwards
-	// compatible manner.
+	// compatible manner. For v2 nodes, this holds the TLV encoding of the
+	// unknown signed fields of the announcement.
 	ExtraOpaqueData []byte
 }
 
@@ -103,6 +112,67 @@ func NewV1Node(pub route.Vertex, n *NodeV1Fields) *Node {
 	}
 }
 
+// NodeV2Fields houses the fields that are specific to a version 2 node
+// announcement.
+type NodeV2Fields struct {
+	// BlockHeight is the block height that the announcement was signed
+	// at.
+	BlockHeight uint32
+
+	// Addresses is the list of addresses this node is reachable over.
+	Addresses []net.Addr
+
+	// Signature is the raw Schnorr signature under the advertised public
+	// key which serves to authenticate the attributes announced by this
+	// node.
+	Signature []byte
+
+	// Features is the list of protocol features supported by this node.
+	Features *lnwire.RawFeatureVector
+
+	// Color is the optional color of the node.
+	Color fn.Option[color.RGBA]
+
+	// Alias is the optional nick-name of the node.
+	Alias fn.Option[string]
+
+	// LastUpdate is the time at which the announcement was accepted.
+	LastUpdate time.Time
+
+	// ExtraSignedFields is the set of signed TLV fields of the
+	// announcement that we don't know about.
+	ExtraSignedFields lnwire.ExtraSignedFields
+}
+
+// NewV2Node creates a new version 2 node from the passed fields.
+func NewV2Node(pub route.Vertex, n *NodeV2Fields) (*Node, error) {
+	extra, err := n.ExtraSignedFields.ToOpaqueData()
+	if err != nil {
+		return nil, err
+	}
+
+	// Without any extra fields, the data is nil as it is when read back
+	// from the graph stores.
+	if len(extra) == 0 {
+		extra = nil
+	}
+
+	return &Node{
+		Version:      lnwire.GossipVersion2,
+		PubKeyBytes:  pub,
+		BlockHeight:  n.BlockHeight,
+		Addresses:    n.Addresses,
+		AuthSigBytes: n.Signature,
+		Features: lnwire.NewFeatureVector(
+			n.Features, lnwire.Features,
+		),
+		Color:           n.Color,
+		Alias:           n.Alias,
+		LastUpdate:      n.LastUpdate,
+		ExtraOpaqueData: extra,
+	}, nil
+}
+
 // NewV1ShellNode creates a new shell version 1 node.
 func NewV1ShellNode(pubKey route.Vertex) *Node {
 	return NewShellNode(lnwire.GossipVersion1, pubKey)
@@ -126,12 +196,34 @@ func (n *Node) HaveAnnouncement() bool {
 	return len(n.AuthSigBytes) > 0
 }
 
+// LeaseRates returns the rates the node sells inbound liquidity at, if it
+// advertised any in its node announcement.
+func (n *Node) LeaseRates() (fn.Option[lnwire.LeaseRates], error) {
+	return lnwire.ExtractLeaseRates(n.ExtraOpaqueData)
+}
+
+// TrampolinePolicy returns the policy the node relays trampoline payments by,
+// if it advertised one in its node announcement.
+func (n *Node) TrampolinePolicy() (fn.Option[lnwire.TrampolinePolicy], error) {
+	return lnwire.ExtractTrampolinePolicy(n.ExtraOpaqueData)
+}
+
 // PubKey is the node's long-term identity public key. This key will be used to
 // authenticated any advertisements/updates sent by the node.
 func (n *Node) PubKey() (*crypto.PublicKey, error) {
 	return crypto.ParsePubKey(n.PubKeyBytes[:])
 }
 
+// GossipVersion returns the gossip version that the node was advertised on.
+// Nodes stored before versions were tracked are v1 nodes.
+func (n *Node) GossipVersion() lnwire.GossipVersion {
+	if n.Version == 0 {
+		return lnwire.GossipVersion1
+	}
+
+	return n.Version
+}
+
 // NodeAnnouncement retrieves the latest node announcement of the node.
 func (n *Node) NodeAnnouncement(signed bool) (*lnwire.NodeAnnouncement1,
 	error) {
@@ -194,3 +286,157 @@ func NodeFromWireAnnouncement(msg *lnwire.NodeAnnouncement1) *Node {
 		},
 	)
 }
+
+// NodeAnnouncement2 retrieves the latest v2 node announcement of the node.
+func (n *Node) NodeAnnouncement2(signed bool) (*lnwire.NodeAnnouncement2,
+	error) {
+
+	if n.GossipVersion() != lnwire.GossipVersion2 {
+		return nil, fmt.Errorf("node %x is not a v2 node",
+			n.PubKeyBytes)
+	}
+
+	// Error out if we request the signed announcement, but we don't have
+	// a signature for this announcement.
+	if !n.HaveAnnouncement() && signed {
+		return nil, fmt.Errorf("node does not have node announcement")
+	}
+
+	extraFields, err := lnwire.ExtraSignedFieldsFromOpaqueData(
+		n.ExtraOpaqueData,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeAnn := &lnwire.NodeAnnouncement2{
+		ExtraSignedFields: extraFields,
+	}
+	nodeAnn.Features.Val = *n.Features.RawFeatureVector
+	nodeAnn.BlockHeight.Val = n.BlockHeight
+	nodeAnn.NodeID.Val = n.PubKeyBytes
+
+	n.Color.WhenSome(func(c color.RGBA) {
+		nodeAnn.Color = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType1](lnwire.Color(c)),
+		)
+	})
+	n.Alias.WhenSome(func(alias string) {
+		nodeAnn.Alias = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType3](lnwire.NodeAlias2(alias)),
+		)
+	})
+
+	var (
+		ipv4Addrs  lnwire.IPV4Addrs
+		ipv6Addrs  lnwire.IPV6Addrs
+		torV3Addrs lnwire.TorV3Addrs
+	)
+	for _, addr := range n.Addresses {
+		switch addr := addr.(type) {
+		case *net.TCPAddr:
+			if addr.IP.To4() != nil {
+				ipv4Addrs = append(ipv4Addrs, addr)
+			} else {
+				ipv6Addrs = append(ipv6Addrs, addr)
+			}
+
+		case *tor.OnionAddr:
+			if len(addr.OnionService) != tor.V3Len {
+				return nil, fmt.Errorf("unsupported onion "+
+					"address for v2 node: %v", addr)
+			}
+			torV3Addrs = append(torV3Addrs, addr)
+
+		case *lnwire.DNSAddress:
+			if nodeAnn.DNSHostName.IsSome() {
+				return nil, fmt.Errorf("v2 node %x has more "+
+					"than one DNS address", n.PubKeyBytes)
+			}
+			nodeAnn.DNSHostName = tlv.SomeRecordT(
+				tlv.NewRecordT[tlv.TlvType11](*addr),
+			)
+
+		default:
+			return nil, fmt.Errorf("unsupported address for v2 "+
+				"node: %v", addr)
+		}
+	}
+	if len(ipv4Addrs) > 0 {
+		nodeAnn.IPV4Addrs = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType5](ipv4Addrs),
+		)
+	}
+	if len(ipv6Addrs) > 0 {
+		nodeAnn.IPV6Addrs = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType7](ipv6Addrs),
+		)
+	}
+	if len(torV3Addrs) > 0 {
+		nodeAnn.TorV3Addrs = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType9](torV3Addrs),
+		)
+	}
+
+	if !signed {
+		return nodeAnn, nil
+	}
+
+	nodeAnn.Signature.Val, err = lnwire.NewSigFromSchnorrRawSignature(
+		n.AuthSigBytes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeAnn, nil
+}
+
+// NodeFromWireAnnouncement2 creates a Node instance from an
+// lnwire.NodeAnnouncement2 message. As the announcement carries no timestamp,
+// the passed time at which it was accepted is used as the last update time.
+func NodeFromWireAnnouncement2(msg *lnwire.NodeAnnouncement2,
+	lastUpdate time.Time) (*Node, error) {
+
+	// The addresses are kept in the order of their TLV records, so that
+	// the exact announcement can be reconstructed later on.
+	var addrs []net.Addr
+	msg.IPV4Addrs.WhenSomeV(func(ipv4Addrs lnwire.IPV4Addrs) {
+		for _, addr := range ipv4Addrs {
+			addrs = append(addrs, addr)
+		}
+	})
+	msg.IPV6Addrs.WhenSomeV(func(ipv6Addrs lnwire.IPV6Addrs) {
+		for _, addr := range ipv6Addrs {
+			addrs = append(addrs, addr)
+		}
+	})
+	msg.TorV3Addrs.WhenSomeV(func(torV3Addrs lnwire.TorV3Addrs) {
+		for _, addr := range torV3Addrs {
+			addrs = append(addrs, addr)
+		}
+	})
+	msg.DNSHostName.WhenSomeV(func(addr lnwire.DNSAddress) {
+		addrs = append(addrs, &addr)
+	})
+
+	nodeColor := fn.MapOption(func(c lnwire.Color) color.RGBA {
+		return color.RGBA(c)
+	})(msg.Color.ValOpt())
+	alias := fn.MapOption(func(a lnwire.NodeAlias2) string {
+		return string(a)
+	})(msg.Alias.ValOpt())
+
+	features := msg.Features.Val
+
+	return NewV2Node(msg.NodeID.Val, &NodeV2Fields{
+		BlockHeight:       msg.BlockHeight.Val,
+		Addresses:         addrs,
+		Signature:         msg.Signature.Val.ToSignatureBytes(),
+		Features:          &features,
+		Color:             nodeColor,
+		Alias:             alias,
+		LastUpdate:        lastUpdate,
+		ExtraSignedFields: msg.ExtraSignedFields,
+	})
+}
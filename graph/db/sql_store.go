@@ -82,6 +82,7 @@ type SQLQueries interface {
 	*/
 	CreateChannel(ctx context.Context, arg sqlc.CreateChannelParams) (int64, error)
 	AddV1ChannelProof(ctx context.Context, arg sqlc.AddV1ChannelProofParams) (sql.Result, error)
+	AddV2ChannelProof(ctx context.Context, arg sqlc.AddV2ChannelProofParams) (sql.Result, error)
 	GetChannelBySCID(ctx context.Context, arg sqlc.GetChannelBySCIDParams) (sqlc.GraphChannel, error)
 	GetChannelsBySCIDs(ctx context.Context, arg sqlc.GetChannelsBySCIDsParams) ([]sqlc.GraphChannel, error)
 	GetChannelsByOutpoints(ctx context.Context, outpoints []string) ([]sqlc.GetChannelsByOutpointsRow, error)
@@ -273,7 +274,33 @@ func (s *SQLStore) FetchNode(ctx context.Context,
 	var node *models.Node
 	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
 		var err error
-		_, node, err = getNodeByPubKey(ctx, s.cfg.QueryCfg, db, pubKey)
+		_, node, err = getNodeByPubKey(
+			ctx, s.cfg.QueryCfg, db, lnwire.GossipVersion1, pubKey,
+		)
+
+		return err
+	}, sqldb.NoOpReset)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch node: %w", err)
+	}
+
+	return node, nil
+}
+
+// FetchNodeV2 attempts to look up the v2 announcement of a target node by its
+// identity public key. If we don't have a v2 announcement for the node, then
+// ErrGraphNodeNotFound is returned.
+//
+// NOTE: part of the V1Store interface.
+func (s *SQLStore) FetchNodeV2(ctx context.Context,
+	pubKey route.Vertex) (*models.Node, error) {
+
+	var node *models.Node
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		var err error
+		_, node, err = getNodeByPubKey(
+			ctx, s.cfg.QueryCfg, db, lnwire.GossipVersion2, pubKey,
+		)
 
 		return err
 	}, sqldb.NoOpReset)
@@ -399,6 +426,14 @@ func (s *SQLStore) DeleteNode(ctx context.Context,
 			return fmt.Errorf("deleted %d rows, expected 1", rows)
 		}
 
+		// Any v2 announcement of the node goes along with it.
+		_, err = db.DeleteNodeByPubKey(
+			ctx, sqlc.DeleteNodeByPubKeyParams{
+				Version: int16(lnwire.GossipVersion2),
+				PubKey:  pubKey[:],
+			},
+		)
+
 		return err
 	}, sqldb.NoOpReset)
 	if err != nil {
@@ -501,7 +536,9 @@ func (s *SQLStore) SourceNode(ctx context.Context) (*models.Node,
 				err)
 		}
 
-		_, node, err = getNodeByPubKey(ctx, s.cfg.QueryCfg, db, nodePub)
+		_, node, err = getNodeByPubKey(
+			ctx, s.cfg.QueryCfg, db, lnwire.GossipVersion1, nodePub,
+		)
 
 		return err
 	}, sqldb.NoOpReset)
@@ -687,13 +724,9 @@ func (s *SQLStore) AddChannelEdge(ctx context.Context,
 			// do this explicitly instead of relying on catching a
 			// unique constraint error because relying on SQL to
 			// throw that error would abort the entire batch of
-			// transactions.
-			_, err := tx.GetChannelBySCID(
-				ctx, sqlc.GetChannelBySCIDParams{
-					Scid:    chanIDB,
-					Version: int16(lnwire.GossipVersion1),
-				},
-			)
+			// transactions. A SCID may only be known under a single
+			// gossip version, so all versions are checked.
+			_, err := getChannelBySCID(ctx, tx, chanIDB)
 			if err == nil {
 				alreadyExists = true
 				return nil
@@ -729,15 +762,19 @@ func (s *SQLStore) AddChannelEdge(ctx context.Context,
 func (s *SQLStore) HighestChanID(ctx context.Context) (uint64, error) {
 	var highestChanID uint64
 	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
-		chanID, err := db.HighestSCID(ctx, int16(lnwire.GossipVersion1))
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil
-		} else if err != nil {
-			return fmt.Errorf("unable to fetch highest chan ID: %w",
-				err)
-		}
+		for _, v := range gossipVersions {
+			chanID, err := db.HighestSCID(ctx, int16(v))
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			} else if err != nil {
+				return fmt.Errorf("unable to fetch highest "+
+					"chan ID: %w", err)
+			}
 
-		highestChanID = byteOrder.Uint64(chanID)
+			highestChanID = max(
+				highestChanID, byteOrder.Uint64(chanID),
+			)
+		}
 
 		return nil
 	}, sqldb.NoOpReset)
@@ -891,7 +928,8 @@ func (s *SQLStore) ForEachSourceNodeChannel(ctx context.Context,
 				}
 
 				_, otherNode, err := getNodeByPubKey(
-					ctx, s.cfg.QueryCfg, db, otherNodePub,
+					ctx, s.cfg.QueryCfg, db,
+					lnwire.GossipVersion1, otherNodePub,
 				)
 				if err != nil {
 					return fmt.Errorf("unable to fetch "+
@@ -1116,14 +1154,19 @@ func (s *SQLStore) ChanUpdatesInHorizon(startTime, endTime time.Time,
 			total          int
 			lastUpdateTime sql.NullInt64
 			lastID         sql.NullInt64
-			hasMore        = true
+			hasMore        bool
+			versions       = gossipVersions
 		)
 
 		// Each iteration, we'll read a batch amount of channel updates
 		// (consulting the cache along the way), yield them, then loop
-		// back to decide if we have any more updates to read out.
-		for hasMore {
-			var batch []ChannelEdge
+		// back to decide if we have any more updates to read out. The
+		// channels of each gossip version are read out in turn.
+		for len(versions) > 0 {
+			var (
+				batch   []ChannelEdge
+				version = versions[0]
+			)
 
 			// Acquire read lock before starting transaction to
 			// ensure consistent lock ordering (cacheMu -> DB) and
@@ -1134,7 +1177,7 @@ func (s *SQLStore) ChanUpdatesInHorizon(startTime, endTime time.Time,
 				func(db SQLQueries) error {
 					//nolint:ll
 					params := sqlc.GetChannelsByPolicyLastUpdateRangeParams{
-						Version: int16(lnwire.GossipVersion1),
+						Version: int16(version),
 						StartTime: sqldb.SQLInt64(
 							startTime.Unix(),
 						),
@@ -1243,9 +1286,13 @@ func (s *SQLStore) ChanUpdatesInHorizon(startTime, endTime time.Time,
 			s.updateChanCacheBatch(edgesToCache)
 			edgesToCache = make(map[uint64]ChannelEdge)
 
-			// If the batch didn't yield anything, then we're done.
-			if len(batch) == 0 {
-				break
+			// If the batch didn't yield anything, or there are no
+			// more updates to read out, then we're done with the
+			// current gossip version.
+			if len(batch) == 0 || !hasMore {
+				versions = versions[1:]
+				lastUpdateTime = sql.NullInt64{}
+				lastID = sql.NullInt64{}
 			}
 		}
 
@@ -1303,17 +1350,23 @@ func (s *SQLStore) ForEachNodeCached(ctx context.Context,
 			}
 
 			// Batch load ALL unique channels for ALL nodes in this
-			// page.
-			allChannels, err := db.ListChannelsForNodeIDs(
-				ctx, sqlc.ListChannelsForNodeIDsParams{
-					Version:  int16(lnwire.GossipVersion1),
-					Node1Ids: nodeIDs,
-					Node2Ids: nodeIDs,
-				},
-			)
-			if err != nil {
-				return nil, fmt.Errorf("unable to batch "+
-					"fetch channels for nodes: %w", err)
+			// page, across all gossip versions.
+			var allChannels []sqlc.ListChannelsForNodeIDsRow
+			for _, v := range gossipVersions {
+				chans, err := db.ListChannelsForNodeIDs(
+					ctx, sqlc.ListChannelsForNodeIDsParams{
+						Version:  int16(v),
+						Node1Ids: nodeIDs,
+						Node2Ids: nodeIDs,
+					},
+				)
+				if err != nil {
+					return nil, fmt.Errorf("unable to "+
+						"batch fetch channels for "+
+						"nodes: %w", err)
+				}
+
+				allChannels = append(allChannels, chans...)
 			}
 
 			// Deduplicate channels and collect IDs.
@@ -1505,24 +1558,31 @@ func (s *SQLStore) ForEachChannelCacheable(cb func(*models.CachedEdgeInfo,
 	}
 
 	return s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
-		//nolint:ll
-		queryFunc := func(ctx context.Context, lastID int64,
-			limit int32) ([]sqlc.ListChannelsWithPoliciesForCachePaginatedRow,
-			error) {
+		for _, v := range gossipVersions {
+			//nolint:ll
+			queryFunc := func(ctx context.Context, lastID int64,
+				limit int32) ([]sqlc.ListChannelsWithPoliciesForCachePaginatedRow,
+				error) {
 
-			return db.ListChannelsWithPoliciesForCachePaginated(
-				ctx, sqlc.ListChannelsWithPoliciesForCachePaginatedParams{
-					Version: int16(lnwire.GossipVersion1),
-					ID:      lastID,
-					Limit:   limit,
-				},
+				return db.ListChannelsWithPoliciesForCachePaginated(
+					ctx, sqlc.ListChannelsWithPoliciesForCachePaginatedParams{
+						Version: int16(v),
+						ID:      lastID,
+						Limit:   limit,
+					},
+				)
+			}
+
+			err := sqldb.ExecutePaginatedQuery(
+				ctx, s.cfg.QueryCfg, int64(-1), queryFunc,
+				extractCursor, handleChannel,
 			)
+			if err != nil {
+				return err
+			}
 		}
 
-		return sqldb.ExecutePaginatedQuery(
-			ctx, s.cfg.QueryCfg, int64(-1), queryFunc,
-			extractCursor, handleChannel,
-		)
+		return nil
 	}, reset)
 }
 
@@ -1610,7 +1670,7 @@ func (s *SQLStore) FilterChannelRange(startHeight, endHeight uint32,
 			//nolint:ll
 			node1Policy, err := db.GetChannelPolicyByChannelAndNode(
 				ctx, sqlc.GetChannelPolicyByChannelAndNodeParams{
-					Version:   int16(lnwire.GossipVersion1),
+					Version:   dbChan.Version,
 					ChannelID: dbChan.ID,
 					NodeID:    dbChan.NodeID1,
 				},
@@ -1627,7 +1687,7 @@ func (s *SQLStore) FilterChannelRange(startHeight, endHeight uint32,
 			//nolint:ll
 			node2Policy, err := db.GetChannelPolicyByChannelAndNode(
 				ctx, sqlc.GetChannelPolicyByChannelAndNodeParams{
-					Version:   int16(lnwire.GossipVersion1),
+					Version:   dbChan.Version,
 					ChannelID: dbChan.ID,
 					NodeID:    dbChan.NodeID2,
 				},
@@ -1954,12 +2014,21 @@ func (s *SQLStore) FetchChannelEdgesByID(chanID uint64) (
 		chanIDB          = channelIDToBytes(chanID)
 	)
 	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
-		row, err := db.GetChannelBySCIDWithPolicies(
-			ctx, sqlc.GetChannelBySCIDWithPoliciesParams{
-				Scid:    chanIDB,
-				Version: int16(lnwire.GossipVersion1),
-			},
+		var (
+			row sqlc.GetChannelBySCIDWithPoliciesRow
+			err error
 		)
+		for _, v := range gossipVersions {
+			row, err = db.GetChannelBySCIDWithPolicies(
+				ctx, sqlc.GetChannelBySCIDWithPoliciesParams{
+					Scid:    chanIDB,
+					Version: int16(v),
+				},
+			)
+			if !errors.Is(err, sql.ErrNoRows) {
+				break
+			}
+		}
 		if errors.Is(err, sql.ErrNoRows) {
 			// First check if this edge is perhaps in the zombie
 			// index.
@@ -2050,12 +2119,22 @@ func (s *SQLStore) FetchChannelEdgesByOutpoint(op *wire.OutPoint) (
 		policy1, policy2 *models.ChannelEdgePolicy
 	)
 	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
-		row, err := db.GetChannelByOutpointWithPolicies(
-			ctx, sqlc.GetChannelByOutpointWithPoliciesParams{
-				Outpoint: op.String(),
-				Version:  int16(lnwire.GossipVersion1),
-			},
+		var (
+			row sqlc.GetChannelByOutpointWithPoliciesRow
+			err error
 		)
+		for _, v := range gossipVersions {
+			//nolint:ll
+			row, err = db.GetChannelByOutpointWithPolicies(
+				ctx, sqlc.GetChannelByOutpointWithPoliciesParams{
+					Outpoint: op.String(),
+					Version:  int16(v),
+				},
+			)
+			if !errors.Is(err, sql.ErrNoRows) {
+				break
+			}
+		}
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrEdgeNotFound
 		} else if err != nil {
@@ -2151,12 +2230,7 @@ func (s *SQLStore) HasChannelEdge(chanID uint64) (time.Time, time.Time, bool,
 
 	chanIDB := channelIDToBytes(chanID)
 	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
-		channel, err := db.GetChannelBySCID(
-			ctx, sqlc.GetChannelBySCIDParams{
-				Scid:    chanIDB,
-				Version: int16(lnwire.GossipVersion1),
-			},
-		)
+		channel, err := getChannelBySCID(ctx, db, chanIDB)
 		if errors.Is(err, sql.ErrNoRows) {
 			// Check if it is a zombie channel.
 			isZombie, err = db.IsZombieChannel(
@@ -2179,7 +2253,7 @@ func (s *SQLStore) HasChannelEdge(chanID uint64) (time.Time, time.Time, bool,
 
 		policy1, err := db.GetChannelPolicyByChannelAndNode(
 			ctx, sqlc.GetChannelPolicyByChannelAndNodeParams{
-				Version:   int16(lnwire.GossipVersion1),
+				Version:   channel.Version,
 				ChannelID: channel.ID,
 				NodeID:    channel.NodeID1,
 			},
@@ -2193,7 +2267,7 @@ func (s *SQLStore) HasChannelEdge(chanID uint64) (time.Time, time.Time, bool,
 
 		policy2, err := db.GetChannelPolicyByChannelAndNode(
 			ctx, sqlc.GetChannelPolicyByChannelAndNodeParams{
-				Version:   int16(lnwire.GossipVersion1),
+				Version:   channel.Version,
 				ChannelID: channel.ID,
 				NodeID:    channel.NodeID2,
 			},
@@ -2232,12 +2306,21 @@ func (s *SQLStore) ChannelID(chanPoint *wire.OutPoint) (uint64, error) {
 		channelID uint64
 	)
 	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
-		chanID, err := db.GetSCIDByOutpoint(
-			ctx, sqlc.GetSCIDByOutpointParams{
-				Outpoint: chanPoint.String(),
-				Version:  int16(lnwire.GossipVersion1),
-			},
+		var (
+			chanID []byte
+			err    error
 		)
+		for _, v := range gossipVersions {
+			chanID, err = db.GetSCIDByOutpoint(
+				ctx, sqlc.GetSCIDByOutpointParams{
+					Outpoint: chanPoint.String(),
+					Version:  int16(v),
+				},
+			)
+			if !errors.Is(err, sql.ErrNoRows) {
+				break
+			}
+		}
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrEdgeNotFound
 		} else if err != nil {
@@ -2268,6 +2351,13 @@ func (s *SQLStore) IsPublicNode(pubKey [33]byte) (bool, error) {
 	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
 		var err error
 		isPublic, err = db.IsPublicV1Node(ctx, pubKey[:])
+		if err != nil || isPublic {
+			return err
+		}
+
+		// The node may still be public by way of a channel that was
+		// announced using the v2 gossip protocol.
+		isPublic, err = hasPublicV2Channel(ctx, db, pubKey)
 
 		return err
 	}, sqldb.NoOpReset)
@@ -2358,12 +2448,22 @@ func (s *SQLStore) forEachChanWithPoliciesInSCIDList(ctx context.Context,
 		scids [][]byte) ([]sqlc.GetChannelsBySCIDWithPoliciesRow,
 		error) {
 
-		return db.GetChannelsBySCIDWithPolicies(
-			ctx, sqlc.GetChannelsBySCIDWithPoliciesParams{
-				Version: int16(lnwire.GossipVersion1),
-				Scids:   scids,
-			},
-		)
+		var rows []sqlc.GetChannelsBySCIDWithPoliciesRow
+		for _, v := range gossipVersions {
+			vRows, err := db.GetChannelsBySCIDWithPolicies(
+				ctx, sqlc.GetChannelsBySCIDWithPoliciesParams{
+					Version: int16(v),
+					Scids:   scids,
+				},
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			rows = append(rows, vRows...)
+		}
+
+		return rows, nil
 	}
 
 	return sqldb.ExecuteBatchQuery(
@@ -2475,12 +2575,22 @@ func (s *SQLStore) forEachChanInSCIDList(ctx context.Context, db SQLQueries,
 	queryWrapper := func(ctx context.Context,
 		scids [][]byte) ([]sqlc.GraphChannel, error) {
 
-		return db.GetChannelsBySCIDs(
-			ctx, sqlc.GetChannelsBySCIDsParams{
-				Version: int16(lnwire.GossipVersion1),
-				Scids:   scids,
-			},
-		)
+		var channels []sqlc.GraphChannel
+		for _, v := range gossipVersions {
+			vChans, err := db.GetChannelsBySCIDs(
+				ctx, sqlc.GetChannelsBySCIDsParams{
+					Version: int16(v),
+					Scids:   scids,
+				},
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			channels = append(channels, vChans...)
+		}
+
+		return channels, nil
 	}
 
 	chanIDConverter := func(chanInfo ChannelUpdateInfo) []byte {
@@ -2695,12 +2805,24 @@ func (s *SQLStore) ChannelView() ([]EdgePoint, error) {
 	)
 
 	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		var version lnwire.GossipVersion
 		handleChannel := func(_ context.Context,
 			channel sqlc.ListChannelsPaginatedRow) error {
 
-			pkScript, err := genMultiSigP2WSH(
-				channel.FlokicoinKey1, channel.FlokicoinKey2,
+			var (
+				pkScript []byte
+				err      error
 			)
+			if version == lnwire.GossipVersion1 {
+				pkScript, err = genMultiSigP2WSH(
+					channel.FlokicoinKey1,
+					channel.FlokicoinKey2,
+				)
+			} else {
+				pkScript, err = fetchFundingScript(
+					ctx, s.cfg.QueryCfg, db, channel.ID,
+				)
+			}
 			if err != nil {
 				return err
 			}
@@ -2723,7 +2845,7 @@ func (s *SQLStore) ChannelView() ([]EdgePoint, error) {
 
 			return db.ListChannelsPaginated(
 				ctx, sqlc.ListChannelsPaginatedParams{
-					Version: int16(lnwire.GossipVersion1),
+					Version: int16(version),
 					ID:      lastID,
 					Limit:   limit,
 				},
@@ -2734,10 +2856,17 @@ func (s *SQLStore) ChannelView() ([]EdgePoint, error) {
 			return row.ID
 		}
 
-		return sqldb.ExecutePaginatedQuery(
-			ctx, s.cfg.QueryCfg, int64(-1), queryFunc,
-			extractCursor, handleChannel,
-		)
+		for _, version = range gossipVersions {
+			err := sqldb.ExecutePaginatedQuery(
+				ctx, s.cfg.QueryCfg, int64(-1), queryFunc,
+				extractCursor, handleChannel,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
 	}, func() {
 		edgePoints = nil
 	})
@@ -2793,15 +2922,23 @@ func (s *SQLStore) pruneGraphNodes(ctx context.Context,
 			"nodes: %w", err)
 	}
 
-	prunedNodes := make([]route.Vertex, len(nodeKeys))
-	for i, nodeKey := range nodeKeys {
+	// The records of all gossip versions of a node are deleted together,
+	// so a node is only reported once.
+	prunedNodes := make([]route.Vertex, 0, len(nodeKeys))
+	seen := make(map[route.Vertex]struct{}, len(nodeKeys))
+	for _, nodeKey := range nodeKeys {
 		pub, err := route.NewVertexFromBytes(nodeKey)
 		if err != nil {
 			return nil, fmt.Errorf("unable to parse pubkey "+
 				"from bytes: %w", err)
 		}
 
-		prunedNodes[i] = pub
+		if _, ok := seen[pub]; ok {
+			continue
+		}
+		seen[pub] = struct{}{}
+
+		prunedNodes = append(prunedNodes, pub)
 	}
 
 	return prunedNodes, nil
@@ -2913,15 +3050,7 @@ func (s *SQLStore) AddEdgeProof(scid lnwire.ShortChannelID,
 	)
 
 	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
-		res, err := db.AddV1ChannelProof(
-			ctx, sqlc.AddV1ChannelProofParams{
-				Scid:                scidBytes,
-				Node1Signature:      proof.NodeSig1Bytes,
-				Node2Signature:      proof.NodeSig2Bytes,
-				Flokicoin1Signature: proof.FlokicoinSig1Bytes,
-				Flokicoin2Signature: proof.FlokicoinSig2Bytes,
-			},
-		)
+		res, err := addChannelProof(ctx, db, scidBytes, proof)
 		if err != nil {
 			return fmt.Errorf("unable to add edge proof: %w", err)
 		}
@@ -2949,6 +3078,33 @@ func (s *SQLStore) AddEdgeProof(scid lnwire.ShortChannelID,
 	return nil
 }
 
+// addChannelProof writes the given proof to the channel with the given SCID
+// and of the same gossip version as the proof.
+func addChannelProof(ctx context.Context, db SQLQueries, scid []byte,
+	proof *models.ChannelAuthProof) (sql.Result, error) {
+
+	switch proof.GossipVersion() {
+	case lnwire.GossipVersion1:
+		return db.AddV1ChannelProof(ctx, sqlc.AddV1ChannelProofParams{
+			Scid:                scid,
+			Node1Signature:      proof.NodeSig1Bytes,
+			Node2Signature:      proof.NodeSig2Bytes,
+			Flokicoin1Signature: proof.FlokicoinSig1Bytes,
+			Flokicoin2Signature: proof.FlokicoinSig2Bytes,
+		})
+
+	case lnwire.GossipVersion2:
+		return db.AddV2ChannelProof(ctx, sqlc.AddV2ChannelProofParams{
+			Scid:           scid,
+			Node1Signature: proof.Signature,
+		})
+
+	default:
+		return nil, fmt.Errorf("unknown proof version: %v",
+			proof.GossipVersion())
+	}
+}
+
 // PutClosedScid stores a SCID for a closed channel in the database. This is so
 // that we can ignore channel announcements that we know to be closed without
 // having to validate them and fetch a block.
@@ -3073,12 +3229,7 @@ func forEachNodeDirectedChannel(ctx context.Context, db SQLQueries,
 		return fmt.Errorf("unable to fetch node: %w", err)
 	}
 
-	rows, err := db.ListChannelsByNodeID(
-		ctx, sqlc.ListChannelsByNodeIDParams{
-			Version: int16(lnwire.GossipVersion1),
-			NodeID1: dbID,
-		},
-	)
+	rows, err := listChannelsByNodeID(ctx, db, dbID)
 	if err != nil {
 		return fmt.Errorf("unable to fetch channels: %w", err)
 	}
@@ -3227,13 +3378,8 @@ func forEachNodeChannel(ctx context.Context, db SQLQueries,
 		*models.ChannelEdgePolicy,
 		*models.ChannelEdgePolicy) error) error {
 
-	// Get all the V1 channels for this node.
-	rows, err := db.ListChannelsByNodeID(
-		ctx, sqlc.ListChannelsByNodeIDParams{
-			Version: int16(lnwire.GossipVersion1),
-			NodeID1: id,
-		},
-	)
+	// Get all the channels for this node.
+	rows, err := listChannelsByNodeID(ctx, db, id)
 	if err != nil {
 		return fmt.Errorf("unable to fetch channels: %w", err)
 	}
@@ -3328,11 +3474,13 @@ func updateChanEdgePolicy(ctx context.Context, tx SQLQueries,
 	// Check that this edge policy refers to a channel that we already
 	// know of. We do this explicitly so that we can return the appropriate
 	// ErrEdgeNotFound error if the channel doesn't exist, rather than
-	// abort the transaction which would abort the entire batch.
+	// abort the transaction which would abort the entire batch. A policy
+	// can only refer to a channel of the same gossip version.
+	version := int16(edge.GossipVersion())
 	dbChan, err := tx.GetChannelAndNodesBySCID(
 		ctx, sqlc.GetChannelAndNodesBySCIDParams{
 			Scid:    chanIDB,
-			Version: int16(lnwire.GossipVersion1),
+			Version: version,
 		},
 	)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -3362,7 +3510,7 @@ func updateChanEdgePolicy(ctx context.Context, tx SQLQueries,
 	})
 
 	id, err := tx.UpsertEdgePolicy(ctx, sqlc.UpsertEdgePolicyParams{
-		Version:     int16(lnwire.GossipVersion1),
+		Version:     version,
 		ChannelID:   dbChan.ID,
 		NodeID:      nodeID,
 		Timelock:    int32(edge.TimeLockDelta),
@@ -3397,6 +3545,15 @@ func updateChanEdgePolicy(ctx context.Context, tx SQLQueries,
 			"marshal extra opaque data: %w", err)
 	}
 
+	// The v2 specific fields that don't have a column of their own are
+	// stored alongside the extra signed fields.
+	if edge.GossipVersion() != lnwire.GossipVersion1 {
+		var height [4]byte
+		byteOrder.PutUint32(height[:], edge.BlockHeight)
+		extra[policyBlockHeightType] = height[:]
+		extra[policyDisableFlagsType] = []byte{byte(edge.DisableFlags)}
+	}
+
 	// Update the channel policy's extra signed fields.
 	err = upsertChanPolicyExtraSignedFields(ctx, tx, id, extra)
 	if err != nil {
@@ -3407,13 +3564,15 @@ func updateChanEdgePolicy(ctx context.Context, tx SQLQueries,
 	return node1Pub, node2Pub, isNode1, nil
 }
 
-// getNodeByPubKey attempts to look up a target node by its public key.
+// getNodeByPubKey attempts to look up the record of the given gossip version
+// of a target node by its public key.
 func getNodeByPubKey(ctx context.Context, cfg *sqldb.QueryConfig, db SQLQueries,
-	pubKey route.Vertex) (int64, *models.Node, error) {
+	version lnwire.GossipVersion, pubKey route.Vertex) (int64, *models.Node,
+	error) {
 
 	dbNode, err := db.GetNodeByPubKey(
 		ctx, sqlc.GetNodeByPubKeyParams{
-			Version: int16(lnwire.GossipVersion1),
+			Version: int16(version),
 			PubKey:  pubKey[:],
 		},
 	)
@@ -3466,7 +3625,10 @@ func buildNode(ctx context.Context, cfg *sqldb.QueryConfig, db SQLQueries,
 func buildNodeWithBatchData(dbNode sqlc.GraphNode,
 	batchData *batchNodeData) (*models.Node, error) {
 
-	if dbNode.Version != int16(lnwire.GossipVersion1) {
+	version := lnwire.GossipVersion(dbNode.Version)
+	switch version {
+	case lnwire.GossipVersion1, lnwire.GossipVersion2:
+	default:
 		return nil, fmt.Errorf("unsupported node version: %d",
 			dbNode.Version)
 	}
@@ -3474,7 +3636,7 @@ func buildNodeWithBatchData(dbNode sqlc.GraphNode,
 	var pub [33]byte
 	copy(pub[:], dbNode.PubKey)
 
-	node := models.NewV1ShellNode(pub)
+	node := models.NewShellNode(version, pub)
 
 	if len(dbNode.Signature) == 0 {
 		return node, nil
@@ -3521,6 +3683,20 @@ func buildNodeWithBatchData(dbNode sqlc.GraphNode,
 
 	// Use preloaded extra fields.
 	if extraFields, exists := batchData.extraFields[dbNode.ID]; exists {
+		// The block height of a v2 node is stored alongside its
+		// extra signed fields, so it's taken out of the set first.
+		if version == lnwire.GossipVersion2 {
+			extraFields = maps.Clone(extraFields)
+
+			height, ok := extraFields[nodeBlockHeightType]
+			if !ok || len(height) != 4 {
+				return nil, fmt.Errorf("invalid block height "+
+					"for v2 node(%d)", dbNode.ID)
+			}
+			node.BlockHeight = byteOrder.Uint32(height)
+			delete(extraFields, nodeBlockHeightType)
+		}
+
 		recs, err := lnwire.CustomRecords(extraFields).Serialize()
 		if err != nil {
 			return nil, fmt.Errorf("unable to serialize extra "+
@@ -3613,6 +3789,14 @@ func upsertNodeAncillaryData(ctx context.Context, db SQLQueries,
 			err)
 	}
 
+	// The block height of a v2 node doesn't have a column of its own, so
+	// it is stored alongside the extra signed fields.
+	if node.GossipVersion() == lnwire.GossipVersion2 {
+		var height [4]byte
+		byteOrder.PutUint32(height[:], node.BlockHeight)
+		extra[nodeBlockHeightType] = height[:]
+	}
+
 	// Update the node's extra signed fields.
 	err = upsertNodeExtraSignedFields(ctx, db, nodeID, extra)
 	if err != nil {
@@ -3632,8 +3816,10 @@ func populateNodeParams(node *models.Node,
 		return nil
 	}
 
-	switch node.Version {
-	case lnwire.GossipVersion1:
+	switch node.GossipVersion() {
+	// As v2 announcements carry no timestamp, the last update of a v2 node
+	// is the time at which we accepted its announcement.
+	case lnwire.GossipVersion1, lnwire.GossipVersion2:
 		lastUpdate := sqldb.SQLInt64(node.LastUpdate.Unix())
 		var alias, colorStr sql.NullString
 
@@ -3646,9 +3832,6 @@ func populateNodeParams(node *models.Node,
 
 		setParams(lastUpdate, alias, colorStr, node.AuthSigBytes)
 
-	case lnwire.GossipVersion2:
-		// No-op for now.
-
 	default:
 		return fmt.Errorf("unknown gossip version: %d", node.Version)
 	}
@@ -3660,7 +3843,7 @@ func populateNodeParams(node *models.Node,
 // strict UpsertNode query (requires timestamp to be increasing).
 func buildNodeUpsertParams(node *models.Node) (sqlc.UpsertNodeParams, error) {
 	params := sqlc.UpsertNodeParams{
-		Version: int16(lnwire.GossipVersion1),
+		Version: int16(node.GossipVersion()),
 		PubKey:  node.PubKeyBytes[:],
 	}
 
@@ -3684,7 +3867,7 @@ func buildSourceNodeUpsertParams(node *models.Node) (
 	sqlc.UpsertSourceNodeParams, error) {
 
 	params := sqlc.UpsertSourceNodeParams{
-		Version: int16(lnwire.GossipVersion1),
+		Version: int16(node.GossipVersion()),
 		PubKey:  node.PubKeyBytes[:],
 	}
 
@@ -3742,6 +3925,13 @@ func upsertSourceNode(ctx context.Context, db SQLQueries,
 func upsertNode(ctx context.Context, db SQLQueries,
 	node *models.Node) (int64, error) {
 
+	// v2 announcements are ordered by the block height they were signed
+	// at, which the caller has already checked, so their record is
+	// updated unconditionally like the one of our own node.
+	if node.GossipVersion() == lnwire.GossipVersion2 {
+		return upsertSourceNode(ctx, db, node)
+	}
+
 	params, err := buildNodeUpsertParams(node)
 	if err != nil {
 		return 0, err
@@ -4124,11 +4314,10 @@ func marshalExtraOpaqueData(data []byte) (map[uint64][]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrParsingExtraTLVBytes, err)
 	}
-	if len(parsedTypes) == 0 {
-		return nil, nil
-	}
 
-	records := make(map[uint64][]byte)
+	// The map is always allocated so that callers can add the fields of
+	// their own that are stored alongside the extra TLV fields.
+	records := make(map[uint64][]byte, len(parsedTypes))
 	for k, v := range parsedTypes {
 		records[uint64(k)] = v
 	}
@@ -4158,23 +4347,39 @@ func insertChannel(ctx context.Context, db SQLQueries,
 	}
 
 	createParams := sqlc.CreateChannelParams{
-		Version:       int16(lnwire.GossipVersion1),
-		Scid:          channelIDToBytes(edge.ChannelID),
-		NodeID1:       node1DBID,
-		NodeID2:       node2DBID,
-		Outpoint:      edge.ChannelPoint.String(),
-		Capacity:      capacity,
-		FlokicoinKey1: edge.FlokicoinKey1Bytes[:],
-		FlokicoinKey2: edge.FlokicoinKey2Bytes[:],
+		Version:  int16(edge.GossipVersion()),
+		Scid:     channelIDToBytes(edge.ChannelID),
+		NodeID1:  node1DBID,
+		NodeID2:  node2DBID,
+		Outpoint: edge.ChannelPoint.String(),
+		Capacity: capacity,
+	}
+
+	// The funding keys are optional for v2 channels, in which case the
+	// columns are left NULL.
+	if edge.GossipVersion() == lnwire.GossipVersion1 ||
+		edge.HasFlokicoinKeys() {
+
+		createParams.FlokicoinKey1 = edge.FlokicoinKey1Bytes[:]
+		createParams.FlokicoinKey2 = edge.FlokicoinKey2Bytes[:]
 	}
 
+	// A v2 proof consists of a single Schnorr signature which is stored
+	// in the node_1_signature column so that the channel is treated as
+	// public in the same way as a v1 channel with a proof.
 	if edge.AuthProof != nil {
 		proof := edge.AuthProof
 
-		createParams.Node1Signature = proof.NodeSig1Bytes
-		createParams.Node2Signature = proof.NodeSig2Bytes
-		createParams.Flokicoin1Signature = proof.FlokicoinSig1Bytes
-		createParams.Flokicoin2Signature = proof.FlokicoinSig2Bytes
+		if proof.GossipVersion() == lnwire.GossipVersion2 {
+			createParams.Node1Signature = proof.Signature
+		} else {
+			createParams.Node1Signature = proof.NodeSig1Bytes
+			createParams.Node2Signature = proof.NodeSig2Bytes
+			createParams.Flokicoin1Signature =
+				proof.FlokicoinSig1Bytes
+			createParams.Flokicoin2Signature =
+				proof.FlokicoinSig2Bytes
+		}
 	}
 
 	// Insert the new channel record.
@@ -4204,6 +4409,17 @@ func insertChannel(ctx context.Context, db SQLQueries,
 			err)
 	}
 
+	// The v2 specific fields that don't have a column of their own are
+	// stored alongside the extra fields.
+	edge.MerkleRootHash.WhenSome(func(root chainhash.Hash) {
+		extra[chanMerkleRootType] = root[:]
+	})
+	if edge.GossipVersion() != lnwire.GossipVersion1 {
+		edge.FundingScript.WhenSome(func(script []byte) {
+			extra[chanFundingScriptType] = script
+		})
+	}
+
 	for tlvType, value := range extra {
 		err := db.UpsertChannelExtraType(
 			ctx, sqlc.UpsertChannelExtraTypeParams{
@@ -4313,7 +4529,10 @@ func buildEdgeInfoWithBatchData(chain chainhash.Hash,
 	dbChan sqlc.GraphChannel, node1, node2 route.Vertex,
 	batchData *batchChannelData) (*models.ChannelEdgeInfo, error) {
 
-	if dbChan.Version != int16(lnwire.GossipVersion1) {
+	version := lnwire.GossipVersion(dbChan.Version)
+	switch version {
+	case lnwire.GossipVersion1, lnwire.GossipVersion2:
+	default:
 		return nil, fmt.Errorf("unsupported channel version: %d",
 			dbChan.Version)
 	}
@@ -4326,14 +4545,33 @@ func buildEdgeInfoWithBatchData(chain chainhash.Hash,
 		}
 	}
 
-	var extras map[uint64][]byte
-	channelExtras, exists := batchData.chanExtraTypes[dbChan.ID]
-	if exists {
-		extras = channelExtras
-	} else {
+	// The extras are copied since the v2 specific fields are removed from
+	// the set before the rest is turned back into the opaque data.
+	extras := maps.Clone(batchData.chanExtraTypes[dbChan.ID])
+	if extras == nil {
 		extras = make(map[uint64][]byte)
 	}
 
+	var (
+		merkleRoot    fn.Option[chainhash.Hash]
+		fundingScript fn.Option[[]byte]
+	)
+	if version == lnwire.GossipVersion2 {
+		if root, ok := extras[chanMerkleRootType]; ok {
+			if len(root) != chainhash.HashSize {
+				return nil, fmt.Errorf("invalid merkle root "+
+					"length: %d", len(root))
+			}
+
+			merkleRoot = fn.Some(chainhash.Hash(root))
+			delete(extras, chanMerkleRootType)
+		}
+		if script, ok := extras[chanFundingScriptType]; ok {
+			fundingScript = fn.Some(script)
+			delete(extras, chanFundingScriptType)
+		}
+	}
+
 	op, err := wire.NewOutPointFromString(dbChan.Outpoint)
 	if err != nil {
 		return nil, err
@@ -4362,9 +4600,25 @@ func buildEdgeInfoWithBatchData(chain chainhash.Hash,
 		ChannelPoint:       *op,
 		Capacity:           chainutil.Amount(dbChan.Capacity.Int64),
 		Features:           fv,
+		MerkleRootHash:     merkleRoot,
+		FundingScript:      fundingScript,
 		ExtraOpaqueData:    recs,
 	}
 
+	// The single Schnorr signature of a v2 proof is stored in the
+	// node_1_signature column. As with the other stores, the version is
+	// only set explicitly for non-v1 edges.
+	if version == lnwire.GossipVersion2 {
+		channel.Version = version
+		if len(dbChan.Node1Signature) > 0 {
+			channel.AuthProof = models.NewV2ChannelAuthProof(
+				dbChan.Node1Signature,
+			)
+		}
+
+		return channel, nil
+	}
+
 	// We always set all the signatures at the same time, so we can
 	// safely check if one signature is present to determine if we have the
 	// rest of the signatures for the auth proof.
@@ -4478,6 +4732,37 @@ func buildChanPolicy(dbPolicy sqlc.GraphChannelPolicy, channelID uint64,
 	extras map[uint64][]byte,
 	toNode route.Vertex) (*models.ChannelEdgePolicy, error) {
 
+	// The v2 specific fields are pulled out of a copy of the extras before
+	// the rest is turned back into the opaque data.
+	var (
+		version      lnwire.GossipVersion
+		blockHeight  uint32
+		disableFlags lnwire.ChanUpdateDisableFlags
+	)
+	if dbPolicy.Version == int16(lnwire.GossipVersion2) {
+		version = lnwire.GossipVersion2
+		extras = maps.Clone(extras)
+
+		if height, ok := extras[policyBlockHeightType]; ok {
+			if len(height) != 4 {
+				return nil, fmt.Errorf("invalid block height "+
+					"length: %d", len(height))
+			}
+
+			blockHeight = byteOrder.Uint32(height)
+			delete(extras, policyBlockHeightType)
+		}
+		if flags, ok := extras[policyDisableFlagsType]; ok {
+			if len(flags) != 1 {
+				return nil, fmt.Errorf("invalid disable flags "+
+					"length: %d", len(flags))
+			}
+
+			disableFlags = lnwire.ChanUpdateDisableFlags(flags[0])
+			delete(extras, policyDisableFlagsType)
+		}
+	}
+
 	recs, err := lnwire.CustomRecords(extras).Serialize()
 	if err != nil {
 		return nil, fmt.Errorf("unable to serialize extra signed "+
@@ -4495,11 +4780,14 @@ func buildChanPolicy(dbPolicy sqlc.GraphChannelPolicy, channelID uint64,
 	}
 
 	return &models.ChannelEdgePolicy{
+		Version:   version,
 		SigBytes:  dbPolicy.Signature,
 		ChannelID: channelID,
 		LastUpdate: time.Unix(
 			dbPolicy.LastUpdate.Int64, 0,
 		),
+		BlockHeight:  blockHeight,
+		DisableFlags: disableFlags,
 		MessageFlags: sqldb.ExtractSqlInt16[lnwire.ChanUpdateMsgFlags](
 			dbPolicy.MessageFlags,
 		),
@@ -4924,6 +5212,144 @@ func extractChannelPolicies(row any) (*sqlc.GraphChannelPolicy,
 	}
 }
 
+const (
+	// chanMerkleRootType is the extra type under which the tapscript root
+	// of a v2 channel is stored. It matches the TLV type of the field in
+	// the channel_announcement_2 message.
+	chanMerkleRootType = 16
+
+	// chanFundingScriptType is the extra type under which the funding
+	// script of a v2 channel is stored. It is in the unsigned range of the
+	// channel_announcement_2 message and so never collides with any of its
+	// extra signed fields.
+	chanFundingScriptType = 161
+
+	// nodeBlockHeightType is the extra type under which the block height
+	// of a v2 node is stored. It matches the TLV type of the field in the
+	// node_announcement_2 message.
+	nodeBlockHeightType = 2
+
+	// policyBlockHeightType is the extra type under which the block height
+	// of a v2 policy is stored. It matches the TLV type of the field in the
+	// channel_update_2 message.
+	policyBlockHeightType = 4
+
+	// policyDisableFlagsType is the extra type under which the disable
+	// flags of a v2 policy are stored. It matches the TLV type of the field
+	// in the channel_update_2 message.
+	policyDisableFlagsType = 6
+)
+
+// gossipVersions is the set of gossip protocol versions that channels may be
+// stored under in the graph_channels table. A channel (identified by its SCID)
+// is only ever stored under a single one of these versions.
+var gossipVersions = []lnwire.GossipVersion{
+	lnwire.GossipVersion1, lnwire.GossipVersion2,
+}
+
+// getChannelBySCID fetches the channel with the given SCID, regardless of the
+// gossip version it was announced under. If no such channel exists,
+// sql.ErrNoRows is returned.
+func getChannelBySCID(ctx context.Context, db SQLQueries,
+	scid []byte) (sqlc.GraphChannel, error) {
+
+	for _, v := range gossipVersions {
+		dbChan, err := db.GetChannelBySCID(
+			ctx, sqlc.GetChannelBySCIDParams{
+				Scid:    scid,
+				Version: int16(v),
+			},
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		} else if err != nil {
+			return sqlc.GraphChannel{}, err
+		}
+
+		return dbChan, nil
+	}
+
+	return sqlc.GraphChannel{}, sql.ErrNoRows
+}
+
+// listChannelsByNodeID returns the channels of all gossip versions that the
+// node with the given DB ID is a party to.
+func listChannelsByNodeID(ctx context.Context, db SQLQueries,
+	nodeID int64) ([]sqlc.ListChannelsByNodeIDRow, error) {
+
+	var rows []sqlc.ListChannelsByNodeIDRow
+	for _, v := range gossipVersions {
+		vRows, err := db.ListChannelsByNodeID(
+			ctx, sqlc.ListChannelsByNodeIDParams{
+				Version: int16(v),
+				NodeID1: nodeID,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, vRows...)
+	}
+
+	return rows, nil
+}
+
+// fetchFundingScript returns the persisted funding script of the v2 channel
+// with the given DB ID.
+func fetchFundingScript(ctx context.Context, cfg *sqldb.QueryConfig,
+	db SQLQueries, chanID int64) ([]byte, error) {
+
+	data, err := batchLoadChannelData(ctx, cfg, db, []int64{chanID}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load channel data: %w", err)
+	}
+
+	script, ok := data.chanExtraTypes[chanID][chanFundingScriptType]
+	if !ok {
+		return nil, fmt.Errorf("funding script of channel %d not "+
+			"found", chanID)
+	}
+
+	return script, nil
+}
+
+// hasPublicV2Channel returns true if the node with the given public key has at
+// least one channel that was announced using the v2 gossip protocol.
+func hasPublicV2Channel(ctx context.Context, db SQLQueries,
+	pubKey [33]byte) (bool, error) {
+
+	nodeID, err := db.GetNodeIDByPubKey(
+		ctx, sqlc.GetNodeIDByPubKeyParams{
+			Version: int16(lnwire.GossipVersion1),
+			PubKey:  pubKey[:],
+		},
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("unable to fetch node: %w", err)
+	}
+
+	chans, err := db.ListChannelsByNodeID(
+		ctx, sqlc.ListChannelsByNodeIDParams{
+			Version: int16(lnwire.GossipVersion2),
+			NodeID1: nodeID,
+		},
+	)
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch channels: %w", err)
+	}
+
+	for _, c := range chans {
+		if len(c.GraphChannel.Node1Signature) != 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // channelIDToBytes converts a channel ID (SCID) to a byte array
 // representation.
 func channelIDToBytes(channelID uint64) []byte {
@@ -5454,13 +5880,14 @@ func forEachChannelWithPolicies(ctx context.Context, db SQLQueries,
 		policyIDs []int64
 	}
 
+	var version lnwire.GossipVersion
 	pageQueryFunc := func(ctx context.Context, lastID int64,
 		limit int32) ([]sqlc.ListChannelsWithPoliciesPaginatedRow,
 		error) {
 
 		return db.ListChannelsWithPoliciesPaginated(
 			ctx, sqlc.ListChannelsWithPoliciesPaginatedParams{
-				Version: int16(lnwire.GossipVersion1),
+				Version: int16(version),
 				ID:      lastID,
 				Limit:   limit,
 			},
@@ -5550,10 +5977,18 @@ func forEachChannelWithPolicies(ctx context.Context, db SQLQueries,
 		return processChannel(edge, p1, p2)
 	}
 
-	return sqldb.ExecuteCollectAndBatchWithSharedDataQuery(
-		ctx, cfg.QueryCfg, int64(-1), pageQueryFunc, extractPageCursor,
-		collectFunc, batchDataFunc, processItem,
-	)
+	for _, version = range gossipVersions {
+		err := sqldb.ExecuteCollectAndBatchWithSharedDataQuery(
+			ctx, cfg.QueryCfg, int64(-1), pageQueryFunc,
+			extractPageCursor, collectFunc, batchDataFunc,
+			processItem,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // buildDirectedChannel builds a DirectedChannel instance from the provided
@@ -46,6 +46,13 @@ type ChannelGraphSource interface {
 	IsStaleNode(ctx context.Context, node route.Vertex,
 		timestamp time.Time) bool
 
+	// IsStaleNodeV2 returns true if the graph source has a v2 node
+	// announcement for the target node that was signed at the same or a
+	// higher block height. This method will also return true if we don't
+	// have an active channel announcement for the target node.
+	IsStaleNodeV2(ctx context.Context, node route.Vertex,
+		blockHeight uint32) bool
+
 	// IsPublicNode determines whether the given vertex is seen as a public
 	// node in the graph from the graph's source node's point of view.
 	IsPublicNode(node route.Vertex) (bool, error)
@@ -0,0 +1,798 @@
+package htlcswitch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/channeldb"
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/crypto"
+)
+
+var (
+	// ErrDynCommitDisallowed is returned when a dynamic commitment is
+	// requested on a link whose peers didn't both signal support for the
+	// protocol.
+	ErrDynCommitDisallowed = errors.New("dynamic commitments not " +
+		"supported by both peers")
+
+	// ErrDynCommitRemoteInitiator is returned when we attempt to propose
+	// a dynamic commitment while the remote party holds the floor of the
+	// quiesced channel.
+	ErrDynCommitRemoteInitiator = errors.New("remote party is the " +
+		"quiescence initiator")
+
+	// ErrDynCommitInProgress is returned when a dynamic commitment is
+	// requested while another one is still being negotiated.
+	ErrDynCommitInProgress = errors.New("dynamic commitment negotiation " +
+		"already in progress")
+
+	// ErrDynCommitNoChange is returned when a dynamic commitment doesn't
+	// change any of the channel parameters.
+	ErrDynCommitNoChange = errors.New("dynamic commitment doesn't change " +
+		"any channel parameters")
+
+	// ErrInvalidDynAckSig is returned when the signature of a DynAck
+	// doesn't commit to the proposal it acknowledges.
+	ErrInvalidDynAckSig = errors.New("invalid DynAck signature")
+
+	// ErrDynCommitNoKickoffFeerate is returned when a taproot upgrade is
+	// proposed without the fee rate of its kickoff transaction.
+	ErrDynCommitNoKickoffFeerate = errors.New("taproot upgrade without " +
+		"kickoff fee rate")
+
+	// ErrDynHeightMismatch is returned during channel reestablishment if
+	// the parties disagree on the number of executed dynamic commitments
+	// by more than one.
+	ErrDynHeightMismatch = errors.New("dynamic commitment height mismatch")
+)
+
+// dynProposeFieldNames maps the TLV types of the DynPropose fields to their
+// names, in the order they are defined.
+var dynProposeFieldNames = []struct {
+	bit  lnwire.FeatureBit
+	name string
+}{
+	{0, "dust_limit"},
+	{2, "max_value_in_flight"},
+	{4, "htlc_minimum"},
+	{6, "channel_reserve"},
+	{8, "csv_delay"},
+	{10, "max_accepted_htlcs"},
+	{12, "channel_type"},
+	{18, "kickoff_feerate"},
+}
+
+// DynCommitReq is a request to update the parameters of a link's channel via
+// the dynamic commitments protocol. The response is nil once both commitments
+// use the new parameters.
+type DynCommitReq = fn.Req[lnwire.DynPropose, error]
+
+// DynRejectedError is returned when the remote party rejects one of our
+// dynamic commitment proposals.
+type DynRejectedError struct {
+	// Rejections holds the TLV types of the DynPropose fields the remote
+	// party rejected.
+	Rejections *lnwire.RawFeatureVector
+}
+
+// Error returns a human-readable description of the rejection.
+func (e *DynRejectedError) Error() string {
+	var fields []string
+	for _, field := range dynProposeFieldNames {
+		if e.Rejections.IsSet(field.bit) {
+			fields = append(fields, field.name)
+		}
+	}
+
+	return fmt.Sprintf("dynamic commitment rejected by peer, rejected "+
+		"fields: %v", fields)
+}
+
+// dynCommitState tracks a dynamic commitment from the proposal until both
+// commitments use the new channel parameters.
+type dynCommitState struct {
+	// initiator is the party that proposed the dynamic commitment.
+	initiator lntypes.ChannelParty
+
+	// propose is the proposal under negotiation.
+	propose lnwire.DynPropose
+
+	// params are the channel parameters that result from the proposal.
+	params channeldb.ChannelParams
+
+	// req is the local request that triggered the proposal, if we are
+	// the initiator.
+	req fn.Option[DynCommitReq]
+
+	// ackSig is the signature we sent in our DynAck, if we are the
+	// responder.
+	ackSig fn.Option[lnwire.Sig]
+
+	// committed is set once the new parameters were applied to the
+	// channel.
+	committed bool
+}
+
+// resolve sends the result of the dynamic commitment to the local requester,
+// if any.
+func (d *dynCommitState) resolve(err error) {
+	d.req.WhenSome(func(req DynCommitReq) {
+		req.Resolve(err)
+	})
+}
+
+// UpdateChannelParams quiesces the link and proposes the passed parameters to
+// the remote party. The returned channel receives nil once both commitments
+// use the new parameters, or the reason the update failed.
+//
+// NOTE: Part of the ChannelUpdateHandler interface.
+func (l *channelLink) UpdateChannelParams(
+	propose lnwire.DynPropose) <-chan error {
+
+	out := make(chan error, 1)
+	if l.cfg.DisallowDynCommitments {
+		out <- ErrDynCommitDisallowed
+		return out
+	}
+
+	go func() {
+		// We'll first need the channel to be quiescent with us as the
+		// initiator, so that we are the party that gets to propose.
+		var initiator fn.Result[lntypes.ChannelParty]
+		select {
+		case initiator = <-l.InitStfu():
+		case <-l.cg.Done():
+			out <- ErrLinkShuttingDown
+			return
+		}
+
+		party, err := initiator.Unpack()
+		if err != nil {
+			out <- err
+			return
+		}
+		if party.IsRemote() {
+			out <- ErrDynCommitRemoteInitiator
+			return
+		}
+
+		req, resp := fn.NewReq[lnwire.DynPropose, error](propose)
+		select {
+		case l.dynCommitReqs <- req:
+		case <-l.cg.Done():
+			out <- ErrLinkShuttingDown
+			return
+		}
+
+		select {
+		case err := <-resp:
+			out <- err
+		case <-l.cg.Done():
+			out <- ErrLinkShuttingDown
+		}
+	}()
+
+	return out
+}
+
+// handleDynCommitReq validates a locally initiated dynamic commitment and
+// sends the proposal to the remote party.
+func (l *channelLink) handleDynCommitReq(req DynCommitReq) error {
+	if l.dynCommit.IsSome() || l.splice.IsSome() {
+		req.Resolve(ErrDynCommitInProgress)
+		return ErrDynCommitInProgress
+	}
+
+	initiator, err := l.quiescer.QuiescenceInitiator().Unpack()
+	if err != nil {
+		req.Resolve(err)
+		return err
+	}
+	if initiator.IsRemote() {
+		req.Resolve(ErrDynCommitRemoteInitiator)
+		return ErrDynCommitRemoteInitiator
+	}
+
+	// If the proposal can't be executed, we'll release the channel right
+	// away as the remote party is waiting for us to act.
+	propose := req.Request
+	propose.ChanID = l.ChanID()
+	params, err := l.validateDynPropose(&propose, lntypes.Local)
+	if err == nil && l.isTaprootUpgrade(params) {
+		err = l.addTaprootUpgradeNonce(&propose)
+	}
+	if err != nil {
+		req.Resolve(err)
+		l.quiescer.Resume()
+
+		return err
+	}
+
+	err = l.cfg.Peer.SendMessage(false, &propose)
+	if err != nil {
+		req.Resolve(err)
+		l.quiescer.Resume()
+
+		return err
+	}
+
+	l.dynCommit = fn.Some(&dynCommitState{
+		initiator: lntypes.Local,
+		propose:   propose,
+		params:    params,
+		req:       fn.Some(req),
+	})
+
+	return nil
+}
+
+// validateDynPropose returns the channel parameters that result from the
+// passed proposal, or an error if the channel can't switch over to them.
+func (l *channelLink) validateDynPropose(propose *lnwire.DynPropose,
+	sender lntypes.ChannelParty) (channeldb.ChannelParams, error) {
+
+	params, err := l.channel.DynProposeParams(propose, sender)
+	if err != nil {
+		return params, err
+	}
+
+	if params == l.channel.State().ChannelParams() {
+		return params, ErrDynCommitNoChange
+	}
+
+	err = l.channel.ValidateDynCommitment(params, l.cfg.MaxLocalCSVDelay)
+	if err != nil {
+		return params, err
+	}
+
+	if !l.isTaprootUpgrade(params) {
+		return params, nil
+	}
+
+	// The first taproot commitments are signed with the verification
+	// nonce the proposer sends along, and spend the output of a kickoff
+	// transaction paying the proposed fee rate.
+	if sender.IsRemote() && propose.LocalNonce.IsNone() {
+		return params, lnwallet.ErrTaprootUpgradeNoNonce
+	}
+
+	feeRate, err := propose.KickoffFeerate.UnwrapOrErrV(
+		ErrDynCommitNoKickoffFeerate,
+	)
+	if err != nil {
+		return params, err
+	}
+
+	err = l.channel.ValidateTaprootUpgrade(
+		params.ChanType, chainfee.SatPerKWeight(feeRate), sender,
+	)
+	if err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+// isTaprootUpgrade returns true if the passed channel parameters move the
+// channel to taproot, which is carried out by a splice instead of a new
+// commitment.
+func (l *channelLink) isTaprootUpgrade(params channeldb.ChannelParams) bool {
+	return lnwallet.IsTaprootUpgrade(
+		l.channel.State().ChanType, params.ChanType,
+	)
+}
+
+// addTaprootUpgradeNonce adds our verification nonce for the current local
+// commitment to the passed proposal of a taproot upgrade.
+func (l *channelLink) addTaprootUpgradeNonce(propose *lnwire.DynPropose) error {
+	nonce, err := l.channel.TaprootUpgradeNonce()
+	if err != nil {
+		return err
+	}
+
+	propose.LocalNonce = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType16](nonce),
+	)
+
+	return nil
+}
+
+// handleDynPropose handles a dynamic commitment proposed by the remote party.
+// We either reject it, or acknowledge it with a signature that commits to the
+// proposal.
+func (l *channelLink) handleDynPropose(msg *lnwire.DynPropose) error {
+	initiator, err := l.quiescer.QuiescenceInitiator().Unpack()
+	if err != nil {
+		return err
+	}
+	if initiator.IsLocal() || l.dynCommit.IsSome() {
+		return fmt.Errorf("DynPropose received while not holding " +
+			"the floor")
+	}
+
+	var params channeldb.ChannelParams
+	if l.cfg.DisallowDynCommitments {
+		err = ErrDynCommitDisallowed
+	} else {
+		params, err = l.validateDynPropose(msg, lntypes.Remote)
+	}
+	if err != nil {
+		l.log.Infof("Rejecting dynamic commitment: %v", err)
+
+		reject := &lnwire.DynReject{
+			ChanID:           l.ChanID(),
+			UpdateRejections: *dynProposeFields(msg),
+		}
+		l.quiescer.Resume()
+
+		return l.cfg.Peer.SendMessage(false, reject)
+	}
+
+	sig, err := l.signDynAck(msg)
+	if err != nil {
+		return err
+	}
+
+	ack := &lnwire.DynAck{
+		ChanID: l.ChanID(),
+		Sig:    sig,
+	}
+	if l.isTaprootUpgrade(params) {
+		nonce, err := l.channel.TaprootUpgradeNonce()
+		if err != nil {
+			return err
+		}
+
+		ack.LocalNonce = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType14](nonce),
+		)
+	}
+
+	err = l.cfg.Peer.SendMessage(false, ack)
+	if err != nil {
+		return err
+	}
+
+	l.dynCommit = fn.Some(&dynCommitState{
+		initiator: lntypes.Remote,
+		propose:   *msg,
+		params:    params,
+		ackSig:    fn.Some(sig),
+	})
+
+	return nil
+}
+
+// handleDynAck handles the remote party's acceptance of our proposal. We'll
+// persist the new parameters, send the DynCommit and start signing the first
+// commitment that uses them.
+func (l *channelLink) handleDynAck(ctx context.Context,
+	msg *lnwire.DynAck) error {
+
+	dyn, err := l.dynCommit.UnwrapOrErr(
+		fmt.Errorf("DynAck received without a proposal"),
+	)
+	if err != nil {
+		return err
+	}
+	if dyn.initiator.IsRemote() || dyn.committed {
+		return fmt.Errorf("DynAck received out of order")
+	}
+
+	remoteKey := l.channel.State().RemoteChanCfg.MultiSigKey.PubKey
+	err = verifyDynAck(&dyn.propose, msg.Sig, remoteKey)
+	if err != nil {
+		return err
+	}
+
+	commit := &lnwire.DynCommit{
+		DynPropose: dyn.propose,
+		DynAck:     *msg,
+	}
+
+	// A taproot upgrade isn't persisted as a dynamic commitment, it takes
+	// effect once the splice moving the channel to its new funding output
+	// is locked.
+	if l.isTaprootUpgrade(dyn.params) {
+		remoteNonce, err := msg.LocalNonce.UnwrapOrErrV(
+			lnwallet.ErrTaprootUpgradeNoNonce,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = l.cfg.Peer.SendMessage(false, commit)
+		if err != nil {
+			return err
+		}
+
+		return l.startTaprootUpgrade(dyn, remoteNonce)
+	}
+
+	var b bytes.Buffer
+	if _, err := lnwire.WriteMessage(&b, commit, 0); err != nil {
+		return err
+	}
+
+	err = l.channel.ApplyDynCommitment(dyn.params, b.Bytes())
+	if err != nil {
+		return err
+	}
+	dyn.committed = true
+
+	err = l.cfg.Peer.SendMessage(false, commit)
+	if err != nil {
+		l.log.Errorf("failed to send DynCommit: %v", err)
+	}
+
+	l.updateCommitTxOrFail(ctx)
+
+	return nil
+}
+
+// handleDynReject handles the remote party's rejection of our proposal.
+func (l *channelLink) handleDynReject(msg *lnwire.DynReject) error {
+	dyn, err := l.dynCommit.UnwrapOrErr(
+		fmt.Errorf("DynReject received without a proposal"),
+	)
+	if err != nil {
+		return err
+	}
+	if dyn.initiator.IsRemote() || dyn.committed {
+		return fmt.Errorf("DynReject received out of order")
+	}
+
+	dyn.resolve(&DynRejectedError{
+		Rejections: &msg.UpdateRejections,
+	})
+	l.dynCommit = fn.None[*dynCommitState]()
+	l.quiescer.Resume()
+
+	return nil
+}
+
+// handleDynCommit handles the DynCommit with which the remote party executes
+// a proposal we acknowledged. This is either the proposal we are currently
+// negotiating, or one that we acknowledged before the connection was lost.
+func (l *channelLink) handleDynCommit(msg *lnwire.DynCommit) error {
+	var (
+		params channeldb.ChannelParams
+		err    error
+	)
+	switch dyn, ok := l.dynCommit.UnwrapOr(nil), l.dynCommit.IsSome(); {
+	case ok && dyn.initiator.IsRemote() && !dyn.committed:
+		proposed, err := dyn.propose.SerializeTlvData()
+		if err != nil {
+			return err
+		}
+		committed, err := msg.DynPropose.SerializeTlvData()
+		if err != nil {
+			return err
+		}
+
+		sig := dyn.ackSig.UnwrapOr(lnwire.Sig{})
+		if !bytes.Equal(proposed, committed) || sig != msg.DynAck.Sig {
+			return fmt.Errorf("DynCommit doesn't match the " +
+				"acknowledged proposal")
+		}
+
+		params = dyn.params
+
+		if l.isTaprootUpgrade(params) {
+			remoteNonce, err := msg.DynPropose.LocalNonce.
+				UnwrapOrErrV(lnwallet.ErrTaprootUpgradeNoNonce)
+			if err != nil {
+				return err
+			}
+
+			return l.startTaprootUpgrade(dyn, remoteNonce)
+		}
+
+	// If the connection dropped after we sent our DynAck, the remote
+	// party retransmits the DynCommit on reestablish. We'll check that it
+	// carries our own signature before applying it.
+	case !ok && l.dynCommitReplay:
+		localKey := l.channel.State().LocalChanCfg.MultiSigKey.PubKey
+		err = verifyDynAck(&msg.DynPropose, msg.DynAck.Sig, localKey)
+		if err != nil {
+			return err
+		}
+
+		params, err = l.channel.DynProposeParams(
+			&msg.DynPropose, lntypes.Remote,
+		)
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unexpected DynCommit")
+	}
+
+	var b bytes.Buffer
+	if _, err := lnwire.WriteMessage(&b, msg, 0); err != nil {
+		return err
+	}
+
+	err = l.channel.ApplyDynCommitment(params, b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	l.dynCommitReplay = false
+	l.dynCommit = fn.Some(&dynCommitState{
+		initiator: lntypes.Remote,
+		propose:   msg.DynPropose,
+		params:    params,
+		committed: true,
+	})
+
+	return nil
+}
+
+// startTaprootUpgrade hands an acknowledged taproot upgrade over to the
+// splice that moves the channel to its MuSig2 funding output. Both parties
+// build the same kickoff transaction, so the splice starts out with the
+// exchange of the partial signatures for the first taproot commitments.
+func (l *channelLink) startTaprootUpgrade(dyn *dynCommitState,
+	remoteNonce lnwire.Musig2Nonce) error {
+
+	feeRate, err := dyn.propose.KickoffFeerate.UnwrapOrErrV(
+		ErrDynCommitNoKickoffFeerate,
+	)
+	if err != nil {
+		return err
+	}
+
+	tx, prevOuts, err := l.channel.TaprootUpgradeTx(
+		chainfee.SatPerKWeight(feeRate),
+	)
+	if err != nil {
+		return err
+	}
+
+	commits, err := l.channel.SignTaprootUpgradeCommitments(
+		tx, dyn.params.ChanType, dyn.initiator, remoteNonce,
+	)
+	if err != nil {
+		return err
+	}
+	partialSig, err := commits.RemotePartialSig.UnwrapOrErr(
+		lnwallet.ErrNotTaprootUpgrade,
+	)
+	if err != nil {
+		return err
+	}
+
+	l.log.Infof("Upgrading channel to %v with kickoff transaction %v",
+		dyn.params.ChanType, tx.TxHash())
+
+	l.dynCommit = fn.None[*dynCommitState]()
+	l.splice = fn.Some(&spliceState{
+		initiator:  dyn.initiator,
+		stage:      spliceSigning,
+		heightHint: l.cfg.BestHeight(),
+		tx:         tx,
+		prevOuts:   prevOuts,
+		commits:    commits,
+		upgrade:    fn.Some(dyn),
+	})
+
+	return l.cfg.Peer.SendMessage(false, &lnwire.CommitSig{
+		ChanID:     l.ChanID(),
+		PartialSig: lnwire.MaybePartialSigWithNonce(&partialSig),
+	})
+}
+
+// maybeFinishDynCommitment completes the active dynamic commitment once both
+// commitments use the new parameters.
+func (l *channelLink) maybeFinishDynCommitment() {
+	dyn, ok := l.dynCommit.UnwrapOr(nil), l.dynCommit.IsSome()
+	if !ok || !dyn.committed || l.channel.DynCommitmentPending() {
+		return
+	}
+
+	state := l.channel.State()
+	l.log.Infof("Dynamic commitment completed, channel type is now %v",
+		state.ChanType)
+
+	// Later states will need to be backed up using the justice kit of
+	// the new channel type.
+	if l.cfg.TowerClient != nil {
+		err := l.cfg.TowerClient.RegisterChannel(
+			l.ChanID(), state.ChanType,
+		)
+		if err != nil {
+			l.log.Errorf("Unable to register channel type %v with "+
+				"tower client: %v", state.ChanType, err)
+		}
+	}
+
+	l.dynCommit = fn.None[*dynCommitState]()
+	l.quiescer.Resume()
+	dyn.resolve(nil)
+
+	if l.cfg.NotifyChannelParamsUpdate != nil {
+		l.cfg.NotifyChannelParamsUpdate(state)
+	}
+}
+
+// syncDynCommitments compares the number of dynamic commitments both parties
+// executed and retransmits our last DynCommit if the remote party didn't
+// process it before the connection was lost.
+func (l *channelLink) syncDynCommitments(
+	remoteSync *lnwire.ChannelReestablish) error {
+
+	local := lnwire.DynHeight(len(l.channel.State().DynCommitments))
+	remote := remoteSync.DynHeight.UnwrapOr(0)
+
+	switch {
+	case local == remote:
+		return nil
+
+	// We've committed a dynamic commitment the remote party never
+	// received, so we'll retransmit it.
+	case local == remote+1:
+		// A taproot upgrade is recorded once its splice is locked, which
+		// is synced along with the splice.
+		last, _ := l.channel.State().LastDynCommitment()
+		if len(last.Msg) == 0 {
+			return nil
+		}
+
+		msg, err := lnwire.ReadMessage(bytes.NewReader(last.Msg), 0)
+		if err != nil {
+			return err
+		}
+
+		l.log.Infof("Retransmitting DynCommit")
+
+		return l.cfg.Peer.SendMessage(false, msg)
+
+	// The remote party executed a dynamic commitment we acknowledged, but
+	// we never received their DynCommit. They'll retransmit it.
+	case local+1 == remote:
+		// If the remote party locked the splice of a taproot upgrade
+		// before us, there's no DynCommit to replay.
+		pending := l.channel.PendingSplice().UnwrapOr(chanstate.Splice{})
+		if pending.IsUpgrade() {
+			return nil
+		}
+
+		l.dynCommitReplay = true
+
+		return nil
+
+	default:
+		return fmt.Errorf("%w: local dyn height %d, remote dyn "+
+			"height %d", ErrDynHeightMismatch, local, remote)
+	}
+}
+
+// resumeDynCommitment makes sure a dynamic commitment that was interrupted by
+// a disconnect is completed.
+func (l *channelLink) resumeDynCommitment(ctx context.Context) {
+	if !l.channel.DynCommitmentPending() {
+		return
+	}
+
+	l.dynCommit = fn.Some(&dynCommitState{
+		params:    l.channel.State().ChannelParams(),
+		committed: true,
+	})
+
+	if l.channel.OweCommitment() {
+		l.updateCommitTxOrFail(ctx)
+	}
+}
+
+// dynFailf fails the link in the case where the requirements of the dynamic
+// commitments protocol are violated. As with quiescence violations, we drop
+// the connection, which resets the negotiation on both sides.
+func (l *channelLink) dynFailf(format string, args ...interface{}) {
+	l.failf(LinkFailureError{
+		code:             ErrDynCommitViolation,
+		FailureAction:    LinkFailureDisconnect,
+		PermanentFailure: false,
+		Warning:          true,
+	}, format, args...)
+}
+
+// dynProposeFields returns the TLV types of the fields that are set in the
+// passed proposal as a bit vector.
+func dynProposeFields(msg *lnwire.DynPropose) *lnwire.RawFeatureVector {
+	fields := lnwire.NewRawFeatureVector()
+	setIf := func(isSome bool, tlvType uint64) {
+		if isSome {
+			fields.Set(lnwire.FeatureBit(tlvType))
+		}
+	}
+
+	setIf(msg.DustLimit.IsSome(), uint64(msg.DustLimit.TlvType()))
+	setIf(
+		msg.MaxValueInFlight.IsSome(),
+		uint64(msg.MaxValueInFlight.TlvType()),
+	)
+	setIf(msg.HtlcMinimum.IsSome(), uint64(msg.HtlcMinimum.TlvType()))
+	setIf(
+		msg.ChannelReserve.IsSome(),
+		uint64(msg.ChannelReserve.TlvType()),
+	)
+	setIf(msg.CsvDelay.IsSome(), uint64(msg.CsvDelay.TlvType()))
+	setIf(
+		msg.MaxAcceptedHTLCs.IsSome(),
+		uint64(msg.MaxAcceptedHTLCs.TlvType()),
+	)
+	setIf(msg.ChannelType.IsSome(), uint64(msg.ChannelType.TlvType()))
+	setIf(
+		msg.KickoffFeerate.IsSome(),
+		uint64(msg.KickoffFeerate.TlvType()),
+	)
+
+	return fields
+}
+
+// dynAckDigest returns the message a DynAck signature commits to.
+func dynAckDigest(propose *lnwire.DynPropose) ([]byte, error) {
+	tlvData, err := propose.SerializeTlvData()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("dyn_ack")
+	b.Write(propose.ChanID[:])
+	b.Write(tlvData)
+
+	return b.Bytes(), nil
+}
+
+// signDynAck signs the passed proposal with our funding key.
+func (l *channelLink) signDynAck(propose *lnwire.DynPropose) (lnwire.Sig,
+	error) {
+
+	digest, err := dynAckDigest(propose)
+	if err != nil {
+		return lnwire.Sig{}, err
+	}
+
+	keyLoc := l.channel.State().LocalChanCfg.MultiSigKey.KeyLocator
+	sig, err := l.cfg.MessageSigner.SignMessage(keyLoc, digest, false)
+	if err != nil {
+		return lnwire.Sig{}, err
+	}
+
+	return lnwire.NewSigFromSignature(sig)
+}
+
+// verifyDynAck checks that the passed signature commits to the proposal and
+// was created by the given funding key.
+func verifyDynAck(propose *lnwire.DynPropose, sig lnwire.Sig,
+	pubKey *crypto.PublicKey) error {
+
+	digest, err := dynAckDigest(propose)
+	if err != nil {
+		return err
+	}
+
+	signature, err := sig.ToSignature()
+	if err != nil {
+		return err
+	}
+
+	if !signature.Verify(chainhash.HashB(digest), pubKey) {
+		return ErrInvalidDynAckSig
+	}
+
+	return nil
+}
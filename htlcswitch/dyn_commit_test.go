@@ -0,0 +1,187 @@
+package htlcswitch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/stretchr/testify/require"
+)
+
+// waitDynCommit waits for the result of a dynamic commitment request.
+func waitDynCommit(t *testing.T, resp <-chan error) error {
+	t.Helper()
+
+	select {
+	case err := <-resp:
+		return err
+
+	case <-time.After(10 * time.Second):
+		t.Fatalf("dynamic commitment timed out")
+		return nil
+	}
+}
+
+// TestLinkDynCommitment asserts that two links can upgrade their channel to
+// anchors via the dynamic commitments protocol, and that the links can still
+// forward payments afterwards.
+func TestLinkDynCommitment(t *testing.T) {
+	t.Parallel()
+
+	alice, bob, err := createMirroredChannel(
+		t, chainutil.LokiPerFlokicoin, chainutil.LokiPerFlokicoin,
+	)
+	require.NoError(t, err)
+
+	network := newTwoHopNetwork(
+		t, alice.channel, bob.channel, testStartingHeight,
+	)
+	aliceLink := network.aliceChannelLink
+	bobLink := network.bobChannelLink
+
+	propose := lnwire.DynPropose{
+		ChanID: aliceLink.ChanID(),
+		ChannelType: tlv.SomeRecordT(tlv.NewRecordT[tlv.TlvType12](
+			lnwire.ChannelType(*lnwire.NewRawFeatureVector(
+				lnwire.StaticRemoteKeyRequired,
+				lnwire.AnchorsZeroFeeHtlcTxRequired,
+			)),
+		)),
+	}
+	err = waitDynCommit(t, aliceLink.UpdateChannelParams(propose))
+	require.NoError(t, err)
+
+	// Bob finishes the dynamic commitment with the revocation of Alice's
+	// reply, so we wait for his side to catch up.
+	require.Eventually(t, func() bool {
+		return !bob.channel.DynCommitmentPending()
+	}, 5*time.Second, 50*time.Millisecond)
+
+	for _, link := range []*channelLink{aliceLink, bobLink} {
+		state := link.channel.State()
+		require.True(t, state.ChanType.HasAnchors())
+		require.Len(t, state.LocalCommitment.CommitTx.TxOut, 4)
+		require.False(t, link.IsFlushing(Outgoing))
+	}
+
+	// With the links resumed, Alice can pay Bob again.
+	amount := lnwire.NewMSatFromLokis(10_000)
+	htlcAmt, totalTimelock, hops := generateHops(
+		amount, testStartingHeight, bobLink,
+	)
+	firstHop := bobLink.ShortChanID()
+	_, err = makePayment(
+		network.aliceServer, network.bobServer, firstHop, hops, amount,
+		htlcAmt, totalTimelock,
+	).Wait(30 * time.Second)
+	require.NoError(t, err)
+}
+
+// TestLinkDynCommitmentRejected asserts that a proposal that violates the
+// limits of the remote party is rejected, and that the links resume
+// afterwards.
+func TestLinkDynCommitmentRejected(t *testing.T) {
+	t.Parallel()
+
+	alice, bob, err := createMirroredChannel(
+		t, chainutil.LokiPerFlokicoin, chainutil.LokiPerFlokicoin,
+	)
+	require.NoError(t, err)
+
+	network := newTwoHopNetwork(
+		t, alice.channel, bob.channel, testStartingHeight,
+	)
+	aliceLink := network.aliceChannelLink
+
+	// Bob only accepts a csv delay of up to 1000 blocks on his outputs.
+	propose := lnwire.DynPropose{
+		ChanID: aliceLink.ChanID(),
+		CsvDelay: tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType8, uint16](2000),
+		),
+	}
+	err = waitDynCommit(t, aliceLink.UpdateChannelParams(propose))
+
+	var rejected *DynRejectedError
+	require.ErrorAs(t, err, &rejected)
+	require.True(t, rejected.Rejections.IsSet(8))
+
+	// A proposal without any change is refused locally.
+	err = waitDynCommit(t, aliceLink.UpdateChannelParams(
+		lnwire.DynPropose{ChanID: aliceLink.ChanID()},
+	))
+	require.ErrorIs(t, err, ErrDynCommitNoChange)
+
+	state := alice.channel.State()
+	require.False(t, state.ChanType.HasAnchors())
+	require.False(t, alice.channel.DynCommitmentPending())
+}
+
+// TestLinkTaprootUpgrade asserts that two links can upgrade their channel to
+// taproot by moving it to a MuSig2 funding output, and that the links can
+// still forward payments once the upgrade is locked.
+func TestLinkTaprootUpgrade(t *testing.T) {
+	t.Parallel()
+
+	alice, bob, err := createMirroredChannel(
+		t, chainutil.LokiPerFlokicoin, chainutil.LokiPerFlokicoin,
+	)
+	require.NoError(t, err)
+
+	network := newTwoHopNetwork(
+		t, alice.channel, bob.channel, testStartingHeight,
+	)
+	aliceLink := network.aliceChannelLink
+	bobLink := network.bobChannelLink
+
+	oldCapacity := alice.channel.State().Capacity
+
+	propose := lnwire.DynPropose{
+		ChanID: aliceLink.ChanID(),
+		ChannelType: tlv.SomeRecordT(tlv.NewRecordT[tlv.TlvType12](
+			lnwire.ChannelType(*lnwire.NewRawFeatureVector(
+				lnwire.SimpleTaprootChannelsRequiredFinal,
+			)),
+		)),
+		KickoffFeerate: tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType18, uint32](1000),
+		),
+	}
+	err = waitDynCommit(t, aliceLink.UpdateChannelParams(propose))
+	require.NoError(t, err)
+
+	// Both parties publish the kickoff transaction, which only spends the
+	// current funding output.
+	kickoffTx := waitSplicePublished(t, aliceLink)
+	require.Equal(
+		t, kickoffTx.TxHash(), waitSplicePublished(t, bobLink).TxHash(),
+	)
+	require.Len(t, kickoffTx.TxIn, 1)
+	require.True(t, bob.channel.SplicePending())
+
+	confirmSplice(t, kickoffTx, aliceLink, bobLink)
+
+	for _, link := range []*channelLink{aliceLink, bobLink} {
+		state := link.channel.State()
+		require.True(t, state.ChanType.IsTaproot())
+		require.Equal(t, kickoffTx.TxHash(), state.FundingTxOutpoint().Hash)
+		require.Less(t, state.Capacity, oldCapacity)
+
+		require.Eventually(t, link.channel.HasRemoteNonces,
+			5*time.Second, 50*time.Millisecond)
+	}
+
+	// With the upgrade locked, Alice can pay Bob again.
+	amount := lnwire.NewMSatFromLokis(10_000)
+	htlcAmt, totalTimelock, hops := generateHops(
+		amount, testStartingHeight, bobLink,
+	)
+	firstHop := bobLink.ShortChanID()
+	_, err = makePayment(
+		network.aliceServer, network.bobServer, firstHop, hops, amount,
+		htlcAmt, totalTimelock,
+	).Wait(30 * time.Second)
+	require.NoError(t, err)
+}
@@ -54,6 +54,11 @@ const (
 	// OutgoingFailureForwardsDisabled is returned when the switch is
 	// configured to disallow forwards.
 	OutgoingFailureForwardsDisabled
+
+	// OutgoingFailureInsufficientResources is returned when the resource
+	// manager declines to lock up the resources of the incoming channel
+	// for a forward.
+	OutgoingFailureInsufficientResources
 )
 
 // FailureString returns the string representation of a failure detail.
@@ -91,6 +96,9 @@ func (fd OutgoingFailure) FailureString() string {
 	case OutgoingFailureForwardsDisabled:
 		return "node configured to disallow forwards"
 
+	case OutgoingFailureInsufficientResources:
+		return "insufficient resources to forward htlc"
+
 	default:
 		return "unknown failure detail"
 	}
@@ -61,6 +61,23 @@ type offChainHeld struct {
 	// autoFailHeight is the block height at which the held off-chain HTLC
 	// must be failed back to avoid forcing the incoming channel closed.
 	autoFailHeight uint32
+
+	// isReplay indicates whether the HTLC was replayed by its incoming
+	// link, in which case it may have been offered to the interceptors
+	// before and must not be failed back without their consent.
+	isReplay bool
+
+	// stage is the interceptor the HTLC is currently offered to. It is nil
+	// if the HTLC hasn't been offered to any interceptor yet.
+	stage *InterceptorRegistration
+
+	// offers is the number of times the HTLC was offered to an
+	// interceptor, used to tell apart the timeouts of the offers.
+	offers uint64
+
+	// mods are the modifications requested by the interceptors that
+	// already resumed the HTLC.
+	mods fwdModifications
 }
 
 // Assert that offChainHeld implements heldEntry.
@@ -98,9 +115,16 @@ func (h *offChainHeld) interceptedForward() InterceptedForward {
 }
 
 // release resumes the held off-chain HTLC into the normal link forwarding
-// flow.
+// flow, applying the modifications requested by the interceptors.
 func (h *offChainHeld) release() error {
-	return h.fwd.Resume()
+	if !h.mods.modified() {
+		return h.fwd.Resume()
+	}
+
+	return h.fwd.ResumeModified(
+		h.mods.inAmountMsat, h.mods.outAmountMsat,
+		h.mods.outWireCustomRecords,
+	)
 }
 
 // resolve applies an interceptor resolution to the held off-chain HTLC.
@@ -243,14 +267,6 @@ func newHeldHtlcSet() *heldHtlcSet {
 	}
 }
 
-// forEach iterates over all held forwards and calls the given callback for each
-// of them.
-func (h *heldHtlcSet) forEach(cb func(InterceptedForward)) {
-	for _, entry := range h.set {
-		cb(entry.interceptedForward())
-	}
-}
-
 // releaseAllOffChainHeld releases off-chain entries when the optional
 // interceptor disconnects. On-chain entries are kept because there is no link
 // flow to resume, preserving the replay/settle handle while contractcourt waits
@@ -334,6 +350,13 @@ func (h *heldHtlcSet) resolve(res *FwdResolution) error {
 	return nil
 }
 
+// offChain returns the off-chain held entry of the given forward, if any.
+func (h *heldHtlcSet) offChain(key models.CircuitKey) (*offChainHeld, bool) {
+	entry, ok := h.set[key].(*offChainHeld)
+
+	return entry, ok
+}
+
 // exists tests whether the specified forward is part of the set.
 func (h *heldHtlcSet) exists(key models.CircuitKey) bool {
 	_, ok := h.set[key]
@@ -70,11 +70,11 @@ func (m *mockInterceptedForward) Resume() error {
 // ResumeModified records a modified resume call and returns the configured
 // return error.
 func (m *mockInterceptedForward) ResumeModified(
-	_ fn.Option[lnwire.MilliLoki],
-	_ fn.Option[lnwire.MilliLoki],
-	_ fn.Option[lnwire.CustomRecords]) error {
+	inAmountMsat fn.Option[lnwire.MilliLoki],
+	outAmountMsat fn.Option[lnwire.MilliLoki],
+	outWireCustomRecords fn.Option[lnwire.CustomRecords]) error {
 
-	args := m.Called()
+	args := m.Called(inAmountMsat, outAmountMsat, outWireCustomRecords)
 
 	return args.Error(0)
 }
@@ -411,8 +411,8 @@ func TestInterceptableSwitchForwardOnChain(t *testing.T) {
 	t.Run("fresh on-chain htlc is sent", func(t *testing.T) {
 		s := &InterceptableSwitch{
 			heldHtlcSet: newHeldHtlcSet(),
-			interceptor: interceptor,
 		}
+		s.setInterceptor(interceptor)
 		fwd := newMockOnChainInterceptedForward(key, 100)
 
 		require.NoError(t, s.interceptOnChain(fwd))
@@ -425,8 +425,8 @@ func TestInterceptableSwitchForwardOnChain(t *testing.T) {
 			intercepted = nil
 			s := &InterceptableSwitch{
 				heldHtlcSet: newHeldHtlcSet(),
-				interceptor: interceptor,
 			}
+			s.setInterceptor(interceptor)
 			offChain := newMockInterceptedForward(key, 80)
 			onChain := newMockOnChainInterceptedForward(key, 100)
 			onChain.On("Settle", lntypes.Preimage{}).Return(
@@ -453,8 +453,8 @@ func TestInterceptableSwitchForwardOnChain(t *testing.T) {
 		intercepted = nil
 		s := &InterceptableSwitch{
 			heldHtlcSet: newHeldHtlcSet(),
-			interceptor: interceptor,
 		}
+		s.setInterceptor(interceptor)
 		fwd := newMockOnChainInterceptedForward(key, 100)
 		fwd.On("Settle", lntypes.Preimage{}).Return(nil).Once()
 
@@ -481,8 +481,8 @@ func TestInterceptableSwitchForwardOnChain(t *testing.T) {
 		intercepted = nil
 		s := &InterceptableSwitch{
 			heldHtlcSet: newHeldHtlcSet(),
-			interceptor: interceptor,
 		}
+		s.setInterceptor(interceptor)
 		fwd := newMockOnChainInterceptedForward(key, 100)
 
 		require.NoError(t, s.interceptOnChain(fwd))
@@ -497,8 +497,8 @@ func TestInterceptableSwitchForwardOnChain(t *testing.T) {
 		intercepted = nil
 		s := &InterceptableSwitch{
 			heldHtlcSet: newHeldHtlcSet(),
-			interceptor: interceptor,
 		}
+		s.setInterceptor(interceptor)
 		fwd := newMockOnChainInterceptedForward(key, 100)
 
 		require.NoError(t, s.interceptOnChain(fwd))
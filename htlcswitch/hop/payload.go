@@ -118,6 +118,10 @@ type Payload struct {
 	// totalAmtMsat holds the info provided in total_amount_msat when
 	// parsed from a TLV onion payload.
 	totalAmtMsat lnwire.MilliLoki
+
+	// trampolineOnion is the onion packet delivered to the final hop if
+	// it's used as a trampoline node.
+	trampolineOnion []byte
 }
 
 // NewLegacyPayload builds a Payload from the amount, cltv, and next hop
@@ -140,15 +144,16 @@ func NewLegacyPayload(f *sphinx.HopData) *Payload {
 // does not perform validation of TLV types included in the payload.
 func ParseTLVPayload(r io.Reader) (*Payload, map[tlv.Type][]byte, error) {
 	var (
-		cid           uint64
-		amt           uint64
-		totalAmtMsat  uint64
-		cltv          uint32
-		mpp           = &record.MPP{}
-		amp           = &record.AMP{}
-		encryptedData []byte
-		blindingPoint *crypto.PublicKey
-		metadata      []byte
+		cid             uint64
+		amt             uint64
+		totalAmtMsat    uint64
+		cltv            uint32
+		mpp             = &record.MPP{}
+		amp             = &record.AMP{}
+		encryptedData   []byte
+		blindingPoint   *crypto.PublicKey
+		metadata        []byte
+		trampolineOnion []byte
 	)
 
 	tlvStream, err := tlv.NewStream(
@@ -161,6 +166,7 @@ func ParseTLVPayload(r io.Reader) (*Payload, map[tlv.Type][]byte, error) {
 		amp.Record(),
 		record.NewMetadataRecord(&metadata),
 		record.NewTotalAmtMsatBlinded(&totalAmtMsat),
+		record.NewTrampolineOnionRecord(&trampolineOnion),
 	)
 	if err != nil {
 		return nil, nil, err
@@ -197,6 +203,12 @@ func ParseTLVPayload(r io.Reader) (*Payload, map[tlv.Type][]byte, error) {
 		metadata = nil
 	}
 
+	// If no trampoline onion was parsed, set the field on our resulting
+	// payload to nil.
+	if _, ok := parsedTypes[record.TrampolineOnionType]; !ok {
+		trampolineOnion = nil
+	}
+
 	// Filter out the custom records.
 	customRecords := NewCustomRecords(parsedTypes)
 
@@ -206,13 +218,14 @@ func ParseTLVPayload(r io.Reader) (*Payload, map[tlv.Type][]byte, error) {
 			AmountToForward: lnwire.MilliLoki(amt),
 			OutgoingCLTV:    cltv,
 		},
-		MPP:           mpp,
-		AMP:           amp,
-		metadata:      metadata,
-		encryptedData: encryptedData,
-		blindingPoint: blindingPoint,
-		customRecords: customRecords,
-		totalAmtMsat:  lnwire.MilliLoki(totalAmtMsat),
+		MPP:             mpp,
+		AMP:             amp,
+		metadata:        metadata,
+		encryptedData:   encryptedData,
+		blindingPoint:   blindingPoint,
+		customRecords:   customRecords,
+		totalAmtMsat:    lnwire.MilliLoki(totalAmtMsat),
+		trampolineOnion: trampolineOnion,
 	}, parsedTypes, nil
 }
 
@@ -276,6 +289,7 @@ func ValidateParsedPayloadTypes(parsedTypes tlv.TypeMap,
 	_, hasAMP := parsedTypes[record.AMPOnionType]
 	_, hasEncryptedData := parsedTypes[record.EncryptedDataOnionType]
 	_, hasBlinding := parsedTypes[record.BlindingPointOnionType]
+	_, hasTrampoline := parsedTypes[record.TrampolineOnionType]
 
 	// All cleartext hops (including final hop) and the final hop in a
 	// blinded path require the forwading amount and expiry TLVs to be set.
@@ -376,6 +390,14 @@ func ValidateParsedPayloadTypes(parsedTypes tlv.TypeMap,
 			Violation: IncludedViolation,
 			FinalHop:  isFinalHop,
 		}
+
+	// Intermediate nodes should never receive trampoline onions.
+	case !isFinalHop && hasTrampoline:
+		return ErrInvalidPayload{
+			Type:      record.TrampolineOnionType,
+			Violation: IncludedViolation,
+			FinalHop:  isFinalHop,
+		}
 	}
 
 	return nil
@@ -428,6 +450,12 @@ func (h *Payload) TotalAmtMsat() lnwire.MilliLoki {
 	return h.totalAmtMsat
 }
 
+// TrampolineOnion returns the trampoline onion parsed from the onion payload,
+// if the final hop is used as a trampoline node.
+func (h *Payload) TrampolineOnion() []byte {
+	return h.trampolineOnion
+}
+
 // getMinRequiredViolation checks for unrecognized required (even) fields in the
 // standard range and returns the lowest required type. Always returning the
 // lowest required type allows a failure message to be deterministic.
@@ -0,0 +1,163 @@
+package hop
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/record"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	sphinx "github.com/flokiorg/lightning-onion"
+)
+
+// TrampolinePayload encapsulates the information delivered to a trampoline
+// node in its layer of a trampoline onion.
+type TrampolinePayload struct {
+	// AmountToForward is the amount the trampoline node should deliver to
+	// the outgoing node, or the amount of the payment if the trampoline
+	// node is the recipient.
+	AmountToForward lnwire.MilliLoki
+
+	// OutgoingCLTV is the expiry the trampoline node should deliver the
+	// payment to the outgoing node with.
+	OutgoingCLTV uint32
+
+	// OutgoingNodeID is the node the trampoline node should relay the
+	// payment to. It's nil if the trampoline node is the recipient.
+	OutgoingNodeID *crypto.PublicKey
+
+	// MPP holds the payment address and total amount of the payment. It's
+	// only set for the last layer of the onion, either for the recipient
+	// or for a trampoline node that pays a recipient which doesn't
+	// understand trampoline onions.
+	MPP *record.MPP
+
+	// NextOnion is the trampoline onion that should be delivered to the
+	// outgoing node. It's nil for the last layer of the onion.
+	NextOnion []byte
+}
+
+// ParseTrampolinePayload parses and validates the payload of a trampoline
+// onion layer from the passed io.Reader. The finalHop bool should be true if
+// the payload is the last layer of the onion.
+func ParseTrampolinePayload(r io.Reader, finalHop bool) (*TrampolinePayload,
+	error) {
+
+	var (
+		amt          uint64
+		cltv         uint32
+		mpp          = &record.MPP{}
+		outgoingNode *crypto.PublicKey
+	)
+
+	tlvStream, err := tlv.NewStream(
+		record.NewAmtToFwdRecord(&amt),
+		record.NewLockTimeRecord(&cltv),
+		mpp.Record(),
+		record.NewOutgoingNodeIDRecord(&outgoingNode),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedTypes, err := tlvStream.DecodeWithParsedTypesP2P(r)
+	if err != nil {
+		return nil, err
+	}
+
+	_, hasAmt := parsedTypes[record.AmtOnionType]
+	_, hasLockTime := parsedTypes[record.LockTimeOnionType]
+	_, hasMPP := parsedTypes[record.MPPOnionType]
+	_, hasOutgoingNode := parsedTypes[record.OutgoingNodeIDOnionType]
+
+	violation := func(t tlv.Type, v PayloadViolation) error {
+		return ErrInvalidPayload{
+			Type:      t,
+			Violation: v,
+			FinalHop:  finalHop,
+		}
+	}
+
+	switch {
+	case !hasAmt:
+		return nil, violation(record.AmtOnionType, OmittedViolation)
+
+	case !hasLockTime:
+		return nil, violation(
+			record.LockTimeOnionType, OmittedViolation,
+		)
+
+	// Only the last layer of the onion can omit the outgoing node, as
+	// there's no next trampoline node to relay the payment to.
+	case !finalHop && !hasOutgoingNode:
+		return nil, violation(
+			record.OutgoingNodeIDOnionType, OmittedViolation,
+		)
+
+	// The last layer of the onion pays the recipient, which requires its
+	// payment address.
+	case finalHop && !hasMPP:
+		return nil, violation(record.MPPOnionType, OmittedViolation)
+
+	case !finalHop && hasMPP:
+		return nil, violation(record.MPPOnionType, IncludedViolation)
+	}
+
+	violatingType := getMinRequiredViolation(parsedTypes)
+	if violatingType != nil {
+		return nil, violation(*violatingType, RequiredViolation)
+	}
+
+	if !hasMPP {
+		mpp = nil
+	}
+
+	return &TrampolinePayload{
+		AmountToForward: lnwire.MilliLoki(amt),
+		OutgoingCLTV:    cltv,
+		OutgoingNodeID:  outgoingNode,
+		MPP:             mpp,
+	}, nil
+}
+
+// DecodeTrampolineOnion peels our layer off the passed trampoline onion,
+// using the rHash as the associated data, and returns the payload it
+// contains along with the onion for the next trampoline node.
+//
+// NOTE: The same trampoline onion is delivered with every part of a
+// multi-part payment, so the onion isn't checked for replays. Replays of the
+// HTLCs carrying it are already caught when processing the outer onion.
+func (p *OnionProcessor) DecodeTrampolineOnion(onion,
+	rHash []byte) (*TrampolinePayload, error) {
+
+	onionPkt := &sphinx.OnionPacket{}
+	if err := onionPkt.Decode(bytes.NewReader(onion)); err != nil {
+		return nil, err
+	}
+
+	packet, err := p.router.ReconstructOnionPacket(
+		onionPkt, rHash, sphinx.WithTLVPayloadOnly(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	finalHop := packet.Action == sphinx.ExitNode
+	payload, err := ParseTrampolinePayload(
+		bytes.NewReader(packet.Payload.Payload), finalHop,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !finalHop {
+		var b bytes.Buffer
+		if err := packet.NextPacket.Encode(&b); err != nil {
+			return nil, err
+		}
+		payload.NextOnion = b.Bytes()
+	}
+
+	return payload, nil
+}
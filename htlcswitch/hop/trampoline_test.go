@@ -0,0 +1,150 @@
+package hop_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/flokiorg/flnd/htlcswitch/hop"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/record"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTrampolinePayload asserts that the payloads of trampoline onion
+// layers are parsed and validated depending on whether they're the last layer
+// of the onion.
+func TestParseTrampolinePayload(t *testing.T) {
+	t.Parallel()
+
+	var (
+		amtAndCltv = []byte{
+			// amount
+			0x02, 0x02, 0x03, 0xe8,
+			// cltv
+			0x04, 0x01, 0x64,
+		}
+		mpp = append([]byte{
+			// mpp (type / length / payment address)
+			0x08, 0x21,
+		}, append(bytes.Repeat([]byte{0x11}, 32),
+			// mpp total amount
+			0x08,
+		)...)
+		outgoingNode = append([]byte{
+			// outgoing node id (type / length)
+			0x0e, 0x21,
+		}, testPubKey.SerializeCompressed()...)
+	)
+
+	concat := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+
+	tests := []struct {
+		name            string
+		payload         []byte
+		finalHop        bool
+		expErr          error
+		expOutgoingNode bool
+		expMPP          bool
+	}{
+		{
+			name:            "relay to next trampoline",
+			payload:         concat(amtAndCltv, outgoingNode),
+			expOutgoingNode: true,
+		},
+		{
+			name:            "relay to legacy recipient",
+			payload:         concat(amtAndCltv, mpp, outgoingNode),
+			finalHop:        true,
+			expOutgoingNode: true,
+			expMPP:          true,
+		},
+		{
+			name:     "recipient",
+			payload:  concat(amtAndCltv, mpp),
+			finalHop: true,
+			expMPP:   true,
+		},
+		{
+			name:    "intermediate without outgoing node",
+			payload: amtAndCltv,
+			expErr: hop.ErrInvalidPayload{
+				Type:      record.OutgoingNodeIDOnionType,
+				Violation: hop.OmittedViolation,
+			},
+		},
+		{
+			name:    "intermediate with mpp",
+			payload: concat(amtAndCltv, mpp, outgoingNode),
+			expErr: hop.ErrInvalidPayload{
+				Type:      record.MPPOnionType,
+				Violation: hop.IncludedViolation,
+			},
+		},
+		{
+			name:     "recipient without mpp",
+			payload:  amtAndCltv,
+			finalHop: true,
+			expErr: hop.ErrInvalidPayload{
+				Type:      record.MPPOnionType,
+				Violation: hop.OmittedViolation,
+				FinalHop:  true,
+			},
+		},
+		{
+			name:     "missing amount",
+			payload:  concat(amtAndCltv[4:], mpp),
+			finalHop: true,
+			expErr: hop.ErrInvalidPayload{
+				Type:      record.AmtOnionType,
+				Violation: hop.OmittedViolation,
+				FinalHop:  true,
+			},
+		},
+		{
+			name: "unknown required type",
+			payload: concat(
+				amtAndCltv, mpp, []byte{0x20, 0x00},
+			),
+			finalHop: true,
+			expErr: hop.ErrInvalidPayload{
+				Type:      0x20,
+				Violation: hop.RequiredViolation,
+				FinalHop:  true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			payload, err := hop.ParseTrampolinePayload(
+				bytes.NewReader(test.payload), test.finalHop,
+			)
+			require.Equal(t, test.expErr, err)
+			if err != nil {
+				return
+			}
+
+			require.Equal(
+				t, lnwire.MilliLoki(1000), payload.AmountToForward,
+			)
+			require.EqualValues(t, 100, payload.OutgoingCLTV)
+
+			if test.expOutgoingNode {
+				require.Equal(t, testPubKey, payload.OutgoingNodeID)
+			} else {
+				require.Nil(t, payload.OutgoingNodeID)
+			}
+
+			if test.expMPP {
+				require.NotNil(t, payload.MPP)
+				require.EqualValues(t, 8, payload.MPP.TotalMsat())
+			} else {
+				require.Nil(t, payload.MPP)
+			}
+		})
+	}
+}
@@ -50,6 +50,13 @@ import (
 //     the release of a preimage.
 //   - Present for local receives, and successful local sends or forwards.
 //
+// Resource Decision Event:
+//   - Resource decision events are present when the resource manager is
+//     enabled and decides whether a htlc we were asked to forward may lock up
+//     the resources of its incoming channel.
+//   - Present for htlc forwards only. The outgoing htlc id is always zero,
+//     since the htlc hasn't been added to the outgoing link yet.
+//
 // Each htlc is identified by its incoming and outgoing circuit key. Htlcs,
 // and their subsequent settles or fails, can be identified by the combination
 // of incoming and outgoing circuits. Note that receives to our node will
@@ -309,6 +316,27 @@ type FinalHtlcEvent struct {
 	Timestamp time.Time
 }
 
+// ResourceDecisionEvent represents the decision of the resource manager on a
+// htlc that we were asked to forward. Rejected htlcs are failed back, which
+// is reported by a subsequent link failure event.
+type ResourceDecisionEvent struct {
+	// HtlcKey uniquely identifies the htlc.
+	HtlcKey
+
+	// HtlcInfo contains details about the htlc.
+	HtlcInfo
+
+	// IncomingAccountable is true if the incoming htlc carried the
+	// accountable signal.
+	IncomingAccountable bool
+
+	// Decision is the outcome of the evaluation of the htlc.
+	Decision ForwardDecision
+
+	// Timestamp is the time when the decision was made.
+	Timestamp time.Time
+}
+
 // NotifyForwardingEvent notifies the HtlcNotifier than a htlc has been
 // forwarded.
 //
@@ -418,6 +446,29 @@ func (h *HtlcNotifier) NotifyFinalHtlcEvent(key models.CircuitKey,
 	}
 }
 
+// NotifyResourceDecisionEvent notifies the HtlcNotifier that the resource
+// manager decided whether a htlc may be forwarded.
+//
+// Note this is part of the htlcNotifier interface.
+func (h *HtlcNotifier) NotifyResourceDecisionEvent(key HtlcKey, info HtlcInfo,
+	incomingAccountable bool, decision ForwardDecision) {
+
+	event := &ResourceDecisionEvent{
+		HtlcKey:             key,
+		HtlcInfo:            info,
+		IncomingAccountable: incomingAccountable,
+		Decision:            decision,
+		Timestamp:           h.now(),
+	}
+
+	log.Tracef("Notifying resource decision event: bucket %v over %v, %v",
+		decision.Bucket, key, info)
+
+	if err := h.ntfnServer.SendUpdate(event); err != nil {
+		log.Warnf("Unable to send resource decision event: %v", err)
+	}
+}
+
 // newHtlc key returns a htlc key for the packet provided. If the packet
 // has a zero incoming channel ID, the packet is for one of our own sends,
 // which has the payment id stashed in the incoming htlc id. If this is the
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/flokiorg/flnd/chainntnfs"
 	"github.com/flokiorg/flnd/fn"
@@ -58,14 +59,19 @@ type InterceptableSwitch struct {
 
 	// interceptorRegistration is a channel that we use to synchronize
 	// client connect and disconnect.
-	interceptorRegistration chan ForwardInterceptor
+	interceptorRegistration chan *interceptorRegistrationRequest
+
+	// interceptorTimeouts receives the forwards that an interceptor didn't
+	// resolve in time.
+	interceptorTimeouts chan interceptorTimeout
 
 	// requireInterceptor indicates whether processing should block if no
 	// interceptor is connected.
 	requireInterceptor bool
 
-	// interceptor is the handler for intercepted packets.
-	interceptor ForwardInterceptor
+	// interceptors is the ordered chain of interceptors that intercepted
+	// packets are offered to.
+	interceptors interceptorChain
 
 	// heldHtlcSet keeps track of outstanding intercepted forwards.
 	heldHtlcSet *heldHtlcSet
@@ -103,6 +109,20 @@ type interceptedPackets struct {
 	isReplay bool
 }
 
+// interceptorRegistrationRequest registers an interceptor, or unregisters the
+// named one if no registration is given.
+type interceptorRegistrationRequest struct {
+	registration *InterceptorRegistration
+	name         string
+
+	// replace indicates whether a connected interceptor of the same name
+	// is replaced instead of the registration being rejected.
+	replace bool
+
+	// errChan receives the result of the request, unless it is nil.
+	errChan chan error
+}
+
 type onchainInterceptRequest struct {
 	fwd     InterceptedForward
 	errChan chan error
@@ -131,6 +151,12 @@ type FwdResolution struct {
 	// Key is the incoming circuit key of the htlc.
 	Key models.CircuitKey
 
+	// Interceptor is the name of the interceptor that resolves the htlc.
+	// If set, the htlc must currently be offered to this interceptor. If
+	// empty, the resolution applies to whichever interceptor it is
+	// offered to.
+	Interceptor string
+
 	// Action is the action to take on the intercepted htlc.
 	Action FwdAction
 
@@ -206,7 +232,8 @@ func NewInterceptableSwitch(cfg *InterceptableSwitchConfig) (
 		intercepted:             make(chan *interceptedPackets),
 		onchainIntercepted:      make(chan *onchainInterceptRequest),
 		onchainInterceptDone:    make(chan models.CircuitKey),
-		interceptorRegistration: make(chan ForwardInterceptor),
+		interceptorRegistration: make(chan *interceptorRegistrationRequest),
+		interceptorTimeouts:     make(chan interceptorTimeout),
 		heldHtlcSet:             newHeldHtlcSet(),
 		resolutionChan:          make(chan *fwdResolution),
 		requireInterceptor:      cfg.RequireInterceptor,
@@ -218,20 +245,101 @@ func NewInterceptableSwitch(cfg *InterceptableSwitchConfig) (
 	}, nil
 }
 
-// SetInterceptor sets the ForwardInterceptor to be used. A nil argument
-// unregisters the current interceptor.
+// SetInterceptor sets the ForwardInterceptor to be used as the default
+// interceptor, replacing the current one. A nil argument unregisters the
+// current default interceptor.
 func (s *InterceptableSwitch) SetInterceptor(
 	interceptor ForwardInterceptor) {
 
 	// Synchronize setting the handler with the main loop to prevent race
 	// conditions.
 	select {
-	case s.interceptorRegistration <- interceptor:
+	case s.interceptorRegistration <- s.defaultRegistration(interceptor):
+
+	case <-s.quit:
+	}
+}
+
+// RegisterInterceptor adds an interceptor to the chain of interceptors that
+// forwards are offered to. All forwards that are currently held for the
+// interceptor, because it disconnected before, are offered to it again.
+func (s *InterceptableSwitch) RegisterInterceptor(
+	registration InterceptorRegistration) error {
+
+	if registration.Name == "" {
+		return errors.New("interceptor name required")
+	}
+
+	if registration.Interceptor == nil {
+		return errors.New("interceptor handler required")
+	}
+
+	return s.registerInterceptorSync(&interceptorRegistrationRequest{
+		registration: &registration,
+	})
+}
+
+// UnregisterInterceptor disconnects the interceptor with the given name. The
+// forwards that are offered to it are treated according to its policy.
+func (s *InterceptableSwitch) UnregisterInterceptor(name string) error {
+	return s.registerInterceptorSync(&interceptorRegistrationRequest{
+		name: name,
+	})
+}
+
+// registerInterceptorSync hands the registration request to the main loop and
+// waits for its result.
+func (s *InterceptableSwitch) registerInterceptorSync(
+	req *interceptorRegistrationRequest) error {
+
+	req.errChan = make(chan error, 1)
+
+	// Synchronize setting the handler with the main loop to prevent race
+	// conditions.
+	select {
+	case s.interceptorRegistration <- req:
 
 	case <-s.quit:
+		return errors.New("interceptable switch quit")
+	}
+
+	select {
+	case err := <-req.errChan:
+		return err
+
+	case <-s.quit:
+		return errors.New("interceptable switch quit")
 	}
 }
 
+// defaultRegistration returns the request that replaces the default
+// interceptor, or unregisters it if nil is passed. The default interceptor
+// keeps the forwards it holds if an interceptor is required.
+func (s *InterceptableSwitch) defaultRegistration(
+	interceptor ForwardInterceptor) *interceptorRegistrationRequest {
+
+	req := &interceptorRegistrationRequest{
+		name: DefaultInterceptorName,
+	}
+	if interceptor == nil {
+		return req
+	}
+
+	policy := InterceptorFailOpen
+	if s.requireInterceptor {
+		policy = InterceptorFailClosed
+	}
+
+	req.registration = &InterceptorRegistration{
+		Name:        DefaultInterceptorName,
+		Policy:      policy,
+		Interceptor: interceptor,
+	}
+	req.replace = true
+
+	return req
+}
+
 func (s *InterceptableSwitch) Start() error {
 	log.Info("InterceptableSwitch starting...")
 
@@ -301,8 +409,19 @@ func (s *InterceptableSwitch) run() error {
 	for {
 		select {
 		// An interceptor registration or de-registration came in.
-		case interceptor := <-s.interceptorRegistration:
-			s.setInterceptor(interceptor)
+		case req := <-s.interceptorRegistration:
+			err := s.handleRegistration(req)
+			switch {
+			case req.errChan != nil:
+				req.errChan <- err
+
+			case err != nil:
+				log.Errorf("Unable to register interceptor: %v",
+					err)
+			}
+
+		case timeout := <-s.interceptorTimeouts:
+			s.handleInterceptorTimeout(timeout)
 
 		case packets := <-s.intercepted:
 			var notIntercepted []*htlcPacket
@@ -334,8 +453,8 @@ func (s *InterceptableSwitch) run() error {
 				continue
 			}
 
-			if s.interceptor != nil && notify {
-				s.sendForward(req.fwd)
+			if notify {
+				s.notifyOnChain(req.fwd)
 			}
 
 		case key := <-s.onchainInterceptDone:
@@ -371,51 +490,254 @@ func (s *InterceptableSwitch) failExpiredHtlcs() {
 	}
 }
 
-func (s *InterceptableSwitch) sendForward(fwd InterceptedForward) {
-	err := s.interceptor(fwd.Packet())
+// sendForward offers the packet to the interceptor.
+func (s *InterceptableSwitch) sendForward(slot *InterceptorRegistration,
+	packet InterceptedPacket) {
+
+	err := slot.Interceptor(packet)
 	if err != nil {
 		// Only log the error. If we couldn't send the packet, we assume
 		// that the interceptor will reconnect so that we can retry.
-		log.Debugf("Interceptor cannot handle forward: %v", err)
+		log.Debugf("Interceptor %v cannot handle forward: %v",
+			slot.Name, err)
 	}
 }
 
+// notifyOnChain offers the on-chain held forward to all connected
+// interceptors, as any of them may settle it.
+func (s *InterceptableSwitch) notifyOnChain(fwd InterceptedForward) {
+	s.interceptors.forEachOnline(func(slot *InterceptorRegistration) {
+		s.sendForward(slot, fwd.Packet())
+	})
+}
+
+// handleRegistration registers or unregisters an interceptor as requested.
+func (s *InterceptableSwitch) handleRegistration(
+	req *interceptorRegistrationRequest) error {
+
+	if req.registration == nil {
+		s.unregisterInterceptor(req.name)
+
+		return nil
+	}
+
+	if req.replace {
+		s.interceptors.remove(req.registration.Name)
+	}
+
+	return s.registerInterceptor(req.registration)
+}
+
+// setInterceptor replaces the default interceptor. A nil argument unregisters
+// it.
 func (s *InterceptableSwitch) setInterceptor(interceptor ForwardInterceptor) {
-	s.interceptor = interceptor
+	err := s.handleRegistration(s.defaultRegistration(interceptor))
+	if err != nil {
+		log.Errorf("Unable to set default interceptor: %v", err)
+	}
+}
+
+// registerInterceptor adds the interceptor to the chain and offers it the
+// forwards it is expected to handle: all on-chain held forwards, and the
+// off-chain held forwards that wait for it or haven't been offered to any
+// interceptor yet.
+func (s *InterceptableSwitch) registerInterceptor(
+	registration *InterceptorRegistration) error {
 
-	// Replay all currently held htlcs. When an interceptor is not required,
-	// there may be none because they've been cleared after the previous
-	// disconnect.
-	if interceptor != nil {
-		log.Debugf("Interceptor connected")
+	if err := s.interceptors.add(registration); err != nil {
+		return err
+	}
+	slot, _ := s.interceptors.get(registration.Name)
+
+	log.Infof("Interceptor %v connected: priority=%v, timeout=%v, "+
+		"policy=%v", slot.Name, slot.Priority, slot.Timeout,
+		slot.Policy)
 
-		s.heldHtlcSet.forEach(s.sendForward)
+	for key, entry := range s.heldHtlcSet.set {
+		switch e := entry.(type) {
+		case *onChainHeld:
+			s.sendForward(slot, e.fwd.Packet())
 
+		case *offChainHeld:
+			switch {
+			case e.stage == nil:
+				err := s.advance(e)
+				if err != nil {
+					log.Errorf("Cannot resume held "+
+						"forward %v: %v", key, err)
+				}
+
+			case e.stage.Name == slot.Name:
+				e.stage = slot
+				s.offer(e)
+			}
+		}
+	}
+
+	return nil
+}
+
+// unregisterInterceptor disconnects the named interceptor. If it's fail-open,
+// the forwards offered to it are passed on to the next interceptor. If it's
+// fail-closed, they are held until it reconnects.
+func (s *InterceptableSwitch) unregisterInterceptor(name string) {
+	slot, ok := s.interceptors.disconnect(name)
+	if !ok {
 		return
 	}
 
-	// The interceptor disconnects. If an interceptor is required, keep the
-	// held htlcs.
-	if s.requireInterceptor {
-		log.Infof("Interceptor disconnected, retaining held packets")
+	if slot.Policy == InterceptorFailClosed {
+		log.Infof("Interceptor %v disconnected, retaining held packets",
+			name)
+
+		return
+	}
+
+	log.Infof("Interceptor %v disconnected, passing on held packets", name)
+
+	for key, entry := range s.heldHtlcSet.set {
+		offChain, ok := entry.(*offChainHeld)
+		if !ok || offChain.stage == nil || offChain.stage.Name != name {
+			continue
+		}
+
+		if err := s.advance(offChain); err != nil {
+			log.Errorf("Failed to resume hold forward %v: %v", key,
+				err)
+		}
+	}
+}
+
+// advance passes the held off-chain forward on to the next interceptor in the
+// chain. If there is none, the forward is resumed with the modifications the
+// interceptors requested. If the next interceptor is fail-closed but
+// disconnected, the forward is held until it reconnects, or failed back if it
+// is safe to do so.
+func (s *InterceptableSwitch) advance(entry *offChainHeld) error {
+	key := entry.fwd.Packet().IncomingCircuit
+
+	next := s.interceptors.next(entry.stage)
+	if next == nil {
+		if err := entry.release(); err != nil {
+			return err
+		}
+		delete(s.heldHtlcSet.set, key)
+
+		return nil
+	}
+
+	entry.stage = next
+	if next.online() {
+		s.offer(entry)
+
+		return nil
+	}
+
+	// A replayed forward may have been offered to the interceptor before,
+	// so it's not safe to fail it back.
+	if entry.isReplay {
+		log.Debugf("Holding forward %v for disconnected interceptor %v",
+			key, next.Name)
+
+		return nil
+	}
+
+	err := entry.fwd.FailWithCode(lnwire.CodeTemporaryChannelFailure)
+	if err != nil {
+		log.Errorf("Cannot fail packet: %v", err)
+	}
+	delete(s.heldHtlcSet.set, key)
+
+	return nil
+}
+
+// offer offers the held off-chain forward to the interceptor of its current
+// stage, with the modifications of the previous interceptors applied. If the
+// interceptor has a timeout, its policy is applied once the timeout expires
+// without a resolution.
+func (s *InterceptableSwitch) offer(entry *offChainHeld) {
+	slot := entry.stage
+	entry.offers++
+
+	s.sendForward(slot, entry.mods.apply(entry.fwd.Packet()))
 
+	if slot.Timeout == 0 {
 		return
 	}
 
-	// Interceptor is not required. Release off-chain held forwards.
-	log.Infof("Interceptor disconnected, resolving held packets")
+	timeout := interceptorTimeout{
+		key:   entry.fwd.Packet().IncomingCircuit,
+		offer: entry.offers,
+	}
+	time.AfterFunc(slot.Timeout, func() {
+		select {
+		case s.interceptorTimeouts <- timeout:
+
+		case <-s.quit:
+		}
+	})
+}
+
+// handleInterceptorTimeout applies the policy of the interceptor that didn't
+// resolve the forward in time.
+func (s *InterceptableSwitch) handleInterceptorTimeout(
+	timeout interceptorTimeout) {
 
-	errs := s.heldHtlcSet.releaseAllOffChainHeld()
-	for _, releaseErr := range errs {
-		log.Errorf("Failed to resume hold forward %v: %v",
-			releaseErr.key, releaseErr.err)
+	entry, ok := s.heldHtlcSet.offChain(timeout.key)
+	if !ok || entry.offers != timeout.offer || entry.stage == nil {
+		return
 	}
+
+	log.Infof("Interceptor %v timed out on forward %v, applying %v "+
+		"policy", entry.stage.Name, timeout.key, entry.stage.Policy)
+
+	if entry.stage.Policy == InterceptorFailOpen {
+		if err := s.advance(entry); err != nil {
+			log.Errorf("Cannot resume held forward %v: %v",
+				timeout.key, err)
+		}
+
+		return
+	}
+
+	err := entry.fwd.FailWithCode(lnwire.CodeTemporaryChannelFailure)
+	if err != nil {
+		log.Errorf("Cannot fail packet: %v", err)
+
+		return
+	}
+	delete(s.heldHtlcSet.set, timeout.key)
 }
 
 // resolve processes a HTLC given the resolution type specified by the
-// intercepting client.
+// intercepting client. Off-chain forwards that are resumed are passed on to
+// the next interceptor, while settles and fails are final.
 func (s *InterceptableSwitch) resolve(res *FwdResolution) error {
-	return s.heldHtlcSet.resolve(res)
+	entry, ok := s.heldHtlcSet.offChain(res.Key)
+	if !ok {
+		return s.heldHtlcSet.resolve(res)
+	}
+
+	if res.Interceptor != "" &&
+		(entry.stage == nil || entry.stage.Name != res.Interceptor) {
+
+		return fmt.Errorf("%w: %v", ErrFwdNotOffered, res.Interceptor)
+	}
+
+	switch res.Action {
+	case FwdActionResume:
+		return s.advance(entry)
+
+	case FwdActionResumeModified:
+		if err := entry.mods.merge(res); err != nil {
+			return err
+		}
+
+		return s.advance(entry)
+
+	default:
+		return s.heldHtlcSet.resolve(res)
+	}
 }
 
 // Resolve resolves an intercepted packet.
@@ -569,7 +891,7 @@ func (s *InterceptableSwitch) forwardOffChain(
 
 	// If there is no interceptor currently registered, configuration and packet
 	// replay status determine how the packet is handled.
-	if s.interceptor == nil {
+	if s.interceptors.empty() {
 		// Process normally if an interceptor is not required.
 		if !s.requireInterceptor {
 			return false, nil
@@ -595,17 +917,28 @@ func (s *InterceptableSwitch) forwardOffChain(
 		if err := s.heldHtlcSet.addOffChain(fwd); err != nil {
 			return false, err
 		}
+		if entry, ok := s.heldHtlcSet.offChain(inKey); ok {
+			entry.isReplay = true
+		}
 
 		return true, nil
 	}
 
-	// There is an interceptor registered. We can notify it right now. Hold
-	// the packet in the queue too to track what is outstanding.
+	// There are interceptors registered. Hold the packet in the queue to
+	// track what is outstanding, and offer it to the first one.
 	if err := s.heldHtlcSet.addOffChain(fwd); err != nil {
 		return false, err
 	}
 
-	s.sendForward(fwd)
+	entry, ok := s.heldHtlcSet.offChain(inKey)
+	if !ok {
+		return true, nil
+	}
+	entry.isReplay = isReplay
+
+	if err := s.advance(entry); err != nil {
+		log.Errorf("Cannot resume packet %v: %v", inKey, err)
+	}
 
 	return true, nil
 }
@@ -621,8 +954,8 @@ func (s *InterceptableSwitch) interceptOnChain(fwd InterceptedForward) error {
 		return err
 	}
 
-	if s.interceptor != nil && notify {
-		s.sendForward(fwd)
+	if notify {
+		s.notifyOnChain(fwd)
 	}
 
 	return nil
@@ -0,0 +1,261 @@
+package htlcswitch
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/lnwire"
+)
+
+// DefaultInterceptorName is the name under which the interceptor set through
+// SetInterceptor is registered.
+const DefaultInterceptorName = "default"
+
+var (
+	// ErrInterceptorExists is returned when an interceptor is registered
+	// under the name of an interceptor that is already connected.
+	ErrInterceptorExists = errors.New("interceptor already exists")
+
+	// ErrFwdNotOffered is returned when an interceptor tries to resolve a
+	// forward that is currently offered to a different interceptor.
+	ErrFwdNotOffered = errors.New("forward not offered to interceptor")
+)
+
+// InterceptorPolicy defines how htlcs are treated if an interceptor doesn't
+// resolve them in time or disconnects while they're offered to it.
+type InterceptorPolicy uint8
+
+const (
+	// InterceptorFailOpen passes the htlc on to the next interceptor, or
+	// forwards it if there is none.
+	InterceptorFailOpen InterceptorPolicy = iota
+
+	// InterceptorFailClosed never lets a htlc bypass the interceptor. On
+	// timeout, the htlc is failed back. When the interceptor disconnects,
+	// it keeps its place in the chain: the htlcs offered to it are held
+	// until it reconnects or they expire, and new htlcs are failed back.
+	InterceptorFailClosed
+)
+
+// String returns a human-readable name of the policy.
+func (p InterceptorPolicy) String() string {
+	switch p {
+	case InterceptorFailOpen:
+		return "fail-open"
+
+	case InterceptorFailClosed:
+		return "fail-closed"
+
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(p))
+	}
+}
+
+// InterceptorRegistration describes an interceptor that takes part in the
+// chain of interceptors every forward is offered to.
+type InterceptorRegistration struct {
+	// Name uniquely identifies the interceptor.
+	Name string
+
+	// Priority determines the order in which forwards are offered to the
+	// interceptors. Interceptors with a higher priority are asked first,
+	// interceptors with the same priority are ordered by name.
+	Priority int32
+
+	// Timeout is the time the interceptor has to resolve a forward before
+	// its policy is applied. Zero means that the interceptor may hold
+	// forwards until they expire.
+	Timeout time.Duration
+
+	// Policy defines what happens to forwards the interceptor doesn't
+	// resolve.
+	Policy InterceptorPolicy
+
+	// Interceptor is the handler that forwards are offered to. It is nil
+	// for a fail-closed interceptor that is disconnected.
+	Interceptor ForwardInterceptor
+}
+
+// before returns true if forwards are offered to the interceptor before the
+// other one.
+func (r *InterceptorRegistration) before(other *InterceptorRegistration) bool {
+	if r.Priority != other.Priority {
+		return r.Priority > other.Priority
+	}
+
+	return r.Name < other.Name
+}
+
+// online returns true if the interceptor is connected.
+func (r *InterceptorRegistration) online() bool {
+	return r.Interceptor != nil
+}
+
+// interceptorChain keeps the registered interceptors in the order in which
+// forwards are offered to them.
+type interceptorChain struct {
+	slots []*InterceptorRegistration
+}
+
+// get returns the interceptor with the given name, if registered.
+func (c *interceptorChain) get(name string) (*InterceptorRegistration, bool) {
+	for _, slot := range c.slots {
+		if slot.Name == name {
+			return slot, true
+		}
+	}
+
+	return nil, false
+}
+
+// add registers the interceptor, replacing a disconnected fail-closed
+// interceptor of the same name.
+func (c *interceptorChain) add(reg *InterceptorRegistration) error {
+	if slot, ok := c.get(reg.Name); ok {
+		if slot.online() {
+			return fmt.Errorf("%w: %v", ErrInterceptorExists,
+				reg.Name)
+		}
+
+		c.remove(reg.Name)
+	}
+
+	slot := *reg
+	c.slots = append(c.slots, &slot)
+	sort.Slice(c.slots, func(i, j int) bool {
+		return c.slots[i].before(c.slots[j])
+	})
+
+	return nil
+}
+
+// remove removes the interceptor with the given name from the chain.
+func (c *interceptorChain) remove(name string) {
+	for i, slot := range c.slots {
+		if slot.Name == name {
+			c.slots = append(c.slots[:i], c.slots[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// disconnect marks the interceptor with the given name as disconnected and
+// returns its registration. Fail-open interceptors leave the chain, while
+// fail-closed interceptors keep their place in it.
+func (c *interceptorChain) disconnect(name string) (*InterceptorRegistration,
+	bool) {
+
+	slot, ok := c.get(name)
+	if !ok {
+		return nil, false
+	}
+
+	if slot.Policy == InterceptorFailClosed {
+		slot.Interceptor = nil
+
+		return slot, true
+	}
+
+	c.remove(name)
+
+	return slot, true
+}
+
+// next returns the first interceptor that forwards are offered to after the
+// given one, which doesn't need to be part of the chain anymore. If nil is
+// passed, the first interceptor of the chain is returned.
+func (c *interceptorChain) next(
+	after *InterceptorRegistration) *InterceptorRegistration {
+
+	for _, slot := range c.slots {
+		if after == nil || after.before(slot) {
+			return slot
+		}
+	}
+
+	return nil
+}
+
+// empty returns true if no interceptors are registered.
+func (c *interceptorChain) empty() bool {
+	return len(c.slots) == 0
+}
+
+// forEachOnline calls the given callback for every connected interceptor.
+func (c *interceptorChain) forEachOnline(cb func(*InterceptorRegistration)) {
+	for _, slot := range c.slots {
+		if slot.online() {
+			cb(slot)
+		}
+	}
+}
+
+// fwdModifications accumulates the modifications that interceptors request
+// while a forward is passed along the chain. They are applied once the
+// forward is resumed.
+type fwdModifications struct {
+	inAmountMsat         fn.Option[lnwire.MilliLoki]
+	outAmountMsat        fn.Option[lnwire.MilliLoki]
+	outWireCustomRecords fn.Option[lnwire.CustomRecords]
+}
+
+// merge adds the modifications of the resolution, overriding the amounts and
+// custom records set by earlier interceptors.
+func (m *fwdModifications) merge(res *FwdResolution) error {
+	err := fn.MapOptionZ(
+		res.OutWireCustomRecords,
+		func(records lnwire.CustomRecords) error {
+			return records.Validate()
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to validate custom records: %w", err)
+	}
+
+	if res.InAmountMsat.IsSome() {
+		m.inAmountMsat = res.InAmountMsat
+	}
+
+	if res.OutAmountMsat.IsSome() {
+		m.outAmountMsat = res.OutAmountMsat
+	}
+
+	res.OutWireCustomRecords.WhenSome(func(records lnwire.CustomRecords) {
+		merged := m.outWireCustomRecords.UnwrapOr(nil).MergedCopy(
+			records,
+		)
+		m.outWireCustomRecords = fn.Some(merged)
+	})
+
+	return nil
+}
+
+// modified returns true if any interceptor requested modifications.
+func (m *fwdModifications) modified() bool {
+	return m.inAmountMsat.IsSome() || m.outAmountMsat.IsSome() ||
+		m.outWireCustomRecords.IsSome()
+}
+
+// apply returns the packet as seen by the next interceptor, with the amount
+// modifications of the previous ones applied.
+func (m *fwdModifications) apply(packet InterceptedPacket) InterceptedPacket {
+	packet.IncomingAmount = m.inAmountMsat.UnwrapOr(packet.IncomingAmount)
+	packet.OutgoingAmount = m.outAmountMsat.UnwrapOr(packet.OutgoingAmount)
+
+	return packet
+}
+
+// interceptorTimeout signals that an interceptor didn't resolve a forward in
+// time.
+type interceptorTimeout struct {
+	key models.CircuitKey
+
+	// offer identifies the offer of the forward that timed out, so that
+	// timeouts of earlier offers are ignored.
+	offer uint64
+}
@@ -0,0 +1,258 @@
+package htlcswitch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+// testInterceptor records the packets offered to an interceptor.
+type testInterceptor struct {
+	offered []InterceptedPacket
+}
+
+// intercept is the ForwardInterceptor of the test interceptor.
+func (i *testInterceptor) intercept(packet InterceptedPacket) error {
+	i.offered = append(i.offered, packet)
+
+	return nil
+}
+
+// newTestChainSwitch returns an interceptable switch without a main loop, on
+// which the chain logic can be called directly.
+func newTestChainSwitch() *InterceptableSwitch {
+	return &InterceptableSwitch{
+		heldHtlcSet:         newHeldHtlcSet(),
+		interceptorTimeouts: make(chan interceptorTimeout, 1),
+		quit:                make(chan struct{}),
+	}
+}
+
+// registerTestInterceptor registers a test interceptor with the given name,
+// priority, timeout and policy.
+func registerTestInterceptor(t *testing.T, s *InterceptableSwitch,
+	name string, priority int32, timeout time.Duration,
+	policy InterceptorPolicy) *testInterceptor {
+
+	interceptor := &testInterceptor{}
+	require.NoError(t, s.registerInterceptor(&InterceptorRegistration{
+		Name:        name,
+		Priority:    priority,
+		Timeout:     timeout,
+		Policy:      policy,
+		Interceptor: interceptor.intercept,
+	}))
+
+	return interceptor
+}
+
+// TestInterceptorChainOrder tests that forwards are offered to the
+// interceptors in order of their priority, and that modifications requested
+// by the interceptors are accumulated until the forward is resumed.
+func TestInterceptorChainOrder(t *testing.T) {
+	t.Parallel()
+
+	s := newTestChainSwitch()
+	low := registerTestInterceptor(t, s, "low", 0, 0, InterceptorFailOpen)
+	high := registerTestInterceptor(
+		t, s, "high", 10, 0, InterceptorFailOpen,
+	)
+
+	// Names must be unique among the connected interceptors.
+	err := s.registerInterceptor(&InterceptorRegistration{
+		Name:        "low",
+		Interceptor: low.intercept,
+	})
+	require.ErrorIs(t, err, ErrInterceptorExists)
+
+	key := testCircuitKey()
+	fwd := newMockInterceptedForward(key, 100)
+	fwd.packet.OutgoingAmount = 1_000
+
+	handled, err := s.forwardOffChain(fwd, false)
+	require.NoError(t, err)
+	require.True(t, handled)
+
+	// The forward is offered to the interceptor with the higher priority
+	// first, and only it may resolve it.
+	require.Len(t, high.offered, 1)
+	require.Empty(t, low.offered)
+
+	err = s.resolve(&FwdResolution{
+		Key:         key,
+		Interceptor: "low",
+		Action:      FwdActionResume,
+	})
+	require.ErrorIs(t, err, ErrFwdNotOffered)
+
+	// Resuming the forward with a modified amount passes it on, with the
+	// modification applied.
+	require.NoError(t, s.resolve(&FwdResolution{
+		Key:           key,
+		Interceptor:   "high",
+		Action:        FwdActionResumeModified,
+		OutAmountMsat: fn.Some[lnwire.MilliLoki](900),
+	}))
+	require.Len(t, low.offered, 1)
+	require.EqualValues(t, 900, low.offered[0].OutgoingAmount)
+
+	// Once the last interceptor resumes the forward, it is forwarded with
+	// the modifications of all interceptors.
+	records := lnwire.CustomRecords{lnwire.MinCustomRecordsTlvType: {1}}
+	fwd.On(
+		"ResumeModified", fn.None[lnwire.MilliLoki](),
+		fn.Some[lnwire.MilliLoki](900), fn.Some(records),
+	).Return(nil).Once()
+
+	require.NoError(t, s.resolve(&FwdResolution{
+		Key:                  key,
+		Interceptor:          "low",
+		Action:               FwdActionResumeModified,
+		OutWireCustomRecords: fn.Some(records),
+	}))
+	fwd.AssertExpectations(t)
+	require.False(t, s.heldHtlcSet.exists(key))
+}
+
+// TestInterceptorChainSettle tests that settling a forward is final, so later
+// interceptors don't see it.
+func TestInterceptorChainSettle(t *testing.T) {
+	t.Parallel()
+
+	s := newTestChainSwitch()
+	first := registerTestInterceptor(
+		t, s, "first", 1, 0, InterceptorFailOpen,
+	)
+	second := registerTestInterceptor(
+		t, s, "second", 0, 0, InterceptorFailOpen,
+	)
+
+	key := testCircuitKey()
+	fwd := newMockInterceptedForward(key, 100)
+	fwd.On("Settle", lntypes.Preimage{}).Return(nil).Once()
+
+	_, err := s.forwardOffChain(fwd, false)
+	require.NoError(t, err)
+	require.Len(t, first.offered, 1)
+
+	require.NoError(t, s.resolve(&FwdResolution{
+		Key:         key,
+		Interceptor: "first",
+		Action:      FwdActionSettle,
+	}))
+	fwd.AssertExpectations(t)
+	require.Empty(t, second.offered)
+	require.False(t, s.heldHtlcSet.exists(key))
+}
+
+// TestInterceptorChainTimeout tests that the policy of an interceptor is
+// applied to the forwards it doesn't resolve in time.
+func TestInterceptorChainTimeout(t *testing.T) {
+	t.Parallel()
+
+	s := newTestChainSwitch()
+	defer close(s.quit)
+
+	open := registerTestInterceptor(
+		t, s, "open", 2, 10*time.Millisecond, InterceptorFailOpen,
+	)
+	closed := registerTestInterceptor(
+		t, s, "closed", 1, 10*time.Millisecond, InterceptorFailClosed,
+	)
+
+	key := testCircuitKey()
+	fwd := newMockInterceptedForward(key, 100)
+	fwd.On("FailWithCode", lnwire.CodeTemporaryChannelFailure).Return(
+		nil,
+	).Once()
+
+	_, err := s.forwardOffChain(fwd, false)
+	require.NoError(t, err)
+	require.Len(t, open.offered, 1)
+
+	// The fail-open interceptor times out, which passes the forward on.
+	select {
+	case timeout := <-s.interceptorTimeouts:
+		s.handleInterceptorTimeout(timeout)
+
+	case <-time.After(time.Second):
+		t.Fatal("interceptor didn't time out")
+	}
+	require.Len(t, closed.offered, 1)
+
+	// A late resolution of the first interceptor is rejected.
+	err = s.resolve(&FwdResolution{
+		Key:         key,
+		Interceptor: "open",
+		Action:      FwdActionResume,
+	})
+	require.ErrorIs(t, err, ErrFwdNotOffered)
+
+	// The fail-closed interceptor times out, which fails the forward.
+	select {
+	case timeout := <-s.interceptorTimeouts:
+		s.handleInterceptorTimeout(timeout)
+
+	case <-time.After(time.Second):
+		t.Fatal("interceptor didn't time out")
+	}
+	fwd.AssertExpectations(t)
+	require.False(t, s.heldHtlcSet.exists(key))
+}
+
+// TestInterceptorChainDisconnect tests that forwards offered to a fail-open
+// interceptor are passed on when it disconnects, while a disconnected
+// fail-closed interceptor holds them until it reconnects.
+func TestInterceptorChainDisconnect(t *testing.T) {
+	t.Parallel()
+
+	s := newTestChainSwitch()
+	registerTestInterceptor(t, s, "open", 1, 0, InterceptorFailOpen)
+	registerTestInterceptor(t, s, "closed", 0, 0, InterceptorFailClosed)
+
+	key := testCircuitKey()
+	fwd := newMockInterceptedForward(key, 100)
+	fwd.On("Resume").Return(nil).Once()
+
+	_, err := s.forwardOffChain(fwd, false)
+	require.NoError(t, err)
+
+	// The fail-open interceptor disconnects, so the forward is offered to
+	// the fail-closed one, which disconnects as well.
+	s.unregisterInterceptor("open")
+	s.unregisterInterceptor("closed")
+	require.True(t, s.heldHtlcSet.exists(key))
+
+	// New forwards can't bypass the disconnected fail-closed interceptor.
+	otherKey := key
+	otherKey.HtlcID++
+	other := newMockInterceptedForward(otherKey, 100)
+	other.On("FailWithCode", lnwire.CodeTemporaryChannelFailure).Return(
+		nil,
+	).Once()
+
+	_, err = s.forwardOffChain(other, false)
+	require.NoError(t, err)
+	other.AssertExpectations(t)
+	require.False(t, s.heldHtlcSet.exists(otherKey))
+
+	// Once the fail-closed interceptor reconnects, the held forward is
+	// offered to it again and can be resumed.
+	closed := registerTestInterceptor(
+		t, s, "closed", 0, 0, InterceptorFailClosed,
+	)
+	require.Len(t, closed.offered, 1)
+	require.Equal(t, key, closed.offered[0].IncomingCircuit)
+
+	require.NoError(t, s.resolve(&FwdResolution{
+		Key:         key,
+		Interceptor: "closed",
+		Action:      FwdActionResume,
+	}))
+	fwd.AssertExpectations(t)
+	require.False(t, s.heldHtlcSet.exists(key))
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/flokiorg/flnd/record"
 	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/txscript"
 	"github.com/flokiorg/go-flokicoin/wire"
 )
 
@@ -50,6 +52,35 @@ type InvoiceDatabase interface {
 	HodlUnsubscribeAll(subscriber chan<- interface{})
 }
 
+// TrampolineForwarder is an interface which represents the subsystem that
+// handles the htlcs that are delivered to us along with a trampoline onion.
+type TrampolineForwarder interface {
+	// NotifyTrampolineHtlc processes an htlc that was delivered to us
+	// along with a trampoline onion. The return value describes how the
+	// htlc should be resolved. If the htlc cannot be resolved immediately,
+	// the resolution is sent on the passed in hodlChan later.
+	NotifyTrampolineHtlc(htlc *TrampolineHtlc,
+		hodlChan chan<- interface{}) (invoices.HtlcResolution, error)
+
+	// HodlUnsubscribeAll unsubscribes from all htlc resolutions.
+	HodlUnsubscribeAll(subscriber chan<- interface{})
+}
+
+// TrampolinePayer is an interface which represents the subsystem that sends
+// the payments a trampoline node relays.
+type TrampolinePayer interface {
+	// SendTrampolinePayment sends the passed payment and blocks until it
+	// either succeeded, returning its preimage, or failed. If the outcome
+	// of the payment can't be determined before shutting down,
+	// ErrTrampolinePaymentPending is returned.
+	SendTrampolinePayment(payment *TrampolinePayment) (lntypes.Preimage,
+		error)
+
+	// FetchPreimage returns the preimage of the payment with the passed
+	// hash, if we already relayed it successfully.
+	FetchPreimage(hash lntypes.Hash) (fn.Option[lntypes.Preimage], error)
+}
+
 // packetHandler is an interface used exclusively by the Switch to handle
 // htlcPacket and pass them to the link implementation.
 type packetHandler interface {
@@ -185,6 +216,18 @@ type ChannelUpdateHandler interface {
 	// quiescence is a holdover until we have downstream protocols that use
 	// it.
 	InitStfu() <-chan fn.Result[lntypes.ChannelParty]
+
+	// UpdateChannelParams quiesces the channel and proposes the passed
+	// parameters to the remote party using the dynamic commitments
+	// protocol. The returned channel receives nil once both commitments
+	// use the new parameters, or the reason the update failed.
+	UpdateChannelParams(lnwire.DynPropose) <-chan error
+
+	// Splice quiesces the channel and splices funds into or out of it.
+	// The returned channel receives the txid of the splice transaction
+	// once it was signed by both parties and published, or the reason the
+	// splice failed.
+	Splice(SpliceRequest) <-chan fn.Result[chainhash.Hash]
 }
 
 // CommitHookID is a value that is used to uniquely identify hooks in the
@@ -303,6 +346,10 @@ type ChannelLink interface {
 	// total sent/received milli-loki.
 	Stats() (uint64, lnwire.MilliLoki, lnwire.MilliLoki)
 
+	// IncomingLimits returns the limits the remote party must respect when
+	// adding htlcs to the channel.
+	IncomingLimits() ChannelLimits
+
 	// PeerPubKey returns the serialized public key of remote peer with
 	// which we have the channel link opened.
 	PeerPubKey() [33]byte
@@ -364,12 +411,41 @@ type TowerClient interface {
 	BackupState(chanID *lnwire.ChannelID, stateNum uint64) error
 }
 
+// SpliceWallet is the interface the link uses to fund, sign and publish the
+// splice transactions it initiates.
+type SpliceWallet interface {
+	// FundSplice selects the wallet inputs and creates the outputs needed
+	// for the passed splice. The selected inputs are leased until they're
+	// spent or CancelSplice is called.
+	FundSplice(*lnwallet.SpliceFundingRequest) (*lnwallet.SpliceFunding,
+		error)
+
+	// CancelSplice releases the wallet inputs leased for the passed
+	// splice.
+	CancelSplice(*lnwallet.SpliceFunding)
+
+	// SignSpliceInputs signs the wallet inputs of the passed splice
+	// transaction.
+	SignSpliceInputs(*wire.MsgTx, *lnwallet.SpliceFunding,
+		txscript.PrevOutputFetcher) ([]wire.TxWitness, error)
+
+	// PublishTransaction broadcasts the passed transaction.
+	PublishTransaction(tx *wire.MsgTx, label string) error
+}
+
 // InterceptableHtlcForwarder is the interface to set the interceptor
 // implementation that intercepts htlc forwards.
 type InterceptableHtlcForwarder interface {
 	// SetInterceptor sets a ForwardInterceptor.
 	SetInterceptor(interceptor ForwardInterceptor)
 
+	// RegisterInterceptor adds a named interceptor to the chain of
+	// interceptors that forwards are offered to.
+	RegisterInterceptor(registration InterceptorRegistration) error
+
+	// UnregisterInterceptor disconnects the named interceptor.
+	UnregisterInterceptor(name string) error
+
 	// Resolve resolves an intercepted packet.
 	Resolve(res *FwdResolution) error
 }
@@ -513,6 +589,11 @@ type htlcNotifier interface {
 	// for an htlc has been determined.
 	NotifyFinalHtlcEvent(key models.CircuitKey,
 		info channeldb.FinalHtlcInfo)
+
+	// NotifyResourceDecisionEvent notifies the HtlcNotifier that the
+	// resource manager decided whether a htlc may be forwarded.
+	NotifyResourceDecisionEvent(key HtlcKey, info HtlcInfo,
+		incomingAccountable bool, decision ForwardDecision)
 }
 
 // AuxHtlcModifier is an interface that allows the sender to modify the outgoing
@@ -12,6 +12,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/flokiorg/flnd/chainntnfs"
 	"github.com/flokiorg/flnd/channeldb"
 	"github.com/flokiorg/flnd/chanstate"
 	"github.com/flokiorg/flnd/contractcourt"
@@ -21,6 +22,7 @@ import (
 	"github.com/flokiorg/flnd/htlcswitch/hop"
 	"github.com/flokiorg/flnd/input"
 	"github.com/flokiorg/flnd/invoices"
+	"github.com/flokiorg/flnd/keychain"
 	"github.com/flokiorg/flnd/lnpeer"
 	"github.com/flokiorg/flnd/lntypes"
 	"github.com/flokiorg/flnd/lnutils"
@@ -32,6 +34,7 @@ import (
 	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/flnd/ticker"
 	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	flog "github.com/flokiorg/go-flokicoin/log/v2"
 	"github.com/flokiorg/go-flokicoin/wire"
@@ -307,11 +310,44 @@ type ChannelLinkConfig struct {
 	// related wire messages.
 	AuxChannelNegotiator fn.Option[lnwallet.AuxChannelNegotiator]
 
+	// TrampolineForwarder is an optional subsystem that handles the htlcs
+	// that are delivered to us along with a trampoline onion. If it isn't
+	// set, such htlcs are failed.
+	TrampolineForwarder fn.Option[TrampolineForwarder]
+
 	// QuiescenceTimeout is the max duration that the channel can be
 	// quiesced. Any dependent protocols (dynamic commitments, splicing,
 	// etc.) must finish their operations under this timeout value,
 	// otherwise the node will disconnect.
 	QuiescenceTimeout time.Duration
+
+	// DisallowDynCommitments is a flag that can be used to disable the
+	// dynamic commitments protocol.
+	DisallowDynCommitments bool
+
+	// MessageSigner is used to sign the DynAck of a dynamic commitment
+	// proposed by the remote party with our funding key.
+	MessageSigner keychain.MessageSignerRing
+
+	// MaxLocalCSVDelay is the maximum csv delay we'll accept for our own
+	// outputs when the remote party proposes new channel parameters.
+	MaxLocalCSVDelay uint16
+
+	// NotifyChannelParamsUpdate allows the link to tell the
+	// ChannelNotifier when the channel type or parameters of the channel
+	// were changed by a dynamic commitment.
+	NotifyChannelParamsUpdate func(*chanstate.OpenChannel)
+
+	// DisallowSplicing is a flag that can be used to disable splicing.
+	DisallowSplicing bool
+
+	// SpliceWallet is used to fund, sign and publish the splice
+	// transactions we initiate.
+	SpliceWallet SpliceWallet
+
+	// ChainNotifier is used to watch for the confirmation of splice
+	// transactions.
+	ChainNotifier chainntnfs.ChainNotifier
 }
 
 // channelLink is the service which drives a channel's commitment update
@@ -423,6 +459,36 @@ type channelLink struct {
 	// the result.
 	quiescenceReqs chan StfuReq
 
+	// dynCommitReqs is a queue of locally initiated requests to update
+	// the parameters of this link's channel.
+	dynCommitReqs chan DynCommitReq
+
+	// dynCommit tracks the dynamic commitment this link is currently
+	// negotiating or executing, if any.
+	dynCommit fn.Option[*dynCommitState]
+
+	// dynCommitReplay is set during channel reestablishment if the remote
+	// party executed a dynamic commitment whose DynCommit we never
+	// received, and which they'll retransmit.
+	dynCommitReplay bool
+
+	// spliceReqs is a queue of locally initiated requests to splice this
+	// link's channel.
+	spliceReqs chan SpliceReq
+
+	// splice tracks the splice this link is currently negotiating, if
+	// any.
+	splice fn.Option[*spliceState]
+
+	// spliceRemoteNonce is the verification nonce the remote party sent
+	// in its SpliceLocked for a taproot upgrade, until we locked the
+	// upgrade as well.
+	spliceRemoteNonce fn.Option[lnwire.Musig2Nonce]
+
+	// spliceConfirmed receives the txid of a pending splice transaction
+	// once it's sufficiently confirmed.
+	spliceConfirmed chan chainhash.Hash
+
 	// cg is a helper that encapsulates a wait group and quit channel and
 	// allows contexts that either block or cancel on those depending on
 	// the use case.
@@ -532,6 +598,9 @@ func NewChannelLink(cfg ChannelLinkConfig,
 		incomingCommitHooks: newHookMap(),
 		quiescer:            qsm,
 		quiescenceReqs:      quiescenceReqs,
+		dynCommitReqs:       make(chan DynCommitReq, 1),
+		spliceReqs:          make(chan SpliceReq, 1),
+		spliceConfirmed:     make(chan chainhash.Hash, 1),
 		cg:                  fn.NewContextGuard(),
 	}
 }
@@ -632,6 +701,9 @@ func (l *channelLink) Stop() {
 	// As the link is stopping, we are no longer interested in htlc
 	// resolutions coming from the invoice registry.
 	l.cfg.Registry.HodlUnsubscribeAll(l.hodlQueue.ChanIn())
+	l.cfg.TrampolineForwarder.WhenSome(func(f TrampolineForwarder) {
+		f.HodlUnsubscribeAll(l.hodlQueue.ChanIn())
+	})
 
 	if l.cfg.ChainEvents.Cancel != nil {
 		l.cfg.ChainEvents.Cancel()
@@ -714,7 +786,8 @@ func (l *channelLink) eligibleToUpdate() bool {
 	return l.channel.RemoteNextRevocation() != nil &&
 		l.channel.ShortChanID() != hop.Source &&
 		l.isReestablished() &&
-		l.quiescer.CanSendUpdates()
+		l.quiescer.CanSendUpdates() &&
+		!l.channel.SplicePending()
 }
 
 // EnableAdds sets the ChannelUpdateHandler state to allow UpdateAddHtlc's in
@@ -1040,6 +1113,20 @@ func (l *channelLink) syncChanStates(ctx context.Context) error {
 			return err
 		}
 
+		// A DynCommit the remote party missed needs to be retransmitted
+		// before any commitment that uses the new parameters.
+		err = l.syncDynCommitments(remoteChanSyncMsg)
+		if err != nil {
+			return err
+		}
+
+		// The TxSignatures and SpliceLocked of a pending splice are
+		// retransmitted if the remote party didn't process them.
+		err = l.syncSplice(remoteChanSyncMsg)
+		if err != nil {
+			return err
+		}
+
 		if len(msgsToReSend) > 0 {
 			l.log.Infof("sending %v updates to synchronize the "+
 				"state", len(msgsToReSend))
@@ -1285,6 +1372,10 @@ func (l *channelLink) handleChanSyncErr(err error) {
 // NOTE: This MUST be run as a goroutine.
 func (l *channelLink) htlcManager(ctx context.Context) {
 	defer func() {
+		// A splice that wasn't signed by both parties is abandoned
+		// along with the connection.
+		l.cancelSplice(ErrLinkShuttingDown)
+
 		l.cfg.BatchTicker.Stop()
 		l.cg.WgDone()
 		l.log.Infof("exited")
@@ -1429,6 +1520,37 @@ func (l *channelLink) htlcManager(ctx context.Context) {
 					"req: %v", err)
 			}
 
+		// A user-initiated dynamic commitment request is received.
+		// The channel is quiescent at this point, so we can propose
+		// the new parameters.
+		case dynReq := <-l.dynCommitReqs:
+			err := l.handleDynCommitReq(dynReq)
+			if err != nil {
+				l.log.Errorf("failed to handle dynamic "+
+					"commitment req: %v", err)
+			}
+
+		// A user-initiated splice request is received. The channel is
+		// quiescent at this point, so we can initiate the splice.
+		case spliceReq := <-l.spliceReqs:
+			err := l.handleSpliceReq(spliceReq)
+			if err != nil {
+				l.log.Errorf("failed to handle splice req: %v",
+					err)
+			}
+
+		// The transaction of a pending splice confirmed, so we can
+		// switch over to its funding output.
+		case txid := <-l.spliceConfirmed:
+			err := l.handleSpliceConfirmed(txid)
+			if err != nil {
+				l.failf(LinkFailureError{
+					code: ErrInternalError,
+				}, "unable to lock splice: %v", err)
+
+				return
+			}
+
 		case <-l.cg.Done():
 			return
 		}
@@ -1503,6 +1625,19 @@ func (l *channelLink) processHtlcResolution(resolution invoices.HtlcResolution,
 			res.Preimage, htlc.add.ID, htlc.sourceRef,
 		)
 
+	// Fail htlcs of trampoline payments with the failure the trampoline
+	// forwarder returned.
+	case *TrampolineFailResolution:
+		l.log.Debugf("received trampoline fail resolution for %v: %v",
+			circuitKey, res.Failure)
+
+		l.sendHTLCError(
+			htlc.add, htlc.sourceRef, NewLinkError(res.Failure),
+			htlc.obfuscator, true,
+		)
+
+		return nil
+
 	// For htlc failures, we get the relevant failure message based
 	// on the failure resolution and then fail the htlc.
 	case *invoices.HtlcFailResolution:
@@ -1570,10 +1705,12 @@ func (l *channelLink) handleDownstreamUpdateAdd(ctx context.Context,
 		return errors.New("not an UpdateAddHTLC packet")
 	}
 
-	// If we are flushing the link in the outgoing direction or we have
-	// already sent Stfu, then we can't add new htlcs to the link and we
-	// need to bounce it.
-	if l.IsFlushing(Outgoing) || !l.quiescer.CanSendUpdates() {
+	// If we are flushing the link in the outgoing direction, we have
+	// already sent Stfu or a splice is waiting to be locked, then we can't
+	// add new htlcs to the link and we need to bounce it.
+	if l.IsFlushing(Outgoing) || !l.quiescer.CanSendUpdates() ||
+		l.channel.SplicePending() {
+
 		l.mailBox.FailAdd(pkt)
 
 		return NewDetailedLinkError(
@@ -1694,6 +1831,13 @@ func (l *channelLink) handleDownstreamPkt(ctx context.Context,
 		return
 	}
 
+	if pkt.htlc.MsgType().IsChannelUpdate() && l.channel.SplicePending() {
+		l.log.Warnf("unable to process channel update. "+
+			"ChannelID=%v has a pending splice.", l.ChanID())
+
+		return
+	}
+
 	switch htlc := pkt.htlc.(type) {
 	case *lnwire.UpdateAddHTLC:
 		// Handle add message. The returned error can be ignored,
@@ -1799,6 +1943,13 @@ func (l *channelLink) handleUpstreamMsg(ctx context.Context,
 		return
 	}
 
+	// No updates may be exchanged until a pending splice is locked by
+	// both parties.
+	if msg.MsgType().IsChannelUpdate() && l.channel.SplicePending() {
+		l.spliceFailf("update received with pending splice: %T", msg)
+		return
+	}
+
 	var err error
 
 	switch msg := msg.(type) {
@@ -1814,7 +1965,18 @@ func (l *channelLink) handleUpstreamMsg(ctx context.Context,
 	case *lnwire.UpdateFailHTLC:
 		err = l.processRemoteUpdateFailHTLC(msg)
 
+	// While the commitments of a splice are being signed, the CommitSig
+	// is for the commitment spending the new funding output.
 	case *lnwire.CommitSig:
+		if l.signingSplice() {
+			err = l.handleSpliceCommitSig(msg)
+			if err != nil {
+				l.spliceFailf("handleSpliceCommitSig: %v", err)
+			}
+
+			break
+		}
+
 		err = l.processRemoteCommitSig(ctx, msg)
 
 	case *lnwire.RevokeAndAck:
@@ -1829,6 +1991,68 @@ func (l *channelLink) handleUpstreamMsg(ctx context.Context,
 			l.stfuFailf("handleStfu: %v", err)
 		}
 
+	case *lnwire.DynPropose:
+		err = l.handleDynPropose(msg)
+		if err != nil {
+			l.stfuFailf("handleDynPropose: %v", err)
+		}
+
+	case *lnwire.DynAck:
+		err = l.handleDynAck(ctx, msg)
+		if err != nil {
+			l.dynFailf("handleDynAck: %v", err)
+		}
+
+	case *lnwire.DynReject:
+		err = l.handleDynReject(msg)
+		if err != nil {
+			l.stfuFailf("handleDynReject: %v", err)
+		}
+
+	case *lnwire.DynCommit:
+		err = l.handleDynCommit(msg)
+		if err != nil {
+			l.dynFailf("handleDynCommit: %v", err)
+		}
+
+	case *lnwire.SpliceInit:
+		err = l.handleSpliceInit(msg)
+		if err != nil {
+			l.stfuFailf("handleSpliceInit: %v", err)
+		}
+
+	case *lnwire.SpliceAck:
+		err = l.handleSpliceAck(msg)
+		if err != nil {
+			l.spliceFailf("handleSpliceAck: %v", err)
+		}
+
+	case *lnwire.TxAddInput, *lnwire.TxAddOutput, *lnwire.TxRemoveInput,
+		*lnwire.TxRemoveOutput, *lnwire.TxComplete:
+
+		err = l.handleInteractiveTxMsg(msg)
+		if err != nil {
+			l.spliceFailf("handleInteractiveTxMsg: %v", err)
+		}
+
+	case *lnwire.TxSignatures:
+		err = l.handleTxSignatures(msg)
+		if err != nil {
+			l.spliceFailf("handleTxSignatures: %v", err)
+		}
+
+	case *lnwire.TxAbort:
+		err = l.handleTxAbort(msg)
+		if err != nil {
+			l.spliceFailf("handleTxAbort: %v", err)
+		}
+
+	case *lnwire.SpliceLocked:
+		err = l.handleSpliceLocked(msg)
+		if err != nil {
+			l.spliceFailf("handleSpliceLocked: %v", err)
+		}
+
 	// In the case where we receive a warning message from our peer, just
 	// log it and move on. We choose not to disconnect from our peer,
 	// although we "MAY" do so according to the specification.
@@ -2741,6 +2965,19 @@ func (l *channelLink) Stats() (uint64, lnwire.MilliLoki, lnwire.MilliLoki) {
 		snapshot.TotalMSatReceived
 }
 
+// IncomingLimits returns the limits the remote party must respect when adding
+// htlcs to the channel.
+//
+// NOTE: Part of the ChannelLink interface.
+func (l *channelLink) IncomingLimits() ChannelLimits {
+	localCfg := l.channel.State().LocalChanCfg
+
+	return ChannelLimits{
+		MaxHtlcs:    localCfg.MaxAcceptedHtlcs,
+		MaxInFlight: localCfg.MaxPendingAmount,
+	}
+}
+
 // String returns the string representation of channel link.
 //
 // NOTE: Part of the ChannelLink interface.
@@ -3404,7 +3641,7 @@ func (l *channelLink) experimentalAccountability(
 func (l *channelLink) processExitHop(add lnwire.UpdateAddHTLC,
 	sourceRef channeldb.AddRef, obfuscator hop.ErrorEncrypter,
 	fwdInfo hop.ForwardingInfo, heightNow uint32,
-	payload invoices.Payload) error {
+	payload *hop.Payload) error {
 
 	// If hodl.ExitSettle is requested, we will not validate the final hop's
 	// ADD, nor will we settle the corresponding invoice or respond with the
@@ -3488,10 +3725,45 @@ func (l *channelLink) processExitHop(add lnwire.UpdateAddHTLC,
 		HtlcID: add.ID,
 	}
 
-	event, err := l.cfg.Registry.NotifyExitHopHtlc(
-		invoiceHash, add.Amount, add.Expiry, int32(heightNow),
-		circuitKey, l.hodlQueue.ChanIn(), add.CustomRecords, payload,
+	var (
+		event invoices.HtlcResolution
+		err   error
 	)
+
+	// If the htlc carries a trampoline onion, we're asked to relay the
+	// payment as a trampoline node, so we hand it to the trampoline
+	// forwarder instead of the invoice registry.
+	if payload.TrampolineOnion() != nil {
+		forwarder := l.cfg.TrampolineForwarder.UnwrapOr(nil)
+		if forwarder == nil {
+			l.log.Debugf("rejecting htlc(%x) with trampoline onion, "+
+				"trampoline routing is disabled",
+				add.PaymentHash)
+
+			failure := NewLinkError(lnwire.NewInvalidOnionPayload(
+				uint64(record.TrampolineOnionType), 0,
+			))
+			l.sendHTLCError(add, sourceRef, failure, obfuscator, true)
+
+			return nil
+		}
+
+		event, err = forwarder.NotifyTrampolineHtlc(&TrampolineHtlc{
+			CircuitKey:        circuitKey,
+			PaymentHash:       invoiceHash,
+			Amount:            add.Amount,
+			Expiry:            add.Expiry,
+			CurrentHeight:     heightNow,
+			WireCustomRecords: add.CustomRecords,
+			Payload:           payload,
+		}, l.hodlQueue.ChanIn())
+	} else {
+		event, err = l.cfg.Registry.NotifyExitHopHtlc(
+			invoiceHash, add.Amount, add.Expiry, int32(heightNow),
+			circuitKey, l.hodlQueue.ChanIn(), add.CustomRecords,
+			payload,
+		)
+	}
 	if err != nil {
 		return err
 	}
@@ -3940,6 +4212,15 @@ func (l *channelLink) resumeLink(ctx context.Context) error {
 
 			return err
 		}
+
+		// If a dynamic commitment was interrupted by the disconnect,
+		// we'll continue signing the commitments that use the new
+		// parameters.
+		l.resumeDynCommitment(ctx)
+
+		// A splice that wasn't locked yet needs to be watched for
+		// again.
+		l.resumeSplice()
 	}
 
 	// If a shutdown message has previously been sent on this link, then we
@@ -4431,6 +4712,10 @@ func (l *channelLink) processRemoteCommitSig(ctx context.Context,
 		}
 	}
 
+	// Both commitments may now use the parameters of a pending dynamic
+	// commitment.
+	l.maybeFinishDynCommitment()
+
 	// Now that we have finished processing the incoming CommitSig and sent
 	// out our RevokeAndAck, we invoke the flushHooks if the channel state
 	// is clean.
@@ -4537,6 +4822,10 @@ func (l *channelLink) processRemoteRevokeAndAck(ctx context.Context,
 		}
 	}
 
+	// Both commitments may now use the parameters of a pending dynamic
+	// commitment.
+	l.maybeFinishDynCommitment()
+
 	// Now that we have finished processing the RevokeAndAck, we can invoke
 	// the flushHooks if the channel state is clean.
 	l.Lock()
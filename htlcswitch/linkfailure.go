@@ -57,6 +57,16 @@ const (
 	// time, or that an update has been sent/received while the channel is
 	// quiesced.
 	ErrStfuViolation
+
+	// ErrDynCommitViolation indicates that the dynamic commitments
+	// protocol has been violated, either because a message was received
+	// at an invalid time or because its contents couldn't be verified.
+	ErrDynCommitViolation
+
+	// ErrSpliceViolation indicates that the splicing protocol has been
+	// violated, either because a message was received at an invalid time
+	// or because the negotiated splice couldn't be verified.
+	ErrSpliceViolation
 )
 
 // LinkFailureAction is an enum-like type that describes the action that should
@@ -130,6 +140,10 @@ func (e LinkFailureError) Error() string {
 		return "non-fatal circuit map error"
 	case ErrStfuViolation:
 		return "quiescence protocol executed improperly"
+	case ErrDynCommitViolation:
+		return "dynamic commitments protocol executed improperly"
+	case ErrSpliceViolation:
+		return "splicing protocol executed improperly"
 	default:
 		return "unknown error"
 	}
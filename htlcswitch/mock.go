@@ -23,17 +23,21 @@ import (
 	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/graph/db/models"
 	"github.com/flokiorg/flnd/htlcswitch/hop"
+	"github.com/flokiorg/flnd/input"
 	"github.com/flokiorg/flnd/invoices"
 	"github.com/flokiorg/flnd/lnpeer"
 	"github.com/flokiorg/flnd/lntest/mock"
 	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwallet"
 	"github.com/flokiorg/flnd/lnwallet/chainfee"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/ticker"
 	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/crypto"
 	"github.com/flokiorg/go-flokicoin/crypto/ecdsa"
+	"github.com/flokiorg/go-flokicoin/txscript"
 	"github.com/flokiorg/go-flokicoin/wire"
 	sphinx "github.com/flokiorg/lightning-onion"
 )
@@ -180,7 +184,7 @@ func initSwitchWithDB(startingHeight uint32, db *channeldb.DB) (*Switch, error)
 		FetchAllOpenChannels: db.ChannelStateDB().FetchAllOpenChannels,
 		FetchAllChannels:     db.ChannelStateDB().FetchAllChannels,
 		FetchClosedChannels:  db.ChannelStateDB().FetchClosedChannels,
-		SwitchPackager:       channeldb.NewSwitchPackager(),
+		FwdPkgStore:          db.ChannelStateDB(),
 		FwdingLog: &mockForwardingLog{
 			events: make(map[time.Time]channeldb.ForwardingEvent),
 		},
@@ -631,6 +635,34 @@ func (s *mockServer) readHandler(message lnwire.Message) error {
 		targetChan = msg.ChanID
 	case *lnwire.Stfu:
 		targetChan = msg.ChanID
+	case *lnwire.DynPropose:
+		targetChan = msg.ChanID
+	case *lnwire.DynAck:
+		targetChan = msg.ChanID
+	case *lnwire.DynReject:
+		targetChan = msg.ChanID
+	case *lnwire.DynCommit:
+		targetChan = msg.DynPropose.ChanID
+	case *lnwire.SpliceInit:
+		targetChan = msg.ChanID
+	case *lnwire.SpliceAck:
+		targetChan = msg.ChanID
+	case *lnwire.SpliceLocked:
+		targetChan = msg.ChanID
+	case *lnwire.TxAddInput:
+		targetChan = msg.ChanID
+	case *lnwire.TxAddOutput:
+		targetChan = msg.ChanID
+	case *lnwire.TxRemoveInput:
+		targetChan = msg.ChanID
+	case *lnwire.TxRemoveOutput:
+		targetChan = msg.ChanID
+	case *lnwire.TxComplete:
+		targetChan = msg.ChanID
+	case *lnwire.TxSignatures:
+		targetChan = msg.ChanID
+	case *lnwire.TxAbort:
+		targetChan = msg.ChanID
 	default:
 		return fmt.Errorf("unknown message type: %T", msg)
 	}
@@ -738,6 +770,8 @@ type mockChannelLink struct {
 
 	checkHtlcForwardResult *LinkError
 
+	incomingLimits ChannelLimits
+
 	failAliasUpdate func(sid lnwire.ShortChannelID,
 		incoming bool) *lnwire.ChannelUpdate1
 
@@ -861,6 +895,10 @@ func (f *mockChannelLink) CheckHtlcTransit(payHash [32]byte,
 	return f.checkHtlcTransitResult
 }
 
+func (f *mockChannelLink) IncomingLimits() ChannelLimits {
+	return f.incomingLimits
+}
+
 func (f *mockChannelLink) Stats() (
 	uint64, lnwire.MilliLoki, lnwire.MilliLoki) {
 
@@ -963,6 +1001,24 @@ func (f *mockChannelLink) InitStfu() <-chan fn.Result[lntypes.ChannelParty] {
 	return c
 }
 
+func (f *mockChannelLink) UpdateChannelParams(lnwire.DynPropose) <-chan error {
+	c := make(chan error, 1)
+
+	c <- fmt.Errorf("UpdateChannelParams not implemented")
+
+	return c
+}
+
+func (f *mockChannelLink) Splice(
+	SpliceRequest) <-chan fn.Result[chainhash.Hash] {
+
+	c := make(chan fn.Result[chainhash.Hash], 1)
+
+	c <- fn.Errf[chainhash.Hash]("Splice not implemented")
+
+	return c
+}
+
 func (f *mockChannelLink) FundingCustomBlob() fn.Option[tlv.Blob] {
 	return fn.None[tlv.Blob]()
 }
@@ -1182,3 +1238,99 @@ func (h *mockHTLCNotifier) NotifyFinalHtlcEvent(key models.CircuitKey,
 	info channeldb.FinalHtlcInfo) {
 
 }
+
+func (h *mockHTLCNotifier) NotifyResourceDecisionEvent(key HtlcKey,
+	info HtlcInfo, incomingAccountable bool, decision ForwardDecision) {
+
+}
+
+// mockSpliceWallet is a SpliceWallet that funds splice-ins from inputs that
+// anyone can spend, and records the transactions it publishes.
+type mockSpliceWallet struct {
+	published chan *wire.MsgTx
+}
+
+func newMockSpliceWallet() *mockSpliceWallet {
+	return &mockSpliceWallet{
+		published: make(chan *wire.MsgTx, 1),
+	}
+}
+
+// mockSpliceFee is the fee the mock wallet pays for any splice transaction.
+const mockSpliceFee = chainutil.Amount(1_000)
+
+// anyoneCanSpendScript returns the witness script and P2WSH pkScript of an
+// output that anyone can spend.
+func anyoneCanSpendScript() ([]byte, []byte) {
+	witnessScript := []byte{txscript.OP_TRUE}
+	pkScript, _ := input.WitnessScriptHash(witnessScript)
+
+	return witnessScript, pkScript
+}
+
+func (m *mockSpliceWallet) FundSplice(
+	req *lnwallet.SpliceFundingRequest) (*lnwallet.SpliceFunding, error) {
+
+	if req.Amount < 0 {
+		return &lnwallet.SpliceFunding{
+			Outputs: []*wire.TxOut{{
+				Value:    int64(-req.Amount),
+				PkScript: req.OutputScript,
+			}},
+			Capacity:     req.Capacity + req.Amount - mockSpliceFee,
+			Contribution: req.Amount - mockSpliceFee,
+			Fee:          mockSpliceFee,
+		}, nil
+	}
+
+	_, pkScript := anyoneCanSpendScript()
+	prevTx := wire.NewMsgTx(2)
+	prevTx.AddTxIn(&wire.TxIn{})
+	prevTx.AddTxOut(&wire.TxOut{
+		Value:    int64(req.Amount + mockSpliceFee),
+		PkScript: pkScript,
+	})
+
+	return &lnwallet.SpliceFunding{
+		Inputs: []*lnwallet.Utxo{{
+			Value:    req.Amount + mockSpliceFee,
+			PkScript: pkScript,
+			OutPoint: wire.OutPoint{Hash: prevTx.TxHash()},
+			PrevTx:   prevTx,
+		}},
+		Capacity:     req.Capacity + req.Amount,
+		Contribution: req.Amount,
+		Fee:          mockSpliceFee,
+	}, nil
+}
+
+func (m *mockSpliceWallet) CancelSplice(*lnwallet.SpliceFunding) {}
+
+func (m *mockSpliceWallet) SignSpliceInputs(tx *wire.MsgTx,
+	funding *lnwallet.SpliceFunding,
+	_ txscript.PrevOutputFetcher) ([]wire.TxWitness, error) {
+
+	witnessScript, _ := anyoneCanSpendScript()
+
+	var witnesses []wire.TxWitness
+	for _, txIn := range tx.TxIn {
+		for _, utxo := range funding.Inputs {
+			if utxo.OutPoint != txIn.PreviousOutPoint {
+				continue
+			}
+
+			witness := wire.TxWitness{witnessScript}
+			witnesses = append(witnesses, witness)
+		}
+	}
+
+	return witnesses, nil
+}
+
+func (m *mockSpliceWallet) PublishTransaction(tx *wire.MsgTx,
+	_ string) error {
+
+	m.published <- tx.Copy()
+
+	return nil
+}
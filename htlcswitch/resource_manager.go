@@ -0,0 +1,703 @@
+package htlcswitch
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/clock"
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lnwire"
+)
+
+const (
+	// DefaultRevenueWindow is the default period over which the revenue
+	// of an incoming channel is averaged.
+	DefaultRevenueWindow = 14 * 24 * time.Hour
+
+	// DefaultReputationMultiplier is the default multiple of the revenue
+	// window over which the reputation of an outgoing channel is
+	// averaged.
+	DefaultReputationMultiplier = 12
+
+	// DefaultResolutionPeriod is the default time within which we expect
+	// a well-behaved peer to resolve a htlc we forwarded to it.
+	DefaultResolutionPeriod = 90 * time.Second
+
+	// DefaultGeneralBucketPercent is the default share of the slots and
+	// liquidity of an incoming channel that any forward may use.
+	DefaultGeneralBucketPercent = 40
+
+	// DefaultCongestionBucketPercent is the default share of the slots
+	// and liquidity of an incoming channel that is used once the general
+	// bucket is full.
+	DefaultCongestionBucketPercent = 20
+)
+
+// ResourceBucket identifies the share of the resources of an incoming channel
+// that a forwarded htlc occupies.
+type ResourceBucket uint8
+
+const (
+	// ResourceBucketNone means that the htlc was not granted any
+	// resources and is failed back.
+	ResourceBucketNone ResourceBucket = iota
+
+	// ResourceBucketGeneral is the share of the resources that any
+	// unaccountable htlc may use.
+	ResourceBucketGeneral
+
+	// ResourceBucketCongestion is the share of the resources used by
+	// unaccountable htlcs once the general bucket is full. Each outgoing
+	// channel may only hold a single htlc in it, and loses access if it
+	// holds one for longer than the resolution period.
+	ResourceBucketCongestion
+
+	// ResourceBucketProtected is the share of the resources reserved for
+	// htlcs forwarded to outgoing channels with good reputation.
+	ResourceBucketProtected
+)
+
+// String returns a human-readable name of the bucket.
+func (b ResourceBucket) String() string {
+	switch b {
+	case ResourceBucketNone:
+		return "none"
+
+	case ResourceBucketGeneral:
+		return "general"
+
+	case ResourceBucketCongestion:
+		return "congestion"
+
+	case ResourceBucketProtected:
+		return "protected"
+
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(b))
+	}
+}
+
+// ChannelLimits are the limits the remote party must respect when adding
+// htlcs to a channel, which are the resources a forward locks up on its
+// incoming channel.
+type ChannelLimits struct {
+	// MaxHtlcs is the number of htlcs that may be pending at once.
+	MaxHtlcs uint16
+
+	// MaxInFlight is the total value of htlcs that may be pending at
+	// once.
+	MaxInFlight lnwire.MilliLoki
+}
+
+// ProposedHtlc is a htlc that we were asked to forward, as presented to the
+// resource manager.
+type ProposedHtlc struct {
+	// IncomingCircuit identifies the htlc on its incoming channel.
+	IncomingCircuit models.CircuitKey
+
+	// OutgoingChannel is the channel the htlc is forwarded over.
+	OutgoingChannel lnwire.ShortChannelID
+
+	// IncomingAmount is the amount of the incoming htlc.
+	IncomingAmount lnwire.MilliLoki
+
+	// OutgoingAmount is the amount of the outgoing htlc.
+	OutgoingAmount lnwire.MilliLoki
+
+	// OutgoingExpiry is the expiry height of the outgoing htlc.
+	OutgoingExpiry uint32
+
+	// Height is the current block height.
+	Height uint32
+
+	// Accountable is true if the incoming htlc carried the accountable
+	// signal.
+	Accountable bool
+}
+
+// fee returns the fee we earn if the htlc settles.
+func (p *ProposedHtlc) fee() lnwire.MilliLoki {
+	if p.IncomingAmount <= p.OutgoingAmount {
+		return 0
+	}
+
+	return p.IncomingAmount - p.OutgoingAmount
+}
+
+// ForwardDecision is the outcome of the resource manager's evaluation of a
+// proposed htlc.
+type ForwardDecision struct {
+	// Bucket is the bucket of the incoming channel that the htlc occupies.
+	// ResourceBucketNone means the htlc must be failed back.
+	Bucket ResourceBucket
+
+	// OutgoingAccountable is the accountable signal to set on the
+	// outgoing htlc.
+	OutgoingAccountable bool
+
+	// Reputation is the reputation of the outgoing channel, in msat.
+	Reputation int64
+
+	// Revenue is the revenue of the incoming channel, in msat.
+	Revenue int64
+
+	// InFlightRisk is the risk of the accountable htlcs already in flight
+	// on the outgoing channel.
+	InFlightRisk lnwire.MilliLoki
+
+	// HtlcRisk is the fee we'd lose if the htlc was held until it
+	// expires.
+	HtlcRisk lnwire.MilliLoki
+}
+
+// Admitted returns true if the htlc may be forwarded.
+func (d *ForwardDecision) Admitted() bool {
+	return d.Bucket != ResourceBucketNone
+}
+
+// ResourceManagerConfig holds the parameters and dependencies of the
+// ResourceManager.
+type ResourceManagerConfig struct {
+	// DB is the database the channel reputations and in-flight htlcs are
+	// stored in. It must be the database the switch stores its circuits
+	// in, so that in-flight htlcs whose circuit is gone can be pruned.
+	DB kvdb.Backend
+
+	// FetchClosedChannels fetches all closed channels from the channel
+	// database, so that their state can be pruned on start.
+	FetchClosedChannels func(
+		pendingOnly bool) ([]*chanstate.ChannelCloseSummary, error)
+
+	// Clock is used to age the revenue and reputation and to time the
+	// resolution of htlcs.
+	Clock clock.Clock
+
+	// RevenueWindow is the period over which the revenue of incoming
+	// channels is averaged.
+	RevenueWindow time.Duration
+
+	// ReputationMultiplier is the multiple of the revenue window over
+	// which the reputation of outgoing channels is averaged.
+	ReputationMultiplier uint8
+
+	// ResolutionPeriod is the time within which htlcs are expected to
+	// resolve. Accountable htlcs held longer are charged an opportunity
+	// cost to the reputation of their outgoing channel.
+	ResolutionPeriod time.Duration
+
+	// BlockTime is the expected time between two blocks, used to turn the
+	// expiry of a htlc into the longest time it may be held.
+	BlockTime time.Duration
+
+	// GeneralBucketPercent is the share of slots and liquidity of an
+	// incoming channel that any htlc may use.
+	GeneralBucketPercent uint8
+
+	// CongestionBucketPercent is the share of slots and liquidity of an
+	// incoming channel that is used once the general bucket is full. The
+	// remainder makes up the protected bucket.
+	CongestionBucketPercent uint8
+}
+
+// reputationWindow returns the period over which reputation is averaged.
+func (c *ResourceManagerConfig) reputationWindow() time.Duration {
+	return c.RevenueWindow * time.Duration(c.ReputationMultiplier)
+}
+
+// decayingAverage is a value that decays over time, so it approximates the
+// sum of the amounts added within a window.
+type decayingAverage struct {
+	value      float64
+	lastUpdate time.Time
+}
+
+// valueAt returns the value decayed to the given time. The value halves every
+// half window.
+func (d *decayingAverage) valueAt(now time.Time, window time.Duration) float64 {
+	elapsed := now.Sub(d.lastUpdate)
+	if d.lastUpdate.IsZero() || elapsed <= 0 || window <= 0 {
+		return d.value
+	}
+
+	return d.value * math.Pow(0.5, 2*elapsed.Seconds()/window.Seconds())
+}
+
+// add decays the value to the given time and adds the amount to it.
+func (d *decayingAverage) add(amt float64, now time.Time,
+	window time.Duration) {
+
+	d.value = d.valueAt(now, window) + amt
+	d.lastUpdate = now
+}
+
+// channelReputation is what the resource manager knows about a channel.
+type channelReputation struct {
+	// revenue is the decaying average of the fees earned by htlcs that
+	// arrived on the channel.
+	revenue decayingAverage
+
+	// reputation is the decaying average of the effective fees of htlcs
+	// we forwarded over the channel.
+	reputation decayingAverage
+
+	// congestionMisuse is the last time the channel held a htlc in the
+	// congestion bucket for longer than the resolution period.
+	congestionMisuse time.Time
+}
+
+// inFlightHtlc is a htlc that was granted resources and hasn't resolved yet.
+type inFlightHtlc struct {
+	incoming    models.CircuitKey
+	outgoing    lnwire.ShortChannelID
+	amount      lnwire.MilliLoki
+	fee         lnwire.MilliLoki
+	risk        lnwire.MilliLoki
+	bucket      ResourceBucket
+	accountable bool
+	addedAt     time.Time
+}
+
+// ResourceManager protects the slots and liquidity of incoming channels from
+// being jammed. It tracks the revenue earned by each incoming channel and the
+// reputation of each outgoing channel, which is built up by resolving the
+// htlcs we forward over it quickly. The resources of an incoming channel are
+// split into general, congestion and protected buckets. Htlcs are only
+// admitted to the protected bucket if the reputation of their outgoing
+// channel exceeds the revenue of their incoming channel, so a peer that holds
+// htlcs can't lock up more than the general and congestion buckets.
+type ResourceManager struct {
+	cfg *ResourceManagerConfig
+
+	store *resourceStore
+
+	mu sync.Mutex
+
+	// channels holds the revenue and reputation of each channel.
+	channels map[lnwire.ShortChannelID]*channelReputation
+
+	// inFlight holds the htlcs that were granted resources, indexed by
+	// their incoming channel.
+	inFlight map[lnwire.ShortChannelID]map[models.CircuitKey]*inFlightHtlc
+
+	// outgoingRisk is the total risk of the accountable htlcs in flight
+	// on each outgoing channel.
+	outgoingRisk map[lnwire.ShortChannelID]lnwire.MilliLoki
+}
+
+// NewResourceManager creates a resource manager, restoring the reputations
+// and in-flight htlcs from its database. In-flight htlcs that resolved while
+// we weren't tracking them, and the state of channels that were closed, are
+// pruned first.
+func NewResourceManager(cfg *ResourceManagerConfig) (*ResourceManager,
+	error) {
+
+	total := uint16(cfg.GeneralBucketPercent) +
+		uint16(cfg.CongestionBucketPercent)
+	if total > 100 {
+		return nil, fmt.Errorf("general (%d%%) and congestion (%d%%) "+
+			"buckets exceed 100%%", cfg.GeneralBucketPercent,
+			cfg.CongestionBucketPercent)
+	}
+
+	r := &ResourceManager{
+		cfg:      cfg,
+		store:    newResourceStore(cfg.DB),
+		channels: make(map[lnwire.ShortChannelID]*channelReputation),
+		inFlight: make(
+			map[lnwire.ShortChannelID]map[models.CircuitKey]*inFlightHtlc,
+		),
+		outgoingRisk: make(map[lnwire.ShortChannelID]lnwire.MilliLoki),
+	}
+
+	if err := r.prune(); err != nil {
+		return nil, fmt.Errorf("unable to prune resource manager "+
+			"state: %w", err)
+	}
+
+	channels, htlcs, err := r.store.fetchAll()
+	if err != nil {
+		return nil, err
+	}
+	r.channels = channels
+	for _, htlc := range htlcs {
+		r.trackHtlc(htlc)
+	}
+
+	log.Infof("Resource manager restored %d channels and %d in-flight "+
+		"htlcs", len(channels), len(htlcs))
+
+	return r, nil
+}
+
+// prune removes the persisted in-flight htlcs whose circuit no longer exists
+// or whose incoming channel was closed, and the reputations of closed
+// channels.
+func (r *ResourceManager) prune() error {
+	closedChannels, err := r.cfg.FetchClosedChannels(false)
+	if err != nil {
+		return err
+	}
+
+	closed := make(map[lnwire.ShortChannelID]struct{})
+	for _, closedChannel := range closedChannels {
+		// Skip if the channel close is pending, its htlcs may still
+		// be resolved on chain.
+		if closedChannel.IsPending {
+			continue
+		}
+
+		closed[closedChannel.ShortChanID] = struct{}{}
+	}
+
+	numHtlcs, numChannels, err := r.store.prune(closed)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Resource manager pruned %d stale in-flight htlcs and %d "+
+		"closed channels", numHtlcs, numChannels)
+
+	return nil
+}
+
+// channel returns the reputation of the given channel, creating it if it's
+// unknown.
+//
+// NOTE: Must be called with the mutex held.
+func (r *ResourceManager) channel(
+	scid lnwire.ShortChannelID) *channelReputation {
+
+	c, ok := r.channels[scid]
+	if !ok {
+		c = &channelReputation{}
+		r.channels[scid] = c
+	}
+
+	return c
+}
+
+// trackHtlc adds the htlc to the in-flight indexes.
+//
+// NOTE: Must be called with the mutex held.
+func (r *ResourceManager) trackHtlc(htlc *inFlightHtlc) {
+	htlcs, ok := r.inFlight[htlc.incoming.ChanID]
+	if !ok {
+		htlcs = make(map[models.CircuitKey]*inFlightHtlc)
+		r.inFlight[htlc.incoming.ChanID] = htlcs
+	}
+	htlcs[htlc.incoming] = htlc
+
+	if htlc.accountable {
+		r.outgoingRisk[htlc.outgoing] += htlc.risk
+	}
+}
+
+// untrackHtlc removes the htlc from the in-flight indexes.
+//
+// NOTE: Must be called with the mutex held.
+func (r *ResourceManager) untrackHtlc(htlc *inFlightHtlc) {
+	htlcs := r.inFlight[htlc.incoming.ChanID]
+	delete(htlcs, htlc.incoming)
+	if len(htlcs) == 0 {
+		delete(r.inFlight, htlc.incoming.ChanID)
+	}
+
+	if htlc.accountable {
+		r.outgoingRisk[htlc.outgoing] -= htlc.risk
+		if r.outgoingRisk[htlc.outgoing] == 0 {
+			delete(r.outgoingRisk, htlc.outgoing)
+		}
+	}
+}
+
+// htlcRisk returns the fee we'd lose if the htlc was held until it expires,
+// which is its fee for every resolution period it may be held.
+func (r *ResourceManager) htlcRisk(htlc *ProposedHtlc) lnwire.MilliLoki {
+	if htlc.OutgoingExpiry <= htlc.Height || r.cfg.ResolutionPeriod <= 0 {
+		return 0
+	}
+
+	maxHold := time.Duration(htlc.OutgoingExpiry-htlc.Height) *
+		r.cfg.BlockTime
+	periods := (maxHold + r.cfg.ResolutionPeriod - 1) /
+		r.cfg.ResolutionPeriod
+
+	return htlc.fee() * lnwire.MilliLoki(periods)
+}
+
+// bucketSize returns the slots and liquidity of the bucket on a channel with
+// the given limits.
+func (r *ResourceManager) bucketSize(bucket ResourceBucket,
+	limits ChannelLimits) (int, lnwire.MilliLoki) {
+
+	share := func(percent uint8) (int, lnwire.MilliLoki) {
+		slots := int(limits.MaxHtlcs) * int(percent) / 100
+		liquidity := limits.MaxInFlight * lnwire.MilliLoki(percent) / 100
+
+		return slots, liquidity
+	}
+
+	switch bucket {
+	case ResourceBucketGeneral:
+		return share(r.cfg.GeneralBucketPercent)
+
+	case ResourceBucketCongestion:
+		return share(r.cfg.CongestionBucketPercent)
+
+	case ResourceBucketProtected:
+		genSlots, genLiquidity := share(r.cfg.GeneralBucketPercent)
+		conSlots, conLiquidity := share(r.cfg.CongestionBucketPercent)
+
+		return int(limits.MaxHtlcs) - genSlots - conSlots,
+			limits.MaxInFlight - genLiquidity - conLiquidity
+
+	default:
+		return 0, 0
+	}
+}
+
+// hasRoom returns true if the bucket of the incoming channel can hold another
+// htlc of the given amount.
+//
+// NOTE: Must be called with the mutex held.
+func (r *ResourceManager) hasRoom(incoming lnwire.ShortChannelID,
+	bucket ResourceBucket, amt lnwire.MilliLoki,
+	limits ChannelLimits) bool {
+
+	slots, liquidity := r.bucketSize(bucket, limits)
+
+	var (
+		used   int
+		locked lnwire.MilliLoki
+	)
+	for _, htlc := range r.inFlight[incoming] {
+		if htlc.bucket != bucket {
+			continue
+		}
+
+		used++
+		locked += htlc.amount
+	}
+
+	return used < slots && locked+amt <= liquidity
+}
+
+// congestionAllowed returns true if the outgoing channel may use the
+// congestion bucket of the incoming channel. It may hold a single htlc in it,
+// and only if it didn't hold one for too long within the revenue window.
+//
+// NOTE: Must be called with the mutex held.
+func (r *ResourceManager) congestionAllowed(htlc *ProposedHtlc,
+	now time.Time) bool {
+
+	misuse := r.channel(htlc.OutgoingChannel).congestionMisuse
+	if !misuse.IsZero() && now.Sub(misuse) < r.cfg.RevenueWindow {
+		return false
+	}
+
+	for _, inFlight := range r.inFlight[htlc.IncomingCircuit.ChanID] {
+		if inFlight.bucket == ResourceBucketCongestion &&
+			inFlight.outgoing == htlc.OutgoingChannel {
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// AddHtlc decides whether the proposed htlc may lock up resources of its
+// incoming channel, whose limits are given, and which accountable signal to
+// forward it with. If the htlc is admitted, it's tracked until it's resolved
+// through ResolveHtlc.
+func (r *ResourceManager) AddHtlc(htlc *ProposedHtlc,
+	limits ChannelLimits) (*ForwardDecision, error) {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.cfg.Clock.Now()
+
+	// A htlc that is already tracked was admitted before, which can happen
+	// if it's replayed after a restart.
+	key := htlc.IncomingCircuit
+	if existing, ok := r.inFlight[key.ChanID][key]; ok {
+		return &ForwardDecision{
+			Bucket:              existing.bucket,
+			OutgoingAccountable: existing.accountable,
+		}, nil
+	}
+
+	incoming := r.channel(htlc.IncomingCircuit.ChanID)
+	outgoing := r.channel(htlc.OutgoingChannel)
+
+	revenue := incoming.revenue.valueAt(now, r.cfg.RevenueWindow)
+	reputation := outgoing.reputation.valueAt(
+		now, r.cfg.reputationWindow(),
+	)
+
+	decision := &ForwardDecision{
+		Reputation:   int64(reputation),
+		Revenue:      int64(revenue),
+		InFlightRisk: r.outgoingRisk[htlc.OutgoingChannel],
+		HtlcRisk:     r.htlcRisk(htlc),
+	}
+
+	// The outgoing channel has good reputation if it earned us more than
+	// the incoming channel, even if all the accountable htlcs it holds,
+	// including this one, are held until they expire.
+	reputable := reputation-float64(decision.InFlightRisk)-
+		float64(decision.HtlcRisk) >= revenue
+
+	hasRoom := func(bucket ResourceBucket) bool {
+		return r.hasRoom(
+			htlc.IncomingCircuit.ChanID, bucket,
+			htlc.IncomingAmount, limits,
+		)
+	}
+
+	switch {
+	// Accountable htlcs to reputable channels may use the protected
+	// bucket, and the general one if that's full.
+	case htlc.Accountable && reputable:
+		decision.OutgoingAccountable = true
+
+		switch {
+		case hasRoom(ResourceBucketProtected):
+			decision.Bucket = ResourceBucketProtected
+
+		case hasRoom(ResourceBucketGeneral):
+			decision.Bucket = ResourceBucketGeneral
+		}
+
+	// We don't take on the accountability for a htlc whose outgoing
+	// channel could make us lose reputation with the incoming peer.
+	case htlc.Accountable:
+
+	// Unaccountable htlcs use the general bucket and are forwarded as
+	// unaccountable.
+	case hasRoom(ResourceBucketGeneral):
+		decision.Bucket = ResourceBucketGeneral
+
+	// Once the general bucket is full, reputable channels may use the
+	// protected bucket, but then need to resolve the htlc quickly.
+	case reputable && hasRoom(ResourceBucketProtected):
+		decision.Bucket = ResourceBucketProtected
+		decision.OutgoingAccountable = true
+
+	// Otherwise, the htlc may use the congestion bucket if its outgoing
+	// channel hasn't misused it.
+	case r.congestionAllowed(htlc, now) &&
+		hasRoom(ResourceBucketCongestion):
+
+		decision.Bucket = ResourceBucketCongestion
+		decision.OutgoingAccountable = true
+	}
+
+	log.Debugf("Resource decision for htlc %v -> %v: bucket=%v, "+
+		"accountable=%v->%v, reputation=%v, revenue=%v, "+
+		"in_flight_risk=%v, htlc_risk=%v", htlc.IncomingCircuit,
+		htlc.OutgoingChannel, decision.Bucket, htlc.Accountable,
+		decision.OutgoingAccountable, decision.Reputation,
+		decision.Revenue, decision.InFlightRisk, decision.HtlcRisk)
+
+	if !decision.Admitted() {
+		return decision, nil
+	}
+
+	inFlight := &inFlightHtlc{
+		incoming:    htlc.IncomingCircuit,
+		outgoing:    htlc.OutgoingChannel,
+		amount:      htlc.IncomingAmount,
+		fee:         htlc.fee(),
+		risk:        decision.HtlcRisk,
+		bucket:      decision.Bucket,
+		accountable: decision.OutgoingAccountable,
+		addedAt:     now,
+	}
+	if err := r.store.addHtlc(inFlight); err != nil {
+		return nil, err
+	}
+	r.trackHtlc(inFlight)
+
+	return decision, nil
+}
+
+// ResolveHtlc releases the resources of the htlc with the given incoming
+// circuit key, and updates the revenue of its incoming channel and the
+// reputation of its outgoing channel based on its outcome. Htlcs that aren't
+// tracked are ignored.
+func (r *ResourceManager) ResolveHtlc(key models.CircuitKey,
+	settled bool) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	htlc, ok := r.inFlight[key.ChanID][key]
+	if !ok {
+		return nil
+	}
+
+	now := r.cfg.Clock.Now()
+	holdTime := now.Sub(htlc.addedAt)
+	fast := holdTime <= r.cfg.ResolutionPeriod
+
+	incoming := r.channel(htlc.incoming.ChanID)
+	outgoing := r.channel(htlc.outgoing)
+
+	// Unaccountable htlcs only build reputation if they settle quickly,
+	// while accountable htlcs held for longer than the resolution period
+	// cost their outgoing channel the fees we could have earned with the
+	// resources meanwhile.
+	var effectiveFee float64
+	switch {
+	case htlc.accountable:
+		if settled {
+			effectiveFee = float64(htlc.fee)
+		}
+
+		if !fast {
+			periods := math.Ceil(
+				float64(holdTime-r.cfg.ResolutionPeriod) /
+					float64(r.cfg.ResolutionPeriod),
+			)
+			effectiveFee -= periods * float64(htlc.fee)
+		}
+
+	case settled && fast:
+		effectiveFee = float64(htlc.fee)
+	}
+
+	outgoing.reputation.add(effectiveFee, now, r.cfg.reputationWindow())
+
+	if settled {
+		incoming.revenue.add(
+			float64(htlc.fee), now, r.cfg.RevenueWindow,
+		)
+	}
+
+	if htlc.bucket == ResourceBucketCongestion && !fast {
+		outgoing.congestionMisuse = now
+	}
+
+	err := r.store.resolveHtlc(
+		key, map[lnwire.ShortChannelID]*channelReputation{
+			htlc.incoming.ChanID: incoming,
+			htlc.outgoing:        outgoing,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	r.untrackHtlc(htlc)
+
+	log.Debugf("Resolved htlc %v -> %v (settled=%v) after %v: "+
+		"effective_fee=%v", htlc.incoming, htlc.outgoing, settled,
+		holdTime, effectiveFee)
+
+	return nil
+}
@@ -0,0 +1,368 @@
+package htlcswitch
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/clock"
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testResourceLimits = ChannelLimits{
+		MaxHtlcs:    10,
+		MaxInFlight: 100_000_000,
+	}
+
+	testResourceStart = time.Unix(1_700_000_000, 0)
+)
+
+// newTestResourceManager creates a resource manager backed by a fresh
+// database, returning the database so it can be restarted.
+func newTestResourceManager(t *testing.T) (*ResourceManager, kvdb.Backend,
+	*clock.TestClock) {
+
+	dbPath := filepath.Join(t.TempDir(), "testdb")
+	db, err := kvdb.Create(
+		kvdb.BoltBackendName, dbPath, true, kvdb.DefaultDBTimeout,
+		false,
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	testClock := clock.NewTestClock(testResourceStart)
+
+	return restartTestResourceManager(t, db, testClock), db, testClock
+}
+
+// restartTestResourceManager creates a resource manager on an existing
+// database, in which the given channels are closed.
+func restartTestResourceManager(t *testing.T, db kvdb.Backend,
+	testClock *clock.TestClock,
+	closed ...lnwire.ShortChannelID) *ResourceManager {
+
+	fetchClosedChannels := func(
+		bool) ([]*chanstate.ChannelCloseSummary, error) {
+
+		var summaries []*chanstate.ChannelCloseSummary
+		for _, scid := range closed {
+			summaries = append(
+				summaries, &chanstate.ChannelCloseSummary{
+					ShortChanID: scid,
+				},
+			)
+		}
+
+		return summaries, nil
+	}
+
+	mgr, err := NewResourceManager(&ResourceManagerConfig{
+		DB:                      db,
+		FetchClosedChannels:     fetchClosedChannels,
+		Clock:                   testClock,
+		RevenueWindow:           DefaultRevenueWindow,
+		ReputationMultiplier:    DefaultReputationMultiplier,
+		ResolutionPeriod:        DefaultResolutionPeriod,
+		BlockTime:               time.Minute,
+		GeneralBucketPercent:    DefaultGeneralBucketPercent,
+		CongestionBucketPercent: DefaultCongestionBucketPercent,
+	})
+	require.NoError(t, err)
+
+	return mgr
+}
+
+// addTestCircuit stores the circuit of the htlc with the given incoming key, as
+// the switch does once it forwards the htlc.
+func addTestCircuit(t *testing.T, db kvdb.Backend, key models.CircuitKey) {
+	circuit := &PaymentCircuit{Incoming: key}
+
+	var b bytes.Buffer
+	require.NoError(t, circuit.Encode(&b))
+
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		bucket, err := tx.CreateTopLevelBucket(circuitAddKey)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key.Bytes(), b.Bytes())
+	}, func() {})
+	require.NoError(t, err)
+}
+
+// testProposedHtlc returns a htlc forwarded from the incoming to the outgoing
+// channel that pays the given fee and expires in a single block.
+func testProposedHtlc(incoming, outgoing uint64, htlcID uint64,
+	fee lnwire.MilliLoki, accountable bool) *ProposedHtlc {
+
+	return &ProposedHtlc{
+		IncomingCircuit: models.CircuitKey{
+			ChanID: lnwire.NewShortChanIDFromInt(incoming),
+			HtlcID: htlcID,
+		},
+		OutgoingChannel: lnwire.NewShortChanIDFromInt(outgoing),
+		IncomingAmount:  10_000 + fee,
+		OutgoingAmount:  10_000,
+		OutgoingExpiry:  101,
+		Height:          100,
+		Accountable:     accountable,
+	}
+}
+
+// TestResourceManagerBuckets tests that htlcs to outgoing channels without
+// reputation fill up the general bucket, followed by a single htlc per
+// outgoing channel in the congestion bucket.
+func TestResourceManagerBuckets(t *testing.T) {
+	t.Parallel()
+
+	mgr, _, _ := newTestResourceManager(t)
+
+	// The general bucket holds 40% of the 10 slots. Unaccountable htlcs
+	// are forwarded as unaccountable while they fit in it.
+	for i := uint64(0); i < 4; i++ {
+		decision, err := mgr.AddHtlc(
+			testProposedHtlc(1, 2, i, 100, false),
+			testResourceLimits,
+		)
+		require.NoError(t, err)
+		require.Equal(t, ResourceBucketGeneral, decision.Bucket)
+		require.False(t, decision.OutgoingAccountable)
+	}
+
+	// Once it's full, the outgoing channel may use the congestion bucket
+	// once, as accountable.
+	decision, err := mgr.AddHtlc(
+		testProposedHtlc(1, 2, 4, 100, false), testResourceLimits,
+	)
+	require.NoError(t, err)
+	require.Equal(t, ResourceBucketCongestion, decision.Bucket)
+	require.True(t, decision.OutgoingAccountable)
+
+	decision, err = mgr.AddHtlc(
+		testProposedHtlc(1, 2, 5, 100, false), testResourceLimits,
+	)
+	require.NoError(t, err)
+	require.False(t, decision.Admitted())
+
+	// Another outgoing channel may still use the congestion bucket.
+	decision, err = mgr.AddHtlc(
+		testProposedHtlc(1, 3, 6, 100, false), testResourceLimits,
+	)
+	require.NoError(t, err)
+	require.Equal(t, ResourceBucketCongestion, decision.Bucket)
+
+	// An accountable htlc to a channel without reputation is rejected.
+	decision, err = mgr.AddHtlc(
+		testProposedHtlc(4, 2, 0, 100, true), testResourceLimits,
+	)
+	require.NoError(t, err)
+	require.False(t, decision.Admitted())
+
+	// Resolving a htlc frees its slot in the general bucket.
+	err = mgr.ResolveHtlc(
+		testProposedHtlc(1, 2, 0, 100, false).IncomingCircuit, false,
+	)
+	require.NoError(t, err)
+
+	decision, err = mgr.AddHtlc(
+		testProposedHtlc(1, 2, 7, 100, false), testResourceLimits,
+	)
+	require.NoError(t, err)
+	require.Equal(t, ResourceBucketGeneral, decision.Bucket)
+}
+
+// TestResourceManagerReputation tests that fast settles build the reputation
+// of the outgoing channel, granting it access to the protected bucket, and
+// that slow accountable htlcs cost it reputation.
+func TestResourceManagerReputation(t *testing.T) {
+	t.Parallel()
+
+	mgr, _, testClock := newTestResourceManager(t)
+
+	// Settle a htlc from channel 1 to channel 2 quickly, which builds the
+	// reputation of channel 2 and the revenue of channel 1.
+	htlc := testProposedHtlc(1, 2, 0, 1_000, false)
+	_, err := mgr.AddHtlc(htlc, testResourceLimits)
+	require.NoError(t, err)
+	require.NoError(t, mgr.ResolveHtlc(htlc.IncomingCircuit, true))
+
+	// An accountable htlc from channel 3, which hasn't earned us anything,
+	// to channel 2 is granted protected resources.
+	htlc = testProposedHtlc(3, 2, 0, 100, true)
+	decision, err := mgr.AddHtlc(htlc, testResourceLimits)
+	require.NoError(t, err)
+	require.Equal(t, ResourceBucketProtected, decision.Bucket)
+	require.True(t, decision.OutgoingAccountable)
+	require.EqualValues(t, 1_000, decision.Reputation)
+	require.Zero(t, decision.Revenue)
+	require.EqualValues(t, 100, decision.HtlcRisk)
+
+	// The in-flight htlc now counts against the reputation of channel 2.
+	decision, err = mgr.AddHtlc(
+		testProposedHtlc(3, 2, 1, 100, true), testResourceLimits,
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 100, decision.InFlightRisk)
+	require.NoError(t, mgr.ResolveHtlc(
+		testProposedHtlc(3, 2, 1, 100, true).IncomingCircuit, true,
+	))
+
+	// Channel 1 earned us as much as channel 2, so its accountable htlcs
+	// can't be covered by the reputation of channel 2.
+	decision, err = mgr.AddHtlc(
+		testProposedHtlc(1, 2, 1, 100, true), testResourceLimits,
+	)
+	require.NoError(t, err)
+	require.False(t, decision.Admitted())
+
+	// Holding the accountable htlc for three resolution periods costs
+	// channel 2 two periods worth of fees, minus the fee it earned.
+	testClock.SetTime(testResourceStart.Add(3 * DefaultResolutionPeriod))
+	require.NoError(t, mgr.ResolveHtlc(htlc.IncomingCircuit, true))
+
+	outgoing := mgr.channels[lnwire.NewShortChanIDFromInt(2)]
+	reputation := outgoing.reputation.valueAt(
+		testClock.Now(), mgr.cfg.reputationWindow(),
+	)
+	require.InDelta(t, 1_000+100-2*100+100, reputation, 1)
+}
+
+// TestResourceManagerCongestionMisuse tests that an outgoing channel that
+// holds a htlc in the congestion bucket for too long is barred from it.
+func TestResourceManagerCongestionMisuse(t *testing.T) {
+	t.Parallel()
+
+	mgr, _, testClock := newTestResourceManager(t)
+
+	for i := uint64(0); i < 4; i++ {
+		_, err := mgr.AddHtlc(
+			testProposedHtlc(1, 2, i, 100, false),
+			testResourceLimits,
+		)
+		require.NoError(t, err)
+	}
+
+	htlc := testProposedHtlc(1, 3, 4, 100, false)
+	decision, err := mgr.AddHtlc(htlc, testResourceLimits)
+	require.NoError(t, err)
+	require.Equal(t, ResourceBucketCongestion, decision.Bucket)
+
+	testClock.SetTime(testResourceStart.Add(2 * DefaultResolutionPeriod))
+	require.NoError(t, mgr.ResolveHtlc(htlc.IncomingCircuit, false))
+
+	decision, err = mgr.AddHtlc(
+		testProposedHtlc(1, 3, 5, 100, false), testResourceLimits,
+	)
+	require.NoError(t, err)
+	require.False(t, decision.Admitted())
+
+	// The channel may use the congestion bucket again once the revenue
+	// window has passed.
+	testClock.SetTime(testClock.Now().Add(DefaultRevenueWindow))
+	decision, err = mgr.AddHtlc(
+		testProposedHtlc(1, 3, 6, 100, false), testResourceLimits,
+	)
+	require.NoError(t, err)
+	require.Equal(t, ResourceBucketCongestion, decision.Bucket)
+}
+
+// TestResourceManagerRestart tests that reputations and in-flight htlcs are
+// restored from the database.
+func TestResourceManagerRestart(t *testing.T) {
+	t.Parallel()
+
+	mgr, db, testClock := newTestResourceManager(t)
+
+	settled := testProposedHtlc(1, 2, 0, 1_000, false)
+	_, err := mgr.AddHtlc(settled, testResourceLimits)
+	require.NoError(t, err)
+	require.NoError(t, mgr.ResolveHtlc(settled.IncomingCircuit, true))
+
+	pending := testProposedHtlc(3, 2, 0, 100, true)
+	decision, err := mgr.AddHtlc(pending, testResourceLimits)
+	require.NoError(t, err)
+	require.Equal(t, ResourceBucketProtected, decision.Bucket)
+	addTestCircuit(t, db, pending.IncomingCircuit)
+
+	mgr = restartTestResourceManager(t, db, testClock)
+
+	// The pending htlc is still tracked, so replaying it returns its
+	// original decision.
+	decision, err = mgr.AddHtlc(pending, testResourceLimits)
+	require.NoError(t, err)
+	require.Equal(t, ResourceBucketProtected, decision.Bucket)
+	require.True(t, decision.OutgoingAccountable)
+
+	// The reputation of channel 2 and the risk of its pending htlc were
+	// restored as well.
+	decision, err = mgr.AddHtlc(
+		testProposedHtlc(3, 2, 1, 100, true), testResourceLimits,
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 1_000, decision.Reputation)
+	require.EqualValues(t, 100, decision.InFlightRisk)
+
+	require.NoError(t, mgr.ResolveHtlc(pending.IncomingCircuit, true))
+	require.NotContains(
+		t, mgr.inFlight[pending.IncomingCircuit.ChanID],
+		pending.IncomingCircuit,
+	)
+}
+
+// TestResourceManagerPrune tests that in-flight htlcs whose circuit is gone or
+// whose incoming channel was closed, and the reputations of closed channels,
+// are pruned on start.
+func TestResourceManagerPrune(t *testing.T) {
+	t.Parallel()
+
+	mgr, db, testClock := newTestResourceManager(t)
+
+	// Build up reputation for channels 2 and 4.
+	for i, outgoing := range []uint64{2, 4} {
+		settled := testProposedHtlc(1, outgoing, uint64(i), 1_000, false)
+		_, err := mgr.AddHtlc(settled, testResourceLimits)
+		require.NoError(t, err)
+		require.NoError(
+			t, mgr.ResolveHtlc(settled.IncomingCircuit, true),
+		)
+	}
+
+	// Forward a htlc with a circuit, one whose circuit was removed while
+	// we weren't tracking it, and one from a channel that gets closed.
+	live := testProposedHtlc(3, 2, 0, 100, false)
+	stale := testProposedHtlc(3, 2, 1, 100, false)
+	closed := testProposedHtlc(5, 2, 0, 100, false)
+	for _, htlc := range []*ProposedHtlc{live, stale, closed} {
+		_, err := mgr.AddHtlc(htlc, testResourceLimits)
+		require.NoError(t, err)
+	}
+	addTestCircuit(t, db, live.IncomingCircuit)
+	addTestCircuit(t, db, closed.IncomingCircuit)
+
+	mgr = restartTestResourceManager(
+		t, db, testClock, lnwire.NewShortChanIDFromInt(4),
+		lnwire.NewShortChanIDFromInt(5),
+	)
+
+	require.Len(t, mgr.inFlight, 1)
+	require.Contains(
+		t, mgr.inFlight[live.IncomingCircuit.ChanID],
+		live.IncomingCircuit,
+	)
+	require.NotContains(
+		t, mgr.inFlight[stale.IncomingCircuit.ChanID],
+		stale.IncomingCircuit,
+	)
+
+	require.Contains(t, mgr.channels, lnwire.NewShortChanIDFromInt(2))
+	require.NotContains(t, mgr.channels, lnwire.NewShortChanIDFromInt(4))
+}
@@ -0,0 +1,426 @@
+package htlcswitch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lnwire"
+)
+
+var (
+	// resourceBucketKey is the root level bucket of the resource manager.
+	resourceBucketKey = []byte("resource-manager-bucket")
+
+	// resourceChannelsKey is the sub-bucket that stores the
+	// ShortChannelID -> channelReputation mapping.
+	resourceChannelsKey = []byte("channels")
+
+	// resourceInFlightKey is the sub-bucket that stores the
+	// CircuitKey -> inFlightHtlc mapping of the htlcs that hold resources.
+	resourceInFlightKey = []byte("in-flight")
+)
+
+// resourceStore persists the state of the ResourceManager, so reputations
+// survive restarts and the resources held by in-flight htlcs are released
+// once they resolve.
+type resourceStore struct {
+	backend kvdb.Backend
+}
+
+func newResourceStore(db kvdb.Backend) *resourceStore {
+	return &resourceStore{
+		backend: db,
+	}
+}
+
+// fetchAll returns all stored channel reputations and in-flight htlcs.
+func (r *resourceStore) fetchAll() (map[lnwire.ShortChannelID]*channelReputation,
+	[]*inFlightHtlc, error) {
+
+	var (
+		channels map[lnwire.ShortChannelID]*channelReputation
+		htlcs    []*inFlightHtlc
+	)
+	err := kvdb.View(r.backend, func(tx kvdb.RTx) error {
+		rootBucket := tx.ReadBucket(resourceBucketKey)
+		if rootBucket == nil {
+			return nil
+		}
+
+		chanBucket := rootBucket.NestedReadBucket(resourceChannelsKey)
+		if chanBucket != nil {
+			err := chanBucket.ForEach(func(k, v []byte) error {
+				scid := lnwire.NewShortChanIDFromInt(
+					binary.BigEndian.Uint64(k),
+				)
+
+				c, err := deserializeChannelReputation(
+					bytes.NewReader(v),
+				)
+				if err != nil {
+					return err
+				}
+				channels[scid] = c
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		htlcBucket := rootBucket.NestedReadBucket(resourceInFlightKey)
+		if htlcBucket == nil {
+			return nil
+		}
+
+		return htlcBucket.ForEach(func(k, v []byte) error {
+			htlc, err := deserializeInFlightHtlc(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+			if err := htlc.incoming.SetBytes(k); err != nil {
+				return err
+			}
+			htlcs = append(htlcs, htlc)
+
+			return nil
+		})
+	}, func() {
+		channels = make(map[lnwire.ShortChannelID]*channelReputation)
+		htlcs = nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return channels, htlcs, nil
+}
+
+// prune removes the in-flight htlcs whose circuit no longer exists, as they
+// were resolved while we weren't tracking them, or that arrived on one of the
+// given closed channels. The reputations of the closed channels are removed
+// as well. The number of removed htlcs and channels is returned.
+func (r *resourceStore) prune(
+	closed map[lnwire.ShortChannelID]struct{}) (int, int, error) {
+
+	var numHtlcs, numChannels int
+	err := kvdb.Update(r.backend, func(tx kvdb.RwTx) error {
+		rootBucket := tx.ReadWriteBucket(resourceBucketKey)
+		if rootBucket == nil {
+			return nil
+		}
+
+		// The circuits are stored by the switch under their incoming
+		// circuit key, just like the in-flight htlcs.
+		circuitBucket := tx.ReadBucket(circuitAddKey)
+		isStale := func(k []byte) (bool, error) {
+			if circuitBucket == nil || circuitBucket.Get(k) == nil {
+				return true, nil
+			}
+
+			var key models.CircuitKey
+			if err := key.SetBytes(k); err != nil {
+				return false, err
+			}
+			_, ok := closed[key.ChanID]
+
+			return ok, nil
+		}
+
+		htlcBucket := rootBucket.NestedReadWriteBucket(
+			resourceInFlightKey,
+		)
+		if htlcBucket != nil {
+			stale, err := staleKeys(htlcBucket, isStale)
+			if err != nil {
+				return err
+			}
+
+			for _, k := range stale {
+				if err := htlcBucket.Delete(k); err != nil {
+					return err
+				}
+			}
+			numHtlcs = len(stale)
+		}
+
+		chanBucket := rootBucket.NestedReadWriteBucket(
+			resourceChannelsKey,
+		)
+		if chanBucket == nil {
+			return nil
+		}
+
+		stale, err := staleKeys(chanBucket, func(k []byte) (bool,
+			error) {
+
+			scid := lnwire.NewShortChanIDFromInt(
+				binary.BigEndian.Uint64(k),
+			)
+			_, ok := closed[scid]
+
+			return ok, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range stale {
+			if err := chanBucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		numChannels = len(stale)
+
+		return nil
+	}, func() {
+		numHtlcs = 0
+		numChannels = 0
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return numHtlcs, numChannels, nil
+}
+
+// staleKeys returns the keys of the bucket that the passed predicate marks as
+// stale. They're collected first, as a bucket can't be modified while it's
+// iterated over.
+func staleKeys(bucket kvdb.RBucket,
+	isStale func(k []byte) (bool, error)) ([][]byte, error) {
+
+	var stale [][]byte
+	err := bucket.ForEach(func(k, _ []byte) error {
+		ok, err := isStale(k)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stale, nil
+}
+
+// addHtlc persists a htlc that was granted resources.
+func (r *resourceStore) addHtlc(htlc *inFlightHtlc) error {
+	var b bytes.Buffer
+	if err := serializeInFlightHtlc(&b, htlc); err != nil {
+		return err
+	}
+
+	return kvdb.Update(r.backend, func(tx kvdb.RwTx) error {
+		rootBucket, err := tx.CreateTopLevelBucket(resourceBucketKey)
+		if err != nil {
+			return err
+		}
+
+		htlcBucket, err := rootBucket.CreateBucketIfNotExists(
+			resourceInFlightKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		return htlcBucket.Put(htlc.incoming.Bytes(), b.Bytes())
+	}, func() {})
+}
+
+// resolveHtlc removes a resolved htlc and stores the updated reputations of
+// its channels.
+func (r *resourceStore) resolveHtlc(key models.CircuitKey,
+	channels map[lnwire.ShortChannelID]*channelReputation) error {
+
+	return kvdb.Update(r.backend, func(tx kvdb.RwTx) error {
+		rootBucket, err := tx.CreateTopLevelBucket(resourceBucketKey)
+		if err != nil {
+			return err
+		}
+
+		htlcBucket, err := rootBucket.CreateBucketIfNotExists(
+			resourceInFlightKey,
+		)
+		if err != nil {
+			return err
+		}
+		if err := htlcBucket.Delete(key.Bytes()); err != nil {
+			return err
+		}
+
+		chanBucket, err := rootBucket.CreateBucketIfNotExists(
+			resourceChannelsKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		for scid, c := range channels {
+			var k [8]byte
+			binary.BigEndian.PutUint64(k[:], scid.ToUint64())
+
+			var b bytes.Buffer
+			if err := serializeChannelReputation(&b, c); err != nil {
+				return err
+			}
+
+			if err := chanBucket.Put(k[:], b.Bytes()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {})
+}
+
+// writeTime writes the time as unix nanoseconds, using zero for the zero
+// time.
+func writeTime(w io.Writer, t time.Time) error {
+	var nanos int64
+	if !t.IsZero() {
+		nanos = t.UnixNano()
+	}
+
+	return binary.Write(w, binary.BigEndian, nanos)
+}
+
+// readTime reads a time written by writeTime.
+func readTime(r io.Reader) (time.Time, error) {
+	var nanos int64
+	if err := binary.Read(r, binary.BigEndian, &nanos); err != nil {
+		return time.Time{}, err
+	}
+
+	if nanos == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(0, nanos), nil
+}
+
+// writeAverage writes the value and last update of a decaying average.
+func writeAverage(w io.Writer, d decayingAverage) error {
+	err := binary.Write(w, binary.BigEndian, math.Float64bits(d.value))
+	if err != nil {
+		return err
+	}
+
+	return writeTime(w, d.lastUpdate)
+}
+
+// readAverage reads a decaying average written by writeAverage.
+func readAverage(r io.Reader) (decayingAverage, error) {
+	var bits uint64
+	if err := binary.Read(r, binary.BigEndian, &bits); err != nil {
+		return decayingAverage{}, err
+	}
+
+	lastUpdate, err := readTime(r)
+	if err != nil {
+		return decayingAverage{}, err
+	}
+
+	return decayingAverage{
+		value:      math.Float64frombits(bits),
+		lastUpdate: lastUpdate,
+	}, nil
+}
+
+func serializeChannelReputation(w io.Writer, c *channelReputation) error {
+	if err := writeAverage(w, c.revenue); err != nil {
+		return err
+	}
+
+	if err := writeAverage(w, c.reputation); err != nil {
+		return err
+	}
+
+	return writeTime(w, c.congestionMisuse)
+}
+
+func deserializeChannelReputation(r io.Reader) (*channelReputation, error) {
+	var (
+		c   channelReputation
+		err error
+	)
+
+	if c.revenue, err = readAverage(r); err != nil {
+		return nil, err
+	}
+
+	if c.reputation, err = readAverage(r); err != nil {
+		return nil, err
+	}
+
+	if c.congestionMisuse, err = readTime(r); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// serializeInFlightHtlc writes all fields of the htlc except for its incoming
+// circuit key, which is the key it's stored under.
+func serializeInFlightHtlc(w io.Writer, htlc *inFlightHtlc) error {
+	var accountable uint8
+	if htlc.accountable {
+		accountable = 1
+	}
+
+	fields := []interface{}{
+		htlc.outgoing.ToUint64(), uint64(htlc.amount),
+		uint64(htlc.fee), uint64(htlc.risk), uint8(htlc.bucket),
+		accountable,
+	}
+	for _, field := range fields {
+		if err := binary.Write(w, binary.BigEndian, field); err != nil {
+			return err
+		}
+	}
+
+	return writeTime(w, htlc.addedAt)
+}
+
+func deserializeInFlightHtlc(r io.Reader) (*inFlightHtlc, error) {
+	var (
+		outgoing, amount, fee, risk uint64
+		bucket, accountable         uint8
+	)
+
+	fields := []interface{}{
+		&outgoing, &amount, &fee, &risk, &bucket, &accountable,
+	}
+	for _, field := range fields {
+		if err := binary.Read(r, binary.BigEndian, field); err != nil {
+			return nil, err
+		}
+	}
+
+	addedAt, err := readTime(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inFlightHtlc{
+		outgoing:    lnwire.NewShortChanIDFromInt(outgoing),
+		amount:      lnwire.MilliLoki(amount),
+		fee:         lnwire.MilliLoki(fee),
+		risk:        lnwire.MilliLoki(risk),
+		bucket:      ResourceBucket(bucket),
+		accountable: accountable == 1,
+		addedAt:     addedAt,
+	}, nil
+}
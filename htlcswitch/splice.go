@@ -0,0 +1,1102 @@
+package htlcswitch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/interactivetx"
+	"github.com/flokiorg/flnd/labels"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr/musig2"
+	"github.com/flokiorg/go-flokicoin/txscript"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+var (
+	// ErrSplicingDisallowed is returned when a splice is requested on a
+	// link whose peers didn't both signal support for splicing.
+	ErrSplicingDisallowed = errors.New("splicing not supported by both " +
+		"peers")
+
+	// ErrSpliceRemoteInitiator is returned when we attempt to initiate a
+	// splice while the remote party holds the floor of the quiesced
+	// channel.
+	ErrSpliceRemoteInitiator = errors.New("remote party is the " +
+		"quiescence initiator")
+
+	// ErrSpliceNegotiationInProgress is returned when a splice is
+	// requested while another one is still being negotiated.
+	ErrSpliceNegotiationInProgress = errors.New("splice negotiation " +
+		"already in progress")
+
+	// ErrSpliceRemoteContribution is returned when the remote party
+	// attempts to contribute to a splice we initiated. We only support
+	// splices funded by their initiator.
+	ErrSpliceRemoteContribution = errors.New("splice contributions of " +
+		"the responder are not supported")
+
+	// ErrSpliceFundingKey is returned when a party attempts to change its
+	// funding key with a splice.
+	ErrSpliceFundingKey = errors.New("splice funding key doesn't match " +
+		"the channel's funding key")
+)
+
+// SpliceRequest describes a splice of a link's channel that we initiate.
+type SpliceRequest struct {
+	// Amount is the amount to add to the channel. A negative amount is
+	// removed from the channel instead.
+	Amount chainutil.Amount
+
+	// OutputScript is the script the amount removed from the channel is
+	// paid to. If empty, it's paid to a new wallet address.
+	OutputScript []byte
+
+	// FeeRate is the fee rate of the splice transaction.
+	FeeRate chainfee.SatPerKWeight
+
+	// MinConfs is the number of confirmations the wallet inputs used for
+	// a splice-in need to have.
+	MinConfs int32
+}
+
+// SpliceReq is a request to splice a link's channel. The response is the txid
+// of the splice transaction once it was signed by both parties and published.
+type SpliceReq = fn.Req[SpliceRequest, fn.Result[chainhash.Hash]]
+
+// SpliceAbortedError is returned when the remote party aborts a splice.
+type SpliceAbortedError struct {
+	// Reason is the explanation the remote party gave, if any.
+	Reason string
+}
+
+// Error returns a human-readable description of the abort.
+func (e *SpliceAbortedError) Error() string {
+	return fmt.Sprintf("splice aborted by peer: %v", e.Reason)
+}
+
+// spliceStage is the stage of a splice negotiation.
+type spliceStage uint8
+
+const (
+	// spliceAwaitAck is the stage of a splice we initiated until the
+	// remote party accepts it.
+	spliceAwaitAck spliceStage = iota
+
+	// spliceConstructing is the stage in which the inputs and outputs of
+	// the splice transaction are exchanged.
+	spliceConstructing
+
+	// spliceSigning is the stage in which the commitments spending the
+	// new funding output and the splice transaction are signed.
+	spliceSigning
+)
+
+// spliceState tracks a splice from the SpliceInit until both parties
+// exchanged their TxSignatures. From then on the pending splice of the
+// channel tracks it until it's locked.
+type spliceState struct {
+	// initiator is the party that initiated the splice.
+	initiator lntypes.ChannelParty
+
+	// stage is the current stage of the negotiation.
+	stage spliceStage
+
+	// req is the local request that triggered the splice, if we are the
+	// initiator.
+	req fn.Option[SpliceReq]
+
+	// funding is the on-chain side of the splice, if we are the
+	// initiator.
+	funding *lnwallet.SpliceFunding
+
+	// localContribution and remoteContribution are the amounts the
+	// balances of both parties change by.
+	localContribution  chainutil.Amount
+	remoteContribution chainutil.Amount
+
+	// locktime is the locktime of the splice transaction.
+	locktime uint32
+
+	// heightHint is the best height known when the splice was started.
+	heightHint uint32
+
+	// session is the interactive construction of the splice transaction.
+	session *interactivetx.Session
+
+	// result holds the inputs and outputs of the splice transaction once
+	// its construction completed.
+	result *interactivetx.Result
+
+	// tx is the splice transaction once its construction completed.
+	tx *wire.MsgTx
+
+	// prevOuts fetches the outputs spent by the splice transaction.
+	prevOuts txscript.PrevOutputFetcher
+
+	// commits are the commitments spending the new funding output.
+	commits *lnwallet.SpliceCommitments
+
+	// remoteCommitSig is the remote party's signature for our commitment
+	// spending the new funding output, in the form it's stored along with
+	// the commitment.
+	remoteCommitSig fn.Option[[]byte]
+
+	// upgrade is the dynamic commitment that upgrades the channel to
+	// taproot, if the splice moves the channel to its MuSig2 funding
+	// output. The splice transaction is then the kickoff transaction that
+	// both parties built on their own, so there's no construction phase.
+	upgrade fn.Option[*dynCommitState]
+}
+
+// resolve sends the result of the splice to the local requester, if any. For
+// a taproot upgrade, the requester learns about the result once the kickoff
+// transaction is published.
+func (s *spliceState) resolve(txid chainhash.Hash, err error) {
+	s.upgrade.WhenSome(func(dyn *dynCommitState) {
+		dyn.resolve(err)
+	})
+
+	s.req.WhenSome(func(req SpliceReq) {
+		if err != nil {
+			req.Resolve(fn.Err[chainhash.Hash](err))
+			return
+		}
+
+		req.Resolve(fn.Ok(txid))
+	})
+}
+
+// Splice quiesces the link and splices funds into or out of the channel. The
+// returned channel receives the txid of the splice transaction once it was
+// signed by both parties and published, or the reason the splice failed.
+//
+// NOTE: Part of the ChannelUpdateHandler interface.
+func (l *channelLink) Splice(
+	req SpliceRequest) <-chan fn.Result[chainhash.Hash] {
+
+	out := make(chan fn.Result[chainhash.Hash], 1)
+	if l.cfg.DisallowSplicing {
+		out <- fn.Err[chainhash.Hash](ErrSplicingDisallowed)
+		return out
+	}
+
+	go func() {
+		// We'll first need the channel to be quiescent with us as the
+		// initiator, so that we are the party that gets to propose.
+		var initiator fn.Result[lntypes.ChannelParty]
+		select {
+		case initiator = <-l.InitStfu():
+		case <-l.cg.Done():
+			out <- fn.Err[chainhash.Hash](ErrLinkShuttingDown)
+			return
+		}
+
+		party, err := initiator.Unpack()
+		if err != nil {
+			out <- fn.Err[chainhash.Hash](err)
+			return
+		}
+		if party.IsRemote() {
+			out <- fn.Err[chainhash.Hash](ErrSpliceRemoteInitiator)
+			return
+		}
+
+		spliceReq, resp := fn.NewReq[
+			SpliceRequest, fn.Result[chainhash.Hash],
+		](req)
+		select {
+		case l.spliceReqs <- spliceReq:
+		case <-l.cg.Done():
+			out <- fn.Err[chainhash.Hash](ErrLinkShuttingDown)
+			return
+		}
+
+		select {
+		case result := <-resp:
+			out <- result
+		case <-l.cg.Done():
+			out <- fn.Err[chainhash.Hash](ErrLinkShuttingDown)
+		}
+	}()
+
+	return out
+}
+
+// handleSpliceReq funds a locally initiated splice and sends the SpliceInit
+// to the remote party.
+func (l *channelLink) handleSpliceReq(req SpliceReq) error {
+	fail := func(err error) error {
+		req.Resolve(fn.Err[chainhash.Hash](err))
+		return err
+	}
+
+	if l.splice.IsSome() || l.dynCommit.IsSome() {
+		return fail(ErrSpliceNegotiationInProgress)
+	}
+
+	initiator, err := l.quiescer.QuiescenceInitiator().Unpack()
+	if err != nil {
+		return fail(err)
+	}
+	if initiator.IsRemote() {
+		return fail(ErrSpliceRemoteInitiator)
+	}
+
+	// If the splice can't be executed, we'll release the channel right
+	// away as the remote party is waiting for us to act.
+	err = l.channel.ValidateSplice()
+	if err != nil {
+		l.quiescer.Resume()
+		return fail(err)
+	}
+
+	state := l.channel.State()
+	funding, err := l.cfg.SpliceWallet.FundSplice(
+		&lnwallet.SpliceFundingRequest{
+			Capacity:     state.Capacity,
+			Amount:       req.Request.Amount,
+			OutputScript: req.Request.OutputScript,
+			FeeRate:      req.Request.FeeRate,
+			MinConfs:     req.Request.MinConfs,
+		},
+	)
+	if err != nil {
+		l.quiescer.Resume()
+		return fail(err)
+	}
+
+	splice, err := l.newSpliceNegotiation(funding)
+	if err != nil {
+		l.cfg.SpliceWallet.CancelSplice(funding)
+		l.quiescer.Resume()
+
+		return fail(err)
+	}
+	splice.req = fn.Some(req)
+
+	err = l.cfg.Peer.SendMessage(false, &lnwire.SpliceInit{
+		ChanID:              l.ChanID(),
+		FundingContribution: int64(funding.Contribution),
+		FeeRate:             uint32(req.Request.FeeRate),
+		Locktime:            splice.locktime,
+		FundingPubKey:       state.LocalChanCfg.MultiSigKey.PubKey,
+	})
+	if err != nil {
+		l.cfg.SpliceWallet.CancelSplice(funding)
+		l.quiescer.Resume()
+
+		return fail(err)
+	}
+
+	l.log.Infof("Initiated splice with contribution %v, fee %v",
+		funding.Contribution, funding.Fee)
+
+	l.splice = fn.Some(splice)
+
+	return nil
+}
+
+// newSpliceNegotiation creates the state of a splice we initiate, including
+// the construction session that adds all inputs and outputs of the splice
+// transaction.
+func (l *channelLink) newSpliceNegotiation(
+	funding *lnwallet.SpliceFunding) (*spliceState, error) {
+
+	fundingPoint := l.channel.State().FundingTxOutpoint()
+	height := l.cfg.BestHeight()
+
+	splice := &spliceState{
+		initiator:         lntypes.Local,
+		stage:             spliceAwaitAck,
+		funding:           funding,
+		localContribution: funding.Contribution,
+		locktime:          height,
+		heightHint:        height,
+	}
+
+	// The shared input and the new funding output come first.
+	inputs := []interactivetx.Input{{OutPoint: fundingPoint}}
+	for _, utxo := range funding.Inputs {
+		inputs = append(inputs, interactivetx.Input{
+			OutPoint: utxo.OutPoint,
+			PrevTx:   utxo.PrevTx,
+		})
+	}
+
+	outputs := append([]*wire.TxOut{{
+		Value:    int64(funding.Capacity),
+		PkScript: l.channel.FundingScript(),
+	}}, funding.Outputs...)
+
+	cfg := l.spliceSessionCfg(height)
+	cfg.Initiator = true
+	cfg.Inputs = inputs
+	cfg.Outputs = outputs
+
+	session, err := interactivetx.NewSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	splice.session = session
+
+	return splice, nil
+}
+
+// spliceSessionCfg returns the configuration of the interactive construction
+// of a splice transaction with the passed locktime, which spends the current
+// funding output and creates the new one.
+func (l *channelLink) spliceSessionCfg(locktime uint32) interactivetx.Config {
+	state := l.channel.State()
+
+	return interactivetx.Config{
+		ChanID:   l.ChanID(),
+		Locktime: locktime,
+		SharedInput: fn.Some(interactivetx.SharedInput{
+			OutPoint: state.FundingTxOutpoint(),
+			PrevOut:  l.channel.FundingTxOutput(),
+		}),
+		SharedOutputScript: l.channel.FundingScript(),
+		DustLimit:          state.LocalChanCfg.DustLimit,
+	}
+}
+
+// handleSpliceInit handles a splice initiated by the remote party. We either
+// abort it, or accept it without contributing to it.
+func (l *channelLink) handleSpliceInit(msg *lnwire.SpliceInit) error {
+	initiator, err := l.quiescer.QuiescenceInitiator().Unpack()
+	if err != nil {
+		return err
+	}
+	if initiator.IsLocal() || l.splice.IsSome() || l.dynCommit.IsSome() {
+		return fmt.Errorf("SpliceInit received while not holding " +
+			"the floor")
+	}
+
+	state := l.channel.State()
+	remoteKey := state.RemoteChanCfg.MultiSigKey.PubKey
+	switch {
+	case l.cfg.DisallowSplicing:
+		err = ErrSplicingDisallowed
+
+	case msg.FundingPubKey == nil || !msg.FundingPubKey.IsEqual(remoteKey):
+		err = ErrSpliceFundingKey
+
+	default:
+		err = l.channel.ValidateSplice()
+	}
+	if err != nil {
+		l.log.Infof("Rejecting splice: %v", err)
+		l.quiescer.Resume()
+
+		return l.cfg.Peer.SendMessage(false, &lnwire.TxAbort{
+			ChanID: l.ChanID(),
+			Data:   lnwire.ErrorData(err.Error()),
+		})
+	}
+
+	session, err := interactivetx.NewSession(
+		l.spliceSessionCfg(msg.Locktime),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = l.cfg.Peer.SendMessage(false, &lnwire.SpliceAck{
+		ChanID:        l.ChanID(),
+		FundingPubKey: state.LocalChanCfg.MultiSigKey.PubKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	l.splice = fn.Some(&spliceState{
+		initiator:          lntypes.Remote,
+		stage:              spliceConstructing,
+		remoteContribution: chainutil.Amount(msg.FundingContribution),
+		locktime:           msg.Locktime,
+		heightHint:         l.cfg.BestHeight(),
+		session:            session,
+	})
+
+	return nil
+}
+
+// handleSpliceAck handles the remote party's acceptance of our splice and
+// starts the construction of the splice transaction.
+func (l *channelLink) handleSpliceAck(msg *lnwire.SpliceAck) error {
+	splice, err := l.splice.UnwrapOrErr(
+		fmt.Errorf("SpliceAck received without a splice"),
+	)
+	if err != nil {
+		return err
+	}
+	if splice.initiator.IsRemote() || splice.stage != spliceAwaitAck {
+		return fmt.Errorf("SpliceAck received out of order")
+	}
+
+	remoteKey := l.channel.State().RemoteChanCfg.MultiSigKey.PubKey
+	switch {
+	case msg.FundingContribution != 0:
+		return ErrSpliceRemoteContribution
+
+	case msg.FundingPubKey == nil || !msg.FundingPubKey.IsEqual(remoteKey):
+		return ErrSpliceFundingKey
+	}
+
+	splice.stage = spliceConstructing
+
+	firstMsg, err := splice.session.Start()
+	if err != nil {
+		return err
+	}
+
+	return l.cfg.Peer.SendMessage(false, firstMsg)
+}
+
+// handleInteractiveTxMsg passes an interactive tx message, such as a
+// TxAddInput or TxComplete, of the remote party to the construction of the
+// splice transaction, and sends our reply. Once both parties sent a TxComplete in succession, we move on to
+// signing the splice.
+func (l *channelLink) handleInteractiveTxMsg(msg lnwire.Message) error {
+	splice, err := l.splice.UnwrapOrErr(
+		fmt.Errorf("%v received without a splice", msg.MsgType()),
+	)
+	if err != nil {
+		return err
+	}
+	if splice.stage != spliceConstructing {
+		return fmt.Errorf("%v received out of order", msg.MsgType())
+	}
+
+	reply, err := splice.session.ReceiveMsg(msg)
+	if err != nil {
+		return err
+	}
+
+	err = fn.MapOptionZ(reply, func(reply lnwire.Message) error {
+		return l.cfg.Peer.SendMessage(false, reply)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !splice.session.Complete() {
+		return nil
+	}
+
+	return l.completeSpliceConstruction(splice)
+}
+
+// completeSpliceConstruction builds the negotiated splice transaction, and
+// sends our signature for the remote commitment spending its funding output.
+func (l *channelLink) completeSpliceConstruction(splice *spliceState) error {
+	// The session made sure the splice transaction spends the funding
+	// output and doesn't spend more than its inputs. The fee is paid by
+	// the initiator, either from its inputs or from its contribution.
+	result, err := splice.session.Result()
+	if err != nil {
+		return err
+	}
+	tx := result.Tx
+
+	commits, err := l.channel.SignSpliceCommitments(
+		tx, splice.localContribution, splice.remoteContribution,
+	)
+	if err != nil {
+		return err
+	}
+
+	splice.result = result
+	splice.tx = tx
+	splice.prevOuts = result.PrevOuts
+	splice.commits = commits
+	splice.stage = spliceSigning
+
+	return l.cfg.Peer.SendMessage(false, &lnwire.CommitSig{
+		ChanID:    l.ChanID(),
+		CommitSig: commits.RemoteCommitSig,
+	})
+}
+
+// signingSplice returns true if the commitments of a splice are being signed,
+// in which case the next CommitSig belongs to the splice.
+func (l *channelLink) signingSplice() bool {
+	splice, ok := l.splice.UnwrapOr(nil), l.splice.IsSome()
+
+	return ok && splice.stage == spliceSigning &&
+		splice.remoteCommitSig.IsNone()
+}
+
+// handleSpliceCommitSig verifies the remote party's signature for our
+// commitment spending the new funding output. If we are the responder, we
+// then send our signature for the shared input, as we don't contribute any
+// inputs.
+func (l *channelLink) handleSpliceCommitSig(msg *lnwire.CommitSig) error {
+	splice, err := l.splice.UnwrapOrErr(
+		fmt.Errorf("CommitSig received without a splice"),
+	)
+	if err != nil {
+		return err
+	}
+	if len(msg.HtlcSigs) != 0 {
+		return fmt.Errorf("splice CommitSig carries %d HTLC "+
+			"signatures", len(msg.HtlcSigs))
+	}
+
+	localCommitSig, err := l.verifySpliceCommitSig(splice, msg)
+	if err != nil {
+		return err
+	}
+	splice.remoteCommitSig = fn.Some(localCommitSig)
+
+	if splice.initiator.IsLocal() {
+		return nil
+	}
+
+	txSigs, err := l.spliceTxSignatures(splice, nil)
+	if err != nil {
+		return err
+	}
+
+	pending := splice.commits.PendingSplice(
+		splice.tx, localCommitSig, false, splice.heightHint,
+	)
+	pending.TxSignatures, err = serializeMsg(txSigs)
+	if err != nil {
+		return err
+	}
+
+	// Once we persisted the splice, the remote party is able to publish
+	// the splice transaction, so we'll start watching for it right away.
+	err = l.channel.PutPendingSplice(pending)
+	if err != nil {
+		return err
+	}
+	l.watchSplice(pending)
+
+	return l.cfg.Peer.SendMessage(false, txSigs)
+}
+
+// verifySpliceCommitSig verifies the remote party's signature for our
+// commitment spending the new funding output, and returns it in the form it's
+// stored along with the commitment. The commitments of a taproot upgrade are
+// signed with MuSig2 partial signatures.
+func (l *channelLink) verifySpliceCommitSig(splice *spliceState,
+	msg *lnwire.CommitSig) ([]byte, error) {
+
+	if splice.upgrade.IsNone() {
+		err := l.channel.VerifySpliceCommitSig(
+			splice.commits, msg.CommitSig,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return msg.CommitSig.ToSignatureBytes(), nil
+	}
+
+	partialSig, err := msg.PartialSig.UnwrapOrErrV(
+		fmt.Errorf("taproot upgrade CommitSig without partial " +
+			"signature"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.channel.VerifyTaprootUpgradeCommitSig(
+		splice.commits, &partialSig,
+	)
+}
+
+// spliceTxSignatures creates our TxSignatures for the splice transaction.
+func (l *channelLink) spliceTxSignatures(splice *spliceState,
+	witnesses []wire.TxWitness) (*lnwire.TxSignatures, error) {
+
+	sig, err := l.channel.SignSpliceInput(splice.tx, splice.prevOuts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnwire.TxSignatures{
+		ChanID:    l.ChanID(),
+		TxID:      splice.tx.TxHash(),
+		Witnesses: witnesses,
+		SharedInputSig: tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType0](sig),
+		),
+	}, nil
+}
+
+// handleTxSignatures completes the splice transaction with the remote
+// party's signatures and publishes it.
+func (l *channelLink) handleTxSignatures(msg *lnwire.TxSignatures) error {
+	splice, ok := l.splice.UnwrapOr(nil), l.splice.IsSome()
+	if !ok {
+		return l.handleTxSignaturesReplay(msg)
+	}
+	if splice.stage != spliceSigning || splice.remoteCommitSig.IsNone() {
+		return fmt.Errorf("TxSignatures received out of order")
+	}
+
+	txid := splice.tx.TxHash()
+	if msg.TxID != txid {
+		return fmt.Errorf("TxSignatures for unknown transaction %v",
+			chainhash.Hash(msg.TxID))
+	}
+
+	remoteSig, err := msg.SharedInputSig.UnwrapOrErrV(
+		fmt.Errorf("TxSignatures without shared input signature"),
+	)
+	if err != nil {
+		return err
+	}
+
+	localSig, err := l.channel.SignSpliceInput(splice.tx, splice.prevOuts)
+	if err != nil {
+		return err
+	}
+	err = l.channel.FinalizeSpliceInput(
+		splice.tx, splice.prevOuts, localSig, remoteSig,
+	)
+	if err != nil {
+		return err
+	}
+
+	// The witnesses of the remote party are for the inputs it added,
+	// which are all inputs but the shared one if we are the responder.
+	// The kickoff transaction of a taproot upgrade only spends the shared
+	// input.
+	var witnesses []wire.TxWitness
+	switch {
+	case splice.upgrade.IsSome():
+		if len(msg.Witnesses) != 0 {
+			return fmt.Errorf("taproot upgrade TxSignatures carry "+
+				"%d witnesses", len(msg.Witnesses))
+		}
+
+	case splice.initiator.IsLocal():
+		if len(msg.Witnesses) != 0 {
+			return fmt.Errorf("responder sent %d witnesses",
+				len(msg.Witnesses))
+		}
+
+		witnesses, err = l.cfg.SpliceWallet.SignSpliceInputs(
+			splice.tx, splice.funding, splice.prevOuts,
+		)
+		if err != nil {
+			return err
+		}
+
+	default:
+		witnesses = msg.Witnesses
+	}
+
+	if splice.result != nil {
+		err = splice.result.SetWitnesses(splice.initiator, witnesses)
+		if err != nil {
+			return err
+		}
+	}
+	err = interactivetx.VerifyTx(splice.tx, splice.prevOuts)
+	if err != nil {
+		return err
+	}
+
+	var pending chanstate.Splice
+	if splice.initiator.IsLocal() {
+		txSigs, err := l.spliceTxSignatures(splice, witnesses)
+		if err != nil {
+			return err
+		}
+
+		remoteCommitSig := splice.remoteCommitSig.UnwrapOr(nil)
+		pending = splice.commits.PendingSplice(
+			splice.tx, remoteCommitSig, true, splice.heightHint,
+		)
+		pending.TxSignatures, err = serializeMsg(txSigs)
+		if err != nil {
+			return err
+		}
+		pending.RemoteSigned = true
+
+		err = l.channel.PutPendingSplice(pending)
+		if err != nil {
+			return err
+		}
+
+		err = l.cfg.Peer.SendMessage(false, txSigs)
+		if err != nil {
+			l.log.Errorf("failed to send TxSignatures: %v", err)
+		}
+
+		l.watchSplice(pending)
+	} else {
+		pending, err = l.channel.PendingSplice().UnwrapOrErr(
+			chanstate.ErrNoPendingSplice,
+		)
+		if err != nil {
+			return err
+		}
+		pending.FundingTx = splice.tx
+		pending.RemoteSigned = true
+
+		err = l.channel.PutPendingSplice(pending)
+		if err != nil {
+			return err
+		}
+	}
+
+	l.publishSplice(pending.FundingTx)
+
+	l.splice = fn.None[*spliceState]()
+	l.quiescer.Resume()
+	splice.resolve(txid, nil)
+
+	return nil
+}
+
+// handleTxSignaturesReplay handles the TxSignatures the remote party
+// retransmits on reestablish if the connection was lost before we received
+// them. The splice transaction was already published by the remote party, so
+// we only need to record that it was signed.
+func (l *channelLink) handleTxSignaturesReplay(
+	msg *lnwire.TxSignatures) error {
+
+	pending, err := l.channel.PendingSplice().UnwrapOrErr(
+		fmt.Errorf("TxSignatures received without a splice"),
+	)
+	if err != nil {
+		return err
+	}
+	if pending.FundingOutpoint.Hash != msg.TxID {
+		return fmt.Errorf("TxSignatures for unknown transaction %v",
+			chainhash.Hash(msg.TxID))
+	}
+	if pending.RemoteSigned {
+		return nil
+	}
+
+	pending.RemoteSigned = true
+
+	return l.channel.PutPendingSplice(pending)
+}
+
+// publishSplice broadcasts the splice transaction.
+func (l *channelLink) publishSplice(tx *wire.MsgTx) {
+	scid := l.ShortChanID()
+	label := labels.MakeLabel(labels.LabelTypeSplice, &scid)
+
+	err := l.cfg.SpliceWallet.PublishTransaction(tx, label)
+	if err != nil {
+		l.log.Errorf("Unable to publish splice transaction %v: %v",
+			tx.TxHash(), err)
+	}
+}
+
+// handleTxAbort handles the remote party's abort of the splice being
+// negotiated, or of a splice it doesn't know about after reestablish.
+func (l *channelLink) handleTxAbort(msg *lnwire.TxAbort) error {
+	reason := &SpliceAbortedError{Reason: string(msg.Data)}
+
+	if splice, ok := l.splice.UnwrapOr(nil), l.splice.IsSome(); ok {
+		l.log.Infof("Splice negotiation aborted by peer: %v",
+			reason.Reason)
+
+		l.cancelSplice(reason)
+		if splice.stage != spliceSigning {
+			return nil
+		}
+	}
+
+	// A splice we persisted can only be aborted as long as the remote
+	// party didn't sign it.
+	pending, ok := l.channel.PendingSplice().UnwrapOr(chanstate.Splice{}),
+		l.channel.SplicePending()
+	if !ok || pending.RemoteSigned {
+		return nil
+	}
+
+	l.log.Infof("Abandoning splice %v: %v", pending.FundingOutpoint,
+		reason.Reason)
+
+	return l.channel.AbortSplice()
+}
+
+// cancelSplice abandons the splice being negotiated, releasing its inputs
+// and the quiesced channel.
+func (l *channelLink) cancelSplice(err error) {
+	splice, ok := l.splice.UnwrapOr(nil), l.splice.IsSome()
+	if !ok {
+		return
+	}
+
+	if splice.funding != nil {
+		l.cfg.SpliceWallet.CancelSplice(splice.funding)
+	}
+
+	l.splice = fn.None[*spliceState]()
+	l.quiescer.Resume()
+	splice.resolve(chainhash.Hash{}, err)
+}
+
+// watchSplice waits for the passed splice transaction to confirm and hands it
+// to the main loop to lock the splice.
+func (l *channelLink) watchSplice(splice chanstate.Splice) {
+	txid := splice.FundingOutpoint.Hash
+	pkScript := splice.FundingTx.TxOut[splice.FundingOutpoint.Index].PkScript
+	numConfs := max(uint32(l.channel.State().NumConfsRequired), 1)
+
+	l.cg.WgAdd(1)
+	go func() {
+		defer l.cg.WgDone()
+
+		confNtfn, err := l.cfg.ChainNotifier.RegisterConfirmationsNtfn(
+			&txid, pkScript, numConfs, splice.HeightHint,
+		)
+		if err != nil {
+			l.log.Errorf("Unable to register for confirmation of "+
+				"splice %v: %v", txid, err)
+			return
+		}
+		defer confNtfn.Cancel()
+
+		select {
+		case _, ok := <-confNtfn.Confirmed:
+			if !ok {
+				return
+			}
+
+		case <-l.cg.Done():
+			return
+		}
+
+		select {
+		case l.spliceConfirmed <- txid:
+		case <-l.cg.Done():
+		}
+	}()
+}
+
+// handleSpliceConfirmed switches the channel over to the funding output of
+// the confirmed splice and tells the remote party.
+func (l *channelLink) handleSpliceConfirmed(txid chainhash.Hash) error {
+	pending, ok := l.channel.PendingSplice().UnwrapOr(chanstate.Splice{}),
+		l.channel.SplicePending()
+	if !ok || pending.FundingOutpoint.Hash != txid || pending.LocalLocked {
+		return nil
+	}
+
+	if err := l.channel.LockSplice(); err != nil {
+		return err
+	}
+
+	l.log.Infof("Splice %v locked, capacity is now %v",
+		pending.FundingOutpoint, pending.Capacity)
+
+	msg, err := l.spliceLockedMsg(&pending)
+	if err != nil {
+		return err
+	}
+
+	// If the remote party locked the taproot upgrade before us, we can
+	// now set up the musig sessions with the nonce it sent.
+	if pending.IsUpgrade() {
+		err := l.initUpgradeSessions()
+		if err != nil {
+			return err
+		}
+	}
+
+	if !l.channel.SplicePending() {
+		l.log.Infof("Splice %v completed", pending.FundingOutpoint)
+	}
+
+	return l.cfg.Peer.SendMessage(false, msg)
+}
+
+// spliceLockedMsg returns the SpliceLocked for the passed splice. If the
+// splice upgraded the channel to taproot, it carries our verification nonce
+// for the next local commitment.
+func (l *channelLink) spliceLockedMsg(
+	pending *chanstate.Splice) (*lnwire.SpliceLocked, error) {
+
+	msg := &lnwire.SpliceLocked{
+		ChanID:     l.ChanID(),
+		SpliceTxID: pending.FundingOutpoint.Hash,
+	}
+	if !pending.IsUpgrade() {
+		return msg, nil
+	}
+
+	nonce, err := l.channel.GenMusigNonces()
+	if err != nil {
+		return nil, err
+	}
+	msg.NextLocalNonce = lnwire.SomeMusig2Nonce(nonce.PubNonce)
+
+	return msg, nil
+}
+
+// initUpgradeSessions sets up the musig sessions of a channel that was
+// upgraded to taproot once both parties locked the upgrade and we received
+// the remote party's verification nonce.
+func (l *channelLink) initUpgradeSessions() error {
+	remoteNonce, ok := l.spliceRemoteNonce.UnwrapOr(
+		lnwire.Musig2Nonce{},
+	), l.spliceRemoteNonce.IsSome()
+	if !ok {
+		return nil
+	}
+
+	err := l.channel.InitRemoteMusigNonces(&musig2.Nonces{
+		PubNonce: remoteNonce,
+	})
+	if err != nil {
+		return err
+	}
+	l.spliceRemoteNonce = fn.None[lnwire.Musig2Nonce]()
+
+	return nil
+}
+
+// handleSpliceLocked records that the remote party locked the pending splice.
+func (l *channelLink) handleSpliceLocked(msg *lnwire.SpliceLocked) error {
+	pending, ok := l.channel.PendingSplice().UnwrapOr(chanstate.Splice{}),
+		l.channel.SplicePending()
+	fundingTxid := l.channel.State().FundingTxOutpoint().Hash
+
+	switch {
+	// The remote party might retransmit its SpliceLocked on reestablish
+	// after we already completed the splice.
+	case !ok && fundingTxid == msg.SpliceTxID:
+		return nil
+
+	case !ok || pending.FundingOutpoint.Hash != msg.SpliceTxID:
+		return fmt.Errorf("SpliceLocked for unknown splice %v",
+			chainhash.Hash(msg.SpliceTxID))
+	}
+
+	// The verification nonce the remote party sends for its next
+	// commitment can only be used once we locked the upgrade as well.
+	if pending.IsUpgrade() {
+		nonce, err := msg.NextLocalNonce.UnwrapOrErrV(
+			lnwallet.ErrTaprootUpgradeNoNonce,
+		)
+		if err != nil {
+			return err
+		}
+		l.spliceRemoteNonce = fn.Some(nonce)
+
+		if pending.LocalLocked {
+			if err := l.initUpgradeSessions(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := l.channel.MarkSpliceRemoteLocked(); err != nil {
+		return err
+	}
+
+	if !l.channel.SplicePending() {
+		l.log.Infof("Splice %v completed", pending.FundingOutpoint)
+	}
+
+	return nil
+}
+
+// syncSplice retransmits the messages of a pending splice the remote party
+// didn't process before the connection was lost.
+func (l *channelLink) syncSplice(remoteSync *lnwire.ChannelReestablish) error {
+	pending, ok := l.channel.PendingSplice().UnwrapOr(chanstate.Splice{}),
+		l.channel.SplicePending()
+
+	var err error
+	remoteSync.NextFundingTxid.WhenSomeV(func(txid [32]byte) {
+		// If we don't know the splice the remote party is waiting
+		// for, we never completed signing it and it needs to be
+		// abandoned.
+		if !ok || pending.FundingOutpoint.Hash != txid ||
+			len(pending.TxSignatures) == 0 {
+
+			l.log.Infof("Aborting unknown splice %v",
+				chainhash.Hash(txid))
+
+			err = l.cfg.Peer.SendMessage(false, &lnwire.TxAbort{
+				ChanID: l.ChanID(),
+				Data:   lnwire.ErrorData("unknown splice"),
+			})
+
+			return
+		}
+
+		var msg lnwire.Message
+		msg, err = lnwire.ReadMessage(
+			bytes.NewReader(pending.TxSignatures), 0,
+		)
+		if err != nil {
+			return
+		}
+
+		l.log.Infof("Retransmitting TxSignatures for splice %v",
+			pending.FundingOutpoint)
+
+		err = l.cfg.Peer.SendMessage(false, msg)
+	})
+	if err != nil || !ok {
+		return err
+	}
+
+	if pending.LocalLocked && !pending.RemoteLocked {
+		msg, err := l.spliceLockedMsg(&pending)
+		if err != nil {
+			return err
+		}
+
+		return l.cfg.Peer.SendMessage(false, msg)
+	}
+
+	return nil
+}
+
+// resumeSplice restarts watching for the confirmation of a pending splice
+// after the link was restarted.
+func (l *channelLink) resumeSplice() {
+	l.channel.PendingSplice().WhenSome(func(pending chanstate.Splice) {
+		if !pending.LocalLocked {
+			l.watchSplice(pending)
+		}
+	})
+}
+
+// spliceFailf fails the link in the case where the requirements of the
+// splicing protocol are violated. As with quiescence violations, we drop the
+// connection, which resets the negotiation on both sides.
+func (l *channelLink) spliceFailf(format string, args ...interface{}) {
+	l.failf(LinkFailureError{
+		code:             ErrSpliceViolation,
+		FailureAction:    LinkFailureDisconnect,
+		PermanentFailure: false,
+		Warning:          true,
+	}, format, args...)
+}
+
+// serializeMsg returns the serialized form of the passed message, including
+// its type.
+func serializeMsg(msg lnwire.Message) ([]byte, error) {
+	var b bytes.Buffer
+	if _, err := lnwire.WriteMessage(&b, msg, 0); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,212 @@
+package htlcswitch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/chainntnfs"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lntest/mock"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/txscript"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// waitSplice waits for the result of a splice request.
+func waitSplice(t *testing.T,
+	resp <-chan fn.Result[chainhash.Hash]) (chainhash.Hash, error) {
+
+	t.Helper()
+
+	select {
+	case result := <-resp:
+		return result.Unpack()
+
+	case <-time.After(10 * time.Second):
+		t.Fatalf("splice timed out")
+		return chainhash.Hash{}, nil
+	}
+}
+
+// waitSplicePublished returns the splice transaction published by the link.
+func waitSplicePublished(t *testing.T, link *channelLink) *wire.MsgTx {
+	t.Helper()
+
+	wallet, ok := link.cfg.SpliceWallet.(*mockSpliceWallet)
+	require.True(t, ok)
+
+	select {
+	case tx := <-wallet.published:
+		return tx
+
+	case <-time.After(10 * time.Second):
+		t.Fatalf("splice transaction not published")
+		return nil
+	}
+}
+
+// confirmSplice confirms the splice transaction for the passed links and
+// waits for both of them to lock the splice.
+func confirmSplice(t *testing.T, spliceTx *wire.MsgTx,
+	links ...*channelLink) {
+
+	t.Helper()
+
+	for _, link := range links {
+		notifier, ok := link.cfg.ChainNotifier.(*mock.ChainNotifier)
+		require.True(t, ok)
+
+		notifier.ConfChan <- &chainntnfs.TxConfirmation{Tx: spliceTx}
+	}
+
+	for _, link := range links {
+		require.Eventually(t, func() bool {
+			return !link.channel.SplicePending()
+		}, 10*time.Second, 50*time.Millisecond)
+	}
+}
+
+// TestLinkSpliceIn asserts that two links can splice funds into their channel
+// and continue forwarding payments on top of the new funding output.
+func TestLinkSpliceIn(t *testing.T) {
+	t.Parallel()
+
+	alice, bob, err := createMirroredChannel(
+		t, chainutil.LokiPerFlokicoin, chainutil.LokiPerFlokicoin,
+	)
+	require.NoError(t, err)
+
+	network := newTwoHopNetwork(
+		t, alice.channel, bob.channel, testStartingHeight,
+	)
+	aliceLink := network.aliceChannelLink
+	bobLink := network.bobChannelLink
+
+	const amt = chainutil.Amount(500_000)
+	oldCapacity := alice.channel.State().Capacity
+	oldBalance := alice.channel.State().LocalCommitment.LocalBalance
+
+	txid, err := waitSplice(t, aliceLink.Splice(SpliceRequest{
+		Amount:  amt,
+		FeeRate: 1000,
+	}))
+	require.NoError(t, err)
+
+	// Both parties publish the fully signed splice transaction.
+	spliceTx := waitSplicePublished(t, aliceLink)
+	require.Equal(t, txid, spliceTx.TxHash())
+	require.Equal(t, txid, waitSplicePublished(t, bobLink).TxHash())
+	require.Len(t, spliceTx.TxIn, 2)
+
+	// No updates are possible until the splice is locked.
+	require.True(t, bob.channel.SplicePending())
+	require.False(t, aliceLink.EligibleToForward())
+
+	confirmSplice(t, spliceTx, aliceLink, bobLink)
+
+	for _, link := range []*channelLink{aliceLink, bobLink} {
+		state := link.channel.State()
+		require.Equal(t, oldCapacity+amt, state.Capacity)
+		require.Equal(t, txid, state.FundingTxOutpoint().Hash)
+	}
+	require.Equal(
+		t, oldBalance+lnwire.NewMSatFromLokis(amt),
+		alice.channel.State().LocalCommitment.LocalBalance,
+	)
+
+	// With the splice locked, Alice can pay Bob again.
+	amount := lnwire.NewMSatFromLokis(10_000)
+	htlcAmt, totalTimelock, hops := generateHops(
+		amount, testStartingHeight, bobLink,
+	)
+	firstHop := bobLink.ShortChanID()
+	_, err = makePayment(
+		network.aliceServer, network.bobServer, firstHop, hops, amount,
+		htlcAmt, totalTimelock,
+	).Wait(30 * time.Second)
+	require.NoError(t, err)
+
+	commitTx := bob.channel.State().LocalCommitment.CommitTx
+	require.Equal(t, txid, commitTx.TxIn[0].PreviousOutPoint.Hash)
+}
+
+// TestLinkSpliceOut asserts that a link can splice funds out of its channel
+// to an external output, paying the fee from its balance.
+func TestLinkSpliceOut(t *testing.T) {
+	t.Parallel()
+
+	alice, bob, err := createMirroredChannel(
+		t, chainutil.LokiPerFlokicoin, chainutil.LokiPerFlokicoin,
+	)
+	require.NoError(t, err)
+
+	network := newTwoHopNetwork(
+		t, alice.channel, bob.channel, testStartingHeight,
+	)
+	aliceLink := network.aliceChannelLink
+	bobLink := network.bobChannelLink
+
+	const amt = chainutil.Amount(200_000)
+	oldCapacity := alice.channel.State().Capacity
+	oldBalance := bob.channel.State().LocalCommitment.LocalBalance
+
+	// Bob splices out to an external address.
+	outputScript := []byte{txscript.OP_0, 0x14}
+	outputScript = append(outputScript, make([]byte, 20)...)
+
+	txid, err := waitSplice(t, bobLink.Splice(SpliceRequest{
+		Amount:       -amt,
+		OutputScript: outputScript,
+		FeeRate:      1000,
+	}))
+	require.NoError(t, err)
+
+	spliceTx := waitSplicePublished(t, bobLink)
+	require.Equal(t, txid, spliceTx.TxHash())
+	waitSplicePublished(t, aliceLink)
+
+	var found bool
+	for _, txOut := range spliceTx.TxOut {
+		if chainutil.Amount(txOut.Value) == amt {
+			require.Equal(t, outputScript, txOut.PkScript)
+			found = true
+		}
+	}
+	require.True(t, found)
+
+	confirmSplice(t, spliceTx, aliceLink, bobLink)
+
+	newCapacity := oldCapacity - amt - mockSpliceFee
+	require.Equal(t, newCapacity, alice.channel.State().Capacity)
+	require.Equal(
+		t, oldBalance-lnwire.NewMSatFromLokis(amt+mockSpliceFee),
+		bob.channel.State().LocalCommitment.LocalBalance,
+	)
+}
+
+// TestLinkSpliceDisallowed asserts that a splice is refused if splicing
+// wasn't negotiated with the remote party.
+func TestLinkSpliceDisallowed(t *testing.T) {
+	t.Parallel()
+
+	alice, bob, err := createMirroredChannel(
+		t, chainutil.LokiPerFlokicoin, chainutil.LokiPerFlokicoin,
+	)
+	require.NoError(t, err)
+
+	network := newTwoHopNetwork(
+		t, alice.channel, bob.channel, testStartingHeight,
+	)
+	aliceLink := network.aliceChannelLink
+	aliceLink.cfg.DisallowSplicing = true
+
+	_, err = waitSplice(t, aliceLink.Splice(SpliceRequest{
+		Amount:  100_000,
+		FeeRate: 1000,
+	}))
+	require.ErrorIs(t, err, ErrSplicingDisallowed)
+	require.False(t, alice.channel.SplicePending())
+}
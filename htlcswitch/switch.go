@@ -24,6 +24,7 @@ import (
 	"github.com/flokiorg/flnd/lnwallet"
 	"github.com/flokiorg/flnd/lnwallet/chainfee"
 	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/flnd/ticker"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/crypto/ecdsa"
@@ -162,10 +163,10 @@ type Config struct {
 	FetchClosedChannels func(
 		pendingOnly bool) ([]*chanstate.ChannelCloseSummary, error)
 
-	// SwitchPackager provides access to the forwarding packages of all
+	// FwdPkgStore provides access to the forwarding packages of all
 	// active channels. This gives the switch the ability to read arbitrary
 	// forwarding packages, and ack settles and fails contained within them.
-	SwitchPackager channeldb.FwdOperator
+	FwdPkgStore chanstate.GlobalFwdPkgStore
 
 	// ExtractErrorEncrypter is an interface allowing switch to reextract
 	// error encrypters stored in the circuit map on restarts, since they
@@ -230,6 +231,12 @@ type Config struct {
 
 	// IsAlias returns whether or not a given SCID is an alias.
 	IsAlias func(scid lnwire.ShortChannelID) bool
+
+	// ResourceManager is an optional manager that decides whether a
+	// forwarded htlc may lock up the resources of its incoming channel,
+	// based on the reputation of its outgoing channel. If it's not set,
+	// all forwards are admitted.
+	ResourceManager fn.Option[*ResourceManager]
 }
 
 // Switch is the central messaging bus for all incoming/outgoing HTLCs.
@@ -773,8 +780,16 @@ func (s *Switch) ForwardPackets(linkQuit <-chan struct{},
 	}
 
 	// Now, forward any packets for circuits that were successfully added to
-	// the switch's circuit map.
+	// the switch's circuit map, unless they can't be granted the resources
+	// of their incoming channel.
 	for _, packet := range addedPackets {
+		if linkErr := s.allocateResources(packet); linkErr != nil {
+			// We don't handle the error here since this method
+			// always returns an error.
+			_ = s.failAddPacket(packet, linkErr)
+			continue
+		}
+
 		err := s.routeAsync(packet, fwdChan, linkQuit)
 		if err != nil {
 			return fmt.Errorf("failed to forward packet %w", err)
@@ -823,6 +838,92 @@ func (s *Switch) ForwardPackets(linkQuit <-chan struct{},
 	return nil
 }
 
+// allocateResources asks the resource manager, if any, whether the forwarded
+// add packet may lock up the resources of its incoming channel. A link error
+// is returned if the htlc must be failed back. Otherwise, the accountable
+// signal of the outgoing htlc is set as decided by the resource manager.
+func (s *Switch) allocateResources(packet *htlcPacket) *LinkError {
+	if s.cfg.ResourceManager.IsNone() ||
+		packet.incomingChanID == hop.Source {
+
+		return nil
+	}
+	resourceMgr := s.cfg.ResourceManager.UnsafeFromSome()
+
+	// If the incoming link is gone, we let the forwarding logic fail the
+	// htlc back.
+	s.indexMtx.RLock()
+	incomingLink, err := s.getLinkByShortID(packet.incomingChanID)
+	s.indexMtx.RUnlock()
+	if err != nil {
+		return nil
+	}
+
+	accountableType := uint64(lnwire.ExperimentalAccountableType)
+	incomingAccountable := bytes.Equal(
+		packet.inWireCustomRecords[accountableType],
+		[]byte{lnwire.ExperimentalAccountable},
+	)
+
+	decision, err := resourceMgr.AddHtlc(&ProposedHtlc{
+		IncomingCircuit: packet.inKey(),
+		OutgoingChannel: packet.outgoingChanID,
+		IncomingAmount:  packet.incomingAmount,
+		OutgoingAmount:  packet.amount,
+		OutgoingExpiry:  packet.outgoingTimeout,
+		Height:          atomic.LoadUint32(&s.bestHeight),
+		Accountable:     incomingAccountable,
+	}, incomingLink.IncomingLimits())
+	if err != nil {
+		log.Errorf("Unable to allocate resources for htlc %v: %v",
+			packet.inKey(), err)
+
+		return NewLinkError(&lnwire.FailTemporaryNodeFailure{})
+	}
+
+	s.cfg.HtlcNotifier.NotifyResourceDecisionEvent(
+		newHtlcKey(packet), newHtlcInfo(packet), incomingAccountable,
+		*decision,
+	)
+
+	if !decision.Admitted() {
+		return NewDetailedLinkError(
+			lnwire.NewTemporaryChannelFailure(nil),
+			OutgoingFailureInsufficientResources,
+		)
+	}
+
+	// Only update the accountable signal if the incoming link forwards it
+	// at all.
+	htlc, ok := packet.htlc.(*lnwire.UpdateAddHTLC)
+	if ok {
+		if _, set := htlc.CustomRecords[accountableType]; set {
+			value := byte(lnwire.ExperimentalUnaccountable)
+			if decision.OutgoingAccountable {
+				value = lnwire.ExperimentalAccountable
+			}
+			htlc.CustomRecords[accountableType] = []byte{value}
+		}
+	}
+
+	return nil
+}
+
+// releaseResources tells the resource manager, if any, that the forwarded
+// htlc with the given incoming circuit key was resolved.
+func (s *Switch) releaseResources(inKey CircuitKey, settled bool) {
+	s.cfg.ResourceManager.WhenSome(func(resourceMgr *ResourceManager) {
+		if inKey.ChanID == hop.Source {
+			return
+		}
+
+		if err := resourceMgr.ResolveHtlc(inKey, settled); err != nil {
+			log.Errorf("Unable to release resources of htlc %v: %v",
+				inKey, err)
+		}
+	})
+}
+
 // logFwdErrs logs any errors received on `fwdChan`.
 func (s *Switch) logFwdErrs(num *int, wg *sync.WaitGroup, fwdChan chan error) {
 	defer s.wg.Done()
@@ -1230,6 +1331,10 @@ func (s *Switch) checkCircularForward(incoming, outgoing lnwire.ShortChannelID,
 // The ciphertext will be derived from the failure message proivded by context.
 // This method returns the failErr if all other steps complete successfully.
 func (s *Switch) failAddPacket(packet *htlcPacket, failure *LinkError) error {
+	// The htlc won't be forwarded, so it no longer holds any resources of
+	// its incoming channel.
+	s.releaseResources(packet.inKey(), false)
+
 	// Encrypt the failure so that the sender will be able to read the error
 	// message. Since we failed this packet, we use EncryptFirstHop to
 	// obfuscate the failure for their eyes only.
@@ -1378,9 +1483,7 @@ func (s *Switch) closeCircuit(pkt *htlcPacket) (*PaymentCircuit, error) {
 // we're the originator of the payment, so the link stops attempting to
 // re-broadcast.
 func (s *Switch) ackSettleFail(settleFailRefs ...channeldb.SettleFailRef) error {
-	return kvdb.Batch(s.cfg.DB, func(tx kvdb.RwTx) error {
-		return s.cfg.SwitchPackager.AckSettleFails(tx, settleFailRefs...)
-	})
+	return s.cfg.FwdPkgStore.AckSettleFailRefs(settleFailRefs...)
 }
 
 // teardownCircuit removes a pending or open circuit from the switch's circuit
@@ -1906,20 +2009,7 @@ func (s *Switch) reforwardResponses() error {
 // channel identifier.
 func (s *Switch) loadChannelFwdPkgs(source lnwire.ShortChannelID) ([]*channeldb.FwdPkg, error) {
 
-	var fwdPkgs []*channeldb.FwdPkg
-	if err := kvdb.View(s.cfg.DB, func(tx kvdb.RTx) error {
-		var err error
-		fwdPkgs, err = s.cfg.SwitchPackager.LoadChannelFwdPkgs(
-			tx, source,
-		)
-		return err
-	}, func() {
-		fwdPkgs = nil
-	}); err != nil {
-		return nil, err
-	}
-
-	return fwdPkgs, nil
+	return s.cfg.FwdPkgStore.LoadChannelFwdPkgs(source)
 }
 
 // reforwardSettleFails parses the Settle and Fail HTLCs from the list of
@@ -3073,6 +3163,8 @@ func (s *Switch) handlePacketSettle(packet *htlcPacket) error {
 		return nil
 	}
 
+	s.releaseResources(packet.inKey(), true)
+
 	// If this is an HTLC settle, and it wasn't from a locally initiated
 	// HTLC, then we'll log a forwarding event so we can flush it to disk
 	// later.
@@ -3083,23 +3175,24 @@ func (s *Switch) handlePacketSettle(packet *htlcPacket) error {
 			circuit.IncomingAmount-circuit.OutgoingAmount,
 			circuit.Incoming.ChanID, circuit.Outgoing.ChanID)
 
-		s.fwdEventMtx.Lock()
-		s.pendingFwdingEvents = append(
-			s.pendingFwdingEvents,
-			channeldb.ForwardingEvent{
-				Timestamp:      time.Now(),
-				IncomingChanID: circuit.Incoming.ChanID,
-				OutgoingChanID: circuit.Outgoing.ChanID,
-				AmtIn:          circuit.IncomingAmount,
-				AmtOut:         circuit.OutgoingAmount,
-				IncomingHtlcID: fn.Some(
-					circuit.Incoming.HtlcID,
-				),
-				OutgoingHtlcID: fn.Some(
-					circuit.Outgoing.HtlcID,
-				),
-			},
+		event := channeldb.ForwardingEvent{
+			Timestamp:      time.Now(),
+			IncomingChanID: circuit.Incoming.ChanID,
+			OutgoingChanID: circuit.Outgoing.ChanID,
+			AmtIn:          circuit.IncomingAmount,
+			AmtOut:         circuit.OutgoingAmount,
+			IncomingHtlcID: fn.Some(circuit.Incoming.HtlcID),
+			OutgoingHtlcID: fn.Some(circuit.Outgoing.HtlcID),
+		}
+
+		// Record the peers of both channels so that the forwarding
+		// log can be aggregated per peer.
+		event.IncomingPeer, event.OutgoingPeer = s.fwdEventPeers(
+			circuit,
 		)
+
+		s.fwdEventMtx.Lock()
+		s.pendingFwdingEvents = append(s.pendingFwdingEvents, event)
 		s.fwdEventMtx.Unlock()
 	}
 
@@ -3107,6 +3200,94 @@ func (s *Switch) handlePacketSettle(packet *htlcPacket) error {
 	return s.mailOrchestrator.Deliver(packet.incomingChanID, packet)
 }
 
+// fwdEventPeers returns the public keys of the peers of the incoming and
+// outgoing channel of the given circuit. Each peer is taken from the link of
+// its channel, or from the channel database if the link is gone already, for
+// example because the channel was closed in the meantime. A peer that can't be
+// found is returned as none.
+func (s *Switch) fwdEventPeers(circuit *PaymentCircuit) (
+	fn.Option[route.Vertex], fn.Option[route.Vertex]) {
+
+	incoming := s.linkPeer(circuit.Incoming.ChanID)
+	outgoing := s.linkPeer(circuit.Outgoing.ChanID)
+	if incoming.IsSome() && outgoing.IsSome() {
+		return incoming, outgoing
+	}
+
+	peers, err := s.fetchChannelPeers()
+	if err != nil {
+		log.Warnf("Unable to fetch channel peers for forwarding "+
+			"event: %v", err)
+
+		return incoming, outgoing
+	}
+
+	lookup := func(scid lnwire.ShortChannelID) fn.Option[route.Vertex] {
+		peer, ok := peers[scid]
+		if !ok {
+			return fn.None[route.Vertex]()
+		}
+
+		return fn.Some(peer)
+	}
+
+	incoming = incoming.Alt(lookup(circuit.Incoming.ChanID))
+	outgoing = outgoing.Alt(lookup(circuit.Outgoing.ChanID))
+
+	return incoming, outgoing
+}
+
+// linkPeer returns the public key of the peer of the link with the given short
+// channel ID, if the link is known.
+func (s *Switch) linkPeer(scid lnwire.ShortChannelID) fn.Option[route.Vertex] {
+	s.indexMtx.RLock()
+	defer s.indexMtx.RUnlock()
+
+	link, err := s.getLinkByShortID(scid)
+	if err != nil {
+		return fn.None[route.Vertex]()
+	}
+
+	return fn.Some(route.Vertex(link.PeerPubKey()))
+}
+
+// fetchChannelPeers returns the public keys of the peers of all our channels,
+// including the closed ones, keyed by their short channel IDs. Zero-conf
+// channels are found by their alias as well as by their confirmed short
+// channel ID.
+func (s *Switch) fetchChannelPeers() (map[lnwire.ShortChannelID]route.Vertex,
+	error) {
+
+	channels, err := s.cfg.FetchAllChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make(map[lnwire.ShortChannelID]route.Vertex)
+	for _, channel := range channels {
+		peer := route.NewVertex(channel.IdentityPub)
+		peers[channel.ShortChannelID] = peer
+
+		if channel.IsZeroConf() && channel.ZeroConfConfirmed() {
+			peers[channel.ZeroConfRealScid()] = peer
+		}
+	}
+
+	closed, err := s.cfg.FetchClosedChannels(false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, summary := range closed {
+		peer := route.NewVertex(summary.RemotePub)
+		if _, ok := peers[summary.ShortChanID]; !ok {
+			peers[summary.ShortChanID] = peer
+		}
+	}
+
+	return peers, nil
+}
+
 // handlePacketFail handles forwarding a fail packet.
 func (s *Switch) handlePacketFail(packet *htlcPacket,
 	htlc *lnwire.UpdateFailHTLC) error {
@@ -3135,6 +3316,8 @@ func (s *Switch) handlePacketFail(packet *htlcPacket,
 		return nil
 	}
 
+	s.releaseResources(packet.inKey(), false)
+
 	// Exit early if this hasSource is true. This flag is only set via
 	// mailbox's `FailAdd`. This method has two callsites,
 	// - the packet has timed out after `MailboxDeliveryTimeout`, defaults
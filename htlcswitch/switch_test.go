@@ -24,8 +24,10 @@ import (
 	"github.com/flokiorg/flnd/lntypes"
 	"github.com/flokiorg/flnd/lnwallet/chainfee"
 	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/flnd/ticker"
 	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/crypto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -2984,6 +2986,80 @@ func TestMultiHopPaymentForwardingEvents(t *testing.T) {
 	}
 }
 
+// TestSwitchFwdEventPeers tests that the peers of a forwarding event are
+// resolved for each side on its own, from the link if it's still there and
+// from the channel state otherwise.
+func TestSwitchFwdEventPeers(t *testing.T) {
+	t.Parallel()
+
+	alicePeer, err := newMockServer(
+		t, "alice", testStartingHeight, nil, testDefaultDelta,
+	)
+	require.NoError(t, err, "unable to create alice server")
+	bobPeer, err := newMockServer(
+		t, "bob", testStartingHeight, nil, testDefaultDelta,
+	)
+	require.NoError(t, err, "unable to create bob server")
+
+	s, err := initSwitchWithTempDB(t, testStartingHeight)
+	require.NoError(t, err, "unable to init switch")
+	require.NoError(t, s.Start())
+	defer s.Stop()
+
+	chanID1, chanID2, aliceScid, bobScid := genIDs()
+	aliceLink := newMockChannelLink(
+		s, chanID1, aliceScid, emptyScid, alicePeer, true, false,
+		false, false,
+	)
+	bobLink := newMockChannelLink(
+		s, chanID2, bobScid, emptyScid, bobPeer, true, false, false,
+		false,
+	)
+	require.NoError(t, s.AddLink(aliceLink))
+	require.NoError(t, s.AddLink(bobLink))
+
+	// The channel of Bob is closed, while a third channel is unknown to
+	// the channel state.
+	closedPriv, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+	closedPeer := route.NewVertex(closedPriv.PubKey())
+	s.cfg.FetchClosedChannels = func(
+		bool) ([]*channeldb.ChannelCloseSummary, error) {
+
+		return []*channeldb.ChannelCloseSummary{{
+			ShortChanID: bobScid,
+			RemotePub:   closedPriv.PubKey(),
+		}}, nil
+	}
+	unknownScid := lnwire.NewShortChanIDFromInt(9999)
+
+	circuit := &PaymentCircuit{
+		Incoming: CircuitKey{ChanID: aliceScid},
+		Outgoing: &CircuitKey{ChanID: bobScid},
+	}
+
+	// While both links are there, the peers are taken from them.
+	incoming, outgoing := s.fwdEventPeers(circuit)
+	require.Equal(t, fn.Some(route.Vertex(alicePeer.PubKey())), incoming)
+	require.Equal(t, fn.Some(route.Vertex(bobPeer.PubKey())), outgoing)
+
+	// Once the link of the outgoing channel is gone, its peer is taken
+	// from the close summary, while the incoming peer is still known.
+	s.RemoveLink(chanID2)
+
+	incoming, outgoing = s.fwdEventPeers(circuit)
+	require.Equal(t, fn.Some(route.Vertex(alicePeer.PubKey())), incoming)
+	require.Equal(t, fn.Some(closedPeer), outgoing)
+
+	// A channel that can't be found doesn't keep the other peer from
+	// being recorded.
+	circuit.Outgoing.ChanID = unknownScid
+
+	incoming, outgoing = s.fwdEventPeers(circuit)
+	require.Equal(t, fn.Some(route.Vertex(alicePeer.PubKey())), incoming)
+	require.True(t, outgoing.IsNone())
+}
+
 // TestUpdateFailMalformedHTLCErrorConversion tests that we're able to properly
 // convert malformed HTLC errors that originate at the direct link, as well as
 // during multi-hop HTLC forwarding.
@@ -5554,3 +5630,95 @@ func testSwitchAliasInterceptFail(t *testing.T, zeroConf bool) {
 
 	require.NoError(t, interceptSwitch.Stop())
 }
+
+// TestSwitchResourceManager tests that forwards are failed back if the
+// resource manager doesn't grant them the resources of their incoming
+// channel.
+func TestSwitchResourceManager(t *testing.T) {
+	t.Parallel()
+
+	alicePeer, err := newMockServer(
+		t, "alice", testStartingHeight, nil, testDefaultDelta,
+	)
+	require.NoError(t, err)
+	bobPeer, err := newMockServer(
+		t, "bob", testStartingHeight, nil, testDefaultDelta,
+	)
+	require.NoError(t, err)
+
+	s, err := initSwitchWithTempDB(t, testStartingHeight)
+	require.NoError(t, err)
+
+	resourceMgr, _, _ := newTestResourceManager(t)
+	s.cfg.ResourceManager = fn.Some(resourceMgr)
+
+	require.NoError(t, s.Start())
+	defer func() {
+		require.NoError(t, s.Stop())
+	}()
+
+	chanID1, chanID2, aliceChanID, bobChanID := genIDs()
+
+	aliceChannelLink := newMockChannelLink(
+		s, chanID1, aliceChanID, emptyScid, alicePeer, true, false,
+		false, false,
+	)
+	aliceChannelLink.incomingLimits = testResourceLimits
+	bobChannelLink := newMockChannelLink(
+		s, chanID2, bobChanID, emptyScid, bobPeer, true, false, false,
+		false,
+	)
+	require.NoError(t, s.AddLink(aliceChannelLink))
+	require.NoError(t, s.AddLink(bobChannelLink))
+
+	newPacket := func(htlcID uint64, accountable bool) *htlcPacket {
+		records := lnwire.CustomRecords{}
+		if accountable {
+			records[uint64(lnwire.ExperimentalAccountableType)] =
+				[]byte{lnwire.ExperimentalAccountable}
+		}
+
+		return &htlcPacket{
+			incomingChanID:      aliceChannelLink.ShortChanID(),
+			incomingHTLCID:      htlcID,
+			outgoingChanID:      bobChannelLink.ShortChanID(),
+			incomingAmount:      1_100,
+			amount:              1_000,
+			incomingTimeout:     testStartingHeight + 50,
+			outgoingTimeout:     testStartingHeight + 40,
+			obfuscator:          NewMockObfuscator(),
+			inWireCustomRecords: records,
+			htlc: &lnwire.UpdateAddHTLC{
+				Amount: 1_000,
+			},
+		}
+	}
+
+	// An accountable htlc towards bob, who has no reputation yet, is
+	// failed back to alice.
+	require.NoError(t, s.ForwardPackets(nil, newPacket(0, true)))
+
+	select {
+	case pkt := <-aliceChannelLink.packets:
+		require.IsType(t, &lnwire.UpdateFailHTLC{}, pkt.htlc)
+
+	case <-bobChannelLink.packets:
+		t.Fatal("htlc should not be forwarded")
+
+	case <-time.After(time.Second):
+		t.Fatal("htlc was not failed back")
+	}
+
+	// An unaccountable htlc is granted general resources and forwarded.
+	require.NoError(t, s.ForwardPackets(nil, newPacket(1, false)))
+
+	select {
+	case <-bobChannelLink.packets:
+
+	case <-aliceChannelLink.packets:
+		t.Fatal("htlc should not be failed back")
+
+	case <-time.After(time.Second):
+		t.Fatal("htlc was not forwarded")
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/flokiorg/flnd/chainntnfs"
 	"github.com/flokiorg/flnd/channeldb"
 	"github.com/flokiorg/flnd/chanstate"
 	"github.com/flokiorg/flnd/contractcourt"
@@ -28,6 +29,7 @@ import (
 	"github.com/flokiorg/flnd/kvdb"
 	"github.com/flokiorg/flnd/lnpeer"
 	"github.com/flokiorg/flnd/lntest/channels"
+	"github.com/flokiorg/flnd/lntest/mock"
 	"github.com/flokiorg/flnd/lntest/wait"
 	"github.com/flokiorg/flnd/lntypes"
 	"github.com/flokiorg/flnd/lnwallet"
@@ -1117,6 +1119,22 @@ func newHopNetwork() *hopNetwork {
 	}
 }
 
+// testMessageSigner returns a message signer that holds the private key of
+// the local multisig key of the given channel.
+func testMessageSigner(
+	channel *lnwallet.LightningChannel) keychain.MessageSignerRing {
+
+	multiSigKey := channel.State().LocalChanCfg.MultiSigKey.PubKey
+	for _, key := range [][]byte{alicePrivKey, bobPrivKey, carolPrivKey} {
+		priv, pub := crypto.PrivKeyFromBytes(key)
+		if pub.IsEqual(multiSigKey) {
+			return &mock.SecretKeyRing{RootKey: priv}
+		}
+	}
+
+	return nil
+}
+
 func (h *hopNetwork) createChannelLink(server, peer *mockServer,
 	channel *lnwallet.LightningChannel,
 	decoder *mockIteratorDecoder) (ChannelLink, error) {
@@ -1192,6 +1210,14 @@ func (h *hopNetwork) createChannelLink(server, peer *mockServer,
 			HtlcNotifier:               server.htlcSwitch.cfg.HtlcNotifier,
 			GetAliases:                 getAliases,
 			ShouldFwdExpAccountability: func() bool { return true },
+			MessageSigner:              testMessageSigner(channel),
+			MaxLocalCSVDelay:           1000,
+			SpliceWallet:               newMockSpliceWallet(),
+			ChainNotifier: &mock.ChainNotifier{
+				ConfChan: make(
+					chan *chainntnfs.TxConfirmation, 1,
+				),
+			},
 		},
 		channel,
 	)
@@ -0,0 +1,609 @@
+package htlcswitch
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/htlcswitch/hop"
+	"github.com/flokiorg/flnd/invoices"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/record"
+	"github.com/flokiorg/flnd/routing/route"
+)
+
+// ErrTrampolinePaymentPending is returned by a TrampolinePayer if it's unable
+// to determine the outcome of a relayed payment before shutting down. The
+// htlcs of the payment are left unresolved, as the payment may still succeed.
+var ErrTrampolinePaymentPending = errors.New("trampoline payment pending")
+
+// TrampolineHtlc describes an htlc that was delivered to us along with a
+// trampoline onion.
+type TrampolineHtlc struct {
+	// CircuitKey identifies the htlc.
+	CircuitKey models.CircuitKey
+
+	// PaymentHash is the payment hash of the htlc.
+	PaymentHash lntypes.Hash
+
+	// Amount is the amount of the htlc.
+	Amount lnwire.MilliLoki
+
+	// Expiry is the absolute expiry height of the htlc.
+	Expiry uint32
+
+	// CurrentHeight is the current block height.
+	CurrentHeight uint32
+
+	// WireCustomRecords are the custom records of the update_add_htlc
+	// message the htlc was added with.
+	WireCustomRecords lnwire.CustomRecords
+
+	// Payload is the payload of the outer onion, which carries the
+	// trampoline onion.
+	Payload *hop.Payload
+}
+
+// TrampolinePayment describes a payment a trampoline node sends to relay the
+// payment it received to the next trampoline node or the recipient.
+type TrampolinePayment struct {
+	// PaymentHash is the payment hash of the relayed payment.
+	PaymentHash lntypes.Hash
+
+	// Target is the node the payment is relayed to.
+	Target route.Vertex
+
+	// Amount is the amount to deliver to the target.
+	Amount lnwire.MilliLoki
+
+	// FeeLimit is the maximum fee that may be spent on the route to the
+	// target.
+	FeeLimit lnwire.MilliLoki
+
+	// FinalCltvExpiry is the absolute expiry height the payment should be
+	// delivered to the target with.
+	FinalCltvExpiry uint32
+
+	// MaxCltvExpiry is the maximum absolute expiry height of the htlcs the
+	// payment is sent with.
+	MaxCltvExpiry uint32
+
+	// CurrentHeight is the block height the payment is relayed at.
+	CurrentHeight uint32
+
+	// PaymentAddr is the payment address the payment is delivered with.
+	PaymentAddr [32]byte
+
+	// TrampolineOnion is the trampoline onion for the target, if it's the
+	// next trampoline node.
+	TrampolineOnion []byte
+}
+
+// TrampolineFailResolution is an htlc resolution which fails an htlc that was
+// delivered to us along with a trampoline onion.
+type TrampolineFailResolution struct {
+	// circuitKey is the key of the htlc for which we have a resolution.
+	circuitKey models.CircuitKey
+
+	// Failure is the failure the htlc should be failed with.
+	Failure lnwire.FailureMessage
+}
+
+// CircuitKey returns the circuit key for the htlc that we have a resolution
+// for.
+//
+// NOTE: Part of the invoices.HtlcResolution interface.
+func (f *TrampolineFailResolution) CircuitKey() models.CircuitKey {
+	return f.circuitKey
+}
+
+// trampolineRecipientPayload is the payload the invoice registry is notified
+// with for htlcs of trampoline payments we're the recipient of. The payment
+// address and total amount of the payment are taken from the trampoline
+// onion, rather than from the outer onion which was created by the last
+// trampoline node.
+type trampolineRecipientPayload struct {
+	*hop.Payload
+
+	mpp *record.MPP
+}
+
+// MultiPath returns the MPP record of the trampoline onion.
+//
+// NOTE: Part of the invoices.Payload interface.
+func (p *trampolineRecipientPayload) MultiPath() *record.MPP {
+	return p.mpp
+}
+
+// trampolineSetHtlc is an htlc that's part of a trampoline payment we relay.
+type trampolineSetHtlc struct {
+	amt      lnwire.MilliLoki
+	expiry   uint32
+	hodlChan chan<- interface{}
+}
+
+// trampolineSet is the set of htlcs of a trampoline payment we relay.
+type trampolineSet struct {
+	payload     *hop.TrampolinePayload
+	paymentAddr [32]byte
+	total       lnwire.MilliLoki
+	received    lnwire.MilliLoki
+	htlcs       map[models.CircuitKey]*trampolineSetHtlc
+	timer       *time.Timer
+
+	// relaying is true once the set is complete and the payment is being
+	// relayed.
+	relaying bool
+
+	// preimage is set once the relayed payment succeeded.
+	preimage fn.Option[lntypes.Preimage]
+
+	// failure is set once the set was failed.
+	failure lnwire.FailureMessage
+}
+
+// minExpiry returns the lowest expiry of the htlcs of the set.
+func (s *trampolineSet) minExpiry() uint32 {
+	var minExpiry uint32
+	for _, htlc := range s.htlcs {
+		if minExpiry == 0 || htlc.expiry < minExpiry {
+			minExpiry = htlc.expiry
+		}
+	}
+
+	return minExpiry
+}
+
+// maxExpiry returns the highest expiry of the htlcs of the set.
+func (s *trampolineSet) maxExpiry() uint32 {
+	var maxExpiry uint32
+	for _, htlc := range s.htlcs {
+		maxExpiry = max(maxExpiry, htlc.expiry)
+	}
+
+	return maxExpiry
+}
+
+// resolution returns the resolution of the passed htlc of the set, or nil if
+// the set isn't resolved yet.
+func (s *trampolineSet) resolution(
+	key models.CircuitKey) invoices.HtlcResolution {
+
+	if s.failure != nil {
+		return &TrampolineFailResolution{
+			circuitKey: key,
+			Failure:    s.failure,
+		}
+	}
+
+	var resolution invoices.HtlcResolution
+	s.preimage.WhenSome(func(preimage lntypes.Preimage) {
+		resolution = invoices.NewSettleResolution(
+			preimage, key, 0, invoices.ResultSettled,
+		)
+	})
+
+	return resolution
+}
+
+// TrampolineRelayConfig holds the dependencies of the TrampolineRelay.
+type TrampolineRelayConfig struct {
+	// Policy is the policy we relay trampoline payments by.
+	Policy lnwire.TrampolinePolicy
+
+	// MinExpiryDelta is the minimum number of blocks between the highest
+	// expiry of the htlcs of a relayed payment and the lowest expiry of
+	// the htlcs we received for it.
+	MinExpiryDelta uint32
+
+	// MppTimeout is the time after which the htlcs of a trampoline payment
+	// are failed if they don't add up to its total amount.
+	MppTimeout time.Duration
+
+	// DecodeTrampolineOnion peels our layer off a trampoline onion.
+	DecodeTrampolineOnion func(onion,
+		rHash []byte) (*hop.TrampolinePayload, error)
+
+	// Registry is notified of the htlcs of trampoline payments we're the
+	// recipient of.
+	Registry InvoiceDatabase
+
+	// Payer sends the payments we relay.
+	Payer TrampolinePayer
+}
+
+// TrampolineRelay handles the htlcs that are delivered to us along with a
+// trampoline onion. It collects the htlcs of each trampoline payment, checks
+// that they pay the fee and CLTV delta of our trampoline policy, and relays
+// the payment to the next trampoline node or the recipient by finding a route
+// of its own. The htlcs are settled or failed once the relayed payment
+// completes.
+type TrampolineRelay struct {
+	started atomic.Bool
+	stopped atomic.Bool
+
+	cfg *TrampolineRelayConfig
+
+	// sets are the htlc sets of the trampoline payments we relay, keyed
+	// by payment hash.
+	sets map[lntypes.Hash]*trampolineSet
+
+	mu sync.Mutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// A compile time check to ensure TrampolineRelay implements the
+// TrampolineForwarder interface.
+var _ TrampolineForwarder = (*TrampolineRelay)(nil)
+
+// NewTrampolineRelay creates a new trampoline relay.
+func NewTrampolineRelay(cfg *TrampolineRelayConfig) *TrampolineRelay {
+	return &TrampolineRelay{
+		cfg:  cfg,
+		sets: make(map[lntypes.Hash]*trampolineSet),
+		quit: make(chan struct{}),
+	}
+}
+
+// Start starts the trampoline relay.
+func (r *TrampolineRelay) Start() error {
+	if r.started.Swap(true) {
+		return fmt.Errorf("trampoline relay started more than once")
+	}
+
+	log.Infof("Trampoline relay starting with policy: base_fee=%v, "+
+		"fee_rate=%v, cltv_delta=%v", r.cfg.Policy.FeeBaseMSat,
+		r.cfg.Policy.FeeProportionalMillionths,
+		r.cfg.Policy.CltvExpiryDelta)
+
+	return nil
+}
+
+// Stop stops the trampoline relay and waits for the payments it relays to
+// return. Payments that are still in flight are left unresolved, and are
+// picked up again when their htlcs are reprocessed after restart.
+func (r *TrampolineRelay) Stop() error {
+	if r.stopped.Swap(true) {
+		return fmt.Errorf("trampoline relay stopped more than once")
+	}
+
+	log.Info("Trampoline relay shutting down...")
+	defer log.Debug("Trampoline relay shutdown complete")
+
+	close(r.quit)
+	r.wg.Wait()
+
+	r.mu.Lock()
+	for _, set := range r.sets {
+		if set.timer != nil {
+			set.timer.Stop()
+		}
+	}
+	r.mu.Unlock()
+
+	return nil
+}
+
+// NotifyTrampolineHtlc processes an htlc that was delivered to us along with
+// a trampoline onion. The return value describes how the htlc should be
+// resolved. If the htlc can't be resolved immediately, the resolution is sent
+// on the passed hodlChan later.
+//
+// NOTE: Part of the TrampolineForwarder interface.
+func (r *TrampolineRelay) NotifyTrampolineHtlc(htlc *TrampolineHtlc,
+	hodlChan chan<- interface{}) (invoices.HtlcResolution, error) {
+
+	payload, err := r.cfg.DecodeTrampolineOnion(
+		htlc.Payload.TrampolineOnion(), htlc.PaymentHash[:],
+	)
+	if err != nil {
+		log.Debugf("Unable to decode trampoline onion of htlc %v: %v",
+			htlc.CircuitKey, err)
+
+		return &TrampolineFailResolution{
+			circuitKey: htlc.CircuitKey,
+			Failure: lnwire.NewInvalidOnionPayload(
+				uint64(record.TrampolineOnionType), 0,
+			),
+		}, nil
+	}
+
+	// If the onion doesn't name an outgoing node, we're the recipient of
+	// the payment and hand the htlc to the invoice registry.
+	if payload.OutgoingNodeID == nil {
+		return r.cfg.Registry.NotifyExitHopHtlc(
+			htlc.PaymentHash, htlc.Amount, htlc.Expiry,
+			int32(htlc.CurrentHeight), htlc.CircuitKey, hodlChan,
+			htlc.WireCustomRecords, &trampolineRecipientPayload{
+				Payload: htlc.Payload,
+				mpp:     payload.MPP,
+			},
+		)
+	}
+
+	fail := func(failure lnwire.FailureMessage) invoices.HtlcResolution {
+		return &TrampolineFailResolution{
+			circuitKey: htlc.CircuitKey,
+			Failure:    failure,
+		}
+	}
+
+	// The outer onion must tell us the total amount of the payment, so we
+	// know when we received all of its htlcs.
+	mpp := htlc.Payload.MultiPath()
+	if mpp == nil {
+		return fail(lnwire.NewInvalidOnionPayload(
+			uint64(record.MPPOnionType), 0,
+		)), nil
+	}
+
+	// If we pay a recipient that doesn't understand trampoline onions, we
+	// deliver the whole payment ourselves.
+	if payload.MPP != nil &&
+		payload.MPP.TotalMsat() != payload.AmountToForward {
+
+		return fail(lnwire.NewInvalidOnionPayload(
+			uint64(record.MPPOnionType), 0,
+		)), nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pruneSets(htlc.CurrentHeight)
+
+	set, ok := r.sets[htlc.PaymentHash]
+
+	// If the htlc is part of a set we already know, it's being replayed
+	// after a restart of its link. We update the channel its resolution
+	// is delivered on, and return the resolution if the set is resolved.
+	if ok {
+		if setHtlc, ok := set.htlcs[htlc.CircuitKey]; ok {
+			setHtlc.hodlChan = hodlChan
+			return set.resolution(htlc.CircuitKey), nil
+		}
+	}
+
+	// A resolved set is replaced by a new one, unless the payment
+	// succeeded, in which case we settle the htlc right away.
+	if ok && (set.relaying || set.failure != nil) {
+		if set.preimage.IsSome() {
+			return set.resolution(htlc.CircuitKey), nil
+		}
+
+		if set.failure == nil {
+			log.Debugf("Failing htlc %v of trampoline payment %v "+
+				"that is already being relayed",
+				htlc.CircuitKey, htlc.PaymentHash)
+
+			return fail(&lnwire.FailTemporaryNodeFailure{}), nil
+		}
+
+		ok = false
+	}
+
+	if !ok {
+		set = &trampolineSet{
+			payload:     payload,
+			paymentAddr: mpp.PaymentAddr(),
+			total:       mpp.TotalMsat(),
+			htlcs:       make(map[models.CircuitKey]*trampolineSetHtlc),
+		}
+		r.sets[htlc.PaymentHash] = set
+
+		// If we already relayed the payment before a restart, we can
+		// settle its htlcs right away.
+		preimage, err := r.cfg.Payer.FetchPreimage(htlc.PaymentHash)
+		if err != nil {
+			return nil, err
+		}
+		set.preimage = preimage
+		if preimage.IsSome() {
+			set.relaying = true
+		}
+
+		hash := htlc.PaymentHash
+		set.timer = time.AfterFunc(r.cfg.MppTimeout, func() {
+			r.timeoutSet(hash, set)
+		})
+	}
+
+	set.htlcs[htlc.CircuitKey] = &trampolineSetHtlc{
+		amt:      htlc.Amount,
+		expiry:   htlc.Expiry,
+		hodlChan: hodlChan,
+	}
+
+	if resolution := set.resolution(htlc.CircuitKey); resolution != nil {
+		return resolution, nil
+	}
+
+	// All htlcs of the set must agree on the payment.
+	if mpp.PaymentAddr() != set.paymentAddr ||
+		mpp.TotalMsat() != set.total {
+
+		delete(set.htlcs, htlc.CircuitKey)
+
+		return fail(lnwire.NewFailIncorrectDetails(
+			htlc.Amount, htlc.CurrentHeight,
+		)), nil
+	}
+
+	set.received += htlc.Amount
+	if set.received < set.total {
+		return nil, nil
+	}
+
+	set.relaying = true
+	set.timer.Stop()
+
+	// Make sure the htlcs pay the fee and leave the CLTV delta of our
+	// policy before relaying the payment.
+	amt := set.payload.AmountToForward
+	fee := r.cfg.Policy.ComputeFee(amt)
+	if set.received < amt+fee {
+		log.Debugf("Trampoline payment %v pays insufficient fee: "+
+			"received=%v, amt_to_forward=%v, fee=%v",
+			htlc.PaymentHash, set.received, amt, fee)
+
+		r.resolveSet(htlc.CircuitKey, set, fn.None[lntypes.Preimage](),
+			&lnwire.FailTrampolineFeeInsufficient{})
+
+		return set.resolution(htlc.CircuitKey), nil
+	}
+
+	minExpiry := set.minExpiry()
+	outgoingCltv := set.payload.OutgoingCLTV
+	delta := uint32(r.cfg.Policy.CltvExpiryDelta)
+	if minExpiry < outgoingCltv+delta ||
+		minExpiry < outgoingCltv+r.cfg.MinExpiryDelta ||
+		outgoingCltv <= htlc.CurrentHeight {
+
+		log.Debugf("Trampoline payment %v expires too soon: "+
+			"expiry=%v, outgoing_cltv=%v, cltv_delta=%v",
+			htlc.PaymentHash, minExpiry, outgoingCltv, delta)
+
+		r.resolveSet(htlc.CircuitKey, set, fn.None[lntypes.Preimage](),
+			&lnwire.FailTrampolineExpiryTooSoon{})
+
+		return set.resolution(htlc.CircuitKey), nil
+	}
+
+	payment := &TrampolinePayment{
+		PaymentHash:     htlc.PaymentHash,
+		Target:          route.NewVertex(set.payload.OutgoingNodeID),
+		Amount:          amt,
+		FeeLimit:        set.received - amt,
+		FinalCltvExpiry: outgoingCltv,
+		MaxCltvExpiry:   minExpiry - r.cfg.MinExpiryDelta,
+		CurrentHeight:   htlc.CurrentHeight,
+		TrampolineOnion: set.payload.NextOnion,
+	}
+
+	if set.payload.MPP != nil {
+		payment.PaymentAddr = set.payload.MPP.PaymentAddr()
+	} else if _, err := rand.Read(payment.PaymentAddr[:]); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Relaying trampoline payment %v of %v to %v",
+		htlc.PaymentHash, amt, payment.Target)
+
+	r.wg.Add(1)
+	go r.relay(set, payment)
+
+	return nil, nil
+}
+
+// relay sends the relayed payment and resolves the htlcs of its set with the
+// outcome.
+func (r *TrampolineRelay) relay(set *trampolineSet,
+	payment *TrampolinePayment) {
+
+	defer r.wg.Done()
+
+	preimage, err := r.cfg.Payer.SendTrampolinePayment(payment)
+	if errors.Is(err, ErrTrampolinePaymentPending) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		log.Errorf("Unable to relay trampoline payment %v: %v",
+			payment.PaymentHash, err)
+
+		r.resolveSet(
+			models.CircuitKey{}, set, fn.None[lntypes.Preimage](),
+			&lnwire.FailTemporaryNodeFailure{},
+		)
+
+		return
+	}
+
+	log.Infof("Relayed trampoline payment %v", payment.PaymentHash)
+
+	r.resolveSet(models.CircuitKey{}, set, fn.Some(preimage), nil)
+}
+
+// timeoutSet fails the htlcs of the passed set if it isn't complete by the
+// time the MPP timeout expires.
+func (r *TrampolineRelay) timeoutSet(hash lntypes.Hash, set *trampolineSet) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if set.relaying || set.failure != nil {
+		return
+	}
+
+	log.Debugf("Trampoline payment %v timed out with %v of %v received",
+		hash, set.received, set.total)
+
+	r.resolveSet(
+		models.CircuitKey{}, set, fn.None[lntypes.Preimage](),
+		&lnwire.FailMPPTimeout{},
+	)
+}
+
+// resolveSet settles the htlcs of the set with the preimage or fails them
+// with the failure, and sends the resolution of every htlc but skipKey to the
+// link it arrived on. skipKey is the htlc that is being processed, whose
+// resolution is returned to its link directly. The caller must hold the
+// relay's mutex.
+func (r *TrampolineRelay) resolveSet(skipKey models.CircuitKey,
+	set *trampolineSet, preimage fn.Option[lntypes.Preimage],
+	failure lnwire.FailureMessage) {
+
+	set.preimage = preimage
+	set.failure = failure
+
+	for key, htlc := range set.htlcs {
+		if key == skipKey || htlc.hodlChan == nil {
+			continue
+		}
+
+		select {
+		case htlc.hodlChan <- set.resolution(key):
+		case <-r.quit:
+			return
+		}
+
+		htlc.hodlChan = nil
+	}
+}
+
+// pruneSets removes the resolved sets whose htlcs all expired, as they can't
+// be replayed anymore. The caller must hold the relay's mutex.
+func (r *TrampolineRelay) pruneSets(height uint32) {
+	for hash, set := range r.sets {
+		resolved := set.preimage.IsSome() || set.failure != nil
+		if resolved && set.maxExpiry() < height {
+			delete(r.sets, hash)
+		}
+	}
+}
+
+// HodlUnsubscribeAll stops delivering resolutions on the passed channel.
+//
+// NOTE: Part of the TrampolineForwarder interface.
+func (r *TrampolineRelay) HodlUnsubscribeAll(subscriber chan<- interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, set := range r.sets {
+		for _, htlc := range set.htlcs {
+			if htlc.hodlChan == subscriber {
+				htlc.hodlChan = nil
+			}
+		}
+	}
+}
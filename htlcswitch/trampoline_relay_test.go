@@ -0,0 +1,293 @@
+package htlcswitch
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/graph/db/models"
+	"github.com/flokiorg/flnd/htlcswitch/hop"
+	"github.com/flokiorg/flnd/invoices"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/record"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	trampolineTestHeight = 100
+
+	// trampolineTestTimeout is the time we wait for resolutions to be
+	// delivered.
+	trampolineTestTimeout = 5 * time.Second
+)
+
+// mockTrampolinePayer is a TrampolinePayer that hands the payments it's asked
+// to send to the test and returns the outcome the test decides on.
+type mockTrampolinePayer struct {
+	payments chan *TrampolinePayment
+	results  chan fn.Result[lntypes.Preimage]
+}
+
+func newMockTrampolinePayer() *mockTrampolinePayer {
+	return &mockTrampolinePayer{
+		payments: make(chan *TrampolinePayment, 1),
+		results:  make(chan fn.Result[lntypes.Preimage], 1),
+	}
+}
+
+func (m *mockTrampolinePayer) SendTrampolinePayment(
+	p *TrampolinePayment) (lntypes.Preimage, error) {
+
+	m.payments <- p
+	result := <-m.results
+
+	return result.Unpack()
+}
+
+func (m *mockTrampolinePayer) FetchPreimage(
+	lntypes.Hash) (fn.Option[lntypes.Preimage], error) {
+
+	return fn.None[lntypes.Preimage](), nil
+}
+
+// trampolineRelayTestCtx holds the relay under test along with its mocked
+// dependencies.
+type trampolineRelayTestCtx struct {
+	t       *testing.T
+	relay   *TrampolineRelay
+	payer   *mockTrampolinePayer
+	hash    lntypes.Hash
+	addr    [32]byte
+	payload *hop.TrampolinePayload
+}
+
+func newTrampolineRelayTestCtx(t *testing.T,
+	mppTimeout time.Duration) *trampolineRelayTestCtx {
+
+	_, outgoingNode := crypto.PrivKeyFromBytes(bytes.Repeat([]byte{1}, 32))
+
+	ctx := &trampolineRelayTestCtx{
+		t:     t,
+		payer: newMockTrampolinePayer(),
+		hash:  lntypes.Hash{1},
+		addr:  [32]byte{2},
+		payload: &hop.TrampolinePayload{
+			AmountToForward: 100_000,
+			OutgoingCLTV:    trampolineTestHeight + 200,
+			OutgoingNodeID:  outgoingNode,
+			NextOnion:       []byte{3},
+		},
+	}
+
+	ctx.relay = NewTrampolineRelay(&TrampolineRelayConfig{
+		Policy: lnwire.TrampolinePolicy{
+			FeeBaseMSat:               1_000,
+			FeeProportionalMillionths: 1_000,
+			CltvExpiryDelta:           100,
+		},
+		MinExpiryDelta: 40,
+		MppTimeout:     mppTimeout,
+		DecodeTrampolineOnion: func(_,
+			_ []byte) (*hop.TrampolinePayload, error) {
+
+			return ctx.payload, nil
+		},
+		Payer: ctx.payer,
+	})
+	require.NoError(t, ctx.relay.Start())
+	t.Cleanup(func() {
+		require.NoError(t, ctx.relay.Stop())
+	})
+
+	return ctx
+}
+
+// notify notifies the relay of an htlc that's part of a trampoline payment of
+// the given total amount.
+func (c *trampolineRelayTestCtx) notify(id uint64, amt,
+	total lnwire.MilliLoki, expiry uint32,
+	hodlChan chan interface{}) invoices.HtlcResolution {
+
+	c.t.Helper()
+
+	var (
+		amtToFwd = uint64(amt)
+		onion    = []byte{3}
+		mpp      = record.NewMPP(total, c.addr)
+	)
+	tlvStream, err := tlv.NewStream(
+		record.NewAmtToFwdRecord(&amtToFwd),
+		mpp.Record(),
+		record.NewTrampolineOnionRecord(&onion),
+	)
+	require.NoError(c.t, err)
+
+	var b bytes.Buffer
+	require.NoError(c.t, tlvStream.Encode(&b))
+
+	payload, _, err := hop.ParseTLVPayload(&b)
+	require.NoError(c.t, err)
+
+	resolution, err := c.relay.NotifyTrampolineHtlc(&TrampolineHtlc{
+		CircuitKey: models.CircuitKey{
+			ChanID: lnwire.NewShortChanIDFromInt(1),
+			HtlcID: id,
+		},
+		PaymentHash:   c.hash,
+		Amount:        amt,
+		Expiry:        expiry,
+		CurrentHeight: trampolineTestHeight,
+		Payload:       payload,
+	}, hodlChan)
+	require.NoError(c.t, err)
+
+	return resolution
+}
+
+// receiveResolution waits for a resolution on the passed channel.
+func (c *trampolineRelayTestCtx) receiveResolution(
+	hodlChan chan interface{}) interface{} {
+
+	c.t.Helper()
+
+	select {
+	case resolution := <-hodlChan:
+		return resolution
+
+	case <-time.After(trampolineTestTimeout):
+		c.t.Fatalf("no resolution received")
+		return nil
+	}
+}
+
+// TestTrampolineRelay asserts that the htlcs of a trampoline payment are
+// collected until they add up to its total amount, and that the payment is
+// then relayed and its htlcs settled with the preimage.
+func TestTrampolineRelay(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTrampolineRelayTestCtx(t, time.Minute)
+
+	var (
+		hodlChan1 = make(chan interface{}, 1)
+		hodlChan2 = make(chan interface{}, 1)
+		expiry    = uint32(trampolineTestHeight + 400)
+	)
+
+	// The payment pays 1_100 msat of fees, which is exactly what our
+	// policy charges.
+	resolution := ctx.notify(0, 50_000, 101_100, expiry, hodlChan1)
+	require.Nil(t, resolution)
+
+	resolution = ctx.notify(1, 51_100, 101_100, expiry+10, hodlChan2)
+	require.Nil(t, resolution)
+
+	var payment *TrampolinePayment
+	select {
+	case payment = <-ctx.payer.payments:
+	case <-time.After(trampolineTestTimeout):
+		t.Fatalf("payment not relayed")
+	}
+
+	require.Equal(t, ctx.hash, payment.PaymentHash)
+	require.EqualValues(t, 100_000, payment.Amount)
+	require.EqualValues(t, 1_100, payment.FeeLimit)
+	require.Equal(t, ctx.payload.OutgoingCLTV, payment.FinalCltvExpiry)
+	require.Equal(t, expiry-40, payment.MaxCltvExpiry)
+	require.Equal(t, ctx.payload.NextOnion, payment.TrampolineOnion)
+
+	preimage := lntypes.Preimage{4}
+	ctx.payer.results <- fn.Ok(preimage)
+
+	for _, hodlChan := range []chan interface{}{hodlChan1, hodlChan2} {
+		resolution := ctx.receiveResolution(hodlChan)
+		settle, ok := resolution.(*invoices.HtlcSettleResolution)
+		require.True(t, ok)
+		require.Equal(t, preimage, settle.Preimage)
+	}
+
+	// A replay of an htlc of the settled payment is settled right away.
+	resolution = ctx.notify(0, 50_000, 101_100, expiry, hodlChan1)
+	require.IsType(t, &invoices.HtlcSettleResolution{}, resolution)
+}
+
+// TestTrampolineRelayFailures asserts that the htlcs of trampoline payments
+// that don't follow our policy, or that we fail to relay, are failed.
+func TestTrampolineRelayFailures(t *testing.T) {
+	t.Parallel()
+
+	const expiry = trampolineTestHeight + 400
+
+	assertFailure := func(t *testing.T, resolution interface{},
+		expected lnwire.FailureMessage) {
+
+		t.Helper()
+
+		fail, ok := resolution.(*TrampolineFailResolution)
+		require.True(t, ok)
+		require.Equal(t, expected, fail.Failure)
+	}
+
+	t.Run("insufficient fee", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := newTrampolineRelayTestCtx(t, time.Minute)
+		resolution := ctx.notify(
+			0, 101_000, 101_000, expiry, make(chan interface{}, 1),
+		)
+		assertFailure(
+			t, resolution, &lnwire.FailTrampolineFeeInsufficient{},
+		)
+	})
+
+	t.Run("expiry too soon", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := newTrampolineRelayTestCtx(t, time.Minute)
+		resolution := ctx.notify(
+			0, 101_100, 101_100, trampolineTestHeight+250,
+			make(chan interface{}, 1),
+		)
+		assertFailure(
+			t, resolution, &lnwire.FailTrampolineExpiryTooSoon{},
+		)
+	})
+
+	t.Run("mpp timeout", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := newTrampolineRelayTestCtx(t, 10*time.Millisecond)
+		hodlChan := make(chan interface{}, 1)
+		resolution := ctx.notify(0, 50_000, 101_100, expiry, hodlChan)
+		require.Nil(t, resolution)
+
+		assertFailure(
+			t, ctx.receiveResolution(hodlChan),
+			&lnwire.FailMPPTimeout{},
+		)
+	})
+
+	t.Run("relay failure", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := newTrampolineRelayTestCtx(t, time.Minute)
+		hodlChan := make(chan interface{}, 1)
+		resolution := ctx.notify(0, 101_100, 101_100, expiry, hodlChan)
+		require.Nil(t, resolution)
+
+		<-ctx.payer.payments
+		ctx.payer.results <- fn.Err[lntypes.Preimage](
+			errors.New("no route"),
+		)
+
+		assertFailure(
+			t, ctx.receiveResolution(hodlChan),
+			&lnwire.FailTemporaryNodeFailure{},
+		)
+	})
+}
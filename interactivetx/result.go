@@ -0,0 +1,259 @@
+package interactivetx
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/flokiorg/flnd/input"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/go-flokicoin/blockchain"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/flokiorg/go-flokicoin/txscript"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+// Result is the transaction constructed by a completed session.
+type Result struct {
+	// Tx is the unsigned transaction. Its inputs and outputs are ordered
+	// by their serial ID, so both parties arrive at the same transaction.
+	Tx *wire.MsgTx
+
+	// Inputs and Outputs are the inputs and outputs of the transaction,
+	// in the same order as within Tx.
+	Inputs  []Input
+	Outputs []Output
+
+	// PrevOuts fetches the outputs spent by the transaction.
+	PrevOuts *txscript.MultiPrevOutFetcher
+
+	// initiator is the party that initiated the construction.
+	initiator lntypes.ChannelParty
+}
+
+// newResult assembles the transaction from the passed inputs and outputs.
+func newResult(cfg Config, inputs []*Input, outputs []*Output) *Result {
+	r := &Result{
+		Tx:        wire.NewMsgTx(2),
+		PrevOuts:  txscript.NewMultiPrevOutFetcher(nil),
+		initiator: lntypes.Remote,
+	}
+	if cfg.Initiator {
+		r.initiator = lntypes.Local
+	}
+
+	for _, in := range inputs {
+		r.Inputs = append(r.Inputs, *in)
+	}
+	sort.Slice(r.Inputs, func(i, j int) bool {
+		return r.Inputs[i].SerialID < r.Inputs[j].SerialID
+	})
+
+	for _, out := range outputs {
+		r.Outputs = append(r.Outputs, *out)
+	}
+	sort.Slice(r.Outputs, func(i, j int) bool {
+		return r.Outputs[i].SerialID < r.Outputs[j].SerialID
+	})
+
+	r.Tx.LockTime = cfg.Locktime
+	for i := range r.Inputs {
+		in := &r.Inputs[i]
+
+		txIn := wire.NewTxIn(&in.OutPoint, nil, nil)
+		txIn.Sequence = in.Sequence
+		r.Tx.AddTxIn(txIn)
+
+		prevOut := in.PrevOut
+		r.PrevOuts.AddPrevOut(in.OutPoint, &prevOut)
+	}
+	for i := range r.Outputs {
+		txOut := r.Outputs[i].TxOut
+		r.Tx.AddTxOut(&txOut)
+	}
+
+	return r
+}
+
+// OutputIndex returns the index of the output paying to the passed script.
+func (r *Result) OutputIndex(pkScript []byte) (uint32, error) {
+	for i, txOut := range r.Tx.TxOut {
+		if bytes.Equal(txOut.PkScript, pkScript) {
+			return uint32(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: no output paying to %x", ErrInvalidTx,
+		pkScript)
+}
+
+// Contributed returns the total value of the inputs and outputs the passed
+// party added, not counting the shared input and the shared output.
+func (r *Result) Contributed(party lntypes.ChannelParty,
+	sharedScript []byte) (chainutil.Amount, chainutil.Amount) {
+
+	var in, out chainutil.Amount
+	for _, i := range r.Inputs {
+		if i.Party == party && !i.Shared() {
+			in += chainutil.Amount(i.PrevOut.Value)
+		}
+	}
+	for _, o := range r.Outputs {
+		if o.Party == party &&
+			!bytes.Equal(o.TxOut.PkScript, sharedScript) {
+
+			out += chainutil.Amount(o.TxOut.Value)
+		}
+	}
+
+	return in, out
+}
+
+// Weight returns the weight of the transaction the passed party pays the fee
+// for. This is the weight of the inputs and outputs it added. The initiator
+// also pays for the common fields of the transaction and for the shared input
+// and output, whoever added them.
+func (r *Result) Weight(party lntypes.ChannelParty,
+	sharedScript []byte) lntypes.WeightUnit {
+
+	isInitiator := party == r.initiator
+
+	var weight lntypes.WeightUnit
+	if isInitiator {
+		inputCount := wire.VarIntSerializeSize(uint64(len(r.Tx.TxIn)))
+		outputCount := wire.VarIntSerializeSize(
+			uint64(len(r.Tx.TxOut)),
+		)
+		stripped := input.BaseTxSize + inputCount + outputCount
+		weight += lntypes.WeightUnit(
+			stripped*blockchain.WitnessScaleFactor,
+		) + input.WitnessHeaderSize
+	}
+
+	for _, in := range r.Inputs {
+		shared := in.Shared()
+		if (shared && isInitiator) || (!shared && in.Party == party) {
+			weight += inputWeight(&in)
+		}
+	}
+	for _, out := range r.Outputs {
+		shared := bytes.Equal(out.TxOut.PkScript, sharedScript)
+		if (shared && isInitiator) || (!shared && out.Party == party) {
+			weight += outputWeight(&out.TxOut)
+		}
+	}
+
+	return weight
+}
+
+// SetWitnesses sets the passed witnesses of a party on the inputs it added,
+// not counting the shared input. The witnesses must be in the order of the
+// inputs within the transaction.
+func (r *Result) SetWitnesses(party lntypes.ChannelParty,
+	witnesses []wire.TxWitness) error {
+
+	var n int
+	for i, in := range r.Inputs {
+		if in.Party != party || in.Shared() {
+			continue
+		}
+		if n >= len(witnesses) {
+			return fmt.Errorf("missing witness for input %v",
+				in.OutPoint)
+		}
+
+		r.Tx.TxIn[i].Witness = witnesses[n]
+		n++
+	}
+
+	if n != len(witnesses) {
+		return fmt.Errorf("got %d witnesses for %d inputs",
+			len(witnesses), n)
+	}
+
+	return nil
+}
+
+// SendSignaturesFirst returns true if we must send our TxSignatures before
+// the remote party. The party whose inputs are worth less sends first, which
+// prevents it from holding back its signatures to force the other party to
+// broadcast alone. If both contributed the same amount, the node with the
+// lower public key sends first.
+func (r *Result) SendSignaturesFirst(localNode,
+	remoteNode *crypto.PublicKey) bool {
+
+	var local, remote int64
+	for _, in := range r.Inputs {
+		if in.Party == lntypes.Local {
+			local += in.PrevOut.Value
+		} else {
+			remote += in.PrevOut.Value
+		}
+	}
+
+	if local != remote {
+		return local < remote
+	}
+
+	return bytes.Compare(
+		localNode.SerializeCompressed(),
+		remoteNode.SerializeCompressed(),
+	) < 0
+}
+
+// VerifyTx checks that all inputs of the passed transaction are fully signed.
+func VerifyTx(tx *wire.MsgTx, prevOuts txscript.PrevOutputFetcher) error {
+	err := blockchain.CheckTransactionSanity(chainutil.NewTx(tx))
+	if err != nil {
+		return err
+	}
+
+	sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+	for i, txIn := range tx.TxIn {
+		prevOut := prevOuts.FetchPrevOutput(txIn.PreviousOutPoint)
+		if prevOut == nil {
+			return fmt.Errorf("unknown input %v",
+				txIn.PreviousOutPoint)
+		}
+
+		vm, err := txscript.NewEngine(
+			prevOut.PkScript, tx, i, txscript.StandardVerifyFlags,
+			nil, sigHashes, prevOut.Value, prevOuts,
+		)
+		if err != nil {
+			return err
+		}
+		if err := vm.Execute(); err != nil {
+			return fmt.Errorf("input %v: %w", txIn.PreviousOutPoint,
+				err)
+		}
+	}
+
+	return nil
+}
+
+// inputWeight estimates the weight of the passed input once it's signed. The
+// shared input is assumed to spend a 2-of-2 multisig funding output.
+func inputWeight(in *Input) lntypes.WeightUnit {
+	var witnessSize lntypes.WeightUnit
+	switch {
+	case in.Shared():
+		witnessSize = input.MultiSigWitnessSize
+
+	case txscript.IsPayToTaproot(in.PrevOut.PkScript):
+		witnessSize = input.TaprootKeyPathWitnessSize
+
+	default:
+		witnessSize = input.P2WKHWitnessSize
+	}
+
+	return input.InputSize*blockchain.WitnessScaleFactor + witnessSize
+}
+
+// outputWeight returns the weight of the passed output.
+func outputWeight(txOut *wire.TxOut) lntypes.WeightUnit {
+	return lntypes.WeightUnit(
+		txOut.SerializeSize() * blockchain.WitnessScaleFactor,
+	)
+}
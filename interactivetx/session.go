@@ -0,0 +1,760 @@
+// Package interactivetx implements the interactive transaction construction
+// protocol, in which two peers take turns adding and removing inputs and
+// outputs of a transaction they both contribute to using tx_add_input,
+// tx_add_output, tx_remove_input, tx_remove_output and tx_complete. It is used
+// by splicing and dual-funded channel establishment.
+package interactivetx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/txscript"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+const (
+	// MaxAdditions is the maximum number of inputs, and separately of
+	// outputs, a party may add to a transaction.
+	MaxAdditions = 4096
+
+	// DefaultSequence is the sequence number of the inputs we add. It
+	// signals replaceability, so the transaction can be fee bumped, and
+	// enables the locktime of the transaction.
+	DefaultSequence = wire.MaxTxInSequenceNum - 2
+)
+
+var (
+	// ErrOutOfTurn is returned when a message is received while it's our
+	// turn to send one.
+	ErrOutOfTurn = errors.New("interactive tx message received out of " +
+		"turn")
+
+	// ErrSessionComplete is returned when a message is received after
+	// the construction of the transaction completed.
+	ErrSessionComplete = errors.New("interactive tx construction " +
+		"already complete")
+
+	// ErrSessionIncomplete is returned when the result of a session is
+	// requested before the construction of the transaction completed.
+	ErrSessionIncomplete = errors.New("interactive tx construction " +
+		"incomplete")
+
+	// ErrInvalidSerialID is returned when a serial ID has the wrong
+	// parity or was used before.
+	ErrInvalidSerialID = errors.New("invalid serial ID")
+
+	// ErrInvalidInput is returned when an added input is malformed, spends
+	// an unsupported output or was added before.
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrInvalidOutput is returned when an added output is malformed or
+	// below the dust limit.
+	ErrInvalidOutput = errors.New("invalid output")
+
+	// ErrTooManyAdditions is returned when the remote party adds more
+	// than MaxAdditions inputs or outputs.
+	ErrTooManyAdditions = errors.New("too many inputs or outputs added")
+
+	// ErrInvalidTx is returned when the completed transaction isn't
+	// valid, e.g. because its outputs exceed its inputs.
+	ErrInvalidTx = errors.New("invalid interactive transaction")
+)
+
+// SharedInput is an output owned jointly by both parties that is spent by the
+// constructed transaction, such as the current funding output of a channel
+// being spliced.
+type SharedInput struct {
+	// OutPoint is the shared output.
+	OutPoint wire.OutPoint
+
+	// PrevOut is the shared output itself.
+	PrevOut wire.TxOut
+}
+
+// Input is an input of the constructed transaction.
+type Input struct {
+	// SerialID is the serial ID the input was added with. Inputs are
+	// ordered by their serial ID within the transaction.
+	SerialID uint64
+
+	// OutPoint is the output spent by the input.
+	OutPoint wire.OutPoint
+
+	// PrevTx is the transaction that created the spent output. It is nil
+	// for the shared input.
+	PrevTx *wire.MsgTx
+
+	// PrevOut is the output spent by the input.
+	PrevOut wire.TxOut
+
+	// Sequence is the sequence number of the input.
+	Sequence uint32
+
+	// Party is the party that added the input.
+	Party lntypes.ChannelParty
+}
+
+// Shared returns true if the input spends the shared output.
+func (i *Input) Shared() bool {
+	return i.PrevTx == nil
+}
+
+// Output is an output of the constructed transaction.
+type Output struct {
+	// SerialID is the serial ID the output was added with. Outputs are
+	// ordered by their serial ID within the transaction.
+	SerialID uint64
+
+	// TxOut is the output itself.
+	TxOut wire.TxOut
+
+	// Party is the party that added the output.
+	Party lntypes.ChannelParty
+}
+
+// Config holds the parameters of an interactive transaction construction
+// session.
+type Config struct {
+	// ChanID is the channel ID the messages of the session are sent with.
+	ChanID lnwire.ChannelID
+
+	// Initiator is true if we initiated the construction, in which case
+	// we send the first message and use even serial IDs.
+	Initiator bool
+
+	// Locktime is the locktime of the constructed transaction.
+	Locktime uint32
+
+	// SharedInput is the shared output spent by the transaction, if any.
+	// Exactly one input must spend it once the construction completes.
+	SharedInput fn.Option[SharedInput]
+
+	// SharedOutputScript is the script of the shared output created by
+	// the transaction, such as a new funding output. Exactly one output
+	// must pay to it once the construction completes.
+	SharedOutputScript []byte
+
+	// DustLimit is the minimum value of outputs added by the remote party.
+	DustLimit chainutil.Amount
+
+	// Inputs are the inputs we add. Only their OutPoint, PrevTx and
+	// Sequence need to be set. An input without PrevTx spends the shared
+	// input. A zero Sequence is replaced by DefaultSequence.
+	Inputs []Input
+
+	// Outputs are the outputs we add.
+	Outputs []*wire.TxOut
+}
+
+// Session tracks a single interactive transaction construction. It isn't safe
+// for concurrent use.
+type Session struct {
+	cfg Config
+
+	// inputs and outputs are the inputs and outputs added by both
+	// parties so far.
+	inputs  []*Input
+	outputs []*Output
+
+	// serialIDs holds all serial IDs used so far.
+	serialIDs fn.Set[uint64]
+
+	// queue holds the messages adding our inputs and outputs we still
+	// need to send.
+	queue []lnwire.Message
+
+	// remoteInputs and remoteOutputs count the additions of the remote
+	// party.
+	remoteInputs  int
+	remoteOutputs int
+
+	// ourTurn is true if we are to send the next message.
+	ourTurn bool
+
+	// sentComplete and recvComplete are true if the last message sent,
+	// respectively received, was a TxComplete.
+	sentComplete bool
+	recvComplete bool
+
+	// complete is true once both parties sent a TxComplete in
+	// succession.
+	complete bool
+}
+
+// NewSession creates a new interactive transaction construction session that
+// adds the passed local inputs and outputs.
+func NewSession(cfg Config) (*Session, error) {
+	s := &Session{
+		cfg:       cfg,
+		serialIDs: fn.NewSet[uint64](),
+		ourTurn:   cfg.Initiator,
+	}
+
+	// The initiator uses even serial IDs and the responder odd ones, so
+	// both parties can choose them independently.
+	var nextSerialID uint64
+	if !cfg.Initiator {
+		nextSerialID = 1
+	}
+	serialID := func() uint64 {
+		id := nextSerialID
+		nextSerialID += 2
+		s.serialIDs.Add(id)
+
+		return id
+	}
+
+	for _, in := range cfg.Inputs {
+		added := in
+		added.SerialID = serialID()
+		added.Party = lntypes.Local
+		if added.Sequence == 0 {
+			added.Sequence = DefaultSequence
+		}
+
+		msg := &lnwire.TxAddInput{
+			ChanID:    cfg.ChanID,
+			SerialID:  added.SerialID,
+			PrevTxOut: added.OutPoint.Index,
+			Sequence:  added.Sequence,
+		}
+
+		if added.Shared() {
+			shared, err := s.cfg.SharedInput.UnwrapOrErr(
+				fmt.Errorf("%w: no shared input to spend",
+					ErrInvalidInput),
+			)
+			if err != nil {
+				return nil, err
+			}
+			if shared.OutPoint != added.OutPoint {
+				return nil, fmt.Errorf("%w: %v isn't the "+
+					"shared input", ErrInvalidInput,
+					added.OutPoint)
+			}
+
+			added.PrevOut = shared.PrevOut
+			msg.SharedInputTxid = tlv.SomeRecordT(
+				tlv.NewPrimitiveRecord[tlv.TlvType0, [32]byte](
+					added.OutPoint.Hash,
+				),
+			)
+		} else {
+			prevOut, err := prevOutput(
+				added.PrevTx, added.OutPoint.Index,
+			)
+			if err != nil {
+				return nil, err
+			}
+			added.PrevOut = *prevOut
+
+			var prevTx bytes.Buffer
+			err = added.PrevTx.Serialize(&prevTx)
+			if err != nil {
+				return nil, err
+			}
+			msg.PrevTx = prevTx.Bytes()
+		}
+
+		if s.hasInput(added.OutPoint) {
+			return nil, fmt.Errorf("%w: duplicate input %v",
+				ErrInvalidInput, added.OutPoint)
+		}
+
+		s.inputs = append(s.inputs, &added)
+		s.queue = append(s.queue, msg)
+	}
+
+	for _, txOut := range cfg.Outputs {
+		added := &Output{
+			SerialID: serialID(),
+			TxOut:    *txOut,
+			Party:    lntypes.Local,
+		}
+
+		s.outputs = append(s.outputs, added)
+		s.queue = append(s.queue, &lnwire.TxAddOutput{
+			ChanID:   cfg.ChanID,
+			SerialID: added.SerialID,
+			Amount:   chainutil.Amount(txOut.Value),
+			PkScript: txOut.PkScript,
+		})
+	}
+
+	return s, nil
+}
+
+// Start returns the first message of the session. It must only be called by
+// the initiator.
+func (s *Session) Start() (lnwire.Message, error) {
+	if !s.cfg.Initiator {
+		return nil, fmt.Errorf("only the initiator starts the " +
+			"construction")
+	}
+	if !s.ourTurn || s.complete {
+		return nil, fmt.Errorf("session already started")
+	}
+
+	return s.nextMsg(), nil
+}
+
+// ReceiveMsg processes a TxAddInput, TxAddOutput, TxRemoveInput,
+// TxRemoveOutput or TxComplete of the remote party and returns our reply, if
+// any. No reply is returned once both parties
+// sent a TxComplete in succession, which completes the construction. An error
+// means the construction failed and should be aborted with a TxAbort.
+func (s *Session) ReceiveMsg(msg lnwire.Message) (fn.Option[lnwire.Message],
+	error) {
+
+	none := fn.None[lnwire.Message]()
+
+	switch {
+	case s.complete:
+		return none, ErrSessionComplete
+
+	case s.ourTurn:
+		return none, ErrOutOfTurn
+	}
+
+	switch msg := msg.(type) {
+	case *lnwire.TxAddInput:
+		if err := s.addRemoteInput(msg); err != nil {
+			return none, err
+		}
+		s.recvComplete = false
+
+	case *lnwire.TxAddOutput:
+		if err := s.addRemoteOutput(msg); err != nil {
+			return none, err
+		}
+		s.recvComplete = false
+
+	case *lnwire.TxRemoveInput:
+		if err := s.removeRemoteInput(msg.SerialID); err != nil {
+			return none, err
+		}
+		s.recvComplete = false
+
+	case *lnwire.TxRemoveOutput:
+		if err := s.removeRemoteOutput(msg.SerialID); err != nil {
+			return none, err
+		}
+		s.recvComplete = false
+
+	case *lnwire.TxComplete:
+		s.recvComplete = true
+
+		// If our last message was a TxComplete too, the
+		// construction is complete and there's nothing left to send.
+		if s.sentComplete {
+			return none, s.finish()
+		}
+
+	default:
+		return none, fmt.Errorf("unexpected interactive tx message %T",
+			msg)
+	}
+
+	s.ourTurn = true
+	reply := s.nextMsg()
+
+	if s.sentComplete && s.recvComplete {
+		if err := s.finish(); err != nil {
+			return none, err
+		}
+	}
+
+	return fn.Some(reply), nil
+}
+
+// RemoveInput removes our input spending the passed outpoint from the
+// transaction. If the input wasn't sent to the remote party yet, it's just
+// dropped, otherwise a TxRemoveInput is sent on our next turn. Our inputs can
+// only be removed until we sent a TxComplete.
+func (s *Session) RemoveInput(op wire.OutPoint) error {
+	if err := s.canRemove(); err != nil {
+		return err
+	}
+
+	for i, in := range s.inputs {
+		if in.Party != lntypes.Local || in.OutPoint != op {
+			continue
+		}
+
+		s.inputs = append(s.inputs[:i], s.inputs[i+1:]...)
+		s.dequeueOrRemove(in.SerialID, &lnwire.TxRemoveInput{
+			ChanID:   s.cfg.ChanID,
+			SerialID: in.SerialID,
+		})
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: no input of ours spends %v", ErrInvalidInput,
+		op)
+}
+
+// RemoveOutput removes our output paying to the passed script from the
+// transaction. If the output wasn't sent to the remote party yet, it's just
+// dropped, otherwise a TxRemoveOutput is sent on our next turn. Our outputs can
+// only be removed until we sent a TxComplete.
+func (s *Session) RemoveOutput(pkScript []byte) error {
+	if err := s.canRemove(); err != nil {
+		return err
+	}
+
+	for i, out := range s.outputs {
+		if out.Party != lntypes.Local ||
+			!bytes.Equal(out.TxOut.PkScript, pkScript) {
+
+			continue
+		}
+
+		s.outputs = append(s.outputs[:i], s.outputs[i+1:]...)
+		s.dequeueOrRemove(out.SerialID, &lnwire.TxRemoveOutput{
+			ChanID:   s.cfg.ChanID,
+			SerialID: out.SerialID,
+		})
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: no output of ours pays to %x",
+		ErrInvalidOutput, pkScript)
+}
+
+// canRemove returns an error if we can no longer remove our inputs and
+// outputs. Once we sent a TxComplete, the remote party may complete the
+// construction with its reply, so our removal would never be sent.
+func (s *Session) canRemove() error {
+	switch {
+	case s.complete:
+		return ErrSessionComplete
+
+	case s.sentComplete:
+		return fmt.Errorf("interactive tx construction already " +
+			"completed by us")
+	}
+
+	return nil
+}
+
+// dequeueOrRemove drops the queued message adding the input or output with
+// the passed serial ID. If it was already sent, the passed removal message is
+// queued instead.
+func (s *Session) dequeueOrRemove(serialID uint64, remove lnwire.Message) {
+	for i, msg := range s.queue {
+		var queuedID uint64
+		switch msg := msg.(type) {
+		case *lnwire.TxAddInput:
+			queuedID = msg.SerialID
+
+		case *lnwire.TxAddOutput:
+			queuedID = msg.SerialID
+
+		default:
+			continue
+		}
+
+		if queuedID == serialID {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+
+			return
+		}
+	}
+
+	s.queue = append(s.queue, remove)
+}
+
+// Complete returns true once both parties sent a TxComplete in succession.
+func (s *Session) Complete() bool {
+	return s.complete
+}
+
+// Result returns the constructed transaction once the construction completed.
+func (s *Session) Result() (*Result, error) {
+	if !s.complete {
+		return nil, ErrSessionIncomplete
+	}
+
+	return newResult(s.cfg, s.inputs, s.outputs), nil
+}
+
+// nextMsg returns the next message we send, which is a TxComplete once all
+// our inputs and outputs were added.
+func (s *Session) nextMsg() lnwire.Message {
+	s.ourTurn = false
+
+	if len(s.queue) == 0 {
+		s.sentComplete = true
+
+		return &lnwire.TxComplete{ChanID: s.cfg.ChanID}
+	}
+
+	msg := s.queue[0]
+	s.queue = s.queue[1:]
+	s.sentComplete = false
+
+	return msg
+}
+
+// validateSerialID checks that the passed serial ID of the remote party has
+// the right parity and wasn't used before.
+func (s *Session) validateSerialID(serialID uint64) error {
+	// The remote party uses even serial IDs if it's the initiator.
+	remoteParity := uint64(0)
+	if s.cfg.Initiator {
+		remoteParity = 1
+	}
+
+	switch {
+	case serialID%2 != remoteParity:
+		return fmt.Errorf("%w: serial ID %d has the wrong parity",
+			ErrInvalidSerialID, serialID)
+
+	case s.serialIDs.Contains(serialID):
+		return fmt.Errorf("%w: duplicate serial ID %d",
+			ErrInvalidSerialID, serialID)
+	}
+
+	return nil
+}
+
+// hasInput returns true if an input spending the passed outpoint was added.
+func (s *Session) hasInput(op wire.OutPoint) bool {
+	for _, in := range s.inputs {
+		if in.OutPoint == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addRemoteInput validates and adds an input of the remote party.
+func (s *Session) addRemoteInput(msg *lnwire.TxAddInput) error {
+	if err := s.validateSerialID(msg.SerialID); err != nil {
+		return err
+	}
+	if s.remoteInputs >= MaxAdditions {
+		return ErrTooManyAdditions
+	}
+
+	// Inputs that don't signal replaceability would prevent fee bumping
+	// the transaction.
+	if msg.Sequence >= wire.MaxTxInSequenceNum-1 {
+		return fmt.Errorf("%w: sequence %x isn't replaceable",
+			ErrInvalidInput, msg.Sequence)
+	}
+
+	in := &Input{
+		SerialID: msg.SerialID,
+		Sequence: msg.Sequence,
+		Party:    lntypes.Remote,
+	}
+
+	if msg.SharedInputTxid.IsSome() {
+		in.OutPoint = wire.OutPoint{
+			Hash:  msg.SharedInputTxid.ValOpt().UnsafeFromSome(),
+			Index: msg.PrevTxOut,
+		}
+
+		shared, err := s.cfg.SharedInput.UnwrapOrErr(
+			fmt.Errorf("%w: no shared input to spend",
+				ErrInvalidInput),
+		)
+		if err != nil {
+			return err
+		}
+		if shared.OutPoint != in.OutPoint {
+			return fmt.Errorf("%w: %v isn't the shared input",
+				ErrInvalidInput, in.OutPoint)
+		}
+		in.PrevOut = shared.PrevOut
+	} else {
+		prevTx := &wire.MsgTx{}
+		err := prevTx.Deserialize(bytes.NewReader(msg.PrevTx))
+		if err != nil {
+			return fmt.Errorf("%w: invalid previous transaction: "+
+				"%v", ErrInvalidInput, err)
+		}
+
+		prevOut, err := prevOutput(prevTx, msg.PrevTxOut)
+		if err != nil {
+			return err
+		}
+
+		in.PrevTx = prevTx
+		in.PrevOut = *prevOut
+		in.OutPoint = wire.OutPoint{
+			Hash:  prevTx.TxHash(),
+			Index: msg.PrevTxOut,
+		}
+
+		// Spending the shared output must be signaled, as its
+		// previous transaction isn't needed.
+		s.cfg.SharedInput.WhenSome(func(shared SharedInput) {
+			if shared.OutPoint == in.OutPoint {
+				err = fmt.Errorf("%w: shared input added as "+
+					"regular input", ErrInvalidInput)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.hasInput(in.OutPoint) {
+		return fmt.Errorf("%w: duplicate input %v", ErrInvalidInput,
+			in.OutPoint)
+	}
+
+	s.serialIDs.Add(in.SerialID)
+	s.inputs = append(s.inputs, in)
+	s.remoteInputs++
+
+	return nil
+}
+
+// addRemoteOutput validates and adds an output of the remote party.
+func (s *Session) addRemoteOutput(msg *lnwire.TxAddOutput) error {
+	if err := s.validateSerialID(msg.SerialID); err != nil {
+		return err
+	}
+	if s.remoteOutputs >= MaxAdditions {
+		return ErrTooManyAdditions
+	}
+
+	switch {
+	case len(msg.PkScript) == 0:
+		return fmt.Errorf("%w: empty output script", ErrInvalidOutput)
+
+	case msg.Amount < s.cfg.DustLimit:
+		return fmt.Errorf("%w: amount %v below dust limit %v",
+			ErrInvalidOutput, msg.Amount, s.cfg.DustLimit)
+	}
+
+	s.serialIDs.Add(msg.SerialID)
+	s.outputs = append(s.outputs, &Output{
+		SerialID: msg.SerialID,
+		TxOut: wire.TxOut{
+			Value:    int64(msg.Amount),
+			PkScript: msg.PkScript,
+		},
+		Party: lntypes.Remote,
+	})
+	s.remoteOutputs++
+
+	return nil
+}
+
+// removeRemoteInput removes the input with the passed serial ID, which must
+// have been added by the remote party. The serial ID can't be reused.
+func (s *Session) removeRemoteInput(serialID uint64) error {
+	for i, in := range s.inputs {
+		if in.SerialID != serialID {
+			continue
+		}
+		if in.Party != lntypes.Remote {
+			return fmt.Errorf("%w: input %d wasn't added by the "+
+				"remote party", ErrInvalidSerialID, serialID)
+		}
+
+		s.inputs = append(s.inputs[:i], s.inputs[i+1:]...)
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: no input with serial ID %d", ErrInvalidSerialID,
+		serialID)
+}
+
+// removeRemoteOutput removes the output with the passed serial ID, which must
+// have been added by the remote party. The serial ID can't be reused.
+func (s *Session) removeRemoteOutput(serialID uint64) error {
+	for i, out := range s.outputs {
+		if out.SerialID != serialID {
+			continue
+		}
+		if out.Party != lntypes.Remote {
+			return fmt.Errorf("%w: output %d wasn't added by the "+
+				"remote party", ErrInvalidSerialID, serialID)
+		}
+
+		s.outputs = append(s.outputs[:i], s.outputs[i+1:]...)
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: no output with serial ID %d",
+		ErrInvalidSerialID, serialID)
+}
+
+// finish validates the completed transaction and marks the session as
+// complete.
+func (s *Session) finish() error {
+	var sharedInputs, sharedOutputs int
+	var totalIn, totalOut int64
+	for _, in := range s.inputs {
+		if in.Shared() {
+			sharedInputs++
+		}
+		totalIn += in.PrevOut.Value
+	}
+	for _, out := range s.outputs {
+		if len(s.cfg.SharedOutputScript) != 0 &&
+			bytes.Equal(out.TxOut.PkScript, s.cfg.SharedOutputScript) {
+
+			sharedOutputs++
+		}
+		totalOut += out.TxOut.Value
+	}
+
+	switch {
+	case s.cfg.SharedInput.IsSome() && sharedInputs != 1:
+		return fmt.Errorf("%w: shared input spent %d times",
+			ErrInvalidTx, sharedInputs)
+
+	case len(s.cfg.SharedOutputScript) != 0 && sharedOutputs != 1:
+		return fmt.Errorf("%w: %d shared outputs", ErrInvalidTx,
+			sharedOutputs)
+
+	case totalOut > totalIn:
+		return fmt.Errorf("%w: outputs %v exceed inputs %v",
+			ErrInvalidTx, chainutil.Amount(totalOut),
+			chainutil.Amount(totalIn))
+	}
+
+	s.complete = true
+
+	return nil
+}
+
+// prevOutput returns the output of the passed transaction spent by an input,
+// making sure it's a segwit output, so the constructed transaction can't be
+// malleated.
+func prevOutput(prevTx *wire.MsgTx, index uint32) (*wire.TxOut, error) {
+	if prevTx == nil {
+		return nil, fmt.Errorf("%w: missing previous transaction",
+			ErrInvalidInput)
+	}
+	if int(index) >= len(prevTx.TxOut) {
+		return nil, fmt.Errorf("%w: previous transaction has no "+
+			"output %d", ErrInvalidInput, index)
+	}
+
+	prevOut := prevTx.TxOut[index]
+	if !txscript.IsWitnessProgram(prevOut.PkScript) {
+		return nil, fmt.Errorf("%w: %v:%d isn't a segwit output",
+			ErrInvalidInput, prevTx.TxHash(), index)
+	}
+
+	return prevOut, nil
+}
@@ -0,0 +1,478 @@
+package interactivetx
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// testChanID is the channel ID used by the test sessions.
+	testChanID = lnwire.ChannelID{1}
+
+	// p2wkhScript and p2trScript are scripts of segwit outputs spent and
+	// created by the test transactions.
+	p2wkhScript = append([]byte{0x00, 0x14}, make([]byte, 20)...)
+	p2trScript  = append([]byte{0x51, 0x20}, make([]byte, 32)...)
+
+	// fundingScript is the script of the shared output.
+	fundingScript = append([]byte{0x00, 0x20}, make([]byte, 32)...)
+)
+
+// testInput returns an input spending a new segwit output of the passed value.
+func testInput(value int64, pkScript []byte) Input {
+	prevTx := wire.NewMsgTx(2)
+	prevTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: uint32(value)},
+	})
+	prevTx.AddTxOut(&wire.TxOut{Value: value, PkScript: pkScript})
+
+	return Input{
+		OutPoint: wire.OutPoint{Hash: prevTx.TxHash()},
+		PrevTx:   prevTx,
+	}
+}
+
+// runSessions passes the messages of both sessions to each other until the
+// construction completes.
+func runSessions(t *testing.T, initiator, responder *Session) {
+	t.Helper()
+
+	msg, err := initiator.Start()
+	require.NoError(t, err)
+
+	sender, receiver := initiator, responder
+	for {
+		reply, err := receiver.ReceiveMsg(msg)
+		require.NoError(t, err)
+
+		if reply.IsNone() {
+			break
+		}
+
+		msg = reply.UnsafeFromSome()
+		sender, receiver = receiver, sender
+	}
+
+	require.True(t, sender.Complete())
+	require.True(t, receiver.Complete())
+}
+
+// TestSessionConstruction asserts that both parties of a session construct
+// the same transaction from the inputs and outputs they added.
+func TestSessionConstruction(t *testing.T) {
+	t.Parallel()
+
+	initiator, err := NewSession(Config{
+		ChanID:             testChanID,
+		Initiator:          true,
+		Locktime:           100,
+		SharedOutputScript: fundingScript,
+		Inputs:             []Input{testInput(50_000, p2wkhScript)},
+		Outputs: []*wire.TxOut{
+			{Value: 70_000, PkScript: fundingScript},
+			{Value: 9_000, PkScript: p2trScript},
+		},
+	})
+	require.NoError(t, err)
+
+	responder, err := NewSession(Config{
+		ChanID:             testChanID,
+		Locktime:           100,
+		SharedOutputScript: fundingScript,
+		DustLimit:          354,
+		Inputs: []Input{
+			testInput(20_000, p2trScript),
+			testInput(10_000, p2wkhScript),
+		},
+	})
+	require.NoError(t, err)
+
+	runSessions(t, initiator, responder)
+
+	local, err := initiator.Result()
+	require.NoError(t, err)
+	remote, err := responder.Result()
+	require.NoError(t, err)
+
+	require.Equal(t, local.Tx.TxHash(), remote.Tx.TxHash())
+	require.Len(t, local.Tx.TxIn, 3)
+	require.Len(t, local.Tx.TxOut, 2)
+	require.EqualValues(t, 100, local.Tx.LockTime)
+
+	// Inputs and outputs are ordered by serial ID, so the initiator's
+	// input comes first.
+	require.Equal(t, lntypes.Local, local.Inputs[0].Party)
+	require.Equal(t, lntypes.Remote, remote.Inputs[0].Party)
+	for _, txIn := range local.Tx.TxIn {
+		require.EqualValues(t, DefaultSequence, txIn.Sequence)
+	}
+
+	idx, err := local.OutputIndex(fundingScript)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, idx)
+
+	in, out := local.Contributed(lntypes.Remote, fundingScript)
+	require.EqualValues(t, 30_000, in)
+	require.Zero(t, out)
+
+	in, out = local.Contributed(lntypes.Local, fundingScript)
+	require.EqualValues(t, 50_000, in)
+	require.EqualValues(t, 9_000, out)
+
+	// The initiator pays for the common fields and the shared output, so
+	// its weight and the responder's must add up to the weight of the
+	// whole transaction.
+	total := local.Weight(lntypes.Local, fundingScript) +
+		local.Weight(lntypes.Remote, fundingScript)
+	require.Equal(t, total, remote.Weight(lntypes.Local, fundingScript)+
+		remote.Weight(lntypes.Remote, fundingScript))
+	require.Greater(t, total, lntypes.WeightUnit(local.Tx.SerializeSize()))
+
+	// The responder's witnesses are applied to its inputs only.
+	witnesses := []wire.TxWitness{{{1}}, {{2}}}
+	require.NoError(t, local.SetWitnesses(lntypes.Remote, witnesses))
+	require.Empty(t, local.Tx.TxIn[0].Witness)
+	require.Equal(t, witnesses[0], local.Tx.TxIn[1].Witness)
+	require.Equal(t, witnesses[1], local.Tx.TxIn[2].Witness)
+	require.Error(t, local.SetWitnesses(lntypes.Local, witnesses))
+
+	// The responder contributed less, so it sends its signatures first.
+	key1, _ := crypto.NewPrivateKey()
+	key2, _ := crypto.NewPrivateKey()
+	require.False(t, local.SendSignaturesFirst(
+		key1.PubKey(), key2.PubKey(),
+	))
+	require.True(t, remote.SendSignaturesFirst(
+		key2.PubKey(), key1.PubKey(),
+	))
+}
+
+// TestSessionSharedInput asserts that the shared input is added without its
+// previous transaction and must be spent by the completed transaction.
+func TestSessionSharedInput(t *testing.T) {
+	t.Parallel()
+
+	shared := SharedInput{
+		OutPoint: wire.OutPoint{Index: 1},
+		PrevOut:  wire.TxOut{Value: 100_000, PkScript: fundingScript},
+	}
+
+	initiator, err := NewSession(Config{
+		ChanID:             testChanID,
+		Initiator:          true,
+		SharedInput:        fn.Some(shared),
+		SharedOutputScript: fundingScript,
+		Inputs:             []Input{{OutPoint: shared.OutPoint}},
+		Outputs: []*wire.TxOut{
+			{Value: 99_000, PkScript: fundingScript},
+		},
+	})
+	require.NoError(t, err)
+
+	responder, err := NewSession(Config{
+		ChanID:             testChanID,
+		SharedInput:        fn.Some(shared),
+		SharedOutputScript: fundingScript,
+	})
+	require.NoError(t, err)
+
+	runSessions(t, initiator, responder)
+
+	result, err := responder.Result()
+	require.NoError(t, err)
+	require.True(t, result.Inputs[0].Shared())
+	require.Equal(t, shared.PrevOut, *result.PrevOuts.FetchPrevOutput(
+		shared.OutPoint,
+	))
+
+	// A responder that expects a shared input fails the construction if
+	// it isn't spent.
+	initiator, err = NewSession(Config{
+		ChanID:    testChanID,
+		Initiator: true,
+		Inputs:    []Input{testInput(50_000, p2wkhScript)},
+	})
+	require.NoError(t, err)
+
+	responder, err = NewSession(Config{
+		ChanID:      testChanID,
+		SharedInput: fn.Some(shared),
+	})
+	require.NoError(t, err)
+
+	msg, err := initiator.Start()
+	require.NoError(t, err)
+	reply, err := responder.ReceiveMsg(msg)
+	require.NoError(t, err)
+	reply, err = initiator.ReceiveMsg(reply.UnsafeFromSome())
+	require.NoError(t, err)
+	_, err = responder.ReceiveMsg(reply.UnsafeFromSome())
+	require.ErrorIs(t, err, ErrInvalidTx)
+}
+
+// TestSessionValidation asserts that invalid messages of the remote party
+// fail the construction.
+func TestSessionValidation(t *testing.T) {
+	t.Parallel()
+
+	validInput := testInput(10_000, p2wkhScript)
+	addInput := func(serialID uint64, in Input) *lnwire.TxAddInput {
+		var prevTx bytes.Buffer
+		require.NoError(t, in.PrevTx.Serialize(&prevTx))
+
+		return &lnwire.TxAddInput{
+			ChanID:    testChanID,
+			SerialID:  serialID,
+			PrevTx:    prevTx.Bytes(),
+			PrevTxOut: in.OutPoint.Index,
+			Sequence:  DefaultSequence,
+		}
+	}
+
+	testCases := []struct {
+		name string
+		msgs []lnwire.Message
+		err  error
+	}{
+		{
+			name: "wrong parity",
+			msgs: []lnwire.Message{addInput(1, validInput)},
+			err:  ErrInvalidSerialID,
+		},
+		{
+			name: "duplicate serial id",
+			msgs: []lnwire.Message{
+				addInput(0, validInput),
+				&lnwire.TxAddOutput{
+					ChanID:   testChanID,
+					SerialID: 0,
+					Amount:   1000,
+					PkScript: p2wkhScript,
+				},
+			},
+			err: ErrInvalidSerialID,
+		},
+		{
+			name: "duplicate input",
+			msgs: []lnwire.Message{
+				addInput(0, validInput),
+				addInput(2, validInput),
+			},
+			err: ErrInvalidInput,
+		},
+		{
+			name: "non-segwit input",
+			msgs: []lnwire.Message{
+				addInput(0, testInput(10_000, []byte{0x51})),
+			},
+			err: ErrInvalidInput,
+		},
+		{
+			name: "final sequence",
+			msgs: []lnwire.Message{
+				&lnwire.TxAddInput{
+					ChanID:   testChanID,
+					PrevTx:   addInput(0, validInput).PrevTx,
+					Sequence: wire.MaxTxInSequenceNum,
+				},
+			},
+			err: ErrInvalidInput,
+		},
+		{
+			name: "dust output",
+			msgs: []lnwire.Message{
+				&lnwire.TxAddOutput{
+					ChanID:   testChanID,
+					Amount:   100,
+					PkScript: p2wkhScript,
+				},
+			},
+			err: ErrInvalidOutput,
+		},
+		{
+			name: "outputs exceed inputs",
+			msgs: []lnwire.Message{
+				addInput(0, validInput),
+				&lnwire.TxAddOutput{
+					ChanID:   testChanID,
+					SerialID: 2,
+					Amount:   20_000,
+					PkScript: p2wkhScript,
+				},
+				&lnwire.TxComplete{ChanID: testChanID},
+			},
+			err: ErrInvalidTx,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := NewSession(Config{
+				ChanID:    testChanID,
+				DustLimit: 354,
+			})
+			require.NoError(t, err)
+
+			for i, msg := range tc.msgs {
+				_, err = s.ReceiveMsg(msg)
+				if i < len(tc.msgs)-1 {
+					require.NoError(t, err)
+				}
+			}
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+// TestSessionTurns asserts that messages received out of turn or after the
+// construction completed are rejected.
+func TestSessionTurns(t *testing.T) {
+	t.Parallel()
+
+	s, err := NewSession(Config{ChanID: testChanID, Initiator: true})
+	require.NoError(t, err)
+
+	_, err = s.ReceiveMsg(&lnwire.TxComplete{ChanID: testChanID})
+	require.ErrorIs(t, err, ErrOutOfTurn)
+
+	msg, err := s.Start()
+	require.NoError(t, err)
+	require.IsType(t, &lnwire.TxComplete{}, msg)
+
+	_, err = s.Result()
+	require.ErrorIs(t, err, ErrSessionIncomplete)
+
+	// Responding to our TxComplete with a TxComplete completes the
+	// construction.
+	reply, err := s.ReceiveMsg(&lnwire.TxComplete{ChanID: testChanID})
+	require.NoError(t, err)
+	require.True(t, reply.IsNone())
+	require.True(t, s.Complete())
+
+	_, err = s.ReceiveMsg(&lnwire.TxComplete{ChanID: testChanID})
+	require.ErrorIs(t, err, ErrSessionComplete)
+
+	result, err := s.Result()
+	require.NoError(t, err)
+	require.Empty(t, result.Tx.TxIn)
+}
+
+// TestSessionRemoval asserts that inputs and outputs can be removed from the
+// transaction by the party that added them.
+func TestSessionRemoval(t *testing.T) {
+	t.Parallel()
+
+	removedInput := testInput(30_000, p2wkhScript)
+	initiator, err := NewSession(Config{
+		ChanID:             testChanID,
+		Initiator:          true,
+		SharedOutputScript: fundingScript,
+		Inputs: []Input{
+			removedInput, testInput(50_000, p2trScript),
+		},
+		Outputs: []*wire.TxOut{
+			{Value: 60_000, PkScript: fundingScript},
+			{Value: 9_000, PkScript: p2trScript},
+		},
+	})
+	require.NoError(t, err)
+
+	responder, err := NewSession(Config{
+		ChanID:             testChanID,
+		SharedOutputScript: fundingScript,
+		Inputs:             []Input{testInput(20_000, p2wkhScript)},
+	})
+	require.NoError(t, err)
+
+	// An output that wasn't sent yet is dropped without a message.
+	require.NoError(t, initiator.RemoveOutput(p2trScript))
+	require.ErrorIs(
+		t, initiator.RemoveOutput(p2trScript), ErrInvalidOutput,
+	)
+
+	msg, err := initiator.Start()
+	require.NoError(t, err)
+	require.IsType(t, &lnwire.TxAddInput{}, msg)
+
+	// The input was sent already, so its removal is sent later on.
+	require.NoError(t, initiator.RemoveInput(removedInput.OutPoint))
+
+	sender, receiver := initiator, responder
+	var removals int
+	for {
+		switch msg.(type) {
+		case *lnwire.TxRemoveInput, *lnwire.TxRemoveOutput:
+			removals++
+		}
+
+		reply, err := receiver.ReceiveMsg(msg)
+		require.NoError(t, err)
+
+		if reply.IsNone() {
+			break
+		}
+
+		msg = reply.UnsafeFromSome()
+		sender, receiver = receiver, sender
+	}
+	require.True(t, sender.Complete())
+	require.True(t, receiver.Complete())
+	require.Equal(t, 1, removals)
+
+	local, err := initiator.Result()
+	require.NoError(t, err)
+	remote, err := responder.Result()
+	require.NoError(t, err)
+
+	require.Equal(t, local.Tx.TxHash(), remote.Tx.TxHash())
+	require.Len(t, local.Tx.TxIn, 2)
+	require.Len(t, local.Tx.TxOut, 1)
+	for _, txIn := range local.Tx.TxIn {
+		require.NotEqual(
+			t, removedInput.OutPoint, txIn.PreviousOutPoint,
+		)
+	}
+
+	// Once we sent a TxComplete, we can't remove anything anymore.
+	s, err := NewSession(Config{
+		ChanID:    testChanID,
+		Initiator: true,
+		Inputs:    []Input{testInput(10_000, p2wkhScript)},
+	})
+	require.NoError(t, err)
+
+	_, err = s.Start()
+	require.NoError(t, err)
+	_, err = s.ReceiveMsg(&lnwire.TxComplete{ChanID: testChanID})
+	require.NoError(t, err)
+	require.Error(t, s.RemoveInput(s.cfg.Inputs[0].OutPoint))
+
+	// The remote party can only remove what it added.
+	s, err = NewSession(Config{
+		ChanID: testChanID,
+		Inputs: []Input{testInput(10_000, p2wkhScript)},
+	})
+	require.NoError(t, err)
+
+	_, err = s.ReceiveMsg(&lnwire.TxRemoveInput{
+		ChanID: testChanID, SerialID: 1,
+	})
+	require.ErrorIs(t, err, ErrInvalidSerialID)
+
+	s, err = NewSession(Config{ChanID: testChanID})
+	require.NoError(t, err)
+
+	_, err = s.ReceiveMsg(&lnwire.TxRemoveOutput{
+		ChanID: testChanID, SerialID: 2,
+	})
+	require.ErrorIs(t, err, ErrInvalidSerialID)
+}
@@ -263,6 +263,12 @@ type SingleKeyMessageSigner interface {
 	// hashing it first, with the wrapped private key and returns the
 	// signature in the compact, public key recoverable format.
 	SignMessageCompact(message []byte, doubleHash bool) ([]byte, error)
+
+	// SignMessageSchnorr signs the given message, single or double SHA256
+	// hashing it first, or taking the tagged hash if a tag is given, with
+	// the wrapped private key, optionally tweaked by the taproot tweak.
+	SignMessageSchnorr(message []byte, doubleHash bool, taprootTweak []byte,
+		tag []byte) (*schnorr.Signature, error)
 }
 
 // ECDHRing is an interface that abstracts away basic low-level ECDH shared key
@@ -5,6 +5,8 @@ import (
 
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/crypto/ecdsa"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr"
+	"github.com/flokiorg/go-flokicoin/txscript"
 )
 
 func NewPubKeyMessageSigner(pubKey *crypto.PublicKey, keyLoc KeyLocator,
@@ -43,6 +45,14 @@ func (p *PubKeyMessageSigner) SignMessageCompact(msg []byte,
 	return p.digestSigner.SignMessageCompact(p.keyLoc, msg, doubleHash)
 }
 
+func (p *PubKeyMessageSigner) SignMessageSchnorr(msg []byte, doubleHash bool,
+	taprootTweak []byte, tag []byte) (*schnorr.Signature, error) {
+
+	return p.digestSigner.SignMessageSchnorr(
+		p.keyLoc, msg, doubleHash, taprootTweak, tag,
+	)
+}
+
 func NewPrivKeyMessageSigner(privKey *crypto.PrivateKey,
 	keyLoc KeyLocator) *PrivKeyMessageSigner {
 
@@ -90,5 +100,27 @@ func (p *PrivKeyMessageSigner) SignMessageCompact(msg []byte,
 	return ecdsa.SignCompact(p.privKey, digest, true), nil
 }
 
+func (p *PrivKeyMessageSigner) SignMessageSchnorr(msg []byte, doubleHash bool,
+	taprootTweak []byte, tag []byte) (*schnorr.Signature, error) {
+
+	privKey := p.privKey
+	if len(taprootTweak) > 0 {
+		privKey = txscript.TweakTaprootPrivKey(*privKey, taprootTweak)
+	}
+
+	var digest []byte
+	switch {
+	case len(tag) > 0:
+		taggedHash := chainhash.TaggedHash(tag, msg)
+		digest = taggedHash[:]
+	case doubleHash:
+		digest = chainhash.DoubleHashB(msg)
+	default:
+		digest = chainhash.HashB(msg)
+	}
+
+	return schnorr.Sign(privKey, digest)
+}
+
 var _ SingleKeyMessageSigner = (*PubKeyMessageSigner)(nil)
 var _ SingleKeyMessageSigner = (*PrivKeyMessageSigner)(nil)
@@ -68,6 +68,12 @@ const (
 
 	// LabelTypeSweepTransaction is used to label sweeps.
 	LabelTypeSweepTransaction LabelType = "sweep"
+
+	// LabelTypeSplice is used to label splice transactions.
+	LabelTypeSplice LabelType = "splice"
+
+	// LabelTypeBatchSend is used to label batched on-chain sends.
+	LabelTypeBatchSend LabelType = "batchsend"
 )
 
 // LabelField is used to tag a value within a label.
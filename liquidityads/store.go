@@ -0,0 +1,245 @@
+package liquidityads
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+var (
+	// leaseBucket is the top-level bucket that stores the leases of
+	// channels we bought or sold inbound liquidity in. The keys are the
+	// funding outpoints of the channels and the values the serialized
+	// leases.
+	leaseBucket = []byte("liquidity-lease-bucket")
+
+	// ErrLeaseExists is returned when a lease for a channel is added
+	// twice.
+	ErrLeaseExists = errors.New("lease already exists")
+)
+
+const (
+	// LeaseDuration is the number of blocks the seller of inbound
+	// liquidity keeps its funds in the leased channel.
+	LeaseDuration = 4032
+
+	leaseRoleType   tlv.Type = 0
+	leasePeerType   tlv.Type = 1
+	leaseAmountType tlv.Type = 2
+	leaseFeeType    tlv.Type = 3
+	leaseExpiryType tlv.Type = 4
+)
+
+// Role is the role we took in a lease.
+type Role uint8
+
+const (
+	// RoleBuyer is the role of the party that paid for the inbound
+	// liquidity.
+	RoleBuyer Role = 0
+
+	// RoleSeller is the role of the party that contributed the leased
+	// funds to the channel and earned the lease fee.
+	RoleSeller Role = 1
+)
+
+// String returns a human-readable version of the role.
+func (r Role) String() string {
+	switch r {
+	case RoleBuyer:
+		return "buyer"
+
+	case RoleSeller:
+		return "seller"
+
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(r))
+	}
+}
+
+// Lease is the purchase of inbound liquidity in a dual-funded channel.
+type Lease struct {
+	// ChanPoint is the funding outpoint the channel was opened with.
+	ChanPoint wire.OutPoint
+
+	// Peer is the identity key of the other party of the lease.
+	Peer [33]byte
+
+	// Role is the role we took in the lease.
+	Role Role
+
+	// Amount is the amount the seller contributed to the channel.
+	Amount chainutil.Amount
+
+	// Fee is the lease fee the buyer paid to the seller.
+	Fee chainutil.Amount
+
+	// Rates are the lease rates the fee was computed with.
+	Rates lnwire.LeaseRates
+
+	// Expiry is the height until which the seller keeps the leased funds
+	// in the channel.
+	Expiry uint32
+}
+
+// Store persists the leases of our channels and tracks the income we earned
+// from selling inbound liquidity.
+type Store struct {
+	db kvdb.Backend
+}
+
+// NewStore creates a new lease store backed by the passed database.
+func NewStore(db kvdb.Backend) (*Store, error) {
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(leaseBucket)
+		return err
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// AddLease persists a new lease.
+func (s *Store) AddLease(lease *Lease) error {
+	var key bytes.Buffer
+	if err := lnwire.WriteOutPoint(&key, lease.ChanPoint); err != nil {
+		return err
+	}
+
+	var value bytes.Buffer
+	if err := serializeLease(&value, lease); err != nil {
+		return err
+	}
+
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(leaseBucket)
+		if bucket == nil {
+			return fmt.Errorf("lease bucket not found")
+		}
+
+		if bucket.Get(key.Bytes()) != nil {
+			return ErrLeaseExists
+		}
+
+		return bucket.Put(key.Bytes(), value.Bytes())
+	}, func() {})
+}
+
+// FetchLeases returns all leases we bought or sold.
+func (s *Store) FetchLeases() ([]*Lease, error) {
+	var leases []*Lease
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(leaseBucket)
+		if bucket == nil {
+			return fmt.Errorf("lease bucket not found")
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			lease, err := deserializeLease(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+
+			err = lnwire.ReadElement(
+				bytes.NewReader(k), &lease.ChanPoint,
+			)
+			if err != nil {
+				return err
+			}
+
+			leases = append(leases, lease)
+
+			return nil
+		})
+	}, func() {
+		leases = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return leases, nil
+}
+
+// Income returns the total of the lease fees we earned from selling inbound
+// liquidity.
+func (s *Store) Income() (chainutil.Amount, error) {
+	leases, err := s.FetchLeases()
+	if err != nil {
+		return 0, err
+	}
+
+	var income chainutil.Amount
+	for _, lease := range leases {
+		if lease.Role == RoleSeller {
+			income += lease.Fee
+		}
+	}
+
+	return income, nil
+}
+
+// serializeLease writes the lease, except for its channel point which is used
+// as the key, as a TLV stream.
+func serializeLease(w io.Writer, lease *Lease) error {
+	var (
+		role   = uint8(lease.Role)
+		amount = uint64(lease.Amount)
+		fee    = uint64(lease.Fee)
+		rates  = tlv.NewRecordT[tlv.TlvType5](lease.Rates)
+	)
+	tlvStream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(leaseRoleType, &role),
+		tlv.MakePrimitiveRecord(leasePeerType, &lease.Peer),
+		tlv.MakePrimitiveRecord(leaseAmountType, &amount),
+		tlv.MakePrimitiveRecord(leaseFeeType, &fee),
+		tlv.MakePrimitiveRecord(leaseExpiryType, &lease.Expiry),
+		rates.Record(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tlvStream.Encode(w)
+}
+
+// deserializeLease reads a lease written by serializeLease.
+func deserializeLease(r io.Reader) (*Lease, error) {
+	var (
+		lease       Lease
+		role        uint8
+		amount, fee uint64
+		rates       = tlv.ZeroRecordT[tlv.TlvType5, lnwire.LeaseRates]()
+	)
+	tlvStream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(leaseRoleType, &role),
+		tlv.MakePrimitiveRecord(leasePeerType, &lease.Peer),
+		tlv.MakePrimitiveRecord(leaseAmountType, &amount),
+		tlv.MakePrimitiveRecord(leaseFeeType, &fee),
+		tlv.MakePrimitiveRecord(leaseExpiryType, &lease.Expiry),
+		rates.Record(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tlvStream.Decode(r); err != nil {
+		return nil, err
+	}
+
+	lease.Role = Role(role)
+	lease.Amount = chainutil.Amount(amount)
+	lease.Fee = chainutil.Amount(fee)
+	lease.Rates = rates.Val
+
+	return &lease, nil
+}
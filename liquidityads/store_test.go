@@ -0,0 +1,69 @@
+package liquidityads
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStoreLeases asserts that leases are persisted and that only the fees of
+// leases we sold count towards our income.
+func TestStoreLeases(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "testdb")
+	db, err := kvdb.Create(
+		kvdb.BoltBackendName, dbPath, true, kvdb.DefaultDBTimeout,
+		false,
+	)
+	require.NoError(t, err)
+	defer db.Close()
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	income, err := store.Income()
+	require.NoError(t, err)
+	require.Zero(t, income)
+
+	sold := &Lease{
+		ChanPoint: wire.OutPoint{Hash: [32]byte{1}, Index: 1},
+		Peer:      [33]byte{2},
+		Role:      RoleSeller,
+		Amount:    1_000_000,
+		Fee:       7_110,
+		Rates: lnwire.LeaseRates{
+			FundingWeight: 444,
+			LeaseFeeBasis: 50,
+			LeaseFeeBase:  1_000,
+		},
+		Expiry: 104_032,
+	}
+	bought := &Lease{
+		ChanPoint: wire.OutPoint{Hash: [32]byte{3}},
+		Peer:      [33]byte{4},
+		Role:      RoleBuyer,
+		Amount:    500_000,
+		Fee:       2_000,
+		Expiry:    105_000,
+	}
+	require.NoError(t, store.AddLease(sold))
+	require.NoError(t, store.AddLease(bought))
+	require.ErrorIs(t, store.AddLease(sold), ErrLeaseExists)
+
+	// Reopening the store gives us back the same leases.
+	store, err = NewStore(db)
+	require.NoError(t, err)
+
+	leases, err := store.FetchLeases()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []*Lease{sold, bought}, leases)
+
+	income, err = store.Income()
+	require.NoError(t, err)
+	require.EqualValues(t, 7_110, income)
+}
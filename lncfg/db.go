@@ -250,6 +250,11 @@ type DatabaseBackends struct {
 	// the underlying wallet database from.
 	WalletDB btcwallet.LoaderOption
 
+	// WalletBackend points to the database backend that stores the wallet
+	// data. This is nil for a local bbolt wallet, which is opened by the
+	// wallet loader itself.
+	WalletBackend kvdb.Backend
+
 	// NativeSQLStore holds a reference to the native SQL store that can
 	// be used for native SQL queries for tables that already support it.
 	// This may be nil if the use-native-sql flag was not set.
@@ -398,8 +403,9 @@ func (db *DB) GetBackends(ctx context.Context, chanDBPath,
 			WalletDB: btcwallet.LoaderWithExternalWalletDB(
 				etcdWalletBackend,
 			),
-			Remote:     true,
-			CloseFuncs: closeFuncs,
+			WalletBackend: etcdWalletBackend,
+			Remote:        true,
+			CloseFuncs:    closeFuncs,
 		}, nil
 
 	case PostgresBackend:
@@ -521,6 +527,7 @@ func (db *DB) GetBackends(ctx context.Context, chanDBPath,
 			WalletDB: btcwallet.LoaderWithExternalWalletDB(
 				postgresWalletBackend,
 			),
+			WalletBackend:  postgresWalletBackend,
 			NativeSQLStore: nativeSQLStore,
 			Remote:         true,
 			CloseFuncs:     closeFuncs,
@@ -644,6 +651,7 @@ func (db *DB) GetBackends(ctx context.Context, chanDBPath,
 			WalletDB: btcwallet.LoaderWithExternalWalletDB(
 				sqliteWalletBackend,
 			),
+			WalletBackend:  sqliteWalletBackend,
 			NativeSQLStore: nativeSQLStore,
 			CloseFuncs:     closeFuncs,
 		}, nil
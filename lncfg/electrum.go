@@ -0,0 +1,18 @@
+package lncfg
+
+import "time"
+
+const (
+	// DefaultElectrumTimeout is the default timeout for requests made to
+	// the electrum server.
+	DefaultElectrumTimeout = 30 * time.Second
+)
+
+// Electrum holds the configuration options for the daemon's connection to an
+// electrum server.
+//
+//nolint:ll
+type Electrum struct {
+	Server  string        `long:"server" description:"The host:port of the electrum server to connect to."`
+	Timeout time.Duration `long:"timeout" description:"The amount of time to wait for a response from the electrum server before giving up on a request. Valid time units are {s, m, h}."`
+}
@@ -0,0 +1,23 @@
+package lncfg
+
+import "time"
+
+const (
+	// DefaultEsploraTimeout is the default timeout for requests made to
+	// the Esplora API.
+	DefaultEsploraTimeout = 30 * time.Second
+
+	// DefaultEsploraPollInterval is the default interval at which the
+	// Esplora API is polled for new blocks.
+	DefaultEsploraPollInterval = 10 * time.Second
+)
+
+// Esplora holds the configuration options for the daemon's connection to an
+// Esplora HTTP API.
+//
+//nolint:ll
+type Esplora struct {
+	URL          string        `long:"url" description:"The base URL of the Esplora API to use, e.g. https://esplora.example.com/api."`
+	Timeout      time.Duration `long:"timeout" description:"The amount of time to wait for a response from the Esplora API before giving up on a request. Valid time units are {s, m, h}."`
+	PollInterval time.Duration `long:"pollinterval" description:"How often the Esplora API is polled for new blocks. Valid time units are {s, m, h}."`
+}
@@ -0,0 +1,52 @@
+package lncfg
+
+import (
+	"fmt"
+
+	"github.com/flokiorg/flnd/lnwire"
+)
+
+//nolint:ll
+type LiquidityAds struct {
+	Active bool `long:"active" description:"If set, then flnd will advertise the lease rates below in its node announcement and sell inbound liquidity to peers that request it when opening a dual-funded channel. Requires protocol.dual-funding and script enforced lease commitments."`
+
+	LeaseFeeBase uint32 `long:"leasefeebase" description:"The fixed fee in loki charged for each lease."`
+
+	LeaseFeeBasis uint16 `long:"leasefeebasis" description:"The fee charged for the leased amount, in basis points."`
+
+	FundingWeight uint16 `long:"fundingweight" description:"The weight of the inputs and outputs we add to the funding transaction of a lease, which the buyer pays for at the fee rate of the funding transaction."`
+
+	ChannelFeeMaxBase uint32 `long:"channelfeemaxbase" description:"The maximum base fee in milli-loki we promise to charge for forwarding payments through a leased channel."`
+
+	ChannelFeeMaxProportional uint16 `long:"channelfeemaxproportional" description:"The maximum proportional fee, in thousandths of a basis point, we promise to charge for forwarding payments through a leased channel."`
+
+	MaxLeaseAmount uint64 `long:"maxleaseamount" description:"The maximum amount in loki we contribute to a single lease."`
+}
+
+// Validate checks the values configured for liquidity ads.
+func (l *LiquidityAds) Validate() error {
+	if !l.Active {
+		return nil
+	}
+
+	if l.LeaseFeeBasis > 10_000 {
+		return fmt.Errorf("leasefeebasis must be <= 10000")
+	}
+
+	if l.MaxLeaseAmount == 0 {
+		return fmt.Errorf("maxleaseamount must be positive")
+	}
+
+	return nil
+}
+
+// Rates returns the lease rates we advertise.
+func (l *LiquidityAds) Rates() lnwire.LeaseRates {
+	return lnwire.LeaseRates{
+		FundingWeight:             l.FundingWeight,
+		LeaseFeeBasis:             l.LeaseFeeBasis,
+		ChannelFeeMaxProportional: l.ChannelFeeMaxProportional,
+		LeaseFeeBase:              l.LeaseFeeBase,
+		ChannelFeeMaxBase:         l.ChannelFeeMaxBase,
+	}
+}
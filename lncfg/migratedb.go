@@ -0,0 +1,61 @@
+package lncfg
+
+import "fmt"
+
+// MigrateDB holds the configuration of the migrate-db command, which migrates
+// all data of the node from a KV database into the native SQL database that is
+// configured in the db section.
+//
+//nolint:ll
+type MigrateDB struct {
+	Source *DB `group:"source" namespace:"source" description:"The KV database to migrate the node's data from. If it is the same database as the destination, the KV data is migrated to native SQL in place."`
+}
+
+// DefaultMigrateDB returns the default migrate-db configuration, which uses the
+// default bbolt database as the source.
+func DefaultMigrateDB() *MigrateDB {
+	return &MigrateDB{
+		Source: DefaultDB(),
+	}
+}
+
+// Validate checks that the source of the migration is a valid KV database and
+// that the given destination is a database that supports native SQL.
+func (m *MigrateDB) Validate(dest *DB) error {
+	if m.Source.UseNativeSQL {
+		return fmt.Errorf("the migration source must not use native " +
+			"SQL")
+	}
+
+	if err := m.Source.Validate(); err != nil {
+		return fmt.Errorf("invalid migration source: %w", err)
+	}
+
+	switch dest.Backend {
+	case SqliteBackend, PostgresBackend:
+
+	default:
+		return fmt.Errorf("the migration destination must be a %v or "+
+			"%v database, got %v", SqliteBackend, PostgresBackend,
+			dest.Backend)
+	}
+
+	// Moving KV data between two different postgres databases is not
+	// supported, the source must either be a different kind of database
+	// or the destination itself.
+	if m.Source.Backend == PostgresBackend &&
+		dest.Backend == PostgresBackend &&
+		m.Source.Postgres.Dsn != dest.Postgres.Dsn {
+
+		return fmt.Errorf("migrating between two different postgres " +
+			"databases is not supported")
+	}
+
+	return nil
+}
+
+// SameKVDatabase returns true if the KV data of the migration source already
+// lives in the given destination database.
+func (m *MigrateDB) SameKVDatabase(dest *DB) bool {
+	return m.Source.Backend == dest.Backend
+}
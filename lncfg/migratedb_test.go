@@ -0,0 +1,88 @@
+package lncfg_test
+
+import (
+	"testing"
+
+	"github.com/flokiorg/flnd/lncfg"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrateDBValidate tests that only migrations from a KV database to a
+// database supporting native SQL are accepted.
+func TestMigrateDBValidate(t *testing.T) {
+	t.Parallel()
+
+	dbWithBackend := func(backend string) *lncfg.DB {
+		db := lncfg.DefaultDB()
+		db.Backend = backend
+		db.Postgres.Dsn = "postgres://dest"
+
+		return db
+	}
+
+	tests := []struct {
+		name   string
+		source *lncfg.DB
+		dest   *lncfg.DB
+		err    string
+	}{
+		{
+			name:   "bolt to sqlite",
+			source: dbWithBackend(lncfg.BoltBackend),
+			dest:   dbWithBackend(lncfg.SqliteBackend),
+		},
+		{
+			name:   "kv sqlite to native sqlite",
+			source: dbWithBackend(lncfg.SqliteBackend),
+			dest:   dbWithBackend(lncfg.SqliteBackend),
+		},
+		{
+			name:   "kv postgres to native postgres",
+			source: dbWithBackend(lncfg.PostgresBackend),
+			dest:   dbWithBackend(lncfg.PostgresBackend),
+		},
+		{
+			name:   "bolt to bolt",
+			source: dbWithBackend(lncfg.BoltBackend),
+			dest:   dbWithBackend(lncfg.BoltBackend),
+			err:    "must be a sqlite or postgres database",
+		},
+		{
+			name: "native source",
+			source: func() *lncfg.DB {
+				db := dbWithBackend(lncfg.SqliteBackend)
+				db.UseNativeSQL = true
+
+				return db
+			}(),
+			dest: dbWithBackend(lncfg.SqliteBackend),
+			err:  "must not use native SQL",
+		},
+		{
+			name: "different postgres databases",
+			source: func() *lncfg.DB {
+				db := dbWithBackend(lncfg.PostgresBackend)
+				db.Postgres.Dsn = "postgres://source"
+
+				return db
+			}(),
+			dest: dbWithBackend(lncfg.PostgresBackend),
+			err:  "two different postgres databases",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := &lncfg.MigrateDB{Source: test.source}
+			err := cfg.Validate(test.dest)
+			if test.err == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package lncfg
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flokiorg/flnd/routing/route"
+)
+
+// Probe holds the configuration options for the prober that warms up mission
+// control.
+//
+//nolint:ll
+type Probe struct {
+	Active bool `long:"active" description:"If set, then probes with a random payment hash are sent periodically to warm up mission control. The probes don't show up in the payment history."`
+
+	Interval time.Duration `long:"interval" description:"The interval in which probes are sent."`
+
+	Destinations []string `long:"destination" description:"The hex-encoded pubkey of a node that is always probed. Can be specified multiple times."`
+
+	CentralNodes int `long:"centralnodes" description:"The number of nodes with the highest betweenness centrality that are probed in addition to the destinations."`
+
+	CentralityRefresh time.Duration `long:"centralityrefresh" description:"The interval in which the nodes with the highest centrality are determined anew."`
+
+	MinAmount uint64 `long:"minamt" description:"The smallest amount in loki that is probed."`
+
+	MaxAmount uint64 `long:"maxamt" description:"The largest amount in loki that is probed."`
+
+	InFlightBudget uint64 `long:"inflightbudget" description:"The total amount in loki, including fees, that probes may lock up in our channels at the same time."`
+}
+
+// DestinationVertices returns the parsed pubkeys of the destinations.
+func (p *Probe) DestinationVertices() ([]route.Vertex, error) {
+	vertices := make([]route.Vertex, 0, len(p.Destinations))
+	for _, pubkeyStr := range p.Destinations {
+		vertex, err := route.NewVertexFromStr(pubkeyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid probe destination "+
+				"%v: %w", pubkeyStr, err)
+		}
+
+		vertices = append(vertices, vertex)
+	}
+
+	return vertices, nil
+}
+
+// Validate checks that the probe config options are sane.
+//
+// NOTE: this is part of the Validator interface.
+func (p *Probe) Validate() error {
+	if !p.Active {
+		return nil
+	}
+
+	if p.Interval <= 0 {
+		return fmt.Errorf("probe interval must be positive")
+	}
+
+	if _, err := p.DestinationVertices(); err != nil {
+		return err
+	}
+
+	if p.CentralNodes < 0 {
+		return fmt.Errorf("probe centralnodes must not be negative")
+	}
+
+	if len(p.Destinations) == 0 && p.CentralNodes == 0 {
+		return fmt.Errorf("probing requires a destination or " +
+			"centralnodes to be set")
+	}
+
+	if p.CentralNodes > 0 && p.CentralityRefresh <= 0 {
+		return fmt.Errorf("probe centralityrefresh must be positive")
+	}
+
+	if p.MinAmount == 0 {
+		return fmt.Errorf("probe minamt must be positive")
+	}
+
+	if p.MinAmount > p.MaxAmount {
+		return fmt.Errorf("probe minamt must not exceed maxamt")
+	}
+
+	if p.MaxAmount > p.InFlightBudget {
+		return fmt.Errorf("probe maxamt must not exceed " +
+			"inflightbudget")
+	}
+
+	return nil
+}
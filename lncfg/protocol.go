@@ -39,6 +39,31 @@ type ProtocolOptions struct {
 	// the new experimental RBF coop close feature.
 	RbfCoopClose bool `long:"rbf-coop-close" description:"if set, then flnd will signal that it supports the new RBF based coop close protocol, taproot channels are not supported"`
 
+	// DualFunding should be set if we want to signal that we support
+	// dual-funded channel opens where both peers contribute inputs.
+	DualFunding bool `long:"dual-funding" description:"if set, then flnd will signal that it supports dual-funded channel establishment, allowing both peers to contribute funds to a new channel"`
+
+	// Splicing should be set if we want to signal that we support
+	// splicing funds into and out of open channels.
+	Splicing bool `long:"splicing" description:"if set, then flnd will signal that it supports splicing, allowing funds to be added to or removed from open channels without closing them"`
+
+	// DynamicCommitments should be set if we want to signal that we
+	// support renegotiating the parameters of live channels.
+	DynamicCommitments bool `long:"dynamic-commitments" description:"if set, then flnd will signal that it supports the dynamic commitments protocol, allowing the parameters and commitment type of open channels to be upgraded"`
+
+	// GossipV2 should be set if we want to signal that we support the
+	// taproot gossip protocol, which also allows public taproot channels
+	// to be opened with peers that support it.
+	GossipV2 bool `long:"gossip-v2" description:"if set, then flnd will signal that it supports the taproot gossip protocol (channel_announcement_2 and channel_update_2), which is required to open public taproot channels"`
+
+	// PeerStorage should be set if we want to exchange encrypted backups
+	// of our static channel state with peers.
+	PeerStorage bool `long:"peer-storage" description:"if set, then flnd will signal that it supports peer storage, handing peers an encrypted backup of its static channel state and storing the backups of peers it has channels with in return"`
+
+	// TrampolineRouting should be set if we want to relay payments as a
+	// trampoline node.
+	TrampolineRouting bool `long:"trampoline-routing" description:"if set, then flnd will signal that it supports trampoline routing, finding routes for and relaying the payments it receives with a trampoline onion for the fees set in routing.trampoline"`
+
 	// NoAnchors should be set if we don't want to support opening or accepting
 	// channels having the anchor commitment type.
 	NoAnchors bool `long:"no-anchors" description:"disable support for anchor commitments"`
@@ -0,0 +1,53 @@
+package lncfg
+
+import (
+	"fmt"
+	"time"
+)
+
+// Rebalance holds the configuration options for the rebalancer.
+//
+//nolint:ll
+type Rebalance struct {
+	Active bool `long:"active" description:"If set, then our channels are rebalanced automatically towards the targets set with SetRebalanceTargets. Rebalances requested over RPC are made regardless."`
+
+	Interval time.Duration `long:"interval" description:"The interval in which the channels are checked against their targets."`
+
+	MaxFee uint64 `long:"maxfee" description:"The maximum fee in loki of a single rebalance, unless a different maximum is requested."`
+
+	MaxFeeRate uint64 `long:"maxfeerate" description:"The maximum fee of a single rebalance in parts per million of the rebalanced amount, unless a different maximum fee is requested."`
+
+	DailyBudget uint64 `long:"dailybudget" description:"The total fee in loki that may be spent on rebalances within a day."`
+
+	MinAmount uint64 `long:"minamt" description:"The smallest amount in loki that is rebalanced automatically."`
+
+	MaxAmount uint64 `long:"maxamt" description:"The largest amount in loki that is rebalanced automatically at once."`
+
+	Tolerance float64 `long:"tolerance" description:"The fraction of the capacity by which the local balance of a channel may deviate from its target before it is rebalanced automatically."`
+
+	MaxAttempts uint32 `long:"maxattempts" description:"The number of routes that are tried for a single rebalance."`
+}
+
+// Validate checks that the rebalance config options are sane.
+//
+// NOTE: this is part of the Validator interface.
+func (r *Rebalance) Validate() error {
+	if r.Active && r.Interval <= 0 {
+		return fmt.Errorf("rebalance interval must be positive")
+	}
+
+	if r.MaxAttempts == 0 {
+		return fmt.Errorf("rebalance maxattempts must be positive")
+	}
+
+	if r.Tolerance < 0 || r.Tolerance >= 1 {
+		return fmt.Errorf("rebalance tolerance must be in the range " +
+			"[0,1)")
+	}
+
+	if r.MinAmount > r.MaxAmount {
+		return fmt.Errorf("rebalance minamt must not exceed maxamt")
+	}
+
+	return nil
+}
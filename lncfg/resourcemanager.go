@@ -0,0 +1,57 @@
+package lncfg
+
+import (
+	"fmt"
+	"time"
+)
+
+// MinResolutionPeriod is the shortest resolution period allowed for htlcs
+// forwarded with the resource manager active.
+const MinResolutionPeriod = 10 * time.Second
+
+//nolint:ll
+type ResourceManager struct {
+	Active bool `long:"active" description:"If set, then forwarded htlcs are only granted the slots and liquidity of their incoming channel according to the reputation of their outgoing channel, to mitigate channel jamming."`
+
+	RevenueWindow time.Duration `long:"revenuewindow" description:"The period over which the revenue of incoming channels is averaged."`
+
+	ReputationMultiplier uint8 `long:"reputationmultiplier" description:"The multiple of the revenue window over which the reputation of outgoing channels is averaged."`
+
+	ResolutionPeriod time.Duration `long:"resolutionperiod" description:"The time within which forwarded htlcs are expected to resolve. Accountable htlcs held longer reduce the reputation of their outgoing channel."`
+
+	GeneralBucketPercent uint8 `long:"generalbucketpercent" description:"The percentage of the slots and liquidity of an incoming channel that any htlc may use."`
+
+	CongestionBucketPercent uint8 `long:"congestionbucketpercent" description:"The percentage of the slots and liquidity of an incoming channel that is used once the general bucket is full. The remainder is reserved for htlcs towards channels with a good reputation."`
+}
+
+// Validate checks the values configured for the resource manager.
+func (r *ResourceManager) Validate() error {
+	if !r.Active {
+		return nil
+	}
+
+	if r.RevenueWindow <= 0 {
+		return fmt.Errorf("resourcemanager.revenuewindow must be " +
+			"positive")
+	}
+
+	if r.ReputationMultiplier == 0 {
+		return fmt.Errorf("resourcemanager.reputationmultiplier must " +
+			"be positive")
+	}
+
+	if r.ResolutionPeriod < MinResolutionPeriod {
+		return fmt.Errorf("resourcemanager.resolutionperiod must be "+
+			"at least %v", MinResolutionPeriod)
+	}
+
+	total := uint16(r.GeneralBucketPercent) +
+		uint16(r.CongestionBucketPercent)
+	if total > 100 {
+		return fmt.Errorf("resourcemanager.generalbucketpercent and "+
+			"resourcemanager.congestionbucketpercent must not add "+
+			"up to more than 100, got %v", total)
+	}
+
+	return nil
+}
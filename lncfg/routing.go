@@ -11,6 +11,12 @@ type Routing struct {
 	StrictZombiePruning bool `long:"strictgraphpruning" description:"If true, then the graph will be pruned more aggressively for zombies. In practice this means that edges with a single stale edge will be considered a zombie."`
 
 	BlindedPaths BlindedPaths `group:"blinding" namespace:"blinding"`
+
+	Trampoline Trampoline `group:"trampoline" namespace:"trampoline"`
+
+	Rebalance Rebalance `group:"rebalance" namespace:"rebalance"`
+
+	Probe Probe `group:"probe" namespace:"probe"`
 }
 
 // BlindedPaths holds the configuration options for blinded path construction.
@@ -50,5 +56,17 @@ func (r *Routing) Validate() error {
 			"multiplier must be in the range (0,1]")
 	}
 
+	if err := r.Trampoline.Validate(); err != nil {
+		return err
+	}
+
+	if err := r.Rebalance.Validate(); err != nil {
+		return err
+	}
+
+	if err := r.Probe.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,79 @@
+package lncfg
+
+import (
+	"fmt"
+
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
+)
+
+const (
+	// DefaultTrampolineBaseFee is the default base fee in milli-loki we
+	// charge for relaying trampoline payments.
+	DefaultTrampolineBaseFee = 1000
+
+	// DefaultTrampolineFeeRate is the default proportional fee in
+	// millionths we charge for relaying trampoline payments. It's higher
+	// than the usual channel fee rates, as it needs to cover the fees of
+	// the route we find to the next node.
+	DefaultTrampolineFeeRate = 2500
+
+	// DefaultTrampolineTimeLockDelta is the default CLTV delta we require
+	// for relaying trampoline payments. It needs to cover the CLTV deltas
+	// of the route we find to the next node on top of our own.
+	DefaultTrampolineTimeLockDelta = 2400
+)
+
+// Trampoline holds the configuration options for trampoline routing.
+//
+//nolint:ll
+type Trampoline struct {
+	Nodes []string `long:"node" description:"The public key of a trampoline node to relay our payments through. Can be specified multiple times, in which case payments are relayed through the nodes in the order given."`
+
+	BaseFee uint32 `long:"basefee" description:"The base fee in milli-loki we charge for relaying trampoline payments."`
+
+	FeeRate uint32 `long:"feerate" description:"The fee rate in millionths we charge for relaying trampoline payments."`
+
+	TimeLockDelta uint16 `long:"timelockdelta" description:"The CLTV delta we require for relaying trampoline payments."`
+}
+
+// Policy returns the policy we advertise for relaying trampoline payments.
+func (t *Trampoline) Policy() lnwire.TrampolinePolicy {
+	return lnwire.TrampolinePolicy{
+		FeeBaseMSat:               t.BaseFee,
+		FeeProportionalMillionths: t.FeeRate,
+		CltvExpiryDelta:           t.TimeLockDelta,
+	}
+}
+
+// NodeVertices returns the public keys of the trampoline nodes we relay our
+// payments through, in the order they were given.
+func (t *Trampoline) NodeVertices() ([]route.Vertex, error) {
+	vertices := make([]route.Vertex, 0, len(t.Nodes))
+	for _, node := range t.Nodes {
+		vertex, err := route.NewVertexFromStr(node)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trampoline node %v: %w",
+				node, err)
+		}
+
+		vertices = append(vertices, vertex)
+	}
+
+	return vertices, nil
+}
+
+// Validate checks that the trampoline config options are sane.
+//
+// NOTE: this is part of the Validator interface.
+func (t *Trampoline) Validate() error {
+	if _, err := t.NodeVertices(); err != nil {
+		return err
+	}
+
+	if t.TimeLockDelta == 0 {
+		return fmt.Errorf("trampoline timelockdelta must be positive")
+	}
+
+	return nil
+}
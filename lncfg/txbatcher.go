@@ -0,0 +1,33 @@
+package lncfg
+
+import (
+	"fmt"
+	"time"
+)
+
+// MinTxBatcherInterval is the shortest interval allowed between two periodic
+// batch transactions.
+const MinTxBatcherInterval = time.Minute
+
+//nolint:ll
+type TxBatcher struct {
+	Active bool `long:"active" description:"If set, then on-chain sends from SendCoins/SendMany without an explicit fee rate are queued and paid together in a periodic batch transaction. Queued payments can also ride along in batch channel opens and in sweeps without a deadline."`
+
+	Interval time.Duration `long:"interval" description:"The interval between two periodic batch transactions."`
+
+	UrgentDelta uint32 `long:"urgentdelta" description:"The number of blocks before its deadline at which a queued payment is considered urgent and is broadcast (or the pending batch is fee bumped) without waiting for the next interval."`
+}
+
+// Validate checks the values configured for the tx batcher.
+func (t *TxBatcher) Validate() error {
+	if !t.Active {
+		return nil
+	}
+
+	if t.Interval < MinTxBatcherInterval {
+		return fmt.Errorf("txbatcher.interval must be at least %v",
+			MinTxBatcherInterval)
+	}
+
+	return nil
+}
@@ -298,6 +298,26 @@ type LightningClient interface {
 	// UpdateChannelPolicy allows the caller to update the fee schedule and
 	// channel policies for all channels globally, or a particular channel.
 	UpdateChannelPolicy(ctx context.Context, in *PolicyUpdateRequest, opts ...grpc.CallOption) (*PolicyUpdateResponse, error)
+	// lncli: `updatechanparams`
+	//UpdateChannelParams renegotiates the parameters and the commitment type of
+	//an open channel with the channel peer using the dynamic commitments
+	//protocol. The call blocks until both commitments use the new parameters or
+	//the peer rejects the update. Only the fields that are set are changed.
+	UpdateChannelParams(ctx context.Context, in *UpdateChannelParamsRequest, opts ...grpc.CallOption) (*UpdateChannelParamsResponse, error)
+	// lncli: `splicein`
+	//SpliceIn adds funds from the internal wallet to an open channel by
+	//splicing them into its funding output. The call blocks until the splice
+	//transaction was signed by both parties and published. The channel can't
+	//forward payments until the splice transaction confirmed.
+	SpliceIn(ctx context.Context, in *SpliceInRequest, opts ...grpc.CallOption) (*SpliceResponse, error)
+	// lncli: `spliceout`
+	//SpliceOut removes funds from an open channel by splicing them out of its
+	//funding output, either to the internal wallet or to an external address.
+	//The fee of the splice transaction is paid from the local channel balance.
+	//The call blocks until the splice transaction was signed by both parties
+	//and published. The channel can't forward payments until the splice
+	//transaction confirmed.
+	SpliceOut(ctx context.Context, in *SpliceOutRequest, opts ...grpc.CallOption) (*SpliceResponse, error)
 	// lncli: `fwdinghistory`
 	// ForwardingHistory allows the caller to query the htlcswitch for a record of
 	// all HTLCs forwarded within the target time range, and integer offset
@@ -1038,6 +1058,33 @@ func (c *lightningClient) UpdateChannelPolicy(ctx context.Context, in *PolicyUpd
 	return out, nil
 }
 
+func (c *lightningClient) UpdateChannelParams(ctx context.Context, in *UpdateChannelParamsRequest, opts ...grpc.CallOption) (*UpdateChannelParamsResponse, error) {
+	out := new(UpdateChannelParamsResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/UpdateChannelParams", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) SpliceIn(ctx context.Context, in *SpliceInRequest, opts ...grpc.CallOption) (*SpliceResponse, error) {
+	out := new(SpliceResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/SpliceIn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) SpliceOut(ctx context.Context, in *SpliceOutRequest, opts ...grpc.CallOption) (*SpliceResponse, error) {
+	out := new(SpliceResponse)
+	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/SpliceOut", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lightningClient) ForwardingHistory(ctx context.Context, in *ForwardingHistoryRequest, opts ...grpc.CallOption) (*ForwardingHistoryResponse, error) {
 	out := new(ForwardingHistoryResponse)
 	err := c.cc.Invoke(ctx, "/lnrpc.Lightning/ForwardingHistory", in, out, opts...)
@@ -1574,6 +1621,26 @@ type LightningServer interface {
 	// UpdateChannelPolicy allows the caller to update the fee schedule and
 	// channel policies for all channels globally, or a particular channel.
 	UpdateChannelPolicy(context.Context, *PolicyUpdateRequest) (*PolicyUpdateResponse, error)
+	// lncli: `updatechanparams`
+	//UpdateChannelParams renegotiates the parameters and the commitment type of
+	//an open channel with the channel peer using the dynamic commitments
+	//protocol. The call blocks until both commitments use the new parameters or
+	//the peer rejects the update. Only the fields that are set are changed.
+	UpdateChannelParams(context.Context, *UpdateChannelParamsRequest) (*UpdateChannelParamsResponse, error)
+	// lncli: `splicein`
+	//SpliceIn adds funds from the internal wallet to an open channel by
+	//splicing them into its funding output. The call blocks until the splice
+	//transaction was signed by both parties and published. The channel can't
+	//forward payments until the splice transaction confirmed.
+	SpliceIn(context.Context, *SpliceInRequest) (*SpliceResponse, error)
+	// lncli: `spliceout`
+	//SpliceOut removes funds from an open channel by splicing them out of its
+	//funding output, either to the internal wallet or to an external address.
+	//The fee of the splice transaction is paid from the local channel balance.
+	//The call blocks until the splice transaction was signed by both parties
+	//and published. The channel can't forward payments until the splice
+	//transaction confirmed.
+	SpliceOut(context.Context, *SpliceOutRequest) (*SpliceResponse, error)
 	// lncli: `fwdinghistory`
 	// ForwardingHistory allows the caller to query the htlcswitch for a record of
 	// all HTLCs forwarded within the target time range, and integer offset
@@ -1834,6 +1901,15 @@ func (UnimplementedLightningServer) FeeReport(context.Context, *FeeReportRequest
 func (UnimplementedLightningServer) UpdateChannelPolicy(context.Context, *PolicyUpdateRequest) (*PolicyUpdateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateChannelPolicy not implemented")
 }
+func (UnimplementedLightningServer) UpdateChannelParams(context.Context, *UpdateChannelParamsRequest) (*UpdateChannelParamsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateChannelParams not implemented")
+}
+func (UnimplementedLightningServer) SpliceIn(context.Context, *SpliceInRequest) (*SpliceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SpliceIn not implemented")
+}
+func (UnimplementedLightningServer) SpliceOut(context.Context, *SpliceOutRequest) (*SpliceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SpliceOut not implemented")
+}
 func (UnimplementedLightningServer) ForwardingHistory(context.Context, *ForwardingHistoryRequest) (*ForwardingHistoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ForwardingHistory not implemented")
 }
@@ -2812,6 +2888,60 @@ func _Lightning_UpdateChannelPolicy_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Lightning_UpdateChannelParams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateChannelParamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).UpdateChannelParams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/UpdateChannelParams",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).UpdateChannelParams(ctx, req.(*UpdateChannelParamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_SpliceIn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SpliceInRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).SpliceIn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/SpliceIn",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).SpliceIn(ctx, req.(*SpliceInRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_SpliceOut_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SpliceOutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).SpliceOut(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/SpliceOut",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).SpliceOut(ctx, req.(*SpliceOutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Lightning_ForwardingHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ForwardingHistoryRequest)
 	if err := dec(in); err != nil {
@@ -3324,6 +3454,18 @@ var Lightning_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateChannelPolicy",
 			Handler:    _Lightning_UpdateChannelPolicy_Handler,
 		},
+		{
+			MethodName: "UpdateChannelParams",
+			Handler:    _Lightning_UpdateChannelParams_Handler,
+		},
+		{
+			MethodName: "SpliceIn",
+			Handler:    _Lightning_SpliceIn_Handler,
+		},
+		{
+			MethodName: "SpliceOut",
+			Handler:    _Lightning_SpliceOut_Handler,
+		},
 		{
 			MethodName: "ForwardingHistory",
 			Handler:    _Lightning_ForwardingHistory_Handler,
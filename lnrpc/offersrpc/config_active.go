@@ -0,0 +1,25 @@
+//go:build offersrpc
+// +build offersrpc
+
+package offersrpc
+
+import (
+	"github.com/flokiorg/flnd/offers"
+	"github.com/flokiorg/go-flokicoin/chaincfg"
+)
+
+// Config is the primary configuration struct for the offers RPC subserver.
+// It contains all the items required for the server to carry out its duties.
+// The fields with struct tags are meant to be parsed as normal configuration
+// options, while if able to be populated, the latter fields MUST also be
+// specified.
+type Config struct {
+	// OffersMgr creates our offers and answers the invoice requests made
+	// for them. It is nil if onion messaging is disabled, in which case
+	// only the decoding calls are available.
+	OffersMgr *offers.Manager
+
+	// ChainParams are the parameters of the chain we operate on, used to
+	// validate decoded invoice requests.
+	ChainParams *chaincfg.Params
+}
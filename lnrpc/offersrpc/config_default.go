@@ -0,0 +1,7 @@
+//go:build !offersrpc
+// +build !offersrpc
+
+package offersrpc
+
+// Config is empty for non-offersrpc builds.
+type Config struct{}
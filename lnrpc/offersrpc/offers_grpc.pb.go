@@ -0,0 +1,335 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package offersrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// OffersClient is the client API for Offers service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OffersClient interface {
+	// lncli: `offer create`
+	//CreateOffer creates a new offer issued under our node key. Invoice
+	//requests for the offer are answered until it is disabled or expires.
+	CreateOffer(ctx context.Context, in *CreateOfferRequest, opts ...grpc.CallOption) (*CreateOfferResponse, error)
+	// lncli: `offer list`
+	//ListOffers returns the offers we have created.
+	ListOffers(ctx context.Context, in *ListOffersRequest, opts ...grpc.CallOption) (*ListOffersResponse, error)
+	// lncli: `offer disable`
+	//DisableOffer stops answering invoice requests for one of our offers.
+	DisableOffer(ctx context.Context, in *DisableOfferRequest, opts ...grpc.CallOption) (*DisableOfferResponse, error)
+	// lncli: `offer decode`
+	//DecodeOffer decodes a bech32 encoded offer string.
+	DecodeOffer(ctx context.Context, in *DecodeOfferRequest, opts ...grpc.CallOption) (*DecodedOffer, error)
+	// DecodeInvoiceRequest decodes a bech32 encoded invoice request string and
+	// checks its signature.
+	DecodeInvoiceRequest(ctx context.Context, in *DecodeInvoiceRequestRequest, opts ...grpc.CallOption) (*DecodedInvoiceRequest, error)
+	// SubscribeOfferInvoices returns a uni-directional stream (server -> client)
+	// of the invoices we send in reply to invoice requests for our offers.
+	SubscribeOfferInvoices(ctx context.Context, in *SubscribeOfferInvoicesRequest, opts ...grpc.CallOption) (Offers_SubscribeOfferInvoicesClient, error)
+}
+
+type offersClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOffersClient(cc grpc.ClientConnInterface) OffersClient {
+	return &offersClient{cc}
+}
+
+func (c *offersClient) CreateOffer(ctx context.Context, in *CreateOfferRequest, opts ...grpc.CallOption) (*CreateOfferResponse, error) {
+	out := new(CreateOfferResponse)
+	err := c.cc.Invoke(ctx, "/offersrpc.Offers/CreateOffer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *offersClient) ListOffers(ctx context.Context, in *ListOffersRequest, opts ...grpc.CallOption) (*ListOffersResponse, error) {
+	out := new(ListOffersResponse)
+	err := c.cc.Invoke(ctx, "/offersrpc.Offers/ListOffers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *offersClient) DisableOffer(ctx context.Context, in *DisableOfferRequest, opts ...grpc.CallOption) (*DisableOfferResponse, error) {
+	out := new(DisableOfferResponse)
+	err := c.cc.Invoke(ctx, "/offersrpc.Offers/DisableOffer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *offersClient) DecodeOffer(ctx context.Context, in *DecodeOfferRequest, opts ...grpc.CallOption) (*DecodedOffer, error) {
+	out := new(DecodedOffer)
+	err := c.cc.Invoke(ctx, "/offersrpc.Offers/DecodeOffer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *offersClient) DecodeInvoiceRequest(ctx context.Context, in *DecodeInvoiceRequestRequest, opts ...grpc.CallOption) (*DecodedInvoiceRequest, error) {
+	out := new(DecodedInvoiceRequest)
+	err := c.cc.Invoke(ctx, "/offersrpc.Offers/DecodeInvoiceRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *offersClient) SubscribeOfferInvoices(ctx context.Context, in *SubscribeOfferInvoicesRequest, opts ...grpc.CallOption) (Offers_SubscribeOfferInvoicesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Offers_ServiceDesc.Streams[0], "/offersrpc.Offers/SubscribeOfferInvoices", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &offersSubscribeOfferInvoicesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Offers_SubscribeOfferInvoicesClient interface {
+	Recv() (*OfferInvoice, error)
+	grpc.ClientStream
+}
+
+type offersSubscribeOfferInvoicesClient struct {
+	grpc.ClientStream
+}
+
+func (x *offersSubscribeOfferInvoicesClient) Recv() (*OfferInvoice, error) {
+	m := new(OfferInvoice)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// OffersServer is the server API for Offers service.
+// All implementations must embed UnimplementedOffersServer
+// for forward compatibility
+type OffersServer interface {
+	// lncli: `offer create`
+	//CreateOffer creates a new offer issued under our node key. Invoice
+	//requests for the offer are answered until it is disabled or expires.
+	CreateOffer(context.Context, *CreateOfferRequest) (*CreateOfferResponse, error)
+	// lncli: `offer list`
+	//ListOffers returns the offers we have created.
+	ListOffers(context.Context, *ListOffersRequest) (*ListOffersResponse, error)
+	// lncli: `offer disable`
+	//DisableOffer stops answering invoice requests for one of our offers.
+	DisableOffer(context.Context, *DisableOfferRequest) (*DisableOfferResponse, error)
+	// lncli: `offer decode`
+	//DecodeOffer decodes a bech32 encoded offer string.
+	DecodeOffer(context.Context, *DecodeOfferRequest) (*DecodedOffer, error)
+	// DecodeInvoiceRequest decodes a bech32 encoded invoice request string and
+	// checks its signature.
+	DecodeInvoiceRequest(context.Context, *DecodeInvoiceRequestRequest) (*DecodedInvoiceRequest, error)
+	// SubscribeOfferInvoices returns a uni-directional stream (server -> client)
+	// of the invoices we send in reply to invoice requests for our offers.
+	SubscribeOfferInvoices(*SubscribeOfferInvoicesRequest, Offers_SubscribeOfferInvoicesServer) error
+	mustEmbedUnimplementedOffersServer()
+}
+
+// UnimplementedOffersServer must be embedded to have forward compatible implementations.
+type UnimplementedOffersServer struct {
+}
+
+func (UnimplementedOffersServer) CreateOffer(context.Context, *CreateOfferRequest) (*CreateOfferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOffer not implemented")
+}
+func (UnimplementedOffersServer) ListOffers(context.Context, *ListOffersRequest) (*ListOffersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOffers not implemented")
+}
+func (UnimplementedOffersServer) DisableOffer(context.Context, *DisableOfferRequest) (*DisableOfferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableOffer not implemented")
+}
+func (UnimplementedOffersServer) DecodeOffer(context.Context, *DecodeOfferRequest) (*DecodedOffer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DecodeOffer not implemented")
+}
+func (UnimplementedOffersServer) DecodeInvoiceRequest(context.Context, *DecodeInvoiceRequestRequest) (*DecodedInvoiceRequest, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DecodeInvoiceRequest not implemented")
+}
+func (UnimplementedOffersServer) SubscribeOfferInvoices(*SubscribeOfferInvoicesRequest, Offers_SubscribeOfferInvoicesServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeOfferInvoices not implemented")
+}
+func (UnimplementedOffersServer) mustEmbedUnimplementedOffersServer() {}
+
+// UnsafeOffersServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OffersServer will
+// result in compilation errors.
+type UnsafeOffersServer interface {
+	mustEmbedUnimplementedOffersServer()
+}
+
+func RegisterOffersServer(s grpc.ServiceRegistrar, srv OffersServer) {
+	s.RegisterService(&Offers_ServiceDesc, srv)
+}
+
+func _Offers_CreateOffer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOfferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OffersServer).CreateOffer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/offersrpc.Offers/CreateOffer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OffersServer).CreateOffer(ctx, req.(*CreateOfferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Offers_ListOffers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOffersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OffersServer).ListOffers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/offersrpc.Offers/ListOffers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OffersServer).ListOffers(ctx, req.(*ListOffersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Offers_DisableOffer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableOfferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OffersServer).DisableOffer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/offersrpc.Offers/DisableOffer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OffersServer).DisableOffer(ctx, req.(*DisableOfferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Offers_DecodeOffer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecodeOfferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OffersServer).DecodeOffer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/offersrpc.Offers/DecodeOffer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OffersServer).DecodeOffer(ctx, req.(*DecodeOfferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Offers_DecodeInvoiceRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecodeInvoiceRequestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OffersServer).DecodeInvoiceRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/offersrpc.Offers/DecodeInvoiceRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OffersServer).DecodeInvoiceRequest(ctx, req.(*DecodeInvoiceRequestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Offers_SubscribeOfferInvoices_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeOfferInvoicesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OffersServer).SubscribeOfferInvoices(m, &offersSubscribeOfferInvoicesServer{stream})
+}
+
+type Offers_SubscribeOfferInvoicesServer interface {
+	Send(*OfferInvoice) error
+	grpc.ServerStream
+}
+
+type offersSubscribeOfferInvoicesServer struct {
+	grpc.ServerStream
+}
+
+func (x *offersSubscribeOfferInvoicesServer) Send(m *OfferInvoice) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Offers_ServiceDesc is the grpc.ServiceDesc for Offers service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Offers_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "offersrpc.Offers",
+	HandlerType: (*OffersServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateOffer",
+			Handler:    _Offers_CreateOffer_Handler,
+		},
+		{
+			MethodName: "ListOffers",
+			Handler:    _Offers_ListOffers_Handler,
+		},
+		{
+			MethodName: "DisableOffer",
+			Handler:    _Offers_DisableOffer_Handler,
+		},
+		{
+			MethodName: "DecodeOffer",
+			Handler:    _Offers_DecodeOffer_Handler,
+		},
+		{
+			MethodName: "DecodeInvoiceRequest",
+			Handler:    _Offers_DecodeInvoiceRequest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeOfferInvoices",
+			Handler:       _Offers_SubscribeOfferInvoices_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "offersrpc/offers.proto",
+}
@@ -0,0 +1,543 @@
+//go:build offersrpc
+// +build offersrpc
+
+package offersrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/flokiorg/flnd/bolt12"
+	"github.com/flokiorg/flnd/lnrpc"
+	"github.com/flokiorg/flnd/lnrpc/invoicesrpc"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/offers"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"gopkg.in/macaroon-bakery.v2/bakery"
+)
+
+const (
+	// subServerName is the name of the sub rpc server. We'll use this name
+	// to register ourselves, and we also require that the main
+	// SubServerConfigDispatcher instance recognize tt as the name of our
+	// RPC service.
+	subServerName = "OffersRPC"
+)
+
+var (
+	// macPermissions maps RPC calls to the permissions they require.
+	macPermissions = map[string][]bakery.Op{
+		"/offersrpc.Offers/CreateOffer": {{
+			Entity: "invoices",
+			Action: "write",
+		}},
+		"/offersrpc.Offers/ListOffers": {{
+			Entity: "invoices",
+			Action: "read",
+		}},
+		"/offersrpc.Offers/DisableOffer": {{
+			Entity: "invoices",
+			Action: "write",
+		}},
+		"/offersrpc.Offers/DecodeOffer": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
+		"/offersrpc.Offers/DecodeInvoiceRequest": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
+		"/offersrpc.Offers/SubscribeOfferInvoices": {{
+			Entity: "invoices",
+			Action: "read",
+		}},
+	}
+
+	// errOffersDisabled is returned by the calls that need the offers
+	// manager if it is not running.
+	errOffersDisabled = errors.New("offers are not available, onion " +
+		"messaging is disabled")
+
+	// errServerShuttingDown is returned to open invoice subscriptions when
+	// the server shuts down.
+	errServerShuttingDown = errors.New("offers rpc server shutting down")
+)
+
+// ServerShell is a shell struct holding a reference to the actual sub-server.
+// It is used to register the gRPC sub-server with the root server before we
+// have the necessary dependencies to populate the actual sub-server.
+type ServerShell struct {
+	OffersServer
+}
+
+// Server is a sub-server of the main RPC server: the offers RPC. This sub RPC
+// server allows to create and manage the BOLT 12 offers issued by our node.
+type Server struct {
+	started  int32 // To be used atomically.
+	shutdown int32 // To be used atomically.
+
+	// Required by the grpc-gateway/v2 library for forward compatibility.
+	// Must be after the atomically used variables to not break struct
+	// alignment.
+	UnimplementedOffersServer
+
+	cfg *Config
+
+	quit chan struct{}
+}
+
+// A compile time check to ensure that Server fully implements the
+// OffersServer gRPC service.
+var _ OffersServer = (*Server)(nil)
+
+// New returns a new instance of the offersrpc Offers sub-server. We also
+// return the set of permissions for the macaroons that we may create within
+// this method.
+func New(cfg *Config) (*Server, lnrpc.MacaroonPerms, error) {
+	server := &Server{
+		cfg:  cfg,
+		quit: make(chan struct{}),
+	}
+
+	return server, macPermissions, nil
+}
+
+// Start launches any helper goroutines required for the Server to function.
+//
+// NOTE: This is part of the lnrpc.SubServer interface.
+func (s *Server) Start() error {
+	if atomic.AddInt32(&s.started, 1) != 1 {
+		return nil
+	}
+
+	return nil
+}
+
+// Stop signals any active goroutines for a graceful closure.
+//
+// NOTE: This is part of the lnrpc.SubServer interface.
+func (s *Server) Stop() error {
+	if atomic.AddInt32(&s.shutdown, 1) != 1 {
+		return nil
+	}
+
+	close(s.quit)
+
+	return nil
+}
+
+// Name returns a unique string representation of the sub-server. This can be
+// used to identify the sub-server and also de-duplicate them.
+//
+// NOTE: This is part of the lnrpc.SubServer interface.
+func (s *Server) Name() string {
+	return subServerName
+}
+
+// RegisterWithRootServer will be called by the root gRPC server to direct a
+// sub RPC server to register itself with the main gRPC root server. Until this
+// is called, each sub-server won't be able to have requests routed towards it.
+//
+// NOTE: This is part of the lnrpc.GrpcHandler interface.
+func (r *ServerShell) RegisterWithRootServer(grpcServer *grpc.Server) error {
+	// We make sure that we register it with the main gRPC server to ensure
+	// all our methods are routed properly.
+	RegisterOffersServer(grpcServer, r)
+
+	log.Debugf("Offers RPC server successfully registered with root " +
+		"gRPC server")
+
+	return nil
+}
+
+// RegisterWithRestServer will be called by the root REST mux to direct a sub
+// RPC server to register itself with the main REST mux server. Until this is
+// called, each sub-server won't be able to have requests routed towards it.
+//
+// NOTE: This is part of the lnrpc.GrpcHandler interface.
+func (r *ServerShell) RegisterWithRestServer(ctx context.Context,
+	mux *runtime.ServeMux, dest string, opts []grpc.DialOption) error {
+
+	// We make sure that we register it with the main REST server to ensure
+	// all our methods are routed properly.
+	err := RegisterOffersHandlerFromEndpoint(ctx, mux, dest, opts)
+	if err != nil {
+		log.Errorf("Could not register Offers REST server "+
+			"with root REST server: %v", err)
+		return err
+	}
+
+	log.Debugf("Offers REST server successfully registered with " +
+		"root REST server")
+	return nil
+}
+
+// CreateSubServer populates the subserver's dependencies using the passed
+// SubServerConfigDispatcher. This method should fully initialize the
+// sub-server instance, making it ready for action. It returns the macaroon
+// permissions that the sub-server wishes to pass on to the root server for all
+// methods routed towards it.
+//
+// NOTE: This is part of the lnrpc.GrpcHandler interface.
+func (r *ServerShell) CreateSubServer(
+	configRegistry lnrpc.SubServerConfigDispatcher) (lnrpc.SubServer,
+	lnrpc.MacaroonPerms, error) {
+
+	subServer, macPermissions, err := createNewSubServer(configRegistry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.OffersServer = subServer
+	return subServer, macPermissions, nil
+}
+
+// CreateOffer creates a new offer issued under our node key.
+//
+// NOTE: This is part of the OffersServer interface.
+func (s *Server) CreateOffer(_ context.Context,
+	req *CreateOfferRequest) (*CreateOfferResponse, error) {
+
+	if s.cfg.OffersMgr == nil {
+		return nil, errOffersDisabled
+	}
+
+	params := &offers.OfferParams{
+		Description: req.Description,
+		AmountMsat:  lnwire.MilliLoki(req.AmountMsat),
+		Issuer:      req.Issuer,
+		QuantityMax: req.QuantityMax,
+	}
+	if req.AbsoluteExpiry != 0 {
+		params.AbsoluteExpiry = time.Unix(int64(req.AbsoluteExpiry), 0)
+	}
+
+	stored, err := s.cfg.OffersMgr.CreateOffer(params)
+	if err != nil {
+		return nil, err
+	}
+
+	offer, err := marshallStoredOffer(stored)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreateOfferResponse{
+		Offer: offer,
+	}, nil
+}
+
+// ListOffers returns the offers we have created.
+//
+// NOTE: This is part of the OffersServer interface.
+func (s *Server) ListOffers(_ context.Context,
+	req *ListOffersRequest) (*ListOffersResponse, error) {
+
+	if s.cfg.OffersMgr == nil {
+		return nil, errOffersDisabled
+	}
+
+	stored, err := s.cfg.OffersMgr.ListOffers()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListOffersResponse{}
+	for _, o := range stored {
+		if req.ActiveOnly && o.Disabled {
+			continue
+		}
+
+		offer, err := marshallStoredOffer(o)
+		if err != nil {
+			return nil, err
+		}
+		resp.Offers = append(resp.Offers, offer)
+	}
+
+	return resp, nil
+}
+
+// DisableOffer stops answering invoice requests for one of our offers.
+//
+// NOTE: This is part of the OffersServer interface.
+func (s *Server) DisableOffer(_ context.Context,
+	req *DisableOfferRequest) (*DisableOfferResponse, error) {
+
+	if s.cfg.OffersMgr == nil {
+		return nil, errOffersDisabled
+	}
+
+	if len(req.OfferId) != 32 {
+		return nil, fmt.Errorf("offer ID must be 32 bytes, got %d",
+			len(req.OfferId))
+	}
+
+	var id [32]byte
+	copy(id[:], req.OfferId)
+
+	if err := s.cfg.OffersMgr.DisableOffer(id); err != nil {
+		return nil, err
+	}
+
+	return &DisableOfferResponse{}, nil
+}
+
+// DecodeOffer decodes a bech32 encoded offer string.
+//
+// NOTE: This is part of the OffersServer interface.
+func (s *Server) DecodeOffer(_ context.Context,
+	req *DecodeOfferRequest) (*DecodedOffer, error) {
+
+	offer, err := bolt12.DecodeOfferString(req.Offer)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode offer: %w", err)
+	}
+
+	id, err := offer.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	return marshallOffer(id, offer), nil
+}
+
+// DecodeInvoiceRequest decodes a bech32 encoded invoice request string and
+// checks its signature.
+//
+// NOTE: This is part of the OffersServer interface.
+func (s *Server) DecodeInvoiceRequest(_ context.Context,
+	req *DecodeInvoiceRequestRequest) (*DecodedInvoiceRequest, error) {
+
+	ir, err := bolt12.DecodeInvoiceRequestString(req.InvoiceRequest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode invoice request: %w",
+			err)
+	}
+
+	err = bolt12.ValidateInvoiceRequestRead(
+		ir, *s.cfg.ChainParams.GenesisHash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid invoice request: %w", err)
+	}
+
+	id, err := ir.OfferID()
+	if err != nil {
+		return nil, err
+	}
+
+	// The invoice request mirrors every field of the offer it was made
+	// for.
+	offer := &bolt12.Offer{
+		OfferChains:         ir.OfferChains,
+		OfferMetadata:       ir.OfferMetadata,
+		OfferCurrency:       ir.OfferCurrency,
+		OfferAmount:         ir.OfferAmount,
+		OfferDescription:    ir.OfferDescription,
+		OfferFeatures:       ir.OfferFeatures,
+		OfferAbsoluteExpiry: ir.OfferAbsoluteExpiry,
+		OfferPaths:          ir.OfferPaths,
+		OfferIssuer:         ir.OfferIssuer,
+		OfferQuantityMax:    ir.OfferQuantityMax,
+		OfferIssuerID:       ir.OfferIssuerID,
+	}
+
+	resp := &DecodedInvoiceRequest{
+		Offer:         marshallOffer(id, offer),
+		PayerMetadata: ir.InvreqMetadata.ValOpt().UnwrapOr(nil),
+		AmountMsat:    uint64(ir.InvreqAmount.ValOpt().UnwrapOr(0)),
+		Quantity:      uint64(ir.InvreqQuantity.ValOpt().UnwrapOr(0)),
+		PayerNote: string(
+			ir.InvreqPayerNote.ValOpt().UnwrapOr(nil),
+		),
+	}
+	ir.InvreqChain.WhenSomeV(func(chain [32]byte) {
+		resp.Chain = chain[:]
+	})
+	ir.InvreqFeatures.WhenSomeV(func(raw lnwire.RawFeatureVector) {
+		resp.Features = marshallFeatures(&raw)
+	})
+	ir.InvreqPayerID.WhenSomeV(func(payerID *crypto.PublicKey) {
+		resp.PayerId = payerID.SerializeCompressed()
+	})
+	ir.InvreqPaths.WhenSomeV(func(paths lnwire.BlindedPaths) {
+		resp.Paths = marshallBlindedPaths(paths)
+	})
+
+	return resp, nil
+}
+
+// SubscribeOfferInvoices streams the invoices we send in reply to invoice
+// requests for our offers.
+//
+// NOTE: This is part of the OffersServer interface.
+func (s *Server) SubscribeOfferInvoices(_ *SubscribeOfferInvoicesRequest,
+	stream Offers_SubscribeOfferInvoicesServer) error {
+
+	if s.cfg.OffersMgr == nil {
+		return errOffersDisabled
+	}
+
+	client, err := s.cfg.OffersMgr.SubscribeInvoices()
+	if err != nil {
+		return err
+	}
+	defer client.Cancel()
+
+	for {
+		select {
+		case update := <-client.Updates():
+			issued, ok := update.(*offers.IssuedInvoice)
+			if !ok {
+				return fmt.Errorf("unexpected update type %T",
+					update)
+			}
+
+			rpcInvoice, err := marshallIssuedInvoice(issued)
+			if err != nil {
+				return err
+			}
+
+			if err := stream.Send(rpcInvoice); err != nil {
+				return err
+			}
+
+		// If the stream's context is cancelled, return an error.
+		case <-stream.Context().Done():
+			log.Debugf("Offer invoice stream cancelled")
+			return stream.Context().Err()
+
+		// If the subscribe client terminates, exit with an error.
+		case <-client.Quit():
+			return errors.New("offer invoice subscription " +
+				"terminated")
+
+		// If the server has been signalled to shut down, exit.
+		case <-s.quit:
+			return errServerShuttingDown
+		}
+	}
+}
+
+// marshallStoredOffer converts one of our stored offers into its RPC
+// representation.
+func marshallStoredOffer(stored *offers.StoredOffer) (*Offer, error) {
+	encoded, err := stored.Offer.EncodeString()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Offer{
+		OfferId:      stored.ID[:],
+		Offer:        encoded,
+		CreationDate: stored.CreatedAt.Unix(),
+		Disabled:     stored.Disabled,
+		Decoded:      marshallOffer(stored.ID, stored.Offer),
+	}, nil
+}
+
+// marshallOffer converts the fields of an offer into their RPC
+// representation.
+func marshallOffer(id [32]byte, offer *bolt12.Offer) *DecodedOffer {
+	decoded := &DecodedOffer{
+		OfferId:  id[:],
+		Metadata: offer.OfferMetadata.ValOpt().UnwrapOr(nil),
+		Currency: string(offer.OfferCurrency.ValOpt().UnwrapOr(nil)),
+		Amount:   uint64(offer.OfferAmount.ValOpt().UnwrapOr(0)),
+		Description: string(
+			offer.OfferDescription.ValOpt().UnwrapOr(nil),
+		),
+		AbsoluteExpiry: uint64(
+			offer.OfferAbsoluteExpiry.ValOpt().UnwrapOr(0),
+		),
+		Issuer: string(offer.OfferIssuer.ValOpt().UnwrapOr(nil)),
+		QuantityMax: uint64(
+			offer.OfferQuantityMax.ValOpt().UnwrapOr(0),
+		),
+	}
+
+	offer.OfferChains.WhenSomeV(func(chains bolt12.ChainsRecord) {
+		for _, chain := range chains.Chains {
+			decoded.Chains = append(decoded.Chains, chain[:])
+		}
+	})
+	offer.OfferFeatures.WhenSomeV(func(raw lnwire.RawFeatureVector) {
+		decoded.Features = marshallFeatures(&raw)
+	})
+	offer.OfferPaths.WhenSomeV(func(paths lnwire.BlindedPaths) {
+		decoded.Paths = marshallBlindedPaths(paths)
+	})
+	offer.OfferIssuerID.WhenSomeV(func(issuerID *crypto.PublicKey) {
+		decoded.IssuerId = issuerID.SerializeCompressed()
+	})
+
+	return decoded
+}
+
+// marshallIssuedInvoice converts an invoice we issued for one of our offers
+// into its RPC representation.
+func marshallIssuedInvoice(issued *offers.IssuedInvoice) (*OfferInvoice,
+	error) {
+
+	inv := issued.Invoice
+
+	encoded, err := inv.EncodeString()
+	if err != nil {
+		return nil, err
+	}
+
+	rpcInvoice := &OfferInvoice{
+		OfferId:    issued.OfferID[:],
+		Invoice:    encoded,
+		AmountMsat: uint64(inv.InvoiceAmount.ValOpt().UnwrapOr(0)),
+		CreationDate: int64(
+			inv.InvoiceCreatedAt.ValOpt().UnwrapOr(0),
+		),
+		Quantity:  uint64(inv.InvreqQuantity.ValOpt().UnwrapOr(0)),
+		PayerNote: string(inv.InvreqPayerNote.ValOpt().UnwrapOr(nil)),
+	}
+	inv.InvoicePaymentHash.WhenSomeV(func(hash [32]byte) {
+		rpcInvoice.PaymentHash = hash[:]
+	})
+	inv.InvreqPayerID.WhenSomeV(func(payerID *crypto.PublicKey) {
+		rpcInvoice.PayerId = payerID.SerializeCompressed()
+	})
+
+	return rpcInvoice, nil
+}
+
+// marshallFeatures converts a raw BOLT 12 feature vector into its RPC
+// representation.
+func marshallFeatures(raw *lnwire.RawFeatureVector) map[uint32]*lnrpc.Feature {
+	return invoicesrpc.CreateRPCFeatures(
+		lnwire.NewFeatureVector(raw, lnwire.Features),
+	)
+}
+
+// marshallBlindedPaths converts BOLT 12 blinded paths into their RPC
+// representation.
+func marshallBlindedPaths(paths lnwire.BlindedPaths) []*BlindedPath {
+	rpcPaths := make([]*BlindedPath, 0, len(paths.Paths))
+	for _, path := range paths.Paths {
+		rpcPath := &BlindedPath{
+			NumHops: uint32(len(path.Hops)),
+		}
+		if path.IntroductionNode != nil {
+			rpcPath.IntroductionNode = path.IntroductionNode.Bytes()
+		}
+		if path.BlindingPoint != nil {
+			rpcPath.BlindingPoint =
+				path.BlindingPoint.SerializeCompressed()
+		}
+
+		rpcPaths = append(rpcPaths, rpcPath)
+	}
+
+	return rpcPaths
+}
@@ -0,0 +1,183 @@
+//go:build offersrpc
+// +build offersrpc
+
+package offersrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/flokiorg/flnd/bolt12"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chaincfg"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestOffer returns an offer for 1000 msat with a description, an issuer
+// and a blinded path to the issuer.
+func newTestOffer(t *testing.T) (*bolt12.Offer, *crypto.PublicKey) {
+	t.Helper()
+
+	issuerKey, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+
+	introKey, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+
+	introNode, err := lnwire.NewPubkeyIntro(introKey.PubKey())
+	require.NoError(t, err)
+
+	offer := &bolt12.Offer{}
+	offer.OfferAmount = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType8](bolt12.TUint64(1000)),
+	)
+	offer.OfferDescription = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType10](tlv.Blob("coffee")),
+	)
+	offer.OfferIssuer = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType18](tlv.Blob("shop")),
+	)
+	offer.OfferQuantityMax = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType20](bolt12.TUint64(5)),
+	)
+	offer.OfferIssuerID = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType22](issuerKey.PubKey()),
+	)
+	offer.OfferPaths = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType16](lnwire.BlindedPaths{
+			Paths: []lnwire.BlindedPath{{
+				IntroductionNode: introNode,
+				BlindingPoint:    introKey.PubKey(),
+				Hops: []lnwire.BlindedHop{{
+					BlindedNodeID: issuerKey.PubKey(),
+					EncryptedData: []byte{1, 2, 3},
+				}},
+			}},
+		}),
+	)
+
+	return offer, introKey.PubKey()
+}
+
+// TestDecodeOffer checks that the fields of an encoded offer are returned.
+func TestDecodeOffer(t *testing.T) {
+	t.Parallel()
+
+	offer, intro := newTestOffer(t)
+	encoded, err := offer.EncodeString()
+	require.NoError(t, err)
+
+	id, err := offer.ID()
+	require.NoError(t, err)
+
+	s, _, err := New(&Config{ChainParams: &chaincfg.MainNetParams})
+	require.NoError(t, err)
+
+	resp, err := s.DecodeOffer(context.Background(), &DecodeOfferRequest{
+		Offer: encoded,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, id[:], resp.OfferId)
+	require.EqualValues(t, 1000, resp.Amount)
+	require.Equal(t, "coffee", resp.Description)
+	require.Equal(t, "shop", resp.Issuer)
+	require.EqualValues(t, 5, resp.QuantityMax)
+	require.Len(t, resp.IssuerId, 33)
+	require.Len(t, resp.Paths, 1)
+	require.Equal(t, intro.SerializeCompressed(),
+		resp.Paths[0].IntroductionNode)
+	require.EqualValues(t, 1, resp.Paths[0].NumHops)
+
+	_, err = s.DecodeOffer(context.Background(), &DecodeOfferRequest{
+		Offer: "lno1invalid",
+	})
+	require.Error(t, err)
+}
+
+// TestDecodeInvoiceRequest checks that the fields of an encoded invoice
+// request, including those of the offer it mirrors, are returned and that
+// requests with a bad signature are rejected.
+func TestDecodeInvoiceRequest(t *testing.T) {
+	t.Parallel()
+
+	offer, _ := newTestOffer(t)
+	id, err := offer.ID()
+	require.NoError(t, err)
+
+	payerKey, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+
+	ir, err := bolt12.NewInvoiceRequestFromOffer(
+		offer, payerKey.PubKey(), []byte("metadata"),
+		*chaincfg.MainNetParams.GenesisHash,
+	)
+	require.NoError(t, err)
+
+	ir.InvreqQuantity = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType86](bolt12.TUint64(2)),
+	)
+	ir.InvreqPayerNote = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType89](tlv.Blob("thanks")),
+	)
+	require.NoError(t, ir.Sign(payerKey))
+
+	encoded, err := ir.EncodeString()
+	require.NoError(t, err)
+
+	s, _, err := New(&Config{ChainParams: &chaincfg.MainNetParams})
+	require.NoError(t, err)
+
+	resp, err := s.DecodeInvoiceRequest(
+		context.Background(), &DecodeInvoiceRequestRequest{
+			InvoiceRequest: encoded,
+		},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, id[:], resp.Offer.OfferId)
+	require.Equal(t, "coffee", resp.Offer.Description)
+	require.Equal(t, []byte("metadata"), resp.PayerMetadata)
+	require.EqualValues(t, 2, resp.Quantity)
+	require.Equal(t, "thanks", resp.PayerNote)
+	require.Equal(t, payerKey.PubKey().SerializeCompressed(), resp.PayerId)
+
+	// A request with a tampered signature must be rejected.
+	sig := ir.Signature.UnwrapOrFailV(t)
+	sig[0] ^= 1
+	ir.Signature = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType240](sig),
+	)
+
+	encoded, err = ir.EncodeString()
+	require.NoError(t, err)
+
+	_, err = s.DecodeInvoiceRequest(
+		context.Background(), &DecodeInvoiceRequestRequest{
+			InvoiceRequest: encoded,
+		},
+	)
+	require.Error(t, err)
+}
+
+// TestOffersDisabled checks that the calls needing the offers manager fail
+// cleanly if it is not running.
+func TestOffersDisabled(t *testing.T) {
+	t.Parallel()
+
+	s, _, err := New(&Config{ChainParams: &chaincfg.MainNetParams})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = s.CreateOffer(ctx, &CreateOfferRequest{})
+	require.ErrorIs(t, err, errOffersDisabled)
+
+	_, err = s.ListOffers(ctx, &ListOffersRequest{})
+	require.ErrorIs(t, err, errOffersDisabled)
+
+	_, err = s.DisableOffer(ctx, &DisableOfferRequest{})
+	require.ErrorIs(t, err, errOffersDisabled)
+}
@@ -74,6 +74,10 @@ func DefaultConfig() *Config {
 			NodeWeight: routing.DefaultBimodalNodeWeight,
 			DecayTime:  routing.DefaultBimodalDecayTime,
 		},
+		ExternalConfig: &ExternalConfig{
+			Timeout:  routing.DefaultExternalTimeout,
+			CacheTTL: routing.DefaultExternalCacheTTL,
+		},
 		FeeEstimationTimeout: routing.DefaultFeeEstimationTimeout,
 	}
 
@@ -102,6 +106,10 @@ func GetRoutingConfig(cfg *Config) *RoutingConfig {
 			NodeWeight: cfg.BimodalConfig.NodeWeight,
 			DecayTime:  cfg.BimodalConfig.DecayTime,
 		},
+		ExternalConfig: &ExternalConfig{
+			Timeout:  cfg.ExternalConfig.Timeout,
+			CacheTTL: cfg.ExternalConfig.CacheTTL,
+		},
 		FeeEstimationTimeout: cfg.FeeEstimationTimeout,
 	}
 }
@@ -2,6 +2,7 @@ package routerrpc
 
 import (
 	"errors"
+	"time"
 
 	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/graph/db/models"
@@ -28,8 +29,15 @@ var (
 // interceptor streaming session.
 // It is created when the stream opens and disconnects when the stream closes.
 type forwardInterceptor struct {
-	// stream is the bidirectional RPC stream
-	stream Router_HtlcInterceptorServer
+	// registration describes how the interceptor takes part in the chain
+	// of interceptors. It is nil for the default interceptor.
+	registration *htlcswitch.InterceptorRegistration
+
+	// send sends an intercepted packet to the client.
+	send func(*ForwardHtlcInterceptRequest) error
+
+	// recv receives the next resolution from the client.
+	recv func() (*ForwardHtlcInterceptResponse, error)
 
 	htlcSwitch htlcswitch.InterceptableHtlcForwarder
 }
@@ -40,8 +48,113 @@ func newForwardInterceptor(htlcSwitch htlcswitch.InterceptableHtlcForwarder,
 
 	return &forwardInterceptor{
 		htlcSwitch: htlcSwitch,
-		stream:     stream,
+		send:       stream.Send,
+		recv:       stream.Recv,
+	}
+}
+
+// newNamedForwardInterceptor creates a forwardInterceptor that takes part in
+// the chain of interceptors as described by the registration message the
+// client sent first.
+func newNamedForwardInterceptor(
+	htlcSwitch htlcswitch.InterceptableHtlcForwarder,
+	stream Router_RegisterHtlcInterceptorServer) (*forwardInterceptor,
+	error) {
+
+	msg, err := stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	register := msg.GetRegister()
+	if register == nil {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"first message must be the interceptor registration")
+	}
+
+	if register.Name == "" ||
+		register.Name == htlcswitch.DefaultInterceptorName {
+
+		return nil, status.Errorf(codes.InvalidArgument,
+			"invalid interceptor name %q", register.Name)
+	}
+
+	var policy htlcswitch.InterceptorPolicy
+	switch register.FailurePolicy {
+	case InterceptorFailurePolicy_FAIL_OPEN:
+		policy = htlcswitch.InterceptorFailOpen
+
+	case InterceptorFailurePolicy_FAIL_CLOSED:
+		policy = htlcswitch.InterceptorFailClosed
+
+	default:
+		return nil, status.Errorf(codes.InvalidArgument,
+			"unknown failure policy %v", register.FailurePolicy)
+	}
+
+	return &forwardInterceptor{
+		registration: &htlcswitch.InterceptorRegistration{
+			Name:     register.Name,
+			Priority: register.Priority,
+			Timeout: time.Duration(register.TimeoutSeconds) *
+				time.Second,
+			Policy: policy,
+		},
+		htlcSwitch: htlcSwitch,
+		send:       stream.Send,
+		recv: func() (*ForwardHtlcInterceptResponse, error) {
+			msg, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+
+			resolve := msg.GetResolve()
+			if resolve == nil {
+				return nil, status.Errorf(
+					codes.InvalidArgument, "interceptor "+
+						"already registered",
+				)
+			}
+
+			return resolve, nil
+		},
+	}, nil
+}
+
+// name returns the name the interceptor is registered under.
+func (r *forwardInterceptor) name() string {
+	if r.registration == nil {
+		return htlcswitch.DefaultInterceptorName
 	}
+
+	return r.registration.Name
+}
+
+// register registers the interceptor with the switch and returns a function
+// that unregisters it again.
+func (r *forwardInterceptor) register() (func(), error) {
+	if r.registration == nil {
+		r.htlcSwitch.SetInterceptor(r.onIntercept)
+
+		return func() {
+			r.htlcSwitch.SetInterceptor(nil)
+		}, nil
+	}
+
+	registration := *r.registration
+	registration.Interceptor = r.onIntercept
+	err := r.htlcSwitch.RegisterInterceptor(registration)
+	if err != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "%v", err)
+	}
+
+	return func() {
+		err := r.htlcSwitch.UnregisterInterceptor(registration.Name)
+		if err != nil {
+			log.Errorf("Unable to unregister interceptor %v: %v",
+				registration.Name, err)
+		}
+	}, nil
 }
 
 // run sends the intercepted packets to the client and receives the
@@ -52,11 +165,14 @@ func newForwardInterceptor(htlcSwitch htlcswitch.InterceptableHtlcForwarder,
 // packets are sent to the main where they are handled.
 func (r *forwardInterceptor) run() error {
 	// Register our interceptor so we receive all forwarded packets.
-	r.htlcSwitch.SetInterceptor(r.onIntercept)
-	defer r.htlcSwitch.SetInterceptor(nil)
+	unregister, err := r.register()
+	if err != nil {
+		return err
+	}
+	defer unregister()
 
 	for {
-		resp, err := r.stream.Recv()
+		resp, err := r.recv()
 		if err != nil {
 			return err
 		}
@@ -64,7 +180,15 @@ func (r *forwardInterceptor) run() error {
 		log.Tracef("Received packet from stream: %v",
 			lnutils.SpewLogClosure(resp))
 
-		if err := r.resolveFromClient(resp); err != nil {
+		err = r.resolveFromClient(resp)
+		switch {
+		// The htlc was passed on to another interceptor in the
+		// meantime, for example because we didn't resolve it in time.
+		case errors.Is(err, htlcswitch.ErrFwdNotOffered):
+			log.Debugf("Ignoring resolution of interceptor %v: %v",
+				r.name(), err)
+
+		case err != nil:
 			return err
 		}
 	}
@@ -100,7 +224,7 @@ func (r *forwardInterceptor) onIntercept(
 		InWireCustomRecords:     htlc.InWireCustomRecords,
 	}
 
-	return r.stream.Send(interceptionRequest)
+	return r.send(interceptionRequest)
 }
 
 // resolveFromClient handles a resolution arrived from the client.
@@ -120,12 +244,14 @@ func (r *forwardInterceptor) resolveFromClient(
 		),
 		HtlcID: in.IncomingCircuitKey.HtlcId,
 	}
+	name := r.name()
 
 	switch in.Action {
 	case ResolveHoldForwardAction_RESUME:
 		return r.htlcSwitch.Resolve(&htlcswitch.FwdResolution{
-			Key:    circuitKey,
-			Action: htlcswitch.FwdActionResume,
+			Key:         circuitKey,
+			Interceptor: name,
+			Action:      htlcswitch.FwdActionResume,
 		})
 
 	case ResolveHoldForwardAction_RESUME_MODIFIED:
@@ -162,6 +288,7 @@ func (r *forwardInterceptor) resolveFromClient(
 		//nolint:ll
 		return r.htlcSwitch.Resolve(&htlcswitch.FwdResolution{
 			Key:                  circuitKey,
+			Interceptor:          name,
 			Action:               htlcswitch.FwdActionResumeModified,
 			InAmountMsat:         inAmtMsat,
 			OutAmountMsat:        outAmtMsat,
@@ -193,6 +320,7 @@ func (r *forwardInterceptor) resolveFromClient(
 
 			return r.htlcSwitch.Resolve(&htlcswitch.FwdResolution{
 				Key:            circuitKey,
+				Interceptor:    name,
 				Action:         htlcswitch.FwdActionFail,
 				FailureMessage: in.FailureMessage,
 			})
@@ -222,6 +350,7 @@ func (r *forwardInterceptor) resolveFromClient(
 
 		return r.htlcSwitch.Resolve(&htlcswitch.FwdResolution{
 			Key:         circuitKey,
+			Interceptor: name,
 			Action:      htlcswitch.FwdActionFail,
 			FailureCode: code,
 		})
@@ -236,9 +365,10 @@ func (r *forwardInterceptor) resolveFromClient(
 		}
 
 		return r.htlcSwitch.Resolve(&htlcswitch.FwdResolution{
-			Key:      circuitKey,
-			Action:   htlcswitch.FwdActionSettle,
-			Preimage: preimage,
+			Key:         circuitKey,
+			Interceptor: name,
+			Action:      htlcswitch.FwdActionSettle,
+			Preimage:    preimage,
 		})
 
 	default:
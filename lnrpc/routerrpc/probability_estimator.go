@@ -0,0 +1,108 @@
+package routerrpc
+
+import (
+	"github.com/flokiorg/flnd/routing"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// probabilityEstimator is a helper struct that handles the lifecycle of an
+// RPC probability estimator streaming session. It is created when the stream
+// opens and unregisters the estimator when the stream closes.
+type probabilityEstimator struct {
+	client *routing.ProbabilityClient
+	stream Router_RegisterProbabilityEstimatorServer
+	quit   <-chan struct{}
+}
+
+// newProbabilityEstimator registers the client of the stream with the
+// probability service.
+func newProbabilityEstimator(service *routing.ProbabilityService,
+	stream Router_RegisterProbabilityEstimatorServer,
+	quit <-chan struct{}) (*probabilityEstimator, error) {
+
+	client, err := service.Register()
+	if err != nil {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+
+	return &probabilityEstimator{
+		client: client,
+		stream: stream,
+		quit:   quit,
+	}, nil
+}
+
+// run sends the batches of queries to the client and passes its estimates on
+// until the stream or the server is closed.
+func (p *probabilityEstimator) run() error {
+	defer p.client.Close()
+
+	// Read the estimates of the client in a separate goroutine, which
+	// exits once the stream is closed.
+	errChan := make(chan error, 1)
+	go func() {
+		for {
+			resp, err := p.stream.Recv()
+			if err != nil {
+				errChan <- err
+				return
+			}
+
+			err = p.client.Resolve(resp.BatchId, resp.Probabilities)
+			if err != nil {
+				errChan <- status.Error(
+					codes.InvalidArgument, err.Error(),
+				)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case batch := <-p.client.Batches():
+			err := p.stream.Send(marshallProbabilityBatch(batch))
+			if err != nil {
+				return err
+			}
+
+		case err := <-errChan:
+			return err
+
+		case <-p.stream.Context().Done():
+			return p.stream.Context().Err()
+
+		case <-p.quit:
+			return errServerShuttingDown
+		}
+	}
+}
+
+// marshallProbabilityBatch converts a batch of probability queries into its
+// RPC counterpart.
+func marshallProbabilityBatch(
+	batch *routing.ProbabilityBatch) *ProbabilityEstimateRequest {
+
+	rpcBatch := &ProbabilityEstimateRequest{
+		BatchId: batch.ID,
+		Queries: make(
+			[]*ProbabilityEstimateQuery, 0, len(batch.Queries),
+		),
+	}
+	for _, query := range batch.Queries {
+		rpcQuery := &ProbabilityEstimateQuery{
+			FromNode:    query.FromNode[:],
+			ToNode:      query.ToNode[:],
+			AmtMsat:     int64(query.Amount),
+			CapacitySat: int64(query.Capacity),
+		}
+		query.PairResult.WhenSome(func(result routing.TimedPairResult) {
+			rpcQuery.History = toRPCPairData(&result)
+		})
+
+		rpcBatch.Queries = append(rpcBatch.Queries, rpcQuery)
+	}
+
+	return rpcBatch
+}
@@ -10,6 +10,7 @@ import (
 	math "math"
 	"time"
 
+	"github.com/flokiorg/flnd/bolt12"
 	"github.com/flokiorg/flnd/channeldb"
 	"github.com/flokiorg/flnd/clock"
 	"github.com/flokiorg/flnd/feature"
@@ -18,9 +19,11 @@ import (
 	"github.com/flokiorg/flnd/lnrpc"
 	"github.com/flokiorg/flnd/lntypes"
 	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/offers"
 	paymentsdb "github.com/flokiorg/flnd/payments/db"
 	"github.com/flokiorg/flnd/record"
 	"github.com/flokiorg/flnd/routing"
+	"github.com/flokiorg/flnd/routing/rebalance"
 	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/flnd/subscribe"
 	"github.com/flokiorg/flnd/zpay32"
@@ -138,6 +141,25 @@ type RouterBackend struct {
 	// have before it can be deleted. If zero the handler defaults to 1
 	// hour.
 	MinForwardingHistoryAge time.Duration
+
+	// FetchOfferInvoice requests an invoice for a BOLT 12 offer from its
+	// issuer and returns it once it has been validated against the
+	// request. It is nil if onion messages, and with them offers, are
+	// disabled.
+	FetchOfferInvoice func(ctx context.Context,
+		params *offers.FetchInvoiceParams) (*bolt12.Invoice, error)
+
+	// Trampolines returns the trampoline nodes to relay payments through.
+	// It is nil if no trampoline nodes are configured.
+	Trampolines func() ([]routing.TrampolineHop, error)
+
+	// Rebalancer makes rebalances between our channels and keeps track of
+	// their targets.
+	Rebalancer *rebalance.Manager
+
+	// ProbabilityService connects the external probability estimator to
+	// the out-of-process service that is registered over RPC.
+	ProbabilityService *routing.ProbabilityService
 }
 
 // ForwardingLogDB defines the interface for forwarding log database operations.
@@ -154,6 +176,13 @@ type ForwardingLogDB interface {
 	// statistics are returned along with the context error.
 	DeleteForwardingEvents(ctx context.Context, endTime time.Time,
 		batchSize int) (channeldb.DeleteStats, error)
+
+	// AggregateForwardingEvents returns the totals of the forwarding
+	// events within the time range of the query, grouped by channel, peer
+	// or time bucket.
+	AggregateForwardingEvents(ctx context.Context,
+		q channeldb.ForwardingAggregateQuery) (
+		[]channeldb.ForwardingAggregate, error)
 }
 
 // MissionControl defines the mission control dependencies of routerrpc.
@@ -409,8 +438,23 @@ func (r *RouterBackend) parseQueryRoutesRequest(in *lnrpc.QueryRoutesRequest) (
 		return nil, err
 	}
 
+	// Mission control may only learn some probability estimates after
+	// path finding asked for them. In that case, path finding waits for
+	// the estimates this query asked for and searches again.
+	getProbability := r.MissionControl.GetProbability
+	var awaitEstimates func() bool
+	if in.UseMissionControl {
+		rounder, ok := r.MissionControl.(routing.EstimateRounder)
+		if ok {
+			round := rounder.NewEstimateRound()
+			getProbability = round.GetProbability
+			awaitEstimates = round.AwaitEstimates
+		}
+	}
+
 	restrictions := &routing.RestrictParams{
-		FeeLimit: feeLimit,
+		FeeLimit:       feeLimit,
+		AwaitEstimates: awaitEstimates,
 		ProbabilitySource: func(fromNode, toNode route.Vertex,
 			amt lnwire.MilliLoki,
 			capacity chainutil.Amount) float64 {
@@ -431,7 +475,7 @@ func (r *RouterBackend) parseQueryRoutesRequest(in *lnrpc.QueryRoutesRequest) (
 				return 1
 			}
 
-			return r.MissionControl.GetProbability(
+			return getProbability(
 				fromNode, toNode, amt, capacity,
 			)
 		},
@@ -948,22 +992,7 @@ func (r *RouterBackend) extractIntentFromSendRequest(
 	}
 	payIntent.FirstHopCustomRecords = firstHopRecords
 
-	// If the experimental accountable signal is not already set, propagate
-	// a zero value field if configured to set this signal.
-	if r.ShouldSetExpAccountability() {
-		if payIntent.FirstHopCustomRecords == nil {
-			payIntent.FirstHopCustomRecords = make(
-				map[uint64][]byte,
-			)
-		}
-
-		t := uint64(lnwire.ExperimentalAccountableType)
-		if _, set := payIntent.FirstHopCustomRecords[t]; !set {
-			payIntent.FirstHopCustomRecords[t] = []byte{
-				lnwire.ExperimentalUnaccountable,
-			}
-		}
-	}
+	r.setExpAccountability(payIntent)
 
 	payIntent.PayAttemptTimeout = time.Second *
 		time.Duration(rpcPayReq.TimeoutSeconds)
@@ -1283,6 +1312,20 @@ func (r *RouterBackend) extractIntentFromSendRequest(
 		return nil, errors.New("self-payments not allowed")
 	}
 
+	// If we relay our payments through trampoline nodes, we'll let them
+	// find the route to the recipient. Payments that need to reach the
+	// recipient along a specific path can't be relayed though.
+	relayable := len(payIntent.RouteHints) == 0 &&
+		payIntent.BlindedPathSet == nil && !rpcPayReq.Amp &&
+		len(payIntent.DestCustomRecords) == 0 &&
+		payIntent.Metadata == nil
+	if r.Trampolines != nil && relayable {
+		payIntent.Trampolines, err = r.Trampolines()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return payIntent, nil
 }
 
@@ -1755,6 +1798,123 @@ func marshallChannelUpdate(update *lnwire.ChannelUpdate1) *lnrpc.ChannelUpdate {
 	}
 }
 
+// setExpAccountability propagates a zero value experimental accountable
+// signal to the first hop of the payment if configured to set this signal and
+// the signal is not already set.
+func (r *RouterBackend) setExpAccountability(
+	payIntent *routing.LightningPayment) {
+
+	if !r.ShouldSetExpAccountability() {
+		return
+	}
+
+	if payIntent.FirstHopCustomRecords == nil {
+		payIntent.FirstHopCustomRecords = make(map[uint64][]byte)
+	}
+
+	t := uint64(lnwire.ExperimentalAccountableType)
+	if _, set := payIntent.FirstHopCustomRecords[t]; !set {
+		payIntent.FirstHopCustomRecords[t] = []byte{
+			lnwire.ExperimentalUnaccountable,
+		}
+	}
+}
+
+// extractIntentFromPayOfferRequest builds the payment of the invoice fetched
+// for the offer of a PayOfferRequest. The invoice must already have been
+// validated against the invoice request it answers.
+func (r *RouterBackend) extractIntentFromPayOfferRequest(
+	rpcPayReq *PayOfferRequest,
+	inv *bolt12.Invoice) (*routing.LightningPayment, error) {
+
+	payIntent := &routing.LightningPayment{
+		OutgoingChannelIDs: rpcPayReq.OutgoingChanIds,
+		Offer:              []byte(rpcPayReq.Offer),
+		PayerNote:          rpcPayReq.PayerNote,
+	}
+
+	// Take the CLTV limit from the request if set, otherwise use the max.
+	cltvLimit, err := ValidateCLTVLimit(
+		uint32(rpcPayReq.CltvLimit), r.MaxTotalTimelock,
+	)
+	if err != nil {
+		return nil, err
+	}
+	payIntent.CltvLimit = cltvLimit
+
+	payIntent.MaxParts = rpcPayReq.MaxParts
+	if payIntent.MaxParts == 0 {
+		payIntent.MaxParts = DefaultMaxParts
+	}
+
+	payIntent.FeeLimit, err = lnrpc.UnmarshallAmt(
+		0, rpcPayReq.FeeLimitMsat,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	r.setExpAccountability(payIntent)
+
+	payIntent.PayAttemptTimeout = time.Second *
+		time.Duration(rpcPayReq.TimeoutSeconds)
+
+	payHash, err := inv.InvoicePaymentHash.UnwrapOrErrV(
+		bolt12.ErrMissingPaymentHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := payIntent.SetPaymentHash(payHash); err != nil {
+		return nil, err
+	}
+
+	amt, err := inv.InvoiceAmount.UnwrapOrErrV(
+		bolt12.ErrMissingInvoiceAmount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	payIntent.Amount = lnwire.MilliLoki(amt)
+
+	payReq, err := inv.EncodeString()
+	if err != nil {
+		return nil, err
+	}
+	payIntent.PaymentRequest = []byte(payReq)
+
+	features := lnwire.EmptyFeatureVector()
+	inv.InvoiceFeatures.WhenSomeV(func(raw lnwire.RawFeatureVector) {
+		features = lnwire.NewFeatureVector(&raw, lnwire.Features)
+	})
+	if !features.HasFeature(lnwire.MPPOptional) {
+		payIntent.MaxParts = 1
+	}
+	payIntent.DestFeatures = features
+
+	// BOLT 12 invoices can only be paid along their blinded paths, which
+	// also carry the final CLTV delta of the recipient.
+	paths, err := offers.PaymentPaths(inv)
+	if err != nil {
+		return nil, err
+	}
+
+	pathSet, err := BuildBlindedPathSet(paths)
+	if err != nil {
+		return nil, err
+	}
+	payIntent.BlindedPathSet = pathSet
+
+	copy(payIntent.Target[:], pathSet.TargetPubKey().SerializeCompressed())
+
+	pathFeatures := pathSet.Features()
+	if !pathFeatures.IsEmpty() {
+		payIntent.DestFeatures = pathFeatures.Clone()
+	}
+
+	return payIntent, nil
+}
+
 // MarshallPayment marshall a payment to its rpc representation.
 func (r *RouterBackend) MarshallPayment(payment *paymentsdb.MPPayment) (
 	*lnrpc.Payment, error) {
@@ -1821,6 +1981,8 @@ func (r *RouterBackend) MarshallPayment(payment *paymentsdb.MPPayment) (
 		PaymentIndex:          payment.SequenceNum,
 		FailureReason:         failureReason,
 		FirstHopCustomRecords: payment.Info.FirstHopCustomRecords,
+		Offer:                 string(payment.Info.Offer),
+		PayerNote:             payment.Info.PayerNote,
 	}, nil
 }
 
@@ -6,14 +6,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/flokiorg/flnd/bolt12"
 	"github.com/flokiorg/flnd/lnmock"
 	"github.com/flokiorg/flnd/lnrpc"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/record"
 	"github.com/flokiorg/flnd/routing"
 	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/tlv"
 	"github.com/flokiorg/go-flokicoin/chaincfg"
 	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/crypto"
 	"github.com/stretchr/testify/require"
 )
 
@@ -881,3 +884,138 @@ func TestExtractIntentFromSendRequest(t *testing.T) {
 		})
 	}
 }
+
+// newTestOfferInvoice returns a signed BOLT 12 invoice for amt that pays to a
+// single two hop blinded path introduced by intro.
+func newTestOfferInvoice(t *testing.T, intro *crypto.PublicKey,
+	amt lnwire.MilliLoki,
+	features *lnwire.RawFeatureVector) *bolt12.Invoice {
+
+	t.Helper()
+
+	randKey := func() *crypto.PublicKey {
+		priv, err := crypto.NewPrivateKey()
+		require.NoError(t, err)
+
+		return priv.PubKey()
+	}
+
+	nodeKey, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+
+	offer := &bolt12.Offer{}
+	offer.OfferAmount = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType8](bolt12.TUint64(amt)),
+	)
+	offer.OfferDescription = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType10](tlv.Blob("coffee")),
+	)
+	offer.OfferIssuerID = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType22](nodeKey.PubKey()),
+	)
+
+	ir, err := bolt12.NewInvoiceRequestFromOffer(
+		offer, randKey(), []byte("metadata"),
+		*chaincfg.MainNetParams.GenesisHash,
+	)
+	require.NoError(t, err)
+
+	introNode, err := lnwire.NewPubkeyIntro(intro)
+	require.NoError(t, err)
+
+	paths := lnwire.BlindedPaths{
+		Paths: []lnwire.BlindedPath{{
+			IntroductionNode: introNode,
+			BlindingPoint:    randKey(),
+			Hops: []lnwire.BlindedHop{{
+				BlindedNodeID: randKey(),
+				EncryptedData: []byte{1, 2, 3},
+			}, {
+				BlindedNodeID: randKey(),
+				EncryptedData: []byte{4, 5, 6},
+			}},
+		}},
+	}
+	payInfos := []bolt12.BlindedPayInfo{{
+		FeeBaseMsat:               1000,
+		FeeProportionalMillionths: 10,
+		CltvExpiryDelta:           80,
+		HtlcMinimumMsat:           1,
+		HtlcMaximumMsat:           uint64(amt),
+		Features:                  *lnwire.NewRawFeatureVector(),
+	}}
+
+	inv, err := bolt12.NewInvoiceFromRequest(
+		ir, nodeKey.PubKey(), [32]byte{1, 2, 3}, uint64(amt),
+		time.Now(), paths, payInfos,
+	)
+	require.NoError(t, err)
+
+	inv.InvoiceFeatures = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType174](*features),
+	)
+	require.NoError(t, inv.Sign(nodeKey))
+
+	return inv
+}
+
+// TestExtractIntentFromPayOfferRequest asserts that the payment of an invoice
+// fetched for an offer pays along its blinded path and keeps the offer
+// metadata.
+func TestExtractIntentFromPayOfferRequest(t *testing.T) {
+	t.Parallel()
+
+	const amt = lnwire.MilliLoki(50_000)
+
+	introPriv, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+	intro := introPriv.PubKey()
+
+	backend := &RouterBackend{
+		MaxTotalTimelock: 1000,
+		ShouldSetExpAccountability: func() bool {
+			return false
+		},
+	}
+
+	req := &PayOfferRequest{
+		Offer:          "lno1test",
+		PayerNote:      "thanks",
+		FeeLimitMsat:   1000,
+		TimeoutSeconds: 60,
+	}
+
+	mpp := lnwire.NewRawFeatureVector(lnwire.MPPOptional)
+	inv := newTestOfferInvoice(t, intro, amt, mpp)
+
+	payment, err := backend.extractIntentFromPayOfferRequest(req, inv)
+	require.NoError(t, err)
+
+	require.Equal(t, amt, payment.Amount)
+	require.Equal(t, lnwire.MilliLoki(1000), payment.FeeLimit)
+	require.Equal(t, uint32(1000), payment.CltvLimit)
+	require.EqualValues(t, DefaultMaxParts, payment.MaxParts)
+	require.Equal(t, [32]byte{1, 2, 3}, payment.Identifier())
+	require.Equal(t, []byte("lno1test"), payment.Offer)
+	require.Equal(t, "thanks", payment.PayerNote)
+
+	payReq, err := inv.EncodeString()
+	require.NoError(t, err)
+	require.Equal(t, []byte(payReq), payment.PaymentRequest)
+
+	// The payment must go through the real introduction node of the
+	// invoice path towards the blinded target.
+	require.NotNil(t, payment.BlindedPathSet)
+	require.True(t, payment.BlindedPathSet.IsIntroNode(
+		route.NewVertex(intro),
+	))
+	require.Equal(t, route.NewVertex(&routing.BlindedPathNUMSKey),
+		payment.Target)
+
+	// Without MPP support in the invoice, the payment is sent in one
+	// part.
+	inv = newTestOfferInvoice(t, intro, amt, lnwire.NewRawFeatureVector())
+	payment, err = backend.extractIntentFromPayOfferRequest(req, inv)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), payment.MaxParts)
+}
@@ -26,6 +26,12 @@ type RouterClient interface {
 	// routes without fees will be attempted which often fails with
 	// FAILURE_REASON_NO_ROUTE.
 	SendPaymentV2(ctx context.Context, in *SendPaymentRequest, opts ...grpc.CallOption) (Router_SendPaymentV2Client, error)
+	// PayOffer pays a BOLT 12 offer. It requests an invoice for the offer from
+	// its issuer over onion messages under a fresh payer key and pays it along
+	// the blinded paths of the invoice. The call returns a stream of payment
+	// updates, just like SendPaymentV2. When using this RPC, make sure to set a
+	// fee limit, as the default routing fee limit is 0 sats.
+	PayOffer(ctx context.Context, in *PayOfferRequest, opts ...grpc.CallOption) (Router_PayOfferClient, error)
 	// lncli: `trackpayment`
 	// TrackPaymentV2 returns an update stream for the payment identified by the
 	// payment hash.
@@ -92,6 +98,22 @@ type RouterClient interface {
 	// In case of interception, the htlc can be either settled, cancelled or
 	// resumed later by using the ResolveHoldForward endpoint.
 	HtlcInterceptor(ctx context.Context, opts ...grpc.CallOption) (Router_HtlcInterceptorClient, error)
+	// RegisterHtlcInterceptor dispatches a bi-directional streaming RPC that
+	// registers a named interceptor. Several interceptors can be registered at
+	// the same time, next to the one registered through HtlcInterceptor. Every
+	// forwarded htlc is offered to them one after another, in order of their
+	// priority. Resuming a htlc passes it on to the next interceptor, while
+	// settling or failing it is final. The first message sent by the client must
+	// be the registration.
+	RegisterHtlcInterceptor(ctx context.Context, opts ...grpc.CallOption) (Router_RegisterHtlcInterceptorClient, error)
+	// RegisterProbabilityEstimator dispatches a bi-directional streaming RPC that
+	// registers an out-of-process probability estimator. While the external
+	// estimator is selected in the mission control config, pathfinding sends
+	// batches of node pairs to the client, which answers each batch with the
+	// success probabilities of its pairs. Pairs that aren't answered in time are
+	// estimated by the apriori estimator instead. Only one estimator can be
+	// registered at a time.
+	RegisterProbabilityEstimator(ctx context.Context, opts ...grpc.CallOption) (Router_RegisterProbabilityEstimatorClient, error)
 	// lncli: `updatechanstatus`
 	// UpdateChanStatus attempts to manually set the state of a channel
 	// (enabled, disabled, or auto). A manual "disable" request will cause the
@@ -122,6 +144,30 @@ type RouterClient interface {
 	// deletion is performed in a transaction-safe manner with configurable batch
 	// sizes to avoid holding large database locks.
 	DeleteForwardingHistory(ctx context.Context, in *DeleteForwardingHistoryRequest, opts ...grpc.CallOption) (*DeleteForwardingHistoryResponse, error)
+	// lncli: `aggregatefwdhistory`
+	//AggregateForwardingHistory returns the number of forwarded HTLCs, the
+	//forwarded volume and the fees earned within a time range, aggregated per
+	//channel, per peer or per hourly or daily time bucket.
+	AggregateForwardingHistory(ctx context.Context, in *AggregateForwardingHistoryRequest, opts ...grpc.CallOption) (*AggregateForwardingHistoryResponse, error)
+	// lncli: `rebalance`
+	// Rebalance moves funds into one of our channels by paying ourselves along a
+	// circular route that leaves through one of our other channels and returns
+	// through the given one. The fee of the rebalance is limited by the
+	// requested or configured maximum fee and by the daily rebalance budget. A
+	// rebalance for which no route succeeded is returned as a failed result.
+	Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (*RebalanceResult, error)
+	// lncli: `setrebalancetargets`
+	// SetRebalanceTargets sets the local balances we'd like our channels to
+	// have. If automatic rebalancing is active, the channels that deviate from
+	// their targets are rebalanced periodically.
+	SetRebalanceTargets(ctx context.Context, in *SetRebalanceTargetsRequest, opts ...grpc.CallOption) (*SetRebalanceTargetsResponse, error)
+	// lncli: `listrebalances`
+	// ListRebalances returns the rebalances made since the given time together
+	// with the fees spent on rebalancing within the last day.
+	ListRebalances(ctx context.Context, in *ListRebalancesRequest, opts ...grpc.CallOption) (*ListRebalancesResponse, error)
+	// SubscribeRebalances returns a stream of status updates of the rebalances
+	// that are made, whether they were requested or made automatically.
+	SubscribeRebalances(ctx context.Context, in *SubscribeRebalancesRequest, opts ...grpc.CallOption) (Router_SubscribeRebalancesClient, error)
 }
 
 type routerClient struct {
@@ -164,8 +210,40 @@ func (x *routerSendPaymentV2Client) Recv() (*lnrpc.Payment, error) {
 	return m, nil
 }
 
+func (c *routerClient) PayOffer(ctx context.Context, in *PayOfferRequest, opts ...grpc.CallOption) (Router_PayOfferClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[1], "/routerrpc.Router/PayOffer", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &routerPayOfferClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Router_PayOfferClient interface {
+	Recv() (*lnrpc.Payment, error)
+	grpc.ClientStream
+}
+
+type routerPayOfferClient struct {
+	grpc.ClientStream
+}
+
+func (x *routerPayOfferClient) Recv() (*lnrpc.Payment, error) {
+	m := new(lnrpc.Payment)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *routerClient) TrackPaymentV2(ctx context.Context, in *TrackPaymentRequest, opts ...grpc.CallOption) (Router_TrackPaymentV2Client, error) {
-	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[1], "/routerrpc.Router/TrackPaymentV2", opts...)
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[2], "/routerrpc.Router/TrackPaymentV2", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -197,7 +275,7 @@ func (x *routerTrackPaymentV2Client) Recv() (*lnrpc.Payment, error) {
 }
 
 func (c *routerClient) TrackPayments(ctx context.Context, in *TrackPaymentsRequest, opts ...grpc.CallOption) (Router_TrackPaymentsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[2], "/routerrpc.Router/TrackPayments", opts...)
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[3], "/routerrpc.Router/TrackPayments", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -310,7 +388,7 @@ func (c *routerClient) BuildRoute(ctx context.Context, in *BuildRouteRequest, op
 }
 
 func (c *routerClient) SubscribeHtlcEvents(ctx context.Context, in *SubscribeHtlcEventsRequest, opts ...grpc.CallOption) (Router_SubscribeHtlcEventsClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[3], "/routerrpc.Router/SubscribeHtlcEvents", opts...)
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[4], "/routerrpc.Router/SubscribeHtlcEvents", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -342,7 +420,7 @@ func (x *routerSubscribeHtlcEventsClient) Recv() (*HtlcEvent, error) {
 }
 
 func (c *routerClient) HtlcInterceptor(ctx context.Context, opts ...grpc.CallOption) (Router_HtlcInterceptorClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[4], "/routerrpc.Router/HtlcInterceptor", opts...)
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[5], "/routerrpc.Router/HtlcInterceptor", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -372,6 +450,68 @@ func (x *routerHtlcInterceptorClient) Recv() (*ForwardHtlcInterceptRequest, erro
 	return m, nil
 }
 
+func (c *routerClient) RegisterHtlcInterceptor(ctx context.Context, opts ...grpc.CallOption) (Router_RegisterHtlcInterceptorClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[6], "/routerrpc.Router/RegisterHtlcInterceptor", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &routerRegisterHtlcInterceptorClient{stream}
+	return x, nil
+}
+
+type Router_RegisterHtlcInterceptorClient interface {
+	Send(*HtlcInterceptorMessage) error
+	Recv() (*ForwardHtlcInterceptRequest, error)
+	grpc.ClientStream
+}
+
+type routerRegisterHtlcInterceptorClient struct {
+	grpc.ClientStream
+}
+
+func (x *routerRegisterHtlcInterceptorClient) Send(m *HtlcInterceptorMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *routerRegisterHtlcInterceptorClient) Recv() (*ForwardHtlcInterceptRequest, error) {
+	m := new(ForwardHtlcInterceptRequest)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *routerClient) RegisterProbabilityEstimator(ctx context.Context, opts ...grpc.CallOption) (Router_RegisterProbabilityEstimatorClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[7], "/routerrpc.Router/RegisterProbabilityEstimator", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &routerRegisterProbabilityEstimatorClient{stream}
+	return x, nil
+}
+
+type Router_RegisterProbabilityEstimatorClient interface {
+	Send(*ProbabilityEstimateResponse) error
+	Recv() (*ProbabilityEstimateRequest, error)
+	grpc.ClientStream
+}
+
+type routerRegisterProbabilityEstimatorClient struct {
+	grpc.ClientStream
+}
+
+func (x *routerRegisterProbabilityEstimatorClient) Send(m *ProbabilityEstimateResponse) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *routerRegisterProbabilityEstimatorClient) Recv() (*ProbabilityEstimateRequest, error) {
+	m := new(ProbabilityEstimateRequest)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *routerClient) UpdateChanStatus(ctx context.Context, in *UpdateChanStatusRequest, opts ...grpc.CallOption) (*UpdateChanStatusResponse, error) {
 	out := new(UpdateChanStatusResponse)
 	err := c.cc.Invoke(ctx, "/routerrpc.Router/UpdateChanStatus", in, out, opts...)
@@ -417,6 +557,74 @@ func (c *routerClient) DeleteForwardingHistory(ctx context.Context, in *DeleteFo
 	return out, nil
 }
 
+func (c *routerClient) AggregateForwardingHistory(ctx context.Context, in *AggregateForwardingHistoryRequest, opts ...grpc.CallOption) (*AggregateForwardingHistoryResponse, error) {
+	out := new(AggregateForwardingHistoryResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/AggregateForwardingHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (*RebalanceResult, error) {
+	out := new(RebalanceResult)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/Rebalance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) SetRebalanceTargets(ctx context.Context, in *SetRebalanceTargetsRequest, opts ...grpc.CallOption) (*SetRebalanceTargetsResponse, error) {
+	out := new(SetRebalanceTargetsResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/SetRebalanceTargets", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) ListRebalances(ctx context.Context, in *ListRebalancesRequest, opts ...grpc.CallOption) (*ListRebalancesResponse, error) {
+	out := new(ListRebalancesResponse)
+	err := c.cc.Invoke(ctx, "/routerrpc.Router/ListRebalances", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *routerClient) SubscribeRebalances(ctx context.Context, in *SubscribeRebalancesRequest, opts ...grpc.CallOption) (Router_SubscribeRebalancesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Router_ServiceDesc.Streams[8], "/routerrpc.Router/SubscribeRebalances", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &routerSubscribeRebalancesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Router_SubscribeRebalancesClient interface {
+	Recv() (*RebalanceEvent, error)
+	grpc.ClientStream
+}
+
+type routerSubscribeRebalancesClient struct {
+	grpc.ClientStream
+}
+
+func (x *routerSubscribeRebalancesClient) Recv() (*RebalanceEvent, error) {
+	m := new(RebalanceEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // RouterServer is the server API for Router service.
 // All implementations must embed UnimplementedRouterServer
 // for forward compatibility
@@ -428,6 +636,12 @@ type RouterServer interface {
 	// routes without fees will be attempted which often fails with
 	// FAILURE_REASON_NO_ROUTE.
 	SendPaymentV2(*SendPaymentRequest, Router_SendPaymentV2Server) error
+	// PayOffer pays a BOLT 12 offer. It requests an invoice for the offer from
+	// its issuer over onion messages under a fresh payer key and pays it along
+	// the blinded paths of the invoice. The call returns a stream of payment
+	// updates, just like SendPaymentV2. When using this RPC, make sure to set a
+	// fee limit, as the default routing fee limit is 0 sats.
+	PayOffer(*PayOfferRequest, Router_PayOfferServer) error
 	// lncli: `trackpayment`
 	// TrackPaymentV2 returns an update stream for the payment identified by the
 	// payment hash.
@@ -494,6 +708,22 @@ type RouterServer interface {
 	// In case of interception, the htlc can be either settled, cancelled or
 	// resumed later by using the ResolveHoldForward endpoint.
 	HtlcInterceptor(Router_HtlcInterceptorServer) error
+	// RegisterHtlcInterceptor dispatches a bi-directional streaming RPC that
+	// registers a named interceptor. Several interceptors can be registered at
+	// the same time, next to the one registered through HtlcInterceptor. Every
+	// forwarded htlc is offered to them one after another, in order of their
+	// priority. Resuming a htlc passes it on to the next interceptor, while
+	// settling or failing it is final. The first message sent by the client must
+	// be the registration.
+	RegisterHtlcInterceptor(Router_RegisterHtlcInterceptorServer) error
+	// RegisterProbabilityEstimator dispatches a bi-directional streaming RPC that
+	// registers an out-of-process probability estimator. While the external
+	// estimator is selected in the mission control config, pathfinding sends
+	// batches of node pairs to the client, which answers each batch with the
+	// success probabilities of its pairs. Pairs that aren't answered in time are
+	// estimated by the apriori estimator instead. Only one estimator can be
+	// registered at a time.
+	RegisterProbabilityEstimator(Router_RegisterProbabilityEstimatorServer) error
 	// lncli: `updatechanstatus`
 	// UpdateChanStatus attempts to manually set the state of a channel
 	// (enabled, disabled, or auto). A manual "disable" request will cause the
@@ -524,6 +754,30 @@ type RouterServer interface {
 	// deletion is performed in a transaction-safe manner with configurable batch
 	// sizes to avoid holding large database locks.
 	DeleteForwardingHistory(context.Context, *DeleteForwardingHistoryRequest) (*DeleteForwardingHistoryResponse, error)
+	// lncli: `aggregatefwdhistory`
+	//AggregateForwardingHistory returns the number of forwarded HTLCs, the
+	//forwarded volume and the fees earned within a time range, aggregated per
+	//channel, per peer or per hourly or daily time bucket.
+	AggregateForwardingHistory(context.Context, *AggregateForwardingHistoryRequest) (*AggregateForwardingHistoryResponse, error)
+	// lncli: `rebalance`
+	// Rebalance moves funds into one of our channels by paying ourselves along a
+	// circular route that leaves through one of our other channels and returns
+	// through the given one. The fee of the rebalance is limited by the
+	// requested or configured maximum fee and by the daily rebalance budget. A
+	// rebalance for which no route succeeded is returned as a failed result.
+	Rebalance(context.Context, *RebalanceRequest) (*RebalanceResult, error)
+	// lncli: `setrebalancetargets`
+	// SetRebalanceTargets sets the local balances we'd like our channels to
+	// have. If automatic rebalancing is active, the channels that deviate from
+	// their targets are rebalanced periodically.
+	SetRebalanceTargets(context.Context, *SetRebalanceTargetsRequest) (*SetRebalanceTargetsResponse, error)
+	// lncli: `listrebalances`
+	// ListRebalances returns the rebalances made since the given time together
+	// with the fees spent on rebalancing within the last day.
+	ListRebalances(context.Context, *ListRebalancesRequest) (*ListRebalancesResponse, error)
+	// SubscribeRebalances returns a stream of status updates of the rebalances
+	// that are made, whether they were requested or made automatically.
+	SubscribeRebalances(*SubscribeRebalancesRequest, Router_SubscribeRebalancesServer) error
 	mustEmbedUnimplementedRouterServer()
 }
 
@@ -534,6 +788,9 @@ type UnimplementedRouterServer struct {
 func (UnimplementedRouterServer) SendPaymentV2(*SendPaymentRequest, Router_SendPaymentV2Server) error {
 	return status.Errorf(codes.Unimplemented, "method SendPaymentV2 not implemented")
 }
+func (UnimplementedRouterServer) PayOffer(*PayOfferRequest, Router_PayOfferServer) error {
+	return status.Errorf(codes.Unimplemented, "method PayOffer not implemented")
+}
 func (UnimplementedRouterServer) TrackPaymentV2(*TrackPaymentRequest, Router_TrackPaymentV2Server) error {
 	return status.Errorf(codes.Unimplemented, "method TrackPaymentV2 not implemented")
 }
@@ -573,6 +830,12 @@ func (UnimplementedRouterServer) SubscribeHtlcEvents(*SubscribeHtlcEventsRequest
 func (UnimplementedRouterServer) HtlcInterceptor(Router_HtlcInterceptorServer) error {
 	return status.Errorf(codes.Unimplemented, "method HtlcInterceptor not implemented")
 }
+func (UnimplementedRouterServer) RegisterHtlcInterceptor(Router_RegisterHtlcInterceptorServer) error {
+	return status.Errorf(codes.Unimplemented, "method RegisterHtlcInterceptor not implemented")
+}
+func (UnimplementedRouterServer) RegisterProbabilityEstimator(Router_RegisterProbabilityEstimatorServer) error {
+	return status.Errorf(codes.Unimplemented, "method RegisterProbabilityEstimator not implemented")
+}
 func (UnimplementedRouterServer) UpdateChanStatus(context.Context, *UpdateChanStatusRequest) (*UpdateChanStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateChanStatus not implemented")
 }
@@ -588,6 +851,21 @@ func (UnimplementedRouterServer) XFindBaseLocalChanAlias(context.Context, *FindB
 func (UnimplementedRouterServer) DeleteForwardingHistory(context.Context, *DeleteForwardingHistoryRequest) (*DeleteForwardingHistoryResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteForwardingHistory not implemented")
 }
+func (UnimplementedRouterServer) AggregateForwardingHistory(context.Context, *AggregateForwardingHistoryRequest) (*AggregateForwardingHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AggregateForwardingHistory not implemented")
+}
+func (UnimplementedRouterServer) Rebalance(context.Context, *RebalanceRequest) (*RebalanceResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rebalance not implemented")
+}
+func (UnimplementedRouterServer) SetRebalanceTargets(context.Context, *SetRebalanceTargetsRequest) (*SetRebalanceTargetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRebalanceTargets not implemented")
+}
+func (UnimplementedRouterServer) ListRebalances(context.Context, *ListRebalancesRequest) (*ListRebalancesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRebalances not implemented")
+}
+func (UnimplementedRouterServer) SubscribeRebalances(*SubscribeRebalancesRequest, Router_SubscribeRebalancesServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeRebalances not implemented")
+}
 func (UnimplementedRouterServer) mustEmbedUnimplementedRouterServer() {}
 
 // UnsafeRouterServer may be embedded to opt out of forward compatibility for this service.
@@ -622,6 +900,27 @@ func (x *routerSendPaymentV2Server) Send(m *lnrpc.Payment) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Router_PayOffer_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PayOfferRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RouterServer).PayOffer(m, &routerPayOfferServer{stream})
+}
+
+type Router_PayOfferServer interface {
+	Send(*lnrpc.Payment) error
+	grpc.ServerStream
+}
+
+type routerPayOfferServer struct {
+	grpc.ServerStream
+}
+
+func (x *routerPayOfferServer) Send(m *lnrpc.Payment) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 func _Router_TrackPaymentV2_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(TrackPaymentRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -873,6 +1172,58 @@ func (x *routerHtlcInterceptorServer) Recv() (*ForwardHtlcInterceptResponse, err
 	return m, nil
 }
 
+func _Router_RegisterHtlcInterceptor_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RouterServer).RegisterHtlcInterceptor(&routerRegisterHtlcInterceptorServer{stream})
+}
+
+type Router_RegisterHtlcInterceptorServer interface {
+	Send(*ForwardHtlcInterceptRequest) error
+	Recv() (*HtlcInterceptorMessage, error)
+	grpc.ServerStream
+}
+
+type routerRegisterHtlcInterceptorServer struct {
+	grpc.ServerStream
+}
+
+func (x *routerRegisterHtlcInterceptorServer) Send(m *ForwardHtlcInterceptRequest) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *routerRegisterHtlcInterceptorServer) Recv() (*HtlcInterceptorMessage, error) {
+	m := new(HtlcInterceptorMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Router_RegisterProbabilityEstimator_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RouterServer).RegisterProbabilityEstimator(&routerRegisterProbabilityEstimatorServer{stream})
+}
+
+type Router_RegisterProbabilityEstimatorServer interface {
+	Send(*ProbabilityEstimateRequest) error
+	Recv() (*ProbabilityEstimateResponse, error)
+	grpc.ServerStream
+}
+
+type routerRegisterProbabilityEstimatorServer struct {
+	grpc.ServerStream
+}
+
+func (x *routerRegisterProbabilityEstimatorServer) Send(m *ProbabilityEstimateRequest) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *routerRegisterProbabilityEstimatorServer) Recv() (*ProbabilityEstimateResponse, error) {
+	m := new(ProbabilityEstimateResponse)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Router_UpdateChanStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateChanStatusRequest)
 	if err := dec(in); err != nil {
@@ -963,6 +1314,99 @@ func _Router_DeleteForwardingHistory_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Router_AggregateForwardingHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AggregateForwardingHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).AggregateForwardingHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/AggregateForwardingHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).AggregateForwardingHistory(ctx, req.(*AggregateForwardingHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_Rebalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).Rebalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/Rebalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).Rebalance(ctx, req.(*RebalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_SetRebalanceTargets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRebalanceTargetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).SetRebalanceTargets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/SetRebalanceTargets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).SetRebalanceTargets(ctx, req.(*SetRebalanceTargetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_ListRebalances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRebalancesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RouterServer).ListRebalances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/routerrpc.Router/ListRebalances",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RouterServer).ListRebalances(ctx, req.(*ListRebalancesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Router_SubscribeRebalances_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRebalancesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RouterServer).SubscribeRebalances(m, &routerSubscribeRebalancesServer{stream})
+}
+
+type Router_SubscribeRebalancesServer interface {
+	Send(*RebalanceEvent) error
+	grpc.ServerStream
+}
+
+type routerSubscribeRebalancesServer struct {
+	grpc.ServerStream
+}
+
+func (x *routerSubscribeRebalancesServer) Send(m *RebalanceEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Router_ServiceDesc is the grpc.ServiceDesc for Router service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1026,6 +1470,22 @@ var Router_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteForwardingHistory",
 			Handler:    _Router_DeleteForwardingHistory_Handler,
 		},
+		{
+			MethodName: "AggregateForwardingHistory",
+			Handler:    _Router_AggregateForwardingHistory_Handler,
+		},
+		{
+			MethodName: "Rebalance",
+			Handler:    _Router_Rebalance_Handler,
+		},
+		{
+			MethodName: "SetRebalanceTargets",
+			Handler:    _Router_SetRebalanceTargets_Handler,
+		},
+		{
+			MethodName: "ListRebalances",
+			Handler:    _Router_ListRebalances_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1033,6 +1493,11 @@ var Router_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Router_SendPaymentV2_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "PayOffer",
+			Handler:       _Router_PayOffer_Handler,
+			ServerStreams: true,
+		},
 		{
 			StreamName:    "TrackPaymentV2",
 			Handler:       _Router_TrackPaymentV2_Handler,
@@ -1054,6 +1519,23 @@ var Router_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "RegisterHtlcInterceptor",
+			Handler:       _Router_RegisterHtlcInterceptor_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "RegisterProbabilityEstimator",
+			Handler:       _Router_RegisterProbabilityEstimator_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "SubscribeRebalances",
+			Handler:       _Router_SubscribeRebalances_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "routerrpc/router.proto",
 }
@@ -11,14 +11,18 @@ import (
 	"time"
 
 	"github.com/flokiorg/flnd/aliasmgr"
+	"github.com/flokiorg/flnd/bolt12"
+	"github.com/flokiorg/flnd/channeldb"
 	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/lnrpc"
 	"github.com/flokiorg/flnd/lnrpc/invoicesrpc"
 	"github.com/flokiorg/flnd/lntypes"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/macaroons"
+	"github.com/flokiorg/flnd/offers"
 	paymentsdb "github.com/flokiorg/flnd/payments/db"
 	"github.com/flokiorg/flnd/routing"
+	"github.com/flokiorg/flnd/routing/rebalance"
 	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/flnd/zpay32"
 	"github.com/flokiorg/go-flokicoin/chainutil"
@@ -44,6 +48,10 @@ const (
 	// when attempting to fulfill the payment.
 	DefaultPaymentTimeout int32 = 60
 
+	// DefaultInvoiceRequestTimeout is the default number of seconds we
+	// wait for the issuer of an offer to answer our invoice request.
+	DefaultInvoiceRequestTimeout int32 = 30
+
 	// MaxLspsToProbe is the maximum number of LSPs to probe when
 	// estimating fees for worst-case fee estimation. This is a
 	// precautionary measure to prevent the estimation from taking too
@@ -92,6 +100,10 @@ var (
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/routerrpc.Router/PayOffer": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
 		"/routerrpc.Router/SendToRouteV2": {{
 			Entity: "offchain",
 			Action: "write",
@@ -144,6 +156,14 @@ var (
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/routerrpc.Router/RegisterHtlcInterceptor": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/routerrpc.Router/RegisterProbabilityEstimator": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
 		"/routerrpc.Router/UpdateChanStatus": {{
 			Entity: "offchain",
 			Action: "write",
@@ -160,6 +180,26 @@ var (
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/routerrpc.Router/AggregateForwardingHistory": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
+		"/routerrpc.Router/Rebalance": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/routerrpc.Router/SetRebalanceTargets": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/routerrpc.Router/ListRebalances": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
+		"/routerrpc.Router/SubscribeRebalances": {{
+			Entity: "offchain",
+			Action: "read",
+		}},
 	}
 
 	// DefaultRouterMacFilename is the default name of the router macaroon
@@ -359,6 +399,77 @@ func (s *Server) SendPaymentV2(req *SendPaymentRequest,
 		return err
 	}
 
+	return s.sendPayment(
+		payment, stream, req.Cancelable, req.NoInflightUpdates,
+	)
+}
+
+// PayOffer pays a BOLT 12 offer. It requests an invoice for the offer from
+// its issuer over onion messages and pays it along the blinded paths of the
+// invoice. The call returns a stream of payment updates.
+func (s *Server) PayOffer(req *PayOfferRequest,
+	stream Router_PayOfferServer) error {
+
+	fetchInvoice := s.cfg.RouterBackend.FetchOfferInvoice
+	if fetchInvoice == nil {
+		return status.Error(codes.Unimplemented, "offers require "+
+			"onion messages to be enabled")
+	}
+
+	offer, err := bolt12.DecodeOfferString(req.Offer)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid offer: "+
+			"%v", err)
+	}
+
+	if req.AmtMsat < 0 {
+		return status.Error(codes.InvalidArgument, "amount must not "+
+			"be negative")
+	}
+
+	// Set payment request attempt timeout.
+	if req.TimeoutSeconds == 0 {
+		req.TimeoutSeconds = DefaultPaymentTimeout
+	}
+
+	invoiceTimeout := req.InvoiceTimeoutSeconds
+	if invoiceTimeout == 0 {
+		invoiceTimeout = DefaultInvoiceRequestTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(
+		stream.Context(), time.Duration(invoiceTimeout)*time.Second,
+	)
+	defer cancel()
+
+	inv, err := fetchInvoice(ctx, &offers.FetchInvoiceParams{
+		Offer:      offer,
+		AmountMsat: lnwire.MilliLoki(req.AmtMsat),
+		Quantity:   req.Quantity,
+		PayerNote:  req.PayerNote,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to fetch invoice for offer: %w", err)
+	}
+
+	payment, err := s.cfg.RouterBackend.extractIntentFromPayOfferRequest(
+		req, inv,
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.sendPayment(
+		payment, stream, req.Cancelable, req.NoInflightUpdates,
+	)
+}
+
+// sendPayment initializes the payment in the db, dispatches it and streams
+// its updates back to the caller until it reaches a final state.
+func (s *Server) sendPayment(payment *routing.LightningPayment,
+	stream Router_SendPaymentV2Server, cancelable,
+	noInflightUpdates bool) error {
+
 	// Get the payment hash.
 	payHash := payment.Identifier()
 
@@ -398,7 +509,7 @@ func (s *Server) SendPaymentV2(req *SendPaymentRequest,
 	// user provided 'cancelable' and ends the stream before the timeout is
 	// reached the payment will be canceled.
 	ctx := context.Background()
-	if req.Cancelable {
+	if cancelable {
 		ctx = stream.Context()
 	}
 
@@ -406,7 +517,7 @@ func (s *Server) SendPaymentV2(req *SendPaymentRequest,
 	s.cfg.Router.SendPaymentAsync(ctx, payment, paySession, shardTracker)
 
 	// Track the payment and return.
-	return s.trackPayment(sub, payHash, stream, req.NoInflightUpdates)
+	return s.trackPayment(sub, payHash, stream, noInflightUpdates)
 }
 
 // EstimateRouteFee allows callers to obtain an expected value w.r.t how much it
@@ -470,13 +581,22 @@ func (s *Server) probeDestination(dest []byte, amtSat int64,
 	// restriction for the default CLTV limit, otherwise we can find a route
 	// that exceeds it and is useless to us.
 	backend := s.cfg.RouterBackend
-	mc := backend.MissionControl
+	getProbability := backend.MissionControl.GetProbability
+	var awaitEstimates func() bool
+	rounder, ok := backend.MissionControl.(routing.EstimateRounder)
+	if ok {
+		round := rounder.NewEstimateRound()
+		getProbability = round.GetProbability
+		awaitEstimates = round.AwaitEstimates
+	}
+
 	routeReq, err := routing.NewRouteRequest(
 		backend.SelfNode, &destNode, amtMsat, 0,
 		&routing.RestrictParams{
 			FeeLimit:           routeFeeLimitSat,
 			CltvLimit:          backend.MaxTotalTimelock,
-			ProbabilitySource:  mc.GetProbability,
+			ProbabilitySource:  getProbability,
+			AwaitEstimates:     awaitEstimates,
 			OutgoingChannelIDs: outgoingChanIDs,
 		}, nil, nil, nil, backend.DefaultFinalCltvDelta,
 	)
@@ -1215,6 +1335,29 @@ func (s *Server) GetMissionControlConfig(ctx context.Context,
 			Bimodal: &bCfg,
 		}
 
+	case routing.ExternalConfig:
+		resp.Config.Model = MissionControlConfig_EXTERNAL
+		fallback := v.FallbackConfig
+		eCfg := ExternalParameters{
+			TimeoutMs: uint64(v.ExternalTimeout.Milliseconds()),
+			CacheTtlSeconds: uint64(
+				v.ExternalCacheTTL.Seconds(),
+			),
+			Fallback: &AprioriParameters{
+				HalfLifeSeconds: uint64(
+					fallback.PenaltyHalfLife.Seconds(),
+				),
+				HopProbability: fallback.
+					AprioriHopProbability,
+				Weight:           fallback.AprioriWeight,
+				CapacityFraction: fallback.CapacityFraction,
+			},
+		}
+
+		resp.Config.EstimatorConfig = &MissionControlConfig_External{
+			External: &eCfg,
+		}
+
 	default:
 		return nil, fmt.Errorf("unknown estimator config type %T", v)
 	}
@@ -1245,6 +1388,10 @@ func (s *Server) SetMissionControlConfig(ctx context.Context,
 			return nil, fmt.Errorf("bimodal config " +
 				"provided, but apriori model requested")
 
+		case *MissionControlConfig_External:
+			return nil, fmt.Errorf("external config " +
+				"provided, but apriori model requested")
+
 		case *MissionControlConfig_Apriori:
 			aprioriConfig = routing.AprioriConfig{
 				PenaltyHalfLife: time.Duration(
@@ -1298,6 +1445,48 @@ func (s *Server) SetMissionControlConfig(ctx context.Context,
 		}
 		mcCfg.Estimator = estimator
 
+	case MissionControlConfig_EXTERNAL:
+		cfg, ok := req.Config.
+			EstimatorConfig.(*MissionControlConfig_External)
+		if !ok {
+			return nil, fmt.Errorf("external estimator requested " +
+				"but corresponding config not set")
+		}
+		eCfg := cfg.External
+
+		// Without fallback parameters, the defaults are used.
+		fallbackConfig := routing.DefaultAprioriConfig()
+		if eCfg.Fallback != nil {
+			fallbackConfig = routing.AprioriConfig{
+				PenaltyHalfLife: time.Duration(
+					eCfg.Fallback.HalfLifeSeconds,
+				) * time.Second,
+				AprioriHopProbability: eCfg.Fallback.
+					HopProbability,
+				AprioriWeight: eCfg.Fallback.Weight,
+				CapacityFraction: eCfg.Fallback.
+					CapacityFraction,
+			}
+		}
+
+		externalConfig := routing.ExternalConfig{
+			ExternalTimeout: time.Duration(
+				eCfg.TimeoutMs,
+			) * time.Millisecond,
+			ExternalCacheTTL: time.Duration(
+				eCfg.CacheTtlSeconds,
+			) * time.Second,
+			FallbackConfig: fallbackConfig,
+		}
+
+		estimator, err := routing.NewExternalEstimator(
+			externalConfig, s.cfg.RouterBackend.ProbabilityService,
+		)
+		if err != nil {
+			return nil, err
+		}
+		mcCfg.Estimator = estimator
+
 	default:
 		return nil, fmt.Errorf("unknown estimator type %v",
 			req.Config.Model)
@@ -1822,6 +2011,39 @@ func (s *Server) HtlcInterceptor(stream Router_HtlcInterceptorServer) error {
 	).run()
 }
 
+// RegisterHtlcInterceptor is a bidirectional stream that registers a named
+// interceptor in the chain of interceptors every forwarded htlc is offered to.
+// The first message of the client must be the registration, after which it
+// receives the htlcs offered to it and resolves them.
+func (s *Server) RegisterHtlcInterceptor(
+	stream Router_RegisterHtlcInterceptorServer) error {
+
+	interceptor, err := newNamedForwardInterceptor(
+		s.cfg.RouterBackend.InterceptableForwarder, stream,
+	)
+	if err != nil {
+		return err
+	}
+
+	return interceptor.run()
+}
+
+// RegisterProbabilityEstimator is a bidirectional stream that registers an
+// out-of-process probability estimator. The client receives batches of node
+// pairs and answers them with their success probabilities.
+func (s *Server) RegisterProbabilityEstimator(
+	stream Router_RegisterProbabilityEstimatorServer) error {
+
+	estimator, err := newProbabilityEstimator(
+		s.cfg.RouterBackend.ProbabilityService, stream, s.quit,
+	)
+	if err != nil {
+		return err
+	}
+
+	return estimator.run()
+}
+
 // XAddLocalChanAliases is an experimental API that creates a set of new
 // channel SCID alias mappings. The final total set of aliases in the manager
 // after the add operation is returned. This is only a locally stored alias, and
@@ -2091,3 +2313,360 @@ func (s *Server) DeleteForwardingHistory(ctx context.Context,
 			stats.NumEventsDeleted),
 	}, nil
 }
+
+// AggregateForwardingHistory returns the number of forwarded HTLCs, the
+// forwarded volume and the fees earned within a time range, aggregated per
+// channel, per peer or per hourly or daily time bucket.
+func (s *Server) AggregateForwardingHistory(ctx context.Context,
+	req *AggregateForwardingHistoryRequest) (
+	*AggregateForwardingHistoryResponse, error) {
+
+	// If the end time wasn't specified, we'll aggregate all events up to
+	// now.
+	startTime := time.Unix(int64(req.StartTime), 0)
+	endTime := s.cfg.RouterBackend.Clock.Now()
+	if req.EndTime != 0 {
+		endTime = time.Unix(int64(req.EndTime), 0)
+	}
+	if endTime.Before(startTime) {
+		return nil, fmt.Errorf("end time %v is before start time %v",
+			endTime, startTime)
+	}
+
+	query := channeldb.ForwardingAggregateQuery{
+		StartTime: startTime,
+		EndTime:   endTime,
+	}
+	switch req.GroupBy {
+	case ForwardingGroupBy_GROUP_BY_CHANNEL:
+		query.GroupBy = channeldb.ForwardingGroupChannel
+
+	case ForwardingGroupBy_GROUP_BY_PEER:
+		query.GroupBy = channeldb.ForwardingGroupPeer
+
+	case ForwardingGroupBy_GROUP_BY_HOUR:
+		query.GroupBy = channeldb.ForwardingGroupTime
+		query.BucketSize = time.Hour
+
+	case ForwardingGroupBy_GROUP_BY_DAY:
+		query.GroupBy = channeldb.ForwardingGroupTime
+		query.BucketSize = 24 * time.Hour
+
+	default:
+		return nil, fmt.Errorf("unknown group by: %v", req.GroupBy)
+	}
+
+	aggregates, err := s.cfg.RouterBackend.ForwardingLog.
+		AggregateForwardingEvents(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to aggregate forwarding "+
+			"events: %w", err)
+	}
+
+	resp := &AggregateForwardingHistoryResponse{
+		Aggregates: make([]*ForwardingAggregate, 0, len(aggregates)),
+	}
+	for _, aggregate := range aggregates {
+		rpcAggregate := &ForwardingAggregate{
+			Incoming: marshallForwardingStats(aggregate.Incoming),
+			Outgoing: marshallForwardingStats(aggregate.Outgoing),
+		}
+
+		// Only the key of the requested group is set.
+		switch query.GroupBy {
+		case channeldb.ForwardingGroupChannel:
+			rpcAggregate.ChanId = aggregate.ChanID.ToUint64()
+
+		case channeldb.ForwardingGroupPeer:
+			rpcAggregate.PeerPubkey = aggregate.Peer.String()
+
+		case channeldb.ForwardingGroupTime:
+			rpcAggregate.BucketStart = uint64(
+				aggregate.BucketStart.Unix(),
+			)
+		}
+
+		resp.Aggregates = append(resp.Aggregates, rpcAggregate)
+	}
+
+	return resp, nil
+}
+
+// marshallForwardingStats converts the totals of a set of forwarding events
+// into their RPC counterpart.
+func marshallForwardingStats(
+	stats channeldb.ForwardingStats) *ForwardingStats {
+
+	return &ForwardingStats{
+		NumEvents:  stats.NumEvents,
+		AmtInMsat:  uint64(stats.AmtIn),
+		AmtOutMsat: uint64(stats.AmtOut),
+		FeeMsat:    uint64(stats.Fee()),
+	}
+}
+
+// Rebalance moves funds into one of our channels by paying ourselves along a
+// circular route that leaves through one of our other channels.
+func (s *Server) Rebalance(ctx context.Context,
+	req *RebalanceRequest) (*RebalanceResult, error) {
+
+	if req.AmtSat <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must "+
+			"be positive")
+	}
+	if req.MaxFeeSat < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max fee must "+
+			"not be negative")
+	}
+
+	outgoing := make([]lnwire.ShortChannelID, 0, len(req.OutgoingChanIds))
+	for _, chanID := range req.OutgoingChanIds {
+		outgoing = append(
+			outgoing, lnwire.NewShortChanIDFromInt(chanID),
+		)
+	}
+
+	result, err := s.cfg.RouterBackend.Rebalancer.Rebalance(
+		ctx, &rebalance.Request{
+			OutgoingChannels: outgoing,
+			IncomingChannel: lnwire.NewShortChanIDFromInt(
+				req.IncomingChanId,
+			),
+			Amount: chainutil.Amount(req.AmtSat),
+			MaxFee: chainutil.Amount(req.MaxFeeSat),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshallRebalanceResult(result), nil
+}
+
+// SetRebalanceTargets sets the local balances we'd like our channels to have.
+// Targets without a goal remove the target of their channel.
+func (s *Server) SetRebalanceTargets(_ context.Context,
+	req *SetRebalanceTargetsRequest) (*SetRebalanceTargetsResponse,
+	error) {
+
+	var (
+		targets []*rebalance.Target
+		remove  []lnwire.ShortChannelID
+	)
+	for _, rpcTarget := range req.Targets {
+		chanID := lnwire.NewShortChanIDFromInt(rpcTarget.ChanId)
+		target := &rebalance.Target{
+			ChanID: chanID,
+		}
+
+		switch goal := rpcTarget.Target.(type) {
+		case *RebalanceTarget_LocalRatio:
+			target.LocalRatio = fn.Some(goal.LocalRatio)
+
+		case *RebalanceTarget_LocalBalanceSat:
+			if goal.LocalBalanceSat < 0 {
+				return nil, status.Errorf(codes.InvalidArgument,
+					"local balance of channel %v must not "+
+						"be negative", chanID)
+			}
+
+			target.LocalBalance = fn.Some(
+				chainutil.Amount(goal.LocalBalanceSat),
+			)
+
+		default:
+			remove = append(remove, chanID)
+			continue
+		}
+
+		targets = append(targets, target)
+	}
+
+	updated, err := s.cfg.RouterBackend.Rebalancer.SetTargets(
+		targets, remove, req.Replace,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &SetRebalanceTargetsResponse{
+		Targets: make([]*RebalanceTarget, 0, len(updated)),
+	}
+	for _, target := range updated {
+		resp.Targets = append(
+			resp.Targets, marshallRebalanceTarget(target),
+		)
+	}
+
+	return resp, nil
+}
+
+// ListRebalances returns the rebalances made since the given time together
+// with the fees spent on rebalancing within the last day.
+func (s *Server) ListRebalances(_ context.Context,
+	req *ListRebalancesRequest) (*ListRebalancesResponse, error) {
+
+	rebalancer := s.cfg.RouterBackend.Rebalancer
+
+	var since time.Time
+	if req.StartTime != 0 {
+		since = time.Unix(int64(req.StartTime), 0)
+	}
+
+	results, err := rebalancer.Results(since)
+	if err != nil {
+		return nil, err
+	}
+
+	spent, err := rebalancer.SpentFees()
+	if err != nil {
+		return nil, err
+	}
+
+	budget := lnwire.NewMSatFromLokis(rebalancer.DailyBudget())
+	resp := &ListRebalancesResponse{
+		Rebalances:      make([]*RebalanceResult, 0, len(results)),
+		FeesSpentMsat:   int64(spent),
+		DailyBudgetMsat: int64(budget),
+	}
+	for _, result := range results {
+		resp.Rebalances = append(
+			resp.Rebalances, marshallRebalanceResult(result),
+		)
+	}
+
+	return resp, nil
+}
+
+// SubscribeRebalances returns a stream of status updates of the rebalances
+// that are made.
+func (s *Server) SubscribeRebalances(_ *SubscribeRebalancesRequest,
+	stream Router_SubscribeRebalancesServer) error {
+
+	client, err := s.cfg.RouterBackend.Rebalancer.SubscribeEvents()
+	if err != nil {
+		return err
+	}
+	defer client.Cancel()
+
+	for {
+		select {
+		case event := <-client.Updates():
+			rpcEvent, err := marshallRebalanceEvent(event)
+			if err != nil {
+				return err
+			}
+
+			if err := stream.Send(rpcEvent); err != nil {
+				return err
+			}
+
+		case <-stream.Context().Done():
+			log.Debugf("rebalance event stream cancelled")
+			return stream.Context().Err()
+
+		case <-client.Quit():
+			return errors.New("rebalance event subscription " +
+				"terminated")
+
+		case <-s.quit:
+			return errServerShuttingDown
+		}
+	}
+}
+
+// marshallRebalanceEvent converts an event of the rebalancer into its RPC
+// counterpart.
+func marshallRebalanceEvent(event interface{}) (*RebalanceEvent, error) {
+	switch e := event.(type) {
+	case *rebalance.StartedEvent:
+		req := &RebalanceRequest{
+			IncomingChanId: e.Request.IncomingChannel.ToUint64(),
+			AmtSat:         int64(e.Request.Amount),
+			MaxFeeSat:      int64(e.Request.MaxFee),
+		}
+		for _, chanID := range e.Request.OutgoingChannels {
+			req.OutgoingChanIds = append(
+				req.OutgoingChanIds, chanID.ToUint64(),
+			)
+		}
+
+		return &RebalanceEvent{
+			Event: &RebalanceEvent_Started{
+				Started: &RebalanceStarted{
+					PaymentHash: e.PaymentHash[:],
+					Request:     req,
+					MaxFeeMsat:  int64(e.MaxFee),
+					Automatic:   e.Automatic,
+				},
+			},
+		}, nil
+
+	case *rebalance.AttemptFailedEvent:
+		return &RebalanceEvent{
+			Event: &RebalanceEvent_AttemptFailed{
+				AttemptFailed: &RebalanceAttemptFailed{
+					PaymentHash: e.PaymentHash[:],
+					Attempt:     e.Attempt,
+					OutgoingChanId: e.OutgoingChannel.
+						ToUint64(),
+					FailureReason: e.FailureReason,
+				},
+			},
+		}, nil
+
+	case *rebalance.Result:
+		return &RebalanceEvent{
+			Event: &RebalanceEvent_Finished{
+				Finished: marshallRebalanceResult(e),
+			},
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown rebalance event: %T", event)
+	}
+}
+
+// marshallRebalanceResult converts the result of a rebalance into its RPC
+// counterpart.
+func marshallRebalanceResult(result *rebalance.Result) *RebalanceResult {
+	rpcStatus := RebalanceStatus_REBALANCE_FAILED
+	if result.Status == rebalance.StatusSucceeded {
+		rpcStatus = RebalanceStatus_REBALANCE_SUCCEEDED
+	}
+
+	return &RebalanceResult{
+		Id:             result.ID,
+		Timestamp:      uint64(result.Timestamp.Unix()),
+		OutgoingChanId: result.OutgoingChannel.ToUint64(),
+		IncomingChanId: result.IncomingChannel.ToUint64(),
+		AmtSat:         int64(result.Amount),
+		FeeMsat:        int64(result.Fee),
+		Status:         rpcStatus,
+		Attempts:       result.Attempts,
+		FailureReason:  result.FailureReason,
+		PaymentHash:    result.PaymentHash[:],
+		Automatic:      result.Automatic,
+	}
+}
+
+// marshallRebalanceTarget converts the target of a channel into its RPC
+// counterpart.
+func marshallRebalanceTarget(target *rebalance.Target) *RebalanceTarget {
+	rpcTarget := &RebalanceTarget{
+		ChanId: target.ChanID.ToUint64(),
+	}
+
+	target.LocalRatio.WhenSome(func(ratio float64) {
+		rpcTarget.Target = &RebalanceTarget_LocalRatio{
+			LocalRatio: ratio,
+		}
+	})
+	target.LocalBalance.WhenSome(func(balance chainutil.Amount) {
+		rpcTarget.Target = &RebalanceTarget_LocalBalanceSat{
+			LocalBalanceSat: int64(balance),
+		}
+	})
+
+	return rpcTarget
+}
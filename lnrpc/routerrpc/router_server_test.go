@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/flokiorg/flnd/channeldb"
+	"github.com/flokiorg/flnd/clock"
 	"github.com/flokiorg/flnd/lnrpc"
 	"github.com/flokiorg/flnd/lnwire"
 	paymentsdb "github.com/flokiorg/flnd/payments/db"
@@ -901,3 +903,133 @@ func TestProbePaymentRequestUsesUniqueHashPerLSP(t *testing.T) {
 	require.Contains(t, probedDests, eveVertex)
 	require.Contains(t, probedDests, daveVertex)
 }
+
+// forwardingLogMock is a mock forwarding log that records the last aggregate
+// query and returns a fixed set of aggregates.
+type forwardingLogMock struct {
+	ForwardingLogDB
+
+	query      channeldb.ForwardingAggregateQuery
+	aggregates []channeldb.ForwardingAggregate
+}
+
+func (f *forwardingLogMock) AggregateForwardingEvents(_ context.Context,
+	q channeldb.ForwardingAggregateQuery) ([]channeldb.ForwardingAggregate,
+	error) {
+
+	f.query = q
+
+	return f.aggregates, nil
+}
+
+// TestAggregateForwardingHistory asserts that the aggregation request is
+// translated into the right forwarding log query and that only the key of the
+// requested group is returned.
+func TestAggregateForwardingHistory(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(10_000, 0)
+	peer := route.Vertex{2, 1}
+	stats := channeldb.ForwardingStats{
+		NumEvents: 2,
+		AmtIn:     3_300,
+		AmtOut:    3_000,
+	}
+	rpcStats := &ForwardingStats{
+		NumEvents:  2,
+		AmtInMsat:  3_300,
+		AmtOutMsat: 3_000,
+		FeeMsat:    300,
+	}
+
+	fwdLog := &forwardingLogMock{
+		aggregates: []channeldb.ForwardingAggregate{{
+			ChanID:      lnwire.NewShortChanIDFromInt(5),
+			Peer:        peer,
+			BucketStart: time.Unix(7_200, 0),
+			Incoming:    stats,
+			Outgoing:    stats,
+		}},
+	}
+	server := &Server{
+		cfg: &Config{
+			RouterBackend: &RouterBackend{
+				Clock:         clock.NewTestClock(now),
+				ForwardingLog: fwdLog,
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		groupBy       ForwardingGroupBy
+		expectedQuery channeldb.ForwardingAggregateQuery
+		expected      *ForwardingAggregate
+	}{
+		{
+			name:    "channel",
+			groupBy: ForwardingGroupBy_GROUP_BY_CHANNEL,
+			expectedQuery: channeldb.ForwardingAggregateQuery{
+				StartTime: time.Unix(100, 0),
+				EndTime:   now,
+				GroupBy:   channeldb.ForwardingGroupChannel,
+			},
+			expected: &ForwardingAggregate{
+				ChanId:   5,
+				Incoming: rpcStats,
+				Outgoing: rpcStats,
+			},
+		},
+		{
+			name:    "peer",
+			groupBy: ForwardingGroupBy_GROUP_BY_PEER,
+			expectedQuery: channeldb.ForwardingAggregateQuery{
+				StartTime: time.Unix(100, 0),
+				EndTime:   now,
+				GroupBy:   channeldb.ForwardingGroupPeer,
+			},
+			expected: &ForwardingAggregate{
+				PeerPubkey: peer.String(),
+				Incoming:   rpcStats,
+				Outgoing:   rpcStats,
+			},
+		},
+		{
+			name:    "day",
+			groupBy: ForwardingGroupBy_GROUP_BY_DAY,
+			expectedQuery: channeldb.ForwardingAggregateQuery{
+				StartTime:  time.Unix(100, 0),
+				EndTime:    now,
+				GroupBy:    channeldb.ForwardingGroupTime,
+				BucketSize: 24 * time.Hour,
+			},
+			expected: &ForwardingAggregate{
+				BucketStart: 7_200,
+				Incoming:    rpcStats,
+				Outgoing:    rpcStats,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		resp, err := server.AggregateForwardingHistory(
+			t.Context(), &AggregateForwardingHistoryRequest{
+				StartTime: 100,
+				GroupBy:   tc.groupBy,
+			},
+		)
+		require.NoError(t, err, tc.name)
+		require.Equal(t, tc.expectedQuery, fwdLog.query, tc.name)
+		require.Len(t, resp.Aggregates, 1, tc.name)
+		require.Equal(t, tc.expected, resp.Aggregates[0], tc.name)
+	}
+
+	// An end time before the start time is rejected.
+	_, err := server.AggregateForwardingHistory(
+		t.Context(), &AggregateForwardingHistoryRequest{
+			StartTime: 200,
+			EndTime:   100,
+		},
+	)
+	require.Error(t, err)
+}
@@ -11,7 +11,7 @@ import (
 //nolint:ll
 type RoutingConfig struct {
 	// ProbabilityEstimatorType sets the estimator to use.
-	ProbabilityEstimatorType string `long:"estimator" choice:"apriori" choice:"bimodal" description:"Probability estimator used for pathfinding." `
+	ProbabilityEstimatorType string `long:"estimator" choice:"apriori" choice:"bimodal" choice:"external" description:"Probability estimator used for pathfinding. The external estimator asks a service registered over RPC and falls back to the apriori estimator." `
 
 	// MinRouteProbability is the minimum required route success probability
 	// to attempt the payment.
@@ -42,6 +42,9 @@ type RoutingConfig struct {
 	// BimodalConfig defines parameters for the bimodal probability.
 	BimodalConfig *BimodalConfig `group:"bimodal" namespace:"bimodal" description:"configuration for the bimodal pathfinding probability estimator"`
 
+	// ExternalConfig defines parameters for the external probability.
+	ExternalConfig *ExternalConfig `group:"external" namespace:"external" description:"configuration for the external pathfinding probability estimator, which uses the apriori configuration as its fallback"`
+
 	// FeeEstimationTimeout is the maximum time to wait for routing fees to be estimated.
 	FeeEstimationTimeout time.Duration `long:"fee-estimation-timeout" description:"the maximum time to wait for routing fees to be estimated by payment probes"`
 }
@@ -89,3 +92,17 @@ type BimodalConfig struct {
 	// time for previous successes or failures.
 	DecayTime time.Duration `long:"decaytime" description:"Describes the information decay of knowledge about previous successes and failures in channels."`
 }
+
+// ExternalConfig defines parameters for the external probability.
+//
+//nolint:ll
+type ExternalConfig struct {
+	// Timeout is the time a pathfinding round waits for the external
+	// service to estimate the probabilities it asked for before it falls
+	// back to the apriori estimator.
+	Timeout time.Duration `long:"timeout" description:"The time a pathfinding round waits for the registered service to estimate the probabilities it asked for before falling back to the apriori estimator."`
+
+	// CacheTTL is the time a probability that was estimated by the
+	// external service is reused for.
+	CacheTTL time.Duration `long:"cachettl" description:"The time a probability estimated by the registered service is reused for. Must be positive, as pathfinding picks up the estimates from the cache."`
+}
@@ -71,6 +71,32 @@ func rpcHtlcEvent(htlcEvent interface{}) (*HtlcEvent, error) {
 		eventType = &e.HtlcEventType
 		timestamp = e.Timestamp
 
+	case *htlcswitch.ResourceDecisionEvent:
+		bucket, err := rpcResourceBucket(e.Decision.Bucket)
+		if err != nil {
+			return nil, err
+		}
+
+		event = &HtlcEvent_ResourceDecisionEvent{
+			ResourceDecisionEvent: &ResourceDecisionEvent{
+				Info:                   rpcInfo(e.HtlcInfo),
+				IncomingAccountable:    e.IncomingAccountable,
+				OutgoingAccountable:    e.Decision.OutgoingAccountable,
+				Bucket:                 bucket,
+				OutgoingReputationMsat: e.Decision.Reputation,
+				IncomingRevenueMsat:    e.Decision.Revenue,
+				InFlightRiskMsat: uint64(
+					e.Decision.InFlightRisk,
+				),
+				HtlcRiskMsat: uint64(e.Decision.HtlcRisk),
+			},
+		}
+
+		forwardType := htlcswitch.HtlcEventTypeForward
+		key = e.HtlcKey
+		eventType = &forwardType
+		timestamp = e.Timestamp
+
 	case *htlcswitch.FinalHtlcEvent:
 		event = &HtlcEvent_FinalHtlcEvent{
 			FinalHtlcEvent: &FinalHtlcEvent{
@@ -118,6 +144,29 @@ func rpcHtlcEvent(htlcEvent interface{}) (*HtlcEvent, error) {
 	return rpcEvent, nil
 }
 
+// rpcResourceBucket maps a resource bucket assigned by the switch's resource
+// manager to its rpc counterpart.
+func rpcResourceBucket(bucket htlcswitch.ResourceBucket) (
+	ResourceDecisionEvent_ResourceBucket, error) {
+
+	switch bucket {
+	case htlcswitch.ResourceBucketNone:
+		return ResourceDecisionEvent_NONE, nil
+
+	case htlcswitch.ResourceBucketGeneral:
+		return ResourceDecisionEvent_GENERAL, nil
+
+	case htlcswitch.ResourceBucketCongestion:
+		return ResourceDecisionEvent_CONGESTION, nil
+
+	case htlcswitch.ResourceBucketProtected:
+		return ResourceDecisionEvent_PROTECTED, nil
+
+	default:
+		return 0, fmt.Errorf("unknown resource bucket: %v", bucket)
+	}
+}
+
 // rpcInfo returns a rpc struct containing the htlc information from the
 // switch's htlc info struct.
 func rpcInfo(info htlcswitch.HtlcInfo) *HtlcInfo {
@@ -269,6 +318,9 @@ func rpcOutgoingFailure(failureDetail htlcswitch.OutgoingFailure) (
 	case htlcswitch.OutgoingFailureForwardsDisabled:
 		return FailureDetail_FORWARDS_DISABLED, nil
 
+	case htlcswitch.OutgoingFailureInsufficientResources:
+		return FailureDetail_INSUFFICIENT_RESOURCES, nil
+
 	default:
 		return 0, fmt.Errorf("unknown outgoing failure "+
 			"detail: %v", failureDetail.FailureString())
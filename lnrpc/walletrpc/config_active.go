@@ -11,6 +11,7 @@ import (
 	"github.com/flokiorg/flnd/macaroons"
 	"github.com/flokiorg/flnd/sweep"
 	"github.com/flokiorg/go-flokicoin/chaincfg"
+	"github.com/flokiorg/go-flokicoin/wire"
 	"github.com/flokiorg/walletd/wallet"
 )
 
@@ -80,4 +81,9 @@ type Config struct {
 
 	// ChanStateDB is the reference to the open channel store.
 	ChanStateDB chanstate.OpenChannelStore
+
+	// BumpFundingFee replaces the unconfirmed funding transaction of the
+	// pending dual-funded channel with the passed funding outpoint by one
+	// paying the passed fee rate.
+	BumpFundingFee func(wire.OutPoint, chainfee.SatPerKWeight) error
 }
@@ -997,6 +997,9 @@ func (w *WalletKit) PendingSweeps(ctx context.Context,
 			DeadlineHeight:       inp.DeadlineHeight,
 			RequestedSatPerVbyte: startingFeeRate,
 			MaturityHeight:       inp.MaturityHeight,
+			FeeFunction: marshalFeeFunction(
+				inp.Params.FeeFunction,
+			),
 		}
 		rpcPendingSweeps = append(rpcPendingSweeps, ps)
 	}
@@ -1126,6 +1129,11 @@ func (w *WalletKit) prepareSweepParams(in *BumpFeeRequest,
 		return sweep.Params{}, false, err
 	}
 
+	feeFunction, err := unmarshalFeeFunction(in.FeeFunction)
+	if err != nil {
+		return sweep.Params{}, false, err
+	}
+
 	// Get the current pending inputs.
 	inputMap, err := w.cfg.Sweeper.PendingInputs()
 	if err != nil {
@@ -1145,6 +1153,9 @@ func (w *WalletKit) prepareSweepParams(in *BumpFeeRequest,
 			Immediate:       immediate,
 			StartingFeeRate: feeRate,
 			Budget:          chainutil.Amount(in.Budget),
+			FeeFunction: feeFunction.UnwrapOr(
+				sweep.FeeFunctionLinear,
+			),
 		}
 
 		if in.DeadlineDelta != 0 {
@@ -1188,7 +1199,8 @@ func (w *WalletKit) prepareSweepParams(in *BumpFeeRequest,
 		startingFeeRate = feeRate
 	}
 
-	// Prepare the new sweep params.
+	// Prepare the new sweep params. The fee function is only replaced if
+	// a new one was specified.
 	//
 	// NOTE: if this input doesn't exist and the new budget is not
 	// specified, the params would have a zero budget.
@@ -1197,6 +1209,7 @@ func (w *WalletKit) prepareSweepParams(in *BumpFeeRequest,
 		DeadlineHeight:  deadline,
 		StartingFeeRate: startingFeeRate,
 		Budget:          budget,
+		FeeFunction:     feeFunction.UnwrapOr(inp.Params.FeeFunction),
 	}
 
 	log.Infof("[BumpFee]: bumping fee for existing input=%v, old "+
@@ -1205,6 +1218,57 @@ func (w *WalletKit) prepareSweepParams(in *BumpFeeRequest,
 	return params, ok, nil
 }
 
+// unmarshalFeeFunction converts the RPC fee function type into the one used by
+// the sweeper. None is returned if no fee function was specified.
+func unmarshalFeeFunction(
+	f FeeFunctionType) (fn.Option[sweep.FeeFunctionType], error) {
+
+	switch f {
+	case FeeFunctionType_FEE_FUNCTION_TYPE_UNSPECIFIED:
+		return fn.None[sweep.FeeFunctionType](), nil
+
+	case FeeFunctionType_FEE_FUNCTION_TYPE_LINEAR:
+		return fn.Some(sweep.FeeFunctionLinear), nil
+
+	case FeeFunctionType_FEE_FUNCTION_TYPE_EXPONENTIAL:
+		return fn.Some(sweep.FeeFunctionExponential), nil
+
+	case FeeFunctionType_FEE_FUNCTION_TYPE_CUBIC:
+		return fn.Some(sweep.FeeFunctionCubic), nil
+
+	case FeeFunctionType_FEE_FUNCTION_TYPE_STEP:
+		return fn.Some(sweep.FeeFunctionStep), nil
+
+	case FeeFunctionType_FEE_FUNCTION_TYPE_MEMPOOL:
+		return fn.Some(sweep.FeeFunctionMempool), nil
+
+	default:
+		return fn.None[sweep.FeeFunctionType](), fmt.Errorf("unknown "+
+			"fee function %v", f)
+	}
+}
+
+// marshalFeeFunction converts the sweeper's fee function type into its RPC
+// counterpart.
+func marshalFeeFunction(f sweep.FeeFunctionType) FeeFunctionType {
+	switch f {
+	case sweep.FeeFunctionExponential:
+		return FeeFunctionType_FEE_FUNCTION_TYPE_EXPONENTIAL
+
+	case sweep.FeeFunctionCubic:
+		return FeeFunctionType_FEE_FUNCTION_TYPE_CUBIC
+
+	case sweep.FeeFunctionStep:
+		return FeeFunctionType_FEE_FUNCTION_TYPE_STEP
+
+	case sweep.FeeFunctionMempool:
+		return FeeFunctionType_FEE_FUNCTION_TYPE_MEMPOOL
+
+	default:
+		return FeeFunctionType_FEE_FUNCTION_TYPE_LINEAR
+	}
+}
+
 // BumpFee allows bumping the fee rate of an arbitrary input. A fee preference
 // can be expressed either as a specific fee rate or a delta of blocks in which
 // the output should be swept on-chain within. If a fee preference is not
@@ -1219,6 +1283,20 @@ func (w *WalletKit) BumpFee(ctx context.Context,
 		return nil, err
 	}
 
+	// The funding output of a pending dual-funded channel can't be swept,
+	// instead its funding transaction is replaced by one paying a higher
+	// fee rate.
+	replaced, err := w.bumpFundingFee(in, *op)
+	if err != nil {
+		return nil, err
+	}
+	if replaced {
+		return &BumpFeeResponse{
+			Status: "Successfully requested replacement of " +
+				"funding tx",
+		}, nil
+	}
+
 	// Get the current height so we can calculate the deadline height.
 	_, currentHeight, err := w.cfg.Chain.GetBestBlock()
 	if err != nil {
@@ -1260,6 +1338,49 @@ func (w *WalletKit) BumpFee(ctx context.Context,
 	}, nil
 }
 
+// bumpFundingFee replaces the funding transaction of the pending channel with
+// the passed funding outpoint by one paying the requested fee rate. False is
+// returned if the outpoint isn't the funding output of a pending channel.
+func (w *WalletKit) bumpFundingFee(in *BumpFeeRequest,
+	op wire.OutPoint) (bool, error) {
+
+	if w.cfg.BumpFundingFee == nil {
+		return false, nil
+	}
+
+	chans, err := w.cfg.ChanStateDB.FetchPendingChannels()
+	if err != nil {
+		return false, err
+	}
+
+	channel := fn.Find(chans, func(c *chanstate.OpenChannel) bool {
+		return c.FundingOutpoint == op
+	})
+	if channel.IsNone() {
+		return false, nil
+	}
+
+	feeRateOpt, _, err := validateBumpFeeRequest(in, w.cfg.FeeEstimator)
+	if err != nil {
+		return false, err
+	}
+	feeRate, err := feeRateOpt.UnwrapOrErr(errors.New("either " +
+		"TargetConf or SatPerVbyte must be set to replace a funding " +
+		"tx"))
+	if err != nil {
+		return false, err
+	}
+
+	log.Infof("[BumpFee]: replacing funding tx of ChannelPoint(%v) with "+
+		"fee rate %v", op, feeRate)
+
+	if err := w.cfg.BumpFundingFee(op, feeRate); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 // getWaitingCloseChannel returns the waiting close channel in case it does
 // exist in the underlying channel state database.
 func (w *WalletKit) getWaitingCloseChannel(
@@ -1396,6 +1517,7 @@ func (w *WalletKit) BumpForceCloseFee(_ context.Context,
 				Immediate:     in.Immediate,
 				Budget:        in.Budget,
 				DeadlineDelta: in.DeadlineDelta,
+				FeeFunction:   in.FeeFunction,
 			}, anchor.OutPoint, currentHeight,
 		)
 		if err != nil {
@@ -64,6 +64,33 @@ func TestWitnessTypeMapping(t *testing.T) {
 	}
 }
 
+// TestFeeFunctionMapping tests that every fee function type in the walletrpc
+// package maps to the sweeper's fee function of the same name and back.
+func TestFeeFunctionMapping(t *testing.T) {
+	t.Parallel()
+
+	for value, name := range FeeFunctionType_name {
+		rpcType := FeeFunctionType(value)
+
+		feeFunction, err := unmarshalFeeFunction(rpcType)
+		require.NoError(t, err)
+
+		if rpcType == FeeFunctionType_FEE_FUNCTION_TYPE_UNSPECIFIED {
+			require.True(t, feeFunction.IsNone())
+			continue
+		}
+
+		sweepType := feeFunction.UnsafeFromSome()
+		sweepName := strings.ToUpper(sweepType.String())
+		require.Equal(t, "FEE_FUNCTION_TYPE_"+sweepName, name)
+		require.Equal(t, rpcType, marshalFeeFunction(sweepType))
+	}
+
+	unknown := FeeFunctionType(len(FeeFunctionType_name))
+	_, err := unmarshalFeeFunction(unknown)
+	require.Error(t, err)
+}
+
 type mockCoinSelectionLocker struct {
 	fail bool
 }
@@ -299,3 +299,19 @@ func (h *HarnessRPC) DeleteForwardingHistory(
 
 	return resp
 }
+
+// AggregateForwardingHistory makes a RPC call to the node's RouterClient and
+// asserts.
+//
+//nolint:ll
+func (h *HarnessRPC) AggregateForwardingHistory(
+	req *routerrpc.AggregateForwardingHistoryRequest) *routerrpc.AggregateForwardingHistoryResponse {
+
+	ctxt, cancel := context.WithTimeout(h.runCtx, DefaultTimeout)
+	defer cancel()
+
+	resp, err := h.Router.AggregateForwardingHistory(ctxt, req)
+	h.NoError(err, "AggregateForwardingHistory")
+
+	return resp
+}
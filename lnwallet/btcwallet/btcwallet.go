@@ -1153,15 +1153,16 @@ func mapRpcclientError(err error) error {
 // already published to the network (either in the mempool or chain) no error
 // will be returned.
 func (b *BtcWallet) PublishTransaction(tx *wire.MsgTx, label string) error {
-	// For neutrino backend there's no mempool, so we return early by
-	// publishing the transaction.
-	if b.chain.BackEnd() == "neutrino" {
+	// For neutrino, electrum and esplora backends there's no mempool
+	// acceptance test, so we return early by publishing the transaction.
+	switch b.chain.BackEnd() {
+	case "neutrino", "electrum", "esplora":
 		err := b.wallet.PublishTransaction(tx, label)
 
 		return mapRpcclientError(err)
 	}
 
-	// For non-neutrino nodes, we will first check whether the transaction
+	// For other nodes, we will first check whether the transaction
 	// can be accepted by the mempool.
 	// Use a max feerate of 0 means the default value will be used when
 	// testing mempool acceptance. The default max feerate is 0.10 FLC/kvb,
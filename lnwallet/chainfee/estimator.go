@@ -1,6 +1,7 @@
 package chainfee
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	prand "math/rand"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -626,6 +628,178 @@ func chooseMinFee(minRelayFeeFunc func() SatPerKWeight,
 // Estimator interface.
 var _ Estimator = (*FlokicoindEstimator)(nil)
 
+// ElectrumFeeSource is the subset of an electrum client's methods that the
+// ElectrumEstimator relies on. Both fee rates are returned in FLC/kB.
+type ElectrumFeeSource interface {
+	// GetFee returns the fee rate needed for a transaction to confirm
+	// within the target number of blocks, or a negative value if the
+	// server can't estimate it.
+	GetFee(ctx context.Context, target uint32) (float32, error)
+
+	// GetRelayFee returns the minimum fee rate a transaction must pay to
+	// be accepted into the server's mempool.
+	GetRelayFee(ctx context.Context) (float32, error)
+}
+
+// ElectrumEstimator is an implementation of the Estimator interface backed by
+// an electrum server. This implementation will proxy any fee estimation
+// requests to the server's blockchain.estimatefee method.
+type ElectrumEstimator struct {
+	// fallbackFeePerKW is the fall back fee rate in sat/kw that is returned
+	// if the server does not yet have enough data to actually produce fee
+	// estimates.
+	fallbackFeePerKW SatPerKWeight
+
+	// minFeeManager is used to query the current minimum fee, in sat/kw,
+	// that we should enforce. This will be used to determine fee rate for
+	// a transaction when the estimated fee rate is too low to allow the
+	// transaction to propagate through the network.
+	minFeeManager *minFeeManager
+
+	// timeout is the amount of time we wait for the server to respond to
+	// a request.
+	timeout time.Duration
+
+	source ElectrumFeeSource
+}
+
+// NewElectrumEstimator creates a new ElectrumEstimator given a connection to
+// an electrum server and a fall back fee rate. The fallback fee rate is used in
+// the occasion that the server has insufficient data to estimate a fee rate.
+func NewElectrumEstimator(source ElectrumFeeSource, timeout time.Duration,
+	fallBackFeeRate SatPerKWeight) *ElectrumEstimator {
+
+	return &ElectrumEstimator{
+		fallbackFeePerKW: fallBackFeeRate,
+		timeout:          timeout,
+		source:           source,
+	}
+}
+
+// Start signals the Estimator to start any processes or goroutines
+// it needs to perform its duty.
+//
+// NOTE: This method is part of the Estimator interface.
+func (e *ElectrumEstimator) Start() error {
+	minRelayFeeManager, err := newMinFeeManager(
+		defaultUpdateInterval, e.fetchMinRelayFee,
+	)
+	if err != nil {
+		return err
+	}
+	e.minFeeManager = minRelayFeeManager
+
+	return nil
+}
+
+// fetchMinRelayFee fetches and returns the minimum relay fee in sat/kw from
+// the electrum server.
+func (e *ElectrumEstimator) fetchMinRelayFee() (SatPerKWeight, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	btcPerKB, err := e.source.GetRelayFee(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return btcPerKBToFeePerKW(btcPerKB)
+}
+
+// Stop stops any spawned goroutines and cleans up the resources used
+// by the fee estimator.
+//
+// NOTE: This method is part of the Estimator interface.
+func (e *ElectrumEstimator) Stop() error {
+	return nil
+}
+
+// EstimateFeePerKW takes in a target for the number of blocks until an initial
+// confirmation and returns the estimated fee expressed in sat/kw.
+//
+// NOTE: This method is part of the Estimator interface.
+func (e *ElectrumEstimator) EstimateFeePerKW(
+	numBlocks uint32) (SatPerKWeight, error) {
+
+	feeEstimate, err := e.fetchEstimate(numBlocks)
+	switch {
+	// If the server doesn't have enough data, or returns an error, then
+	// to return a proper value, then we'll return the default fall back
+	// fee rate.
+	case err != nil:
+		log.Errorf("unable to query estimator: %v", err)
+		fallthrough
+
+	case feeEstimate == 0:
+		return e.fallbackFeePerKW, nil
+	}
+
+	return feeEstimate, nil
+}
+
+// RelayFeePerKW returns the minimum fee rate required for transactions to be
+// relayed.
+//
+// NOTE: This method is part of the Estimator interface.
+func (e *ElectrumEstimator) RelayFeePerKW() SatPerKWeight {
+	return e.minFeeManager.fetchMinFee()
+}
+
+// fetchEstimate returns a fee estimate for a transaction to be confirmed in
+// confTarget blocks. The estimate is returned in sat/kw, and is zero if the
+// server can't estimate it.
+func (e *ElectrumEstimator) fetchEstimate(confTarget uint32) (SatPerKWeight,
+	error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+
+	// First, we'll fetch the estimate for our confirmation target. A
+	// negative value means the server has insufficient data.
+	btcPerKB, err := e.source.GetFee(ctx, confTarget)
+	if err != nil {
+		return 0, err
+	}
+	if btcPerKB <= 0 {
+		return 0, nil
+	}
+
+	satPerKw, err := btcPerKBToFeePerKW(btcPerKB)
+	if err != nil {
+		return 0, err
+	}
+
+	// Finally, we'll enforce our fee floor.
+	absoluteMinFee := e.RelayFeePerKW()
+	if satPerKw < absoluteMinFee {
+		log.Debugf("Estimated fee rate of %v sat/kw is too low, "+
+			"using fee floor of %v sat/kw instead", satPerKw,
+			absoluteMinFee)
+
+		satPerKw = absoluteMinFee
+	}
+
+	log.Debugf("Returning %v sat/kw for conf target of %v",
+		int64(satPerKw), confTarget)
+
+	return satPerKw, nil
+}
+
+// btcPerKBToFeePerKW converts a fee rate expressed in FLC/kB, as returned by
+// electrum servers, to sat/kw.
+func btcPerKBToFeePerKW(btcPerKB float32) (SatPerKWeight, error) {
+	satPerKB, err := chainutil.NewAmount(float64(btcPerKB))
+	if err != nil {
+		return 0, err
+	}
+
+	return SatPerKVByte(satPerKB).FeePerKWeight(), nil
+}
+
+// A compile-time assertion to ensure that ElectrumEstimator implements the
+// Estimator interface.
+var _ Estimator = (*ElectrumEstimator)(nil)
+
 // WebAPIFeeSource is an interface allows the WebAPIEstimator to query an
 // arbitrary HTTP-based fee estimator. Each new set/network will gain an
 // implementation of this interface in order to allow the WebAPIEstimator to
@@ -683,6 +857,14 @@ func (s SparseConfFeeSource) parseResponse(r io.Reader) (
 // confirmation targets to sat/kw fees and min relay feerate in a parsed
 // response.
 func (s SparseConfFeeSource) GetFeeInfo() (WebAPIResponse, error) {
+	return queryWebAPI(s.URL, s.parseResponse)
+}
+
+// queryWebAPI queries the fee estimation API found at the target URL, and
+// parses its response using the passed parse function.
+func queryWebAPI(targetURL string,
+	parse func(io.Reader) (WebAPIResponse, error)) (WebAPIResponse, error) {
+
 	// Rather than use the default http.Client, we'll make a custom one
 	// which will allow us to control how long we'll wait to read the
 	// response from the service. This way, if the service is down or
@@ -700,7 +882,6 @@ func (s SparseConfFeeSource) GetFeeInfo() (WebAPIResponse, error) {
 
 	// With the client created, we'll query the API source to fetch the URL
 	// that we should use to query for the fee estimation.
-	targetURL := s.URL
 	resp, err := netClient.Get(targetURL)
 	if err != nil {
 		log.Errorf("unable to query web api for fee response: %v",
@@ -711,7 +892,7 @@ func (s SparseConfFeeSource) GetFeeInfo() (WebAPIResponse, error) {
 
 	// Once we've obtained the response, we'll instruct the WebAPIFeeSource
 	// to parse out the body to obtain our final result.
-	parsedResp, err := s.parseResponse(resp.Body)
+	parsedResp, err := parse(resp.Body)
 	if err != nil {
 		log.Errorf("unable to parse fee api response: %v", err)
 
@@ -725,6 +906,58 @@ func (s SparseConfFeeSource) GetFeeInfo() (WebAPIResponse, error) {
 // WebAPIFeeSource interface.
 var _ WebAPIFeeSource = (*SparseConfFeeSource)(nil)
 
+// EsploraFeeSource is an implementation of the WebAPIFeeSource that queries
+// the fee estimates of an Esplora API. It expects the response to be in the
+// JSON format: `{ "1": 12.5, ... }` where the keys are block targets and the
+// values are fee estimates in sat per vbyte.
+type EsploraFeeSource struct {
+	// URL is the URL of the Esplora API's fee-estimates endpoint.
+	URL string
+}
+
+// parseResponse parses the fee estimates returned by the Esplora API, which
+// don't include the minimum relay fee rate, so the floor fee rate is used for
+// it.
+func (s EsploraFeeSource) parseResponse(r io.Reader) (WebAPIResponse, error) {
+	var estimates map[string]float64
+	if err := json.NewDecoder(r).Decode(&estimates); err != nil {
+		return WebAPIResponse{}, err
+	}
+
+	resp := WebAPIResponse{
+		FeeByBlockTarget: make(map[uint32]uint32, len(estimates)),
+		MinRelayFeerate:  FeePerKwFloor.FeePerKVByte(),
+	}
+	for target, satPerVByte := range estimates {
+		numBlocks, err := strconv.ParseUint(target, 10, 32)
+		if err != nil {
+			return WebAPIResponse{}, fmt.Errorf("invalid block "+
+				"target %q: %w", target, err)
+		}
+		if satPerVByte < 0 {
+			return WebAPIResponse{}, fmt.Errorf("invalid fee rate "+
+				"%v for block target %d", satPerVByte, numBlocks)
+		}
+
+		resp.FeeByBlockTarget[uint32(numBlocks)] = uint32(
+			math.Round(satPerVByte * 1000),
+		)
+	}
+
+	return resp, nil
+}
+
+// GetFeeInfo will query the Esplora API, parse the response and return a map
+// of confirmation targets to sat/kvb fees and min relay feerate in a parsed
+// response.
+func (s EsploraFeeSource) GetFeeInfo() (WebAPIResponse, error) {
+	return queryWebAPI(s.URL, s.parseResponse)
+}
+
+// A compile-time assertion to ensure that EsploraFeeSource implements the
+// WebAPIFeeSource interface.
+var _ WebAPIFeeSource = (*EsploraFeeSource)(nil)
+
 // WebAPIEstimator is an implementation of the Estimator interface that
 // queries an HTTP-based fee estimation from an existing web API.
 type WebAPIEstimator struct {
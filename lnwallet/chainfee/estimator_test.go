@@ -2,7 +2,9 @@ package chainfee
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -91,6 +93,77 @@ func TestStaticFeeEstimator(t *testing.T) {
 	}
 }
 
+// mockElectrumFeeSource is a mock implementation of the ElectrumFeeSource
+// interface that returns fixed fee rates.
+type mockElectrumFeeSource struct {
+	fees     map[uint32]float32
+	relayFee float32
+}
+
+func (m *mockElectrumFeeSource) GetFee(_ context.Context,
+	target uint32) (float32, error) {
+
+	fee, ok := m.fees[target]
+	if !ok {
+		return -1, nil
+	}
+
+	return fee, nil
+}
+
+func (m *mockElectrumFeeSource) GetRelayFee(_ context.Context) (float32,
+	error) {
+
+	return m.relayFee, nil
+}
+
+// TestElectrumEstimator checks that the ElectrumEstimator converts the fee
+// rates returned by the server, enforces the relay fee floor and falls back to
+// the fallback fee rate when the server can't estimate a fee rate.
+func TestElectrumEstimator(t *testing.T) {
+	t.Parallel()
+
+	const fallbackFee = SatPerKWeight(12500)
+
+	source := &mockElectrumFeeSource{
+		fees: map[uint32]float32{
+			// 0.0002 FLC/kB is 20000 sat/kvB, which is 5000
+			// sat/kw.
+			2: 0.0002,
+
+			// 0.00002 FLC/kB is 2000 sat/kvB, which is below our
+			// relay fee.
+			6: 0.00002,
+		},
+
+		// 0.00004 FLC/kB is 4000 sat/kvB, which is 1000 sat/kw.
+		relayFee: 0.00004,
+	}
+
+	estimator := NewElectrumEstimator(source, time.Second, fallbackFee)
+	require.NoError(t, estimator.Start())
+	t.Cleanup(func() {
+		require.NoError(t, estimator.Stop())
+	})
+
+	require.Equal(t, SatPerKWeight(1000), estimator.RelayFeePerKW())
+
+	fee, err := estimator.EstimateFeePerKW(2)
+	require.NoError(t, err)
+	require.Equal(t, SatPerKWeight(5000), fee)
+
+	// An estimate below the relay fee is raised to the relay fee.
+	fee, err = estimator.EstimateFeePerKW(6)
+	require.NoError(t, err)
+	require.Equal(t, SatPerKWeight(1000), fee)
+
+	// The server has no estimate for this target, so the fallback fee rate
+	// is returned.
+	fee, err = estimator.EstimateFeePerKW(144)
+	require.NoError(t, err)
+	require.Equal(t, fallbackFee, fee)
+}
+
 // TestSparseConfFeeSource checks that SparseConfFeeSource generates URLs and
 // parses API responses as expected.
 func TestSparseConfFeeSource(t *testing.T) {
@@ -173,6 +246,38 @@ func TestFeeSourceCompatibility(t *testing.T) {
 	require.Equal(t, FeePerKwFloor.FeePerKVByte(), resp.MinRelayFeerate)
 }
 
+// TestEsploraFeeSource checks that EsploraFeeSource parses the fee estimates
+// of an Esplora API as expected.
+func TestEsploraFeeSource(t *testing.T) {
+	t.Parallel()
+
+	feeSource := EsploraFeeSource{URL: "test"}
+
+	// The estimates are in sat/vb, and are converted to sat/kvb. As the
+	// API doesn't return a min relay fee rate, the floor should be used.
+	reader := strings.NewReader(`{"1": 12.5, "6": 3.0071, "144": 1}`)
+	resp, err := feeSource.parseResponse(reader)
+	require.NoError(t, err, "unable to parse API response")
+	require.Equal(t, WebAPIResponse{
+		FeeByBlockTarget: map[uint32]uint32{
+			1:   12500,
+			6:   3007,
+			144: 1000,
+		},
+		MinRelayFeerate: FeePerKwFloor.FeePerKVByte(),
+	}, resp)
+
+	// Block targets that aren't numbers should be rejected.
+	reader = strings.NewReader(`{"soon": 12.5}`)
+	_, err = feeSource.parseResponse(reader)
+	require.Error(t, err, "expected error when parsing bad target")
+
+	// And so should negative fee rates.
+	reader = strings.NewReader(`{"1": -1}`)
+	_, err = feeSource.parseResponse(reader)
+	require.Error(t, err, "expected error when parsing bad fee rate")
+}
+
 // TestWebAPIFeeEstimator checks that the WebAPIFeeEstimator returns fee rates
 // as expected.
 func TestWebAPIFeeEstimator(t *testing.T) {
@@ -0,0 +1,111 @@
+package chanfunding
+
+import (
+	"fmt"
+
+	"github.com/flokiorg/flnd/input"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/txscript"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+// InteractiveIntent is an intent for a dual-funded channel. Its funding
+// transaction is constructed interactively with the remote party, which may
+// contribute inputs of its own. The intent holds the coins and change outputs
+// we contribute, but leaves assembling the transaction to the caller.
+//
+// Steps to final channel provisioning:
+//  1. Call BindKeys to notify the intent which keys to use when constructing
+//     the multi-sig output.
+//  2. Call SetRemoteFundingAmt once the remote contribution is known.
+//  3. Call SetFundingTx with the constructed funding transaction.
+//  4. Call SignInputs to obtain the witnesses of our inputs.
+//
+// If either of these steps fail, then the Cancel method MUST be called.
+type InteractiveIntent struct {
+	FullIntent
+}
+
+// SetRemoteFundingAmt sets the amount the remote party contributes to the
+// funding output.
+func (i *InteractiveIntent) SetRemoteFundingAmt(amt chainutil.Amount) {
+	i.remoteFundingAmt = amt
+}
+
+// SetFundingTx sets the interactively constructed funding transaction. The
+// transaction must create the funding output described by the intent.
+func (i *InteractiveIntent) SetFundingTx(tx *wire.MsgTx) error {
+	_, fundingOutput, err := i.FundingOutput()
+	if err != nil {
+		return err
+	}
+
+	found, index := input.FindScriptOutputIndex(tx, fundingOutput.PkScript)
+	if !found {
+		return fmt.Errorf("funding tx %v has no funding output",
+			tx.TxHash())
+	}
+	if tx.TxOut[index].Value != fundingOutput.Value {
+		return fmt.Errorf("funding output has value %v, expected %v",
+			tx.TxOut[index].Value, fundingOutput.Value)
+	}
+
+	i.chanPoint = &wire.OutPoint{
+		Hash:  tx.TxHash(),
+		Index: index,
+	}
+
+	return nil
+}
+
+// SignInputs signs the inputs of the passed transaction that spend our
+// coins. The witnesses are returned in the order of the inputs within the
+// transaction.
+func (i *InteractiveIntent) SignInputs(tx *wire.MsgTx,
+	prevOuts txscript.PrevOutputFetcher) ([]wire.TxWitness, error) {
+
+	ours := make(map[wire.OutPoint]struct{}, len(i.InputCoins))
+	for _, coin := range i.InputCoins {
+		ours[coin.OutPoint] = struct{}{}
+	}
+
+	sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+
+	var witnesses []wire.TxWitness
+	for idx, txIn := range tx.TxIn {
+		if _, ok := ours[txIn.PreviousOutPoint]; !ok {
+			continue
+		}
+
+		prevOut := prevOuts.FetchPrevOutput(txIn.PreviousOutPoint)
+		if prevOut == nil {
+			return nil, fmt.Errorf("unknown input %v",
+				txIn.PreviousOutPoint)
+		}
+
+		signDesc := input.SignDescriptor{
+			Output:            prevOut,
+			HashType:          txscript.SigHashAll,
+			SigHashes:         sigHashes,
+			PrevOutputFetcher: prevOuts,
+			InputIndex:        idx,
+		}
+		if txscript.IsPayToTaproot(prevOut.PkScript) {
+			signDesc.HashType = txscript.SigHashDefault
+		}
+
+		// Interactively constructed transactions only carry segwit
+		// inputs, so there's never a signature script to set.
+		inputScript, err := i.signer.ComputeInputScript(tx, &signDesc)
+		if err != nil {
+			return nil, err
+		}
+
+		witnesses = append(witnesses, inputScript.Witness)
+	}
+
+	return witnesses, nil
+}
+
+// A compile-time check to ensure InteractiveIntent meets the Intent interface.
+var _ Intent = (*InteractiveIntent)(nil)
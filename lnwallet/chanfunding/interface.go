@@ -126,6 +126,12 @@ type Request struct {
 	// create the funding output. This field will only be utilized if the
 	// Musig2 flag above is set to true.
 	TapscriptRoot fn.Option[chainhash.Hash]
+
+	// Interactive is true if the funding transaction is constructed
+	// interactively with the remote party. Assemblers that support this
+	// return an InteractiveIntent, which doesn't assemble the funding
+	// transaction itself.
+	Interactive bool
 }
 
 // Intent is returned by an Assembler and represents the base functionality the
@@ -555,6 +555,9 @@ func (w *WalletAssembler) ProvisionChannel(r *Request) (Intent, error) {
 		}
 
 		intent = newIntent
+		if r.Interactive {
+			intent = &InteractiveIntent{FullIntent: *newIntent}
+		}
 
 		return nil
 	})
@@ -2101,6 +2101,11 @@ func NewBreachRetribution(chanState *chanstate.OpenChannel, stateNum uint64,
 	auxResolver fn.Option[AuxContractResolver]) (*BreachRetribution,
 	error) {
 
+	// If the channel executed a dynamic commitment, then the revoked state
+	// may have been created with different parameters than the current
+	// ones, so we'll use the parameters in effect at that height.
+	chanState = chanState.ParamsAtHeight(lntypes.Remote, stateNum)
+
 	// Query the on-disk revocation log for the snapshot which was recorded
 	// at this particular state num. Based on whether a legacy revocation
 	// log is returned or not, we will process them differently.
@@ -2979,9 +2984,11 @@ func (lc *LightningChannel) fetchCommitmentView(
 
 // fundingTxIn returns the funding output as a transaction input. The input
 // returned by this function uses a max sequence number, so it isn't able to be
-// used with RBF by default.
+// used with RBF by default. Once the channel was spliced, this is the funding
+// output of the last locked splice.
 func fundingTxIn(chanState *chanstate.OpenChannel) wire.TxIn {
-	return *wire.NewTxIn(&chanState.FundingOutpoint, nil, nil)
+	fundingOutpoint := chanState.FundingTxOutpoint()
+	return *wire.NewTxIn(&fundingOutpoint, nil, nil)
 }
 
 // evaluateHTLCView processes all update entries in both HTLC update logs,
@@ -4447,6 +4454,11 @@ func (lc *LightningChannel) ProcessChanSyncMsg(ctx context.Context,
 	// bail out, otherwise we'll init our local session then continue as
 	// normal.
 	switch {
+	// If we locked a taproot upgrade the remote party didn't lock yet, it
+	// sends its nonce along with its SpliceLocked instead.
+	case lc.upgradeAwaitingRemoteLock():
+		break
+
 	case lc.channelState.ChanType.IsTaproot() && msg.LocalNonce.IsNone():
 		return nil, nil, nil, fmt.Errorf("remote verification nonce " +
 			"not sent")
@@ -4801,6 +4813,15 @@ func (lc *LightningChannel) computeView(view *HtlcView,
 	}
 	nextHeight := commitChain.tip().height + 1
 
+	// If a dynamic commitment added anchor outputs starting with this
+	// commitment, the initiator pays for them out of their balance.
+	anchorCost := lc.dynAnchorCost(whoseCommitChain, nextHeight)
+	if lc.channelState.IsInitiator {
+		ourBalance -= anchorCost
+	} else {
+		theirBalance -= anchorCost
+	}
+
 	// Initiate feePerKw to the last committed fee for this chain as we'll
 	// need this to determine which HTLCs are dust, and also the final fee
 	// rate.
@@ -5707,8 +5728,11 @@ func (lc *LightningChannel) oweCommitment(issuer lntypes.ChannelParty) bool {
 	}
 
 	// If any of the conditions above is true, we owe a commitment
-	// signature.
-	oweCommitment := localUpdatesPending || remoteUpdatesPending
+	// signature. We'll also owe one if the issuer's counterparty hasn't
+	// received a commitment using the parameters of the last dynamic
+	// commitment yet.
+	oweCommitment := localUpdatesPending || remoteUpdatesPending ||
+		lc.oweDynCommitment(issuer)
 
 	lc.log.Tracef("%v owes commit: %v (local updates: %v, "+
 		"remote updates %v)", perspective, oweCommitment,
@@ -6949,6 +6973,15 @@ func NewUnilateralCloseSummary(chanState *chanstate.OpenChannel,
 	auxResolver fn.Option[AuxContractResolver]) (*UnilateralCloseSummary,
 	error) {
 
+	// If we know which commitment was broadcast, then we'll use the
+	// channel parameters that were in effect for it, as a dynamic
+	// commitment may have changed them since.
+	if remoteCommit.CommitTx != nil {
+		chanState = chanState.ParamsAtHeight(
+			lntypes.Remote, remoteCommit.CommitHeight,
+		)
+	}
+
 	// First, we'll generate the commitment point and the revocation point
 	// so we can re-construct the HTLC state and also our payment key.
 	commitType := lntypes.Remote
@@ -8263,6 +8296,10 @@ func NewLocalForceCloseSummary(chanState *chanstate.OpenChannel,
 	auxResolver fn.Option[AuxContractResolver]) (*LocalForceCloseSummary,
 	error) {
 
+	// Use the channel parameters in effect for the commitment being
+	// broadcast, as a dynamic commitment may have changed them since.
+	chanState = chanState.ParamsAtHeight(lntypes.Local, stateNum)
+
 	// Re-derive the original pkScript for to-self output within the
 	// commitment transaction. We'll need this to find the corresponding
 	// output in the commitment transaction and potentially for creating
@@ -2,7 +2,7 @@ package lnwallet
 
 import (
 	"github.com/flokiorg/flnd/chainntnfs"
-	"github.com/flokiorg/flnd/channeldb"
+	"github.com/flokiorg/flnd/chanstate"
 	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/input"
 	"github.com/flokiorg/flnd/keychain"
@@ -20,7 +20,7 @@ type Config struct {
 	// Database is a wrapper around a namespace within boltdb reserved for
 	// ln-based wallet metadata. See the 'channeldb' package for further
 	// information.
-	Database *channeldb.ChannelStateDB
+	Database chanstate.Store
 
 	// Notifier is used by in order to obtain notifications about funding
 	// transaction reaching a specified confirmation depth, and to catch
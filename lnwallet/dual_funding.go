@@ -0,0 +1,171 @@
+package lnwallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/input"
+	"github.com/flokiorg/flnd/lnwallet/chanfunding"
+	"github.com/flokiorg/go-flokicoin/txscript"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+var (
+	// ErrInteractiveFundingRequired is the error that is returned during
+	// the contribution handling process if the process should be paused
+	// for the interactive construction of the funding transaction.
+	ErrInteractiveFundingRequired = errors.New("interactive funding " +
+		"required")
+
+	// ErrNotDualFunded is returned when an operation that's specific to
+	// dual-funded channels is called on another reservation.
+	ErrNotDualFunded = errors.New("reservation isn't dual-funded")
+)
+
+// InteractiveFundingRequired is a type that implements the error interface
+// and contains the intent whose funding transaction must be constructed
+// interactively with the remote party.
+type InteractiveFundingRequired struct {
+	// Intent is the pending interactive funding intent that needs to be
+	// funded if the wrapping error is returned.
+	Intent *chanfunding.InteractiveIntent
+}
+
+// Error returns the underlying error.
+//
+// NOTE: This method is part of the error interface.
+func (i *InteractiveFundingRequired) Error() string {
+	return ErrInteractiveFundingRequired.Error()
+}
+
+// IsDualFunded returns true if the funding transaction of this reservation is
+// constructed interactively with the remote party.
+func (r *ChannelReservation) IsDualFunded() bool {
+	r.RLock()
+	defer r.RUnlock()
+
+	_, ok := r.fundingIntent.(*chanfunding.InteractiveIntent)
+	return ok
+}
+
+// interactiveIntent returns the interactive funding intent of the
+// reservation.
+//
+// NOTE: The reservation's lock must be held.
+func (r *ChannelReservation) interactiveIntent() (
+	*chanfunding.InteractiveIntent, error) {
+
+	intent, ok := r.fundingIntent.(*chanfunding.InteractiveIntent)
+	if !ok {
+		return nil, ErrNotDualFunded
+	}
+
+	return intent, nil
+}
+
+// InteractiveFunding returns the wallet outputs we contribute to the funding
+// transaction of a dual-funded channel, along with our change outputs. The
+// outputs carry their previous transaction, as it must be sent to the remote
+// party.
+func (r *ChannelReservation) InteractiveFunding() ([]*Utxo, []*wire.TxOut,
+	error) {
+
+	r.RLock()
+	defer r.RUnlock()
+
+	intent, err := r.interactiveIntent()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	utxos := make([]*Utxo, 0, len(intent.InputCoins))
+	for _, coin := range intent.InputCoins {
+		utxo, err := r.wallet.FetchOutpointInfo(&coin.OutPoint)
+		if err != nil {
+			return nil, nil, err
+		}
+		if utxo.PrevTx == nil {
+			return nil, nil, fmt.Errorf("previous transaction of "+
+				"%v unknown", coin.OutPoint)
+		}
+
+		utxos = append(utxos, utxo)
+	}
+
+	return utxos, intent.ChangeOutputs, nil
+}
+
+// FundingScript returns the script of the funding output of a dual-funded
+// channel. It's known once the contribution of the remote party has been
+// processed.
+func (r *ChannelReservation) FundingScript() ([]byte, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	if _, err := r.interactiveIntent(); err != nil {
+		return nil, err
+	}
+
+	ourKey := r.ourContribution.MultiSigKey.PubKey
+	theirKey := r.theirContribution.MultiSigKey.PubKey
+	if ourKey == nil || theirKey == nil {
+		return nil, fmt.Errorf("multisig keys unknown")
+	}
+
+	_, fundingOutput, err := input.GenFundingPkScript(
+		ourKey.SerializeCompressed(), theirKey.SerializeCompressed(),
+		int64(r.partialState.Capacity),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return fundingOutput.PkScript, nil
+}
+
+// ProcessInteractiveTx continues a funding flow that was paused for the
+// interactive construction of the funding transaction. Once called, both
+// commitment transactions are created and the remote one is signed.
+//
+// NOTE: This must only be called by the initiator of the channel, the
+// responder processes the funding outpoint in CompleteReservationSingle.
+func (r *ChannelReservation) ProcessInteractiveTx(tx *wire.MsgTx) error {
+	r.Lock()
+	intent, err := r.interactiveIntent()
+	if err == nil {
+		err = intent.SetFundingTx(tx)
+	}
+	r.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	errChan := make(chan error, 1)
+
+	r.wallet.msgChan <- &continueContributionMsg{
+		auxFundingDesc:   fn.None[AuxFundingDesc](),
+		pendingFundingID: r.reservationID,
+		err:              errChan,
+	}
+
+	return <-errChan
+}
+
+// SignInteractiveInputs signs the inputs we contributed to the passed
+// interactively constructed funding transaction. The witnesses are returned
+// in the order of our inputs within the transaction.
+func (r *ChannelReservation) SignInteractiveInputs(tx *wire.MsgTx,
+	prevOuts txscript.PrevOutputFetcher) ([]wire.TxWitness, error) {
+
+	r.RLock()
+	defer r.RUnlock()
+
+	intent, err := r.interactiveIntent()
+	if err != nil {
+		return nil, err
+	}
+
+	return intent.SignInputs(tx, prevOuts)
+}
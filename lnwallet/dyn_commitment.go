@@ -0,0 +1,480 @@
+package lnwallet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/flokiorg/flnd/channeldb"
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/input"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+)
+
+var (
+	// ErrDynCommitTaproot is returned when a dynamic commitment attempts
+	// to change the type of a taproot channel. Taproot is the final
+	// commitment format supported by dynamic commitments.
+	ErrDynCommitTaproot = errors.New("the channel type of taproot " +
+		"channels cannot be changed")
+
+	// ErrDynCommitTaprootPublic is returned when a dynamic commitment
+	// attempts to upgrade an announced channel to taproot. Taproot
+	// channels can't be announced yet.
+	ErrDynCommitTaprootPublic = errors.New("only private channels can " +
+		"be upgraded to taproot")
+
+	// ErrDynCommitTaprootParams is returned when a taproot upgrade also
+	// proposes changes to other channel parameters. Since the upgrade
+	// moves the channel to a new funding output, it's executed on its
+	// own.
+	ErrDynCommitTaprootParams = errors.New("a taproot upgrade can't " +
+		"change any other channel parameter")
+
+	// ErrDynCommitTaprootSplice is returned when a taproot upgrade is
+	// applied like any other dynamic commitment instead of being carried
+	// out by a splice.
+	ErrDynCommitTaprootSplice = errors.New("taproot upgrades take " +
+		"effect once their splice is locked")
+
+	// ErrDynCommitChanType is returned when a dynamic commitment proposes
+	// a channel type that is not supported as an upgrade target.
+	ErrDynCommitChanType = errors.New("unsupported dynamic commitment " +
+		"channel type")
+
+	// ErrDynCommitDowngrade is returned when a dynamic commitment
+	// attempts to remove features from the current channel type.
+	ErrDynCommitDowngrade = errors.New("dynamic commitments can only " +
+		"upgrade the channel type")
+
+	// ErrDynCommitLease is returned when a dynamic commitment attempts to
+	// change the type of a script enforced lease channel.
+	ErrDynCommitLease = errors.New("the channel type of leased channels " +
+		"cannot be changed")
+
+	// ErrDynCommitActiveHtlcs is returned when a dynamic commitment would
+	// change the format of the commitment transaction while HTLCs are
+	// still active on the channel.
+	ErrDynCommitActiveHtlcs = errors.New("the channel type, dust limit " +
+		"and csv delay can only be changed while no HTLCs are active")
+
+	// ErrDynCommitNotClean is returned when a dynamic commitment is
+	// attempted while either commitment has pending updates.
+	ErrDynCommitNotClean = errors.New("channel has pending updates")
+
+	// ErrDynCommitFunderBalance is returned when the channel initiator
+	// can't afford the commitment transaction of the proposed channel
+	// type.
+	ErrDynCommitFunderBalance = errors.New("channel initiator can't " +
+		"afford the commitment fee of the new channel type")
+)
+
+// dynCommitFormatBits are the channel type bits that describe the format of
+// the commitment transaction and can be changed by a dynamic commitment.
+const dynCommitFormatBits = channeldb.SingleFunderTweaklessBit |
+	channeldb.AnchorOutputsBit | channeldb.ZeroHtlcTxFeeBit
+
+// dynCommitTaprootBits are the channel type bits that can be set by a
+// dynamic commitment that upgrades the channel to taproot.
+const dynCommitTaprootBits = dynCommitFormatBits |
+	channeldb.SimpleTaprootFeatureBit | channeldb.TaprootFinalBit
+
+// DynChannelType returns the channel type that results from applying the
+// commitment format proposed by a DynPropose to the current channel type.
+// The legacy, static remote key, zero fee anchor and simple taproot formats
+// can be proposed.
+func DynChannelType(current channeldb.ChannelType,
+	proposed lnwire.ChannelType) (channeldb.ChannelType, error) {
+
+	features := lnwire.RawFeatureVector(proposed)
+
+	var formatBits channeldb.ChannelType
+	switch {
+	// Taproot channels imply the zero fee anchor format and are moved to
+	// a MuSig2 funding output as part of the upgrade.
+	case features.OnlyContains(lnwire.SimpleTaprootChannelsRequiredStaging):
+		formatBits = dynCommitFormatBits | channeldb.SimpleTaprootFeatureBit
+
+	case features.OnlyContains(lnwire.SimpleTaprootChannelsRequiredFinal):
+		formatBits = dynCommitFormatBits |
+			channeldb.SimpleTaprootFeatureBit |
+			channeldb.TaprootFinalBit
+
+	// An empty channel type denotes the legacy commitment format.
+	case features.OnlyContains():
+
+	case features.OnlyContains(lnwire.StaticRemoteKeyRequired):
+		formatBits = channeldb.SingleFunderTweaklessBit
+
+	case features.OnlyContains(
+		lnwire.AnchorsZeroFeeHtlcTxRequired,
+		lnwire.StaticRemoteKeyRequired,
+	):
+		formatBits = dynCommitFormatBits
+
+	default:
+		return 0, ErrDynCommitChanType
+	}
+
+	return current&^dynCommitTaprootBits | formatBits, nil
+}
+
+// validateDynChanType checks that switching the channel from the current to
+// the next channel type is a supported upgrade.
+func validateDynChanType(current, next channeldb.ChannelType) error {
+	switch {
+	case current == next:
+		return nil
+
+	case current.IsTaproot():
+		return ErrDynCommitTaproot
+
+	case current.HasLeaseExpiration():
+		return ErrDynCommitLease
+
+	// Everything but the commitment format must stay untouched.
+	case current&^dynCommitTaprootBits != next&^dynCommitTaprootBits:
+		return ErrDynCommitChanType
+
+	// Features can only be added, never removed.
+	case current&next != current:
+		return ErrDynCommitDowngrade
+
+	// Anchor outputs are only supported along with zero fee second level
+	// HTLC transactions.
+	case next.HasAnchors() && !next.ZeroHtlcTxFee():
+		return ErrDynCommitChanType
+	}
+
+	return nil
+}
+
+// DynProposeParams returns the channel parameters that result from applying
+// the passed DynPropose, sent by the given party, to the current channel
+// parameters. The dust limit applies to the sender, while all other values
+// constrain the recipient.
+func (lc *LightningChannel) DynProposeParams(msg *lnwire.DynPropose,
+	sender lntypes.ChannelParty) (channeldb.ChannelParams, error) {
+
+	params := lc.channelState.ChannelParams()
+
+	senderParams, recipientParams := &params.LocalParams,
+		&params.RemoteParams
+	recipientBounds := &params.RemoteBounds
+	if sender.IsRemote() {
+		senderParams, recipientParams = &params.RemoteParams,
+			&params.LocalParams
+		recipientBounds = &params.LocalBounds
+	}
+
+	type amount = tlv.BigSizeT[chainutil.Amount]
+
+	msg.DustLimit.WhenSomeV(func(dust amount) {
+		senderParams.DustLimit = dust.Int()
+	})
+	msg.MaxValueInFlight.WhenSomeV(func(maxValue lnwire.MilliLoki) {
+		recipientBounds.MaxPendingAmount = maxValue
+	})
+	msg.HtlcMinimum.WhenSomeV(func(minHtlc lnwire.MilliLoki) {
+		recipientBounds.MinHTLC = minHtlc
+	})
+	msg.ChannelReserve.WhenSomeV(func(reserve amount) {
+		recipientBounds.ChanReserve = reserve.Int()
+	})
+	msg.CsvDelay.WhenSomeV(func(delay uint16) {
+		recipientParams.CsvDelay = delay
+	})
+	msg.MaxAcceptedHTLCs.WhenSomeV(func(maxHtlcs uint16) {
+		recipientBounds.MaxAcceptedHtlcs = maxHtlcs
+	})
+
+	var err error
+	msg.ChannelType.WhenSomeV(func(chanType lnwire.ChannelType) {
+		params.ChanType, err = DynChannelType(
+			params.ChanType, chanType,
+		)
+	})
+	if err != nil {
+		return channeldb.ChannelParams{}, err
+	}
+
+	return params, nil
+}
+
+// ValidateDynCommitment checks whether the channel can switch over to the
+// passed parameters. The maxLocalCSVDelay bounds the delay that the remote
+// party may impose on our own outputs.
+func (lc *LightningChannel) ValidateDynCommitment(
+	params channeldb.ChannelParams, maxLocalCSVDelay uint16) error {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	chanState := lc.channelState
+	current := chanState.ChannelParams()
+
+	if lc.commitChains.Local.hasUnackedCommitment() ||
+		lc.commitChains.Remote.hasUnackedCommitment() ||
+		lc.oweCommitment(lntypes.Local) ||
+		lc.oweCommitment(lntypes.Remote) {
+
+		return ErrDynCommitNotClean
+	}
+
+	if chanState.DynCommitmentPending() {
+		return chanstate.ErrDynCommitmentInProgress
+	}
+
+	if err := validateDynChanType(
+		current.ChanType, params.ChanType,
+	); err != nil {
+		return err
+	}
+
+	// An upgrade to taproot moves the channel to a new funding output,
+	// which is only done for private channels without any other change.
+	if IsTaprootUpgrade(current.ChanType, params.ChanType) {
+		next := params
+		next.ChanType = current.ChanType
+
+		switch {
+		case chanState.ChannelFlags&lnwire.FFAnnounceChannel != 0:
+			return ErrDynCommitTaprootPublic
+
+		case next != current:
+			return ErrDynCommitTaprootParams
+
+		case chanState.PendingSpliceInfo().IsSome():
+			return chanstate.ErrSpliceInProgress
+		}
+	}
+
+	// Changing any of the values that are used to render the commitment
+	// transaction would also change the HTLC outputs, so we only allow
+	// this if there are none.
+	formatChanged := current.ChanType != params.ChanType ||
+		current.LocalParams != params.LocalParams ||
+		current.RemoteParams != params.RemoteParams
+	if formatChanged && len(chanState.ActiveHtlcs()) != 0 {
+		return ErrDynCommitActiveHtlcs
+	}
+
+	// We'll only sanity check the values that were changed, as an
+	// existing channel may legitimately fall outside of the bounds we
+	// enforce for new channels today.
+	err := verifyDynConstraints(
+		&current.LocalBounds, &params.LocalBounds,
+		&current.LocalParams, &params.LocalParams, maxLocalCSVDelay,
+		chanState.Capacity,
+	)
+	if err != nil {
+		return err
+	}
+	err = verifyDynConstraints(
+		&current.RemoteBounds, &params.RemoteBounds,
+		&current.RemoteParams, &params.RemoteParams, math.MaxUint16,
+		chanState.Capacity,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Both reserves need to stay above both dust limits so that neither
+	// party can end up with an output that can't be materialized.
+	minReserve := min(
+		params.LocalBounds.ChanReserve, params.RemoteBounds.ChanReserve,
+	)
+	maxDust := max(
+		params.LocalParams.DustLimit, params.RemoteParams.DustLimit,
+	)
+	limitsChanged := current.LocalBounds.ChanReserve !=
+		params.LocalBounds.ChanReserve ||
+		current.RemoteBounds.ChanReserve !=
+			params.RemoteBounds.ChanReserve ||
+		current.LocalParams.DustLimit != params.LocalParams.DustLimit ||
+		current.RemoteParams.DustLimit != params.RemoteParams.DustLimit
+	if limitsChanged && minReserve < maxDust {
+		return ErrChanReserveTooSmall(minReserve, maxDust)
+	}
+
+	if current.ChanType == params.ChanType {
+		return nil
+	}
+
+	// A new channel type may make the commitment transaction heavier and
+	// add anchor outputs, both of which are paid for by the initiator. So
+	// we'll make sure they can still afford the commitment at the current
+	// fee rate.
+	commit := chanState.LocalCommitment
+	feePerKw := chainfee.SatPerKWeight(commit.FeePerKw)
+	funderBalance := commit.RemoteBalance.ToLokis()
+	if chanState.IsInitiator {
+		funderBalance = commit.LocalBalance.ToLokis()
+	}
+
+	available := funderBalance + commit.CommitFee +
+		dynAnchorCost(current.ChanType)
+	required := feePerKw.FeeForWeight(CommitWeight(params.ChanType)) +
+		dynAnchorCost(params.ChanType)
+	if available < required {
+		return fmt.Errorf("%w: required=%v, available=%v",
+			ErrDynCommitFunderBalance, required, available)
+	}
+
+	return nil
+}
+
+// verifyDynConstraints applies the checks of VerifyConstraints to the values
+// of one side of the channel that differ between the current and the next
+// parameters.
+func verifyDynConstraints(curBounds, nextBounds *channeldb.ChannelStateBounds,
+	curParams, nextParams *channeldb.CommitmentParams,
+	maxLocalCSVDelay uint16, channelCapacity chainutil.Amount) error {
+
+	if nextParams.CsvDelay != curParams.CsvDelay &&
+		nextParams.CsvDelay > maxLocalCSVDelay {
+
+		return ErrCsvDelayTooLarge(
+			nextParams.CsvDelay, maxLocalCSVDelay,
+		)
+	}
+
+	maxWitnessLimit := DustLimitForSize(input.UnknownWitnessSize)
+	if nextParams.DustLimit != curParams.DustLimit &&
+		(nextParams.DustLimit < maxWitnessLimit ||
+			nextParams.DustLimit > 3*maxWitnessLimit) {
+
+		return ErrInvalidDustLimit(nextParams.DustLimit)
+	}
+
+	maxChanReserve := channelCapacity / 5
+	if nextBounds.ChanReserve != curBounds.ChanReserve &&
+		nextBounds.ChanReserve > maxChanReserve {
+
+		return ErrChanReserveTooLarge(
+			nextBounds.ChanReserve, maxChanReserve,
+		)
+	}
+
+	htlcLimitsChanged := nextBounds.MinHTLC != curBounds.MinHTLC ||
+		nextBounds.MaxPendingAmount != curBounds.MaxPendingAmount
+	if htlcLimitsChanged &&
+		nextBounds.MinHTLC > nextBounds.MaxPendingAmount {
+
+		return ErrMinHtlcTooLarge(
+			nextBounds.MinHTLC, nextBounds.MaxPendingAmount,
+		)
+	}
+
+	const minNumHtlc = 5
+	if nextBounds.MaxAcceptedHtlcs != curBounds.MaxAcceptedHtlcs {
+		maxNumHtlc := uint16(input.MaxHTLCNumber / 2)
+		if nextBounds.MaxAcceptedHtlcs > maxNumHtlc {
+			return ErrMaxHtlcNumTooLarge(
+				nextBounds.MaxAcceptedHtlcs, maxNumHtlc,
+			)
+		}
+		if nextBounds.MaxAcceptedHtlcs < minNumHtlc {
+			return ErrMaxHtlcNumTooSmall(
+				nextBounds.MaxAcceptedHtlcs, minNumHtlc,
+			)
+		}
+	}
+
+	if htlcLimitsChanged &&
+		nextBounds.MaxPendingAmount < minNumHtlc*nextBounds.MinHTLC {
+
+		return ErrMaxValueInFlightTooSmall(
+			nextBounds.MaxPendingAmount,
+			minNumHtlc*nextBounds.MinHTLC,
+		)
+	}
+
+	return nil
+}
+
+// dynAnchorCost returns the amount the channel initiator pays for the anchor
+// outputs of the given channel type.
+func dynAnchorCost(chanType channeldb.ChannelType) chainutil.Amount {
+	if !chanType.HasAnchors() {
+		return 0
+	}
+
+	return 2 * AnchorSize
+}
+
+// dynAnchorCost returns the amount that is deducted from the initiator's
+// balance because the commitment of the given party at the given height is the
+// first one to carry the anchor outputs introduced by a dynamic commitment.
+// This function expects to be executed with a lock held.
+func (lc *LightningChannel) dynAnchorCost(party lntypes.ChannelParty,
+	height uint64) lnwire.MilliLoki {
+
+	dyn, ok := lc.channelState.LastDynCommitment()
+	if !ok {
+		return 0
+	}
+
+	switchHeight := dyn.LocalCommitHeight
+	if party.IsRemote() {
+		switchHeight = dyn.RemoteCommitHeight
+	}
+
+	if height != switchHeight || dyn.PrevChanType.HasAnchors() ||
+		!lc.channelState.ChanType.HasAnchors() {
+
+		return 0
+	}
+
+	return lnwire.NewMSatFromLokis(dynAnchorCost(lc.channelState.ChanType))
+}
+
+// ApplyDynCommitment switches the channel over to the passed parameters. The
+// next commitment of each party will be the first one to use them. The msg is
+// the serialized DynCommit that executed the update.
+func (lc *LightningChannel) ApplyDynCommitment(
+	params channeldb.ChannelParams, msg []byte) error {
+
+	lc.Lock()
+	defer lc.Unlock()
+
+	// An upgrade to taproot takes effect once the splice moving the
+	// channel to its new funding output is locked.
+	if IsTaprootUpgrade(lc.channelState.ChanType, params.ChanType) {
+		return ErrDynCommitTaprootSplice
+	}
+
+	return lc.channelState.ApplyDynCommitment(params, msg)
+}
+
+// DynCommitmentPending returns true if a dynamic commitment was applied, but
+// at least one of the current commitments still uses the previous parameters.
+func (lc *LightningChannel) DynCommitmentPending() bool {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	return lc.channelState.DynCommitmentPending()
+}
+
+// oweDynCommitment returns true if the issuer still needs to sign a
+// commitment for the other party in order to complete the last dynamic
+// commitment. This function expects to be executed with a lock held.
+func (lc *LightningChannel) oweDynCommitment(
+	issuer lntypes.ChannelParty) bool {
+
+	dyn, ok := lc.channelState.LastDynCommitment()
+	if !ok {
+		return false
+	}
+
+	if issuer.IsLocal() {
+		return lc.commitChains.Remote.tip().height <
+			dyn.RemoteCommitHeight
+	}
+
+	return lc.commitChains.Local.tip().height < dyn.LocalCommitHeight
+}
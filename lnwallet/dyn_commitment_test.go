@@ -0,0 +1,311 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/flokiorg/flnd/channeldb"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/stretchr/testify/require"
+)
+
+// dynChanTypeRecord wraps the passed feature bits into the channel type
+// record of a DynPropose.
+func dynChanTypeRecord(bits ...lnwire.FeatureBit) tlv.OptionalRecordT[
+	tlv.TlvType12, lnwire.ChannelType] {
+
+	return tlv.SomeRecordT(tlv.NewRecordT[tlv.TlvType12](
+		lnwire.ChannelType(*lnwire.NewRawFeatureVector(bits...)),
+	))
+}
+
+// TestDynChannelType asserts that only the legacy, static remote key, zero fee
+// anchor and simple taproot commitment formats can be proposed by a dynamic
+// commitment.
+func TestDynChannelType(t *testing.T) {
+	t.Parallel()
+
+	tweakless := channeldb.SingleFunderTweaklessBit
+	anchors := tweakless | channeldb.AnchorOutputsBit |
+		channeldb.ZeroHtlcTxFeeBit
+
+	tests := []struct {
+		name     string
+		current  channeldb.ChannelType
+		proposed []lnwire.FeatureBit
+		expected channeldb.ChannelType
+		err      error
+	}{
+		{
+			name:     "legacy",
+			current:  tweakless,
+			expected: channeldb.SingleFunderBit,
+		},
+		{
+			name:    "static remote key",
+			current: channeldb.SingleFunderBit,
+			proposed: []lnwire.FeatureBit{
+				lnwire.StaticRemoteKeyRequired,
+			},
+			expected: tweakless,
+		},
+		{
+			name:    "anchors",
+			current: channeldb.SingleFunderBit,
+			proposed: []lnwire.FeatureBit{
+				lnwire.StaticRemoteKeyRequired,
+				lnwire.AnchorsZeroFeeHtlcTxRequired,
+			},
+			expected: anchors,
+		},
+		{
+			name: "unrelated bits are kept",
+			current: channeldb.SingleFunderBit |
+				channeldb.ScidAliasChanBit,
+			proposed: []lnwire.FeatureBit{
+				lnwire.StaticRemoteKeyRequired,
+				lnwire.AnchorsZeroFeeHtlcTxRequired,
+			},
+			expected: anchors | channeldb.ScidAliasChanBit,
+		},
+		{
+			name:    "taproot",
+			current: anchors,
+			proposed: []lnwire.FeatureBit{
+				lnwire.SimpleTaprootChannelsRequiredFinal,
+			},
+			expected: anchors | channeldb.SimpleTaprootFeatureBit |
+				channeldb.TaprootFinalBit,
+		},
+		{
+			name:    "taproot staging",
+			current: channeldb.SingleFunderBit,
+			proposed: []lnwire.FeatureBit{
+				lnwire.SimpleTaprootChannelsRequiredStaging,
+			},
+			expected: anchors | channeldb.SimpleTaprootFeatureBit,
+		},
+		{
+			name:    "anchors without static remote key",
+			current: tweakless,
+			proposed: []lnwire.FeatureBit{
+				lnwire.AnchorsZeroFeeHtlcTxRequired,
+			},
+			err: ErrDynCommitChanType,
+		},
+		{
+			name:    "script enforced lease",
+			current: anchors,
+			proposed: []lnwire.FeatureBit{
+				lnwire.StaticRemoteKeyRequired,
+				lnwire.AnchorsZeroFeeHtlcTxRequired,
+				lnwire.ScriptEnforcedLeaseRequired,
+			},
+			err: ErrDynCommitChanType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			chanType, err := DynChannelType(
+				test.current, lnwire.ChannelType(
+					*lnwire.NewRawFeatureVector(
+						test.proposed...,
+					),
+				),
+			)
+			require.ErrorIs(t, err, test.err)
+			require.Equal(t, test.expected, chanType)
+		})
+	}
+}
+
+// TestValidateDynChanType asserts that dynamic commitments can only upgrade
+// the commitment format of non-taproot, non-lease channels.
+func TestValidateDynChanType(t *testing.T) {
+	t.Parallel()
+
+	tweakless := channeldb.SingleFunderTweaklessBit
+	anchors := tweakless | channeldb.AnchorOutputsBit |
+		channeldb.ZeroHtlcTxFeeBit
+
+	tests := []struct {
+		name          string
+		current, next channeldb.ChannelType
+		err           error
+	}{
+		{
+			name:    "unchanged",
+			current: anchors,
+			next:    anchors,
+		},
+		{
+			name:    "legacy to anchors",
+			current: channeldb.SingleFunderBit,
+			next:    anchors,
+		},
+		{
+			name:    "downgrade",
+			current: anchors,
+			next:    tweakless,
+			err:     ErrDynCommitDowngrade,
+		},
+		{
+			name:    "taproot",
+			current: anchors | channeldb.SimpleTaprootFeatureBit,
+			next:    anchors,
+			err:     ErrDynCommitTaproot,
+		},
+		{
+			name:    "anchors to taproot",
+			current: anchors,
+			next:    anchors | channeldb.SimpleTaprootFeatureBit,
+		},
+		{
+			name:    "lease",
+			current: anchors | channeldb.LeaseExpirationBit,
+			next:    anchors,
+			err:     ErrDynCommitLease,
+		},
+		{
+			name:    "non format bits",
+			current: tweakless,
+			next:    tweakless | channeldb.ScidAliasChanBit,
+			err:     ErrDynCommitChanType,
+		},
+		{
+			name:    "anchors with htlc fees",
+			current: tweakless,
+			next:    tweakless | channeldb.AnchorOutputsBit,
+			err:     ErrDynCommitChanType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateDynChanType(test.current, test.next)
+			require.ErrorIs(t, err, test.err)
+		})
+	}
+}
+
+// TestDynCommitmentUpgrade asserts that a static remote key channel can be
+// upgraded to anchors by a dynamic commitment, and that the new commitment
+// format takes effect with the next state transition.
+func TestDynCommitmentUpgrade(t *testing.T) {
+	t.Parallel()
+
+	aliceChannel, bobChannel, err := CreateTestChannels(
+		t, channeldb.SingleFunderTweaklessBit,
+	)
+	require.NoError(t, err, "unable to create test channels")
+
+	propose := &lnwire.DynPropose{
+		ChannelType: dynChanTypeRecord(
+			lnwire.StaticRemoteKeyRequired,
+			lnwire.AnchorsZeroFeeHtlcTxRequired,
+		),
+		CsvDelay: tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType8, uint16](200),
+		),
+	}
+
+	// Alice proposes the upgrade, so the csv delay applies to Bob's
+	// outputs.
+	aliceParams, err := aliceChannel.DynProposeParams(
+		propose, lntypes.Local,
+	)
+	require.NoError(t, err)
+	require.True(t, aliceParams.ChanType.HasAnchors())
+	require.EqualValues(t, 200, aliceParams.RemoteParams.CsvDelay)
+
+	bobParams, err := bobChannel.DynProposeParams(propose, lntypes.Remote)
+	require.NoError(t, err)
+	require.EqualValues(t, 200, bobParams.LocalParams.CsvDelay)
+
+	const maxCSV = 1000
+	require.NoError(t, aliceChannel.ValidateDynCommitment(
+		aliceParams, maxCSV,
+	))
+	require.NoError(t, bobChannel.ValidateDynCommitment(
+		bobParams, maxCSV,
+	))
+
+	require.NoError(t, aliceChannel.ApplyDynCommitment(aliceParams, nil))
+	require.NoError(t, bobChannel.ApplyDynCommitment(bobParams, nil))
+
+	// Both parties now owe each other a commitment that uses the new
+	// format, even though there are no updates.
+	require.True(t, aliceChannel.DynCommitmentPending())
+	require.True(t, aliceChannel.OweCommitment())
+	require.True(t, bobChannel.OweCommitment())
+
+	// A second dynamic commitment can't be started before the first one
+	// completed.
+	err = aliceChannel.ValidateDynCommitment(aliceParams, maxCSV)
+	require.Error(t, err)
+
+	require.NoError(t, ForceStateTransition(aliceChannel, bobChannel))
+
+	require.False(t, aliceChannel.DynCommitmentPending())
+	require.False(t, bobChannel.DynCommitmentPending())
+	require.False(t, aliceChannel.OweCommitment())
+	require.False(t, bobChannel.OweCommitment())
+
+	// The new commitments carry the two anchor outputs next to the
+	// balances of both parties.
+	for _, c := range []*LightningChannel{aliceChannel, bobChannel} {
+		commitTx := c.State().LocalCommitment.CommitTx
+		require.Len(t, commitTx.TxOut, 4)
+	}
+
+	// The revoked commitments still resolve with the previous format.
+	state := aliceChannel.State()
+	require.Equal(t, channeldb.SingleFunderTweaklessBit, state.CommitTypeAt(
+		lntypes.Remote, state.RemoteCommitment.CommitHeight-1,
+	))
+}
+
+// TestValidateDynCommitmentActiveHtlcs asserts that the commitment format
+// can't be changed while HTLCs are active, while the HTLC limits can.
+func TestValidateDynCommitmentActiveHtlcs(t *testing.T) {
+	t.Parallel()
+
+	aliceChannel, bobChannel, err := CreateTestChannels(
+		t, channeldb.SingleFunderTweaklessBit,
+	)
+	require.NoError(t, err, "unable to create test channels")
+
+	htlc, _ := createHTLC(0, lnwire.NewMSatFromLokis(10_000))
+	_, err = aliceChannel.AddHTLC(htlc, nil)
+	require.NoError(t, err)
+	_, err = bobChannel.ReceiveHTLC(htlc)
+	require.NoError(t, err)
+
+	params := aliceChannel.State().ChannelParams()
+	params.RemoteBounds.MaxAcceptedHtlcs--
+
+	// With the HTLC not yet locked in, the channel isn't clean.
+	err = aliceChannel.ValidateDynCommitment(params, 1000)
+	require.ErrorIs(t, err, ErrDynCommitNotClean)
+
+	require.NoError(t, ForceStateTransition(aliceChannel, bobChannel))
+	require.NoError(t, aliceChannel.ValidateDynCommitment(params, 1000))
+
+	propose := &lnwire.DynPropose{
+		ChannelType: dynChanTypeRecord(
+			lnwire.StaticRemoteKeyRequired,
+			lnwire.AnchorsZeroFeeHtlcTxRequired,
+		),
+	}
+	params, err = aliceChannel.DynProposeParams(propose, lntypes.Local)
+	require.NoError(t, err)
+
+	err = aliceChannel.ValidateDynCommitment(params, 1000)
+	require.ErrorIs(t, err, ErrDynCommitActiveHtlcs)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/flokiorg/go-flokicoin/chainutil/hdkeychain"
 	"github.com/flokiorg/go-flokicoin/chainutil/psbt"
 	"github.com/flokiorg/go-flokicoin/crypto/ecdsa"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr"
 	"github.com/flokiorg/go-flokicoin/txscript"
 	"github.com/flokiorg/go-flokicoin/wire"
 	"github.com/flokiorg/walletd/waddrmgr"
@@ -604,6 +605,15 @@ type BlockChainIO interface {
 	GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error)
 }
 
+// BlockTxFetcher is an optional interface a BlockChainIO can implement if it's
+// able to fetch a single transaction of a block without fetching the entire
+// block, as is the case for backends that can't serve full blocks.
+type BlockTxFetcher interface {
+	// GetBlockTransaction returns the transaction at the given index of
+	// the main chain block at the given height.
+	GetBlockTransaction(blockHeight, txIndex uint32) (*wire.MsgTx, error)
+}
+
 // MessageSigner represents an abstract object capable of signing arbitrary
 // messages. The capabilities of this interface are used to sign announcements
 // to the network, or just arbitrary messages that leverage the wallet's keys
@@ -617,6 +627,22 @@ type MessageSigner interface {
 		doubleHash bool) (*ecdsa.Signature, error)
 }
 
+// SchnorrMessageSigner is a MessageSigner that is additionally able to produce
+// BIP-340 Schnorr signatures. This is required to sign the taproot based
+// (v2) gossip messages.
+type SchnorrMessageSigner interface {
+	MessageSigner
+
+	// SignMessageSchnorr attempts to sign a target message with the
+	// private key described in the key locator, optionally tweaked by the
+	// given taproot tweak. If a tag is provided, the tagged hash of the
+	// message is signed, otherwise the single or double SHA-256 of the
+	// message is.
+	SignMessageSchnorr(keyLoc keychain.KeyLocator, msg []byte,
+		doubleHash bool, taprootTweak []byte,
+		tag []byte) (*schnorr.Signature, error)
+}
+
 // AddrWithKey wraps a normal addr, but also includes the internal key for the
 // delivery addr if known.
 type AddrWithKey struct {
@@ -812,6 +838,14 @@ func FetchFundingTxWrapper(chain BlockChainIO, chanID lnwire.ShortChannelID,
 func FetchFundingTx(chain BlockChainIO,
 	chanID lnwire.ShortChannelID) (*wire.MsgTx, error) {
 
+	// If the backend is able to fetch the transaction on its own, there's
+	// no need to fetch the entire block.
+	if fetcher, ok := chain.(BlockTxFetcher); ok {
+		return fetcher.GetBlockTransaction(
+			chanID.BlockHeight, chanID.TxIndex,
+		)
+	}
+
 	// First fetch the block hash by the block number encoded, then use
 	// that hash to fetch the block itself.
 	blockNum := int64(chanID.BlockHeight)
@@ -320,10 +320,39 @@ func NewChannelReservation(capacity, localFundingAmt chainutil.Amount,
 	// Used to cut down on verbosity.
 	defaultDust := DustLimitUnknownWitness()
 
+	switch {
+	// In a dual-funded channel each party starts with the amount it
+	// contributed to the funding output, and the initiator pays all fees.
+	// The initiator learns about the remote contribution only once the
+	// channel was accepted, so it's added to the reservation later on. The
+	// initiator only pushes funds to pay for leased inbound liquidity.
+	case req.DualFunded:
+		initiator = req.RemoteFundingAmt == 0
+		if initiator {
+			ourBalance = localFundingMSat - feeMSat - req.PushMSat
+			theirBalance = req.PushMSat
+		} else {
+			ourBalance = localFundingMSat + req.PushMSat
+			theirBalance = capacityMSat - localFundingMSat -
+				feeMSat - req.PushMSat
+		}
+
+		funderBalance := theirBalance
+		if initiator {
+			funderBalance = ourBalance
+		}
+		if int64(funderBalance) < 0 {
+			return nil, ErrFunderBalanceDust(
+				int64(commitFee),
+				int64(funderBalance.ToLokis()),
+				int64(2*defaultDust),
+			)
+		}
+
 	// If we're the responder to a single-funder reservation, then we have
 	// no initial balance in the channel unless the remote party is pushing
 	// some funds to us within the first commitment state.
-	if localFundingAmt == 0 {
+	case localFundingAmt == 0:
 		ourBalance = req.PushMSat
 		theirBalance = capacityMSat - feeMSat - req.PushMSat
 		initiator = false
@@ -336,7 +365,8 @@ func NewChannelReservation(capacity, localFundingAmt chainutil.Amount,
 				int64(2*defaultDust),
 			)
 		}
-	} else {
+
+	default:
 		// TODO(roasbeef): need to rework fee structure in general and
 		// also when we "unlock" dual funder within the daemon
 
@@ -396,8 +426,12 @@ func NewChannelReservation(capacity, localFundingAmt chainutil.Amount,
 
 	// If either of the balances are zero at this point, or we have a
 	// non-zero push amt (there's no pushing for dual funder), then this is
-	// a single-funder channel.
-	if ourBalance == 0 || theirBalance == 0 || req.PushMSat != 0 {
+	// a single-funder channel. Channels dual-funded through interactive
+	// transaction construction also use the single-funder type, as the
+	// initiator still pays all fees of the commitment transactions.
+	singleFunder := ourBalance == 0 || theirBalance == 0 ||
+		req.PushMSat != 0
+	if req.DualFunded || singleFunder {
 		// Both the tweakless type and the anchor type is tweakless,
 		// hence set the bit.
 		if req.CommitType.HasStaticRemoteKey() {
@@ -426,6 +460,13 @@ func NewChannelReservation(capacity, localFundingAmt chainutil.Amount,
 		default:
 			chanType |= channeldb.NoFundingTxBit
 		}
+
+		// The funding transaction of a dual-funded channel is only
+		// complete once both parties exchanged their signatures after
+		// the channel was persisted, so the channel never holds it.
+		if req.DualFunded {
+			chanType |= channeldb.NoFundingTxBit
+		}
 	} else {
 		// Otherwise, this is a dual funder channel, and no side is
 		// technically the "initiator"
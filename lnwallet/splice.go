@@ -0,0 +1,799 @@
+package lnwallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/input"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwallet/chanfunding"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/blockchain"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/txscript"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+var (
+	// ErrSpliceTaproot is returned when a splice is attempted on a
+	// taproot channel. Only channels with a segwit v0 funding output can
+	// currently be spliced.
+	ErrSpliceTaproot = errors.New("taproot channels can't be spliced")
+
+	// ErrSpliceNotClean is returned when a splice is attempted while the
+	// channel has pending updates or active HTLCs.
+	ErrSpliceNotClean = errors.New("channel must not have pending " +
+		"updates or active HTLCs to be spliced")
+
+	// ErrSpliceDynCommitPending is returned when a splice is attempted
+	// before the last dynamic commitment of the channel completed.
+	ErrSpliceDynCommitPending = errors.New("dynamic commitment pending")
+
+	// ErrSpliceNoFundingOutput is returned when a splice transaction
+	// doesn't pay to the funding script of the channel.
+	ErrSpliceNoFundingOutput = errors.New("splice transaction has no " +
+		"funding output")
+
+	// ErrSpliceCapacity is returned when the capacity of the splice
+	// funding output doesn't match the contributions of both parties.
+	ErrSpliceCapacity = errors.New("splice funding output doesn't " +
+		"match the contributions")
+
+	// ErrSpliceBalance is returned when a splice would remove more funds
+	// from a party's side of the channel than it owns, or leave it below
+	// its channel reserve.
+	ErrSpliceBalance = errors.New("insufficient balance for splice")
+
+	// ErrInvalidSpliceSig is returned when the remote party's signature
+	// for the shared funding input of a splice transaction is invalid.
+	ErrInvalidSpliceSig = errors.New("invalid splice input signature")
+)
+
+// SpliceFundingRequest describes the on-chain side of a splice we initiate.
+type SpliceFundingRequest struct {
+	// Capacity is the current capacity of the channel.
+	Capacity chainutil.Amount
+
+	// Amount is the amount to add to the channel. A negative amount is
+	// removed from the channel instead.
+	Amount chainutil.Amount
+
+	// OutputScript is the script the amount removed from the channel is
+	// paid to. If empty, it's paid to a new wallet address.
+	OutputScript []byte
+
+	// FeeRate is the fee rate of the splice transaction.
+	FeeRate chainfee.SatPerKWeight
+
+	// MinConfs is the number of confirmations the wallet inputs used for
+	// a splice-in need to have.
+	MinConfs int32
+}
+
+// SpliceFunding is the on-chain side of a splice we initiate.
+type SpliceFunding struct {
+	// Inputs are the wallet inputs funding a splice-in.
+	Inputs []*Utxo
+
+	// Outputs are the outputs of the splice transaction besides the
+	// funding output. These are the change output of a splice-in, or the
+	// output receiving the funds of a splice-out.
+	Outputs []*wire.TxOut
+
+	// Capacity is the capacity of the channel after the splice.
+	Capacity chainutil.Amount
+
+	// Contribution is the amount the initiator's channel balance changes
+	// by. For a splice-out this includes the fee of the splice
+	// transaction.
+	Contribution chainutil.Amount
+
+	// Fee is the fee paid by the splice transaction.
+	Fee chainutil.Amount
+}
+
+// spliceWeight returns a weight estimator for a splice transaction that
+// spends the current funding output and creates the new one.
+func spliceWeight() input.TxWeightEstimator {
+	var weight input.TxWeightEstimator
+	weight.AddWitnessInput(input.MultiSigWitnessSize)
+	weight.AddP2WSHOutput()
+
+	return weight
+}
+
+// FundSplice selects the wallet inputs and creates the outputs needed for the
+// passed splice. The selected inputs are leased until they're spent or
+// CancelSplice is called.
+func (l *LightningWallet) FundSplice(
+	req *SpliceFundingRequest) (*SpliceFunding, error) {
+
+	weight := spliceWeight()
+	dustLimit := DustLimitForSize(input.P2WSHSize)
+
+	// A splice-out doesn't need any wallet inputs. The removed amount and
+	// the fee are paid from the channel.
+	if req.Amount < 0 {
+		outputScript := req.OutputScript
+		if len(outputScript) == 0 {
+			addr, err := l.NewAddress(
+				TaprootPubkey, false, DefaultAccountName,
+			)
+			if err != nil {
+				return nil, err
+			}
+			outputScript, err = txscript.PayToAddrScript(addr)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		output := &wire.TxOut{
+			Value:    int64(-req.Amount),
+			PkScript: outputScript,
+		}
+		weight.AddTxOutput(output)
+		fee := req.FeeRate.FeeForWeight(weight.Weight())
+
+		capacity := req.Capacity + req.Amount - fee
+		if capacity < dustLimit {
+			return nil, fmt.Errorf("%w: capacity after splice "+
+				"is %v", ErrSpliceBalance, capacity)
+		}
+
+		return &SpliceFunding{
+			Outputs:      []*wire.TxOut{output},
+			Capacity:     capacity,
+			Contribution: req.Amount - fee,
+			Fee:          fee,
+		}, nil
+	}
+
+	var funding *SpliceFunding
+	err := l.WithCoinSelectLock(func() error {
+		coins, err := NewCoinSource(l, nil).ListCoins(
+			req.MinConfs, math.MaxInt32,
+		)
+		if err != nil {
+			return err
+		}
+
+		selected, changeAmt, err := chanfunding.CoinSelect(
+			req.FeeRate, req.Amount, dustLimit, coins,
+			l.Cfg.CoinSelectionStrategy, weight,
+			chanfunding.P2TRChangeAddress,
+			chanfunding.DefaultMaxFeeRatio,
+		)
+		if err != nil {
+			return err
+		}
+
+		funding = &SpliceFunding{
+			Capacity:     req.Capacity + req.Amount,
+			Contribution: req.Amount,
+		}
+
+		var totalIn chainutil.Amount
+		for _, coin := range selected {
+			utxo, err := l.FetchOutpointInfo(&coin.OutPoint)
+			if err != nil {
+				return err
+			}
+			if utxo.PrevTx == nil {
+				return fmt.Errorf("previous transaction of "+
+					"%v unknown", coin.OutPoint)
+			}
+
+			funding.Inputs = append(funding.Inputs, utxo)
+			totalIn += utxo.Value
+		}
+
+		if changeAmt != 0 {
+			addr, err := l.NewAddress(
+				TaprootPubkey, true, DefaultAccountName,
+			)
+			if err != nil {
+				return err
+			}
+			changeScript, err := txscript.PayToAddrScript(addr)
+			if err != nil {
+				return err
+			}
+
+			funding.Outputs = append(funding.Outputs, &wire.TxOut{
+				Value:    int64(changeAmt),
+				PkScript: changeScript,
+			})
+		}
+		funding.Fee = totalIn - req.Amount - changeAmt
+
+		for _, utxo := range funding.Inputs {
+			_, err = l.LeaseOutput(
+				chanfunding.LndInternalLockID, utxo.OutPoint,
+				chanfunding.DefaultReservationTimeout,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if funding != nil {
+			l.CancelSplice(funding)
+		}
+
+		return nil, err
+	}
+
+	return funding, nil
+}
+
+// CancelSplice releases the wallet inputs leased for the passed splice.
+func (l *LightningWallet) CancelSplice(funding *SpliceFunding) {
+	for _, utxo := range funding.Inputs {
+		err := l.ReleaseOutput(
+			chanfunding.LndInternalLockID, utxo.OutPoint,
+		)
+		if err != nil {
+			walletLog.Warnf("Failed to release UTXO %v: %v",
+				utxo.OutPoint, err)
+		}
+	}
+}
+
+// SignSpliceInputs signs the wallet inputs of the passed splice transaction.
+// The witnesses are returned in the order of the inputs within the
+// transaction.
+func (l *LightningWallet) SignSpliceInputs(tx *wire.MsgTx,
+	funding *SpliceFunding,
+	prevOuts txscript.PrevOutputFetcher) ([]wire.TxWitness, error) {
+
+	ours := make(map[wire.OutPoint]*Utxo, len(funding.Inputs))
+	for _, utxo := range funding.Inputs {
+		ours[utxo.OutPoint] = utxo
+	}
+
+	sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+
+	var witnesses []wire.TxWitness
+	for i, txIn := range tx.TxIn {
+		utxo, ok := ours[txIn.PreviousOutPoint]
+		if !ok {
+			continue
+		}
+
+		signDesc := &input.SignDescriptor{
+			Output: &wire.TxOut{
+				Value:    int64(utxo.Value),
+				PkScript: utxo.PkScript,
+			},
+			HashType:          txscript.SigHashAll,
+			SigHashes:         sigHashes,
+			PrevOutputFetcher: prevOuts,
+			InputIndex:        i,
+		}
+		if txscript.IsPayToTaproot(utxo.PkScript) {
+			signDesc.HashType = txscript.SigHashDefault
+		}
+
+		// Splice transactions only carry segwit inputs, so there's
+		// never a signature script to set.
+		script, err := l.Cfg.Signer.ComputeInputScript(tx, signDesc)
+		if err != nil {
+			return nil, err
+		}
+
+		witnesses = append(witnesses, script.Witness)
+	}
+
+	return witnesses, nil
+}
+
+// SpliceCommitments holds the commitment transactions of the channel that
+// spend the funding output of a splice transaction.
+type SpliceCommitments struct {
+	// FundingOutpoint is the funding output created by the splice.
+	FundingOutpoint wire.OutPoint
+
+	// Capacity is the capacity of the channel after the splice.
+	Capacity chainutil.Amount
+
+	// LocalBalance is our balance after the splice.
+	LocalBalance lnwire.MilliLoki
+
+	// RemoteBalance is the remote party's balance after the splice.
+	RemoteBalance lnwire.MilliLoki
+
+	// LocalCommitTx is our commitment transaction spending the new
+	// funding output.
+	LocalCommitTx *wire.MsgTx
+
+	// RemoteCommitTx is the remote party's commitment transaction
+	// spending the new funding output.
+	RemoteCommitTx *wire.MsgTx
+
+	// RemoteCommitSig is our signature for RemoteCommitTx.
+	RemoteCommitSig lnwire.Sig
+
+	// RemotePartialSig is our partial signature for RemoteCommitTx if the
+	// splice upgrades the channel to taproot. RemoteCommitSig is unused
+	// in that case.
+	RemotePartialSig fn.Option[lnwire.PartialSigWithNonce]
+
+	// Upgrade is set if the splice moves the channel to a new channel
+	// type, which the commitments already use.
+	Upgrade fn.Option[chanstate.SpliceUpgrade]
+}
+
+// PendingSplice returns the splice to persist once the remote party's
+// signature for our commitment spending the splice funding output was
+// verified.
+func (s *SpliceCommitments) PendingSplice(spliceTx *wire.MsgTx,
+	localCommitSig []byte, isInitiator bool,
+	heightHint uint32) chanstate.Splice {
+
+	return chanstate.Splice{
+		FundingTx:       spliceTx,
+		FundingOutpoint: s.FundingOutpoint,
+		Capacity:        s.Capacity,
+		LocalBalance:    s.LocalBalance,
+		RemoteBalance:   s.RemoteBalance,
+		LocalCommitTx:   s.LocalCommitTx,
+		LocalCommitSig:  localCommitSig,
+		RemoteCommitTx:  s.RemoteCommitTx,
+		IsInitiator:     isInitiator,
+		HeightHint:      heightHint,
+		Upgrade:         s.Upgrade,
+	}
+}
+
+// ValidateSplice returns an error if the channel can't be spliced in its
+// current state.
+func (lc *LightningChannel) ValidateSplice() error {
+	if lc.channelState.ChanType.IsTaproot() {
+		return ErrSpliceTaproot
+	}
+	if lc.DynCommitmentPending() {
+		return ErrSpliceDynCommitPending
+	}
+	if lc.SplicePending() {
+		return chanstate.ErrSpliceInProgress
+	}
+	if !lc.IsChannelClean() {
+		return ErrSpliceNotClean
+	}
+
+	return nil
+}
+
+// FundingScript returns the pkScript of the funding output of the channel.
+// Splicing doesn't change the funding keys, so this is also the script of the
+// funding output created by a splice.
+func (lc *LightningChannel) FundingScript() []byte {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	return bytes.Clone(lc.fundingOutput.PkScript)
+}
+
+// FundingTxOutput returns the output that currently funds the channel.
+func (lc *LightningChannel) FundingTxOutput() wire.TxOut {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	return wire.TxOut{
+		Value:    lc.fundingOutput.Value,
+		PkScript: bytes.Clone(lc.fundingOutput.PkScript),
+	}
+}
+
+// spliceBalance applies the passed delta to a balance of the channel. A
+// negative delta may not push the balance below the given channel reserve.
+func spliceBalance(balance lnwire.MilliLoki, delta,
+	reserve chainutil.Amount) (lnwire.MilliLoki, error) {
+
+	newBalance := int64(balance) + int64(delta)*1000
+	if newBalance < 0 {
+		return 0, fmt.Errorf("%w: balance %v, delta %v",
+			ErrSpliceBalance, balance, delta)
+	}
+	if delta < 0 && lnwire.MilliLoki(newBalance).ToLokis() < reserve {
+		return 0, fmt.Errorf("%w: balance after splice below reserve "+
+			"of %v", ErrSpliceBalance, reserve)
+	}
+
+	return lnwire.MilliLoki(newBalance), nil
+}
+
+// SignSpliceCommitments creates the commitment transactions that spend the
+// funding output of the passed splice transaction and signs the remote
+// party's. The commitments are built at the current commitment heights with
+// both balances adjusted by the contributions of each party.
+func (lc *LightningChannel) SignSpliceCommitments(spliceTx *wire.MsgTx,
+	localDelta, remoteDelta chainutil.Amount) (*SpliceCommitments, error) {
+
+	lc.Lock()
+	defer lc.Unlock()
+
+	found, index := input.FindScriptOutputIndex(
+		spliceTx, lc.fundingOutput.PkScript,
+	)
+	if !found {
+		return nil, ErrSpliceNoFundingOutput
+	}
+
+	capacity := chainutil.Amount(spliceTx.TxOut[index].Value)
+	if capacity != lc.channelState.Capacity+localDelta+remoteDelta {
+		return nil, fmt.Errorf("%w: capacity %v, current capacity %v, "+
+			"contributions %v and %v", ErrSpliceCapacity, capacity,
+			lc.channelState.Capacity, localDelta, remoteDelta)
+	}
+
+	splice := &SpliceCommitments{
+		FundingOutpoint: wire.OutPoint{
+			Hash:  spliceTx.TxHash(),
+			Index: index,
+		},
+		Capacity: capacity,
+	}
+
+	localCommit := lc.commitChains.Local.tip()
+	remoteCommit := lc.commitChains.Remote.tip()
+
+	var err error
+	splice.LocalBalance, err = spliceBalance(
+		localCommit.ourBalance, localDelta,
+		lc.channelState.LocalChanCfg.ChanReserve,
+	)
+	if err != nil {
+		return nil, err
+	}
+	splice.RemoteBalance, err = spliceBalance(
+		localCommit.theirBalance, remoteDelta,
+		lc.channelState.RemoteChanCfg.ChanReserve,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	splice.LocalCommitTx, err = lc.createSpliceCommitTx(
+		lntypes.Local, splice, localCommit.height,
+	)
+	if err != nil {
+		return nil, err
+	}
+	splice.RemoteCommitTx, err = lc.createSpliceCommitTx(
+		lntypes.Remote, splice, remoteCommit.height,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	signDesc := lc.spliceSignDesc(capacity)
+	signDesc.SigHashes = input.NewTxSigHashesV0Only(splice.RemoteCommitTx)
+	rawSig, err := lc.Signer.SignOutputRaw(splice.RemoteCommitTx, signDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	splice.RemoteCommitSig, err = lnwire.NewSigFromSignature(rawSig)
+	if err != nil {
+		return nil, err
+	}
+
+	return splice, nil
+}
+
+// createSpliceCommitTx creates the commitment transaction of the given party
+// at the passed height, spending the funding output of the splice.
+func (lc *LightningChannel) createSpliceCommitTx(
+	whoseCommit lntypes.ChannelParty, splice *SpliceCommitments,
+	height uint64) (*wire.MsgTx, error) {
+
+	chanState := lc.channelState
+	chanType := chanState.ChanType
+	splice.Upgrade.WhenSome(func(upgrade chanstate.SpliceUpgrade) {
+		chanType = upgrade.ChanType
+	})
+
+	var keyRing *CommitmentKeyRing
+	if whoseCommit.IsLocal() {
+		secret, err := chanState.RevocationProducer.AtIndex(height)
+		if err != nil {
+			return nil, err
+		}
+		keyRing = DeriveCommitmentKeys(
+			input.ComputeCommitmentPoint(secret[:]), lntypes.Local,
+			chanType, &chanState.LocalChanCfg,
+			&chanState.RemoteChanCfg,
+		)
+	} else {
+		keyRing = DeriveCommitmentKeys(
+			chanState.RemoteCurrentRevocation, lntypes.Remote,
+			chanType, &chanState.LocalChanCfg,
+			&chanState.RemoteChanCfg,
+		)
+	}
+
+	var leaseExpiry uint32
+	if chanState.ChanType.HasLeaseExpiration() {
+		leaseExpiry = chanState.ThawHeight
+	}
+
+	fundingTxIn := *wire.NewTxIn(&splice.FundingOutpoint, nil, nil)
+
+	var (
+		commitTx *wire.MsgTx
+		err      error
+	)
+	if whoseCommit.IsLocal() {
+		commitTx, err = CreateCommitTx(
+			chanType, fundingTxIn, keyRing,
+			&chanState.LocalChanCfg, &chanState.RemoteChanCfg,
+			splice.LocalBalance.ToLokis(),
+			splice.RemoteBalance.ToLokis(), 0,
+			chanState.IsInitiator, leaseExpiry,
+			fn.None[CommitAuxLeaves](),
+		)
+	} else {
+		commitTx, err = CreateCommitTx(
+			chanType, fundingTxIn, keyRing,
+			&chanState.RemoteChanCfg, &chanState.LocalChanCfg,
+			splice.RemoteBalance.ToLokis(),
+			splice.LocalBalance.ToLokis(), 0,
+			!chanState.IsInitiator, leaseExpiry,
+			fn.None[CommitAuxLeaves](),
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = SetStateNumHint(commitTx, height, lc.commitBuilder.obfuscator)
+	if err != nil {
+		return nil, err
+	}
+
+	InPlaceCommitSort(commitTx, make([]uint32, len(commitTx.TxOut)))
+
+	err = blockchain.CheckTransactionSanity(chainutil.NewTx(commitTx))
+	if err != nil {
+		return nil, err
+	}
+
+	return commitTx, nil
+}
+
+// spliceSignDesc returns a copy of the sign descriptor of the funding output
+// with the capacity of a splice funding output.
+func (lc *LightningChannel) spliceSignDesc(
+	capacity chainutil.Amount) *input.SignDescriptor {
+
+	signDesc := *lc.signDesc
+	signDesc.Output = &wire.TxOut{
+		Value:    int64(capacity),
+		PkScript: lc.fundingOutput.PkScript,
+	}
+
+	return &signDesc
+}
+
+// VerifySpliceCommitSig checks the remote party's signature for our
+// commitment transaction spending the splice funding output.
+func (lc *LightningChannel) VerifySpliceCommitSig(splice *SpliceCommitments,
+	sig lnwire.Sig) error {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	multiSigScript := lc.signDesc.WitnessScript
+	prevFetcher := txscript.NewCannedPrevOutputFetcher(
+		multiSigScript, int64(splice.Capacity),
+	)
+	hashCache := txscript.NewTxSigHashes(splice.LocalCommitTx, prevFetcher)
+
+	sigHash, err := txscript.CalcWitnessSigHash(
+		multiSigScript, hashCache, txscript.SigHashAll,
+		splice.LocalCommitTx, 0, int64(splice.Capacity),
+	)
+	if err != nil {
+		return err
+	}
+
+	cSig, err := sig.ToSignature()
+	if err != nil {
+		return err
+	}
+
+	verifyKey := lc.channelState.RemoteChanCfg.MultiSigKey.PubKey
+	if !cSig.Verify(sigHash, verifyKey) {
+		var txBytes bytes.Buffer
+		_ = splice.LocalCommitTx.Serialize(&txBytes)
+
+		return &InvalidCommitSigError{
+			commitHeight: lc.currentHeight,
+			commitSig:    sig.ToSignatureBytes(),
+			sigHash:      sigHash,
+			commitTx:     txBytes.Bytes(),
+		}
+	}
+
+	return nil
+}
+
+// sharedInputIndex returns the index of the input of the splice transaction
+// that spends the current funding output.
+func (lc *LightningChannel) sharedInputIndex(spliceTx *wire.MsgTx) (int,
+	error) {
+
+	fundingOutpoint := lc.channelState.FundingTxOutpoint()
+	for i, txIn := range spliceTx.TxIn {
+		if txIn.PreviousOutPoint == fundingOutpoint {
+			return i, nil
+		}
+	}
+
+	return 0, fmt.Errorf("splice transaction doesn't spend funding "+
+		"output %v", fundingOutpoint)
+}
+
+// SignSpliceInput signs the input of the splice transaction that spends the
+// current funding output.
+func (lc *LightningChannel) SignSpliceInput(spliceTx *wire.MsgTx,
+	prevOuts txscript.PrevOutputFetcher) (lnwire.Sig, error) {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	index, err := lc.sharedInputIndex(spliceTx)
+	if err != nil {
+		return lnwire.Sig{}, err
+	}
+
+	signDesc := *lc.signDesc
+	signDesc.InputIndex = index
+	signDesc.PrevOutputFetcher = prevOuts
+	signDesc.SigHashes = txscript.NewTxSigHashes(spliceTx, prevOuts)
+
+	rawSig, err := lc.Signer.SignOutputRaw(spliceTx, &signDesc)
+	if err != nil {
+		return lnwire.Sig{}, err
+	}
+
+	return lnwire.NewSigFromSignature(rawSig)
+}
+
+// FinalizeSpliceInput verifies the remote party's signature for the input of
+// the splice transaction that spends the current funding output and
+// populates the witness of the input.
+func (lc *LightningChannel) FinalizeSpliceInput(spliceTx *wire.MsgTx,
+	prevOuts txscript.PrevOutputFetcher, localSig,
+	remoteSig lnwire.Sig) error {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	index, err := lc.sharedInputIndex(spliceTx)
+	if err != nil {
+		return err
+	}
+
+	multiSigScript := lc.signDesc.WitnessScript
+	sigHash, err := txscript.CalcWitnessSigHash(
+		multiSigScript, txscript.NewTxSigHashes(spliceTx, prevOuts),
+		txscript.SigHashAll, spliceTx, index, lc.fundingOutput.Value,
+	)
+	if err != nil {
+		return err
+	}
+
+	theirSig, err := remoteSig.ToSignature()
+	if err != nil {
+		return err
+	}
+	theirKey := lc.channelState.RemoteChanCfg.MultiSigKey.PubKey
+	if !theirSig.Verify(sigHash, theirKey) {
+		return ErrInvalidSpliceSig
+	}
+
+	ourSig, err := localSig.ToSignature()
+	if err != nil {
+		return err
+	}
+	ourKey := lc.channelState.LocalChanCfg.MultiSigKey.PubKey
+
+	spliceTx.TxIn[index].Witness = input.SpendMultiSig(
+		multiSigScript, ourKey.SerializeCompressed(), ourSig,
+		theirKey.SerializeCompressed(), theirSig,
+	)
+
+	return nil
+}
+
+// SplicePending returns true if the channel has a splice that wasn't locked
+// by both parties yet. No updates may be exchanged until it is.
+func (lc *LightningChannel) SplicePending() bool {
+	return lc.channelState.PendingSpliceInfo().IsSome()
+}
+
+// PendingSplice returns the splice of the channel that wasn't locked by both
+// parties yet, if any.
+func (lc *LightningChannel) PendingSplice() fn.Option[chanstate.Splice] {
+	return lc.channelState.PendingSpliceInfo()
+}
+
+// PutPendingSplice persists the passed splice of the channel.
+func (lc *LightningChannel) PutPendingSplice(splice chanstate.Splice) error {
+	return lc.channelState.PutPendingSplice(splice)
+}
+
+// AbortSplice removes the pending splice of the channel. This may only be
+// done before the splice was locked.
+func (lc *LightningChannel) AbortSplice() error {
+	return lc.channelState.AbortPendingSplice()
+}
+
+// MarkSpliceRemoteLocked records that the remote party locked the pending
+// splice of the channel.
+func (lc *LightningChannel) MarkSpliceRemoteLocked() error {
+	return lc.channelState.MarkSpliceRemoteLocked()
+}
+
+// LockSplice switches the channel over to the funding output of its pending
+// splice. The commitments spending the new funding output replace the current
+// ones, and all later commitments spend the new funding output.
+func (lc *LightningChannel) LockSplice() error {
+	lc.Lock()
+	defer lc.Unlock()
+
+	splice, err := lc.channelState.PendingSpliceInfo().UnwrapOrErr(
+		chanstate.ErrNoPendingSplice,
+	)
+	if err != nil {
+		return err
+	}
+	if splice.LocalLocked {
+		return nil
+	}
+
+	if err := lc.channelState.LockSplice(); err != nil {
+		return err
+	}
+
+	lc.Capacity = splice.Capacity
+	if err := lc.createSignDesc(); err != nil {
+		return err
+	}
+
+	localCommit := lc.commitChains.Local.tip()
+	localCommit.txn = splice.LocalCommitTx
+	localCommit.sig = splice.LocalCommitSig
+	localCommit.ourBalance = splice.LocalBalance
+	localCommit.theirBalance = splice.RemoteBalance
+
+	remoteCommit := lc.commitChains.Remote.tip()
+	remoteCommit.txn = splice.RemoteCommitTx
+	remoteCommit.sig = nil
+	remoteCommit.ourBalance = splice.LocalBalance
+	remoteCommit.theirBalance = splice.RemoteBalance
+
+	// If the splice upgraded the channel to taproot, the musig sessions
+	// for the next commitments are set up once both parties exchanged
+	// their verification nonces in SpliceLocked.
+	splice.Upgrade.WhenSome(func(upgrade chanstate.SpliceUpgrade) {
+		localCommit.fee = upgrade.CommitFee
+		remoteCommit.fee = upgrade.CommitFee
+
+		lc.musigSessions = nil
+		lc.pendingVerificationNonce = nil
+	})
+
+	return nil
+}
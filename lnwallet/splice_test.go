@@ -0,0 +1,189 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/flokiorg/flnd/channeldb"
+	"github.com/flokiorg/flnd/interactivetx"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/txscript"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// testSpliceTx builds a splice transaction for the passed channel that adds
+// the given amount from a single external input.
+func testSpliceTx(t *testing.T, channel *LightningChannel,
+	amt chainutil.Amount) (*wire.MsgTx, *txscript.MultiPrevOutFetcher) {
+
+	t.Helper()
+
+	prevTx := wire.NewMsgTx(2)
+	prevTx.AddTxIn(&wire.TxIn{})
+	prevTx.AddTxOut(&wire.TxOut{
+		Value:    int64(amt) + 1_000,
+		PkScript: []byte{txscript.OP_TRUE},
+	})
+
+	fundingPoint := channel.State().FundingTxOutpoint()
+	fundingOutput := channel.FundingTxOutput()
+	externalPoint := wire.OutPoint{Hash: prevTx.TxHash()}
+
+	spliceTx := wire.NewMsgTx(2)
+	spliceTx.AddTxIn(wire.NewTxIn(&fundingPoint, nil, nil))
+	spliceTx.AddTxIn(wire.NewTxIn(&externalPoint, nil, nil))
+	spliceTx.AddTxOut(&wire.TxOut{
+		Value:    fundingOutput.Value + int64(amt),
+		PkScript: fundingOutput.PkScript,
+	})
+
+	prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+	prevOuts.AddPrevOut(fundingPoint, &fundingOutput)
+	prevOuts.AddPrevOut(externalPoint, prevTx.TxOut[0])
+
+	return spliceTx, prevOuts
+}
+
+// TestSpliceIn asserts that both parties of a channel arrive at the same
+// commitments for a splice, and that the channel continues on the new funding
+// output once the splice is locked.
+func TestSpliceIn(t *testing.T) {
+	t.Parallel()
+
+	aliceChannel, bobChannel, err := CreateTestChannels(
+		t, channeldb.SingleFunderTweaklessBit,
+	)
+	require.NoError(t, err, "unable to create test channels")
+
+	require.NoError(t, aliceChannel.ValidateSplice())
+	require.NoError(t, bobChannel.ValidateSplice())
+
+	const amt = chainutil.Amount(1_000_000)
+	oldCapacity := aliceChannel.State().Capacity
+	oldBalance := aliceChannel.State().LocalCommitment.LocalBalance
+
+	spliceTx, prevOuts := testSpliceTx(t, aliceChannel, amt)
+
+	aliceCommits, err := aliceChannel.SignSpliceCommitments(
+		spliceTx, amt, 0,
+	)
+	require.NoError(t, err)
+	bobCommits, err := bobChannel.SignSpliceCommitments(spliceTx, 0, amt)
+	require.NoError(t, err)
+
+	require.Equal(
+		t, aliceCommits.FundingOutpoint, bobCommits.FundingOutpoint,
+	)
+	require.Equal(t, oldCapacity+amt, aliceCommits.Capacity)
+	require.Equal(
+		t, aliceCommits.LocalCommitTx.TxHash(),
+		bobCommits.RemoteCommitTx.TxHash(),
+	)
+	require.Equal(
+		t, aliceCommits.RemoteCommitTx.TxHash(),
+		bobCommits.LocalCommitTx.TxHash(),
+	)
+
+	// Each party can verify the other's signature for its own
+	// commitment, but not for the other party's commitment.
+	require.NoError(t, aliceChannel.VerifySpliceCommitSig(
+		aliceCommits, bobCommits.RemoteCommitSig,
+	))
+	require.NoError(t, bobChannel.VerifySpliceCommitSig(
+		bobCommits, aliceCommits.RemoteCommitSig,
+	))
+	require.Error(t, aliceChannel.VerifySpliceCommitSig(
+		aliceCommits, aliceCommits.RemoteCommitSig,
+	))
+
+	// Both parties sign the shared input, which results in a valid
+	// witness for the current funding output.
+	aliceSig, err := aliceChannel.SignSpliceInput(spliceTx, prevOuts)
+	require.NoError(t, err)
+	bobSig, err := bobChannel.SignSpliceInput(spliceTx, prevOuts)
+	require.NoError(t, err)
+
+	err = aliceChannel.FinalizeSpliceInput(
+		spliceTx, prevOuts, aliceSig, aliceSig,
+	)
+	require.ErrorIs(t, err, ErrInvalidSpliceSig)
+
+	require.NoError(t, aliceChannel.FinalizeSpliceInput(
+		spliceTx, prevOuts, aliceSig, bobSig,
+	))
+
+	require.NoError(t, interactivetx.VerifyTx(spliceTx, prevOuts))
+
+	// Persist and lock the splice on both sides.
+	aliceSplice := aliceCommits.PendingSplice(
+		spliceTx, bobCommits.RemoteCommitSig.ToSignatureBytes(), true,
+		100,
+	)
+	require.NoError(t, aliceChannel.PutPendingSplice(aliceSplice))
+	bobSplice := bobCommits.PendingSplice(
+		spliceTx, aliceCommits.RemoteCommitSig.ToSignatureBytes(),
+		false, 100,
+	)
+	require.NoError(t, bobChannel.PutPendingSplice(bobSplice))
+
+	require.True(t, aliceChannel.SplicePending())
+	require.ErrorIs(
+		t, aliceChannel.ValidateSplice(), channeldb.ErrSpliceInProgress,
+	)
+
+	require.NoError(t, aliceChannel.LockSplice())
+	require.NoError(t, bobChannel.LockSplice())
+	require.NoError(t, aliceChannel.MarkSpliceRemoteLocked())
+	require.NoError(t, bobChannel.MarkSpliceRemoteLocked())
+
+	require.False(t, aliceChannel.SplicePending())
+	require.False(t, bobChannel.SplicePending())
+
+	state := aliceChannel.State()
+	require.Equal(
+		t, aliceCommits.FundingOutpoint, state.FundingTxOutpoint(),
+	)
+	require.Equal(t, oldCapacity+amt, state.Capacity)
+	require.Equal(
+		t, oldBalance+lnwire.NewMSatFromLokis(amt),
+		state.LocalCommitment.LocalBalance,
+	)
+
+	// The channel keeps working on top of the new funding output.
+	htlc, _ := createHTLC(0, lnwire.NewMSatFromLokis(10_000))
+	_, err = aliceChannel.AddHTLC(htlc, nil)
+	require.NoError(t, err)
+	_, err = bobChannel.ReceiveHTLC(htlc)
+	require.NoError(t, err)
+	require.NoError(t, ForceStateTransition(aliceChannel, bobChannel))
+
+	for _, c := range []*LightningChannel{aliceChannel, bobChannel} {
+		commitTx := c.State().LocalCommitment.CommitTx
+		require.Equal(
+			t, aliceCommits.FundingOutpoint,
+			commitTx.TxIn[0].PreviousOutPoint,
+		)
+	}
+}
+
+// TestSpliceOutBalance asserts that a party can't splice out more than its
+// balance allows.
+func TestSpliceOutBalance(t *testing.T) {
+	t.Parallel()
+
+	aliceChannel, bobChannel, err := CreateTestChannels(
+		t, channeldb.SingleFunderTweaklessBit,
+	)
+	require.NoError(t, err, "unable to create test channels")
+
+	balance := bobChannel.State().LocalCommitment.LocalBalance.ToLokis()
+
+	spliceTx, _ := testSpliceTx(t, aliceChannel, -balance)
+	_, err = bobChannel.SignSpliceCommitments(spliceTx, -balance, 0)
+	require.ErrorIs(t, err, ErrSpliceBalance)
+
+	// The funding output has to match the contributions.
+	_, err = bobChannel.SignSpliceCommitments(spliceTx, -balance+1, 0)
+	require.ErrorIs(t, err, ErrSpliceCapacity)
+}
@@ -0,0 +1,357 @@
+package lnwallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/channeldb"
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/input"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr/musig2"
+	"github.com/flokiorg/go-flokicoin/txscript"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+var (
+	// ErrTaprootUpgradeFeeRate is returned when the fee rate proposed for
+	// the kickoff transaction of a taproot upgrade is below the relay
+	// floor.
+	ErrTaprootUpgradeFeeRate = errors.New("kickoff fee rate below the " +
+		"minimum relay fee rate")
+
+	// ErrTaprootUpgradeNoNonce is returned when the remote party didn't
+	// send the verification nonce needed to sign its first taproot
+	// commitment.
+	ErrTaprootUpgradeNoNonce = errors.New("taproot upgrade without " +
+		"verification nonce")
+
+	// ErrNotTaprootUpgrade is returned when the commitments of a regular
+	// splice are handled as the ones of a taproot upgrade, or vice versa.
+	ErrNotTaprootUpgrade = errors.New("splice doesn't upgrade the " +
+		"channel to taproot")
+)
+
+// IsTaprootUpgrade returns true if switching the channel from the current to
+// the next channel type moves it to a MuSig2 funding output.
+func IsTaprootUpgrade(current, next channeldb.ChannelType) bool {
+	return !current.IsTaproot() && next.IsTaproot()
+}
+
+// taprootUpgradeWeight returns the weight of the kickoff transaction of a
+// taproot upgrade, which spends the segwit v0 funding output and creates the
+// MuSig2 one.
+func taprootUpgradeWeight() input.TxWeightEstimator {
+	var weight input.TxWeightEstimator
+	weight.AddWitnessInput(input.MultiSigWitnessSize)
+	weight.AddP2TROutput()
+
+	return weight
+}
+
+// TaprootUpgradeTx creates the kickoff transaction of a taproot upgrade. It
+// spends the current funding output to a MuSig2 funding output of the same
+// funding keys, paying the fee at the passed fee rate from the channel. Both
+// parties build the same transaction, so it doesn't need to be negotiated.
+func (lc *LightningChannel) TaprootUpgradeTx(
+	feeRate chainfee.SatPerKWeight) (*wire.MsgTx,
+	txscript.PrevOutputFetcher, error) {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	return lc.taprootUpgradeTx(feeRate)
+}
+
+// taprootUpgradeTx creates the kickoff transaction of a taproot upgrade. This
+// function expects to be executed with a lock held.
+func (lc *LightningChannel) taprootUpgradeTx(
+	feeRate chainfee.SatPerKWeight) (*wire.MsgTx,
+	txscript.PrevOutputFetcher, error) {
+
+	if feeRate < chainfee.FeePerKwFloor {
+		return nil, nil, fmt.Errorf("%w: %v < %v",
+			ErrTaprootUpgradeFeeRate, feeRate,
+			chainfee.FeePerKwFloor)
+	}
+
+	chanState := lc.channelState
+	weight := taprootUpgradeWeight()
+	fee := feeRate.FeeForWeight(weight.Weight())
+	capacity := chanState.Capacity - fee
+	if capacity <= 0 {
+		return nil, nil, fmt.Errorf("%w: kickoff fee %v exceeds "+
+			"capacity %v", ErrDynCommitFunderBalance, fee,
+			chanState.Capacity)
+	}
+
+	fundingOutput, err := lc.upgradeFundingOutput(capacity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fundingOutpoint := chanState.FundingTxOutpoint()
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&fundingOutpoint, nil, nil))
+	tx.AddTxOut(fundingOutput)
+
+	prevOuts := txscript.NewCannedPrevOutputFetcher(
+		lc.fundingOutput.PkScript, lc.fundingOutput.Value,
+	)
+
+	return tx, prevOuts, nil
+}
+
+// ValidateTaprootUpgrade checks that both parties can afford the taproot
+// upgrade with the kickoff transaction paying the passed fee rate. The party
+// that initiated the upgrade pays for the kickoff transaction, while the
+// channel initiator keeps paying for the commitments.
+func (lc *LightningChannel) ValidateTaprootUpgrade(
+	next channeldb.ChannelType, feeRate chainfee.SatPerKWeight,
+	initiator lntypes.ChannelParty) error {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	if !IsTaprootUpgrade(lc.channelState.ChanType, next) {
+		return ErrNotTaprootUpgrade
+	}
+
+	tx, _, err := lc.taprootUpgradeTx(feeRate)
+	if err != nil {
+		return err
+	}
+
+	_, err = lc.taprootUpgradeCommitments(tx, next, initiator)
+
+	return err
+}
+
+// taprootUpgradeCommitments returns the commitments spending the MuSig2
+// funding output created by the passed kickoff transaction. This function
+// expects to be executed with a lock held.
+func (lc *LightningChannel) taprootUpgradeCommitments(kickoffTx *wire.MsgTx,
+	next channeldb.ChannelType,
+	initiator lntypes.ChannelParty) (*SpliceCommitments, error) {
+
+	chanState := lc.channelState
+	capacity := chainutil.Amount(kickoffTx.TxOut[0].Value)
+	kickoffFee := chanState.Capacity - capacity
+
+	localCommit := lc.commitChains.Local.tip()
+	feePerKw := localCommit.feePerKw
+	commitFee := feePerKw.FeeForWeight(CommitWeight(next))
+
+	// The channel initiator pays for the heavier commitment and the anchor
+	// outputs the new channel type may add.
+	funderDelta := localCommit.fee + dynAnchorCost(chanState.ChanType) -
+		commitFee - dynAnchorCost(next)
+
+	var localDelta, remoteDelta chainutil.Amount
+	if chanState.IsInitiator {
+		localDelta += funderDelta
+	} else {
+		remoteDelta += funderDelta
+	}
+	if initiator.IsLocal() {
+		localDelta -= kickoffFee
+	} else {
+		remoteDelta -= kickoffFee
+	}
+
+	splice := &SpliceCommitments{
+		FundingOutpoint: wire.OutPoint{
+			Hash: kickoffTx.TxHash(),
+		},
+		Capacity: capacity,
+		Upgrade: fn.Some(chanstate.SpliceUpgrade{
+			ChanType:  next,
+			CommitFee: commitFee,
+		}),
+	}
+
+	var err error
+	splice.LocalBalance, err = spliceBalance(
+		localCommit.ourBalance, localDelta,
+		chanState.LocalChanCfg.ChanReserve,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDynCommitFunderBalance,
+			err)
+	}
+	splice.RemoteBalance, err = spliceBalance(
+		localCommit.theirBalance, remoteDelta,
+		chanState.RemoteChanCfg.ChanReserve,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDynCommitFunderBalance,
+			err)
+	}
+
+	splice.LocalCommitTx, err = lc.createSpliceCommitTx(
+		lntypes.Local, splice, localCommit.height,
+	)
+	if err != nil {
+		return nil, err
+	}
+	splice.RemoteCommitTx, err = lc.createSpliceCommitTx(
+		lntypes.Remote, splice, lc.commitChains.Remote.tip().height,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return splice, nil
+}
+
+// TaprootUpgradeNonce returns our verification nonce for the current local
+// commitment height. The remote party uses it to sign the taproot commitment
+// that replaces our current commitment once the upgrade is locked.
+func (lc *LightningChannel) TaprootUpgradeNonce() (lnwire.Musig2Nonce,
+	error) {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	nonce, err := lc.taprootUpgradeNonce()
+	if err != nil {
+		return lnwire.Musig2Nonce{}, err
+	}
+
+	return nonce.PubNonce, nil
+}
+
+// taprootUpgradeNonce derives our verification nonce for the current local
+// commitment height. This function expects to be executed with a lock held.
+func (lc *LightningChannel) taprootUpgradeNonce() (*musig2.Nonces, error) {
+	return channeldb.NewMusigVerificationNonce(
+		lc.channelState.LocalChanCfg.MultiSigKey.PubKey,
+		lc.currentHeight, lc.taprootNonceProducer,
+	)
+}
+
+// upgradeMusigSession returns a MuSig2 session for a commitment spending the
+// MuSig2 funding output created by a taproot upgrade.
+func (lc *LightningChannel) upgradeMusigSession(nonce musig2.Nonces,
+	fundingOutput *wire.TxOut,
+	commitType MusigCommitType) *MusigSession {
+
+	chanState := lc.channelState
+
+	return NewPartialMusigSession(
+		nonce, chanState.LocalChanCfg.MultiSigKey,
+		chanState.RemoteChanCfg.MultiSigKey, lc.Signer, fundingOutput,
+		commitType,
+		fn.MapOption(TapscriptRootToTweak)(chanState.TapscriptRoot),
+		lc.opts.customSigningRand,
+	)
+}
+
+// SignTaprootUpgradeCommitments creates the taproot commitments spending the
+// funding output of the passed kickoff transaction and signs the remote
+// party's using the verification nonce it sent for its current commitment.
+// The commitments are built at the current commitment heights.
+func (lc *LightningChannel) SignTaprootUpgradeCommitments(
+	kickoffTx *wire.MsgTx, next channeldb.ChannelType,
+	initiator lntypes.ChannelParty,
+	remoteNonce lnwire.Musig2Nonce) (*SpliceCommitments, error) {
+
+	lc.Lock()
+	defer lc.Unlock()
+
+	if !IsTaprootUpgrade(lc.channelState.ChanType, next) {
+		return nil, ErrNotTaprootUpgrade
+	}
+
+	splice, err := lc.taprootUpgradeCommitments(kickoffTx, next, initiator)
+	if err != nil {
+		return nil, err
+	}
+
+	session := lc.upgradeMusigSession(
+		musig2.Nonces{PubNonce: remoteNonce}, kickoffTx.TxOut[0],
+		RemoteMusigCommit,
+	)
+	sig, err := session.SignCommit(splice.RemoteCommitTx)
+	if err != nil {
+		return nil, err
+	}
+	splice.RemotePartialSig = fn.Some(*sig.ToWireSig())
+
+	return splice, nil
+}
+
+// VerifyTaprootUpgradeCommitSig checks the remote party's partial signature
+// for our taproot commitment spending the funding output of a taproot
+// upgrade. On success, the signature is returned in the form it's stored
+// along with our commitment.
+func (lc *LightningChannel) VerifyTaprootUpgradeCommitSig(
+	splice *SpliceCommitments, sig *lnwire.PartialSigWithNonce) ([]byte,
+	error) {
+
+	lc.RLock()
+	defer lc.RUnlock()
+
+	if splice.Upgrade.IsNone() {
+		return nil, ErrNotTaprootUpgrade
+	}
+
+	localNonce, err := lc.taprootUpgradeNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	fundingOutput, err := lc.upgradeFundingOutput(splice.Capacity)
+	if err != nil {
+		return nil, err
+	}
+
+	session := lc.upgradeMusigSession(
+		*localNonce, fundingOutput, LocalMusigCommit,
+	)
+	_, err = session.VerifyCommitSig(splice.LocalCommitTx, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err := sig.Encode(&b); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// upgradeFundingOutput returns the MuSig2 funding output of the given
+// capacity created by the kickoff transaction of a taproot upgrade. This
+// function expects to be executed with a lock held.
+func (lc *LightningChannel) upgradeFundingOutput(
+	capacity chainutil.Amount) (*wire.TxOut, error) {
+
+	chanState := lc.channelState
+	pkScript, _, err := input.GenTaprootFundingScript(
+		chanState.LocalChanCfg.MultiSigKey.PubKey,
+		chanState.RemoteChanCfg.MultiSigKey.PubKey, int64(capacity),
+		chanState.TapscriptRoot,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return wire.NewTxOut(int64(capacity), pkScript), nil
+}
+
+// upgradeAwaitingRemoteLock returns true if we locked a taproot upgrade that
+// the remote party didn't lock yet. Until it does, the remote party doesn't
+// use the MuSig2 funding output and won't send any verification nonces on
+// reestablish.
+func (lc *LightningChannel) upgradeAwaitingRemoteLock() bool {
+	splice := lc.channelState.PendingSpliceInfo().UnwrapOr(chanstate.Splice{})
+
+	return splice.IsUpgrade() && splice.LocalLocked && !splice.RemoteLocked
+}
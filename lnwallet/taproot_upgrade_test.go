@@ -0,0 +1,212 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/flokiorg/flnd/channeldb"
+	"github.com/flokiorg/flnd/interactivetx"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr/musig2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTaprootUpgrade asserts that an anchor channel can be upgraded to a
+// taproot channel by moving it to a MuSig2 funding output, and that the
+// channel continues as a taproot channel once the upgrade is locked.
+func TestTaprootUpgrade(t *testing.T) {
+	t.Parallel()
+
+	chanType := channeldb.SingleFunderTweaklessBit |
+		channeldb.AnchorOutputsBit | channeldb.ZeroHtlcTxFeeBit
+	aliceChannel, bobChannel, err := CreateTestChannels(t, chanType)
+	require.NoError(t, err, "unable to create test channels")
+
+	propose := &lnwire.DynPropose{
+		ChannelType: dynChanTypeRecord(
+			lnwire.SimpleTaprootChannelsRequiredFinal,
+		),
+	}
+
+	aliceParams, err := aliceChannel.DynProposeParams(
+		propose, lntypes.Local,
+	)
+	require.NoError(t, err)
+	bobParams, err := bobChannel.DynProposeParams(propose, lntypes.Remote)
+	require.NoError(t, err)
+
+	next := aliceParams.ChanType
+	require.True(t, IsTaprootUpgrade(chanType, next))
+	require.True(t, next.IsTaprootFinal())
+
+	const maxCSV = 1000
+	require.NoError(t, aliceChannel.ValidateDynCommitment(
+		aliceParams, maxCSV,
+	))
+	require.NoError(t, bobChannel.ValidateDynCommitment(
+		bobParams, maxCSV,
+	))
+
+	// The upgrade can't be applied like any other dynamic commitment.
+	err = aliceChannel.ApplyDynCommitment(aliceParams, nil)
+	require.ErrorIs(t, err, ErrDynCommitTaprootSplice)
+
+	// The kickoff transaction must pay at least the relay fee rate.
+	err = aliceChannel.ValidateTaprootUpgrade(
+		next, chainfee.FeePerKwFloor-1, lntypes.Local,
+	)
+	require.ErrorIs(t, err, ErrTaprootUpgradeFeeRate)
+
+	const feeRate = chainfee.SatPerKWeight(2_000)
+	require.NoError(t, aliceChannel.ValidateTaprootUpgrade(
+		next, feeRate, lntypes.Local,
+	))
+	require.NoError(t, bobChannel.ValidateTaprootUpgrade(
+		next, feeRate, lntypes.Remote,
+	))
+
+	// Both parties build the same kickoff transaction.
+	kickoffTx, prevOuts, err := aliceChannel.TaprootUpgradeTx(feeRate)
+	require.NoError(t, err)
+	bobKickoffTx, _, err := bobChannel.TaprootUpgradeTx(feeRate)
+	require.NoError(t, err)
+	require.Equal(t, kickoffTx.TxHash(), bobKickoffTx.TxHash())
+
+	oldCapacity := aliceChannel.State().Capacity
+	kickoffFee := oldCapacity - chainutil.Amount(kickoffTx.TxOut[0].Value)
+	require.Positive(t, kickoffFee)
+
+	aliceNonce, err := aliceChannel.TaprootUpgradeNonce()
+	require.NoError(t, err)
+	bobNonce, err := bobChannel.TaprootUpgradeNonce()
+	require.NoError(t, err)
+
+	aliceCommits, err := aliceChannel.SignTaprootUpgradeCommitments(
+		kickoffTx, next, lntypes.Local, bobNonce,
+	)
+	require.NoError(t, err)
+	bobCommits, err := bobChannel.SignTaprootUpgradeCommitments(
+		bobKickoffTx, next, lntypes.Remote, aliceNonce,
+	)
+	require.NoError(t, err)
+
+	require.Equal(
+		t, aliceCommits.LocalCommitTx.TxHash(),
+		bobCommits.RemoteCommitTx.TxHash(),
+	)
+	require.Equal(
+		t, aliceCommits.RemoteCommitTx.TxHash(),
+		bobCommits.LocalCommitTx.TxHash(),
+	)
+
+	// Alice initiated the upgrade, so she pays for the kickoff
+	// transaction.
+	require.Equal(
+		t, aliceChannel.State().RemoteCommitment.RemoteBalance,
+		bobCommits.LocalBalance,
+	)
+
+	alicePartialSig, err := aliceCommits.RemotePartialSig.UnwrapOrErr(
+		ErrNotTaprootUpgrade,
+	)
+	require.NoError(t, err)
+	bobPartialSig, err := bobCommits.RemotePartialSig.UnwrapOrErr(
+		ErrNotTaprootUpgrade,
+	)
+	require.NoError(t, err)
+
+	aliceCommitSig, err := aliceChannel.VerifyTaprootUpgradeCommitSig(
+		aliceCommits, &bobPartialSig,
+	)
+	require.NoError(t, err)
+	bobCommitSig, err := bobChannel.VerifyTaprootUpgradeCommitSig(
+		bobCommits, &alicePartialSig,
+	)
+	require.NoError(t, err)
+
+	// A partial signature for the other party's commitment doesn't
+	// verify.
+	_, err = aliceChannel.VerifyTaprootUpgradeCommitSig(
+		aliceCommits, &alicePartialSig,
+	)
+	require.Error(t, err)
+
+	// The kickoff transaction spends the current funding output like any
+	// other splice.
+	aliceSig, err := aliceChannel.SignSpliceInput(kickoffTx, prevOuts)
+	require.NoError(t, err)
+	bobSig, err := bobChannel.SignSpliceInput(kickoffTx, prevOuts)
+	require.NoError(t, err)
+	require.NoError(t, aliceChannel.FinalizeSpliceInput(
+		kickoffTx, prevOuts, aliceSig, bobSig,
+	))
+	require.NoError(t, interactivetx.VerifyTx(kickoffTx, prevOuts))
+
+	aliceSplice := aliceCommits.PendingSplice(
+		kickoffTx, aliceCommitSig, true, 100,
+	)
+	require.NoError(t, aliceChannel.PutPendingSplice(aliceSplice))
+	bobSplice := bobCommits.PendingSplice(
+		kickoffTx, bobCommitSig, false, 100,
+	)
+	require.NoError(t, bobChannel.PutPendingSplice(bobSplice))
+
+	require.NoError(t, aliceChannel.LockSplice())
+	require.NoError(t, bobChannel.LockSplice())
+
+	// Once locked, the channel is a taproot channel funded by the output
+	// of the kickoff transaction.
+	for _, c := range []*LightningChannel{aliceChannel, bobChannel} {
+		state := c.State()
+		require.Equal(t, next, state.ChanType)
+		require.Equal(
+			t, aliceCommits.FundingOutpoint,
+			state.FundingTxOutpoint(),
+		)
+		require.Equal(t, oldCapacity-kickoffFee, state.Capacity)
+
+		last, ok := state.LastDynCommitment()
+		require.True(t, ok)
+		require.Equal(t, chanType, last.PrevChanType)
+	}
+
+	// The commitment that replaced our current one can be broadcast with
+	// the partial signature of the remote party.
+	commitTx, err := aliceChannel.getSignedCommitTx()
+	require.NoError(t, err)
+	require.Equal(t, aliceCommits.LocalCommitTx.TxHash(), commitTx.TxHash())
+
+	// The verification nonces for the next commitments are exchanged in
+	// SpliceLocked.
+	aliceNextNonce, err := aliceChannel.GenMusigNonces()
+	require.NoError(t, err)
+	bobNextNonce, err := bobChannel.GenMusigNonces()
+	require.NoError(t, err)
+	require.NoError(t, aliceChannel.InitRemoteMusigNonces(&musig2.Nonces{
+		PubNonce: bobNextNonce.PubNonce,
+	}))
+	require.NoError(t, bobChannel.InitRemoteMusigNonces(&musig2.Nonces{
+		PubNonce: aliceNextNonce.PubNonce,
+	}))
+
+	require.NoError(t, aliceChannel.MarkSpliceRemoteLocked())
+	require.NoError(t, bobChannel.MarkSpliceRemoteLocked())
+	require.False(t, aliceChannel.SplicePending())
+
+	// The channel keeps working as a taproot channel.
+	htlc, _ := createHTLC(0, lnwire.NewMSatFromLokis(10_000))
+	_, err = aliceChannel.AddHTLC(htlc, nil)
+	require.NoError(t, err)
+	_, err = bobChannel.ReceiveHTLC(htlc)
+	require.NoError(t, err)
+	require.NoError(t, ForceStateTransition(aliceChannel, bobChannel))
+
+	commitTx, err = aliceChannel.getSignedCommitTx()
+	require.NoError(t, err)
+	require.Equal(
+		t, aliceCommits.FundingOutpoint,
+		commitTx.TxIn[0].PreviousOutPoint,
+	)
+}
@@ -2962,11 +2962,19 @@ func clearWalletStates(a, b *lnwallet.LightningWallet) error {
 	a.ResetReservations()
 	b.ResetReservations()
 
-	if err := a.Cfg.Database.GetParentDB().Wipe(); err != nil {
-		return err
+	for _, w := range []*lnwallet.LightningWallet{a, b} {
+		chanStateDB, ok := w.Cfg.Database.(*channeldb.ChannelStateDB)
+		if !ok {
+			return fmt.Errorf("unexpected channel state store %T",
+				w.Cfg.Database)
+		}
+
+		if err := chanStateDB.GetParentDB().Wipe(); err != nil {
+			return err
+		}
 	}
 
-	return b.Cfg.Database.GetParentDB().Wipe()
+	return nil
 }
 
 func waitForMempoolTx(r *rpctest.Harness, txid *chainhash.Hash) error {
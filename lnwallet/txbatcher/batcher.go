@@ -0,0 +1,1013 @@
+package txbatcher
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flokiorg/flnd/chainntnfs"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/labels"
+	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/ticker"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/wire"
+	base "github.com/flokiorg/walletd/wallet"
+	"github.com/flokiorg/walletd/wallet/txauthor"
+	"github.com/flokiorg/walletd/wallet/txrules"
+)
+
+const (
+	// DefaultBatchInterval is the default interval at which the queued
+	// requests are published in a batch transaction.
+	DefaultBatchInterval = 10 * time.Minute
+
+	// DefaultUrgentDelta is the default number of blocks before its
+	// deadline at which a request stops waiting for the next batch
+	// interval.
+	DefaultUrgentDelta = 6
+
+	// maxConfTarget is the largest conf target we ask the fee estimator
+	// for.
+	maxConfTarget = 1008
+)
+
+var (
+	// ErrBatcherShuttingDown is returned to queued requests when the
+	// batcher shuts down before they were published.
+	ErrBatcherShuttingDown = errors.New("tx batcher shutting down")
+
+	// ErrNoOutputs is returned when a request without outputs is queued.
+	ErrNoOutputs = errors.New("request has no outputs")
+
+	// ErrRequestNotQueued is returned when cancelling a request that is
+	// unknown or already part of a transaction.
+	ErrRequestNotQueued = errors.New("request is not queued")
+
+	// ErrChangeSpent is returned when the change of the pending batch tx
+	// was spent by another tx, so replacing the batch would evict it.
+	ErrChangeSpent = errors.New("change of pending batch tx is spent")
+
+	// ErrMaxFeeRate is returned when replacing the pending batch tx would
+	// need a fee rate above the configured maximum.
+	ErrMaxFeeRate = errors.New("replacement exceeds max fee rate")
+)
+
+// batchLabel is the label of the transactions published by the batcher.
+var batchLabel = labels.MakeLabel(labels.LabelTypeBatchSend, nil)
+
+// Wallet is the subset of the wallet functionality the batcher needs to fund
+// and publish the batch transactions.
+type Wallet interface {
+	// CreateSimpleTx creates a transaction paying to the passed outputs,
+	// optionally restricted to the passed inputs.
+	CreateSimpleTx(inputs fn.Set[wire.OutPoint], outputs []*wire.TxOut,
+		feeRate chainfee.SatPerKWeight, minConfs int32,
+		strategy base.CoinSelectionStrategy,
+		dryRun bool) (*txauthor.AuthoredTx, error)
+
+	// SendOutputs funds, signs and publishes a transaction paying to the
+	// passed outputs, optionally restricted to the passed inputs.
+	SendOutputs(inputs fn.Set[wire.OutPoint], outputs []*wire.TxOut,
+		feeRate chainfee.SatPerKWeight, minConfs int32, label string,
+		strategy base.CoinSelectionStrategy) (*wire.MsgTx, error)
+
+	// CheckReservedValueTx checks that the passed transaction doesn't
+	// spend the wallet balance below the reserve for anchor channels.
+	CheckReservedValueTx(
+		lnwallet.CheckReservedValueTxReq) (chainutil.Amount, error)
+
+	// ListUnspentWitness returns the unspent outputs of the wallet with a
+	// number of confirmations in the passed range.
+	ListUnspentWitness(minConfs, maxConfs int32,
+		accountFilter string) ([]*lnwallet.Utxo, error)
+
+	// RemoveDescendants removes the passed transaction and any
+	// transactions spending its outputs from the wallet.
+	RemoveDescendants(*wire.MsgTx) error
+
+	// PublishTransaction broadcasts the passed transaction.
+	PublishTransaction(tx *wire.MsgTx, label string) error
+
+	// WithCoinSelectLock runs the passed closure while holding the
+	// global coin selection lock.
+	WithCoinSelectLock(f func() error) error
+}
+
+// Config holds the dependencies of the Batcher.
+type Config struct {
+	// Wallet is used to fund and publish the batch transactions.
+	Wallet Wallet
+
+	// Estimator is used to pick the fee rate of a batch based on the
+	// earliest deadline among its requests.
+	Estimator chainfee.Estimator
+
+	// Notifier is used to track the best height and the confirmation of
+	// the pending batch transaction.
+	Notifier chainntnfs.ChainNotifier
+
+	// Ticker fires at the interval at which the queued requests are
+	// published in a new batch.
+	Ticker ticker.Ticker
+
+	// UrgentDelta is the number of blocks before its deadline at which a
+	// request is published right away, replacing the pending batch tx if
+	// there is one.
+	UrgentDelta uint32
+
+	// MaxFeeRate is the maximum fee rate a batch transaction may use.
+	MaxFeeRate chainfee.SatPerKWeight
+
+	// CoinSelectionStrategy is the strategy used to fund the batches.
+	CoinSelectionStrategy base.CoinSelectionStrategy
+}
+
+// Request is a set of outputs to be paid in the next batch transaction.
+type Request struct {
+	// Outputs are the outputs to pay to. They are always published
+	// together in the same transaction.
+	Outputs []*wire.TxOut
+
+	// DeadlineHeight is the height by which the outputs should be
+	// confirmed.
+	DeadlineHeight int32
+
+	// MinConfs is the minimum number of confirmations the inputs funding
+	// the outputs must have. A batch uses the largest value of all its
+	// requests.
+	MinConfs int32
+}
+
+// Result is sent to the requester once the outputs of its request are
+// published, or if they can't be sent.
+type Result struct {
+	// Tx is the transaction carrying the outputs of the request.
+	//
+	// NOTE: If the request is urgent, a later request may cause the tx to
+	// be replaced with one that carries the same outputs.
+	Tx *wire.MsgTx
+
+	// Err is set if the outputs couldn't be sent.
+	Err error
+}
+
+// requestState is the state of a request in the batcher.
+type requestState uint8
+
+const (
+	// stateQueued means the request waits for the next batch.
+	stateQueued requestState = iota
+
+	// stateClaimed means the request is being added to a transaction,
+	// either a batch of our own or one created by another subsystem.
+	stateClaimed
+
+	// statePublished means the transaction carrying the request was
+	// published but hasn't confirmed yet.
+	statePublished
+)
+
+// batchRequest tracks a single queued request.
+type batchRequest struct {
+	id  uint64
+	req *Request
+
+	state requestState
+
+	// notified is set once a result was sent to the requester.
+	notified   bool
+	resultChan chan *Result
+}
+
+// value returns the total value of the outputs of the request.
+func (r *batchRequest) value() chainutil.Amount {
+	var value chainutil.Amount
+	for _, out := range r.req.Outputs {
+		value += chainutil.Amount(out.Value)
+	}
+
+	return value
+}
+
+// notify sends the result to the requester, unless a result was already
+// sent.
+func (r *batchRequest) notify(result *Result) {
+	if r.notified {
+		return
+	}
+
+	r.notified = true
+	r.resultChan <- result
+}
+
+// batch is a published batch transaction that hasn't confirmed yet.
+type batch struct {
+	tx       *wire.MsgTx
+	feeRate  chainfee.SatPerKWeight
+	requests []*batchRequest
+
+	confEvent *chainntnfs.ConfirmationEvent
+}
+
+// outputKey identifies an output by its script and value.
+func outputKey(out *wire.TxOut) string {
+	return fmt.Sprintf("%x:%d", out.PkScript, out.Value)
+}
+
+// changeOutpoint returns the outpoint of the change output of the batch tx,
+// which is the only output that doesn't belong to any of its requests.
+func (bt *batch) changeOutpoint() fn.Option[wire.OutPoint] {
+	requested := make(map[string]int)
+	for _, r := range bt.requests {
+		for _, out := range r.req.Outputs {
+			requested[outputKey(out)]++
+		}
+	}
+
+	for idx, out := range bt.tx.TxOut {
+		key := outputKey(out)
+		if requested[key] > 0 {
+			requested[key]--
+			continue
+		}
+
+		return fn.Some(wire.OutPoint{
+			Hash:  bt.tx.TxHash(),
+			Index: uint32(idx),
+		})
+	}
+
+	return fn.None[wire.OutPoint]()
+}
+
+// Batcher queues on-chain payments and publishes them together in a single
+// periodic transaction. Requests that get close to their deadline are
+// published right away by replacing the pending batch tx. Other subsystems
+// that build their own transactions, such as batch channel funding and the
+// sweeper, can claim queued requests to carry their outputs instead.
+type Batcher struct {
+	started sync.Once
+	stopped sync.Once
+
+	cfg *Config
+
+	// requests are the requests that are queued or whose transaction
+	// hasn't confirmed yet.
+	requests map[uint64]*batchRequest
+
+	// nextID is the ID of the last queued request.
+	nextID uint64
+
+	// currentHeight is the best known height.
+	currentHeight int32
+
+	mu sync.Mutex
+
+	// pending is our last batch tx if it hasn't confirmed yet. It's only
+	// accessed by the batch handler.
+	pending *batch
+
+	// urgent is signaled when an urgent request is queued.
+	urgent chan struct{}
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a new tx batcher.
+func New(cfg *Config) *Batcher {
+	return &Batcher{
+		cfg:      cfg,
+		requests: make(map[uint64]*batchRequest),
+		urgent:   make(chan struct{}, 1),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start starts the batch handler.
+func (b *Batcher) Start() error {
+	var startErr error
+	b.started.Do(func() {
+		log.Info("Tx batcher starting")
+
+		blockEpochs, err := b.cfg.Notifier.RegisterBlockEpochNtfn(nil)
+		if err != nil {
+			startErr = fmt.Errorf("register block epochs: %w", err)
+			return
+		}
+
+		// The notifier sends the current tip right away, which gives
+		// us our starting height.
+		select {
+		case epoch, ok := <-blockEpochs.Epochs:
+			if !ok {
+				startErr = ErrBatcherShuttingDown
+				return
+			}
+
+			b.mu.Lock()
+			b.currentHeight = epoch.Height
+			b.mu.Unlock()
+
+		case <-b.quit:
+			startErr = ErrBatcherShuttingDown
+			return
+		}
+
+		b.cfg.Ticker.Resume()
+
+		b.wg.Add(1)
+		go b.batchHandler(blockEpochs)
+	})
+
+	return startErr
+}
+
+// Stop stops the batch handler and fails all requests that are still queued.
+func (b *Batcher) Stop() error {
+	b.stopped.Do(func() {
+		log.Info("Tx batcher shutting down...")
+		defer log.Debug("Tx batcher shutdown complete")
+
+		close(b.quit)
+		b.wg.Wait()
+
+		b.cfg.Ticker.Stop()
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		for id, r := range b.requests {
+			if r.state != stateQueued {
+				continue
+			}
+
+			r.notify(&Result{Err: ErrBatcherShuttingDown})
+			delete(b.requests, id)
+		}
+	})
+
+	return nil
+}
+
+// Queue adds the request to the next batch. It returns the ID of the request
+// and a channel that receives the result once the outputs are published or
+// can't be sent.
+func (b *Batcher) Queue(req *Request) (uint64, <-chan *Result, error) {
+	if len(req.Outputs) == 0 {
+		return 0, nil, ErrNoOutputs
+	}
+
+	// Make sure a single bad output can't make the whole batch fail.
+	for _, out := range req.Outputs {
+		err := txrules.CheckOutput(out, txrules.DefaultRelayFeePerKb)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	b.mu.Lock()
+	b.nextID++
+	r := &batchRequest{
+		id:         b.nextID,
+		req:        req,
+		resultChan: make(chan *Result, 1),
+	}
+	b.requests[r.id] = r
+	urgent := b.isUrgent(r)
+	b.mu.Unlock()
+
+	log.Debugf("Queued request %d with %d outputs, value=%v, "+
+		"deadline=%d, urgent=%v", r.id, len(req.Outputs), r.value(),
+		req.DeadlineHeight, urgent)
+
+	if urgent {
+		b.signalUrgent()
+	}
+
+	return r.id, r.resultChan, nil
+}
+
+// Cancel removes a queued request. It fails if the request is already part
+// of a transaction.
+func (b *Batcher) Cancel(id uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r, ok := b.requests[id]
+	if !ok || r.state != stateQueued {
+		return ErrRequestNotQueued
+	}
+
+	delete(b.requests, id)
+
+	log.Debugf("Cancelled request %d", id)
+
+	return nil
+}
+
+// signalUrgent wakes up the batch handler to publish the urgent requests.
+func (b *Batcher) signalUrgent() {
+	select {
+	case b.urgent <- struct{}{}:
+	default:
+	}
+}
+
+// isUrgent returns true if the request is too close to its deadline to wait
+// for the next batch.
+//
+// NOTE: Must be called with the mutex held.
+func (b *Batcher) isUrgent(r *batchRequest) bool {
+	return r.req.DeadlineHeight-b.currentHeight <= int32(b.cfg.UrgentDelta)
+}
+
+// queuedRequests returns the queued requests in the order they were queued.
+//
+// NOTE: Must be called with the mutex held.
+func (b *Batcher) queuedRequests() []*batchRequest {
+	var queued []*batchRequest
+	for _, r := range b.requests {
+		if r.state == stateQueued {
+			queued = append(queued, r)
+		}
+	}
+
+	sort.Slice(queued, func(i, j int) bool {
+		return queued[i].id < queued[j].id
+	})
+
+	return queued
+}
+
+// batchHandler publishes the queued requests on every tick, or right away if
+// a request becomes urgent, and tracks the confirmation of the pending batch.
+//
+// NOTE: Must be run as a goroutine.
+func (b *Batcher) batchHandler(blockEpochs *chainntnfs.BlockEpochEvent) {
+	defer b.wg.Done()
+	defer blockEpochs.Cancel()
+
+	for {
+		var confChan <-chan *chainntnfs.TxConfirmation
+		if b.pending != nil {
+			confChan = b.pending.confEvent.Confirmed
+		}
+
+		select {
+		case epoch, ok := <-blockEpochs.Epochs:
+			if !ok {
+				log.Debug("Block epoch subscription closed")
+				return
+			}
+
+			b.mu.Lock()
+			b.currentHeight = epoch.Height
+			b.mu.Unlock()
+
+			b.flush(false)
+
+		case <-b.cfg.Ticker.Ticks():
+			b.flush(true)
+
+		case <-b.urgent:
+			b.flush(false)
+
+		case _, ok := <-confChan:
+			if !ok {
+				log.Debug("Confirmation subscription closed")
+				return
+			}
+
+			b.handleConfirmation()
+
+		case <-b.quit:
+			if b.pending != nil {
+				b.pending.confEvent.Cancel()
+			}
+
+			return
+		}
+	}
+}
+
+// handleConfirmation forgets the requests of the pending batch once it
+// confirmed.
+func (b *Batcher) handleConfirmation() {
+	log.Infof("Batch tx %v with %d requests confirmed",
+		b.pending.tx.TxHash(), len(b.pending.requests))
+
+	b.mu.Lock()
+	for _, r := range b.pending.requests {
+		delete(b.requests, r.id)
+	}
+	b.mu.Unlock()
+
+	b.pending.confEvent.Cancel()
+	b.pending = nil
+}
+
+// flush publishes the queued requests if it's time for a new batch or if
+// any of them is urgent. While a batch is pending, only urgent requests are
+// published, by replacing the pending batch tx.
+func (b *Batcher) flush(periodic bool) {
+	b.mu.Lock()
+	queued := b.queuedRequests()
+
+	var urgent bool
+	for _, r := range queued {
+		urgent = urgent || b.isUrgent(r)
+	}
+
+	if len(queued) == 0 || (!urgent && (!periodic || b.pending != nil)) {
+		b.mu.Unlock()
+
+		if len(queued) != 0 && periodic {
+			log.Debugf("Holding %d requests until batch tx %v "+
+				"confirms", len(queued), b.pending.tx.TxHash())
+		}
+
+		return
+	}
+
+	// Claim the requests so nobody else can add them to their tx while
+	// we create ours.
+	for _, r := range queued {
+		r.state = stateClaimed
+	}
+	height := b.currentHeight
+	b.mu.Unlock()
+
+	if b.pending != nil {
+		err := b.replaceBatch(queued, height)
+		if err == nil {
+			return
+		}
+
+		log.Warnf("Unable to replace batch tx %v, publishing %d "+
+			"urgent requests separately: %v",
+			b.pending.tx.TxHash(), len(queued), err)
+	}
+
+	b.publishBatch(queued, height)
+}
+
+// publishBatch publishes a new batch tx for the passed requests. If that
+// fails, each request is sent on its own so a single request the wallet
+// can't fund doesn't fail the others.
+func (b *Batcher) publishBatch(requests []*batchRequest, height int32) {
+	tx, feeRate, err := b.sendBatch(requests, height)
+	if err == nil {
+		b.batchPublished(tx, feeRate, requests)
+		return
+	}
+
+	if len(requests) == 1 {
+		b.failRequests(requests, err)
+		return
+	}
+
+	log.Warnf("Unable to publish batch of %d requests, sending them "+
+		"separately: %v", len(requests), err)
+
+	for _, r := range requests {
+		tx, feeRate, err := b.sendBatch([]*batchRequest{r}, height)
+		if err != nil {
+			b.failRequests([]*batchRequest{r}, err)
+			continue
+		}
+
+		b.batchPublished(tx, feeRate, []*batchRequest{r})
+	}
+}
+
+// sendBatch funds, signs and publishes a transaction paying to the outputs
+// of the passed requests.
+func (b *Batcher) sendBatch(requests []*batchRequest,
+	height int32) (*wire.MsgTx, chainfee.SatPerKWeight, error) {
+
+	feeRate, err := b.feeRate(requests, height)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var minConfs int32
+	for _, r := range requests {
+		minConfs = max(minConfs, r.req.MinConfs)
+	}
+
+	var tx *wire.MsgTx
+	err = b.cfg.Wallet.WithCoinSelectLock(func() error {
+		tx, err = b.sendOutputs(requests, nil, feeRate, minConfs)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tx, feeRate, nil
+}
+
+// replaceBatch replaces the pending batch tx with one that spends the same
+// inputs and pays to the outputs of both the pending and the passed
+// requests.
+func (b *Batcher) replaceBatch(requests []*batchRequest, height int32) error {
+	old := b.pending
+	all := append(append([]*batchRequest{}, old.requests...), requests...)
+
+	feeRate, err := b.feeRate(all, height)
+	if err != nil {
+		return err
+	}
+
+	// The replacement must pay for its own relay on top of the fee of the
+	// tx it replaces.
+	minFeeRate := old.feeRate + b.cfg.Estimator.RelayFeePerKW()
+	feeRate = max(feeRate, minFeeRate)
+	if feeRate > b.cfg.MaxFeeRate {
+		return fmt.Errorf("%w: need %v, max is %v", ErrMaxFeeRate,
+			feeRate, b.cfg.MaxFeeRate)
+	}
+
+	inputs := fn.NewSet[wire.OutPoint]()
+	for _, txIn := range old.tx.TxIn {
+		inputs.Add(txIn.PreviousOutPoint)
+	}
+
+	var tx *wire.MsgTx
+	err = b.cfg.Wallet.WithCoinSelectLock(func() error {
+		// We can't replace the batch if anything spends its change,
+		// as the replacement would evict that tx as well.
+		if err := b.checkChangeUnspent(old); err != nil {
+			return err
+		}
+
+		// Remove the old batch from the wallet so its inputs can be
+		// spent by the replacement.
+		if err := b.cfg.Wallet.RemoveDescendants(old.tx); err != nil {
+			return err
+		}
+
+		// The inputs of the old batch were already selected, so we
+		// don't need to check their confirmations again.
+		tx, err = b.sendOutputs(all, inputs, feeRate, 0)
+		if err == nil {
+			return nil
+		}
+
+		// Add the old batch back to the wallet, it's still in the
+		// mempool.
+		pubErr := b.cfg.Wallet.PublishTransaction(old.tx, batchLabel)
+		if pubErr != nil {
+			log.Errorf("Unable to republish batch tx %v: %v",
+				old.tx.TxHash(), pubErr)
+		}
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Replaced batch tx %v(%v) with tx %v(%v) to add %d urgent "+
+		"requests", old.tx.TxHash(), old.feeRate, tx.TxHash(), feeRate,
+		len(requests))
+
+	old.confEvent.Cancel()
+	b.pending = nil
+
+	b.batchPublished(tx, feeRate, all)
+
+	return nil
+}
+
+// checkChangeUnspent returns an error if the change output of the batch tx
+// was spent.
+//
+// NOTE: Must be called with the coin selection lock held.
+func (b *Batcher) checkChangeUnspent(bt *batch) error {
+	change := bt.changeOutpoint()
+	if change.IsNone() {
+		return nil
+	}
+	changeOp := change.UnsafeFromSome()
+
+	utxos, err := b.cfg.Wallet.ListUnspentWitness(
+		0, 0, lnwallet.DefaultAccountName,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, utxo := range utxos {
+		if utxo.OutPoint == changeOp {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %v", ErrChangeSpent, changeOp)
+}
+
+// sendOutputs funds, signs and publishes a transaction paying to the outputs
+// of the passed requests, making sure it doesn't spend the wallet below its
+// reserved value.
+//
+// NOTE: Must be called with the coin selection lock held.
+func (b *Batcher) sendOutputs(requests []*batchRequest,
+	inputs fn.Set[wire.OutPoint], feeRate chainfee.SatPerKWeight,
+	minConfs int32) (*wire.MsgTx, error) {
+
+	var outputs []*wire.TxOut
+	for _, r := range requests {
+		outputs = append(outputs, r.req.Outputs...)
+	}
+
+	// We first do a dry run, to sanity check we won't spend our wallet
+	// balance below the reserved amount.
+	authoredTx, err := b.cfg.Wallet.CreateSimpleTx(
+		inputs, outputs, feeRate, minConfs,
+		b.cfg.CoinSelectionStrategy, true,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = b.cfg.Wallet.CheckReservedValueTx(
+		lnwallet.CheckReservedValueTxReq{
+			Tx:          authoredTx.Tx,
+			ChangeIndex: &authoredTx.ChangeIndex,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.cfg.Wallet.SendOutputs(
+		inputs, outputs, feeRate, minConfs, batchLabel,
+		b.cfg.CoinSelectionStrategy,
+	)
+}
+
+// feeRate returns the fee rate for a batch of the passed requests, which is
+// the estimate for the earliest deadline among them.
+func (b *Batcher) feeRate(requests []*batchRequest,
+	height int32) (chainfee.SatPerKWeight, error) {
+
+	deadline := requests[0].req.DeadlineHeight
+	for _, r := range requests[1:] {
+		deadline = min(deadline, r.req.DeadlineHeight)
+	}
+
+	confTarget := min(max(deadline-height, 1), maxConfTarget)
+	feeRate, err := b.cfg.Estimator.EstimateFeePerKW(uint32(confTarget))
+	if err != nil {
+		return 0, fmt.Errorf("estimate fee rate: %w", err)
+	}
+
+	return min(feeRate, b.cfg.MaxFeeRate), nil
+}
+
+// batchPublished notifies the requesters of a published batch tx. If there's
+// no pending batch yet, the tx becomes the pending batch so urgent requests
+// can be added to it later on.
+func (b *Batcher) batchPublished(tx *wire.MsgTx,
+	feeRate chainfee.SatPerKWeight, requests []*batchRequest) {
+
+	txid := tx.TxHash()
+
+	log.Infof("Published batch tx %v with %d requests, fee_rate=%v",
+		txid, len(requests), feeRate)
+
+	track := b.pending == nil
+	if track {
+		confEvent, err := b.cfg.Notifier.RegisterConfirmationsNtfn(
+			&txid, tx.TxOut[0].PkScript, 1,
+			uint32(b.bestHeight()),
+		)
+		if err != nil {
+			log.Errorf("Unable to track confirmation of batch tx "+
+				"%v: %v", txid, err)
+
+			track = false
+		} else {
+			b.pending = &batch{
+				tx:        tx,
+				feeRate:   feeRate,
+				requests:  requests,
+				confEvent: confEvent,
+			}
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, r := range requests {
+		r.notify(&Result{Tx: tx})
+
+		if track {
+			r.state = statePublished
+			continue
+		}
+
+		delete(b.requests, r.id)
+	}
+}
+
+// failRequests sends the error to the requesters and forgets the requests.
+func (b *Batcher) failRequests(requests []*batchRequest, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, r := range requests {
+		log.Errorf("Unable to send request %d: %v", r.id, err)
+
+		r.notify(&Result{Err: err})
+		delete(b.requests, r.id)
+	}
+}
+
+// bestHeight returns the best known height.
+func (b *Batcher) bestHeight() int32 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.currentHeight
+}
+
+// Claim is a set of queued requests handed to another subsystem that adds
+// their outputs to a transaction it builds itself.
+type Claim struct {
+	b *Batcher
+
+	requests []*batchRequest
+	outputs  []*wire.TxOut
+
+	// height is the best known height when the requests were claimed.
+	height int32
+
+	// txids are the txids of all the txns carrying the outputs that were
+	// published, including the ones that were replaced since.
+	txids fn.Set[chainhash.Hash]
+}
+
+// ClaimOutputs claims the queued requests whose deadline isn't before the
+// passed height. If maxValue is set, requests are claimed in the order they
+// were queued as long as their total value stays within it. None is returned
+// if no request could be claimed.
+func (b *Batcher) ClaimOutputs(deadline int32,
+	maxValue fn.Option[chainutil.Amount]) fn.Option[*Claim] {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	limit := maxValue.UnwrapOr(chainutil.Amount(chainutil.MaxLoki))
+
+	var (
+		claim = &Claim{
+			b:      b,
+			height: b.currentHeight,
+			txids:  fn.NewSet[chainhash.Hash](),
+		}
+		total chainutil.Amount
+	)
+	for _, r := range b.queuedRequests() {
+		if r.req.DeadlineHeight < deadline {
+			continue
+		}
+
+		value := r.value()
+		if total+value > limit {
+			continue
+		}
+
+		total += value
+		r.state = stateClaimed
+		claim.requests = append(claim.requests, r)
+		claim.outputs = append(claim.outputs, r.req.Outputs...)
+	}
+
+	if len(claim.requests) == 0 {
+		return fn.None[*Claim]()
+	}
+
+	log.Debugf("Claimed %d requests with value %v for deadline %d",
+		len(claim.requests), total, deadline)
+
+	return fn.Some(claim)
+}
+
+// Outputs returns the outputs the claiming tx must pay to.
+func (c *Claim) Outputs() []*wire.TxOut {
+	return c.outputs
+}
+
+// Published notifies the requesters that their outputs were published in the
+// passed tx.
+func (c *Claim) Published(tx *wire.MsgTx) {
+	c.b.mu.Lock()
+	defer c.b.mu.Unlock()
+
+	c.txids.Add(tx.TxHash())
+	for _, r := range c.requests {
+		r.state = statePublished
+		r.notify(&Result{Tx: tx})
+	}
+}
+
+// WatchInput keeps track of the published txns carrying the claimed outputs
+// after their publisher gave up on them, as any of them may still confirm.
+// The passed input must be spent by all of them. Once it's spent by one of
+// them, the requests are done. If it's spent by another tx instead, none of
+// them can confirm anymore, and the requests go back to the queue.
+func (c *Claim) WatchInput(outpoint wire.OutPoint, pkScript []byte) error {
+	spendEvent, err := c.b.cfg.Notifier.RegisterSpendNtfn(
+		&outpoint, pkScript, uint32(c.height),
+	)
+	if err != nil {
+		return fmt.Errorf("register spend of %v: %w", outpoint, err)
+	}
+
+	log.Debugf("Watching spend of %v to settle %d claimed requests",
+		outpoint, len(c.requests))
+
+	c.b.wg.Add(1)
+	go c.waitForSpend(spendEvent)
+
+	return nil
+}
+
+// waitForSpend settles the claimed requests once the watched input is spent.
+//
+// NOTE: Must be run as a goroutine.
+func (c *Claim) waitForSpend(spendEvent *chainntnfs.SpendEvent) {
+	defer c.b.wg.Done()
+	defer spendEvent.Cancel()
+
+	select {
+	case spend, ok := <-spendEvent.Spend:
+		if !ok {
+			return
+		}
+
+		c.b.mu.Lock()
+		ours := c.txids.Contains(*spend.SpenderTxHash)
+		c.b.mu.Unlock()
+
+		if ours {
+			log.Debugf("Tx %v carrying %d claimed requests "+
+				"confirmed", spend.SpenderTxHash,
+				len(c.requests))
+
+			c.Done()
+
+			return
+		}
+
+		log.Infof("Txns carrying %d claimed requests were double "+
+			"spent by %v, queueing them again", len(c.requests),
+			spend.SpenderTxHash)
+
+		c.Release()
+
+	case <-c.b.quit:
+	}
+}
+
+// Release returns the claimed requests to the queue, because the tx carrying
+// them won't confirm.
+func (c *Claim) Release() {
+	c.b.mu.Lock()
+
+	var urgent bool
+	for _, r := range c.requests {
+		if _, ok := c.b.requests[r.id]; !ok {
+			continue
+		}
+
+		r.state = stateQueued
+		urgent = urgent || c.b.isUrgent(r)
+	}
+	c.b.mu.Unlock()
+
+	log.Debugf("Released %d claimed requests", len(c.requests))
+
+	if urgent {
+		c.b.signalUrgent()
+	}
+}
+
+// Done forgets the claimed requests once the tx carrying them confirmed.
+func (c *Claim) Done() {
+	c.b.mu.Lock()
+	defer c.b.mu.Unlock()
+
+	for _, r := range c.requests {
+		delete(c.b.requests, r.id)
+	}
+}
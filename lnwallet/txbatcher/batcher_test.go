@@ -0,0 +1,507 @@
+package txbatcher
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/chainntnfs"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/ticker"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/wire"
+	base "github.com/flokiorg/walletd/wallet"
+	"github.com/flokiorg/walletd/wallet/txauthor"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testHeight  = 100
+	testTimeout = 5 * time.Second
+)
+
+var (
+	errTooManyOutputs = errors.New("too many outputs")
+
+	changeScript = []byte{0x51, 0x20, 0xff}
+)
+
+// mockWallet is a wallet that creates a tx spending a single fresh input, or
+// the passed inputs, with a change output.
+type mockWallet struct {
+	mu sync.Mutex
+
+	nextInput  uint32
+	maxOutputs int
+
+	published []*wire.MsgTx
+	feeRates  []chainfee.SatPerKWeight
+	removed   []*wire.MsgTx
+	unspent   []*lnwallet.Utxo
+}
+
+func (m *mockWallet) createTx(inputs fn.Set[wire.OutPoint],
+	outputs []*wire.TxOut) (*wire.MsgTx, error) {
+
+	if m.maxOutputs != 0 && len(outputs) > m.maxOutputs {
+		return nil, errTooManyOutputs
+	}
+
+	tx := wire.NewMsgTx(2)
+	if len(inputs) == 0 {
+		m.nextInput++
+		inputs = fn.NewSet(wire.OutPoint{Index: m.nextInput})
+	}
+	for _, op := range inputs.ToSlice() {
+		tx.AddTxIn(&wire.TxIn{PreviousOutPoint: op})
+	}
+	for _, out := range outputs {
+		tx.AddTxOut(out)
+	}
+	tx.AddTxOut(&wire.TxOut{Value: 1000, PkScript: changeScript})
+
+	return tx, nil
+}
+
+func (m *mockWallet) CreateSimpleTx(inputs fn.Set[wire.OutPoint],
+	outputs []*wire.TxOut, _ chainfee.SatPerKWeight, _ int32,
+	_ base.CoinSelectionStrategy, _ bool) (*txauthor.AuthoredTx, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx, err := m.createTx(inputs, outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txauthor.AuthoredTx{
+		Tx:          tx,
+		ChangeIndex: len(tx.TxOut) - 1,
+	}, nil
+}
+
+func (m *mockWallet) SendOutputs(inputs fn.Set[wire.OutPoint],
+	outputs []*wire.TxOut, feeRate chainfee.SatPerKWeight, _ int32,
+	_ string, _ base.CoinSelectionStrategy) (*wire.MsgTx, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx, err := m.createTx(inputs, outputs)
+	if err != nil {
+		return nil, err
+	}
+
+	m.published = append(m.published, tx)
+	m.feeRates = append(m.feeRates, feeRate)
+	m.unspent = append(m.unspent, &lnwallet.Utxo{
+		OutPoint: wire.OutPoint{
+			Hash:  tx.TxHash(),
+			Index: uint32(len(tx.TxOut) - 1),
+		},
+	})
+
+	return tx, nil
+}
+
+func (m *mockWallet) CheckReservedValueTx(
+	lnwallet.CheckReservedValueTxReq) (chainutil.Amount, error) {
+
+	return 0, nil
+}
+
+func (m *mockWallet) ListUnspentWitness(_, _ int32,
+	_ string) ([]*lnwallet.Utxo, error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.unspent, nil
+}
+
+func (m *mockWallet) RemoveDescendants(tx *wire.MsgTx) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removed = append(m.removed, tx)
+
+	return nil
+}
+
+func (m *mockWallet) PublishTransaction(*wire.MsgTx, string) error {
+	return nil
+}
+
+func (m *mockWallet) WithCoinSelectLock(f func() error) error {
+	return f()
+}
+
+func (m *mockWallet) publishedTxns() []*wire.MsgTx {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*wire.MsgTx{}, m.published...)
+}
+
+type testContext struct {
+	t *testing.T
+
+	batcher   *Batcher
+	wallet    *mockWallet
+	estimator *chainfee.MockEstimator
+	notifier  *chainntnfs.MockChainNotifier
+	ticker    *ticker.Force
+
+	epochs    chan *chainntnfs.BlockEpoch
+	confirmed chan *chainntnfs.TxConfirmation
+}
+
+func newTestContext(t *testing.T) *testContext {
+	ctx := &testContext{
+		t:         t,
+		wallet:    &mockWallet{},
+		estimator: &chainfee.MockEstimator{},
+		notifier:  &chainntnfs.MockChainNotifier{},
+		ticker:    ticker.NewForce(time.Hour),
+		epochs:    make(chan *chainntnfs.BlockEpoch, 1),
+		confirmed: make(chan *chainntnfs.TxConfirmation),
+	}
+
+	ctx.epochs <- &chainntnfs.BlockEpoch{Height: testHeight}
+	ctx.notifier.On("RegisterBlockEpochNtfn", mock.Anything).Return(
+		&chainntnfs.BlockEpochEvent{
+			Epochs: ctx.epochs,
+			Cancel: func() {},
+		}, nil,
+	)
+	ctx.notifier.On(
+		"RegisterConfirmationsNtfn", mock.Anything, mock.Anything,
+		mock.Anything, mock.Anything,
+	).Return(&chainntnfs.ConfirmationEvent{
+		Confirmed: ctx.confirmed,
+		Cancel:    func() {},
+	}, nil)
+
+	ctx.estimator.On("EstimateFeePerKW", mock.Anything).Return(
+		chainfee.SatPerKWeight(1000), nil,
+	)
+	ctx.estimator.On("RelayFeePerKW").Return(
+		chainfee.SatPerKWeight(250),
+	)
+
+	ctx.batcher = New(&Config{
+		Wallet:      ctx.wallet,
+		Estimator:   ctx.estimator,
+		Notifier:    ctx.notifier,
+		Ticker:      ctx.ticker,
+		UrgentDelta: DefaultUrgentDelta,
+		MaxFeeRate:  10_000,
+	})
+	require.NoError(t, ctx.batcher.Start())
+	t.Cleanup(func() {
+		require.NoError(t, ctx.batcher.Stop())
+	})
+
+	return ctx
+}
+
+func (c *testContext) queue(value int64,
+	deadline int32) (uint64, <-chan *Result) {
+
+	id, resultChan, err := c.batcher.Queue(&Request{
+		Outputs:        []*wire.TxOut{testOutput(value)},
+		DeadlineHeight: deadline,
+		MinConfs:       1,
+	})
+	require.NoError(c.t, err)
+
+	return id, resultChan
+}
+
+func (c *testContext) tick() {
+	select {
+	case c.ticker.Force <- time.Now():
+	case <-time.After(testTimeout):
+		c.t.Fatal("tick not consumed")
+	}
+}
+
+func receiveResult(t *testing.T, resultChan <-chan *Result) *Result {
+	t.Helper()
+
+	select {
+	case result := <-resultChan:
+		return result
+	case <-time.After(testTimeout):
+		t.Fatal("no result received")
+		return nil
+	}
+}
+
+func testOutput(value int64) *wire.TxOut {
+	return &wire.TxOut{
+		Value:    value,
+		PkScript: append([]byte{0x00, 0x14}, make([]byte, 20)...),
+	}
+}
+
+// TestBatcherPeriodicBatch checks that queued requests are published together
+// on the next tick and forgotten once the batch confirms.
+func TestBatcherPeriodicBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(t)
+
+	_, result1 := ctx.queue(10_000, testHeight+100)
+	_, result2 := ctx.queue(20_000, testHeight+50)
+
+	// Nothing is published before the batch interval passed.
+	require.Empty(t, ctx.wallet.publishedTxns())
+
+	ctx.tick()
+
+	tx1 := receiveResult(t, result1).Tx
+	tx2 := receiveResult(t, result2).Tx
+	require.Equal(t, tx1.TxHash(), tx2.TxHash())
+	require.Len(t, tx1.TxOut, 3)
+
+	// The batch uses the fee rate of the earliest deadline.
+	ctx.estimator.AssertCalled(t, "EstimateFeePerKW", uint32(50))
+
+	// New requests wait for the pending batch to confirm.
+	_, result3 := ctx.queue(30_000, testHeight+100)
+	ctx.tick()
+	require.Len(t, ctx.wallet.publishedTxns(), 1)
+
+	ctx.confirmed <- &chainntnfs.TxConfirmation{Tx: tx1}
+	ctx.tick()
+
+	tx3 := receiveResult(t, result3).Tx
+	require.NotEqual(t, tx1.TxHash(), tx3.TxHash())
+	require.Len(t, ctx.wallet.publishedTxns(), 2)
+}
+
+// TestBatcherUrgentReplacement checks that an urgent request replaces the
+// pending batch tx with one that spends the same inputs.
+func TestBatcherUrgentReplacement(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(t)
+
+	_, result1 := ctx.queue(10_000, testHeight+100)
+	ctx.tick()
+	oldTx := receiveResult(t, result1).Tx
+
+	// The request is urgent, so it's added to the pending batch right
+	// away.
+	_, result2 := ctx.queue(20_000, testHeight+DefaultUrgentDelta)
+	newTx := receiveResult(t, result2).Tx
+
+	require.NotEqual(t, oldTx.TxHash(), newTx.TxHash())
+	require.Equal(
+		t, oldTx.TxIn[0].PreviousOutPoint,
+		newTx.TxIn[0].PreviousOutPoint,
+	)
+	require.Len(t, newTx.TxOut, 3)
+
+	ctx.wallet.mu.Lock()
+	require.Equal(t, []*wire.MsgTx{oldTx}, ctx.wallet.removed)
+
+	// The replacement pays at least the relay fee on top.
+	require.Equal(t, chainfee.SatPerKWeight(1250), ctx.wallet.feeRates[1])
+	ctx.wallet.mu.Unlock()
+}
+
+// TestBatcherReplacementChangeSpent checks that the pending batch isn't
+// replaced if its change was spent.
+func TestBatcherReplacementChangeSpent(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(t)
+
+	_, result1 := ctx.queue(10_000, testHeight+100)
+	ctx.tick()
+	oldTx := receiveResult(t, result1).Tx
+
+	ctx.wallet.mu.Lock()
+	ctx.wallet.unspent = nil
+	ctx.wallet.mu.Unlock()
+
+	_, result2 := ctx.queue(20_000, testHeight+1)
+	newTx := receiveResult(t, result2).Tx
+
+	// The urgent request is sent in a separate tx instead.
+	require.NotEqual(
+		t, oldTx.TxIn[0].PreviousOutPoint,
+		newTx.TxIn[0].PreviousOutPoint,
+	)
+	require.Len(t, newTx.TxOut, 2)
+
+	ctx.wallet.mu.Lock()
+	require.Empty(t, ctx.wallet.removed)
+	ctx.wallet.mu.Unlock()
+}
+
+// TestBatcherFallback checks that the requests of a batch the wallet can't
+// create are sent separately.
+func TestBatcherFallback(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(t)
+
+	ctx.wallet.mu.Lock()
+	ctx.wallet.maxOutputs = 1
+	ctx.wallet.mu.Unlock()
+
+	_, result1 := ctx.queue(10_000, testHeight+100)
+	_, result2 := ctx.queue(20_000, testHeight+100)
+	ctx.tick()
+
+	tx1 := receiveResult(t, result1).Tx
+	tx2 := receiveResult(t, result2).Tx
+	require.NotEqual(t, tx1.TxHash(), tx2.TxHash())
+
+	// The first tx became the pending batch, so we confirm it to let the
+	// next request go out on the next tick.
+	ctx.confirmed <- &chainntnfs.TxConfirmation{Tx: tx1}
+
+	// A request that can't be sent on its own fails.
+	_, resultChan, err := ctx.batcher.Queue(&Request{
+		Outputs: []*wire.TxOut{
+			testOutput(10_000), testOutput(20_000),
+		},
+		DeadlineHeight: testHeight + 100,
+	})
+	require.NoError(t, err)
+
+	ctx.tick()
+	require.ErrorIs(t, receiveResult(t, resultChan).Err, errTooManyOutputs)
+}
+
+// TestBatcherClaim checks that other subsystems can claim queued requests.
+func TestBatcherClaim(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(t)
+
+	_, result1 := ctx.queue(10_000, testHeight+100)
+	_, result2 := ctx.queue(20_000, testHeight+50)
+	id3, _ := ctx.queue(30_000, testHeight+100)
+
+	// Only requests whose deadline isn't before the claim's are claimed,
+	// as long as they fit within the max value.
+	claim := ctx.batcher.ClaimOutputs(
+		testHeight+60, fn.Some(chainutil.Amount(35_000)),
+	).UnwrapOrFail(t)
+	require.Equal(t, []*wire.TxOut{testOutput(10_000)}, claim.Outputs())
+
+	// Released requests can be claimed again.
+	claim.Release()
+	claim = ctx.batcher.ClaimOutputs(
+		testHeight, fn.None[chainutil.Amount](),
+	).UnwrapOrFail(t)
+	require.Len(t, claim.Outputs(), 3)
+
+	// Claimed requests can't be cancelled.
+	require.ErrorIs(t, ctx.batcher.Cancel(id3), ErrRequestNotQueued)
+
+	tx := wire.NewMsgTx(2)
+	tx.TxIn = append(tx.TxIn, &wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{1}},
+	})
+	claim.Published(tx)
+	require.Equal(t, tx, receiveResult(t, result1).Tx)
+	require.Equal(t, tx, receiveResult(t, result2).Tx)
+
+	// Nothing is left to claim or batch.
+	require.True(t, ctx.batcher.ClaimOutputs(
+		testHeight, fn.None[chainutil.Amount](),
+	).IsNone())
+
+	claim.Done()
+	ctx.tick()
+	require.Empty(t, ctx.wallet.publishedTxns())
+}
+
+// TestBatcherClaimWatchInput checks that claimed requests whose tx was given
+// up on after being published are done once one of their txns confirms, and
+// queued again once the watched input is double spent.
+func TestBatcherClaimWatchInput(t *testing.T) {
+	t.Parallel()
+
+	ctx := newTestContext(t)
+
+	spends := make(chan *chainntnfs.SpendDetail, 1)
+	ctx.notifier.On(
+		"RegisterSpendNtfn", mock.Anything, mock.Anything,
+		mock.Anything,
+	).Return(&chainntnfs.SpendEvent{
+		Spend:  spends,
+		Cancel: func() {},
+	}, nil)
+
+	input := wire.OutPoint{Hash: chainhash.Hash{1}}
+	newTx := func(lockTime uint32) *wire.MsgTx {
+		tx := wire.NewMsgTx(2)
+		tx.LockTime = lockTime
+		tx.AddTxIn(&wire.TxIn{PreviousOutPoint: input})
+
+		return tx
+	}
+
+	claimAndPublish := func() *Claim {
+		claim := ctx.batcher.ClaimOutputs(
+			testHeight, fn.None[chainutil.Amount](),
+		).UnwrapOrFail(t)
+
+		// The tx is replaced before its publisher gives up on it.
+		claim.Published(newTx(1))
+		claim.Published(newTx(2))
+		require.NoError(t, claim.WatchInput(input, nil))
+
+		return claim
+	}
+
+	// A spend by a replaced tx of the claim settles the requests.
+	_, result := ctx.queue(10_000, testHeight+100)
+	claimAndPublish()
+	receiveResult(t, result)
+
+	spenderHash := newTx(1).TxHash()
+	spends <- &chainntnfs.SpendDetail{SpenderTxHash: &spenderHash}
+	require.Eventually(t, func() bool {
+		ctx.batcher.mu.Lock()
+		defer ctx.batcher.mu.Unlock()
+
+		return len(ctx.batcher.requests) == 0
+	}, testTimeout, 10*time.Millisecond)
+
+	// A spend by another tx queues the requests again, so they're
+	// published in the next batch.
+	ctx.queue(20_000, testHeight+100)
+	claimAndPublish()
+
+	spenderHash = chainhash.Hash{2}
+	spends <- &chainntnfs.SpendDetail{SpenderTxHash: &spenderHash}
+	require.Eventually(t, func() bool {
+		claim := ctx.batcher.ClaimOutputs(
+			testHeight, fn.None[chainutil.Amount](),
+		)
+		claim.WhenSome(func(c *Claim) {
+			c.Release()
+		})
+
+		return claim.IsSome()
+	}, testTimeout, 10*time.Millisecond)
+
+	ctx.tick()
+	require.Eventually(t, func() bool {
+		return len(ctx.wallet.publishedTxns()) == 1
+	}, testTimeout, 10*time.Millisecond)
+}
@@ -244,6 +244,18 @@ type InitFundingReserveMsg struct {
 	// be used to create the combined key for musig2 based channels.
 	TapscriptRoot fn.Option[chainhash.Hash]
 
+	// DualFunded is true if the funding transaction is constructed
+	// interactively, which allows both parties to contribute funds. The
+	// initiator sets RemoteFundingAmt to zero, as it learns about the
+	// remote contribution only once the channel was accepted.
+	DualFunded bool
+
+	// LeaseExpiry is the absolute height the lease of a dual-funded
+	// CommitmentTypeScriptEnforcedLease channel expires at. As such a
+	// channel isn't funded through a shim, it's used as the thaw height
+	// of the channel instead.
+	LeaseExpiry uint32
+
 	// err is a channel in which all errors will be sent across. Will be
 	// nil if this initial set is successful.
 	//
@@ -984,7 +996,8 @@ func (l *LightningWallet) handleFundingReserveRequest(req *InitFundingReserveMsg
 					TaprootPubkey, true, DefaultAccountName,
 				)
 			},
-			Musig2: req.CommitType.IsTaproot(),
+			Musig2:      req.CommitType.IsTaproot(),
+			Interactive: req.DualFunded,
 		}
 		fundingIntent, err = req.ChanFunder.ProvisionChannel(
 			fundingReq,
@@ -1040,6 +1053,13 @@ func (l *LightningWallet) handleFundingReserveRequest(req *InitFundingReserveMsg
 		thawHeight = shimIntent.ThawHeight()
 	}
 
+	// A leased dual-funded channel isn't funded through a shim, so its
+	// thaw height is the expiry of the lease instead.
+	leased := req.CommitType == CommitmentTypeScriptEnforcedLease
+	if thawHeight == 0 && leased {
+		thawHeight = req.LeaseExpiry
+	}
+
 	// Now that we have a funding intent, we'll check whether funding a
 	// channel using it would violate our reserved value for anchor channel
 	// fee bumping.
@@ -1348,7 +1368,16 @@ func (l *LightningWallet) initOurContribution(reservation *ChannelReservation,
 	// At this point, if we have a funding intent, we'll use it to populate
 	// the existing reservation state entries for our coin selection.
 	if fundingIntent != nil {
-		if intent, ok := fundingIntent.(*chanfunding.FullIntent); ok {
+		var intent *chanfunding.FullIntent
+		switch i := fundingIntent.(type) {
+		case *chanfunding.FullIntent:
+			intent = i
+
+		case *chanfunding.InteractiveIntent:
+			intent = &i.FullIntent
+		}
+
+		if intent != nil {
 			for _, coin := range intent.InputCoins {
 				reservation.ourContribution.Inputs = append(
 					reservation.ourContribution.Inputs,
@@ -1683,6 +1712,36 @@ func (l *LightningWallet) handleContributionMsg(req *addContributionMsg) {
 		}
 		return
 
+	// The funding transaction of a dual-funded channel is constructed
+	// interactively with the remote party, so we'll pause the process
+	// until the construction completed, much like for PSBT funding.
+	case *chanfunding.InteractiveIntent:
+		fundingIntent.BindKeys(
+			&ourContribution.MultiSigKey,
+			theirContribution.MultiSigKey.PubKey,
+		)
+
+		// As the initiator, we only now learn about the amount the
+		// remote party contributes, which ends up on their side of
+		// the channel.
+		chanState := pendingReservation.partialState
+		if chanState.IsInitiator {
+			remoteAmt := theirContribution.FundingAmount
+			remoteMSat := lnwire.NewMSatFromLokis(remoteAmt)
+
+			fundingIntent.SetRemoteFundingAmt(remoteAmt)
+			chanState.Capacity += remoteAmt
+			chanState.LocalCommitment.RemoteBalance += remoteMSat
+			chanState.RemoteCommitment.RemoteBalance += remoteMSat
+			chanState.InitialRemoteBalance += remoteMSat
+		}
+
+		// Exit early because we can't continue the funding flow yet.
+		req.err <- &InteractiveFundingRequired{
+			Intent: fundingIntent,
+		}
+		return
+
 	case *chanfunding.FullIntent:
 		// Now that we know their public key, we can bind theirs as
 		// well as ours to the funding intent.
@@ -1915,6 +1974,20 @@ func (l *LightningWallet) handleChanPointReady(req *continueContributionMsg) {
 		}
 	}
 
+	// The interactively constructed funding transaction has been set on
+	// the intent, so the channel point is now known as well.
+	if interactive, ok := intent.(*chanfunding.InteractiveIntent); ok {
+		chanPointPtr, err := interactive.ChanPoint()
+		if err != nil {
+			req.err <- fmt.Errorf("unable to obtain chan "+
+				"point: %v", err)
+			return
+		}
+
+		pendingReservation.partialState.FundingOutpoint = *chanPointPtr
+		chanPoint = *chanPointPtr
+	}
+
 	// Initialize an empty sha-chain for them, tracking the current pending
 	// revocation hash (we don't yet know the preimage so we can't add it
 	// to the chain).
@@ -2665,7 +2738,7 @@ func (l *LightningWallet) ValidateChannel(channelState *chanstate.OpenChannel,
 	// used.
 	_, err = chanvalidate.Validate(&chanvalidate.Context{
 		Locator: &chanvalidate.OutPointChanLocator{
-			ChanPoint: channelState.FundingOutpoint,
+			ChanPoint: channelState.FundingTxOutpoint(),
 		},
 		MultiSigPkScript: fundingScript,
 		FundingTx:        fundingTx,
@@ -113,6 +113,17 @@ type AcceptChannel struct {
 	// negotiated.
 	LocalNonce OptMusig2NonceTLV
 
+	// FundingContribution is the amount the responder adds to the funding
+	// output of a dual-funded channel. It is only set in response to an
+	// OpenChannel message that requested a dual-funded open. The record is
+	// odd, so that peers without dual funding support can ignore it.
+	FundingContribution tlv.OptionalRecordT[tlv.TlvType65541, uint64]
+
+	// WillFund is set by the responder if it sells the inbound liquidity
+	// the initiator requested. It holds the lease rates the responder
+	// applied.
+	WillFund tlv.OptionalRecordT[tlv.TlvType8, LeaseRates]
+
 	// ExtraData is the set of data that was appended to this message to
 	// fill out the full maximum transport message size. These fields can
 	// be used to specify optional data such as custom TLV fields.
@@ -149,6 +160,8 @@ func (a *AcceptChannel) Encode(w *bytes.Buffer, pver uint32) error {
 	a.LocalNonce.WhenSome(func(localNonce Musig2NonceTLV) {
 		recordProducers = append(recordProducers, &localNonce)
 	})
+	AddOpt(&recordProducers, a.FundingContribution)
+	AddOpt(&recordProducers, a.WillFund)
 	err := EncodeMessageExtraData(&a.ExtraData, recordProducers...)
 	if err != nil {
 		return err
@@ -251,13 +264,15 @@ func (a *AcceptChannel) Decode(r io.Reader, pver uint32) error {
 	// Next we'll parse out the set of known records, keeping the raw tlv
 	// bytes untouched to ensure we don't drop any bytes erroneously.
 	var (
-		chanType    ChannelType
-		leaseExpiry LeaseExpiry
-		localNonce  = a.LocalNonce.Zero()
+		chanType     ChannelType
+		leaseExpiry  LeaseExpiry
+		localNonce   = a.LocalNonce.Zero()
+		contribution = a.FundingContribution.Zero()
+		willFund     = a.WillFund.Zero()
 	)
 	typeMap, err := tlvRecords.ExtractRecords(
 		&a.UpfrontShutdownScript, &chanType, &leaseExpiry,
-		&localNonce,
+		&localNonce, &contribution, &willFund,
 	)
 	if err != nil {
 		return err
@@ -273,6 +288,8 @@ func (a *AcceptChannel) Decode(r io.Reader, pver uint32) error {
 	if val, ok := typeMap[a.LocalNonce.TlvType()]; ok && val == nil {
 		a.LocalNonce = tlv.SomeRecordT(localNonce)
 	}
+	SetOptFromMap(typeMap, &a.FundingContribution, contribution)
+	SetOptFromMap(typeMap, &a.WillFund, willFund)
 
 	a.ExtraData = tlvRecords
 
@@ -97,6 +97,13 @@ type ChannelReestablish struct {
 	// its own nonce keyed by the relevant funding TXID.
 	LocalNonces OptLocalNonces
 
+	// NextFundingTxid is an optional field that is set when the sending
+	// node has signed a splice transaction, but hasn't yet received the
+	// remote party's TxSignatures for it. Upon receipt, the remote party
+	// should retransmit its TxSignatures if it knows of the transaction,
+	// or abort it with a TxAbort otherwise.
+	NextFundingTxid tlv.OptionalRecordT[tlv.TlvType0, [32]byte]
+
 	// ExtraData is the set of data that was appended to this message to
 	// fill out the full maximum transport message size. These fields can
 	// be used to specify optional data such as custom TLV fields.
@@ -148,7 +155,7 @@ func (a *ChannelReestablish) Encode(w *bytes.Buffer, pver uint32) error {
 		return err
 	}
 
-	recordProducers := make([]tlv.RecordProducer, 0, 3)
+	recordProducers := make([]tlv.RecordProducer, 0, 4)
 	a.LocalNonce.WhenSome(func(localNonce Musig2NonceTLV) {
 		recordProducers = append(recordProducers, &localNonce)
 	})
@@ -158,6 +165,11 @@ func (a *ChannelReestablish) Encode(w *bytes.Buffer, pver uint32) error {
 	a.LocalNonces.WhenSome(func(ln LocalNoncesData) {
 		recordProducers = append(recordProducers, &ln)
 	})
+	a.NextFundingTxid.WhenSome(
+		func(txid tlv.RecordT[tlv.TlvType0, [32]byte]) {
+			recordProducers = append(recordProducers, &txid)
+		},
+	)
 
 	err := EncodeMessageExtraData(&a.ExtraData, recordProducers...)
 	if err != nil {
@@ -221,10 +233,11 @@ func (a *ChannelReestablish) Decode(r io.Reader, pver uint32) error {
 		dynHeight       DynHeight
 		localNonce      = a.LocalNonce.Zero()
 		localNoncesData LocalNoncesData
+		nextFundingTxid = a.NextFundingTxid.Zero()
 	)
 
 	typeMap, err := tlvRecords.ExtractRecords(
-		&localNonce, &dynHeight, &localNoncesData,
+		&localNonce, &dynHeight, &localNoncesData, &nextFundingTxid,
 	)
 	if err != nil {
 		return err
@@ -239,6 +252,10 @@ func (a *ChannelReestablish) Decode(r io.Reader, pver uint32) error {
 	if val, ok := typeMap[CRLocalNonces]; ok && val == nil {
 		a.LocalNonces = SomeLocalNonces(localNoncesData)
 	}
+	val, ok := typeMap[a.NextFundingTxid.TlvType()]
+	if ok && val == nil {
+		a.NextFundingTxid = tlv.SomeRecordT(nextFundingTxid)
+	}
 
 	if len(tlvRecords) != 0 {
 		a.ExtraData = tlvRecords
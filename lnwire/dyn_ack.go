@@ -134,3 +134,15 @@ func (da *DynAck) MsgType() MessageType {
 func (da *DynAck) SerializedSize() (uint32, error) {
 	return MessageSerializedSize(da)
 }
+
+// A compile time check to ensure DynAck implements the lnwire.LinkUpdater
+// interface.
+var _ LinkUpdater = (*DynAck)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (da *DynAck) TargetChanID() ChannelID {
+	return da.ChanID
+}
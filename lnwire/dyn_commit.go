@@ -54,7 +54,7 @@ func (dc *DynCommit) Encode(w *bytes.Buffer, _ uint32) error {
 
 	// Append the known records.
 	producers = append(producers, dynProposeRecords(&dc.DynPropose)...)
-	dc.LocalNonce.WhenSome(
+	dc.DynAck.LocalNonce.WhenSome(
 		func(rec tlv.RecordT[tlv.TlvType14, Musig2Nonce]) {
 			producers = append(producers, &rec)
 		},
@@ -96,12 +96,15 @@ func (dc *DynCommit) Decode(r io.Reader, _ uint32) error {
 	csvDelay := dc.CsvDelay.Zero()
 	maxHtlcs := dc.MaxAcceptedHTLCs.Zero()
 	chanType := dc.ChannelType.Zero()
-	nonce := dc.LocalNonce.Zero()
+	nonce := dc.DynAck.LocalNonce.Zero()
+	proposeNonce := dc.DynPropose.LocalNonce.Zero()
+	kickoffFeerate := dc.KickoffFeerate.Zero()
 
 	// Parse all known records and extra data.
 	knownRecords, extraData, err := ParseAndExtractExtraData(
 		tlvRecords, &dustLimit, &maxValue, &htlcMin, &reserve,
-		&csvDelay, &maxHtlcs, &chanType, &nonce,
+		&csvDelay, &maxHtlcs, &chanType, &nonce, &proposeNonce,
+		&kickoffFeerate,
 	)
 	if err != nil {
 		return err
@@ -130,8 +133,14 @@ func (dc *DynCommit) Decode(r io.Reader, _ uint32) error {
 	if _, ok := knownRecords[dc.ChannelType.TlvType()]; ok {
 		dc.ChannelType = tlv.SomeRecordT(chanType)
 	}
-	if _, ok := knownRecords[dc.LocalNonce.TlvType()]; ok {
-		dc.LocalNonce = tlv.SomeRecordT(nonce)
+	if _, ok := knownRecords[dc.DynAck.LocalNonce.TlvType()]; ok {
+		dc.DynAck.LocalNonce = tlv.SomeRecordT(nonce)
+	}
+	if _, ok := knownRecords[dc.DynPropose.LocalNonce.TlvType()]; ok {
+		dc.DynPropose.LocalNonce = tlv.SomeRecordT(proposeNonce)
+	}
+	if _, ok := knownRecords[dc.KickoffFeerate.TlvType()]; ok {
+		dc.KickoffFeerate = tlv.SomeRecordT(kickoffFeerate)
 	}
 
 	dc.ExtraData = extraData
@@ -153,3 +162,15 @@ func (dc *DynCommit) MsgType() MessageType {
 func (dc *DynCommit) SerializedSize() (uint32, error) {
 	return MessageSerializedSize(dc)
 }
+
+// A compile time check to ensure DynCommit implements the lnwire.LinkUpdater
+// interface.
+var _ LinkUpdater = (*DynCommit)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (dc *DynCommit) TargetChanID() ChannelID {
+	return dc.DynPropose.ChanID
+}
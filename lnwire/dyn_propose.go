@@ -47,6 +47,17 @@ type DynPropose struct {
 	// parameter.
 	ChannelType tlv.OptionalRecordT[tlv.TlvType12, ChannelType]
 
+	// LocalNonce, if not nil, carries the sender's musig2 verification
+	// nonce for its current commitment. It is required when ChannelType
+	// proposes an upgrade to a taproot channel, since the first taproot
+	// commitments are signed before the upgrade takes effect.
+	LocalNonce tlv.OptionalRecordT[tlv.TlvType16, Musig2Nonce]
+
+	// KickoffFeerate, if not nil, is the fee rate in sat/kw the initiator
+	// proposes for the transaction that moves the channel to its new
+	// taproot funding output.
+	KickoffFeerate tlv.OptionalRecordT[tlv.TlvType18, uint32]
+
 	// ExtraData is the set of data that was appended to this message to
 	// fill out the full maximum transport message size. These fields can
 	// be used to specify optional data such as custom TLV fields.
@@ -118,10 +129,12 @@ func (dp *DynPropose) Decode(r io.Reader, _ uint32) error {
 	csvDelay := dp.CsvDelay.Zero()
 	maxHtlcs := dp.MaxAcceptedHTLCs.Zero()
 	chanType := dp.ChannelType.Zero()
+	nonce := dp.LocalNonce.Zero()
+	kickoffFeerate := dp.KickoffFeerate.Zero()
 
 	knownRecords, extraData, err := ParseAndExtractExtraData(
 		tlvRecords, &dustLimit, &maxValue, &htlcMin, &reserve,
-		&csvDelay, &maxHtlcs, &chanType,
+		&csvDelay, &maxHtlcs, &chanType, &nonce, &kickoffFeerate,
 	)
 	if err != nil {
 		return err
@@ -157,6 +170,14 @@ func (dp *DynPropose) Decode(r io.Reader, _ uint32) error {
 		dp.ChannelType = tlv.SomeRecordT(chanType)
 	}
 
+	if _, ok := knownRecords[dp.LocalNonce.TlvType()]; ok {
+		dp.LocalNonce = tlv.SomeRecordT(nonce)
+	}
+
+	if _, ok := knownRecords[dp.KickoffFeerate.TlvType()]; ok {
+		dp.KickoffFeerate = tlv.SomeRecordT(kickoffFeerate)
+	}
+
 	dp.ExtraData = extraData
 
 	return nil
@@ -193,7 +214,7 @@ func (dp *DynPropose) SerializeTlvData() ([]byte, error) {
 }
 
 func dynProposeRecords(dp *DynPropose) []tlv.RecordProducer {
-	recordProducers := make([]tlv.RecordProducer, 0, 7)
+	recordProducers := make([]tlv.RecordProducer, 0, 9)
 
 	dp.DustLimit.WhenSome(
 		func(dl tlv.RecordT[tlv.TlvType0,
@@ -234,6 +255,28 @@ func dynProposeRecords(dp *DynPropose) []tlv.RecordProducer {
 			recordProducers = append(recordProducers, &ty)
 		},
 	)
+	dp.LocalNonce.WhenSome(
+		func(nonce tlv.RecordT[tlv.TlvType16, Musig2Nonce]) {
+			recordProducers = append(recordProducers, &nonce)
+		},
+	)
+	dp.KickoffFeerate.WhenSome(
+		func(rate tlv.RecordT[tlv.TlvType18, uint32]) {
+			recordProducers = append(recordProducers, &rate)
+		},
+	)
 
 	return recordProducers
 }
+
+// A compile time check to ensure DynPropose implements the lnwire.LinkUpdater
+// interface.
+var _ LinkUpdater = (*DynPropose)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (dp *DynPropose) TargetChanID() ChannelID {
+	return dp.ChanID
+}
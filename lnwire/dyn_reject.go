@@ -85,3 +85,15 @@ func (dr *DynReject) MsgType() MessageType {
 func (dr *DynReject) SerializedSize() (uint32, error) {
 	return MessageSerializedSize(dr)
 }
+
+// A compile time check to ensure DynReject implements the lnwire.LinkUpdater
+// interface.
+var _ LinkUpdater = (*DynReject)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (dr *DynReject) TargetChanID() ChannelID {
+	return dr.ChanID
+}
@@ -181,6 +181,16 @@ const (
 	// quiescence protocol.
 	QuiescenceOptional FeatureBit = 35
 
+	// ProvideStorageRequired is a required feature bit that signals that
+	// the node requires its peers to store a backup blob on its behalf,
+	// and returns the blobs its peers asked it to store on reconnection.
+	ProvideStorageRequired FeatureBit = 42
+
+	// ProvideStorageOptional is an optional feature bit that signals that
+	// the node stores a backup blob on behalf of its peers, and asks its
+	// peers to do the same for it.
+	ProvideStorageOptional FeatureBit = 43
+
 	// ExplicitChannelTypeRequired is a required bit that denotes that a
 	// connection established with this node is to use explicit channel
 	// commitment types for negotiation instead of the existing implicit
@@ -233,6 +243,18 @@ const (
 	// able and willing to accept keysend payments.
 	KeysendOptional = 55
 
+	// TrampolineRoutingRequired is a required feature bit that signals
+	// that the node is able to relay payments as a trampoline node, finding
+	// a route to the next trampoline node or the final recipient on behalf
+	// of the sender.
+	TrampolineRoutingRequired FeatureBit = 56
+
+	// TrampolineRoutingOptional is an optional feature bit that signals
+	// that the node is able to relay payments as a trampoline node, finding
+	// a route to the next trampoline node or the final recipient on behalf
+	// of the sender.
+	TrampolineRoutingOptional FeatureBit = 57
+
 	// RbfCoopCloseRequired is a required feature bit that signals that
 	// the new RBF-based co-op close protocol is supported.
 	RbfCoopCloseRequired = 60
@@ -249,6 +271,56 @@ const (
 	// that the new RBF-based co-op close protocol is supported.
 	RbfCoopCloseOptionalStaging = 161
 
+	// SpliceRequiredStaging is a required feature bit that signals that
+	// the node requires support for splicing, which allows funds to be
+	// added to or removed from a channel without closing it.
+	SpliceRequiredStaging FeatureBit = 162
+
+	// SpliceOptionalStaging is an optional feature bit that signals that
+	// the node supports splicing.
+	SpliceOptionalStaging FeatureBit = 163
+
+	// DynamicCommitmentsRequired is a required feature bit that signals
+	// that the node requires support for the dynamic commitments protocol,
+	// which allows the parameters and type of a live channel to be
+	// renegotiated without closing it.
+	//
+	// TODO: Decide on actual feature bit value.
+	DynamicCommitmentsRequired FeatureBit = 164
+
+	// DynamicCommitmentsOptional is an optional feature bit that signals
+	// that the node supports the dynamic commitments protocol.
+	//
+	// TODO: Decide on actual feature bit value.
+	DynamicCommitmentsOptional FeatureBit = 165
+
+	// GossipV2Required is a required feature bit that signals that the
+	// node requires support for the taproot gossip protocol, which
+	// consists of the channel_announcement_2, channel_update_2 and
+	// announcement_signatures_2 messages.
+	//
+	// TODO: Decide on actual feature bit value.
+	GossipV2Required FeatureBit = 166
+
+	// GossipV2Optional is an optional feature bit that signals that the
+	// node supports the taproot gossip protocol.
+	//
+	// TODO: Decide on actual feature bit value.
+	GossipV2Optional FeatureBit = 167
+
+	// DualFundRequiredStaging is a required feature bit that signals that
+	// the node requires support for dual-funded channel establishment,
+	// where both peers may contribute inputs to the funding transaction.
+	// As the establishment is negotiated with experimental records of the
+	// open_channel and accept_channel messages rather than with
+	// open_channel2 and accept_channel2, this isn't the BOLT 2 dual_fund
+	// bit.
+	DualFundRequiredStaging FeatureBit = 168
+
+	// DualFundOptionalStaging is an optional feature bit that signals that
+	// the node supports dual-funded channel establishment.
+	DualFundOptionalStaging FeatureBit = 169
+
 	// ScriptEnforcedLeaseRequired is a required feature bit that signals
 	// that the node requires channels having zero-fee second-level HTLC
 	// transactions, which also imply anchor commitments, along with an
@@ -379,8 +451,12 @@ var Features = map[FeatureBit]string{
 	ExplicitChannelTypeRequired:          "explicit-commitment-type",
 	KeysendOptional:                      "keysend",
 	KeysendRequired:                      "keysend",
+	TrampolineRoutingOptional:            "trampoline-routing",
+	TrampolineRoutingRequired:            "trampoline-routing",
 	ScriptEnforcedLeaseRequired:          "script-enforced-lease",
 	ScriptEnforcedLeaseOptional:          "script-enforced-lease",
+	ProvideStorageRequired:               "provide-storage",
+	ProvideStorageOptional:               "provide-storage",
 	ScidAliasRequired:                    "scid-alias",
 	ScidAliasOptional:                    "scid-alias",
 	ZeroConfRequired:                     "zero-conf",
@@ -389,6 +465,8 @@ var Features = map[FeatureBit]string{
 	RouteBlindingOptional:                "route-blinding",
 	ShutdownAnySegwitRequired:            "shutdown-any-segwit",
 	ShutdownAnySegwitOptional:            "shutdown-any-segwit",
+	DualFundOptionalStaging:              "dual-fund-x",
+	DualFundRequiredStaging:              "dual-fund-x",
 	SimpleTaprootChannelsRequiredFinal:   "simple-taproot-chans",
 	SimpleTaprootChannelsOptionalFinal:   "simple-taproot-chans",
 	SimpleTaprootChannelsRequiredStaging: "simple-taproot-chans-x",
@@ -403,6 +481,12 @@ var Features = map[FeatureBit]string{
 	RbfCoopCloseRequired:                 "rbf-coop-close",
 	RbfCoopCloseOptionalStaging:          "rbf-coop-close-x",
 	RbfCoopCloseRequiredStaging:          "rbf-coop-close-x",
+	SpliceOptionalStaging:                "splice-x",
+	SpliceRequiredStaging:                "splice-x",
+	DynamicCommitmentsOptional:           "dynamic-commitments",
+	DynamicCommitmentsRequired:           "dynamic-commitments",
+	GossipV2Optional:                     "gossip-v2",
+	GossipV2Required:                     "gossip-v2",
 	OnionMessagesOptional:                "onion-messages",
 	OnionMessagesRequired:                "onion-messages",
 }
@@ -0,0 +1,217 @@
+package lnwire
+
+import (
+	"io"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/tlv"
+)
+
+const (
+	// LeaseRatesRecordType is the type of the record a node uses to
+	// advertise its liquidity lease rates within the extra data of its
+	// node announcement.
+	LeaseRatesRecordType tlv.Type = 1
+
+	// leaseRatesSize is the size of the encoded LeaseRates record.
+	leaseRatesSize = 14
+
+	// leaseRequestSize is the size of the encoded LeaseRequest record.
+	leaseRequestSize = 8 + leaseRatesSize
+)
+
+// LeaseRates are the rates a node charges for leasing its funds to a peer as
+// inbound liquidity of a dual-funded channel.
+type LeaseRates struct {
+	// FundingWeight is the weight of the inputs and outputs the seller
+	// adds to the funding transaction, which the buyer pays for.
+	FundingWeight uint16
+
+	// LeaseFeeBasis is the proportional fee charged for the leased
+	// amount, in basis points.
+	LeaseFeeBasis uint16
+
+	// ChannelFeeMaxProportional is the maximum proportional routing fee,
+	// in thousandths of a basis point, the seller charges for forwarding
+	// payments through the leased channel.
+	ChannelFeeMaxProportional uint16
+
+	// LeaseFeeBase is the fixed fee charged for a lease, in loki.
+	LeaseFeeBase uint32
+
+	// ChannelFeeMaxBase is the maximum base routing fee, in milli-loki,
+	// the seller charges for forwarding payments through the leased
+	// channel.
+	ChannelFeeMaxBase uint32
+}
+
+// LeaseFee returns the fee in loki a buyer pays for leasing amt loki, given
+// the fee rate in loki per kw of the funding transaction.
+func (l *LeaseRates) LeaseFee(amt uint64, feePerKw uint32) uint64 {
+	fundingFee := uint64(l.FundingWeight) * uint64(feePerKw) / 1000
+	proportionalFee := amt * uint64(l.LeaseFeeBasis) / 10_000
+
+	return uint64(l.LeaseFeeBase) + proportionalFee + fundingFee
+}
+
+// Record returns a TLV record that can be used to encode/decode the lease
+// rates from a given TLV stream.
+func (l *LeaseRates) Record() tlv.Record {
+	return tlv.MakeStaticRecord(
+		LeaseRatesRecordType, l, leaseRatesSize, leaseRatesEncoder,
+		leaseRatesDecoder,
+	)
+}
+
+// leaseRatesEncoder is a custom TLV encoder for the LeaseRates record.
+func leaseRatesEncoder(w io.Writer, val interface{}, buf *[8]byte) error {
+	v, ok := val.(*LeaseRates)
+	if !ok {
+		return tlv.NewTypeForEncodingErr(val, "lnwire.LeaseRates")
+	}
+
+	if err := tlv.EUint16T(w, v.FundingWeight, buf); err != nil {
+		return err
+	}
+	if err := tlv.EUint16T(w, v.LeaseFeeBasis, buf); err != nil {
+		return err
+	}
+	err := tlv.EUint16T(w, v.ChannelFeeMaxProportional, buf)
+	if err != nil {
+		return err
+	}
+	if err := tlv.EUint32T(w, v.LeaseFeeBase, buf); err != nil {
+		return err
+	}
+
+	return tlv.EUint32T(w, v.ChannelFeeMaxBase, buf)
+}
+
+// leaseRatesDecoder is a custom TLV decoder for the LeaseRates record.
+func leaseRatesDecoder(r io.Reader, val interface{}, buf *[8]byte,
+	l uint64) error {
+
+	v, ok := val.(*LeaseRates)
+	if !ok || l != leaseRatesSize {
+		return tlv.NewTypeForDecodingErr(
+			val, "lnwire.LeaseRates", l, leaseRatesSize,
+		)
+	}
+
+	if err := tlv.DUint16(r, &v.FundingWeight, buf, 2); err != nil {
+		return err
+	}
+	if err := tlv.DUint16(r, &v.LeaseFeeBasis, buf, 2); err != nil {
+		return err
+	}
+	err := tlv.DUint16(r, &v.ChannelFeeMaxProportional, buf, 2)
+	if err != nil {
+		return err
+	}
+	if err := tlv.DUint32(r, &v.LeaseFeeBase, buf, 4); err != nil {
+		return err
+	}
+
+	return tlv.DUint32(r, &v.ChannelFeeMaxBase, buf, 4)
+}
+
+// LeaseRequest is sent by the initiator of a dual-funded channel to buy
+// inbound liquidity from the responder at the rates the responder advertised.
+type LeaseRequest struct {
+	// Amount is the amount in loki the responder should contribute to the
+	// funding output.
+	Amount uint64
+
+	// Rates are the advertised rates of the responder the initiator
+	// agrees to pay.
+	Rates LeaseRates
+}
+
+// Record returns a TLV record that can be used to encode/decode the lease
+// request from a given TLV stream.
+//
+// NOTE: The record type is overridden by the message holding the request.
+func (l *LeaseRequest) Record() tlv.Record {
+	return tlv.MakeStaticRecord(
+		0, l, leaseRequestSize, leaseRequestEncoder,
+		leaseRequestDecoder,
+	)
+}
+
+// leaseRequestEncoder is a custom TLV encoder for the LeaseRequest record.
+func leaseRequestEncoder(w io.Writer, val interface{}, buf *[8]byte) error {
+	v, ok := val.(*LeaseRequest)
+	if !ok {
+		return tlv.NewTypeForEncodingErr(val, "lnwire.LeaseRequest")
+	}
+
+	if err := tlv.EUint64T(w, v.Amount, buf); err != nil {
+		return err
+	}
+
+	return leaseRatesEncoder(w, &v.Rates, buf)
+}
+
+// leaseRequestDecoder is a custom TLV decoder for the LeaseRequest record.
+func leaseRequestDecoder(r io.Reader, val interface{}, buf *[8]byte,
+	l uint64) error {
+
+	v, ok := val.(*LeaseRequest)
+	if !ok || l != leaseRequestSize {
+		return tlv.NewTypeForDecodingErr(
+			val, "lnwire.LeaseRequest", l, leaseRequestSize,
+		)
+	}
+
+	if err := tlv.DUint64(r, &v.Amount, buf, 8); err != nil {
+		return err
+	}
+
+	return leaseRatesDecoder(r, &v.Rates, buf, leaseRatesSize)
+}
+
+// ExtractLeaseRates returns the lease rates a node advertised within the
+// extra data of its node announcement, if any.
+func ExtractLeaseRates(extraData ExtraOpaqueData) (fn.Option[LeaseRates],
+	error) {
+
+	var rates LeaseRates
+	typeMap, err := extraData.ExtractRecords(&rates)
+	if err != nil {
+		return fn.None[LeaseRates](), err
+	}
+
+	val, ok := typeMap[LeaseRatesRecordType]
+	if !ok || val != nil {
+		return fn.None[LeaseRates](), nil
+	}
+
+	return fn.Some(rates), nil
+}
+
+// SetLeaseRates adds the lease rates to the extra data of a node
+// announcement, replacing any rates that were already present while keeping
+// all other records.
+func SetLeaseRates(extraData *ExtraOpaqueData, rates LeaseRates) error {
+	typeMap, err := extraData.ExtractRecords()
+	if err != nil {
+		return err
+	}
+	delete(typeMap, LeaseRatesRecordType)
+
+	otherRecords, err := NewExtraOpaqueData(typeMap)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := MergeAndEncode(
+		[]tlv.RecordProducer{&rates}, otherRecords, nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	*extraData = encoded
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package lnwire
+
+import (
+	"testing"
+
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLeaseFee asserts that the lease fee is made up of the base fee, the
+// proportional fee and the fee for the seller's funding weight.
+func TestLeaseFee(t *testing.T) {
+	t.Parallel()
+
+	rates := LeaseRates{
+		FundingWeight: 444,
+		LeaseFeeBasis: 50,
+		LeaseFeeBase:  1_000,
+	}
+
+	// 1_000 base + 0.5% of 1_000_000 + 444 * 2_500 / 1_000.
+	require.EqualValues(t, 7_110, rates.LeaseFee(1_000_000, 2_500))
+	require.EqualValues(t, 1_000, rates.LeaseFee(0, 0))
+}
+
+// TestSetLeaseRates asserts that lease rates can be added to and replaced
+// within extra data without losing any other records.
+func TestSetLeaseRates(t *testing.T) {
+	t.Parallel()
+
+	// Extra data without the record doesn't hold any rates.
+	other := tlv.NewPrimitiveRecord[tlv.TlvType3](uint32(7))
+	var extraData ExtraOpaqueData
+	require.NoError(t, extraData.PackRecords(&other))
+
+	rates, err := ExtractLeaseRates(extraData)
+	require.NoError(t, err)
+	require.True(t, rates.IsNone())
+
+	setAndAssert := func(expected LeaseRates) {
+		require.NoError(t, SetLeaseRates(&extraData, expected))
+
+		rates, err := ExtractLeaseRates(extraData)
+		require.NoError(t, err)
+		require.Equal(t, expected, rates.UnwrapOr(LeaseRates{}))
+
+		var otherVal = other.Zero()
+		typeMap, err := extraData.ExtractRecords(&otherVal)
+		require.NoError(t, err)
+		require.Contains(t, typeMap, other.TlvType())
+		require.EqualValues(t, 7, otherVal.Val)
+	}
+
+	setAndAssert(LeaseRates{
+		FundingWeight:             444,
+		LeaseFeeBasis:             50,
+		ChannelFeeMaxProportional: 100,
+		LeaseFeeBase:              1_000,
+		ChannelFeeMaxBase:         2_000,
+	})
+	setAndAssert(LeaseRates{LeaseFeeBase: 500})
+}
@@ -149,6 +149,23 @@ type OpenChannel struct {
 	// negotiated.
 	LocalNonce OptMusig2NonceTLV
 
+	// DualFundFeeRate is set by the initiator when it wishes to open a
+	// dual-funded channel. It is the fee rate, in loki per kw, that the
+	// interactively constructed funding transaction should pay. The
+	// record is odd, so that peers without dual funding support can
+	// ignore it.
+	DualFundFeeRate tlv.OptionalRecordT[tlv.TlvType65541, uint32]
+
+	// DualFundLocktime is the locktime the initiator proposes for the
+	// interactively constructed funding transaction. It is only set along
+	// with DualFundFeeRate.
+	DualFundLocktime tlv.OptionalRecordT[tlv.TlvType65543, uint32]
+
+	// RequestFunds is set by the initiator of a dual-funded channel to buy
+	// inbound liquidity from the responder at its advertised lease rates.
+	// The lease fee is paid through the push amount.
+	RequestFunds tlv.OptionalRecordT[tlv.TlvType10, LeaseRequest]
+
 	// ExtraData is the set of data that was appended to this message to
 	// fill out the full maximum transport message size. These fields can
 	// be used to specify optional data such as custom TLV fields.
@@ -183,6 +200,9 @@ func (o *OpenChannel) Encode(w *bytes.Buffer, pver uint32) error {
 	o.LocalNonce.WhenSome(func(localNonce Musig2NonceTLV) {
 		recordProducers = append(recordProducers, &localNonce)
 	})
+	AddOpt(&recordProducers, o.DualFundFeeRate)
+	AddOpt(&recordProducers, o.DualFundLocktime)
+	AddOpt(&recordProducers, o.RequestFunds)
 	err := EncodeMessageExtraData(&o.ExtraData, recordProducers...)
 	if err != nil {
 		return err
@@ -308,10 +328,13 @@ func (o *OpenChannel) Decode(r io.Reader, pver uint32) error {
 		chanType    ChannelType
 		leaseExpiry LeaseExpiry
 		localNonce  = o.LocalNonce.Zero()
+		feeRate     = o.DualFundFeeRate.Zero()
+		locktime    = o.DualFundLocktime.Zero()
+		leaseReq    = o.RequestFunds.Zero()
 	)
 	typeMap, err := tlvRecords.ExtractRecords(
 		&o.UpfrontShutdownScript, &chanType, &leaseExpiry,
-		&localNonce,
+		&localNonce, &feeRate, &locktime, &leaseReq,
 	)
 	if err != nil {
 		return err
@@ -327,6 +350,9 @@ func (o *OpenChannel) Decode(r io.Reader, pver uint32) error {
 	if val, ok := typeMap[o.LocalNonce.TlvType()]; ok && val == nil {
 		o.LocalNonce = tlv.SomeRecordT(localNonce)
 	}
+	SetOptFromMap(typeMap, &o.DualFundFeeRate, feeRate)
+	SetOptFromMap(typeMap, &o.DualFundLocktime, locktime)
+	SetOptFromMap(typeMap, &o.RequestFunds, leaseReq)
 
 	o.ExtraData = tlvRecords
 
@@ -0,0 +1,148 @@
+package lnwire
+
+import (
+	"bytes"
+	"io"
+)
+
+// MaxPeerStorageBlobSize is the maximum size of a blob a node may ask its
+// peers to store. It's the largest blob that fits a message next to the
+// message type and the length prefix of the blob.
+const MaxPeerStorageBlobSize = 65531
+
+// PeerStorageBlob is a length prefixed, opaque blob a node asks its peers to
+// store on its behalf.
+type PeerStorageBlob []byte
+
+// PeerStorage is sent to a peer that signals option_provide_storage to ask it
+// to store the given blob for us. The peer replaces any blob it stored for us
+// before, and returns the latest one in a PeerStorageRetrieval message each
+// time we reconnect.
+type PeerStorage struct {
+	// Blob is the data the peer should store for us.
+	Blob PeerStorageBlob
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure PeerStorage implements the lnwire.Message
+// interface.
+var _ Message = (*PeerStorage)(nil)
+
+// A compile time check to ensure PeerStorage implements the
+// lnwire.SizeableMessage interface.
+var _ SizeableMessage = (*PeerStorage)(nil)
+
+// Encode serializes the target PeerStorage into the passed io.Writer.
+// Serialization will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (p *PeerStorage) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WritePeerStorageBlob(w, p.Blob); err != nil {
+		return err
+	}
+
+	return WriteBytes(w, p.ExtraData)
+}
+
+// Decode deserializes the serialized PeerStorage stored in the passed
+// io.Reader into the target PeerStorage using the deserialization rules
+// defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (p *PeerStorage) Decode(r io.Reader, _ uint32) error {
+	if err := ReadElements(r, &p.Blob, &p.ExtraData); err != nil {
+		return err
+	}
+
+	// This is required to pass the fuzz test round trip equality check.
+	if len(p.ExtraData) == 0 {
+		p.ExtraData = nil
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a PeerStorage on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (p *PeerStorage) MsgType() MessageType {
+	return MsgPeerStorage
+}
+
+// SerializedSize returns the serialized size of the message in bytes.
+//
+// This is part of the lnwire.SizeableMessage interface.
+func (p *PeerStorage) SerializedSize() (uint32, error) {
+	return MessageSerializedSize(p)
+}
+
+// PeerStorageRetrieval is sent to a peer after reconnecting to return the
+// latest blob it asked us to store with a PeerStorage message.
+type PeerStorageRetrieval struct {
+	// Blob is the data the peer asked us to store.
+	Blob PeerStorageBlob
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure PeerStorageRetrieval implements the
+// lnwire.Message interface.
+var _ Message = (*PeerStorageRetrieval)(nil)
+
+// A compile time check to ensure PeerStorageRetrieval implements the
+// lnwire.SizeableMessage interface.
+var _ SizeableMessage = (*PeerStorageRetrieval)(nil)
+
+// Encode serializes the target PeerStorageRetrieval into the passed
+// io.Writer. Serialization will observe the rules defined by the passed
+// protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (p *PeerStorageRetrieval) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WritePeerStorageBlob(w, p.Blob); err != nil {
+		return err
+	}
+
+	return WriteBytes(w, p.ExtraData)
+}
+
+// Decode deserializes the serialized PeerStorageRetrieval stored in the
+// passed io.Reader into the target PeerStorageRetrieval using the
+// deserialization rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (p *PeerStorageRetrieval) Decode(r io.Reader, _ uint32) error {
+	if err := ReadElements(r, &p.Blob, &p.ExtraData); err != nil {
+		return err
+	}
+
+	// This is required to pass the fuzz test round trip equality check.
+	if len(p.ExtraData) == 0 {
+		p.ExtraData = nil
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a PeerStorageRetrieval on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (p *PeerStorageRetrieval) MsgType() MessageType {
+	return MsgPeerStorageRetrieval
+}
+
+// SerializedSize returns the serialized size of the message in bytes.
+//
+// This is part of the lnwire.SizeableMessage interface.
+func (p *PeerStorageRetrieval) SerializedSize() (uint32, error) {
+	return MessageSerializedSize(p)
+}
@@ -127,3 +127,33 @@ func ExtraSignedFieldsFromTypeMapFn(m tlv.TypeMap,
 
 	return extraFields
 }
+
+// ExtraSignedFieldsFromOpaqueData parses the given opaque TLV stream into a
+// set of ExtraSignedFields. This is the inverse of ToOpaqueData and is used to
+// rebuild a pure TLV message from persisted extra data.
+func ExtraSignedFieldsFromOpaqueData(
+	data ExtraOpaqueData) (ExtraSignedFields, error) {
+
+	typeMap, err := data.ExtractRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	extraFields := make(ExtraSignedFields, len(typeMap))
+	for t, v := range typeMap {
+		extraFields[uint64(t)] = v
+	}
+
+	return extraFields, nil
+}
+
+// ToOpaqueData encodes the extra signed fields as a TLV stream so that they
+// can be persisted alongside the known fields of the message.
+func (e ExtraSignedFields) ToOpaqueData() (ExtraOpaqueData, error) {
+	typeMap := make(tlv.TypeMap, len(e))
+	for t, v := range e {
+		typeMap[tlv.Type(t)] = v
+	}
+
+	return NewExtraOpaqueData(typeMap)
+}
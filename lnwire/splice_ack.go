@@ -0,0 +1,108 @@
+package lnwire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/flokiorg/go-flokicoin/crypto"
+)
+
+// SpliceAck is sent in response to a SpliceInit to accept the proposed
+// splice. It carries the responder's own contribution to the new channel
+// capacity.
+type SpliceAck struct {
+	// ChanID identifies the channel to be spliced.
+	ChanID ChannelID
+
+	// FundingContribution is the amount the responder is adding to, or
+	// if negative, removing from the channel.
+	FundingContribution int64
+
+	// FundingPubKey is the funding key the responder will use for the new
+	// funding output.
+	FundingPubKey *crypto.PublicKey
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure SpliceAck implements the lnwire.Message
+// interface.
+var _ Message = (*SpliceAck)(nil)
+
+// A compile time check to ensure SpliceAck implements the
+// lnwire.SizeableMessage interface.
+var _ SizeableMessage = (*SpliceAck)(nil)
+
+// Encode serializes the target SpliceAck into the passed io.Writer.
+// Serialization will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (s *SpliceAck) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WriteChannelID(w, s.ChanID); err != nil {
+		return err
+	}
+
+	if err := WriteUint64(w, uint64(s.FundingContribution)); err != nil {
+		return err
+	}
+
+	if err := WritePublicKey(w, s.FundingPubKey); err != nil {
+		return err
+	}
+
+	return WriteBytes(w, s.ExtraData)
+}
+
+// Decode deserializes the serialized SpliceAck stored in the passed io.Reader
+// into the target SpliceAck using the deserialization rules defined by the
+// passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (s *SpliceAck) Decode(r io.Reader, _ uint32) error {
+	var contribution uint64
+	err := ReadElements(
+		r, &s.ChanID, &contribution, &s.FundingPubKey, &s.ExtraData,
+	)
+	if err != nil {
+		return err
+	}
+
+	s.FundingContribution = int64(contribution)
+
+	// This is required to pass the fuzz test round trip equality check.
+	if len(s.ExtraData) == 0 {
+		s.ExtraData = nil
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a SpliceAck on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (s *SpliceAck) MsgType() MessageType {
+	return MsgSpliceAck
+}
+
+// SerializedSize returns the serialized size of the message in bytes.
+//
+// This is part of the lnwire.SizeableMessage interface.
+func (s *SpliceAck) SerializedSize() (uint32, error) {
+	return MessageSerializedSize(s)
+}
+
+// A compile time check to ensure SpliceAck implements the lnwire.LinkUpdater
+// interface.
+var _ LinkUpdater = (*SpliceAck)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (s *SpliceAck) TargetChanID() ChannelID {
+	return s.ChanID
+}
@@ -0,0 +1,124 @@
+package lnwire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/flokiorg/go-flokicoin/crypto"
+)
+
+// SpliceInit is sent by the splice initiator once the channel has been
+// quiesced to propose changing the capacity of the channel. It kicks off the
+// interactive construction of the splice transaction.
+type SpliceInit struct {
+	// ChanID identifies the channel to be spliced.
+	ChanID ChannelID
+
+	// FundingContribution is the amount the initiator is adding to, or if
+	// negative, removing from the channel.
+	FundingContribution int64
+
+	// FeeRate is the fee rate in loki per kw the splice transaction
+	// should pay.
+	FeeRate uint32
+
+	// Locktime is the locktime to use for the splice transaction.
+	Locktime uint32
+
+	// FundingPubKey is the funding key the initiator will use for the new
+	// funding output.
+	FundingPubKey *crypto.PublicKey
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure SpliceInit implements the lnwire.Message
+// interface.
+var _ Message = (*SpliceInit)(nil)
+
+// A compile time check to ensure SpliceInit implements the
+// lnwire.SizeableMessage interface.
+var _ SizeableMessage = (*SpliceInit)(nil)
+
+// Encode serializes the target SpliceInit into the passed io.Writer.
+// Serialization will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (s *SpliceInit) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WriteChannelID(w, s.ChanID); err != nil {
+		return err
+	}
+
+	if err := WriteUint64(w, uint64(s.FundingContribution)); err != nil {
+		return err
+	}
+
+	if err := WriteUint32(w, s.FeeRate); err != nil {
+		return err
+	}
+
+	if err := WriteUint32(w, s.Locktime); err != nil {
+		return err
+	}
+
+	if err := WritePublicKey(w, s.FundingPubKey); err != nil {
+		return err
+	}
+
+	return WriteBytes(w, s.ExtraData)
+}
+
+// Decode deserializes the serialized SpliceInit stored in the passed
+// io.Reader into the target SpliceInit using the deserialization rules
+// defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (s *SpliceInit) Decode(r io.Reader, _ uint32) error {
+	var contribution uint64
+	err := ReadElements(
+		r, &s.ChanID, &contribution, &s.FeeRate, &s.Locktime,
+		&s.FundingPubKey, &s.ExtraData,
+	)
+	if err != nil {
+		return err
+	}
+
+	s.FundingContribution = int64(contribution)
+
+	// This is required to pass the fuzz test round trip equality check.
+	if len(s.ExtraData) == 0 {
+		s.ExtraData = nil
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a SpliceInit on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (s *SpliceInit) MsgType() MessageType {
+	return MsgSpliceInit
+}
+
+// SerializedSize returns the serialized size of the message in bytes.
+//
+// This is part of the lnwire.SizeableMessage interface.
+func (s *SpliceInit) SerializedSize() (uint32, error) {
+	return MessageSerializedSize(s)
+}
+
+// A compile time check to ensure SpliceInit implements the lnwire.LinkUpdater
+// interface.
+var _ LinkUpdater = (*SpliceInit)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (s *SpliceInit) TargetChanID() ChannelID {
+	return s.ChanID
+}
@@ -0,0 +1,119 @@
+package lnwire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/flokiorg/flnd/tlv"
+)
+
+// SpliceLocked is sent by either side once the splice transaction has reached
+// the required number of confirmations. Once both sides have sent it, the new
+// funding output replaces the previous one.
+type SpliceLocked struct {
+	// ChanID identifies the channel that was spliced.
+	ChanID ChannelID
+
+	// SpliceTxID is the txid of the confirmed splice transaction.
+	SpliceTxID [32]byte
+
+	// NextLocalNonce is an optional field carrying the sender's musig2
+	// verification nonce for its next commitment. It is sent when the
+	// splice moved the channel to a taproot funding output.
+	NextLocalNonce OptMusig2NonceTLV
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure SpliceLocked implements the lnwire.Message
+// interface.
+var _ Message = (*SpliceLocked)(nil)
+
+// A compile time check to ensure SpliceLocked implements the
+// lnwire.SizeableMessage interface.
+var _ SizeableMessage = (*SpliceLocked)(nil)
+
+// Encode serializes the target SpliceLocked into the passed io.Writer.
+// Serialization will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (s *SpliceLocked) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WriteChannelID(w, s.ChanID); err != nil {
+		return err
+	}
+
+	if err := WriteBytes(w, s.SpliceTxID[:]); err != nil {
+		return err
+	}
+
+	recordProducers := make([]tlv.RecordProducer, 0, 1)
+	s.NextLocalNonce.WhenSome(func(localNonce Musig2NonceTLV) {
+		recordProducers = append(recordProducers, &localNonce)
+	})
+
+	err := EncodeMessageExtraData(&s.ExtraData, recordProducers...)
+	if err != nil {
+		return err
+	}
+
+	return WriteBytes(w, s.ExtraData)
+}
+
+// Decode deserializes the serialized SpliceLocked stored in the passed
+// io.Reader into the target SpliceLocked using the deserialization rules
+// defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (s *SpliceLocked) Decode(r io.Reader, _ uint32) error {
+	err := ReadElements(r, &s.ChanID, s.SpliceTxID[:], &s.ExtraData)
+	if err != nil {
+		return err
+	}
+
+	localNonce := s.NextLocalNonce.Zero()
+	typeMap, err := s.ExtraData.ExtractRecords(&localNonce)
+	if err != nil {
+		return err
+	}
+
+	if val, ok := typeMap[s.NextLocalNonce.TlvType()]; ok && val == nil {
+		s.NextLocalNonce = tlv.SomeRecordT(localNonce)
+	}
+
+	// This is required to pass the fuzz test round trip equality check.
+	if len(s.ExtraData) == 0 {
+		s.ExtraData = nil
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a SpliceLocked on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (s *SpliceLocked) MsgType() MessageType {
+	return MsgSpliceLocked
+}
+
+// SerializedSize returns the serialized size of the message in bytes.
+//
+// This is part of the lnwire.SizeableMessage interface.
+func (s *SpliceLocked) SerializedSize() (uint32, error) {
+	return MessageSerializedSize(s)
+}
+
+// A compile time check to ensure SpliceLocked implements the
+// lnwire.LinkUpdater interface.
+var _ LinkUpdater = (*SpliceLocked)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (s *SpliceLocked) TargetChanID() ChannelID {
+	return s.ChanID
+}
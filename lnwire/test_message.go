@@ -13,6 +13,7 @@ import (
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/flokiorg/go-flokicoin/wire"
 	"github.com/stretchr/testify/require"
 	"pgregory.net/rapid"
 )
@@ -52,6 +53,22 @@ func (a *AcceptChannel) RandTestMessage(t *rapid.T) Message {
 		channelType = RandChannelType(t)
 	}
 
+	var contribution tlv.OptionalRecordT[tlv.TlvType65541, uint64]
+	if rapid.Bool().Draw(t, "includeFundingContribution") {
+		contribution = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType65541](
+				rapid.Uint64().Draw(t, "fundingContribution"),
+			),
+		)
+	}
+
+	var willFund tlv.OptionalRecordT[tlv.TlvType8, LeaseRates]
+	if rapid.Bool().Draw(t, "includeWillFund") {
+		willFund = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType8](RandLeaseRates(t)),
+		)
+	}
+
 	var leaseExpiry *LeaseExpiry
 	if includeLeaseExpiry {
 		leaseExpiry = RandLeaseExpiry(t)
@@ -100,6 +117,8 @@ func (a *AcceptChannel) RandTestMessage(t *rapid.T) Message {
 		ChannelType:           channelType,
 		LeaseExpiry:           leaseExpiry,
 		LocalNonce:            localNonce,
+		FundingContribution:   contribution,
+		WillFund:              willFund,
 		ExtraData:             RandExtraOpaqueData(t, nil),
 	}
 }
@@ -387,6 +406,14 @@ func (a *ChannelReestablish) RandTestMessage(t *rapid.T) Message {
 		msg.DynHeight = fn.Some(height)
 	}
 
+	if rapid.Bool().Draw(t, "includeNextFundingTxid") {
+		msg.NextFundingTxid = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType0, [32]byte](
+				RandPaymentHash(t),
+			),
+		)
+	}
+
 	return msg
 }
 
@@ -945,10 +972,22 @@ func (dp *DynPropose) RandTestMessage(t *rapid.T) Message {
 		msg.ChannelType = tlv.SomeRecordT(chanType)
 	}
 
+	if rapid.Bool().Draw(t, "includeLocalNonce") {
+		nonce := RandMusig2Nonce(t)
+		rec := tlv.NewRecordT[tlv.TlvType16](nonce)
+		msg.LocalNonce = tlv.SomeRecordT(rec)
+	}
+
+	if rapid.Bool().Draw(t, "includeKickoffFeerate") {
+		rate := msg.KickoffFeerate.Zero()
+		rate.Val = rapid.Uint32().Draw(t, "kickoffFeerate")
+		msg.KickoffFeerate = tlv.SomeRecordT(rate)
+	}
+
 	// Create a tlv type lists to hold all known records which will be
 	// ignored when creating ExtraData records.
 	ignoreRecords := fn.NewSet[uint64]()
-	for i := range uint64(13) {
+	for i := range uint64(19) {
 		// Ignore known records.
 		if i%2 == 0 {
 			ignoreRecords.Add(i)
@@ -1073,10 +1112,22 @@ func (dc *DynCommit) RandTestMessage(t *rapid.T) Message {
 		da.LocalNonce = tlv.SomeRecordT(rec)
 	}
 
+	if rapid.Bool().Draw(t, "includeProposeNonce") {
+		nonce := RandMusig2Nonce(t)
+		rec := tlv.NewRecordT[tlv.TlvType16](nonce)
+		dp.LocalNonce = tlv.SomeRecordT(rec)
+	}
+
+	if rapid.Bool().Draw(t, "includeKickoffFeerate") {
+		rate := dp.KickoffFeerate.Zero()
+		rate.Val = rapid.Uint32().Draw(t, "kickoffFeerate")
+		dp.KickoffFeerate = tlv.SomeRecordT(rate)
+	}
+
 	// Create a tlv type lists to hold all known records which will be
 	// ignored when creating ExtraData records.
 	ignoreRecords := fn.NewSet[uint64]()
-	for i := range uint64(15) {
+	for i := range uint64(19) {
 		// Ignore known records.
 		if i%2 == 0 {
 			ignoreRecords.Add(i)
@@ -1276,6 +1327,13 @@ func (a *NodeAnnouncement1) RandTestMessage(t *rapid.T) Message {
 		B: uint8(rapid.IntRange(0, 255).Draw(t, "rgbB")),
 	}
 
+	// Nodes selling liquidity advertise their lease rates.
+	extraData := RandExtraOpaqueData(t, nil)
+	if rapid.Bool().Draw(t, "includeLeaseRates") {
+		err := SetLeaseRates(&extraData, RandLeaseRates(t))
+		require.NoError(t, err)
+	}
+
 	return &NodeAnnouncement1{
 		Signature: RandSignature(t),
 		Features:  RandFeatureVector(t),
@@ -1286,7 +1344,7 @@ func (a *NodeAnnouncement1) RandTestMessage(t *rapid.T) Message {
 		RGBColor:        rgbColor,
 		Alias:           RandNodeAlias(t),
 		Addresses:       RandNetAddrs(t),
-		ExtraOpaqueData: RandExtraOpaqueData(t, nil),
+		ExtraOpaqueData: extraData,
 	}
 }
 
@@ -1461,6 +1519,32 @@ func (o *OpenChannel) RandTestMessage(t *rapid.T) Message {
 		leaseExpiry = RandLeaseExpiry(t)
 	}
 
+	var (
+		dualFundFeeRate  tlv.OptionalRecordT[tlv.TlvType65541, uint32]
+		dualFundLocktime tlv.OptionalRecordT[tlv.TlvType65543, uint32]
+		requestFunds     tlv.OptionalRecordT[tlv.TlvType10, LeaseRequest]
+	)
+	if rapid.Bool().Draw(t, "dualFunded") {
+		dualFundFeeRate = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType65541](
+				rapid.Uint32().Draw(t, "dualFundFeeRate"),
+			),
+		)
+		dualFundLocktime = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType65543](
+				rapid.Uint32().Draw(t, "dualFundLocktime"),
+			),
+		)
+	}
+	if rapid.Bool().Draw(t, "requestFunds") {
+		requestFunds = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType10](LeaseRequest{
+				Amount: rapid.Uint64().Draw(t, "leaseAmount"),
+				Rates:  RandLeaseRates(t),
+			}),
+		)
+	}
+
 	return &OpenChannel{
 		ChainHash:        hash,
 		PendingChannelID: pendingChanID,
@@ -1504,6 +1588,9 @@ func (o *OpenChannel) RandTestMessage(t *rapid.T) Message {
 		ChannelType:           channelType,
 		LeaseExpiry:           leaseExpiry,
 		LocalNonce:            localNonce,
+		DualFundFeeRate:       dualFundFeeRate,
+		DualFundLocktime:      dualFundLocktime,
+		RequestFunds:          requestFunds,
 		ExtraData:             RandExtraOpaqueData(t, nil),
 	}
 }
@@ -1811,6 +1898,80 @@ func (s *Shutdown) RandTestMessage(t *rapid.T) Message {
 	}
 }
 
+// A compile time check to ensure SpliceAck implements the lnwire.TestMessage
+// interface.
+var _ TestMessage = (*SpliceAck)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (s *SpliceAck) RandTestMessage(t *rapid.T) Message {
+	m := &SpliceAck{
+		ChanID: RandChannelID(t),
+		FundingContribution: rapid.Int64().Draw(
+			t, "fundingContribution",
+		),
+		FundingPubKey: RandPubKey(t),
+	}
+
+	extraData := RandExtraOpaqueData(t, nil)
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure SpliceInit implements the lnwire.TestMessage
+// interface.
+var _ TestMessage = (*SpliceInit)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (s *SpliceInit) RandTestMessage(t *rapid.T) Message {
+	m := &SpliceInit{
+		ChanID: RandChannelID(t),
+		FundingContribution: rapid.Int64().Draw(
+			t, "fundingContribution",
+		),
+		FeeRate:       rapid.Uint32().Draw(t, "feeRate"),
+		Locktime:      rapid.Uint32().Draw(t, "locktime"),
+		FundingPubKey: RandPubKey(t),
+	}
+
+	extraData := RandExtraOpaqueData(t, nil)
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure SpliceLocked implements the
+// lnwire.TestMessage interface.
+var _ TestMessage = (*SpliceLocked)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (s *SpliceLocked) RandTestMessage(t *rapid.T) Message {
+	m := &SpliceLocked{
+		ChanID:     RandChannelID(t),
+		SpliceTxID: RandPaymentHash(t),
+	}
+
+	if rapid.Bool().Draw(t, "includeNextLocalNonce") {
+		nonce := RandMusig2Nonce(t)
+		m.NextLocalNonce = SomeMusig2Nonce(nonce)
+	}
+
+	return m
+}
+
 // A compile time check to ensure Stfu implements the lnwire.TestMessage
 // interface.
 var _ TestMessage = (*Stfu)(nil)
@@ -1833,6 +1994,299 @@ func (s *Stfu) RandTestMessage(t *rapid.T) Message {
 	return m
 }
 
+// A compile time check to ensure PeerStorage implements the
+// lnwire.TestMessage interface.
+var _ TestMessage = (*PeerStorage)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (p *PeerStorage) RandTestMessage(t *rapid.T) Message {
+	m := &PeerStorage{
+		Blob: rapid.SliceOfN(rapid.Byte(), 1, 1000).Draw(t, "blob"),
+	}
+
+	extraData := RandExtraOpaqueData(t, nil)
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure PeerStorageRetrieval implements the
+// lnwire.TestMessage interface.
+var _ TestMessage = (*PeerStorageRetrieval)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (p *PeerStorageRetrieval) RandTestMessage(t *rapid.T) Message {
+	m := &PeerStorageRetrieval{
+		Blob: rapid.SliceOfN(rapid.Byte(), 1, 1000).Draw(t, "blob"),
+	}
+
+	extraData := RandExtraOpaqueData(t, nil)
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure TxAbort implements the lnwire.TestMessage
+// interface.
+var _ TestMessage = (*TxAbort)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (ta *TxAbort) RandTestMessage(t *rapid.T) Message {
+	m := &TxAbort{
+		ChanID: RandChannelID(t),
+	}
+
+	data := rapid.SliceOfN(rapid.Byte(), 0, 100).Draw(t, "data")
+	if len(data) > 0 {
+		m.Data = data
+	}
+
+	extraData := RandExtraOpaqueData(t, nil)
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure TxAckRbf implements the lnwire.TestMessage
+// interface.
+var _ TestMessage = (*TxAckRbf)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (ta *TxAckRbf) RandTestMessage(t *rapid.T) Message {
+	m := &TxAckRbf{
+		ChanID: RandChannelID(t),
+		FundingContribution: rapid.Int64().Draw(
+			t, "fundingContribution",
+		),
+	}
+
+	extraData := RandExtraOpaqueData(t, nil)
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure TxAddInput implements the lnwire.TestMessage
+// interface.
+var _ TestMessage = (*TxAddInput)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (ta *TxAddInput) RandTestMessage(t *rapid.T) Message {
+	m := &TxAddInput{
+		ChanID:    RandChannelID(t),
+		SerialID:  rapid.Uint64().Draw(t, "serialID"),
+		PrevTxOut: rapid.Uint32().Draw(t, "prevTxOut"),
+		Sequence:  rapid.Uint32().Draw(t, "sequence"),
+	}
+
+	// The previous transaction is only left out when spending the shared
+	// funding input.
+	if rapid.Bool().Draw(t, "sharedInput") {
+		m.SharedInputTxid = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType0, [32]byte](
+				RandPaymentHash(t),
+			),
+		)
+	} else {
+		m.PrevTx = rapid.SliceOfN(rapid.Byte(), 1, 500).Draw(
+			t, "prevTx",
+		)
+	}
+
+	extraData := RandExtraOpaqueData(t, fn.NewSet[uint64](0))
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure TxAddOutput implements the
+// lnwire.TestMessage interface.
+var _ TestMessage = (*TxAddOutput)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (ta *TxAddOutput) RandTestMessage(t *rapid.T) Message {
+	m := &TxAddOutput{
+		ChanID:   RandChannelID(t),
+		SerialID: rapid.Uint64().Draw(t, "serialID"),
+		Amount: chainutil.Amount(
+			rapid.Int64Range(0, 21e14).Draw(t, "amount"),
+		),
+		PkScript: rapid.SliceOfN(rapid.Byte(), 1, 100).Draw(
+			t, "pkScript",
+		),
+	}
+
+	extraData := RandExtraOpaqueData(t, nil)
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure TxRemoveInput implements the
+// lnwire.TestMessage interface.
+var _ TestMessage = (*TxRemoveInput)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (ti *TxRemoveInput) RandTestMessage(t *rapid.T) Message {
+	m := &TxRemoveInput{
+		ChanID:   RandChannelID(t),
+		SerialID: rapid.Uint64().Draw(t, "serialID"),
+	}
+
+	extraData := RandExtraOpaqueData(t, nil)
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure TxRemoveOutput implements the
+// lnwire.TestMessage interface.
+var _ TestMessage = (*TxRemoveOutput)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (to *TxRemoveOutput) RandTestMessage(t *rapid.T) Message {
+	m := &TxRemoveOutput{
+		ChanID:   RandChannelID(t),
+		SerialID: rapid.Uint64().Draw(t, "serialID"),
+	}
+
+	extraData := RandExtraOpaqueData(t, nil)
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure TxComplete implements the lnwire.TestMessage
+// interface.
+var _ TestMessage = (*TxComplete)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (tc *TxComplete) RandTestMessage(t *rapid.T) Message {
+	m := &TxComplete{
+		ChanID: RandChannelID(t),
+	}
+
+	extraData := RandExtraOpaqueData(t, nil)
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure TxInitRbf implements the lnwire.TestMessage
+// interface.
+var _ TestMessage = (*TxInitRbf)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (ti *TxInitRbf) RandTestMessage(t *rapid.T) Message {
+	m := &TxInitRbf{
+		ChanID:   RandChannelID(t),
+		Locktime: rapid.Uint32().Draw(t, "locktime"),
+		FeeRate:  rapid.Uint32().Draw(t, "feeRate"),
+		FundingContribution: rapid.Int64().Draw(
+			t, "fundingContribution",
+		),
+	}
+
+	extraData := RandExtraOpaqueData(t, nil)
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
+// A compile time check to ensure TxSignatures implements the
+// lnwire.TestMessage interface.
+var _ TestMessage = (*TxSignatures)(nil)
+
+// RandTestMessage populates the message with random data suitable for testing.
+// It uses the rapid testing framework to generate random values.
+//
+// This is part of the TestMessage interface.
+func (ts *TxSignatures) RandTestMessage(t *rapid.T) Message {
+	m := &TxSignatures{
+		ChanID: RandChannelID(t),
+		TxID:   RandPaymentHash(t),
+	}
+
+	numWitnesses := rapid.IntRange(0, 5).Draw(t, "numWitnesses")
+	for i := 0; i < numWitnesses; i++ {
+		numItems := rapid.IntRange(1, 4).Draw(
+			t, fmt.Sprintf("numItems-%d", i),
+		)
+
+		witness := make(wire.TxWitness, numItems)
+		for j := range witness {
+			witness[j] = rapid.SliceOfN(rapid.Byte(), 1, 80).Draw(
+				t, fmt.Sprintf("witnessItem-%d-%d", i, j),
+			)
+		}
+
+		m.Witnesses = append(m.Witnesses, witness)
+	}
+
+	if rapid.Bool().Draw(t, "includeSharedInputSig") {
+		m.SharedInputSig = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType0, Sig](RandSignature(t)),
+		)
+	}
+
+	extraData := RandExtraOpaqueData(t, fn.NewSet[uint64](0))
+	if len(extraData) > 0 {
+		m.ExtraData = extraData
+	}
+
+	return m
+}
+
 // A compile time check to ensure UpdateAddHTLC implements the
 // lnwire.TestMessage interface.
 var _ TestMessage = (*UpdateAddHTLC)(nil)
@@ -0,0 +1,146 @@
+package lnwire
+
+import (
+	"io"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/tlv"
+)
+
+const (
+	// TrampolinePolicyRecordType is the type of the record a node uses to
+	// advertise the fees it charges for relaying trampoline payments
+	// within the extra data of its node announcement.
+	TrampolinePolicyRecordType tlv.Type = 5
+
+	// trampolinePolicySize is the size of the encoded TrampolinePolicy
+	// record.
+	trampolinePolicySize = 10
+)
+
+// TrampolinePolicy is the policy a trampoline node relays payments by. The
+// fees and CLTV delta cover the whole route the trampoline node finds to the
+// next trampoline node or the final recipient.
+type TrampolinePolicy struct {
+	// FeeBaseMSat is the base fee in milli-loki charged for relaying a
+	// payment.
+	FeeBaseMSat uint32
+
+	// FeeProportionalMillionths is the fee rate, in parts per million,
+	// charged for the relayed amount.
+	FeeProportionalMillionths uint32
+
+	// CltvExpiryDelta is the minimum difference between the expiry of the
+	// incoming HTLCs and the expiry the trampoline node is asked to
+	// deliver the payment with.
+	CltvExpiryDelta uint16
+}
+
+// ComputeFee returns the fee charged for relaying amt.
+func (p *TrampolinePolicy) ComputeFee(amt MilliLoki) MilliLoki {
+	proportionalFee := amt * MilliLoki(p.FeeProportionalMillionths) /
+		1_000_000
+
+	return MilliLoki(p.FeeBaseMSat) + proportionalFee
+}
+
+// Record returns a TLV record that can be used to encode/decode the
+// trampoline policy from a given TLV stream.
+func (p *TrampolinePolicy) Record() tlv.Record {
+	return tlv.MakeStaticRecord(
+		TrampolinePolicyRecordType, p, trampolinePolicySize,
+		trampolinePolicyEncoder, trampolinePolicyDecoder,
+	)
+}
+
+// trampolinePolicyEncoder is a custom TLV encoder for the TrampolinePolicy
+// record.
+func trampolinePolicyEncoder(w io.Writer, val interface{},
+	buf *[8]byte) error {
+
+	v, ok := val.(*TrampolinePolicy)
+	if !ok {
+		return tlv.NewTypeForEncodingErr(val, "lnwire.TrampolinePolicy")
+	}
+
+	if err := tlv.EUint32T(w, v.FeeBaseMSat, buf); err != nil {
+		return err
+	}
+	err := tlv.EUint32T(w, v.FeeProportionalMillionths, buf)
+	if err != nil {
+		return err
+	}
+
+	return tlv.EUint16T(w, v.CltvExpiryDelta, buf)
+}
+
+// trampolinePolicyDecoder is a custom TLV decoder for the TrampolinePolicy
+// record.
+func trampolinePolicyDecoder(r io.Reader, val interface{}, buf *[8]byte,
+	l uint64) error {
+
+	v, ok := val.(*TrampolinePolicy)
+	if !ok || l != trampolinePolicySize {
+		return tlv.NewTypeForDecodingErr(
+			val, "lnwire.TrampolinePolicy", l, trampolinePolicySize,
+		)
+	}
+
+	if err := tlv.DUint32(r, &v.FeeBaseMSat, buf, 4); err != nil {
+		return err
+	}
+	err := tlv.DUint32(r, &v.FeeProportionalMillionths, buf, 4)
+	if err != nil {
+		return err
+	}
+
+	return tlv.DUint16(r, &v.CltvExpiryDelta, buf, 2)
+}
+
+// ExtractTrampolinePolicy returns the trampoline policy a node advertised
+// within the extra data of its node announcement, if any.
+func ExtractTrampolinePolicy(
+	extraData ExtraOpaqueData) (fn.Option[TrampolinePolicy], error) {
+
+	var policy TrampolinePolicy
+	typeMap, err := extraData.ExtractRecords(&policy)
+	if err != nil {
+		return fn.None[TrampolinePolicy](), err
+	}
+
+	val, ok := typeMap[TrampolinePolicyRecordType]
+	if !ok || val != nil {
+		return fn.None[TrampolinePolicy](), nil
+	}
+
+	return fn.Some(policy), nil
+}
+
+// SetTrampolinePolicy adds the trampoline policy to the extra data of a node
+// announcement, replacing any policy that was already present while keeping
+// all other records.
+func SetTrampolinePolicy(extraData *ExtraOpaqueData,
+	policy TrampolinePolicy) error {
+
+	typeMap, err := extraData.ExtractRecords()
+	if err != nil {
+		return err
+	}
+	delete(typeMap, TrampolinePolicyRecordType)
+
+	otherRecords, err := NewExtraOpaqueData(typeMap)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := MergeAndEncode(
+		[]tlv.RecordProducer{&policy}, otherRecords, nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	*extraData = encoded
+
+	return nil
+}
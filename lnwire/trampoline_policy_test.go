@@ -0,0 +1,58 @@
+package lnwire
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTrampolinePolicyFee asserts that the trampoline fee is made up of the
+// base fee and the proportional fee.
+func TestTrampolinePolicyFee(t *testing.T) {
+	t.Parallel()
+
+	policy := TrampolinePolicy{
+		FeeBaseMSat:               1_000,
+		FeeProportionalMillionths: 2_500,
+	}
+
+	// 1_000 base + 0.25% of 1_000_000.
+	require.EqualValues(t, 3_500, policy.ComputeFee(1_000_000))
+	require.EqualValues(t, 1_000, policy.ComputeFee(0))
+}
+
+// TestSetTrampolinePolicy asserts that a trampoline policy can be added to
+// and replaced within extra data that also holds lease rates.
+func TestSetTrampolinePolicy(t *testing.T) {
+	t.Parallel()
+
+	rates := LeaseRates{LeaseFeeBase: 1_000}
+	var extraData ExtraOpaqueData
+	require.NoError(t, SetLeaseRates(&extraData, rates))
+
+	policy, err := ExtractTrampolinePolicy(extraData)
+	require.NoError(t, err)
+	require.True(t, policy.IsNone())
+
+	setAndAssert := func(expected TrampolinePolicy) {
+		err := SetTrampolinePolicy(&extraData, expected)
+		require.NoError(t, err)
+
+		policy, err := ExtractTrampolinePolicy(extraData)
+		require.NoError(t, err)
+		require.Equal(
+			t, expected, policy.UnwrapOr(TrampolinePolicy{}),
+		)
+
+		leaseRates, err := ExtractLeaseRates(extraData)
+		require.NoError(t, err)
+		require.Equal(t, rates, leaseRates.UnwrapOr(LeaseRates{}))
+	}
+
+	setAndAssert(TrampolinePolicy{
+		FeeBaseMSat:               1_000,
+		FeeProportionalMillionths: 2_500,
+		CltvExpiryDelta:           2_400,
+	})
+	setAndAssert(TrampolinePolicy{CltvExpiryDelta: 144})
+}
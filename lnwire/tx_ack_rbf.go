@@ -0,0 +1,97 @@
+package lnwire
+
+import (
+	"bytes"
+	"io"
+)
+
+// TxAckRbf is sent in response to a TxInitRbf to accept replacing the
+// unconfirmed funding transaction. It carries the responder's contribution to
+// the funding output of the replacement transaction.
+type TxAckRbf struct {
+	// ChanID identifies the channel whose funding transaction is to be
+	// replaced.
+	ChanID ChannelID
+
+	// FundingContribution is the amount the responder adds to the funding
+	// output of the replacement transaction.
+	FundingContribution int64
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure TxAckRbf implements the lnwire.Message
+// interface.
+var _ Message = (*TxAckRbf)(nil)
+
+// A compile time check to ensure TxAckRbf implements the
+// lnwire.SizeableMessage interface.
+var _ SizeableMessage = (*TxAckRbf)(nil)
+
+// Encode serializes the target TxAckRbf into the passed io.Writer.
+// Serialization will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (t *TxAckRbf) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WriteChannelID(w, t.ChanID); err != nil {
+		return err
+	}
+
+	if err := WriteUint64(w, uint64(t.FundingContribution)); err != nil {
+		return err
+	}
+
+	return WriteBytes(w, t.ExtraData)
+}
+
+// Decode deserializes the serialized TxAckRbf stored in the passed io.Reader
+// into the target TxAckRbf using the deserialization rules defined by the
+// passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (t *TxAckRbf) Decode(r io.Reader, _ uint32) error {
+	var contribution uint64
+	err := ReadElements(r, &t.ChanID, &contribution, &t.ExtraData)
+	if err != nil {
+		return err
+	}
+
+	t.FundingContribution = int64(contribution)
+
+	// This is required to pass the fuzz test round trip equality check.
+	if len(t.ExtraData) == 0 {
+		t.ExtraData = nil
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a TxAckRbf on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (t *TxAckRbf) MsgType() MessageType {
+	return MsgTxAckRbf
+}
+
+// SerializedSize returns the serialized size of the message in bytes.
+//
+// This is part of the lnwire.SizeableMessage interface.
+func (t *TxAckRbf) SerializedSize() (uint32, error) {
+	return MessageSerializedSize(t)
+}
+
+// A compile time check to ensure TxAckRbf implements the lnwire.LinkUpdater
+// interface.
+var _ LinkUpdater = (*TxAckRbf)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (t *TxAckRbf) TargetChanID() ChannelID {
+	return t.ChanID
+}
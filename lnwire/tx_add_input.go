@@ -0,0 +1,163 @@
+package lnwire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/flokiorg/flnd/tlv"
+)
+
+// TxData is a length prefixed blob of transaction data that is exchanged
+// during interactive transaction construction. Depending on the message it
+// holds a serialized transaction, an output script or a serialized witness.
+type TxData []byte
+
+// TxAddInput is sent during interactive transaction construction to add a
+// new input to the transaction being negotiated.
+type TxAddInput struct {
+	// ChanID identifies the channel the transaction is being constructed
+	// for.
+	ChanID ChannelID
+
+	// SerialID is a unique identifier for this input within the
+	// negotiation. The initiator MUST use even serial IDs, while the
+	// non-initiator uses odd ones.
+	SerialID uint64
+
+	// PrevTx is the serialized transaction that contains the output being
+	// spent. This is left blank if the input is the shared funding input
+	// of a splice.
+	PrevTx TxData
+
+	// PrevTxOut is the index of the output within PrevTx being spent.
+	PrevTxOut uint32
+
+	// Sequence is the sequence number to use for this input.
+	Sequence uint32
+
+	// SharedInputTxid is set when the input being added is the current
+	// funding output of the channel. In this case PrevTx is empty, as both
+	// sides already know the transaction.
+	SharedInputTxid tlv.OptionalRecordT[tlv.TlvType0, [32]byte]
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure TxAddInput implements the lnwire.Message
+// interface.
+var _ Message = (*TxAddInput)(nil)
+
+// A compile time check to ensure TxAddInput implements the
+// lnwire.SizeableMessage interface.
+var _ SizeableMessage = (*TxAddInput)(nil)
+
+// Encode serializes the target TxAddInput into the passed io.Writer.
+// Serialization will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (t *TxAddInput) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WriteChannelID(w, t.ChanID); err != nil {
+		return err
+	}
+
+	if err := WriteUint64(w, t.SerialID); err != nil {
+		return err
+	}
+
+	if err := WriteTxData(w, t.PrevTx); err != nil {
+		return err
+	}
+
+	if err := WriteUint32(w, t.PrevTxOut); err != nil {
+		return err
+	}
+
+	if err := WriteUint32(w, t.Sequence); err != nil {
+		return err
+	}
+
+	recordProducers := make([]tlv.RecordProducer, 0, 1)
+	t.SharedInputTxid.WhenSome(
+		func(txid tlv.RecordT[tlv.TlvType0, [32]byte]) {
+			recordProducers = append(recordProducers, &txid)
+		},
+	)
+
+	err := EncodeMessageExtraData(&t.ExtraData, recordProducers...)
+	if err != nil {
+		return err
+	}
+
+	return WriteBytes(w, t.ExtraData)
+}
+
+// Decode deserializes the serialized TxAddInput stored in the passed
+// io.Reader into the target TxAddInput using the deserialization rules
+// defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (t *TxAddInput) Decode(r io.Reader, _ uint32) error {
+	err := ReadElements(
+		r, &t.ChanID, &t.SerialID, &t.PrevTx, &t.PrevTxOut,
+		&t.Sequence,
+	)
+	if err != nil {
+		return err
+	}
+
+	var tlvRecords ExtraOpaqueData
+	if err := ReadElements(r, &tlvRecords); err != nil {
+		return err
+	}
+
+	sharedTxid := t.SharedInputTxid.Zero()
+	typeMap, err := tlvRecords.ExtractRecords(&sharedTxid)
+	if err != nil {
+		return err
+	}
+
+	val, ok := typeMap[t.SharedInputTxid.TlvType()]
+	if ok && val == nil {
+		t.SharedInputTxid = tlv.SomeRecordT(sharedTxid)
+	}
+
+	// This is required to pass the fuzz test round trip equality check.
+	if len(t.PrevTx) == 0 {
+		t.PrevTx = nil
+	}
+	if len(tlvRecords) != 0 {
+		t.ExtraData = tlvRecords
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a TxAddInput on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (t *TxAddInput) MsgType() MessageType {
+	return MsgTxAddInput
+}
+
+// SerializedSize returns the serialized size of the message in bytes.
+//
+// This is part of the lnwire.SizeableMessage interface.
+func (t *TxAddInput) SerializedSize() (uint32, error) {
+	return MessageSerializedSize(t)
+}
+
+// A compile time check to ensure TxAddInput implements the lnwire.LinkUpdater
+// interface.
+var _ LinkUpdater = (*TxAddInput)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (t *TxAddInput) TargetChanID() ChannelID {
+	return t.ChanID
+}
@@ -0,0 +1,115 @@
+package lnwire
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/flokiorg/go-flokicoin/chainutil"
+)
+
+// TxAddOutput is sent during interactive transaction construction to add a
+// new output to the transaction being negotiated.
+type TxAddOutput struct {
+	// ChanID identifies the channel the transaction is being constructed
+	// for.
+	ChanID ChannelID
+
+	// SerialID is a unique identifier for this output within the
+	// negotiation. The initiator MUST use even serial IDs, while the
+	// non-initiator uses odd ones.
+	SerialID uint64
+
+	// Amount is the value of the output.
+	Amount chainutil.Amount
+
+	// PkScript is the output script of the output.
+	PkScript TxData
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure TxAddOutput implements the lnwire.Message
+// interface.
+var _ Message = (*TxAddOutput)(nil)
+
+// A compile time check to ensure TxAddOutput implements the
+// lnwire.SizeableMessage interface.
+var _ SizeableMessage = (*TxAddOutput)(nil)
+
+// Encode serializes the target TxAddOutput into the passed io.Writer.
+// Serialization will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (t *TxAddOutput) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WriteChannelID(w, t.ChanID); err != nil {
+		return err
+	}
+
+	if err := WriteUint64(w, t.SerialID); err != nil {
+		return err
+	}
+
+	if err := WriteLoki(w, t.Amount); err != nil {
+		return err
+	}
+
+	if err := WriteTxData(w, t.PkScript); err != nil {
+		return err
+	}
+
+	return WriteBytes(w, t.ExtraData)
+}
+
+// Decode deserializes the serialized TxAddOutput stored in the passed
+// io.Reader into the target TxAddOutput using the deserialization rules
+// defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (t *TxAddOutput) Decode(r io.Reader, _ uint32) error {
+	err := ReadElements(
+		r, &t.ChanID, &t.SerialID, &t.Amount, &t.PkScript, &t.ExtraData,
+	)
+	if err != nil {
+		return err
+	}
+
+	// This is required to pass the fuzz test round trip equality check.
+	if len(t.PkScript) == 0 {
+		t.PkScript = nil
+	}
+	if len(t.ExtraData) == 0 {
+		t.ExtraData = nil
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a TxAddOutput on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (t *TxAddOutput) MsgType() MessageType {
+	return MsgTxAddOutput
+}
+
+// SerializedSize returns the serialized size of the message in bytes.
+//
+// This is part of the lnwire.SizeableMessage interface.
+func (t *TxAddOutput) SerializedSize() (uint32, error) {
+	return MessageSerializedSize(t)
+}
+
+// A compile time check to ensure TxAddOutput implements the
+// lnwire.LinkUpdater interface.
+var _ LinkUpdater = (*TxAddOutput)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (t *TxAddOutput) TargetChanID() ChannelID {
+	return t.ChanID
+}
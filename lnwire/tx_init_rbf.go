@@ -0,0 +1,115 @@
+package lnwire
+
+import (
+	"bytes"
+	"io"
+)
+
+// TxInitRbf is sent by the initiator of a dual-funded channel open to start
+// replacing the unconfirmed funding transaction with one paying a higher fee
+// rate. It kicks off a new round of interactive transaction construction.
+type TxInitRbf struct {
+	// ChanID identifies the channel whose funding transaction is to be
+	// replaced.
+	ChanID ChannelID
+
+	// Locktime is the locktime to use for the replacement transaction.
+	Locktime uint32
+
+	// FeeRate is the fee rate in loki per kw the replacement transaction
+	// should pay. It must be at least 25/24 of the previous fee rate.
+	FeeRate uint32
+
+	// FundingContribution is the amount the initiator adds to the funding
+	// output of the replacement transaction.
+	FundingContribution int64
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure TxInitRbf implements the lnwire.Message
+// interface.
+var _ Message = (*TxInitRbf)(nil)
+
+// A compile time check to ensure TxInitRbf implements the
+// lnwire.SizeableMessage interface.
+var _ SizeableMessage = (*TxInitRbf)(nil)
+
+// Encode serializes the target TxInitRbf into the passed io.Writer.
+// Serialization will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (t *TxInitRbf) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WriteChannelID(w, t.ChanID); err != nil {
+		return err
+	}
+
+	if err := WriteUint32(w, t.Locktime); err != nil {
+		return err
+	}
+
+	if err := WriteUint32(w, t.FeeRate); err != nil {
+		return err
+	}
+
+	if err := WriteUint64(w, uint64(t.FundingContribution)); err != nil {
+		return err
+	}
+
+	return WriteBytes(w, t.ExtraData)
+}
+
+// Decode deserializes the serialized TxInitRbf stored in the passed io.Reader
+// into the target TxInitRbf using the deserialization rules defined by the
+// passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (t *TxInitRbf) Decode(r io.Reader, _ uint32) error {
+	var contribution uint64
+	err := ReadElements(
+		r, &t.ChanID, &t.Locktime, &t.FeeRate, &contribution,
+		&t.ExtraData,
+	)
+	if err != nil {
+		return err
+	}
+
+	t.FundingContribution = int64(contribution)
+
+	// This is required to pass the fuzz test round trip equality check.
+	if len(t.ExtraData) == 0 {
+		t.ExtraData = nil
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a TxInitRbf on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (t *TxInitRbf) MsgType() MessageType {
+	return MsgTxInitRbf
+}
+
+// SerializedSize returns the serialized size of the message in bytes.
+//
+// This is part of the lnwire.SizeableMessage interface.
+func (t *TxInitRbf) SerializedSize() (uint32, error) {
+	return MessageSerializedSize(t)
+}
+
+// A compile time check to ensure TxInitRbf implements the lnwire.LinkUpdater
+// interface.
+var _ LinkUpdater = (*TxInitRbf)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (t *TxInitRbf) TargetChanID() ChannelID {
+	return t.ChanID
+}
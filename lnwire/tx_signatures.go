@@ -0,0 +1,220 @@
+package lnwire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+// maxWitnessItemSize is the largest witness element we'll accept when
+// decoding a TxSignatures message.
+const maxWitnessItemSize = MaxMsgBody
+
+// TxSignatures is sent once interactive transaction construction has
+// completed and the commitment transactions spending the new funding output
+// have been signed. It carries the witnesses for all the inputs the sender
+// contributed to the transaction.
+type TxSignatures struct {
+	// ChanID identifies the channel the transaction was constructed for.
+	ChanID ChannelID
+
+	// TxID is the txid of the negotiated transaction.
+	TxID [32]byte
+
+	// Witnesses holds one witness for each input contributed by the
+	// sender, ordered by the serial ID of the inputs.
+	Witnesses []wire.TxWitness
+
+	// SharedInputSig is the sender's signature for the shared funding
+	// input of a splice transaction.
+	SharedInputSig tlv.OptionalRecordT[tlv.TlvType0, Sig]
+
+	// ExtraData is the set of data that was appended to this message to
+	// fill out the full maximum transport message size. These fields can
+	// be used to specify optional data such as custom TLV fields.
+	ExtraData ExtraOpaqueData
+}
+
+// A compile time check to ensure TxSignatures implements the lnwire.Message
+// interface.
+var _ Message = (*TxSignatures)(nil)
+
+// A compile time check to ensure TxSignatures implements the
+// lnwire.SizeableMessage interface.
+var _ SizeableMessage = (*TxSignatures)(nil)
+
+// encodeWitness serializes the witness stack using the same format as the
+// witness section of a transaction.
+func encodeWitness(witness wire.TxWitness) (TxData, error) {
+	var b bytes.Buffer
+	if err := wire.WriteVarInt(&b, 0, uint64(len(witness))); err != nil {
+		return nil, err
+	}
+
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(&b, 0, item); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Bytes(), nil
+}
+
+// decodeWitness parses a witness stack serialized with encodeWitness.
+func decodeWitness(data TxData) (wire.TxWitness, error) {
+	r := bytes.NewReader(data)
+
+	numItems, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each item takes up at least one byte, so we can bound the number of
+	// items by the size of the data.
+	if numItems > uint64(len(data)) {
+		return nil, fmt.Errorf("too many witness items: %v", numItems)
+	}
+
+	witness := make(wire.TxWitness, 0, numItems)
+	for i := uint64(0); i < numItems; i++ {
+		item, err := wire.ReadVarBytes(
+			r, 0, maxWitnessItemSize, "witness item",
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		witness = append(witness, item)
+	}
+
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%d trailing witness bytes", r.Len())
+	}
+
+	return witness, nil
+}
+
+// Encode serializes the target TxSignatures into the passed io.Writer.
+// Serialization will observe the rules defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (t *TxSignatures) Encode(w *bytes.Buffer, _ uint32) error {
+	if err := WriteChannelID(w, t.ChanID); err != nil {
+		return err
+	}
+
+	if err := WriteBytes(w, t.TxID[:]); err != nil {
+		return err
+	}
+
+	if err := WriteUint16(w, uint16(len(t.Witnesses))); err != nil {
+		return err
+	}
+
+	for _, witness := range t.Witnesses {
+		witnessData, err := encodeWitness(witness)
+		if err != nil {
+			return err
+		}
+
+		if err := WriteTxData(w, witnessData); err != nil {
+			return err
+		}
+	}
+
+	recordProducers := make([]tlv.RecordProducer, 0, 1)
+	t.SharedInputSig.WhenSome(func(sig tlv.RecordT[tlv.TlvType0, Sig]) {
+		recordProducers = append(recordProducers, &sig)
+	})
+
+	err := EncodeMessageExtraData(&t.ExtraData, recordProducers...)
+	if err != nil {
+		return err
+	}
+
+	return WriteBytes(w, t.ExtraData)
+}
+
+// Decode deserializes the serialized TxSignatures stored in the passed
+// io.Reader into the target TxSignatures using the deserialization rules
+// defined by the passed protocol version.
+//
+// This is a part of the lnwire.Message interface.
+func (t *TxSignatures) Decode(r io.Reader, _ uint32) error {
+	if err := ReadElements(r, &t.ChanID, t.TxID[:]); err != nil {
+		return err
+	}
+
+	var numWitnesses [2]byte
+	if _, err := io.ReadFull(r, numWitnesses[:]); err != nil {
+		return err
+	}
+
+	t.Witnesses = nil
+	for i := 0; i < int(binary.BigEndian.Uint16(numWitnesses[:])); i++ {
+		var witnessData TxData
+		if err := ReadElement(r, &witnessData); err != nil {
+			return err
+		}
+
+		witness, err := decodeWitness(witnessData)
+		if err != nil {
+			return err
+		}
+
+		t.Witnesses = append(t.Witnesses, witness)
+	}
+
+	var tlvRecords ExtraOpaqueData
+	if err := ReadElements(r, &tlvRecords); err != nil {
+		return err
+	}
+
+	sharedSig := t.SharedInputSig.Zero()
+	typeMap, err := tlvRecords.ExtractRecords(&sharedSig)
+	if err != nil {
+		return err
+	}
+
+	val, ok := typeMap[t.SharedInputSig.TlvType()]
+	if ok && val == nil {
+		t.SharedInputSig = tlv.SomeRecordT(sharedSig)
+	}
+
+	if len(tlvRecords) != 0 {
+		t.ExtraData = tlvRecords
+	}
+
+	return nil
+}
+
+// MsgType returns the MessageType code which uniquely identifies this message
+// as a TxSignatures on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (t *TxSignatures) MsgType() MessageType {
+	return MsgTxSignatures
+}
+
+// SerializedSize returns the serialized size of the message in bytes.
+//
+// This is part of the lnwire.SizeableMessage interface.
+func (t *TxSignatures) SerializedSize() (uint32, error) {
+	return MessageSerializedSize(t)
+}
+
+// A compile time check to ensure TxSignatures implements the
+// lnwire.LinkUpdater interface.
+var _ LinkUpdater = (*TxSignatures)(nil)
+
+// TargetChanID returns the channel id of the link for which this message is
+// intended.
+//
+// NOTE: Part of peer.LinkUpdater interface.
+func (t *TxSignatures) TargetChanID() ChannelID {
+	return t.ChanID
+}
@@ -254,6 +254,16 @@ func WriteErrorData(buf *bytes.Buffer, data ErrorData) error {
 	return writeDataWithLength(buf, data)
 }
 
+// WriteTxData appends the transaction data to the provided buffer.
+func WriteTxData(buf *bytes.Buffer, data TxData) error {
+	return writeDataWithLength(buf, data)
+}
+
+// WritePeerStorageBlob appends the peer storage blob to the provided buffer.
+func WritePeerStorageBlob(buf *bytes.Buffer, blob PeerStorageBlob) error {
+	return writeDataWithLength(buf, blob)
+}
+
 // WriteOpaqueReason appends the reason to the provided buffer.
 func WriteOpaqueReason(buf *bytes.Buffer, reason OpaqueReason) error {
 	return writeDataWithLength(buf, reason)
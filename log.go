@@ -28,6 +28,7 @@ import (
 	"github.com/flokiorg/flnd/lnrpc/devrpc"
 	"github.com/flokiorg/flnd/lnrpc/invoicesrpc"
 	"github.com/flokiorg/flnd/lnrpc/neutrinorpc"
+	"github.com/flokiorg/flnd/lnrpc/offersrpc"
 	"github.com/flokiorg/flnd/lnrpc/peersrpc"
 	"github.com/flokiorg/flnd/lnrpc/routerrpc"
 	"github.com/flokiorg/flnd/lnrpc/signrpc"
@@ -38,9 +39,12 @@ import (
 	"github.com/flokiorg/flnd/lnwallet/chancloser"
 	"github.com/flokiorg/flnd/lnwallet/chanfunding"
 	"github.com/flokiorg/flnd/lnwallet/rpcwallet"
+	"github.com/flokiorg/flnd/lnwallet/txbatcher"
+	"github.com/flokiorg/flnd/migratedb"
 	"github.com/flokiorg/flnd/monitoring"
 	"github.com/flokiorg/flnd/msgmux"
 	"github.com/flokiorg/flnd/netann"
+	"github.com/flokiorg/flnd/offers"
 	"github.com/flokiorg/flnd/onionmessage"
 	paymentsdb "github.com/flokiorg/flnd/payments/db"
 	"github.com/flokiorg/flnd/peer"
@@ -49,6 +53,8 @@ import (
 	"github.com/flokiorg/flnd/routing"
 	"github.com/flokiorg/flnd/routing/blindedpath"
 	"github.com/flokiorg/flnd/routing/localchans"
+	"github.com/flokiorg/flnd/routing/probe"
+	"github.com/flokiorg/flnd/routing/rebalance"
 	"github.com/flokiorg/flnd/rpcperms"
 	"github.com/flokiorg/flnd/signal"
 	"github.com/flokiorg/flnd/sqldb"
@@ -165,6 +171,7 @@ func SetupLoggers(root *build.SubLoggerManager, interceptor signal.Interceptor)
 	AddSubLogger(root, "BRAR", interceptor, contractcourt.UseBreachLogger)
 	// AddV1SubLogger(root, "SPHX", interceptor, sphinx.UseLogger) // #FLZ_CHANGE
 	AddSubLogger(root, "SWPR", interceptor, sweep.UseLogger)
+	AddSubLogger(root, txbatcher.Subsystem, interceptor, txbatcher.UseLogger)
 	AddSubLogger(root, "SGNR", interceptor, signrpc.UseLogger)
 	AddSubLogger(root, "WLKT", interceptor, walletrpc.UseLogger)
 	AddSubLogger(root, "ARPC", interceptor, autopilotrpc.UseLogger)
@@ -201,6 +208,7 @@ func SetupLoggers(root *build.SubLoggerManager, interceptor signal.Interceptor)
 	AddSubLogger(root, btcwallet.Subsystem, interceptor, btcwallet.UseLogger)
 	AddSubLogger(root, rpcwallet.Subsystem, interceptor, rpcwallet.UseLogger)
 	AddSubLogger(root, peersrpc.Subsystem, interceptor, peersrpc.UseLogger)
+	AddSubLogger(root, offersrpc.Subsystem, interceptor, offersrpc.UseLogger)
 	AddSubLogger(root, graph.Subsystem, interceptor, graph.UseLogger)
 	AddSubLogger(root, lncfg.Subsystem, interceptor, lncfg.UseLogger)
 	AddSubLogger(
@@ -213,8 +221,14 @@ func SetupLoggers(root *build.SubLoggerManager, interceptor signal.Interceptor)
 	AddSubLogger(
 		root, paymentsdb.Subsystem, interceptor, paymentsdb.UseLogger,
 	)
+	AddSubLogger(
+		root, migratedb.Subsystem, interceptor, migratedb.UseLogger,
+	)
 
 	AddSubLogger(root, onionmessage.Subsystem, interceptor, onionmessage.UseLogger)
+	AddSubLogger(root, offers.Subsystem, interceptor, offers.UseLogger)
+	AddSubLogger(root, rebalance.Subsystem, interceptor, rebalance.UseLogger)
+	AddSubLogger(root, probe.Subsystem, interceptor, probe.UseLogger)
 }
 
 // AddSubLogger is a helper method to conveniently create and register the
@@ -0,0 +1,220 @@
+package flnd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lncfg"
+	"github.com/flokiorg/flnd/lnrpc"
+	"github.com/flokiorg/flnd/migratedb"
+)
+
+// kvMigration is a KV database that is copied from the migration source to
+// the destination.
+type kvMigration struct {
+	name string
+	src  kvdb.Backend
+	dst  kvdb.Backend
+}
+
+// MigrateDB migrates all data of the node from the KV database configured in
+// the migratedb.source section into the native SQL database configured in the
+// db section. The migration runs in three steps, each of which can be resumed
+// by running the migration again if it is interrupted:
+//
+//  1. All KV databases are copied to the KV tables of the destination, unless
+//     the source already is the destination.
+//  2. Every copied record is verified against the original one.
+//  3. All stores that have a native SQL implementation are migrated from the
+//     KV tables of the destination to their native SQL schema. These are the
+//     same migrations that run on startup with db.use-native-sql set, which
+//     compare every migrated record against the original one.
+//
+// The neutrino database is not migrated, as its data is synced again from the
+// network. The source database and the configuration of the node are never
+// modified. Once the migration has completed, the node can be started with the
+// destination database and db.use-native-sql set.
+//
+// NOTE: The node must not be running while the migration is in progress.
+func MigrateDB(cfg *Config) error {
+	defer func() {
+		err := cfg.LogRotator.Close()
+		if err != nil {
+			ltndLog.Errorf("Could not close log rotator: %v", err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := cfg.MigrateDB.Validate(cfg.DB); err != nil {
+		return err
+	}
+
+	// Run configuration dependent DB pre-initialization for both the
+	// source and the destination.
+	err := cfg.MigrateDB.Source.Init(ctx, cfg.graphDatabaseDir())
+	if err != nil {
+		return fmt.Errorf("error initializing source DB: %w", err)
+	}
+	if err := cfg.DB.Init(ctx, cfg.graphDatabaseDir()); err != nil {
+		return fmt.Errorf("error initializing destination DB: %w", err)
+	}
+
+	ltndLog.Infof("Migrating node data from %v database to native SQL "+
+		"in %v database", cfg.MigrateDB.Source.Backend, cfg.DB.Backend)
+
+	if cfg.MigrateDB.SameKVDatabase(cfg.DB) {
+		ltndLog.Infof("Step 1/3 and 2/3: KV data already in %v "+
+			"database, skipping copy and verification",
+			cfg.DB.Backend)
+	} else if err := copyKVDatabases(ctx, cfg); err != nil {
+		return err
+	}
+
+	// With the KV data in place, we can run the native SQL migrations on
+	// the destination by opening its databases just like the daemon does
+	// on startup with native SQL enabled.
+	ltndLog.Infof("Step 3/3: migrating KV data to native SQL")
+
+	nativeDB := *cfg.DB
+	nativeDB.UseNativeSQL = true
+	nativeDB.SkipNativeSQLMigration = false
+
+	nativeCfg := *cfg
+	nativeCfg.DB = &nativeDB
+
+	dbBuilder := NewDefaultDatabaseBuilder(&nativeCfg, ltndLog)
+	_, cleanUp, err := dbBuilder.BuildDatabase(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to migrate to native SQL: %w", err)
+	}
+	cleanUp()
+
+	ltndLog.Infof("Migration complete! Restart the node with "+
+		"db.backend=%v and db.use-native-sql=true", cfg.DB.Backend)
+
+	return nil
+}
+
+// copyKVDatabases copies all KV databases of the migration source to the
+// destination and verifies the copies record by record.
+func copyKVDatabases(ctx context.Context, cfg *Config) error {
+	towerDir := filepath.Join(
+		cfg.Watchtower.TowerDir, FlokicoinChainName,
+		lncfg.NormalizeNetwork(cfg.ActiveNetParams.Name),
+	)
+
+	// The watchtower databases are copied whenever they exist, no matter
+	// whether the client or the tower is currently enabled, so that their
+	// data isn't lost if they are enabled again later. Only a bbolt
+	// database needs to be checked, as we'd otherwise create an empty file
+	// in the source directory. All other backends keep the watchtower data
+	// in the same database as the rest of the node.
+	towerClient, towerServer := true, true
+	if cfg.MigrateDB.Source.Backend == lncfg.BoltBackend {
+		towerClient = lnrpc.FileExists(filepath.Join(
+			cfg.graphDatabaseDir(), lncfg.TowerClientDBName,
+		))
+		towerServer = lnrpc.FileExists(filepath.Join(
+			towerDir, lncfg.TowerServerDBName,
+		))
+	}
+
+	getBackends := func(db *lncfg.DB) (*lncfg.DatabaseBackends, error) {
+		// Only the KV databases are needed to copy the data.
+		kvDB := *db
+		kvDB.UseNativeSQL = false
+
+		return kvDB.GetBackends(
+			ctx, cfg.graphDatabaseDir(), cfg.networkDir, towerDir,
+			towerClient, towerServer, ltndLog,
+		)
+	}
+
+	src, err := getBackends(cfg.MigrateDB.Source)
+	if err != nil {
+		return fmt.Errorf("unable to open source databases: %w", err)
+	}
+	defer closeDatabaseBackends(src)
+
+	dst, err := getBackends(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("unable to open destination databases: %w",
+			err)
+	}
+	defer closeDatabaseBackends(dst)
+
+	// The graph and the height hints always live in the same database as
+	// the channel state.
+	migrations := []kvMigration{
+		{lncfg.NSChannelDB, src.ChanStateDB, dst.ChanStateDB},
+		{lncfg.NSMacaroonDB, src.MacaroonDB, dst.MacaroonDB},
+		{lncfg.NSDecayedLogDB, src.DecayedLogDB, dst.DecayedLogDB},
+		{lncfg.NSTowerClientDB, src.TowerClientDB, dst.TowerClientDB},
+		{lncfg.NSTowerServerDB, src.TowerServerDB, dst.TowerServerDB},
+	}
+
+	// A local bbolt wallet is opened by the wallet loader, so we need to
+	// open it ourselves.
+	srcWallet := src.WalletBackend
+	walletPath := filepath.Join(cfg.networkDir, lncfg.WalletDBName)
+	if srcWallet == nil && lnrpc.FileExists(walletPath) {
+		boltCfg := cfg.MigrateDB.Source.Bolt
+		srcWallet, err = kvdb.GetBoltBackend(&kvdb.BoltBackendConfig{
+			DBPath:         cfg.networkDir,
+			DBFileName:     lncfg.WalletDBName,
+			DBTimeout:      boltCfg.DBTimeout,
+			NoFreelistSync: boltCfg.NoFreelistSync,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to open source wallet DB: %w",
+				err)
+		}
+		defer srcWallet.Close()
+	}
+	if srcWallet != nil {
+		migrations = append(migrations, kvMigration{
+			lncfg.NSWalletDB, srcWallet, dst.WalletBackend,
+		})
+	}
+
+	ltndLog.Infof("Step 1/3: copying KV data")
+	for _, m := range migrations {
+		// The watchtower databases are only opened if they exist.
+		if m.src == nil || m.dst == nil {
+			continue
+		}
+
+		err := migratedb.CopyBackend(m.name, m.src, m.dst)
+		if err != nil {
+			return err
+		}
+	}
+
+	ltndLog.Infof("Step 2/3: verifying KV data")
+	for _, m := range migrations {
+		if m.src == nil || m.dst == nil {
+			continue
+		}
+
+		err := migratedb.VerifyBackend(m.name, m.src, m.dst)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeDatabaseBackends closes all databases of the given backends.
+func closeDatabaseBackends(backends *lncfg.DatabaseBackends) {
+	for name, closeFunc := range backends.CloseFuncs {
+		if err := closeFunc(); err != nil {
+			ltndLog.Errorf("Error closing %s database: %v", name,
+				err)
+		}
+	}
+}
@@ -0,0 +1,527 @@
+package migratedb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flokiorg/flnd/kvdb"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultCopyChunkSize is the maximum number of keys that are copied
+	// in a single transaction of the destination database.
+	DefaultCopyChunkSize = 10_000
+
+	// metaStateCopying is the first byte of the meta entry of a top-level
+	// bucket that is only partially copied. It is followed by the cursor
+	// that points to the last copied key.
+	metaStateCopying byte = 0
+
+	// metaStateCopied is the meta entry of a top-level bucket that has
+	// been copied completely.
+	metaStateCopied byte = 1
+)
+
+var (
+	// metaBucket is the top-level bucket in the destination database that
+	// keeps track of the progress of the migration. The copy state of each
+	// top-level bucket is recorded under the key <name>/<bucket> and each
+	// verified database under the key <name>.
+	metaBucket = []byte("migratedb-meta")
+
+	// ErrBucketExists is returned if a top-level bucket that is about to be
+	// copied already exists in the destination database without having
+	// been copied there by a previous run.
+	ErrBucketExists = errors.New("bucket already exists in destination " +
+		"database")
+)
+
+// progressLogger logs the number of copied or verified keys of a database at
+// most every 30 seconds.
+type progressLogger struct {
+	action string
+	name   string
+
+	s     rate.Sometimes
+	t0    time.Time
+	total int
+	chunk int
+}
+
+// newProgressLogger creates a new progress logger for the given action and
+// database name.
+func newProgressLogger(action, name string) *progressLogger {
+	return &progressLogger{
+		action: action,
+		name:   name,
+		s: rate.Sometimes{
+			Interval: 30 * time.Second,
+		},
+		t0: time.Now(),
+	}
+}
+
+// inc records that another key has been processed.
+func (p *progressLogger) inc() {
+	p.total++
+	p.chunk++
+
+	p.s.Do(func() {
+		elapsed := time.Since(p.t0).Seconds()
+		ratePerSec := float64(p.chunk) / elapsed
+		log.Infof("%s %d keys of %s database (%.2f keys/sec)",
+			p.action, p.total, p.name, ratePerSec)
+
+		p.t0 = time.Now()
+		p.chunk = 0
+	})
+}
+
+// metaKey returns the key under which the given top-level bucket of the named
+// database is recorded in the meta bucket.
+func metaKey(name string, bucket []byte) []byte {
+	key := make([]byte, 0, len(name)+1+len(bucket))
+	key = append(key, name...)
+	key = append(key, '/')
+
+	return append(key, bucket...)
+}
+
+// isVerified returns true if the named database has already been verified.
+func isVerified(name string, dst kvdb.Backend) (bool, error) {
+	var verified bool
+	err := kvdb.View(dst, func(tx kvdb.RTx) error {
+		meta := tx.ReadBucket(metaBucket)
+		verified = meta != nil && meta.Get([]byte(name)) != nil
+
+		return nil
+	}, func() {
+		verified = false
+	})
+
+	return verified, err
+}
+
+// topLevelBuckets returns the keys of all top-level buckets of the given
+// backend.
+func topLevelBuckets(db kvdb.Backend) ([][]byte, error) {
+	var buckets [][]byte
+	err := kvdb.View(db, func(tx kvdb.RTx) error {
+		return tx.ForEachBucket(func(key []byte) error {
+			buckets = append(buckets, bytes.Clone(key))
+
+			return nil
+		})
+	}, func() {
+		buckets = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buckets, nil
+}
+
+// CopyBackend copies all top-level buckets of the src backend, including their
+// nested buckets and sequence numbers, into the dst backend. The keys are
+// copied in chunks of at most DefaultCopyChunkSize keys, each of which is
+// written in its own transaction together with a cursor in a meta bucket of
+// the destination that points to the last copied key. An interrupted copy is
+// resumed by calling CopyBackend again, which continues right after the
+// recorded cursor and skips all buckets that have already been copied.
+//
+// NOTE: The name must be unique among all databases that are copied into the
+// same destination backend.
+func CopyBackend(name string, src, dst kvdb.Backend) error {
+	return copyBackend(name, src, dst, DefaultCopyChunkSize)
+}
+
+// copyBackend copies the src backend into the dst backend, committing at most
+// chunkSize keys per transaction.
+func copyBackend(name string, src, dst kvdb.Backend, chunkSize int) error {
+	buckets, err := topLevelBuckets(src)
+	if err != nil {
+		return fmt.Errorf("unable to list buckets of %s: %w", name,
+			err)
+	}
+
+	log.Infof("Copying %d top-level buckets of %s database", len(buckets),
+		name)
+
+	progress := newProgressLogger("Copied", name)
+	for _, bucket := range buckets {
+		for {
+			done, err := copyBucketChunk(
+				name, bucket, src, dst, chunkSize, progress,
+			)
+			if err != nil {
+				return fmt.Errorf("unable to copy bucket %q of "+
+					"%s: %w", bucket, name, err)
+			}
+
+			if done {
+				break
+			}
+		}
+	}
+
+	log.Infof("Copied %d keys of %s database", progress.total, name)
+
+	return nil
+}
+
+// copyBucketChunk copies the next chunk of at most chunkSize keys of the given
+// top-level bucket in a single transaction of the dst backend. It returns true
+// once the bucket has been copied completely.
+func copyBucketChunk(name string, bucket []byte, src, dst kvdb.Backend,
+	chunkSize int, progress *progressLogger) (bool, error) {
+
+	var done bool
+	err := kvdb.Update(dst, func(dstTx kvdb.RwTx) error {
+		meta, err := dstTx.CreateTopLevelBucket(metaBucket)
+		if err != nil {
+			return err
+		}
+
+		key := metaKey(name, bucket)
+		state := meta.Get(key)
+
+		var resume [][]byte
+		switch {
+		case len(state) > 0 && state[0] == metaStateCopied:
+			log.Debugf("Skipping bucket %q of %s database, already "+
+				"copied", bucket, name)
+
+			done = true
+
+			return nil
+
+		// A previous run was interrupted while copying this bucket, so
+		// we continue after the last key it copied.
+		case len(state) > 0 && state[0] == metaStateCopying:
+			resume, err = decodeCursor(state[1:])
+			if err != nil {
+				return err
+			}
+
+		case dstTx.ReadWriteBucket(bucket) != nil:
+			return fmt.Errorf("%w: %q", ErrBucketExists, bucket)
+		}
+
+		dstBucket, err := dstTx.CreateTopLevelBucket(bucket)
+		if err != nil {
+			return err
+		}
+
+		c := &chunkCopier{
+			remaining: chunkSize,
+			progress:  progress,
+		}
+		err = kvdb.View(src, func(srcTx kvdb.RTx) error {
+			done, err = c.copyBucket(
+				srcTx.ReadBucket(bucket), dstBucket, nil, resume,
+			)
+
+			return err
+		}, func() {
+			done = false
+		})
+		if err != nil {
+			return err
+		}
+
+		if done {
+			return meta.Put(key, []byte{metaStateCopied})
+		}
+
+		return meta.Put(
+			key, append([]byte{metaStateCopying},
+				encodeCursor(c.cursor)...),
+		)
+	}, func() {
+		done = false
+	})
+
+	return done, err
+}
+
+// chunkCopier copies the keys of a bucket tree in depth-first order until its
+// budget of keys for the current chunk is exhausted.
+type chunkCopier struct {
+	// remaining is the number of keys that may still be copied in the
+	// current chunk.
+	remaining int
+
+	// cursor is the path of bucket keys leading to the last copied key,
+	// with the key itself as its last element.
+	cursor [][]byte
+
+	progress *progressLogger
+}
+
+// copyBucket copies the keys, nested buckets and the sequence number of the
+// src bucket into the dst bucket, starting after the given resume path. The
+// path is the list of bucket keys leading to the src bucket below its
+// top-level bucket. It returns true if the src bucket has been copied
+// completely.
+func (c *chunkCopier) copyBucket(src kvdb.RBucket, dst kvdb.RwBucket,
+	path, resume [][]byte) (bool, error) {
+
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return false, err
+	}
+
+	cursor := src.ReadCursor()
+	k, v := cursor.First()
+	if len(resume) > 0 {
+		k, v = cursor.Seek(resume[0])
+
+		// A plain key at the resume position has already been copied,
+		// while a nested bucket may have been copied only partially.
+		if k != nil && bytes.Equal(k, resume[0]) {
+			nested := src.NestedReadBucket(k)
+			if v == nil && nested != nil {
+				done, err := c.copyNested(
+					k, nested, dst, path, resume[1:],
+				)
+				if err != nil || !done {
+					return done, err
+				}
+			}
+
+			k, v = cursor.Next()
+		}
+	}
+
+	for ; k != nil; k, v = cursor.Next() {
+		if c.remaining <= 0 {
+			return false, nil
+		}
+
+		if v == nil {
+			if nested := src.NestedReadBucket(k); nested != nil {
+				done, err := c.copyNested(
+					k, nested, dst, path, nil,
+				)
+				if err != nil || !done {
+					return done, err
+				}
+
+				continue
+			}
+		}
+
+		if err := dst.Put(k, v); err != nil {
+			return false, err
+		}
+
+		c.remaining--
+		c.cursor = appendPath(path, k)
+		c.progress.inc()
+	}
+
+	return true, nil
+}
+
+// copyNested copies the nested bucket with the given key of the src bucket
+// into the dst bucket, starting after the given resume path.
+func (c *chunkCopier) copyNested(key []byte, nested kvdb.RBucket,
+	dst kvdb.RwBucket, path, resume [][]byte) (bool, error) {
+
+	dstNested, err := dst.CreateBucketIfNotExists(key)
+	if err != nil {
+		return false, err
+	}
+
+	nestedPath := appendPath(path, key)
+	c.cursor = nestedPath
+
+	return c.copyBucket(nested, dstNested, nestedPath, resume)
+}
+
+// appendPath returns a copy of the given path with the key appended.
+func appendPath(path [][]byte, key []byte) [][]byte {
+	newPath := make([][]byte, 0, len(path)+1)
+	newPath = append(newPath, path...)
+
+	return append(newPath, bytes.Clone(key))
+}
+
+// encodeCursor serializes the given cursor as a list of length prefixed keys.
+func encodeCursor(cursor [][]byte) []byte {
+	var b []byte
+	for _, key := range cursor {
+		b = binary.AppendUvarint(b, uint64(len(key)))
+		b = append(b, key...)
+	}
+
+	return b
+}
+
+// decodeCursor parses a cursor that was serialized by encodeCursor.
+func decodeCursor(b []byte) ([][]byte, error) {
+	var cursor [][]byte
+	for len(b) > 0 {
+		keyLen, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b)-n) < keyLen {
+			return nil, fmt.Errorf("invalid copy cursor %x", b)
+		}
+		b = b[n:]
+
+		cursor = append(cursor, bytes.Clone(b[:keyLen]))
+		b = b[keyLen:]
+	}
+
+	return cursor, nil
+}
+
+// VerifyBackend compares all top-level buckets of the src backend record by
+// record against their copies in the dst backend. An error is returned if a
+// key, a value, a nested bucket or a sequence number differs, or if the copy
+// contains a record the original doesn't have. A successful verification is
+// recorded in the destination, so that it is skipped by subsequent calls.
+func VerifyBackend(name string, src, dst kvdb.Backend) error {
+	// Once verified, the copy may be modified by the migrations that
+	// follow, so we must not verify it again.
+	verified, err := isVerified(name, dst)
+	if err != nil {
+		return err
+	}
+	if verified {
+		log.Infof("Skipping verification of %s database, already "+
+			"verified", name)
+
+		return nil
+	}
+
+	buckets, err := topLevelBuckets(src)
+	if err != nil {
+		return fmt.Errorf("unable to list buckets of %s: %w", name,
+			err)
+	}
+
+	log.Infof("Verifying %d top-level buckets of %s database",
+		len(buckets), name)
+
+	progress := newProgressLogger("Verified", name)
+	err = kvdb.View(src, func(srcTx kvdb.RTx) error {
+		return kvdb.View(dst, func(dstTx kvdb.RTx) error {
+			for _, bucket := range buckets {
+				dstBucket := dstTx.ReadBucket(bucket)
+				if dstBucket == nil {
+					return fmt.Errorf("bucket %q missing "+
+						"in destination", bucket)
+				}
+
+				err := verifyBucket(
+					srcTx.ReadBucket(bucket), dstBucket,
+					[][]byte{bucket}, progress,
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}, func() {})
+	}, func() {
+		progress = newProgressLogger("Verified", name)
+	})
+	if err != nil {
+		return fmt.Errorf("verification of %s database failed: %w",
+			name, err)
+	}
+
+	log.Infof("Verified %d keys of %s database", progress.total, name)
+
+	return kvdb.Update(dst, func(tx kvdb.RwTx) error {
+		meta, err := tx.CreateTopLevelBucket(metaBucket)
+		if err != nil {
+			return err
+		}
+
+		return meta.Put([]byte(name), []byte{1})
+	}, func() {})
+}
+
+// verifyBucket recursively asserts that the dst bucket is an exact copy of the
+// src bucket. The path is the list of bucket keys leading to the src bucket
+// and is only used for error reporting.
+func verifyBucket(src, dst kvdb.RBucket, path [][]byte,
+	progress *progressLogger) error {
+
+	if src.Sequence() != dst.Sequence() {
+		return fmt.Errorf("sequence of bucket %q differs: %d != %d",
+			path, src.Sequence(), dst.Sequence())
+	}
+
+	var numKeys int
+	err := src.ForEach(func(k, v []byte) error {
+		numKeys++
+
+		if v == nil {
+			if nested := src.NestedReadBucket(k); nested != nil {
+				dstNested := dst.NestedReadBucket(k)
+				if dstNested == nil {
+					return fmt.Errorf("nested bucket %q "+
+						"missing in bucket %q", k, path)
+				}
+
+				nestedPath := append(
+					append([][]byte{}, path...), k,
+				)
+
+				return verifyBucket(
+					nested, dstNested, nestedPath, progress,
+				)
+			}
+		}
+
+		progress.inc()
+
+		if dst.NestedReadBucket(k) != nil {
+			return fmt.Errorf("key %x in bucket %q is a nested "+
+				"bucket in destination", k, path)
+		}
+
+		// We use a cursor to look up the key, as some backends don't
+		// distinguish between a missing key and an empty value on Get.
+		dstKey, dstValue := dst.ReadCursor().Seek(k)
+		if !bytes.Equal(k, dstKey) {
+			return fmt.Errorf("key %x missing in bucket %q", k,
+				path)
+		}
+
+		if !bytes.Equal(v, dstValue) {
+			return fmt.Errorf("value of key %x in bucket %q "+
+				"differs", k, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Finally, make sure the copy doesn't contain any additional keys.
+	var numDstKeys int
+	err = dst.ForEach(func(_, _ []byte) error {
+		numDstKeys++
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if numKeys != numDstKeys {
+		return fmt.Errorf("bucket %q has %d keys in destination, "+
+			"expected %d", path, numDstKeys, numKeys)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package migratedb
+
+import (
+	"testing"
+
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testBucket1 = []byte("bucket-1")
+	testBucket2 = []byte("bucket-2")
+	testNested  = []byte("nested")
+)
+
+// newTestBackend creates a new empty test backend.
+func newTestBackend(t *testing.T) kvdb.Backend {
+	t.Helper()
+
+	db, cleanup, err := kvdb.GetTestBackend(t.TempDir(), "test.db")
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	return db
+}
+
+// fillTestBackend populates the given backend with two top-level buckets, one
+// of them containing a nested bucket with two keys.
+func fillTestBackend(t *testing.T, db kvdb.Backend) {
+	t.Helper()
+
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		bucket1, err := tx.CreateTopLevelBucket(testBucket1)
+		if err != nil {
+			return err
+		}
+		if err := bucket1.SetSequence(42); err != nil {
+			return err
+		}
+		err = bucket1.Put([]byte("key-1"), []byte("value-1"))
+		if err != nil {
+			return err
+		}
+		if err := bucket1.Put([]byte("empty"), []byte{}); err != nil {
+			return err
+		}
+
+		nested, err := bucket1.CreateBucket(testNested)
+		if err != nil {
+			return err
+		}
+		if err := nested.SetSequence(7); err != nil {
+			return err
+		}
+		err = nested.Put([]byte("key-2"), []byte("value-2"))
+		if err != nil {
+			return err
+		}
+		err = nested.Put([]byte("key-4"), []byte("value-4"))
+		if err != nil {
+			return err
+		}
+
+		bucket2, err := tx.CreateTopLevelBucket(testBucket2)
+		if err != nil {
+			return err
+		}
+
+		return bucket2.Put([]byte("key-3"), []byte("value-3"))
+	}, func() {})
+	require.NoError(t, err)
+}
+
+// TestCopyBackend asserts that a backend is copied and verified correctly.
+func TestCopyBackend(t *testing.T) {
+	t.Parallel()
+
+	src, dst := newTestBackend(t), newTestBackend(t)
+	fillTestBackend(t, src)
+
+	require.NoError(t, CopyBackend("test", src, dst))
+
+	// Copying again is a no-op as all buckets have already been copied.
+	require.NoError(t, CopyBackend("test", src, dst))
+
+	// Any difference in the copy is detected by the verification.
+	setNested := func(value []byte) {
+		err := kvdb.Update(dst, func(tx kvdb.RwTx) error {
+			nested := tx.ReadWriteBucket(testBucket1).
+				NestedReadWriteBucket(testNested)
+
+			return nested.Put([]byte("key-2"), value)
+		}, func() {})
+		require.NoError(t, err)
+	}
+	setNested([]byte("modified"))
+	require.ErrorContains(t, VerifyBackend("test", src, dst), "differs")
+
+	setNested([]byte("value-2"))
+	require.NoError(t, VerifyBackend("test", src, dst))
+
+	// Once verified, the copy isn't verified again, as it may have been
+	// modified by the native SQL migrations.
+	setNested([]byte("modified"))
+	require.NoError(t, VerifyBackend("test", src, dst))
+}
+
+// TestCopyBackendResume asserts that an interrupted copy is resumed without
+// copying a bucket twice.
+func TestCopyBackendResume(t *testing.T) {
+	t.Parallel()
+
+	src, dst := newTestBackend(t), newTestBackend(t)
+	fillTestBackend(t, src)
+
+	// Simulate a previous run that copied only the first bucket by copying
+	// the complete database and then removing the second bucket together
+	// with its meta entry.
+	require.NoError(t, CopyBackend("test", src, dst))
+	err := kvdb.Update(dst, func(tx kvdb.RwTx) error {
+		err := tx.DeleteTopLevelBucket(testBucket2)
+		if err != nil {
+			return err
+		}
+
+		meta := tx.ReadWriteBucket(metaBucket)
+
+		return meta.Delete(metaKey("test", testBucket2))
+	}, func() {})
+	require.NoError(t, err)
+	require.Error(t, VerifyBackend("test", src, dst))
+
+	require.NoError(t, CopyBackend("test", src, dst))
+	require.NoError(t, VerifyBackend("test", src, dst))
+}
+
+// TestCopyBackendChunks asserts that a copy that is interrupted in the middle
+// of a bucket is continued after the last copied key.
+func TestCopyBackendChunks(t *testing.T) {
+	t.Parallel()
+
+	src, dst := newTestBackend(t), newTestBackend(t)
+	fillTestBackend(t, src)
+
+	// Copy only the first three keys of the first bucket, which leaves a
+	// cursor pointing into its nested bucket.
+	progress := newProgressLogger("Copied", "test")
+	for i := 0; i < 3; i++ {
+		done, err := copyBucketChunk(
+			"test", testBucket1, src, dst, 1, progress,
+		)
+		require.NoError(t, err)
+		require.False(t, done)
+	}
+
+	err := kvdb.View(dst, func(tx kvdb.RTx) error {
+		state := tx.ReadBucket(metaBucket).Get(
+			metaKey("test", testBucket1),
+		)
+		require.Equal(t, metaStateCopying, state[0])
+
+		cursor, err := decodeCursor(state[1:])
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{testNested, []byte("key-2")}, cursor)
+
+		return nil
+	}, func() {})
+	require.NoError(t, err)
+
+	// The bucket already exists in the destination, but as it is only
+	// partially copied, the copy is resumed instead of failing.
+	require.NoError(t, copyBackend("test", src, dst, 1))
+	require.NoError(t, VerifyBackend("test", src, dst))
+}
+
+// TestCopyBackendExistingBucket asserts that a bucket that already exists in
+// the destination is never overwritten.
+func TestCopyBackendExistingBucket(t *testing.T) {
+	t.Parallel()
+
+	src, dst := newTestBackend(t), newTestBackend(t)
+	fillTestBackend(t, src)
+
+	err := kvdb.Update(dst, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(testBucket1)
+
+		return err
+	}, func() {})
+	require.NoError(t, err)
+
+	err = CopyBackend("test", src, dst)
+	require.ErrorIs(t, err, ErrBucketExists)
+}
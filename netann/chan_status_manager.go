@@ -2,6 +2,7 @@ package netann
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -50,7 +51,7 @@ type ChanStatusConfig struct {
 	OurKeyLoc keychain.KeyLocator
 
 	// MessageSigner signs messages that validate under OurPubKey.
-	MessageSigner lnwallet.MessageSigner
+	MessageSigner lnwallet.SchnorrMessageSigner
 
 	// IsChannelActive checks whether the channel identified by the provided
 	// ChannelID is considered active. This should only return true if the
@@ -61,9 +62,13 @@ type ChanStatusConfig struct {
 	// ApplyChannelUpdate processes new ChannelUpdates signed by our node by
 	// updating our local routing table and broadcasting the update to our
 	// peers.
-	ApplyChannelUpdate func(*lnwire.ChannelUpdate1, *wire.OutPoint,
+	ApplyChannelUpdate func(lnwire.ChannelUpdate, *wire.OutPoint,
 		bool) error
 
+	// BestBlockHeight returns the height of the current chain tip. It is
+	// used as the block height of the updates of gossip v2 channels.
+	BestBlockHeight func() (uint32, error)
+
 	// DB stores the set of channels that are to be monitored.
 	DB DB
 
@@ -635,10 +640,20 @@ func (m *ChanStatusManager) signAndSendNextUpdate(outpoint wire.OutPoint,
 		return err
 	}
 
-	err = SignChannelUpdate(
-		m.cfg.MessageSigner, m.cfg.OurKeyLoc, chanUpdate,
-		ChanUpdSetDisable(disabled), ChanUpdSetTimestamp,
-	)
+	switch upd := chanUpdate.(type) {
+	case *lnwire.ChannelUpdate1:
+		err = SignChannelUpdate(
+			m.cfg.MessageSigner, m.cfg.OurKeyLoc, upd,
+			ChanUpdSetDisable(disabled), ChanUpdSetTimestamp,
+		)
+
+	case *lnwire.ChannelUpdate2:
+		err = m.signNextChannelUpdate2(upd, disabled)
+
+	default:
+		err = fmt.Errorf("unhandled implementation of "+
+			"lnwire.ChannelUpdate: %T", chanUpdate)
+	}
 	if err != nil {
 		return err
 	}
@@ -646,12 +661,33 @@ func (m *ChanStatusManager) signAndSendNextUpdate(outpoint wire.OutPoint,
 	return m.cfg.ApplyChannelUpdate(chanUpdate, &outpoint, private)
 }
 
+// signNextChannelUpdate2 toggles the disabled flags of the passed v2 update
+// and signs it. As v2 updates are ordered by the block height they were
+// signed at, the new update will use the current height, or increment the old
+// height by 1 to ensure the update can propagate.
+func (m *ChanStatusManager) signNextChannelUpdate2(
+	update *lnwire.ChannelUpdate2, disabled bool) error {
+
+	height, err := m.cfg.BestBlockHeight()
+	if err != nil {
+		return err
+	}
+	if height <= update.BlockHeight.Val {
+		height = update.BlockHeight.Val + 1
+	}
+
+	update.BlockHeight.Val = height
+	update.SetDisabledFlag(disabled)
+
+	return SignChannelUpdate2(m.cfg.MessageSigner, m.cfg.OurKeyLoc, update)
+}
+
 // fetchLastChanUpdateByOutPoint fetches the latest policy for our direction of
 // a channel, and crafts a new ChannelUpdate with this policy. Returns an error
 // in case our ChannelEdgePolicy is not found in the database. Also returns if
 // the channel is private by checking AuthProof for nil.
 func (m *ChanStatusManager) fetchLastChanUpdateByOutPoint(op wire.OutPoint) (
-	*lnwire.ChannelUpdate1, bool, error) {
+	lnwire.ChannelUpdate, bool, error) {
 
 	// Get the edge info and policies for this channel from the graph.
 	info, edge1, edge2, err := m.cfg.Graph.FetchChannelEdgesByOutpoint(&op)
@@ -659,6 +695,18 @@ func (m *ChanStatusManager) fetchLastChanUpdateByOutPoint(op wire.OutPoint) (
 		return nil, false, err
 	}
 
+	// Gossip v2 channels are updated using v2 channel updates.
+	if info.GossipVersion() == lnwire.GossipVersion2 {
+		update, err := ExtractChannelUpdate2(
+			m.ourPubKeyBytes, info, edge1, edge2,
+		)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return update, info.AuthProof == nil, nil
+	}
+
 	update, err := ExtractChannelUpdate(
 		m.ourPubKeyBytes, info, edge1, edge2,
 	)
@@ -681,7 +729,7 @@ func (m *ChanStatusManager) loadInitialChanState(
 	// Determine the channel's starting status by inspecting the disable bit
 	// on last announcement we sent out.
 	var initialStatus ChanStatus
-	if lastUpdate.ChannelFlags&lnwire.ChanUpdateDisabled == 0 {
+	if !lastUpdate.IsDisabled() {
 		initialStatus = ChanStatusEnabled
 	} else {
 		initialStatus = ChanStatusDisabled
@@ -178,12 +178,17 @@ func (g *mockGraph) FetchChannelEdgesByOutpoint(
 	return info, pol1, pol2, nil
 }
 
-func (g *mockGraph) ApplyChannelUpdate(update *lnwire.ChannelUpdate1,
+func (g *mockGraph) ApplyChannelUpdate(upd lnwire.ChannelUpdate,
 	op *wire.OutPoint, private bool) error {
 
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	update, ok := upd.(*lnwire.ChannelUpdate1)
+	if !ok {
+		return fmt.Errorf("unexpected channel update: %T", upd)
+	}
+
 	outpoint, ok := g.sidToCid[update.ShortChannelID]
 	if !ok {
 		return fmt.Errorf("unknown short channel id: %v",
@@ -1,6 +1,7 @@
 package netann
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 
@@ -104,6 +105,94 @@ func CreateChanAnnouncement(chanProof *models.ChannelAuthProof,
 	return chanAnn, edge1Ann, edge2Ann, nil
 }
 
+// CreateChanAnnouncement2 is the gossip v2 counterpart of
+// CreateChanAnnouncement. It re-creates the authenticated
+// channel_announcement_2 of a v2 channel along with the channel_update_2
+// messages of each of the given policies.
+func CreateChanAnnouncement2(chanProof *models.ChannelAuthProof,
+	chanInfo *models.ChannelEdgeInfo,
+	e1, e2 *models.ChannelEdgePolicy) (*lnwire.ChannelAnnouncement2,
+	*lnwire.ChannelUpdate2, *lnwire.ChannelUpdate2, error) {
+
+	chanAnn, err := UnsignedChanAnnouncement2FromEdge(chanInfo)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	chanAnn.Signature.Val, err = lnwire.NewSigFromSchnorrRawSignature(
+		chanProof.Signature,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// As with v1 channels, we only create an update for the directions
+	// that have been advertised.
+	var edge1Ann, edge2Ann *lnwire.ChannelUpdate2
+	if e1 != nil {
+		edge1Ann, err = ChannelUpdate2FromEdge(chanInfo, e1)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if e2 != nil {
+		edge2Ann, err = ChannelUpdate2FromEdge(chanInfo, e2)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return chanAnn, edge1Ann, edge2Ann, nil
+}
+
+// UnsignedChanAnnouncement2FromEdge reconstructs an unsigned
+// ChannelAnnouncement2 from the given edge info. The ExtraOpaqueData of the
+// edge is expected to hold the TLV encoding of any unknown signed fields of
+// the original announcement.
+func UnsignedChanAnnouncement2FromEdge(
+	info *models.ChannelEdgeInfo) (*lnwire.ChannelAnnouncement2, error) {
+
+	extraFields, err := lnwire.ExtraSignedFieldsFromOpaqueData(
+		info.ExtraOpaqueData,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var ann lnwire.ChannelAnnouncement2
+	ann.ChainHash.Val = info.ChainHash
+	ann.ShortChannelID.Val = lnwire.NewShortChanIDFromInt(info.ChannelID)
+	ann.Capacity.Val = uint64(info.Capacity)
+	ann.NodeID1.Val = info.NodeKey1Bytes
+	ann.NodeID2.Val = info.NodeKey2Bytes
+	ann.Outpoint.Val = lnwire.OutPoint(info.ChannelPoint)
+	ann.ExtraSignedFields = extraFields
+
+	if info.Features != nil {
+		ann.Features.Val = *info.Features.RawFeatureVector
+	} else {
+		ann.Features.Val = *lnwire.NewRawFeatureVector()
+	}
+
+	if info.HasFlokicoinKeys() {
+		flcKey1 := tlv.ZeroRecordT[tlv.TlvType12, [33]byte]()
+		flcKey1.Val = info.FlokicoinKey1Bytes
+		ann.FlokicoinKey1 = tlv.SomeRecordT(flcKey1)
+
+		flcKey2 := tlv.ZeroRecordT[tlv.TlvType14, [33]byte]()
+		flcKey2.Val = info.FlokicoinKey2Bytes
+		ann.FlokicoinKey2 = tlv.SomeRecordT(flcKey2)
+	}
+
+	info.MerkleRootHash.WhenSome(func(root chainhash.Hash) {
+		merkleRoot := tlv.ZeroRecordT[tlv.TlvType16, [32]byte]()
+		merkleRoot.Val = root
+		ann.MerkleRootHash = tlv.SomeRecordT(merkleRoot)
+	})
+
+	return &ann, nil
+}
+
 // FetchPkScript defines a function that can be used to fetch the output script
 // for the transaction with the given SCID.
 type FetchPkScript func(lnwire.ShortChannelID) (txscript.ScriptClass,
@@ -347,6 +436,16 @@ func chanAnn2P2TRMuSig2Keys(a *lnwire.ChannelAnnouncement2,
 			return nil, err
 		}
 
+		// The announced keys must be the ones that the funding output
+		// actually commits to, otherwise the signature wouldn't prove
+		// that the signers control the output.
+		err = checkTaprootFundingKeys(
+			bitcoinKey1, bitcoinKey2, a.MerkleRootHash, scriptAddr,
+		)
+		if err != nil {
+			return nil, err
+		}
+
 		keys = append(keys, bitcoinKey1, bitcoinKey2)
 	} else {
 		// If bitcoin keys are not provided, then the on-chain output
@@ -364,6 +463,42 @@ func chanAnn2P2TRMuSig2Keys(a *lnwire.ChannelAnnouncement2,
 	return keys, nil
 }
 
+// checkTaprootFundingKeys checks that the output key of a P2TR funding output
+// is the MuSig2 aggregate of the two given funding keys. If a merkle root is
+// announced, then the aggregate key must commit to it. Otherwise, both the
+// untweaked aggregate key and the BIP 86 tweaked one are accepted, the latter
+// being what the taproot channels of this implementation use.
+func checkTaprootFundingKeys(key1, key2 *crypto.PublicKey,
+	merkleRoot tlv.OptionalRecordT[tlv.TlvType16, [32]byte],
+	scriptAddr chainutil.Address) error {
+
+	tweakOpts := [][]musig2.KeyAggOption{
+		nil, {musig2.WithBIP86KeyTweak()},
+	}
+	merkleRoot.WhenSome(func(root tlv.RecordT[tlv.TlvType16, [32]byte]) {
+		tweakOpts = [][]musig2.KeyAggOption{
+			{musig2.WithTaprootKeyTweak(root.Val[:])},
+		}
+	})
+
+	keys := []*crypto.PublicKey{key1, key2}
+	outputKey := scriptAddr.ScriptAddress()
+	for _, opts := range tweakOpts {
+		aggKey, _, _, err := musig2.AggregateKeys(keys, true, opts...)
+		if err != nil {
+			return err
+		}
+
+		finalKey := schnorr.SerializePubKey(aggKey.FinalKey)
+		if bytes.Equal(finalKey, outputKey) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("announced flokicoin keys don't match the funding " +
+		"output key")
+}
+
 // ChanAnn2DigestToSign computes the digest of the message to be signed.
 func ChanAnn2DigestToSign(a *lnwire.ChannelAnnouncement2) (*chainhash.Hash,
 	error) {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/graph/db/models"
 	"github.com/flokiorg/flnd/input"
 	"github.com/flokiorg/flnd/lnwire"
@@ -68,6 +69,127 @@ func TestCreateChanAnnouncement(t *testing.T) {
 	assert.Equal(t, chanAnn, expChanAnn)
 }
 
+// TestCreateChanAnnouncement2 asserts that a channel_announcement_2 is
+// correctly re-created from a v2 edge and its proof.
+func TestCreateChanAnnouncement2(t *testing.T) {
+	t.Parallel()
+
+	node1, node2 := genChanAnnKeys(t)
+	expChanAnn := buildUnsignedChanAnnouncement(node1, node2, true)
+	expChanAnn.Features.Val = *lnwire.NewRawFeatureVector(
+		lnwire.SimpleTaprootChannelsRequiredStaging,
+	)
+	expChanAnn.Outpoint.Val = lnwire.OutPoint{Index: 1}
+	expChanAnn.MerkleRootHash = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType16, [32]byte]([32]byte{0x1}),
+	)
+	expChanAnn.ExtraSignedFields = lnwire.ExtraSignedFields{
+		100: {0x1, 0x2},
+	}
+
+	var sig [64]byte
+	sig[0] = 0x1
+	expChanAnn.Signature.Val, _ = lnwire.NewSigFromSchnorrRawSignature(
+		sig[:],
+	)
+
+	extraData, err := expChanAnn.ExtraSignedFields.ToOpaqueData()
+	require.NoError(t, err)
+
+	chanInfo := &models.ChannelEdgeInfo{
+		Version:        lnwire.GossipVersion2,
+		ChainHash:      expChanAnn.ChainHash.Val,
+		ChannelID:      expChanAnn.ShortChannelID.Val.ToUint64(),
+		ChannelPoint:   wire.OutPoint(expChanAnn.Outpoint.Val),
+		Capacity:       chainutil.Amount(expChanAnn.Capacity.Val),
+		NodeKey1Bytes:  expChanAnn.NodeID1.Val,
+		NodeKey2Bytes:  expChanAnn.NodeID2.Val,
+		MerkleRootHash: fn.Some(chainhash.Hash{0x1}),
+		Features: lnwire.NewFeatureVector(
+			&expChanAnn.Features.Val, lnwire.Features,
+		),
+		ExtraOpaqueData: extraData,
+	}
+	copy(chanInfo.FlokicoinKey1Bytes[:], node1.flcPub.SerializeCompressed())
+	copy(chanInfo.FlokicoinKey2Bytes[:], node2.flcPub.SerializeCompressed())
+
+	chanAnn, upd1, upd2, err := CreateChanAnnouncement2(
+		models.NewV2ChannelAuthProof(sig[:]), chanInfo, nil, nil,
+	)
+	require.NoError(t, err)
+	require.Nil(t, upd1)
+	require.Nil(t, upd2)
+	require.Equal(t, expChanAnn, chanAnn)
+
+	// Without the funding keys, the announcement should not carry them
+	// either, as the signature is then over the funding output key.
+	chanInfo.FlokicoinKey1Bytes = [33]byte{}
+	chanInfo.FlokicoinKey2Bytes = [33]byte{}
+	chanAnn, err = UnsignedChanAnnouncement2FromEdge(chanInfo)
+	require.NoError(t, err)
+	require.True(t, chanAnn.FlokicoinKey1.IsNone())
+	require.True(t, chanAnn.FlokicoinKey2.IsNone())
+}
+
+// TestChanAnnounce2FundingKeys checks that the flokicoin keys of a 4-of-4
+// channel_announcement_2 are only accepted if the P2TR funding output actually
+// commits to them.
+func TestChanAnnounce2FundingKeys(t *testing.T) {
+	t.Parallel()
+
+	node1, node2 := genChanAnnKeys(t)
+	ann := buildUnsignedChanAnnouncement(node1, node2, true)
+
+	taprootAddr := func(
+		tapscriptRoot fn.Option[chainhash.Hash]) chainutil.Address {
+
+		pkScript, _, err := input.GenTaprootFundingScript(
+			node1.flcPub, node2.flcPub, 0, tapscriptRoot,
+		)
+		require.NoError(t, err)
+
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			pkScript, &chaincfg.MainNetParams,
+		)
+		require.NoError(t, err)
+		require.Len(t, addrs, 1)
+
+		return addrs[0]
+	}
+
+	// A BIP 86 funding output, as created for our own taproot channels,
+	// is accepted.
+	bip86Addr := taprootAddr(fn.None[chainhash.Hash]())
+	keys, err := chanAnn2P2TRMuSig2Keys(ann, bip86Addr)
+	require.NoError(t, err)
+	require.Len(t, keys, 4)
+
+	// An output that commits to a tapscript root is only accepted if the
+	// announcement carries that root.
+	root := chainhash.Hash{0x1}
+	rootAddr := taprootAddr(fn.Some(root))
+	_, err = chanAnn2P2TRMuSig2Keys(ann, rootAddr)
+	require.Error(t, err)
+
+	ann.MerkleRootHash = tlv.SomeRecordT(
+		tlv.NewPrimitiveRecord[tlv.TlvType16, [32]byte](root),
+	)
+	_, err = chanAnn2P2TRMuSig2Keys(ann, rootAddr)
+	require.NoError(t, err)
+
+	// Finally, an output that doesn't belong to the announced keys at all
+	// is rejected.
+	otherKey, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+	otherAddr, err := chainutil.NewAddressTaproot(
+		otherKey.PubKey().SerializeCompressed()[1:],
+		&chaincfg.MainNetParams,
+	)
+	require.NoError(t, err)
+	_, err = chanAnn2P2TRMuSig2Keys(ann, otherAddr)
+	require.Error(t, err)
+}
+
 // TestChanAnnounce2Validation checks that the various forms of the
 // channel_announcement_2 message are validated correctly.
 func TestChanAnnounce2Validation(t *testing.T) {
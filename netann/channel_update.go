@@ -98,6 +98,31 @@ func SignChannelUpdate(signer lnwallet.MessageSigner, keyLoc keychain.KeyLocator
 	return nil
 }
 
+// SignChannelUpdate2 signs the given lnwire.ChannelUpdate2 with the key
+// described by the key locator, producing a Schnorr signature over the tagged
+// hash of the signed range of the message.
+//
+// NOTE: This method modifies the given update.
+func SignChannelUpdate2(signer lnwallet.SchnorrMessageSigner,
+	keyLoc keychain.KeyLocator, update *lnwire.ChannelUpdate2) error {
+
+	data, err := lnwire.SerialiseFieldsToSign(update)
+	if err != nil {
+		return fmt.Errorf("unable to get data to sign: %w", err)
+	}
+
+	sig, err := signer.SignMessageSchnorr(
+		keyLoc, data, false, nil, ChanUpdate2DigestTag(),
+	)
+	if err != nil {
+		return err
+	}
+
+	update.Signature.Val, err = lnwire.NewSigFromSignature(sig)
+
+	return err
+}
+
 // ExtractChannelUpdate attempts to retrieve a lnwire.ChannelUpdate message from
 // an edge's info and a set of routing policies.
 //
@@ -107,6 +132,39 @@ func ExtractChannelUpdate(ownerPubKey []byte,
 	policies ...*models.ChannelEdgePolicy) (
 	*lnwire.ChannelUpdate1, error) {
 
+	// Extract the channel update from the policy we own, if any.
+	edge := ownedPolicy(ownerPubKey, info, policies...)
+	if edge == nil {
+		return nil, ErrUnableToExtractChanUpdate
+	}
+
+	return ChannelUpdateFromEdge(info, edge)
+}
+
+// ExtractChannelUpdate2 is the gossip v2 counterpart of ExtractChannelUpdate.
+// It attempts to retrieve a lnwire.ChannelUpdate2 message from a v2 edge's
+// info and a set of routing policies.
+//
+// NOTE: The passed policies can be nil.
+func ExtractChannelUpdate2(ownerPubKey []byte,
+	info *models.ChannelEdgeInfo,
+	policies ...*models.ChannelEdgePolicy) (
+	*lnwire.ChannelUpdate2, error) {
+
+	// Extract the channel update from the policy we own, if any.
+	edge := ownedPolicy(ownerPubKey, info, policies...)
+	if edge == nil {
+		return nil, ErrUnableToExtractChanUpdate
+	}
+
+	return ChannelUpdate2FromEdge(info, edge)
+}
+
+// ownedPolicy returns the policy among the given ones that is owned by the
+// node with the given public key, or nil if there is none.
+func ownedPolicy(ownerPubKey []byte, info *models.ChannelEdgeInfo,
+	policies ...*models.ChannelEdgePolicy) *models.ChannelEdgePolicy {
+
 	// Helper function to extract the owner of the given policy.
 	owner := func(edge *models.ChannelEdgePolicy) []byte {
 		var pubKey *crypto.PublicKey
@@ -124,14 +182,13 @@ func ExtractChannelUpdate(ownerPubKey []byte,
 		return pubKey.SerializeCompressed()
 	}
 
-	// Extract the channel update from the policy we own, if any.
 	for _, edge := range policies {
 		if edge != nil && bytes.Equal(ownerPubKey, owner(edge)) {
-			return ChannelUpdateFromEdge(info, edge)
+			return edge
 		}
 	}
 
-	return nil, ErrUnableToExtractChanUpdate
+	return nil
 }
 
 // UnsignedChannelUpdateFromEdge reconstructs an unsigned ChannelUpdate from the
@@ -179,6 +236,72 @@ func ChannelUpdateFromEdge(info *models.ChannelEdgeInfo,
 	return update, nil
 }
 
+// UnsignedChannelUpdate2FromEdge reconstructs an unsigned ChannelUpdate2 from
+// the given edge info and policy. The ExtraOpaqueData of the policy is expected
+// to hold the TLV encoding of any unknown signed fields of the original update.
+func UnsignedChannelUpdate2FromEdge(info *models.ChannelEdgeInfo,
+	policy *models.ChannelEdgePolicy) (*lnwire.ChannelUpdate2, error) {
+
+	extraFields, err := lnwire.ExtraSignedFieldsFromOpaqueData(
+		policy.ExtraOpaqueData,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var update lnwire.ChannelUpdate2
+	update.ChainHash.Val = info.ChainHash
+	update.ShortChannelID.Val = lnwire.NewShortChanIDFromInt(
+		policy.ChannelID,
+	)
+	update.BlockHeight.Val = policy.BlockHeight
+	update.DisabledFlags.Val = policy.DisableFlags
+	update.CLTVExpiryDelta.Val = policy.TimeLockDelta
+	update.HTLCMinimumMsat.Val = policy.MinHTLC
+	update.HTLCMaximumMsat.Val = policy.MaxHTLC
+	update.FeeBaseMsat.Val = uint32(policy.FeeBaseMSat)
+	update.FeeProportionalMillionths.Val = uint32(
+		policy.FeeProportionalMillionths,
+	)
+	update.ExtraSignedFields = extraFields
+
+	// The direction of a v2 update is signalled by the presence of the
+	// second_peer record.
+	if policy.ChannelFlags&lnwire.ChanUpdateDirection != 0 {
+		update.SecondPeer = tlv.SomeRecordT(
+			tlv.ZeroRecordT[tlv.TlvType8, lnwire.TrueBoolean](),
+		)
+	}
+
+	policy.InboundFee.WhenSome(func(fee lnwire.Fee) {
+		update.InboundFee = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType55555, lnwire.Fee](fee),
+		)
+	})
+
+	return &update, nil
+}
+
+// ChannelUpdate2FromEdge reconstructs a signed ChannelUpdate2 from the given
+// edge info and policy.
+func ChannelUpdate2FromEdge(info *models.ChannelEdgeInfo,
+	policy *models.ChannelEdgePolicy) (*lnwire.ChannelUpdate2, error) {
+
+	update, err := UnsignedChannelUpdate2FromEdge(info, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	update.Signature.Val, err = lnwire.NewSigFromSchnorrRawSignature(
+		policy.SigBytes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return update, nil
+}
+
 // ValidateChannelUpdateAnn validates the channel update announcement by
 // checking (1) that the included signature covers the announcement and has been
 // signed by the node's private key, and (2) that the announcement's message
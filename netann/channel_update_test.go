@@ -5,12 +5,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/graph/db/models"
 	"github.com/flokiorg/flnd/keychain"
 	"github.com/flokiorg/flnd/lnwallet"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/netann"
+	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/crypto"
 	"github.com/flokiorg/go-flokicoin/crypto/ecdsa"
+	"github.com/stretchr/testify/require"
 )
 
 type mockSigner struct {
@@ -191,3 +195,53 @@ func TestUpdateDisableFlag(t *testing.T) {
 		})
 	}
 }
+
+// TestSignChannelUpdate2 asserts that a channel_update_2 re-created from a v2
+// policy can be signed and then verified under the signer's node key, and that
+// the signed update can be re-created from the policy it is stored as.
+func TestSignChannelUpdate2(t *testing.T) {
+	t.Parallel()
+
+	info := &models.ChannelEdgeInfo{
+		Version:   lnwire.GossipVersion2,
+		ChannelID: 1234,
+		Capacity:  chainutil.LokiPerFlokicoin,
+	}
+	policy := &models.ChannelEdgePolicy{
+		Version:         lnwire.GossipVersion2,
+		ChannelID:       info.ChannelID,
+		BlockHeight:     100,
+		DisableFlags:    lnwire.ChanUpdateDisableOutgoing,
+		ChannelFlags:    lnwire.ChanUpdateDirection,
+		TimeLockDelta:   40,
+		MinHTLC:         1,
+		MaxHTLC:         1000,
+		FeeBaseMSat:     10,
+		InboundFee:      fn.Some(lnwire.Fee{BaseFee: -1, FeeRate: -2}),
+		ExtraOpaqueData: []byte{0x01, 0x01, 0xff},
+	}
+
+	update, err := netann.UnsignedChannelUpdate2FromEdge(info, policy)
+	require.NoError(t, err)
+	require.False(t, update.IsNode1())
+	require.True(t, update.IsDisabled())
+
+	signer := netann.NewNodeSigner(privKeySigner)
+	err = netann.SignChannelUpdate2(signer, testKeyLoc, update)
+	require.NoError(t, err)
+	require.NoError(t, netann.VerifyChannelUpdateSignature(update, pubKey))
+
+	// A signer for any other key should be refused.
+	otherLoc := keychain.KeyLocator{Family: keychain.KeyFamilyMultiSig}
+	require.Error(t, netann.SignChannelUpdate2(signer, otherLoc, update))
+
+	// Re-creating the update from the stored signature must yield an
+	// identical, still valid, message.
+	policy.SigBytes = update.Signature.Val.RawBytes()
+	dbUpdate, err := netann.ChannelUpdate2FromEdge(info, policy)
+	require.NoError(t, err)
+	require.Equal(t, update, dbUpdate)
+
+	err = netann.VerifyChannelUpdateSignature(dbUpdate, pubKey)
+	require.NoError(t, err)
+}
@@ -15,6 +15,20 @@ import (
 	"github.com/flokiorg/go-flokicoin/crypto"
 )
 
+const (
+	// nodeAnn2MsgName is a string representing the name of the
+	// NodeAnnouncement2 message. This string will be used during the
+	// construction of the tagged hash message to be signed when producing
+	// the signature for the NodeAnnouncement2 message.
+	nodeAnn2MsgName = "node_announcement_2"
+
+	// nodeAnn2SigField is the name of the signature field of the
+	// NodeAnnouncement2 message. This string will be used during the
+	// construction of the tagged hash message to be signed when producing
+	// the signature for the NodeAnnouncement2 message.
+	nodeAnn2SigField = "signature"
+)
+
 // NodeAnnModifier is a closure that makes in-place modifications to an
 // lnwire.NodeAnnouncement1.
 type NodeAnnModifier func(*lnwire.NodeAnnouncement1)
@@ -155,3 +169,82 @@ func ValidateNodeAnnSignature(a *lnwire.NodeAnnouncement1) error {
 
 	return nil
 }
+
+// SignNodeAnnouncement2 signs the given lnwire.NodeAnnouncement2 with the key
+// described by the key locator, producing a Schnorr signature over the tagged
+// hash of the signed range of the message.
+//
+// NOTE: This method modifies the given announcement.
+func SignNodeAnnouncement2(signer lnwallet.SchnorrMessageSigner,
+	keyLoc keychain.KeyLocator, nodeAnn *lnwire.NodeAnnouncement2) error {
+
+	data, err := lnwire.SerialiseFieldsToSign(nodeAnn)
+	if err != nil {
+		return fmt.Errorf("unable to get data to sign: %w", err)
+	}
+
+	sig, err := signer.SignMessageSchnorr(
+		keyLoc, data, false, nil, NodeAnn2DigestTag(),
+	)
+	if err != nil {
+		return err
+	}
+
+	nodeAnn.Signature.Val, err = lnwire.NewSigFromSignature(sig)
+
+	return err
+}
+
+// ValidateNodeAnn2 validates the fields and signature of a v2 node
+// announcement.
+func ValidateNodeAnn2(a *lnwire.NodeAnnouncement2) error {
+	err := ValidateNodeAnn2Fields(a)
+	if err != nil {
+		return fmt.Errorf("invalid node announcement fields: %w", err)
+	}
+
+	return ValidateNodeAnn2Signature(a)
+}
+
+// ValidateNodeAnn2Fields validates the fields of a v2 node announcement.
+func ValidateNodeAnn2Fields(a *lnwire.NodeAnnouncement2) error {
+	var err error
+	a.DNSHostName.WhenSomeV(func(addr lnwire.DNSAddress) {
+		err = lnwire.ValidateDNSAddr(addr.Hostname, addr.Port)
+	})
+
+	return err
+}
+
+// ValidateNodeAnn2Signature validates that the Schnorr signature of the v2
+// node announcement covers the announcement and was made by the announced
+// node key.
+func ValidateNodeAnn2Signature(a *lnwire.NodeAnnouncement2) error {
+	data, err := lnwire.SerialiseFieldsToSign(a)
+	if err != nil {
+		return fmt.Errorf("unable to reconstruct message data: %w", err)
+	}
+	digest := MsgHash(nodeAnn2MsgName, nodeAnn2SigField, data)
+
+	nodeSig, err := a.Signature.Val.ToSignature()
+	if err != nil {
+		return err
+	}
+	nodeKey, err := crypto.ParsePubKey(a.NodeID.Val[:])
+	if err != nil {
+		return err
+	}
+
+	if !nodeSig.Verify(digest[:], nodeKey) {
+		return fmt.Errorf("signature on NodeAnnouncement2(%x) is "+
+			"invalid", nodeKey.SerializeCompressed())
+	}
+
+	return nil
+}
+
+// NodeAnn2DigestTag returns the tag to be used when signing the digest of a
+// node_announcement_2 message.
+func NodeAnn2DigestTag() []byte {
+	return MsgTag(nodeAnn2MsgName, nodeAnn2SigField)
+}
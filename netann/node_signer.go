@@ -6,6 +6,7 @@ import (
 	"github.com/flokiorg/flnd/keychain"
 	"github.com/flokiorg/flnd/lnwallet"
 	"github.com/flokiorg/go-flokicoin/crypto/ecdsa"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr"
 )
 
 // NodeSigner is an implementation of the MessageSigner interface backed by the
@@ -52,6 +53,30 @@ func (n *NodeSigner) SignMessageCompact(msg []byte, doubleHash bool) ([]byte,
 	return n.keySigner.SignMessageCompact(msg, doubleHash)
 }
 
-// A compile time check to ensure that NodeSigner implements the MessageSigner
-// interface.
-var _ lnwallet.MessageSigner = (*NodeSigner)(nil)
+// SignMessageSchnorr signs the passed message under the resident node's
+// private key described in the key locator, producing a Schnorr signature. If
+// the target key locator is _not_ the node's private key, then an error will be
+// returned.
+func (n *NodeSigner) SignMessageSchnorr(keyLoc keychain.KeyLocator,
+	msg []byte, doubleHash bool, taprootTweak []byte,
+	tag []byte) (*schnorr.Signature, error) {
+
+	// If this isn't our identity public key, then we'll exit early with an
+	// error as we can't sign with this key.
+	if keyLoc != n.keySigner.KeyLocator() {
+		return nil, fmt.Errorf("unknown public key locator")
+	}
+
+	sig, err := n.keySigner.SignMessageSchnorr(
+		msg, doubleHash, taprootTweak, tag,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("can't sign the message: %w", err)
+	}
+
+	return sig, nil
+}
+
+// A compile time check to ensure that NodeSigner implements the
+// SchnorrMessageSigner interface.
+var _ lnwallet.SchnorrMessageSigner = (*NodeSigner)(nil)
@@ -0,0 +1,32 @@
+package offers
+
+import (
+	"github.com/flokiorg/flnd/build"
+	flog "github.com/flokiorg/go-flokicoin/log/v2"
+)
+
+// Subsystem defines the logging code for this subsystem.
+const Subsystem = "OFRS"
+
+// log is a logger that is initialized with no output filters.  This
+// means the package will not perform any logging by default until the caller
+// requests it.
+var log flog.Logger
+
+// The default amount of logging is none.
+func init() {
+	UseLogger(build.NewSubLogger(Subsystem, nil))
+}
+
+// DisableLog disables all library log output.  Logging output is disabled
+// by default until UseLogger is called.
+func DisableLog() {
+	UseLogger(flog.Disabled)
+}
+
+// UseLogger uses a specified Logger to output package logging info.
+// This should be used in preference to SetLogWriter if the caller is also
+// using flog.
+func UseLogger(logger flog.Logger) {
+	log = logger
+}
@@ -0,0 +1,665 @@
+package offers
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flokiorg/flnd/bolt12"
+	"github.com/flokiorg/flnd/clock"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/invoices"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/onionmessage"
+	"github.com/flokiorg/flnd/record"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/subscribe"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/flnd/zpay32"
+	"github.com/flokiorg/go-flokicoin/chaincfg"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr"
+)
+
+const (
+	// DefaultHandlerTimeout is the time we allow for answering a single
+	// invoice request, including building blinded paths and sending the
+	// reply.
+	DefaultHandlerTimeout = time.Minute
+
+	// maxConcurrentRequests is the number of invoice requests that are
+	// handled in parallel. Requests beyond that are dropped, as the payer
+	// will simply retry.
+	maxConcurrentRequests = 16
+
+	// offerMetadataLen is the length of the random offer_metadata we set
+	// on the offers we create.
+	offerMetadataLen = 16
+)
+
+var (
+	// ErrOfferDisabled is returned when an invoice request is received for
+	// an offer that has been disabled.
+	ErrOfferDisabled = errors.New("offer has been disabled")
+
+	// ErrOfferExpired is returned when an invoice request is received for
+	// an offer past its absolute expiry.
+	ErrOfferExpired = errors.New("offer has expired")
+
+	// ErrCurrencyUnsupported is returned for offers denominated in a
+	// currency other than the chain's native unit.
+	ErrCurrencyUnsupported = errors.New("offers in a currency other " +
+		"than msat are not supported")
+
+	// ErrMissingAmount is returned when neither the offer nor the invoice
+	// request carry an amount.
+	ErrMissingAmount = errors.New("invoice request has no amount")
+
+	// ErrMissingDescription is returned when an offer with an amount is
+	// created without a description.
+	ErrMissingDescription = errors.New("an offer with an amount must " +
+		"have a description")
+)
+
+// Config holds the dependencies of the offers Manager.
+type Config struct {
+	// NodeKey is our node's public key. It is the offer_issuer_id of the
+	// offers we create and the invoice_node_id of our invoices.
+	NodeKey *crypto.PublicKey
+
+	// ChainHash is the genesis hash of the chain we operate on.
+	ChainHash chainhash.Hash
+
+	// Store persists the offers we have created.
+	Store Store
+
+	// SignMessageSchnorr produces a BIP-340 signature with our node key
+	// over the tagged hash of msg.
+	SignMessageSchnorr func(msg, tag []byte) (*schnorr.Signature, error)
+
+	// AddInvoice adds an invoice to the invoice registry.
+	AddInvoice func(ctx context.Context, invoice *invoices.Invoice,
+		paymentHash lntypes.Hash) (uint64, error)
+
+	// BuildBlindedPaths builds blinded payment paths to our node for the
+	// given amount. The path ID is embedded in the final hop so the
+	// registry can match the payment to the invoice, and the paths must
+	// remain valid for at least the given expiry.
+	BuildBlindedPaths func(amt lnwire.MilliLoki, pathID []byte,
+		expiry time.Duration) ([]*zpay32.BlindedPaymentPath, error)
+
+	// GenInvoiceFeatures returns the feature vector of the invoices we add
+	// to the registry.
+	GenInvoiceFeatures func() *lnwire.FeatureVector
+
+	// MinFinalCLTVExpiryDelta is the final CLTV delta of our invoices.
+	MinFinalCLTVExpiryDelta uint32
+
+	// InvoiceExpiry is the relative expiry of the invoices we create in
+	// response to invoice requests.
+	InvoiceExpiry time.Duration
+
+	// SubscribeOnionMessages subscribes to onion messages delivered to our
+	// node.
+	SubscribeOnionMessages func() (*subscribe.Client, error)
+
+	// FindPath finds a route of onion message capable nodes from our node
+	// to the destination.
+	FindPath func(ctx context.Context,
+		dest route.Vertex) (onionmessage.OnionMessagePath, error)
+
+	// SendOnionMessage sends an onion message to one of our peers.
+	SendOnionMessage func(ctx context.Context, peer [33]byte,
+		pathKey *crypto.PublicKey, onion []byte) error
+
+	// DecryptBlindedHopData decrypts the recipient data of an onion
+	// message that arrived over one of our blinded reply paths.
+	DecryptBlindedHopData func(pathKey *crypto.PublicKey,
+		encData []byte) ([]byte, error)
+
+	// Clock is the time source used for offer and invoice timestamps.
+	Clock clock.Clock
+}
+
+// IssuedInvoice is sent to invoice subscribers for every invoice we send in
+// reply to an invoice request for one of our offers.
+type IssuedInvoice struct {
+	// OfferID is the ID of the offer the invoice was requested for.
+	OfferID [32]byte
+
+	// Invoice is the signed invoice sent to the payer.
+	Invoice *bolt12.Invoice
+}
+
+// OfferParams describes an offer to create.
+type OfferParams struct {
+	// Description is the offer_description. It is required when an amount
+	// is set.
+	Description string
+
+	// AmountMsat is the amount per item in msat. Zero means the payer
+	// chooses the amount.
+	AmountMsat lnwire.MilliLoki
+
+	// Issuer is an optional offer_issuer string.
+	Issuer string
+
+	// QuantityMax is the maximum quantity per invoice. Zero means the
+	// offer is for a single item.
+	QuantityMax uint64
+
+	// AbsoluteExpiry is the time after which the offer should no longer
+	// be used. The zero value means the offer does not expire.
+	AbsoluteExpiry time.Time
+}
+
+// Manager creates offers and answers the invoice requests made for them. An
+// invoice request arrives in an onion message, is matched to one of our offers
+// by its offer_id, and is answered with a signed invoice that pays to blinded
+// paths into our node, sent back along the request's reply path.
+type Manager struct {
+	started atomic.Bool
+	stopped atomic.Bool
+
+	cfg *Config
+
+	// requestSem bounds the number of invoice requests handled at once.
+	requestSem chan struct{}
+
+	// pendingRequests maps the path ID of the reply path of each invoice
+	// request we sent to the channel its answer is delivered on.
+	pendingRequests map[[32]byte]chan record.CustomSet
+	pendingMtx      sync.Mutex
+
+	// ntfnServer notifies subscribers of the invoices we issue for our
+	// offers.
+	ntfnServer *subscribe.Server
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewManager creates a new offers Manager.
+func NewManager(cfg *Config) *Manager {
+	return &Manager{
+		cfg:        cfg,
+		requestSem: make(chan struct{}, maxConcurrentRequests),
+		pendingRequests: make(
+			map[[32]byte]chan record.CustomSet,
+		),
+		ntfnServer: subscribe.NewServer(),
+		quit:       make(chan struct{}),
+	}
+}
+
+// Start subscribes to onion messages and starts answering invoice requests.
+func (m *Manager) Start() error {
+	log.Info("Offers manager starting...")
+
+	if m.started.Swap(true) {
+		return fmt.Errorf("offers manager started more than once")
+	}
+
+	if err := m.ntfnServer.Start(); err != nil {
+		return err
+	}
+
+	client, err := m.cfg.SubscribeOnionMessages()
+	if err != nil {
+		return fmt.Errorf("unable to subscribe to onion messages: %w",
+			err)
+	}
+
+	m.wg.Add(1)
+	go m.consumeOnionMessages(client)
+
+	log.Debug("Offers manager started")
+
+	return nil
+}
+
+// Stop stops answering invoice requests and waits for in-flight requests to
+// finish.
+func (m *Manager) Stop() error {
+	log.Info("Offers manager shutting down...")
+
+	if m.stopped.Swap(true) {
+		return fmt.Errorf("offers manager stopped more than once")
+	}
+
+	close(m.quit)
+	m.wg.Wait()
+
+	if err := m.ntfnServer.Stop(); err != nil {
+		return err
+	}
+
+	log.Debug("Offers manager shutdown complete")
+
+	return nil
+}
+
+// CreateOffer creates a new offer issued under our node key and persists it.
+func (m *Manager) CreateOffer(params *OfferParams) (*StoredOffer, error) {
+	if params.AmountMsat != 0 && params.Description == "" {
+		return nil, ErrMissingDescription
+	}
+
+	// Random metadata keeps the offer_id of otherwise identical offers
+	// apart, so each offer can be disabled on its own.
+	metadata := make([]byte, offerMetadataLen)
+	if _, err := rand.Read(metadata); err != nil {
+		return nil, err
+	}
+
+	offer := &bolt12.Offer{
+		OfferMetadata: tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType4](
+				tlv.Blob(metadata),
+			),
+		),
+		OfferIssuerID: tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType22](m.cfg.NodeKey),
+		),
+	}
+
+	// Offers are only valid on mainnet unless they list their chains.
+	if m.cfg.ChainHash != *chaincfg.MainNetParams.GenesisHash {
+		offer.OfferChains = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType2](bolt12.ChainsRecord{
+				Chains: [][32]byte{m.cfg.ChainHash},
+			}),
+		)
+	}
+
+	if params.Description != "" {
+		offer.OfferDescription = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType10](
+				tlv.Blob(params.Description),
+			),
+		)
+	}
+
+	if params.AmountMsat != 0 {
+		offer.OfferAmount = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType8](
+				bolt12.TUint64(params.AmountMsat),
+			),
+		)
+	}
+
+	if params.Issuer != "" {
+		offer.OfferIssuer = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType18](
+				tlv.Blob(params.Issuer),
+			),
+		)
+	}
+
+	if params.QuantityMax != 0 {
+		offer.OfferQuantityMax = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType20](
+				bolt12.TUint64(params.QuantityMax),
+			),
+		)
+	}
+
+	if !params.AbsoluteExpiry.IsZero() {
+		offer.OfferAbsoluteExpiry = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType14](
+				bolt12.TUint64(params.AbsoluteExpiry.Unix()),
+			),
+		)
+	}
+
+	if err := bolt12.ValidateOfferWrite(offer); err != nil {
+		return nil, err
+	}
+
+	id, err := offer.ID()
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &StoredOffer{
+		ID:        id,
+		Offer:     offer,
+		CreatedAt: m.cfg.Clock.Now(),
+	}
+	if err := m.cfg.Store.AddOffer(stored); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Created offer %x", id[:])
+
+	return stored, nil
+}
+
+// ListOffers returns all offers we have created.
+func (m *Manager) ListOffers() ([]*StoredOffer, error) {
+	return m.cfg.Store.ListOffers()
+}
+
+// LookupOffer returns the offer with the given offer ID.
+func (m *Manager) LookupOffer(id [32]byte) (*StoredOffer, error) {
+	return m.cfg.Store.FetchOffer(id)
+}
+
+// DisableOffer stops answering invoice requests for the given offer.
+func (m *Manager) DisableOffer(id [32]byte) error {
+	if err := m.cfg.Store.DisableOffer(id); err != nil {
+		return err
+	}
+
+	log.Infof("Disabled offer %x", id[:])
+
+	return nil
+}
+
+// SubscribeInvoices returns a subscribe.Client that receives an
+// *IssuedInvoice for every invoice we send in reply to an invoice request for
+// one of our offers, from the point of subscription onwards.
+func (m *Manager) SubscribeInvoices() (*subscribe.Client, error) {
+	return m.ntfnServer.Subscribe()
+}
+
+// consumeOnionMessages reads onion messages delivered to our node, answers
+// those that carry an invoice request and hands invoices sent in reply to our
+// own requests to the caller waiting for them.
+//
+// NOTE: This MUST be run as a goroutine.
+func (m *Manager) consumeOnionMessages(client *subscribe.Client) {
+	defer m.wg.Done()
+	defer client.Cancel()
+
+	for {
+		select {
+		case u := <-client.Updates():
+			update, ok := u.(*onionmessage.OnionMessageUpdate)
+			if !ok {
+				continue
+			}
+
+			if m.handleInvoiceReply(update) {
+				continue
+			}
+
+			invreq, ok := update.CustomRecords[uint64(
+				lnwire.InvoiceRequestNamespaceType,
+			)]
+			if !ok {
+				continue
+			}
+
+			if update.ReplyPath == nil {
+				log.Debugf("Ignoring invoice request from "+
+					"peer %x without reply path",
+					update.Peer)
+
+				continue
+			}
+
+			// Drop the request rather than queue it if we are
+			// already answering as many as we are willing to.
+			select {
+			case m.requestSem <- struct{}{}:
+			default:
+				log.Warnf("Dropping invoice request from "+
+					"peer %x: too many requests in flight",
+					update.Peer)
+
+				continue
+			}
+
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				defer func() { <-m.requestSem }()
+
+				m.handleInvoiceRequest(invreq, update.ReplyPath)
+			}()
+
+		case <-client.Quit():
+			log.Debugf("Onion message subscription closed")
+			return
+
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// handleInvoiceRequest answers a single invoice request along its reply path.
+func (m *Manager) handleInvoiceRequest(data []byte,
+	replyPath *lnwire.BlindedPath) {
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), DefaultHandlerTimeout,
+	)
+	defer cancel()
+
+	// Tie the request's lifetime to ours so shutdown is not held up by a
+	// slow path search or peer.
+	go func() {
+		select {
+		case <-m.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	issued, err := m.createInvoice(ctx, data)
+	if err != nil {
+		log.Warnf("Unable to answer invoice request: %v", err)
+		return
+	}
+	inv := issued.Invoice
+
+	invBytes, err := inv.Encode()
+	if err != nil {
+		log.Errorf("Unable to encode invoice: %v", err)
+		return
+	}
+
+	err = m.sendToBlindedPath(ctx, replyPath, []*lnwire.FinalHopTLV{{
+		TLVType: lnwire.InvoiceNamespaceType,
+		Value:   invBytes,
+	}})
+	if err != nil {
+		log.Warnf("Unable to send invoice along reply path: %v", err)
+		return
+	}
+
+	log.Debugf("Sent invoice with payment hash %x in reply to invoice "+
+		"request", inv.InvoicePaymentHash.ValOpt().UnwrapOr([32]byte{}))
+
+	if err := m.ntfnServer.SendUpdate(issued); err != nil {
+		log.Warnf("Unable to send issued invoice update: %v", err)
+	}
+}
+
+// createInvoice validates an encoded invoice request against our offers, adds
+// a matching invoice to the registry and returns the signed BOLT 12 invoice.
+func (m *Manager) createInvoice(ctx context.Context,
+	data []byte) (*IssuedInvoice, error) {
+
+	ir, err := bolt12.DecodeInvoiceRequest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bolt12.ValidateInvoiceRequestRead(
+		ir, m.cfg.ChainHash,
+	); err != nil {
+		return nil, err
+	}
+
+	offerID, err := ir.OfferID()
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := m.cfg.Store.FetchOffer(offerID)
+	if err != nil {
+		return nil, fmt.Errorf("offer %x: %w", offerID[:], err)
+	}
+
+	if stored.Disabled {
+		return nil, fmt.Errorf("offer %x: %w", offerID[:],
+			ErrOfferDisabled)
+	}
+
+	now := m.cfg.Clock.Now()
+	expired := fn.MapOptionZ(
+		stored.Offer.OfferAbsoluteExpiry.ValOpt(),
+		func(expiry bolt12.TUint64) bool {
+			return now.Unix() >= int64(expiry)
+		},
+	)
+	if expired {
+		return nil, fmt.Errorf("offer %x: %w", offerID[:],
+			ErrOfferExpired)
+	}
+
+	amt, err := invoiceAmount(ir)
+	if err != nil {
+		return nil, err
+	}
+
+	var preimage lntypes.Preimage
+	if _, err := rand.Read(preimage[:]); err != nil {
+		return nil, err
+	}
+	paymentHash := preimage.Hash()
+
+	// The payment address is never revealed. It is the path ID of our
+	// blinded paths, which the registry uses to match the payment.
+	var paymentAddr [32]byte
+	if _, err := rand.Read(paymentAddr[:]); err != nil {
+		return nil, err
+	}
+
+	paths, err := m.cfg.BuildBlindedPaths(
+		lnwire.MilliLoki(amt), paymentAddr[:], m.cfg.InvoiceExpiry,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to build blinded paths: %w",
+			err)
+	}
+
+	blindedPaths, payInfos, err := toBolt12Paths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := bolt12.NewInvoiceFromRequest(
+		ir, m.cfg.NodeKey, paymentHash, amt, now, blindedPaths,
+		payInfos,
+	)
+	if err != nil {
+		return nil, err
+	}
+	inv.InvoiceRelativeExpiry = tlv.SomeRecordT(
+		tlv.NewRecordT[tlv.TlvType166](
+			bolt12.TUint32(m.cfg.InvoiceExpiry.Seconds()),
+		),
+	)
+
+	if err := m.signInvoice(inv); err != nil {
+		return nil, err
+	}
+
+	// The invoice is stored without a payment request as the registry and
+	// RPC layer only understand BOLT 11 strings.
+	var memo []byte
+	stored.Offer.OfferDescription.WhenSome(
+		func(r tlv.RecordT[tlv.TlvType10, tlv.Blob]) {
+			memo = r.Val
+		},
+	)
+	if len(memo) > invoices.MaxMemoSize {
+		memo = memo[:invoices.MaxMemoSize]
+	}
+
+	_, err = m.cfg.AddInvoice(ctx, &invoices.Invoice{
+		CreationDate: now,
+		Memo:         memo,
+		Terms: invoices.ContractTerm{
+			FinalCltvDelta:  int32(m.cfg.MinFinalCLTVExpiryDelta),
+			Expiry:          m.cfg.InvoiceExpiry,
+			Value:           lnwire.MilliLoki(amt),
+			PaymentPreimage: &preimage,
+			PaymentAddr:     paymentAddr,
+			Features:        m.cfg.GenInvoiceFeatures(),
+		},
+	}, paymentHash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to add invoice: %w", err)
+	}
+
+	return &IssuedInvoice{
+		OfferID: offerID,
+		Invoice: inv,
+	}, nil
+}
+
+// signInvoice signs the invoice with our node key and checks the result.
+func (m *Manager) signInvoice(inv *bolt12.Invoice) error {
+	root, err := inv.MerkleRoot()
+	if err != nil {
+		return err
+	}
+
+	sig, err := m.cfg.SignMessageSchnorr(root[:], inv.SignatureTag())
+	if err != nil {
+		return fmt.Errorf("unable to sign invoice: %w", err)
+	}
+
+	var sigBytes [64]byte
+	copy(sigBytes[:], sig.Serialize())
+	inv.SetSignature(sigBytes)
+
+	return inv.VerifySignature()
+}
+
+// invoiceAmount returns the amount in msat the invoice for the request must
+// carry: invreq_amount if set, otherwise offer_amount times the quantity.
+func invoiceAmount(ir *bolt12.InvoiceRequest) (uint64, error) {
+	// Validation only checks invreq_amount against offer_amount for
+	// native amounts, so a currency offer cannot be priced here.
+	if ir.OfferCurrency.IsSome() {
+		return 0, ErrCurrencyUnsupported
+	}
+
+	if ir.InvreqAmount.IsSome() {
+		amt, err := ir.InvreqAmount.UnwrapOrErrV(ErrMissingAmount)
+		return uint64(amt), err
+	}
+
+	offerAmt, err := ir.OfferAmount.UnwrapOrErrV(ErrMissingAmount)
+	if err != nil {
+		return 0, err
+	}
+
+	var qty uint64 = 1
+	ir.InvreqQuantity.WhenSome(
+		func(r tlv.RecordT[tlv.TlvType86, bolt12.TUint64]) {
+			qty = uint64(r.Val)
+		},
+	)
+
+	hi, amt := bits.Mul64(uint64(offerAmt), qty)
+	if hi != 0 {
+		return 0, fmt.Errorf("offer_amount %d * quantity %d overflows",
+			offerAmt, qty)
+	}
+
+	return amt, nil
+}
@@ -0,0 +1,849 @@
+package offers
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/bolt12"
+	"github.com/flokiorg/flnd/clock"
+	"github.com/flokiorg/flnd/invoices"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/onionmessage"
+	"github.com/flokiorg/flnd/record"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/subscribe"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/flnd/zpay32"
+	"github.com/flokiorg/go-flokicoin/chaincfg"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr"
+	sphinx "github.com/flokiorg/lightning-onion"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// testTimeout is the time we wait for asynchronous events.
+	testTimeout = 5 * time.Second
+
+	// testOfferAmount is the amount of the offers created in tests.
+	testOfferAmount = lnwire.MilliLoki(50_000)
+)
+
+var (
+	// testChain is the chain the test manager operates on. It is not
+	// mainnet, so offers must list it in offer_chains.
+	testChain = *chaincfg.RegressionNetParams.GenesisHash
+
+	// testStartTime is the initial time of the test clock.
+	testStartTime = time.Unix(1_700_000_000, 0)
+)
+
+// sentMessage is an onion message captured by the test harness.
+type sentMessage struct {
+	peer    [33]byte
+	pathKey *crypto.PublicKey
+	onion   []byte
+}
+
+// testHarness wires a Manager to in-memory dependencies.
+type testHarness struct {
+	t *testing.T
+
+	mgr         *Manager
+	nodeKey     *crypto.PrivateKey
+	clock       *clock.TestClock
+	onionServer *subscribe.Server
+
+	// findPath is returned by the FindPath dependency. If nil, FindPath
+	// fails.
+	findPath onionmessage.OnionMessagePath
+
+	mu       sync.Mutex
+	invoices map[lntypes.Hash]*invoices.Invoice
+	pathIDs  [][]byte
+
+	sent chan *sentMessage
+}
+
+// newTestHarness creates a started Manager backed by a test store.
+func newTestHarness(t *testing.T) *testHarness {
+	t.Helper()
+
+	nodeKey, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+
+	h := &testHarness{
+		t:           t,
+		nodeKey:     nodeKey,
+		clock:       clock.NewTestClock(testStartTime),
+		onionServer: subscribe.NewServer(),
+		invoices:    make(map[lntypes.Hash]*invoices.Invoice),
+		sent:        make(chan *sentMessage, 1),
+	}
+
+	require.NoError(t, h.onionServer.Start())
+	t.Cleanup(func() {
+		require.NoError(t, h.onionServer.Stop())
+	})
+
+	h.mgr = NewManager(&Config{
+		NodeKey:   nodeKey.PubKey(),
+		ChainHash: testChain,
+		Store:     newTestStore(t),
+		SignMessageSchnorr: func(msg, tag []byte) (*schnorr.Signature,
+			error) {
+
+			return schnorr.Sign(
+				nodeKey, chainhash.TaggedHash(tag, msg)[:],
+			)
+		},
+		AddInvoice:        h.addInvoice,
+		BuildBlindedPaths: h.buildBlindedPaths,
+		GenInvoiceFeatures: func() *lnwire.FeatureVector {
+			return lnwire.EmptyFeatureVector()
+		},
+		MinFinalCLTVExpiryDelta: 80,
+		InvoiceExpiry:           bolt12.DefaultInvoiceExpiry,
+		SubscribeOnionMessages:  h.onionServer.Subscribe,
+		FindPath: func(context.Context,
+			route.Vertex) (onionmessage.OnionMessagePath, error) {
+
+			if h.findPath == nil {
+				return nil, onionmessage.ErrNoPathFound
+			}
+
+			return h.findPath, nil
+		},
+		SendOnionMessage: func(_ context.Context, peer [33]byte,
+			pathKey *crypto.PublicKey, onion []byte) error {
+
+			h.sent <- &sentMessage{
+				peer:    peer,
+				pathKey: pathKey,
+				onion:   onion,
+			}
+
+			return nil
+		},
+		DecryptBlindedHopData: func(pathKey *crypto.PublicKey,
+			encData []byte) ([]byte, error) {
+
+			router := sphinx.NewRouter(
+				&sphinx.PrivKeyECDH{PrivKey: nodeKey},
+				sphinx.NewNoOpReplayLog(),
+			)
+
+			return router.DecryptBlindedHopData(pathKey, encData)
+		},
+		Clock: h.clock,
+	})
+
+	require.NoError(t, h.mgr.Start())
+	t.Cleanup(func() {
+		require.NoError(t, h.mgr.Stop())
+	})
+
+	return h
+}
+
+// addInvoice records invoices added to the registry.
+func (h *testHarness) addInvoice(_ context.Context, inv *invoices.Invoice,
+	hash lntypes.Hash) (uint64, error) {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.invoices[hash] = inv
+
+	return uint64(len(h.invoices)), nil
+}
+
+// buildBlindedPaths returns a single one-hop blinded path to our node.
+func (h *testHarness) buildBlindedPaths(_ lnwire.MilliLoki, pathID []byte,
+	_ time.Duration) ([]*zpay32.BlindedPaymentPath, error) {
+
+	h.mu.Lock()
+	h.pathIDs = append(h.pathIDs, pathID)
+	h.mu.Unlock()
+
+	plainText, err := record.EncodeBlindedRouteData(
+		record.NewFinalHopBlindedRouteData(nil, pathID),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionKey, err := crypto.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := sphinx.BuildBlindedPath(sessionKey, []*sphinx.HopInfo{{
+		NodePub:   h.nodeKey.PubKey(),
+		PlainText: plainText,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	path.Path.BlindedHops[0].BlindedNodePub = path.Path.IntroductionPoint
+
+	return []*zpay32.BlindedPaymentPath{{
+		FeeBaseMsat:                 1000,
+		FeeRate:                     10,
+		CltvExpiryDelta:             80,
+		HTLCMinMsat:                 1,
+		HTLCMaxMsat:                 1_000_000_000,
+		Features:                    lnwire.EmptyFeatureVector(),
+		FirstEphemeralBlindingPoint: path.Path.BlindingPoint,
+		Hops:                        path.Path.BlindedHops,
+	}}, nil
+}
+
+// createOffer creates an offer for testOfferAmount.
+func (h *testHarness) createOffer() *StoredOffer {
+	h.t.Helper()
+
+	offer, err := h.mgr.CreateOffer(&OfferParams{
+		Description: "coffee",
+		AmountMsat:  testOfferAmount,
+	})
+	require.NoError(h.t, err)
+
+	return offer
+}
+
+// receiveMessage waits for the manager to send an onion message.
+func (h *testHarness) receiveMessage() *sentMessage {
+	h.t.Helper()
+
+	select {
+	case msg := <-h.sent:
+		return msg
+
+	case <-time.After(testTimeout):
+		h.t.Fatalf("no onion message sent")
+		return nil
+	}
+}
+
+// newInvoiceRequest builds a signed invoice request for the offer.
+func newInvoiceRequest(t *testing.T, offer *bolt12.Offer) []byte {
+	t.Helper()
+
+	payerKey, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+
+	ir, err := bolt12.NewInvoiceRequestFromOffer(
+		offer, payerKey.PubKey(), []byte("payer-metadata"), testChain,
+	)
+	require.NoError(t, err)
+	require.NoError(t, ir.Sign(payerKey))
+
+	data, err := ir.Encode()
+	require.NoError(t, err)
+
+	return data
+}
+
+// newReplyPath builds a one-hop blinded reply path to the given node.
+func newReplyPath(t *testing.T, node *crypto.PrivateKey) *lnwire.BlindedPath {
+	t.Helper()
+
+	path := onionmessage.BuildBlindedPath(t, []*sphinx.HopInfo{{
+		NodePub: node.PubKey(),
+		PlainText: onionmessage.EncodeBlindedRouteData(
+			t, record.NewFinalHopBlindedRouteData(
+				nil, []byte("reply-path-id"),
+			),
+		),
+	}})
+
+	intro, err := lnwire.NewPubkeyIntro(node.PubKey())
+	require.NoError(t, err)
+
+	hops := make([]lnwire.BlindedHop, 0, len(path.Path.BlindedHops))
+	for _, hop := range path.Path.BlindedHops {
+		hops = append(hops, lnwire.BlindedHop{
+			BlindedNodeID: hop.BlindedNodePub,
+			EncryptedData: hop.CipherText,
+		})
+	}
+
+	return &lnwire.BlindedPath{
+		IntroductionNode: intro,
+		BlindingPoint:    path.Path.BlindingPoint,
+		Hops:             hops,
+	}
+}
+
+// receivedInvoice peels a sent onion message with the given hop keys and
+// decodes the invoice delivered to the final hop.
+func receivedInvoice(t *testing.T, msg *sentMessage,
+	hopKeys []*crypto.PrivateKey) *bolt12.Invoice {
+
+	t.Helper()
+
+	hops := onionmessage.PeelOnionLayers(
+		t, hopKeys, lnwire.NewOnionMessage(msg.pathKey, msg.onion),
+	)
+	require.Len(t, hops, len(hopKeys))
+
+	final := hops[len(hops)-1]
+	require.True(t, final.IsFinal)
+	require.Len(t, final.Payload.FinalHopTLVs, 1)
+	require.Equal(
+		t, lnwire.InvoiceNamespaceType,
+		final.Payload.FinalHopTLVs[0].TLVType,
+	)
+
+	inv, err := bolt12.DecodeInvoice(final.Payload.FinalHopTLVs[0].Value)
+	require.NoError(t, err)
+
+	return inv
+}
+
+// TestManagerAnswersInvoiceRequest verifies the full flow: an invoice request
+// delivered in an onion message is answered with a valid invoice sent along
+// the reply path, backed by a matching invoice in the registry.
+func TestManagerAnswersInvoiceRequest(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	offer := h.createOffer()
+
+	payerNode, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+
+	sub, err := h.mgr.SubscribeInvoices()
+	require.NoError(t, err)
+	defer sub.Cancel()
+
+	invReqType := uint64(lnwire.InvoiceRequestNamespaceType)
+	err = h.onionServer.SendUpdate(&onionmessage.OnionMessageUpdate{
+		CustomRecords: record.CustomSet{
+			invReqType: newInvoiceRequest(t, offer.Offer),
+		},
+		ReplyPath: newReplyPath(t, payerNode),
+	})
+	require.NoError(t, err)
+
+	msg := h.receiveMessage()
+	require.Equal(t, route.NewVertex(payerNode.PubKey()), route.Vertex(
+		msg.peer,
+	))
+
+	inv := receivedInvoice(t, msg, []*crypto.PrivateKey{payerNode})
+	require.NoError(t, bolt12.ValidateInvoiceRead(
+		inv, testStartTime, testChain,
+	))
+
+	invAmount, err := inv.InvoiceAmount.UnwrapOrErrV(ErrMissingAmount)
+	require.NoError(t, err)
+	require.EqualValues(t, testOfferAmount, invAmount)
+
+	// Subscribers are told about the invoice once it has been sent.
+	select {
+	case update := <-sub.Updates():
+		issued, ok := update.(*IssuedInvoice)
+		require.True(t, ok)
+		require.Equal(t, offer.ID, issued.OfferID)
+
+		issuedBytes, err := issued.Invoice.Encode()
+		require.NoError(t, err)
+		invBytes, err := inv.Encode()
+		require.NoError(t, err)
+		require.Equal(t, invBytes, issuedBytes)
+
+	case <-time.After(testTimeout):
+		t.Fatal("no issued invoice update")
+	}
+
+	// The registry invoice must be keyed by the invoice's payment hash and
+	// use the blinded path ID as payment address.
+	hash, err := inv.InvoicePaymentHash.UnwrapOrErrV(
+		bolt12.ErrMissingPaymentHash,
+	)
+	require.NoError(t, err)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	added, ok := h.invoices[lntypes.Hash(hash)]
+	require.True(t, ok)
+	require.Equal(t, testOfferAmount, added.Terms.Value)
+	require.Equal(t, lntypes.Hash(hash), added.Terms.PaymentPreimage.Hash())
+	require.Len(t, h.pathIDs, 1)
+	require.Equal(t, h.pathIDs[0], added.Terms.PaymentAddr[:])
+	require.Equal(t, []byte("coffee"), added.Memo)
+}
+
+// TestManagerRoutesReplyOverGraph verifies that a reply path whose
+// introduction node is not a peer is reached through a blinded prefix over the
+// graph.
+func TestManagerRoutesReplyOverGraph(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	offer := h.createOffer()
+
+	relay, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+	payerNode, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+
+	h.findPath = onionmessage.OnionMessagePath{
+		route.NewVertex(relay.PubKey()),
+		route.NewVertex(payerNode.PubKey()),
+	}
+
+	issued, err := h.mgr.createInvoice(
+		context.Background(), newInvoiceRequest(t, offer.Offer),
+	)
+	require.NoError(t, err)
+
+	invBytes, err := issued.Invoice.Encode()
+	require.NoError(t, err)
+
+	err = h.mgr.sendToBlindedPath(
+		context.Background(), newReplyPath(t, payerNode),
+		[]*lnwire.FinalHopTLV{{
+			TLVType: lnwire.InvoiceNamespaceType,
+			Value:   invBytes,
+		}},
+	)
+	require.NoError(t, err)
+
+	msg := h.receiveMessage()
+	require.Equal(t, route.NewVertex(relay.PubKey()), route.Vertex(
+		msg.peer,
+	))
+
+	received := receivedInvoice(
+		t, msg, []*crypto.PrivateKey{relay, payerNode},
+	)
+	require.NoError(t, received.VerifySignature())
+}
+
+// TestManagerRejectsInvoiceRequests verifies that invoice requests that do not
+// match a usable offer of ours are not answered.
+func TestManagerRejectsInvoiceRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+
+		// setup returns the encoded invoice request to answer.
+		setup func(t *testing.T, h *testHarness) []byte
+
+		expectedErr error
+	}{
+		{
+			name: "unknown offer",
+			setup: func(t *testing.T, h *testHarness) []byte {
+				unknown := newStoredOffer(t, "unknown")
+				unknown.Offer.OfferAmount = tlv.SomeRecordT(
+					tlv.NewRecordT[tlv.TlvType8](
+						bolt12.TUint64(testOfferAmount),
+					),
+				)
+				unknown.Offer.OfferChains = tlv.SomeRecordT(
+					tlv.NewRecordT[tlv.TlvType2](
+						bolt12.ChainsRecord{
+							Chains: [][32]byte{
+								testChain,
+							},
+						},
+					),
+				)
+
+				return newInvoiceRequest(t, unknown.Offer)
+			},
+			expectedErr: ErrOfferNotFound,
+		},
+		{
+			name: "disabled offer",
+			setup: func(t *testing.T, h *testHarness) []byte {
+				offer := h.createOffer()
+				require.NoError(t, h.mgr.DisableOffer(offer.ID))
+
+				return newInvoiceRequest(t, offer.Offer)
+			},
+			expectedErr: ErrOfferDisabled,
+		},
+		{
+			name: "expired offer",
+			setup: func(t *testing.T, h *testHarness) []byte {
+				expiry := testStartTime.Add(time.Hour)
+				offer, err := h.mgr.CreateOffer(&OfferParams{
+					Description:    "coffee",
+					AmountMsat:     testOfferAmount,
+					AbsoluteExpiry: expiry,
+				})
+				require.NoError(t, err)
+
+				h.clock.SetTime(expiry.Add(time.Hour))
+
+				return newInvoiceRequest(t, offer.Offer)
+			},
+			expectedErr: ErrOfferExpired,
+		},
+		{
+			name: "invalid signature",
+			setup: func(t *testing.T, h *testHarness) []byte {
+				offer := h.createOffer()
+
+				payerKey, err := crypto.NewPrivateKey()
+				require.NoError(t, err)
+
+				ir, err := bolt12.NewInvoiceRequestFromOffer(
+					offer.Offer, payerKey.PubKey(),
+					[]byte("payer-metadata"), testChain,
+				)
+				require.NoError(t, err)
+				ir.Signature = tlv.SomeRecordT(
+					tlv.NewPrimitiveRecord[tlv.TlvType240](
+						[64]byte{1},
+					),
+				)
+
+				data, err := ir.Encode()
+				require.NoError(t, err)
+
+				return data
+			},
+			expectedErr: bolt12.ErrInvalidSignature,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := newTestHarness(t)
+			data := tc.setup(t, h)
+
+			ctx := context.Background()
+			_, err := h.mgr.createInvoice(ctx, data)
+			require.ErrorIs(t, err, tc.expectedErr)
+
+			h.mu.Lock()
+			require.Empty(t, h.invoices)
+			h.mu.Unlock()
+		})
+	}
+}
+
+// TestInvoiceAmount verifies how the invoice amount is derived from an invoice
+// request.
+func TestInvoiceAmount(t *testing.T) {
+	t.Parallel()
+
+	amount := func(v uint64) tlv.OptionalRecordT[tlv.TlvType8,
+		bolt12.TUint64] {
+
+		return tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType8](bolt12.TUint64(v)),
+		)
+	}
+
+	tests := []struct {
+		name        string
+		ir          *bolt12.InvoiceRequest
+		expectedAmt uint64
+		expectedErr error
+	}{
+		{
+			name: "invreq amount takes precedence",
+			ir: &bolt12.InvoiceRequest{
+				OfferAmount: amount(1000),
+				InvreqAmount: tlv.SomeRecordT(
+					tlv.NewRecordT[tlv.TlvType82](
+						bolt12.TUint64(1500),
+					),
+				),
+			},
+			expectedAmt: 1500,
+		},
+		{
+			name: "offer amount times quantity",
+			ir: &bolt12.InvoiceRequest{
+				OfferAmount: amount(1000),
+				InvreqQuantity: tlv.SomeRecordT(
+					tlv.NewRecordT[tlv.TlvType86](
+						bolt12.TUint64(3),
+					),
+				),
+			},
+			expectedAmt: 3000,
+		},
+		{
+			name: "currency offer",
+			ir: &bolt12.InvoiceRequest{
+				OfferAmount: amount(1000),
+				OfferCurrency: tlv.SomeRecordT(
+					tlv.NewPrimitiveRecord[tlv.TlvType6](
+						tlv.Blob("USD"),
+					),
+				),
+			},
+			expectedErr: ErrCurrencyUnsupported,
+		},
+		{
+			name:        "no amount",
+			ir:          &bolt12.InvoiceRequest{},
+			expectedErr: ErrMissingAmount,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			amt, err := invoiceAmount(tc.ir)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedAmt, amt)
+		})
+	}
+}
+
+// fetchResult is the outcome of a FetchInvoice call.
+type fetchResult struct {
+	inv *bolt12.Invoice
+	err error
+}
+
+// fetchInvoice calls FetchInvoice on the payer in the background and returns
+// the channel the result is delivered on together with the payload of the
+// invoice request it sent to the issuer.
+func fetchInvoice(t *testing.T, payer, issuer *testHarness,
+	params *FetchInvoiceParams) (<-chan *fetchResult,
+	*lnwire.OnionMessagePayload) {
+
+	t.Helper()
+
+	results := make(chan *fetchResult, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(
+			context.Background(), testTimeout,
+		)
+		defer cancel()
+
+		inv, err := payer.mgr.FetchInvoice(ctx, params)
+		results <- &fetchResult{inv: inv, err: err}
+	}()
+
+	// The issuer is reached directly, so it is also the final hop of the
+	// request's onion.
+	msg := payer.receiveMessage()
+	require.Equal(t, route.NewVertex(issuer.nodeKey.PubKey()),
+		route.Vertex(msg.peer))
+
+	hops := onionmessage.PeelOnionLayers(
+		t, []*crypto.PrivateKey{issuer.nodeKey},
+		lnwire.NewOnionMessage(msg.pathKey, msg.onion),
+	)
+	require.True(t, hops[0].IsFinal)
+	require.Len(t, hops[0].Payload.FinalHopTLVs, 1)
+	require.NotNil(t, hops[0].Payload.ReplyPath)
+
+	return results, hops[0].Payload
+}
+
+// deliverRequest hands an invoice request to the issuer as an onion message.
+func deliverRequest(t *testing.T, issuer *testHarness,
+	payload *lnwire.OnionMessagePayload) {
+
+	t.Helper()
+
+	err := issuer.onionServer.SendUpdate(&onionmessage.OnionMessageUpdate{
+		CustomRecords: record.CustomSet{
+			uint64(payload.FinalHopTLVs[0].TLVType): payload.
+				FinalHopTLVs[0].Value,
+		},
+		ReplyPath: payload.ReplyPath,
+	})
+	require.NoError(t, err)
+}
+
+// deliverReply peels the issuer's reply and hands it to the payer as an onion
+// message delivered over the payer's reply path.
+func deliverReply(t *testing.T, payer *testHarness, msg *sentMessage) {
+	t.Helper()
+
+	hops := onionmessage.PeelOnionLayers(
+		t, []*crypto.PrivateKey{payer.nodeKey},
+		lnwire.NewOnionMessage(msg.pathKey, msg.onion),
+	)
+	final := hops[0]
+	require.True(t, final.IsFinal)
+
+	customRecords := make(record.CustomSet)
+	for _, tlvRecord := range final.Payload.FinalHopTLVs {
+		customRecords[uint64(tlvRecord.TLVType)] = tlvRecord.Value
+	}
+
+	var pathKey [33]byte
+	copy(pathKey[:], msg.pathKey.SerializeCompressed())
+
+	err := payer.onionServer.SendUpdate(&onionmessage.OnionMessageUpdate{
+		Peer:                   msg.peer,
+		PathKey:                pathKey,
+		CustomRecords:          customRecords,
+		EncryptedRecipientData: final.EncryptedData,
+	})
+	require.NoError(t, err)
+}
+
+// receiveResult waits for the result of a FetchInvoice call.
+func receiveResult(t *testing.T, results <-chan *fetchResult) *fetchResult {
+	t.Helper()
+
+	select {
+	case result := <-results:
+		return result
+
+	case <-time.After(testTimeout):
+		t.Fatalf("no FetchInvoice result")
+		return nil
+	}
+}
+
+// TestManagerFetchInvoice verifies that an invoice fetched from another
+// manager is matched to our request and can be converted to payment paths.
+func TestManagerFetchInvoice(t *testing.T) {
+	t.Parallel()
+
+	issuer := newTestHarness(t)
+	payer := newTestHarness(t)
+	offer := issuer.createOffer()
+
+	results, request := fetchInvoice(
+		t, payer, issuer, &FetchInvoiceParams{
+			Offer:     offer.Offer,
+			PayerNote: "thanks",
+		},
+	)
+	deliverRequest(t, issuer, request)
+
+	// The issuer replies to the payer node directly, as it is the peer
+	// the request came from.
+	reply := issuer.receiveMessage()
+	require.Equal(t, route.NewVertex(payer.nodeKey.PubKey()),
+		route.Vertex(reply.peer))
+
+	deliverReply(t, payer, reply)
+
+	result := receiveResult(t, results)
+	require.NoError(t, result.err)
+
+	inv := result.inv
+	invAmount, err := inv.InvoiceAmount.UnwrapOrErrV(ErrMissingAmount)
+	require.NoError(t, err)
+	require.EqualValues(t, testOfferAmount, invAmount)
+
+	note, err := inv.InvreqPayerNote.UnwrapOrErrV(ErrMissingAmount)
+	require.NoError(t, err)
+	require.Equal(t, "thanks", string(note))
+
+	paths, err := PaymentPaths(inv)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.True(t, paths[0].Hops[0].BlindedNodePub.IsEqual(
+		issuer.nodeKey.PubKey(),
+	))
+	require.EqualValues(t, 1000, paths[0].FeeBaseMsat)
+}
+
+// TestManagerFetchInvoiceErrors verifies that FetchInvoice fails on an
+// invoice_error reply, on an invoice that is not for our request and on a
+// reply that never arrives.
+func TestManagerFetchInvoiceErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invoice error", func(t *testing.T) {
+		t.Parallel()
+
+		issuer := newTestHarness(t)
+		payer := newTestHarness(t)
+		offer := issuer.createOffer()
+
+		results, request := fetchInvoice(
+			t, payer, issuer, &FetchInvoiceParams{
+				Offer: offer.Offer,
+			},
+		)
+
+		// Answer with an invoice_error that only carries the error
+		// string.
+		var errMsg bytes.Buffer
+		msg := []byte("out of stock")
+		stream, err := tlv.NewStream(tlv.MakePrimitiveRecord(
+			invoiceErrorMessageType, &msg,
+		))
+		require.NoError(t, err)
+		require.NoError(t, stream.Encode(&errMsg))
+
+		err = issuer.mgr.sendToBlindedPath(
+			context.Background(), request.ReplyPath,
+			[]*lnwire.FinalHopTLV{{
+				TLVType: lnwire.InvoiceErrorNamespaceType,
+				Value:   errMsg.Bytes(),
+			}},
+		)
+		require.NoError(t, err)
+
+		deliverReply(t, payer, issuer.receiveMessage())
+
+		result := receiveResult(t, results)
+		require.ErrorIs(t, result.err, ErrInvoiceRequestRejected)
+		require.ErrorContains(t, result.err, "out of stock")
+	})
+
+	t.Run("unknown path id", func(t *testing.T) {
+		t.Parallel()
+
+		payer := newTestHarness(t)
+
+		// A reply over a path we did not create is dropped rather
+		// than handed to a pending request.
+		invType := uint64(lnwire.InvoiceNamespaceType)
+		require.True(t, payer.mgr.handleInvoiceReply(
+			&onionmessage.OnionMessageUpdate{
+				CustomRecords: record.CustomSet{invType: nil},
+			},
+		))
+		require.False(t, payer.mgr.handleInvoiceReply(
+			&onionmessage.OnionMessageUpdate{},
+		))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		issuer := newTestHarness(t)
+		payer := newTestHarness(t)
+		offer := issuer.createOffer()
+
+		ctx, cancel := context.WithTimeout(
+			context.Background(), 100*time.Millisecond,
+		)
+		defer cancel()
+
+		_, err := payer.mgr.FetchInvoice(ctx, &FetchInvoiceParams{
+			Offer: offer.Offer,
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		payer.receiveMessage()
+	})
+}
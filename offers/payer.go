@@ -0,0 +1,468 @@
+package offers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/bolt12"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/onionmessage"
+	"github.com/flokiorg/flnd/record"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/flnd/zpay32"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	sphinx "github.com/flokiorg/lightning-onion"
+)
+
+const (
+	// payerMetadataLen is the length of the random invreq_metadata we set
+	// on the invoice requests we send.
+	payerMetadataLen = 16
+
+	// invoiceErrorMessageType is the TLV type of the error string in an
+	// invoice_error message.
+	invoiceErrorMessageType tlv.Type = 5
+)
+
+var (
+	// ErrShuttingDown is returned when the manager is stopped while
+	// waiting for an invoice.
+	ErrShuttingDown = errors.New("offers manager shutting down")
+
+	// ErrInvoiceRequestRejected is returned when the offer issuer answers
+	// our invoice request with an invoice_error.
+	ErrInvoiceRequestRejected = errors.New("invoice request rejected")
+
+	// ErrUnexpectedInvoiceAmount is returned when the invoice we receive
+	// asks for a different amount than we requested.
+	ErrUnexpectedInvoiceAmount = errors.New("invoice amount does not " +
+		"match the requested amount")
+)
+
+// FetchInvoiceParams describes an invoice request to send for an offer.
+type FetchInvoiceParams struct {
+	// Offer is the offer to request an invoice for.
+	Offer *bolt12.Offer
+
+	// AmountMsat is the amount to pay in msat. It is required when the
+	// offer has no amount and may otherwise be set to pay more than the
+	// offer asks for.
+	AmountMsat lnwire.MilliLoki
+
+	// Quantity is the number of items to pay for. It must be set if and
+	// only if the offer has an offer_quantity_max.
+	Quantity uint64
+
+	// PayerNote is an optional note for the recipient.
+	PayerNote string
+}
+
+// FetchInvoice sends an invoice request for the given offer under a fresh
+// payer key and waits for the invoice that answers it. The invoice is only
+// returned once it has been validated against the request, so it can be paid
+// as is. The caller bounds the wait through the context.
+func (m *Manager) FetchInvoice(ctx context.Context,
+	params *FetchInvoiceParams) (*bolt12.Invoice, error) {
+
+	offer := params.Offer
+	err := bolt12.ValidateOfferRead(
+		offer, m.cfg.Clock.Now(), m.cfg.ChainHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ir, err := m.newInvoiceRequest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	amt, err := invoiceAmount(ir)
+	if err != nil {
+		return nil, err
+	}
+
+	irBytes, err := ir.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	// The path ID of our reply path ties the answer to this request. It is
+	// only known to us, so nobody else can answer in the issuer's stead.
+	var pathID [32]byte
+	if _, err := rand.Read(pathID[:]); err != nil {
+		return nil, err
+	}
+
+	replies := make(chan record.CustomSet, 1)
+	m.pendingMtx.Lock()
+	m.pendingRequests[pathID] = replies
+	m.pendingMtx.Unlock()
+
+	defer func() {
+		m.pendingMtx.Lock()
+		delete(m.pendingRequests, pathID)
+		m.pendingMtx.Unlock()
+	}()
+
+	err = m.sendInvoiceRequest(ctx, offer, irBytes, pathID[:])
+	if err != nil {
+		return nil, fmt.Errorf("unable to send invoice request: %w",
+			err)
+	}
+
+	var reply record.CustomSet
+	select {
+	case reply = <-replies:
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case <-m.quit:
+		return nil, ErrShuttingDown
+	}
+
+	if errData, ok := reply[uint64(lnwire.InvoiceErrorNamespaceType)]; ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvoiceRequestRejected,
+			decodeInvoiceError(errData))
+	}
+
+	inv, err := bolt12.DecodeInvoice(
+		reply[uint64(lnwire.InvoiceNamespaceType)],
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = bolt12.ValidateInvoiceRead(
+		inv, m.cfg.Clock.Now(), m.cfg.ChainHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bolt12.CheckInvoiceMatchesRequest(inv, ir); err != nil {
+		return nil, err
+	}
+
+	invAmt, err := inv.InvoiceAmount.UnwrapOrErrV(
+		bolt12.ErrMissingInvoiceAmount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(invAmt) != amt {
+		return nil, fmt.Errorf("%w: got %d msat, expected %d msat",
+			ErrUnexpectedInvoiceAmount, invAmt, amt)
+	}
+
+	return inv, nil
+}
+
+// newInvoiceRequest builds and signs the invoice request for the given
+// parameters under a fresh payer key.
+func (m *Manager) newInvoiceRequest(
+	params *FetchInvoiceParams) (*bolt12.InvoiceRequest, error) {
+
+	// A fresh payer key and metadata per request keep our requests from
+	// being linked to each other or to our node.
+	payerKey, err := crypto.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make([]byte, payerMetadataLen)
+	if _, err := rand.Read(metadata); err != nil {
+		return nil, err
+	}
+
+	ir, err := bolt12.NewInvoiceRequestFromOffer(
+		params.Offer, payerKey.PubKey(), metadata, m.cfg.ChainHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.AmountMsat != 0 {
+		ir.InvreqAmount = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType82](
+				bolt12.TUint64(params.AmountMsat),
+			),
+		)
+	}
+
+	if params.Quantity != 0 {
+		ir.InvreqQuantity = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType86](
+				bolt12.TUint64(params.Quantity),
+			),
+		)
+	}
+
+	if params.PayerNote != "" {
+		ir.InvreqPayerNote = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType89](
+				tlv.Blob(params.PayerNote),
+			),
+		)
+	}
+
+	if err := ir.Sign(payerKey); err != nil {
+		return nil, err
+	}
+
+	if err := bolt12.ValidateInvoiceRequestWrite(ir); err != nil {
+		return nil, err
+	}
+
+	return ir, nil
+}
+
+// sendInvoiceRequest sends the encoded invoice request to the offer issuer
+// with a reply path to our node that carries the given path ID. Offers with
+// blinded paths are reached through the first path we can route to, others
+// through their issuer key.
+func (m *Manager) sendInvoiceRequest(ctx context.Context, offer *bolt12.Offer,
+	data, pathID []byte) error {
+
+	finalHopTLVs := []*lnwire.FinalHopTLV{{
+		TLVType: lnwire.InvoiceRequestNamespaceType,
+		Value:   data,
+	}}
+
+	ourNode := route.NewVertex(m.cfg.NodeKey)
+
+	send := func(path *sphinx.BlindedPath, firstHop route.Vertex,
+		firstHopIsIssuer bool) error {
+
+		// Replies come back through the peer we hand the request to,
+		// unless that peer is the issuer itself and can reach us
+		// directly.
+		replyIntro := firstHop
+		if firstHopIsIssuer {
+			replyIntro = ourNode
+		}
+
+		replyPath, err := m.buildReplyPath(replyIntro, pathID)
+		if err != nil {
+			return err
+		}
+
+		return m.sendOnion(ctx, path, firstHop, replyPath, finalHopTLVs)
+	}
+
+	if !offer.OfferPaths.IsSome() {
+		issuerID, err := offer.OfferIssuerID.UnwrapOrErrV(
+			bolt12.ErrNoIssuerIdentity,
+		)
+		if err != nil {
+			return err
+		}
+
+		issuer := route.NewVertex(issuerID)
+		path, firstHop, err := m.routeToNode(ctx, issuer)
+		if err != nil {
+			return err
+		}
+
+		return send(path, firstHop, firstHop == issuer)
+	}
+
+	offerPaths := offer.OfferPaths.UnsafeFromSome().Val
+	var errs []error
+	for _, offerPath := range offerPaths.Paths {
+		path, firstHop, err := m.routeToBlindedPath(ctx, &offerPath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		// A single hop path ends at its introduction node.
+		intro, ok := offerPath.IntroductionNode.(lnwire.PubkeyIntro)
+		firstHopIsIssuer := ok && len(offerPath.Hops) == 1 &&
+			firstHop == route.NewVertex(intro.Pubkey)
+
+		err = send(path, firstHop, firstHopIsIssuer)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		return nil
+	}
+
+	return errors.Join(errs...)
+}
+
+// handleInvoiceReply hands an invoice or invoice_error delivered over one of
+// our reply paths to the request waiting for it. It returns false if the
+// message carries neither.
+func (m *Manager) handleInvoiceReply(
+	update *onionmessage.OnionMessageUpdate) bool {
+
+	_, isInvoice := update.CustomRecords[uint64(
+		lnwire.InvoiceNamespaceType,
+	)]
+	_, isInvoiceErr := update.CustomRecords[uint64(
+		lnwire.InvoiceErrorNamespaceType,
+	)]
+	if !isInvoice && !isInvoiceErr {
+		return false
+	}
+
+	pathID, err := m.replyPathID(update)
+	if err != nil {
+		log.Debugf("Ignoring invoice reply from peer %x: %v",
+			update.Peer, err)
+
+		return true
+	}
+
+	m.pendingMtx.Lock()
+	replies, ok := m.pendingRequests[pathID]
+	m.pendingMtx.Unlock()
+
+	if !ok {
+		log.Debugf("Ignoring invoice reply from peer %x for unknown "+
+			"request", update.Peer)
+
+		return true
+	}
+
+	// Only the first reply is used, any duplicates are dropped.
+	select {
+	case replies <- update.CustomRecords:
+	default:
+	}
+
+	return true
+}
+
+// replyPathID decrypts the recipient data of an onion message delivered to us
+// and returns the path ID of the reply path it arrived over.
+func (m *Manager) replyPathID(
+	update *onionmessage.OnionMessageUpdate) ([32]byte, error) {
+
+	var pathID [32]byte
+
+	pathKey, err := crypto.ParsePubKey(update.PathKey[:])
+	if err != nil {
+		return pathID, err
+	}
+
+	plainText, err := m.cfg.DecryptBlindedHopData(
+		pathKey, update.EncryptedRecipientData,
+	)
+	if err != nil {
+		return pathID, err
+	}
+
+	routeData, err := record.DecodeBlindedRouteData(
+		bytes.NewReader(plainText),
+	)
+	if err != nil {
+		return pathID, err
+	}
+
+	id, err := routeData.PathID.UnwrapOrErrV(
+		errors.New("missing path ID"),
+	)
+	if err != nil {
+		return pathID, err
+	}
+	if len(id) != len(pathID) {
+		return pathID, fmt.Errorf("invalid path ID length %d", len(id))
+	}
+	copy(pathID[:], id)
+
+	return pathID, nil
+}
+
+// decodeInvoiceError returns the error string of an invoice_error message, or
+// a placeholder if it has none.
+func decodeInvoiceError(data []byte) string {
+	var msg []byte
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(invoiceErrorMessageType, &msg),
+	)
+	if err != nil {
+		return "unknown error"
+	}
+
+	if err := stream.Decode(bytes.NewReader(data)); err != nil ||
+		len(msg) == 0 {
+
+		return "unknown error"
+	}
+
+	return string(msg)
+}
+
+// PaymentPaths converts the invoice_paths and invoice_blindedpay fields of a
+// BOLT 12 invoice into blinded payment paths the router can pay to. As with
+// BOLT 11 invoices, the first hop of each path carries the real introduction
+// node key.
+func PaymentPaths(
+	inv *bolt12.Invoice) ([]*zpay32.BlindedPaymentPath, error) {
+
+	blindedPaths, err := inv.InvoicePaths.UnwrapOrErrV(
+		bolt12.ErrMissingInvoicePaths,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	payInfos, err := inv.InvoiceBlindedPay.UnwrapOrErrV(
+		bolt12.ErrBlindedPayMismatch,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(payInfos.PayInfos) != len(blindedPaths.Paths) {
+		return nil, bolt12.ErrBlindedPayMismatch
+	}
+
+	paths := make([]*zpay32.BlindedPaymentPath, 0, len(blindedPaths.Paths))
+	for i, blindedPath := range blindedPaths.Paths {
+		intro, ok := blindedPath.IntroductionNode.(lnwire.PubkeyIntro)
+		if !ok {
+			return nil, ErrUnsupportedIntroNode
+		}
+		if len(blindedPath.Hops) == 0 {
+			return nil, lnwire.ErrEmptyBlindedPath
+		}
+
+		hops := make([]*sphinx.BlindedHopInfo, 0, len(blindedPath.Hops))
+		for j, hop := range blindedPath.Hops {
+			nodePub := hop.BlindedNodeID
+			if j == 0 {
+				nodePub = intro.Pubkey
+			}
+
+			hops = append(hops, &sphinx.BlindedHopInfo{
+				BlindedNodePub: nodePub,
+				CipherText:     hop.EncryptedData,
+			})
+		}
+
+		payInfo := payInfos.PayInfos[i]
+		paths = append(paths, &zpay32.BlindedPaymentPath{
+			FeeBaseMsat:     payInfo.FeeBaseMsat,
+			FeeRate:         payInfo.FeeProportionalMillionths,
+			CltvExpiryDelta: payInfo.CltvExpiryDelta,
+			HTLCMinMsat:     payInfo.HtlcMinimumMsat,
+			HTLCMaxMsat:     payInfo.HtlcMaximumMsat,
+			Features: lnwire.NewFeatureVector(
+				payInfo.Features.Clone(), lnwire.Features,
+			),
+			FirstEphemeralBlindingPoint: blindedPath.BlindingPoint,
+			Hops:                        hops,
+		})
+	}
+
+	return paths, nil
+}
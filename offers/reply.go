@@ -0,0 +1,409 @@
+package offers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/bolt12"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/record"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/zpay32"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	sphinx "github.com/flokiorg/lightning-onion"
+)
+
+var (
+	// ErrUnsupportedIntroNode is returned when a reply path names its
+	// introduction node by short channel ID, which we cannot resolve.
+	ErrUnsupportedIntroNode = errors.New("reply path introduction node " +
+		"must be a public key")
+
+	// ErrReplyPathToSelf is returned when a reply path starts at our own
+	// node.
+	ErrReplyPathToSelf = errors.New("reply path starts at our node")
+)
+
+// toBolt12Paths converts the blinded payment paths built for us into the
+// invoice_paths and invoice_blindedpay fields of a BOLT 12 invoice.
+func toBolt12Paths(paths []*zpay32.BlindedPaymentPath) (lnwire.BlindedPaths,
+	[]bolt12.BlindedPayInfo, error) {
+
+	var (
+		blindedPaths lnwire.BlindedPaths
+		payInfos     = make([]bolt12.BlindedPayInfo, 0, len(paths))
+	)
+	for _, path := range paths {
+		if len(path.Hops) == 0 {
+			return blindedPaths, nil, lnwire.ErrEmptyBlindedPath
+		}
+
+		// The path builder replaces the blinded key of the first hop
+		// with the real introduction node key.
+		intro, err := lnwire.NewPubkeyIntro(path.Hops[0].BlindedNodePub)
+		if err != nil {
+			return blindedPaths, nil, err
+		}
+
+		hops := make([]lnwire.BlindedHop, 0, len(path.Hops))
+		for _, hop := range path.Hops {
+			hops = append(hops, lnwire.BlindedHop{
+				BlindedNodeID: hop.BlindedNodePub,
+				EncryptedData: hop.CipherText,
+			})
+		}
+
+		blindedPath := lnwire.BlindedPath{
+			IntroductionNode: intro,
+			BlindingPoint:    path.FirstEphemeralBlindingPoint,
+			Hops:             hops,
+		}
+		blindedPaths.Paths = append(blindedPaths.Paths, blindedPath)
+
+		features := lnwire.NewRawFeatureVector()
+		if path.Features != nil {
+			features = path.Features.RawFeatureVector.Clone()
+		}
+
+		payInfos = append(payInfos, bolt12.BlindedPayInfo{
+			FeeBaseMsat:               path.FeeBaseMsat,
+			FeeProportionalMillionths: path.FeeRate,
+			CltvExpiryDelta:           path.CltvExpiryDelta,
+			HtlcMinimumMsat:           path.HTLCMinMsat,
+			HtlcMaximumMsat:           path.HTLCMaxMsat,
+			Features:                  *features,
+		})
+	}
+
+	return blindedPaths, payInfos, nil
+}
+
+// sendToBlindedPath sends the final hop payloads as an onion message along the
+// given blinded reply path. If the introduction node is not one of our peers,
+// the message is first routed to it over the graph through a blinded prefix
+// that hands over to the reply path's blinding point.
+func (m *Manager) sendToBlindedPath(ctx context.Context,
+	replyPath *lnwire.BlindedPath,
+	finalHopTLVs []*lnwire.FinalHopTLV) error {
+
+	path, firstHop, err := m.routeToBlindedPath(ctx, replyPath)
+	if err != nil {
+		return err
+	}
+
+	return m.sendOnion(ctx, path, firstHop, nil, finalHopTLVs)
+}
+
+// routeToBlindedPath returns the full blinded path an onion message to dest
+// travels and the peer it must be handed to.
+func (m *Manager) routeToBlindedPath(ctx context.Context,
+	dest *lnwire.BlindedPath) (*sphinx.BlindedPath, route.Vertex, error) {
+
+	intro, ok := dest.IntroductionNode.(lnwire.PubkeyIntro)
+	if !ok {
+		return nil, route.Vertex{}, ErrUnsupportedIntroNode
+	}
+	if intro.Pubkey.IsEqual(m.cfg.NodeKey) {
+		return nil, route.Vertex{}, ErrReplyPathToSelf
+	}
+
+	hops := make([]*sphinx.BlindedHopInfo, 0, len(dest.Hops))
+	for _, hop := range dest.Hops {
+		hops = append(hops, &sphinx.BlindedHopInfo{
+			BlindedNodePub: hop.BlindedNodeID,
+			CipherText:     hop.EncryptedData,
+		})
+	}
+
+	path := &sphinx.BlindedPath{
+		IntroductionPoint: intro.Pubkey,
+		BlindingPoint:     dest.BlindingPoint,
+		BlindedHops:       hops,
+	}
+
+	introVertex := route.NewVertex(intro.Pubkey)
+	graphPath := m.findPath(ctx, introVertex)
+	if len(graphPath) <= 1 {
+		return path, introVertex, nil
+	}
+
+	path, err := prependGraphPath(graphPath, path)
+	if err != nil {
+		return nil, route.Vertex{}, err
+	}
+
+	return path, graphPath[0], nil
+}
+
+// routeToNode returns a blinded path over the graph to the given node, for
+// which we create the encrypted data ourselves, and the peer the onion message
+// must be handed to.
+func (m *Manager) routeToNode(ctx context.Context,
+	dest route.Vertex) (*sphinx.BlindedPath, route.Vertex, error) {
+
+	if dest == route.NewVertex(m.cfg.NodeKey) {
+		return nil, route.Vertex{}, ErrReplyPathToSelf
+	}
+
+	graphPath := m.findPath(ctx, dest)
+	if len(graphPath) == 0 {
+		graphPath = []route.Vertex{dest}
+	}
+
+	hopInfos := make([]*sphinx.HopInfo, 0, len(graphPath))
+	for i, vertex := range graphPath {
+		nodePub, err := crypto.ParsePubKey(vertex[:])
+		if err != nil {
+			return nil, route.Vertex{}, err
+		}
+
+		// The destination gets empty route data, every hop before it
+		// is told who to pass the message on to.
+		data := &record.BlindedRouteData{}
+		if i < len(graphPath)-1 {
+			nextNode, err := crypto.ParsePubKey(graphPath[i+1][:])
+			if err != nil {
+				return nil, route.Vertex{}, err
+			}
+
+			data = record.NewNonFinalBlindedRouteDataOnionMessage(
+				fn.NewLeft[*crypto.PublicKey,
+					lnwire.ShortChannelID](nextNode),
+				nil, nil,
+			)
+		}
+
+		plainText, err := record.EncodeBlindedRouteData(data)
+		if err != nil {
+			return nil, route.Vertex{}, err
+		}
+
+		hopInfos = append(hopInfos, &sphinx.HopInfo{
+			NodePub:   nodePub,
+			PlainText: plainText,
+		})
+	}
+
+	path, err := buildBlindedPath(hopInfos)
+	if err != nil {
+		return nil, route.Vertex{}, err
+	}
+
+	return path, graphPath[0], nil
+}
+
+// findPath returns the graph route from our node to dest, excluding our node.
+// If we cannot find one we still try to reach dest directly, since it may be a
+// peer we only share private channels with, so nil is returned instead of an
+// error.
+func (m *Manager) findPath(ctx context.Context,
+	dest route.Vertex) []route.Vertex {
+
+	graphPath, err := m.cfg.FindPath(ctx, dest)
+	if err != nil {
+		log.Debugf("No onion message route to %v, sending directly: %v",
+			dest, err)
+
+		return nil
+	}
+
+	return graphPath
+}
+
+// sendOnion builds the onion for a message along path, carrying the given
+// reply path and final hop payloads, and hands it to our peer firstHop.
+func (m *Manager) sendOnion(ctx context.Context, path *sphinx.BlindedPath,
+	firstHop route.Vertex, replyPath *lnwire.BlindedPath,
+	finalHopTLVs []*lnwire.FinalHopTLV) error {
+
+	onion, err := buildOnion(path, replyPath, finalHopTLVs)
+	if err != nil {
+		return err
+	}
+
+	return m.cfg.SendOnionMessage(
+		ctx, [33]byte(firstHop), path.BlindingPoint, onion,
+	)
+}
+
+// buildReplyPath builds a blinded path to our node introduced by introNode.
+// Unless introNode is our own node, the path runs through it as a relay. The
+// final hop carries the given path ID so the reply can be matched to the
+// message it answers.
+func (m *Manager) buildReplyPath(introNode route.Vertex,
+	pathID []byte) (*lnwire.BlindedPath, error) {
+
+	introPub, err := crypto.ParsePubKey(introNode[:])
+	if err != nil {
+		return nil, err
+	}
+
+	finalData, err := record.EncodeBlindedRouteData(
+		record.NewFinalHopBlindedRouteData(nil, pathID),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hopInfos := []*sphinx.HopInfo{
+		{NodePub: m.cfg.NodeKey, PlainText: finalData},
+	}
+
+	if !introPub.IsEqual(m.cfg.NodeKey) {
+		introData, err := record.EncodeBlindedRouteData(
+			record.NewNonFinalBlindedRouteDataOnionMessage(
+				fn.NewLeft[*crypto.PublicKey,
+					lnwire.ShortChannelID](m.cfg.NodeKey),
+				nil, nil,
+			),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		hopInfos = append([]*sphinx.HopInfo{
+			{NodePub: introPub, PlainText: introData},
+		}, hopInfos...)
+	}
+
+	path, err := buildBlindedPath(hopInfos)
+	if err != nil {
+		return nil, err
+	}
+
+	intro, err := lnwire.NewPubkeyIntro(introPub)
+	if err != nil {
+		return nil, err
+	}
+
+	hops := make([]lnwire.BlindedHop, 0, len(path.BlindedHops))
+	for _, hop := range path.BlindedHops {
+		hops = append(hops, lnwire.BlindedHop{
+			BlindedNodeID: hop.BlindedNodePub,
+			EncryptedData: hop.CipherText,
+		})
+	}
+
+	return &lnwire.BlindedPath{
+		IntroductionNode: intro,
+		BlindingPoint:    path.BlindingPoint,
+		Hops:             hops,
+	}, nil
+}
+
+// prependGraphPath builds a blinded path over the graph route to the
+// introduction node of dest and concatenates it with dest. The last hop of the
+// prefix carries dest's blinding point as next_path_key_override so the
+// introduction node can continue along dest.
+func prependGraphPath(graphPath []route.Vertex,
+	dest *sphinx.BlindedPath) (*sphinx.BlindedPath, error) {
+
+	// The final vertex of the graph path is the introduction node itself,
+	// which is already the first hop of dest.
+	prefix := graphPath[:len(graphPath)-1]
+
+	hopInfos := make([]*sphinx.HopInfo, 0, len(prefix))
+	for i, vertex := range prefix {
+		nodePub, err := crypto.ParsePubKey(vertex[:])
+		if err != nil {
+			return nil, err
+		}
+
+		nextNode, err := crypto.ParsePubKey(graphPath[i+1][:])
+		if err != nil {
+			return nil, err
+		}
+
+		var override *crypto.PublicKey
+		if i == len(prefix)-1 {
+			override = dest.BlindingPoint
+		}
+
+		routeData := record.NewNonFinalBlindedRouteDataOnionMessage(
+			fn.NewLeft[*crypto.PublicKey, lnwire.ShortChannelID](
+				nextNode,
+			), override, nil,
+		)
+		plainText, err := record.EncodeBlindedRouteData(routeData)
+		if err != nil {
+			return nil, err
+		}
+
+		hopInfos = append(hopInfos, &sphinx.HopInfo{
+			NodePub:   nodePub,
+			PlainText: plainText,
+		})
+	}
+
+	prefixPath, err := buildBlindedPath(hopInfos)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sphinx.BlindedPath{
+		IntroductionPoint: prefixPath.IntroductionPoint,
+		BlindingPoint:     prefixPath.BlindingPoint,
+		BlindedHops: append(
+			prefixPath.BlindedHops, dest.BlindedHops...,
+		),
+	}, nil
+}
+
+// buildBlindedPath blinds the given hops under a fresh session key.
+func buildBlindedPath(hopInfos []*sphinx.HopInfo) (*sphinx.BlindedPath,
+	error) {
+
+	sessionKey, err := crypto.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := sphinx.BuildBlindedPath(sessionKey, hopInfos)
+	if err != nil {
+		return nil, fmt.Errorf("build blinded path: %w", err)
+	}
+
+	return path.Path, nil
+}
+
+// buildOnion creates the onion packet for a message along path. The packet
+// uses the regular 1300 byte payload size when the payloads fit and the jumbo
+// onion message size otherwise, as suggested by BOLT 4.
+func buildOnion(path *sphinx.BlindedPath, replyPath *lnwire.BlindedPath,
+	finalHopTLVs []*lnwire.FinalHopTLV) ([]byte, error) {
+
+	sphinxPath, err := route.OnionMessageBlindedPathToSphinxPath(
+		path, replyPath, finalHopTLVs,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadSize := sphinx.MaxRoutingPayloadSize
+	if sphinxPath.TotalPayloadSize() > payloadSize {
+		payloadSize = sphinx.MaxOnionMessagePayloadSize
+	}
+
+	sessionKey, err := crypto.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	onionPkt, err := sphinx.NewOnionPacket(
+		sphinxPath, sessionKey, nil, sphinx.DeterministicPacketFiller,
+		sphinx.WithMaxPayloadSize(payloadSize),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("new onion packet: %w", err)
+	}
+
+	var b bytes.Buffer
+	if err := onionPkt.Encode(&b); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,273 @@
+package offers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flokiorg/flnd/bolt12"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/tlv"
+)
+
+var (
+	// offersBucket is the top-level bucket that holds the offers we have
+	// created. Keys are offer IDs and values are TLV-encoded offer
+	// records.
+	offersBucket = []byte("bolt12-offers")
+
+	// ErrOfferNotFound is returned when an offer is not in the store.
+	ErrOfferNotFound = errors.New("offer not found")
+
+	// ErrOfferExists is returned when an offer with the same ID is already
+	// in the store.
+	ErrOfferExists = errors.New("offer already exists")
+
+	// ErrCorruptedOfferStore is returned when the offers bucket is
+	// missing.
+	ErrCorruptedOfferStore = errors.New("offer store has been corrupted")
+)
+
+const (
+	// offerRecordType is the TLV type of the encoded offer.
+	offerRecordType tlv.Type = 0
+
+	// createdAtRecordType is the TLV type of the creation time in unix
+	// nanoseconds.
+	createdAtRecordType tlv.Type = 2
+
+	// disabledRecordType is the TLV type of the disabled flag.
+	disabledRecordType tlv.Type = 4
+)
+
+// StoredOffer is an offer we created along with its bookkeeping state.
+type StoredOffer struct {
+	// ID is the offer_id, the Merkle root of the offer's TLV records.
+	ID [32]byte
+
+	// Offer is the offer itself.
+	Offer *bolt12.Offer
+
+	// CreatedAt is the time the offer was created.
+	CreatedAt time.Time
+
+	// Disabled is set once the offer has been disabled. Invoice requests
+	// for a disabled offer are no longer answered.
+	Disabled bool
+}
+
+// Store persists the offers we have created.
+type Store interface {
+	// AddOffer adds a new offer to the store. ErrOfferExists is returned
+	// if an offer with the same ID is already stored.
+	AddOffer(offer *StoredOffer) error
+
+	// FetchOffer returns the offer with the given ID, or ErrOfferNotFound.
+	FetchOffer(id [32]byte) (*StoredOffer, error)
+
+	// ListOffers returns all stored offers.
+	ListOffers() ([]*StoredOffer, error)
+
+	// DisableOffer marks the offer with the given ID as disabled.
+	DisableOffer(id [32]byte) error
+}
+
+// KVStore is a Store backed by a kvdb backend.
+type KVStore struct {
+	db kvdb.Backend
+}
+
+// A compile-time check to ensure KVStore implements the Store interface.
+var _ Store = (*KVStore)(nil)
+
+// NewKVStore creates a new offer store on top of the given backend, creating
+// the offers bucket if it does not exist yet.
+func NewKVStore(db kvdb.Backend) (*KVStore, error) {
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(offersBucket)
+		return err
+	}, func() {})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create offers bucket: %w",
+			err)
+	}
+
+	return &KVStore{db: db}, nil
+}
+
+// AddOffer adds a new offer to the store.
+//
+// NOTE: This is part of the Store interface.
+func (s *KVStore) AddOffer(offer *StoredOffer) error {
+	var b bytes.Buffer
+	if err := serializeOffer(&b, offer); err != nil {
+		return err
+	}
+
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(offersBucket)
+		if bucket == nil {
+			return ErrCorruptedOfferStore
+		}
+
+		if bucket.Get(offer.ID[:]) != nil {
+			return ErrOfferExists
+		}
+
+		return bucket.Put(offer.ID[:], b.Bytes())
+	}, func() {})
+}
+
+// FetchOffer returns the offer with the given ID.
+//
+// NOTE: This is part of the Store interface.
+func (s *KVStore) FetchOffer(id [32]byte) (*StoredOffer, error) {
+	var offer *StoredOffer
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(offersBucket)
+		if bucket == nil {
+			return ErrCorruptedOfferStore
+		}
+
+		v := bucket.Get(id[:])
+		if v == nil {
+			return ErrOfferNotFound
+		}
+
+		var err error
+		offer, err = deserializeOffer(id, bytes.NewReader(v))
+
+		return err
+	}, func() {
+		offer = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+// ListOffers returns all stored offers ordered by offer ID.
+//
+// NOTE: This is part of the Store interface.
+func (s *KVStore) ListOffers() ([]*StoredOffer, error) {
+	var offers []*StoredOffer
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(offersBucket)
+		if bucket == nil {
+			return ErrCorruptedOfferStore
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var id [32]byte
+			copy(id[:], k)
+
+			offer, err := deserializeOffer(id, bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+			offers = append(offers, offer)
+
+			return nil
+		})
+	}, func() {
+		offers = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return offers, nil
+}
+
+// DisableOffer marks the offer with the given ID as disabled.
+//
+// NOTE: This is part of the Store interface.
+func (s *KVStore) DisableOffer(id [32]byte) error {
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(offersBucket)
+		if bucket == nil {
+			return ErrCorruptedOfferStore
+		}
+
+		v := bucket.Get(id[:])
+		if v == nil {
+			return ErrOfferNotFound
+		}
+
+		offer, err := deserializeOffer(id, bytes.NewReader(v))
+		if err != nil {
+			return err
+		}
+		offer.Disabled = true
+
+		var b bytes.Buffer
+		if err := serializeOffer(&b, offer); err != nil {
+			return err
+		}
+
+		return bucket.Put(id[:], b.Bytes())
+	}, func() {})
+}
+
+// serializeOffer writes the offer record as a TLV stream.
+func serializeOffer(w *bytes.Buffer, offer *StoredOffer) error {
+	offerBytes, err := offer.Offer.Encode()
+	if err != nil {
+		return err
+	}
+
+	createdAt := uint64(offer.CreatedAt.UnixNano())
+
+	var disabled uint8
+	if offer.Disabled {
+		disabled = 1
+	}
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(offerRecordType, &offerBytes),
+		tlv.MakePrimitiveRecord(createdAtRecordType, &createdAt),
+		tlv.MakePrimitiveRecord(disabledRecordType, &disabled),
+	)
+	if err != nil {
+		return err
+	}
+
+	return stream.Encode(w)
+}
+
+// deserializeOffer reads an offer record written by serializeOffer.
+func deserializeOffer(id [32]byte, r *bytes.Reader) (*StoredOffer, error) {
+	var (
+		offerBytes []byte
+		createdAt  uint64
+		disabled   uint8
+	)
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(offerRecordType, &offerBytes),
+		tlv.MakePrimitiveRecord(createdAtRecordType, &createdAt),
+		tlv.MakePrimitiveRecord(disabledRecordType, &disabled),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.Decode(r); err != nil {
+		return nil, err
+	}
+
+	offer, err := bolt12.DecodeOffer(offerBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StoredOffer{
+		ID:        id,
+		Offer:     offer,
+		CreatedAt: time.Unix(0, int64(createdAt)),
+		Disabled:  disabled == 1,
+	}, nil
+}
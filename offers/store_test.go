@@ -0,0 +1,94 @@
+package offers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/bolt12"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore creates a KVStore on a fresh test backend.
+func newTestStore(t *testing.T) *KVStore {
+	t.Helper()
+
+	backend, cleanup, err := kvdb.GetTestBackend(t.TempDir(), "offers")
+	require.NoError(t, err)
+	t.Cleanup(cleanup)
+
+	store, err := NewKVStore(backend)
+	require.NoError(t, err)
+
+	return store
+}
+
+// newStoredOffer returns a minimal offer with the given description, keyed by
+// its offer_id.
+func newStoredOffer(t *testing.T, description string) *StoredOffer {
+	t.Helper()
+
+	priv, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+
+	offer := &bolt12.Offer{
+		OfferDescription: tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType10](
+				tlv.Blob(description),
+			),
+		),
+		OfferIssuerID: tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType22](priv.PubKey()),
+		),
+	}
+
+	id, err := offer.ID()
+	require.NoError(t, err)
+
+	return &StoredOffer{
+		ID:        id,
+		Offer:     offer,
+		CreatedAt: time.Unix(0, 1_700_000_000_123_456_789),
+	}
+}
+
+// TestKVStore exercises adding, fetching, listing and disabling offers.
+func TestKVStore(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t)
+
+	offer1 := newStoredOffer(t, "coffee")
+	offer2 := newStoredOffer(t, "tea")
+
+	_, err := store.FetchOffer(offer1.ID)
+	require.ErrorIs(t, err, ErrOfferNotFound)
+
+	require.NoError(t, store.AddOffer(offer1))
+	require.NoError(t, store.AddOffer(offer2))
+	require.ErrorIs(t, store.AddOffer(offer1), ErrOfferExists)
+
+	fetched, err := store.FetchOffer(offer1.ID)
+	require.NoError(t, err)
+	require.Equal(t, offer1.ID, fetched.ID)
+	require.True(t, offer1.CreatedAt.Equal(fetched.CreatedAt))
+	require.False(t, fetched.Disabled)
+
+	// The decoded offer must hash back to the key it is stored under.
+	fetchedID, err := fetched.Offer.ID()
+	require.NoError(t, err)
+	require.Equal(t, offer1.ID, fetchedID)
+
+	offers, err := store.ListOffers()
+	require.NoError(t, err)
+	require.Len(t, offers, 2)
+
+	require.NoError(t, store.DisableOffer(offer2.ID))
+	fetched, err = store.FetchOffer(offer2.ID)
+	require.NoError(t, err)
+	require.True(t, fetched.Disabled)
+
+	require.ErrorIs(t, store.DisableOffer([32]byte{1}), ErrOfferNotFound)
+}
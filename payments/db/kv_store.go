@@ -1092,6 +1092,12 @@ func (p *KVStore) QueryPayments(_ context.Context,
 				return false, err
 			}
 
+			// Trampoline payments we relay aren't our own, so
+			// they're never returned.
+			if payment.Info.TrampolineRelay {
+				return false, nil
+			}
+
 			// To keep compatibility with the old API, we only
 			// return non-succeeded payments if requested.
 			if payment.Status != StatusSucceeded &&
@@ -1145,8 +1151,16 @@ func (p *KVStore) QueryPayments(_ context.Context,
 				totalPayments uint64
 				err           error
 			)
-			countFn := func(_, _ []byte) error {
-				totalPayments++
+			countFn := func(_, hash []byte) error {
+				relay, err := isTrampolineRelay(
+					paymentsBucket, hash,
+				)
+				if err != nil {
+					return err
+				}
+				if !relay {
+					totalPayments++
+				}
 
 				return nil
 			}
@@ -1193,6 +1207,29 @@ func (p *KVStore) QueryPayments(_ context.Context,
 	return resp, nil
 }
 
+// isTrampolineRelay returns true if the payment the given index entry points
+// to relays a trampoline payment.
+func isTrampolineRelay(paymentsBucket kvdb.RBucket, index []byte) (bool,
+	error) {
+
+	paymentHash, err := deserializePaymentIndex(bytes.NewReader(index))
+	if err != nil {
+		return false, err
+	}
+
+	bucket := paymentsBucket.NestedReadBucket(paymentHash[:])
+	if bucket == nil {
+		return false, ErrPaymentNotInitiated
+	}
+
+	info, err := fetchCreationInfo(bucket)
+	if err != nil {
+		return false, err
+	}
+
+	return info.TrampolineRelay, nil
+}
+
 // fetchPaymentWithSequenceNumber get the payment which matches the payment hash
 // *and* sequence number provided from the database. This is required because
 // we previously had more than one payment per hash, so we have multiple indexes
@@ -1422,6 +1459,16 @@ func (p *KVStore) DeletePayments(failedOnly,
 				return nil
 			}
 
+			// Trampoline payments we relay aren't our own, so we
+			// leave them alone.
+			info, err := fetchCreationInfo(bucket)
+			if err != nil {
+				return err
+			}
+			if info.TrampolineRelay {
+				return nil
+			}
+
 			// If we are only deleting failed HTLCs, fetch them.
 			if failedHtlcsOnly {
 				toDelete, err := fetchFailedHtlcKeys(bucket)
@@ -1568,15 +1615,36 @@ func serializePaymentCreationInfo(w io.Writer, c *PaymentCreationInfo) error {
 		return err
 	}
 
-	// Any remaining bytes are TLV encoded records. Currently, these are
-	// only the custom records provided by the user to be sent to the first
-	// hop. But this can easily be extended with further records by merging
-	// the records into a single TLV stream.
-	err := c.FirstHopCustomRecords.SerializeTo(w)
+	// Any remaining bytes are TLV encoded records. These are the offer
+	// fields of BOLT 12 payments and the trampoline relay flag merged with the custom records provided
+	// by the user to be sent to the first hop into a single TLV stream.
+	var producers []tlv.RecordProducer
+	if len(c.Offer) > 0 {
+		offer := tlv.NewPrimitiveRecord[tlv.TlvType1](c.Offer)
+		producers = append(producers, &offer)
+	}
+	if len(c.PayerNote) > 0 {
+		payerNote := tlv.NewPrimitiveRecord[tlv.TlvType3](
+			[]byte(c.PayerNote),
+		)
+		producers = append(producers, &payerNote)
+	}
+	if c.TrampolineRelay {
+		relay := tlv.NewPrimitiveRecord[tlv.TlvType5](uint8(1))
+		producers = append(producers, &relay)
+	}
+
+	tlvData, err := lnwire.MergeAndEncode(
+		producers, nil, c.FirstHopCustomRecords,
+	)
 	if err != nil {
 		return err
 	}
 
+	if _, err := w.Write(tlvData); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1615,15 +1683,32 @@ func deserializePaymentCreationInfo(r io.Reader) (*PaymentCreationInfo,
 	}
 	c.PaymentRequest = payReq
 
-	// Any remaining bytes are TLV encoded records. Currently, these are
-	// only the custom records provided by the user to be sent to the first
-	// hop. But this can easily be extended with further records by merging
-	// the records into a single TLV stream.
-	c.FirstHopCustomRecords, err = lnwire.ParseCustomRecordsFrom(r)
+	// Any remaining bytes are TLV encoded records, which are parsed into
+	// the offer fields and the first hop custom records.
+	extraData, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
 
+	offer := tlv.ZeroRecordT[tlv.TlvType1, []byte]()
+	payerNote := tlv.ZeroRecordT[tlv.TlvType3, []byte]()
+	relay := tlv.ZeroRecordT[tlv.TlvType5, uint8]()
+	customRecords, parsed, _, err := lnwire.ParseAndExtractCustomRecords(
+		extraData, &offer, &payerNote, &relay,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Contains(offer.TlvType()) {
+		c.Offer = offer.Val
+	}
+	if parsed.Contains(payerNote.TlvType()) {
+		c.PayerNote = string(payerNote.Val)
+	}
+	c.TrampolineRelay = parsed.Contains(relay.TlvType())
+	c.FirstHopCustomRecords = customRecords
+
 	return c, nil
 }
 
@@ -1785,6 +1870,14 @@ func serializeHop(w io.Writer, h *route.Hop) error {
 		)
 	}
 
+	if h.TrampolineOnion != nil {
+		records = append(
+			records, record.NewTrampolineOnionRecord(
+				&h.TrampolineOnion,
+			),
+		)
+	}
+
 	// Final sanity check to absolutely rule out custom records that are not
 	// custom and write into the standard range.
 	if err := h.CustomRecords.Validate(); err != nil {
@@ -1963,6 +2056,15 @@ func deserializeHop(r io.Reader) (*route.Hop, error) {
 		h.TotalAmtMsat = lnwire.MilliLoki(totalAmtMsatInt)
 	}
 
+	// If the trampoline onion type is present, remove it from the tlv map
+	// and populate directly on the hop.
+	trampolineOnionType := uint64(record.TrampolineOnionType)
+	if onion, ok := tlvMap[trampolineOnionType]; ok {
+		delete(tlvMap, trampolineOnionType)
+
+		h.TrampolineOnion = onion
+	}
+
 	h.CustomRecords = tlvMap
 
 	return h, nil
@@ -2095,23 +2197,9 @@ func deserializeHTLCFailInfo(r io.Reader) (*HTLCFailInfo, error) {
 	if err != nil {
 		return nil, err
 	}
-	if len(failureBytes) > 0 {
-		f.Message, err = lnwire.DecodeFailureMessage(
-			bytes.NewReader(failureBytes), 0,
-		)
-		if err != nil &&
-			!errors.Is(err, lnwire.ErrParsingExtraTLVBytes) {
-
-			return nil, err
-		}
-
-		// In case we have an invalid TLV stream regarding the extra
-		// tlv data we still continue with the decoding of the
-		// HTLCFailInfo.
-		if errors.Is(err, lnwire.ErrParsingExtraTLVBytes) {
-			log.Warnf("Failed to decode extra TLV bytes for "+
-				"failure message: %v", err)
-		}
+	f.Message, err = decodeFailureMessage(failureBytes)
+	if err != nil {
+		return nil, err
 	}
 
 	var reason byte
@@ -2123,3 +2211,25 @@ func deserializeHTLCFailInfo(r io.Reader) (*HTLCFailInfo, error) {
 
 	return f, nil
 }
+
+// decodeFailureMessage decodes the given wire encoded failure message of a
+// failed htlc. An empty byte slice decodes to a nil message.
+func decodeFailureMessage(b []byte) (lnwire.FailureMessage, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	msg, err := lnwire.DecodeFailureMessage(bytes.NewReader(b), 0)
+	switch {
+	// In case we have an invalid TLV stream regarding the extra tlv data
+	// we still continue with the decoding of the HTLCFailInfo.
+	case errors.Is(err, lnwire.ErrParsingExtraTLVBytes):
+		log.Warnf("Failed to decode extra TLV bytes for failure "+
+			"message: %v", err)
+
+	case err != nil:
+		return nil, err
+	}
+
+	return msg, nil
+}
@@ -21,6 +21,23 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// NewKVTestDB is a helper function that creates an BBolt database for testing
+// and there is no need to convert the interface to the KVStore because for
+// some unit tests we still need access to the kvdb interface.
+func NewKVTestDB(t *testing.T, opts ...OptionModifier) *KVStore {
+	backend, backendCleanup, err := kvdb.GetTestBackend(
+		t.TempDir(), "kvPaymentDB",
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(backendCleanup)
+
+	paymentDB, err := NewKVStore(backend, opts...)
+	require.NoError(t, err)
+
+	return paymentDB
+}
+
 // TestKVStoreDeleteNonInFlight checks that calling DeletePayments only
 // deletes payments from the database that are not in-flight.
 //
@@ -380,6 +397,18 @@ func TestSentPaymentSerialization(t *testing.T) {
 	require.NoError(t, err, "deserialize")
 	require.Equal(t, c, newCreationInfo)
 
+	b.Reset()
+
+	// The offer fields of BOLT 12 payments share the TLV stream with the
+	// custom records.
+	c.Offer = []byte("lno1test")
+	c.PayerNote = "thanks"
+	require.NoError(t, serializePaymentCreationInfo(&b, c), "serialize")
+
+	newCreationInfo, err = deserializePaymentCreationInfo(&b)
+	require.NoError(t, err, "deserialize")
+	require.Equal(t, c, newCreationInfo)
+
 	b.Reset()
 	require.NoError(t, serializeHTLCAttemptInfo(&b, s), "serialize")
 
@@ -93,6 +93,19 @@ type PaymentCreationInfo struct {
 	// wire message (UpdateAddHTLC) only and therefore do not affect the
 	// onion payload size.
 	FirstHopCustomRecords lnwire.CustomRecords
+
+	// Offer is the encoded BOLT 12 offer this payment was made for, if
+	// any. PaymentRequest then holds the invoice fetched for it.
+	Offer []byte
+
+	// PayerNote is the note sent along with the invoice request of a BOLT
+	// 12 payment, if any.
+	PayerNote string
+
+	// TrampolineRelay is set if this payment relays a trampoline payment
+	// we received as a trampoline node. As it isn't our own payment, it's
+	// neither returned by QueryPayments nor removed by DeletePayments.
+	TrampolineRelay bool
 }
 
 // String returns a human-readable description of the payment creation info.
@@ -49,8 +49,9 @@ var (
 			65536: []byte{},
 			80001: []byte{},
 		},
-		MPP:      record.NewMPP(32, [32]byte{0x42}),
-		Metadata: []byte{1, 2, 3},
+		MPP:             record.NewMPP(32, [32]byte{0x42}),
+		Metadata:        []byte{1, 2, 3},
+		TrampolineOnion: []byte{4, 5, 6},
 	}
 
 	testHop2 = &route.Hop{
@@ -1544,6 +1545,167 @@ func TestDeletePayments(t *testing.T) {
 	assertDBPayments(t, paymentDB, payments[2:])
 }
 
+// TestQueryPaymentsFilters checks that payments are paginated in both
+// directions and filtered by their status and creation date.
+func TestQueryPaymentsFilters(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	paymentDB := NewTestDB(t)
+
+	payments := []*payment{
+		{status: StatusFailed},
+		{status: StatusSucceeded},
+		{status: StatusInFlight},
+		{status: StatusSucceeded},
+		{status: StatusSucceeded},
+	}
+	createTestPayments(t, paymentDB, payments)
+
+	// hashes returns the payment hashes of the given payments in order.
+	hashes := func(pmts []*MPPayment) []lntypes.Hash {
+		var h []lntypes.Hash
+		for _, p := range pmts {
+			h = append(h, p.Info.PaymentIdentifier)
+		}
+
+		return h
+	}
+
+	// Without including incomplete payments, only the succeeded ones are
+	// returned, while all payments are counted.
+	resp, err := paymentDB.QueryPayments(ctx, Query{
+		MaxPayments: 10,
+		CountTotal:  true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []lntypes.Hash{
+		payments[1].id, payments[3].id, payments[4].id,
+	}, hashes(resp.Payments))
+	require.EqualValues(t, len(payments), resp.TotalCount)
+
+	// Paginate forwards through all payments two at a time.
+	var (
+		forward []lntypes.Hash
+		offset  uint64
+	)
+	for {
+		resp, err := paymentDB.QueryPayments(ctx, Query{
+			IndexOffset:       offset,
+			MaxPayments:       2,
+			IncludeIncomplete: true,
+		})
+		require.NoError(t, err)
+
+		if len(resp.Payments) == 0 {
+			break
+		}
+		require.LessOrEqual(t, len(resp.Payments), 2)
+
+		forward = append(forward, hashes(resp.Payments)...)
+		offset = resp.LastIndexOffset
+	}
+
+	var all []lntypes.Hash
+	for _, p := range payments {
+		all = append(all, p.id)
+	}
+	require.Equal(t, all, forward)
+
+	// Paginate backwards, the result of each page is still returned in
+	// ascending order.
+	resp, err = paymentDB.QueryPayments(ctx, Query{
+		MaxPayments:       2,
+		Reversed:          true,
+		IncludeIncomplete: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, all[3:], hashes(resp.Payments))
+
+	resp, err = paymentDB.QueryPayments(ctx, Query{
+		IndexOffset:       resp.FirstIndexOffset,
+		MaxPayments:       2,
+		Reversed:          true,
+		IncludeIncomplete: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, all[1:3], hashes(resp.Payments))
+
+	// Finally, no payments are returned for a creation date range in the
+	// future.
+	resp, err = paymentDB.QueryPayments(ctx, Query{
+		MaxPayments:       10,
+		IncludeIncomplete: true,
+		CreationDateStart: time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Payments)
+
+	resp, err = paymentDB.QueryPayments(ctx, Query{
+		MaxPayments:       10,
+		IncludeIncomplete: true,
+		CreationDateEnd:   time.Now().Add(-time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Payments)
+}
+
+// TestTrampolineRelayPayments checks that the trampoline payments we relay
+// are neither returned when querying payments nor deleted along with our own
+// payments.
+func TestTrampolineRelayPayments(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	paymentDB := NewTestDB(t)
+
+	payments := []*payment{
+		{status: StatusSucceeded},
+	}
+	createTestPayments(t, paymentDB, payments)
+
+	// Relay a trampoline payment that settles just like our own one.
+	info, attempt, preimg, err := genInfo(t)
+	require.NoError(t, err)
+	info.TrampolineRelay = true
+
+	require.NoError(t, paymentDB.InitPayment(info.PaymentIdentifier, info))
+
+	attempt.AttemptID = 100
+	_, err = paymentDB.RegisterAttempt(info.PaymentIdentifier, attempt)
+	require.NoError(t, err)
+
+	_, err = paymentDB.SettleAttempt(
+		info.PaymentIdentifier, attempt.AttemptID,
+		&HTLCSettleInfo{Preimage: preimg},
+	)
+	require.NoError(t, err)
+
+	// The relayed payment is neither returned nor counted.
+	resp, err := paymentDB.QueryPayments(ctx, Query{
+		MaxPayments:       10,
+		IncludeIncomplete: true,
+		CountTotal:        true,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Payments, 1)
+	require.Equal(
+		t, payments[0].id, resp.Payments[0].Info.PaymentIdentifier,
+	)
+	require.EqualValues(t, 1, resp.TotalCount)
+
+	// Deleting all payments only removes our own one.
+	numPayments, err := paymentDB.DeletePayments(false, false)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, numPayments)
+
+	relay, err := paymentDB.FetchPayment(info.PaymentIdentifier)
+	require.NoError(t, err)
+	require.True(t, relay.Info.TrampolineRelay)
+	require.Equal(t, StatusSucceeded, relay.Status)
+	require.Len(t, relay.HTLCs, 1)
+}
+
 // TestSwitchDoubleSend checks the ability of payment control to
 // prevent double sending of htlc message, when message is in StatusInFlight.
 func TestSwitchDoubleSend(t *testing.T) {
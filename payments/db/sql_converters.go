@@ -0,0 +1,535 @@
+package paymentsdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/record"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/crypto"
+)
+
+// attemptResolutionType is the resolution of an HTLC attempt as stored in the
+// resolution_type column of the payment_htlc_attempts table.
+type attemptResolutionType int16
+
+const (
+	// attemptResolutionSettled indicates that the attempt was settled.
+	attemptResolutionSettled attemptResolutionType = 1
+
+	// attemptResolutionFailed indicates that the attempt has failed.
+	attemptResolutionFailed attemptResolutionType = 2
+)
+
+// insertPayment inserts the payment with the given creation info and status
+// together with its first hop custom records and returns its ID.
+func insertPayment(ctx context.Context, db SQLQueries,
+	paymentHash lntypes.Hash, info *PaymentCreationInfo,
+	status PaymentStatus) (int64, error) {
+
+	paymentID, err := db.InsertPayment(ctx, sqlc.InsertPaymentParams{
+		PaymentHash:     paymentHash[:],
+		AmountMsat:      int64(info.Value),
+		CreatedAt:       unixNano(info.CreationTime),
+		Status:          int16(status),
+		PaymentRequest:  sqlBytes(info.PaymentRequest),
+		Offer:           sqlBytes(info.Offer),
+		PayerNote:       sqldb.SQLStr(info.PayerNote),
+		TrampolineRelay: info.TrampolineRelay,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to insert payment: %w", err)
+	}
+
+	for key, value := range info.FirstHopCustomRecords {
+		err := db.InsertPaymentFirstHopCustomRecord(
+			ctx, sqlc.InsertPaymentFirstHopCustomRecordParams{
+				PaymentID: paymentID,
+				Key:       int64(key),
+				Value:     value,
+			},
+		)
+		if err != nil {
+			return 0, fmt.Errorf("unable to insert first hop "+
+				"custom record: %w", err)
+		}
+	}
+
+	return paymentID, nil
+}
+
+// fetchPaymentCreationInfo assembles the creation info of the payment of the
+// given row.
+func fetchPaymentCreationInfo(ctx context.Context, db SQLQueries,
+	row sqlc.Payment) (*PaymentCreationInfo, error) {
+
+	paymentHash, err := lntypes.MakeHash(row.PaymentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := db.FetchPaymentFirstHopCustomRecords(ctx, row.ID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch first hop custom "+
+			"records: %w", err)
+	}
+
+	var customRecords lnwire.CustomRecords
+	for _, r := range records {
+		if customRecords == nil {
+			customRecords = make(lnwire.CustomRecords)
+		}
+		customRecords[uint64(r.Key)] = customRecordValue(r.Value)
+	}
+
+	return &PaymentCreationInfo{
+		PaymentIdentifier:     paymentHash,
+		Value:                 lnwire.MilliLoki(row.AmountMsat),
+		CreationTime:          timeFromUnixNano(row.CreatedAt),
+		PaymentRequest:        row.PaymentRequest,
+		FirstHopCustomRecords: customRecords,
+		Offer:                 row.Offer,
+		PayerNote:             row.PayerNote.String,
+		TrampolineRelay:       row.TrampolineRelay,
+	}, nil
+}
+
+// insertHtlcAttempt inserts the given HTLC attempt of a payment together with
+// the hops of its route and all their custom records.
+func insertHtlcAttempt(ctx context.Context, db SQLQueries, paymentID int64,
+	attempt *HTLCAttemptInfo) error {
+
+	var hash []byte
+	if attempt.Hash != nil {
+		hash = attempt.Hash[:]
+	}
+
+	rt := &attempt.Route
+	attemptID, err := db.InsertPaymentHtlcAttempt(
+		ctx, sqlc.InsertPaymentHtlcAttemptParams{
+			PaymentID:          paymentID,
+			AttemptID:          int64(attempt.AttemptID),
+			SessionKey:         attempt.sessionKey[:],
+			AttemptTime:        unixNano(attempt.AttemptTime),
+			PaymentHash:        hash,
+			RouteTotalTimeLock: int32(rt.TotalTimeLock),
+			RouteTotalAmount:   int64(rt.TotalAmount),
+			RouteSourceKey:     rt.SourcePubKey[:],
+			FirstHopAmountMsat: int64(rt.FirstHopAmount.Val.Int()),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to insert htlc attempt: %w", err)
+	}
+
+	for key, value := range rt.FirstHopWireCustomRecords {
+		params := sqlc.InsertPaymentAttemptFirstHopCustomRecordParams{
+			HtlcAttemptID: attemptID,
+			Key:           int64(key),
+			Value:         value,
+		}
+		err := db.InsertPaymentAttemptFirstHopCustomRecord(ctx, params)
+		if err != nil {
+			return fmt.Errorf("unable to insert attempt first "+
+				"hop custom record: %w", err)
+		}
+	}
+
+	for i, hop := range rt.Hops {
+		err := insertRouteHop(ctx, db, attemptID, i, hop)
+		if err != nil {
+			return fmt.Errorf("unable to insert hop %d: %w", i,
+				err)
+		}
+	}
+
+	return nil
+}
+
+// insertRouteHop inserts the hop at the given index of the route of an HTLC
+// attempt together with its custom records.
+func insertRouteHop(ctx context.Context, db SQLQueries, attemptID int64,
+	index int, hop *route.Hop) error {
+
+	// Rule out custom records that are not custom and would write into the
+	// standard range.
+	if err := hop.CustomRecords.Validate(); err != nil {
+		return err
+	}
+
+	params := sqlc.InsertPaymentRouteHopParams{
+		HtlcAttemptID:    attemptID,
+		HopIndex:         int32(index),
+		PubKey:           hop.PubKeyBytes[:],
+		Scid:             int64(hop.ChannelID),
+		OutgoingTimeLock: int32(hop.OutgoingTimeLock),
+		AmtToForward:     int64(hop.AmtToForward),
+		LegacyPayload:    hop.LegacyPayload,
+		MetaData:         hop.Metadata,
+		EncryptedData:    hop.EncryptedData,
+		TrampolineOnion:  hop.TrampolineOnion,
+	}
+
+	if hop.MPP != nil {
+		paymentAddr := hop.MPP.PaymentAddr()
+		params.MppPaymentAddr = paymentAddr[:]
+		params.MppTotalMsat = sqldb.SQLInt64(hop.MPP.TotalMsat())
+	}
+
+	if hop.AMP != nil {
+		rootShare := hop.AMP.RootShare()
+		setID := hop.AMP.SetID()
+		params.AmpRootShare = rootShare[:]
+		params.AmpSetID = setID[:]
+		params.AmpChildIndex = sqldb.SQLInt32(hop.AMP.ChildIndex())
+	}
+
+	if hop.BlindingPoint != nil {
+		params.BlindingPoint = hop.BlindingPoint.SerializeCompressed()
+	}
+
+	if hop.TotalAmtMsat != 0 {
+		params.BlindedPathTotalAmt = sqldb.SQLInt64(hop.TotalAmtMsat)
+	}
+
+	hopID, err := db.InsertPaymentRouteHop(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range hop.CustomRecords {
+		err := db.InsertPaymentHopCustomRecord(
+			ctx, sqlc.InsertPaymentHopCustomRecordParams{
+				HopID: hopID,
+				Key:   int64(key),
+				Value: value,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to insert hop custom "+
+				"record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// fetchSQLHtlcAttempts assembles all HTLC attempts of the payment with the
+// given ID, ordered by their attempt ID.
+func fetchSQLHtlcAttempts(ctx context.Context, db SQLQueries,
+	paymentID int64) ([]HTLCAttempt, error) {
+
+	attemptRows, err := db.FetchPaymentHtlcAttempts(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch htlc attempts: %w", err)
+	}
+
+	// Nothing more to fetch if the payment has no attempts yet.
+	if len(attemptRows) == 0 {
+		return nil, nil
+	}
+
+	hops, err := fetchRouteHops(ctx, db, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := db.FetchPaymentAttemptFirstHopCustomRecords(
+		ctx, paymentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch attempt first hop "+
+			"custom records: %w", err)
+	}
+
+	firstHopRecords := make(map[int64]lnwire.CustomRecords)
+	for _, r := range records {
+		if firstHopRecords[r.HtlcAttemptID] == nil {
+			firstHopRecords[r.HtlcAttemptID] = make(
+				lnwire.CustomRecords,
+			)
+		}
+		firstHopRecords[r.HtlcAttemptID][uint64(r.Key)] =
+			customRecordValue(r.Value)
+	}
+
+	htlcs := make([]HTLCAttempt, 0, len(attemptRows))
+	for _, row := range attemptRows {
+		htlc, err := unmarshalHtlcAttempt(
+			row, hops[row.ID], firstHopRecords[row.ID],
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal htlc "+
+				"attempt %d: %w", row.AttemptID, err)
+		}
+
+		htlcs = append(htlcs, *htlc)
+	}
+
+	return htlcs, nil
+}
+
+// unmarshalHtlcAttempt assembles the HTLC attempt of the given row from its
+// route hops and first hop custom records.
+func unmarshalHtlcAttempt(row sqlc.PaymentHtlcAttempt, hops []*route.Hop,
+	firstHopRecords lnwire.CustomRecords) (*HTLCAttempt, error) {
+
+	sourceKey, err := route.NewVertexFromBytes(row.RouteSourceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	firstHopAmt := lnwire.MilliLoki(row.FirstHopAmountMsat)
+	info := HTLCAttemptInfo{
+		AttemptID: uint64(row.AttemptID),
+		Route: route.Route{
+			TotalTimeLock: uint32(row.RouteTotalTimeLock),
+			TotalAmount:   lnwire.MilliLoki(row.RouteTotalAmount),
+			SourcePubKey:  sourceKey,
+			Hops:          hops,
+			FirstHopAmount: tlv.NewRecordT[tlv.TlvType0](
+				tlv.NewBigSizeT(firstHopAmt),
+			),
+			FirstHopWireCustomRecords: firstHopRecords,
+		},
+		AttemptTime: timeFromUnixNano(row.AttemptTime),
+	}
+	copy(info.sessionKey[:], row.SessionKey)
+
+	if row.PaymentHash != nil {
+		hash, err := lntypes.MakeHash(row.PaymentHash)
+		if err != nil {
+			return nil, err
+		}
+		info.Hash = &hash
+	}
+
+	htlc := &HTLCAttempt{
+		HTLCAttemptInfo: info,
+	}
+
+	if !row.ResolutionType.Valid {
+		return htlc, nil
+	}
+
+	resolveTime := timeFromUnixNano(row.ResolutionTime.Int64)
+	switch attemptResolutionType(row.ResolutionType.Int16) {
+	case attemptResolutionSettled:
+		preimage, err := lntypes.MakePreimage(row.SettlePreimage)
+		if err != nil {
+			return nil, err
+		}
+
+		htlc.Settle = &HTLCSettleInfo{
+			Preimage:   preimage,
+			SettleTime: resolveTime,
+		}
+
+	case attemptResolutionFailed:
+		msg, err := decodeFailureMessage(row.FailureMsg)
+		if err != nil {
+			return nil, err
+		}
+
+		htlc.Failure = &HTLCFailInfo{
+			FailTime: resolveTime,
+			Message:  msg,
+			Reason:   HTLCFailReason(row.HtlcFailReason.Int16),
+			FailureSourceIndex: uint32(
+				row.FailureSourceIndex.Int32,
+			),
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown resolution type %d",
+			row.ResolutionType.Int16)
+	}
+
+	return htlc, nil
+}
+
+// fetchRouteHops assembles the route hops of all HTLC attempts of the payment
+// with the given ID, keyed by the DB ID of the attempt they belong to.
+func fetchRouteHops(ctx context.Context, db SQLQueries,
+	paymentID int64) (map[int64][]*route.Hop, error) {
+
+	hopRows, err := db.FetchPaymentRouteHops(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch route hops: %w", err)
+	}
+
+	records, err := db.FetchPaymentHopCustomRecords(ctx, paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch hop custom records: "+
+			"%w", err)
+	}
+
+	customRecords := make(map[int64]record.CustomSet)
+	for _, r := range records {
+		if customRecords[r.HopID] == nil {
+			customRecords[r.HopID] = make(record.CustomSet)
+		}
+		customRecords[r.HopID][uint64(r.Key)] =
+			customRecordValue(r.Value)
+	}
+
+	// The hops are returned ordered by attempt and hop index, so we can
+	// just append them to the route of their attempt.
+	hops := make(map[int64][]*route.Hop)
+	for _, row := range hopRows {
+		hop, err := unmarshalRouteHop(row, customRecords[row.ID])
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal hop: %w",
+				err)
+		}
+
+		hops[row.HtlcAttemptID] = append(hops[row.HtlcAttemptID], hop)
+	}
+
+	return hops, nil
+}
+
+// unmarshalRouteHop assembles the route hop of the given row.
+func unmarshalRouteHop(row sqlc.PaymentRouteHop,
+	customRecords record.CustomSet) (*route.Hop, error) {
+
+	pubKey, err := route.NewVertexFromBytes(row.PubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	hop := &route.Hop{
+		PubKeyBytes:      pubKey,
+		ChannelID:        uint64(row.Scid),
+		OutgoingTimeLock: uint32(row.OutgoingTimeLock),
+		AmtToForward:     lnwire.MilliLoki(row.AmtToForward),
+		CustomRecords:    customRecords,
+		LegacyPayload:    row.LegacyPayload,
+		Metadata:         row.MetaData,
+		EncryptedData:    row.EncryptedData,
+		TrampolineOnion:  row.TrampolineOnion,
+	}
+
+	if row.MppTotalMsat.Valid {
+		var paymentAddr [32]byte
+		copy(paymentAddr[:], row.MppPaymentAddr)
+
+		hop.MPP = record.NewMPP(
+			lnwire.MilliLoki(row.MppTotalMsat.Int64), paymentAddr,
+		)
+	}
+
+	if row.AmpChildIndex.Valid {
+		var rootShare, setID [32]byte
+		copy(rootShare[:], row.AmpRootShare)
+		copy(setID[:], row.AmpSetID)
+
+		hop.AMP = record.NewAMP(
+			rootShare, setID, uint32(row.AmpChildIndex.Int32),
+		)
+	}
+
+	if row.BlindingPoint != nil {
+		hop.BlindingPoint, err = crypto.ParsePubKey(row.BlindingPoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blinding point: %w",
+				err)
+		}
+	}
+
+	if row.BlindedPathTotalAmt.Valid {
+		hop.TotalAmtMsat = lnwire.MilliLoki(
+			row.BlindedPathTotalAmt.Int64,
+		)
+	}
+
+	return hop, nil
+}
+
+// settleAttemptParams returns the parameters to settle the given HTLC attempt
+// of a payment.
+func settleAttemptParams(paymentID int64, attemptID uint64,
+	settleInfo *HTLCSettleInfo) sqlc.SettlePaymentHtlcAttemptParams {
+
+	return sqlc.SettlePaymentHtlcAttemptParams{
+		PaymentID:      paymentID,
+		AttemptID:      int64(attemptID),
+		ResolutionTime: sqldb.SQLInt64(unixNano(settleInfo.SettleTime)),
+		SettlePreimage: settleInfo.Preimage[:],
+	}
+}
+
+// failAttemptParams returns the parameters to fail the given HTLC attempt of
+// a payment.
+func failAttemptParams(paymentID int64, attemptID uint64,
+	failInfo *HTLCFailInfo) (sqlc.FailPaymentHtlcAttemptParams, error) {
+
+	var failureMsg bytes.Buffer
+	if failInfo.Message != nil {
+		err := lnwire.EncodeFailureMessage(
+			&failureMsg, failInfo.Message, 0,
+		)
+		if err != nil {
+			return sqlc.FailPaymentHtlcAttemptParams{}, err
+		}
+	}
+
+	return sqlc.FailPaymentHtlcAttemptParams{
+		PaymentID:      paymentID,
+		AttemptID:      int64(attemptID),
+		ResolutionTime: sqldb.SQLInt64(unixNano(failInfo.FailTime)),
+		HtlcFailReason: sqldb.SQLInt16(failInfo.Reason),
+		FailureSourceIndex: sqldb.SQLInt32(
+			failInfo.FailureSourceIndex,
+		),
+		FailureMsg: sqlBytes(failureMsg.Bytes()),
+	}, nil
+}
+
+// unixNano returns the given time as unix nanoseconds. Like in the KV store, a
+// zero time is stored as zero since calling UnixNano on it yields an
+// undefined result.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano()
+}
+
+// timeFromUnixNano is the inverse of unixNano and interprets zero as the zero
+// time.
+func timeFromUnixNano(unixNano int64) time.Time {
+	if unixNano == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, unixNano)
+}
+
+// customRecordValue returns the value of a custom record read from the
+// database. Empty values may be read back as nil, which we turn into an empty
+// byte slice again.
+func customRecordValue(value []byte) []byte {
+	if value == nil {
+		return []byte{}
+	}
+
+	return value
+}
+
+// sqlBytes returns nil for an empty byte slice so that it is stored as NULL.
+func sqlBytes(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+
+	return b
+}
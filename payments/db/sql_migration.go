@@ -0,0 +1,228 @@
+package paymentsdb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"golang.org/x/time/rate"
+)
+
+// MigratePaymentsToSQL runs the migration of all payments from the KV database
+// to the SQL database. Payments are migrated in the order of their sequence
+// numbers so that the order in which they are returned when paginating is
+// preserved. Note that the sequence numbers themselves are not preserved, as
+// the SQL store uses an auto-incrementing primary key for this purpose.
+//
+// Legacy duplicate payments, which older versions allowed to be made to the
+// same payment hash, are not migrated since the SQL schema only permits a
+// single payment per payment hash.
+func MigratePaymentsToSQL(ctx context.Context, db kvdb.Backend,
+	tx *sqlc.Queries) error {
+
+	log.Infof("Starting migration of payments from KV to SQL")
+
+	s := rate.Sometimes{
+		Interval: 30 * time.Second,
+	}
+
+	var (
+		t0         = time.Now()
+		chunk      int
+		total      int
+		duplicates int
+	)
+	err := kvdb.View(db, func(kvTx kvdb.RTx) error {
+		payments := kvTx.ReadBucket(paymentsRootBucket)
+		if payments == nil {
+			return nil
+		}
+
+		indexes := kvTx.ReadBucket(paymentsIndexBucket)
+		if indexes == nil {
+			return fmt.Errorf("index bucket does not exist")
+		}
+
+		return indexes.ForEach(func(seqBytes, v []byte) error {
+			paymentHash, err := deserializePaymentIndex(
+				bytes.NewReader(v),
+			)
+			if err != nil {
+				return err
+			}
+
+			bucket := payments.NestedReadBucket(paymentHash[:])
+			if bucket == nil {
+				return fmt.Errorf("payment %v not found",
+					paymentHash)
+			}
+
+			// If the index doesn't point to the top level payment,
+			// it belongs to a legacy duplicate payment which we
+			// skip.
+			if !bytes.Equal(
+				bucket.Get(paymentSequenceKey), seqBytes,
+			) {
+
+				duplicates++
+
+				return nil
+			}
+
+			err = migrateSinglePayment(ctx, tx, paymentHash, bucket)
+			if err != nil {
+				return fmt.Errorf("unable to migrate "+
+					"payment(%v): %w", paymentHash, err)
+			}
+
+			total++
+			chunk++
+
+			s.Do(func() {
+				elapsed := time.Since(t0).Seconds()
+				ratePerSec := float64(chunk) / elapsed
+				log.Debugf("Migrated %d payments (%.2f "+
+					"payments/sec)", total, ratePerSec)
+
+				t0 = time.Now()
+				chunk = 0
+			})
+
+			return nil
+		})
+	}, func() {
+		chunk, total, duplicates = 0, 0, 0
+	})
+	if err != nil {
+		return err
+	}
+
+	if duplicates > 0 {
+		log.Warnf("Skipped %d legacy duplicate payments during "+
+			"migration", duplicates)
+	}
+
+	log.Infof("Migration of %d payments from KV to SQL completed", total)
+
+	return nil
+}
+
+// migrateSinglePayment migrates the payment stored in the given KV bucket to
+// the SQL database and verifies that the migrated payment matches the
+// original one.
+func migrateSinglePayment(ctx context.Context, tx SQLQueries,
+	paymentHash lntypes.Hash, bucket kvdb.RBucket) error {
+
+	payment, err := fetchPayment(bucket)
+	if err != nil {
+		return err
+	}
+
+	paymentID, err := insertPayment(
+		ctx, tx, paymentHash, payment.Info, payment.Status,
+	)
+	if err != nil {
+		return err
+	}
+
+	if payment.FailureReason != nil {
+		reason := *payment.FailureReason
+		err := tx.UpdatePaymentFailReason(
+			ctx, sqlc.UpdatePaymentFailReasonParams{
+				ID:         paymentID,
+				FailReason: sqldb.SQLInt16(reason),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to set fail reason: %w", err)
+		}
+	}
+
+	for _, htlc := range payment.HTLCs {
+		err := insertHtlcAttempt(
+			ctx, tx, paymentID, &htlc.HTLCAttemptInfo,
+		)
+		if err != nil {
+			return err
+		}
+
+		if htlc.Settle != nil {
+			err := tx.SettlePaymentHtlcAttempt(
+				ctx, settleAttemptParams(
+					paymentID, htlc.AttemptID, htlc.Settle,
+				),
+			)
+			if err != nil {
+				return fmt.Errorf("unable to settle htlc "+
+					"attempt: %w", err)
+			}
+		}
+
+		if htlc.Failure != nil {
+			params, err := failAttemptParams(
+				paymentID, htlc.AttemptID, htlc.Failure,
+			)
+			if err != nil {
+				return err
+			}
+
+			err = tx.FailPaymentHtlcAttempt(ctx, params)
+			if err != nil {
+				return fmt.Errorf("unable to fail htlc "+
+					"attempt: %w", err)
+			}
+		}
+	}
+
+	row, err := fetchPaymentRow(ctx, tx, paymentHash)
+	if err != nil {
+		return fmt.Errorf("unable to fetch migrated payment: %w", err)
+	}
+
+	migratedPayment, err := fetchSQLPayment(ctx, tx, row)
+	if err != nil {
+		return fmt.Errorf("unable to fetch migrated payment: %w", err)
+	}
+
+	// Override the sequence number before checking for equality.
+	migratedPayment.SequenceNum = payment.SequenceNum
+
+	return sqldb.CompareRecords(
+		normalizePayment(payment), normalizePayment(migratedPayment),
+		"payment",
+	)
+}
+
+// normalizePayment brings the given payment into a canonical form in which
+// empty byte slices and maps are nil. The KV store returns empty but non-nil
+// values for some of the fields that the SQL store stores as NULL, which must
+// not make a migrated payment differ from its original.
+func normalizePayment(p *MPPayment) *MPPayment {
+	if len(p.Info.PaymentRequest) == 0 {
+		p.Info.PaymentRequest = nil
+	}
+
+	for _, htlc := range p.HTLCs {
+		for _, hop := range htlc.Route.Hops {
+			if len(hop.CustomRecords) == 0 {
+				hop.CustomRecords = nil
+			}
+			if len(hop.Metadata) == 0 {
+				hop.Metadata = nil
+			}
+			if len(hop.EncryptedData) == 0 {
+				hop.EncryptedData = nil
+			}
+			if len(hop.TrampolineOnion) == 0 {
+				hop.TrampolineOnion = nil
+			}
+		}
+	}
+
+	return p
+}
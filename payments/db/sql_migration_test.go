@@ -0,0 +1,189 @@
+package paymentsdb
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/record"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigratePaymentsToSQL checks that payments are migrated from the KV store
+// to the SQL store in order and with all their HTLC attempts, and that legacy
+// duplicate payments are skipped.
+func TestMigratePaymentsToSQL(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	kvStore := NewKVTestDB(t)
+
+	payments := []*payment{
+		{status: StatusFailed},
+		{status: StatusSucceeded},
+		{status: StatusInFlight},
+		{status: StatusSucceeded},
+	}
+	createTestPayments(t, kvStore, payments)
+
+	// Add a legacy duplicate payment to the last payment.
+	last, err := kvStore.FetchPayment(payments[3].id)
+	require.NoError(t, err)
+	appendDuplicatePayment(
+		t, kvStore.db, payments[3].id, last.SequenceNum+1,
+		lntypes.Preimage{1},
+	)
+
+	sqlStore := migrateToSQLStore(t, kvStore)
+
+	// The migrated payments are returned in the same order and only the
+	// duplicate payment is missing.
+	assertDBPayments(t, sqlStore, payments)
+
+	resp, err := sqlStore.QueryPayments(ctx, Query{
+		MaxPayments:       10,
+		IncludeIncomplete: true,
+		CountTotal:        true,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, len(payments), resp.TotalCount)
+
+	for _, p := range payments {
+		kvPayment, err := kvStore.FetchPayment(p.id)
+		require.NoError(t, err)
+
+		sqlPayment, err := sqlStore.FetchPayment(p.id)
+		require.NoError(t, err)
+
+		sqlPayment.SequenceNum = kvPayment.SequenceNum
+		require.Equal(t, kvPayment, sqlPayment)
+	}
+
+	inFlight, err := sqlStore.FetchInFlightPayments()
+	require.NoError(t, err)
+	require.Len(t, inFlight, 1)
+	require.Equal(t, payments[2].id, inFlight[0].Info.PaymentIdentifier)
+
+	// The migrated in-flight payment can still be resolved.
+	_, err = sqlStore.FailAttempt(
+		payments[2].id, inFlight[0].HTLCs[1].AttemptID,
+		&HTLCFailInfo{Reason: HTLCFailUnreadable},
+	)
+	require.NoError(t, err)
+
+	failed, err := sqlStore.Fail(payments[2].id, FailureReasonNoRoute)
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, failed.Status)
+}
+
+// TestMigratePaymentDetailsToSQL checks that all the details of a payment, its
+// HTLC attempts, their routes and resolutions survive the migration into the
+// SQL schema.
+func TestMigratePaymentDetailsToSQL(t *testing.T) {
+	t.Parallel()
+
+	kvStore := NewKVTestDB(t)
+
+	info, attempt, preimage, err := genInfo(t)
+	require.NoError(t, err)
+
+	hash := info.PaymentIdentifier
+	info.PaymentRequest = nil
+	info.Offer = []byte("offer")
+	info.PayerNote = "payer note"
+	info.FirstHopCustomRecords = lnwire.CustomRecords{
+		65536: []byte{1},
+		65537: []byte{},
+	}
+	require.NoError(t, kvStore.InitPayment(hash, info))
+
+	// The first attempt is sent along a blinded route with custom records
+	// for the first hop and fails with a wire message.
+	attempt.Route = *testBlindedRoute.Copy()
+	attempt.Route.FinalHop().EncryptedData = []byte{7, 8, 9}
+	attempt.Route.FirstHopAmount = tlv.NewRecordT[tlv.TlvType0](
+		tlv.NewBigSizeT(lnwire.MilliLoki(1100)),
+	)
+	attempt.Route.FirstHopWireCustomRecords = lnwire.CustomRecords{
+		65540: []byte{2, 3},
+	}
+	attempt.AttemptTime = time.Unix(10, 20)
+	_, err = kvStore.RegisterAttempt(hash, attempt)
+	require.NoError(t, err)
+
+	_, err = kvStore.FailAttempt(hash, attempt.AttemptID, &HTLCFailInfo{
+		FailTime:           time.Unix(11, 0),
+		Message:            lnwire.NewTemporaryChannelFailure(nil),
+		Reason:             HTLCFailMessage,
+		FailureSourceIndex: 1,
+	})
+	require.NoError(t, err)
+
+	// The second attempt pays to an AMP final hop and is settled.
+	ampRoute := testRoute.Copy()
+	ampRoute.Hops[1].MPP = record.NewMPP(info.Value, [32]byte{0x42})
+	ampRoute.Hops[1].AMP = record.NewAMP([32]byte{1}, [32]byte{2}, 3)
+	second, err := NewHtlcAttempt(
+		attempt.AttemptID+1, priv, *ampRoute, time.Unix(12, 0),
+		attempt.Hash,
+	)
+	require.NoError(t, err)
+
+	_, err = kvStore.RegisterAttempt(hash, &second.HTLCAttemptInfo)
+	require.NoError(t, err)
+
+	_, err = kvStore.SettleAttempt(
+		hash, second.AttemptID, &HTLCSettleInfo{
+			Preimage:   preimage,
+			SettleTime: time.Unix(13, 0),
+		},
+	)
+	require.NoError(t, err)
+
+	sqlStore := migrateToSQLStore(t, kvStore)
+
+	kvPayment, err := kvStore.FetchPayment(hash)
+	require.NoError(t, err)
+
+	sqlPayment, err := sqlStore.FetchPayment(hash)
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, sqlPayment.Status)
+
+	sqlPayment.SequenceNum = kvPayment.SequenceNum
+	require.Equal(t, normalizePayment(kvPayment),
+		normalizePayment(sqlPayment))
+}
+
+// migrateToSQLStore migrates the payments of the given KV store into a fresh
+// SQL database and returns a SQL store on top of it.
+func migrateToSQLStore(t *testing.T, kvStore *KVStore) *SQLStore {
+	t.Helper()
+
+	ctx := t.Context()
+
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+	genericExecutor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) *sqlc.Queries {
+			return db.WithTx(tx)
+		},
+	)
+	err := genericExecutor.ExecTx(
+		ctx, sqldb.WriteTxOpt(), func(tx *sqlc.Queries) error {
+			return MigratePaymentsToSQL(ctx, kvStore.db, tx)
+		}, sqldb.NoOpReset,
+	)
+	require.NoError(t, err)
+
+	paymentsExecutor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) SQLQueries {
+			return db.WithTx(tx)
+		},
+	)
+
+	return NewSQLStore(paymentsExecutor)
+}
@@ -0,0 +1,752 @@
+package paymentsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+)
+
+const (
+	// defaultQueryPaginationLimit is used in the LIMIT clause of the SQL
+	// queries to limit the number of rows returned at once.
+	defaultQueryPaginationLimit = 100
+)
+
+// SQLQueries is an interface that defines the set of operations that can be
+// executed against the payments SQL database.
+type SQLQueries interface { //nolint:interfacebloat
+	InsertPayment(ctx context.Context,
+		arg sqlc.InsertPaymentParams) (int64, error)
+
+	FetchPayment(ctx context.Context, paymentHash []byte) (sqlc.Payment,
+		error)
+
+	FilterPayments(ctx context.Context,
+		arg sqlc.FilterPaymentsParams) ([]sqlc.Payment, error)
+
+	FetchInFlightPayments(ctx context.Context) ([]sqlc.Payment, error)
+
+	CountPayments(ctx context.Context) (int64, error)
+
+	UpdatePaymentStatus(ctx context.Context,
+		arg sqlc.UpdatePaymentStatusParams) error
+
+	UpdatePaymentFailReason(ctx context.Context,
+		arg sqlc.UpdatePaymentFailReasonParams) error
+
+	DeletePayment(ctx context.Context, id int64) error
+
+	DeletePaymentsByStatus(ctx context.Context, status int16) (sql.Result,
+		error)
+
+	InsertPaymentFirstHopCustomRecord(ctx context.Context,
+		arg sqlc.InsertPaymentFirstHopCustomRecordParams) error
+
+	FetchPaymentFirstHopCustomRecords(ctx context.Context,
+		paymentID int64) ([]sqlc.PaymentFirstHopCustomRecord, error)
+
+	// HTLC attempt specific methods.
+	InsertPaymentHtlcAttempt(ctx context.Context,
+		arg sqlc.InsertPaymentHtlcAttemptParams) (int64, error)
+
+	InsertPaymentAttemptFirstHopCustomRecord(ctx context.Context,
+		arg sqlc.InsertPaymentAttemptFirstHopCustomRecordParams) error
+
+	FetchPaymentHtlcAttempts(ctx context.Context,
+		paymentID int64) ([]sqlc.PaymentHtlcAttempt, error)
+
+	FetchPaymentAttemptFirstHopCustomRecords(ctx context.Context,
+		paymentID int64) ([]sqlc.PaymentAttemptFirstHopCustomRecord,
+		error)
+
+	SettlePaymentHtlcAttempt(ctx context.Context,
+		arg sqlc.SettlePaymentHtlcAttemptParams) error
+
+	FailPaymentHtlcAttempt(ctx context.Context,
+		arg sqlc.FailPaymentHtlcAttemptParams) error
+
+	DeleteFailedPaymentHtlcAttempts(ctx context.Context,
+		paymentID int64) error
+
+	DeleteFailedPaymentHtlcAttemptsByStatus(ctx context.Context,
+		status int16) error
+
+	// Route specific methods.
+	InsertPaymentRouteHop(ctx context.Context,
+		arg sqlc.InsertPaymentRouteHopParams) (int64, error)
+
+	InsertPaymentHopCustomRecord(ctx context.Context,
+		arg sqlc.InsertPaymentHopCustomRecordParams) error
+
+	FetchPaymentRouteHops(ctx context.Context,
+		paymentID int64) ([]sqlc.PaymentRouteHop, error)
+
+	FetchPaymentHopCustomRecords(ctx context.Context,
+		paymentID int64) ([]sqlc.PaymentHopCustomRecord, error)
+}
+
+// BatchedSQLQueries is a version of the SQLQueries that's capable of batched
+// database operations.
+type BatchedSQLQueries interface {
+	SQLQueries
+
+	sqldb.BatchedTx[SQLQueries]
+}
+
+// SQLStore implements persistence for payments and payment attempts on top of
+// a native SQL database.
+type SQLStore struct {
+	db BatchedSQLQueries
+
+	// keepFailedPaymentAttempts is a flag that indicates whether we should
+	// keep failed payment attempts in the database.
+	keepFailedPaymentAttempts bool
+}
+
+// A compile-time constraint to ensure SQLStore implements DB.
+var _ DB = (*SQLStore)(nil)
+
+// NewSQLStore creates a new SQLStore for payments given an open
+// BatchedSQLQueries storage backend.
+func NewSQLStore(db BatchedSQLQueries, options ...OptionModifier) *SQLStore {
+	opts := DefaultOptions()
+	for _, applyOption := range options {
+		applyOption(opts)
+	}
+
+	return &SQLStore{
+		db:                        db,
+		keepFailedPaymentAttempts: opts.KeepFailedPaymentAttempts,
+	}
+}
+
+// InitPayment checks or records the given PaymentCreationInfo with the DB,
+// making sure it does not already exist as an in-flight payment. When this
+// method returns successfully, the payment is guaranteed to be in the
+// Initiated state.
+func (s *SQLStore) InitPayment(paymentHash lntypes.Hash,
+	info *PaymentCreationInfo) error {
+
+	ctx := context.TODO()
+
+	var updateErr error
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, err := db.FetchPayment(ctx, paymentHash[:])
+		switch {
+		// If we already have this payment, we'll check the status to
+		// decide whether we allow retrying the payment or return a
+		// specific error.
+		case err == nil:
+			status := PaymentStatus(row.Status)
+			if err := status.initializable(); err != nil {
+				updateErr = err
+				return nil
+			}
+
+			// The payment is being retried, so we remove the old
+			// record together with its HTLC attempts. Inserting it
+			// again assigns a new sequence number and clears any
+			// lingering failure info.
+			if err := db.DeletePayment(ctx, row.ID); err != nil {
+				return fmt.Errorf("unable to delete payment: "+
+					"%w", err)
+			}
+
+		case !errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("unable to fetch payment: %w", err)
+		}
+
+		_, err = insertPayment(
+			ctx, db, paymentHash, info, StatusInitiated,
+		)
+
+		return err
+	}, func() {
+		updateErr = nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to init payment: %w", err)
+	}
+
+	return updateErr
+}
+
+// RegisterAttempt atomically records the provided HTLCAttemptInfo to the
+// DB.
+func (s *SQLStore) RegisterAttempt(paymentHash lntypes.Hash,
+	attempt *HTLCAttemptInfo) (*MPPayment, error) {
+
+	ctx := context.TODO()
+
+	var payment *MPPayment
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, err := fetchPaymentRow(ctx, db, paymentHash)
+		if err != nil {
+			return err
+		}
+
+		payment, err = fetchSQLPayment(ctx, db, row)
+		if err != nil {
+			return err
+		}
+
+		// Check if registering a new attempt is allowed.
+		if err := payment.Registrable(); err != nil {
+			return err
+		}
+
+		// Verify the attempt is compatible with the existing payment.
+		if err := verifyAttempt(payment, attempt); err != nil {
+			return err
+		}
+
+		err = insertHtlcAttempt(ctx, db, row.ID, attempt)
+		if err != nil {
+			return err
+		}
+
+		// Retrieve attempt info for the notification.
+		payment, err = refreshPayment(ctx, db, row)
+
+		return err
+	}, func() {
+		payment = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// SettleAttempt marks the given attempt settled with the preimage. If this is
+// a multi shard payment, this might implicitly mean that the full payment
+// succeeded.
+//
+// After invoking this method, InitPayment should always return an error to
+// prevent us from making duplicate payments to the same payment hash. The
+// provided preimage is atomically saved to the DB for record keeping.
+func (s *SQLStore) SettleAttempt(hash lntypes.Hash,
+	attemptID uint64, settleInfo *HTLCSettleInfo) (*MPPayment, error) {
+
+	return s.updateHtlc(
+		hash, attemptID, func(ctx context.Context, db SQLQueries,
+			paymentID int64) error {
+
+			return db.SettlePaymentHtlcAttempt(
+				ctx, settleAttemptParams(
+					paymentID, attemptID, settleInfo,
+				),
+			)
+		},
+	)
+}
+
+// FailAttempt marks the given payment attempt failed.
+func (s *SQLStore) FailAttempt(hash lntypes.Hash,
+	attemptID uint64, failInfo *HTLCFailInfo) (*MPPayment, error) {
+
+	return s.updateHtlc(
+		hash, attemptID, func(ctx context.Context, db SQLQueries,
+			paymentID int64) error {
+
+			params, err := failAttemptParams(
+				paymentID, attemptID, failInfo,
+			)
+			if err != nil {
+				return err
+			}
+
+			return db.FailPaymentHtlcAttempt(ctx, params)
+		},
+	)
+}
+
+// updateHtlc checks that the given HTLC attempt of the payment can still be
+// resolved and then applies the passed update to it.
+func (s *SQLStore) updateHtlc(paymentHash lntypes.Hash, attemptID uint64,
+	update func(context.Context, SQLQueries, int64) error) (*MPPayment,
+	error) {
+
+	ctx := context.TODO()
+
+	var payment *MPPayment
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, err := fetchPaymentRow(ctx, db, paymentHash)
+		if err != nil {
+			return err
+		}
+
+		p, err := fetchSQLPayment(ctx, db, row)
+		if err != nil {
+			return err
+		}
+
+		// We can only update HTLCs of in-flight payments. We allow
+		// updating them even if the payment has reached a terminal
+		// condition, since the HTLC outcomes must still be updated.
+		if err := p.Status.updatable(); err != nil {
+			return err
+		}
+
+		htlc, err := p.GetAttempt(attemptID)
+		if err != nil {
+			return fmt.Errorf("HTLC with ID %v not registered",
+				attemptID)
+		}
+
+		// Make sure the shard is not already failed or settled.
+		if htlc.Failure != nil {
+			return ErrAttemptAlreadyFailed
+		}
+
+		if htlc.Settle != nil {
+			return ErrAttemptAlreadySettled
+		}
+
+		if err := update(ctx, db, row.ID); err != nil {
+			return fmt.Errorf("unable to update htlc attempt: %w",
+				err)
+		}
+
+		// Retrieve attempt info for the notification.
+		payment, err = refreshPayment(ctx, db, row)
+
+		return err
+	}, func() {
+		payment = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// Fail transitions a payment into the Failed state, and records the reason the
+// payment failed. After invoking this method, InitPayment should return nil on
+// its next call for this payment hash, allowing the switch to make a
+// subsequent payment.
+func (s *SQLStore) Fail(paymentHash lntypes.Hash,
+	reason FailureReason) (*MPPayment, error) {
+
+	ctx := context.TODO()
+
+	var payment *MPPayment
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, err := fetchPaymentRow(ctx, db, paymentHash)
+		if err != nil {
+			return err
+		}
+
+		// We mark the payment as failed as long as it is known. This
+		// lets the last attempt to fail with a terminal write its
+		// failure to the store without synchronizing with other
+		// attempts.
+		err = db.UpdatePaymentFailReason(
+			ctx, sqlc.UpdatePaymentFailReasonParams{
+				ID:         row.ID,
+				FailReason: sqldb.SQLInt16(reason),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to update fail reason: %w",
+				err)
+		}
+
+		row.FailReason = sqldb.SQLInt16(reason)
+
+		// Retrieve attempt info for the notification, if available.
+		payment, err = refreshPayment(ctx, db, row)
+
+		return err
+	}, func() {
+		payment = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// DeleteFailedAttempts deletes all failed htlcs for a payment if configured
+// by the SQLStore db.
+func (s *SQLStore) DeleteFailedAttempts(hash lntypes.Hash) error {
+	if !s.keepFailedPaymentAttempts {
+		const failedHtlcsOnly = true
+		err := s.DeletePayment(hash, failedHtlcsOnly)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FetchPayment returns information about a payment from the database.
+func (s *SQLStore) FetchPayment(paymentHash lntypes.Hash) (*MPPayment,
+	error) {
+
+	ctx := context.TODO()
+
+	var payment *MPPayment
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		row, err := fetchPaymentRow(ctx, db, paymentHash)
+		if err != nil {
+			return err
+		}
+
+		payment, err = fetchSQLPayment(ctx, db, row)
+
+		return err
+	}, func() {
+		payment = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// FetchInFlightPayments returns all payments with status InFlight.
+func (s *SQLStore) FetchInFlightPayments() ([]*MPPayment, error) {
+	ctx := context.TODO()
+	start := time.Now()
+
+	var inFlights []*MPPayment
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		rows, err := db.FetchInFlightPayments(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to fetch inflight payments: "+
+				"%w", err)
+		}
+
+		for _, row := range rows {
+			p, err := fetchSQLPayment(ctx, db, row)
+			if err != nil {
+				return err
+			}
+
+			// The stored status should always match the derived
+			// one, but we double-check to not return a payment
+			// that is already terminated.
+			if p.Terminated() {
+				continue
+			}
+
+			inFlights = append(inFlights, p)
+		}
+
+		return nil
+	}, func() {
+		inFlights = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Completed scanning for inflight payments: "+
+		"found_inflight=%d, elapsed=%v", len(inFlights),
+		time.Since(start).Round(time.Millisecond))
+
+	return inFlights, nil
+}
+
+// QueryPayments is a query to the payments database which is restricted
+// to a subset of payments by the payments query, containing an offset
+// index and a maximum number of returned payments.
+func (s *SQLStore) QueryPayments(ctx context.Context,
+	query Query) (Response, error) {
+
+	var resp Response
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(), func(db SQLQueries) error {
+		// The index offset is always exclusive, so depending on the
+		// direction we start one above or one below it.
+		var indexGet, indexLet sql.NullInt64
+		switch {
+		case !query.Reversed:
+			indexGet = sqldb.SQLInt64(query.IndexOffset + 1)
+
+		case query.IndexOffset > 0:
+			indexLet = sqldb.SQLInt64(query.IndexOffset - 1)
+		}
+
+		// To keep compatibility with the old API, we only return
+		// non-succeeded payments if requested.
+		var status sql.NullInt16
+		if !query.IncludeIncomplete {
+			status = sqldb.SQLInt16(StatusSucceeded)
+		}
+
+		// The creation date range is given in unix seconds, while the
+		// creation time is stored in nanoseconds. The end of the range
+		// is inclusive, so it covers the entire last second.
+		var createdAfter, createdBefore sql.NullInt64
+		if query.CreationDateStart != 0 {
+			start := time.Unix(query.CreationDateStart, 0)
+			createdAfter = sqldb.SQLInt64(start.UnixNano())
+		}
+		if query.CreationDateEnd != 0 {
+			end := time.Unix(query.CreationDateEnd+1, 0)
+			createdBefore = sqldb.SQLInt64(end.UnixNano() - 1)
+		}
+
+		for uint64(len(resp.Payments)) < query.MaxPayments {
+			limit := query.MaxPayments - uint64(len(resp.Payments))
+			limit = min(limit, defaultQueryPaginationLimit)
+
+			rows, err := db.FilterPayments(
+				ctx, sqlc.FilterPaymentsParams{
+					IndexGet:      indexGet,
+					IndexLet:      indexLet,
+					Status:        status,
+					CreatedAfter:  createdAfter,
+					CreatedBefore: createdBefore,
+					Reverse:       query.Reversed,
+					NumLimit:      int32(limit),
+				},
+			)
+			if err != nil {
+				return fmt.Errorf("unable to query payments: "+
+					"%w", err)
+			}
+
+			for _, row := range rows {
+				payment, err := fetchSQLPayment(ctx, db, row)
+				if err != nil {
+					return err
+				}
+
+				resp.Payments = append(resp.Payments, payment)
+			}
+
+			if uint64(len(rows)) < limit {
+				break
+			}
+
+			// Continue the next page right after the last row we
+			// have read.
+			lastID := rows[len(rows)-1].ID
+			if query.Reversed {
+				indexLet = sqldb.SQLInt64(lastID - 1)
+			} else {
+				indexGet = sqldb.SQLInt64(lastID + 1)
+			}
+		}
+
+		if query.CountTotal {
+			total, err := db.CountPayments(ctx)
+			if err != nil {
+				return fmt.Errorf("error counting payments: %w",
+					err)
+			}
+
+			resp.TotalCount = uint64(total)
+		}
+
+		return nil
+	}, func() {
+		resp = Response{}
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	// Need to swap the payments slice order if reversed order.
+	if query.Reversed {
+		for l, r := 0, len(resp.Payments)-1; l < r; l, r = l+1, r-1 {
+			resp.Payments[l], resp.Payments[r] =
+				resp.Payments[r], resp.Payments[l]
+		}
+	}
+
+	// Set the first and last index of the returned payments so that the
+	// caller can resume from this point later on.
+	if len(resp.Payments) > 0 {
+		resp.FirstIndexOffset = resp.Payments[0].SequenceNum
+		resp.LastIndexOffset =
+			resp.Payments[len(resp.Payments)-1].SequenceNum
+	}
+
+	return resp, nil
+}
+
+// DeletePayment deletes a payment from the DB given its payment hash. If
+// failedHtlcsOnly is set, only failed HTLC attempts of the payment will be
+// deleted.
+func (s *SQLStore) DeletePayment(paymentHash lntypes.Hash,
+	failedHtlcsOnly bool) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		row, err := fetchPaymentRow(ctx, db, paymentHash)
+		if err != nil {
+			return err
+		}
+
+		// If the payment has inflight HTLCs, we cannot safely delete
+		// the payment information, so we return an error.
+		status := PaymentStatus(row.Status)
+		if err := status.removable(); err != nil {
+			return fmt.Errorf("payment '%v' has inflight HTLCs"+
+				"and therefore cannot be deleted: %w",
+				paymentHash.String(), err)
+		}
+
+		if !failedHtlcsOnly {
+			return db.DeletePayment(ctx, row.ID)
+		}
+
+		// Delete the failed HTLC attempts of the payment. This never
+		// changes the status of a payment that is removable.
+		return db.DeleteFailedPaymentHtlcAttempts(ctx, row.ID)
+	}, sqldb.NoOpReset)
+}
+
+// DeletePayments deletes all completed and failed payments from the DB. If
+// failedOnly is set, only failed payments will be considered for deletion. If
+// failedHtlcsOnly is set, the payment itself won't be deleted, only failed HTLC
+// attempts. The method returns the number of deleted payments, which is always
+// 0 if failedHtlcsOnly is set.
+func (s *SQLStore) DeletePayments(failedOnly,
+	failedHtlcsOnly bool) (int, error) {
+
+	ctx := context.TODO()
+
+	// Payments with inflight HTLCs are never deleted.
+	statuses := []PaymentStatus{StatusFailed}
+	if !failedOnly {
+		statuses = append(statuses, StatusInitiated, StatusSucceeded)
+	}
+
+	var numPayments int
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(), func(db SQLQueries) error {
+		for _, status := range statuses {
+			if failedHtlcsOnly {
+				//nolint:ll
+				err := db.DeleteFailedPaymentHtlcAttemptsByStatus(
+					ctx, int16(status),
+				)
+				if err != nil {
+					return fmt.Errorf("unable to delete "+
+						"failed htlcs: %w", err)
+				}
+
+				continue
+			}
+
+			res, err := db.DeletePaymentsByStatus(
+				ctx, int16(status),
+			)
+			if err != nil {
+				return fmt.Errorf("unable to delete payments: "+
+					"%w", err)
+			}
+
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			numPayments += int(n)
+		}
+
+		return nil
+	}, func() {
+		numPayments = 0
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return numPayments, nil
+}
+
+// fetchPaymentRow fetches the payment row for the given payment hash. If the
+// payment does not exist, ErrPaymentNotInitiated is returned.
+func fetchPaymentRow(ctx context.Context, db SQLQueries,
+	paymentHash lntypes.Hash) (sqlc.Payment, error) {
+
+	row, err := db.FetchPayment(ctx, paymentHash[:])
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return sqlc.Payment{}, ErrPaymentNotInitiated
+
+	case err != nil:
+		return sqlc.Payment{}, fmt.Errorf("unable to fetch payment: %w",
+			err)
+	}
+
+	return row, nil
+}
+
+// refreshPayment reloads the payment of the given row after one of its HTLC
+// attempts or its failure reason has changed and persists its new status.
+func refreshPayment(ctx context.Context, db SQLQueries,
+	row sqlc.Payment) (*MPPayment, error) {
+
+	payment, err := fetchSQLPayment(ctx, db, row)
+	if err != nil {
+		return nil, err
+	}
+
+	if int16(payment.Status) == row.Status {
+		return payment, nil
+	}
+
+	err = db.UpdatePaymentStatus(ctx, sqlc.UpdatePaymentStatusParams{
+		ID:     row.ID,
+		Status: int16(payment.Status),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to update payment status: %w",
+			err)
+	}
+
+	return payment, nil
+}
+
+// fetchSQLPayment assembles the full payment of the given row, including all of
+// its HTLC attempts and their routes.
+func fetchSQLPayment(ctx context.Context, db SQLQueries,
+	row sqlc.Payment) (*MPPayment, error) {
+
+	creationInfo, err := fetchPaymentCreationInfo(ctx, db, row)
+	if err != nil {
+		return nil, err
+	}
+
+	htlcs, err := fetchSQLHtlcAttempts(ctx, db, row.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var failureReason *FailureReason
+	if row.FailReason.Valid {
+		reason := FailureReason(row.FailReason.Int16)
+		failureReason = &reason
+	}
+
+	payment := &MPPayment{
+		SequenceNum:   uint64(row.ID),
+		Info:          creationInfo,
+		HTLCs:         htlcs,
+		FailureReason: failureReason,
+	}
+
+	// Set its state and status.
+	if err := payment.setState(); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
@@ -1,3 +1,5 @@
+//go:build !test_db_sqlite && !test_db_postgres
+
 package paymentsdb
 
 import (
@@ -21,20 +23,3 @@ func NewTestDB(t *testing.T, opts ...OptionModifier) DB {
 
 	return paymentDB
 }
-
-// NewKVTestDB is a helper function that creates an BBolt database for testing
-// and there is no need to convert the interface to the KVStore because for
-// some unit tests we still need access to the kvdb interface.
-func NewKVTestDB(t *testing.T, opts ...OptionModifier) *KVStore {
-	backend, backendCleanup, err := kvdb.GetTestBackend(
-		t.TempDir(), "kvPaymentDB",
-	)
-	require.NoError(t, err)
-
-	t.Cleanup(backendCleanup)
-
-	paymentDB, err := NewKVStore(backend, opts...)
-	require.NoError(t, err)
-
-	return paymentDB
-}
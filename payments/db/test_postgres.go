@@ -0,0 +1,31 @@
+//go:build test_db_postgres && !test_db_sqlite
+
+package paymentsdb
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/flokiorg/flnd/sqldb"
+)
+
+// NewTestDB is a helper function that creates a SQLStore backed by a Postgres
+// database for testing.
+func NewTestDB(t *testing.T, opts ...OptionModifier) DB {
+	pgFixture := sqldb.NewTestPgFixture(
+		t, sqldb.DefaultPostgresFixtureLifetime,
+	)
+	t.Cleanup(func() {
+		pgFixture.TearDown(t)
+	})
+
+	db := sqldb.NewTestPostgresDB(t, pgFixture).BaseDB
+
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) SQLQueries {
+			return db.WithTx(tx)
+		},
+	)
+
+	return NewSQLStore(executor, opts...)
+}
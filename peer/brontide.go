@@ -48,6 +48,7 @@ import (
 	"github.com/flokiorg/flnd/msgmux"
 	"github.com/flokiorg/flnd/netann"
 	"github.com/flokiorg/flnd/onionmessage"
+	"github.com/flokiorg/flnd/peerstorage"
 	"github.com/flokiorg/flnd/pool"
 	"github.com/flokiorg/flnd/protofsm"
 	"github.com/flokiorg/flnd/queue"
@@ -368,6 +369,12 @@ type Config struct {
 	// FundingManager is an implementation of the funding.Controller interface.
 	FundingManager funding.Controller
 
+	// PeerStorage is an optional implementation of the
+	// peerstorage.Controller interface, which stores the backup blobs the
+	// peer asks us to store and handles the blobs it returns to us. If
+	// not set, peer storage messages are ignored.
+	PeerStorage fn.Option[peerstorage.Controller]
+
 	// Hodl is used when creating ChannelLinks to specify HodlFlags as
 	// breakpoints in dev builds.
 	Hodl *hodl.Config
@@ -476,6 +483,10 @@ type Config struct {
 	// otherwise the node will disconnect.
 	QuiescenceTimeout time.Duration
 
+	// MaxLocalCSVDelay is the maximum csv delay we'll accept for our own
+	// outputs when the remote party proposes new channel parameters.
+	MaxLocalCSVDelay uint16
+
 	// MaxFeeExposure limits the number of outstanding fees in a channel.
 	// This value will be passed to created links.
 	MaxFeeExposure lnwire.MilliLoki
@@ -494,6 +505,10 @@ type Config struct {
 	// related wire messages.
 	AuxChannelNegotiator fn.Option[lnwallet.AuxChannelNegotiator]
 
+	// TrampolineForwarder is an optional subsystem that relays the
+	// payments we receive as a trampoline node.
+	TrampolineForwarder fn.Option[htlcswitch.TrampolineForwarder]
+
 	// ShouldFwdExpAccountability is a closure that indicates whether
 	// experimental accountability signals should be set.
 	ShouldFwdExpAccountability func() bool
@@ -1539,7 +1554,24 @@ func (p *Brontide) addLink(chanPoint *wire.OutPoint,
 			!p.remoteFeatures.HasFeature(lnwire.QuiescenceOptional),
 		AuxTrafficShaper:     p.cfg.AuxTrafficShaper,
 		AuxChannelNegotiator: p.cfg.AuxChannelNegotiator,
+		TrampolineForwarder:  p.cfg.TrampolineForwarder,
 		QuiescenceTimeout:    p.cfg.QuiescenceTimeout,
+		DisallowDynCommitments: !p.cfg.Features.HasFeature(
+			lnwire.DynamicCommitmentsOptional,
+		) || !p.remoteFeatures.HasFeature(
+			lnwire.DynamicCommitmentsOptional,
+		),
+		MessageSigner:    p.cfg.Wallet.Cfg.SecretKeyRing,
+		MaxLocalCSVDelay: p.cfg.MaxLocalCSVDelay,
+		NotifyChannelParamsUpdate: p.cfg.ChannelNotifier.
+			NotifyChannelParamsUpdateEvent,
+		DisallowSplicing: !p.cfg.Features.HasFeature(
+			lnwire.SpliceOptionalStaging,
+		) || !p.remoteFeatures.HasFeature(
+			lnwire.SpliceOptionalStaging,
+		),
+		SpliceWallet:  p.cfg.Wallet,
+		ChainNotifier: p.cfg.ChainNotifier,
 	}
 
 	// Before adding our new link, purge the switch of any pending or live
@@ -2303,7 +2335,8 @@ out:
 			*lnwire.AcceptChannel,
 			*lnwire.FundingCreated,
 			*lnwire.FundingSigned,
-			*lnwire.ChannelReady:
+			*lnwire.ChannelReady,
+			*lnwire.AnnounceSignatures2:
 
 			p.cfg.FundingManager.ProcessFundingMsg(msg, p)
 
@@ -2353,6 +2386,21 @@ out:
 		// if the channel is not active yet.
 		case lnwire.LinkUpdater:
 			targetChan = msg.TargetChanID()
+
+			// The funding transaction of a pending dual-funded
+			// channel is constructed, signed and replaced through
+			// the funding manager, which also handles the
+			// commitment signatures of a replacement.
+			if !p.isActiveChannel(targetChan) &&
+				p.cfg.FundingManager.IsPendingChannel(
+					targetChan, p,
+				) {
+
+				p.cfg.FundingManager.ProcessFundingMsg(msg, p)
+
+				break
+			}
+
 			isLinkUpdate = p.hasChannel(targetChan)
 
 			// Log an error if we don't have this channel. This
@@ -2366,7 +2414,10 @@ out:
 
 		case *lnwire.ChannelUpdate1,
 			*lnwire.ChannelAnnouncement1,
+			*lnwire.ChannelUpdate2,
+			*lnwire.ChannelAnnouncement2,
 			*lnwire.NodeAnnouncement1,
+			*lnwire.NodeAnnouncement2,
 			*lnwire.AnnounceSignatures1,
 			*lnwire.GossipTimestampRange,
 			*lnwire.QueryShortChanIDs,
@@ -2376,6 +2427,15 @@ out:
 
 			discStream.AddMsg(msg)
 
+		case *lnwire.PeerStorage,
+			*lnwire.PeerStorageRetrieval:
+
+			p.cfg.PeerStorage.WhenSome(
+				func(c peerstorage.Controller) {
+					c.ProcessPeerStorageMsg(msg, p)
+				},
+			)
+
 		case *lnwire.OnionMessage:
 			p.onionActorRef.WhenSome(
 				func(ref onionmessage.OnionPeerActorRef) {
@@ -2669,10 +2729,29 @@ func messageSummary(msg lnwire.Message) string {
 			msg.ShortChannelID.ToUint64(), msg.MessageFlags,
 			msg.ChannelFlags, time.Unix(int64(msg.Timestamp), 0))
 
+	case *lnwire.AnnounceSignatures2:
+		return fmt.Sprintf("chan_id=%v, short_chan_id=%v",
+			msg.ChannelID.Val, msg.ShortChannelID.Val.ToUint64())
+
+	case *lnwire.ChannelAnnouncement2:
+		return fmt.Sprintf("chain_hash=%v, short_chan_id=%v",
+			msg.ChainHash.Val, msg.ShortChannelID.Val.ToUint64())
+
+	case *lnwire.ChannelUpdate2:
+		return fmt.Sprintf("chain_hash=%v, short_chan_id=%v, "+
+			"node1=%v, disabled_flags=%v, block_height=%v",
+			msg.ChainHash.Val, msg.ShortChannelID.Val.ToUint64(),
+			msg.IsNode1(), msg.DisabledFlags.Val,
+			msg.BlockHeight.Val)
+
 	case *lnwire.NodeAnnouncement1:
 		return fmt.Sprintf("node=%x, update_time=%v",
 			msg.NodeID, time.Unix(int64(msg.Timestamp), 0))
 
+	case *lnwire.NodeAnnouncement2:
+		return fmt.Sprintf("node=%x, block_height=%v",
+			msg.NodeID.Val, msg.BlockHeight.Val)
+
 	case *lnwire.Ping:
 		return fmt.Sprintf("ping_bytes=%x", msg.PaddingBytes[:])
 
@@ -2717,6 +2796,25 @@ func messageSummary(msg lnwire.Message) string {
 		return fmt.Sprintf("chan_id=%v, initiator=%v", msg.ChanID,
 			msg.Initiator)
 
+	case *lnwire.SpliceInit:
+		return fmt.Sprintf("chan_id=%v, contribution=%v, fee_rate=%v",
+			msg.ChanID, msg.FundingContribution, msg.FeeRate)
+
+	case *lnwire.SpliceLocked:
+		return fmt.Sprintf("chan_id=%v, splice_txid=%v", msg.ChanID,
+			chainhash.Hash(msg.SpliceTxID))
+
+	case *lnwire.TxSignatures:
+		return fmt.Sprintf("chan_id=%v, txid=%v, num_witnesses=%d",
+			msg.ChanID, chainhash.Hash(msg.TxID),
+			len(msg.Witnesses))
+
+	case *lnwire.PeerStorage:
+		return fmt.Sprintf("blob_len=%d", len(msg.Blob))
+
+	case *lnwire.PeerStorageRetrieval:
+		return fmt.Sprintf("blob_len=%d", len(msg.Blob))
+
 	case *lnwire.Custom:
 		return fmt.Sprintf("type=%d", msg.Type)
 	}
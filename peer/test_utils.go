@@ -4,6 +4,7 @@ import (
 	"bytes"
 	crand "crypto/rand"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"math/rand"
 	"net"
@@ -481,6 +482,26 @@ func (m *mockUpdateHandler) InitStfu() <-chan fn.Result[lntypes.ChannelParty] {
 	return c
 }
 
+func (m *mockUpdateHandler) UpdateChannelParams(
+	lnwire.DynPropose) <-chan error {
+
+	c := make(chan error, 1)
+
+	c <- fmt.Errorf("UpdateChannelParams not implemented")
+
+	return c
+}
+
+func (m *mockUpdateHandler) Splice(
+	htlcswitch.SpliceRequest) <-chan fn.Result[chainhash.Hash] {
+
+	c := make(chan fn.Result[chainhash.Hash], 1)
+
+	c <- fn.Errf[chainhash.Hash]("Splice not implemented")
+
+	return c
+}
+
 func newMockConn(t *testing.T, expectedMessages int) *mockMessageConn {
 	return &mockMessageConn{
 		t:               t,
@@ -624,7 +645,7 @@ func createTestPeer(t *testing.T) *peerTestCtx {
 		IsChannelActive: func(lnwire.ChannelID) bool {
 			return true
 		},
-		ApplyChannelUpdate: func(*lnwire.ChannelUpdate1,
+		ApplyChannelUpdate: func(lnwire.ChannelUpdate,
 			*wire.OutPoint, bool) error {
 
 			return nil
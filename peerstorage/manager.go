@@ -0,0 +1,364 @@
+package peerstorage
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/flokiorg/flnd/chanbackup"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/keychain"
+	"github.com/flokiorg/flnd/lnpeer"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/crypto"
+)
+
+// Controller is an interface with basic methods that the peer storage manager
+// uses to process the peer storage messages peers send us.
+type Controller interface {
+	// ProcessPeerStorageMsg processes a peer storage message sent by the
+	// given peer.
+	ProcessPeerStorageMsg(lnwire.Message, lnpeer.Peer)
+}
+
+// Config holds the dependencies of the peer storage Manager.
+type Config struct {
+	// Store persists the blobs our peers asked us to store.
+	Store *Store
+
+	// KeyRing derives the key our static channel backups are encrypted
+	// with before we hand them to our peers.
+	KeyRing keychain.KeyRing
+
+	// HasOpenChannel returns true if we have an open channel with the
+	// passed peer. We only store the blobs of peers we have a channel
+	// with, so others can't use us as free storage.
+	HasOpenChannel func(peer *crypto.PublicKey) (bool, error)
+
+	// RestoreChannels restores the channels of the static channel backups
+	// a peer returned to us. It's only set while we're restoring from
+	// seed, so the backups our peers hold for us can be used to recover
+	// the channels that weren't part of the backups supplied manually.
+	RestoreChannels fn.Option[func([]chanbackup.Single) error]
+}
+
+// Manager hands our encrypted static channel backups to the peers that
+// signal option_provide_storage, stores the blobs they hand us in return,
+// and returns each peer its latest blob when it reconnects.
+type Manager struct {
+	stopped sync.Once
+
+	cfg *Config
+
+	// blob is the latest blob of our encrypted static channel backups,
+	// or nil if we don't have any channels to back up.
+	blob []byte
+
+	// peers are the online peers that store our blob.
+	peers map[[33]byte]lnpeer.Peer
+
+	mu sync.Mutex
+
+	wg sync.WaitGroup
+}
+
+// A compile time check to ensure Manager implements the Controller
+// interface.
+var _ Controller = (*Manager)(nil)
+
+// NewManager creates a new peer storage manager.
+func NewManager(cfg *Config) *Manager {
+	return &Manager{
+		cfg:   cfg,
+		peers: make(map[[33]byte]lnpeer.Peer),
+	}
+}
+
+// Stop waits for any pending channel restores to finish.
+func (m *Manager) Stop() error {
+	m.stopped.Do(func() {
+		log.Info("Peer storage manager shutting down...")
+		defer log.Debug("Peer storage manager shutdown complete")
+
+		m.wg.Wait()
+	})
+
+	return nil
+}
+
+// UpdateBackups replaces the blob we hand our peers with the passed packed
+// multi-channel backup, and sends it to all online peers that store our
+// blob.
+func (m *Manager) UpdateBackups(packedMulti chanbackup.PackedMulti) error {
+	multi, err := packedMulti.Unpack(m.cfg.KeyRing)
+	if err != nil {
+		return err
+	}
+
+	blob, err := packBlob(multi.StaticBackups, m.cfg.KeyRing)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.blob = blob
+	peers := make([]lnpeer.Peer, 0, len(m.peers))
+	for _, peer := range m.peers {
+		peers = append(peers, peer)
+	}
+	m.mu.Unlock()
+
+	for _, peer := range peers {
+		m.sendBlob(peer, blob)
+	}
+
+	return nil
+}
+
+// PeerOnline hands a newly connected peer our latest blob if it signals
+// option_provide_storage, and returns the peer the latest blob it asked us
+// to store.
+func (m *Manager) PeerOnline(peer lnpeer.Peer) {
+	pubKey := peer.PubKey()
+
+	blob, err := m.cfg.Store.FetchBlob(pubKey)
+	switch {
+	case err == nil:
+		log.Debugf("Returning blob of %d bytes to peer %x", len(blob),
+			pubKey)
+
+		err := peer.SendMessage(false, &lnwire.PeerStorageRetrieval{
+			Blob: blob,
+		})
+		if err != nil {
+			log.Errorf("Unable to return blob to peer %x: %v",
+				pubKey, err)
+		}
+
+	case !errors.Is(err, ErrNoBlob):
+		log.Errorf("Unable to fetch blob of peer %x: %v", pubKey, err)
+	}
+
+	features := peer.RemoteFeatures()
+	if features == nil ||
+		!features.HasFeature(lnwire.ProvideStorageOptional) {
+
+		return
+	}
+
+	m.mu.Lock()
+	m.peers[pubKey] = peer
+	ourBlob := m.blob
+	m.mu.Unlock()
+
+	m.sendBlob(peer, ourBlob)
+}
+
+// PeerOffline stops handing our blob to the passed peer.
+func (m *Manager) PeerOffline(pubKey [33]byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.peers, pubKey)
+}
+
+// ProcessPeerStorageMsg processes a peer storage message sent by the given
+// peer.
+//
+// NOTE: Part of the Controller interface.
+func (m *Manager) ProcessPeerStorageMsg(msg lnwire.Message,
+	peer lnpeer.Peer) {
+
+	switch msg := msg.(type) {
+	case *lnwire.PeerStorage:
+		m.handlePeerStorage(msg, peer)
+
+	case *lnwire.PeerStorageRetrieval:
+		m.handlePeerStorageRetrieval(msg, peer)
+
+	default:
+		log.Warnf("Received unknown peer storage message %T from "+
+			"peer %x", msg, peer.PubKey())
+	}
+}
+
+// handlePeerStorage stores the blob a peer asked us to store on its behalf.
+func (m *Manager) handlePeerStorage(msg *lnwire.PeerStorage,
+	peer lnpeer.Peer) {
+
+	pubKey := peer.PubKey()
+
+	if len(msg.Blob) > lnwire.MaxPeerStorageBlobSize {
+		log.Warnf("Ignoring blob of %d bytes from peer %x, exceeds "+
+			"maximum of %d bytes", len(msg.Blob), pubKey,
+			lnwire.MaxPeerStorageBlobSize)
+
+		return
+	}
+
+	hasChannel, err := m.cfg.HasOpenChannel(peer.IdentityKey())
+	if err != nil {
+		log.Errorf("Unable to fetch channels with peer %x: %v",
+			pubKey, err)
+
+		return
+	}
+	if !hasChannel {
+		log.Debugf("Ignoring blob from peer %x without open channel",
+			pubKey)
+
+		return
+	}
+
+	if err := m.cfg.Store.PutBlob(pubKey, msg.Blob); err != nil {
+		log.Errorf("Unable to store blob of peer %x: %v", pubKey, err)
+
+		return
+	}
+
+	log.Debugf("Stored blob of %d bytes for peer %x", len(msg.Blob),
+		pubKey)
+}
+
+// handlePeerStorageRetrieval decrypts the blob a peer returned to us and, if
+// we're restoring from seed, restores the channels it contains backups of.
+func (m *Manager) handlePeerStorageRetrieval(msg *lnwire.PeerStorageRetrieval,
+	peer lnpeer.Peer) {
+
+	pubKey := peer.PubKey()
+
+	// The blob may have been stored by a previous version of our node
+	// that used a different seed, or the peer may have tampered with it,
+	// so we only log if we're unable to decrypt it.
+	var multi chanbackup.Multi
+	err := multi.UnpackFromReader(bytes.NewReader(msg.Blob), m.cfg.KeyRing)
+	if err != nil {
+		log.Warnf("Unable to unpack blob returned by peer %x: %v",
+			pubKey, err)
+
+		return
+	}
+
+	log.Infof("Peer %x returned backups of %d channels", pubKey,
+		len(multi.StaticBackups))
+
+	if len(multi.StaticBackups) == 0 {
+		return
+	}
+
+	m.cfg.RestoreChannels.WhenSome(
+		func(restore func([]chanbackup.Single) error) {
+			// Restoring the channels reconnects to their peers,
+			// which may include the peer that is waiting for us
+			// to process its message, so we restore them in the
+			// background.
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+
+				err := restore(multi.StaticBackups)
+				if err != nil {
+					log.Errorf("Unable to restore "+
+						"channels from peer %x: %v",
+						pubKey, err)
+				}
+			}()
+		},
+	)
+}
+
+// sendBlob hands our latest blob to the passed peer.
+func (m *Manager) sendBlob(peer lnpeer.Peer, blob []byte) {
+	// We don't overwrite the blob a peer stores for us unless we have any
+	// channels to back up, as we may be restoring from seed and waiting
+	// for the peer to return our previous blob.
+	if len(blob) == 0 {
+		return
+	}
+
+	err := peer.SendMessage(false, &lnwire.PeerStorage{Blob: blob})
+	if err != nil {
+		log.Errorf("Unable to send blob to peer %x: %v", peer.PubKey(),
+			err)
+	}
+}
+
+// packBlob packs (encrypts+serializes) the passed static channel backups into
+// a blob small enough to be stored by our peers. If the backups of all
+// channels don't fit, the backups of the channels with the lowest capacity
+// are left out. If there are no backups, a nil blob is returned.
+func packBlob(backups []chanbackup.Single,
+	keyRing keychain.KeyRing) ([]byte, error) {
+
+	backups = slices.Clone(backups)
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Capacity > backups[j].Capacity
+	})
+
+	numBackups := len(backups)
+	for len(backups) > 0 {
+		var b bytes.Buffer
+		multi := chanbackup.Multi{StaticBackups: backups}
+		if err := multi.PackToWriter(&b, keyRing); err != nil {
+			return nil, err
+		}
+
+		if b.Len() <= lnwire.MaxPeerStorageBlobSize {
+			if len(backups) < numBackups {
+				log.Warnf("Backups of %d channels exceed the "+
+					"maximum blob size, leaving out %d "+
+					"channels", numBackups,
+					numBackups-len(backups))
+			}
+
+			return b.Bytes(), nil
+		}
+
+		// Leave out the backups we expect not to fit, but at least
+		// one of them.
+		keep := len(backups) * lnwire.MaxPeerStorageBlobSize / b.Len()
+		if keep >= len(backups) {
+			keep = len(backups) - 1
+		}
+		backups = backups[:keep]
+	}
+
+	return nil, nil
+}
+
+// swapper wraps the chanbackup.Swapper that persists our static channel
+// backups, to hand each new backup to our peers as well.
+type swapper struct {
+	chanbackup.Swapper
+
+	mgr *Manager
+}
+
+// UpdateAndSwap persists the new multi-channel backup with the wrapped
+// swapper, and then hands it to our peers.
+//
+// NOTE: Part of the chanbackup.Swapper interface.
+func (s *swapper) UpdateAndSwap(newBackup chanbackup.PackedMulti) error {
+	if err := s.Swapper.UpdateAndSwap(newBackup); err != nil {
+		return err
+	}
+
+	// Our peers only hold a secondary copy of the backup, so we don't
+	// fail the update if we're unable to hand it to them.
+	if err := s.mgr.UpdateBackups(newBackup); err != nil {
+		log.Errorf("Unable to update peer storage blob: %v", err)
+	}
+
+	return nil
+}
+
+// WrapSwapper returns a chanbackup.Swapper that hands every multi-channel
+// backup persisted by the passed swapper to our peers as well.
+func (m *Manager) WrapSwapper(s chanbackup.Swapper) chanbackup.Swapper {
+	return &swapper{
+		Swapper: s,
+		mgr:     m,
+	}
+}
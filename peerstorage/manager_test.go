@@ -0,0 +1,263 @@
+package peerstorage
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/flokiorg/flnd/chanbackup"
+	"github.com/flokiorg/flnd/chanstate"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/keychain"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lnencrypt"
+	"github.com/flokiorg/flnd/lnpeer"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testSingle returns a static channel backup of a channel with the given
+// capacity.
+func testSingle(t *testing.T, index uint32,
+	capacity chainutil.Amount) chanbackup.Single {
+
+	priv, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+	pub := priv.PubKey()
+	key := keychain.KeyDescriptor{PubKey: pub}
+
+	return chanbackup.Single{
+		Version: chanbackup.AnchorsCommitVersion,
+		FundingOutpoint: wire.OutPoint{
+			Hash:  [32]byte{1},
+			Index: index,
+		},
+		RemoteNodePub: pub,
+		Capacity:      capacity,
+		RemoteChanCfg: chanstate.ChannelConfig{
+			MultiSigKey:         key,
+			RevocationBasePoint: key,
+			PaymentBasePoint:    key,
+			DelayBasePoint:      key,
+			HtlcBasePoint:       key,
+		},
+	}
+}
+
+// newTestPeer returns a mock peer that records the messages sent to it.
+func newTestPeer(t *testing.T, provideStorage bool) (*lnpeer.MockPeer,
+	*[]lnwire.Message) {
+
+	priv, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+
+	var pubKey [33]byte
+	copy(pubKey[:], priv.PubKey().SerializeCompressed())
+
+	features := lnwire.NewRawFeatureVector()
+	if provideStorage {
+		features.Set(lnwire.ProvideStorageOptional)
+	}
+
+	var sent []lnwire.Message
+	peer := &lnpeer.MockPeer{}
+	peer.On("PubKey").Return(pubKey)
+	peer.On("IdentityKey").Return(priv.PubKey())
+	peer.On("RemoteFeatures").Return(
+		lnwire.NewFeatureVector(features, lnwire.Features),
+	)
+	peer.On("SendMessage", false, mock.Anything).Run(
+		func(args mock.Arguments) {
+			msgs := args.Get(1).([]lnwire.Message)
+			sent = append(sent, msgs...)
+		},
+	).Return(nil)
+
+	return peer, &sent
+}
+
+// newTestManager creates a peer storage manager backed by a fresh database.
+func newTestManager(t *testing.T, keyRing keychain.KeyRing,
+	hasChannel bool) *Manager {
+
+	dbPath := filepath.Join(t.TempDir(), "testdb")
+	db, err := kvdb.Create(
+		kvdb.BoltBackendName, dbPath, true, kvdb.DefaultDBTimeout,
+		false,
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+
+	mgr := NewManager(&Config{
+		Store:   store,
+		KeyRing: keyRing,
+		HasOpenChannel: func(*crypto.PublicKey) (bool, error) {
+			return hasChannel, nil
+		},
+	})
+	t.Cleanup(func() {
+		require.NoError(t, mgr.Stop())
+	})
+
+	return mgr
+}
+
+// unpackBlob decrypts the passed blob into the backups it contains.
+func unpackBlob(t *testing.T, blob []byte,
+	keyRing keychain.KeyRing) []chanbackup.Single {
+
+	var multi chanbackup.Multi
+	err := multi.UnpackFromReader(bytes.NewReader(blob), keyRing)
+	require.NoError(t, err)
+
+	return multi.StaticBackups
+}
+
+// TestPackBlobSizeLimit asserts that the backups of the channels with the
+// lowest capacity are left out if the backups of all channels don't fit in a
+// blob.
+func TestPackBlobSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	keyRing := &lnencrypt.MockKeyRing{}
+
+	blob, err := packBlob(nil, keyRing)
+	require.NoError(t, err)
+	require.Nil(t, blob)
+
+	var backups []chanbackup.Single
+	for i := range 500 {
+		backups = append(
+			backups, testSingle(t, uint32(i), chainutil.Amount(i)),
+		)
+	}
+
+	blob, err = packBlob(backups, keyRing)
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(blob), lnwire.MaxPeerStorageBlobSize)
+
+	packed := unpackBlob(t, blob, keyRing)
+	require.NotEmpty(t, packed)
+	require.Less(t, len(packed), len(backups))
+
+	// Only the largest channels are kept.
+	minCapacity := chainutil.Amount(len(backups) - len(packed))
+	for _, backup := range packed {
+		require.GreaterOrEqual(t, backup.Capacity, minCapacity)
+	}
+}
+
+// TestManagerExchangeBlobs asserts that we hand our backups to peers that
+// signal option_provide_storage, only store the blobs of peers we have a
+// channel with, and return their blobs when they reconnect.
+func TestManagerExchangeBlobs(t *testing.T) {
+	t.Parallel()
+
+	keyRing := &lnencrypt.MockKeyRing{}
+	backups := []chanbackup.Single{
+		testSingle(t, 0, 1_000_000), testSingle(t, 1, 2_000_000),
+	}
+
+	var packed bytes.Buffer
+	multi := chanbackup.Multi{StaticBackups: backups}
+	require.NoError(t, multi.PackToWriter(&packed, keyRing))
+
+	// A peer that doesn't signal option_provide_storage isn't handed our
+	// blob, while one that does gets it once it's online and whenever our
+	// backups change.
+	mgr := newTestManager(t, keyRing, true)
+
+	storagePeer, storageSent := newTestPeer(t, true)
+	otherPeer, otherSent := newTestPeer(t, false)
+	mgr.PeerOnline(storagePeer)
+	mgr.PeerOnline(otherPeer)
+	require.Empty(t, *storageSent)
+
+	err := mgr.UpdateBackups(chanbackup.PackedMulti(packed.Bytes()))
+	require.NoError(t, err)
+	require.Empty(t, *otherSent)
+	require.Len(t, *storageSent, 1)
+
+	msg, ok := (*storageSent)[0].(*lnwire.PeerStorage)
+	require.True(t, ok)
+	require.Len(t, unpackBlob(t, msg.Blob, keyRing), len(backups))
+
+	mgr.PeerOffline(storagePeer.PubKey())
+	mgr.PeerOnline(storagePeer)
+	require.Len(t, *storageSent, 2)
+	require.IsType(t, &lnwire.PeerStorage{}, (*storageSent)[1])
+
+	// The blob of a peer we have a channel with is stored and returned
+	// once it reconnects.
+	theirBlob := []byte{1, 2, 3}
+	mgr.ProcessPeerStorageMsg(
+		&lnwire.PeerStorage{Blob: theirBlob}, otherPeer,
+	)
+	mgr.PeerOnline(otherPeer)
+	require.Equal(t, []lnwire.Message{
+		&lnwire.PeerStorageRetrieval{Blob: theirBlob},
+	}, *otherSent)
+
+	// Without a channel, the blob of a peer isn't stored.
+	noChanMgr := newTestManager(t, keyRing, false)
+	noChanMgr.ProcessPeerStorageMsg(
+		&lnwire.PeerStorage{Blob: theirBlob}, storagePeer,
+	)
+	_, err = noChanMgr.cfg.Store.FetchBlob(storagePeer.PubKey())
+	require.ErrorIs(t, err, ErrNoBlob)
+}
+
+// TestManagerRestoreChannels asserts that the backups a peer returns to us
+// are restored if we're restoring from seed.
+func TestManagerRestoreChannels(t *testing.T) {
+	t.Parallel()
+
+	keyRing := &lnencrypt.MockKeyRing{}
+	backups := []chanbackup.Single{testSingle(t, 0, 1_000_000)}
+
+	var packed bytes.Buffer
+	multi := chanbackup.Multi{StaticBackups: backups}
+	require.NoError(t, multi.PackToWriter(&packed, keyRing))
+
+	restored := make(chan []chanbackup.Single, 1)
+	mgr := newTestManager(t, keyRing, true)
+	mgr.cfg.RestoreChannels = fn.Some(
+		func(singles []chanbackup.Single) error {
+			restored <- singles
+			return nil
+		},
+	)
+
+	// A blob we can't decrypt is ignored.
+	peer, _ := newTestPeer(t, true)
+	mgr.ProcessPeerStorageMsg(
+		&lnwire.PeerStorageRetrieval{Blob: []byte{1, 2, 3}}, peer,
+	)
+
+	mgr.ProcessPeerStorageMsg(
+		&lnwire.PeerStorageRetrieval{Blob: packed.Bytes()}, peer,
+	)
+	require.NoError(t, mgr.Stop())
+
+	select {
+	case singles := <-restored:
+		require.Len(t, singles, 1)
+		require.Equal(
+			t, backups[0].FundingOutpoint,
+			singles[0].FundingOutpoint,
+		)
+
+	default:
+		t.Fatal("backups not restored")
+	}
+	require.Empty(t, restored)
+}
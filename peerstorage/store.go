@@ -0,0 +1,79 @@
+package peerstorage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/kvdb"
+)
+
+var (
+	// peerStorageBucket is the top-level bucket that stores the blobs our
+	// peers asked us to store on their behalf. The keys are the
+	// compressed identity keys of the peers and the values their latest
+	// blobs.
+	peerStorageBucket = []byte("peer-storage-bucket")
+
+	// ErrNoBlob is returned when we don't store a blob for a peer.
+	ErrNoBlob = errors.New("no blob stored for peer")
+)
+
+// Store persists the blobs our peers asked us to store on their behalf.
+type Store struct {
+	db kvdb.Backend
+}
+
+// NewStore creates a new peer storage store backed by the passed database.
+func NewStore(db kvdb.Backend) (*Store, error) {
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(peerStorageBucket)
+		return err
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// PutBlob stores the blob of the passed peer, replacing the blob it asked us
+// to store before.
+func (s *Store) PutBlob(peer [33]byte, blob []byte) error {
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(peerStorageBucket)
+		if bucket == nil {
+			return fmt.Errorf("peer storage bucket not found")
+		}
+
+		return bucket.Put(peer[:], blob)
+	}, func() {})
+}
+
+// FetchBlob returns the latest blob the passed peer asked us to store. If we
+// don't store a blob for the peer, ErrNoBlob is returned.
+func (s *Store) FetchBlob(peer [33]byte) ([]byte, error) {
+	var blob []byte
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(peerStorageBucket)
+		if bucket == nil {
+			return fmt.Errorf("peer storage bucket not found")
+		}
+
+		stored := bucket.Get(peer[:])
+		if stored == nil {
+			return ErrNoBlob
+		}
+
+		blob = make([]byte, len(stored))
+		copy(blob, stored)
+
+		return nil
+	}, func() {
+		blob = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blob, nil
+}
@@ -29,6 +29,10 @@ const (
 	// ephemeral keys in the onion that are used in blinded paths.
 	BlindingPointOnionType tlv.Type = 12
 
+	// OutgoingNodeIDOnionType is the type used in a trampoline onion to
+	// reference the node the trampoline node should relay the payment to.
+	OutgoingNodeIDOnionType tlv.Type = 14
+
 	// MetadataOnionType is the type used in the onion for the payment
 	// metadata.
 	MetadataOnionType tlv.Type = 16
@@ -37,6 +41,10 @@ const (
 	// amount field that is included in the final hop for blinded payments.
 	TotalAmtMsatBlindedType tlv.Type = 18
 
+	// TrampolineOnionType is the type used in the onion to include the
+	// trampoline onion for the trampoline node receiving the payload.
+	TrampolineOnionType tlv.Type = 20
+
 	// Onion Message Packet types.
 
 	// ReplyPathType is the type used in the onion message to indicate the
@@ -152,3 +160,21 @@ func NewTotalAmtMsatBlinded(amt *uint64) tlv.Record {
 		tlv.ETUint64, tlv.DTUint64,
 	)
 }
+
+// NewOutgoingNodeIDRecord creates a tlv.Record that encodes the
+// outgoing_node_id (type 14) for a trampoline onion payload.
+func NewOutgoingNodeIDRecord(nodeID **crypto.PublicKey) tlv.Record {
+	return tlv.MakePrimitiveRecord(OutgoingNodeIDOnionType, nodeID)
+}
+
+// NewTrampolineOnionRecord creates a tlv.Record that encodes the
+// trampoline_onion (type 20) for an onion payload.
+func NewTrampolineOnionRecord(onion *[]byte) tlv.Record {
+	return tlv.MakeDynamicRecord(
+		TrampolineOnionType, onion,
+		func() uint64 {
+			return uint64(len(*onion))
+		},
+		tlv.EVarBytes, tlv.DVarBytes,
+	)
+}
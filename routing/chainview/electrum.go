@@ -0,0 +1,654 @@
+package chainview
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flokiorg/flnd/electrumio"
+	graphdb "github.com/flokiorg/flnd/graph/db"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/wire"
+	"github.com/flokiorg/walletd/chain/electrum"
+)
+
+const (
+	// electrumReorgDepth is the number of connected block hashes the
+	// electrum chain view keeps around in order to detect reorgs.
+	electrumReorgDepth = 144
+
+	// electrumTipPollInterval is how often the electrum chain view asks the
+	// server for its chain tip, in case a header notification was lost.
+	electrumTipPollInterval = 30 * time.Second
+)
+
+// ElectrumFilteredChainView is an implementation of the FilteredChainView
+// interface which is backed by an electrum server. As electrum servers can't
+// serve full blocks, the view subscribes to the funding scripts of all watched
+// outputs, and looks up the history of a script whenever the server reports
+// its status changed. Any spends of watched outputs found this way are
+// recorded by the height of the block they confirmed in.
+type ElectrumFilteredChainView struct {
+	started int32 // To be used atomically.
+	stopped int32 // To be used atomically.
+
+	backend *electrumio.Backend
+
+	// bestHeight and bestHash describe the latest block added to the
+	// blockQueue. They're only accessed by the chainFilterer goroutine
+	// once started.
+	bestHeight uint32
+	bestHash   chainhash.Hash
+
+	// blockHashes holds the hashes of the blocks we've connected that
+	// are within electrumReorgDepth of our best block, by height.
+	blockHashes map[uint32]chainhash.Hash
+
+	// blockEventQueue is the ordered queue used to keep the order
+	// of connected and disconnected blocks sent to the reader of the
+	// chainView.
+	blockQueue *blockEventQueue
+
+	// filterUpdates is a channel in which updates to the utxo filter
+	// attached to this instance are sent over.
+	filterUpdates chan electrumFilterUpdate
+
+	// chainFilter is the set of utxo's that we're currently watching
+	// spends for within the chain, along with their funding script.
+	chainFilter map[wire.OutPoint][]byte
+
+	// scripts maps the electrum script hashes of the funding scripts of
+	// the watched utxo's to the scripts themselves.
+	scripts map[string][]byte
+
+	// spends holds the confirmed spends of watched utxo's we've found, by
+	// the height of the block they were confirmed in.
+	spends map[uint32]map[chainhash.Hash]*wire.MsgTx
+
+	// dirtyScripts is the set of script hashes whose history has changed
+	// since we last looked it up.
+	dirtyMtx     sync.Mutex
+	dirtyScripts map[string]struct{}
+
+	headers       <-chan *electrum.SubscribeHeadersResult
+	scriptSub     *electrum.ScripthashSubscription
+	scriptUpdates <-chan *electrum.SubscribeNotif
+	tipCheck      chan struct{}
+
+	// filterBlockReqs is a channel in which requests to filter select
+	// blocks will be sent over.
+	filterBlockReqs chan *filterBlockReq
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// A compile time check to ensure ElectrumFilteredChainView implements the
+// chainview.FilteredChainView.
+var _ FilteredChainView = (*ElectrumFilteredChainView)(nil)
+
+// NewElectrumFilteredChainView creates a new instance of a FilteredChainView
+// backed by the given electrum backend, whose client must already be started.
+func NewElectrumFilteredChainView(
+	backend *electrumio.Backend) *ElectrumFilteredChainView {
+
+	return &ElectrumFilteredChainView{
+		backend:         backend,
+		blockHashes:     make(map[uint32]chainhash.Hash),
+		blockQueue:      newBlockEventQueue(),
+		filterUpdates:   make(chan electrumFilterUpdate),
+		chainFilter:     make(map[wire.OutPoint][]byte),
+		scripts:         make(map[string][]byte),
+		spends:          make(map[uint32]map[chainhash.Hash]*wire.MsgTx),
+		dirtyScripts:    make(map[string]struct{}),
+		tipCheck:        make(chan struct{}, 1),
+		filterBlockReqs: make(chan *filterBlockReq),
+		quit:            make(chan struct{}),
+	}
+}
+
+// Start starts all goroutines necessary for normal operation.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *ElectrumFilteredChainView) Start() error {
+	// Already started?
+	if atomic.AddInt32(&e.started, 1) != 1 {
+		return nil
+	}
+
+	log.Infof("FilteredChainView starting")
+
+	headers, err := e.backend.Client().SubscribeHeaders(
+		context.Background(),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to subscribe to headers: %w", err)
+	}
+	e.headers = headers
+
+	bestHash, bestHeight, err := e.backend.BestBlock()
+	if err != nil {
+		return err
+	}
+	e.bestHeight = uint32(bestHeight)
+	e.bestHash = *bestHash
+	e.blockHashes[e.bestHeight] = e.bestHash
+
+	e.scriptSub, e.scriptUpdates = e.backend.Client().SubscribeScripthash()
+
+	e.blockQueue.Start()
+
+	e.wg.Add(2)
+	go e.scriptUpdateHandler()
+	go e.chainFilterer()
+
+	return nil
+}
+
+// Stop stops all goroutines which we launched by the prior call to the Start
+// method.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *ElectrumFilteredChainView) Stop() error {
+	log.Debug("ElectrumFilteredChainView stopping")
+	defer log.Debug("ElectrumFilteredChainView stopped")
+
+	// Already shutting down?
+	if atomic.AddInt32(&e.stopped, 1) != 1 {
+		return nil
+	}
+
+	e.blockQueue.Stop()
+
+	close(e.quit)
+	e.wg.Wait()
+
+	return nil
+}
+
+// scriptUpdateHandler marks the scripts whose status changed as dirty, so
+// their history is looked up again once the next block is connected.
+//
+// NOTE: This MUST be run as a goroutine.
+func (e *ElectrumFilteredChainView) scriptUpdateHandler() {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case update := <-e.scriptUpdates:
+			e.dirtyMtx.Lock()
+			e.dirtyScripts[update.Params[0]] = struct{}{}
+			e.dirtyMtx.Unlock()
+
+			select {
+			case e.tipCheck <- struct{}{}:
+			default:
+			}
+
+		case <-e.quit:
+			return
+		}
+	}
+}
+
+// chainFilterer is the primary goroutine which: listens for new blocks coming
+// and dispatches the relevant FilteredBlock notifications, updates the filter
+// due to requests by callers, and finally is able to preform targeted block
+// filtration.
+func (e *ElectrumFilteredChainView) chainFilterer() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(electrumTipPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		// The caller has just sent an update to the current chain
+		// filter, so we'll apply the update, possibly rewinding our
+		// state partially.
+		case update := <-e.filterUpdates:
+			log.Tracef("Updating chain filter with new UTXO's: %v",
+				update.newUtxos)
+
+			err := e.applyFilterUpdate(update)
+			if err != nil {
+				log.Errorf("Unable to update chain filter: %v",
+					err)
+			}
+
+		// We've received a new request to manually filter a block.
+		case req := <-e.filterBlockReqs:
+			block, err := e.filterBlock(req.blockHash)
+			req.resp <- block
+			req.err <- err
+
+		case header := <-e.headers:
+			if err := e.syncChain(uint32(header.Height)); err != nil {
+				log.Errorf("Unable to sync to block at height "+
+					"%d: %v", header.Height, err)
+			}
+
+		case <-e.tipCheck:
+			e.syncBestBlock()
+
+		case <-ticker.C:
+			e.syncBestBlock()
+
+		case <-e.quit:
+			return
+		}
+	}
+}
+
+// applyFilterUpdate adds the new utxo's of a filter update to our chain
+// filter, and looks up the spends of these utxo's that were confirmed after
+// the update height.
+func (e *ElectrumFilteredChainView) applyFilterUpdate(
+	update electrumFilterUpdate) error {
+
+	var newScripts [][]byte
+	for _, op := range update.newUtxos {
+		e.chainFilter[op.OutPoint] = op.FundingPkScript
+
+		scriptHash := electrumio.ScriptHash(op.FundingPkScript)
+		if _, ok := e.scripts[scriptHash]; ok {
+			continue
+		}
+		e.scripts[scriptHash] = op.FundingPkScript
+		newScripts = append(newScripts, op.FundingPkScript)
+	}
+
+	// We subscribe to the new scripts before looking up their history,
+	// so that we won't miss any spend confirmed in between.
+	for _, pkScript := range newScripts {
+		err := e.backend.SubscribeScript(e.scriptSub, pkScript)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, pkScript := range newScripts {
+		if _, err := e.scanScript(pkScript); err != nil {
+			return err
+		}
+	}
+
+	// If the update height matches our best known height, then we don't
+	// need to do any rewinding.
+	if update.updateHeight >= e.bestHeight {
+		return nil
+	}
+
+	// Otherwise, we'll resend the blocks after the update height that
+	// hold any spends, to ensure the caller doesn't miss any relevant
+	// notifications.
+	for height := update.updateHeight + 1; height <= e.bestHeight; height++ {
+		if len(e.spends[height]) == 0 {
+			continue
+		}
+
+		hash, _, err := e.backend.BlockHeader(height)
+		if err != nil {
+			return err
+		}
+
+		e.blockQueue.Add(&blockEvent{
+			eventType: connected,
+			block:     e.spendsBlock(height, *hash),
+		})
+	}
+
+	return nil
+}
+
+// scanScript looks up the history of a watched script, recording all
+// confirmed spends of watched utxo's. It returns the heights of the blocks in
+// which new spends were found.
+func (e *ElectrumFilteredChainView) scanScript(pkScript []byte) ([]uint32,
+	error) {
+
+	history, err := e.backend.ScriptHistory(pkScript)
+	if err != nil {
+		return nil, err
+	}
+
+	var spendHeights []uint32
+
+	var pending bool
+	for txid, height := range history {
+		if height <= 0 {
+			pending = true
+			continue
+		}
+
+		if _, ok := e.spends[uint32(height)][txid]; ok {
+			continue
+		}
+
+		tx, err := e.backend.Transaction(&txid)
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.spendsWatchedUtxo(tx) {
+			continue
+		}
+
+		if e.spends[uint32(height)] == nil {
+			e.spends[uint32(height)] = make(
+				map[chainhash.Hash]*wire.MsgTx,
+			)
+		}
+		e.spends[uint32(height)][txid] = tx
+		spendHeights = append(spendHeights, uint32(height))
+	}
+
+	// Unconfirmed transactions will change the status of the script once
+	// they confirm, but we'll keep the script dirty until then in case
+	// the server's notification is lost.
+	if pending {
+		e.dirtyMtx.Lock()
+		e.dirtyScripts[electrumio.ScriptHash(pkScript)] = struct{}{}
+		e.dirtyMtx.Unlock()
+	}
+
+	return spendHeights, nil
+}
+
+// spendsWatchedUtxo returns whether the transaction spends any of the utxo's
+// we're watching.
+func (e *ElectrumFilteredChainView) spendsWatchedUtxo(tx *wire.MsgTx) bool {
+	for _, txIn := range tx.TxIn {
+		if _, ok := e.chainFilter[txIn.PreviousOutPoint]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// spendsBlock returns a FilteredBlock holding the spends we've recorded for
+// the block at the given height, removing the spent utxo's from our chain
+// filter.
+func (e *ElectrumFilteredChainView) spendsBlock(height uint32,
+	hash chainhash.Hash) *FilteredBlock {
+
+	txs := make([]*wire.MsgTx, 0, len(e.spends[height]))
+	for _, tx := range e.spends[height] {
+		txs = append(txs, tx.Copy())
+
+		// We can delete the spent outpoints from the chainFilter, as
+		// they were spent in a confirmed block. In case of a reorg,
+		// the outpoint might get "un-spent", but that's okay since it
+		// would never be wise to consider the channel open again.
+		for _, txIn := range tx.TxIn {
+			delete(e.chainFilter, txIn.PreviousOutPoint)
+		}
+	}
+
+	return &FilteredBlock{
+		Hash:         hash,
+		Height:       height,
+		Transactions: txs,
+	}
+}
+
+// filterBlock returns the spends of watched utxo's within the given block.
+// Electrum servers can only be queried for blocks by height, so the block must
+// have been looked up by height before.
+func (e *ElectrumFilteredChainView) filterBlock(
+	blockHash *chainhash.Hash) (*FilteredBlock, error) {
+
+	height, err := e.backend.BlockHeight(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.spendsBlock(height, *blockHash), nil
+}
+
+// syncBestBlock syncs the chain view with the chain tip of the electrum
+// server.
+func (e *ElectrumFilteredChainView) syncBestBlock() {
+	_, bestHeight, err := e.backend.BestBlock()
+	if err != nil {
+		log.Errorf("Unable to get best block: %v", err)
+		return
+	}
+
+	if err := e.syncChain(uint32(bestHeight)); err != nil {
+		log.Errorf("Unable to sync to block at height %d: %v",
+			bestHeight, err)
+	}
+}
+
+// syncChain brings the chain view in line with the main chain of the electrum
+// server, whose tip is at the given height. Any of our blocks that are no
+// longer part of the main chain are disconnected first, after which the
+// server's blocks above our new best block are connected one at a time.
+func (e *ElectrumFilteredChainView) syncChain(tipHeight uint32) error {
+	for e.bestHeight > 0 {
+		if e.bestHeight <= tipHeight {
+			hash, _, err := e.backend.BlockHeader(e.bestHeight)
+			if err != nil {
+				return err
+			}
+
+			if *hash == e.bestHash {
+				break
+			}
+		}
+
+		if err := e.disconnectTip(); err != nil {
+			return err
+		}
+	}
+
+	for height := e.bestHeight + 1; height <= tipHeight; height++ {
+		hash, header, err := e.backend.BlockHeader(height)
+		if err != nil {
+			return err
+		}
+
+		// If the block doesn't extend our tip, the server's chain was
+		// reorged while we were syncing. We'll catch up with it on
+		// our next sync.
+		if header.PrevBlock != e.bestHash {
+			return fmt.Errorf("block %v at height %d doesn't "+
+				"extend our tip %v", hash, height, e.bestHash)
+		}
+
+		if err := e.connectBlock(height, *hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// disconnectTip disconnects our best block, which is no longer part of the
+// main chain.
+func (e *ElectrumFilteredChainView) disconnectTip() error {
+	log.Debugf("got disconnected block at height %d: %v", e.bestHeight,
+		e.bestHash)
+
+	e.blockQueue.Add(&blockEvent{
+		eventType: disconnected,
+		block: &FilteredBlock{
+			Hash:   e.bestHash,
+			Height: e.bestHeight,
+		},
+	})
+
+	// Any spends we recorded for the block are no longer valid, they'll
+	// be found again once the spending transactions confirm in the new
+	// chain.
+	delete(e.spends, e.bestHeight)
+	delete(e.blockHashes, e.bestHeight)
+	e.bestHeight--
+
+	// If we didn't connect the previous block ourselves, as happens when
+	// the block we started out with is reorged out, we'll fetch it from
+	// the server.
+	prevHash, ok := e.blockHashes[e.bestHeight]
+	if !ok {
+		hash, _, err := e.backend.BlockHeader(e.bestHeight)
+		if err != nil {
+			return err
+		}
+		prevHash = *hash
+	}
+	e.bestHash = prevHash
+
+	return nil
+}
+
+// connectBlock looks up the history of all dirty scripts and dispatches the
+// connected block along with the spends of watched utxo's it holds. Any
+// spends found that were confirmed in earlier blocks are dispatched first.
+func (e *ElectrumFilteredChainView) connectBlock(height uint32,
+	hash chainhash.Hash) error {
+
+	e.dirtyMtx.Lock()
+	dirtyScripts := e.dirtyScripts
+	e.dirtyScripts = make(map[string]struct{})
+	e.dirtyMtx.Unlock()
+
+	// Spends we find in blocks we had already connected are resent as an
+	// update to these blocks.
+	staleHeights := make(map[uint32]struct{})
+	for scriptHash := range dirtyScripts {
+		pkScript, ok := e.scripts[scriptHash]
+		if !ok {
+			continue
+		}
+
+		spendHeights, err := e.scanScript(pkScript)
+		if err != nil {
+			// We'll look the script up again with the next block.
+			e.dirtyMtx.Lock()
+			e.dirtyScripts[scriptHash] = struct{}{}
+			e.dirtyMtx.Unlock()
+
+			return err
+		}
+
+		for _, spendHeight := range spendHeights {
+			if spendHeight < height {
+				staleHeights[spendHeight] = struct{}{}
+			}
+		}
+	}
+
+	for spendHeight := range staleHeights {
+		spendHash, ok := e.blockHashes[spendHeight]
+		if !ok {
+			blockHash, _, err := e.backend.BlockHeader(spendHeight)
+			if err != nil {
+				return err
+			}
+			spendHash = *blockHash
+		}
+
+		e.blockQueue.Add(&blockEvent{
+			eventType: connected,
+			block:     e.spendsBlock(spendHeight, spendHash),
+		})
+	}
+
+	e.bestHeight = height
+	e.bestHash = hash
+	e.blockHashes[height] = hash
+	delete(e.blockHashes, height-electrumReorgDepth)
+
+	e.blockQueue.Add(&blockEvent{
+		eventType: connected,
+		block:     e.spendsBlock(height, hash),
+	})
+
+	// Spends that are buried deep enough won't be reorged out anymore,
+	// and the utxo's they spent have been removed from our filter.
+	for spendHeight := range e.spends {
+		if spendHeight+electrumReorgDepth < height {
+			delete(e.spends, spendHeight)
+		}
+	}
+
+	return nil
+}
+
+// electrumFilterUpdate is a message sent to the chainFilterer to update the
+// current chainFilter state. Unlike filterUpdate, it carries the funding
+// scripts of the new utxo's, as electrum servers index outputs by script.
+type electrumFilterUpdate struct {
+	newUtxos     []graphdb.EdgePoint
+	updateHeight uint32
+}
+
+// FilterBlock takes a block hash, and returns a FilteredBlocks which is the
+// result of applying the current registered UTXO sub-set on the block
+// corresponding to that block hash. If any watched UTXO's are spent by the
+// selected block, then the internal chainFilter will also be updated.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *ElectrumFilteredChainView) FilterBlock(
+	blockHash *chainhash.Hash) (*FilteredBlock, error) {
+
+	req := &filterBlockReq{
+		blockHash: blockHash,
+		resp:      make(chan *FilteredBlock, 1),
+		err:       make(chan error, 1),
+	}
+
+	select {
+	case e.filterBlockReqs <- req:
+	case <-e.quit:
+		return nil, fmt.Errorf("FilteredChainView shutting down")
+	}
+
+	return <-req.resp, <-req.err
+}
+
+// UpdateFilter updates the UTXO filter which is to be consulted when creating
+// FilteredBlocks to be sent to subscribed clients. This method is cumulative
+// meaning repeated calls to this method should _expand_ the size of the UTXO
+// sub-set currently being watched.  If the set updateHeight is _lower_ than
+// the best known height of the implementation, then the state should be
+// rewound to ensure all relevant notifications are dispatched.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *ElectrumFilteredChainView) UpdateFilter(ops []graphdb.EdgePoint,
+	updateHeight uint32) error {
+
+	select {
+	case e.filterUpdates <- electrumFilterUpdate{
+		newUtxos:     ops,
+		updateHeight: updateHeight,
+	}:
+		return nil
+
+	case <-e.quit:
+		return fmt.Errorf("chain filter shutting down")
+	}
+}
+
+// FilteredBlocks returns the channel that filtered blocks are to be sent over.
+// Each time a block is connected to the end of a main chain, and appropriate
+// FilteredBlock which contains the transactions which mutate our watched UTXO
+// set is to be returned.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *ElectrumFilteredChainView) FilteredBlocks() <-chan *FilteredBlock {
+	return e.blockQueue.newBlocks
+}
+
+// DisconnectedBlocks returns a receive only channel which will be sent upon
+// with the empty filtered blocks of blocks which are disconnected from the
+// main chain in the case of a re-org.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *ElectrumFilteredChainView) DisconnectedBlocks() <-chan *FilteredBlock {
+	return e.blockQueue.staleBlocks
+}
@@ -0,0 +1,478 @@
+package chainview
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flokiorg/flnd/esploraio"
+	graphdb "github.com/flokiorg/flnd/graph/db"
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+	"github.com/flokiorg/go-flokicoin/wire"
+)
+
+const (
+	// esploraReorgDepth is the number of connected block hashes the
+	// esplora chain view keeps around in order to detect reorgs.
+	esploraReorgDepth = 144
+)
+
+// EsploraFilteredChainView is an implementation of the FilteredChainView
+// interface which is backed by an Esplora API. The view polls the API for its
+// chain tip, and filters the full blocks of the backend's validated most-work
+// header chain once they've been verified against their headers. When the
+// filter is updated, spends of the new utxo's in earlier blocks are looked up
+// through the API's outspend index rather than by rescanning blocks.
+type EsploraFilteredChainView struct {
+	started int32 // To be used atomically.
+	stopped int32 // To be used atomically.
+
+	backend *esploraio.Backend
+
+	// pollInterval is how often the API is polled for its chain tip.
+	pollInterval time.Duration
+
+	// bestHeight and bestHash describe the latest block added to the
+	// blockQueue. They're only accessed by the chainFilterer goroutine
+	// once started.
+	bestHeight uint32
+	bestHash   chainhash.Hash
+
+	// blockHashes holds the hashes of the blocks we've connected that
+	// are within esploraReorgDepth of our best block, by height.
+	blockHashes map[uint32]chainhash.Hash
+
+	// blockEventQueue is the ordered queue used to keep the order
+	// of connected and disconnected blocks sent to the reader of the
+	// chainView.
+	blockQueue *blockEventQueue
+
+	// filterUpdates is a channel in which updates to the utxo filter
+	// attached to this instance are sent over.
+	filterUpdates chan filterUpdate
+
+	// chainFilter is the set of utxo's that we're currently watching
+	// spends for within the chain. It's only accessed by the
+	// chainFilterer goroutine once started.
+	chainFilter map[wire.OutPoint]struct{}
+
+	// filterBlockReqs is a channel in which requests to filter select
+	// blocks will be sent over.
+	filterBlockReqs chan *filterBlockReq
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// A compile time check to ensure EsploraFilteredChainView implements the
+// chainview.FilteredChainView.
+var _ FilteredChainView = (*EsploraFilteredChainView)(nil)
+
+// NewEsploraFilteredChainView creates a new instance of a FilteredChainView
+// backed by the given Esplora backend, which is polled for new blocks at the
+// given interval.
+func NewEsploraFilteredChainView(backend *esploraio.Backend,
+	pollInterval time.Duration) *EsploraFilteredChainView {
+
+	return &EsploraFilteredChainView{
+		backend:         backend,
+		pollInterval:    pollInterval,
+		blockHashes:     make(map[uint32]chainhash.Hash),
+		blockQueue:      newBlockEventQueue(),
+		filterUpdates:   make(chan filterUpdate),
+		chainFilter:     make(map[wire.OutPoint]struct{}),
+		filterBlockReqs: make(chan *filterBlockReq),
+		quit:            make(chan struct{}),
+	}
+}
+
+// Start starts all goroutines necessary for normal operation.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *EsploraFilteredChainView) Start() error {
+	// Already started?
+	if atomic.AddInt32(&e.started, 1) != 1 {
+		return nil
+	}
+
+	log.Infof("FilteredChainView starting")
+
+	bestHash, bestHeight, err := e.backend.BestBlock()
+	if err != nil {
+		return err
+	}
+	e.bestHeight = uint32(bestHeight)
+	e.bestHash = *bestHash
+	e.blockHashes[e.bestHeight] = e.bestHash
+
+	e.blockQueue.Start()
+
+	e.wg.Add(1)
+	go e.chainFilterer()
+
+	return nil
+}
+
+// Stop stops all goroutines which we launched by the prior call to the Start
+// method.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *EsploraFilteredChainView) Stop() error {
+	log.Debug("EsploraFilteredChainView stopping")
+	defer log.Debug("EsploraFilteredChainView stopped")
+
+	// Already shutting down?
+	if atomic.AddInt32(&e.stopped, 1) != 1 {
+		return nil
+	}
+
+	e.blockQueue.Stop()
+
+	close(e.quit)
+	e.wg.Wait()
+
+	return nil
+}
+
+// chainFilterer is the primary goroutine which: listens for new blocks coming
+// and dispatches the relevant FilteredBlock notifications, updates the filter
+// due to requests by callers, and finally is able to preform targeted block
+// filtration.
+func (e *EsploraFilteredChainView) chainFilterer() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		// The caller has just sent an update to the current chain
+		// filter, so we'll apply the update, possibly rewinding our
+		// state partially.
+		case update := <-e.filterUpdates:
+			log.Tracef("Updating chain filter with new UTXO's: %v",
+				update.newUtxos)
+
+			err := e.applyFilterUpdate(update)
+			if err != nil {
+				log.Errorf("Unable to update chain filter: %v",
+					err)
+			}
+
+		// We've received a new request to manually filter a block.
+		case req := <-e.filterBlockReqs:
+			block, err := e.filterBlockByHash(req.blockHash)
+			req.resp <- block
+			req.err <- err
+
+		case <-ticker.C:
+			if err := e.syncChain(); err != nil {
+				log.Errorf("Unable to sync with the chain: %v",
+					err)
+			}
+
+		case <-e.quit:
+			return
+		}
+	}
+}
+
+// applyFilterUpdate adds the new utxo's of a filter update to our chain
+// filter. Any spends of these utxo's that were confirmed after the update
+// height, up to our best block, are dispatched as updates to the blocks that
+// hold them.
+func (e *EsploraFilteredChainView) applyFilterUpdate(
+	update filterUpdate) error {
+
+	for _, op := range update.newUtxos {
+		e.chainFilter[op] = struct{}{}
+	}
+
+	// If the update height matches our best known height, then we don't
+	// need to do any rewinding.
+	if update.updateHeight >= e.bestHeight {
+		return nil
+	}
+
+	// Otherwise, we'll look up the spends of the new utxo's to ensure the
+	// caller doesn't miss any relevant notifications. A block spending
+	// several of them is only dispatched once.
+	spendBlocks := make(map[uint32]*chainhash.Hash)
+	for _, op := range update.newUtxos {
+		spend, _, err := e.backend.SpendingTx(&op)
+		if err != nil {
+			return err
+		}
+
+		// Spends confirmed above our best block will be found once we
+		// connect the block holding them.
+		if spend == nil || spend.BlockHeight <= update.updateHeight ||
+			spend.BlockHeight > e.bestHeight {
+
+			continue
+		}
+
+		spendBlocks[spend.BlockHeight] = &spend.BlockHash
+	}
+
+	for height := update.updateHeight + 1; height <= e.bestHeight; height++ {
+		blockHash, ok := spendBlocks[height]
+		if !ok {
+			continue
+		}
+
+		block, err := e.backend.GetBlock(blockHash)
+		if err != nil {
+			return err
+		}
+
+		e.blockQueue.Add(&blockEvent{
+			eventType: connected,
+			block: &FilteredBlock{
+				Hash:         *blockHash,
+				Height:       height,
+				Transactions: e.filterBlock(block),
+			},
+		})
+	}
+
+	return nil
+}
+
+// filterBlock scans the given block, and notes which transactions spend
+// outputs which are currently being watched. Additionally, the chain filter
+// will also be updated by removing any spent outputs.
+func (e *EsploraFilteredChainView) filterBlock(
+	block *wire.MsgBlock) []*wire.MsgTx {
+
+	var filteredTxns []*wire.MsgTx
+	for _, tx := range block.Transactions {
+		var txAlreadyFiltered bool
+		for _, txIn := range tx.TxIn {
+			prevOp := txIn.PreviousOutPoint
+			if _, ok := e.chainFilter[prevOp]; !ok {
+				continue
+			}
+
+			delete(e.chainFilter, prevOp)
+
+			// Only add this txn to our list of filtered txns if it
+			// is the first previous outpoint to cause a match.
+			if txAlreadyFiltered {
+				continue
+			}
+
+			filteredTxns = append(filteredTxns, tx.Copy())
+			txAlreadyFiltered = true
+		}
+	}
+
+	return filteredTxns
+}
+
+// filterBlockByHash filters the main chain block with the given hash.
+func (e *EsploraFilteredChainView) filterBlockByHash(
+	blockHash *chainhash.Hash) (*FilteredBlock, error) {
+
+	height, err := e.backend.BlockHeight(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := e.backend.GetBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilteredBlock{
+		Hash:         *blockHash,
+		Height:       uint32(height),
+		Transactions: e.filterBlock(block),
+	}, nil
+}
+
+// syncChain brings the chain view in line with the main chain of the
+// backend's header chain, after syncing it with the Esplora API. Any of our
+// blocks that are no longer part of the main chain are disconnected first,
+// after which the main chain's blocks above our new best block are connected
+// one at a time.
+func (e *EsploraFilteredChainView) syncChain() error {
+	_, bestHeight, err := e.backend.BestBlock()
+	if err != nil {
+		return err
+	}
+	tipHeight := uint32(bestHeight)
+
+	for e.bestHeight > 0 {
+		if e.bestHeight <= tipHeight {
+			hash, err := e.backend.BlockHash(int32(e.bestHeight))
+			if err != nil {
+				return err
+			}
+
+			if *hash == e.bestHash {
+				break
+			}
+		}
+
+		if err := e.disconnectTip(); err != nil {
+			return err
+		}
+	}
+
+	for height := e.bestHeight + 1; height <= tipHeight; height++ {
+		hash, header, err := e.backend.BlockHeaderByHeight(
+			int32(height),
+		)
+		if err != nil {
+			return err
+		}
+
+		// If the block doesn't extend our tip, the backend's header
+		// chain switched to another branch while we were syncing.
+		// We'll catch up with it on our next sync.
+		if header.PrevBlock != e.bestHash {
+			return fmt.Errorf("block %v at height %d doesn't "+
+				"extend our tip %v", hash, height, e.bestHash)
+		}
+
+		if err := e.connectBlock(height, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// disconnectTip disconnects our best block, which is no longer part of the
+// main chain.
+func (e *EsploraFilteredChainView) disconnectTip() error {
+	log.Debugf("got disconnected block at height %d: %v", e.bestHeight,
+		e.bestHash)
+
+	// If we didn't connect the previous block ourselves, as happens when
+	// the block we started out with is reorged out, we'll look it up by
+	// the hash our block commits to, as the main chain's block at that
+	// height may be part of the new branch already.
+	prevHash, ok := e.blockHashes[e.bestHeight-1]
+	if !ok {
+		header, err := e.backend.BlockHeader(&e.bestHash)
+		if err != nil {
+			return err
+		}
+		prevHash = header.PrevBlock
+	}
+
+	e.blockQueue.Add(&blockEvent{
+		eventType: disconnected,
+		block: &FilteredBlock{
+			Hash:   e.bestHash,
+			Height: e.bestHeight,
+		},
+	})
+
+	delete(e.blockHashes, e.bestHeight)
+	e.bestHeight--
+	e.bestHash = prevHash
+
+	return nil
+}
+
+// connectBlock fetches the verified block with the given hash, and dispatches
+// it along with the spends of watched utxo's it holds.
+func (e *EsploraFilteredChainView) connectBlock(height uint32,
+	hash *chainhash.Hash) error {
+
+	block, err := e.backend.GetBlock(hash)
+	if err != nil {
+		return err
+	}
+
+	e.bestHeight = height
+	e.bestHash = *hash
+	e.blockHashes[height] = *hash
+	delete(e.blockHashes, height-esploraReorgDepth)
+
+	e.blockQueue.Add(&blockEvent{
+		eventType: connected,
+		block: &FilteredBlock{
+			Hash:         *hash,
+			Height:       height,
+			Transactions: e.filterBlock(block),
+		},
+	})
+
+	return nil
+}
+
+// FilterBlock takes a block hash, and returns a FilteredBlocks which is the
+// result of applying the current registered UTXO sub-set on the block
+// corresponding to that block hash. If any watched UTXO's are spent by the
+// selected block, then the internal chainFilter will also be updated.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *EsploraFilteredChainView) FilterBlock(
+	blockHash *chainhash.Hash) (*FilteredBlock, error) {
+
+	req := &filterBlockReq{
+		blockHash: blockHash,
+		resp:      make(chan *FilteredBlock, 1),
+		err:       make(chan error, 1),
+	}
+
+	select {
+	case e.filterBlockReqs <- req:
+	case <-e.quit:
+		return nil, fmt.Errorf("FilteredChainView shutting down")
+	}
+
+	return <-req.resp, <-req.err
+}
+
+// UpdateFilter updates the UTXO filter which is to be consulted when creating
+// FilteredBlocks to be sent to subscribed clients. This method is cumulative
+// meaning repeated calls to this method should _expand_ the size of the UTXO
+// sub-set currently being watched.  If the set updateHeight is _lower_ than
+// the best known height of the implementation, then the state should be
+// rewound to ensure all relevant notifications are dispatched.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *EsploraFilteredChainView) UpdateFilter(ops []graphdb.EdgePoint,
+	updateHeight uint32) error {
+
+	newUtxos := make([]wire.OutPoint, len(ops))
+	for i, op := range ops {
+		newUtxos[i] = op.OutPoint
+	}
+
+	select {
+	case e.filterUpdates <- filterUpdate{
+		newUtxos:     newUtxos,
+		updateHeight: updateHeight,
+	}:
+		return nil
+
+	case <-e.quit:
+		return fmt.Errorf("chain filter shutting down")
+	}
+}
+
+// FilteredBlocks returns the channel that filtered blocks are to be sent over.
+// Each time a block is connected to the end of a main chain, and appropriate
+// FilteredBlock which contains the transactions which mutate our watched UTXO
+// set is to be returned.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *EsploraFilteredChainView) FilteredBlocks() <-chan *FilteredBlock {
+	return e.blockQueue.newBlocks
+}
+
+// DisconnectedBlocks returns a receive only channel which will be sent upon
+// with the empty filtered blocks of blocks which are disconnected from the
+// main chain in the case of a re-org.
+//
+// NOTE: This is part of the FilteredChainView interface.
+func (e *EsploraFilteredChainView) DisconnectedBlocks() <-chan *FilteredBlock {
+	return e.blockQueue.staleBlocks
+}
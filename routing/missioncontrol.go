@@ -540,6 +540,18 @@ func (m *MissionControl) ResetHistory() error {
 func (m *MissionControl) GetProbability(fromNode, toNode route.Vertex,
 	amt lnwire.MilliLoki, capacity chainutil.Amount) float64 {
 
+	probability, _ := m.getProbability(fromNode, toNode, amt, capacity)
+
+	return probability
+}
+
+// getProbability returns the success probability of a payment from fromNode
+// along edge. If the estimator couldn't answer right away, the returned
+// channel is closed once its estimate arrives.
+func (m *MissionControl) getProbability(fromNode, toNode route.Vertex,
+	amt lnwire.MilliLoki, capacity chainutil.Amount) (float64,
+	<-chan struct{}) {
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -548,12 +560,78 @@ func (m *MissionControl) GetProbability(fromNode, toNode route.Vertex,
 
 	// Use a distinct probability estimation function for local channels.
 	if fromNode == m.cfg.selfNode {
-		return m.estimator.LocalPairProbability(now, results, toNode)
+		return m.estimator.LocalPairProbability(
+			now, results, toNode,
+		), nil
+	}
+
+	// Some estimators need to know the node the pair starts at as well.
+	if estimator, ok := m.estimator.(NodePairEstimator); ok {
+		return estimator.NodePairProbability(
+			now, results, fromNode, toNode, amt, capacity,
+		)
 	}
 
 	return m.estimator.PairProbability(
 		now, results, toNode, amt, capacity,
+	), nil
+}
+
+// EstimateRound collects the probability estimates that mission control
+// couldn't answer right away during a single path finding call, so that the
+// call can wait for them without waiting for those of concurrent calls.
+type EstimateRound struct {
+	mc *MissionControl
+
+	mu      sync.Mutex
+	pending []<-chan struct{}
+}
+
+// NewEstimateRound returns a new EstimateRound for a path finding call.
+func (m *MissionControl) NewEstimateRound() *EstimateRound {
+	return &EstimateRound{mc: m}
+}
+
+// GetProbability returns the success probability of a payment from fromNode
+// along edge, and remembers the estimate if it isn't available yet.
+func (r *EstimateRound) GetProbability(fromNode, toNode route.Vertex,
+	amt lnwire.MilliLoki, capacity chainutil.Amount) float64 {
+
+	probability, pending := r.mc.getProbability(
+		fromNode, toNode, amt, capacity,
 	)
+	if pending != nil {
+		r.mu.Lock()
+		r.pending = append(r.pending, pending)
+		r.mu.Unlock()
+	}
+
+	return probability
+}
+
+// AwaitEstimates waits for the probability estimates that the estimator
+// couldn't answer right away since the last call. The wait happens without
+// holding the mission control lock. It returns true if new estimates arrived,
+// so that path finding can be repeated with them.
+func (r *EstimateRound) AwaitEstimates() bool {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+
+	if len(pending) == 0 {
+		return false
+	}
+
+	r.mc.mu.Lock()
+	estimator, ok := r.mc.estimator.(NodePairEstimator)
+	r.mc.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	return estimator.AwaitEstimates(pending)
 }
 
 // GetHistorySnapshot takes a snapshot from the current mission control state
@@ -312,6 +312,75 @@ func TestMissionControlNamespaces(t *testing.T) {
 	ctx.expectP(1000, testAprioriHopProbability+0.05)
 }
 
+// TestMissionControlAwaitEstimates tests that mission control doesn't wait for
+// an external estimator while holding its lock, that each pathfinding round
+// waits for its own estimates outside of it instead, and that estimates are
+// queried again once mission control learned something new about the pair.
+func TestMissionControlAwaitEstimates(t *testing.T) {
+	ctx := createMcTestContext(t)
+
+	service := NewProbabilityService()
+	estimator := newTestExternalEstimator(t, service, time.Minute)
+
+	cfg := ctx.mc.GetConfig()
+	cfg.Estimator = estimator
+	require.NoError(t, ctx.mc.SetConfig(cfg))
+
+	client, err := service.Register()
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Nobody answers the query yet, so the fallback estimate is returned
+	// right away.
+	fallback := estimator.fallback.PairProbability(
+		mcTestTime, nil, mcTestNode2, 1000, testCapacity,
+	)
+	round := ctx.mc.NewEstimateRound()
+	probability := round.GetProbability(
+		mcTestNode1, mcTestNode2, 1000, testCapacity,
+	)
+	require.Equal(t, fallback, probability)
+
+	// Another round didn't ask for the estimate, so it doesn't wait for
+	// it.
+	require.False(t, ctx.mc.NewEstimateRound().AwaitEstimates())
+
+	awaited := make(chan bool)
+	go func() {
+		awaited <- round.AwaitEstimates()
+	}()
+
+	// While the round waits for the service, mission control remains
+	// available.
+	batch := receiveBatch(t, client)
+	ctx.expectP(1000, fallback)
+	ctx.reportSuccess()
+
+	require.NoError(t, client.Resolve(batch.ID, []float64{0.25}))
+	require.True(t, <-awaited)
+
+	// The answer was estimated before the success was reported, so it
+	// isn't used anymore and the pair is queried again with its result.
+	round = ctx.mc.NewEstimateRound()
+	probability = round.GetProbability(
+		mcTestNode1, mcTestNode2, 1000, testCapacity,
+	)
+	require.NotEqual(t, 0.25, probability)
+
+	go func() {
+		awaited <- round.AwaitEstimates()
+	}()
+
+	batch = receiveBatch(t, client)
+	require.Len(t, batch.Queries, 1)
+	require.True(t, batch.Queries[0].PairResult.IsSome())
+
+	require.NoError(t, client.Resolve(batch.ID, []float64{0.5}))
+	require.True(t, <-awaited)
+
+	ctx.expectP(1000, 0.5)
+}
+
 // testClock is an implementation of clock.Clock that lets the caller overwrite
 // the current time at any point.
 type testClock struct {
@@ -117,6 +117,10 @@ type finalHopParams struct {
 	// metadata is additional data that is sent along with the payment to
 	// the payee.
 	metadata []byte
+
+	// trampolineOnion is the onion for the trampoline node the payment is
+	// delivered to, if the target of the route is a trampoline node.
+	trampolineOnion []byte
 }
 
 // newRoute constructs a route using the provided path and final hop constraints.
@@ -212,6 +216,7 @@ func newRoute(sourceVertex route.Vertex,
 			customRecords       record.CustomSet
 			mpp                 *record.MPP
 			metadata            []byte
+			trampolineOnion     []byte
 		)
 
 		// Define a helper function that checks this edge's feature
@@ -266,6 +271,7 @@ func newRoute(sourceVertex route.Vertex,
 			})
 
 			metadata = finalHop.metadata
+			trampolineOnion = finalHop.trampolineOnion
 
 			if blindedPathSet != nil {
 				totalAmtMsatBlinded = finalHop.totalAmt
@@ -314,6 +320,7 @@ func newRoute(sourceVertex route.Vertex,
 			MPP:              mpp,
 			Metadata:         metadata,
 			TotalAmtMsat:     totalAmtMsatBlinded,
+			TrampolineOnion:  trampolineOnion,
 		}
 
 		hops = append([]*route.Hop{currentHop}, hops...)
@@ -438,6 +445,11 @@ type RestrictParams struct {
 	ProbabilitySource func(route.Vertex, route.Vertex,
 		lnwire.MilliLoki, chainutil.Amount) float64
 
+	// AwaitEstimates optionally waits for the probability estimates that
+	// ProbabilitySource couldn't answer right away. It returns true if new
+	// estimates arrived, in which case FindRoute searches again with them.
+	AwaitEstimates func() bool
+
 	// FeeLimit is a maximum fee amount allowed to be used on the path from
 	// the source to the target.
 	FeeLimit lnwire.MilliLoki
@@ -450,6 +462,12 @@ type RestrictParams struct {
 	// is reached. If nil, any node may be used.
 	LastHop *route.Vertex
 
+	// IncomingChannelIDs is the list of channels that are allowed for the
+	// last hop into the final destination. It is mostly useful for routes
+	// to self, which can be restricted to return through one of our own
+	// channels. If nil, any channel may be used.
+	IncomingChannelIDs []uint64
+
 	// CltvLimit is the maximum time lock of the route excluding the final
 	// ctlv. After path finding is complete, the caller needs to increase
 	// all cltv expiry heights with the required final cltv delta.
@@ -478,6 +496,11 @@ type RestrictParams struct {
 	// the payee.
 	Metadata []byte
 
+	// TrampolineOnion is the onion for the trampoline node the payment is
+	// delivered to. It's needed to determine the hop size of the last
+	// hop.
+	TrampolineOnion []byte
+
 	// BlindedPaymentPathSet is necessary to determine the hop size of the
 	// last/exit hop.
 	BlindedPaymentPathSet *BlindedPaymentPathSet
@@ -664,6 +687,15 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 		}
 	}
 
+	// Set up incoming channel map for quicker access.
+	var incomingChanMap map[uint64]struct{}
+	if len(r.IncomingChannelIDs) > 0 {
+		incomingChanMap = make(map[uint64]struct{})
+		for _, inChan := range r.IncomingChannelIDs {
+			incomingChanMap[inChan] = struct{}{}
+		}
+	}
+
 	// If we are routing from ourselves, check that we have enough local
 	// balance available.
 	if source == self {
@@ -1064,6 +1096,12 @@ func findPath(g *graphParams, r *RestrictParams, cfg *PathFindingConfig,
 			self, pivot, !isExitHop, outgoingChanMap,
 		)
 
+		// Only the connections into the target are subject to the
+		// incoming channel restriction.
+		if pivot == target {
+			u.inChanRestr = incomingChanMap
+		}
+
 		err := u.addGraphPolicies(g.graph)
 		if err != nil {
 			return nil, 0, err
@@ -1626,6 +1664,7 @@ func lastHopPayloadSize(r *RestrictParams, finalHtlcExpiry int32,
 		MPP:              mpp,
 		AMP:              amp,
 		Metadata:         r.Metadata,
+		TrampolineOnion:  r.TrampolineOnion,
 	}
 
 	// The final hop does not have a short chanID set.
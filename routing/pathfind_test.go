@@ -3015,6 +3015,10 @@ func runRouteToSelf(t *testing.T, useCache bool) {
 			Expiry:      144,
 			FeeBaseMsat: 1000,
 		}, 3),
+		symmetricTestChannel("source", "b", 100000, &testChannelPolicy{
+			Expiry:      144,
+			FeeBaseMsat: 200,
+		}, 4),
 	}
 
 	ctx := newPathFindingTestContext(t, useCache, testChannels, "source")
@@ -3038,6 +3042,16 @@ func runRouteToSelf(t *testing.T, useCache bool) {
 	path, err = ctx.findPath(target, paymentAmt)
 	require.NoError(t, err, "unable to find path")
 	ctx.assertPath(path, []uint64{1, 3, 2})
+
+	// Find the best path to self given that we want to go out via channel 1
+	// and return through channel 4. Restricting the last hop to node b
+	// alone doesn't select it, as the policy of the parallel channel 2 is
+	// used for both.
+	ctx.restrictParams.LastHop = nil
+	ctx.restrictParams.IncomingChannelIDs = []uint64{4}
+	path, err = ctx.findPath(target, paymentAmt)
+	require.NoError(t, err, "unable to find path")
+	ctx.assertPath(path, []uint64{1, 3, 4})
 }
 
 // runInboundFees tests whether correct routes are built when inbound fees
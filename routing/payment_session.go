@@ -280,8 +280,15 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliLoki,
 	// Taking into account this prune view, we'll attempt to locate a path
 	// to our destination, respecting the recommendations from
 	// MissionController.
+	//
+	// The estimates mission control couldn't answer right away are only
+	// waited for by this call.
+	getProbability, awaitEstimates := newProbabilityRound(
+		p.missionControl,
+	)
 	restrictions := &RestrictParams{
-		ProbabilitySource:     p.missionControl.GetProbability,
+		ProbabilitySource:     getProbability,
+		AwaitEstimates:        awaitEstimates,
 		FeeLimit:              feeLimit,
 		OutgoingChannelIDs:    p.payment.OutgoingChannelIDs,
 		LastHop:               p.payment.LastHop,
@@ -291,6 +298,7 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliLoki,
 		PaymentAddr:           p.payment.PaymentAddr,
 		Amp:                   p.payment.amp,
 		Metadata:              p.payment.Metadata,
+		TrampolineOnion:       p.payment.TrampolineOnion,
 		FirstHopCustomRecords: firstHopCustomRecords,
 	}
 
@@ -324,16 +332,31 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliLoki,
 		p.log.Debugf("pathfinding for amt=%v", maxAmt)
 
 		// Find a route for the current amount.
-		path, _, err = p.pathFinder(
-			&graphParams{
-				additionalEdges: p.additionalEdges,
-				bandwidthHints:  bandwidthHints,
-				graph:           graph,
-			},
-			restrictions, &p.pathFindingConfig,
-			p.selfNode, p.selfNode, p.payment.Target,
-			maxAmt, p.payment.TimePref, finalHtlcExpiry,
-		)
+		find := func() error {
+			path, _, err = p.pathFinder(
+				&graphParams{
+					additionalEdges: p.additionalEdges,
+					bandwidthHints:  bandwidthHints,
+					graph:           graph,
+				},
+				restrictions, &p.pathFindingConfig,
+				p.selfNode, p.selfNode, p.payment.Target,
+				maxAmt, p.payment.TimePref, finalHtlcExpiry,
+			)
+
+			return err
+		}
+		err = find()
+
+		// If mission control asked for probability estimates that
+		// weren't available yet, we wait for them and search again
+		// with their answers.
+		if awaitEstimates() {
+			p.log.Debugf("pathfinding again with new probability " +
+				"estimates")
+
+			err = find()
+		}
 		if err != nil {
 			// Wrap the error to distinguish path finding errors
 			// from other errors in this closure.
@@ -441,12 +464,13 @@ func (p *paymentSession) RequestRoute(maxAmt, feeLimit lnwire.MilliLoki,
 		route, err := newRoute(
 			p.selfNode, path, height,
 			finalHopParams{
-				amt:         maxAmt,
-				totalAmt:    p.payment.Amount,
-				cltvDelta:   finalCltvDelta,
-				records:     p.payment.DestCustomRecords,
-				paymentAddr: p.payment.PaymentAddr,
-				metadata:    p.payment.Metadata,
+				amt:             maxAmt,
+				totalAmt:        p.payment.Amount,
+				cltvDelta:       finalCltvDelta,
+				records:         p.payment.DestCustomRecords,
+				paymentAddr:     p.payment.PaymentAddr,
+				metadata:        p.payment.Metadata,
+				trampolineOnion: p.payment.TrampolineOnion,
 			}, p.payment.BlindedPathSet,
 		)
 		if err != nil {
@@ -30,6 +30,30 @@ type Estimator interface {
 	String() string
 }
 
+// NodePairEstimator is an Estimator that needs to know the node a pair starts
+// at to estimate its probability. Mission control passes the from node to it
+// for all pairs that don't start at our own node. Its estimates are answered
+// asynchronously, so that mission control doesn't block while holding its
+// lock.
+type NodePairEstimator interface {
+	Estimator
+
+	// NodePairProbability estimates the probability of successfully
+	// traversing from fromNode to toNode. The historical payment outcomes
+	// for the from node are passed in via the results parameter. If the
+	// estimate isn't available yet, a fallback estimate is returned along
+	// with a channel that is closed once the estimate arrives.
+	NodePairProbability(now time.Time, results NodeResults, fromNode,
+		toNode route.Vertex, amt lnwire.MilliLoki,
+		capacity chainutil.Amount) (float64, <-chan struct{})
+
+	// AwaitEstimates waits for the given estimates, which were returned
+	// as pending by NodePairProbability during a single path finding
+	// call. It returns true if new estimates arrived, in which case path
+	// finding should be repeated to make use of them.
+	AwaitEstimates(pending []<-chan struct{}) bool
+}
+
 // estimatorConfig represents a configuration for a probability estimator.
 type estimatorConfig interface {
 	// validate checks that all configuration parameters are sane.
@@ -0,0 +1,713 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+)
+
+const (
+	// DefaultExternalTimeout is the default value for ExternalTimeout in
+	// ExternalConfig. Every pathfinding round that misses the cache waits
+	// for the external service, so the timeout is kept short.
+	DefaultExternalTimeout = 10 * time.Millisecond
+
+	// DefaultExternalCacheTTL is the default value for ExternalCacheTTL in
+	// ExternalConfig.
+	DefaultExternalCacheTTL = time.Minute
+
+	// ExternalEstimatorName is used to identify the external estimator.
+	ExternalEstimatorName = "external"
+
+	// maxProbabilityBatchSize is the maximum number of queries that are
+	// sent to the external service at once.
+	maxProbabilityBatchSize = 1000
+
+	// maxExternalCacheSize is the number of cached probabilities after
+	// which expired entries are pruned from the cache.
+	maxExternalCacheSize = 100_000
+
+	// amountBucketBits is the number of most significant bits that are
+	// kept when rounding amounts into buckets. Queries for amounts in the
+	// same bucket share their cached probability.
+	amountBucketBits = 4
+)
+
+var (
+	// ErrInvalidExternalTimeout is returned when we get an external
+	// timeout that isn't positive.
+	ErrInvalidExternalTimeout = errors.New("external timeout must be " +
+		"larger than zero")
+
+	// ErrInvalidCacheTTL is returned when we get a cache ttl that isn't
+	// positive.
+	ErrInvalidCacheTTL = errors.New("cache ttl must be larger than zero")
+
+	// ErrProbabilityServiceRegistered is returned when a probability
+	// service registers while another one is still connected.
+	ErrProbabilityServiceRegistered = errors.New("a probability service " +
+		"is already registered")
+
+	// ErrProbabilityServiceClosed is returned for the queries that were
+	// pending when the probability service disconnected.
+	ErrProbabilityServiceClosed = errors.New("probability service " +
+		"disconnected")
+
+	// ErrUnknownProbabilityBatch is returned when the probability service
+	// answers a batch that isn't pending.
+	ErrUnknownProbabilityBatch = errors.New("unknown probability batch")
+)
+
+// ExternalConfig contains configuration for the external probability
+// estimator.
+type ExternalConfig struct {
+	// ExternalTimeout is the time a pathfinding round waits for the
+	// external service to estimate the probabilities it asked for before
+	// falling back to the apriori estimator. Once a round timed out, the
+	// service is considered slow and we don't wait for it anymore until it
+	// answers again.
+	ExternalTimeout time.Duration
+
+	// ExternalCacheTTL is the time a probability that was estimated by the
+	// external service is reused for. As pathfinding only learns about the
+	// estimates through the cache, it must be positive.
+	ExternalCacheTTL time.Duration
+
+	// FallbackConfig configures the apriori estimator that is used while
+	// no external service is registered or the service is slow.
+	FallbackConfig AprioriConfig
+}
+
+// validate checks the configuration of the estimator for allowed values.
+func (p ExternalConfig) validate() error {
+	if p.ExternalTimeout <= 0 {
+		return fmt.Errorf("%v: %w", ExternalEstimatorName,
+			ErrInvalidExternalTimeout)
+	}
+
+	if p.ExternalCacheTTL <= 0 {
+		return fmt.Errorf("%v: %w", ExternalEstimatorName,
+			ErrInvalidCacheTTL)
+	}
+
+	if err := p.FallbackConfig.validate(); err != nil {
+		return fmt.Errorf("%v fallback: %w", ExternalEstimatorName,
+			err)
+	}
+
+	return nil
+}
+
+// DefaultExternalConfig returns the default configuration for the estimator.
+func DefaultExternalConfig() ExternalConfig {
+	return ExternalConfig{
+		ExternalTimeout:  DefaultExternalTimeout,
+		ExternalCacheTTL: DefaultExternalCacheTTL,
+		FallbackConfig:   DefaultAprioriConfig(),
+	}
+}
+
+// ProbabilityQuery asks the external service for the success probability of
+// a pair.
+type ProbabilityQuery struct {
+	// FromNode is the node the pair starts at.
+	FromNode route.Vertex
+
+	// ToNode is the node the pair ends at.
+	ToNode route.Vertex
+
+	// Amount is the amount to send. It is rounded up, so that queries for
+	// similar amounts can share their answer.
+	Amount lnwire.MilliLoki
+
+	// Capacity is the capacity of the channel between the nodes.
+	Capacity chainutil.Amount
+
+	// PairResult holds what mission control learned about the pair, if
+	// anything.
+	PairResult fn.Option[TimedPairResult]
+}
+
+// probabilityKey identifies the answer to a query.
+type probabilityKey struct {
+	fromNode route.Vertex
+	toNode   route.Vertex
+	amount   lnwire.MilliLoki
+	capacity chainutil.Amount
+}
+
+// key returns the key of the query's answer.
+func (q *ProbabilityQuery) key() probabilityKey {
+	return probabilityKey{
+		fromNode: q.FromNode,
+		toNode:   q.ToNode,
+		amount:   q.Amount,
+		capacity: q.Capacity,
+	}
+}
+
+// ProbabilityBatch is a set of queries that are sent to the external service
+// together.
+type ProbabilityBatch struct {
+	// ID identifies the batch in the answer of the service.
+	ID uint64
+
+	// Queries are the queries of the batch.
+	Queries []*ProbabilityQuery
+}
+
+// probabilityCallback is called with the answer to a query, or with an error
+// if the query won't be answered.
+type probabilityCallback func(probability float64, err error)
+
+// pendingQuery is a query that is waiting to be answered.
+type pendingQuery struct {
+	query     *ProbabilityQuery
+	callbacks []probabilityCallback
+}
+
+// ProbabilityService connects the external estimators to the out-of-process
+// service that estimates probabilities for them. At most one service can be
+// registered at a time.
+type ProbabilityService struct {
+	mu     sync.Mutex
+	client *ProbabilityClient
+}
+
+// NewProbabilityService creates a new ProbabilityService without a registered
+// client.
+func NewProbabilityService() *ProbabilityService {
+	return &ProbabilityService{}
+}
+
+// Register registers a new out-of-process service. The service receives the
+// batches of queries from the returned client and answers them through it.
+func (s *ProbabilityService) Register() (*ProbabilityClient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		return nil, ErrProbabilityServiceRegistered
+	}
+
+	client := &ProbabilityClient{
+		service:  s,
+		batches:  make(chan *ProbabilityBatch),
+		wake:     make(chan struct{}, 1),
+		pending:  make(map[probabilityKey]*pendingQuery),
+		inFlight: make(map[uint64][]*pendingQuery),
+		quit:     make(chan struct{}),
+	}
+	s.client = client
+
+	client.wg.Add(1)
+	go client.batchLoop()
+
+	log.Infof("External probability service registered")
+
+	return client, nil
+}
+
+// query passes the query on to the registered client. It returns false if no
+// client is registered, in which case the callback is never called. If the
+// caller doesn't wait for the answer and the same query is already pending,
+// the callback is dropped as well.
+func (s *ProbabilityService) query(query *ProbabilityQuery,
+	callback probabilityCallback, wait bool) bool {
+
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	client := s.client
+	s.mu.Unlock()
+
+	if client == nil {
+		return false
+	}
+
+	return client.enqueue(query, callback, wait)
+}
+
+// unregister removes the given client if it is still registered.
+func (s *ProbabilityService) unregister(client *ProbabilityClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == client {
+		s.client = nil
+	}
+}
+
+// ProbabilityClient is the connection to a registered out-of-process service.
+// Queries that arrive while the previous batch is still being sent are
+// collected into the next batch, and queries for the same pair and amount are
+// only sent once.
+type ProbabilityClient struct {
+	service *ProbabilityService
+
+	// batches delivers the batches to send to the service.
+	batches chan *ProbabilityBatch
+
+	// wake signals the batch loop that queries were queued.
+	wake chan struct{}
+
+	mu sync.Mutex
+
+	// queue holds the queries that weren't sent yet.
+	queue []*pendingQuery
+
+	// pending holds all queries that weren't answered yet, whether they
+	// were sent or not.
+	pending map[probabilityKey]*pendingQuery
+
+	// inFlight holds the queries of the batches that were sent, keyed by
+	// the batch ID.
+	inFlight map[uint64][]*pendingQuery
+
+	nextBatchID uint64
+
+	closeOnce sync.Once
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// Batches returns the channel the batches of queries for the service are
+// delivered on.
+func (c *ProbabilityClient) Batches() <-chan *ProbabilityBatch {
+	return c.batches
+}
+
+// enqueue queues the query for the next batch. It returns false if the client
+// is closed.
+func (c *ProbabilityClient) enqueue(query *ProbabilityQuery,
+	callback probabilityCallback, wait bool) bool {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.quit:
+		return false
+	default:
+	}
+
+	// If the same query is already pending, we only need to be told about
+	// its answer if we wait for it. This keeps the callbacks from piling up
+	// while the service is slow.
+	key := query.key()
+	if pending, ok := c.pending[key]; ok {
+		if wait {
+			pending.callbacks = append(pending.callbacks, callback)
+		}
+
+		return true
+	}
+
+	pending := &pendingQuery{
+		query:     query,
+		callbacks: []probabilityCallback{callback},
+	}
+	c.pending[key] = pending
+	c.queue = append(c.queue, pending)
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// batchLoop delivers the queued queries in batches until the client is
+// closed.
+//
+// NOTE: This MUST be run as a goroutine.
+func (c *ProbabilityClient) batchLoop() {
+	defer c.wg.Done()
+
+	for {
+		select {
+		case <-c.wake:
+		case <-c.quit:
+			return
+		}
+
+		for {
+			batch := c.nextBatch()
+			if batch == nil {
+				break
+			}
+
+			select {
+			case c.batches <- batch:
+			case <-c.quit:
+				return
+			}
+		}
+	}
+}
+
+// nextBatch takes the next batch of queries from the queue and marks it as
+// in flight. It returns nil if the queue is empty.
+func (c *ProbabilityClient) nextBatch() *ProbabilityBatch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.queue) == 0 {
+		return nil
+	}
+
+	n := min(len(c.queue), maxProbabilityBatchSize)
+	queries := c.queue[:n]
+	c.queue = c.queue[n:]
+
+	batch := &ProbabilityBatch{
+		ID:      c.nextBatchID,
+		Queries: make([]*ProbabilityQuery, 0, n),
+	}
+	for _, pending := range queries {
+		batch.Queries = append(batch.Queries, pending.query)
+	}
+
+	c.inFlight[batch.ID] = queries
+	c.nextBatchID++
+
+	return batch
+}
+
+// Resolve delivers the probabilities the service estimated for the queries of
+// the given batch, in the order of the queries.
+func (c *ProbabilityClient) Resolve(batchID uint64,
+	probabilities []float64) error {
+
+	c.mu.Lock()
+	queries, ok := c.inFlight[batchID]
+	if !ok {
+		c.mu.Unlock()
+		return fmt.Errorf("%w: %v", ErrUnknownProbabilityBatch, batchID)
+	}
+
+	if len(probabilities) != len(queries) {
+		c.mu.Unlock()
+		return fmt.Errorf("batch %v has %v queries, but got %v "+
+			"probabilities", batchID, len(queries),
+			len(probabilities))
+	}
+
+	for i, probability := range probabilities {
+		if probability < 0 || probability > 1 {
+			c.mu.Unlock()
+			return fmt.Errorf("probability %v of query %v in "+
+				"batch %v not in [0, 1]", probability, i,
+				batchID)
+		}
+	}
+
+	delete(c.inFlight, batchID)
+	for _, pending := range queries {
+		delete(c.pending, pending.query.key())
+	}
+	c.mu.Unlock()
+
+	for i, pending := range queries {
+		for _, callback := range pending.callbacks {
+			callback(probabilities[i], nil)
+		}
+	}
+
+	return nil
+}
+
+// Close unregisters the client. The queries that weren't answered yet are
+// failed.
+func (c *ProbabilityClient) Close() {
+	c.closeOnce.Do(func() {
+		c.service.unregister(c)
+
+		c.mu.Lock()
+		close(c.quit)
+		pending := c.pending
+		c.pending = make(map[probabilityKey]*pendingQuery)
+		c.queue = nil
+		c.inFlight = make(map[uint64][]*pendingQuery)
+		c.mu.Unlock()
+
+		c.wg.Wait()
+
+		for _, query := range pending {
+			for _, callback := range query.callbacks {
+				callback(0, ErrProbabilityServiceClosed)
+			}
+		}
+
+		log.Infof("External probability service unregistered")
+	})
+}
+
+// cachedProbability is a probability that was estimated by the external
+// service.
+type cachedProbability struct {
+	probability float64
+	timestamp   time.Time
+
+	// resultTime is the time of the last result mission control had for
+	// the pair when the probability was queried. Once mission control
+	// learns something new about the pair, the probability is queried
+	// again.
+	resultTime time.Time
+}
+
+// ExternalEstimator delegates the estimation of pair probabilities to an
+// out-of-process service that is registered with the ProbabilityService. The
+// answers of the service are cached for similar amounts. Estimates that aren't
+// cached are queried in the background, so that mission control never waits
+// for the service while holding its lock. Instead, each pathfinding call waits
+// for the queries it sent with AwaitEstimates and then runs again with the
+// answers.
+// While no service is registered or the service doesn't answer in time, the
+// probabilities are estimated by an apriori estimator, and so are the
+// probabilities of our own channels.
+type ExternalEstimator struct {
+	// ExternalConfig contains configuration options for our estimator.
+	ExternalConfig
+
+	// fallback estimates the probabilities the service doesn't answer.
+	fallback *AprioriEstimator
+
+	// service connects us to the registered out-of-process service.
+	service *ProbabilityService
+
+	// slow is set when the service didn't answer a query in time. We
+	// don't wait for the service while it's set.
+	slow atomic.Bool
+
+	cacheMtx sync.Mutex
+	cache    map[probabilityKey]cachedProbability
+}
+
+// NewExternalEstimator creates a new ExternalEstimator that sends its queries
+// to the service registered with the given ProbabilityService.
+func NewExternalEstimator(cfg ExternalConfig,
+	service *ProbabilityService) (*ExternalEstimator, error) {
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	fallback, err := NewAprioriEstimator(cfg.FallbackConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExternalEstimator{
+		ExternalConfig: cfg,
+		fallback:       fallback,
+		service:        service,
+		cache:          make(map[probabilityKey]cachedProbability),
+	}, nil
+}
+
+// Compile-time checks that interfaces are implemented.
+var _ NodePairEstimator = (*ExternalEstimator)(nil)
+var _ estimatorConfig = (*ExternalConfig)(nil)
+
+// Config returns the estimator's configuration.
+func (p *ExternalEstimator) Config() estimatorConfig {
+	return p.ExternalConfig
+}
+
+// String returns the estimator's configuration as a string representation.
+func (p *ExternalEstimator) String() string {
+	return fmt.Sprintf("estimator type: %v, timeout: %v, cache ttl: %v, "+
+		"fallback: (%v)", ExternalEstimatorName, p.ExternalTimeout,
+		p.ExternalCacheTTL, p.fallback)
+}
+
+// PairProbability estimates the probability of successfully traversing to
+// toNode with the fallback estimator, as the service can't be queried without
+// knowing the node the pair starts at.
+func (p *ExternalEstimator) PairProbability(now time.Time,
+	results NodeResults, toNode route.Vertex, amt lnwire.MilliLoki,
+	capacity chainutil.Amount) float64 {
+
+	return p.fallback.PairProbability(now, results, toNode, amt, capacity)
+}
+
+// LocalPairProbability estimates the probability of successfully traversing
+// our own channels to toNode with the fallback estimator.
+func (p *ExternalEstimator) LocalPairProbability(now time.Time,
+	results NodeResults, toNode route.Vertex) float64 {
+
+	return p.fallback.LocalPairProbability(now, results, toNode)
+}
+
+// NodePairProbability returns the probability of successfully traversing from
+// fromNode to toNode that the external service estimated, if it is cached.
+// Otherwise, the service is queried in the background and the probability is
+// estimated by the fallback estimator for now. In that case, the returned
+// channel is closed once the service answered, unless the service is slow.
+func (p *ExternalEstimator) NodePairProbability(now time.Time,
+	results NodeResults, fromNode, toNode route.Vertex,
+	amt lnwire.MilliLoki, capacity chainutil.Amount) (float64,
+	<-chan struct{}) {
+
+	query := &ProbabilityQuery{
+		FromNode: fromNode,
+		ToNode:   toNode,
+		Amount:   bucketAmount(amt),
+		Capacity: capacity,
+	}
+
+	var resultTime time.Time
+	if result, ok := results[toNode]; ok {
+		query.PairResult = fn.Some(result)
+		resultTime = lastResultTime(result)
+	}
+
+	key := query.key()
+	if probability, ok := p.cached(now, key, resultTime); ok {
+		return probability, nil
+	}
+
+	// While the service is slow, we only warm up the cache and don't
+	// wait for the answer.
+	wait := !p.slow.Load()
+	answered := make(chan struct{})
+	queued := p.service.query(query, func(probability float64,
+		err error) {
+
+		if err == nil {
+			p.slow.Store(false)
+			p.store(now, key, probability, resultTime)
+		}
+
+		close(answered)
+	}, wait)
+
+	probability := p.fallback.PairProbability(
+		now, results, toNode, amt, capacity,
+	)
+	if !queued || !wait {
+		return probability, nil
+	}
+
+	return probability, answered
+}
+
+// AwaitEstimates waits up to the external timeout for the answers to the
+// given queries, which were returned by NodePairProbability during a single
+// pathfinding call. It returns true if any of them was answered.
+func (p *ExternalEstimator) AwaitEstimates(
+	outstanding []<-chan struct{}) bool {
+
+	if len(outstanding) == 0 {
+		return false
+	}
+
+	timeout := time.NewTimer(p.ExternalTimeout)
+	defer timeout.Stop()
+
+	for i, answered := range outstanding {
+		select {
+		case <-answered:
+
+		case <-timeout.C:
+			log.Debugf("External probability service didn't "+
+				"answer %v of %v queries within %v, falling "+
+				"back to apriori estimation",
+				len(outstanding)-i, len(outstanding),
+				p.ExternalTimeout)
+
+			p.slow.Store(true)
+
+			return i > 0
+		}
+	}
+
+	return true
+}
+
+// cached returns the cached probability for the given key if it didn't
+// expire yet and was queried with the given last result of the pair.
+func (p *ExternalEstimator) cached(now time.Time, key probabilityKey,
+	resultTime time.Time) (float64, bool) {
+
+	p.cacheMtx.Lock()
+	defer p.cacheMtx.Unlock()
+
+	cached, ok := p.cache[key]
+	if !ok || now.Sub(cached.timestamp) >= p.ExternalCacheTTL {
+		return 0, false
+	}
+
+	if !cached.resultTime.Equal(resultTime) {
+		return 0, false
+	}
+
+	return cached.probability, true
+}
+
+// store caches the probability for the given key, along with the time of the
+// last result of the pair it was queried with.
+func (p *ExternalEstimator) store(now time.Time, key probabilityKey,
+	probability float64, resultTime time.Time) {
+
+	p.cacheMtx.Lock()
+	defer p.cacheMtx.Unlock()
+
+	// Prune the expired entries once the cache grows large. If that isn't
+	// enough, we start over.
+	if len(p.cache) >= maxExternalCacheSize {
+		for k, cached := range p.cache {
+			if now.Sub(cached.timestamp) >= p.ExternalCacheTTL {
+				delete(p.cache, k)
+			}
+		}
+
+		if len(p.cache) >= maxExternalCacheSize {
+			p.cache = make(map[probabilityKey]cachedProbability)
+		}
+	}
+
+	p.cache[key] = cachedProbability{
+		probability: probability,
+		timestamp:   now,
+		resultTime:  resultTime,
+	}
+}
+
+// lastResultTime returns the time of the latest result mission control
+// recorded for a pair.
+func lastResultTime(result TimedPairResult) time.Time {
+	if result.SuccessTime.After(result.FailTime) {
+		return result.SuccessTime
+	}
+
+	return result.FailTime
+}
+
+// bucketAmount rounds the amount up to keep only its most significant bits,
+// so that it is at most an eighth larger. This makes probabilities cached for
+// the bucket slightly conservative.
+func bucketAmount(amt lnwire.MilliLoki) lnwire.MilliLoki {
+	length := bits.Len64(uint64(amt))
+	if length <= amountBucketBits {
+		return amt
+	}
+
+	shift := length - amountBucketBits
+	step := uint64(1) << shift
+	rounded := (uint64(amt) + step - 1) >> shift << shift
+
+	return lnwire.MilliLoki(rounded)
+}
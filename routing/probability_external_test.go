@@ -0,0 +1,435 @@
+package routing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	externalFromNode = route.Vertex{1}
+	externalToNode   = route.Vertex{2}
+)
+
+const (
+	externalAmount   = lnwire.MilliLoki(100_000_000)
+	externalCapacity = chainutil.Amount(1_000_000)
+)
+
+// newTestExternalEstimator creates an external estimator that waits for the
+// service for the given time.
+func newTestExternalEstimator(t *testing.T, service *ProbabilityService,
+	timeout time.Duration) *ExternalEstimator {
+
+	cfg := DefaultExternalConfig()
+	cfg.ExternalTimeout = timeout
+
+	estimator, err := NewExternalEstimator(cfg, service)
+	require.NoError(t, err)
+
+	return estimator
+}
+
+// receiveBatch waits for the next batch of the client.
+func receiveBatch(t *testing.T, client *ProbabilityClient) *ProbabilityBatch {
+	select {
+	case batch := <-client.Batches():
+		return batch
+
+	case <-time.After(time.Second):
+		t.Fatal("no batch received")
+		return nil
+	}
+}
+
+// TestExternalEstimatorFallback tests that the apriori estimator is used
+// while no service is registered and for our own channels.
+func TestExternalEstimatorFallback(t *testing.T) {
+	t.Parallel()
+
+	service := NewProbabilityService()
+	estimator := newTestExternalEstimator(t, service, time.Second)
+
+	apriori, err := NewAprioriEstimator(DefaultAprioriConfig())
+	require.NoError(t, err)
+
+	now := time.Now()
+	expected := apriori.PairProbability(
+		now, nil, externalToNode, externalAmount, externalCapacity,
+	)
+	probability, pending := estimator.NodePairProbability(
+		now, nil, externalFromNode, externalToNode, externalAmount,
+		externalCapacity,
+	)
+	require.Equal(t, expected, probability)
+	require.Nil(t, pending)
+
+	// Our own channels are always estimated locally, even if a service
+	// is registered.
+	client, err := service.Register()
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.Equal(
+		t, apriori.LocalPairProbability(now, nil, externalToNode),
+		estimator.LocalPairProbability(now, nil, externalToNode),
+	)
+}
+
+// TestExternalEstimatorQuery tests that queries are answered by the service
+// in the background and that the answers are cached for similar amounts.
+func TestExternalEstimatorQuery(t *testing.T) {
+	t.Parallel()
+
+	service := NewProbabilityService()
+	estimator := newTestExternalEstimator(t, service, time.Minute)
+
+	client, err := service.Register()
+	require.NoError(t, err)
+	defer client.Close()
+
+	// A second service can't register while the first is connected.
+	_, err = service.Register()
+	require.ErrorIs(t, err, ErrProbabilityServiceRegistered)
+
+	// Without an answer, the fallback estimate is returned right away.
+	now := time.Now()
+	fallback := estimator.fallback.PairProbability(
+		now, nil, externalToNode, externalAmount, externalCapacity,
+	)
+	probability, pending := estimator.NodePairProbability(
+		now, nil, externalFromNode, externalToNode, externalAmount,
+		externalCapacity,
+	)
+	require.Equal(t, fallback, probability)
+	require.NotNil(t, pending)
+
+	awaited := make(chan bool)
+	go func() {
+		awaited <- estimator.AwaitEstimates(
+			[]<-chan struct{}{pending},
+		)
+	}()
+
+	batch := receiveBatch(t, client)
+	require.Len(t, batch.Queries, 1)
+	query := batch.Queries[0]
+	require.Equal(t, externalFromNode, query.FromNode)
+	require.Equal(t, externalToNode, query.ToNode)
+	require.Equal(t, bucketAmount(externalAmount), query.Amount)
+	require.Equal(t, externalCapacity, query.Capacity)
+	require.True(t, query.PairResult.IsNone())
+
+	// Answers that don't match the batch are rejected.
+	err = client.Resolve(batch.ID+1, []float64{0.3})
+	require.ErrorIs(t, err, ErrUnknownProbabilityBatch)
+	require.Error(t, client.Resolve(batch.ID, []float64{0.3, 0.4}))
+	require.Error(t, client.Resolve(batch.ID, []float64{1.5}))
+
+	require.NoError(t, client.Resolve(batch.ID, []float64{0.3}))
+	require.True(t, <-awaited)
+
+	// The answer is cached, and a slightly larger amount falls into the
+	// same bucket.
+	// As the answer was cached, there is nothing to wait for.
+	probability, pending = estimator.NodePairProbability(
+		now.Add(time.Second), nil, externalFromNode, externalToNode,
+		externalAmount+1, externalCapacity,
+	)
+	require.Equal(t, 0.3, probability)
+	require.Nil(t, pending)
+	require.False(t, estimator.AwaitEstimates(nil))
+
+	// Once the cached answer expired, the service is asked again.
+	later := now.Add(DefaultExternalCacheTTL)
+	probability, pending = estimator.NodePairProbability(
+		later, nil, externalFromNode, externalToNode, externalAmount,
+		externalCapacity,
+	)
+	require.Equal(t, fallback, probability)
+
+	go func() {
+		awaited <- estimator.AwaitEstimates(
+			[]<-chan struct{}{pending},
+		)
+	}()
+
+	batch = receiveBatch(t, client)
+	require.NoError(t, client.Resolve(batch.ID, []float64{0.7}))
+	require.True(t, <-awaited)
+
+	probability, _ = estimator.NodePairProbability(
+		later, nil, externalFromNode, externalToNode, externalAmount,
+		externalCapacity,
+	)
+	require.Equal(t, 0.7, probability)
+}
+
+// TestExternalEstimatorPairResult tests that a cached answer isn't used
+// anymore once mission control learned something new about the pair.
+func TestExternalEstimatorPairResult(t *testing.T) {
+	t.Parallel()
+
+	service := NewProbabilityService()
+	estimator := newTestExternalEstimator(t, service, time.Minute)
+
+	client, err := service.Register()
+	require.NoError(t, err)
+	defer client.Close()
+
+	// query asks for the probability of the pair with the given results
+	// and answers it with the given probability.
+	now := time.Now()
+	query := func(results NodeResults, answer float64) {
+		_, pending := estimator.NodePairProbability(
+			now, results, externalFromNode, externalToNode,
+			externalAmount, externalCapacity,
+		)
+		require.NotNil(t, pending)
+
+		batch := receiveBatch(t, client)
+		require.Len(t, batch.Queries, 1)
+		require.NoError(t, client.Resolve(batch.ID, []float64{answer}))
+		require.True(t, estimator.AwaitEstimates(
+			[]<-chan struct{}{pending},
+		))
+	}
+	cached := func(results NodeResults) float64 {
+		probability, pending := estimator.NodePairProbability(
+			now, results, externalFromNode, externalToNode,
+			externalAmount, externalCapacity,
+		)
+		require.Nil(t, pending)
+
+		return probability
+	}
+
+	query(nil, 0.3)
+	require.Equal(t, 0.3, cached(nil))
+
+	// A failure of the pair invalidates the answer.
+	failed := NodeResults{
+		externalToNode: {
+			FailTime: now.Add(-time.Second),
+			FailAmt:  externalAmount,
+		},
+	}
+	query(failed, 0.1)
+	require.Equal(t, 0.1, cached(failed))
+
+	// And so does a later success.
+	succeeded := NodeResults{
+		externalToNode: {
+			FailTime:    now.Add(-time.Second),
+			FailAmt:     externalAmount,
+			SuccessTime: now,
+			SuccessAmt:  externalAmount,
+		},
+	}
+	query(succeeded, 0.9)
+	require.Equal(t, 0.9, cached(succeeded))
+}
+
+// TestExternalEstimatorRound tests that the queries of a pathfinding round are
+// sent in batches rather than one by one, and that the round waits for all of
+// them.
+func TestExternalEstimatorRound(t *testing.T) {
+	t.Parallel()
+
+	service := NewProbabilityService()
+	estimator := newTestExternalEstimator(t, service, time.Minute)
+
+	client, err := service.Register()
+	require.NoError(t, err)
+	defer client.Close()
+
+	now := time.Now()
+	nodes := []route.Vertex{{3}, {4}, {5}, {6}, {7}}
+	var round []<-chan struct{}
+	for _, node := range nodes {
+		_, pending := estimator.NodePairProbability(
+			now, nil, externalFromNode, node, externalAmount,
+			externalCapacity,
+		)
+		round = append(round, pending)
+	}
+
+	awaited := make(chan bool)
+	go func() {
+		awaited <- estimator.AwaitEstimates(round)
+	}()
+
+	// The batch loop may take the first query before the others are
+	// queued, but the remaining queries are sent together.
+	var batches []*ProbabilityBatch
+	for queries := 0; queries < len(nodes); {
+		batch := receiveBatch(t, client)
+		batches = append(batches, batch)
+		queries += len(batch.Queries)
+	}
+	require.LessOrEqual(t, len(batches), 2)
+
+	// The round only completes once all of its queries are answered.
+	for i, batch := range batches {
+		select {
+		case <-awaited:
+			t.Fatal("round completed early")
+
+		default:
+		}
+
+		probabilities := make([]float64, len(batch.Queries))
+		for j := range probabilities {
+			probabilities[j] = 0.1 * float64(i+1)
+		}
+		require.NoError(t, client.Resolve(batch.ID, probabilities))
+	}
+	require.True(t, <-awaited)
+
+	for _, node := range nodes {
+		probability, _ := estimator.NodePairProbability(
+			now, nil, externalFromNode, node, externalAmount,
+			externalCapacity,
+		)
+		require.NotEqual(t, 0.0, probability)
+		require.LessOrEqual(t, probability, 0.2)
+	}
+}
+
+// TestExternalEstimatorSlow tests that a round falls back to the apriori
+// estimator when the service is slow, and that we stop waiting for it until it
+// answers again.
+func TestExternalEstimatorSlow(t *testing.T) {
+	t.Parallel()
+
+	service := NewProbabilityService()
+	estimator := newTestExternalEstimator(
+		t, service, 50*time.Millisecond,
+	)
+
+	client, err := service.Register()
+	require.NoError(t, err)
+	defer client.Close()
+
+	now := time.Now()
+	fallback := estimator.fallback.PairProbability(
+		now, nil, externalToNode, externalAmount, externalCapacity,
+	)
+
+	// The batch is only taken once the round timed out.
+	probability, pending := estimator.NodePairProbability(
+		now, nil, externalFromNode, externalToNode, externalAmount,
+		externalCapacity,
+	)
+	require.Equal(t, fallback, probability)
+	require.False(t, estimator.AwaitEstimates(
+		[]<-chan struct{}{pending},
+	))
+	require.True(t, estimator.slow.Load())
+
+	// While the service is slow, further queries are batched up without
+	// waiting for them.
+	otherNode := route.Vertex{3}
+	probability, pending = estimator.NodePairProbability(
+		now, nil, externalFromNode, otherNode, externalAmount,
+		externalCapacity,
+	)
+	require.Equal(t, fallback, probability)
+	require.Nil(t, pending)
+
+	first := receiveBatch(t, client)
+	require.Len(t, first.Queries, 1)
+	second := receiveBatch(t, client)
+	require.Len(t, second.Queries, 1)
+	require.Equal(t, otherNode, second.Queries[0].ToNode)
+
+	// The late answers are cached and the service is no longer
+	// considered slow.
+	require.NoError(t, client.Resolve(first.ID, []float64{0.2}))
+	require.NoError(t, client.Resolve(second.ID, []float64{0.4}))
+	require.False(t, estimator.slow.Load())
+
+	probability, _ = estimator.NodePairProbability(
+		now, nil, externalFromNode, otherNode, externalAmount,
+		externalCapacity,
+	)
+	require.Equal(t, 0.4, probability)
+}
+
+// TestProbabilityClientBatching tests that queued queries are sent in one
+// batch, that duplicate queries are only sent once and that pending queries
+// are failed when the client is closed.
+func TestProbabilityClientBatching(t *testing.T) {
+	t.Parallel()
+
+	service := NewProbabilityService()
+	client, err := service.Register()
+	require.NoError(t, err)
+
+	var answers []error
+	callback := func(_ float64, err error) {
+		answers = append(answers, err)
+	}
+
+	queries := []*ProbabilityQuery{
+		{FromNode: externalFromNode, ToNode: externalToNode},
+		{FromNode: externalFromNode, ToNode: route.Vertex{3}},
+		{FromNode: externalFromNode, ToNode: route.Vertex{4}},
+	}
+
+	// Once the first query is taken from the queue, the batch loop waits
+	// for its batch to be received.
+	require.True(t, service.query(queries[0], callback, true))
+	require.Eventually(t, func() bool {
+		client.mu.Lock()
+		defer client.mu.Unlock()
+
+		return len(client.queue) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// The queries that arrive in the meantime are sent together, while
+	// the duplicate of the first query isn't sent again.
+	for _, query := range queries {
+		require.True(t, service.query(query, callback, true))
+	}
+
+	batch := receiveBatch(t, client)
+	require.Equal(t, queries[:1], batch.Queries)
+	batch = receiveBatch(t, client)
+	require.Equal(t, queries[1:], batch.Queries)
+
+	client.Close()
+	require.Len(t, answers, 4)
+	for _, err := range answers {
+		require.ErrorIs(t, err, ErrProbabilityServiceClosed)
+	}
+
+	// Once closed, queries are no longer accepted and a new service can
+	// register.
+	require.False(t, service.query(queries[0], callback, true))
+
+	client, err = service.Register()
+	require.NoError(t, err)
+	client.Close()
+}
+
+// TestBucketAmount tests that amounts are rounded up to at most an eighth.
+func TestBucketAmount(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, lnwire.MilliLoki(15), bucketAmount(15))
+	require.Equal(t, lnwire.MilliLoki(16), bucketAmount(16))
+	require.Equal(t, lnwire.MilliLoki(18), bucketAmount(17))
+	require.Equal(t, lnwire.MilliLoki(1024), bucketAmount(1000))
+	require.Equal(t, lnwire.MilliLoki(1152), bucketAmount(1025))
+
+	for amt := lnwire.MilliLoki(1); amt < 100_000; amt += 7 {
+		bucket := bucketAmount(amt)
+		require.GreaterOrEqual(t, bucket, amt)
+		require.LessOrEqual(t, bucket-amt, amt/8)
+	}
+}
@@ -0,0 +1,181 @@
+package routing
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/htlcswitch"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	paymentsdb "github.com/flokiorg/flnd/payments/db"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/routing/shards"
+)
+
+// ProbeResult is the outcome of a probe sent with SendProbe.
+type ProbeResult struct {
+	// Reached is true if the probe made it to the final hop of the route,
+	// which means that every channel of the route was able to carry its
+	// amount.
+	Reached bool
+
+	// FailureSourceIdx is the index of the node that failed the probe,
+	// where zero is our own node. It is none if the failure couldn't be
+	// attributed to a node.
+	FailureSourceIdx fn.Option[int]
+
+	// Failure is the failure message the probe was failed with. It is nil
+	// if the failure couldn't be decoded.
+	Failure lnwire.FailureMessage
+}
+
+// SendProbe sends an HTLC with a random payment hash along the given route and
+// waits for its result. As nobody knows the preimage of the hash, the final
+// hop fails the HTLC with an unknown payment details error if it ever gets
+// there. The outcome is reported to mission control just like the outcome of
+// a payment attempt, but the probe isn't registered with the control tower,
+// so it doesn't show up in the payment history. If the context is canceled
+// before the result arrives, we stop waiting for it and return
+// ErrPaymentLifecycleExiting, while the HTLC itself stays in flight until it
+// is resolved.
+func (r *ChannelRouter) SendProbe(ctx context.Context,
+	rt *route.Route) (*ProbeResult, error) {
+
+	var hash lntypes.Hash
+	if _, err := rand.Read(hash[:]); err != nil {
+		return nil, err
+	}
+
+	// The lifecycle is only used to prepare the HTLC and to collect its
+	// result, none of which touches the control tower.
+	p := newPaymentLifecycle(
+		r, 0, hash, nil, shards.NewSimpleShardTracker(hash, nil), 0,
+		nil,
+	)
+	if err := p.amendFirstHopData(rt); err != nil {
+		return nil, err
+	}
+
+	stopWaiting := context.AfterFunc(ctx, p.stop)
+	defer stopWaiting()
+
+	sessionKey, err := generateNewSessionKey()
+	if err != nil {
+		return nil, err
+	}
+
+	attemptID, err := r.cfg.NextPaymentID()
+	if err != nil {
+		return nil, err
+	}
+
+	attempt, err := paymentsdb.NewHtlcAttempt(
+		attemptID, sessionKey, *rt, r.cfg.Clock.Now(), &hash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	onionBlob, err := attempt.OnionBlob()
+	if err != nil {
+		return nil, err
+	}
+
+	htlcAdd := &lnwire.UpdateAddHTLC{
+		Amount:        rt.FirstHopAmount.Val.Int(),
+		Expiry:        rt.TotalTimeLock,
+		PaymentHash:   hash,
+		OnionBlob:     onionBlob,
+		CustomRecords: rt.FirstHopWireCustomRecords,
+	}
+
+	log.Debugf("Sending probe %v of %v to %v", attemptID, rt.ReceiverAmt(),
+		rt.Hops[len(rt.Hops)-1].PubKeyBytes)
+
+	firstHop := lnwire.NewShortChanIDFromInt(rt.Hops[0].ChannelID)
+	err = r.cfg.Payer.SendHTLC(firstHop, attemptID, htlcAdd)
+	if err != nil {
+		return r.reportProbeFailure(p, attempt, err)
+	}
+
+	result, err := p.collectResult(attempt)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Error != nil {
+		return r.reportProbeFailure(p, attempt, result.Error)
+	}
+
+	// A settled probe is unexpected, but it proves the route all the same.
+	log.Warnf("Probe %v with hash %v was settled", attemptID, hash)
+
+	err = r.cfg.MissionControl.ReportPaymentSuccess(
+		attemptID, &attempt.Route,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProbeResult{
+		Reached: true,
+	}, nil
+}
+
+// reportProbeFailure interprets the error a probe failed with and reports it
+// to mission control. Errors that don't stem from the propagation of the probe
+// tell nothing about the route and are returned instead.
+func (r *ChannelRouter) reportProbeFailure(p *paymentLifecycle,
+	attempt *paymentsdb.HTLCAttempt, sendErr error) (*ProbeResult, error) {
+
+	rt := &attempt.Route
+	result := &ProbeResult{}
+
+	var (
+		failureSourceIdx *int
+		rtErr            htlcswitch.ClearTextError
+	)
+	switch {
+	// An unreadable failure is reported without source and message, so
+	// that mission control can penalize the route as a whole.
+	case errors.Is(sendErr, htlcswitch.ErrUnreadableFailureMessage):
+
+	case errors.As(sendErr, &rtErr):
+		idx := 0
+		var source *htlcswitch.ForwardingError
+		if errors.As(rtErr, &source) {
+			idx = source.FailureSourceIdx
+		}
+
+		result.Failure = rtErr.WireMessage()
+		err := p.handleFailureMessage(rt, idx, result.Failure)
+		if err != nil {
+			return nil, err
+		}
+
+		failureSourceIdx = &idx
+		result.FailureSourceIdx = fn.Some(idx)
+
+		// The final hop doesn't know our random hash, so it fails
+		// the probe with unknown payment details.
+		_, unknownHash := result.Failure.(*lnwire.FailIncorrectDetails)
+		result.Reached = idx == len(rt.Hops) && unknownHash
+
+	default:
+		return nil, sendErr
+	}
+
+	_, err := r.cfg.MissionControl.ReportPaymentFail(
+		attempt.AttemptID, rt, failureSourceIdx, result.Failure,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Probe %v failed (reached=%v): %v", attempt.AttemptID,
+		result.Reached, sendErr)
+
+	return result, nil
+}
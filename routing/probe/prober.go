@@ -0,0 +1,386 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+)
+
+const (
+	// DefaultInterval is the default interval in which probes are sent.
+	DefaultInterval = time.Minute
+
+	// DefaultMinAmount is the default smallest amount that is probed.
+	DefaultMinAmount chainutil.Amount = 10_000
+
+	// DefaultMaxAmount is the default largest amount that is probed.
+	DefaultMaxAmount chainutil.Amount = 1_000_000
+
+	// DefaultInFlightBudget is the default total amount that probes may
+	// lock up in our channels at the same time.
+	DefaultInFlightBudget chainutil.Amount = 5_000_000
+
+	// DefaultCentralNodes is the default number of nodes with the highest
+	// betweenness centrality that are probed.
+	DefaultCentralNodes = 10
+
+	// DefaultCentralityRefresh is the default interval in which the nodes
+	// with the highest centrality are determined anew.
+	DefaultCentralityRefresh = 24 * time.Hour
+)
+
+// Config holds the dependencies and parameters of the Prober.
+type Config struct {
+	// SelfNode is our node's public key. Probes are sent from it.
+	SelfNode route.Vertex
+
+	// Destinations are the nodes that are always probed.
+	Destinations []route.Vertex
+
+	// CentralNodes is the number of nodes with the highest betweenness
+	// centrality that are probed in addition to the destinations.
+	CentralNodes int
+
+	// CentralityRefresh is the interval in which the nodes with the
+	// highest centrality are determined anew.
+	CentralityRefresh time.Duration
+
+	// TopCentralNodes returns the given number of nodes with the highest
+	// betweenness centrality in the graph, ordered by their centrality.
+	TopCentralNodes func(ctx context.Context, n int) ([]route.Vertex,
+		error)
+
+	// FindRoute finds a route that satisfies the given request.
+	FindRoute func(req *routing.RouteRequest) (*route.Route, float64,
+		error)
+
+	// SendProbe sends a probe along the given route, waits for its
+	// outcome and reports it to mission control. It stops waiting once
+	// the context is canceled.
+	SendProbe func(ctx context.Context, rt *route.Route) (
+		*routing.ProbeResult, error)
+
+	// ProbabilitySource returns the success probability of the hops
+	// considered in path finding.
+	ProbabilitySource func(fromNode, toNode route.Vertex,
+		amt lnwire.MilliLoki, capacity chainutil.Amount) float64
+
+	// FinalCltvDelta is the final CLTV delta of the probe routes.
+	FinalCltvDelta uint16
+
+	// CltvLimit is the maximum time lock of a probe route.
+	CltvLimit uint32
+
+	// Interval is the interval in which probes are sent.
+	Interval time.Duration
+
+	// MinAmount is the smallest amount that is probed.
+	MinAmount chainutil.Amount
+
+	// MaxAmount is the largest amount that is probed.
+	MaxAmount chainutil.Amount
+
+	// InFlightBudget is the total amount, including fees, that probes may
+	// lock up in our channels at the same time.
+	InFlightBudget chainutil.Amount
+}
+
+// Prober warms up mission control before our first payments by periodically
+// sending probes to the configured destinations and to the most central nodes
+// of the network. The probes carry a random payment hash and vary in amount,
+// so their outcomes tell mission control which amounts the probed routes can
+// carry without any funds actually being moved. Probes aren't recorded as
+// payments, and they only lock up as much liquidity as the in-flight budget
+// allows.
+type Prober struct {
+	started atomic.Bool
+	stopped atomic.Bool
+
+	cfg *Config
+
+	// mu guards the fields below.
+	mu sync.Mutex
+
+	// centralNodes are the most central nodes of the graph as of the last
+	// refresh.
+	centralNodes []route.Vertex
+
+	// next is the index of the next destination that is probed.
+	next int
+
+	// inFlight is the total amount of the probes that are in flight.
+	inFlight lnwire.MilliLoki
+
+	cg *fn.ContextGuard
+}
+
+// NewProber creates a new Prober.
+func NewProber(cfg *Config) *Prober {
+	return &Prober{
+		cfg: cfg,
+		cg:  fn.NewContextGuard(),
+	}
+}
+
+// Start starts sending probes and, if central nodes are probed, determining
+// the most central nodes.
+func (p *Prober) Start() error {
+	log.Info("Prober starting...")
+
+	if p.started.Swap(true) {
+		return fmt.Errorf("prober started more than once")
+	}
+
+	ctx, _ := p.cg.Create(context.Background())
+
+	if p.cfg.CentralNodes > 0 {
+		p.cg.WgAdd(1)
+		go p.centralityLoop(ctx)
+	}
+
+	p.cg.WgAdd(1)
+	go p.probeLoop(ctx)
+
+	log.Debug("Prober started")
+
+	return nil
+}
+
+// Stop stops sending probes. As an intermediate node may hold a probe for a
+// long time, we stop waiting for the outcome of the probes that are still in
+// flight instead of waiting for them to be resolved.
+func (p *Prober) Stop() error {
+	log.Info("Prober shutting down...")
+
+	if p.stopped.Swap(true) {
+		return fmt.Errorf("prober stopped more than once")
+	}
+
+	p.cg.Quit()
+	p.cg.WgWait()
+
+	log.Debug("Prober shutdown complete")
+
+	return nil
+}
+
+// centralityLoop determines the most central nodes of the graph right away
+// and then in every refresh interval.
+func (p *Prober) centralityLoop(ctx context.Context) {
+	defer p.cg.WgDone()
+
+	ticker := time.NewTicker(p.cfg.CentralityRefresh)
+	defer ticker.Stop()
+
+	for {
+		if err := p.refreshCentralNodes(ctx); err != nil {
+			log.Errorf("Unable to determine central nodes: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+
+		case <-p.cg.Done():
+			return
+		}
+	}
+}
+
+// refreshCentralNodes replaces the central nodes that are probed with the
+// currently most central nodes of the graph.
+func (p *Prober) refreshCentralNodes(ctx context.Context) error {
+	// Our own node may be among the most central ones, so we ask for one
+	// more node than we need.
+	nodes, err := p.cfg.TopCentralNodes(ctx, p.cfg.CentralNodes+1)
+	if err != nil {
+		return err
+	}
+
+	central := make([]route.Vertex, 0, len(nodes))
+	for _, node := range nodes {
+		if node == p.cfg.SelfNode {
+			continue
+		}
+		if len(central) == p.cfg.CentralNodes {
+			break
+		}
+
+		central = append(central, node)
+	}
+
+	log.Debugf("Probing %v central nodes", len(central))
+
+	p.mu.Lock()
+	p.centralNodes = central
+	p.mu.Unlock()
+
+	return nil
+}
+
+// probeLoop sends a probe in every interval.
+func (p *Prober) probeLoop(ctx context.Context) {
+	defer p.cg.WgDone()
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.probeNext(ctx)
+
+		case <-p.cg.Done():
+			return
+		}
+	}
+}
+
+// probeNext sends a probe of a random amount to the next destination, unless
+// the probe would exceed the in-flight budget. It doesn't wait for the outcome
+// of the probe, which is reported to mission control by the router.
+func (p *Prober) probeNext(ctx context.Context) {
+	dest, ok := p.nextDestination()
+	if !ok {
+		log.Debugf("No destination to probe")
+
+		return
+	}
+
+	amt := lnwire.NewMSatFromLokis(p.randomAmount())
+	if !p.reserve(amt) {
+		log.Debugf("Skipping probe of %v to %v: in-flight budget "+
+			"exhausted", amt, dest)
+
+		return
+	}
+
+	rt, _, err := p.cfg.FindRoute(&routing.RouteRequest{
+		Source: p.cfg.SelfNode,
+		Target: dest,
+		Amount: amt,
+		Restrictions: &routing.RestrictParams{
+			ProbabilitySource: p.cfg.ProbabilitySource,
+			FeeLimit:          lnwire.MaxMilliLoki,
+			CltvLimit:         p.cfg.CltvLimit,
+		},
+		FinalExpiry: p.cfg.FinalCltvDelta,
+	})
+	if err != nil {
+		p.release(amt)
+
+		log.Debugf("No route to probe %v to %v: %v", amt, dest, err)
+
+		return
+	}
+
+	// The fees of the route are locked up as well, so we reserve them in
+	// addition to the amount.
+	fees := rt.TotalFees()
+	if !p.reserve(fees) {
+		p.release(amt)
+
+		log.Debugf("Skipping probe of %v to %v: in-flight budget "+
+			"exhausted by fees", amt, dest)
+
+		return
+	}
+
+	// The probe is only resolved once it made its way back to us, which
+	// may take a while if it is held up along the way. Hence, it is sent
+	// in the background until the prober shuts down.
+	p.cg.WgAdd(1)
+	go func() {
+		defer p.cg.WgDone()
+		defer p.release(amt + fees)
+
+		result, err := p.cfg.SendProbe(ctx, rt)
+		switch {
+		case errors.Is(err, routing.ErrPaymentLifecycleExiting):
+			log.Debugf("Stopped waiting for probe of %v to %v",
+				amt, dest)
+
+			return
+
+		case err != nil:
+			log.Warnf("Probe of %v to %v failed: %v", amt, dest,
+				err)
+
+			return
+		}
+
+		log.Debugf("Probe of %v to %v via %v hops: reached=%v, "+
+			"failure=%v", amt, dest, len(rt.Hops), result.Reached,
+			result.Failure)
+	}()
+}
+
+// nextDestination returns the next node to probe. The configured destinations
+// and the central nodes are probed in turn.
+func (p *Prober) nextDestination() (route.Vertex, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	total := len(p.cfg.Destinations) + len(p.centralNodes)
+	if total == 0 {
+		return route.Vertex{}, false
+	}
+
+	idx := p.next % total
+	p.next = idx + 1
+
+	if idx < len(p.cfg.Destinations) {
+		return p.cfg.Destinations[idx], true
+	}
+
+	return p.centralNodes[idx-len(p.cfg.Destinations)], true
+}
+
+// randomAmount returns an amount between the minimum and maximum amount. The
+// amounts are distributed logarithmically, so that every order of magnitude is
+// probed equally often.
+func (p *Prober) randomAmount() chainutil.Amount {
+	minAmt := float64(p.cfg.MinAmount)
+	maxAmt := float64(p.cfg.MaxAmount)
+	if minAmt <= 0 || maxAmt <= minAmt {
+		return p.cfg.MaxAmount
+	}
+
+	amt := minAmt * math.Pow(maxAmt/minAmt, rand.Float64())
+
+	return chainutil.Amount(math.Round(amt))
+}
+
+// reserve adds the amount to the in-flight amount if it stays within the
+// budget. It returns false otherwise.
+func (p *Prober) reserve(amt lnwire.MilliLoki) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	budget := lnwire.NewMSatFromLokis(p.cfg.InFlightBudget)
+	if p.inFlight+amt > budget {
+		return false
+	}
+
+	p.inFlight += amt
+
+	return true
+}
+
+// release removes the amount of a resolved probe from the in-flight amount.
+func (p *Prober) release(amt lnwire.MilliLoki) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.inFlight -= amt
+}
@@ -0,0 +1,248 @@
+package probe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testSelf = route.Vertex{1}
+	testDest = route.Vertex{2}
+
+	testCentralA = route.Vertex{3}
+	testCentralB = route.Vertex{4}
+)
+
+const (
+	testAmount = chainutil.Amount(100_000)
+	testFee    = lnwire.MilliLoki(1_000)
+)
+
+// testProbe is a probe the prober sent, which is resolved by sending its
+// result.
+type testProbe struct {
+	route  *route.Route
+	result chan *routing.ProbeResult
+}
+
+// testHarness holds a prober with mocked dependencies.
+type testHarness struct {
+	prober *Prober
+
+	// probes receives the probes the prober sent.
+	probes chan *testProbe
+}
+
+// newTestHarness creates a prober that probes the given destinations with
+// the test amount. Two probes and their fees of one loki each fit into its
+// in-flight budget.
+func newTestHarness(t *testing.T, destinations ...route.Vertex) *testHarness {
+	h := &testHarness{
+		probes: make(chan *testProbe, 10),
+	}
+
+	h.prober = NewProber(&Config{
+		SelfNode:     testSelf,
+		Destinations: destinations,
+		CentralNodes: 2,
+		TopCentralNodes: func(_ context.Context,
+			n int) ([]route.Vertex, error) {
+
+			nodes := []route.Vertex{
+				testCentralA, testSelf, testCentralB, testDest,
+			}
+
+			return nodes[:n], nil
+		},
+		FindRoute: func(req *routing.RouteRequest) (*route.Route,
+			float64, error) {
+
+			require.Equal(t, testSelf, req.Source)
+			require.Equal(t, lnwire.MaxMilliLoki,
+				req.Restrictions.FeeLimit)
+
+			return &route.Route{
+				TotalAmount: req.Amount + testFee,
+				Hops: []*route.Hop{{
+					PubKeyBytes:  req.Target,
+					AmtToForward: req.Amount,
+				}},
+			}, 1, nil
+		},
+		SendProbe: func(ctx context.Context,
+			rt *route.Route) (*routing.ProbeResult, error) {
+
+			probe := &testProbe{
+				route:  rt,
+				result: make(chan *routing.ProbeResult),
+			}
+			h.probes <- probe
+
+			select {
+			case result := <-probe.result:
+				return result, nil
+
+			case <-ctx.Done():
+				return nil, routing.ErrPaymentLifecycleExiting
+			}
+		},
+		CentralityRefresh: time.Hour,
+		Interval:          time.Hour,
+		MinAmount:         testAmount,
+		MaxAmount:         testAmount,
+		InFlightBudget:    2*testAmount + 2,
+	})
+
+	return h
+}
+
+// receiveProbe waits for the next probe the prober sends.
+func (h *testHarness) receiveProbe(t *testing.T) *testProbe {
+	select {
+	case probe := <-h.probes:
+		return probe
+
+	case <-time.After(time.Second):
+		t.Fatal("no probe sent")
+		return nil
+	}
+}
+
+// assertNoProbe asserts that the prober doesn't send a probe.
+func (h *testHarness) assertNoProbe(t *testing.T) {
+	select {
+	case <-h.probes:
+		t.Fatal("unexpected probe")
+
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// assertInFlight asserts that the in-flight amount of the prober settles at
+// the expected amount.
+func (h *testHarness) assertInFlight(t *testing.T,
+	expected lnwire.MilliLoki) {
+
+	require.Eventually(t, func() bool {
+		h.prober.mu.Lock()
+		defer h.prober.mu.Unlock()
+
+		return h.prober.inFlight == expected
+	}, time.Second, 10*time.Millisecond)
+}
+
+// destination returns the node the probe is sent to.
+func (p *testProbe) destination() route.Vertex {
+	return p.route.Hops[len(p.route.Hops)-1].PubKeyBytes
+}
+
+// TestProbeDestinations tests that the configured destinations and the most
+// central nodes other than our own are probed in turn.
+func TestProbeDestinations(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t, testDest)
+
+	// Until the central nodes are known, only the configured destination
+	// is probed.
+	h.prober.probeNext(t.Context())
+	probe := h.receiveProbe(t)
+	require.Equal(t, testDest, probe.destination())
+	probe.result <- &routing.ProbeResult{Reached: true}
+	h.assertInFlight(t, 0)
+
+	require.NoError(t, h.prober.refreshCentralNodes(context.Background()))
+	require.Equal(
+		t, []route.Vertex{testCentralA, testCentralB},
+		h.prober.centralNodes,
+	)
+
+	expected := []route.Vertex{
+		testCentralA, testCentralB, testDest, testCentralA,
+	}
+	for _, dest := range expected {
+		h.prober.probeNext(t.Context())
+		probe := h.receiveProbe(t)
+		require.Equal(t, dest, probe.destination())
+		probe.result <- &routing.ProbeResult{Reached: true}
+		h.assertInFlight(t, 0)
+	}
+
+	// Without any destination, nothing is probed.
+	h = newTestHarness(t)
+	h.prober.probeNext(t.Context())
+	h.assertNoProbe(t)
+}
+
+// TestProbeInFlightBudget tests that probes are only sent while their amounts
+// and fees fit into the in-flight budget.
+func TestProbeInFlightBudget(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t, testDest)
+
+	// Two probes fit into the budget.
+	h.prober.probeNext(t.Context())
+	first := h.receiveProbe(t)
+	h.prober.probeNext(t.Context())
+	second := h.receiveProbe(t)
+
+	probeAmt := lnwire.NewMSatFromLokis(testAmount) + testFee
+	h.assertInFlight(t, 2*probeAmt)
+
+	// The budget is used up, so the third probe isn't sent until the first
+	// one is resolved.
+	h.prober.probeNext(t.Context())
+	h.assertNoProbe(t)
+
+	first.result <- &routing.ProbeResult{}
+	h.assertInFlight(t, probeAmt)
+
+	h.prober.probeNext(t.Context())
+	third := h.receiveProbe(t)
+
+	second.result <- &routing.ProbeResult{}
+	third.result <- &routing.ProbeResult{}
+	h.assertInFlight(t, 0)
+}
+
+// TestProbeStop tests that stopping the prober stops waiting for the probes
+// in flight and releases their amounts.
+func TestProbeStop(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t, testDest)
+	h.prober.cfg.Interval = 10 * time.Millisecond
+	require.NoError(t, h.prober.Start())
+
+	// The probe is never resolved, yet the prober stops.
+	h.receiveProbe(t)
+	require.NoError(t, h.prober.Stop())
+
+	h.prober.mu.Lock()
+	require.Zero(t, h.prober.inFlight)
+	h.prober.mu.Unlock()
+}
+
+// TestRandomAmount tests that the probed amounts stay within the configured
+// range.
+func TestRandomAmount(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	h.prober.cfg.MinAmount = 1_000
+	h.prober.cfg.MaxAmount = 1_000_000
+
+	for i := 0; i < 1000; i++ {
+		amt := h.prober.randomAmount()
+		require.GreaterOrEqual(t, amt, h.prober.cfg.MinAmount)
+		require.LessOrEqual(t, amt, h.prober.cfg.MaxAmount)
+	}
+}
@@ -0,0 +1,921 @@
+package rebalance
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/flokiorg/flnd/clock"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/invoices"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	paymentsdb "github.com/flokiorg/flnd/payments/db"
+	"github.com/flokiorg/flnd/record"
+	"github.com/flokiorg/flnd/routing"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/subscribe"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+)
+
+const (
+	// DefaultInterval is the default interval in which the targets are
+	// checked when automatic rebalancing is active.
+	DefaultInterval = 10 * time.Minute
+
+	// DefaultMaxFee is the default maximum fee of a single rebalance.
+	DefaultMaxFee chainutil.Amount = 1_000
+
+	// DefaultMaxFeeRate is the default maximum fee of a single rebalance
+	// in parts per million of the rebalanced amount.
+	DefaultMaxFeeRate = 500
+
+	// DefaultDailyBudget is the default total fee that may be spent on
+	// rebalances within a day.
+	DefaultDailyBudget chainutil.Amount = 10_000
+
+	// DefaultMinAmount is the default smallest amount that is rebalanced
+	// automatically.
+	DefaultMinAmount chainutil.Amount = 10_000
+
+	// DefaultMaxAmount is the default largest amount that is rebalanced
+	// automatically at once.
+	DefaultMaxAmount chainutil.Amount = 1_000_000
+
+	// DefaultTolerance is the default fraction of the capacity by which the
+	// local balance of a channel may deviate from its target before it is
+	// rebalanced automatically.
+	DefaultTolerance = 0.1
+
+	// DefaultMaxAttempts is the default number of routes that are tried
+	// for a single rebalance.
+	DefaultMaxAttempts = 3
+
+	// budgetPeriod is the period over which the fees of our rebalances
+	// are limited by the daily budget.
+	budgetPeriod = 24 * time.Hour
+
+	// invoiceExpiry is the expiry of the invoices we pay to ourselves.
+	invoiceExpiry = time.Hour
+
+	// invoiceMemo is the memo of the invoices we pay to ourselves.
+	invoiceMemo = "rebalance"
+)
+
+var (
+	// ErrBudgetExhausted is returned when the fees of the rebalances made
+	// within the last day used up the daily budget.
+	ErrBudgetExhausted = errors.New("daily rebalance fee budget exhausted")
+
+	// ErrInvalidTarget is returned for a target that doesn't set exactly
+	// one valid local ratio or balance.
+	ErrInvalidTarget = errors.New("target must set either a local ratio " +
+		"between 0 and 1 or a local balance")
+
+	// ErrUnknownChannel is returned when a rebalance or target refers to a
+	// channel that is not one of our open channels.
+	ErrUnknownChannel = errors.New("unknown channel")
+
+	// ErrSameChannel is returned when a rebalance would leave and return
+	// through the same channel.
+	ErrSameChannel = errors.New("rebalance must leave and return " +
+		"through different channels")
+
+	// ErrZeroAmount is returned for a rebalance without an amount.
+	ErrZeroAmount = errors.New("rebalance amount must be positive")
+)
+
+// ChannelBalance describes the balance of one of our channels.
+type ChannelBalance struct {
+	// ChanID is the short channel ID of the channel.
+	ChanID lnwire.ShortChannelID
+
+	// Capacity is the capacity of the channel.
+	Capacity chainutil.Amount
+
+	// LocalBalance is our balance in the channel.
+	LocalBalance chainutil.Amount
+
+	// Active is true if the channel is able to forward htlcs.
+	Active bool
+}
+
+// Target is the local balance we'd like one of our channels to have. Exactly
+// one of the local ratio and the local balance is set.
+type Target struct {
+	// ChanID is the short channel ID of the channel.
+	ChanID lnwire.ShortChannelID
+
+	// LocalRatio is the fraction of the capacity of the channel we'd like
+	// to be on our side.
+	LocalRatio fn.Option[float64]
+
+	// LocalBalance is the absolute balance we'd like to have in the
+	// channel.
+	LocalBalance fn.Option[chainutil.Amount]
+}
+
+// Validate checks that the target sets exactly one valid goal.
+func (t *Target) Validate() error {
+	if t.LocalRatio.IsSome() == t.LocalBalance.IsSome() {
+		return ErrInvalidTarget
+	}
+
+	ratio := t.LocalRatio.UnwrapOr(0)
+	if ratio < 0 || ratio > 1 {
+		return ErrInvalidTarget
+	}
+
+	return nil
+}
+
+// desired returns the local balance the target asks for in a channel of the
+// given capacity.
+func (t *Target) desired(capacity chainutil.Amount) chainutil.Amount {
+	return t.LocalBalance.UnwrapOrFunc(func() chainutil.Amount {
+		return chainutil.Amount(
+			t.LocalRatio.UnwrapOr(0) * float64(capacity),
+		)
+	})
+}
+
+// Request describes a rebalance to make.
+type Request struct {
+	// OutgoingChannels are the channels the rebalance may leave through.
+	// If empty, any of our active channels other than the incoming channel
+	// may be used.
+	OutgoingChannels []lnwire.ShortChannelID
+
+	// IncomingChannel is the channel the rebalance returns through, which
+	// increases our balance in it.
+	IncomingChannel lnwire.ShortChannelID
+
+	// Amount is the amount to move into the incoming channel.
+	Amount chainutil.Amount
+
+	// MaxFee is the maximum fee to pay for the rebalance. If zero, the
+	// configured maximum fee and fee rate apply. The daily budget always
+	// applies.
+	MaxFee chainutil.Amount
+}
+
+// Status is the outcome of a rebalance.
+type Status uint8
+
+const (
+	// StatusSucceeded is the status of a rebalance that moved the full
+	// amount.
+	StatusSucceeded Status = iota
+
+	// StatusFailed is the status of a rebalance that didn't move any
+	// funds.
+	StatusFailed
+)
+
+// String returns a human-readable name of the status.
+func (s Status) String() string {
+	switch s {
+	case StatusSucceeded:
+		return "succeeded"
+
+	case StatusFailed:
+		return "failed"
+
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(s))
+	}
+}
+
+// Result is the record of a rebalance we made. It is also sent to event
+// subscribers once the rebalance is finished.
+type Result struct {
+	// ID is the sequence number of the result in the store.
+	ID uint64
+
+	// Timestamp is the time the rebalance finished.
+	Timestamp time.Time
+
+	// OutgoingChannel is the channel the last route we tried left
+	// through. It's zero if no route was found.
+	OutgoingChannel lnwire.ShortChannelID
+
+	// IncomingChannel is the channel the rebalance returned through.
+	IncomingChannel lnwire.ShortChannelID
+
+	// Amount is the amount that was rebalanced.
+	Amount chainutil.Amount
+
+	// Fee is the fee paid for the rebalance. It's zero for failed
+	// rebalances.
+	Fee lnwire.MilliLoki
+
+	// Status is the outcome of the rebalance.
+	Status Status
+
+	// Attempts is the number of routes that were tried.
+	Attempts uint32
+
+	// FailureReason describes why a failed rebalance failed.
+	FailureReason string
+
+	// PaymentHash is the hash of the payment to ourselves.
+	PaymentHash lntypes.Hash
+
+	// Automatic is true if the rebalance was made to reach the targets.
+	Automatic bool
+}
+
+// StartedEvent is sent to event subscribers when a rebalance starts.
+type StartedEvent struct {
+	// PaymentHash is the hash of the payment to ourselves.
+	PaymentHash lntypes.Hash
+
+	// Request is the rebalance that was started.
+	Request *Request
+
+	// MaxFee is the maximum fee the rebalance may cost, including the
+	// limit of the daily budget.
+	MaxFee lnwire.MilliLoki
+
+	// Automatic is true if the rebalance is made to reach the targets.
+	Automatic bool
+}
+
+// AttemptFailedEvent is sent to event subscribers when a route that was tried
+// for a rebalance failed.
+type AttemptFailedEvent struct {
+	// PaymentHash is the hash of the payment to ourselves.
+	PaymentHash lntypes.Hash
+
+	// Attempt is the number of the attempt, starting at one.
+	Attempt uint32
+
+	// OutgoingChannel is the channel the route left through.
+	OutgoingChannel lnwire.ShortChannelID
+
+	// FailureReason describes why the attempt failed.
+	FailureReason string
+}
+
+// Config holds the dependencies and parameters of the rebalance Manager.
+type Config struct {
+	// SelfNode is our node's public key. Rebalances are payments from and
+	// to it.
+	SelfNode route.Vertex
+
+	// Store persists the targets and the results of our rebalances.
+	Store Store
+
+	// ListChannels returns the balances of our open channels.
+	ListChannels func() ([]ChannelBalance, error)
+
+	// FindRoute finds a route that satisfies the given request.
+	FindRoute func(req *routing.RouteRequest) (*route.Route, float64,
+		error)
+
+	// SendToRoute pays along the given route and waits for the result.
+	SendToRoute func(htlcHash lntypes.Hash, rt *route.Route,
+		firstHopCustomRecords lnwire.CustomRecords) (
+		*paymentsdb.HTLCAttempt, error)
+
+	// ProbabilitySource returns the success probability of the hops
+	// considered in path finding.
+	ProbabilitySource func(fromNode, toNode route.Vertex,
+		amt lnwire.MilliLoki, capacity chainutil.Amount) float64
+
+	// AddInvoice adds an invoice to the invoice registry.
+	AddInvoice func(ctx context.Context, invoice *invoices.Invoice,
+		paymentHash lntypes.Hash) (uint64, error)
+
+	// CancelInvoice cancels the invoice of a failed rebalance.
+	CancelInvoice func(ctx context.Context, payHash lntypes.Hash) error
+
+	// FinalCltvDelta is the final CLTV delta of the invoices we pay to
+	// ourselves.
+	FinalCltvDelta uint16
+
+	// CltvLimit is the maximum time lock of a rebalance route.
+	CltvLimit uint32
+
+	// Interval is the interval in which the targets are checked. If zero,
+	// rebalances are only made on request.
+	Interval time.Duration
+
+	// MaxFee is the maximum fee of a single rebalance.
+	MaxFee chainutil.Amount
+
+	// MaxFeeRate is the maximum fee of a single rebalance in parts per
+	// million of the rebalanced amount.
+	MaxFeeRate uint64
+
+	// DailyBudget is the total fee that may be spent on rebalances within
+	// a day.
+	DailyBudget chainutil.Amount
+
+	// MinAmount is the smallest amount that is rebalanced automatically.
+	MinAmount chainutil.Amount
+
+	// MaxAmount is the largest amount that is rebalanced automatically at
+	// once.
+	MaxAmount chainutil.Amount
+
+	// Tolerance is the fraction of the capacity by which the local balance
+	// of a channel may deviate from its target before it is rebalanced
+	// automatically.
+	Tolerance float64
+
+	// MaxAttempts is the number of routes that are tried for a single
+	// rebalance.
+	MaxAttempts uint32
+
+	// Clock is the time source used for the budget and the results.
+	Clock clock.Clock
+}
+
+// Manager moves liquidity between our channels by paying ourselves along
+// circular routes, which leave through a channel with surplus local balance
+// and return through a channel that lacks it. Rebalances are made on request,
+// or automatically to move the channels towards their targets. The fees are
+// capped per rebalance and per day.
+type Manager struct {
+	started atomic.Bool
+	stopped atomic.Bool
+
+	cfg *Config
+
+	// rebalanceMtx makes sure rebalances are made one at a time, so that
+	// concurrent rebalances can't overspend the daily budget.
+	rebalanceMtx sync.Mutex
+
+	// ntfnServer notifies subscribers of the progress of rebalances.
+	ntfnServer *subscribe.Server
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewManager creates a new rebalance Manager.
+func NewManager(cfg *Config) *Manager {
+	return &Manager{
+		cfg:        cfg,
+		ntfnServer: subscribe.NewServer(),
+		quit:       make(chan struct{}),
+	}
+}
+
+// Start starts the manager and, if an interval is configured, the automatic
+// rebalancing towards the targets.
+func (m *Manager) Start() error {
+	log.Info("Rebalance manager starting...")
+
+	if m.started.Swap(true) {
+		return fmt.Errorf("rebalance manager started more than once")
+	}
+
+	if err := m.ntfnServer.Start(); err != nil {
+		return err
+	}
+
+	if m.cfg.Interval > 0 {
+		m.wg.Add(1)
+		go m.rebalanceTargetsLoop()
+	}
+
+	log.Debug("Rebalance manager started")
+
+	return nil
+}
+
+// Stop stops the manager and waits for a running rebalance to finish.
+func (m *Manager) Stop() error {
+	log.Info("Rebalance manager shutting down...")
+
+	if m.stopped.Swap(true) {
+		return fmt.Errorf("rebalance manager stopped more than once")
+	}
+
+	close(m.quit)
+	m.wg.Wait()
+
+	if err := m.ntfnServer.Stop(); err != nil {
+		return err
+	}
+
+	log.Debug("Rebalance manager shutdown complete")
+
+	return nil
+}
+
+// SubscribeEvents returns a client that receives a StartedEvent and any
+// number of AttemptFailedEvents for every rebalance, followed by its Result.
+func (m *Manager) SubscribeEvents() (*subscribe.Client, error) {
+	return m.ntfnServer.Subscribe()
+}
+
+// Targets returns the targets of our channels.
+func (m *Manager) Targets() ([]*Target, error) {
+	return m.cfg.Store.FetchTargets()
+}
+
+// SetTargets stores the given targets and removes the targets of the given
+// channels. If replace is true, all other targets are removed as well. The
+// targets of all channels are returned.
+func (m *Manager) SetTargets(targets []*Target,
+	remove []lnwire.ShortChannelID, replace bool) ([]*Target, error) {
+
+	channels, err := m.channelMap()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, target := range targets {
+		if err := target.Validate(); err != nil {
+			return nil, fmt.Errorf("channel %v: %w", target.ChanID,
+				err)
+		}
+
+		channel, ok := channels[target.ChanID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %v", ErrUnknownChannel,
+				target.ChanID)
+		}
+
+		balance := target.LocalBalance.UnwrapOr(0)
+		if balance > channel.Capacity {
+			return nil, fmt.Errorf("channel %v: local balance %v "+
+				"exceeds capacity %v", target.ChanID, balance,
+				channel.Capacity)
+		}
+	}
+
+	err = m.cfg.Store.UpdateTargets(targets, remove, replace)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.cfg.Store.FetchTargets()
+}
+
+// Results returns the results of the rebalances made at or after the given
+// time.
+func (m *Manager) Results(since time.Time) ([]*Result, error) {
+	return m.cfg.Store.FetchResults(since)
+}
+
+// SpentFees returns the fees spent on the rebalances made within the last
+// day, which count against the daily budget.
+func (m *Manager) SpentFees() (lnwire.MilliLoki, error) {
+	since := m.cfg.Clock.Now().Add(-budgetPeriod)
+	results, err := m.cfg.Store.FetchResults(since)
+	if err != nil {
+		return 0, err
+	}
+
+	var spent lnwire.MilliLoki
+	for _, result := range results {
+		spent += result.Fee
+	}
+
+	return spent, nil
+}
+
+// DailyBudget returns the total fee that may be spent on rebalances within a
+// day.
+func (m *Manager) DailyBudget() chainutil.Amount {
+	return m.cfg.DailyBudget
+}
+
+// Rebalance makes the requested rebalance and returns its result. A rebalance
+// for which no route succeeded is not an error, but recorded as a failed
+// result.
+func (m *Manager) Rebalance(ctx context.Context,
+	req *Request) (*Result, error) {
+
+	return m.rebalance(ctx, req, false)
+}
+
+// channelMap returns the balances of our open channels keyed by their short
+// channel ID.
+func (m *Manager) channelMap() (map[lnwire.ShortChannelID]ChannelBalance,
+	error) {
+
+	channels, err := m.cfg.ListChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	channelMap := make(map[lnwire.ShortChannelID]ChannelBalance)
+	for _, channel := range channels {
+		channelMap[channel.ChanID] = channel
+	}
+
+	return channelMap, nil
+}
+
+// maxFee returns the maximum fee of the given rebalance, which is limited by
+// the remaining daily budget.
+func (m *Manager) maxFee(req *Request) (lnwire.MilliLoki, error) {
+	maxFee := lnwire.NewMSatFromLokis(req.MaxFee)
+	if req.MaxFee == 0 {
+		maxFee = lnwire.NewMSatFromLokis(m.cfg.MaxFee)
+
+		rateLimit := lnwire.NewMSatFromLokis(req.Amount) *
+			lnwire.MilliLoki(m.cfg.MaxFeeRate) / 1_000_000
+		if rateLimit < maxFee {
+			maxFee = rateLimit
+		}
+	}
+
+	spent, err := m.SpentFees()
+	if err != nil {
+		return 0, err
+	}
+
+	budget := lnwire.NewMSatFromLokis(m.cfg.DailyBudget)
+	if spent >= budget {
+		return 0, ErrBudgetExhausted
+	}
+
+	if remaining := budget - spent; remaining < maxFee {
+		maxFee = remaining
+	}
+
+	return maxFee, nil
+}
+
+// outgoingChannels returns the channels the given rebalance may leave
+// through.
+func (m *Manager) outgoingChannels(req *Request,
+	channels map[lnwire.ShortChannelID]ChannelBalance) ([]uint64, error) {
+
+	var outgoing []uint64
+	if len(req.OutgoingChannels) > 0 {
+		for _, chanID := range req.OutgoingChannels {
+			if chanID == req.IncomingChannel {
+				return nil, ErrSameChannel
+			}
+
+			if _, ok := channels[chanID]; !ok {
+				return nil, fmt.Errorf("%w: %v",
+					ErrUnknownChannel, chanID)
+			}
+
+			outgoing = append(outgoing, chanID.ToUint64())
+		}
+
+		return outgoing, nil
+	}
+
+	for chanID, channel := range channels {
+		if chanID == req.IncomingChannel || !channel.Active {
+			continue
+		}
+
+		outgoing = append(outgoing, chanID.ToUint64())
+	}
+
+	if len(outgoing) == 0 {
+		return nil, errors.New("no active channel to leave through")
+	}
+
+	return outgoing, nil
+}
+
+// rebalance makes the given rebalance by paying an invoice to ourselves along
+// circular routes, trying up to the configured number of routes.
+func (m *Manager) rebalance(ctx context.Context, req *Request,
+	automatic bool) (*Result, error) {
+
+	if req.Amount <= 0 {
+		return nil, ErrZeroAmount
+	}
+
+	channels, err := m.channelMap()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := channels[req.IncomingChannel]; !ok {
+		return nil, fmt.Errorf("%w: %v", ErrUnknownChannel,
+			req.IncomingChannel)
+	}
+
+	outgoing, err := m.outgoingChannels(req, channels)
+	if err != nil {
+		return nil, err
+	}
+
+	m.rebalanceMtx.Lock()
+	defer m.rebalanceMtx.Unlock()
+
+	maxFee, err := m.maxFee(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var preimage lntypes.Preimage
+	if _, err := rand.Read(preimage[:]); err != nil {
+		return nil, err
+	}
+	paymentHash := preimage.Hash()
+
+	var payAddr [32]byte
+	if _, err := rand.Read(payAddr[:]); err != nil {
+		return nil, err
+	}
+
+	amt := lnwire.NewMSatFromLokis(req.Amount)
+	features := lnwire.NewFeatureVector(
+		lnwire.NewRawFeatureVector(
+			lnwire.TLVOnionPayloadRequired,
+			lnwire.PaymentAddrRequired,
+		), lnwire.Features,
+	)
+	_, err = m.cfg.AddInvoice(ctx, &invoices.Invoice{
+		Memo:         []byte(invoiceMemo),
+		CreationDate: m.cfg.Clock.Now(),
+		Terms: invoices.ContractTerm{
+			FinalCltvDelta:  int32(m.cfg.FinalCltvDelta),
+			Expiry:          invoiceExpiry,
+			PaymentPreimage: &preimage,
+			Value:           amt,
+			PaymentAddr:     payAddr,
+			Features:        features,
+		},
+	}, paymentHash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to add invoice: %w", err)
+	}
+
+	log.Infof("Rebalancing %v into channel %v with max fee %v (hash=%v)",
+		req.Amount, req.IncomingChannel, maxFee, paymentHash)
+
+	m.notify(&StartedEvent{
+		PaymentHash: paymentHash,
+		Request:     req,
+		MaxFee:      maxFee,
+		Automatic:   automatic,
+	})
+
+	result := &Result{
+		IncomingChannel: req.IncomingChannel,
+		Amount:          req.Amount,
+		Status:          StatusFailed,
+		PaymentHash:     paymentHash,
+		Automatic:       automatic,
+	}
+
+	routeReq := &routing.RouteRequest{
+		Source: m.cfg.SelfNode,
+		Target: m.cfg.SelfNode,
+		Amount: amt,
+		Restrictions: &routing.RestrictParams{
+			ProbabilitySource:  m.cfg.ProbabilitySource,
+			FeeLimit:           maxFee,
+			OutgoingChannelIDs: outgoing,
+			IncomingChannelIDs: []uint64{
+				req.IncomingChannel.ToUint64(),
+			},
+			CltvLimit: m.cfg.CltvLimit,
+		},
+		FinalExpiry: m.cfg.FinalCltvDelta,
+	}
+
+	for result.Attempts < m.cfg.MaxAttempts {
+		if reason := m.interrupted(ctx); reason != "" {
+			result.FailureReason = reason
+
+			break
+		}
+
+		// Mission control learns from every failed attempt, so a new
+		// route is found for each of them.
+		rt, _, err := m.cfg.FindRoute(routeReq)
+		if err != nil {
+			result.FailureReason = fmt.Sprintf("unable to find "+
+				"route: %v", err)
+
+			break
+		}
+
+		finalHop := rt.Hops[len(rt.Hops)-1]
+		finalHop.MPP = record.NewMPP(amt, payAddr)
+
+		result.Attempts++
+		result.OutgoingChannel = lnwire.NewShortChanIDFromInt(
+			rt.Hops[0].ChannelID,
+		)
+
+		attempt, err := m.cfg.SendToRoute(paymentHash, rt, nil)
+		if attempt != nil && attempt.Settle != nil {
+			result.Status = StatusSucceeded
+			result.Fee = rt.TotalFees()
+
+			break
+		}
+
+		reason := "htlc failed"
+		if err != nil {
+			reason = err.Error()
+		}
+		result.FailureReason = reason
+
+		log.Debugf("Rebalance attempt %v via channel %v failed "+
+			"(hash=%v): %v", result.Attempts,
+			result.OutgoingChannel, paymentHash, reason)
+
+		m.notify(&AttemptFailedEvent{
+			PaymentHash:     paymentHash,
+			Attempt:         result.Attempts,
+			OutgoingChannel: result.OutgoingChannel,
+			FailureReason:   reason,
+		})
+	}
+
+	if result.Status == StatusSucceeded {
+		result.FailureReason = ""
+
+		log.Infof("Rebalanced %v from channel %v into channel %v for "+
+			"fee %v (hash=%v)", req.Amount, result.OutgoingChannel,
+			req.IncomingChannel, result.Fee, paymentHash)
+	} else {
+		log.Infof("Rebalance into channel %v failed after %v "+
+			"attempts (hash=%v): %v", req.IncomingChannel,
+			result.Attempts, paymentHash, result.FailureReason)
+
+		err := m.cfg.CancelInvoice(context.Background(), paymentHash)
+		if err != nil {
+			log.Warnf("Unable to cancel rebalance invoice %v: %v",
+				paymentHash, err)
+		}
+	}
+
+	result.Timestamp = m.cfg.Clock.Now()
+	if err := m.cfg.Store.AddResult(result); err != nil {
+		return nil, err
+	}
+
+	m.notify(result)
+
+	return result, nil
+}
+
+// interrupted returns why a rebalance needs to stop before its next attempt,
+// or an empty string if it may go on.
+func (m *Manager) interrupted(ctx context.Context) string {
+	select {
+	case <-ctx.Done():
+		return ctx.Err().Error()
+
+	case <-m.quit:
+		return "rebalance manager shutting down"
+
+	default:
+		return ""
+	}
+}
+
+// notify sends the event to all subscribers.
+func (m *Manager) notify(event interface{}) {
+	if err := m.ntfnServer.SendUpdate(event); err != nil {
+		log.Warnf("Unable to send rebalance event: %v", err)
+	}
+}
+
+// rebalanceTargetsLoop rebalances our channels towards their targets in the
+// configured interval.
+//
+// NOTE: This MUST be run as a goroutine.
+func (m *Manager) rebalanceTargetsLoop() {
+	defer m.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := m.rebalanceTargets(ctx)
+			if err != nil {
+				log.Errorf("Unable to rebalance towards "+
+					"targets: %v", err)
+			}
+
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// imbalance is the amount by which the local balance of a channel deviates
+// from its target.
+type imbalance struct {
+	chanID lnwire.ShortChannelID
+	amount chainutil.Amount
+}
+
+// rebalanceTargets makes one rebalance into each channel that lacks local
+// balance, leaving through the channels that have a surplus of it. Only
+// channels with a target take part, and only deviations beyond the tolerance
+// are rebalanced.
+func (m *Manager) rebalanceTargets(ctx context.Context) error {
+	targets, err := m.cfg.Store.FetchTargets()
+	if err != nil {
+		return err
+	}
+
+	channels, err := m.channelMap()
+	if err != nil {
+		return err
+	}
+
+	var deficits, surpluses []*imbalance
+	for _, target := range targets {
+		channel, ok := channels[target.ChanID]
+		if !ok || !channel.Active {
+			continue
+		}
+
+		tolerance := chainutil.Amount(
+			m.cfg.Tolerance * float64(channel.Capacity),
+		)
+		delta := target.desired(channel.Capacity) -
+			channel.LocalBalance
+
+		switch {
+		case delta > tolerance:
+			deficits = append(deficits, &imbalance{
+				chanID: target.ChanID,
+				amount: delta,
+			})
+
+		case -delta > tolerance:
+			surpluses = append(surpluses, &imbalance{
+				chanID: target.ChanID,
+				amount: -delta,
+			})
+		}
+	}
+
+	// Rebalance the largest deficits first, as long as the budget lasts.
+	sort.Slice(deficits, func(i, j int) bool {
+		return deficits[i].amount > deficits[j].amount
+	})
+
+	for _, deficit := range deficits {
+		amt := min(deficit.amount, m.cfg.MaxAmount)
+
+		var largest chainutil.Amount
+		for _, surplus := range surpluses {
+			largest = max(largest, surplus.amount)
+		}
+		amt = min(amt, largest)
+
+		if amt < m.cfg.MinAmount {
+			continue
+		}
+
+		var outgoing []lnwire.ShortChannelID
+		for _, surplus := range surpluses {
+			if surplus.amount >= amt {
+				outgoing = append(outgoing, surplus.chanID)
+			}
+		}
+
+		result, err := m.rebalance(ctx, &Request{
+			OutgoingChannels: outgoing,
+			IncomingChannel:  deficit.chanID,
+			Amount:           amt,
+		}, true)
+		switch {
+		case errors.Is(err, ErrBudgetExhausted):
+			log.Infof("Daily rebalance budget of %v exhausted",
+				m.cfg.DailyBudget)
+
+			return nil
+
+		case err != nil:
+			return err
+		}
+
+		if result.Status != StatusSucceeded {
+			continue
+		}
+
+		for _, surplus := range surpluses {
+			if surplus.chanID == result.OutgoingChannel {
+				surplus.amount -= amt
+			}
+		}
+	}
+
+	return nil
+}
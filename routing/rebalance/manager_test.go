@@ -0,0 +1,406 @@
+package rebalance
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/clock"
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/invoices"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	paymentsdb "github.com/flokiorg/flnd/payments/db"
+	"github.com/flokiorg/flnd/routing"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/subscribe"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testSelf = route.Vertex{1}
+
+	testStart = time.Unix(1_700_000_000, 0)
+
+	testChanA = lnwire.NewShortChanIDFromInt(1)
+	testChanB = lnwire.NewShortChanIDFromInt(2)
+	testChanC = lnwire.NewShortChanIDFromInt(3)
+)
+
+// testHarness holds a rebalance manager with mocked dependencies.
+type testHarness struct {
+	mgr   *Manager
+	clock *clock.TestClock
+
+	channels []ChannelBalance
+
+	// routeReqs records the route requests made by the manager.
+	routeReqs []*routing.RouteRequest
+
+	// routeFee is the fee of the routes returned to the manager.
+	routeFee lnwire.MilliLoki
+
+	// sendResults are the results of the payment attempts, in order. An
+	// attempt without a result fails.
+	sendResults []bool
+
+	// routes records the routes the manager paid along.
+	routes []*route.Route
+
+	// invoices records the invoices the manager added.
+	invoices map[lntypes.Hash]*invoices.Invoice
+
+	// canceled records the invoices the manager canceled.
+	canceled []lntypes.Hash
+}
+
+// newTestHarness creates a manager with three channels of 1M loki each, of
+// which we own 100k, 500k and 900k.
+func newTestHarness(t *testing.T) *testHarness {
+	h := &testHarness{
+		clock:    clock.NewTestClock(testStart),
+		routeFee: 100_000,
+		channels: []ChannelBalance{{
+			ChanID:       testChanA,
+			Capacity:     1_000_000,
+			LocalBalance: 100_000,
+			Active:       true,
+		}, {
+			ChanID:       testChanB,
+			Capacity:     1_000_000,
+			LocalBalance: 500_000,
+			Active:       true,
+		}, {
+			ChanID:       testChanC,
+			Capacity:     1_000_000,
+			LocalBalance: 900_000,
+			Active:       true,
+		}},
+		invoices: make(map[lntypes.Hash]*invoices.Invoice),
+	}
+
+	h.mgr = NewManager(&Config{
+		SelfNode: testSelf,
+		Store:    newTestStore(t),
+		ListChannels: func() ([]ChannelBalance, error) {
+			return h.channels, nil
+		},
+		FindRoute:   h.findRoute,
+		SendToRoute: h.sendToRoute,
+		AddInvoice: func(_ context.Context, invoice *invoices.Invoice,
+			hash lntypes.Hash) (uint64, error) {
+
+			h.invoices[hash] = invoice
+
+			return uint64(len(h.invoices)), nil
+		},
+		CancelInvoice: func(_ context.Context,
+			hash lntypes.Hash) error {
+
+			h.canceled = append(h.canceled, hash)
+
+			return nil
+		},
+		FinalCltvDelta: 80,
+		MaxFee:         DefaultMaxFee,
+		MaxFeeRate:     DefaultMaxFeeRate,
+		DailyBudget:    DefaultDailyBudget,
+		MinAmount:      DefaultMinAmount,
+		MaxAmount:      DefaultMaxAmount,
+		Tolerance:      DefaultTolerance,
+		MaxAttempts:    DefaultMaxAttempts,
+		Clock:          h.clock,
+	})
+	require.NoError(t, h.mgr.Start())
+	t.Cleanup(func() {
+		require.NoError(t, h.mgr.Stop())
+	})
+
+	return h
+}
+
+// findRoute returns a route that leaves through the first allowed outgoing
+// channel and returns through the first allowed incoming channel.
+func (h *testHarness) findRoute(req *routing.RouteRequest) (*route.Route,
+	float64, error) {
+
+	h.routeReqs = append(h.routeReqs, req)
+
+	restrictions := req.Restrictions
+	if restrictions.FeeLimit < h.routeFee {
+		return nil, 0, errors.New("fee limit exceeded")
+	}
+
+	return &route.Route{
+		TotalAmount:  req.Amount + h.routeFee,
+		SourcePubKey: req.Source,
+		Hops: []*route.Hop{{
+			ChannelID:    restrictions.OutgoingChannelIDs[0],
+			AmtToForward: req.Amount,
+		}, {
+			ChannelID:    restrictions.IncomingChannelIDs[0],
+			AmtToForward: req.Amount,
+		}},
+	}, 1, nil
+}
+
+// sendToRoute settles or fails the attempt according to the next send result.
+func (h *testHarness) sendToRoute(hash lntypes.Hash, rt *route.Route,
+	_ lnwire.CustomRecords) (*paymentsdb.HTLCAttempt, error) {
+
+	h.routes = append(h.routes, rt)
+
+	if len(h.sendResults) == 0 || !h.sendResults[0] {
+		if len(h.sendResults) > 0 {
+			h.sendResults = h.sendResults[1:]
+		}
+
+		return &paymentsdb.HTLCAttempt{
+			Failure: &paymentsdb.HTLCFailInfo{},
+		}, errors.New("temporary channel failure")
+	}
+	h.sendResults = h.sendResults[1:]
+
+	return &paymentsdb.HTLCAttempt{
+		Settle: &paymentsdb.HTLCSettleInfo{
+			Preimage: *h.invoices[hash].Terms.PaymentPreimage,
+		},
+	}, nil
+}
+
+// receiveEvent returns the next event received by the client.
+func receiveEvent(t *testing.T, client *subscribe.Client) interface{} {
+	select {
+	case event := <-client.Updates():
+		return event
+
+	case <-time.After(time.Second):
+		t.Fatal("no rebalance event received")
+
+		return nil
+	}
+}
+
+// TestRebalance tests that a rebalance pays an invoice to ourselves along a
+// circular route, retrying failed routes, and records the result.
+func TestRebalance(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	h.sendResults = []bool{false, true}
+
+	client, err := h.mgr.SubscribeEvents()
+	require.NoError(t, err)
+	defer client.Cancel()
+
+	result, err := h.mgr.Rebalance(context.Background(), &Request{
+		IncomingChannel: testChanA,
+		Amount:          400_000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, result.Status)
+	require.EqualValues(t, 2, result.Attempts)
+	require.Equal(t, h.routeFee, result.Fee)
+	require.Empty(t, result.FailureReason)
+	require.False(t, result.Automatic)
+
+	// The fee is limited by the fee rate, which is lower than the maximum
+	// fee for this amount.
+	require.Len(t, h.routeReqs, 2)
+	req := h.routeReqs[0]
+	require.Equal(t, testSelf, req.Source)
+	require.Equal(t, testSelf, req.Target)
+	require.EqualValues(t, 200_000, req.Restrictions.FeeLimit)
+	require.Equal(
+		t, []uint64{testChanA.ToUint64()},
+		req.Restrictions.IncomingChannelIDs,
+	)
+	require.ElementsMatch(
+		t, []uint64{testChanB.ToUint64(), testChanC.ToUint64()},
+		req.Restrictions.OutgoingChannelIDs,
+	)
+
+	// The route pays the invoice we added to ourselves.
+	invoice := h.invoices[result.PaymentHash]
+	require.NotNil(t, invoice)
+	require.Equal(t, lnwire.NewMSatFromLokis(400_000), invoice.Terms.Value)
+
+	finalHop := h.routes[1].Hops[1]
+	require.NotNil(t, finalHop.MPP)
+	require.Equal(t, invoice.Terms.PaymentAddr, finalHop.MPP.PaymentAddr())
+	require.Empty(t, h.canceled)
+
+	// Subscribers are notified of the start, the failed attempt and the
+	// result.
+	started, ok := receiveEvent(t, client).(*StartedEvent)
+	require.True(t, ok)
+	require.Equal(t, result.PaymentHash, started.PaymentHash)
+
+	attemptFailed, ok := receiveEvent(t, client).(*AttemptFailedEvent)
+	require.True(t, ok)
+	require.EqualValues(t, 1, attemptFailed.Attempt)
+
+	finished, ok := receiveEvent(t, client).(*Result)
+	require.True(t, ok)
+	require.Equal(t, result, finished)
+
+	results, err := h.mgr.Results(time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []*Result{result}, results)
+
+	// Leaving and returning through the same channel isn't allowed.
+	_, err = h.mgr.Rebalance(context.Background(), &Request{
+		OutgoingChannels: []lnwire.ShortChannelID{testChanA},
+		IncomingChannel:  testChanA,
+		Amount:           400_000,
+	})
+	require.ErrorIs(t, err, ErrSameChannel)
+}
+
+// TestRebalanceFailed tests that a rebalance for which all routes fail is
+// recorded as failed and its invoice is canceled.
+func TestRebalanceFailed(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+
+	result, err := h.mgr.Rebalance(context.Background(), &Request{
+		OutgoingChannels: []lnwire.ShortChannelID{testChanC},
+		IncomingChannel:  testChanA,
+		Amount:           400_000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, result.Status)
+	require.EqualValues(t, DefaultMaxAttempts, result.Attempts)
+	require.Equal(t, testChanC, result.OutgoingChannel)
+	require.Zero(t, result.Fee)
+	require.Equal(t, "temporary channel failure", result.FailureReason)
+	require.Equal(t, []lntypes.Hash{result.PaymentHash}, h.canceled)
+}
+
+// TestRebalanceBudget tests that the fees of a day's rebalances are limited
+// by the daily budget.
+func TestRebalanceBudget(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	h.sendResults = []bool{true, true, true}
+
+	// A rebalance with an explicit maximum fee isn't limited by the fee
+	// rate, but by the budget.
+	_, err := h.mgr.Rebalance(context.Background(), &Request{
+		IncomingChannel: testChanA,
+		Amount:          100_000,
+		MaxFee:          DefaultDailyBudget,
+	})
+	require.NoError(t, err)
+	require.Equal(
+		t, lnwire.NewMSatFromLokis(DefaultDailyBudget),
+		h.routeReqs[0].Restrictions.FeeLimit,
+	)
+
+	// The fees of the next rebalances are limited by the remainder of
+	// the budget, until it's exhausted.
+	h.routeFee = lnwire.NewMSatFromLokis(DefaultDailyBudget) - 100_000
+	_, err = h.mgr.Rebalance(context.Background(), &Request{
+		IncomingChannel: testChanA,
+		Amount:          100_000,
+		MaxFee:          DefaultDailyBudget,
+	})
+	require.NoError(t, err)
+	require.Equal(
+		t, lnwire.NewMSatFromLokis(DefaultDailyBudget)-100_000,
+		h.routeReqs[1].Restrictions.FeeLimit,
+	)
+
+	spent, err := h.mgr.SpentFees()
+	require.NoError(t, err)
+	require.Equal(t, lnwire.NewMSatFromLokis(DefaultDailyBudget), spent)
+
+	_, err = h.mgr.Rebalance(context.Background(), &Request{
+		IncomingChannel: testChanA,
+		Amount:          100_000,
+	})
+	require.ErrorIs(t, err, ErrBudgetExhausted)
+
+	// A day later, the budget is available again.
+	h.clock.SetTime(testStart.Add(budgetPeriod + time.Second))
+	h.routeFee = 1_000
+	result, err := h.mgr.Rebalance(context.Background(), &Request{
+		IncomingChannel: testChanA,
+		Amount:          100_000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, result.Status)
+}
+
+// TestRebalanceTargets tests that channels are rebalanced towards their
+// targets, from the channels with a surplus into the ones with a deficit.
+func TestRebalanceTargets(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness(t)
+	h.routeFee = 1_000
+	h.sendResults = []bool{true}
+
+	// Targets must refer to our channels and set exactly one goal.
+	_, err := h.mgr.SetTargets([]*Target{{
+		ChanID:     lnwire.NewShortChanIDFromInt(4),
+		LocalRatio: fn.Some(0.5),
+	}}, nil, false)
+	require.ErrorIs(t, err, ErrUnknownChannel)
+
+	_, err = h.mgr.SetTargets([]*Target{{
+		ChanID:     testChanA,
+		LocalRatio: fn.Some(1.5),
+	}}, nil, false)
+	require.ErrorIs(t, err, ErrInvalidTarget)
+
+	_, err = h.mgr.SetTargets([]*Target{{
+		ChanID:       testChanA,
+		LocalRatio:   fn.Some(0.5),
+		LocalBalance: fn.Some(chainutil.Amount(500_000)),
+	}}, nil, false)
+	require.ErrorIs(t, err, ErrInvalidTarget)
+
+	// Channel A lacks 400k, channel B is within the tolerance of its
+	// target and channel C has a surplus of 300k.
+	targets, err := h.mgr.SetTargets([]*Target{{
+		ChanID:     testChanA,
+		LocalRatio: fn.Some(0.5),
+	}, {
+		ChanID:       testChanB,
+		LocalBalance: fn.Some(chainutil.Amount(550_000)),
+	}, {
+		ChanID:     testChanC,
+		LocalRatio: fn.Some(0.6),
+	}}, nil, false)
+	require.NoError(t, err)
+	require.Len(t, targets, 3)
+
+	require.NoError(t, h.mgr.rebalanceTargets(context.Background()))
+
+	// The rebalance moves the surplus of channel C into channel A.
+	require.Len(t, h.routeReqs, 1)
+	require.Equal(
+		t, []uint64{testChanC.ToUint64()},
+		h.routeReqs[0].Restrictions.OutgoingChannelIDs,
+	)
+
+	results, err := h.mgr.Results(time.Time{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, testChanA, results[0].IncomingChannel)
+	require.Equal(t, testChanC, results[0].OutgoingChannel)
+	require.EqualValues(t, 300_000, results[0].Amount)
+	require.True(t, results[0].Automatic)
+
+	// Once the targets are removed, there's nothing to rebalance.
+	_, err = h.mgr.SetTargets(nil, nil, true)
+	require.NoError(t, err)
+
+	require.NoError(t, h.mgr.rebalanceTargets(context.Background()))
+	require.Len(t, h.routeReqs, 1)
+}
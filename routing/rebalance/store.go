@@ -0,0 +1,467 @@
+package rebalance
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+)
+
+var (
+	// rebalanceBucket is the top-level bucket of the rebalancer.
+	rebalanceBucket = []byte("rebalance-bucket")
+
+	// targetsBucket is the sub-bucket that stores the
+	// ShortChannelID -> Target mapping.
+	targetsBucket = []byte("targets")
+
+	// resultsBucket is the sub-bucket that stores the results of the
+	// rebalances we made, keyed by their ID.
+	resultsBucket = []byte("results")
+
+	// ErrCorruptedRebalanceStore is returned when one of the buckets of
+	// the rebalancer is missing.
+	ErrCorruptedRebalanceStore = errors.New("rebalance store has been " +
+		"corrupted")
+)
+
+const (
+	// localRatioRecordType is the TLV type of the local ratio of a target,
+	// encoded as the bits of a float64.
+	localRatioRecordType tlv.Type = 0
+
+	// localBalanceRecordType is the TLV type of the local balance of a
+	// target in loki.
+	localBalanceRecordType tlv.Type = 2
+)
+
+const (
+	// timestampRecordType is the TLV type of the time of a result in unix
+	// nanoseconds.
+	timestampRecordType tlv.Type = 0
+
+	// outgoingRecordType is the TLV type of the outgoing channel of a
+	// result.
+	outgoingRecordType tlv.Type = 2
+
+	// incomingRecordType is the TLV type of the incoming channel of a
+	// result.
+	incomingRecordType tlv.Type = 4
+
+	// amountRecordType is the TLV type of the amount of a result in loki.
+	amountRecordType tlv.Type = 6
+
+	// feeRecordType is the TLV type of the fee of a result in milli-loki.
+	feeRecordType tlv.Type = 8
+
+	// statusRecordType is the TLV type of the status of a result.
+	statusRecordType tlv.Type = 10
+
+	// attemptsRecordType is the TLV type of the number of attempts of a
+	// result.
+	attemptsRecordType tlv.Type = 12
+
+	// failureRecordType is the TLV type of the failure reason of a result.
+	failureRecordType tlv.Type = 14
+
+	// paymentHashRecordType is the TLV type of the payment hash of a
+	// result.
+	paymentHashRecordType tlv.Type = 16
+
+	// automaticRecordType is the TLV type of the flag that marks results of
+	// automatic rebalances.
+	automaticRecordType tlv.Type = 18
+)
+
+// Store persists the rebalance targets and the results of our rebalances.
+type Store interface {
+	// FetchTargets returns all stored targets.
+	FetchTargets() ([]*Target, error)
+
+	// UpdateTargets atomically stores the given targets and removes the
+	// targets of the given channels. If replace is true, all other
+	// targets are removed as well.
+	UpdateTargets(targets []*Target, remove []lnwire.ShortChannelID,
+		replace bool) error
+
+	// AddResult stores the result of a rebalance, assigning it the next
+	// ID.
+	AddResult(result *Result) error
+
+	// FetchResults returns the results of the rebalances made at or after
+	// the given time, ordered by their ID.
+	FetchResults(since time.Time) ([]*Result, error)
+}
+
+// KVStore is a Store backed by a kvdb backend.
+type KVStore struct {
+	db kvdb.Backend
+}
+
+// A compile-time check to ensure KVStore implements the Store interface.
+var _ Store = (*KVStore)(nil)
+
+// NewKVStore creates a new rebalance store on top of the given backend,
+// creating its buckets if they do not exist yet.
+func NewKVStore(db kvdb.Backend) (*KVStore, error) {
+	err := kvdb.Update(db, func(tx kvdb.RwTx) error {
+		rootBucket, err := tx.CreateTopLevelBucket(rebalanceBucket)
+		if err != nil {
+			return err
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(targetsBucket)
+		if err != nil {
+			return err
+		}
+
+		_, err = rootBucket.CreateBucketIfNotExists(resultsBucket)
+
+		return err
+	}, func() {})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create rebalance buckets: %w",
+			err)
+	}
+
+	return &KVStore{db: db}, nil
+}
+
+// FetchTargets returns all stored targets ordered by channel ID.
+//
+// NOTE: This is part of the Store interface.
+func (s *KVStore) FetchTargets() ([]*Target, error) {
+	var targets []*Target
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket, err := readBucket(tx, targetsBucket)
+		if err != nil {
+			return err
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			chanID := lnwire.NewShortChanIDFromInt(
+				binary.BigEndian.Uint64(k),
+			)
+
+			target, err := deserializeTarget(
+				chanID, bytes.NewReader(v),
+			)
+			if err != nil {
+				return err
+			}
+			targets = append(targets, target)
+
+			return nil
+		})
+	}, func() {
+		targets = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// UpdateTargets atomically stores the given targets and removes the targets
+// of the given channels.
+//
+// NOTE: This is part of the Store interface.
+func (s *KVStore) UpdateTargets(targets []*Target,
+	remove []lnwire.ShortChannelID, replace bool) error {
+
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		rootBucket := tx.ReadWriteBucket(rebalanceBucket)
+		if rootBucket == nil {
+			return ErrCorruptedRebalanceStore
+		}
+
+		if replace {
+			err := rootBucket.DeleteNestedBucket(targetsBucket)
+			if err != nil {
+				return err
+			}
+
+			_, err = rootBucket.CreateBucket(targetsBucket)
+			if err != nil {
+				return err
+			}
+		}
+
+		bucket := rootBucket.NestedReadWriteBucket(targetsBucket)
+		if bucket == nil {
+			return ErrCorruptedRebalanceStore
+		}
+
+		for _, chanID := range remove {
+			if err := bucket.Delete(chanKey(chanID)); err != nil {
+				return err
+			}
+		}
+
+		for _, target := range targets {
+			var b bytes.Buffer
+			if err := serializeTarget(&b, target); err != nil {
+				return err
+			}
+
+			err := bucket.Put(chanKey(target.ChanID), b.Bytes())
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func() {})
+}
+
+// AddResult stores the result of a rebalance, assigning it the next ID.
+//
+// NOTE: This is part of the Store interface.
+func (s *KVStore) AddResult(result *Result) error {
+	return kvdb.Update(s.db, func(tx kvdb.RwTx) error {
+		rootBucket := tx.ReadWriteBucket(rebalanceBucket)
+		if rootBucket == nil {
+			return ErrCorruptedRebalanceStore
+		}
+
+		bucket := rootBucket.NestedReadWriteBucket(resultsBucket)
+		if bucket == nil {
+			return ErrCorruptedRebalanceStore
+		}
+
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := serializeResult(&b, result); err != nil {
+			return err
+		}
+
+		var k [8]byte
+		binary.BigEndian.PutUint64(k[:], id)
+		if err := bucket.Put(k[:], b.Bytes()); err != nil {
+			return err
+		}
+
+		result.ID = id
+
+		return nil
+	}, func() {})
+}
+
+// FetchResults returns the results of the rebalances made at or after the
+// given time, ordered by their ID.
+//
+// NOTE: This is part of the Store interface.
+func (s *KVStore) FetchResults(since time.Time) ([]*Result, error) {
+	var results []*Result
+	err := kvdb.View(s.db, func(tx kvdb.RTx) error {
+		bucket, err := readBucket(tx, resultsBucket)
+		if err != nil {
+			return err
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			result, err := deserializeResult(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+
+			if result.Timestamp.Before(since) {
+				return nil
+			}
+
+			result.ID = binary.BigEndian.Uint64(k)
+			results = append(results, result)
+
+			return nil
+		})
+	}, func() {
+		results = nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// readBucket returns the given sub-bucket of the rebalance bucket.
+func readBucket(tx kvdb.RTx, key []byte) (kvdb.RBucket, error) {
+	rootBucket := tx.ReadBucket(rebalanceBucket)
+	if rootBucket == nil {
+		return nil, ErrCorruptedRebalanceStore
+	}
+
+	bucket := rootBucket.NestedReadBucket(key)
+	if bucket == nil {
+		return nil, ErrCorruptedRebalanceStore
+	}
+
+	return bucket, nil
+}
+
+// chanKey returns the key a channel is stored under.
+func chanKey(chanID lnwire.ShortChannelID) []byte {
+	var k [8]byte
+	binary.BigEndian.PutUint64(k[:], chanID.ToUint64())
+
+	return k[:]
+}
+
+// serializeTarget writes the goal of the target as a TLV stream. The channel
+// ID is the key it's stored under.
+func serializeTarget(w io.Writer, target *Target) error {
+	var records []tlv.Record
+
+	target.LocalRatio.WhenSome(func(ratio float64) {
+		bits := math.Float64bits(ratio)
+		records = append(records, tlv.MakePrimitiveRecord(
+			localRatioRecordType, &bits,
+		))
+	})
+
+	target.LocalBalance.WhenSome(func(amt chainutil.Amount) {
+		balance := uint64(amt)
+		records = append(records, tlv.MakePrimitiveRecord(
+			localBalanceRecordType, &balance,
+		))
+	})
+
+	if len(records) == 0 {
+		return ErrInvalidTarget
+	}
+
+	stream, err := tlv.NewStream(records...)
+	if err != nil {
+		return err
+	}
+
+	return stream.Encode(w)
+}
+
+// deserializeTarget reads a target written by serializeTarget.
+func deserializeTarget(chanID lnwire.ShortChannelID,
+	r io.Reader) (*Target, error) {
+
+	var ratio, balance uint64
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(localRatioRecordType, &ratio),
+		tlv.MakePrimitiveRecord(localBalanceRecordType, &balance),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := stream.DecodeWithParsedTypes(r)
+	if err != nil {
+		return nil, err
+	}
+
+	target := &Target{
+		ChanID: chanID,
+	}
+	if _, ok := parsed[localRatioRecordType]; ok {
+		target.LocalRatio = fn.Some(math.Float64frombits(ratio))
+	}
+	if _, ok := parsed[localBalanceRecordType]; ok {
+		target.LocalBalance = fn.Some(chainutil.Amount(balance))
+	}
+
+	return target, nil
+}
+
+// serializeResult writes all fields of the result except for its ID, which is
+// the key it's stored under, as a TLV stream.
+func serializeResult(w io.Writer, result *Result) error {
+	var (
+		timestamp   = uint64(result.Timestamp.UnixNano())
+		outgoing    = result.OutgoingChannel.ToUint64()
+		incoming    = result.IncomingChannel.ToUint64()
+		amount      = uint64(result.Amount)
+		fee         = uint64(result.Fee)
+		status      = uint8(result.Status)
+		attempts    = result.Attempts
+		failure     = []byte(result.FailureReason)
+		paymentHash = [32]byte(result.PaymentHash)
+		automatic   uint8
+	)
+	if result.Automatic {
+		automatic = 1
+	}
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(timestampRecordType, &timestamp),
+		tlv.MakePrimitiveRecord(outgoingRecordType, &outgoing),
+		tlv.MakePrimitiveRecord(incomingRecordType, &incoming),
+		tlv.MakePrimitiveRecord(amountRecordType, &amount),
+		tlv.MakePrimitiveRecord(feeRecordType, &fee),
+		tlv.MakePrimitiveRecord(statusRecordType, &status),
+		tlv.MakePrimitiveRecord(attemptsRecordType, &attempts),
+		tlv.MakePrimitiveRecord(failureRecordType, &failure),
+		tlv.MakePrimitiveRecord(paymentHashRecordType, &paymentHash),
+		tlv.MakePrimitiveRecord(automaticRecordType, &automatic),
+	)
+	if err != nil {
+		return err
+	}
+
+	return stream.Encode(w)
+}
+
+// deserializeResult reads a result written by serializeResult.
+func deserializeResult(r io.Reader) (*Result, error) {
+	var (
+		timestamp, outgoing, incoming, amount, fee uint64
+		status, automatic                          uint8
+		attempts                                   uint32
+		failure                                    []byte
+		paymentHash                                [32]byte
+	)
+
+	stream, err := tlv.NewStream(
+		tlv.MakePrimitiveRecord(timestampRecordType, &timestamp),
+		tlv.MakePrimitiveRecord(outgoingRecordType, &outgoing),
+		tlv.MakePrimitiveRecord(incomingRecordType, &incoming),
+		tlv.MakePrimitiveRecord(amountRecordType, &amount),
+		tlv.MakePrimitiveRecord(feeRecordType, &fee),
+		tlv.MakePrimitiveRecord(statusRecordType, &status),
+		tlv.MakePrimitiveRecord(attemptsRecordType, &attempts),
+		tlv.MakePrimitiveRecord(failureRecordType, &failure),
+		tlv.MakePrimitiveRecord(paymentHashRecordType, &paymentHash),
+		tlv.MakePrimitiveRecord(automaticRecordType, &automatic),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.Decode(r); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Timestamp:       time.Unix(0, int64(timestamp)),
+		OutgoingChannel: lnwire.NewShortChanIDFromInt(outgoing),
+		IncomingChannel: lnwire.NewShortChanIDFromInt(incoming),
+		Amount:          chainutil.Amount(amount),
+		Fee:             lnwire.MilliLoki(fee),
+		Status:          Status(status),
+		Attempts:        attempts,
+		FailureReason:   string(failure),
+		PaymentHash:     lntypes.Hash(paymentHash),
+		Automatic:       automatic == 1,
+	}, nil
+}
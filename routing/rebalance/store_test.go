@@ -0,0 +1,115 @@
+package rebalance
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/go-flokicoin/chainutil"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore creates a rebalance store backed by a fresh database.
+func newTestStore(t *testing.T) *KVStore {
+	dbPath := filepath.Join(t.TempDir(), "testdb")
+	db, err := kvdb.Create(
+		kvdb.BoltBackendName, dbPath, true, kvdb.DefaultDBTimeout,
+		false,
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	store, err := NewKVStore(db)
+	require.NoError(t, err)
+
+	return store
+}
+
+// TestStoreTargets tests that targets are updated, removed and replaced.
+func TestStoreTargets(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t)
+
+	ratio := &Target{
+		ChanID:     lnwire.NewShortChanIDFromInt(1),
+		LocalRatio: fn.Some(0.5),
+	}
+	balance := &Target{
+		ChanID:       lnwire.NewShortChanIDFromInt(2),
+		LocalBalance: fn.Some(chainutil.Amount(100_000)),
+	}
+	require.NoError(t, store.UpdateTargets(
+		[]*Target{balance, ratio}, nil, false,
+	))
+
+	targets, err := store.FetchTargets()
+	require.NoError(t, err)
+	require.Equal(t, []*Target{ratio, balance}, targets)
+
+	// Targets without a goal can't be stored.
+	err = store.UpdateTargets([]*Target{{ChanID: ratio.ChanID}}, nil, false)
+	require.ErrorIs(t, err, ErrInvalidTarget)
+
+	require.NoError(t, store.UpdateTargets(
+		nil, []lnwire.ShortChannelID{ratio.ChanID}, false,
+	))
+	targets, err = store.FetchTargets()
+	require.NoError(t, err)
+	require.Equal(t, []*Target{balance}, targets)
+
+	// Replacing the targets removes the ones not given.
+	require.NoError(t, store.UpdateTargets([]*Target{ratio}, nil, true))
+	targets, err = store.FetchTargets()
+	require.NoError(t, err)
+	require.Equal(t, []*Target{ratio}, targets)
+}
+
+// TestStoreResults tests that results are assigned increasing IDs and are
+// filtered by their time.
+func TestStoreResults(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t)
+
+	start := time.Unix(1_700_000_000, 0)
+	failed := &Result{
+		Timestamp:       start,
+		OutgoingChannel: lnwire.NewShortChanIDFromInt(1),
+		IncomingChannel: lnwire.NewShortChanIDFromInt(2),
+		Amount:          50_000,
+		Status:          StatusFailed,
+		Attempts:        3,
+		FailureReason:   "unable to find route",
+		PaymentHash:     lntypes.Hash{1},
+	}
+	succeeded := &Result{
+		Timestamp:       start.Add(time.Hour),
+		OutgoingChannel: lnwire.NewShortChanIDFromInt(3),
+		IncomingChannel: lnwire.NewShortChanIDFromInt(2),
+		Amount:          50_000,
+		Fee:             12_345,
+		Status:          StatusSucceeded,
+		Attempts:        1,
+		PaymentHash:     lntypes.Hash{2},
+		Automatic:       true,
+	}
+	require.NoError(t, store.AddResult(failed))
+	require.NoError(t, store.AddResult(succeeded))
+	require.EqualValues(t, 1, failed.ID)
+	require.EqualValues(t, 2, succeeded.ID)
+
+	results, err := store.FetchResults(time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, []*Result{failed, succeeded}, results)
+
+	results, err = store.FetchResults(start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, []*Result{succeeded}, results)
+}
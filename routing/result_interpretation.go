@@ -321,6 +321,17 @@ func (i *interpretedResult) processPaymentOutcomeFinal(route *mcRoute,
 	case *lnwire.FailInvalidBlinding:
 		failNode()
 
+	// The final hop is a trampoline node that didn't accept the fee or
+	// expiry of its trampoline onion. The route to it was fine, but the
+	// trampoline policy we built the onion for is outdated, so we fail
+	// the payment without penalizing the node.
+	case *lnwire.FailTrampolineFeeInsufficient,
+		*lnwire.FailTrampolineExpiryTooSoon:
+
+		i.successPairRange(route, 0, n-1)
+
+		i.finalFailureReason = &reasonError
+
 	// All other errors are considered terminal if coming from the
 	// final hop. They indicate that something is wrong at the
 	// recipient, so we do apply a penalty.
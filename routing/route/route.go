@@ -39,6 +39,12 @@ var (
 	// record but no MPP record is presented for the final hop.
 	ErrAMPMissingMPP = errors.New("cannot send AMP without MPP record")
 
+	// ErrIntermediateTrampolineHop is returned when a hop tries to deliver
+	// a trampoline onion to an intermediate hop, only final hops can
+	// receive trampoline onions.
+	ErrIntermediateTrampolineHop = errors.New("cannot send trampoline " +
+		"onion to intermediate")
+
 	// ErrMissingField is returned if a required TLV is missing.
 	ErrMissingField = errors.New("required tlv missing")
 
@@ -183,6 +189,11 @@ type Hop struct {
 	// spread over more than one HTLC. This field should only be set for
 	// the final hop in a blinded path.
 	TotalAmtMsat lnwire.MilliLoki
+
+	// TrampolineOnion is the onion packet for the trampoline node this hop
+	// delivers the payment to. This field should only be set for the final
+	// hop.
+	TrampolineOnion []byte
 }
 
 // Copy returns a deep copy of the Hop.
@@ -322,6 +333,18 @@ func (h *Hop) PackHopPayload(w io.Writer, nextChanID uint64,
 		)
 	}
 
+	// If a trampoline onion is destined for this hop, ensure that we only
+	// ever attach it to the final hop.
+	if h.TrampolineOnion != nil {
+		if !finalHop {
+			return ErrIntermediateTrampolineHop
+		}
+
+		records = append(records,
+			record.NewTrampolineOnionRecord(&h.TrampolineOnion),
+		)
+	}
+
 	if h.TotalAmtMsat != 0 {
 		totalAmtInt := uint64(h.TotalAmtMsat)
 		records = append(records,
@@ -471,6 +494,14 @@ func (h *Hop) PayloadSize(nextChanID uint64) uint64 {
 		)
 	}
 
+	// Add trampoline onion if present.
+	if h.TrampolineOnion != nil {
+		addRecord(
+			record.TrampolineOnionType,
+			uint64(len(h.TrampolineOnion)),
+		)
+	}
+
 	// Add custom records.
 	for k, v := range h.CustomRecords {
 		addRecord(tlv.Type(k), uint64(len(v)))
@@ -252,6 +252,27 @@ func TestBlindedHops(t *testing.T) {
 			nextChannel: 0,
 			isFinal:     true,
 		},
+		{
+			name: "intermediate trampoline onion",
+			hop: Hop{
+				AmtToForward:     100,
+				OutgoingTimeLock: 52,
+				TrampolineOnion:  []byte{1, 2, 3},
+			},
+			nextChannel: 1,
+			isFinal:     false,
+			err:         ErrIntermediateTrampolineHop,
+		},
+		{
+			name: "valid final trampoline onion",
+			hop: Hop{
+				AmtToForward:     100,
+				OutgoingTimeLock: 52,
+				TrampolineOnion:  []byte{1, 2, 3},
+			},
+			nextChannel: 0,
+			isFinal:     true,
+		},
 	}
 
 	for _, testCase := range tests {
@@ -343,6 +364,25 @@ func TestPayloadSize(t *testing.T) {
 		},
 	}
 
+	trampolineHops := []*Hop{
+		{
+			PubKeyBytes:      testPubKeyBytes,
+			AmtToForward:     1500,
+			OutgoingTimeLock: 700000,
+			ChannelID:        63584534844,
+		},
+		{
+			// Final hop is the trampoline node receiving the
+			// trampoline onion.
+			PubKeyBytes:      testPubKeyBytes,
+			AmtToForward:     1000,
+			OutgoingTimeLock: 700000,
+			ChannelID:        51784534844,
+			MPP:              record.NewMPP(1000, [32]byte{}),
+			TrampolineOnion:  bytes.Repeat([]byte{1}, 650),
+		},
+	}
+
 	testCases := []struct {
 		name string
 		hops []*Hop
@@ -355,6 +395,10 @@ func TestPayloadSize(t *testing.T) {
 			name: "blinded route",
 			hops: blindedHops,
 		},
+		{
+			name: "trampoline route",
+			hops: trampolineHops,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -185,6 +185,31 @@ type MissionControlQuerier interface {
 		amt lnwire.MilliLoki, capacity chainutil.Amount) float64
 }
 
+// EstimateRounder is implemented by a mission control whose probability
+// estimates may only arrive after path finding asked for them.
+type EstimateRounder interface {
+	// NewEstimateRound returns a new EstimateRound that collects the
+	// estimates a single path finding call asked for.
+	NewEstimateRound() *EstimateRound
+}
+
+// newProbabilityRound returns the probability source for a single path finding
+// call that queries the given mission control, along with a function that
+// waits for the estimates the source couldn't answer right away. The function
+// returns true if path finding should be repeated with them.
+func newProbabilityRound(mc MissionControlQuerier) (probabilitySource,
+	func() bool) {
+
+	rounder, ok := mc.(EstimateRounder)
+	if !ok {
+		return mc.GetProbability, func() bool { return false }
+	}
+
+	round := rounder.NewEstimateRound()
+
+	return round.GetProbability, round.AwaitEstimates
+}
+
 // FeeSchema is the set fee configuration for a Lightning Node on the network.
 // Using the coefficients described within the schema, the required fee to
 // forward outgoing payments can be derived.
@@ -545,16 +570,26 @@ func (r *ChannelRouter) FindRoute(req *RouteRequest) (*route.Route, float64,
 		return nil, 0, errors.New("time preference out of range")
 	}
 
-	path, probability, err := findPath(
-		&graphParams{
-			additionalEdges: req.RouteHints,
-			bandwidthHints:  bandwidthHints,
-			graph:           r.cfg.RoutingGraph,
-		},
-		req.Restrictions, &r.cfg.PathFindingConfig,
-		r.cfg.SelfNode, req.Source, req.Target, req.Amount,
-		req.TimePreference, finalHtlcExpiry,
-	)
+	find := func() ([]*unifiedEdge, float64, error) {
+		return findPath(
+			&graphParams{
+				additionalEdges: req.RouteHints,
+				bandwidthHints:  bandwidthHints,
+				graph:           r.cfg.RoutingGraph,
+			},
+			req.Restrictions, &r.cfg.PathFindingConfig,
+			r.cfg.SelfNode, req.Source, req.Target, req.Amount,
+			req.TimePreference, finalHtlcExpiry,
+		)
+	}
+	path, probability, err := find()
+
+	// If mission control asked for probability estimates that weren't
+	// available yet, we wait for them and search again with their answers.
+	awaitEstimates := req.Restrictions.AwaitEstimates
+	if awaitEstimates != nil && awaitEstimates() {
+		path, probability, err = find()
+	}
 	if err != nil {
 		return nil, 0, err
 	}
@@ -816,6 +851,14 @@ type LightningPayment struct {
 	// attempting to complete.
 	PaymentRequest []byte
 
+	// Offer is the optional BOLT 12 offer that PaymentRequest, the invoice
+	// fetched for it, was requested for.
+	Offer []byte
+
+	// PayerNote is the optional note sent with the invoice request of a
+	// BOLT 12 payment.
+	PayerNote string
+
 	// DestCustomRecords are TLV records that are to be sent to the final
 	// hop in the new onion payload format. If the destination does not
 	// understand this new onion payload format, then the payment will
@@ -847,6 +890,23 @@ type LightningPayment struct {
 	// Metadata is additional data that is sent along with the payment to
 	// the payee.
 	Metadata []byte
+
+	// Trampolines is the list of trampoline nodes the payment should be
+	// relayed through, in the order they are visited. If set, the payment
+	// is sent to the first trampoline node, which finds the route to the
+	// next one, and the last one finds the route to the recipient.
+	Trampolines []TrampolineHop
+
+	// TrampolineOnion is the onion delivered to the target of the
+	// payment, if the target is a trampoline node. It's created from
+	// Trampolines when the payment is prepared, or set by a trampoline
+	// node that relays a payment to the next trampoline node.
+	TrampolineOnion []byte
+
+	// TrampolineRelay is set if the payment relays a trampoline payment we
+	// received as a trampoline node. As it isn't our own payment, it's
+	// neither listed nor deleted along with ours.
+	TrampolineRelay bool
 }
 
 // AMPOptions houses information that must be known in order to send an AMP
@@ -967,6 +1027,19 @@ func spewPayment(payment *LightningPayment) lnutils.LogClosure {
 func (r *ChannelRouter) PreparePayment(payment *LightningPayment) (
 	PaymentSession, shards.ShardTracker, error) {
 
+	// If the payment should be relayed through trampoline nodes, we send
+	// it to the first of them instead of the recipient.
+	if len(payment.Trampolines) > 0 {
+		_, height, err := r.cfg.Chain.GetBestBlock()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := payment.applyTrampolines(uint32(height)); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Assemble any custom data we want to send to the first hop only.
 	var firstHopData fn.Option[tlv.Blob]
 	if len(payment.FirstHopCustomRecords) > 0 {
@@ -1004,6 +1077,9 @@ func (r *ChannelRouter) PreparePayment(payment *LightningPayment) (
 		CreationTime:          r.cfg.Clock.Now(),
 		PaymentRequest:        payment.PaymentRequest,
 		FirstHopCustomRecords: payment.FirstHopCustomRecords,
+		Offer:                 payment.Offer,
+		PayerNote:             payment.PayerNote,
+		TrampolineRelay:       payment.TrampolineRelay,
 	}
 
 	// Create a new ShardTracker that we'll use during the life cycle of
@@ -1449,6 +1449,108 @@ func TestSendToRouteStructuredError(t *testing.T) {
 	}
 }
 
+// TestSendProbe asserts that the outcome of a probe is reported to mission
+// control without registering a payment with the control tower.
+func TestSendProbe(t *testing.T) {
+	t.Parallel()
+
+	// Setup a three node network.
+	chanCapSat := chainutil.Amount(100000)
+	testChannels := []*testChannel{
+		symmetricTestChannel("a", "b", chanCapSat, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+			MinHTLC: 1,
+			MaxHTLC: lnwire.NewMSatFromLokis(chanCapSat),
+		}, 1),
+		symmetricTestChannel("b", "c", chanCapSat, &testChannelPolicy{
+			Expiry:  144,
+			FeeRate: 400,
+			MinHTLC: 1,
+			MaxHTLC: lnwire.NewMSatFromLokis(chanCapSat),
+		}, 2),
+	}
+
+	testGraph, err := createTestGraphFromChannels(t, true, testChannels, "a")
+	require.NoError(t, err, "unable to create graph")
+
+	const startingBlockHeight = 101
+	ctx := createTestCtxFromGraphInstance(t, startingBlockHeight, testGraph)
+
+	init := make(chan initArgs, 1)
+	ctx.router.cfg.Control.(*mockControlTowerOld).init = init
+
+	const probeAmt = lnwire.MilliLoki(10000)
+	hops := []*route.Hop{
+		{
+			ChannelID:     1,
+			PubKeyBytes:   ctx.aliases["b"],
+			AmtToForward:  probeAmt,
+			LegacyPayload: true,
+		},
+		{
+			ChannelID:     2,
+			PubKeyBytes:   ctx.aliases["c"],
+			AmtToForward:  probeAmt,
+			LegacyPayload: true,
+		},
+	}
+
+	rt, err := route.NewRouteFromHops(probeAmt, 100, ctx.aliases["a"], hops)
+	require.NoError(t, err, "unable to create route")
+
+	payer := ctx.router.cfg.Payer.(*mockPaymentAttemptDispatcherOld)
+	mc := ctx.router.cfg.MissionControl.(*MissionControl)
+
+	// The final hop fails the probe because it doesn't know the hash,
+	// which proves that the route can carry the amount.
+	payer.setPaymentResult(
+		func(firstHop lnwire.ShortChannelID) ([32]byte, error) {
+			return [32]byte{}, htlcswitch.NewForwardingError(
+				lnwire.NewFailIncorrectDetails(probeAmt, 100),
+				len(hops),
+			)
+		},
+	)
+
+	result, err := ctx.router.SendProbe(t.Context(), rt)
+	require.NoError(t, err)
+	require.True(t, result.Reached)
+	require.Equal(t, fn.Some(len(hops)), result.FailureSourceIdx)
+
+	pair := mc.GetPairHistorySnapshot(ctx.aliases["b"], ctx.aliases["c"])
+	require.Equal(t, probeAmt, pair.SuccessAmt)
+
+	// An intermediate node that lacks the liquidity fails the probe, which
+	// is recorded as a failure of its outgoing channel.
+	payer.setPaymentResult(
+		func(firstHop lnwire.ShortChannelID) ([32]byte, error) {
+			return [32]byte{}, htlcswitch.NewForwardingError(
+				&lnwire.FailTemporaryChannelFailure{}, 1,
+			)
+		},
+	)
+
+	result, err = ctx.router.SendProbe(t.Context(), rt)
+	require.NoError(t, err)
+	require.False(t, result.Reached)
+	require.Equal(t, fn.Some(1), result.FailureSourceIdx)
+	require.IsType(
+		t, &lnwire.FailTemporaryChannelFailure{}, result.Failure,
+	)
+
+	pair = mc.GetPairHistorySnapshot(ctx.aliases["b"], ctx.aliases["c"])
+	require.Equal(t, probeAmt, pair.FailAmt)
+
+	// Neither of the probes was registered as a payment.
+	select {
+	case <-init:
+		t.Fatal("probe registered with the control tower")
+
+	default:
+	}
+}
+
 // TestSendToRouteMaxHops asserts that SendToRoute fails when using a route that
 // exceeds the maximum number of hops.
 func TestSendToRouteMaxHops(t *testing.T) {
@@ -0,0 +1,263 @@
+package routing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/record"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/flnd/tlv"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	sphinx "github.com/flokiorg/lightning-onion"
+)
+
+// TrampolineOnionPayloadSize is the size of the routing info of the
+// trampoline onions we create. It's small enough for the trampoline onion to
+// fit into the final hop payload of the outer onion while still leaving room
+// for the payloads of the hops that lead to the first trampoline node.
+const TrampolineOnionPayloadSize = sphinx.MaxRoutingPayloadSize / 2
+
+var (
+	// ErrTrampolineFeeLimit is returned if the fees of the trampoline
+	// nodes a payment is relayed through exceed its fee limit.
+	ErrTrampolineFeeLimit = errors.New("trampoline fees exceed fee limit")
+
+	// ErrTrampolineCltvLimit is returned if the CLTV deltas of the
+	// trampoline nodes a payment is relayed through exceed its CLTV limit.
+	ErrTrampolineCltvLimit = errors.New("trampoline cltv deltas exceed " +
+		"cltv limit")
+)
+
+// TrampolineHop is a trampoline node a payment is relayed through, along with
+// the policy it advertised for relaying trampoline payments.
+type TrampolineHop struct {
+	// Node is the public key of the trampoline node.
+	Node route.Vertex
+
+	// Policy is the policy the node relays trampoline payments by.
+	Policy lnwire.TrampolinePolicy
+
+	// Features is the feature vector the node advertised. It's used as
+	// the destination features of the route to the first trampoline node.
+	Features *lnwire.FeatureVector
+}
+
+// trampolineHopPayload is the payload of a single hop of a trampoline onion.
+type trampolineHopPayload struct {
+	amt          uint64
+	cltv         uint32
+	outgoingNode *crypto.PublicKey
+	mpp          *record.MPP
+}
+
+// pack encodes the payload as a TLV stream suitable for a sphinx hop payload.
+func (h *trampolineHopPayload) pack() (sphinx.HopPayload, error) {
+	records := []tlv.Record{
+		record.NewAmtToFwdRecord(&h.amt),
+		record.NewLockTimeRecord(&h.cltv),
+	}
+	if h.mpp != nil {
+		records = append(records, h.mpp.Record())
+	}
+	if h.outgoingNode != nil {
+		records = append(records, record.NewOutgoingNodeIDRecord(
+			&h.outgoingNode,
+		))
+	}
+
+	tlv.SortRecords(records)
+
+	tlvStream, err := tlv.NewStream(records...)
+	if err != nil {
+		return sphinx.HopPayload{}, err
+	}
+
+	var b bytes.Buffer
+	if err := tlvStream.Encode(&b); err != nil {
+		return sphinx.HopPayload{}, err
+	}
+
+	return sphinx.NewTLVHopPayload(b.Bytes())
+}
+
+// applyTrampolines rewrites the payment to be relayed through its trampoline
+// nodes. The payment is sent to the first trampoline node, carrying a
+// trampoline onion that instructs each trampoline node to find a route to
+// the next one, and the last one to find a route to the recipient. The
+// amount, fee limit and CLTV delta of the payment are adjusted for the fees
+// and CLTV deltas the trampoline nodes charge.
+//
+// If the payment doesn't have any trampoline nodes or is destined to one of
+// them, it's left unchanged.
+func (l *LightningPayment) applyTrampolines(height uint32) error {
+	if len(l.Trampolines) == 0 {
+		return nil
+	}
+
+	for _, hop := range l.Trampolines {
+		if hop.Node == l.Target {
+			return nil
+		}
+	}
+
+	switch {
+	case l.paymentHash == nil:
+		return errors.New("trampoline payments require a payment hash")
+
+	case l.BlindedPathSet != nil:
+		return errors.New("trampoline payments can't be sent to " +
+			"blinded paths")
+
+	case len(l.RouteHints) > 0:
+		return errors.New("trampoline payments can't use route hints")
+
+	case len(l.DestCustomRecords) > 0 || l.Metadata != nil:
+		return errors.New("trampoline payments can't carry custom " +
+			"records or metadata")
+	}
+
+	// If the recipient understands trampoline onions, it receives the
+	// innermost layer of the onion. Otherwise the last trampoline node
+	// pays it as a regular payment, which requires the payment address of
+	// the invoice.
+	recipientSupport := l.DestFeatures != nil &&
+		l.DestFeatures.HasFeature(lnwire.TrampolineRoutingOptional)
+
+	var mpp *record.MPP
+	l.PaymentAddr.WhenSome(func(addr [32]byte) {
+		mpp = record.NewMPP(l.Amount, addr)
+	})
+	if mpp == nil && !recipientSupport {
+		return errors.New("trampoline payments require a payment " +
+			"address")
+	}
+
+	// Walk the trampoline route backwards, accumulating the fees and CLTV
+	// deltas of each trampoline node.
+	var (
+		payloads []*trampolineHopPayload
+		pubKeys  []*crypto.PublicKey
+
+		amt  = l.Amount
+		cltv = height + uint32(l.FinalCLTVDelta) + uint32(BlockPadding)
+		next = l.Target
+	)
+	if recipientSupport {
+		pubKey, err := crypto.ParsePubKey(l.Target[:])
+		if err != nil {
+			return err
+		}
+
+		payloads = append(payloads, &trampolineHopPayload{
+			amt:  uint64(amt),
+			cltv: cltv,
+			mpp:  mpp,
+		})
+		pubKeys = append(pubKeys, pubKey)
+	}
+
+	for i := len(l.Trampolines) - 1; i >= 0; i-- {
+		hop := l.Trampolines[i]
+
+		nextPubKey, err := crypto.ParsePubKey(next[:])
+		if err != nil {
+			return err
+		}
+		pubKey, err := crypto.ParsePubKey(hop.Node[:])
+		if err != nil {
+			return err
+		}
+
+		payload := &trampolineHopPayload{
+			amt:          uint64(amt),
+			cltv:         cltv,
+			outgoingNode: nextPubKey,
+		}
+		if i == len(l.Trampolines)-1 && !recipientSupport {
+			payload.mpp = mpp
+		}
+
+		payloads = append([]*trampolineHopPayload{payload}, payloads...)
+		pubKeys = append([]*crypto.PublicKey{pubKey}, pubKeys...)
+
+		amt += hop.Policy.ComputeFee(amt)
+		cltv += uint32(hop.Policy.CltvExpiryDelta)
+		next = hop.Node
+	}
+
+	if len(payloads) > sphinx.NumMaxHops {
+		return route.ErrMaxRouteHopsExceeded
+	}
+
+	trampolineFees := amt - l.Amount
+	if trampolineFees > l.FeeLimit {
+		return fmt.Errorf("%w: fees %v, limit %v",
+			ErrTrampolineFeeLimit, trampolineFees, l.FeeLimit)
+	}
+
+	finalCltvDelta := cltv - height
+	if finalCltvDelta > math.MaxUint16 ||
+		finalCltvDelta+uint32(BlockPadding) > l.CltvLimit {
+
+		return fmt.Errorf("%w: delta %v, limit %v",
+			ErrTrampolineCltvLimit, finalCltvDelta, l.CltvLimit)
+	}
+
+	var path sphinx.PaymentPath
+	for i, payload := range payloads {
+		hopPayload, err := payload.pack()
+		if err != nil {
+			return err
+		}
+
+		path[i] = sphinx.OnionHop{
+			NodePub:    *pubKeys[i],
+			HopPayload: hopPayload,
+		}
+	}
+
+	sessionKey, err := crypto.NewPrivateKey()
+	if err != nil {
+		return err
+	}
+
+	onion, err := sphinx.NewOnionPacket(
+		&path, sessionKey, l.paymentHash[:],
+		sphinx.DeterministicPacketFiller,
+		sphinx.WithMaxPayloadSize(TrampolineOnionPayloadSize),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create trampoline onion: %w", err)
+	}
+
+	var b bytes.Buffer
+	if err := onion.Encode(&b); err != nil {
+		return err
+	}
+
+	// The first trampoline node only learns the total amount of the
+	// payment, so it gets a payment address of its own.
+	var trampolineAddr [32]byte
+	if _, err := rand.Read(trampolineAddr[:]); err != nil {
+		return err
+	}
+
+	log.Debugf("Relaying payment %v through %d trampoline nodes, "+
+		"fees=%v, final_cltv_delta=%v", l.paymentHash,
+		len(l.Trampolines), trampolineFees, finalCltvDelta)
+
+	l.Target = l.Trampolines[0].Node
+	l.Amount = amt
+	l.FeeLimit -= trampolineFees
+	l.FinalCLTVDelta = uint16(finalCltvDelta)
+	l.PaymentAddr = fn.Some(trampolineAddr)
+	l.DestFeatures = l.Trampolines[0].Features
+	l.TrampolineOnion = b.Bytes()
+
+	return nil
+}
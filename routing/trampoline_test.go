@@ -0,0 +1,203 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/flokiorg/flnd/fn"
+	switchhop "github.com/flokiorg/flnd/htlcswitch/hop"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/routing/route"
+	"github.com/flokiorg/go-flokicoin/crypto"
+	sphinx "github.com/flokiorg/lightning-onion"
+	"github.com/stretchr/testify/require"
+)
+
+// trampolineTestNode is a node of a trampoline route along with the onion
+// processor it peels its layer of a trampoline onion with.
+type trampolineTestNode struct {
+	pubKey    *crypto.PublicKey
+	vertex    route.Vertex
+	processor *switchhop.OnionProcessor
+}
+
+func newTrampolineTestNode(t *testing.T) *trampolineTestNode {
+	priv, err := crypto.NewPrivateKey()
+	require.NoError(t, err)
+
+	router := sphinx.NewRouter(
+		&sphinx.PrivKeyECDH{PrivKey: priv}, sphinx.NewMemoryReplayLog(),
+	)
+
+	return &trampolineTestNode{
+		pubKey:    priv.PubKey(),
+		vertex:    route.NewVertex(priv.PubKey()),
+		processor: switchhop.NewOnionProcessor(router),
+	}
+}
+
+// TestApplyTrampolines asserts that a payment relayed through trampoline
+// nodes is sent to the first trampoline node, paying the fees and CLTV deltas
+// of all of them, and that every trampoline node finds its instructions in
+// the trampoline onion.
+func TestApplyTrampolines(t *testing.T) {
+	t.Parallel()
+
+	const height = 100
+
+	var (
+		t1        = newTrampolineTestNode(t)
+		t2        = newTrampolineTestNode(t)
+		recipient = newTrampolineTestNode(t)
+
+		hash = lntypes.Hash{1, 2, 3}
+		addr = [32]byte{4, 5, 6}
+
+		trampolineFeatures = lnwire.NewFeatureVector(
+			lnwire.NewRawFeatureVector(
+				lnwire.TrampolineRoutingOptional,
+			), lnwire.Features,
+		)
+	)
+
+	newPayment := func(
+		recipientFeatures *lnwire.FeatureVector) *LightningPayment {
+
+		payment := &LightningPayment{
+			Target:         recipient.vertex,
+			Amount:         100_000,
+			FeeLimit:       10_000,
+			CltvLimit:      1_000,
+			FinalCLTVDelta: 40,
+			PaymentAddr:    fn.Some(addr),
+			DestFeatures:   recipientFeatures,
+			Trampolines: []TrampolineHop{
+				{
+					Node: t1.vertex,
+					Policy: lnwire.TrampolinePolicy{
+						FeeBaseMSat:               1_000,
+						FeeProportionalMillionths: 1_000,
+						CltvExpiryDelta:           100,
+					},
+					Features: trampolineFeatures,
+				},
+				{
+					Node: t2.vertex,
+					Policy: lnwire.TrampolinePolicy{
+						FeeBaseMSat:               500,
+						FeeProportionalMillionths: 2_000,
+						CltvExpiryDelta:           200,
+					},
+					Features: trampolineFeatures,
+				},
+			},
+		}
+		require.NoError(t, payment.SetPaymentHash(hash))
+
+		return payment
+	}
+
+	// assertOuterPayment asserts that the payment is sent to the first
+	// trampoline node, paying 700 msat to the second and 1_100 msat to
+	// the first trampoline node.
+	assertOuterPayment := func(payment *LightningPayment) {
+		require.Equal(t, t1.vertex, payment.Target)
+		require.EqualValues(t, 101_800, payment.Amount)
+		require.EqualValues(t, 8_200, payment.FeeLimit)
+		require.EqualValues(t, 343, payment.FinalCLTVDelta)
+		require.Equal(t, trampolineFeatures, payment.DestFeatures)
+		require.NotEqual(t, addr, payment.PaymentAddr.UnwrapOr(addr))
+	}
+
+	t.Run("legacy recipient", func(t *testing.T) {
+		t.Parallel()
+
+		payment := newPayment(nil)
+		require.NoError(t, payment.applyTrampolines(height))
+		assertOuterPayment(payment)
+
+		payload, err := t1.processor.DecodeTrampolineOnion(
+			payment.TrampolineOnion, hash[:],
+		)
+		require.NoError(t, err)
+		require.EqualValues(t, 100_700, payload.AmountToForward)
+		require.EqualValues(t, 343, payload.OutgoingCLTV)
+		require.Equal(t, t2.pubKey, payload.OutgoingNodeID)
+		require.Nil(t, payload.MPP)
+
+		// The last trampoline node pays the recipient as a regular
+		// payment, using the payment address of the invoice.
+		payload, err = t2.processor.DecodeTrampolineOnion(
+			payload.NextOnion, hash[:],
+		)
+		require.NoError(t, err)
+		require.EqualValues(t, 100_000, payload.AmountToForward)
+		require.EqualValues(t, 143, payload.OutgoingCLTV)
+		require.Equal(t, recipient.pubKey, payload.OutgoingNodeID)
+		require.Equal(t, addr, payload.MPP.PaymentAddr())
+		require.EqualValues(t, 100_000, payload.MPP.TotalMsat())
+		require.Nil(t, payload.NextOnion)
+	})
+
+	t.Run("trampoline recipient", func(t *testing.T) {
+		t.Parallel()
+
+		payment := newPayment(trampolineFeatures)
+		require.NoError(t, payment.applyTrampolines(height))
+		assertOuterPayment(payment)
+
+		payload, err := t1.processor.DecodeTrampolineOnion(
+			payment.TrampolineOnion, hash[:],
+		)
+		require.NoError(t, err)
+
+		payload, err = t2.processor.DecodeTrampolineOnion(
+			payload.NextOnion, hash[:],
+		)
+		require.NoError(t, err)
+		require.Equal(t, recipient.pubKey, payload.OutgoingNodeID)
+		require.Nil(t, payload.MPP)
+
+		// The recipient finds the innermost layer of the onion.
+		payload, err = recipient.processor.DecodeTrampolineOnion(
+			payload.NextOnion, hash[:],
+		)
+		require.NoError(t, err)
+		require.EqualValues(t, 100_000, payload.AmountToForward)
+		require.EqualValues(t, 143, payload.OutgoingCLTV)
+		require.Nil(t, payload.OutgoingNodeID)
+		require.Equal(t, addr, payload.MPP.PaymentAddr())
+	})
+
+	t.Run("fee limit", func(t *testing.T) {
+		t.Parallel()
+
+		payment := newPayment(nil)
+		payment.FeeLimit = 1_000
+
+		err := payment.applyTrampolines(height)
+		require.ErrorIs(t, err, ErrTrampolineFeeLimit)
+	})
+
+	t.Run("cltv limit", func(t *testing.T) {
+		t.Parallel()
+
+		payment := newPayment(nil)
+		payment.CltvLimit = 300
+
+		err := payment.applyTrampolines(height)
+		require.ErrorIs(t, err, ErrTrampolineCltvLimit)
+	})
+
+	t.Run("trampoline target", func(t *testing.T) {
+		t.Parallel()
+
+		payment := newPayment(nil)
+		payment.Target = t2.vertex
+
+		require.NoError(t, payment.applyTrampolines(height))
+		require.Equal(t, t2.vertex, payment.Target)
+		require.EqualValues(t, 100_000, payment.Amount)
+		require.Nil(t, payment.TrampolineOnion)
+	})
+}
@@ -29,6 +29,10 @@ type nodeEdgeUnifier struct {
 	// outChanRestr is an optional outgoing channel restriction for the
 	// local channel to use.
 	outChanRestr map[uint64]struct{}
+
+	// inChanRestr is an optional channel restriction for the connections
+	// into the toNode.
+	inChanRestr map[uint64]struct{}
 }
 
 // newNodeEdgeUnifier instantiates a new nodeEdgeUnifier object. Channel
@@ -66,6 +70,16 @@ func (u *nodeEdgeUnifier) addPolicy(fromNode route.Vertex,
 		}
 	}
 
+	// Skip channels if there is an incoming channel restriction.
+	if u.inChanRestr != nil {
+		if _, ok := u.inChanRestr[edge.ChannelID]; !ok {
+			log.Debugf("Skipped adding policy for restricted "+
+				"incoming edge %v", edge.ChannelID)
+
+			return
+		}
+	}
+
 	// Update the edgeUnifiers map.
 	unifier, ok := u.edgeUnifiers[fromNode]
 	if !ok {
@@ -56,6 +56,7 @@ import (
 	"github.com/flokiorg/flnd/lnwallet/chainfee"
 	"github.com/flokiorg/flnd/lnwallet/chancloser"
 	"github.com/flokiorg/flnd/lnwallet/chanfunding"
+	"github.com/flokiorg/flnd/lnwallet/txbatcher"
 	"github.com/flokiorg/flnd/lnwallet/types"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/macaroons"
@@ -485,6 +486,24 @@ func MainRPCServerPermissions() map[string][]bakery.Op {
 			Entity: "offchain",
 			Action: "write",
 		}},
+		"/lnrpc.Lightning/UpdateChannelParams": {{
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/SpliceIn": {{
+			Entity: "onchain",
+			Action: "write",
+		}, {
+			Entity: "offchain",
+			Action: "write",
+		}},
+		"/lnrpc.Lightning/SpliceOut": {{
+			Entity: "onchain",
+			Action: "write",
+		}, {
+			Entity: "offchain",
+			Action: "write",
+		}},
 		"/lnrpc.Lightning/ForwardingHistory": {{
 			Entity: "offchain",
 			Action: "read",
@@ -766,6 +785,25 @@ func (r *rpcServer) addDeps(ctx context.Context, s *server,
 		ShouldSetExpAccountability: func() bool {
 			return !s.cfg.ProtocolOptions.NoExpAccountability()
 		},
+		ForwardingLog:      s.fwdLog,
+		Rebalancer:         s.rebalanceMgr,
+		ProbabilityService: s.probabilityService,
+	}
+
+	// Offers can only be paid if we're able to fetch their invoices over
+	// onion messages.
+	if s.offersMgr != nil {
+		routerBackend.FetchOfferInvoice = s.offersMgr.FetchInvoice
+	}
+
+	// If trampoline nodes are configured, we'll relay our payments through
+	// them instead of finding the route to the recipient ourselves.
+	if len(s.cfg.Routing.Trampoline.Nodes) > 0 {
+		routerBackend.Trampolines = func() ([]routing.TrampolineHop,
+			error) {
+
+			return s.fetchTrampolineHops(ctx)
+		}
 	}
 
 	genInvoiceFeatures := func() *lnwire.FeatureVector {
@@ -797,7 +835,7 @@ func (r *rpcServer) addDeps(ctx context.Context, s *server,
 		genInvoiceFeatures, genAmpInvoiceFeatures,
 		s.getNodeAnnouncement, s.updateAndBroadcastSelfNode, parseAddr,
 		rpcsLog, s.aliasMgr, r.implCfg.AuxDataParser,
-		invoiceHtlcModifier,
+		invoiceHtlcModifier, s.offersMgr, s.fundingMgr.BumpFundingFee,
 	)
 	if err != nil {
 		return err
@@ -1166,6 +1204,70 @@ func (r *rpcServer) sendCoinsOnChain(paymentMap map[string]int64,
 	return &txHash, nil
 }
 
+// canBatchSend returns true if an on-chain send with the passed parameters can
+// be queued in the tx batcher instead of being published on its own. Sends
+// that pin down their fee rate, inputs, coin selection strategy or label need
+// a transaction of their own.
+func (r *rpcServer) canBatchSend(satPerByte int64, satPerVByte uint64,
+	numOutpoints int, strategy lnrpc.CoinSelectionStrategy,
+	label string) bool {
+
+	return r.server.txBatcher != nil && satPerByte == 0 &&
+		satPerVByte == 0 && numOutpoints == 0 &&
+		strategy == lnrpc.CoinSelectionStrategy_STRATEGY_USE_GLOBAL_CONFIG &&
+		label == ""
+}
+
+// sendCoinsBatched queues the payments in the tx batcher, to be confirmed
+// within targetConf blocks, and waits for the transaction paying them to be
+// published.
+func (r *rpcServer) sendCoinsBatched(ctx context.Context,
+	paymentMap map[string]int64, targetConf uint32,
+	minConfs int32) (*chainhash.Hash, error) {
+
+	outputs, err := addrPairsToOutputs(paymentMap, r.cfg.ActiveNetParams.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	_, bestHeight, err := r.server.cc.ChainIO.GetBestBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	id, resultChan, err := r.server.txBatcher.Queue(&txbatcher.Request{
+		Outputs:        outputs,
+		DeadlineHeight: bestHeight + int32(targetConf),
+		MinConfs:       minConfs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case result := <-resultChan:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		txHash := result.Tx.TxHash()
+		return &txHash, nil
+
+	case <-ctx.Done():
+		// Once the request is part of a transaction it can no longer be
+		// cancelled, the payment is then made regardless.
+		if err := r.server.txBatcher.Cancel(id); err != nil {
+			rpcsLog.Warnf("Unable to cancel batched send %d: %v",
+				id, err)
+		}
+
+		return nil, ctx.Err()
+
+	case <-r.quit:
+		return nil, ErrServerShuttingDown
+	}
+}
+
 // ListUnspent returns useful information about each unspent output owned by
 // the wallet, as reported by the underlying `ListUnspentWitness`; the
 // information returned is: outpoint, amount in loki, address, address
@@ -1563,6 +1665,18 @@ func (r *rpcServer) SendCoins(ctx context.Context,
 
 		sweepTXID := sweepTxPkg.SweepTx.TxHash()
 		txid = &sweepTXID
+	} else if r.canBatchSend(
+		in.SatPerByte, in.SatPerVbyte, //nolint:staticcheck
+		len(in.Outpoints), in.CoinSelectionStrategy, label,
+	) {
+
+		paymentMap := map[string]int64{targetAddr.String(): in.Amount}
+		txid, err = r.sendCoinsBatched(
+			ctx, paymentMap, targetConf, minConfs,
+		)
+		if err != nil {
+			return nil, err
+		}
 	} else {
 
 		// We'll now construct out payment map, and use the wallet's
@@ -1638,6 +1752,26 @@ func (r *rpcServer) SendMany(ctx context.Context,
 
 	var txid *chainhash.Hash
 
+	// If batching is enabled, the outputs are paid in the next batch
+	// transaction instead.
+	if r.canBatchSend(
+		in.SatPerByte, in.SatPerVbyte, 0, //nolint:staticcheck
+		in.CoinSelectionStrategy, label,
+	) {
+
+		txid, err = r.sendCoinsBatched(
+			ctx, in.AddrToAmount, targetConf, minConfs,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		rpcsLog.Infof("[sendmany] batched spend txid: %v",
+			txid.String())
+
+		return &lnrpc.SendManyResponse{Txid: txid.String()}, nil
+	}
+
 	// We'll attempt to send to the target set of outputs, ensuring that we
 	// synchronize with any other ongoing coin selection attempts which
 	// happen to also be concurrently executing.
@@ -2707,6 +2841,22 @@ func (r *rpcServer) BatchOpenChannel(ctx context.Context,
 	channelAbandoner := func(point *wire.OutPoint) error {
 		return r.abandonChan(point, uint32(bestHeight))
 	}
+	// If tx batching is enabled, queued wallet payments that can wait for
+	// the funding transaction to confirm are paid by it as well.
+	var claimOutputs func() fn.Option[*txbatcher.Claim]
+	if r.server.txBatcher != nil {
+		confTarget := in.TargetConf
+		if confTarget <= 0 {
+			confTarget = defaultNumBlocksEstimate
+		}
+
+		claimOutputs = func() fn.Option[*txbatcher.Claim] {
+			return r.server.txBatcher.ClaimOutputs(
+				bestHeight+confTarget, fn.None[chainutil.Amount](),
+			)
+		}
+	}
+
 	batcher := funding.NewBatcher(&funding.BatchConfig{
 		RequestParser:    requestParser,
 		ChannelAbandoner: channelAbandoner,
@@ -2715,6 +2865,7 @@ func (r *rpcServer) BatchOpenChannel(ctx context.Context,
 		Wallet:           r.server.cc.Wallet,
 		NetParams:        &r.server.cc.Wallet.Cfg.NetParams,
 		Quit:             r.quit,
+		ClaimOutputs:     claimOutputs,
 	})
 	rpcPoints, err := batcher.BatchFund(ctx, in)
 	if err != nil {
@@ -5536,11 +5687,12 @@ func (r *rpcServer) SubscribeChannelEvents(req *lnrpc.ChannelEventSubscription,
 					},
 				}
 
-			// Completely ignore ActiveLinkEvent and
-			// InactiveLinkEvent as this is explicitly not exposed
-			// to the RPC.
+			// Completely ignore ActiveLinkEvent,
+			// InactiveLinkEvent and ChannelParamsUpdateEvent as
+			// these are explicitly not exposed to the RPC.
 			case channelnotifier.ActiveLinkEvent,
-				channelnotifier.InactiveLinkEvent:
+				channelnotifier.InactiveLinkEvent,
+				channelnotifier.ChannelParamsUpdateEvent:
 
 				continue
 
@@ -7239,7 +7391,7 @@ func (r *rpcServer) FeeReport(ctx context.Context,
 		return nil, err
 	}
 
-	fwdEventLog := r.server.miscDB.ForwardingLog()
+	fwdEventLog := r.server.fwdLog
 
 	// computeFeeSum is a helper function that computes the total fees for
 	// a particular time slice described by a forwarding event query.
@@ -7472,6 +7624,309 @@ func (r *rpcServer) UpdateChannelPolicy(ctx context.Context,
 	}, nil
 }
 
+// UpdateChannelParams renegotiates the parameters and the commitment type of
+// an open channel with the channel peer using the dynamic commitments
+// protocol.
+func (r *rpcServer) UpdateChannelParams(ctx context.Context,
+	req *lnrpc.UpdateChannelParamsRequest) (
+	*lnrpc.UpdateChannelParamsResponse, error) {
+
+	if req.ChanPoint == nil {
+		return nil, status.Error(
+			codes.InvalidArgument, "chan_point must be set",
+		)
+	}
+
+	txid, err := lnrpc.GetChanPointFundingTxid(req.ChanPoint)
+	if err != nil {
+		return nil, err
+	}
+	chanPoint := wire.NewOutPoint(txid, req.ChanPoint.OutputIndex)
+
+	propose := lnwire.DynPropose{
+		ChanID: lnwire.NewChanIDFromOutPoint(*chanPoint),
+	}
+
+	if req.DustLimitSat != 0 {
+		dustLimit := chainutil.Amount(req.DustLimitSat)
+		propose.DustLimit = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType0](tlv.NewBigSizeT(dustLimit)),
+		)
+	}
+	if req.MaxValueInFlightMsat != 0 {
+		propose.MaxValueInFlight = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType2](
+				lnwire.MilliLoki(req.MaxValueInFlightMsat),
+			),
+		)
+	}
+	if req.MinHtlcMsat != 0 {
+		propose.HtlcMinimum = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType4](
+				lnwire.MilliLoki(req.MinHtlcMsat),
+			),
+		)
+	}
+	if req.ChanReserveSat != 0 {
+		propose.ChannelReserve = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType6](
+				tlv.NewBigSizeT(
+					chainutil.Amount(req.ChanReserveSat),
+				),
+			),
+		)
+	}
+	if req.CsvDelay != 0 {
+		if req.CsvDelay > math.MaxUint16 {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"csv_delay %d too large", req.CsvDelay)
+		}
+
+		propose.CsvDelay = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType8](
+				uint16(req.CsvDelay),
+			),
+		)
+	}
+	if req.MaxAcceptedHtlcs != 0 {
+		if req.MaxAcceptedHtlcs > math.MaxUint16 {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"max_accepted_htlcs %d too large",
+				req.MaxAcceptedHtlcs)
+		}
+
+		propose.MaxAcceptedHTLCs = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType10](
+				uint16(req.MaxAcceptedHtlcs),
+			),
+		)
+	}
+
+	var chanType fn.Option[lnwire.ChannelType]
+	switch req.CommitmentType {
+	case lnrpc.CommitmentType_UNKNOWN_COMMITMENT_TYPE:
+
+	case lnrpc.CommitmentType_LEGACY:
+		chanType = fn.Some(lnwire.ChannelType(
+			*lnwire.NewRawFeatureVector(),
+		))
+
+	case lnrpc.CommitmentType_STATIC_REMOTE_KEY:
+		chanType = fn.Some(lnwire.ChannelType(
+			*lnwire.NewRawFeatureVector(
+				lnwire.StaticRemoteKeyRequired,
+			),
+		))
+
+	case lnrpc.CommitmentType_ANCHORS:
+		chanType = fn.Some(lnwire.ChannelType(
+			*lnwire.NewRawFeatureVector(
+				lnwire.StaticRemoteKeyRequired,
+				lnwire.AnchorsZeroFeeHtlcTxRequired,
+			),
+		))
+
+	case lnrpc.CommitmentType_SIMPLE_TAPROOT:
+		chanType = fn.Some(lnwire.ChannelType(
+			*lnwire.NewRawFeatureVector(
+				lnwire.SimpleTaprootChannelsRequiredStaging,
+			),
+		))
+
+	case lnrpc.CommitmentType_SIMPLE_TAPROOT_FINAL:
+		chanType = fn.Some(lnwire.ChannelType(
+			*lnwire.NewRawFeatureVector(
+				lnwire.SimpleTaprootChannelsRequiredFinal,
+			),
+		))
+
+	default:
+		return nil, status.Errorf(codes.InvalidArgument,
+			"commitment type %v can't be reached by a parameter "+
+				"update", req.CommitmentType)
+	}
+	chanType.WhenSome(func(t lnwire.ChannelType) {
+		propose.ChannelType = tlv.SomeRecordT(
+			tlv.NewRecordT[tlv.TlvType12](t),
+		)
+	})
+
+	// An upgrade to taproot moves the channel to a new funding output
+	// with a kickoff transaction, which we pay for at the default fee
+	// rate.
+	if req.CommitmentType == lnrpc.CommitmentType_SIMPLE_TAPROOT ||
+		req.CommitmentType == lnrpc.CommitmentType_SIMPLE_TAPROOT_FINAL {
+
+		feeRate, err := lnrpc.CalculateFeeRate(
+			0, 0, maybeUseDefaultConf(0, 0, 0),
+			r.server.cc.FeeEstimator,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		propose.KickoffFeerate = tlv.SomeRecordT(
+			tlv.NewPrimitiveRecord[tlv.TlvType18](uint32(feeRate)),
+		)
+	}
+
+	link, err := r.server.htlcSwitch.GetLink(propose.ChanID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find active link for "+
+			"channel %v: %w", chanPoint, err)
+	}
+
+	rpcsLog.Infof("[updatechanparams] updating parameters of "+
+		"ChannelPoint(%v)", chanPoint)
+
+	select {
+	case err := <-link.UpdateChannelParams(propose):
+		if err != nil {
+			return nil, err
+		}
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case <-r.quit:
+		return nil, fmt.Errorf("server shutting down")
+	}
+
+	return &lnrpc.UpdateChannelParamsResponse{}, nil
+}
+
+// SpliceIn adds funds from the internal wallet to an open channel by splicing
+// them into its funding output.
+func (r *rpcServer) SpliceIn(ctx context.Context,
+	req *lnrpc.SpliceInRequest) (*lnrpc.SpliceResponse, error) {
+
+	if req.Amount <= 0 {
+		return nil, status.Error(
+			codes.InvalidArgument, "amount must be positive",
+		)
+	}
+
+	minConfs := req.MinConfs
+	switch {
+	case minConfs < 0:
+		return nil, status.Error(
+			codes.InvalidArgument, "min_confs must not be negative",
+		)
+
+	case minConfs == 0:
+		minConfs = 1
+	}
+
+	feeRate, err := lnrpc.CalculateFeeRate(
+		0, req.SatPerVbyte,
+		maybeUseDefaultConf(0, req.SatPerVbyte, uint32(req.TargetConf)),
+		r.server.cc.FeeEstimator,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.spliceChannel(ctx, req.ChanPoint, htlcswitch.SpliceRequest{
+		Amount:   chainutil.Amount(req.Amount),
+		FeeRate:  feeRate,
+		MinConfs: minConfs,
+	})
+}
+
+// SpliceOut removes funds from an open channel by splicing them out of its
+// funding output, either to the internal wallet or to an external address.
+func (r *rpcServer) SpliceOut(ctx context.Context,
+	req *lnrpc.SpliceOutRequest) (*lnrpc.SpliceResponse, error) {
+
+	if req.Amount <= 0 {
+		return nil, status.Error(
+			codes.InvalidArgument, "amount must be positive",
+		)
+	}
+
+	feeRate, err := lnrpc.CalculateFeeRate(
+		0, req.SatPerVbyte,
+		maybeUseDefaultConf(0, req.SatPerVbyte, uint32(req.TargetConf)),
+		r.server.cc.FeeEstimator,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// If no address is given, the wallet creates one when funding the
+	// splice.
+	var outputScript []byte
+	if req.Addr != "" {
+		params := r.cfg.ActiveNetParams.Params
+		addr, err := chainutil.DecodeAddress(req.Addr, params)
+		if err != nil {
+			return nil, err
+		}
+		if !addr.IsForNet(params) {
+			return nil, fmt.Errorf("address: %v is not valid for "+
+				"this network: %v", addr, params.Name)
+		}
+
+		outputScript, err = txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return r.spliceChannel(ctx, req.ChanPoint, htlcswitch.SpliceRequest{
+		Amount:       -chainutil.Amount(req.Amount),
+		OutputScript: outputScript,
+		FeeRate:      feeRate,
+	})
+}
+
+// spliceChannel executes the passed splice on the link of the given channel.
+func (r *rpcServer) spliceChannel(ctx context.Context,
+	rpcChanPoint *lnrpc.ChannelPoint,
+	spliceReq htlcswitch.SpliceRequest) (*lnrpc.SpliceResponse, error) {
+
+	if rpcChanPoint == nil {
+		return nil, status.Error(
+			codes.InvalidArgument, "chan_point must be set",
+		)
+	}
+
+	txid, err := lnrpc.GetChanPointFundingTxid(rpcChanPoint)
+	if err != nil {
+		return nil, err
+	}
+	chanPoint := wire.NewOutPoint(txid, rpcChanPoint.OutputIndex)
+
+	link, err := r.server.htlcSwitch.GetLink(
+		lnwire.NewChanIDFromOutPoint(*chanPoint),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find active link for "+
+			"channel %v: %w", chanPoint, err)
+	}
+
+	rpcsLog.Infof("[splice] splicing %v into ChannelPoint(%v), sat/kw=%v",
+		spliceReq.Amount, chanPoint, int64(spliceReq.FeeRate))
+
+	select {
+	case result := <-link.Splice(spliceReq):
+		spliceTxid, err := result.Unpack()
+		if err != nil {
+			return nil, err
+		}
+
+		return &lnrpc.SpliceResponse{
+			Txid: spliceTxid.String(),
+		}, nil
+
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case <-r.quit:
+		return nil, fmt.Errorf("server shutting down")
+	}
+}
+
 // ForwardingHistory allows the caller to query the htlcswitch for a record of
 // all HTLC's forwarded within the target time range, and integer offset within
 // that time range. If no time-range is specified, then the first chunk of the
@@ -7535,7 +7990,7 @@ func (r *rpcServer) ForwardingHistory(ctx context.Context,
 		IncomingChanIDs: incomingChanIDs,
 		OutgoingChanIDs: outgoingChanIDs,
 	}
-	timeSlice, err := r.server.miscDB.ForwardingLog().Query(eventQuery)
+	timeSlice, err := r.server.fwdLog.Query(eventQuery)
 	if err != nil {
 		return nil, fmt.Errorf("unable to query forwarding log: %w",
 			err)
@@ -7977,11 +8432,14 @@ func (r *rpcServer) SubscribeChannelBackups(req *lnrpc.ChannelBackupSubscription
 			// way as the synchronous call and the file based
 			// backup, we also include pending channels in the
 			// update.
+			// Dynamic commitments change the channel type stored
+			// in the SCB, so those are included as well.
 			case channelnotifier.PendingOpenChannelEvent,
 				channelnotifier.OpenChannelEvent,
 				channelnotifier.ClosedChannelEvent,
 				channelnotifier.FullyResolvedChannelEvent,
-				channelnotifier.FundingTimeoutEvent:
+				channelnotifier.FundingTimeoutEvent,
+				channelnotifier.ChannelParamsUpdateEvent:
 
 			default:
 				continue
@@ -11,6 +11,8 @@ import (
 	"math/big"
 	prand "math/rand"
 	"net"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,6 +25,7 @@ import (
 
 	"github.com/flokiorg/flnd/aliasmgr"
 	"github.com/flokiorg/flnd/autopilot"
+	"github.com/flokiorg/flnd/bolt12"
 	"github.com/flokiorg/flnd/brontide"
 	"github.com/flokiorg/flnd/chainio"
 	"github.com/flokiorg/flnd/chainreg"
@@ -48,6 +51,7 @@ import (
 	"github.com/flokiorg/flnd/input"
 	"github.com/flokiorg/flnd/invoices"
 	"github.com/flokiorg/flnd/keychain"
+	"github.com/flokiorg/flnd/liquidityads"
 	"github.com/flokiorg/flnd/lncfg"
 	"github.com/flokiorg/flnd/lnencrypt"
 	"github.com/flokiorg/flnd/lnpeer"
@@ -59,17 +63,23 @@ import (
 	chcl "github.com/flokiorg/flnd/lnwallet/chancloser"
 	"github.com/flokiorg/flnd/lnwallet/chanfunding"
 	"github.com/flokiorg/flnd/lnwallet/rpcwallet"
+	"github.com/flokiorg/flnd/lnwallet/txbatcher"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/nat"
 	"github.com/flokiorg/flnd/netann"
+	"github.com/flokiorg/flnd/offers"
 	"github.com/flokiorg/flnd/onionmessage"
 	paymentsdb "github.com/flokiorg/flnd/payments/db"
 	"github.com/flokiorg/flnd/peer"
 	"github.com/flokiorg/flnd/peernotifier"
+	"github.com/flokiorg/flnd/peerstorage"
 	"github.com/flokiorg/flnd/pool"
 	"github.com/flokiorg/flnd/queue"
 	"github.com/flokiorg/flnd/routing"
 	"github.com/flokiorg/flnd/routing/localchans"
+	"github.com/flokiorg/flnd/routing/blindedpath"
+	"github.com/flokiorg/flnd/routing/probe"
+	"github.com/flokiorg/flnd/routing/rebalance"
 	"github.com/flokiorg/flnd/routing/route"
 	"github.com/flokiorg/flnd/subscribe"
 	"github.com/flokiorg/flnd/sweep"
@@ -80,11 +90,13 @@ import (
 	"github.com/flokiorg/flnd/watchtower/wtclient"
 	"github.com/flokiorg/flnd/watchtower/wtpolicy"
 	"github.com/flokiorg/flnd/watchtower/wtserver"
+	"github.com/flokiorg/flnd/zpay32"
 	"github.com/flokiorg/go-flokicoin/chaincfg"
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/connmgr"
 	"github.com/flokiorg/go-flokicoin/crypto/ecdsa"
+	"github.com/flokiorg/go-flokicoin/crypto/schnorr"
 	flog "github.com/flokiorg/go-flokicoin/log/v2"
 	"github.com/flokiorg/go-flokicoin/txscript"
 	"github.com/flokiorg/go-flokicoin/wire"
@@ -342,8 +354,14 @@ type server struct {
 	// payments.
 	paymentsDB paymentsdb.DB
 
+	// fwdLog is the log of all the HTLCs that we've forwarded.
+	fwdLog channeldb.ForwardingLogStore
+
 	aliasMgr *aliasmgr.Manager
 
+	// leaseStore persists the inbound liquidity leases we bought or sold.
+	leaseStore *liquidityads.Store
+
 	htlcSwitch *htlcswitch.Switch
 
 	interceptableSwitch *htlcswitch.InterceptableSwitch
@@ -365,6 +383,10 @@ type server struct {
 	missionController *routing.MissionController
 	defaultMC         *routing.MissionControl
 
+	// probabilityService connects the external probability estimator to
+	// the service that is registered over RPC.
+	probabilityService *routing.ProbabilityService
+
 	graphBuilder *graph.Builder
 
 	chanRouter *routing.ChannelRouter
@@ -418,6 +440,14 @@ type server struct {
 	// channelNotifier to be notified of newly opened and closed channels.
 	chanSubSwapper *chanbackup.SubSwapper
 
+	// peerStorage hands our encrypted channel backups to peers and stores
+	// theirs in return. It's nil if peer storage isn't enabled.
+	peerStorage *peerstorage.Manager
+
+	// trampolineRelay relays the payments we receive as a trampoline
+	// node. It's nil if trampoline routing isn't enabled.
+	trampolineRelay *htlcswitch.TrampolineRelay
+
 	// chanEventStore tracks the behaviour of channels and their remote peers to
 	// provide insights into their health and performance.
 	chanEventStore *chanfitness.ChannelEventStore
@@ -448,9 +478,26 @@ type server struct {
 		*onionmessage.Request, *onionmessage.Response,
 	]
 
+	// offersMgr answers BOLT 12 invoice requests for the offers we have
+	// created and fetches the invoices of the offers we pay. It is nil if
+	// onion messaging is disabled.
+	offersMgr *offers.Manager
+
+	// rebalanceMgr moves liquidity between our channels by paying
+	// ourselves along circular routes.
+	rebalanceMgr *rebalance.Manager
+
+	// prober warms up mission control by probing the network. It is nil
+	// if probing isn't active.
+	prober *probe.Prober
+
 	// txPublisher is a publisher with fee-bumping capability.
 	txPublisher *sweep.TxPublisher
 
+	// txBatcher batches on-chain sends, batch channel opens and sweeps into
+	// shared transactions. It is nil if tx batching is disabled.
+	txBatcher *txbatcher.Batcher
+
 	// blockbeatDispatcher is a block dispatcher that notifies subscribers
 	// of new blocks.
 	blockbeatDispatcher *chainio.BlockbeatDispatcher
@@ -751,6 +798,12 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 		NoExperimentalAccountability: cfg.ProtocolOptions.NoExpAccountability(),
 		NoQuiescence:                 cfg.ProtocolOptions.NoQuiescence(),
 		NoRbfCoopClose:               !cfg.ProtocolOptions.RbfCoopClose,
+		NoDualFunding:                !cfg.ProtocolOptions.DualFunding,
+		NoSplicing:                   !cfg.ProtocolOptions.Splicing,
+		NoDynamicCommitments:         !cfg.ProtocolOptions.DynamicCommitments,
+		NoGossipV2:                   !cfg.ProtocolOptions.GossipV2,
+		NoPeerStorage:                !cfg.ProtocolOptions.PeerStorage,
+		NoTrampolineRouting:          !cfg.ProtocolOptions.TrampolineRouting,
 	})
 	if err != nil {
 		return nil, err
@@ -770,18 +823,19 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 	}
 
 	addrSource := channeldb.NewMultiAddrSource(dbs.ChanStateDB, dbs.GraphDB)
-	chanStateDB := dbs.ChanStateDB.ChannelStateDB()
+	chanStateDB := dbs.ChanStateStore
 
 	s := &server{
 		cfg:            cfg,
 		implCfg:        implCfg,
 		graphDB:        dbs.GraphDB,
 		chanStateDB:    chanStateDB,
-		linkNodeDB:     chanStateDB.LinkNodeDB(),
+		linkNodeDB:     dbs.ChanStateDB.ChannelStateDB().LinkNodeDB(),
 		addrSource:     addrSource,
 		miscDB:         dbs.ChanStateDB,
 		invoicesDB:     dbs.InvoiceDB,
 		paymentsDB:     dbs.PaymentsDB,
+		fwdLog:         dbs.ForwardingLog,
 		cc:             cc,
 		sigPool:        lnwallet.NewSigPool(cfg.Workers.Sig, cc.Signer),
 		writePool:      writePool,
@@ -870,6 +924,32 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 		return nil, err
 	}
 
+	s.leaseStore, err = liquidityads.NewStore(dbs.ChanStateDB)
+	if err != nil {
+		return nil, err
+	}
+
+	var resourceMgr fn.Option[*htlcswitch.ResourceManager]
+	if rmCfg := cfg.ResourceManager; rmCfg.Active {
+		mgr, err := htlcswitch.NewResourceManager(
+			&htlcswitch.ResourceManagerConfig{
+				DB:                      dbs.ChanStateDB,
+				FetchClosedChannels:     s.chanStateDB.FetchClosedChannels,
+				Clock:                   clock.NewDefaultClock(),
+				RevenueWindow:           rmCfg.RevenueWindow,
+				ReputationMultiplier:    rmCfg.ReputationMultiplier,
+				ResolutionPeriod:        rmCfg.ResolutionPeriod,
+				BlockTime:               cfg.ActiveNetParams.TargetTimePerBlock,
+				GeneralBucketPercent:    rmCfg.GeneralBucketPercent,
+				CongestionBucketPercent: rmCfg.CongestionBucketPercent,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		resourceMgr = fn.Some(mgr)
+	}
+
 	s.htlcSwitch, err = htlcswitch.New(htlcswitch.Config{
 		DB:                   dbs.ChanStateDB,
 		FetchAllOpenChannels: s.chanStateDB.FetchAllOpenChannels,
@@ -889,8 +969,8 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 
 			peer.HandleLocalCloseChanReqs(request)
 		},
-		FwdingLog:              dbs.ChanStateDB.ForwardingLog(),
-		SwitchPackager:         channeldb.NewSwitchPackager(),
+		FwdingLog:              dbs.ForwardingLog,
+		FwdPkgStore:            s.chanStateDB,
 		ExtractErrorEncrypter:  s.sphinx.ExtractErrorEncrypter,
 		FetchLastChannelUpdate: s.fetchLastChanUpdate(),
 		Notifier:               s.cc.ChainNotifier,
@@ -905,6 +985,7 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 		MaxFeeExposure:         thresholdMSats,
 		SignAliasUpdate:        s.signAliasUpdate,
 		IsAlias:                aliasmgr.IsAlias,
+		ResourceManager:        resourceMgr,
 	}, uint32(currentHeight))
 	if err != nil {
 		return nil, err
@@ -937,6 +1018,7 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 		MessageSigner:            s.nodeSigner,
 		IsChannelActive:          s.htlcSwitch.HasActiveLink,
 		ApplyChannelUpdate:       s.applyChannelUpdate,
+		BestBlockHeight:          s.cc.BestBlockTracker.BestHeight,
 		DB:                       s.chanStateDB,
 		Graph:                    dbs.GraphDB,
 	}
@@ -1004,6 +1086,10 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 	// servers, the mission control instance itself can be moved there too.
 	routingConfig := routerrpc.GetRoutingConfig(cfg.SubRPCServers.RouterRPC)
 
+	// The probability service outlives the estimators, which are replaced
+	// whenever the mission control config is updated.
+	s.probabilityService = routing.NewProbabilityService()
+
 	// We only initialize a probability estimator if there's no custom one.
 	var estimator routing.Estimator
 	if cfg.Estimator != nil {
@@ -1043,6 +1129,30 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 				return nil, err
 			}
 
+		case routing.ExternalEstimatorName:
+			// The apriori config is used for the fallback.
+			aCfg := routingConfig.AprioriConfig
+			fallbackConfig := routing.AprioriConfig{
+				AprioriHopProbability: aCfg.HopProbability,
+				PenaltyHalfLife:       aCfg.PenaltyHalfLife,
+				AprioriWeight:         aCfg.Weight,
+				CapacityFraction:      aCfg.CapacityFraction,
+			}
+
+			eCfg := routingConfig.ExternalConfig
+			externalConfig := routing.ExternalConfig{
+				ExternalTimeout:  eCfg.Timeout,
+				ExternalCacheTTL: eCfg.CacheTTL,
+				FallbackConfig:   fallbackConfig,
+			}
+
+			estimator, err = routing.NewExternalEstimator(
+				externalConfig, s.probabilityService,
+			)
+			if err != nil {
+				return nil, err
+			}
+
 		default:
 			return nil, fmt.Errorf("unknown estimator type %v",
 				routingConfig.ProbabilityEstimatorType)
@@ -1101,6 +1211,8 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 	s.controlTower = routing.NewControlTower(dbs.PaymentsDB)
 
 	strictPruning := cfg.Flokicoin.Node == "neutrino" ||
+		cfg.Flokicoin.Node == "electrum" ||
+		cfg.Flokicoin.Node == "esplora" ||
 		cfg.Routing.StrictZombiePruning
 
 	s.graphBuilder, err = graph.NewBuilder(&graph.Config{
@@ -1140,6 +1252,127 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 		return nil, fmt.Errorf("can't create router: %w", err)
 	}
 
+	if cfg.ProtocolOptions.TrampolineRouting {
+		s.trampolineRelay = htlcswitch.NewTrampolineRelay(
+			&htlcswitch.TrampolineRelayConfig{
+				Policy:                cfg.Routing.Trampoline.Policy(),
+				MinExpiryDelta:        cfg.Flokicoin.TimeLockDelta,
+				MppTimeout:            invoices.DefaultHtlcHoldDuration,
+				DecodeTrampolineOnion: s.sphinx.DecodeTrampolineOnion,
+				Registry:              s.invoices,
+				Payer: &trampolinePayer{
+					router: s.chanRouter,
+					tower:  s.controlTower,
+					quit:   s.quit,
+				},
+			},
+		)
+	}
+
+	// BOLT 12 invoice requests and invoices are exchanged over onion
+	// messages, so there is no point in answering them if onion messaging
+	// is disabled.
+	if !cfg.ProtocolOptions.NoOnionMessages() {
+		offerStore, err := offers.NewKVStore(dbs.ChanStateDB)
+		if err != nil {
+			return nil, err
+		}
+
+		s.offersMgr = offers.NewManager(&offers.Config{
+			NodeKey:   nodeKeyDesc.PubKey,
+			ChainHash: *cfg.ActiveNetParams.GenesisHash,
+			Store:     offerStore,
+			SignMessageSchnorr: func(msg, tag []byte) (*schnorr.Signature,
+				error) {
+
+				return cc.KeyRing.SignMessageSchnorr(
+					nodeKeyDesc.KeyLocator, msg, false, nil,
+					tag,
+				)
+			},
+			AddInvoice:              s.invoices.AddInvoice,
+			BuildBlindedPaths:       s.buildOfferBlindedPaths,
+			GenInvoiceFeatures:      s.genOfferInvoiceFeatures,
+			MinFinalCLTVExpiryDelta: cfg.Flokicoin.TimeLockDelta,
+			InvoiceExpiry:           bolt12.DefaultInvoiceExpiry,
+			SubscribeOnionMessages:  s.SubscribeOnionMessages,
+			FindPath: func(ctx context.Context,
+				dest route.Vertex) (onionmessage.OnionMessagePath,
+				error) {
+
+				return onionmessage.FindPath(
+					ctx, s.graphDB, nodePubKey, dest,
+					sphinx.NumMaxHops,
+				)
+			},
+			SendOnionMessage: s.SendOnionMessage,
+			DecryptBlindedHopData: sphinxOnionMsg.
+				DecryptBlindedHopData,
+			Clock: clock.NewDefaultClock(),
+		})
+	}
+
+	rebalanceStore, err := rebalance.NewKVStore(dbs.ChanStateDB)
+	if err != nil {
+		return nil, err
+	}
+
+	// The targets are only checked periodically if automatic rebalancing
+	// is active, otherwise rebalances are only made on request.
+	rebalanceCfg := cfg.Routing.Rebalance
+	var rebalanceInterval time.Duration
+	if rebalanceCfg.Active {
+		rebalanceInterval = rebalanceCfg.Interval
+	}
+
+	s.rebalanceMgr = rebalance.NewManager(&rebalance.Config{
+		SelfNode:          nodePubKey,
+		Store:             rebalanceStore,
+		ListChannels:      s.rebalanceChannels,
+		FindRoute:         s.chanRouter.FindRoute,
+		SendToRoute:       s.chanRouter.SendToRoute,
+		ProbabilitySource: s.defaultMC.GetProbability,
+		AddInvoice:        s.invoices.AddInvoice,
+		CancelInvoice:     s.invoices.CancelInvoice,
+		FinalCltvDelta:    uint16(cfg.Flokicoin.TimeLockDelta),
+		CltvLimit:         cfg.MaxOutgoingCltvExpiry,
+		Interval:          rebalanceInterval,
+		MaxFee:            chainutil.Amount(rebalanceCfg.MaxFee),
+		MaxFeeRate:        rebalanceCfg.MaxFeeRate,
+		DailyBudget:       chainutil.Amount(rebalanceCfg.DailyBudget),
+		MinAmount:         chainutil.Amount(rebalanceCfg.MinAmount),
+		MaxAmount:         chainutil.Amount(rebalanceCfg.MaxAmount),
+		Tolerance:         rebalanceCfg.Tolerance,
+		MaxAttempts:       rebalanceCfg.MaxAttempts,
+		Clock:             clock.NewDefaultClock(),
+	})
+
+	if probeCfg := cfg.Routing.Probe; probeCfg.Active {
+		destinations, err := probeCfg.DestinationVertices()
+		if err != nil {
+			return nil, err
+		}
+
+		s.prober = probe.NewProber(&probe.Config{
+			SelfNode:          nodePubKey,
+			Destinations:      destinations,
+			CentralNodes:      probeCfg.CentralNodes,
+			CentralityRefresh: probeCfg.CentralityRefresh,
+			TopCentralNodes:   s.topCentralNodes,
+			FindRoute:         s.chanRouter.FindRoute,
+			SendProbe:         s.chanRouter.SendProbe,
+			ProbabilitySource: s.defaultMC.GetProbability,
+			FinalCltvDelta:    uint16(cfg.Flokicoin.TimeLockDelta),
+			CltvLimit:         cfg.MaxOutgoingCltvExpiry,
+			Interval:          probeCfg.Interval,
+			MinAmount:         chainutil.Amount(probeCfg.MinAmount),
+			MaxAmount:         chainutil.Amount(probeCfg.MaxAmount),
+			InFlightBudget: chainutil.Amount(
+				probeCfg.InFlightBudget,
+			),
+		})
+	}
+
 	chanSeries := discovery.NewChanSeries(s.graphDB)
 	gossipMessageStore, err := discovery.NewMessageStore(dbs.ChanStateDB)
 	if err != nil {
@@ -1279,6 +1512,20 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 		AuxSweeper: s.implCfg.AuxSweeper,
 	})
 
+	var outputBatcher fn.Option[sweep.OutputBatcher]
+	if cfg.TxBatcher.Active {
+		s.txBatcher = txbatcher.New(&txbatcher.Config{
+			Wallet:                cc.Wallet,
+			Estimator:             cc.FeeEstimator,
+			Notifier:              cc.ChainNotifier,
+			Ticker:                ticker.New(cfg.TxBatcher.Interval),
+			UrgentDelta:           cfg.TxBatcher.UrgentDelta,
+			MaxFeeRate:            cfg.Sweeper.MaxFeeRate.FeePerKWeight(),
+			CoinSelectionStrategy: cc.Wallet.Cfg.CoinSelectionStrategy,
+		})
+		outputBatcher = fn.Some[sweep.OutputBatcher](s.txBatcher)
+	}
+
 	s.sweeper = sweep.New(&sweep.UtxoSweeperConfig{
 		FeeEstimator: cc.FeeEstimator,
 		GenSweepScript: newSweepPkScriptGen(
@@ -1294,6 +1541,7 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 		Aggregator:           aggregator,
 		Publisher:            s.txPublisher,
 		NoDeadlineConfTarget: cfg.Sweeper.NoDeadlineConfTarget,
+		OutputBatcher:        outputBatcher,
 	})
 
 	s.utxoNursery = contractcourt.NewUtxoNursery(&contractcourt.NurseryConfig{
@@ -1482,7 +1730,7 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 			},
 		)(s.implCfg.AuxChanCloser),
 		ChannelCloseConfs: cfg.Dev.ChannelCloseConfs(),
-	}, dbs.ChanStateDB)
+	}, dbs.ChanStateDB, s.chanStateDB)
 
 	// Select the configuration and funding parameters for Flokicoin.
 	chainCfg := cfg.Flokicoin
@@ -1567,6 +1815,12 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 			err)
 	}
 
+	// We only sell inbound liquidity to peers if configured to.
+	leaseRates := fn.None[lnwire.LeaseRates]()
+	if cfg.LiquidityAds.Active {
+		leaseRates = fn.Some(cfg.LiquidityAds.Rates())
+	}
+
 	//nolint:ll
 	s.fundingMgr, err = funding.NewFundingManager(funding.Config{
 		Dev:                devCfg,
@@ -1578,10 +1832,11 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 		UpdateLabel: func(hash chainhash.Hash, label string) error {
 			return cc.Wallet.LabelTransaction(hash, label, true)
 		},
-		Notifier:     cc.ChainNotifier,
-		ChannelDB:    s.chanStateDB,
-		FeeEstimator: cc.FeeEstimator,
-		SignMessage:  cc.MsgSigner.SignMessage,
+		Notifier:           cc.ChainNotifier,
+		ChannelDB:          s.chanStateDB,
+		FeeEstimator:       cc.FeeEstimator,
+		SignMessage:        cc.MsgSigner.SignMessage,
+		SignMessageSchnorr: s.nodeSigner.SignMessageSchnorr,
 		CurrentNodeAnnouncement: func() (lnwire.NodeAnnouncement1,
 			error) {
 
@@ -1698,6 +1953,10 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 		OpenChannelPredicate:          chanPredicate,
 		NotifyPendingOpenChannelEvent: s.notifyPendingOpenChannelPeerEvent,
 		NotifyFundingTimeout:          s.notifyFundingTimeoutPeerEvent,
+		NotifyFundingTxReplaced:       s.chainArb.NotifyFundingTxReplaced,
+		LeaseRates:                    leaseRates,
+		MaxLeaseAmount:                chainutil.Amount(cfg.LiquidityAds.MaxLeaseAmount),
+		RecordLease:                   s.leaseStore.AddLease,
 		EnableUpfrontShutdown:         cfg.EnableUpfrontShutdown,
 		MaxAnchorsCommitFeeRate: chainfee.SatPerKVByte(
 			s.cfg.MaxCommitFeeRateAnchors * 1000).FeePerKWeight(),
@@ -1720,9 +1979,43 @@ func newServer(ctx context.Context, cfg *Config, listenAddrs []net.Addr,
 		chanNotifier: s.channelNotifier,
 		addrs:        s.addrSource,
 	}
-	backupFile := chanbackup.NewMultiFile(
+	var backupFile chanbackup.Swapper = chanbackup.NewMultiFile(
 		cfg.BackupFilePath, cfg.NoBackupArchive,
 	)
+
+	// If peer storage is enabled, every backup we persist is handed to
+	// our peers as well.
+	if cfg.ProtocolOptions.PeerStorage {
+		peerStorageStore, err := peerstorage.NewStore(dbs.ChanStateDB)
+		if err != nil {
+			return nil, err
+		}
+
+		// While we're restoring from seed, the backups our peers
+		// return to us are used to recover any channels that weren't
+		// part of the supplied backups.
+		restoreChannels := fn.None[func([]chanbackup.Single) error]()
+		if cc.Cfg.WalletUnlockParams.RecoveryWindow > 0 {
+			restoreChannels = fn.Some(s.restorePeerStorageBackups)
+		}
+
+		hasOpenChannel := func(peer *crypto.PublicKey) (bool, error) {
+			channels, err := s.chanStateDB.FetchOpenChannels(peer)
+			if err != nil {
+				return false, err
+			}
+
+			return len(channels) > 0, nil
+		}
+
+		s.peerStorage = peerstorage.NewManager(&peerstorage.Config{
+			Store:           peerStorageStore,
+			KeyRing:         s.cc.KeyRing,
+			HasOpenChannel:  hasOpenChannel,
+			RestoreChannels: restoreChannels,
+		})
+		backupFile = s.peerStorage.WrapSwapper(backupFile)
+	}
 	startingChans, err := chanbackup.FetchStaticChanBackups(
 		ctx, s.chanStateDB, s.addrSource,
 	)
@@ -1964,6 +2257,22 @@ func (s *server) UpdateRoutingConfig(cfg *routing.MissionControlConfig) {
 		targetCfg.Scale = int64(c.BimodalScaleMsat)
 		targetCfg.NodeWeight = c.BimodalNodeWeight
 		targetCfg.DecayTime = c.BimodalDecayTime
+
+	case routing.ExternalConfig:
+		routerCfg.ProbabilityEstimatorType =
+			routing.ExternalEstimatorName
+
+		targetCfg := routerCfg.ExternalConfig
+		targetCfg.Timeout = c.ExternalTimeout
+		targetCfg.CacheTTL = c.ExternalCacheTTL
+
+		// The fallback is configured by the apriori config.
+		fallback := c.FallbackConfig
+		aprioriCfg := routerCfg.AprioriConfig
+		aprioriCfg.PenaltyHalfLife = fallback.PenaltyHalfLife
+		aprioriCfg.Weight = fallback.AprioriWeight
+		aprioriCfg.CapacityFraction = fallback.CapacityFraction
+		aprioriCfg.HopProbability = fallback.AprioriHopProbability
 	}
 
 	routerCfg.MaxMcHistory = cfg.MaxMcHistory
@@ -2346,6 +2655,14 @@ func (s *server) Start(ctx context.Context) error {
 			return
 		}
 
+		if s.txBatcher != nil {
+			cleanup = cleanup.add(s.txBatcher.Stop)
+			if err := s.txBatcher.Start(); err != nil {
+				startErr = err
+				return
+			}
+		}
+
 		cleanup = cleanup.add(s.sweeper.Stop)
 		if err := s.sweeper.Start(beat); err != nil {
 			startErr = err
@@ -2414,6 +2731,15 @@ func (s *server) Start(ctx context.Context) error {
 			startErr = err
 			return
 		}
+
+		if s.trampolineRelay != nil {
+			cleanup = cleanup.add(s.trampolineRelay.Stop)
+			if err := s.trampolineRelay.Start(); err != nil {
+				startErr = err
+				return
+			}
+		}
+
 		// The authGossiper depends on the chanRouter and therefore
 		// should be started after it.
 		cleanup = cleanup.add(s.authGossiper.Stop)
@@ -2459,6 +2785,28 @@ func (s *server) Start(ctx context.Context) error {
 				DefaultOnionActorOpts()
 		}
 
+		if s.offersMgr != nil {
+			cleanup = cleanup.add(s.offersMgr.Stop)
+			if err := s.offersMgr.Start(); err != nil {
+				startErr = err
+				return
+			}
+		}
+
+		cleanup = cleanup.add(s.rebalanceMgr.Stop)
+		if err := s.rebalanceMgr.Start(); err != nil {
+			startErr = err
+			return
+		}
+
+		if s.prober != nil {
+			cleanup = cleanup.add(s.prober.Stop)
+			if err := s.prober.Start(); err != nil {
+				startErr = err
+				return
+			}
+		}
+
 		cleanup = cleanup.add(s.chanStatusMgr.Stop)
 		if err := s.chanStatusMgr.Start(); err != nil {
 			startErr = err
@@ -2750,6 +3098,12 @@ func (s *server) Stop() error {
 			srvrLog.Warnf("failed to stop htlc invoices "+
 				"modifier: %v", err)
 		}
+		if s.trampolineRelay != nil {
+			if err := s.trampolineRelay.Stop(); err != nil {
+				srvrLog.Warnf("failed to stop trampoline "+
+					"relay: %v", err)
+			}
+		}
 		if err := s.chanRouter.Stop(); err != nil {
 			srvrLog.Warnf("failed to stop chanRouter: %v", err)
 		}
@@ -2781,6 +3135,12 @@ func (s *server) Stop() error {
 		if err := s.txPublisher.Stop(); err != nil {
 			srvrLog.Warnf("failed to stop txPublisher: %v", err)
 		}
+		if s.txBatcher != nil {
+			if err := s.txBatcher.Stop(); err != nil {
+				srvrLog.Warnf("failed to stop txBatcher: %v",
+					err)
+			}
+		}
 		if err := s.channelNotifier.Stop(); err != nil {
 			srvrLog.Warnf("failed to stop channelNotifier: %v", err)
 		}
@@ -2810,6 +3170,12 @@ func (s *server) Stop() error {
 		if err := s.chanSubSwapper.Stop(); err != nil {
 			srvrLog.Warnf("failed to stop chanSubSwapper: %v", err)
 		}
+		if s.peerStorage != nil {
+			if err := s.peerStorage.Stop(); err != nil {
+				srvrLog.Warnf("failed to stop peer storage: %v",
+					err)
+			}
+		}
 		if err := s.cc.ChainNotifier.Stop(); err != nil {
 			srvrLog.Warnf("Unable to stop ChainNotifier: %v", err)
 		}
@@ -2821,6 +3187,21 @@ func (s *server) Stop() error {
 			srvrLog.Warnf("Unable to stop ChannelEventStore: %v",
 				err)
 		}
+		if s.offersMgr != nil {
+			if err := s.offersMgr.Stop(); err != nil {
+				srvrLog.Warnf("Unable to stop offers manager: "+
+					"%v", err)
+			}
+		}
+		if err := s.rebalanceMgr.Stop(); err != nil {
+			srvrLog.Warnf("Unable to stop rebalance manager: %v",
+				err)
+		}
+		if s.prober != nil {
+			if err := s.prober.Stop(); err != nil {
+				srvrLog.Warnf("Unable to stop prober: %v", err)
+			}
+		}
 		s.missionController.StopStoreTickers()
 
 		// Disconnect from each active peers to ensure that
@@ -4495,6 +4876,18 @@ func (s *server) peerConnected(conn net.Conn, connReq *connmgr.ConnReq,
 	thresholdSats := chainutil.Amount(s.cfg.MaxFeeExposure)
 	thresholdMSats := lnwire.NewMSatFromLokis(thresholdSats)
 
+	var peerStorage fn.Option[peerstorage.Controller]
+	if s.peerStorage != nil {
+		peerStorage = fn.Some[peerstorage.Controller](s.peerStorage)
+	}
+
+	var trampolineForwarder fn.Option[htlcswitch.TrampolineForwarder]
+	if s.trampolineRelay != nil {
+		trampolineForwarder = fn.Some[htlcswitch.TrampolineForwarder](
+			s.trampolineRelay,
+		)
+	}
+
 	// Now that we've established a connection, create a peer, and it to the
 	// set of currently active peers. Configure the peer with the incoming
 	// and outgoing broadcast deltas to prevent htlcs from being accepted or
@@ -4556,6 +4949,7 @@ func (s *server) peerConnected(conn net.Conn, connReq *connmgr.ConnReq,
 		FetchLastChanUpdate: s.fetchLastChanUpdate(),
 
 		FundingManager: s.fundingMgr,
+		PeerStorage:    peerStorage,
 
 		Hodl:                    s.cfg.Hodl,
 		UnsafeReplay:            s.cfg.UnsafeReplay,
@@ -4574,6 +4968,7 @@ func (s *server) peerConnected(conn net.Conn, connReq *connmgr.ConnReq,
 		DisallowRouteBlinding:  s.cfg.ProtocolOptions.NoRouteBlinding(),
 		DisallowQuiescence:     s.cfg.ProtocolOptions.NoQuiescence(),
 		QuiescenceTimeout:      s.cfg.Htlcswitch.QuiescenceTimeout,
+		MaxLocalCSVDelay:       s.cfg.Flokicoin.MaxLocalDelay,
 		MaxFeeExposure:         thresholdMSats,
 		Quit:                   s.quit,
 		AuxLeafStore:           s.implCfg.AuxLeafStore,
@@ -4583,6 +4978,7 @@ func (s *server) peerConnected(conn net.Conn, connReq *connmgr.ConnReq,
 		AuxResolver:            s.implCfg.AuxContractResolver,
 		AuxTrafficShaper:       s.implCfg.TrafficShaper,
 		AuxChannelNegotiator:   s.implCfg.AuxChannelNegotiator,
+		TrampolineForwarder:    trampolineForwarder,
 		ShouldFwdExpAccountability: func() bool {
 			return !s.cfg.ProtocolOptions.NoExpAccountability()
 		},
@@ -4703,6 +5099,12 @@ func (s *server) peerInitializer(p *peer.Brontide) {
 	// was successful, and to begin watching the peer's wait group.
 	close(ready)
 
+	// Now that we know the features of the peer, we'll exchange our
+	// backup blobs with it.
+	if s.peerStorage != nil {
+		s.peerStorage.PeerOnline(p)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -5094,6 +5496,10 @@ func (s *server) removePeerUnsafe(ctx context.Context, p *peer.Brontide) {
 		copy(pubKey[:], pubSer)
 
 		s.peerNotifier.NotifyPeerOffline(pubKey)
+
+		if s.peerStorage != nil {
+			s.peerStorage.PeerOffline(pubKey)
+		}
 	}()
 }
 
@@ -5392,7 +5798,7 @@ func (s *server) fetchLastChanUpdate() func(lnwire.ShortChannelID) (
 // applyChannelUpdate applies the channel update to the different sub-systems of
 // the server. The useAlias boolean denotes whether or not to send an alias in
 // place of the real SCID.
-func (s *server) applyChannelUpdate(update *lnwire.ChannelUpdate1,
+func (s *server) applyChannelUpdate(update lnwire.ChannelUpdate,
 	op *wire.OutPoint, useAlias bool) error {
 
 	var (
@@ -5484,6 +5890,146 @@ func (s *server) SendOnionMessage(ctx context.Context, peerPub [33]byte,
 	return peer.SendMessageLazy(true, msg)
 }
 
+// buildOfferBlindedPaths builds the blinded payment paths of an invoice we
+// create in response to a BOLT 12 invoice request. It uses the same routing
+// restrictions and probing buffers as blinded BOLT 11 invoices.
+func (s *server) buildOfferBlindedPaths(amt lnwire.MilliLoki, pathID []byte,
+	expiry time.Duration) ([]*zpay32.BlindedPaymentPath, error) {
+
+	bpConfig := s.cfg.Routing.BlindedPaths
+	restrictions := &routing.BlindedPathRestrictions{
+		MinDistanceFromIntroNode: bpConfig.MinNumRealHops,
+		NumHops:                  bpConfig.NumHops,
+		MaxNumPaths:              bpConfig.MaxNumPaths,
+		NodeOmissionSet:          fn.NewSet[route.Vertex](),
+	}
+
+	// Use the 10-min-per-block assumption to estimate the number of blocks
+	// until the invoice expires and double it, so the paths do not expire
+	// before the invoice does.
+	blocksUntilExpiry := uint32(expiry.Minutes()/10) * 2
+
+	cltvDelta := s.cfg.Flokicoin.TimeLockDelta
+	selfNode := route.NewVertex(s.identityECDH.PubKey())
+
+	return blindedpath.BuildBlindedPaymentPaths(
+		&blindedpath.BuildBlindedPathCfg{
+			FindRoutes: func(value lnwire.MilliLoki) ([]*route.Route,
+				error) {
+
+				return s.chanRouter.FindBlindedPaths(
+					selfNode, value,
+					s.defaultMC.GetProbability, restrictions,
+				)
+			},
+			FetchChannelEdgesByID: s.graphDB.FetchChannelEdgesByID,
+			FetchOurOpenChannels:  s.chanStateDB.FetchAllOpenChannels,
+			PathID:                pathID,
+			ValueMsat:             amt,
+			BestHeight:            s.cc.BestBlockTracker.BestHeight,
+			MinFinalCLTVExpiryDelta: cltvDelta +
+				uint32(routing.BlockPadding),
+			BlocksUntilExpiry: blocksUntilExpiry,
+			AddPolicyBuffer: func(p *blindedpath.BlindedHopPolicy) (
+				*blindedpath.BlindedHopPolicy, error) {
+
+				return blindedpath.AddPolicyBuffer(
+					p, bpConfig.PolicyIncreaseMultiplier,
+					bpConfig.PolicyDecreaseMultiplier,
+				)
+			},
+			MinNumHops: bpConfig.NumHops,
+			DefaultDummyHopPolicy: &blindedpath.BlindedHopPolicy{
+				CLTVExpiryDelta: uint16(cltvDelta),
+				FeeRate:         uint32(s.cfg.Flokicoin.FeeRate),
+				BaseFee:         s.cfg.Flokicoin.BaseFee,
+				MinHTLCMsat:     s.cfg.Flokicoin.MinHTLCIn,
+
+				// MaxHTLCMsat will be calculated on the fly by
+				// using the introduction node's channel's
+				// capacities.
+				MaxHTLCMsat: 0,
+			},
+		},
+	)
+}
+
+// rebalanceChannels returns the balances of our open channels for the
+// rebalancer.
+func (s *server) rebalanceChannels() ([]rebalance.ChannelBalance, error) {
+	channels, err := s.chanStateDB.FetchAllOpenChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]rebalance.ChannelBalance, 0, len(channels))
+	for _, channel := range channels {
+		scid := channel.ShortChanID()
+
+		// Only channels with a link that is eligible to forward can
+		// take part in a rebalance.
+		link, err := s.htlcSwitch.GetLinkByShortID(scid)
+		active := err == nil && link.EligibleToForward()
+
+		balances = append(balances, rebalance.ChannelBalance{
+			ChanID:   scid,
+			Capacity: channel.Capacity,
+			LocalBalance: channel.LocalCommitment.LocalBalance.
+				ToLokis(),
+			Active: active,
+		})
+	}
+
+	return balances, nil
+}
+
+// topCentralNodes returns the n nodes of the graph with the highest
+// betweenness centrality for the prober. Depending on the size of the graph,
+// this may take a few minutes.
+func (s *server) topCentralNodes(ctx context.Context,
+	n int) ([]route.Vertex, error) {
+
+	centralityMetric, err := autopilot.NewBetweennessCentralityMetric(
+		runtime.NumCPU(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	channelGraph := autopilot.ChannelGraphFromDatabase(s.graphDB)
+	if err := centralityMetric.Refresh(ctx, channelGraph); err != nil {
+		return nil, err
+	}
+
+	centrality := centralityMetric.GetMetric(false)
+	nodes := make([]route.Vertex, 0, len(centrality))
+	for nodeID := range centrality {
+		nodes = append(nodes, route.Vertex(nodeID))
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return centrality[autopilot.NodeID(nodes[i])] >
+			centrality[autopilot.NodeID(nodes[j])]
+	})
+
+	if len(nodes) > n {
+		nodes = nodes[:n]
+	}
+
+	return nodes, nil
+}
+
+// genOfferInvoiceFeatures returns the feature vector of the registry invoices
+// backing BOLT 12 invoices. As with blinded BOLT 11 invoices, the path ID in
+// the final hop's encrypted data stands in for the payment address.
+func (s *server) genOfferInvoiceFeatures() *lnwire.FeatureVector {
+	v := s.featureMgr.Get(feature.SetInvoice)
+	v.Unset(lnwire.PaymentAddrRequired)
+	v.Set(lnwire.PaymentAddrOptional)
+
+	return v
+}
+
 // SendToPeer sends an onion message to the peer identified by the given
 // compressed public key. This implements the onionmessage.PeerMessageSender
 // interface and is used by the onion peer actor when forwarding messages.
@@ -5770,15 +6316,41 @@ func (s *server) setSelfNode(ctx context.Context, nodePub route.Vertex,
 		return err
 	}
 
+	// If we sell inbound liquidity, we'll advertise our lease rates in
+	// the node announcement so buyers can find us.
+	var extraData lnwire.ExtraOpaqueData
+	if s.cfg.LiquidityAds.Active {
+		err := lnwire.SetLeaseRates(
+			&extraData, s.cfg.LiquidityAds.Rates(),
+		)
+		if err != nil {
+			return fmt.Errorf("unable to encode lease rates: %w",
+				err)
+		}
+	}
+
+	// If we relay trampoline payments, we'll advertise the fees and CLTV
+	// delta we charge for it so senders can route through us.
+	if s.cfg.ProtocolOptions.TrampolineRouting {
+		err := lnwire.SetTrampolinePolicy(
+			&extraData, s.cfg.Routing.Trampoline.Policy(),
+		)
+		if err != nil {
+			return fmt.Errorf("unable to encode trampoline "+
+				"policy: %w", err)
+		}
+	}
+
 	// TODO(abdulkbk): potentially find a way to use the source node's
 	// features in the self node.
 	selfNode := models.NewV1Node(
 		nodePub, &models.NodeV1Fields{
-			Alias:      nodeAlias.String(),
-			Color:      nodeColor,
-			LastUpdate: nodeLastUpdate,
-			Addresses:  addrs,
-			Features:   s.featureMgr.GetRaw(feature.SetNodeAnn),
+			Alias:           nodeAlias.String(),
+			Color:           nodeColor,
+			LastUpdate:      nodeLastUpdate,
+			Addresses:       addrs,
+			Features:        s.featureMgr.GetRaw(feature.SetNodeAnn),
+			ExtraOpaqueData: extraData,
 		},
 	)
 
@@ -93,6 +93,71 @@ var (
 			// schema. This is optional and can be disabled by the
 			// user if necessary.
 		},
+		{
+			Name:          "000009_payments",
+			Version:       11,
+			SchemaVersion: 9,
+		},
+		{
+			Name:          "kv_payments_migration",
+			Version:       12,
+			SchemaVersion: 9,
+			// A migration function may be attached to this
+			// migration to migrate KV payments to the native SQL
+			// schema. This is optional and can be disabled by the
+			// user if necessary.
+		},
+		{
+			Name:          "000010_channel_state",
+			Version:       13,
+			SchemaVersion: 10,
+		},
+		{
+			Name:          "kv_channel_state_migration",
+			Version:       14,
+			SchemaVersion: 10,
+			// A migration function may be attached to this
+			// migration to migrate the KV channel state to the
+			// native SQL schema. This is optional and can be
+			// disabled by the user if necessary.
+		},
+		{
+			Name:          "000011_forwarding_log",
+			Version:       15,
+			SchemaVersion: 11,
+		},
+		{
+			Name:          "kv_forwarding_log_migration",
+			Version:       16,
+			SchemaVersion: 11,
+			// A migration function may be attached to this
+			// migration to migrate the KV forwarding log to the
+			// native SQL schema. This is optional and can be
+			// disabled by the user if necessary.
+		},
+		{
+			Name:          "000012_watchtower",
+			Version:       17,
+			SchemaVersion: 12,
+		},
+		{
+			Name:          "kv_wtclient_migration",
+			Version:       18,
+			SchemaVersion: 12,
+			// A migration function may be attached to this
+			// migration to migrate the KV watchtower client DB to
+			// the native SQL schema. This is optional and can be
+			// disabled by the user if necessary.
+		},
+		{
+			Name:          "kv_towerdb_migration",
+			Version:       19,
+			SchemaVersion: 12,
+			// A migration function may be attached to this
+			// migration to migrate the KV watchtower server DB to
+			// the native SQL schema. This is optional and can be
+			// disabled by the user if necessary.
+		},
 	}, migrationAdditions...)
 
 	// ErrMigrationMismatch is returned when a migrated record does not
@@ -55,6 +55,22 @@ func (q *Queries) AddV1ChannelProof(ctx context.Context, arg AddV1ChannelProofPa
 	)
 }
 
+const addV2ChannelProof = `-- name: AddV2ChannelProof :execresult
+UPDATE graph_channels
+SET node_1_signature = $2
+WHERE scid = $1
+  AND version = 2
+`
+
+type AddV2ChannelProofParams struct {
+	Scid           []byte
+	Node1Signature []byte
+}
+
+func (q *Queries) AddV2ChannelProof(ctx context.Context, arg AddV2ChannelProofParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, addV2ChannelProof, arg.Scid, arg.Node1Signature)
+}
+
 const countZombieChannels = `-- name: CountZombieChannels :one
 SELECT COUNT(*)
 FROM graph_zombie_channels
@@ -242,11 +258,15 @@ WHERE
         FROM graph_source_nodes sn
         WHERE sn.node_id = graph_nodes.id
     )
-    -- Select all nodes that do not have any channels.
+    -- Select all nodes that do not have any channels. Channels always
+    -- refer to the v1 record of a node, so the records of the other gossip
+    -- versions of a node are kept for as long as it has channels.
     AND NOT EXISTS (
         SELECT 1
         FROM graph_channels c
-        WHERE c.node_id_1 = graph_nodes.id OR c.node_id_2 = graph_nodes.id
+        JOIN graph_nodes n
+            ON n.id = c.node_id_1 OR n.id = c.node_id_2
+        WHERE n.pub_key = graph_nodes.pub_key
 ) RETURNING pub_key
 `
 
@@ -0,0 +1,16 @@
+-- Drop indexes.
+DROP INDEX IF EXISTS payment_hop_custom_records_unique;
+DROP INDEX IF EXISTS payment_route_hops_unique;
+DROP INDEX IF EXISTS payment_attempt_first_hop_custom_records_unique;
+DROP INDEX IF EXISTS payment_htlc_attempts_unique;
+DROP INDEX IF EXISTS payment_first_hop_custom_records_unique;
+DROP INDEX IF EXISTS payments_created_at_idx;
+DROP INDEX IF EXISTS payments_status_idx;
+
+-- Drop tables in order of reverse dependencies.
+DROP TABLE IF EXISTS payment_hop_custom_records;
+DROP TABLE IF EXISTS payment_route_hops;
+DROP TABLE IF EXISTS payment_attempt_first_hop_custom_records;
+DROP TABLE IF EXISTS payment_htlc_attempts;
+DROP TABLE IF EXISTS payment_first_hop_custom_records;
+DROP TABLE IF EXISTS payments;
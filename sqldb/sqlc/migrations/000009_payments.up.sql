@@ -0,0 +1,234 @@
+/* ─────────────────────────────────────────────
+   payment data tables
+   ─────────────────────────────────────────────
+*/
+
+-- payments stores a single payment per payment hash together with its
+-- static creation info and its current status.
+CREATE TABLE IF NOT EXISTS payments (
+    -- The id of the payment. This is also used as the sequence number of
+    -- the payment which determines the order in which payments are
+    -- returned when paginating.
+    id INTEGER PRIMARY KEY,
+
+    -- The payment hash (or payment identifier) of the payment.
+    payment_hash BLOB NOT NULL UNIQUE,
+
+    -- The amount of the payment in milliloki.
+    amount_msat BIGINT NOT NULL,
+
+    -- The unix timestamp (in nanoseconds) at which the payment was
+    -- created. A zero value indicates an unknown creation time.
+    created_at BIGINT NOT NULL,
+
+    -- The status of the payment as derived from its HTLC attempts and
+    -- failure reason. This is updated whenever any of those change so
+    -- that payments can be filtered by status.
+    status SMALLINT NOT NULL,
+
+    -- The reason the payment failed, if it has failed.
+    fail_reason SMALLINT,
+
+    -- The full payment request of the payment, if any. For BOLT 12
+    -- payments this is the invoice that was fetched for the offer.
+    payment_request BLOB,
+
+    -- The encoded BOLT 12 offer the payment was made for, if any.
+    offer BLOB,
+
+    -- The note sent along with the invoice request of a BOLT 12 payment,
+    -- if any.
+    payer_note TEXT,
+
+    -- Whether this payment relays a trampoline payment we received as a
+    -- trampoline node. Such payments aren't our own and are therefore
+    -- neither listed nor deleted along with them.
+    trampoline_relay BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS payments_status_idx ON payments(status);
+CREATE INDEX IF NOT EXISTS payments_created_at_idx ON payments(created_at);
+
+-- payment_first_hop_custom_records stores the custom records that are sent
+-- to the first hop of a payment in the wire message of every HTLC.
+CREATE TABLE IF NOT EXISTS payment_first_hop_custom_records (
+    -- The payment this record belongs to.
+    payment_id BIGINT NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+
+    -- The custom type identifier for this record.
+    key BIGINT NOT NULL,
+
+    -- The custom value for this record.
+    value BLOB NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS payment_first_hop_custom_records_unique ON payment_first_hop_custom_records (
+    payment_id, key
+);
+
+/* ─────────────────────────────────────────────
+   htlc attempt data tables
+   ─────────────────────────────────────────────
+*/
+
+-- payment_htlc_attempts stores the HTLC attempts that were made for a
+-- payment, together with the route they were sent along and their
+-- resolution.
+CREATE TABLE IF NOT EXISTS payment_htlc_attempts (
+    -- The db ID of the HTLC attempt. This will only be used DB level
+    -- relations.
+    id INTEGER PRIMARY KEY,
+
+    -- The payment this attempt belongs to.
+    payment_id BIGINT NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+
+    -- The attempt ID assigned by the router.
+    attempt_id BIGINT NOT NULL,
+
+    -- The ephemeral session key used to construct the onion of the
+    -- attempt.
+    session_key BLOB NOT NULL,
+
+    -- The unix timestamp (in nanoseconds) at which the attempt was made.
+    attempt_time BIGINT NOT NULL,
+
+    -- The hash used for this attempt. This differs between the attempts
+    -- of AMP payments and is NULL for attempts made by older versions, in
+    -- which case the payment hash applies.
+    payment_hash BLOB,
+
+    -- The cumulative time lock of the route, which is the CLTV value that
+    -- is extended to the first hop.
+    route_total_time_lock INTEGER NOT NULL,
+
+    -- The total amount in milliloki sent along the route, including the
+    -- fees of all hops.
+    route_total_amount BIGINT NOT NULL,
+
+    -- The public key of the node the route originates from.
+    route_source_key BLOB NOT NULL,
+
+    -- The amount in milliloki that is actually sent to the first hop. This
+    -- only differs from the total amount for custom channels.
+    first_hop_amount_msat BIGINT NOT NULL,
+
+    -- How the attempt was resolved. This is NULL as long as the attempt is
+    -- in flight, 1 once it was settled and 2 once it has failed.
+    resolution_type SMALLINT,
+
+    -- The unix timestamp (in nanoseconds) at which the attempt was
+    -- resolved.
+    resolution_time BIGINT,
+
+    -- The preimage that settled the attempt.
+    settle_preimage BLOB,
+
+    -- The reason the attempt failed.
+    htlc_fail_reason SMALLINT,
+
+    -- The index of the hop in the route that returned the failure.
+    failure_source_index INTEGER,
+
+    -- The wire encoded failure message that was returned for the attempt,
+    -- if it could be decrypted.
+    failure_msg BLOB
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS payment_htlc_attempts_unique ON payment_htlc_attempts (
+    payment_id, attempt_id
+);
+
+-- payment_attempt_first_hop_custom_records stores the custom records that
+-- were sent to the first hop in the wire message of an HTLC attempt.
+CREATE TABLE IF NOT EXISTS payment_attempt_first_hop_custom_records (
+    -- The HTLC attempt this record belongs to.
+    htlc_attempt_id BIGINT NOT NULL REFERENCES payment_htlc_attempts(id) ON DELETE CASCADE,
+
+    -- The custom type identifier for this record.
+    key BIGINT NOT NULL,
+
+    -- The custom value for this record.
+    value BLOB NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS payment_attempt_first_hop_custom_records_unique ON payment_attempt_first_hop_custom_records (
+    htlc_attempt_id, key
+);
+
+/* ─────────────────────────────────────────────
+   route data tables
+   ─────────────────────────────────────────────
+*/
+
+-- payment_route_hops stores the hops of the route an HTLC attempt was sent
+-- along, including the payload that was delivered to each of them.
+CREATE TABLE IF NOT EXISTS payment_route_hops (
+    -- The db ID of the hop. This will only be used DB level relations.
+    id INTEGER PRIMARY KEY,
+
+    -- The HTLC attempt this hop belongs to.
+    htlc_attempt_id BIGINT NOT NULL REFERENCES payment_htlc_attempts(id) ON DELETE CASCADE,
+
+    -- The position of the hop in the route, starting at zero.
+    hop_index INTEGER NOT NULL,
+
+    -- The public key (serialised compressed) of the hop.
+    pub_key BLOB NOT NULL,
+
+    -- The short channel ID of the channel used to reach the hop.
+    scid BIGINT NOT NULL,
+
+    -- The time lock value the HTLC extended to the hop must have.
+    outgoing_time_lock INTEGER NOT NULL,
+
+    -- The amount in milliloki the hop should forward.
+    amt_to_forward BIGINT NOT NULL,
+
+    -- Whether the hop received a legacy (non-TLV) payload.
+    legacy_payload BOOLEAN NOT NULL,
+
+    -- The payment metadata sent to the hop, if any.
+    meta_data BLOB,
+
+    -- The MPP record of the final hop of a multi-path payment.
+    mpp_payment_addr BLOB,
+    mpp_total_msat BIGINT,
+
+    -- The AMP record of the final hop of an AMP payment.
+    amp_root_share BLOB,
+    amp_set_id BLOB,
+    amp_child_index INTEGER,
+
+    -- The encrypted data and blinding point delivered to a hop of a
+    -- blinded path.
+    encrypted_data BLOB,
+    blinding_point BLOB,
+
+    -- The total amount in milliloki of a payment to a blinded path, which
+    -- is only set for the final hop.
+    blinded_path_total_amt BIGINT,
+
+    -- The trampoline onion delivered to a trampoline hop.
+    trampoline_onion BLOB
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS payment_route_hops_unique ON payment_route_hops (
+    htlc_attempt_id, hop_index
+);
+
+-- payment_hop_custom_records stores the custom records that were delivered
+-- to a hop in its onion payload.
+CREATE TABLE IF NOT EXISTS payment_hop_custom_records (
+    -- The hop this record belongs to.
+    hop_id BIGINT NOT NULL REFERENCES payment_route_hops(id) ON DELETE CASCADE,
+
+    -- The custom type identifier for this record.
+    key BIGINT NOT NULL,
+
+    -- The custom value for this record.
+    value BLOB NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS payment_hop_custom_records_unique ON payment_hop_custom_records (
+    hop_id, key
+);
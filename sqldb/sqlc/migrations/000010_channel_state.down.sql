@@ -0,0 +1,31 @@
+-- Drop indexes.
+DROP INDEX IF EXISTS channel_final_htlcs_unique;
+DROP INDEX IF EXISTS channel_forwarding_package_updates_unique;
+DROP INDEX IF EXISTS channel_forwarding_packages_unique;
+DROP INDEX IF EXISTS channel_close_summaries_chan_id_idx;
+DROP INDEX IF EXISTS channel_revocation_log_htlcs_revocation_log_id_idx;
+DROP INDEX IF EXISTS channel_revocation_logs_unique;
+DROP INDEX IF EXISTS channel_log_updates_channel_id_idx;
+DROP INDEX IF EXISTS channel_commit_diff_circuit_keys_commit_diff_id_idx;
+DROP INDEX IF EXISTS channel_htlc_custom_records_unique;
+DROP INDEX IF EXISTS channel_commitment_htlcs_commitment_id_idx;
+DROP INDEX IF EXISTS channel_commitments_unique;
+DROP INDEX IF EXISTS channels_closed_idx;
+DROP INDEX IF EXISTS channels_node_pub_key_idx;
+
+-- Drop tables in order of reverse dependencies.
+DROP TABLE IF EXISTS channel_forwarding_policies;
+DROP TABLE IF EXISTS channel_opening_states;
+DROP TABLE IF EXISTS channel_final_htlcs;
+DROP TABLE IF EXISTS channel_forwarding_package_updates;
+DROP TABLE IF EXISTS channel_forwarding_packages;
+DROP TABLE IF EXISTS channel_close_summaries;
+DROP TABLE IF EXISTS channel_revocation_log_htlcs;
+DROP TABLE IF EXISTS channel_revocation_logs;
+DROP TABLE IF EXISTS channel_log_updates;
+DROP TABLE IF EXISTS channel_commit_diff_circuit_keys;
+DROP TABLE IF EXISTS channel_commit_diffs;
+DROP TABLE IF EXISTS channel_htlc_custom_records;
+DROP TABLE IF EXISTS channel_commitment_htlcs;
+DROP TABLE IF EXISTS channel_commitments;
+DROP TABLE IF EXISTS channels;
@@ -0,0 +1,492 @@
+/* ─────────────────────────────────────────────
+   channel data tables
+   ─────────────────────────────────────────────
+*/
+
+-- channels stores the state of every channel that we have with our peers.
+-- Once a channel is closed, its row is kept around with the closed flag set
+-- so that it can still be served as the historical view of the channel.
+CREATE TABLE IF NOT EXISTS channels (
+    -- The db ID of the channel. This will only be used DB level
+    -- relations.
+    id INTEGER PRIMARY KEY,
+
+    -- The serialized funding outpoint of the channel.
+    outpoint BLOB NOT NULL UNIQUE,
+
+    -- The 32-byte channel ID derived from the funding outpoint.
+    chan_id BLOB NOT NULL UNIQUE,
+
+    -- The public key (serialised compressed) of the remote node.
+    node_pub_key BLOB NOT NULL,
+
+    -- The hash of the genesis block of the chain the channel lives on.
+    chain_hash BLOB NOT NULL,
+
+    -- Whether the channel has been closed.
+    closed BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- The serialized static channel info. This contains the channel
+    -- type, status, configs of both parties and any auxiliary data.
+    chan_info BLOB NOT NULL,
+
+    -- The serialized revocation producer and store, together with the
+    -- current and next revocation points of the remote party.
+    revocation_state BLOB NOT NULL,
+
+    -- The optional upfront shutdown scripts of both parties.
+    local_shutdown_script BLOB,
+    remote_shutdown_script BLOB,
+
+    -- The thaw height of frozen or leased channels.
+    thaw_height BIGINT NOT NULL DEFAULT 0,
+
+    -- Whether the last update we sent was a revocation.
+    last_was_revoke BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- The commit point stored when the channel was marked as having lost
+    -- local data.
+    data_loss_commit_point BLOB,
+
+    -- The broadcast force and cooperative closing transactions.
+    force_close_tx BLOB,
+    coop_close_tx BLOB,
+
+    -- The serialized shutdown info of the channel.
+    shutdown_info BLOB
+);
+
+CREATE INDEX IF NOT EXISTS channels_node_pub_key_idx ON channels(node_pub_key);
+CREATE INDEX IF NOT EXISTS channels_closed_idx ON channels(closed);
+
+/* ─────────────────────────────────────────────
+   commitment data tables
+   ─────────────────────────────────────────────
+*/
+
+-- channel_commitments stores the commitments of a channel. Every channel
+-- has the current commitment of each party, and the remote party may have
+-- a pending commitment that we've extended but they haven't revoked their
+-- current one for yet. Channels restored from a backup have no commitments.
+CREATE TABLE IF NOT EXISTS channel_commitments (
+    -- The db ID of the commitment. This will only be used DB level
+    -- relations.
+    id INTEGER PRIMARY KEY,
+
+    -- The channel this commitment belongs to.
+    channel_id BIGINT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+
+    -- The kind of the commitment: 0 for the local commitment, 1 for the
+    -- current remote commitment and 2 for the pending remote commitment.
+    commitment_type SMALLINT NOT NULL,
+
+    -- The update number of the commitment.
+    commit_height BIGINT NOT NULL,
+
+    -- The cumulative log and HTLC indexes of both parties at this point in
+    -- the commitment chain.
+    local_log_index BIGINT NOT NULL,
+    local_htlc_index BIGINT NOT NULL,
+    remote_log_index BIGINT NOT NULL,
+    remote_htlc_index BIGINT NOT NULL,
+
+    -- The settled balances of both parties in milliloki.
+    local_balance_msat BIGINT NOT NULL,
+    remote_balance_msat BIGINT NOT NULL,
+
+    -- The commitment fee in loki and the fee rate in loki per kw.
+    commit_fee BIGINT NOT NULL,
+    fee_per_kw BIGINT NOT NULL,
+
+    -- The serialized commitment transaction.
+    commit_tx BLOB NOT NULL,
+
+    -- The signature of the remote party for the commitment transaction.
+    commit_sig BLOB NOT NULL,
+
+    -- The optional blob storing custom channel data for this commitment.
+    custom_blob BLOB
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS channel_commitments_unique ON channel_commitments (
+    channel_id, commitment_type
+);
+
+-- channel_commitment_htlcs stores the HTLCs that are pending on a
+-- commitment.
+CREATE TABLE IF NOT EXISTS channel_commitment_htlcs (
+    -- The db ID of the HTLC. This will only be used DB level relations,
+    -- and to keep the HTLCs of a commitment in order.
+    id INTEGER PRIMARY KEY,
+
+    -- The commitment this HTLC belongs to.
+    commitment_id BIGINT NOT NULL REFERENCES channel_commitments(id) ON DELETE CASCADE,
+
+    -- The HTLC counter index and the cumulative log index of the HTLC.
+    htlc_index BIGINT NOT NULL,
+    log_index BIGINT NOT NULL,
+
+    -- Whether we're the receiver of the HTLC.
+    incoming BOOLEAN NOT NULL,
+
+    -- The payment hash of the HTLC.
+    payment_hash BLOB NOT NULL,
+
+    -- The amount of the HTLC in milliloki.
+    amount_msat BIGINT NOT NULL,
+
+    -- The absolute timeout of the HTLC.
+    refund_timeout BIGINT NOT NULL,
+
+    -- The index of the HTLC output within the commitment transaction, or
+    -- -1 for dust HTLCs.
+    output_index INTEGER NOT NULL,
+
+    -- The signature for the second level transaction of the HTLC.
+    signature BLOB,
+
+    -- The onion routing packet of the HTLC.
+    onion_blob BLOB NOT NULL,
+
+    -- The blinding point of the HTLC, if it's part of a blinded route.
+    blinding_point BLOB
+);
+
+CREATE INDEX IF NOT EXISTS channel_commitment_htlcs_commitment_id_idx ON channel_commitment_htlcs(commitment_id);
+
+-- channel_htlc_custom_records stores the custom records that were sent
+-- along with an HTLC.
+CREATE TABLE IF NOT EXISTS channel_htlc_custom_records (
+    -- The HTLC this record belongs to.
+    htlc_id BIGINT NOT NULL REFERENCES channel_commitment_htlcs(id) ON DELETE CASCADE,
+
+    -- The custom type identifier for this record.
+    key BIGINT NOT NULL,
+
+    -- The custom value for this record.
+    value BLOB NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS channel_htlc_custom_records_unique ON channel_htlc_custom_records (
+    htlc_id, key
+);
+
+-- channel_commit_diffs stores the signature we sent for the pending
+-- commitment of the remote party. The pending commitment itself is stored
+-- in channel_commitments, and the updates it includes in
+-- channel_log_updates.
+CREATE TABLE IF NOT EXISTS channel_commit_diffs (
+    -- The db ID of the commit diff. This will only be used DB level
+    -- relations.
+    id INTEGER PRIMARY KEY,
+
+    -- The channel this commit diff belongs to.
+    channel_id BIGINT NOT NULL UNIQUE REFERENCES channels(id) ON DELETE CASCADE,
+
+    -- The wire encoded commit_sig message we sent for the commitment.
+    commit_sig BLOB NOT NULL
+);
+
+-- channel_commit_diff_circuit_keys stores the payment circuits that were
+-- opened and closed by a commit diff.
+CREATE TABLE IF NOT EXISTS channel_commit_diff_circuit_keys (
+    -- The db ID of the circuit key. This will only be used to keep the
+    -- circuit keys of a commit diff in order.
+    id INTEGER PRIMARY KEY,
+
+    -- The commit diff this circuit key belongs to.
+    commit_diff_id BIGINT NOT NULL REFERENCES channel_commit_diffs(id) ON DELETE CASCADE,
+
+    -- Whether the circuit was opened or closed by the commit diff.
+    opened BOOLEAN NOT NULL,
+
+    -- The short channel ID and HTLC ID identifying the circuit.
+    scid BIGINT NOT NULL,
+    htlc_id BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS channel_commit_diff_circuit_keys_commit_diff_id_idx ON channel_commit_diff_circuit_keys(commit_diff_id);
+
+-- channel_log_updates stores the channel updates that need to be restored
+-- after a restart.
+CREATE TABLE IF NOT EXISTS channel_log_updates (
+    -- The db ID of the update. This will only be used to keep the updates
+    -- in order.
+    id INTEGER PRIMARY KEY,
+
+    -- The channel this update belongs to.
+    channel_id BIGINT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+
+    -- The list the update belongs to: 0 for the local updates of the
+    -- pending remote commitment, 1 for the remote updates we've acked but
+    -- not yet signed for and 2 for the local updates the remote party
+    -- hasn't signed for yet.
+    update_type SMALLINT NOT NULL,
+
+    -- The log index of the update.
+    log_index BIGINT NOT NULL,
+
+    -- The type of the wire message of the update.
+    msg_type INTEGER NOT NULL,
+
+    -- The wire encoded update message.
+    msg BLOB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS channel_log_updates_channel_id_idx ON channel_log_updates(channel_id, update_type);
+
+/* ─────────────────────────────────────────────
+   revocation log data tables
+   ─────────────────────────────────────────────
+*/
+
+-- channel_revocation_logs stores the minimal info required to re-construct
+-- a revoked state of a channel in order to punish the remote party if it
+-- broadcasts that state.
+CREATE TABLE IF NOT EXISTS channel_revocation_logs (
+    -- The db ID of the revocation log entry. This will only be used DB
+    -- level relations.
+    id INTEGER PRIMARY KEY,
+
+    -- The channel this log entry belongs to.
+    channel_id BIGINT NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+
+    -- The commitment height of the revoked state.
+    commit_height BIGINT NOT NULL,
+
+    -- The indexes of our and their outputs in the revoked commitment
+    -- transaction.
+    our_output_index INTEGER NOT NULL,
+    their_output_index INTEGER NOT NULL,
+
+    -- The hash of the revoked commitment transaction.
+    commit_tx_hash BLOB NOT NULL,
+
+    -- The balances of both parties in milliloki. These are NULL if the
+    -- node was configured to not store them.
+    our_balance_msat BIGINT,
+    their_balance_msat BIGINT,
+
+    -- The optional blob storing custom channel data for this state.
+    custom_blob BLOB
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS channel_revocation_logs_unique ON channel_revocation_logs (
+    channel_id, commit_height
+);
+
+-- channel_revocation_log_htlcs stores the non-dust HTLCs of a revoked
+-- state.
+CREATE TABLE IF NOT EXISTS channel_revocation_log_htlcs (
+    -- The db ID of the HTLC. This will only be used to keep the HTLCs of
+    -- a revoked state in order.
+    id INTEGER PRIMARY KEY,
+
+    -- The revocation log entry this HTLC belongs to.
+    revocation_log_id BIGINT NOT NULL REFERENCES channel_revocation_logs(id) ON DELETE CASCADE,
+
+    -- The payment hash of the HTLC.
+    payment_hash BLOB NOT NULL,
+
+    -- The absolute timeout of the HTLC.
+    refund_timeout BIGINT NOT NULL,
+
+    -- The index of the HTLC output within the revoked commitment
+    -- transaction.
+    output_index INTEGER NOT NULL,
+
+    -- Whether we're the receiver of the HTLC.
+    incoming BOOLEAN NOT NULL,
+
+    -- The amount of the HTLC in loki.
+    amount BIGINT NOT NULL,
+
+    -- The optional blob storing custom channel data for this HTLC.
+    custom_blob BLOB,
+
+    -- The HTLC counter index of the HTLC. This is NULL for entries that
+    -- were created before the index was stored.
+    htlc_index BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS channel_revocation_log_htlcs_revocation_log_id_idx ON channel_revocation_log_htlcs(revocation_log_id);
+
+/* ─────────────────────────────────────────────
+   closed channel data tables
+   ─────────────────────────────────────────────
+*/
+
+-- channel_close_summaries stores the summary of every closed channel.
+CREATE TABLE IF NOT EXISTS channel_close_summaries (
+    -- The db ID of the close summary.
+    id INTEGER PRIMARY KEY,
+
+    -- The serialized funding outpoint of the closed channel.
+    outpoint BLOB NOT NULL UNIQUE,
+
+    -- The 32-byte channel ID derived from the funding outpoint.
+    chan_id BLOB NOT NULL,
+
+    -- The short channel ID of the closed channel.
+    scid BIGINT NOT NULL,
+
+    -- The hash of the genesis block of the chain the channel lived on.
+    chain_hash BLOB NOT NULL,
+
+    -- The hash of the transaction that closed the channel.
+    closing_txid BLOB NOT NULL,
+
+    -- The height at which the closing transaction was mined.
+    close_height BIGINT NOT NULL,
+
+    -- The public key (serialised compressed) of the remote node.
+    remote_pub BLOB NOT NULL,
+
+    -- The capacity of the channel and our settled and time locked
+    -- balances at the time it was closed, in loki.
+    capacity BIGINT NOT NULL,
+    settled_balance BIGINT NOT NULL,
+    time_locked_balance BIGINT NOT NULL,
+
+    -- How the channel was closed.
+    close_type SMALLINT NOT NULL,
+
+    -- Whether the channel is still waiting to be fully closed.
+    is_pending BOOLEAN NOT NULL,
+
+    -- The current and next revocation points of the remote party. These
+    -- are NULL for summaries that were created before they were stored.
+    remote_current_revocation BLOB,
+    remote_next_revocation BLOB,
+
+    -- The serialized local channel config. This is only set together with
+    -- the current revocation point of the remote party.
+    local_chan_config BLOB,
+
+    -- The wire encoded channel_reestablish message we'd send for the
+    -- channel, if any.
+    last_chan_sync_msg BLOB
+);
+
+CREATE INDEX IF NOT EXISTS channel_close_summaries_chan_id_idx ON channel_close_summaries(chan_id);
+
+/* ─────────────────────────────────────────────
+   forwarding package data tables
+   ─────────────────────────────────────────────
+*/
+
+-- channel_forwarding_packages stores the packages of adds, settles and fails
+-- that were locked in by a revocation of the remote party, keyed by the
+-- short channel ID of the channel they were received on and the remote
+-- commitment height.
+CREATE TABLE IF NOT EXISTS channel_forwarding_packages (
+    -- The db ID of the forwarding package.
+    id INTEGER PRIMARY KEY,
+
+    -- The short channel ID of the source channel.
+    source_scid BIGINT NOT NULL,
+
+    -- The remote commitment height the package was locked in at.
+    height BIGINT NOT NULL,
+
+    -- Whether the adds of the package have been processed, which is when
+    -- it's decided which of them are forwarded.
+    processed BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS channel_forwarding_packages_unique ON channel_forwarding_packages (
+    source_scid, height
+);
+
+-- channel_forwarding_package_updates stores the updates of a forwarding
+-- package together with their progress.
+CREATE TABLE IF NOT EXISTS channel_forwarding_package_updates (
+    -- The forwarding package this update belongs to.
+    fwd_pkg_id BIGINT NOT NULL REFERENCES channel_forwarding_packages(id) ON DELETE CASCADE,
+
+    -- The kind of the update: 0 for adds and 1 for settles and fails.
+    update_type SMALLINT NOT NULL,
+
+    -- The index of the update among the updates of the same kind in the
+    -- package.
+    update_index INTEGER NOT NULL,
+
+    -- The log index of the update.
+    log_index BIGINT NOT NULL,
+
+    -- The type of the wire message of the update.
+    msg_type INTEGER NOT NULL,
+
+    -- The wire encoded update message.
+    msg BLOB NOT NULL,
+
+    -- Whether the update has been acked. For an add, this means that a
+    -- settle or fail for it has been committed to the remote party. For a
+    -- settle or fail, it means that it has been locked into the commitment
+    -- of the incoming channel.
+    acked BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- Whether the add was forwarded to the switch when the package was
+    -- processed.
+    forwarded BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS channel_forwarding_package_updates_unique ON channel_forwarding_package_updates (
+    fwd_pkg_id, update_type, update_index
+);
+
+/* ─────────────────────────────────────────────
+   channel setup data tables
+   ─────────────────────────────────────────────
+*/
+
+-- channel_final_htlcs stores the final resolution of incoming htlcs.
+CREATE TABLE IF NOT EXISTS channel_final_htlcs (
+    -- The short channel ID of the channel the htlc was received on.
+    scid BIGINT NOT NULL,
+
+    -- The index of the htlc within the channel.
+    htlc_index BIGINT NOT NULL,
+
+    -- Whether the htlc was settled or failed.
+    settled BOOLEAN NOT NULL,
+
+    -- Whether the htlc was resolved off-chain or on-chain.
+    offchain BOOLEAN NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS channel_final_htlcs_unique ON channel_final_htlcs (
+    scid, htlc_index
+);
+
+-- channel_opening_states stores the serialized state of channels that are
+-- in the process of being opened.
+CREATE TABLE IF NOT EXISTS channel_opening_states (
+    -- The serialized funding outpoint of the channel.
+    outpoint BLOB NOT NULL UNIQUE,
+
+    -- The serialized opening state.
+    state BLOB NOT NULL
+);
+
+-- channel_forwarding_policies stores the initial forwarding policy of
+-- channels that are in the process of being opened.
+CREATE TABLE IF NOT EXISTS channel_forwarding_policies (
+    -- The 32-byte channel ID of the channel.
+    chan_id BLOB NOT NULL UNIQUE,
+
+    -- The minimum htlc amount in milliloki.
+    min_htlc_msat BIGINT NOT NULL,
+
+    -- The maximum htlc amount in milliloki.
+    max_htlc_msat BIGINT NOT NULL,
+
+    -- The base fee in milliloki.
+    base_fee_msat BIGINT NOT NULL,
+
+    -- The proportional fee rate in millionths.
+    fee_rate BIGINT NOT NULL,
+
+    -- The time lock delta of the channel.
+    time_lock_delta BIGINT NOT NULL
+);
@@ -0,0 +1,9 @@
+-- Drop indexes.
+DROP INDEX IF EXISTS forwarding_events_outgoing_peer_idx;
+DROP INDEX IF EXISTS forwarding_events_incoming_peer_idx;
+DROP INDEX IF EXISTS forwarding_events_outgoing_chan_id_idx;
+DROP INDEX IF EXISTS forwarding_events_incoming_chan_id_idx;
+DROP INDEX IF EXISTS forwarding_events_timestamp_idx;
+
+-- Drop tables.
+DROP TABLE IF EXISTS forwarding_events;
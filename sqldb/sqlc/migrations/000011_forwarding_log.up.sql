@@ -0,0 +1,47 @@
+-- forwarding_events stores the forwarding log of the node. Each row describes
+-- a payment circuit that was torn down after the forwarded HTLC was settled.
+CREATE TABLE IF NOT EXISTS forwarding_events (
+    -- The db ID of the forwarding event. Events with the same timestamp are
+    -- ordered by this ID.
+    id INTEGER PRIMARY KEY,
+
+    -- The settlement time of the payment circuit in nanoseconds since the
+    -- unix epoch.
+    timestamp BIGINT NOT NULL,
+
+    -- The short channel IDs of the incoming and outgoing channels of the
+    -- payment circuit.
+    incoming_chan_id BIGINT NOT NULL,
+    outgoing_chan_id BIGINT NOT NULL,
+
+    -- The public keys (serialised compressed) of the peers of the incoming
+    -- and outgoing channels. These are NULL for events that were recorded
+    -- before the peers were tracked.
+    incoming_peer BLOB,
+    outgoing_peer BLOB,
+
+    -- The amounts of the incoming and outgoing HTLCs in milliloki. The
+    -- difference between the two is the fee earned by the forward.
+    amt_in_msat BIGINT NOT NULL,
+    amt_out_msat BIGINT NOT NULL,
+
+    -- The IDs of the incoming and outgoing HTLCs. These are NULL for events
+    -- that were recorded before the HTLC IDs were tracked.
+    incoming_htlc_id BIGINT,
+    outgoing_htlc_id BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS forwarding_events_timestamp_idx
+ON forwarding_events(timestamp);
+
+CREATE INDEX IF NOT EXISTS forwarding_events_incoming_chan_id_idx
+ON forwarding_events(incoming_chan_id, timestamp);
+
+CREATE INDEX IF NOT EXISTS forwarding_events_outgoing_chan_id_idx
+ON forwarding_events(outgoing_chan_id, timestamp);
+
+CREATE INDEX IF NOT EXISTS forwarding_events_incoming_peer_idx
+ON forwarding_events(incoming_peer, timestamp);
+
+CREATE INDEX IF NOT EXISTS forwarding_events_outgoing_peer_idx
+ON forwarding_events(outgoing_peer, timestamp);
@@ -0,0 +1,17 @@
+-- Drop indexes.
+DROP INDEX IF EXISTS wtserver_state_updates_session_id_idx;
+DROP INDEX IF EXISTS wtclient_acked_ranges_channel_id_idx;
+DROP INDEX IF EXISTS wtclient_sessions_tower_id_idx;
+
+-- Drop tables.
+DROP TABLE IF EXISTS wtserver_lookout_tip;
+DROP TABLE IF EXISTS wtserver_state_updates;
+DROP TABLE IF EXISTS wtserver_sessions;
+DROP TABLE IF EXISTS wtclient_backup_queue;
+DROP TABLE IF EXISTS wtclient_acked_ranges;
+DROP TABLE IF EXISTS wtclient_committed_updates;
+DROP TABLE IF EXISTS wtclient_channels;
+DROP TABLE IF EXISTS wtclient_sessions;
+DROP TABLE IF EXISTS wtclient_session_key_index_sequence;
+DROP TABLE IF EXISTS wtclient_session_key_indexes;
+DROP TABLE IF EXISTS wtclient_towers;
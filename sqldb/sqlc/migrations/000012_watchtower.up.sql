@@ -0,0 +1,231 @@
+/* ─────────────────────────────────────────────
+   watchtower client tables
+   ─────────────────────────────────────────────
+*/
+
+-- wtclient_towers stores the watchtowers that the watchtower client has been
+-- told about.
+CREATE TABLE IF NOT EXISTS wtclient_towers (
+    -- The db ID of the tower. This is the tower ID that is handed out to
+    -- the watchtower client.
+    id INTEGER PRIMARY KEY,
+
+    -- The public key (serialised compressed) of the tower.
+    pub_key BLOB NOT NULL UNIQUE,
+
+    -- The serialised list of the tower's addresses. The most recently added
+    -- address comes first.
+    addresses BLOB NOT NULL,
+
+    -- The status of the tower. The sessions of inactive towers are not used
+    -- for backups.
+    status SMALLINT NOT NULL
+);
+
+-- wtclient_session_key_indexes stores the session key indexes that have been
+-- reserved for a tower and blob type, but not yet used to create a session.
+CREATE TABLE IF NOT EXISTS wtclient_session_key_indexes (
+    -- The tower that the key index is reserved for.
+    tower_id BIGINT NOT NULL,
+
+    -- The blob type of the session that the key index is reserved for.
+    blob_type INTEGER NOT NULL,
+
+    -- The reserved key index.
+    key_index BIGINT NOT NULL,
+
+    PRIMARY KEY (tower_id, blob_type)
+);
+
+-- wtclient_session_key_index_sequence holds the last session key index that
+-- was reserved. Key indexes are never reused, even if the session they were
+-- reserved for has been deleted. The table only ever holds a single row.
+CREATE TABLE IF NOT EXISTS wtclient_session_key_index_sequence (
+    id SMALLINT PRIMARY KEY CHECK (id = 0),
+
+    last_index BIGINT NOT NULL
+);
+
+-- wtclient_sessions stores the sessions that the watchtower client has
+-- negotiated with its towers.
+CREATE TABLE IF NOT EXISTS wtclient_sessions (
+    -- The db ID of the session. This will only be used DB level relations.
+    id INTEGER PRIMARY KEY,
+
+    -- The 33-byte session ID.
+    session_id BLOB NOT NULL UNIQUE,
+
+    -- The tower that the session was negotiated with.
+    tower_id BIGINT NOT NULL REFERENCES wtclient_towers(id),
+
+    -- The key index used to derive the session key.
+    key_index BIGINT NOT NULL,
+
+    -- The negotiated session policy.
+    blob_type INTEGER NOT NULL,
+    max_updates INTEGER NOT NULL,
+    reward_base BIGINT NOT NULL,
+    reward_rate BIGINT NOT NULL,
+    sweep_fee_rate BIGINT NOT NULL,
+
+    -- The script that the tower's reward is paid to, if any.
+    reward_pk_script BLOB,
+
+    -- The sequence number of the last committed update and the last applied
+    -- sequence number echoed by the tower.
+    seq_num INTEGER NOT NULL,
+    tower_last_applied INTEGER NOT NULL,
+
+    -- The status of the session.
+    status SMALLINT NOT NULL,
+
+    -- The number of acked updates for channels that were closed by the time
+    -- the update was acked.
+    rogue_update_count BIGINT NOT NULL DEFAULT 0,
+
+    -- The block height at which the session became closable. This is NULL
+    -- as long as the session is not closable.
+    closable_height BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS wtclient_sessions_tower_id_idx
+ON wtclient_sessions(tower_id);
+
+-- wtclient_channels stores the channels that are registered with the
+-- watchtower client.
+CREATE TABLE IF NOT EXISTS wtclient_channels (
+    -- The db ID of the channel. This will only be used DB level relations.
+    id INTEGER PRIMARY KEY,
+
+    -- The 32-byte channel ID.
+    channel_id BLOB NOT NULL UNIQUE,
+
+    -- The script that the tower should sweep our funds to.
+    sweep_pk_script BLOB NOT NULL,
+
+    -- The block height at which the channel was closed. This is NULL as long
+    -- as the channel is open.
+    closed_height BIGINT,
+
+    -- The highest commitment height of the channel that has been handed to
+    -- the watchtower client for backup.
+    max_commit_height BIGINT
+);
+
+-- wtclient_committed_updates stores the updates that were committed to a
+-- session but have not yet been acked by the tower.
+CREATE TABLE IF NOT EXISTS wtclient_committed_updates (
+    -- The session that the update was committed to.
+    session_id BIGINT NOT NULL REFERENCES wtclient_sessions(id) ON DELETE CASCADE,
+
+    -- The sequence number of the update within the session.
+    seq_num INTEGER NOT NULL,
+
+    -- The channel ID and commitment height of the backed up state. The
+    -- channel is not referenced since it may be closed and deleted while
+    -- the update is pending.
+    channel_id BLOB NOT NULL,
+    commit_height BIGINT NOT NULL,
+
+    -- The breach hint and encrypted justice blob of the update.
+    hint BLOB NOT NULL,
+    encrypted_blob BLOB NOT NULL,
+
+    PRIMARY KEY (session_id, seq_num)
+);
+
+-- wtclient_acked_ranges stores the commitment heights of a channel that were
+-- acked by a session's tower. Consecutive heights are collapsed into a single
+-- range.
+CREATE TABLE IF NOT EXISTS wtclient_acked_ranges (
+    session_id BIGINT NOT NULL REFERENCES wtclient_sessions(id) ON DELETE CASCADE,
+    channel_id BIGINT NOT NULL REFERENCES wtclient_channels(id),
+
+    -- The first and the last commitment height of the range, both
+    -- inclusive.
+    range_start BIGINT NOT NULL,
+    range_end BIGINT NOT NULL,
+
+    PRIMARY KEY (session_id, channel_id, range_start)
+);
+
+CREATE INDEX IF NOT EXISTS wtclient_acked_ranges_channel_id_idx
+ON wtclient_acked_ranges(channel_id);
+
+-- wtclient_backup_queue stores the backups that are waiting to be handed to
+-- a session. Every queue is identified by its namespace.
+CREATE TABLE IF NOT EXISTS wtclient_backup_queue (
+    namespace BLOB NOT NULL,
+
+    -- The position of the item in the queue. Items pushed to the head of the
+    -- queue get a position lower than all existing items, items pushed to
+    -- the tail a position higher than all existing items.
+    queue_index BIGINT NOT NULL,
+
+    -- The channel ID and commitment height of the backup.
+    channel_id BLOB NOT NULL,
+    commit_height BIGINT NOT NULL,
+
+    PRIMARY KEY (namespace, queue_index)
+);
+
+/* ─────────────────────────────────────────────
+   watchtower server tables
+   ─────────────────────────────────────────────
+*/
+
+-- wtserver_sessions stores the sessions that clients negotiated with our
+-- tower.
+CREATE TABLE IF NOT EXISTS wtserver_sessions (
+    -- The db ID of the session. This will only be used DB level relations.
+    id INTEGER PRIMARY KEY,
+
+    -- The 33-byte session ID.
+    session_id BLOB NOT NULL UNIQUE,
+
+    -- The negotiated session policy.
+    blob_type INTEGER NOT NULL,
+    max_updates INTEGER NOT NULL,
+    reward_base BIGINT NOT NULL,
+    reward_rate BIGINT NOT NULL,
+    sweep_fee_rate BIGINT NOT NULL,
+
+    -- The sequence number of the last accepted update and the last applied
+    -- sequence number echoed by the client.
+    last_applied INTEGER NOT NULL,
+    client_last_applied INTEGER NOT NULL,
+
+    -- The address that the tower's reward is paid to, if any.
+    reward_address BLOB
+);
+
+-- wtserver_state_updates stores the state updates that clients sent to our
+-- tower. A session has at most one update per breach hint.
+CREATE TABLE IF NOT EXISTS wtserver_state_updates (
+    session_id BIGINT NOT NULL REFERENCES wtserver_sessions(id) ON DELETE CASCADE,
+
+    -- The breach hint used to find the update once the breach happens.
+    hint BLOB NOT NULL,
+
+    -- The sequence number of the update and the last applied sequence
+    -- number echoed by the client.
+    seq_num INTEGER NOT NULL,
+    last_applied INTEGER NOT NULL,
+
+    -- The encrypted justice blob.
+    encrypted_blob BLOB NOT NULL,
+
+    PRIMARY KEY (hint, session_id)
+);
+
+CREATE INDEX IF NOT EXISTS wtserver_state_updates_session_id_idx
+ON wtserver_state_updates(session_id);
+
+-- wtserver_lookout_tip stores the last block processed by the lookout. The
+-- table only ever holds a single row.
+CREATE TABLE IF NOT EXISTS wtserver_lookout_tip (
+    id SMALLINT PRIMARY KEY CHECK (id = 0),
+
+    block_hash BLOB NOT NULL,
+    block_height INTEGER NOT NULL
+);
@@ -28,6 +28,183 @@ type AmpSubInvoiceHtlc struct {
 	Preimage   []byte
 }
 
+type Channel struct {
+	ID                   int64
+	Outpoint             []byte
+	ChanID               []byte
+	NodePubKey           []byte
+	ChainHash            []byte
+	Closed               bool
+	ChanInfo             []byte
+	RevocationState      []byte
+	LocalShutdownScript  []byte
+	RemoteShutdownScript []byte
+	ThawHeight           int64
+	LastWasRevoke        bool
+	DataLossCommitPoint  []byte
+	ForceCloseTx         []byte
+	CoopCloseTx          []byte
+	ShutdownInfo         []byte
+}
+
+type ChannelCloseSummary struct {
+	ID                      int64
+	Outpoint                []byte
+	ChanID                  []byte
+	Scid                    int64
+	ChainHash               []byte
+	ClosingTxid             []byte
+	CloseHeight             int64
+	RemotePub               []byte
+	Capacity                int64
+	SettledBalance          int64
+	TimeLockedBalance       int64
+	CloseType               int16
+	IsPending               bool
+	RemoteCurrentRevocation []byte
+	RemoteNextRevocation    []byte
+	LocalChanConfig         []byte
+	LastChanSyncMsg         []byte
+}
+
+type ChannelCommitDiff struct {
+	ID        int64
+	ChannelID int64
+	CommitSig []byte
+}
+
+type ChannelCommitDiffCircuitKey struct {
+	ID           int64
+	CommitDiffID int64
+	Opened       bool
+	Scid         int64
+	HtlcID       int64
+}
+
+type ChannelCommitment struct {
+	ID                int64
+	ChannelID         int64
+	CommitmentType    int16
+	CommitHeight      int64
+	LocalLogIndex     int64
+	LocalHtlcIndex    int64
+	RemoteLogIndex    int64
+	RemoteHtlcIndex   int64
+	LocalBalanceMsat  int64
+	RemoteBalanceMsat int64
+	CommitFee         int64
+	FeePerKw          int64
+	CommitTx          []byte
+	CommitSig         []byte
+	CustomBlob        []byte
+}
+
+type ChannelCommitmentHtlc struct {
+	ID            int64
+	CommitmentID  int64
+	HtlcIndex     int64
+	LogIndex      int64
+	Incoming      bool
+	PaymentHash   []byte
+	AmountMsat    int64
+	RefundTimeout int64
+	OutputIndex   int32
+	Signature     []byte
+	OnionBlob     []byte
+	BlindingPoint []byte
+}
+
+type ChannelFinalHtlc struct {
+	Scid      int64
+	HtlcIndex int64
+	Settled   bool
+	Offchain  bool
+}
+
+type ChannelForwardingPackage struct {
+	ID         int64
+	SourceScid int64
+	Height     int64
+	Processed  bool
+}
+
+type ChannelForwardingPackageUpdate struct {
+	FwdPkgID    int64
+	UpdateType  int16
+	UpdateIndex int32
+	LogIndex    int64
+	MsgType     int32
+	Msg         []byte
+	Acked       bool
+	Forwarded   bool
+}
+
+type ChannelForwardingPolicy struct {
+	ChanID        []byte
+	MinHtlcMsat   int64
+	MaxHtlcMsat   int64
+	BaseFeeMsat   int64
+	FeeRate       int64
+	TimeLockDelta int64
+}
+
+type ChannelHtlcCustomRecord struct {
+	HtlcID int64
+	Key    int64
+	Value  []byte
+}
+
+type ChannelLogUpdate struct {
+	ID         int64
+	ChannelID  int64
+	UpdateType int16
+	LogIndex   int64
+	MsgType    int32
+	Msg        []byte
+}
+
+type ChannelOpeningState struct {
+	Outpoint []byte
+	State    []byte
+}
+
+type ChannelRevocationLog struct {
+	ID               int64
+	ChannelID        int64
+	CommitHeight     int64
+	OurOutputIndex   int32
+	TheirOutputIndex int32
+	CommitTxHash     []byte
+	OurBalanceMsat   sql.NullInt64
+	TheirBalanceMsat sql.NullInt64
+	CustomBlob       []byte
+}
+
+type ChannelRevocationLogHtlc struct {
+	ID              int64
+	RevocationLogID int64
+	PaymentHash     []byte
+	RefundTimeout   int64
+	OutputIndex     int32
+	Incoming        bool
+	Amount          int64
+	CustomBlob      []byte
+	HtlcIndex       sql.NullInt64
+}
+
+type ForwardingEvent struct {
+	ID             int64
+	Timestamp      int64
+	IncomingChanID int64
+	OutgoingChanID int64
+	IncomingPeer   []byte
+	OutgoingPeer   []byte
+	AmtInMsat      int64
+	AmtOutMsat     int64
+	IncomingHtlcID sql.NullInt64
+	OutgoingHtlcID sql.NullInt64
+}
+
 type GraphChannel struct {
 	ID                  int64
 	Version             int16
@@ -202,3 +379,168 @@ type MigrationTracker struct {
 	Version       int32
 	MigrationTime time.Time
 }
+
+type Payment struct {
+	ID              int64
+	PaymentHash     []byte
+	AmountMsat      int64
+	CreatedAt       int64
+	Status          int16
+	FailReason      sql.NullInt16
+	PaymentRequest  []byte
+	Offer           []byte
+	PayerNote       sql.NullString
+	TrampolineRelay bool
+}
+
+type PaymentAttemptFirstHopCustomRecord struct {
+	HtlcAttemptID int64
+	Key           int64
+	Value         []byte
+}
+
+type PaymentFirstHopCustomRecord struct {
+	PaymentID int64
+	Key       int64
+	Value     []byte
+}
+
+type PaymentHopCustomRecord struct {
+	HopID int64
+	Key   int64
+	Value []byte
+}
+
+type PaymentHtlcAttempt struct {
+	ID                 int64
+	PaymentID          int64
+	AttemptID          int64
+	SessionKey         []byte
+	AttemptTime        int64
+	PaymentHash        []byte
+	RouteTotalTimeLock int32
+	RouteTotalAmount   int64
+	RouteSourceKey     []byte
+	FirstHopAmountMsat int64
+	ResolutionType     sql.NullInt16
+	ResolutionTime     sql.NullInt64
+	SettlePreimage     []byte
+	HtlcFailReason     sql.NullInt16
+	FailureSourceIndex sql.NullInt32
+	FailureMsg         []byte
+}
+
+type PaymentRouteHop struct {
+	ID                  int64
+	HtlcAttemptID       int64
+	HopIndex            int32
+	PubKey              []byte
+	Scid                int64
+	OutgoingTimeLock    int32
+	AmtToForward        int64
+	LegacyPayload       bool
+	MetaData            []byte
+	MppPaymentAddr      []byte
+	MppTotalMsat        sql.NullInt64
+	AmpRootShare        []byte
+	AmpSetID            []byte
+	AmpChildIndex       sql.NullInt32
+	EncryptedData       []byte
+	BlindingPoint       []byte
+	BlindedPathTotalAmt sql.NullInt64
+	TrampolineOnion     []byte
+}
+
+type WtclientAckedRange struct {
+	SessionID  int64
+	ChannelID  int64
+	RangeStart int64
+	RangeEnd   int64
+}
+
+type WtclientBackupQueue struct {
+	Namespace    []byte
+	QueueIndex   int64
+	ChannelID    []byte
+	CommitHeight int64
+}
+
+type WtclientChannel struct {
+	ID              int64
+	ChannelID       []byte
+	SweepPkScript   []byte
+	ClosedHeight    sql.NullInt64
+	MaxCommitHeight sql.NullInt64
+}
+
+type WtclientCommittedUpdate struct {
+	SessionID     int64
+	SeqNum        int32
+	ChannelID     []byte
+	CommitHeight  int64
+	Hint          []byte
+	EncryptedBlob []byte
+}
+
+type WtclientSession struct {
+	ID               int64
+	SessionID        []byte
+	TowerID          int64
+	KeyIndex         int64
+	BlobType         int32
+	MaxUpdates       int32
+	RewardBase       int64
+	RewardRate       int64
+	SweepFeeRate     int64
+	RewardPkScript   []byte
+	SeqNum           int32
+	TowerLastApplied int32
+	Status           int16
+	RogueUpdateCount int64
+	ClosableHeight   sql.NullInt64
+}
+
+type WtclientSessionKeyIndex struct {
+	TowerID  int64
+	BlobType int32
+	KeyIndex int64
+}
+
+type WtclientSessionKeyIndexSequence struct {
+	ID        int16
+	LastIndex int64
+}
+
+type WtclientTower struct {
+	ID        int64
+	PubKey    []byte
+	Addresses []byte
+	Status    int16
+}
+
+type WtserverLookoutTip struct {
+	ID          int16
+	BlockHash   []byte
+	BlockHeight int32
+}
+
+type WtserverSession struct {
+	ID                int64
+	SessionID         []byte
+	BlobType          int32
+	MaxUpdates        int32
+	RewardBase        int64
+	RewardRate        int64
+	SweepFeeRate      int64
+	LastApplied       int32
+	ClientLastApplied int32
+	RewardAddress     []byte
+}
+
+type WtserverStateUpdate struct {
+	SessionID     int64
+	Hint          []byte
+	SeqNum        int32
+	LastApplied   int32
+	EncryptedBlob []byte
+}
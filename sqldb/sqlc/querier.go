@@ -11,27 +11,94 @@ import (
 )
 
 type Querier interface {
+	AckFwdPkgUpdate(ctx context.Context, arg AckFwdPkgUpdateParams) error
 	AddSourceNode(ctx context.Context, nodeID int64) error
 	AddV1ChannelProof(ctx context.Context, arg AddV1ChannelProofParams) (sql.Result, error)
+	AddV2ChannelProof(ctx context.Context, arg AddV2ChannelProofParams) (sql.Result, error)
+	AggregateForwardingEventsByIncomingChan(ctx context.Context, arg AggregateForwardingEventsByIncomingChanParams) ([]AggregateForwardingEventsByIncomingChanRow, error)
+	AggregateForwardingEventsByIncomingPeer(ctx context.Context, arg AggregateForwardingEventsByIncomingPeerParams) ([]AggregateForwardingEventsByIncomingPeerRow, error)
+	AggregateForwardingEventsByOutgoingChan(ctx context.Context, arg AggregateForwardingEventsByOutgoingChanParams) ([]AggregateForwardingEventsByOutgoingChanRow, error)
+	AggregateForwardingEventsByOutgoingPeer(ctx context.Context, arg AggregateForwardingEventsByOutgoingPeerParams) ([]AggregateForwardingEventsByOutgoingPeerRow, error)
+	AggregateForwardingEventsByTime(ctx context.Context, arg AggregateForwardingEventsByTimeParams) ([]AggregateForwardingEventsByTimeRow, error)
 	ClearKVInvoiceHashIndex(ctx context.Context) error
+	CountPayments(ctx context.Context) (int64, error)
+	CountRevocationLogs(ctx context.Context, channelID int64) (int64, error)
+	CountWtClientChannelAckedRanges(ctx context.Context, channelID int64) (int64, error)
+	CountWtClientCommittedUpdates(ctx context.Context, sessionID int64) (int64, error)
 	CountZombieChannels(ctx context.Context, version int16) (int64, error)
 	CreateChannel(ctx context.Context, arg CreateChannelParams) (int64, error)
 	DeleteCanceledInvoices(ctx context.Context) (sql.Result, error)
+	DeleteChannelCommitment(ctx context.Context, arg DeleteChannelCommitmentParams) error
+	DeleteChannelForwardingPolicy(ctx context.Context, chanID []byte) error
+	DeleteChannelLogUpdates(ctx context.Context, arg DeleteChannelLogUpdatesParams) error
+	DeleteChannelOpeningState(ctx context.Context, outpoint []byte) error
 	DeleteChannelPolicyExtraTypes(ctx context.Context, channelPolicyID int64) error
 	DeleteChannels(ctx context.Context, ids []int64) error
+	DeleteCommitDiff(ctx context.Context, channelID int64) error
 	DeleteExtraNodeType(ctx context.Context, arg DeleteExtraNodeTypeParams) error
+	DeleteFailedPaymentHtlcAttempts(ctx context.Context, paymentID int64) error
+	DeleteFailedPaymentHtlcAttemptsByStatus(ctx context.Context, status int16) error
+	DeleteForwardingEventsUpTo(ctx context.Context, arg DeleteForwardingEventsUpToParams) error
+	DeleteFwdPkg(ctx context.Context, arg DeleteFwdPkgParams) error
+	DeleteFwdPkgUpdatesFrom(ctx context.Context, arg DeleteFwdPkgUpdatesFromParams) error
+	DeleteFwdPkgs(ctx context.Context, sourceScid int64) error
 	DeleteInvoice(ctx context.Context, arg DeleteInvoiceParams) (sql.Result, error)
 	DeleteNode(ctx context.Context, id int64) error
 	DeleteNodeAddresses(ctx context.Context, nodeID int64) error
 	DeleteNodeByPubKey(ctx context.Context, arg DeleteNodeByPubKeyParams) (sql.Result, error)
 	DeleteNodeFeature(ctx context.Context, arg DeleteNodeFeatureParams) error
+	DeletePayment(ctx context.Context, id int64) error
+	DeletePaymentsByStatus(ctx context.Context, status int16) (sql.Result, error)
 	DeletePruneLogEntriesInRange(ctx context.Context, arg DeletePruneLogEntriesInRangeParams) error
+	DeleteRevocationLog(ctx context.Context, arg DeleteRevocationLogParams) error
+	DeleteRevocationLogs(ctx context.Context, channelID int64) error
 	DeleteUnconnectedNodes(ctx context.Context) ([][]byte, error)
+	DeleteWtClientAckedRange(ctx context.Context, arg DeleteWtClientAckedRangeParams) error
+	DeleteWtClientBackupQueueItems(ctx context.Context, arg DeleteWtClientBackupQueueItemsParams) error
+	DeleteWtClientChannel(ctx context.Context, id int64) error
+	DeleteWtClientCommittedUpdate(ctx context.Context, arg DeleteWtClientCommittedUpdateParams) error
+	DeleteWtClientCommittedUpdates(ctx context.Context, sessionID int64) error
+	DeleteWtClientSession(ctx context.Context, id int64) error
+	DeleteWtClientSessionKeyIndex(ctx context.Context, arg DeleteWtClientSessionKeyIndexParams) error
+	DeleteWtClientTower(ctx context.Context, id int64) error
+	DeleteWtClientTowerSessionKeyIndexes(ctx context.Context, towerID int64) error
+	DeleteWtServerSession(ctx context.Context, id int64) error
 	DeleteZombieChannel(ctx context.Context, arg DeleteZombieChannelParams) (sql.Result, error)
+	FailPaymentHtlcAttempt(ctx context.Context, arg FailPaymentHtlcAttemptParams) error
 	FetchAMPSubInvoiceHTLCs(ctx context.Context, arg FetchAMPSubInvoiceHTLCsParams) ([]FetchAMPSubInvoiceHTLCsRow, error)
 	FetchAMPSubInvoices(ctx context.Context, arg FetchAMPSubInvoicesParams) ([]AmpSubInvoice, error)
+	FetchChannelCloseSummary(ctx context.Context, outpoint []byte) (ChannelCloseSummary, error)
+	FetchChannelCloseSummaryByChanID(ctx context.Context, chanID []byte) (ChannelCloseSummary, error)
+	FetchChannelCommitment(ctx context.Context, arg FetchChannelCommitmentParams) (ChannelCommitment, error)
+	FetchChannelForwardingPolicy(ctx context.Context, chanID []byte) (ChannelForwardingPolicy, error)
+	FetchChannelLogUpdates(ctx context.Context, arg FetchChannelLogUpdatesParams) ([]ChannelLogUpdate, error)
+	FetchChannelOpeningState(ctx context.Context, outpoint []byte) ([]byte, error)
+	FetchChannelStateByChanID(ctx context.Context, chanID []byte) (Channel, error)
+	FetchChannelStateByOutpoint(ctx context.Context, outpoint []byte) (Channel, error)
+	FetchCommitDiff(ctx context.Context, channelID int64) (ChannelCommitDiff, error)
+	FetchCommitDiffCircuitKeys(ctx context.Context, commitDiffID int64) ([]ChannelCommitDiffCircuitKey, error)
+	FetchCommitmentHtlcCustomRecords(ctx context.Context, commitmentID int64) ([]ChannelHtlcCustomRecord, error)
+	FetchCommitmentHtlcs(ctx context.Context, commitmentID int64) ([]ChannelCommitmentHtlc, error)
+	FetchFinalHtlc(ctx context.Context, arg FetchFinalHtlcParams) (ChannelFinalHtlc, error)
+	FetchForwardingEvent(ctx context.Context, id int64) (ForwardingEvent, error)
+	FetchForwardingEvents(ctx context.Context, arg FetchForwardingEventsParams) ([]ForwardingEvent, error)
+	FetchForwardingEventsByChans(ctx context.Context, arg FetchForwardingEventsByChansParams) ([]ForwardingEvent, error)
+	FetchForwardingEventsByIncomingChans(ctx context.Context, arg FetchForwardingEventsByIncomingChansParams) ([]ForwardingEvent, error)
+	FetchForwardingEventsByOutgoingChans(ctx context.Context, arg FetchForwardingEventsByOutgoingChansParams) ([]ForwardingEvent, error)
+	FetchFwdPkg(ctx context.Context, arg FetchFwdPkgParams) (ChannelForwardingPackage, error)
+	FetchFwdPkgUpdates(ctx context.Context, fwdPkgID int64) ([]ChannelForwardingPackageUpdate, error)
+	FetchInFlightPayments(ctx context.Context) ([]Payment, error)
+	FetchPayment(ctx context.Context, paymentHash []byte) (Payment, error)
+	FetchPaymentAttemptFirstHopCustomRecords(ctx context.Context, paymentID int64) ([]PaymentAttemptFirstHopCustomRecord, error)
+	FetchPaymentFirstHopCustomRecords(ctx context.Context, paymentID int64) ([]PaymentFirstHopCustomRecord, error)
+	FetchPaymentHopCustomRecords(ctx context.Context, paymentID int64) ([]PaymentHopCustomRecord, error)
+	FetchPaymentHtlcAttempts(ctx context.Context, paymentID int64) ([]PaymentHtlcAttempt, error)
+	FetchPaymentRouteHops(ctx context.Context, paymentID int64) ([]PaymentRouteHop, error)
+	FetchRevocationLog(ctx context.Context, arg FetchRevocationLogParams) (ChannelRevocationLog, error)
+	FetchRevocationLogHtlcs(ctx context.Context, revocationLogID int64) ([]ChannelRevocationLogHtlc, error)
 	FetchSettledAMPSubInvoices(ctx context.Context, arg FetchSettledAMPSubInvoicesParams) ([]FetchSettledAMPSubInvoicesRow, error)
 	FilterInvoices(ctx context.Context, arg FilterInvoicesParams) ([]Invoice, error)
+	FilterPayments(ctx context.Context, arg FilterPaymentsParams) ([]Payment, error)
 	GetAMPInvoiceID(ctx context.Context, setID []byte) (int64, error)
 	GetChannelAndNodesBySCID(ctx context.Context, arg GetChannelAndNodesBySCIDParams) (GetChannelAndNodesBySCIDRow, error)
 	GetChannelByOutpointWithPolicies(ctx context.Context, arg GetChannelByOutpointWithPoliciesParams) (GetChannelByOutpointWithPoliciesRow, error)
@@ -82,25 +149,44 @@ type Querier interface {
 	// structure will have a more complex disabled bit vector
 	// and so the query for V2 may differ.
 	GetV1DisabledSCIDs(ctx context.Context) ([][]byte, error)
+	GetWtClientBackupQueueInfo(ctx context.Context, namespace []byte) (GetWtClientBackupQueueInfoRow, error)
+	GetWtClientChannel(ctx context.Context, channelID []byte) (WtclientChannel, error)
+	GetWtClientCommittedUpdate(ctx context.Context, arg GetWtClientCommittedUpdateParams) (WtclientCommittedUpdate, error)
+	GetWtClientSession(ctx context.Context, sessionID []byte) (WtclientSession, error)
+	GetWtClientSessionKeyIndex(ctx context.Context, arg GetWtClientSessionKeyIndexParams) (int64, error)
+	GetWtClientSessionKeyIndexSequence(ctx context.Context) (int64, error)
+	GetWtClientTowerByID(ctx context.Context, id int64) (WtclientTower, error)
+	GetWtClientTowerByPubKey(ctx context.Context, pubKey []byte) (WtclientTower, error)
+	GetWtServerLookoutTip(ctx context.Context) (GetWtServerLookoutTipRow, error)
+	GetWtServerSession(ctx context.Context, sessionID []byte) (WtserverSession, error)
 	GetZombieChannel(ctx context.Context, arg GetZombieChannelParams) (GraphZombieChannel, error)
 	GetZombieChannelsSCIDs(ctx context.Context, arg GetZombieChannelsSCIDsParams) ([]GraphZombieChannel, error)
 	HighestSCID(ctx context.Context, version int16) ([]byte, error)
 	InsertAMPSubInvoice(ctx context.Context, arg InsertAMPSubInvoiceParams) error
 	InsertAMPSubInvoiceHTLC(ctx context.Context, arg InsertAMPSubInvoiceHTLCParams) error
+	InsertChannelCommitment(ctx context.Context, arg InsertChannelCommitmentParams) (int64, error)
 	InsertChannelFeature(ctx context.Context, arg InsertChannelFeatureParams) error
+	InsertChannelLogUpdate(ctx context.Context, arg InsertChannelLogUpdateParams) error
 	// NOTE: This query is only meant to be used by the graph SQL migration since
 	// for that migration, in order to be retry-safe, we don't want to error out if
 	// we re-insert the same channel again (which would error if the normal
 	// CreateChannel query is used because of the uniqueness constraint on the scid
 	// and version columns).
 	InsertChannelMig(ctx context.Context, arg InsertChannelMigParams) (int64, error)
+	InsertChannelState(ctx context.Context, arg InsertChannelStateParams) (int64, error)
 	InsertClosedChannel(ctx context.Context, scid []byte) error
+	InsertCommitDiff(ctx context.Context, arg InsertCommitDiffParams) (int64, error)
+	InsertCommitDiffCircuitKey(ctx context.Context, arg InsertCommitDiffCircuitKeyParams) error
+	InsertCommitmentHtlc(ctx context.Context, arg InsertCommitmentHtlcParams) (int64, error)
 	// NOTE: This query is only meant to be used by the graph SQL migration since
 	// for that migration, in order to be retry-safe, we don't want to error out if
 	// we re-insert the same policy (which would error if the normal
 	// UpsertEdgePolicy query is used because of the constraint in that query that
 	// requires a policy update to have a newer last_update than the existing one).
 	InsertEdgePolicyMig(ctx context.Context, arg InsertEdgePolicyMigParams) (int64, error)
+	InsertForwardingEvent(ctx context.Context, arg InsertForwardingEventParams) (int64, error)
+	InsertFwdPkg(ctx context.Context, arg InsertFwdPkgParams) (int64, error)
+	InsertHtlcCustomRecord(ctx context.Context, arg InsertHtlcCustomRecordParams) error
 	InsertInvoice(ctx context.Context, arg InsertInvoiceParams) (int64, error)
 	InsertInvoiceFeature(ctx context.Context, arg InsertInvoiceFeatureParams) error
 	InsertInvoiceHTLC(ctx context.Context, arg InsertInvoiceHTLCParams) (int64, error)
@@ -114,16 +200,48 @@ type Querier interface {
 	// is used because of the constraint in that query that requires a node update
 	// to have a newer last_update than the existing node).
 	InsertNodeMig(ctx context.Context, arg InsertNodeMigParams) (int64, error)
+	InsertPayment(ctx context.Context, arg InsertPaymentParams) (int64, error)
+	InsertPaymentAttemptFirstHopCustomRecord(ctx context.Context, arg InsertPaymentAttemptFirstHopCustomRecordParams) error
+	InsertPaymentFirstHopCustomRecord(ctx context.Context, arg InsertPaymentFirstHopCustomRecordParams) error
+	InsertPaymentHopCustomRecord(ctx context.Context, arg InsertPaymentHopCustomRecordParams) error
+	InsertPaymentHtlcAttempt(ctx context.Context, arg InsertPaymentHtlcAttemptParams) (int64, error)
+	InsertPaymentRouteHop(ctx context.Context, arg InsertPaymentRouteHopParams) (int64, error)
+	InsertRevocationLog(ctx context.Context, arg InsertRevocationLogParams) (int64, error)
+	InsertRevocationLogHtlc(ctx context.Context, arg InsertRevocationLogHtlcParams) error
+	InsertWtClientBackupQueueItem(ctx context.Context, arg InsertWtClientBackupQueueItemParams) error
+	InsertWtClientChannel(ctx context.Context, arg InsertWtClientChannelParams) (int64, error)
+	InsertWtClientCommittedUpdate(ctx context.Context, arg InsertWtClientCommittedUpdateParams) error
+	InsertWtClientSession(ctx context.Context, arg InsertWtClientSessionParams) (int64, error)
+	InsertWtClientTower(ctx context.Context, arg InsertWtClientTowerParams) (int64, error)
 	IsClosedChannel(ctx context.Context, scid []byte) (bool, error)
 	IsPublicV1Node(ctx context.Context, pubKey []byte) (bool, error)
+	IsWtClientBackupAcked(ctx context.Context, arg IsWtClientBackupAckedParams) (bool, error)
 	IsZombieChannel(ctx context.Context, arg IsZombieChannelParams) (bool, error)
+	ListChannelCloseSummaries(ctx context.Context) ([]ChannelCloseSummary, error)
+	ListChannelStates(ctx context.Context, closed bool) ([]Channel, error)
+	ListChannelStatesByNode(ctx context.Context, nodePubKey []byte) ([]Channel, error)
 	ListChannelsByNodeID(ctx context.Context, arg ListChannelsByNodeIDParams) ([]ListChannelsByNodeIDRow, error)
 	ListChannelsForNodeIDs(ctx context.Context, arg ListChannelsForNodeIDsParams) ([]ListChannelsForNodeIDsRow, error)
 	ListChannelsPaginated(ctx context.Context, arg ListChannelsPaginatedParams) ([]ListChannelsPaginatedRow, error)
 	ListChannelsWithPoliciesForCachePaginated(ctx context.Context, arg ListChannelsWithPoliciesForCachePaginatedParams) ([]ListChannelsWithPoliciesForCachePaginatedRow, error)
 	ListChannelsWithPoliciesPaginated(ctx context.Context, arg ListChannelsWithPoliciesPaginatedParams) ([]ListChannelsWithPoliciesPaginatedRow, error)
+	ListFwdPkgs(ctx context.Context, sourceScid int64) ([]ChannelForwardingPackage, error)
 	ListNodeIDsAndPubKeys(ctx context.Context, arg ListNodeIDsAndPubKeysParams) ([]ListNodeIDsAndPubKeysRow, error)
 	ListNodesPaginated(ctx context.Context, arg ListNodesPaginatedParams) ([]GraphNode, error)
+	ListWtClientAckedRanges(ctx context.Context, arg ListWtClientAckedRangesParams) ([]WtclientAckedRange, error)
+	ListWtClientBackupQueueItems(ctx context.Context, arg ListWtClientBackupQueueItemsParams) ([]WtclientBackupQueue, error)
+	ListWtClientChannelSessions(ctx context.Context, channelID int64) ([]WtclientSession, error)
+	ListWtClientClosableSessions(ctx context.Context) ([]ListWtClientClosableSessionsRow, error)
+	ListWtClientCommittedUpdates(ctx context.Context, sessionID int64) ([]WtclientCommittedUpdate, error)
+	ListWtClientOpenChannels(ctx context.Context) ([]WtclientChannel, error)
+	ListWtClientSessionAckedRanges(ctx context.Context, sessionID int64) ([]ListWtClientSessionAckedRangesRow, error)
+	ListWtClientSessionChannels(ctx context.Context, sessionID int64) ([]WtclientChannel, error)
+	ListWtClientSessions(ctx context.Context) ([]WtclientSession, error)
+	ListWtClientTowerSessions(ctx context.Context, towerID int64) ([]WtclientSession, error)
+	ListWtClientTowers(ctx context.Context) ([]WtclientTower, error)
+	ListWtServerSessionStateUpdates(ctx context.Context, sessionID int64) ([]WtserverStateUpdate, error)
+	ListWtServerStateUpdatesByHint(ctx context.Context, hint []byte) ([]ListWtServerStateUpdatesByHintRow, error)
+	MarkFwdPkgProcessed(ctx context.Context, id int64) error
 	NextInvoiceSettleIndex(ctx context.Context) (int64, error)
 	OnAMPSubInvoiceCanceled(ctx context.Context, arg OnAMPSubInvoiceCanceledParams) error
 	OnAMPSubInvoiceCreated(ctx context.Context, arg OnAMPSubInvoiceCreatedParams) error
@@ -131,18 +249,40 @@ type Querier interface {
 	OnInvoiceCanceled(ctx context.Context, arg OnInvoiceCanceledParams) error
 	OnInvoiceCreated(ctx context.Context, arg OnInvoiceCreatedParams) error
 	OnInvoiceSettled(ctx context.Context, arg OnInvoiceSettledParams) error
+	SetFwdPkgUpdateForwarded(ctx context.Context, arg SetFwdPkgUpdateForwardedParams) error
 	SetKVInvoicePaymentHash(ctx context.Context, arg SetKVInvoicePaymentHashParams) error
 	SetMigration(ctx context.Context, arg SetMigrationParams) error
+	SetWtClientSessionKeyIndexSequence(ctx context.Context, lastIndex int64) error
+	SettlePaymentHtlcAttempt(ctx context.Context, arg SettlePaymentHtlcAttemptParams) error
 	UpdateAMPSubInvoiceHTLCPreimage(ctx context.Context, arg UpdateAMPSubInvoiceHTLCPreimageParams) (sql.Result, error)
 	UpdateAMPSubInvoiceState(ctx context.Context, arg UpdateAMPSubInvoiceStateParams) error
+	UpdateChannelCloseSummaryPending(ctx context.Context, arg UpdateChannelCloseSummaryPendingParams) error
+	UpdateChannelCommitmentType(ctx context.Context, arg UpdateChannelCommitmentTypeParams) error
+	UpdateChannelState(ctx context.Context, arg UpdateChannelStateParams) error
 	UpdateInvoiceAmountPaid(ctx context.Context, arg UpdateInvoiceAmountPaidParams) (sql.Result, error)
 	UpdateInvoiceHTLC(ctx context.Context, arg UpdateInvoiceHTLCParams) error
 	UpdateInvoiceHTLCs(ctx context.Context, arg UpdateInvoiceHTLCsParams) error
 	UpdateInvoiceState(ctx context.Context, arg UpdateInvoiceStateParams) (sql.Result, error)
+	UpdatePaymentFailReason(ctx context.Context, arg UpdatePaymentFailReasonParams) error
+	UpdatePaymentStatus(ctx context.Context, arg UpdatePaymentStatusParams) error
+	UpdateWtClientChannelClosedHeight(ctx context.Context, arg UpdateWtClientChannelClosedHeightParams) error
+	UpdateWtClientChannelMaxCommitHeight(ctx context.Context, arg UpdateWtClientChannelMaxCommitHeightParams) error
+	UpdateWtClientSessionClosableHeight(ctx context.Context, arg UpdateWtClientSessionClosableHeightParams) error
+	UpdateWtClientSessionRogueUpdateCount(ctx context.Context, arg UpdateWtClientSessionRogueUpdateCountParams) error
+	UpdateWtClientSessionSeqNum(ctx context.Context, arg UpdateWtClientSessionSeqNumParams) error
+	UpdateWtClientSessionStatus(ctx context.Context, arg UpdateWtClientSessionStatusParams) error
+	UpdateWtClientSessionTowerLastApplied(ctx context.Context, arg UpdateWtClientSessionTowerLastAppliedParams) error
+	UpdateWtClientTower(ctx context.Context, arg UpdateWtClientTowerParams) error
+	UpdateWtServerSessionLastApplied(ctx context.Context, arg UpdateWtServerSessionLastAppliedParams) error
 	UpsertAMPSubInvoice(ctx context.Context, arg UpsertAMPSubInvoiceParams) (sql.Result, error)
 	UpsertChanPolicyExtraType(ctx context.Context, arg UpsertChanPolicyExtraTypeParams) error
+	UpsertChannelCloseSummary(ctx context.Context, arg UpsertChannelCloseSummaryParams) error
 	UpsertChannelExtraType(ctx context.Context, arg UpsertChannelExtraTypeParams) error
+	UpsertChannelForwardingPolicy(ctx context.Context, arg UpsertChannelForwardingPolicyParams) error
+	UpsertChannelOpeningState(ctx context.Context, arg UpsertChannelOpeningStateParams) error
 	UpsertEdgePolicy(ctx context.Context, arg UpsertEdgePolicyParams) (int64, error)
+	UpsertFinalHtlc(ctx context.Context, arg UpsertFinalHtlcParams) error
+	UpsertFwdPkgUpdate(ctx context.Context, arg UpsertFwdPkgUpdateParams) error
 	UpsertNode(ctx context.Context, arg UpsertNodeParams) (int64, error)
 	UpsertNodeAddress(ctx context.Context, arg UpsertNodeAddressParams) error
 	UpsertNodeExtraType(ctx context.Context, arg UpsertNodeExtraTypeParams) error
@@ -151,6 +291,11 @@ type Querier interface {
 	// about the last_update field. For our own node, we always want to
 	// update the record even if the last_update is the same as what we have.
 	UpsertSourceNode(ctx context.Context, arg UpsertSourceNodeParams) (int64, error)
+	UpsertWtClientAckedRange(ctx context.Context, arg UpsertWtClientAckedRangeParams) error
+	UpsertWtClientSessionKeyIndex(ctx context.Context, arg UpsertWtClientSessionKeyIndexParams) error
+	UpsertWtServerLookoutTip(ctx context.Context, arg UpsertWtServerLookoutTipParams) error
+	UpsertWtServerSession(ctx context.Context, arg UpsertWtServerSessionParams) error
+	UpsertWtServerStateUpdate(ctx context.Context, arg UpsertWtServerStateUpdateParams) error
 	UpsertZombieChannel(ctx context.Context, arg UpsertZombieChannelParams) error
 }
 
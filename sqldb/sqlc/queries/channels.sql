@@ -0,0 +1,408 @@
+/* ─────────────────────────────────────────────
+   channels table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertChannelState :one
+INSERT INTO channels (
+    outpoint, chan_id, node_pub_key, chain_hash, closed, chan_info,
+    revocation_state, local_shutdown_script, remote_shutdown_script,
+    thaw_height, last_was_revoke, data_loss_commit_point, force_close_tx,
+    coop_close_tx, shutdown_info
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+) RETURNING id;
+
+-- name: UpdateChannelState :exec
+UPDATE channels
+SET closed = $2,
+    chan_info = $3,
+    revocation_state = $4,
+    local_shutdown_script = $5,
+    remote_shutdown_script = $6,
+    thaw_height = $7,
+    last_was_revoke = $8,
+    data_loss_commit_point = $9,
+    force_close_tx = $10,
+    coop_close_tx = $11,
+    shutdown_info = $12
+WHERE id = $1;
+
+-- name: FetchChannelStateByOutpoint :one
+SELECT *
+FROM channels
+WHERE outpoint = $1;
+
+-- name: FetchChannelStateByChanID :one
+SELECT *
+FROM channels
+WHERE chan_id = $1;
+
+-- name: ListChannelStates :many
+SELECT *
+FROM channels
+WHERE closed = $1
+ORDER BY id;
+
+-- name: ListChannelStatesByNode :many
+SELECT *
+FROM channels
+WHERE node_pub_key = $1 AND closed = FALSE
+ORDER BY id;
+
+/* ─────────────────────────────────────────────
+   channel_commitments table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertChannelCommitment :one
+INSERT INTO channel_commitments (
+    channel_id, commitment_type, commit_height, local_log_index,
+    local_htlc_index, remote_log_index, remote_htlc_index,
+    local_balance_msat, remote_balance_msat, commit_fee, fee_per_kw,
+    commit_tx, commit_sig, custom_blob
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+) RETURNING id;
+
+-- name: FetchChannelCommitment :one
+SELECT *
+FROM channel_commitments
+WHERE channel_id = $1 AND commitment_type = $2;
+
+-- name: UpdateChannelCommitmentType :exec
+UPDATE channel_commitments
+SET commitment_type = $3
+WHERE channel_id = $1 AND commitment_type = $2;
+
+-- name: DeleteChannelCommitment :exec
+DELETE FROM channel_commitments
+WHERE channel_id = $1 AND commitment_type = $2;
+
+-- name: InsertCommitmentHtlc :one
+INSERT INTO channel_commitment_htlcs (
+    commitment_id, htlc_index, log_index, incoming, payment_hash,
+    amount_msat, refund_timeout, output_index, signature, onion_blob,
+    blinding_point
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING id;
+
+-- name: FetchCommitmentHtlcs :many
+SELECT *
+FROM channel_commitment_htlcs
+WHERE commitment_id = $1
+ORDER BY id;
+
+-- name: InsertHtlcCustomRecord :exec
+INSERT INTO channel_htlc_custom_records (
+    htlc_id, key, value
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: FetchCommitmentHtlcCustomRecords :many
+SELECT r.htlc_id, r.key, r.value
+FROM channel_htlc_custom_records r
+JOIN channel_commitment_htlcs h ON r.htlc_id = h.id
+WHERE h.commitment_id = $1
+ORDER BY r.htlc_id, r.key;
+
+/* ─────────────────────────────────────────────
+   channel_commit_diffs table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertCommitDiff :one
+INSERT INTO channel_commit_diffs (
+    channel_id, commit_sig
+) VALUES (
+    $1, $2
+) RETURNING id;
+
+-- name: FetchCommitDiff :one
+SELECT *
+FROM channel_commit_diffs
+WHERE channel_id = $1;
+
+-- name: DeleteCommitDiff :exec
+DELETE FROM channel_commit_diffs
+WHERE channel_id = $1;
+
+-- name: InsertCommitDiffCircuitKey :exec
+INSERT INTO channel_commit_diff_circuit_keys (
+    commit_diff_id, opened, scid, htlc_id
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: FetchCommitDiffCircuitKeys :many
+SELECT *
+FROM channel_commit_diff_circuit_keys
+WHERE commit_diff_id = $1
+ORDER BY id;
+
+/* ─────────────────────────────────────────────
+   channel_log_updates table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertChannelLogUpdate :exec
+INSERT INTO channel_log_updates (
+    channel_id, update_type, log_index, msg_type, msg
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: FetchChannelLogUpdates :many
+SELECT *
+FROM channel_log_updates
+WHERE channel_id = $1 AND update_type = $2
+ORDER BY id;
+
+-- name: DeleteChannelLogUpdates :exec
+DELETE FROM channel_log_updates
+WHERE channel_id = $1 AND update_type = $2;
+
+/* ─────────────────────────────────────────────
+   channel_revocation_logs table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertRevocationLog :one
+INSERT INTO channel_revocation_logs (
+    channel_id, commit_height, our_output_index, their_output_index,
+    commit_tx_hash, our_balance_msat, their_balance_msat, custom_blob
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id;
+
+-- name: FetchRevocationLog :one
+SELECT *
+FROM channel_revocation_logs
+WHERE channel_id = $1 AND commit_height = $2;
+
+-- name: CountRevocationLogs :one
+SELECT COUNT(*)
+FROM channel_revocation_logs
+WHERE channel_id = $1;
+
+-- name: DeleteRevocationLog :exec
+DELETE FROM channel_revocation_logs
+WHERE channel_id = $1 AND commit_height = $2;
+
+-- name: DeleteRevocationLogs :exec
+DELETE FROM channel_revocation_logs
+WHERE channel_id = $1;
+
+-- name: InsertRevocationLogHtlc :exec
+INSERT INTO channel_revocation_log_htlcs (
+    revocation_log_id, payment_hash, refund_timeout, output_index,
+    incoming, amount, custom_blob, htlc_index
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+);
+
+-- name: FetchRevocationLogHtlcs :many
+SELECT *
+FROM channel_revocation_log_htlcs
+WHERE revocation_log_id = $1
+ORDER BY id;
+
+/* ─────────────────────────────────────────────
+   channel_close_summaries table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: UpsertChannelCloseSummary :exec
+INSERT INTO channel_close_summaries (
+    outpoint, chan_id, scid, chain_hash, closing_txid, close_height,
+    remote_pub, capacity, settled_balance, time_locked_balance, close_type,
+    is_pending, remote_current_revocation, remote_next_revocation,
+    local_chan_config, last_chan_sync_msg
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+)
+ON CONFLICT (outpoint)
+    DO UPDATE SET
+        chan_id = EXCLUDED.chan_id,
+        scid = EXCLUDED.scid,
+        chain_hash = EXCLUDED.chain_hash,
+        closing_txid = EXCLUDED.closing_txid,
+        close_height = EXCLUDED.close_height,
+        remote_pub = EXCLUDED.remote_pub,
+        capacity = EXCLUDED.capacity,
+        settled_balance = EXCLUDED.settled_balance,
+        time_locked_balance = EXCLUDED.time_locked_balance,
+        close_type = EXCLUDED.close_type,
+        is_pending = EXCLUDED.is_pending,
+        remote_current_revocation = EXCLUDED.remote_current_revocation,
+        remote_next_revocation = EXCLUDED.remote_next_revocation,
+        local_chan_config = EXCLUDED.local_chan_config,
+        last_chan_sync_msg = EXCLUDED.last_chan_sync_msg;
+
+-- name: UpdateChannelCloseSummaryPending :exec
+UPDATE channel_close_summaries
+SET is_pending = $2
+WHERE outpoint = $1;
+
+-- name: FetchChannelCloseSummary :one
+SELECT *
+FROM channel_close_summaries
+WHERE outpoint = $1;
+
+-- name: FetchChannelCloseSummaryByChanID :one
+SELECT *
+FROM channel_close_summaries
+WHERE chan_id = $1
+ORDER BY id
+LIMIT 1;
+
+-- name: ListChannelCloseSummaries :many
+SELECT *
+FROM channel_close_summaries
+ORDER BY id;
+
+/* ─────────────────────────────────────────────
+   channel_forwarding_packages table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertFwdPkg :one
+INSERT INTO channel_forwarding_packages (
+    source_scid, height
+) VALUES (
+    $1, $2
+) RETURNING id;
+
+-- name: FetchFwdPkg :one
+SELECT *
+FROM channel_forwarding_packages
+WHERE source_scid = $1 AND height = $2;
+
+-- name: ListFwdPkgs :many
+SELECT *
+FROM channel_forwarding_packages
+WHERE source_scid = $1
+ORDER BY height;
+
+-- name: MarkFwdPkgProcessed :exec
+UPDATE channel_forwarding_packages
+SET processed = TRUE
+WHERE id = $1;
+
+-- name: DeleteFwdPkg :exec
+DELETE FROM channel_forwarding_packages
+WHERE source_scid = $1 AND height = $2;
+
+-- name: DeleteFwdPkgs :exec
+DELETE FROM channel_forwarding_packages
+WHERE source_scid = $1;
+
+-- name: UpsertFwdPkgUpdate :exec
+INSERT INTO channel_forwarding_package_updates (
+    fwd_pkg_id, update_type, update_index, log_index, msg_type, msg, acked
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (fwd_pkg_id, update_type, update_index)
+    -- The forwarded flag is left untouched as it is only ever written
+    -- once the adds of the package have been processed.
+    DO UPDATE SET
+        log_index = EXCLUDED.log_index,
+        msg_type = EXCLUDED.msg_type,
+        msg = EXCLUDED.msg,
+        acked = EXCLUDED.acked;
+
+-- name: FetchFwdPkgUpdates :many
+SELECT *
+FROM channel_forwarding_package_updates
+WHERE fwd_pkg_id = $1
+ORDER BY update_type, update_index;
+
+-- name: AckFwdPkgUpdate :exec
+UPDATE channel_forwarding_package_updates
+SET acked = TRUE
+WHERE fwd_pkg_id = $1 AND update_type = $2 AND update_index = $3;
+
+-- name: SetFwdPkgUpdateForwarded :exec
+UPDATE channel_forwarding_package_updates
+SET forwarded = TRUE
+WHERE fwd_pkg_id = $1 AND update_type = $2 AND update_index = $3;
+
+-- name: DeleteFwdPkgUpdatesFrom :exec
+DELETE FROM channel_forwarding_package_updates
+WHERE fwd_pkg_id = $1 AND update_type = $2 AND update_index >= $3;
+
+/* ─────────────────────────────────────────────
+   channel_final_htlcs table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: UpsertFinalHtlc :exec
+INSERT INTO channel_final_htlcs (
+    scid, htlc_index, settled, offchain
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (scid, htlc_index)
+    DO UPDATE SET
+        settled = EXCLUDED.settled,
+        offchain = EXCLUDED.offchain;
+
+-- name: FetchFinalHtlc :one
+SELECT *
+FROM channel_final_htlcs
+WHERE scid = $1 AND htlc_index = $2;
+
+/* ─────────────────────────────────────────────
+   channel_opening_states table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: UpsertChannelOpeningState :exec
+INSERT INTO channel_opening_states (
+    outpoint, state
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (outpoint)
+    DO UPDATE SET state = EXCLUDED.state;
+
+-- name: FetchChannelOpeningState :one
+SELECT state
+FROM channel_opening_states
+WHERE outpoint = $1;
+
+-- name: DeleteChannelOpeningState :exec
+DELETE FROM channel_opening_states
+WHERE outpoint = $1;
+
+/* ─────────────────────────────────────────────
+   channel_forwarding_policies table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: UpsertChannelForwardingPolicy :exec
+INSERT INTO channel_forwarding_policies (
+    chan_id, min_htlc_msat, max_htlc_msat, base_fee_msat, fee_rate,
+    time_lock_delta
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (chan_id)
+    DO UPDATE SET
+        min_htlc_msat = EXCLUDED.min_htlc_msat,
+        max_htlc_msat = EXCLUDED.max_htlc_msat,
+        base_fee_msat = EXCLUDED.base_fee_msat,
+        fee_rate = EXCLUDED.fee_rate,
+        time_lock_delta = EXCLUDED.time_lock_delta;
+
+-- name: FetchChannelForwardingPolicy :one
+SELECT *
+FROM channel_forwarding_policies
+WHERE chan_id = $1;
+
+-- name: DeleteChannelForwardingPolicy :exec
+DELETE FROM channel_forwarding_policies
+WHERE chan_id = $1;
@@ -0,0 +1,104 @@
+/* ─────────────────────────────────────────────
+   forwarding_events table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertForwardingEvent :one
+INSERT INTO forwarding_events (
+    timestamp, incoming_chan_id, outgoing_chan_id, incoming_peer,
+    outgoing_peer, amt_in_msat, amt_out_msat, incoming_htlc_id,
+    outgoing_htlc_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id;
+
+-- name: FetchForwardingEvent :one
+SELECT *
+FROM forwarding_events
+WHERE id = $1;
+
+-- name: FetchForwardingEvents :many
+SELECT *
+FROM forwarding_events
+WHERE timestamp >= @start_time AND timestamp <= @end_time
+ORDER BY timestamp, id
+LIMIT @num_limit OFFSET @num_offset;
+
+-- name: FetchForwardingEventsByIncomingChans :many
+SELECT *
+FROM forwarding_events
+WHERE timestamp >= @start_time AND timestamp <= @end_time
+  AND incoming_chan_id IN (sqlc.slice('incoming_chan_ids')/*SLICE:incoming_chan_ids*/)
+ORDER BY timestamp, id;
+
+-- name: FetchForwardingEventsByOutgoingChans :many
+SELECT *
+FROM forwarding_events
+WHERE timestamp >= @start_time AND timestamp <= @end_time
+  AND outgoing_chan_id IN (sqlc.slice('outgoing_chan_ids')/*SLICE:outgoing_chan_ids*/)
+ORDER BY timestamp, id;
+
+-- name: FetchForwardingEventsByChans :many
+SELECT *
+FROM forwarding_events
+WHERE timestamp >= @start_time AND timestamp <= @end_time
+  AND incoming_chan_id IN (sqlc.slice('incoming_chan_ids')/*SLICE:incoming_chan_ids*/)
+  AND outgoing_chan_id IN (sqlc.slice('outgoing_chan_ids')/*SLICE:outgoing_chan_ids*/)
+ORDER BY timestamp, id;
+
+-- name: DeleteForwardingEventsUpTo :exec
+DELETE FROM forwarding_events
+WHERE timestamp < $1 OR (timestamp = $1 AND id <= $2);
+
+-- name: AggregateForwardingEventsByIncomingChan :many
+SELECT
+    incoming_chan_id AS chan_id,
+    COUNT(*) AS num_events,
+    CAST(COALESCE(SUM(amt_in_msat), 0) AS BIGINT) AS amt_in_msat,
+    CAST(COALESCE(SUM(amt_out_msat), 0) AS BIGINT) AS amt_out_msat
+FROM forwarding_events
+WHERE timestamp >= @start_time AND timestamp <= @end_time
+GROUP BY incoming_chan_id;
+
+-- name: AggregateForwardingEventsByOutgoingChan :many
+SELECT
+    outgoing_chan_id AS chan_id,
+    COUNT(*) AS num_events,
+    CAST(COALESCE(SUM(amt_in_msat), 0) AS BIGINT) AS amt_in_msat,
+    CAST(COALESCE(SUM(amt_out_msat), 0) AS BIGINT) AS amt_out_msat
+FROM forwarding_events
+WHERE timestamp >= @start_time AND timestamp <= @end_time
+GROUP BY outgoing_chan_id;
+
+-- name: AggregateForwardingEventsByIncomingPeer :many
+SELECT
+    incoming_peer AS peer,
+    COUNT(*) AS num_events,
+    CAST(COALESCE(SUM(amt_in_msat), 0) AS BIGINT) AS amt_in_msat,
+    CAST(COALESCE(SUM(amt_out_msat), 0) AS BIGINT) AS amt_out_msat
+FROM forwarding_events
+WHERE timestamp >= @start_time AND timestamp <= @end_time
+  AND incoming_peer IS NOT NULL
+GROUP BY incoming_peer;
+
+-- name: AggregateForwardingEventsByOutgoingPeer :many
+SELECT
+    outgoing_peer AS peer,
+    COUNT(*) AS num_events,
+    CAST(COALESCE(SUM(amt_in_msat), 0) AS BIGINT) AS amt_in_msat,
+    CAST(COALESCE(SUM(amt_out_msat), 0) AS BIGINT) AS amt_out_msat
+FROM forwarding_events
+WHERE timestamp >= @start_time AND timestamp <= @end_time
+  AND outgoing_peer IS NOT NULL
+GROUP BY outgoing_peer;
+
+-- name: AggregateForwardingEventsByTime :many
+SELECT
+    CAST(timestamp / @bucket_size AS BIGINT) AS bucket,
+    COUNT(*) AS num_events,
+    CAST(COALESCE(SUM(amt_in_msat), 0) AS BIGINT) AS amt_in_msat,
+    CAST(COALESCE(SUM(amt_out_msat), 0) AS BIGINT) AS amt_out_msat
+FROM forwarding_events
+WHERE timestamp >= @start_time AND timestamp <= @end_time
+GROUP BY bucket
+ORDER BY bucket;
@@ -0,0 +1,170 @@
+-- name: InsertPayment :one
+INSERT INTO payments (
+    payment_hash, amount_msat, created_at, status, payment_request, offer,
+    payer_note, trampoline_relay
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id;
+
+-- name: InsertPaymentFirstHopCustomRecord :exec
+INSERT INTO payment_first_hop_custom_records (
+    payment_id, key, value
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: FetchPaymentFirstHopCustomRecords :many
+SELECT *
+FROM payment_first_hop_custom_records
+WHERE payment_id = $1;
+
+-- name: FetchPayment :one
+SELECT *
+FROM payments
+WHERE payment_hash = $1;
+
+-- name: FilterPayments :many
+SELECT
+    payments.*
+FROM payments
+WHERE trampoline_relay = FALSE AND (
+    id >= sqlc.narg('index_get') OR
+    sqlc.narg('index_get') IS NULL
+) AND (
+    id <= sqlc.narg('index_let') OR
+    sqlc.narg('index_let') IS NULL
+) AND (
+    status = sqlc.narg('status') OR
+    sqlc.narg('status') IS NULL
+) AND (
+    created_at >= sqlc.narg('created_after') OR
+    sqlc.narg('created_after') IS NULL
+) AND (
+    created_at <= sqlc.narg('created_before') OR
+    sqlc.narg('created_before') IS NULL
+)
+ORDER BY
+CASE
+    WHEN sqlc.narg('reverse') = FALSE OR sqlc.narg('reverse') IS NULL THEN id
+    ELSE NULL
+    END ASC,
+CASE
+    WHEN sqlc.narg('reverse') = TRUE THEN id
+    ELSE NULL
+END DESC
+LIMIT @num_limit;
+
+-- name: FetchInFlightPayments :many
+SELECT *
+FROM payments
+WHERE status = 1 OR status = 2
+ORDER BY id;
+
+-- name: CountPayments :one
+SELECT COUNT(*)
+FROM payments
+WHERE trampoline_relay = FALSE;
+
+-- name: UpdatePaymentStatus :exec
+UPDATE payments
+SET status = $2
+WHERE id = $1;
+
+-- name: UpdatePaymentFailReason :exec
+UPDATE payments
+SET fail_reason = $2
+WHERE id = $1;
+
+-- name: DeletePayment :exec
+DELETE FROM payments
+WHERE id = $1;
+
+-- name: DeletePaymentsByStatus :execresult
+DELETE FROM payments
+WHERE status = $1 AND trampoline_relay = FALSE;
+
+-- name: InsertPaymentHtlcAttempt :one
+INSERT INTO payment_htlc_attempts (
+    payment_id, attempt_id, session_key, attempt_time, payment_hash,
+    route_total_time_lock, route_total_amount, route_source_key,
+    first_hop_amount_msat
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id;
+
+-- name: InsertPaymentAttemptFirstHopCustomRecord :exec
+INSERT INTO payment_attempt_first_hop_custom_records (
+    htlc_attempt_id, key, value
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: InsertPaymentRouteHop :one
+INSERT INTO payment_route_hops (
+    htlc_attempt_id, hop_index, pub_key, scid, outgoing_time_lock,
+    amt_to_forward, legacy_payload, meta_data, mpp_payment_addr,
+    mpp_total_msat, amp_root_share, amp_set_id, amp_child_index,
+    encrypted_data, blinding_point, blinded_path_total_amt, trampoline_onion
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16,
+    $17
+) RETURNING id;
+
+-- name: InsertPaymentHopCustomRecord :exec
+INSERT INTO payment_hop_custom_records (
+    hop_id, key, value
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: FetchPaymentHtlcAttempts :many
+SELECT *
+FROM payment_htlc_attempts
+WHERE payment_id = $1
+ORDER BY attempt_id;
+
+-- name: FetchPaymentAttemptFirstHopCustomRecords :many
+SELECT r.*
+FROM payment_htlc_attempts a
+JOIN payment_attempt_first_hop_custom_records r ON a.id = r.htlc_attempt_id
+WHERE a.payment_id = $1;
+
+-- name: FetchPaymentRouteHops :many
+SELECT h.*
+FROM payment_htlc_attempts a
+JOIN payment_route_hops h ON a.id = h.htlc_attempt_id
+WHERE a.payment_id = $1
+ORDER BY h.htlc_attempt_id, h.hop_index;
+
+-- name: FetchPaymentHopCustomRecords :many
+SELECT r.*
+FROM payment_htlc_attempts a
+JOIN payment_route_hops h ON a.id = h.htlc_attempt_id
+JOIN payment_hop_custom_records r ON h.id = r.hop_id
+WHERE a.payment_id = $1;
+
+-- name: SettlePaymentHtlcAttempt :exec
+UPDATE payment_htlc_attempts
+SET resolution_type = 1,
+    resolution_time = $3,
+    settle_preimage = $4
+WHERE payment_id = $1 AND attempt_id = $2;
+
+-- name: FailPaymentHtlcAttempt :exec
+UPDATE payment_htlc_attempts
+SET resolution_type = 2,
+    resolution_time = $3,
+    htlc_fail_reason = $4,
+    failure_source_index = $5,
+    failure_msg = $6
+WHERE payment_id = $1 AND attempt_id = $2;
+
+-- name: DeleteFailedPaymentHtlcAttempts :exec
+DELETE FROM payment_htlc_attempts
+WHERE payment_id = $1 AND resolution_type = 2;
+
+-- name: DeleteFailedPaymentHtlcAttemptsByStatus :exec
+DELETE FROM payment_htlc_attempts
+WHERE resolution_type = 2 AND payment_id IN (
+    SELECT id FROM payments WHERE status = $1 AND trampoline_relay = FALSE
+);
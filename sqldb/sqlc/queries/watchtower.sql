@@ -0,0 +1,405 @@
+/* ─────────────────────────────────────────────
+   wtclient_towers table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertWtClientTower :one
+INSERT INTO wtclient_towers (
+    pub_key, addresses, status
+) VALUES (
+    $1, $2, $3
+) RETURNING id;
+
+-- name: GetWtClientTowerByID :one
+SELECT *
+FROM wtclient_towers
+WHERE id = $1;
+
+-- name: GetWtClientTowerByPubKey :one
+SELECT *
+FROM wtclient_towers
+WHERE pub_key = $1;
+
+-- name: ListWtClientTowers :many
+SELECT *
+FROM wtclient_towers
+ORDER BY id;
+
+-- name: UpdateWtClientTower :exec
+UPDATE wtclient_towers
+SET addresses = $2, status = $3
+WHERE id = $1;
+
+-- name: DeleteWtClientTower :exec
+DELETE FROM wtclient_towers
+WHERE id = $1;
+
+/* ─────────────────────────────────────────────
+   wtclient_session_key_indexes table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: UpsertWtClientSessionKeyIndex :exec
+INSERT INTO wtclient_session_key_indexes (
+    tower_id, blob_type, key_index
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (tower_id, blob_type)
+    DO UPDATE SET key_index = EXCLUDED.key_index;
+
+-- name: GetWtClientSessionKeyIndex :one
+SELECT key_index
+FROM wtclient_session_key_indexes
+WHERE tower_id = $1 AND blob_type = $2;
+
+-- name: DeleteWtClientSessionKeyIndex :exec
+DELETE FROM wtclient_session_key_indexes
+WHERE tower_id = $1 AND blob_type = $2;
+
+-- name: DeleteWtClientTowerSessionKeyIndexes :exec
+DELETE FROM wtclient_session_key_indexes
+WHERE tower_id = $1;
+
+-- name: GetWtClientSessionKeyIndexSequence :one
+SELECT last_index
+FROM wtclient_session_key_index_sequence
+WHERE id = 0;
+
+-- name: SetWtClientSessionKeyIndexSequence :exec
+INSERT INTO wtclient_session_key_index_sequence (
+    id, last_index
+) VALUES (
+    0, $1
+)
+ON CONFLICT (id)
+    DO UPDATE SET last_index = EXCLUDED.last_index;
+
+/* ─────────────────────────────────────────────
+   wtclient_sessions table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertWtClientSession :one
+INSERT INTO wtclient_sessions (
+    session_id, tower_id, key_index, blob_type, max_updates, reward_base,
+    reward_rate, sweep_fee_rate, reward_pk_script, seq_num,
+    tower_last_applied, status, rogue_update_count, closable_height
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+) RETURNING id;
+
+-- name: GetWtClientSession :one
+SELECT *
+FROM wtclient_sessions
+WHERE session_id = $1;
+
+-- name: ListWtClientSessions :many
+SELECT *
+FROM wtclient_sessions
+ORDER BY id;
+
+-- name: ListWtClientTowerSessions :many
+SELECT *
+FROM wtclient_sessions
+WHERE tower_id = $1
+ORDER BY id;
+
+-- name: ListWtClientChannelSessions :many
+SELECT s.*
+FROM wtclient_sessions s
+WHERE s.id IN (
+    SELECT r.session_id
+    FROM wtclient_acked_ranges r
+    WHERE r.channel_id = $1
+)
+ORDER BY s.id;
+
+-- name: ListWtClientClosableSessions :many
+SELECT session_id, closable_height
+FROM wtclient_sessions
+WHERE closable_height IS NOT NULL
+ORDER BY id;
+
+-- name: UpdateWtClientSessionSeqNum :exec
+UPDATE wtclient_sessions
+SET seq_num = $2
+WHERE id = $1;
+
+-- name: UpdateWtClientSessionTowerLastApplied :exec
+UPDATE wtclient_sessions
+SET tower_last_applied = $2
+WHERE id = $1;
+
+-- name: UpdateWtClientSessionStatus :exec
+UPDATE wtclient_sessions
+SET status = $2
+WHERE id = $1;
+
+-- name: UpdateWtClientSessionRogueUpdateCount :exec
+UPDATE wtclient_sessions
+SET rogue_update_count = $2
+WHERE id = $1;
+
+-- name: UpdateWtClientSessionClosableHeight :exec
+UPDATE wtclient_sessions
+SET closable_height = $2
+WHERE id = $1;
+
+-- name: DeleteWtClientSession :exec
+DELETE FROM wtclient_sessions
+WHERE id = $1;
+
+/* ─────────────────────────────────────────────
+   wtclient_committed_updates table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertWtClientCommittedUpdate :exec
+INSERT INTO wtclient_committed_updates (
+    session_id, seq_num, channel_id, commit_height, hint, encrypted_blob
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
+
+-- name: GetWtClientCommittedUpdate :one
+SELECT *
+FROM wtclient_committed_updates
+WHERE session_id = $1 AND seq_num = $2;
+
+-- name: ListWtClientCommittedUpdates :many
+SELECT *
+FROM wtclient_committed_updates
+WHERE session_id = $1
+ORDER BY seq_num;
+
+-- name: CountWtClientCommittedUpdates :one
+SELECT COUNT(*)
+FROM wtclient_committed_updates
+WHERE session_id = $1;
+
+-- name: DeleteWtClientCommittedUpdate :exec
+DELETE FROM wtclient_committed_updates
+WHERE session_id = $1 AND seq_num = $2;
+
+-- name: DeleteWtClientCommittedUpdates :exec
+DELETE FROM wtclient_committed_updates
+WHERE session_id = $1;
+
+/* ─────────────────────────────────────────────
+   wtclient_channels table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertWtClientChannel :one
+INSERT INTO wtclient_channels (
+    channel_id, sweep_pk_script, closed_height, max_commit_height
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id;
+
+-- name: GetWtClientChannel :one
+SELECT *
+FROM wtclient_channels
+WHERE channel_id = $1;
+
+-- name: ListWtClientOpenChannels :many
+SELECT *
+FROM wtclient_channels
+WHERE closed_height IS NULL
+ORDER BY id;
+
+-- name: ListWtClientSessionChannels :many
+SELECT c.*
+FROM wtclient_channels c
+WHERE c.id IN (
+    SELECT r.channel_id
+    FROM wtclient_acked_ranges r
+    WHERE r.session_id = $1
+)
+ORDER BY c.id;
+
+-- name: UpdateWtClientChannelClosedHeight :exec
+UPDATE wtclient_channels
+SET closed_height = $2
+WHERE id = $1;
+
+-- name: UpdateWtClientChannelMaxCommitHeight :exec
+UPDATE wtclient_channels
+SET max_commit_height = @max_commit_height
+WHERE channel_id = @channel_id
+  AND (
+    max_commit_height IS NULL OR
+    max_commit_height < @max_commit_height
+  );
+
+-- name: DeleteWtClientChannel :exec
+DELETE FROM wtclient_channels
+WHERE id = $1;
+
+/* ─────────────────────────────────────────────
+   wtclient_acked_ranges table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: UpsertWtClientAckedRange :exec
+INSERT INTO wtclient_acked_ranges (
+    session_id, channel_id, range_start, range_end
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (session_id, channel_id, range_start)
+    DO UPDATE SET range_end = EXCLUDED.range_end;
+
+-- name: DeleteWtClientAckedRange :exec
+DELETE FROM wtclient_acked_ranges
+WHERE session_id = $1 AND channel_id = $2 AND range_start = $3;
+
+-- name: ListWtClientAckedRanges :many
+SELECT *
+FROM wtclient_acked_ranges
+WHERE session_id = $1 AND channel_id = $2
+ORDER BY range_start;
+
+-- name: ListWtClientSessionAckedRanges :many
+SELECT c.channel_id, r.range_start, r.range_end
+FROM wtclient_acked_ranges r
+JOIN wtclient_channels c ON c.id = r.channel_id
+WHERE r.session_id = $1
+ORDER BY r.channel_id, r.range_start;
+
+-- name: CountWtClientChannelAckedRanges :one
+SELECT COUNT(*)
+FROM wtclient_acked_ranges
+WHERE channel_id = $1;
+
+-- name: IsWtClientBackupAcked :one
+SELECT EXISTS (
+    SELECT 1
+    FROM wtclient_acked_ranges r
+    JOIN wtclient_sessions s ON s.id = r.session_id
+    JOIN wtclient_channels c ON c.id = r.channel_id
+    WHERE s.session_id = @session_id
+      AND c.channel_id = @channel_id
+      AND r.range_start <= @commit_height
+      AND r.range_end >= @commit_height
+);
+
+/* ─────────────────────────────────────────────
+   wtclient_backup_queue table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: InsertWtClientBackupQueueItem :exec
+INSERT INTO wtclient_backup_queue (
+    namespace, queue_index, channel_id, commit_height
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: GetWtClientBackupQueueInfo :one
+SELECT
+    COUNT(*) AS num_items,
+    CAST(COALESCE(MIN(queue_index), 0) AS BIGINT) AS head_index,
+    CAST(COALESCE(MAX(queue_index), 0) AS BIGINT) AS tail_index
+FROM wtclient_backup_queue
+WHERE namespace = $1;
+
+-- name: ListWtClientBackupQueueItems :many
+SELECT *
+FROM wtclient_backup_queue
+WHERE namespace = @namespace
+ORDER BY queue_index
+LIMIT @num_limit;
+
+-- name: DeleteWtClientBackupQueueItems :exec
+DELETE FROM wtclient_backup_queue
+WHERE namespace = $1 AND queue_index <= $2;
+
+/* ─────────────────────────────────────────────
+   wtserver_sessions table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: UpsertWtServerSession :exec
+INSERT INTO wtserver_sessions (
+    session_id, blob_type, max_updates, reward_base, reward_rate,
+    sweep_fee_rate, last_applied, client_last_applied, reward_address
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (session_id)
+    DO UPDATE SET
+        blob_type = EXCLUDED.blob_type,
+        max_updates = EXCLUDED.max_updates,
+        reward_base = EXCLUDED.reward_base,
+        reward_rate = EXCLUDED.reward_rate,
+        sweep_fee_rate = EXCLUDED.sweep_fee_rate,
+        last_applied = EXCLUDED.last_applied,
+        client_last_applied = EXCLUDED.client_last_applied,
+        reward_address = EXCLUDED.reward_address;
+
+-- name: GetWtServerSession :one
+SELECT *
+FROM wtserver_sessions
+WHERE session_id = $1;
+
+-- name: UpdateWtServerSessionLastApplied :exec
+UPDATE wtserver_sessions
+SET last_applied = $2, client_last_applied = $3
+WHERE id = $1;
+
+-- name: DeleteWtServerSession :exec
+DELETE FROM wtserver_sessions
+WHERE id = $1;
+
+/* ─────────────────────────────────────────────
+   wtserver_state_updates table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: UpsertWtServerStateUpdate :exec
+INSERT INTO wtserver_state_updates (
+    session_id, hint, seq_num, last_applied, encrypted_blob
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (hint, session_id)
+    DO UPDATE SET
+        seq_num = EXCLUDED.seq_num,
+        last_applied = EXCLUDED.last_applied,
+        encrypted_blob = EXCLUDED.encrypted_blob;
+
+-- name: ListWtServerStateUpdatesByHint :many
+SELECT sqlc.embed(s), u.seq_num, u.encrypted_blob
+FROM wtserver_state_updates u
+JOIN wtserver_sessions s ON s.id = u.session_id
+WHERE u.hint = $1
+ORDER BY s.session_id;
+
+-- name: ListWtServerSessionStateUpdates :many
+SELECT *
+FROM wtserver_state_updates
+WHERE session_id = $1
+ORDER BY hint;
+
+/* ─────────────────────────────────────────────
+   wtserver_lookout_tip table queries
+   ─────────────────────────────────────────────
+*/
+
+-- name: UpsertWtServerLookoutTip :exec
+INSERT INTO wtserver_lookout_tip (
+    id, block_hash, block_height
+) VALUES (
+    0, $1, $2
+)
+ON CONFLICT (id)
+    DO UPDATE SET
+        block_hash = EXCLUDED.block_hash,
+        block_height = EXCLUDED.block_height;
+
+-- name: GetWtServerLookoutTip :one
+SELECT block_hash, block_height
+FROM wtserver_lookout_tip
+WHERE id = 0;
@@ -20,21 +20,25 @@ import (
 	"github.com/flokiorg/flnd/lnrpc/devrpc"
 	"github.com/flokiorg/flnd/lnrpc/invoicesrpc"
 	"github.com/flokiorg/flnd/lnrpc/neutrinorpc"
+	"github.com/flokiorg/flnd/lnrpc/offersrpc"
 	"github.com/flokiorg/flnd/lnrpc/peersrpc"
 	"github.com/flokiorg/flnd/lnrpc/routerrpc"
 	"github.com/flokiorg/flnd/lnrpc/signrpc"
 	"github.com/flokiorg/flnd/lnrpc/walletrpc"
 	"github.com/flokiorg/flnd/lnrpc/watchtowerrpc"
 	"github.com/flokiorg/flnd/lnrpc/wtclientrpc"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
 	"github.com/flokiorg/flnd/lnwire"
 	"github.com/flokiorg/flnd/macaroons"
 	"github.com/flokiorg/flnd/netann"
+	"github.com/flokiorg/flnd/offers"
 	"github.com/flokiorg/flnd/routing"
 	"github.com/flokiorg/flnd/sweep"
 	"github.com/flokiorg/flnd/watchtower"
 	"github.com/flokiorg/flnd/watchtower/wtclient"
 	"github.com/flokiorg/go-flokicoin/chaincfg"
 	flog "github.com/flokiorg/go-flokicoin/log/v2"
+	"github.com/flokiorg/go-flokicoin/wire"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -72,6 +76,10 @@ type subRPCServerConfigs struct {
 	// as a gRPC service.
 	PeersRPC *peersrpc.Config `group:"peersrpc" namespace:"peersrpc"`
 
+	// OffersRPC is a sub-RPC server that exposes methods to create and
+	// manage BOLT 12 offers as a gRPC service.
+	OffersRPC *offersrpc.Config `group:"offersrpc" namespace:"offersrpc"`
+
 	// NeutrinoKitRPC is a sub-RPC server that exposes functionality allowing
 	// a client to interact with a running neutrino node.
 	NeutrinoKitRPC *neutrinorpc.Config `group:"neutrinorpc" namespace:"neutrinorpc"`
@@ -129,7 +137,9 @@ func (s *subRPCServerConfigs) PopulateDependencies(cfg *Config,
 	parseAddr func(addr string) (net.Addr, error),
 	rpcLogger flog.Logger, aliasMgr *aliasmgr.Manager,
 	auxDataParser fn.Option[AuxDataParser],
-	invoiceHtlcModifier *invoices.HtlcModificationInterceptor) error {
+	invoiceHtlcModifier *invoices.HtlcModificationInterceptor,
+	offersMgr *offers.Manager,
+	bumpFundingFee func(wire.OutPoint, chainfee.SatPerKWeight) error) error {
 
 	// First, we'll use reflect to obtain a version of the config struct
 	// that allows us to programmatically inspect its fields.
@@ -212,6 +222,9 @@ func (s *subRPCServerConfigs) PopulateDependencies(cfg *Config,
 			subCfgValue.FieldByName("ChanStateDB").Set(
 				reflect.ValueOf(chanStateDB),
 			)
+			subCfgValue.FieldByName("BumpFundingFee").Set(
+				reflect.ValueOf(bumpFundingFee),
+			)
 
 		case *autopilotrpc.Config:
 			subCfgValue := extractReflectValue(subCfg)
@@ -368,6 +381,17 @@ func (s *subRPCServerConfigs) PopulateDependencies(cfg *Config,
 				reflect.ValueOf(updateNodeAnnouncement),
 			)
 
+		case *offersrpc.Config:
+			subCfgValue := extractReflectValue(subCfg)
+
+			subCfgValue.FieldByName("OffersMgr").Set(
+				reflect.ValueOf(offersMgr),
+			)
+
+			subCfgValue.FieldByName("ChainParams").Set(
+				reflect.ValueOf(activeNetParams),
+			)
+
 		default:
 			return fmt.Errorf("unknown field: %v, %T", fieldName,
 				cfg)
@@ -171,6 +171,15 @@ type BumpRequest struct {
 	// Immediate is used to specify that the tx should be broadcast
 	// immediately.
 	Immediate bool
+
+	// FeeFunction specifies the fee function used to bump the fee rate of
+	// the tx.
+	FeeFunction FeeFunctionType
+
+	// ExtraOutputs are outputs paid from the value of the inputs on top
+	// of the budget, such as batched wallet payments. They stay the same
+	// across fee bumps.
+	ExtraOutputs []*wire.TxOut
 }
 
 // MaxFeeRateAllowed returns the maximum fee rate allowed for the given
@@ -204,7 +213,7 @@ func (r *BumpRequest) MaxFeeRateAllowed() (chainfee.SatPerKWeight, error) {
 	// Get the size of the sweep tx, which will be used to calculate the
 	// budget fee rate.
 	size, err := calcSweepTxWeight(
-		r.Inputs, sweepAddrs,
+		r.Inputs, r.ExtraOutputs, sweepAddrs,
 	)
 	if err != nil {
 		return 0, err
@@ -230,9 +239,9 @@ func (r *BumpRequest) MaxFeeRateAllowed() (chainfee.SatPerKWeight, error) {
 	return maxFeeRateAllowed, nil
 }
 
-// calcSweepTxWeight calculates the weight of the sweep tx. It assumes a
-// sweeping tx always has a single output(change).
-func calcSweepTxWeight(inputs []input.Input,
+// calcSweepTxWeight calculates the weight of the sweep tx. Besides the given
+// extra outputs, it assumes a sweeping tx always has a single output(change).
+func calcSweepTxWeight(inputs []input.Input, outputs []*wire.TxOut,
 	outputPkScript [][]byte) (lntypes.WeightUnit, error) {
 
 	// Use a const fee rate as we only use the weight estimator to
@@ -240,14 +249,14 @@ func calcSweepTxWeight(inputs []input.Input,
 	const feeRate = 1
 
 	// Initialize the tx weight estimator with,
-	// - nil outputs as we only have one single change output.
+	// - the extra outputs besides the single change output.
 	// - const fee rate as we don't care about the fees here.
 	// - 0 maxfeerate as we don't care about fees here.
 	//
 	// TODO(yy): we should refactor the weight estimator to not require a
 	// fee rate and max fee rate and make it a pure tx weight calculator.
 	_, estimator, err := getWeightEstimate(
-		inputs, nil, feeRate, 0, outputPkScript,
+		inputs, outputs, feeRate, 0, outputPkScript,
 	)
 	if err != nil {
 		return 0, err
@@ -523,16 +532,14 @@ func (t *TxPublisher) initializeFeeFunction(
 		t.currentHeight.Load(), req.DeadlineHeight,
 	)
 
-	log.Debugf("Initializing fee function with conf target=%v, budget=%v, "+
-		"maxFeeRateAllowed=%v", confTarget, req.Budget,
-		maxFeeRateAllowed)
+	log.Debugf("Initializing %v fee function with conf target=%v, "+
+		"budget=%v, maxFeeRateAllowed=%v", req.FeeFunction, confTarget,
+		req.Budget, maxFeeRateAllowed)
 
-	// Initialize the fee function and return it.
-	//
-	// TODO(yy): return based on differet req.Strategy?
-	return NewLinearFeeFunction(
-		maxFeeRateAllowed, confTarget, t.cfg.Estimator,
-		req.StartingFeeRate,
+	// Initialize the fee function requested and return it.
+	return NewFeeFunction(
+		req.FeeFunction, maxFeeRateAllowed, confTarget,
+		t.cfg.Estimator, req.StartingFeeRate,
 	)
 }
 
@@ -622,7 +629,7 @@ func (t *TxPublisher) createAndCheckTx(r *monitorRecord) (*sweepTxCtx, error) {
 	// Create the sweep tx with max fee rate of 0 as the fee function
 	// guarantees the fee rate used here won't exceed the max fee rate.
 	sweepCtx, err := t.createSweepTx(
-		req.Inputs, req.DeliveryAddress, f.FeeRate(),
+		req.Inputs, req.ExtraOutputs, req.DeliveryAddress, f.FeeRate(),
 	)
 	if err != nil {
 		return sweepCtx, fmt.Errorf("create sweep tx: %w", err)
@@ -1495,16 +1502,16 @@ type sweepTxCtx struct {
 	outpointToTxIndex map[wire.OutPoint]int
 }
 
-// createSweepTx creates a sweeping tx based on the given inputs, change
-// address and fee rate.
+// createSweepTx creates a sweeping tx based on the given inputs, extra
+// outputs, change address and fee rate.
 func (t *TxPublisher) createSweepTx(inputs []input.Input,
-	changePkScript lnwallet.AddrWithKey,
+	outputs []*wire.TxOut, changePkScript lnwallet.AddrWithKey,
 	feeRate chainfee.SatPerKWeight) (*sweepTxCtx, error) {
 
 	// Validate and calculate the fee and change amount.
 	txFee, changeOutputsOpt, locktimeOpt, err := prepareSweepTx(
-		inputs, changePkScript, feeRate, t.currentHeight.Load(),
-		t.cfg.AuxSweeper,
+		inputs, outputs, changePkScript, feeRate,
+		t.currentHeight.Load(), t.cfg.AuxSweeper,
 	)
 	if err != nil {
 		return nil, err
@@ -1553,6 +1560,11 @@ func (t *TxPublisher) createSweepTx(inputs []input.Input,
 		})
 	}
 
+	// Add the extra outputs given, if any.
+	for _, o := range outputs {
+		sweepTx.AddTxOut(o)
+	}
+
 	// If we have change outputs to add, then add it the sweep transaction
 	// here.
 	changeOutputsOpt.WhenSome(func(changeOuts []SweepOutput) {
@@ -1642,9 +1654,9 @@ func (t *TxPublisher) createSweepTx(inputs []input.Input,
 // 3. check the inputs cover the outputs.
 //
 // NOTE: if the change amount is below dust, it will be added to the tx fee.
-func prepareSweepTx(inputs []input.Input, changePkScript lnwallet.AddrWithKey,
-	feeRate chainfee.SatPerKWeight, currentHeight int32,
-	auxSweeper fn.Option[AuxSweeper]) (
+func prepareSweepTx(inputs []input.Input, outputs []*wire.TxOut,
+	changePkScript lnwallet.AddrWithKey, feeRate chainfee.SatPerKWeight,
+	currentHeight int32, auxSweeper fn.Option[AuxSweeper]) (
 	chainutil.Amount, fn.Option[[]SweepOutput], fn.Option[int32], error) {
 
 	noChange := fn.None[[]SweepOutput]()
@@ -1682,11 +1694,10 @@ func prepareSweepTx(inputs []input.Input, changePkScript lnwallet.AddrWithKey,
 		changePkScripts = append(changePkScripts, o.TxOut.PkScript)
 	})
 
-	// Creating a weight estimator with nil outputs and zero max fee rate.
-	// We don't allow adding customized outputs in the sweeping tx, and the
-	// fee rate is already being managed before we get here.
+	// Creating a weight estimator with the extra outputs and zero max fee
+	// rate, as the fee rate is already being managed before we get here.
 	inputs, estimator, err := getWeightEstimate(
-		inputs, nil, feeRate, 0, changePkScripts,
+		inputs, outputs, feeRate, 0, changePkScripts,
 	)
 	if err != nil {
 		return 0, noChange, noLocktime, err
@@ -1710,6 +1721,11 @@ func prepareSweepTx(inputs []input.Input, changePkScript lnwallet.AddrWithKey,
 		requiredOutput += chainutil.Amount(o.Value)
 	})
 
+	// The extra outputs must be covered by the inputs as well.
+	for _, o := range outputs {
+		requiredOutput += chainutil.Amount(o.Value)
+	}
+
 	// Go through each input and check if the required lock times have
 	// reached and are the same.
 	for _, o := range inputs {
@@ -137,14 +137,15 @@ func TestCalcSweepTxWeight(t *testing.T) {
 
 	// Use a wrong change script to test the error case.
 	weight, err := calcSweepTxWeight(
-		[]input.Input{&inp}, [][]byte{{0x00}},
+		[]input.Input{&inp}, nil, [][]byte{{0x00}},
 	)
 	require.Error(t, err)
 	require.Zero(t, weight)
 
 	// Use a correct change script to test the success case.
 	weight, err = calcSweepTxWeight(
-		[]input.Input{&inp}, [][]byte{changePkScript.DeliveryAddress},
+		[]input.Input{&inp}, nil,
+		[][]byte{changePkScript.DeliveryAddress},
 	)
 	require.NoError(t, err)
 
@@ -166,7 +167,8 @@ func TestBumpRequestMaxFeeRateAllowed(t *testing.T) {
 
 	// The weight is 487.
 	weight, err := calcSweepTxWeight(
-		[]input.Input{&inp}, [][]byte{changePkScript.DeliveryAddress},
+		[]input.Input{&inp}, nil,
+		[][]byte{changePkScript.DeliveryAddress},
 	)
 	require.NoError(t, err)
 
@@ -2151,7 +2153,7 @@ func TestPrepareSweepTx(t *testing.T) {
 				// Calculate expected weight - only regular
 				// change output, no extra.
 				expectedWeight, err := calcSweepTxWeight(
-					[]input.Input{&inp1, &inp2},
+					[]input.Input{&inp1, &inp2}, nil,
 					[][]byte{
 						changePkScript.DeliveryAddress,
 					},
@@ -2181,7 +2183,7 @@ func TestPrepareSweepTx(t *testing.T) {
 				// Calculate expected weight - includes both
 				// regular change and extra output.
 				expectedWeight, err := calcSweepTxWeight(
-					[]input.Input{&inp1, &inp2},
+					[]input.Input{&inp1, &inp2}, nil,
 					[][]byte{changePkScript.DeliveryAddress,
 						changePkScript.DeliveryAddress},
 				)
@@ -2258,6 +2260,7 @@ func TestPrepareSweepTx(t *testing.T) {
 
 			fee, changeOuts, locktime, err := prepareSweepTx(
 				tc.inputs,
+				nil,
 				tc.changePkScript,
 				tc.feeRate,
 				tc.currentHeight,
@@ -3,6 +3,8 @@ package sweep
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/bits"
 
 	"github.com/flokiorg/flnd/fn"
 	"github.com/flokiorg/flnd/lnwallet/chainfee"
@@ -17,6 +19,10 @@ var (
 
 	// ErrZeroFeeRateDelta is returned when the fee rate delta is zero.
 	ErrZeroFeeRateDelta = errors.New("fee rate delta is zero")
+
+	// ErrUnknownFeeFunction is returned when a fee function of an unknown
+	// type is requested.
+	ErrUnknownFeeFunction = errors.New("unknown fee function")
 )
 
 // mSatPerKWeight represents a fee rate in msat/kw.
@@ -66,6 +72,97 @@ type FeeFunction interface {
 	IncreaseFeeRate(confTarget uint32) (bool, error)
 }
 
+// FeeFunctionType specifies the algorithm the fee bumper uses to raise the fee
+// rate of a sweeping tx from its starting fee rate to the max fee rate allowed
+// by its budget as the deadline approaches.
+type FeeFunctionType uint8
+
+const (
+	// FeeFunctionLinear raises the fee rate by the same amount each block.
+	// This is the default fee function.
+	FeeFunctionLinear FeeFunctionType = iota
+
+	// FeeFunctionExponential raises the fee rate by a constant factor
+	// each block, so most of the budget is only spent in the last few
+	// blocks before the deadline.
+	FeeFunctionExponential
+
+	// FeeFunctionCubic follows a cubic ease-in curve. It stays close to
+	// the starting fee rate for longer than the linear function, then
+	// accelerates towards the max fee rate.
+	FeeFunctionCubic
+
+	// FeeFunctionStep keeps the fee rate flat and raises it by one step
+	// each time the number of blocks left until the deadline is halved.
+	FeeFunctionStep
+
+	// FeeFunctionMempool re-anchors the fee rate to the fee estimator's
+	// estimate for the remaining blocks at every new block, and raises it
+	// linearly from there towards the max fee rate.
+	FeeFunctionMempool
+)
+
+// String returns a human-readable name of the fee function type.
+func (f FeeFunctionType) String() string {
+	switch f {
+	case FeeFunctionLinear:
+		return "Linear"
+
+	case FeeFunctionExponential:
+		return "Exponential"
+
+	case FeeFunctionCubic:
+		return "Cubic"
+
+	case FeeFunctionStep:
+		return "Step"
+
+	case FeeFunctionMempool:
+		return "Mempool"
+
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(f))
+	}
+}
+
+// NewFeeFunction creates a fee function of the given type using the max fee
+// rate, the initial conf target and the optional starting fee rate.
+func NewFeeFunction(fnType FeeFunctionType, maxFeeRate chainfee.SatPerKWeight,
+	confTarget uint32, estimator chainfee.Estimator,
+	startingFeeRate fn.Option[chainfee.SatPerKWeight]) (FeeFunction,
+	error) {
+
+	switch fnType {
+	case FeeFunctionLinear:
+		return NewLinearFeeFunction(
+			maxFeeRate, confTarget, estimator, startingFeeRate,
+		)
+
+	case FeeFunctionExponential:
+		return NewExponentialFeeFunction(
+			maxFeeRate, confTarget, estimator, startingFeeRate,
+		)
+
+	case FeeFunctionCubic:
+		return NewCubicFeeFunction(
+			maxFeeRate, confTarget, estimator, startingFeeRate,
+		)
+
+	case FeeFunctionStep:
+		return NewStepFeeFunction(
+			maxFeeRate, confTarget, estimator, startingFeeRate,
+		)
+
+	case FeeFunctionMempool:
+		return NewMempoolFeeFunction(
+			maxFeeRate, confTarget, estimator, startingFeeRate,
+		)
+
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownFeeFunction, fnType)
+	}
+}
+
 // LinearFeeFunction implements the FeeFunction interface with a linear
 // function:
 //
@@ -75,9 +172,6 @@ type FeeFunction interface {
 //	     - position: currentBlockHeight - startingBlockHeight
 //
 // The fee rate will be capped at endingFeeRate.
-//
-// TODO(yy): implement more functions specified here:
-// - https://github.com/lightningnetwork/lnd/issues/4215
 type LinearFeeFunction struct {
 	// startingFeeRate specifies the initial fee rate to begin with.
 	startingFeeRate chainfee.SatPerKWeight
@@ -288,6 +382,14 @@ func (l *LinearFeeFunction) feeRateAtPosition(p uint32) chainfee.SatPerKWeight {
 func (l *LinearFeeFunction) estimateFeeRate(
 	confTarget uint32) (chainfee.SatPerKWeight, error) {
 
+	return estimateFeeRate(l.estimator, confTarget, l.endingFeeRate)
+}
+
+// estimateFeeRate asks the fee estimator to estimate the fee rate based on the
+// conf target, capped by the ending fee rate.
+func estimateFeeRate(estimator chainfee.Estimator, confTarget uint32,
+	endingFeeRate chainfee.SatPerKWeight) (chainfee.SatPerKWeight, error) {
+
 	fee := FeeEstimateInfo{
 		ConfTarget: confTarget,
 	}
@@ -295,7 +397,7 @@ func (l *LinearFeeFunction) estimateFeeRate(
 	// If the conf target is greater or equal to the max allowed value
 	// (1008), we will use the min relay fee instead.
 	if confTarget >= chainfee.MaxBlockTarget {
-		minFeeRate := l.estimator.RelayFeePerKW()
+		minFeeRate := estimator.RelayFeePerKW()
 		log.Infof("Conf target %v is greater than max block target, "+
 			"using min relay fee rate %v", confTarget, minFeeRate)
 
@@ -305,10 +407,480 @@ func (l *LinearFeeFunction) estimateFeeRate(
 	// endingFeeRate comes from budget/txWeight, which means the returned
 	// fee rate will always be capped by this value, hence we don't need to
 	// worry about overpay.
-	estimatedFeeRate, err := fee.Estimate(l.estimator, l.endingFeeRate)
+	estimatedFeeRate, err := fee.Estimate(estimator, endingFeeRate)
 	if err != nil {
 		return 0, err
 	}
 
 	return estimatedFeeRate, nil
 }
+
+// expFeeCurvature is the growth rate used by the exponential fee function.
+// With a value of 4, only ~12% of the fee rate range is used halfway to the
+// deadline, while the last 10% of the blocks use up a third of it.
+const expFeeCurvature = 4.0
+
+// feeCurve maps a position in the range [0, width] to the fraction of the fee
+// rate range, between the starting and ending fee rates, used at that
+// position. It must return 0 at position 0, 1 at the width and be
+// non-decreasing in between.
+type feeCurve func(position, width uint32) float64
+
+// exponentialCurve implements the feeCurve used by the exponential fee
+// function:
+//
+//	fraction = (e^(k * position/width) - 1) / (e^k - 1)
+func exponentialCurve(position, width uint32) float64 {
+	x := float64(position) / float64(width)
+
+	return math.Expm1(expFeeCurvature*x) / math.Expm1(expFeeCurvature)
+}
+
+// cubicCurve implements the feeCurve used by the cubic ease-in fee function:
+//
+//	fraction = (position/width)^3
+func cubicCurve(position, width uint32) float64 {
+	x := float64(position) / float64(width)
+
+	return x * x * x
+}
+
+// stepCurve implements the feeCurve used by the deadline-aware step function.
+// The range is split into one step per halving of the width, and a step is
+// taken each time the number of blocks left until the deadline drops below
+// the next power of two. For a width of 144 this gives the steps,
+//
+//	blocks left: 144-128 127-64 63-32 31-16 15-8 7-4 3-2 1 0
+//	step:        0       1      2     3     4    5   6   7 8
+//
+// where the last step uses the full budget.
+func stepCurve(position, width uint32) float64 {
+	if position >= width {
+		return 1
+	}
+
+	steps := bits.Len32(width)
+	step := steps - bits.Len32(width-position)
+
+	return float64(step) / float64(steps)
+}
+
+// CurveFeeFunction implements the FeeFunction interface by moving the fee rate
+// along a curve between the starting and ending fee rates:
+//
+//	feeRate = startingFeeRate + curve(position, width) * delta.
+//	     - width: deadlineBlockHeight - startingBlockHeight - 1
+//	     - delta: endingFeeRate - startingFeeRate
+//	     - position: currentBlockHeight - startingBlockHeight
+//
+// The fee rate will be capped at endingFeeRate.
+type CurveFeeFunction struct {
+	// fnType is the type of the fee function, used for logging.
+	fnType FeeFunctionType
+
+	// curve gives the fraction of the fee rate range used at a position.
+	curve feeCurve
+
+	// startingFeeRate specifies the initial fee rate to begin with.
+	startingFeeRate chainfee.SatPerKWeight
+
+	// endingFeeRate specifies the max allowed fee rate.
+	endingFeeRate chainfee.SatPerKWeight
+
+	// currentFeeRate specifies the current calculated fee rate.
+	currentFeeRate chainfee.SatPerKWeight
+
+	// width is the number of blocks between the starting block height
+	// and the deadline block height minus one.
+	width uint32
+
+	// position is the fee function's current position, given a width of w,
+	// a valid position should lie in range [0, w].
+	position uint32
+}
+
+// Compile-time check to ensure CurveFeeFunction satisfies the FeeFunction.
+var _ FeeFunction = (*CurveFeeFunction)(nil)
+
+// NewExponentialFeeFunction creates a fee function whose fee rate grows
+// exponentially from the starting fee rate to the max fee rate.
+func NewExponentialFeeFunction(maxFeeRate chainfee.SatPerKWeight,
+	confTarget uint32, estimator chainfee.Estimator,
+	startingFeeRate fn.Option[chainfee.SatPerKWeight]) (
+	*CurveFeeFunction, error) {
+
+	return newCurveFeeFunction(
+		FeeFunctionExponential, exponentialCurve, maxFeeRate,
+		confTarget, estimator, startingFeeRate,
+	)
+}
+
+// NewCubicFeeFunction creates a fee function whose fee rate follows a cubic
+// ease-in curve from the starting fee rate to the max fee rate.
+func NewCubicFeeFunction(maxFeeRate chainfee.SatPerKWeight,
+	confTarget uint32, estimator chainfee.Estimator,
+	startingFeeRate fn.Option[chainfee.SatPerKWeight]) (
+	*CurveFeeFunction, error) {
+
+	return newCurveFeeFunction(
+		FeeFunctionCubic, cubicCurve, maxFeeRate, confTarget,
+		estimator, startingFeeRate,
+	)
+}
+
+// NewStepFeeFunction creates a fee function whose fee rate is raised by one
+// step each time the number of blocks left until the deadline is halved.
+func NewStepFeeFunction(maxFeeRate chainfee.SatPerKWeight,
+	confTarget uint32, estimator chainfee.Estimator,
+	startingFeeRate fn.Option[chainfee.SatPerKWeight]) (
+	*CurveFeeFunction, error) {
+
+	return newCurveFeeFunction(
+		FeeFunctionStep, stepCurve, maxFeeRate, confTarget, estimator,
+		startingFeeRate,
+	)
+}
+
+// newCurveFeeFunction creates a new curve fee function and initializes it
+// with a starting fee rate, which is either the given one or the value
+// returned from the fee estimator using the initial conf target.
+func newCurveFeeFunction(fnType FeeFunctionType, curve feeCurve,
+	maxFeeRate chainfee.SatPerKWeight, confTarget uint32,
+	estimator chainfee.Estimator,
+	startingFeeRate fn.Option[chainfee.SatPerKWeight]) (
+	*CurveFeeFunction, error) {
+
+	c := &CurveFeeFunction{
+		fnType:        fnType,
+		curve:         curve,
+		endingFeeRate: maxFeeRate,
+	}
+
+	// If the deadline is one block away or has already been reached,
+	// there's nothing the fee function can do. In this case, we'll use the
+	// max fee rate immediately.
+	if confTarget <= 1 {
+		c.startingFeeRate = maxFeeRate
+		c.currentFeeRate = maxFeeRate
+
+		return c, nil
+	}
+
+	start, err := initialFeeRate(
+		maxFeeRate, confTarget, estimator, startingFeeRate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.width = confTarget - 1
+	c.startingFeeRate = start
+	c.currentFeeRate = start
+
+	log.Debugf("%v fee function initialized with startingFeeRate=%v, "+
+		"endingFeeRate=%v, width=%v", fnType, start, maxFeeRate,
+		c.width)
+
+	return c, nil
+}
+
+// FeeRate returns the current fee rate.
+//
+// NOTE: part of the FeeFunction interface.
+func (c *CurveFeeFunction) FeeRate() chainfee.SatPerKWeight {
+	return c.currentFeeRate
+}
+
+// Increment increases the fee rate by one position, returns a boolean to
+// indicate whether the fee rate was increased, and an error if the position is
+// greater than the width.
+//
+// NOTE: part of the FeeFunction interface.
+func (c *CurveFeeFunction) Increment() (bool, error) {
+	return c.increaseFeeRate(c.position + 1)
+}
+
+// IncreaseFeeRate calculates a new position using the given conf target, and
+// increases the fee rate to the new position.
+//
+// NOTE: part of the FeeFunction interface.
+func (c *CurveFeeFunction) IncreaseFeeRate(confTarget uint32) (bool, error) {
+	newPosition := positionForConfTarget(c.width, confTarget)
+	if newPosition <= c.position {
+		log.Tracef("Skipped increase feerate: position=%v, "+
+			"newPosition=%v ", c.position, newPosition)
+
+		return false, nil
+	}
+
+	return c.increaseFeeRate(newPosition)
+}
+
+// increaseFeeRate moves the fee function to the given position and returns a
+// boolean to indicate whether the fee rate was increased.
+func (c *CurveFeeFunction) increaseFeeRate(position uint32) (bool, error) {
+	// If the position is already at the end, we return an error.
+	if c.position >= c.width {
+		return false, ErrMaxPosition
+	}
+
+	oldFeeRate := c.currentFeeRate
+
+	c.position = position
+	c.currentFeeRate = c.feeRateAtPosition(position)
+
+	log.Tracef("%v fee rate increased from %v to %v at position %v",
+		c.fnType, oldFeeRate, c.currentFeeRate, c.position)
+
+	return c.currentFeeRate > oldFeeRate, nil
+}
+
+// feeRateAtPosition calculates the fee rate at a given position and caps it at
+// the ending fee rate.
+func (c *CurveFeeFunction) feeRateAtPosition(p uint32) chainfee.SatPerKWeight {
+	if p >= c.width {
+		return c.endingFeeRate
+	}
+
+	delta := chainutil.Amount(c.endingFeeRate - c.startingFeeRate)
+	feeRateDelta := delta.MulF64(c.curve(p, c.width))
+
+	feeRate := c.startingFeeRate + chainfee.SatPerKWeight(feeRateDelta)
+	if feeRate > c.endingFeeRate {
+		return c.endingFeeRate
+	}
+
+	return feeRate
+}
+
+// MempoolFeeFunction implements the FeeFunction interface by following the fee
+// estimator. At every new block, the fee rate is re-anchored to the larger of
+// the estimate for the remaining blocks and the fee rate it would otherwise
+// have reached, then raised linearly from that anchor to the ending fee rate:
+//
+//	feeRate = anchorFeeRate + (position - anchorPosition) * delta.
+//	     - delta: (endingFeeRate - anchorFeeRate) / (width - anchorPosition)
+//
+// Within a block, Increment moves the fee rate along the current line, so a
+// tx rejected by the mempool is retried with a higher fee rate.
+type MempoolFeeFunction struct {
+	// startingFeeRate specifies the initial fee rate to begin with.
+	startingFeeRate chainfee.SatPerKWeight
+
+	// endingFeeRate specifies the max allowed fee rate.
+	endingFeeRate chainfee.SatPerKWeight
+
+	// currentFeeRate specifies the current calculated fee rate.
+	currentFeeRate chainfee.SatPerKWeight
+
+	// anchorFeeRate is the fee rate the function was last re-anchored to.
+	anchorFeeRate chainfee.SatPerKWeight
+
+	// anchorPosition is the position at which the function was last
+	// re-anchored.
+	anchorPosition uint32
+
+	// width is the number of blocks between the starting block height
+	// and the deadline block height minus one.
+	width uint32
+
+	// position is the fee function's current position, given a width of w,
+	// a valid position should lie in range [0, w].
+	position uint32
+
+	// estimator is the fee estimator the fee rate is re-anchored to.
+	estimator chainfee.Estimator
+}
+
+// Compile-time check to ensure MempoolFeeFunction satisfies the FeeFunction.
+var _ FeeFunction = (*MempoolFeeFunction)(nil)
+
+// NewMempoolFeeFunction creates a new mempool tracking fee function and
+// initializes it with a starting fee rate, which is either the given one or
+// the value returned from the fee estimator using the initial conf target.
+func NewMempoolFeeFunction(maxFeeRate chainfee.SatPerKWeight,
+	confTarget uint32, estimator chainfee.Estimator,
+	startingFeeRate fn.Option[chainfee.SatPerKWeight]) (
+	*MempoolFeeFunction, error) {
+
+	m := &MempoolFeeFunction{
+		endingFeeRate: maxFeeRate,
+		estimator:     estimator,
+	}
+
+	// If the deadline is one block away or has already been reached,
+	// there's nothing the fee function can do. In this case, we'll use the
+	// max fee rate immediately.
+	if confTarget <= 1 {
+		m.startingFeeRate = maxFeeRate
+		m.currentFeeRate = maxFeeRate
+		m.anchorFeeRate = maxFeeRate
+
+		return m, nil
+	}
+
+	start, err := initialFeeRate(
+		maxFeeRate, confTarget, estimator, startingFeeRate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.width = confTarget - 1
+	m.startingFeeRate = start
+	m.currentFeeRate = start
+	m.anchorFeeRate = start
+
+	log.Debugf("Mempool fee function initialized with startingFeeRate=%v, "+
+		"endingFeeRate=%v, width=%v", start, maxFeeRate, m.width)
+
+	return m, nil
+}
+
+// FeeRate returns the current fee rate.
+//
+// NOTE: part of the FeeFunction interface.
+func (m *MempoolFeeFunction) FeeRate() chainfee.SatPerKWeight {
+	return m.currentFeeRate
+}
+
+// Increment increases the fee rate by one position along the line starting at
+// the current anchor, returns a boolean to indicate whether the fee rate was
+// increased, and an error if the position is greater than the width.
+//
+// NOTE: part of the FeeFunction interface.
+func (m *MempoolFeeFunction) Increment() (bool, error) {
+	if m.position >= m.width {
+		return false, ErrMaxPosition
+	}
+
+	oldFeeRate := m.currentFeeRate
+
+	m.position++
+	m.currentFeeRate = m.feeRateAtPosition(m.position)
+
+	log.Tracef("Mempool fee rate increased from %v to %v at position %v",
+		oldFeeRate, m.currentFeeRate, m.position)
+
+	return m.currentFeeRate > oldFeeRate, nil
+}
+
+// IncreaseFeeRate calculates a new position using the given conf target and
+// re-anchors the fee function to the fee estimator's current estimate for
+// this conf target if it's above the fee rate at the new position.
+//
+// NOTE: part of the FeeFunction interface.
+func (m *MempoolFeeFunction) IncreaseFeeRate(confTarget uint32) (bool, error) {
+	newPosition := positionForConfTarget(m.width, confTarget)
+	if newPosition <= m.position {
+		log.Tracef("Skipped increase feerate: position=%v, "+
+			"newPosition=%v ", m.position, newPosition)
+
+		return false, nil
+	}
+
+	// If the position is already at the end, we return an error.
+	if m.position >= m.width {
+		return false, ErrMaxPosition
+	}
+
+	oldFeeRate := m.currentFeeRate
+	anchor := m.feeRateAtPosition(newPosition)
+
+	// Ask the estimator for the fee rate needed to confirm within the
+	// remaining blocks. There's nothing left to track once the deadline
+	// is reached, as the ending fee rate is used from there on.
+	if newPosition < m.width {
+		estimate, err := estimateFeeRate(
+			m.estimator, confTarget, m.endingFeeRate,
+		)
+
+		// A failed estimate shouldn't stop us from bumping the fee,
+		// so we keep following the current line instead.
+		switch {
+		case err != nil:
+			log.Warnf("Unable to estimate fee rate for conf "+
+				"target %v, keeping anchor %v: %v", confTarget,
+				m.anchorFeeRate, err)
+
+		case estimate > anchor:
+			anchor = estimate
+		}
+	}
+
+	m.position = newPosition
+	m.anchorPosition = newPosition
+	m.anchorFeeRate = anchor
+	m.currentFeeRate = m.feeRateAtPosition(newPosition)
+
+	log.Tracef("Mempool fee rate re-anchored from %v to %v at position %v",
+		oldFeeRate, m.currentFeeRate, m.position)
+
+	return m.currentFeeRate > oldFeeRate, nil
+}
+
+// feeRateAtPosition calculates the fee rate at a given position on the line
+// starting at the current anchor and caps it at the ending fee rate.
+func (m *MempoolFeeFunction) feeRateAtPosition(
+	p uint32) chainfee.SatPerKWeight {
+
+	if p >= m.width {
+		return m.endingFeeRate
+	}
+
+	if p <= m.anchorPosition {
+		return m.anchorFeeRate
+	}
+
+	delta := chainutil.Amount(m.endingFeeRate - m.anchorFeeRate)
+	feeRateDelta := delta.MulF64(
+		float64(p-m.anchorPosition) / float64(m.width-m.anchorPosition),
+	)
+
+	feeRate := m.anchorFeeRate + chainfee.SatPerKWeight(feeRateDelta)
+	if feeRate > m.endingFeeRate {
+		return m.endingFeeRate
+	}
+
+	return feeRate
+}
+
+// positionForConfTarget returns the position of a fee function of the given
+// width for the conf target. Conf targets beyond the width map to position 0.
+func positionForConfTarget(width, confTarget uint32) uint32 {
+	if confTarget >= width+1 {
+		return 0
+	}
+
+	return width + 1 - confTarget
+}
+
+// initialFeeRate returns the starting fee rate of a fee function, which is
+// either the one specified by the caller or the one estimated for the initial
+// conf target. An error is returned if it leaves no room to increase the fee
+// rate.
+func initialFeeRate(maxFeeRate chainfee.SatPerKWeight, confTarget uint32,
+	estimator chainfee.Estimator,
+	startingFeeRate fn.Option[chainfee.SatPerKWeight]) (
+	chainfee.SatPerKWeight, error) {
+
+	start, err := startingFeeRate.UnwrapOrFuncErr(
+		func() (chainfee.SatPerKWeight, error) {
+			return estimateFeeRate(estimator, confTarget, maxFeeRate)
+		})
+	if err != nil {
+		return 0, fmt.Errorf("estimate initial fee rate: %w", err)
+	}
+
+	// Similar to the linear fee function, the starting and ending fee
+	// rates can only be the same if there's a single block to go.
+	if start == maxFeeRate && confTarget != 2 {
+		log.Errorf("Failed to init fee function: startingFeeRate=%v, "+
+			"endingFeeRate=%v, confTarget=%v", start, maxFeeRate,
+			confTarget)
+
+		return 0, ErrZeroFeeRateDelta
+	}
+
+	return start, nil
+}
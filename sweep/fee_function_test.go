@@ -392,3 +392,220 @@ func TestLinearFeeFunctionIncreaseFeeRate(t *testing.T) {
 	rt.ErrorIs(err, ErrMaxPosition)
 	rt.False(increased)
 }
+
+// TestFeeCurves checks the curves used by the curve fee functions start at
+// zero, end at one and never decrease in between.
+func TestFeeCurves(t *testing.T) {
+	t.Parallel()
+
+	curves := map[string]feeCurve{
+		"exponential": exponentialCurve,
+		"cubic":       cubicCurve,
+		"step":        stepCurve,
+	}
+
+	for name, curve := range curves {
+		t.Run(name, func(t *testing.T) {
+			for _, width := range []uint32{1, 2, 5, 144, 1007} {
+				require.Zero(t, curve(0, width))
+				require.InDelta(t, 1, curve(width, width), 1e-9)
+
+				prev := 0.0
+				for p := uint32(1); p <= width; p++ {
+					cur := curve(p, width)
+					require.GreaterOrEqual(t, cur, prev)
+					prev = cur
+				}
+			}
+		})
+	}
+
+	// The step curve takes a step each time the number of blocks left is
+	// halved.
+	expected := []struct {
+		position uint32
+		fraction float64
+	}{
+		{0, 0}, {16, 0}, {17, 1.0 / 8}, {80, 1.0 / 8},
+		{81, 2.0 / 8}, {140, 5.0 / 8}, {143, 7.0 / 8}, {144, 1},
+	}
+	for _, e := range expected {
+		require.Equal(t, e.fraction, stepCurve(e.position, 144),
+			"position %v", e.position)
+	}
+
+	// The ease-in curves should stay well below the linear function
+	// halfway to the deadline.
+	require.Less(t, exponentialCurve(72, 144), 0.15)
+	require.Less(t, cubicCurve(72, 144), 0.15)
+}
+
+// TestCurveFeeFunctionIncreaseFeeRate checks the internal state of a curve fee
+// function is updated correctly when the fee rate is increased.
+func TestCurveFeeFunctionIncreaseFeeRate(t *testing.T) {
+	t.Parallel()
+
+	rt := require.New(t)
+
+	// Create a mock fee estimator.
+	estimator := &chainfee.MockEstimator{}
+	defer estimator.AssertExpectations(t)
+
+	// Create testing params. The fee rate range is 800 and the width is
+	// 4, so the cubic curve adds 12.5, 100 and 337.5 in the first three
+	// positions, which are rounded to the nearest sat/kw.
+	maxFeeRate := chainfee.SatPerKWeight(900)
+	estimatedFeeRate := chainfee.SatPerKWeight(100)
+	confTarget := uint32(5)
+
+	// Mock the fee estimator to return the fee rate.
+	estimator.On("EstimateFeePerKW", confTarget).Return(
+		estimatedFeeRate, nil).Once()
+	estimator.On("RelayFeePerKW").Return(estimatedFeeRate).Once()
+
+	f, err := NewFeeFunction(
+		FeeFunctionCubic, maxFeeRate, confTarget, estimator,
+		fn.None[chainfee.SatPerKWeight](),
+	)
+	rt.NoError(err)
+	rt.IsType(&CurveFeeFunction{}, f)
+	rt.Equal(estimatedFeeRate, f.FeeRate())
+
+	// Using the initial conf target won't increase the fee rate.
+	increased, err := f.IncreaseFeeRate(confTarget)
+	rt.NoError(err)
+	rt.False(increased)
+
+	expected := []chainfee.SatPerKWeight{113, 200, 438, maxFeeRate}
+	for i, feeRate := range expected {
+		increased, err := f.IncreaseFeeRate(confTarget - uint32(i) - 1)
+		rt.NoError(err)
+		rt.True(increased)
+		rt.Equal(feeRate, f.FeeRate())
+	}
+
+	// The function is now maxed out.
+	increased, err = f.Increment()
+	rt.ErrorIs(err, ErrMaxPosition)
+	rt.False(increased)
+}
+
+// TestCurveFeeFunctionNewMaxFeeRateUsed tests when the conf target is <= 1,
+// the max fee rate is used.
+func TestCurveFeeFunctionNewMaxFeeRateUsed(t *testing.T) {
+	t.Parallel()
+
+	estimator := &chainfee.MockEstimator{}
+	defer estimator.AssertExpectations(t)
+
+	maxFeeRate := chainfee.SatPerKWeight(10000)
+	noStartFeeRate := fn.None[chainfee.SatPerKWeight]()
+
+	for _, fnType := range []FeeFunctionType{
+		FeeFunctionExponential, FeeFunctionCubic, FeeFunctionStep,
+		FeeFunctionMempool,
+	} {
+		for _, confTarget := range []uint32{0, 1} {
+			f, err := NewFeeFunction(
+				fnType, maxFeeRate, confTarget, estimator,
+				noStartFeeRate,
+			)
+			require.NoError(t, err)
+			require.Equal(t, maxFeeRate, f.FeeRate())
+
+			_, err = f.Increment()
+			require.ErrorIs(t, err, ErrMaxPosition)
+		}
+	}
+
+	// An unknown fee function type results in an error.
+	_, err := NewFeeFunction(
+		FeeFunctionMempool+1, maxFeeRate, 6, estimator, noStartFeeRate,
+	)
+	require.ErrorIs(t, err, ErrUnknownFeeFunction)
+}
+
+// TestMempoolFeeFunctionReanchor checks the mempool fee function re-anchors to
+// the fee estimate when it's above its current line, and keeps following the
+// line otherwise.
+func TestMempoolFeeFunctionReanchor(t *testing.T) {
+	t.Parallel()
+
+	rt := require.New(t)
+
+	// Create a mock fee estimator.
+	estimator := &chainfee.MockEstimator{}
+	defer estimator.AssertExpectations(t)
+
+	// Create testing params. We use a starting fee rate of 100 and a max
+	// fee rate of 1100 over a width of 10, so the initial line increases
+	// the fee rate by 100 per block.
+	maxFeeRate := chainfee.SatPerKWeight(1100)
+	startingFeeRate := chainfee.SatPerKWeight(100)
+	confTarget := uint32(11)
+
+	f, err := NewMempoolFeeFunction(
+		maxFeeRate, confTarget, estimator, fn.Some(startingFeeRate),
+	)
+	rt.NoError(err)
+	rt.Equal(startingFeeRate, f.FeeRate())
+
+	// Increment moves along the initial line.
+	increased, err := f.Increment()
+	rt.NoError(err)
+	rt.True(increased)
+	rt.Equal(chainfee.SatPerKWeight(200), f.FeeRate())
+
+	// Using the same position won't increase the fee rate.
+	increased, err = f.IncreaseFeeRate(confTarget - 1)
+	rt.NoError(err)
+	rt.False(increased)
+
+	// When the estimate is below the line, we keep following it.
+	estimator.On("EstimateFeePerKW", uint32(9)).Return(
+		chainfee.SatPerKWeight(150), nil).Once()
+	estimator.On("RelayFeePerKW").Return(
+		chainfee.SatPerKWeight(100)).Twice()
+
+	increased, err = f.IncreaseFeeRate(9)
+	rt.NoError(err)
+	rt.True(increased)
+	rt.Equal(chainfee.SatPerKWeight(300), f.FeeRate())
+
+	// When the mempool spikes above the line, which is at 600 by now, we
+	// re-anchor to the estimate and spread the remaining range over the
+	// blocks left, which is 80 per block.
+	estimator.On("EstimateFeePerKW", uint32(6)).Return(
+		chainfee.SatPerKWeight(700), nil).Once()
+
+	increased, err = f.IncreaseFeeRate(6)
+	rt.NoError(err)
+	rt.True(increased)
+	rt.Equal(chainfee.SatPerKWeight(700), f.FeeRate())
+	rt.EqualValues(5, f.anchorPosition)
+
+	increased, err = f.Increment()
+	rt.NoError(err)
+	rt.True(increased)
+	rt.Equal(chainfee.SatPerKWeight(780), f.FeeRate())
+
+	// A failed estimate doesn't stop the fee rate from increasing.
+	estimator.On("EstimateFeePerKW", uint32(4)).Return(
+		chainfee.SatPerKWeight(0), errDummy).Once()
+
+	increased, err = f.IncreaseFeeRate(4)
+	rt.NoError(err)
+	rt.True(increased)
+	rt.Equal(chainfee.SatPerKWeight(860), f.FeeRate())
+
+	// The ending fee rate is used once the deadline is reached, without
+	// asking the estimator.
+	increased, err = f.IncreaseFeeRate(1)
+	rt.NoError(err)
+	rt.True(increased)
+	rt.Equal(maxFeeRate, f.FeeRate())
+
+	increased, err = f.IncreaseFeeRate(0)
+	rt.ErrorIs(err, ErrMaxPosition)
+	rt.False(increased)
+}
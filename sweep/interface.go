@@ -5,6 +5,7 @@ import (
 	"github.com/flokiorg/flnd/input"
 	"github.com/flokiorg/flnd/keychain"
 	"github.com/flokiorg/flnd/lnwallet"
+	"github.com/flokiorg/flnd/lnwallet/txbatcher"
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/wire"
@@ -62,6 +63,16 @@ type Wallet interface {
 	BackEnd() string
 }
 
+// OutputBatcher hands out queued wallet payments that can be paid from the
+// value of the inputs of a sweeping tx.
+type OutputBatcher interface {
+	// ClaimOutputs claims the queued payments whose deadline isn't before
+	// the given height, as long as their total value stays within the
+	// given max value.
+	ClaimOutputs(deadline int32,
+		maxValue fn.Option[chainutil.Amount]) fn.Option[*txbatcher.Claim]
+}
+
 // SweepOutput is an output used to sweep funds from a channel output.
 type SweepOutput struct { //nolint:revive
 	wire.TxOut
@@ -275,6 +275,13 @@ func (m *MockInputSet) Immediate() bool {
 	return args.Bool(0)
 }
 
+// FeeFunction returns the fee function to use for the set.
+func (m *MockInputSet) FeeFunction() FeeFunctionType {
+	args := m.Called()
+
+	return args.Get(0).(FeeFunctionType)
+}
+
 // MockBumper is a mock implementation of the interface Bumper.
 type MockBumper struct {
 	mock.Mock
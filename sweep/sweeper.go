@@ -13,6 +13,7 @@ import (
 	"github.com/flokiorg/flnd/lnutils"
 	"github.com/flokiorg/flnd/lnwallet"
 	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwallet/txbatcher"
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/wire"
@@ -66,6 +67,10 @@ type Params struct {
 	// StartingFeeRate is an optional parameter that can be used to specify
 	// the initial fee rate to use for the fee function.
 	StartingFeeRate fn.Option[chainfee.SatPerKWeight]
+
+	// FeeFunction specifies the fee function used by the fee bumper to
+	// raise the fee rate of the sweeping tx towards the deadline.
+	FeeFunction FeeFunctionType
 }
 
 // String returns a human readable interpretation of the sweep parameters.
@@ -81,8 +86,9 @@ func (p Params) String() string {
 	}
 
 	return fmt.Sprintf("startingFeeRate=%v, immediate=%v, "+
-		"exclusive_group=%v, budget=%v, deadline=%v", p.StartingFeeRate,
-		p.Immediate, exclusiveGroup, p.Budget, deadline)
+		"exclusive_group=%v, budget=%v, deadline=%v, feeFunction=%v",
+		p.StartingFeeRate, p.Immediate, exclusiveGroup, p.Budget,
+		deadline, p.FeeFunction)
 }
 
 // SweepState represents the current state of a pending input.
@@ -420,6 +426,11 @@ type UtxoSweeperConfig struct {
 	// NoDeadlineConfTarget is the conf target to use when sweeping
 	// non-time-sensitive outputs.
 	NoDeadlineConfTarget uint32
+
+	// OutputBatcher is an optional source of queued wallet payments that
+	// are added to sweeping txns whose inputs are worth more than their
+	// budget.
+	OutputBatcher fn.Option[OutputBatcher]
 }
 
 // Result is the struct that is pushed through the result channel. Callers can
@@ -861,9 +872,20 @@ func (s *UtxoSweeper) sweep(set InputSet) error {
 		MaxFeeRate:      s.cfg.MaxFeeRate.FeePerKWeight(),
 		StartingFeeRate: set.StartingFeeRate(),
 		Immediate:       set.Immediate(),
-		// TODO(yy): pass the strategy here.
+		FeeFunction:     set.FeeFunction(),
 	}
 
+	// Let queued wallet payments ride along if the inputs are worth more
+	// than their budget.
+	claim := s.claimBatchOutputs(set, sweepAddr)
+	claim.WhenSome(func(c *batchClaim) {
+		req.ExtraOutputs = c.claim.Outputs()
+
+		// All the txns carrying the payments spend the inputs of the
+		// set, so any of them tells whether one of the txns confirmed.
+		c.input = set.Inputs()[0]
+	})
+
 	// Reschedule the inputs that we just tried to sweep. This is done in
 	// case the following publish fails, we'd like to update the inputs'
 	// publish attempts and rescue them in the next sweep.
@@ -877,11 +899,117 @@ func (s *UtxoSweeper) sweep(set InputSet) error {
 	// subscribing to the result chan and listen for future updates about
 	// this tx.
 	s.wg.Add(1)
-	go s.monitorFeeBumpResult(set, resp)
+	go s.monitorFeeBumpResult(set, claim, resp)
 
 	return nil
 }
 
+// batchClaim tracks the wallet payments claimed from the output batcher that
+// are carried by a sweeping tx.
+type batchClaim struct {
+	claim *txbatcher.Claim
+
+	// input is an input of the set spent by all the txns carrying the
+	// payments.
+	input input.Input
+
+	// published is set once a tx carrying the payments was published.
+	published bool
+}
+
+// claimBatchOutputs claims the queued wallet payments that can be paid from
+// the value of the inputs left after their budget and required outputs. Only
+// sweeps without a deadline carry them, so that the payments can't delay a
+// time-sensitive sweep.
+func (s *UtxoSweeper) claimBatchOutputs(set InputSet,
+	sweepAddr lnwallet.AddrWithKey) fn.Option[*batchClaim] {
+
+	if s.cfg.OutputBatcher.IsNone() {
+		return fn.None[*batchClaim]()
+	}
+	batcher := s.cfg.OutputBatcher.UnsafeFromSome()
+
+	for _, inp := range set.Inputs() {
+		pi, ok := s.inputs[inp.OutPoint()]
+		if ok && pi.params.DeadlineHeight.IsSome() {
+			return fn.None[*batchClaim]()
+		}
+	}
+
+	var surplus chainutil.Amount
+	for _, inp := range set.Inputs() {
+		surplus += chainutil.Amount(inp.SignDesc().Output.Value)
+
+		if out := inp.RequiredTxOut(); out != nil {
+			surplus -= chainutil.Amount(out.Value)
+		}
+	}
+
+	// Keep a dust limit aside so the payments don't turn the change into
+	// dust.
+	surplus -= set.Budget() + lnwallet.DustLimitForSize(
+		len(sweepAddr.DeliveryAddress),
+	)
+	if surplus <= 0 {
+		return fn.None[*batchClaim]()
+	}
+
+	claim := batcher.ClaimOutputs(set.DeadlineHeight(), fn.Some(surplus))
+
+	return fn.MapOption(func(c *txbatcher.Claim) *batchClaim {
+		log.Debugf("Adding %d batched outputs to sweep of %d inputs",
+			len(c.Outputs()), len(set.Inputs()))
+
+		return &batchClaim{claim: c}
+	})(claim)
+}
+
+// handleBatchClaim updates the wallet payments carried by a sweeping tx
+// based on the bump result.
+func (s *UtxoSweeper) handleBatchClaim(resp *bumpResp) {
+	resp.claim.WhenSome(func(c *batchClaim) {
+		r := resp.result
+
+		switch r.Event {
+		case TxPublished, TxReplaced:
+			c.published = true
+			c.claim.Published(r.Tx)
+
+		case TxConfirmed:
+			c.claim.Done()
+
+		// The inputs were spent by another tx, so ours can't confirm
+		// and the payments go back to the queue.
+		case TxUnknownSpend:
+			c.claim.Release()
+
+		case TxFailed, TxFatal:
+			if !c.published {
+				c.claim.Release()
+				return
+			}
+
+			// The published tx may still confirm, so handing the
+			// payments to another tx could pay them twice. We keep
+			// track of it until it confirms or its inputs are
+			// double spent.
+			log.Warnf("Sweeping tx carrying %d batched outputs "+
+				"failed after being published: %v",
+				len(c.claim.Outputs()), r.Err)
+
+			err := c.claim.WatchInput(
+				c.input.OutPoint(),
+				c.input.SignDesc().Output.PkScript,
+			)
+			if err != nil {
+				log.Errorf("Unable to watch input %v of "+
+					"sweeping tx carrying batched "+
+					"outputs: %v", c.input.OutPoint(), err)
+			}
+		}
+	})
+}
+
 // markInputsPendingPublish updates the pending inputs with the given tx
 // inputs. It also increments the `publishAttempts`.
 func (s *UtxoSweeper) markInputsPendingPublish(set InputSet) {
@@ -1173,6 +1301,7 @@ func (s *UtxoSweeper) handleUpdateReq(req *updateReq) (
 		Immediate:       req.params.Immediate,
 		Budget:          req.params.Budget,
 		DeadlineHeight:  req.params.DeadlineHeight,
+		FeeFunction:     req.params.FeeFunction,
 		ExclusiveGroup:  sweeperInput.params.ExclusiveGroup,
 	}
 
@@ -1634,6 +1763,9 @@ type bumpResp struct {
 
 	// set is the input set that was used in the bump attempt.
 	set InputSet
+
+	// claim holds the batched wallet payments carried by the tx, if any.
+	claim fn.Option[*batchClaim]
 }
 
 // monitorFeeBumpResult subscribes to the passed result chan to listen for
@@ -1641,7 +1773,7 @@ type bumpResp struct {
 //
 // NOTE: must run as a goroutine.
 func (s *UtxoSweeper) monitorFeeBumpResult(set InputSet,
-	resultChan <-chan *BumpResult) {
+	claim fn.Option[*batchClaim], resultChan <-chan *BumpResult) {
 
 	defer s.wg.Done()
 
@@ -1657,6 +1789,7 @@ func (s *UtxoSweeper) monitorFeeBumpResult(set InputSet,
 			resp := &bumpResp{
 				result: r,
 				set:    set,
+				claim:  claim,
 			}
 
 			// Send the result back to the main event loop.
@@ -1857,11 +1990,14 @@ func (s *UtxoSweeper) markInputsFatal(set InputSet, err error) {
 // handleBumpEvent handles the result sent from the bumper based on its event
 // type.
 //
-// NOTE: TxConfirmed event is not handled, since we already subscribe to the
-// input's spending event, we don't need to do anything here.
+// NOTE: TxConfirmed event is only used to settle the batched wallet payments,
+// since we already subscribe to the input's spending event, we don't need to
+// do anything else here.
 func (s *UtxoSweeper) handleBumpEvent(r *bumpResp) error {
 	log.Debugf("Received bump result %v", r.result)
 
+	s.handleBatchClaim(r)
+
 	switch r.result.Event {
 	// The tx has been published, we update the inputs' state and create a
 	// record to be stored in the sweeper db.
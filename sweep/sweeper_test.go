@@ -12,6 +12,7 @@ import (
 	"github.com/flokiorg/flnd/lntypes"
 	"github.com/flokiorg/flnd/lnwallet"
 	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwallet/txbatcher"
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/go-flokicoin/chainutil"
 	"github.com/flokiorg/go-flokicoin/crypto"
@@ -663,12 +664,14 @@ func TestSweepPendingInputs(t *testing.T) {
 	setNeedWallet.On("StartingFeeRate").Return(
 		fn.None[chainfee.SatPerKWeight]()).Once()
 	setNeedWallet.On("Immediate").Return(false).Once()
+	setNeedWallet.On("FeeFunction").Return(FeeFunctionLinear).Once()
 	normalSet.On("Inputs").Return(nil).Maybe()
 	normalSet.On("DeadlineHeight").Return(testHeight).Once()
 	normalSet.On("Budget").Return(chainutil.Amount(1)).Once()
 	normalSet.On("StartingFeeRate").Return(
 		fn.None[chainfee.SatPerKWeight]()).Once()
 	normalSet.On("Immediate").Return(false).Once()
+	normalSet.On("FeeFunction").Return(FeeFunctionLinear).Once()
 
 	// Make pending inputs for testing. We don't need real values here as
 	// the returned clusters are mocked.
@@ -1055,7 +1058,9 @@ func TestMonitorFeeBumpResult(t *testing.T) {
 
 			s.wg.Add(1)
 			go func() {
-				s.monitorFeeBumpResult(set, resultChan)
+				s.monitorFeeBumpResult(
+					set, fn.None[*batchClaim](), resultChan,
+				)
 				close(done)
 			}()
 
@@ -1446,3 +1451,117 @@ func TestHandleBumpEventTxUnknownSpendWithRetry(t *testing.T) {
 	// Assert the state of the input is updated.
 	require.Equal(t, PublishFailed, s.inputs[op2].state)
 }
+
+// TestClaimBatchOutputs checks that the sweeper only claims the batched wallet
+// payments its inputs can pay for on top of their budget, and that the claim
+// follows the lifecycle of the sweeping tx.
+func TestClaimBatchOutputs(t *testing.T) {
+	t.Parallel()
+
+	notifier := &chainntnfs.MockChainNotifier{}
+	defer notifier.AssertExpectations(t)
+
+	batcher := txbatcher.New(&txbatcher.Config{Notifier: notifier})
+	s := New(&UtxoSweeperConfig{
+		OutputBatcher: fn.Some[OutputBatcher](batcher),
+	})
+
+	pkScript := append([]byte{0x00, 0x14}, make([]byte, 20)...)
+	queue := func(value int64) <-chan *txbatcher.Result {
+		_, resultChan, err := batcher.Queue(&txbatcher.Request{
+			Outputs: []*wire.TxOut{{
+				Value:    value,
+				PkScript: pkScript,
+			}},
+			DeadlineHeight: 1000,
+		})
+		require.NoError(t, err)
+
+		return resultChan
+	}
+	result1 := queue(20_000)
+	result2 := queue(40_000)
+
+	inp := createTestInput(100_000, input.WitnessKeyHash)
+	set := &MockInputSet{}
+	defer set.AssertExpectations(t)
+
+	set.On("Inputs").Return([]input.Input{&inp})
+	set.On("Budget").Return(chainutil.Amount(50_000))
+	set.On("DeadlineHeight").Return(int32(500))
+
+	// A sweep with a deadline doesn't carry any payments.
+	s.inputs[inp.OutPoint()] = &SweeperInput{
+		params: Params{DeadlineHeight: fn.Some(int32(500))},
+	}
+	require.True(t, s.claimBatchOutputs(set, changePkScript).IsNone())
+
+	// Only the first payment fits into the 50k left after the budget, as
+	// both together exceed it.
+	delete(s.inputs, inp.OutPoint())
+	claim := s.claimBatchOutputs(set, changePkScript).UnwrapOrFail(t)
+	require.Len(t, claim.claim.Outputs(), 1)
+	require.EqualValues(t, 20_000, claim.claim.Outputs()[0].Value)
+
+	// A failure before the tx is published releases the payment.
+	s.handleBatchClaim(&bumpResp{
+		result: &BumpResult{Event: TxFatal},
+		claim:  fn.Some(claim),
+	})
+	claim = s.claimBatchOutputs(set, changePkScript).UnwrapOrFail(t)
+	require.Len(t, claim.claim.Outputs(), 1)
+	claim.input = &inp
+
+	// Once published, the requester learns about the tx.
+	tx := &wire.MsgTx{}
+	s.handleBatchClaim(&bumpResp{
+		result: &BumpResult{Event: TxPublished, Tx: tx},
+		claim:  fn.Some(claim),
+	})
+	require.True(t, claim.published)
+	require.Equal(t, tx, (<-result1).Tx)
+
+	// A failure after the tx was published doesn't release the payment,
+	// as the published tx may still confirm. So only the second payment
+	// is left to claim.
+	spendChan := make(chan *chainntnfs.SpendDetail, 1)
+	notifier.On(
+		"RegisterSpendNtfn", mock.Anything, mock.Anything,
+		mock.Anything,
+	).Return(&chainntnfs.SpendEvent{
+		Spend:  spendChan,
+		Cancel: func() {},
+	}, nil).Once()
+
+	s.handleBatchClaim(&bumpResp{
+		result: &BumpResult{Event: TxFailed, Tx: tx},
+		claim:  fn.Some(claim),
+	})
+	claim2 := s.claimBatchOutputs(set, changePkScript).UnwrapOrFail(t)
+	require.Len(t, claim2.claim.Outputs(), 1)
+	require.EqualValues(t, 40_000, claim2.claim.Outputs()[0].Value)
+	claim2.claim.Release()
+
+	select {
+	case <-result2:
+		t.Fatal("unexpected result for unpublished payment")
+	default:
+	}
+
+	// Once the input of the published tx is double spent, the payment
+	// is queued again.
+	spenderHash := chainhash.Hash{1}
+	spendChan <- &chainntnfs.SpendDetail{SpenderTxHash: &spenderHash}
+
+	require.Eventually(t, func() bool {
+		claim := s.claimBatchOutputs(set, changePkScript)
+		if claim.IsNone() {
+			return false
+		}
+
+		outputs := claim.UnsafeFromSome().claim.Outputs()
+		claim.UnsafeFromSome().claim.Release()
+
+		return len(outputs) == 1 && outputs[0].Value == 20_000
+	}, time.Second, 10*time.Millisecond)
+}
@@ -69,8 +69,13 @@ type InputSet interface {
 	// this input set should be published immediately.
 	//
 	// TODO(yy): create a new method `Params` to combine the informational
-	// methods DeadlineHeight, Budget, StartingFeeRate and Immediate.
+	// methods DeadlineHeight, Budget, StartingFeeRate, Immediate and
+	// FeeFunction.
 	Immediate() bool
+
+	// FeeFunction returns the fee function to be used when bumping the
+	// fee of the tx made from this input set.
+	FeeFunction() FeeFunctionType
 }
 
 // createWalletTxInput converts a wallet utxo into an object that can be added
@@ -471,6 +476,25 @@ func (b *BudgetInputSet) StartingFeeRate() fn.Option[chainfee.SatPerKWeight] {
 	return startingFeeRate
 }
 
+// FeeFunction returns the fee function requested by the input with the
+// largest budget, as it contributes the most to the fees paid by the set.
+//
+// NOTE: part of the InputSet interface.
+func (b *BudgetInputSet) FeeFunction() FeeFunctionType {
+	var (
+		maxBudget   chainutil.Amount
+		feeFunction FeeFunctionType
+	)
+	for _, inp := range b.inputs {
+		if inp.params.Budget > maxBudget {
+			maxBudget = inp.params.Budget
+			feeFunction = inp.params.FeeFunction
+		}
+	}
+
+	return feeFunction
+}
+
 // Immediate returns whether the inputs should be swept immediately.
 //
 // NOTE: part of the InputSet interface.
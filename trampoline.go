@@ -0,0 +1,206 @@
+package flnd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/htlcswitch"
+	"github.com/flokiorg/flnd/lntypes"
+	"github.com/flokiorg/flnd/lnwire"
+	paymentsdb "github.com/flokiorg/flnd/payments/db"
+	"github.com/flokiorg/flnd/routing"
+)
+
+// trampolineMaxParts is the maximum number of shards we split a relayed
+// trampoline payment into.
+const trampolineMaxParts = 16
+
+// trampolinePayer pays the outgoing side of the trampoline payments we relay
+// using the channel router.
+//
+// NOTE: This implements the htlcswitch.TrampolinePayer interface.
+type trampolinePayer struct {
+	router *routing.ChannelRouter
+	tower  routing.ControlTower
+	quit   <-chan struct{}
+}
+
+// A compile-time check to ensure trampolinePayer implements the
+// htlcswitch.TrampolinePayer interface.
+var _ htlcswitch.TrampolinePayer = (*trampolinePayer)(nil)
+
+// SendTrampolinePayment finds a route to the outgoing node of a relayed
+// trampoline payment and pays it, blocking until the payment either settles
+// or fails.
+func (t *trampolinePayer) SendTrampolinePayment(
+	p *htlcswitch.TrampolinePayment) (lntypes.Preimage, error) {
+
+	// The router pads the final CLTV delta of the route, so we subtract
+	// the padding to end up with the expiry the trampoline onion asks
+	// for.
+	padding := uint32(routing.BlockPadding)
+	if p.FinalCltvExpiry <= p.CurrentHeight+padding ||
+		p.MaxCltvExpiry <= p.FinalCltvExpiry {
+
+		return lntypes.Preimage{}, fmt.Errorf("invalid trampoline "+
+			"expiry: final=%v, max=%v, height=%v",
+			p.FinalCltvExpiry, p.MaxCltvExpiry, p.CurrentHeight)
+	}
+
+	payment := &routing.LightningPayment{
+		Target:    p.Target,
+		Amount:    p.Amount,
+		FeeLimit:  p.FeeLimit,
+		CltvLimit: p.MaxCltvExpiry - p.CurrentHeight,
+		FinalCLTVDelta: uint16(
+			p.FinalCltvExpiry - p.CurrentHeight - padding,
+		),
+		PaymentAddr:       fn.Some(p.PaymentAddr),
+		TrampolineOnion:   p.TrampolineOnion,
+		TrampolineRelay:   true,
+		MaxParts:          trampolineMaxParts,
+		PayAttemptTimeout: routing.DefaultPayAttemptTimeout,
+	}
+	if err := payment.SetPaymentHash(p.PaymentHash); err != nil {
+		return lntypes.Preimage{}, err
+	}
+
+	preimage, _, err := t.router.SendPayment(payment)
+	if err == nil {
+		return preimage, nil
+	}
+
+	// If the payment was already initiated, for example by an earlier
+	// relay attempt before a restart, we wait for its outcome instead.
+	if !errors.Is(err, paymentsdb.ErrPaymentInFlight) &&
+		!errors.Is(err, paymentsdb.ErrAlreadyPaid) {
+
+		return lntypes.Preimage{}, err
+	}
+
+	return t.waitForPayment(p.PaymentHash)
+}
+
+// waitForPayment blocks until the payment with the given hash reaches a
+// terminal state and returns its preimage if it settled.
+func (t *trampolinePayer) waitForPayment(
+	hash lntypes.Hash) (lntypes.Preimage, error) {
+
+	sub, err := t.tower.SubscribePayment(hash)
+	if err != nil {
+		return lntypes.Preimage{}, err
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case update, ok := <-sub.Updates():
+			if !ok {
+				return lntypes.Preimage{},
+					htlcswitch.ErrTrampolinePaymentPending
+			}
+
+			payment, ok := update.(*paymentsdb.MPPayment)
+			if !ok || !payment.Terminated() {
+				continue
+			}
+
+			return terminalPreimage(payment)
+
+		case <-t.quit:
+			return lntypes.Preimage{},
+				htlcswitch.ErrTrampolinePaymentPending
+		}
+	}
+}
+
+// FetchPreimage returns the preimage of the payment with the given hash if
+// we already paid it.
+func (t *trampolinePayer) FetchPreimage(
+	hash lntypes.Hash) (fn.Option[lntypes.Preimage], error) {
+
+	payment, err := t.tower.FetchPayment(hash)
+	switch {
+	case errors.Is(err, paymentsdb.ErrPaymentNotInitiated):
+		return fn.None[lntypes.Preimage](), nil
+
+	case err != nil:
+		return fn.None[lntypes.Preimage](), err
+	}
+
+	preimage, err := terminalPreimage(payment)
+	if err != nil {
+		return fn.None[lntypes.Preimage](), nil
+	}
+
+	return fn.Some(preimage), nil
+}
+
+// terminalPreimage returns the preimage of a settled payment, or an error if
+// the payment didn't settle.
+func terminalPreimage(payment paymentsdb.DBMPPayment) (lntypes.Preimage,
+	error) {
+
+	settle, failure := payment.TerminalInfo()
+	switch {
+	case settle != nil:
+		return settle.Settle.Preimage, nil
+
+	case failure != nil:
+		return lntypes.Preimage{}, fmt.Errorf("payment failed: %v",
+			*failure)
+
+	default:
+		return lntypes.Preimage{}, errors.New("payment not settled")
+	}
+}
+
+// fetchTrampolineHops looks up the advertised trampoline policies and
+// features of the trampoline nodes we relay our payments through.
+func (s *server) fetchTrampolineHops(
+	ctx context.Context) ([]routing.TrampolineHop, error) {
+
+	nodes, err := s.cfg.Routing.Trampoline.NodeVertices()
+	if err != nil {
+		return nil, err
+	}
+
+	hops := make([]routing.TrampolineHop, 0, len(nodes))
+	for _, vertex := range nodes {
+		node, err := s.graphDB.FetchNode(ctx, vertex)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch trampoline "+
+				"node %v: %w", vertex, err)
+		}
+
+		if !node.Features.HasFeature(
+			lnwire.TrampolineRoutingOptional,
+		) {
+
+			return nil, fmt.Errorf("node %v doesn't support "+
+				"trampoline routing", vertex)
+		}
+
+		policy, err := node.TrampolinePolicy()
+		if err != nil {
+			return nil, fmt.Errorf("invalid trampoline policy of "+
+				"node %v: %w", vertex, err)
+		}
+
+		hop := routing.TrampolineHop{
+			Node:     vertex,
+			Features: node.Features,
+		}
+		hop.Policy, err = policy.UnwrapOrErr(fmt.Errorf("node %v "+
+			"doesn't advertise a trampoline policy", vertex))
+		if err != nil {
+			return nil, err
+		}
+
+		hops = append(hops, hop)
+	}
+
+	return hops, nil
+}
@@ -2,6 +2,7 @@ package wtdb_test
 
 import (
 	crand "crypto/rand"
+	"database/sql"
 	"io"
 	"math/rand"
 	"net"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/flokiorg/flnd/kvdb"
 	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/sqldb"
 	"github.com/flokiorg/flnd/watchtower/blob"
 	"github.com/flokiorg/flnd/watchtower/wtclient"
 	"github.com/flokiorg/flnd/watchtower/wtdb"
@@ -1220,6 +1222,19 @@ func checkCommittedUpdates(t *testing.T, actualUpdates,
 	require.Equal(t, expUpdates, actualUpdates)
 }
 
+// newSQLClientDB creates a new SQL client DB backed by a fresh sqlite
+// database.
+func newSQLClientDB(t *testing.T) wtclient.DB {
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) wtdb.SQLClientDBQueries {
+			return db.WithTx(tx)
+		},
+	)
+
+	return wtdb.NewSQLClientDB(executor)
+}
+
 // TestClientDB asserts the behavior of a fresh client db, a reopened client db,
 // and the mock implementation. This ensures that all databases function
 // identically, especially in the negative paths.
@@ -1276,6 +1291,10 @@ func TestClientDB(t *testing.T) {
 				return db
 			},
 		},
+		{
+			name: "sqlite",
+			init: newSQLClientDB,
+		},
 	}
 
 	tests := []struct {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/flokiorg/flnd/kvdb"
 	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/watchtower/wtclient"
 	"github.com/flokiorg/flnd/watchtower/wtdb"
 	"github.com/stretchr/testify/require"
 )
@@ -32,12 +33,26 @@ func TestDiskQueue(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	testQueue(t, db)
+}
+
+// TestSQLQueue ensures that the SQL client DB's queue methods behave as is
+// expected of a queue.
+func TestSQLQueue(t *testing.T) {
+	t.Parallel()
+
+	testQueue(t, newSQLClientDB(t))
+}
+
+// testQueue asserts that the queue returned by the given client DB behaves as
+// is expected of a queue.
+func testQueue(t *testing.T, db wtclient.DB) {
 	// In order to test that the queue's `onItemWrite` call back (which in
 	// this case will be set to maybeUpdateMaxCommitHeight) is executed as
 	// expected, we need to register a channel so that we can later assert
 	// that it's max height field was updated properly.
 	var chanID lnwire.ChannelID
-	err = db.RegisterChannel(chanID, []byte{})
+	err := db.RegisterChannel(chanID, []byte{})
 	require.NoError(t, err)
 
 	namespace := []byte("test-namespace")
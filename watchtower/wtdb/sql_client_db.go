@@ -0,0 +1,2071 @@
+package wtdb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+
+	"github.com/flokiorg/go-flokicoin/crypto"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"github.com/flokiorg/flnd/watchtower/blob"
+	"github.com/flokiorg/flnd/watchtower/wtpolicy"
+)
+
+// SQLClientDBQueries is an interface that defines the set of operations that
+// can be executed against the watchtower client SQL database.
+type SQLClientDBQueries interface { //nolint:interfacebloat
+	InsertWtClientTower(ctx context.Context,
+		arg sqlc.InsertWtClientTowerParams) (int64, error)
+
+	GetWtClientTowerByID(ctx context.Context, id int64) (
+		sqlc.WtclientTower, error)
+
+	GetWtClientTowerByPubKey(ctx context.Context, pubKey []byte) (
+		sqlc.WtclientTower, error)
+
+	ListWtClientTowers(ctx context.Context) ([]sqlc.WtclientTower, error)
+
+	UpdateWtClientTower(ctx context.Context,
+		arg sqlc.UpdateWtClientTowerParams) error
+
+	DeleteWtClientTower(ctx context.Context, id int64) error
+
+	UpsertWtClientSessionKeyIndex(ctx context.Context,
+		arg sqlc.UpsertWtClientSessionKeyIndexParams) error
+
+	GetWtClientSessionKeyIndex(ctx context.Context,
+		arg sqlc.GetWtClientSessionKeyIndexParams) (int64, error)
+
+	DeleteWtClientSessionKeyIndex(ctx context.Context,
+		arg sqlc.DeleteWtClientSessionKeyIndexParams) error
+
+	DeleteWtClientTowerSessionKeyIndexes(ctx context.Context,
+		towerID int64) error
+
+	GetWtClientSessionKeyIndexSequence(ctx context.Context) (int64, error)
+
+	SetWtClientSessionKeyIndexSequence(ctx context.Context,
+		lastIndex int64) error
+
+	InsertWtClientSession(ctx context.Context,
+		arg sqlc.InsertWtClientSessionParams) (int64, error)
+
+	GetWtClientSession(ctx context.Context, sessionID []byte) (
+		sqlc.WtclientSession, error)
+
+	ListWtClientSessions(ctx context.Context) ([]sqlc.WtclientSession,
+		error)
+
+	ListWtClientTowerSessions(ctx context.Context, towerID int64) (
+		[]sqlc.WtclientSession, error)
+
+	ListWtClientChannelSessions(ctx context.Context, channelID int64) (
+		[]sqlc.WtclientSession, error)
+
+	ListWtClientClosableSessions(ctx context.Context) (
+		[]sqlc.ListWtClientClosableSessionsRow, error)
+
+	UpdateWtClientSessionSeqNum(ctx context.Context,
+		arg sqlc.UpdateWtClientSessionSeqNumParams) error
+
+	UpdateWtClientSessionTowerLastApplied(ctx context.Context,
+		arg sqlc.UpdateWtClientSessionTowerLastAppliedParams) error
+
+	UpdateWtClientSessionStatus(ctx context.Context,
+		arg sqlc.UpdateWtClientSessionStatusParams) error
+
+	UpdateWtClientSessionRogueUpdateCount(ctx context.Context,
+		arg sqlc.UpdateWtClientSessionRogueUpdateCountParams) error
+
+	UpdateWtClientSessionClosableHeight(ctx context.Context,
+		arg sqlc.UpdateWtClientSessionClosableHeightParams) error
+
+	DeleteWtClientSession(ctx context.Context, id int64) error
+
+	InsertWtClientCommittedUpdate(ctx context.Context,
+		arg sqlc.InsertWtClientCommittedUpdateParams) error
+
+	GetWtClientCommittedUpdate(ctx context.Context,
+		arg sqlc.GetWtClientCommittedUpdateParams) (
+		sqlc.WtclientCommittedUpdate, error)
+
+	ListWtClientCommittedUpdates(ctx context.Context, sessionID int64) (
+		[]sqlc.WtclientCommittedUpdate, error)
+
+	CountWtClientCommittedUpdates(ctx context.Context,
+		sessionID int64) (int64, error)
+
+	DeleteWtClientCommittedUpdate(ctx context.Context,
+		arg sqlc.DeleteWtClientCommittedUpdateParams) error
+
+	DeleteWtClientCommittedUpdates(ctx context.Context,
+		sessionID int64) error
+
+	InsertWtClientChannel(ctx context.Context,
+		arg sqlc.InsertWtClientChannelParams) (int64, error)
+
+	GetWtClientChannel(ctx context.Context, channelID []byte) (
+		sqlc.WtclientChannel, error)
+
+	ListWtClientOpenChannels(ctx context.Context) ([]sqlc.WtclientChannel,
+		error)
+
+	ListWtClientSessionChannels(ctx context.Context, sessionID int64) (
+		[]sqlc.WtclientChannel, error)
+
+	UpdateWtClientChannelClosedHeight(ctx context.Context,
+		arg sqlc.UpdateWtClientChannelClosedHeightParams) error
+
+	UpdateWtClientChannelMaxCommitHeight(ctx context.Context,
+		arg sqlc.UpdateWtClientChannelMaxCommitHeightParams) error
+
+	DeleteWtClientChannel(ctx context.Context, id int64) error
+
+	UpsertWtClientAckedRange(ctx context.Context,
+		arg sqlc.UpsertWtClientAckedRangeParams) error
+
+	DeleteWtClientAckedRange(ctx context.Context,
+		arg sqlc.DeleteWtClientAckedRangeParams) error
+
+	ListWtClientAckedRanges(ctx context.Context,
+		arg sqlc.ListWtClientAckedRangesParams) (
+		[]sqlc.WtclientAckedRange, error)
+
+	ListWtClientSessionAckedRanges(ctx context.Context, sessionID int64) (
+		[]sqlc.ListWtClientSessionAckedRangesRow, error)
+
+	CountWtClientChannelAckedRanges(ctx context.Context,
+		channelID int64) (int64, error)
+
+	IsWtClientBackupAcked(ctx context.Context,
+		arg sqlc.IsWtClientBackupAckedParams) (bool, error)
+
+	InsertWtClientBackupQueueItem(ctx context.Context,
+		arg sqlc.InsertWtClientBackupQueueItemParams) error
+
+	GetWtClientBackupQueueInfo(ctx context.Context, namespace []byte) (
+		sqlc.GetWtClientBackupQueueInfoRow, error)
+
+	ListWtClientBackupQueueItems(ctx context.Context,
+		arg sqlc.ListWtClientBackupQueueItemsParams) (
+		[]sqlc.WtclientBackupQueue, error)
+
+	DeleteWtClientBackupQueueItems(ctx context.Context,
+		arg sqlc.DeleteWtClientBackupQueueItemsParams) error
+}
+
+// BatchedSQLClientDBQueries is a version of the SQLClientDBQueries that's
+// capable of batched database operations.
+type BatchedSQLClientDBQueries interface {
+	SQLClientDBQueries
+
+	sqldb.BatchedTx[SQLClientDBQueries]
+}
+
+// SQLClientDB implements the watchtower client database on top of a native
+// SQL database. It behaves exactly like the KV ClientDB, but the towers,
+// sessions, channels and their relations are stored in dedicated tables
+// instead of a set of nested buckets and indexes.
+type SQLClientDB struct {
+	db BatchedSQLClientDBQueries
+}
+
+// NewSQLClientDB creates a new SQLClientDB given an open
+// BatchedSQLClientDBQueries storage backend.
+func NewSQLClientDB(db BatchedSQLClientDBQueries) *SQLClientDB {
+	return &SQLClientDB{
+		db: db,
+	}
+}
+
+// CreateTower initialize an address record used to communicate with a
+// watchtower. Each Tower is assigned a unique ID, that is used to amortize
+// storage costs of the public key when used by multiple sessions. If the tower
+// already exists, the address is appended to the list of all addresses used
+// to that tower previously and its status is set to active.
+func (s *SQLClientDB) CreateTower(lnAddr *lnwire.NetAddress) (*Tower, error) {
+	ctx := context.TODO()
+
+	var tower *Tower
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			pubKey := lnAddr.IdentityKey.SerializeCompressed()
+
+			dbTower, err := db.GetWtClientTowerByPubKey(
+				ctx, pubKey,
+			)
+			switch {
+			// The tower already exists, so we set its status to
+			// active and add the new address to it. If the address
+			// is a duplicate, this will result in no change.
+			case err == nil:
+				tower, err = unmarshalTower(dbTower)
+				if err != nil {
+					return err
+				}
+
+				tower.Status = TowerStatusActive
+				tower.AddAddress(lnAddr.Address)
+
+				return updateTower(ctx, db, tower)
+
+			case !errors.Is(err, sql.ErrNoRows):
+				return err
+			}
+
+			// No such tower exists, so we create a new one.
+			tower = &Tower{
+				IdentityKey: lnAddr.IdentityKey,
+				Addresses:   []net.Addr{lnAddr.Address},
+				Status:      TowerStatusActive,
+			}
+
+			addrs, err := encodeTowerAddresses(tower.Addresses)
+			if err != nil {
+				return err
+			}
+
+			id, err := db.InsertWtClientTower(
+				ctx, sqlc.InsertWtClientTowerParams{
+					PubKey:    pubKey,
+					Addresses: addrs,
+					Status:    int16(tower.Status),
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			tower.ID = TowerID(id)
+
+			return nil
+		}, func() {
+			tower = nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return tower, nil
+}
+
+// RemoveTower modifies a tower's record within the database. If an address is
+// provided, then _only_ the address record should be removed from the tower's
+// persisted state. Otherwise, we'll attempt to mark the tower as inactive. If
+// any of its sessions has unacked updates, then ErrTowerUnackedUpdates is
+// returned. If the tower doesn't have any sessions at all, it'll be completely
+// removed from the database.
+//
+// NOTE: An error is not returned if the tower doesn't exist.
+func (s *SQLClientDB) RemoveTower(pubKey *crypto.PublicKey,
+	addr net.Addr) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbTower, err := db.GetWtClientTowerByPubKey(
+				ctx, pubKey.SerializeCompressed(),
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			tower, err := unmarshalTower(dbTower)
+			if err != nil {
+				return err
+			}
+
+			// If an address is provided, then we should _only_
+			// remove the address record from the database.
+			if addr != nil {
+				// Towers should always have at least one
+				// address saved.
+				tower.RemoveAddress(addr)
+				if len(tower.Addresses) == 0 {
+					return ErrLastTowerAddr
+				}
+
+				return updateTower(ctx, db, tower)
+			}
+
+			sessions, err := db.ListWtClientTowerSessions(
+				ctx, dbTower.ID,
+			)
+			if err != nil {
+				return err
+			}
+
+			// If it doesn't have any sessions, we can completely
+			// remove it from the database.
+			if len(sessions) == 0 {
+				err := db.DeleteWtClientTowerSessionKeyIndexes(
+					ctx, dbTower.ID,
+				)
+				if err != nil {
+					return err
+				}
+
+				return db.DeleteWtClientTower(ctx, dbTower.ID)
+			}
+
+			// Otherwise, we mark the tower as inactive.
+			tower.Status = TowerStatusInactive
+			err = updateTower(ctx, db, tower)
+			if err != nil {
+				return err
+			}
+
+			// We'll do a check to ensure that the tower's sessions
+			// don't have any pending back-ups.
+			for _, session := range sessions {
+				numCommitted, err :=
+					db.CountWtClientCommittedUpdates(
+						ctx, session.ID,
+					)
+				if err != nil {
+					return err
+				}
+
+				if numCommitted > 0 {
+					return ErrTowerUnackedUpdates
+				}
+			}
+
+			return nil
+		}, sqldb.NoOpReset,
+	)
+}
+
+// DeactivateTower sets the given tower's status to inactive. This means that
+// this tower's sessions won't be loaded and used for backups. CreateTower can
+// be used to reactivate the tower again.
+func (s *SQLClientDB) DeactivateTower(pubKey *crypto.PublicKey) error {
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbTower, err := db.GetWtClientTowerByPubKey(
+				ctx, pubKey.SerializeCompressed(),
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTowerNotFound
+			} else if err != nil {
+				return err
+			}
+
+			if TowerStatus(dbTower.Status) == TowerStatusInactive {
+				return nil
+			}
+
+			return db.UpdateWtClientTower(
+				ctx, sqlc.UpdateWtClientTowerParams{
+					ID:        dbTower.ID,
+					Addresses: dbTower.Addresses,
+					Status:    int16(TowerStatusInactive),
+				},
+			)
+		}, sqldb.NoOpReset,
+	)
+}
+
+// LoadTowerByID retrieves a tower by its tower ID.
+func (s *SQLClientDB) LoadTowerByID(towerID TowerID) (*Tower, error) {
+	ctx := context.TODO()
+
+	var tower *Tower
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbTower, err := db.GetWtClientTowerByID(
+				ctx, int64(towerID),
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTowerNotFound
+			} else if err != nil {
+				return err
+			}
+
+			tower, err = unmarshalTower(dbTower)
+
+			return err
+		}, func() {
+			tower = nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return tower, nil
+}
+
+// LoadTower retrieves a tower by its public key.
+func (s *SQLClientDB) LoadTower(pubKey *crypto.PublicKey) (*Tower, error) {
+	ctx := context.TODO()
+
+	var tower *Tower
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbTower, err := db.GetWtClientTowerByPubKey(
+				ctx, pubKey.SerializeCompressed(),
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTowerNotFound
+			} else if err != nil {
+				return err
+			}
+
+			tower, err = unmarshalTower(dbTower)
+
+			return err
+		}, func() {
+			tower = nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return tower, nil
+}
+
+// ListTowers retrieves the list of towers available within the database that
+// have a status matching the given status. The filter function may be set in
+// order to filter out the towers to be returned.
+func (s *SQLClientDB) ListTowers(filter TowerFilterFn) ([]*Tower, error) {
+	ctx := context.TODO()
+
+	var towers []*Tower
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbTowers, err := db.ListWtClientTowers(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, dbTower := range dbTowers {
+				tower, err := unmarshalTower(dbTower)
+				if err != nil {
+					return err
+				}
+
+				if filter != nil && !filter(tower) {
+					continue
+				}
+
+				towers = append(towers, tower)
+			}
+
+			return nil
+		}, func() {
+			towers = nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return towers, nil
+}
+
+// NextSessionKeyIndex reserves a new session key derivation index for a
+// particular tower id and blob type. The index is reserved for that
+// (tower, blob type) pair until CreateClientSession is invoked for that tower
+// and index, at which point a new index for that tower can be reserved.
+// Multiple calls to this method before CreateClientSession is invoked should
+// return the same index unless forceNext is true.
+func (s *SQLClientDB) NextSessionKeyIndex(towerID TowerID, blobType blob.Type,
+	forceNext bool) (uint32, error) {
+
+	ctx := context.TODO()
+
+	var index uint32
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			keyIndexParams := sqlc.GetWtClientSessionKeyIndexParams{
+				TowerID:  int64(towerID),
+				BlobType: int32(blobType),
+			}
+
+			if !forceNext {
+				keyIndex, err := db.GetWtClientSessionKeyIndex(
+					ctx, keyIndexParams,
+				)
+				if err == nil {
+					index = uint32(keyIndex)
+
+					return nil
+				} else if !errors.Is(err, sql.ErrNoRows) {
+					return err
+				}
+			}
+
+			// The key indexes are never reused, so the next index
+			// is derived from the last one handed out, even if the
+			// session it was reserved for no longer exists.
+			current, err := db.GetWtClientSessionKeyIndexSequence(
+				ctx,
+			)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			nextIndex := current + 1
+			if forceNext {
+				nextIndex = current + 1000
+			}
+
+			if nextIndex > math.MaxInt32 {
+				return fmt.Errorf("exhausted session key " +
+					"indexes")
+			}
+
+			err = db.SetWtClientSessionKeyIndexSequence(
+				ctx, nextIndex,
+			)
+			if err != nil {
+				return fmt.Errorf("could not set next key "+
+					"index sequence: %w", err)
+			}
+
+			index = uint32(nextIndex)
+
+			return db.UpsertWtClientSessionKeyIndex(
+				ctx, sqlc.UpsertWtClientSessionKeyIndexParams{
+					TowerID:  keyIndexParams.TowerID,
+					BlobType: keyIndexParams.BlobType,
+					KeyIndex: nextIndex,
+				},
+			)
+		}, func() {
+			index = 0
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return index, nil
+}
+
+// CreateClientSession records a newly negotiated client session in the set of
+// active sessions. The session can be identified by its SessionID.
+func (s *SQLClientDB) CreateClientSession(session *ClientSession) error {
+	ctx := context.TODO()
+
+	policy := session.Policy
+	sessionParams := sqlc.InsertWtClientSessionParams{
+		SessionID:        session.ID[:],
+		TowerID:          int64(session.TowerID),
+		KeyIndex:         int64(session.KeyIndex),
+		BlobType:         int32(policy.BlobType),
+		MaxUpdates:       int32(policy.MaxUpdates),
+		RewardBase:       int64(policy.RewardBase),
+		RewardRate:       int64(policy.RewardRate),
+		SweepFeeRate:     int64(policy.SweepFeeRate),
+		RewardPkScript:   session.RewardPkScript,
+		SeqNum:           int32(session.SeqNum),
+		TowerLastApplied: int32(session.TowerLastApplied),
+		Status:           int16(session.Status),
+	}
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			// Check that client session with this session id
+			// doesn't already exist.
+			_, err := db.GetWtClientSession(ctx, session.ID[:])
+			if err == nil {
+				return ErrClientSessionAlreadyExists
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			// Make sure that we have a record for the tower the
+			// session is being made with.
+			_, err = db.GetWtClientTowerByID(
+				ctx, int64(session.TowerID),
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrTowerNotFound
+			} else if err != nil {
+				return err
+			}
+
+			// Check that this tower's session key index has been
+			// reserved and that it matches the session's index.
+			keyIndexParams := sqlc.GetWtClientSessionKeyIndexParams{
+				TowerID:  int64(session.TowerID),
+				BlobType: int32(session.Policy.BlobType),
+			}
+			keyIndex, err := db.GetWtClientSessionKeyIndex(
+				ctx, keyIndexParams,
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNoReservedKeyIndex
+			} else if err != nil {
+				return err
+			}
+
+			if uint32(keyIndex) != session.KeyIndex {
+				return ErrIncorrectKeyIndex
+			}
+
+			// Remove the key index reservation now that the index
+			// is used by the session.
+			err = db.DeleteWtClientSessionKeyIndex(
+				ctx, sqlc.DeleteWtClientSessionKeyIndexParams(
+					keyIndexParams,
+				),
+			)
+			if err != nil {
+				return err
+			}
+
+			_, err = db.InsertWtClientSession(ctx, sessionParams)
+
+			return err
+		}, sqldb.NoOpReset,
+	)
+}
+
+// GetClientSession loads the ClientSession with the given ID from the DB.
+func (s *SQLClientDB) GetClientSession(id SessionID,
+	opts ...ClientSessionListOption) (*ClientSession, error) {
+
+	ctx := context.TODO()
+
+	cfg := NewClientSessionCfg()
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	var session *ClientSession
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbSession, err := db.GetWtClientSession(ctx, id[:])
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrClientSessionNotFound
+			} else if err != nil {
+				return err
+			}
+
+			session, err = getSQLClientSession(
+				ctx, db, dbSession, cfg,
+			)
+
+			return err
+		}, func() {
+			session = nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// ListClientSessions returns the set of all client sessions known to the db.
+// An optional tower ID can be used to filter out any client sessions in the
+// response that do not correspond to this tower.
+func (s *SQLClientDB) ListClientSessions(id *TowerID,
+	opts ...ClientSessionListOption) (map[SessionID]*ClientSession, error) {
+
+	ctx := context.TODO()
+
+	cfg := NewClientSessionCfg()
+	for _, o := range opts {
+		o(cfg)
+	}
+
+	var sessions map[SessionID]*ClientSession
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLClientDBQueries) error {
+			var (
+				dbSessions []sqlc.WtclientSession
+				err        error
+			)
+			if id != nil {
+				// Ensure that the tower exists before listing
+				// its sessions.
+				_, err = db.GetWtClientTowerByID(
+					ctx, int64(*id),
+				)
+				if errors.Is(err, sql.ErrNoRows) {
+					return ErrTowerNotFound
+				} else if err != nil {
+					return err
+				}
+
+				dbSessions, err = db.ListWtClientTowerSessions(
+					ctx, int64(*id),
+				)
+			} else {
+				dbSessions, err = db.ListWtClientSessions(ctx)
+			}
+			if err != nil {
+				return err
+			}
+
+			for _, dbSession := range dbSessions {
+				session, err := getSQLClientSession(
+					ctx, db, dbSession, cfg,
+				)
+				if errors.Is(err, ErrSessionFailedFilterFn) {
+					continue
+				} else if err != nil {
+					return err
+				}
+
+				sessions[session.ID] = session
+			}
+
+			return nil
+		}, func() {
+			sessions = make(map[SessionID]*ClientSession)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// FetchSessionCommittedUpdates retrieves the current set of un-acked updates
+// of the given session.
+func (s *SQLClientDB) FetchSessionCommittedUpdates(id *SessionID) (
+	[]CommittedUpdate, error) {
+
+	ctx := context.TODO()
+
+	var committedUpdates []CommittedUpdate
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbSession, err := db.GetWtClientSession(ctx, id[:])
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrClientSessionNotFound
+			} else if err != nil {
+				return err
+			}
+
+			dbUpdates, err := db.ListWtClientCommittedUpdates(
+				ctx, dbSession.ID,
+			)
+			if err != nil {
+				return err
+			}
+
+			// Initialize committedUpdates so that we return an
+			// initialized slice if no committed updates exist.
+			committedUpdates = make(
+				[]CommittedUpdate, 0, len(dbUpdates),
+			)
+			for _, dbUpdate := range dbUpdates {
+				committedUpdates = append(
+					committedUpdates,
+					unmarshalCommittedUpdate(dbUpdate),
+				)
+			}
+
+			return nil
+		}, func() {
+			committedUpdates = nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return committedUpdates, nil
+}
+
+// IsAcked returns true if the given backup has been backed up using the given
+// session.
+func (s *SQLClientDB) IsAcked(id *SessionID, backupID *BackupID) (bool,
+	error) {
+
+	ctx := context.TODO()
+
+	var isAcked bool
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLClientDBQueries) error {
+			var err error
+			isAcked, err = db.IsWtClientBackupAcked(
+				ctx, sqlc.IsWtClientBackupAckedParams{
+					SessionID: id[:],
+					ChannelID: backupID.ChanID[:],
+					CommitHeight: int64(
+						backupID.CommitHeight,
+					),
+				},
+			)
+
+			return err
+		}, func() {
+			isAcked = false
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return isAcked, nil
+}
+
+// NumAckedUpdates returns the number of backups that have been successfully
+// backed up using the given session.
+func (s *SQLClientDB) NumAckedUpdates(id *SessionID) (uint64, error) {
+	ctx := context.TODO()
+
+	var numAcked uint64
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbSession, err := db.GetWtClientSession(ctx, id[:])
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			numAcked = uint64(dbSession.RogueUpdateCount)
+
+			ranges, err := db.ListWtClientSessionAckedRanges(
+				ctx, dbSession.ID,
+			)
+			if err != nil {
+				return err
+			}
+
+			for _, r := range ranges {
+				numAcked += uint64(
+					r.RangeEnd - r.RangeStart + 1,
+				)
+			}
+
+			return nil
+		}, func() {
+			numAcked = 0
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return numAcked, nil
+}
+
+// FetchChanInfos loads a mapping from all registered channels to their
+// ChannelInfo. Only the channels that have not yet been marked as closed will
+// be loaded.
+func (s *SQLClientDB) FetchChanInfos() (ChannelInfos, error) {
+	ctx := context.TODO()
+
+	var infos ChannelInfos
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbChannels, err := db.ListWtClientOpenChannels(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, dbChannel := range dbChannels {
+				var chanID lnwire.ChannelID
+				copy(chanID[:], dbChannel.ChannelID)
+
+				info := &ChannelInfo{
+					ClientChanSummary: ClientChanSummary{
+						SweepPkScript: dbChannel.
+							SweepPkScript,
+					},
+				}
+
+				if dbChannel.MaxCommitHeight.Valid {
+					info.MaxHeight = fn.Some(uint64(
+						dbChannel.MaxCommitHeight.Int64,
+					))
+				}
+
+				infos[chanID] = info
+			}
+
+			return nil
+		}, func() {
+			infos = make(ChannelInfos)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return infos, nil
+}
+
+// RegisterChannel registers a channel for use within the client database. For
+// now, all that is stored in the channel summary is the sweep pkscript that
+// we'd like any tower sweeps to pay into. In the future, this will be extended
+// to contain more info about the channel.
+func (s *SQLClientDB) RegisterChannel(chanID lnwire.ChannelID,
+	sweepPkScript []byte) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			_, err := db.GetWtClientChannel(ctx, chanID[:])
+			if err == nil {
+				return ErrChannelAlreadyRegistered
+			} else if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			// The sweep script is a required column, so a nil
+			// script is stored as an empty one.
+			if sweepPkScript == nil {
+				sweepPkScript = []byte{}
+			}
+
+			_, err = db.InsertWtClientChannel(
+				ctx, sqlc.InsertWtClientChannelParams{
+					ChannelID:     chanID[:],
+					SweepPkScript: sweepPkScript,
+				},
+			)
+
+			return err
+		}, sqldb.NoOpReset,
+	)
+}
+
+// MarkBackupIneligible records that the state identified by the (channel id,
+// commit height) tuple was ineligible for being backed up under the current
+// policy. This state can be retried later under a different policy.
+func (s *SQLClientDB) MarkBackupIneligible(_ lnwire.ChannelID, _ uint64) error {
+	return nil
+}
+
+// ListClosableSessions fetches and returns the IDs for all sessions marked as
+// closable.
+func (s *SQLClientDB) ListClosableSessions() (map[SessionID]uint32, error) {
+	ctx := context.TODO()
+
+	var sessions map[SessionID]uint32
+	err := s.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLClientDBQueries) error {
+			rows, err := db.ListWtClientClosableSessions(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, row := range rows {
+				var id SessionID
+				copy(id[:], row.SessionID)
+
+				sessions[id] = uint32(row.ClosableHeight.Int64)
+			}
+
+			return nil
+		}, func() {
+			sessions = make(map[SessionID]uint32)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// DeleteSession can be called when a session should be deleted from the DB.
+// All references to the session will also be deleted from the DB. Note that a
+// session will only be deleted if was previously marked as closable.
+func (s *SQLClientDB) DeleteSession(id SessionID) error {
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			// If the session does not exist then it has already
+			// been deleted and so our work is done.
+			dbSession, err := db.GetWtClientSession(ctx, id[:])
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			// First we check if the session has actually been
+			// marked as closable.
+			if !dbSession.ClosableHeight.Valid {
+				return ErrSessionNotClosable
+			}
+
+			channels, err := db.ListWtClientSessionChannels(
+				ctx, dbSession.ID,
+			)
+			if err != nil {
+				return err
+			}
+
+			// There is a small chance that the session only
+			// contains rogue updates. In that case, there will be
+			// no acked ranges but the rogue update count will be
+			// equal the MaxUpdates.
+			if dbSession.RogueUpdateCount == int64(
+				dbSession.MaxUpdates,
+			) {
+
+				// Do a sanity check to ensure that no acked
+				// ranges exist in this case.
+				if len(channels) != 0 {
+					return fmt.Errorf("acked updates "+
+						"exist for session with a "+
+						"max-updates(%d) rogue count",
+						dbSession.RogueUpdateCount)
+				}
+
+				return db.DeleteWtClientSession(
+					ctx, dbSession.ID,
+				)
+			}
+
+			// A session would only be considered closable if it
+			// was exhausted. Meaning that it should not be the case
+			// that it has no acked-updates.
+			if len(channels) == 0 {
+				return fmt.Errorf("cannot delete session %s "+
+					"since it is not yet exhausted", id)
+			}
+
+			// Deleting the session also deletes its acked ranges
+			// and committed updates.
+			err = db.DeleteWtClientSession(ctx, dbSession.ID)
+			if err != nil {
+				return err
+			}
+
+			// If this was the last session for any of its
+			// channels, we can now delete the channel completely.
+			for _, channel := range channels {
+				numRanges, err :=
+					db.CountWtClientChannelAckedRanges(
+						ctx, channel.ID,
+					)
+				if err != nil {
+					return err
+				}
+
+				if numRanges > 0 {
+					continue
+				}
+
+				err = db.DeleteWtClientChannel(ctx, channel.ID)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}, sqldb.NoOpReset,
+	)
+}
+
+// MarkChannelClosed will mark a registered channel as closed by setting its
+// closed-height as the given block height. It returns a list of session IDs for
+// sessions that are now considered closable due to the close of this channel.
+// The details for this channel will be deleted from the DB if there are no more
+// sessions in the DB that contain updates for this channel.
+func (s *SQLClientDB) MarkChannelClosed(chanID lnwire.ChannelID,
+	blockHeight uint32) ([]SessionID, error) {
+
+	ctx := context.TODO()
+
+	var closableSessions []SessionID
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbChannel, err := db.GetWtClientChannel(ctx, chanID[:])
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrChannelNotRegistered
+			} else if err != nil {
+				return err
+			}
+
+			sessions, err := db.ListWtClientChannelSessions(
+				ctx, dbChannel.ID,
+			)
+			if err != nil {
+				return err
+			}
+
+			// If there are no sessions for this channel, the
+			// channel can be deleted.
+			if len(sessions) == 0 {
+				return db.DeleteWtClientChannel(
+					ctx, dbChannel.ID,
+				)
+			}
+
+			// Otherwise, mark the channel as closed.
+			//nolint:ll
+			closedParams := sqlc.UpdateWtClientChannelClosedHeightParams{
+				ID:           dbChannel.ID,
+				ClosedHeight: sqldb.SQLInt64(blockHeight),
+			}
+			err = db.UpdateWtClientChannelClosedHeight(
+				ctx, closedParams,
+			)
+			if err != nil {
+				return err
+			}
+
+			// Now iterate through all the sessions of the channel
+			// to check if any of them are closeable.
+			for _, dbSession := range sessions {
+				isClosable, err := isSQLSessionClosable(
+					ctx, db, dbSession,
+				)
+				if err != nil {
+					return err
+				}
+
+				if !isClosable {
+					continue
+				}
+
+				// Record the block height that this last
+				// channel was closed in. This will be used in
+				// future to determine when we should delete
+				// the session.
+				//nolint:ll
+				params := sqlc.UpdateWtClientSessionClosableHeightParams{
+					ID: dbSession.ID,
+					ClosableHeight: sqldb.SQLInt64(
+						blockHeight,
+					),
+				}
+				err = db.UpdateWtClientSessionClosableHeight(
+					ctx, params,
+				)
+				if err != nil {
+					return err
+				}
+
+				var id SessionID
+				copy(id[:], dbSession.SessionID)
+
+				closableSessions = append(closableSessions, id)
+			}
+
+			return nil
+		}, func() {
+			closableSessions = nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return closableSessions, nil
+}
+
+// isSQLSessionClosable returns true if a session is considered closable. A
+// session is considered closable only if all the following points are true:
+//  1. It has no un-acked updates.
+//  2. It is exhausted (ie it can't accept any more updates) OR it has been
+//     marked as terminal.
+//  3. All the channels that it has acked updates for are closed.
+func isSQLSessionClosable(ctx context.Context, db SQLClientDBQueries,
+	dbSession sqlc.WtclientSession) (bool, error) {
+
+	// If the session has any un-acked updates, then it is not yet
+	// closable.
+	numCommitted, err := db.CountWtClientCommittedUpdates(ctx, dbSession.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if numCommitted > 0 {
+		return false, nil
+	}
+
+	isTerminal := CSessionStatus(dbSession.Status) == CSessionTerminal
+
+	// If the session is not yet exhausted, and it is not yet in a terminal
+	// state then it is not yet closable.
+	if !isTerminal && dbSession.SeqNum < dbSession.MaxUpdates {
+		return false, nil
+	}
+
+	// Either acked updates should exist _or_ the rogue update count must be
+	// equal to the session's MaxUpdates value, otherwise something is wrong
+	// because the above check ensures that the session has been exhausted.
+	if dbSession.RogueUpdateCount == int64(dbSession.MaxUpdates) {
+		return true, nil
+	}
+
+	channels, err := db.ListWtClientSessionChannels(ctx, dbSession.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if len(channels) == 0 {
+		if isTerminal {
+			return true, nil
+		}
+
+		var id SessionID
+		copy(id[:], dbSession.SessionID)
+
+		return false, fmt.Errorf("no acked-updates found for "+
+			"exhausted session %s", id)
+	}
+
+	// If any of the channels that the session has acked updates for is
+	// not yet closed, then the session is not yet closable.
+	for _, channel := range channels {
+		if !channel.ClosedHeight.Valid {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// CommitUpdate persists the CommittedUpdate provided in the slot for (session,
+// seqNum). This allows the client to retransmit this update on startup.
+func (s *SQLClientDB) CommitUpdate(id *SessionID,
+	update *CommittedUpdate) (uint16, error) {
+
+	ctx := context.TODO()
+
+	var lastApplied uint16
+	err := s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbSession, err := db.GetWtClientSession(ctx, id[:])
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrClientSessionNotFound
+			} else if err != nil {
+				return err
+			}
+
+			// Check to see if a committed update already exists
+			// for this sequence number.
+			dbUpdate, err := db.GetWtClientCommittedUpdate(
+				ctx, sqlc.GetWtClientCommittedUpdateParams{
+					SessionID: dbSession.ID,
+					SeqNum:    int32(update.SeqNum),
+				},
+			)
+			switch {
+			// If an existing committed update has a different
+			// hint, we'll reject this newer update. Otherwise,
+			// capture the last applied value and succeed.
+			case err == nil:
+				if !bytes.Equal(dbUpdate.Hint, update.Hint[:]) {
+					return ErrUpdateAlreadyCommitted
+				}
+
+				lastApplied = uint16(dbSession.TowerLastApplied)
+
+				return nil
+
+			case !errors.Is(err, sql.ErrNoRows):
+				return err
+			}
+
+			// There's no committed update for this sequence
+			// number, ensure that we are committing the next
+			// unallocated one.
+			if update.SeqNum != uint16(dbSession.SeqNum)+1 {
+				return ErrCommitUnorderedUpdate
+			}
+
+			// Increment the session's sequence number.
+			err = db.UpdateWtClientSessionSeqNum(
+				ctx, sqlc.UpdateWtClientSessionSeqNumParams{
+					ID:     dbSession.ID,
+					SeqNum: int32(update.SeqNum),
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			backupID := update.BackupID
+			err = db.InsertWtClientCommittedUpdate(
+				ctx, sqlc.InsertWtClientCommittedUpdateParams{
+					SessionID: dbSession.ID,
+					SeqNum:    int32(update.SeqNum),
+					ChannelID: backupID.ChanID[:],
+					CommitHeight: int64(
+						backupID.CommitHeight,
+					),
+					Hint:          update.Hint[:],
+					EncryptedBlob: update.EncryptedBlob,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			// Update the channel's max commitment height if
+			// needed.
+			err = updateSQLMaxCommitHeight(ctx, db, backupID)
+			if err != nil {
+				return err
+			}
+
+			// Finally, capture the session's last applied value so
+			// it can be sent in the next state update to the
+			// tower.
+			lastApplied = uint16(dbSession.TowerLastApplied)
+
+			return nil
+		}, func() {
+			lastApplied = 0
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return lastApplied, nil
+}
+
+// AckUpdate persists an acknowledgment for a given (session, seqnum) pair. This
+// removes the update from the set of committed updates, and validates the
+// lastApplied value returned from the tower.
+func (s *SQLClientDB) AckUpdate(id *SessionID, seqNum uint16,
+	lastApplied uint16) error {
+
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbSession, err := db.GetWtClientSession(ctx, id[:])
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrClientSessionNotFound
+			} else if err != nil {
+				return err
+			}
+
+			// If the tower has acked a sequence number beyond our
+			// highest sequence number, fail.
+			if lastApplied > uint16(dbSession.SeqNum) {
+				return ErrUnallocatedLastApplied
+			}
+
+			// If the tower acked with a lower sequence number than
+			// it gave us prior, fail.
+			if lastApplied < uint16(dbSession.TowerLastApplied) {
+				return ErrLastAppliedReversion
+			}
+
+			//nolint:ll
+			params := sqlc.UpdateWtClientSessionTowerLastAppliedParams{
+				ID:               dbSession.ID,
+				TowerLastApplied: int32(lastApplied),
+			}
+			err = db.UpdateWtClientSessionTowerLastApplied(
+				ctx, params,
+			)
+			if err != nil {
+				return err
+			}
+
+			// Assert that a committed update exists for this
+			// sequence number and remove it.
+			dbUpdate, err := db.GetWtClientCommittedUpdate(
+				ctx, sqlc.GetWtClientCommittedUpdateParams{
+					SessionID: dbSession.ID,
+					SeqNum:    int32(seqNum),
+				},
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrCommittedUpdateNotFound
+			} else if err != nil {
+				return err
+			}
+
+			err = db.DeleteWtClientCommittedUpdate(
+				ctx, sqlc.DeleteWtClientCommittedUpdateParams{
+					SessionID: dbSession.ID,
+					SeqNum:    int32(seqNum),
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			// There is a chance that the channel corresponding to
+			// this update has been closed and that the details for
+			// this channel no longer exist in the DB. In that case,
+			// we consider this a rogue update and all we do is make
+			// sure to keep track of the number of rogue updates for
+			// this session.
+			dbChannel, err := db.GetWtClientChannel(
+				ctx, dbUpdate.ChannelID,
+			)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ackSQLRogueUpdate(ctx, db, dbSession)
+
+			case err != nil:
+				return err
+			}
+
+			// Load the range index for the given session-channel
+			// pair and add the acked height to it.
+			dbRanges, err := db.ListWtClientAckedRanges(
+				ctx, sqlc.ListWtClientAckedRangesParams{
+					SessionID: dbSession.ID,
+					ChannelID: dbChannel.ID,
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			ranges := make(map[uint64]uint64, len(dbRanges))
+			for _, r := range dbRanges {
+				start := uint64(r.RangeStart)
+				ranges[start] = uint64(r.RangeEnd)
+			}
+
+			index, err := NewRangeIndex(
+				ranges, WithSerializeUint64Fn(writeBigSize),
+			)
+			if err != nil {
+				return err
+			}
+
+			return index.Add(
+				uint64(dbUpdate.CommitHeight),
+				&sqlAckedRangeStore{
+					ctx:       ctx,
+					db:        db,
+					sessionID: dbSession.ID,
+					channelID: dbChannel.ID,
+				},
+			)
+		}, sqldb.NoOpReset,
+	)
+}
+
+// ackSQLRogueUpdate increments the rogue update count of the given session. If
+// the session only has rogue updates, it is marked as closable.
+func ackSQLRogueUpdate(ctx context.Context, db SQLClientDBQueries,
+	dbSession sqlc.WtclientSession) error {
+
+	rogueCount := dbSession.RogueUpdateCount + 1
+	err := db.UpdateWtClientSessionRogueUpdateCount(
+		ctx, sqlc.UpdateWtClientSessionRogueUpdateCountParams{
+			ID:               dbSession.ID,
+			RogueUpdateCount: rogueCount,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	// In the rare chance that this session only has rogue updates, we
+	// check here if the count is equal to the MaxUpdate of the session. If
+	// it is, then we mark the session as closable.
+	if rogueCount != int64(dbSession.MaxUpdates) {
+		return nil
+	}
+
+	// Before we mark the session as closable, we do a sanity check to
+	// ensure that this session has no acked ranges.
+	ranges, err := db.ListWtClientSessionAckedRanges(ctx, dbSession.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(ranges) != 0 {
+		var id SessionID
+		copy(id[:], dbSession.SessionID)
+
+		return fmt.Errorf("session(%s) has acked ranges but has a "+
+			"rogue count indicating saturation", id)
+	}
+
+	return db.UpdateWtClientSessionClosableHeight(
+		ctx, sqlc.UpdateWtClientSessionClosableHeightParams{
+			ID:             dbSession.ID,
+			ClosableHeight: sqldb.SQLInt64(0),
+		},
+	)
+}
+
+// GetDBQueue returns a BackupID Queue instance under the given namespace.
+func (s *SQLClientDB) GetDBQueue(namespace []byte) Queue[*BackupID] {
+	return &sqlBackupQueue{
+		db:        s.db,
+		namespace: namespace,
+	}
+}
+
+// TerminateSession sets the given session's status to CSessionTerminal meaning
+// that it will not be usable again. An error will be returned if the given
+// session still has un-acked updates that should be attended to.
+func (s *SQLClientDB) TerminateSession(id SessionID) error {
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbSession, err := db.GetWtClientSession(ctx, id[:])
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrClientSessionNotFound
+			} else if err != nil {
+				return err
+			}
+
+			// Don't mark the session as terminal if there are
+			// still un-acked updates.
+			numCommitted, err := db.CountWtClientCommittedUpdates(
+				ctx, dbSession.ID,
+			)
+			if err != nil {
+				return err
+			}
+
+			if numCommitted > 0 {
+				return ErrSessionHasUnackedUpdates
+			}
+
+			return db.UpdateWtClientSessionStatus(
+				ctx, sqlc.UpdateWtClientSessionStatusParams{
+					ID:     dbSession.ID,
+					Status: int16(CSessionTerminal),
+				},
+			)
+		}, sqldb.NoOpReset,
+	)
+}
+
+// DeleteCommittedUpdates deletes all the committed updates for the given
+// session.
+func (s *SQLClientDB) DeleteCommittedUpdates(id *SessionID) error {
+	ctx := context.TODO()
+
+	return s.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbSession, err := db.GetWtClientSession(ctx, id[:])
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("session %s not found", id)
+			} else if err != nil {
+				return err
+			}
+
+			numCommitted, err := db.CountWtClientCommittedUpdates(
+				ctx, dbSession.ID,
+			)
+			if err != nil {
+				return err
+			}
+
+			// If there are no committed updates for the session,
+			// then there is nothing to do.
+			if numCommitted == 0 {
+				return nil
+			}
+
+			// Once we delete a committed update from the session,
+			// the SeqNum of the session will be incorrect and so
+			// the session should be marked as terminal.
+			err = db.UpdateWtClientSessionStatus(
+				ctx, sqlc.UpdateWtClientSessionStatusParams{
+					ID:     dbSession.ID,
+					Status: int16(CSessionTerminal),
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			return db.DeleteWtClientCommittedUpdates(
+				ctx, dbSession.ID,
+			)
+		}, sqldb.NoOpReset,
+	)
+}
+
+// getSQLClientSession converts the given session row into a ClientSession and
+// passes it through the filter functions and call-backs of the given config.
+// ErrSessionFailedFilterFn is returned if the session does not pass one of the
+// filter functions.
+func getSQLClientSession(ctx context.Context, db SQLClientDBQueries,
+	dbSession sqlc.WtclientSession,
+	cfg *ClientSessionListCfg) (*ClientSession, error) {
+
+	session := unmarshalClientSession(dbSession)
+
+	if cfg.PreEvaluateFilterFn != nil && !cfg.PreEvaluateFilterFn(session) {
+		return nil, ErrSessionFailedFilterFn
+	}
+
+	// Pass the session's committed (un-acked) updates through the call-back
+	// if one is provided.
+	var numCommittedUpdates uint16
+	if cfg.PerCommittedUpdate != nil {
+		dbUpdates, err := db.ListWtClientCommittedUpdates(
+			ctx, dbSession.ID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dbUpdate := range dbUpdates {
+			update := unmarshalCommittedUpdate(dbUpdate)
+			cfg.PerCommittedUpdate(session, &update)
+		}
+
+		numCommittedUpdates = uint16(len(dbUpdates))
+	} else {
+		numCommitted, err := db.CountWtClientCommittedUpdates(
+			ctx, dbSession.ID,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		numCommittedUpdates = uint16(numCommitted)
+	}
+
+	// Pass the session's acked updates through the call-backs if they are
+	// provided.
+	if cfg.PerRogueUpdateCount != nil {
+		cfg.PerRogueUpdateCount(
+			session, uint16(dbSession.RogueUpdateCount),
+		)
+	}
+
+	if cfg.PerMaxHeight != nil || cfg.PerNumAckedUpdates != nil {
+		err := filterSQLClientSessionAcks(
+			ctx, db, dbSession, session, cfg,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.PostEvaluateFilterFn != nil &&
+		!cfg.PostEvaluateFilterFn(session, numCommittedUpdates) {
+
+		return nil, ErrSessionFailedFilterFn
+	}
+
+	return session, nil
+}
+
+// filterSQLClientSessionAcks passes the max acked height and the number of
+// acked updates of each of the session's channels to the PerMaxHeight and
+// PerNumAckedUpdates call-backs of the given config.
+func filterSQLClientSessionAcks(ctx context.Context, db SQLClientDBQueries,
+	dbSession sqlc.WtclientSession, session *ClientSession,
+	cfg *ClientSessionListCfg) error {
+
+	ranges, err := db.ListWtClientSessionAckedRanges(ctx, dbSession.ID)
+	if err != nil {
+		return err
+	}
+
+	// The ranges are ordered by channel and range start, so the ranges of
+	// a channel are consecutive and the last one holds its max height.
+	type chanAcks struct {
+		chanID    lnwire.ChannelID
+		maxHeight uint64
+		numAcked  uint64
+	}
+
+	var acks []*chanAcks
+	for _, r := range ranges {
+		var chanID lnwire.ChannelID
+		copy(chanID[:], r.ChannelID)
+
+		if len(acks) == 0 || acks[len(acks)-1].chanID != chanID {
+			acks = append(acks, &chanAcks{chanID: chanID})
+		}
+
+		current := acks[len(acks)-1]
+		current.maxHeight = uint64(r.RangeEnd)
+		current.numAcked += uint64(r.RangeEnd - r.RangeStart + 1)
+	}
+
+	for _, a := range acks {
+		if cfg.PerMaxHeight != nil {
+			cfg.PerMaxHeight(session, a.chanID, a.maxHeight)
+		}
+
+		if cfg.PerNumAckedUpdates != nil {
+			cfg.PerNumAckedUpdates(
+				session, a.chanID, uint16(a.numAcked),
+			)
+		}
+	}
+
+	return nil
+}
+
+// updateSQLMaxCommitHeight updates the max commitment height of the backup's
+// channel if the backup's height is larger than the current one. Nothing is
+// done if the channel is not registered.
+func updateSQLMaxCommitHeight(ctx context.Context, db SQLClientDBQueries,
+	backupID BackupID) error {
+
+	return db.UpdateWtClientChannelMaxCommitHeight(
+		ctx, sqlc.UpdateWtClientChannelMaxCommitHeightParams{
+			MaxCommitHeight: sqldb.SQLInt64(backupID.CommitHeight),
+			ChannelID:       backupID.ChanID[:],
+		},
+	)
+}
+
+// updateTower persists the addresses and status of the given tower.
+func updateTower(ctx context.Context, db SQLClientDBQueries,
+	tower *Tower) error {
+
+	addrs, err := encodeTowerAddresses(tower.Addresses)
+	if err != nil {
+		return err
+	}
+
+	return db.UpdateWtClientTower(ctx, sqlc.UpdateWtClientTowerParams{
+		ID:        int64(tower.ID),
+		Addresses: addrs,
+		Status:    int16(tower.Status),
+	})
+}
+
+// encodeTowerAddresses serializes the given list of tower addresses.
+func encodeTowerAddresses(addrs []net.Addr) ([]byte, error) {
+	var b bytes.Buffer
+	if err := WriteElement(&b, addrs); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// unmarshalTower converts the given tower row into a Tower.
+func unmarshalTower(dbTower sqlc.WtclientTower) (*Tower, error) {
+	pubKey, err := crypto.ParsePubKey(dbTower.PubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []net.Addr
+	err = ReadElement(bytes.NewReader(dbTower.Addresses), &addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tower{
+		ID:          TowerID(dbTower.ID),
+		IdentityKey: pubKey,
+		Addresses:   addrs,
+		Status:      TowerStatus(dbTower.Status),
+	}, nil
+}
+
+// unmarshalClientSession converts the given session row into a ClientSession.
+func unmarshalClientSession(dbSession sqlc.WtclientSession) *ClientSession {
+	var id SessionID
+	copy(id[:], dbSession.SessionID)
+
+	// Like the KV store, we never return a nil reward script.
+	rewardPkScript := dbSession.RewardPkScript
+	if rewardPkScript == nil {
+		rewardPkScript = []byte{}
+	}
+
+	return &ClientSession{
+		ID: id,
+		ClientSessionBody: ClientSessionBody{
+			SeqNum:           uint16(dbSession.SeqNum),
+			TowerLastApplied: uint16(dbSession.TowerLastApplied),
+			TowerID:          TowerID(dbSession.TowerID),
+			KeyIndex:         uint32(dbSession.KeyIndex),
+			Policy: wtpolicy.Policy{
+				TxPolicy: wtpolicy.TxPolicy{
+					BlobType: blob.Type(
+						dbSession.BlobType,
+					),
+					RewardBase: uint32(
+						dbSession.RewardBase,
+					),
+					RewardRate: uint32(
+						dbSession.RewardRate,
+					),
+					SweepFeeRate: chainfee.SatPerKWeight(
+						dbSession.SweepFeeRate,
+					),
+				},
+				MaxUpdates: uint16(dbSession.MaxUpdates),
+			},
+			Status:         CSessionStatus(dbSession.Status),
+			RewardPkScript: rewardPkScript,
+		},
+	}
+}
+
+// unmarshalCommittedUpdate converts the given committed update row into a
+// CommittedUpdate.
+func unmarshalCommittedUpdate(
+	dbUpdate sqlc.WtclientCommittedUpdate) CommittedUpdate {
+
+	update := CommittedUpdate{
+		SeqNum: uint16(dbUpdate.SeqNum),
+		CommittedUpdateBody: CommittedUpdateBody{
+			BackupID: BackupID{
+				CommitHeight: uint64(dbUpdate.CommitHeight),
+			},
+			EncryptedBlob: dbUpdate.EncryptedBlob,
+		},
+	}
+	copy(update.BackupID.ChanID[:], dbUpdate.ChannelID)
+	copy(update.Hint[:], dbUpdate.Hint)
+
+	return update
+}
+
+// sqlAckedRangeStore is a KVStore that persists the changes of a session's
+// RangeIndex for a channel as rows of the acked ranges table. The keys and
+// values are the BigSize encoded start and end heights of the ranges.
+type sqlAckedRangeStore struct {
+	ctx       context.Context //nolint:containedctx
+	db        SQLClientDBQueries
+	sessionID int64
+	channelID int64
+}
+
+// A compile-time assertion to ensure sqlAckedRangeStore implements the
+// KVStore interface.
+var _ KVStore = (*sqlAckedRangeStore)(nil)
+
+// Put saves the range with the given start and end height.
+//
+// NOTE: This is part of the KVStore interface.
+func (s *sqlAckedRangeStore) Put(key, value []byte) error {
+	start, err := readBigSize(key)
+	if err != nil {
+		return err
+	}
+
+	end, err := readBigSize(value)
+	if err != nil {
+		return err
+	}
+
+	return s.db.UpsertWtClientAckedRange(
+		s.ctx, sqlc.UpsertWtClientAckedRangeParams{
+			SessionID:  s.sessionID,
+			ChannelID:  s.channelID,
+			RangeStart: int64(start),
+			RangeEnd:   int64(end),
+		},
+	)
+}
+
+// Delete removes the range with the given start height.
+//
+// NOTE: This is part of the KVStore interface.
+func (s *sqlAckedRangeStore) Delete(key []byte) error {
+	start, err := readBigSize(key)
+	if err != nil {
+		return err
+	}
+
+	return s.db.DeleteWtClientAckedRange(
+		s.ctx, sqlc.DeleteWtClientAckedRangeParams{
+			SessionID:  s.sessionID,
+			ChannelID:  s.channelID,
+			RangeStart: int64(start),
+		},
+	)
+}
+
+// sqlBackupQueue is a Queue of BackupIDs that is persisted in the backup queue
+// table. Every item is stored with its position in the queue so that items
+// can be added to both the head and the tail of the queue.
+type sqlBackupQueue struct {
+	db        BatchedSQLClientDBQueries
+	namespace []byte
+}
+
+// A compile-time assertion to ensure sqlBackupQueue implements the Queue
+// interface.
+var _ Queue[*BackupID] = (*sqlBackupQueue)(nil)
+
+// Len returns the number of tasks in the queue.
+//
+// NOTE: This is part of the Queue interface.
+func (q *sqlBackupQueue) Len() (uint64, error) {
+	ctx := context.TODO()
+
+	var numItems uint64
+	err := q.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLClientDBQueries) error {
+			info, err := db.GetWtClientBackupQueueInfo(
+				ctx, q.namespace,
+			)
+			if err != nil {
+				return err
+			}
+
+			numItems = uint64(info.NumItems)
+
+			return nil
+		}, func() {
+			numItems = 0
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return numItems, nil
+}
+
+// Push adds new items to the tail of the queue.
+//
+// NOTE: This is part of the Queue interface.
+func (q *sqlBackupQueue) Push(items ...*BackupID) error {
+	ctx := context.TODO()
+
+	return q.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			info, err := db.GetWtClientBackupQueueInfo(
+				ctx, q.namespace,
+			)
+			if err != nil {
+				return err
+			}
+
+			nextIndex := info.TailIndex + 1
+			for i, item := range items {
+				err := q.addItem(
+					ctx, db, nextIndex+int64(i), item,
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}, sqldb.NoOpReset,
+	)
+}
+
+// PopUpTo attempts to pop up to n items from the head of the queue. If no more
+// items are in the queue then ErrEmptyQueue is returned.
+//
+// NOTE: This is part of the Queue interface.
+func (q *sqlBackupQueue) PopUpTo(n int) ([]*BackupID, error) {
+	ctx := context.TODO()
+
+	var items []*BackupID
+	err := q.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			dbItems, err := db.ListWtClientBackupQueueItems(
+				ctx, sqlc.ListWtClientBackupQueueItemsParams{
+					Namespace: q.namespace,
+					NumLimit:  int32(n),
+				},
+			)
+			if err != nil {
+				return err
+			}
+
+			if len(dbItems) == 0 {
+				return ErrEmptyQueue
+			}
+
+			for _, dbItem := range dbItems {
+				height := uint64(dbItem.CommitHeight)
+				item := &BackupID{CommitHeight: height}
+				copy(item.ChanID[:], dbItem.ChannelID)
+
+				items = append(items, item)
+			}
+
+			return db.DeleteWtClientBackupQueueItems(
+				ctx, sqlc.DeleteWtClientBackupQueueItemsParams{
+					Namespace: q.namespace,
+					QueueIndex: dbItems[len(dbItems)-1].
+						QueueIndex,
+				},
+			)
+		}, func() {
+			items = nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// PushHead pushes new items to the head of the queue. The items keep their
+// order, so the first of the given items will be the next item to be popped.
+//
+// NOTE: This is part of the Queue interface.
+func (q *sqlBackupQueue) PushHead(items ...*BackupID) error {
+	ctx := context.TODO()
+
+	return q.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLClientDBQueries) error {
+			info, err := db.GetWtClientBackupQueueInfo(
+				ctx, q.namespace,
+			)
+			if err != nil {
+				return err
+			}
+
+			firstIndex := info.HeadIndex - int64(len(items))
+			for i, item := range items {
+				err := q.addItem(
+					ctx, db, firstIndex+int64(i), item,
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}, sqldb.NoOpReset,
+	)
+}
+
+// addItem stores the given item at the given position of the queue and updates
+// the max commitment height of the item's channel.
+func (q *sqlBackupQueue) addItem(ctx context.Context, db SQLClientDBQueries,
+	index int64, item *BackupID) error {
+
+	err := db.InsertWtClientBackupQueueItem(
+		ctx, sqlc.InsertWtClientBackupQueueItemParams{
+			Namespace:    q.namespace,
+			QueueIndex:   index,
+			ChannelID:    item.ChanID[:],
+			CommitHeight: int64(item.CommitHeight),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return updateSQLMaxCommitHeight(ctx, db, *item)
+}
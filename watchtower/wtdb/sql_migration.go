@@ -0,0 +1,878 @@
+package wtdb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flokiorg/flnd/fn"
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"golang.org/x/time/rate"
+)
+
+// ErrMissingKVBackend is returned when a watchtower database is migrated to
+// SQL without its KV backend. Succeeding without it would record the migration
+// as applied and leave the KV data behind for good.
+var ErrMissingKVBackend = errors.New("KV backend of the watchtower database " +
+	"is missing")
+
+// MigrateClientDBToSQL migrates the towers, sessions, channels and backup
+// queues of the KV watchtower client database to the native SQL database.
+// Every migrated tower, session and channel is read back and compared with
+// its KV counterpart.
+//
+// NOTE: The tower IDs are re-assigned by the SQL database, the sessions are
+// updated to reference the new IDs. Session key indexes that were reserved
+// but not yet used are not migrated, only the index sequence is. The client
+// reserves a fresh index the next time it negotiates a session.
+func MigrateClientDBToSQL(ctx context.Context, kvBackend kvdb.Backend,
+	tx *sqlc.Queries) error {
+
+	if kvBackend == nil {
+		return fmt.Errorf("client DB: %w", ErrMissingKVBackend)
+	}
+
+	// If the client wasn't active since the last upgrade, its KV database
+	// may still be on an older version. As the client won't open it again
+	// once it runs on SQL, the KV migrations are applied here.
+	migrate, err := syncKVVersions(
+		&ClientDB{db: kvBackend}, clientDBVersions,
+	)
+	if err != nil {
+		return err
+	}
+	if !migrate {
+		log.Infof("Watchtower client DB was never initialized, " +
+			"nothing to migrate to SQL")
+
+		return nil
+	}
+
+	log.Infof("Starting migration of the watchtower client DB from KV " +
+		"to SQL")
+
+	var (
+		numTowers, numSessions, numChannels, numQueueItems int
+	)
+	err = kvdb.View(kvBackend, func(kvTx kvdb.RTx) error {
+		towerIDs, err := migrateTowers(ctx, kvTx, tx)
+		if err != nil {
+			return err
+		}
+		numTowers = len(towerIDs)
+
+		err = migrateSessionKeyIndexSequence(ctx, kvTx, tx)
+		if err != nil {
+			return err
+		}
+
+		chanIDs, err := migrateChannels(ctx, kvTx, tx)
+		if err != nil {
+			return err
+		}
+		numChannels = len(chanIDs)
+
+		numSessions, err = migrateClientSessions(
+			ctx, kvTx, tx, towerIDs, chanIDs,
+		)
+		if err != nil {
+			return err
+		}
+
+		numQueueItems, err = migrateBackupQueues(ctx, kvTx, tx)
+
+		return err
+	}, func() {
+		numTowers, numSessions, numChannels, numQueueItems = 0, 0, 0, 0
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Migration of the watchtower client DB from KV to SQL "+
+		"completed: %d towers, %d sessions, %d channels and %d queued "+
+		"backups migrated", numTowers, numSessions, numChannels,
+		numQueueItems)
+
+	return nil
+}
+
+// syncKVVersions applies the pending KV migrations of the given database. It
+// returns false if the database was never initialized, in which case it holds
+// no data to migrate and is left untouched.
+func syncKVVersions(db versionedDB, versions []version) (bool, error) {
+	firstInit, err := isFirstInit(db.bdb())
+	if err != nil {
+		return false, err
+	}
+	if firstInit {
+		return false, nil
+	}
+
+	if err := syncVersions(db, versions); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// migrateTowers migrates all towers of the KV client DB and returns a map
+// from the KV tower IDs to the IDs assigned by the SQL database.
+func migrateTowers(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries) (map[TowerID]TowerID, error) {
+
+	towerIDs := make(map[TowerID]TowerID)
+
+	towers := kvTx.ReadBucket(cTowerBkt)
+	if towers == nil {
+		return towerIDs, nil
+	}
+
+	err := towers.ForEach(func(towerIDBytes, _ []byte) error {
+		tower, err := getTower(towers, towerIDBytes)
+		if err != nil {
+			return err
+		}
+
+		addrs, err := encodeTowerAddresses(tower.Addresses)
+		if err != nil {
+			return err
+		}
+
+		pubKey := tower.IdentityKey.SerializeCompressed()
+		id, err := tx.InsertWtClientTower(
+			ctx, sqlc.InsertWtClientTowerParams{
+				PubKey:    pubKey,
+				Addresses: addrs,
+				Status:    int16(tower.Status),
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to insert tower %v: %w",
+				tower, err)
+		}
+
+		towerIDs[tower.ID] = TowerID(id)
+
+		dbTower, err := tx.GetWtClientTowerByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		migrated, err := unmarshalTower(dbTower)
+		if err != nil {
+			return err
+		}
+
+		// Apart from its ID, the migrated tower must match the KV
+		// tower.
+		tower.ID = migrated.ID
+
+		return sqldb.CompareRecords(
+			tower, migrated, fmt.Sprintf("tower %v", tower),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return towerIDs, nil
+}
+
+// migrateSessionKeyIndexSequence migrates the sequence from which the session
+// key indexes are derived, so that no key index is ever handed out twice.
+func migrateSessionKeyIndexSequence(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries) error {
+
+	keyIndexes := kvTx.ReadBucket(cSessionKeyIndexBkt)
+	if keyIndexes == nil {
+		return nil
+	}
+
+	return tx.SetWtClientSessionKeyIndexSequence(
+		ctx, int64(keyIndexes.Sequence()),
+	)
+}
+
+// migrateChannels migrates all registered channels of the KV client DB and
+// returns a map from the KV channel DB IDs to the IDs assigned by the SQL
+// database.
+func migrateChannels(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries) (map[uint64]int64, error) {
+
+	chanIDs := make(map[uint64]int64)
+
+	chanDetailsBkt := kvTx.ReadBucket(cChanDetailsBkt)
+	if chanDetailsBkt == nil {
+		return chanIDs, nil
+	}
+
+	err := chanDetailsBkt.ForEach(func(chanIDBytes, _ []byte) error {
+		chanDetails := chanDetailsBkt.NestedReadBucket(chanIDBytes)
+		if chanDetails == nil {
+			return ErrCorruptChanDetails
+		}
+
+		var chanID lnwire.ChannelID
+		copy(chanID[:], chanIDBytes)
+
+		summary, err := getChanSummary(chanDetails)
+		if err != nil {
+			return err
+		}
+
+		dbChanID, err := readBigSize(chanDetails.Get(cChanDBID))
+		if err != nil {
+			return err
+		}
+
+		info := &ChannelInfo{
+			ClientChanSummary: *summary,
+		}
+
+		var maxCommitHeight sql.NullInt64
+		heightBytes := chanDetails.Get(cChanMaxCommitmentHeight)
+		if len(heightBytes) != 0 {
+			height, err := readBigSize(heightBytes)
+			if err != nil {
+				return err
+			}
+
+			info.MaxHeight = fn.Some(height)
+			maxCommitHeight = sqldb.SQLInt64(height)
+		}
+
+		var closedHeight sql.NullInt64
+		closedHeightBytes := chanDetails.Get(cChanClosedHeight)
+		if len(closedHeightBytes) == 4 {
+			closedHeight = sqldb.SQLInt64(
+				byteOrder.Uint32(closedHeightBytes),
+			)
+		}
+
+		sweepPkScript := summary.SweepPkScript
+		if sweepPkScript == nil {
+			sweepPkScript = []byte{}
+		}
+
+		id, err := tx.InsertWtClientChannel(
+			ctx, sqlc.InsertWtClientChannelParams{
+				ChannelID:       chanID[:],
+				SweepPkScript:   sweepPkScript,
+				ClosedHeight:    closedHeight,
+				MaxCommitHeight: maxCommitHeight,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to insert channel %v: %w",
+				chanID, err)
+		}
+
+		chanIDs[dbChanID] = id
+
+		dbChannel, err := tx.GetWtClientChannel(ctx, chanID[:])
+		if err != nil {
+			return err
+		}
+
+		migrated := &ChannelInfo{
+			ClientChanSummary: ClientChanSummary{
+				SweepPkScript: dbChannel.SweepPkScript,
+			},
+		}
+		if dbChannel.MaxCommitHeight.Valid {
+			migrated.MaxHeight = fn.Some(uint64(
+				dbChannel.MaxCommitHeight.Int64,
+			))
+		}
+
+		return sqldb.CompareRecords(
+			info, migrated, fmt.Sprintf("channel %v", chanID),
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chanIDs, nil
+}
+
+// migrateClientSessions migrates all sessions of the KV client DB along with
+// their committed updates and acked ranges. It returns the number of migrated
+// sessions.
+func migrateClientSessions(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries, towerIDs map[TowerID]TowerID,
+	chanIDs map[uint64]int64) (int, error) {
+
+	sessionsBkt := kvTx.ReadBucket(cSessionBkt)
+	if sessionsBkt == nil {
+		return 0, nil
+	}
+
+	closableSessions := kvTx.ReadBucket(cClosableSessionsBkt)
+
+	s := rate.Sometimes{
+		Interval: 30 * time.Second,
+	}
+
+	var (
+		t0    = time.Now()
+		chunk int
+		total int
+	)
+	err := sessionsBkt.ForEach(func(sessionIDBytes, _ []byte) error {
+		sessionBkt := sessionsBkt.NestedReadBucket(sessionIDBytes)
+		if sessionBkt == nil {
+			return ErrCorruptClientSession
+		}
+
+		session, err := getClientSessionBody(
+			sessionsBkt, sessionIDBytes,
+		)
+		if err != nil {
+			return err
+		}
+
+		towerID, ok := towerIDs[session.TowerID]
+		if !ok {
+			return fmt.Errorf("tower %d of session %s not found",
+				session.TowerID, session.ID)
+		}
+		session.TowerID = towerID
+
+		dbIDBytes := sessionBkt.Get(cSessionDBID)
+
+		var rogueCount uint64
+		rogueCountBytes := sessionBkt.Get(cSessionRogueUpdateCount)
+		if len(rogueCountBytes) != 0 {
+			rogueCount, err = readBigSize(rogueCountBytes)
+			if err != nil {
+				return err
+			}
+		}
+
+		var closableHeight sql.NullInt64
+		if closableSessions != nil && dbIDBytes != nil {
+			heightBytes := closableSessions.Get(dbIDBytes)
+			if len(heightBytes) == 4 {
+				closableHeight = sqldb.SQLInt64(
+					byteOrder.Uint32(heightBytes),
+				)
+			}
+		}
+
+		policy := session.Policy
+		id, err := tx.InsertWtClientSession(
+			ctx, sqlc.InsertWtClientSessionParams{
+				SessionID:      session.ID[:],
+				TowerID:        int64(session.TowerID),
+				KeyIndex:       int64(session.KeyIndex),
+				BlobType:       int32(policy.BlobType),
+				MaxUpdates:     int32(policy.MaxUpdates),
+				RewardBase:     int64(policy.RewardBase),
+				RewardRate:     int64(policy.RewardRate),
+				SweepFeeRate:   int64(policy.SweepFeeRate),
+				RewardPkScript: session.RewardPkScript,
+				SeqNum:         int32(session.SeqNum),
+				TowerLastApplied: int32(
+					session.TowerLastApplied,
+				),
+				Status:           int16(session.Status),
+				RogueUpdateCount: int64(rogueCount),
+				ClosableHeight:   closableHeight,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to insert session %s: %w",
+				session.ID, err)
+		}
+
+		err = migrateCommittedUpdates(ctx, sessionBkt, tx, id)
+		if err != nil {
+			return err
+		}
+
+		err = migrateAckedRanges(ctx, sessionBkt, tx, id, chanIDs)
+		if err != nil {
+			return err
+		}
+
+		dbSession, err := tx.GetWtClientSession(ctx, session.ID[:])
+		if err != nil {
+			return err
+		}
+
+		err = sqldb.CompareRecords(
+			session, unmarshalClientSession(dbSession),
+			fmt.Sprintf("session %s", session.ID),
+		)
+		if err != nil {
+			return err
+		}
+
+		total++
+		chunk++
+
+		s.Do(func() {
+			elapsed := time.Since(t0).Seconds()
+			ratePerSec := float64(chunk) / elapsed
+			log.Debugf("Migrated %d watchtower client sessions "+
+				"(%.2f sessions/sec)", total, ratePerSec)
+
+			t0 = time.Now()
+			chunk = 0
+		})
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// migrateCommittedUpdates migrates the committed updates of the given KV
+// session to the session with the given SQL ID.
+func migrateCommittedUpdates(ctx context.Context, sessionBkt kvdb.RBucket,
+	tx *sqlc.Queries, sessionID int64) error {
+
+	commitsBkt := sessionBkt.NestedReadBucket(cSessionCommits)
+	if commitsBkt == nil {
+		return nil
+	}
+
+	return commitsBkt.ForEach(func(k, v []byte) error {
+		var update CommittedUpdate
+		if err := update.Decode(bytes.NewReader(v)); err != nil {
+			return err
+		}
+		update.SeqNum = byteOrder.Uint16(k)
+
+		backupID := update.BackupID
+		err := tx.InsertWtClientCommittedUpdate(
+			ctx, sqlc.InsertWtClientCommittedUpdateParams{
+				SessionID:     sessionID,
+				SeqNum:        int32(update.SeqNum),
+				ChannelID:     backupID.ChanID[:],
+				CommitHeight:  int64(backupID.CommitHeight),
+				Hint:          update.Hint[:],
+				EncryptedBlob: update.EncryptedBlob,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		dbUpdate, err := tx.GetWtClientCommittedUpdate(
+			ctx, sqlc.GetWtClientCommittedUpdateParams{
+				SessionID: sessionID,
+				SeqNum:    int32(update.SeqNum),
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		return sqldb.CompareRecords(
+			update, unmarshalCommittedUpdate(dbUpdate),
+			fmt.Sprintf("committed update %d", update.SeqNum),
+		)
+	})
+}
+
+// migrateAckedRanges migrates the acked ranges of the given KV session to the
+// session with the given SQL ID.
+func migrateAckedRanges(ctx context.Context, sessionBkt kvdb.RBucket,
+	tx *sqlc.Queries, sessionID int64, chanIDs map[uint64]int64) error {
+
+	rangeIndexBkt := sessionBkt.NestedReadBucket(cSessionAckRangeIndex)
+	if rangeIndexBkt == nil {
+		return nil
+	}
+
+	return rangeIndexBkt.ForEach(func(dbChanIDBytes, _ []byte) error {
+		rangesBkt := rangeIndexBkt.NestedReadBucket(dbChanIDBytes)
+		if rangesBkt == nil {
+			return nil
+		}
+
+		dbChanID, err := readBigSize(dbChanIDBytes)
+		if err != nil {
+			return err
+		}
+
+		// A channel is only deleted once no session has acked ranges
+		// for it anymore, so it must have been migrated.
+		channelID, ok := chanIDs[dbChanID]
+		if !ok {
+			return fmt.Errorf("channel with db ID %d not found",
+				dbChanID)
+		}
+
+		index, err := readRangeIndex(rangesBkt)
+		if err != nil {
+			return err
+		}
+
+		for start, end := range index.GetAllRanges() {
+			err := tx.UpsertWtClientAckedRange(
+				ctx, sqlc.UpsertWtClientAckedRangeParams{
+					SessionID:  sessionID,
+					ChannelID:  channelID,
+					RangeStart: int64(start),
+					RangeEnd:   int64(end),
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		dbRanges, err := tx.ListWtClientAckedRanges(
+			ctx, sqlc.ListWtClientAckedRangesParams{
+				SessionID: sessionID,
+				ChannelID: channelID,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		migrated := make(map[uint64]uint64, len(dbRanges))
+		for _, r := range dbRanges {
+			migrated[uint64(r.RangeStart)] = uint64(r.RangeEnd)
+		}
+
+		return sqldb.CompareRecords(
+			index.GetAllRanges(), migrated,
+			fmt.Sprintf("acked ranges of channel %d", dbChanID),
+		)
+	})
+}
+
+// migrateBackupQueues migrates the backup queues of the KV client DB. Every
+// queue lives in its own top-level bucket, which is identified by the task
+// queue sub-bucket. The items are stored in the order in which they would
+// have been popped from the KV queue. It returns the number of migrated items.
+func migrateBackupQueues(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries) (int, error) {
+
+	var total int
+	err := kvTx.ForEachBucket(func(namespace []byte) error {
+		namespacedBkt := kvTx.ReadBucket(namespace)
+		if namespacedBkt == nil {
+			return nil
+		}
+
+		tasksBkt := namespacedBkt.NestedReadBucket(cTaskQueue)
+		if tasksBkt == nil {
+			return nil
+		}
+
+		// Items pushed to the head of the KV queue are popped before
+		// the items of the main queue.
+		var items []*BackupID
+		for _, queueName := range [][]byte{queueHeadBkt, queueMainBkt} {
+			queueItems, err := readQueueItems(tasksBkt, queueName)
+			if err != nil {
+				return err
+			}
+
+			items = append(items, queueItems...)
+		}
+
+		for i, item := range items {
+			err := tx.InsertWtClientBackupQueueItem(
+				ctx, sqlc.InsertWtClientBackupQueueItemParams{
+					Namespace:    namespace,
+					QueueIndex:   int64(i),
+					ChannelID:    item.ChanID[:],
+					CommitHeight: int64(item.CommitHeight),
+				},
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		total += len(items)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// readQueueItems reads the items of the given KV queue in the order in which
+// they would be popped.
+func readQueueItems(tasksBkt kvdb.RBucket, queueName []byte) ([]*BackupID,
+	error) {
+
+	queueBkt := tasksBkt.NestedReadBucket(queueName)
+	if queueBkt == nil {
+		return nil, nil
+	}
+
+	readIndex := func(key []byte) (uint64, error) {
+		indexBytes := queueBkt.Get(key)
+		if indexBytes == nil {
+			return 0, nil
+		}
+
+		return readBigSize(indexBytes)
+	}
+
+	oldestIndex, err := readIndex(oldestIndexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nextIndex, err := readIndex(nextIndexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsBucket := queueBkt.NestedReadBucket(itemsBkt)
+	if itemsBucket == nil {
+		return nil, nil
+	}
+
+	// The item keys are BigSize encoded, so they are not sorted by their
+	// index and must be looked up one by one.
+	var items []*BackupID
+	for index := oldestIndex; index < nextIndex; index++ {
+		indexBytes, err := writeBigSize(index)
+		if err != nil {
+			return nil, err
+		}
+
+		itemBytes := itemsBucket.Get(indexBytes)
+		if itemBytes == nil {
+			return nil, fmt.Errorf("no queue item found under "+
+				"index %d", index)
+		}
+
+		var item BackupID
+		if err := item.Decode(bytes.NewReader(itemBytes)); err != nil {
+			return nil, err
+		}
+
+		items = append(items, &item)
+	}
+
+	return items, nil
+}
+
+// MigrateTowerDBToSQL migrates the sessions, state updates and lookout tip of
+// the KV watchtower database to the native SQL database. Every migrated
+// session and state update is read back and compared with its KV counterpart.
+func MigrateTowerDBToSQL(ctx context.Context, kvBackend kvdb.Backend,
+	tx *sqlc.Queries) error {
+
+	if kvBackend == nil {
+		return fmt.Errorf("tower DB: %w", ErrMissingKVBackend)
+	}
+
+	// The same as for the client DB, the KV database may still be on an
+	// older version if the tower wasn't active since the last upgrade.
+	migrate, err := syncKVVersions(&TowerDB{db: kvBackend}, towerDBVersions)
+	if err != nil {
+		return err
+	}
+	if !migrate {
+		log.Infof("Watchtower DB was never initialized, nothing to " +
+			"migrate to SQL")
+
+		return nil
+	}
+
+	log.Infof("Starting migration of the watchtower DB from KV to SQL")
+
+	var numSessions, numUpdates int
+	err = kvdb.View(kvBackend, func(kvTx kvdb.RTx) error {
+		var err error
+		numSessions, err = migrateTowerSessions(ctx, kvTx, tx)
+		if err != nil {
+			return err
+		}
+
+		numUpdates, err = migrateStateUpdates(ctx, kvTx, tx)
+		if err != nil {
+			return err
+		}
+
+		lookoutTip := kvTx.ReadBucket(lookoutTipBkt)
+		if lookoutTip == nil {
+			return nil
+		}
+
+		epoch := getLookoutEpoch(lookoutTip)
+		if epoch == nil {
+			return nil
+		}
+
+		return tx.UpsertWtServerLookoutTip(
+			ctx, sqlc.UpsertWtServerLookoutTipParams{
+				BlockHash:   epoch.Hash[:],
+				BlockHeight: epoch.Height,
+			},
+		)
+	}, func() {
+		numSessions, numUpdates = 0, 0
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Migration of the watchtower DB from KV to SQL completed: "+
+		"%d sessions and %d state updates migrated", numSessions,
+		numUpdates)
+
+	return nil
+}
+
+// migrateTowerSessions migrates all sessions of the KV tower DB and returns
+// the number of migrated sessions.
+func migrateTowerSessions(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries) (int, error) {
+
+	sessions := kvTx.ReadBucket(sessionsBkt)
+	if sessions == nil {
+		return 0, nil
+	}
+
+	var total int
+	err := sessions.ForEach(func(k, _ []byte) error {
+		session, err := getSession(sessions, k)
+		if err != nil {
+			return err
+		}
+
+		policy := session.Policy
+		err = tx.UpsertWtServerSession(
+			ctx, sqlc.UpsertWtServerSessionParams{
+				SessionID:    session.ID[:],
+				BlobType:     int32(policy.BlobType),
+				MaxUpdates:   int32(policy.MaxUpdates),
+				RewardBase:   int64(policy.RewardBase),
+				RewardRate:   int64(policy.RewardRate),
+				SweepFeeRate: int64(policy.SweepFeeRate),
+				LastApplied:  int32(session.LastApplied),
+				ClientLastApplied: int32(
+					session.ClientLastApplied,
+				),
+				RewardAddress: session.RewardAddress,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to insert session %s: %w",
+				session.ID, err)
+		}
+
+		dbSession, err := tx.GetWtServerSession(ctx, session.ID[:])
+		if err != nil {
+			return err
+		}
+
+		total++
+
+		return sqldb.CompareRecords(
+			session, unmarshalSessionInfo(dbSession),
+			fmt.Sprintf("session %s", session.ID),
+		)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// migrateStateUpdates migrates all state updates of the KV tower DB and
+// returns the number of migrated updates. Updates of sessions that no longer
+// exist are skipped, just like they are ignored when querying for matches.
+func migrateStateUpdates(ctx context.Context, kvTx kvdb.RTx,
+	tx *sqlc.Queries) (int, error) {
+
+	updates := kvTx.ReadBucket(updatesBkt)
+	if updates == nil {
+		return 0, nil
+	}
+
+	s := rate.Sometimes{
+		Interval: 30 * time.Second,
+	}
+
+	var (
+		t0    = time.Now()
+		chunk int
+		total int
+	)
+	err := updates.ForEach(func(hint, _ []byte) error {
+		updatesForHint := updates.NestedReadBucket(hint)
+		if updatesForHint == nil {
+			return nil
+		}
+
+		return updatesForHint.ForEach(func(k, v []byte) error {
+			dbSession, err := tx.GetWtServerSession(ctx, k)
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Warnf("Skipping state update with hint=%x "+
+					"of missing session=%x", hint, k)
+
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			var update SessionStateUpdate
+			err = update.Decode(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+
+			params := sqlc.UpsertWtServerStateUpdateParams{
+				SessionID:     dbSession.ID,
+				Hint:          update.Hint[:],
+				SeqNum:        int32(update.SeqNum),
+				LastApplied:   int32(update.LastApplied),
+				EncryptedBlob: update.EncryptedBlob,
+			}
+			err = tx.UpsertWtServerStateUpdate(ctx, params)
+			if err != nil {
+				return err
+			}
+
+			total++
+			chunk++
+
+			s.Do(func() {
+				elapsed := time.Since(t0).Seconds()
+				ratePerSec := float64(chunk) / elapsed
+				log.Debugf("Migrated %d watchtower state "+
+					"updates (%.2f updates/sec)", total,
+					ratePerSec)
+
+				t0 = time.Now()
+				chunk = 0
+			})
+
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
@@ -0,0 +1,391 @@
+package wtdb_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/lnwire"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"github.com/flokiorg/flnd/watchtower/blob"
+	"github.com/flokiorg/flnd/watchtower/wtclient"
+	"github.com/flokiorg/flnd/watchtower/wtdb"
+	"github.com/flokiorg/flnd/watchtower/wtpolicy"
+	"github.com/stretchr/testify/require"
+)
+
+// clientDBState holds everything that can be read from a client DB through
+// its public interface.
+type clientDBState struct {
+	towers           []*wtdb.Tower
+	sessions         map[wtdb.SessionID]*wtdb.ClientSession
+	committedUpdates map[wtdb.SessionID][]*wtdb.CommittedUpdate
+	maxHeights       map[wtdb.SessionID]map[lnwire.ChannelID]uint64
+	numAcked         map[wtdb.SessionID]map[lnwire.ChannelID]uint16
+	chanInfos        wtdb.ChannelInfos
+	closableSessions map[wtdb.SessionID]uint32
+	queue            []*wtdb.BackupID
+}
+
+// readClientDBState reads the state of the given client DB. The backup queue
+// with the given namespace is drained in the process.
+func readClientDBState(t *testing.T, db wtclient.DB,
+	namespace []byte) *clientDBState {
+
+	t.Helper()
+
+	state := &clientDBState{
+		committedUpdates: make(
+			map[wtdb.SessionID][]*wtdb.CommittedUpdate,
+		),
+		maxHeights: make(
+			map[wtdb.SessionID]map[lnwire.ChannelID]uint64,
+		),
+		numAcked: make(
+			map[wtdb.SessionID]map[lnwire.ChannelID]uint16,
+		),
+	}
+
+	var err error
+	state.towers, err = db.ListTowers(nil)
+	require.NoError(t, err)
+
+	state.sessions, err = db.ListClientSessions(
+		nil,
+		wtdb.WithPerCommittedUpdate(func(s *wtdb.ClientSession,
+			u *wtdb.CommittedUpdate) {
+
+			state.committedUpdates[s.ID] = append(
+				state.committedUpdates[s.ID], u,
+			)
+		}),
+		wtdb.WithPerMaxHeight(func(s *wtdb.ClientSession,
+			chanID lnwire.ChannelID, height uint64) {
+
+			if state.maxHeights[s.ID] == nil {
+				state.maxHeights[s.ID] = make(
+					map[lnwire.ChannelID]uint64,
+				)
+			}
+			state.maxHeights[s.ID][chanID] = height
+		}),
+		wtdb.WithPerNumAckedUpdates(func(s *wtdb.ClientSession,
+			chanID lnwire.ChannelID, num uint16) {
+
+			if state.numAcked[s.ID] == nil {
+				state.numAcked[s.ID] = make(
+					map[lnwire.ChannelID]uint16,
+				)
+			}
+			state.numAcked[s.ID][chanID] = num
+		}),
+	)
+	require.NoError(t, err)
+
+	state.chanInfos, err = db.FetchChanInfos()
+	require.NoError(t, err)
+
+	state.closableSessions, err = db.ListClosableSessions()
+	require.NoError(t, err)
+
+	queue := db.GetDBQueue(namespace)
+	for {
+		items, err := queue.PopUpTo(10)
+		if err == wtdb.ErrEmptyQueue {
+			break
+		}
+		require.NoError(t, err)
+
+		state.queue = append(state.queue, items...)
+	}
+
+	return state
+}
+
+// TestMigrateClientDBToSQL asserts that the KV client DB is migrated to the
+// SQL client DB without any observable change.
+func TestMigrateClientDBToSQL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dbCfg := &kvdb.BoltConfig{DBTimeout: kvdb.DefaultDBTimeout}
+	bdb, err := wtdb.NewBoltBackendCreator(
+		true, t.TempDir(), "wtclient.db",
+	)(dbCfg)
+	require.NoError(t, err)
+
+	kvDB, err := wtdb.OpenClientDB(bdb)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, kvDB.Close())
+	})
+
+	h := &clientDBHarness{t: t, db: kvDB}
+
+	// Create two towers and deactivate the second one.
+	tower1 := h.newTower()
+	tower2 := h.newTower()
+	h.deactivateTower(tower2.IdentityKey, nil)
+
+	// Register two channels.
+	chan1, chan2 := randChannelID(t), randChannelID(t)
+	h.registerChan(chan1, []byte{0x01}, nil)
+	h.registerChan(chan2, []byte{0x02}, nil)
+
+	// The first session has an acked update for the first channel and a
+	// committed update for the second one.
+	session1 := h.randSession(t, tower1.ID, 2)
+	h.insertSession(session1, nil)
+
+	update := randCommittedUpdateForChanWithHeight(t, chan1, 1, 1)
+	h.commitUpdate(&session1.ID, update, nil)
+	h.ackUpdate(&session1.ID, 1, 1, nil)
+
+	update = randCommittedUpdateForChanWithHeight(t, chan2, 2, 1)
+	h.commitUpdate(&session1.ID, update, nil)
+
+	// The second session is exhausted by an acked update for the first
+	// channel.
+	session2 := h.randSession(t, tower2.ID, 1)
+	h.insertSession(session2, nil)
+
+	update = randCommittedUpdateForChanWithHeight(t, chan1, 1, 2)
+	h.commitUpdate(&session2.ID, update, nil)
+	h.ackUpdate(&session2.ID, 1, 1, nil)
+
+	// Closing the first channel makes the second session closable.
+	closable := h.markChannelClosed(chan1, 100, nil)
+	require.Equal(t, []wtdb.SessionID{session2.ID}, closable)
+
+	// Queue a few backups, one of them at the head of the queue.
+	namespace := []byte("test-namespace")
+	kvQueue := kvDB.GetDBQueue(namespace)
+	require.NoError(t, kvQueue.Push(
+		&wtdb.BackupID{ChanID: chan2, CommitHeight: 2},
+		&wtdb.BackupID{ChanID: chan2, CommitHeight: 3},
+	))
+	require.NoError(t, kvQueue.PushHead(
+		&wtdb.BackupID{ChanID: chan2, CommitHeight: 4},
+	))
+
+	// Migrate the KV client DB to a fresh SQL database.
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+	genericExecutor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) *sqlc.Queries {
+			return db.WithTx(tx)
+		},
+	)
+	err = genericExecutor.ExecTx(
+		ctx, sqldb.WriteTxOpt(), func(tx *sqlc.Queries) error {
+			return wtdb.MigrateClientDBToSQL(ctx, bdb, tx)
+		}, sqldb.NoOpReset,
+	)
+	require.NoError(t, err)
+
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) wtdb.SQLClientDBQueries {
+			return db.WithTx(tx)
+		},
+	)
+	sqlDB := wtdb.NewSQLClientDB(executor)
+
+	// Both databases should now be indistinguishable.
+	kvState := readClientDBState(t, kvDB, namespace)
+	sqlState := readClientDBState(t, sqlDB, namespace)
+	require.Equal(t, kvState, sqlState)
+	require.Len(t, sqlState.queue, 3)
+
+	for _, id := range []wtdb.SessionID{session1.ID, session2.ID} {
+		kvNum, err := kvDB.NumAckedUpdates(&id)
+		require.NoError(t, err)
+
+		sqlNum, err := sqlDB.NumAckedUpdates(&id)
+		require.NoError(t, err)
+		require.Equal(t, kvNum, sqlNum)
+	}
+
+	// The session key index sequence must be carried over so that no
+	// index is handed out twice.
+	index, err := sqlDB.NextSessionKeyIndex(
+		tower1.ID, blobType, false,
+	)
+	require.NoError(t, err)
+	require.Greater(t, index, session2.KeyIndex)
+}
+
+// migrateClientDB migrates the client DB behind the given KV backend to the
+// given SQL database.
+func migrateClientDB(kvBackend kvdb.Backend, db *sqldb.BaseDB) error {
+
+	ctx := context.Background()
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) *sqlc.Queries {
+			return db.WithTx(tx)
+		},
+	)
+
+	return executor.ExecTx(
+		ctx, sqldb.WriteTxOpt(), func(tx *sqlc.Queries) error {
+			return wtdb.MigrateClientDBToSQL(ctx, kvBackend, tx)
+		}, sqldb.NoOpReset,
+	)
+}
+
+// TestMigrateInactiveClientDBToSQL asserts that the data of a client that is
+// inactive when the SQL migration runs is migrated all the same, so that it is
+// found once the client is activated later on, and that the migration refuses
+// to succeed without the KV backend.
+func TestMigrateInactiveClientDBToSQL(t *testing.T) {
+	t.Parallel()
+
+	dbCfg := &kvdb.BoltConfig{DBTimeout: kvdb.DefaultDBTimeout}
+	dbDir := t.TempDir()
+	newBackend := func() kvdb.Backend {
+		bdb, err := wtdb.NewBoltBackendCreator(
+			true, dbDir, "wtclient.db",
+		)(dbCfg)
+		require.NoError(t, err)
+
+		return bdb
+	}
+
+	// Without the KV backend, the migration must fail rather than being
+	// recorded as applied.
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+	err := migrateClientDB(nil, db)
+	require.ErrorIs(t, err, wtdb.ErrMissingKVBackend)
+
+	// A client DB that was never initialized has nothing to migrate.
+	bdb := newBackend()
+	require.NoError(t, migrateClientDB(bdb, db))
+	require.NoError(t, bdb.Close())
+
+	// Back when the client was active, it created a tower.
+	kvDB, err := wtdb.OpenClientDB(newBackend())
+	require.NoError(t, err)
+
+	h := &clientDBHarness{t: t, db: kvDB}
+	tower := h.newTower()
+	require.NoError(t, kvDB.Close())
+
+	// The client is inactive on the first start with native SQL, so its
+	// KV backend is opened but not the client DB itself.
+	bdb = newBackend()
+	t.Cleanup(func() {
+		require.NoError(t, bdb.Close())
+	})
+
+	db = sqldb.NewTestSqliteDB(t).BaseDB
+	require.NoError(t, migrateClientDB(bdb, db))
+
+	// Once the client is activated, it finds its tower in the SQL DB.
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) wtdb.SQLClientDBQueries {
+			return db.WithTx(tx)
+		},
+	)
+	sqlDB := wtdb.NewSQLClientDB(executor)
+
+	towers, err := sqlDB.ListTowers(nil)
+	require.NoError(t, err)
+	require.Len(t, towers, 1)
+	require.Equal(t, tower.IdentityKey, towers[0].IdentityKey)
+	require.Equal(t, tower.Addresses, towers[0].Addresses)
+}
+
+// TestMigrateTowerDBToSQL asserts that the KV tower DB is migrated to the SQL
+// tower DB without any observable change.
+func TestMigrateTowerDBToSQL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dbCfg := &kvdb.BoltConfig{DBTimeout: kvdb.DefaultDBTimeout}
+	bdb, err := wtdb.NewBoltBackendCreator(
+		true, t.TempDir(), "watchtower.db",
+	)(dbCfg)
+	require.NoError(t, err)
+
+	kvDB, err := wtdb.OpenTowerDB(bdb)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, kvDB.Close())
+	})
+
+	// Insert two sessions that share a breach hint.
+	session1 := &wtdb.SessionInfo{
+		ID: *id(0),
+		Policy: wtpolicy.Policy{
+			TxPolicy: wtpolicy.TxPolicy{
+				BlobType:     blob.TypeAltruistCommit,
+				SweepFeeRate: wtpolicy.DefaultSweepFeeRate,
+			},
+			MaxUpdates: 10,
+		},
+		RewardAddress: []byte{},
+	}
+	session2 := *session1
+	session2.ID = *id(1)
+
+	require.NoError(t, kvDB.InsertSessionInfo(session1))
+	require.NoError(t, kvDB.InsertSessionInfo(&session2))
+
+	var hint blob.BreachHint
+	for _, session := range []*wtdb.SessionInfo{session1, &session2} {
+		_, err := kvDB.InsertStateUpdate(&wtdb.SessionStateUpdate{
+			ID:            session.ID,
+			SeqNum:        1,
+			Hint:          hint,
+			EncryptedBlob: testBlob,
+		})
+		require.NoError(t, err)
+	}
+
+	epoch := epochFromInt(100)
+	require.NoError(t, kvDB.SetLookoutTip(epoch))
+
+	// Migrate the KV tower DB to a fresh SQL database.
+	db := sqldb.NewTestSqliteDB(t).BaseDB
+	genericExecutor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) *sqlc.Queries {
+			return db.WithTx(tx)
+		},
+	)
+	err = genericExecutor.ExecTx(
+		ctx, sqldb.WriteTxOpt(), func(tx *sqlc.Queries) error {
+			return wtdb.MigrateTowerDBToSQL(ctx, bdb, tx)
+		}, sqldb.NoOpReset,
+	)
+	require.NoError(t, err)
+
+	executor := sqldb.NewTransactionExecutor(
+		db, func(tx *sql.Tx) wtdb.SQLTowerDBQueries {
+			return db.WithTx(tx)
+		},
+	)
+	sqlDB := wtdb.NewSQLTowerDB(executor)
+
+	for _, session := range []*wtdb.SessionInfo{session1, &session2} {
+		kvSession, err := kvDB.GetSessionInfo(&session.ID)
+		require.NoError(t, err)
+
+		sqlSession, err := sqlDB.GetSessionInfo(&session.ID)
+		require.NoError(t, err)
+		require.Equal(t, kvSession, sqlSession)
+	}
+
+	kvMatches, err := kvDB.QueryMatches([]blob.BreachHint{hint})
+	require.NoError(t, err)
+	require.Len(t, kvMatches, 2)
+
+	sqlMatches, err := sqlDB.QueryMatches([]blob.BreachHint{hint})
+	require.NoError(t, err)
+	require.Equal(t, kvMatches, sqlMatches)
+
+	sqlEpoch, err := sqlDB.GetLookoutTip()
+	require.NoError(t, err)
+	require.Equal(t, epoch, sqlEpoch)
+}
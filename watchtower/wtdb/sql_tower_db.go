@@ -0,0 +1,391 @@
+package wtdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
+
+	"github.com/flokiorg/flnd/chainntnfs"
+	"github.com/flokiorg/flnd/lnwallet/chainfee"
+	"github.com/flokiorg/flnd/sqldb"
+	"github.com/flokiorg/flnd/sqldb/sqlc"
+	"github.com/flokiorg/flnd/watchtower/blob"
+	"github.com/flokiorg/flnd/watchtower/wtpolicy"
+)
+
+// SQLTowerDBQueries is an interface that defines the set of operations that
+// can be executed against the watchtower SQL database.
+type SQLTowerDBQueries interface {
+	UpsertWtServerSession(ctx context.Context,
+		arg sqlc.UpsertWtServerSessionParams) error
+
+	GetWtServerSession(ctx context.Context, sessionID []byte) (
+		sqlc.WtserverSession, error)
+
+	UpdateWtServerSessionLastApplied(ctx context.Context,
+		arg sqlc.UpdateWtServerSessionLastAppliedParams) error
+
+	DeleteWtServerSession(ctx context.Context, id int64) error
+
+	UpsertWtServerStateUpdate(ctx context.Context,
+		arg sqlc.UpsertWtServerStateUpdateParams) error
+
+	ListWtServerStateUpdatesByHint(ctx context.Context, hint []byte) (
+		[]sqlc.ListWtServerStateUpdatesByHintRow, error)
+
+	UpsertWtServerLookoutTip(ctx context.Context,
+		arg sqlc.UpsertWtServerLookoutTipParams) error
+
+	GetWtServerLookoutTip(ctx context.Context) (
+		sqlc.GetWtServerLookoutTipRow, error)
+}
+
+// BatchedSQLTowerDBQueries is a version of the SQLTowerDBQueries that's
+// capable of batched database operations.
+type BatchedSQLTowerDBQueries interface {
+	SQLTowerDBQueries
+
+	sqldb.BatchedTx[SQLTowerDBQueries]
+}
+
+// SQLTowerDB implements the watchtower database on top of a native SQL
+// database.
+type SQLTowerDB struct {
+	db BatchedSQLTowerDBQueries
+}
+
+// NewSQLTowerDB creates a new SQLTowerDB given an open
+// BatchedSQLTowerDBQueries storage backend.
+func NewSQLTowerDB(db BatchedSQLTowerDBQueries) *SQLTowerDB {
+	return &SQLTowerDB{
+		db: db,
+	}
+}
+
+// GetSessionInfo retrieves the session for the passed session id. An error is
+// returned if the session could not be found.
+func (t *SQLTowerDB) GetSessionInfo(id *SessionID) (*SessionInfo, error) {
+	ctx := context.TODO()
+
+	var session *SessionInfo
+	err := t.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLTowerDBQueries) error {
+			dbSession, err := db.GetWtServerSession(ctx, id[:])
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrSessionNotFound
+			} else if err != nil {
+				return err
+			}
+
+			session = unmarshalSessionInfo(dbSession)
+
+			return nil
+		}, func() {
+			session = nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// InsertSessionInfo records a negotiated session in the tower database. An
+// error is returned if the session already exists.
+func (t *SQLTowerDB) InsertSessionInfo(session *SessionInfo) error {
+	ctx := context.TODO()
+
+	return t.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLTowerDBQueries) error {
+			dbSession, err := db.GetWtServerSession(
+				ctx, session.ID[:],
+			)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				// proceed.
+
+			case err != nil:
+				return err
+
+			case dbSession.LastApplied > 0:
+				return ErrSessionAlreadyExists
+			}
+
+			// Perform a quick sanity check on the session policy
+			// before accepting.
+			if err := session.Policy.Validate(); err != nil {
+				return err
+			}
+
+			policy := session.Policy
+			params := sqlc.UpsertWtServerSessionParams{
+				SessionID:    session.ID[:],
+				BlobType:     int32(policy.BlobType),
+				MaxUpdates:   int32(policy.MaxUpdates),
+				RewardBase:   int64(policy.RewardBase),
+				RewardRate:   int64(policy.RewardRate),
+				SweepFeeRate: int64(policy.SweepFeeRate),
+				LastApplied:  int32(session.LastApplied),
+				ClientLastApplied: int32(
+					session.ClientLastApplied,
+				),
+				RewardAddress: session.RewardAddress,
+			}
+
+			return db.UpsertWtServerSession(ctx, params)
+		}, sqldb.NoOpReset,
+	)
+}
+
+// InsertStateUpdate stores an update sent by the client after validating that
+// the update is well-formed in the context of other updates sent for the same
+// session. This include verifying that the sequence number is incremented
+// properly and the last applied values echoed by the client are sane.
+func (t *SQLTowerDB) InsertStateUpdate(update *SessionStateUpdate) (uint16,
+	error) {
+
+	ctx := context.TODO()
+
+	var lastApplied uint16
+	err := t.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLTowerDBQueries) error {
+			// Fetch the session corresponding to the update's
+			// session id. This will be used to validate that the
+			// update's sequence number and last applied values are
+			// sane.
+			dbSession, err := db.GetWtServerSession(
+				ctx, update.ID[:],
+			)
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrSessionNotFound
+			} else if err != nil {
+				return err
+			}
+
+			session := unmarshalSessionInfo(dbSession)
+
+			commitType, err := session.Policy.BlobType.
+				CommitmentType(nil)
+			if err != nil {
+				return err
+			}
+
+			kit, err := commitType.EmptyJusticeKit()
+			if err != nil {
+				return err
+			}
+
+			// Assert that the blob is the correct size for the
+			// session's blob type.
+			if len(update.EncryptedBlob) != blob.Size(kit) {
+				return ErrInvalidBlobSize
+			}
+
+			// Validate the update against the current state of the
+			// session.
+			err = session.AcceptUpdateSequence(
+				update.SeqNum, update.LastApplied,
+			)
+			if err != nil {
+				return err
+			}
+
+			// Validation succeeded, therefore the update is
+			// committed and the session's last applied value is
+			// equal to the update's sequence number.
+			lastApplied = session.LastApplied
+
+			//nolint:ll
+			sessionParams := sqlc.UpdateWtServerSessionLastAppliedParams{
+				ID:          dbSession.ID,
+				LastApplied: int32(session.LastApplied),
+				ClientLastApplied: int32(
+					session.ClientLastApplied,
+				),
+			}
+			err = db.UpdateWtServerSessionLastApplied(
+				ctx, sessionParams,
+			)
+			if err != nil {
+				return err
+			}
+
+			updateParams := sqlc.UpsertWtServerStateUpdateParams{
+				SessionID:     dbSession.ID,
+				Hint:          update.Hint[:],
+				SeqNum:        int32(update.SeqNum),
+				LastApplied:   int32(update.LastApplied),
+				EncryptedBlob: update.EncryptedBlob,
+			}
+
+			return db.UpsertWtServerStateUpdate(ctx, updateParams)
+		}, func() {
+			lastApplied = 0
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return lastApplied, nil
+}
+
+// DeleteSession removes all data associated with a particular session id from
+// the tower's database.
+func (t *SQLTowerDB) DeleteSession(target SessionID) error {
+	ctx := context.TODO()
+
+	return t.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLTowerDBQueries) error {
+			// Fail if the session doesn't exit.
+			dbSession, err := db.GetWtServerSession(ctx, target[:])
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrSessionNotFound
+			} else if err != nil {
+				return err
+			}
+
+			// Deleting the session also deletes all of its state
+			// updates.
+			return db.DeleteWtServerSession(ctx, dbSession.ID)
+		}, sqldb.NoOpReset,
+	)
+}
+
+// QueryMatches searches against all known state updates for any that match the
+// passed breachHints. More than one Match will be returned for a given hint if
+// they exist in the database.
+func (t *SQLTowerDB) QueryMatches(breachHints []blob.BreachHint) ([]Match,
+	error) {
+
+	ctx := context.TODO()
+
+	var matches []Match
+	err := t.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLTowerDBQueries) error {
+			for _, hint := range breachHints {
+				rows, err := db.ListWtServerStateUpdatesByHint(
+					ctx, hint[:],
+				)
+				if err != nil {
+					return err
+				}
+
+				for _, row := range rows {
+					match := unmarshalMatch(hint, row)
+					matches = append(matches, match)
+				}
+			}
+
+			return nil
+		}, func() {
+			matches = nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// SetLookoutTip stores the provided epoch as the latest lookout tip epoch in
+// the tower database.
+func (t *SQLTowerDB) SetLookoutTip(epoch *chainntnfs.BlockEpoch) error {
+	ctx := context.TODO()
+
+	return t.db.ExecTx(ctx, sqldb.WriteTxOpt(),
+		func(db SQLTowerDBQueries) error {
+			return db.UpsertWtServerLookoutTip(
+				ctx, sqlc.UpsertWtServerLookoutTipParams{
+					BlockHash:   epoch.Hash[:],
+					BlockHeight: epoch.Height,
+				},
+			)
+		}, sqldb.NoOpReset,
+	)
+}
+
+// GetLookoutTip retrieves the current lookout tip block epoch from the tower
+// database. A nil epoch is returned if no tip has been set yet.
+func (t *SQLTowerDB) GetLookoutTip() (*chainntnfs.BlockEpoch, error) {
+	ctx := context.TODO()
+
+	var epoch *chainntnfs.BlockEpoch
+	err := t.db.ExecTx(ctx, sqldb.ReadTxOpt(),
+		func(db SQLTowerDBQueries) error {
+			tip, err := db.GetWtServerLookoutTip(ctx)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			hash, err := chainhash.NewHash(tip.BlockHash)
+			if err != nil {
+				return err
+			}
+
+			epoch = &chainntnfs.BlockEpoch{
+				Hash:   hash,
+				Height: tip.BlockHeight,
+			}
+
+			return nil
+		}, func() {
+			epoch = nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return epoch, nil
+}
+
+// unmarshalMatch converts the given state update row into a Match for the
+// given hint.
+func unmarshalMatch(hint blob.BreachHint,
+	row sqlc.ListWtServerStateUpdatesByHintRow) Match {
+
+	session := unmarshalSessionInfo(row.WtserverSession)
+
+	return Match{
+		ID:            session.ID,
+		SeqNum:        uint16(row.SeqNum),
+		Hint:          hint,
+		EncryptedBlob: row.EncryptedBlob,
+		SessionInfo:   session,
+	}
+}
+
+// unmarshalSessionInfo converts the given session row into a SessionInfo.
+func unmarshalSessionInfo(dbSession sqlc.WtserverSession) *SessionInfo {
+	session := &SessionInfo{
+		Policy: wtpolicy.Policy{
+			TxPolicy: wtpolicy.TxPolicy{
+				BlobType:   blob.Type(dbSession.BlobType),
+				RewardBase: uint32(dbSession.RewardBase),
+				RewardRate: uint32(dbSession.RewardRate),
+				SweepFeeRate: chainfee.SatPerKWeight(
+					dbSession.SweepFeeRate,
+				),
+			},
+			MaxUpdates: uint16(dbSession.MaxUpdates),
+		},
+		LastApplied:       uint16(dbSession.LastApplied),
+		ClientLastApplied: uint16(dbSession.ClientLastApplied),
+		RewardAddress:     dbSession.RewardAddress,
+	}
+	copy(session.ID[:], dbSession.SessionID)
+
+	// Like the KV store, we never return a nil reward address.
+	if session.RewardAddress == nil {
+		session.RewardAddress = []byte{}
+	}
+
+	return session
+}
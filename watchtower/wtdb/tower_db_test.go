@@ -2,12 +2,14 @@ package wtdb_test
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/binary"
 	"testing"
 
 	"github.com/flokiorg/go-flokicoin/chaincfg/chainhash"
 	"github.com/flokiorg/flnd/chainntnfs"
 	"github.com/flokiorg/flnd/kvdb"
+	"github.com/flokiorg/flnd/sqldb"
 	"github.com/flokiorg/flnd/watchtower"
 	"github.com/flokiorg/flnd/watchtower/blob"
 	"github.com/flokiorg/flnd/watchtower/wtdb"
@@ -642,6 +644,20 @@ func TestTowerDB(t *testing.T) {
 				return wtmock.NewTowerDB()
 			},
 		},
+		{
+			name: "sqlite",
+			init: func(t *testing.T) watchtower.DB {
+				db := sqldb.NewTestSqliteDB(t).BaseDB
+				executor := sqldb.NewTransactionExecutor(
+					db,
+					func(tx *sql.Tx) wtdb.SQLTowerDBQueries {
+						return db.WithTx(tx)
+					},
+				)
+
+				return wtdb.NewSQLTowerDB(executor)
+			},
+		},
 	}
 
 	tests := []struct {